@@ -44,29 +44,74 @@ fi
 
 CC=${CC:-gcc}
 CXX=${CXX:-g++}
+CLANG=${CLANG:-clang}
+CLANGXX=${CLANGXX:-clang++}
 
 go install github.com/google/wuffs/cmd/...
 go test    github.com/google/wuffs/...
 wuffs gen
+wuffs genfuzz
 
 # Compiler warning flags are discussed at
 # http://fastcompression.blogspot.com/2019/01/compiler-warnings.html
-WARNING_FLAGS="-Wall -Werror -Wpedantic -Wcast-qual -Wcast-align -Wpointer-arith -Wfloat-equal -Wundef -Wvla -Wconversion -Wshadow"
+#
+# The C_WARNING_FLAGS are only meaningful for C, not C++.
+WARNING_FLAGS="-Wall -Wextra -Werror -Wpedantic -Wcast-qual -Wcast-align -Wpointer-arith -Wfloat-equal -Wundef -Wvla -Wconversion -Wshadow -Wredundant-decls -Wmissing-declarations"
+C_WARNING_FLAGS="-Wc++-compat -Wmissing-prototypes -Wold-style-definition -Wstrict-prototypes"
+
+for STD in c99 c11; do
+  echo "Checking snapshot compiles cleanly (as C, -std=$STD)"
+  $CC -c $WARNING_FLAGS $C_WARNING_FLAGS                        -std=$STD \
+      release/c/wuffs-unsupported-snapshot.c -o /dev/null
+  $CC -c $WARNING_FLAGS $C_WARNING_FLAGS -DWUFFS_IMPLEMENTATION -std=$STD \
+      release/c/wuffs-unsupported-snapshot.c -o /dev/null
+done
 
-echo "Checking snapshot compiles cleanly (as C)"
-$CC -c $WARNING_FLAGS                        -std=c99 -Wc++-compat \
+echo "Checking snapshot compiles cleanly (as C, -std=c99, C99 dialect)"
+$CC -c $WARNING_FLAGS $C_WARNING_FLAGS -DWUFFS_IMPLEMENTATION -std=c99 \
+    -DWUFFS_CONFIG__C_DIALECT__C99 \
     release/c/wuffs-unsupported-snapshot.c -o /dev/null
-$CC -c $WARNING_FLAGS -DWUFFS_IMPLEMENTATION -std=c99 -Wc++-compat \
+
+echo "Checking snapshot compiles cleanly (as C, -std=c2x, C23 dialect)"
+$CC -c $WARNING_FLAGS $C_WARNING_FLAGS -DWUFFS_IMPLEMENTATION -std=c2x \
+    -DWUFFS_CONFIG__C_DIALECT__C23 \
     release/c/wuffs-unsupported-snapshot.c -o /dev/null
 
-echo "Checking snapshot compiles cleanly (as C++)"
-$CXX -c $WARNING_FLAGS                        -std=c++11 -x c++ \
+for STD in c++11 c++17; do
+  echo "Checking snapshot compiles cleanly (as C++, -std=$STD)"
+  $CXX -c $WARNING_FLAGS                        -std=$STD -x c++ \
+      release/c/wuffs-unsupported-snapshot.c -o /dev/null
+  $CXX -c $WARNING_FLAGS -DWUFFS_IMPLEMENTATION -std=$STD -x c++ \
+      release/c/wuffs-unsupported-snapshot.c -o /dev/null
+done
+
+# Some warnings, such as -Wstringop-overflow, are only issued when optimizing.
+echo "Checking snapshot compiles cleanly (as C and C++, -O2)"
+$CC  -c $WARNING_FLAGS $C_WARNING_FLAGS -DWUFFS_IMPLEMENTATION -std=c99 -O2 \
     release/c/wuffs-unsupported-snapshot.c -o /dev/null
-$CXX -c $WARNING_FLAGS -DWUFFS_IMPLEMENTATION -std=c++11 -x c++ \
+$CXX -c $WARNING_FLAGS -DWUFFS_IMPLEMENTATION -std=c++11 -x c++ -O2 \
     release/c/wuffs-unsupported-snapshot.c -o /dev/null
 
+# clang's warnings differ from gcc's, so check both when clang is installed.
+# MSVC /W4 is checked by .github/workflows/msvc.yml.
+if command -v $CLANG > /dev/null && command -v $CLANGXX > /dev/null; then
+  for OPT in -O0 -O2; do
+    echo "Checking snapshot compiles cleanly (as C and C++, clang, $OPT)"
+    $CLANG   -c $WARNING_FLAGS $C_WARNING_FLAGS -DWUFFS_IMPLEMENTATION -std=c99 $OPT \
+        release/c/wuffs-unsupported-snapshot.c -o /dev/null
+    $CLANGXX -c $WARNING_FLAGS -DWUFFS_IMPLEMENTATION -std=c++11 -x c++ $OPT \
+        release/c/wuffs-unsupported-snapshot.c -o /dev/null
+  done
+else
+  echo "Skipping clang checks: $CLANG or $CLANGXX not found"
+fi
+
 wuffs genlib -skipgen
 wuffs test   -skipgen -mimic
+mkdir -p gen/bin
+$CXX $WARNING_FLAGS -std=c++11 test/c/auxiliary/rac.cc -o gen/bin/test-aux-rac
+echo "Running  gen/bin/test-aux-rac"
+(cd test/c/auxiliary && ../../../gen/bin/test-aux-rac)
 wuffs bench  -skipgen -mimic -reps=1 -iterscale=1
 
 ./build-example.sh
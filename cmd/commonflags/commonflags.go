@@ -27,9 +27,21 @@ import (
 )
 
 const (
+	AnnotationsDefault = false
+	AnnotationsUsage   = `whether to annotate the generated public API with Clang nullability qualifiers and thread-safety comments`
+
 	CcompilersDefault = "clang-9,gcc"
 	CcompilersUsage   = `comma-separated list of C compilers`
 
+	CoroutinedispatchDefault = "switch"
+	CoroutinedispatchUsage   = `comma-separated list of coroutine dispatch mechanisms: "switch", "computedgoto" and/or "closedsrc"`
+
+	CdialectDefault = ""
+	CdialectUsage   = `C dialect of the generated code: "" (C99 or later), "c99" (strict C99, with a "//"-free header) or "c23" (C23 features)`
+
+	CppwrappersDefault = false
+	CppwrappersUsage   = `whether to generate C++ wrapper classes (with RAII and std::span overloads)`
+
 	FocusDefault = ""
 	FocusUsage   = `comma-separated list of tests or benchmarks (name prefixes) to focus on, e.g. "wuffs_gif_decode"`
 
@@ -44,11 +56,26 @@ const (
 	MimicDefault = false
 	MimicUsage   = `whether to compare Wuffs' output with other libraries' output`
 
+	OnlyneededbaseDefault = false
+	OnlyneededbaseUsage   = `whether to emit only the base functions, types and tables that the generated packages refer to`
+
 	RepsDefault = 5
 	RepsMin     = 0
 	RepsMax     = 1000000
 	RepsUsage   = `the number of repetitions per benchmark`
 
+	SmtDefault = ""
+	SmtUsage   = `SMT solver command (e.g. "z3") to try on proof obligations that the built-in checker cannot discharge; "" means none`
+
+	SmtcacheDefault = ""
+	SmtcacheUsage   = `directory for exported SMT-LIB proof obligations and cached solver answers; "" means a default under the user's cache directory`
+
+	RuntimetablesDefault = false
+	RuntimetablesUsage   = `whether to compute large const tables at initialize time, instead of as read-only data`
+
+	TerminationDefault = false
+	TerminationUsage   = `whether to check that every while loop in a non-coroutine function terminates`
+
 	VersionDefault = "0.0.0"
 	VersionUsage   = `version string, e.g. "1.2.3-beta.4"`
 )
@@ -74,6 +101,21 @@ func IsAlphaNumericIsh(s string) bool {
 	return true
 }
 
+func IsValidCdialect(s string) bool {
+	return s == "" || s == "c99" || s == "c23"
+}
+
+// IsValidCoroutinedispatch returns whether s is a non-empty, comma-separated
+// list of "switch", "computedgoto" and "closedsrc".
+func IsValidCoroutinedispatch(s string) bool {
+	for _, x := range strings.Split(s, ",") {
+		if x != "switch" && x != "computedgoto" && x != "closedsrc" {
+			return false
+		}
+	}
+	return true
+}
+
 func IsValidUsePath(s string) bool {
 	return s == path.Clean(s) && s != "" && s[0] != '.' && s[0] != '/'
 }
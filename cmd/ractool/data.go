@@ -111,6 +111,7 @@ Encode-Related Flags:
 
 Codecs:
 
+    bzip2
     lz4
     zlib
     zstd
@@ -112,6 +112,7 @@ Encode-Related Flags:
 
 Codecs:
 
+    bzip2
     lz4
     zlib
     zstd
@@ -258,6 +259,7 @@ import (
 	"strings"
 
 	"github.com/google/wuffs/lib/rac"
+	"github.com/google/wuffs/lib/racbzip2"
 	"github.com/google/wuffs/lib/raclz4"
 	"github.com/google/wuffs/lib/raczlib"
 	"github.com/google/wuffs/lib/raczstd"
@@ -444,6 +446,7 @@ func decode(inFile *os.File) error {
 		ReadSeeker:     rs,
 		CompressedSize: compressedSize,
 		CodecReaders: []rac.CodecReader{
+			&racbzip2.CodecReader{},
 			&raclz4.CodecReader{},
 			&raczlib.CodecReader{},
 			&raczstd.CodecReader{},
@@ -520,6 +523,8 @@ func encode(r io.Reader) error {
 		DChunkSize:    uint64(dchunksize),
 	}
 	switch *codecFlag {
+	case "bzip2":
+		rw.CodecWriter = &racbzip2.CodecWriter{}
 	case "lz4":
 		rw.CodecWriter = &raclz4.CodecWriter{}
 	case "zlib":
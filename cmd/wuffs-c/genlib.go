@@ -17,6 +17,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -30,12 +31,16 @@ import (
 func doGenlib(args []string) error {
 	flags := flag.FlagSet{}
 	ccompilersFlag := flags.String("ccompilers", cf.CcompilersDefault, cf.CcompilersUsage)
+	cdialectFlag := flags.String("cdialect", cf.CdialectDefault, cf.CdialectUsage)
 	dstdirFlag := flags.String("dstdir", "", "directory containing the object files ")
 	srcdirFlag := flags.String("srcdir", "", "directory containing the C source files")
 	if err := flags.Parse(args); err != nil {
 		return err
 	}
 	args = flags.Args()
+	if !cf.IsValidCdialect(*cdialectFlag) {
+		return fmt.Errorf("bad -cdialect flag value %q", *cdialectFlag)
+	}
 
 	filenames := []string(nil)
 	for _, arg := range args {
@@ -55,6 +60,12 @@ func doGenlib(args []string) error {
 		return fmt.Errorf("empty -srcdir flag")
 	}
 
+	if *cdialectFlag == "c99" {
+		if err := checkC99(*srcdirFlag, filenames); err != nil {
+			return err
+		}
+	}
+
 	for _, cc := range strings.Split(*ccompilersFlag, ",") {
 		cc = strings.TrimSpace(cc)
 		if cc == "" {
@@ -66,7 +77,7 @@ func doGenlib(args []string) error {
 			if err := os.MkdirAll(outDir, 0755); err != nil {
 				return err
 			}
-			if err := genObj(outDir, *srcdirFlag, cc, dynamism, filenames); err != nil {
+			if err := genObj(outDir, *srcdirFlag, cc, dynamism, *cdialectFlag, filenames); err != nil {
 				return err
 			}
 			if err := genLib(outDir, cc, dynamism, filenames); err != nil {
@@ -89,13 +100,16 @@ var (
 	}
 )
 
-func genObj(outDir string, inDir string, cc string, dynamism string, filenames []string) error {
+func genObj(outDir string, inDir string, cc string, dynamism string, cdialect string, filenames []string) error {
 	for _, filename := range filenames {
 		in := ""
 		out := genlibOutFilename(outDir, dynamism, filename)
 
 		args := []string(nil)
 		args = append(args, "-O3", "-std=c99", "-DWUFFS_IMPLEMENTATION")
+		if cdialect == "c99" {
+			args = append(args, "-pedantic-errors")
+		}
 
 		const wuffsBasePrefix = "wuffs-base-"
 		if strings.HasPrefix(filename, wuffsBasePrefix) {
@@ -125,6 +139,31 @@ func genObj(outDir string, inDir string, cc string, dynamism string, filenames [
 	return nil
 }
 
+// checkC99 checks that "-cdialect=c99" generated code has no "//" comments
+// in its header section. Compiling with "-pedantic-errors" checks the rest.
+func checkC99(inDir string, filenames []string) error {
+	seen := map[string]bool{}
+	for _, filename := range filenames {
+		if strings.HasPrefix(filename, "wuffs-base-") {
+			filename = "wuffs-base"
+		}
+		if seen[filename] {
+			continue
+		}
+		seen[filename] = true
+
+		in := filepath.Join(inDir, filename+".c")
+		src, err := ioutil.ReadFile(in)
+		if err != nil {
+			return err
+		}
+		if line := cgen.FindLineComment(src); line > 0 {
+			return fmt.Errorf("%s:%d: \"//\" comment in the header section, despite -cdialect=c99", in, line)
+		}
+	}
+	return nil
+}
+
 func genLib(outDir string, cc string, dynamism string, filenames []string) error {
 	args := []string(nil)
 	switch dynamism {
@@ -24,6 +24,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/google/wuffs/internal/cgen"
 	"github.com/google/wuffs/internal/cgen/data"
 
 	cf "github.com/google/wuffs/cmd/commonflags"
@@ -31,14 +32,19 @@ import (
 
 func doGenrelease(args []string) error {
 	flags := flag.FlagSet{}
+	cdialectFlag := flags.String("cdialect", cf.CdialectDefault, cf.CdialectUsage)
 	commitDateFlag := flags.String("commitdate", "", "git commit date the release was built from")
 	gitRevListCountFlag := flags.Int("gitrevlistcount", 0, `git "rev-list --count" that the release was built from`)
+	onlyneededbaseFlag := flags.Bool("only-needed-base", cf.OnlyneededbaseDefault, cf.OnlyneededbaseUsage)
 	revisionFlag := flags.String("revision", "", "git revision the release was built from")
 	versionFlag := flags.String("version", cf.VersionDefault, cf.VersionUsage)
 
 	if err := flags.Parse(args); err != nil {
 		return err
 	}
+	if !cf.IsValidCdialect(*cdialectFlag) {
+		return fmt.Errorf("bad -cdialect flag value %q", *cdialectFlag)
+	}
 	if (*gitRevListCountFlag < 0) || (0x7FFFFFFF < *gitRevListCountFlag) {
 		return fmt.Errorf("bad -gitrevlistcount flag value %d", *gitRevListCountFlag)
 	}
@@ -94,6 +100,12 @@ func doGenrelease(args []string) error {
 	}
 	sort.Strings(h.filesList)
 
+	if *onlyneededbaseFlag {
+		if err := h.pruneBase(); err != nil {
+			return err
+		}
+	}
+
 	out := bytes.NewBuffer(nil)
 	out.WriteString("#ifndef WUFFS_INCLUDE_GUARD\n")
 	out.WriteString("#define WUFFS_INCLUDE_GUARD\n\n")
@@ -139,6 +151,10 @@ func doGenrelease(args []string) error {
 	out.WriteString(grPragmaPop)
 	out.WriteString("#endif  // WUFFS_INCLUDE_GUARD\n")
 
+	if *cdialectFlag == "c99" {
+		os.Stdout.Write(cgen.C99ify(out.Bytes(), false))
+		return nil
+	}
 	os.Stdout.Write(out.Bytes())
 	return nil
 }
@@ -248,6 +264,42 @@ func (h *genReleaseHelper) parse(relFilename string, s []byte) error {
 	return nil
 }
 
+// pruneBase drops the parts of wuffs-base.c that neither the other packages
+// nor the auxiliary C++ code refer to.
+func (h *genReleaseHelper) pruneBase() error {
+	const baseFilename = "wuffs-base.c"
+	base, ok := h.filesMap[baseFilename]
+	if !ok {
+		return fmt.Errorf("cannot resolve %q", baseFilename)
+	}
+
+	roots := [][]byte{
+		[]byte(data.AuxBaseHh),
+		[]byte(data.AuxBaseCc),
+	}
+	for _, f := range data.AuxNonBaseHhFiles {
+		roots = append(roots, []byte(f))
+	}
+	for _, f := range data.AuxNonBaseCcFiles {
+		roots = append(roots, []byte(f))
+	}
+	for _, relFilename := range h.filesList {
+		if relFilename != baseFilename {
+			f := h.filesMap[relFilename]
+			roots = append(roots, f.fragments[0], f.fragments[1])
+		}
+	}
+
+	fragments, err := cgen.PruneBase(base.fragments, roots)
+	if err != nil {
+		return err
+	}
+	base.fragments[0] = bytes.TrimSpace(fragments[0])
+	base.fragments[1] = bytes.TrimSpace(fragments[1])
+	h.filesMap[baseFilename] = base
+	return nil
+}
+
 func parseIncludes(s []byte) (ret []string) {
 	for remaining := []byte(nil); len(s) > 0; s, remaining = remaining, nil {
 		if i := bytes.IndexByte(s, '\n'); i >= 0 {
@@ -35,6 +35,7 @@ func doTest(args []string) error  { return doBenchTest(args, false) }
 func doBenchTest(args []string, bench bool) error {
 	flags := flag.FlagSet{}
 	ccompilersFlag := flags.String("ccompilers", cf.CcompilersDefault, cf.CcompilersUsage)
+	coroutinedispatchFlag := flags.String("coroutinedispatch", cf.CoroutinedispatchDefault, cf.CoroutinedispatchUsage)
 	focusFlag := flags.String("focus", cf.FocusDefault, cf.FocusUsage)
 	iterscaleFlag := flags.Int("iterscale", cf.IterscaleDefault, cf.IterscaleUsage)
 	mimicFlag := flags.Bool("mimic", cf.MimicDefault, cf.MimicUsage)
@@ -47,6 +48,9 @@ func doBenchTest(args []string, bench bool) error {
 	if !cf.IsAlphaNumericIsh(*ccompilersFlag) {
 		return fmt.Errorf("bad -ccompilers flag value %q", *ccompilersFlag)
 	}
+	if !cf.IsValidCoroutinedispatch(*coroutinedispatchFlag) {
+		return fmt.Errorf("bad -coroutinedispatch flag value %q", *coroutinedispatchFlag)
+	}
 	if !cf.IsAlphaNumericIsh(*focusFlag) {
 		return fmt.Errorf("bad -focus flag value %q", *focusFlag)
 	}
@@ -63,8 +67,8 @@ func doBenchTest(args []string, bench bool) error {
 
 	failed := false
 	for _, arg := range args {
-		f, err := doBenchTest1(arg, bench,
-			*ccompilersFlag, *focusFlag, *iterscaleFlag, *mimicFlag, *repsFlag)
+		f, err := doBenchTest1(arg, bench, *ccompilersFlag, *coroutinedispatchFlag,
+			*focusFlag, *iterscaleFlag, *mimicFlag, *repsFlag)
 		if err != nil {
 			return err
 		}
@@ -80,8 +84,8 @@ func doBenchTest(args []string, bench bool) error {
 	return nil
 }
 
-func doBenchTest1(filename string, bench bool, ccompilers string, focus string,
-	iterscale int, mimic bool, reps int) (failed bool, err error) {
+func doBenchTest1(filename string, bench bool, ccompilers string, coroutinedispatch string,
+	focus string, iterscale int, mimic bool, reps int) (failed bool, err error) {
 
 	workDir, err := ioutil.TempDir("", "wuffs-c")
 	if err != nil {
@@ -111,36 +115,57 @@ func doBenchTest1(filename string, bench bool, ccompilers string, focus string,
 			continue
 		}
 
-		ccCmd := exec.Command(cc, ccArgs...)
-		ccCmd.Stdout = os.Stdout
-		ccCmd.Stderr = os.Stderr
-		if err := ccCmd.Run(); err != nil {
-			return false, err
+		for _, cd := range strings.Split(coroutinedispatch, ",") {
+			f, err := doBenchTest2(out, bench, cc, cd, ccArgs, focus, iterscale, reps)
+			if err != nil {
+				return false, err
+			}
+			failed = failed || f
 		}
+	}
+	return failed, nil
+}
 
-		outArgs := []string(nil)
-		if bench {
-			outArgs = append(outArgs, "-bench",
-				fmt.Sprintf("-iterscale=%d", iterscale),
-				fmt.Sprintf("-reps=%d", reps),
-			)
-		}
-		if focus != "" {
-			outArgs = append(outArgs, fmt.Sprintf("-focus=%s", focus))
-		}
-		outCmd := exec.Command(out, outArgs...)
-		outCmd.Stdout = os.Stdout
-		outCmd.Stderr = os.Stderr
-		if outCmd.Dir, err = wuffsroot.Value(); err != nil {
-			return false, err
-		}
-		if err := outCmd.Run(); err == nil {
-			// No-op.
-		} else if _, ok := err.(*exec.ExitError); ok {
-			failed = true
-		} else {
-			return false, err
-		}
+func doBenchTest2(out string, bench bool, cc string, coroutinedispatch string,
+	ccArgs []string, focus string, iterscale int, reps int) (failed bool, err error) {
+
+	// The full slice expression makes the append copy, instead of modifying
+	// the caller's ccArgs backing array.
+	switch coroutinedispatch {
+	case "computedgoto":
+		ccArgs = append(ccArgs[:len(ccArgs):len(ccArgs)], "-DWUFFS_CONFIG__COROUTINE_COMPUTED_GOTO")
+	case "closedsrc":
+		ccArgs = append(ccArgs[:len(ccArgs):len(ccArgs)], "-DWUFFS_CONFIG__CLOSED_SRC_FAST_PATH")
+	}
+	ccCmd := exec.Command(cc, ccArgs...)
+	ccCmd.Stdout = os.Stdout
+	ccCmd.Stderr = os.Stderr
+	if err := ccCmd.Run(); err != nil {
+		return false, err
+	}
+
+	outArgs := []string(nil)
+	if bench {
+		outArgs = append(outArgs, "-bench",
+			fmt.Sprintf("-iterscale=%d", iterscale),
+			fmt.Sprintf("-reps=%d", reps),
+		)
+	}
+	if focus != "" {
+		outArgs = append(outArgs, fmt.Sprintf("-focus=%s", focus))
+	}
+	outCmd := exec.Command(out, outArgs...)
+	outCmd.Stdout = os.Stdout
+	outCmd.Stderr = os.Stderr
+	if outCmd.Dir, err = wuffsroot.Value(); err != nil {
+		return false, err
+	}
+	if err := outCmd.Run(); err == nil {
+		// No-op.
+	} else if _, ok := err.(*exec.ExitError); ok {
+		failed = true
+	} else {
+		return false, err
 	}
 	return failed, nil
 }
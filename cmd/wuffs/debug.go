@@ -0,0 +1,664 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// debug.go implements "wuffs debug", which runs a decoder over an input one
+// small chunk at a time, recording every call's io positions and status and
+// every WUFFS_TRACE event (including each coroutine suspension) in between.
+// The recorded timeline can then be stepped through, forwards or backwards.
+//
+// The decoder runs as a C program, compiled on the fly against the release C
+// file, with a WUFFS_TRACE implementation that prints the events to stdout.
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	cf "github.com/google/wuffs/cmd/commonflags"
+)
+
+const (
+	ccompilerDefault = "cc"
+	ccompilerUsage   = `the C compiler`
+
+	chunkDefault = 64
+	chunkUsage   = `the number of source bytes to make available per short read`
+
+	dstchunkDefault = 4096
+	dstchunkUsage   = `the destination buffer's length, in bytes or tokens, for io_transformers and token_decoders`
+
+	printDefault = false
+	printUsage   = `whether to print the whole timeline instead of stepping through it interactively`
+
+	quirksDefault = ""
+	quirksUsage   = `comma-separated list of quirks (as hexadecimal numbers) to enable`
+
+	recordDefault = ""
+	recordUsage   = `if non-empty, the file to save the timeline to`
+
+	replayDefault = ""
+	replayUsage   = `if non-empty, the file (saved by -record) to load the timeline from, instead of running a decoder`
+)
+
+// debugEvent is a WUFFS_TRACE event.
+type debugEvent struct {
+	Event    string `json:"event"`
+	FuncName string `json:"func"`
+	Status   string `json:"status,omitempty"`
+	Value0   uint64 `json:"v0,omitempty"`
+	Value1   uint64 `json:"v1,omitempty"`
+}
+
+// debugStep is one call to a decoder's method, such as decode_frame.
+type debugStep struct {
+	Method    string       `json:"method"`
+	Status    string       `json:"status"`
+	SrcPos    uint64       `json:"srcPos"`
+	SrcWI     uint64       `json:"srcWI"`
+	SrcClosed bool         `json:"srcClosed"`
+	DstPos    uint64       `json:"dstPos"`
+	Events    []debugEvent `json:"events,omitempty"`
+}
+
+// debugTimeline is everything recorded by one "wuffs debug" run.
+type debugTimeline struct {
+	Package   string      `json:"package"`
+	Interface string      `json:"interface"`
+	Filename  string      `json:"filename"`
+	SrcLen    uint64      `json:"srcLen"`
+	Steps     []debugStep `json:"steps"`
+}
+
+var debugEventNames = [...]string{
+	1: "status",
+	2: "frame_begin",
+	3: "frame_end",
+	4: "quirk",
+	5: "suspend",
+}
+
+func doDebug(wuffsRoot string, args []string) error {
+	flags := flag.NewFlagSet("debug", flag.ExitOnError)
+	ccompilerFlag := flags.String("ccompiler", ccompilerDefault, ccompilerUsage)
+	chunkFlag := flags.Int("chunk", chunkDefault, chunkUsage)
+	dstchunkFlag := flags.Int("dstchunk", dstchunkDefault, dstchunkUsage)
+	printFlag := flags.Bool("print", printDefault, printUsage)
+	quirksFlag := flags.String("quirks", quirksDefault, quirksUsage)
+	recordFlag := flags.String("record", recordDefault, recordUsage)
+	replayFlag := flags.String("replay", replayDefault, replayUsage)
+	skipgenFlag := flags.Bool("skipgen", skipgenDefault, skipgenUsage)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: wuffs debug [flags] package filename\n"+
+			"   or: wuffs debug [flags] -replay=timeline.json\n\n"+
+			"e.g.:  wuffs debug -chunk=16 std/gif test/data/bricks-dither.gif\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !cf.IsAlphaNumericIsh(*ccompilerFlag) {
+		return fmt.Errorf("bad -ccompiler flag value %q", *ccompilerFlag)
+	}
+	if *chunkFlag <= 0 {
+		return fmt.Errorf("bad -chunk flag value %d", *chunkFlag)
+	}
+	if *dstchunkFlag <= 0 {
+		return fmt.Errorf("bad -dstchunk flag value %d", *dstchunkFlag)
+	}
+	quirks, err := parseDebugQuirks(*quirksFlag)
+	if err != nil {
+		return err
+	}
+
+	tl := (*debugTimeline)(nil)
+	if *replayFlag != "" {
+		if flags.NArg() != 0 {
+			flags.Usage()
+			os.Exit(1)
+		}
+		src, err := ioutil.ReadFile(*replayFlag)
+		if err != nil {
+			return err
+		}
+		tl = &debugTimeline{}
+		if err := json.Unmarshal(src, tl); err != nil {
+			return fmt.Errorf("%s: %v", *replayFlag, err)
+		}
+
+	} else {
+		if flags.NArg() != 2 {
+			flags.Usage()
+			os.Exit(1)
+		}
+		h := debugHelper{
+			wuffsRoot: wuffsRoot,
+			ccompiler: *ccompilerFlag,
+			pkg:       strings.TrimSuffix(filepath.ToSlash(flags.Arg(0)), "/"),
+			filename:  flags.Arg(1),
+			chunk:     *chunkFlag,
+			dstchunk:  *dstchunkFlag,
+			quirks:    quirks,
+		}
+		if !*skipgenFlag {
+			gh := genHelper{
+				wuffsRoot: wuffsRoot,
+				langs:     []string{"c"},
+				skipgen:   *skipgenFlag,
+			}
+			if err := gh.gen(h.pkg, false); err != nil {
+				return err
+			}
+			if err := genrelease(wuffsRoot, []string{"c"}, cf.Version{}, cf.CdialectDefault, false); err != nil {
+				return err
+			}
+		}
+		if tl, err = h.run(); err != nil {
+			return err
+		}
+	}
+
+	if *recordFlag != "" {
+		b, err := json.MarshalIndent(tl, "", "\t")
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(*recordFlag, append(b, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+
+	if *printFlag {
+		w := bufio.NewWriter(os.Stdout)
+		for i := range tl.Steps {
+			tl.printStep(w, i)
+		}
+		return w.Flush()
+	}
+	return tl.interact(os.Stdin, os.Stdout)
+}
+
+func parseDebugQuirks(s string) (ret []uint32, err error) {
+	if s == "" {
+		return nil, nil
+	}
+	for _, q := range strings.Split(s, ",") {
+		u, err := strconv.ParseUint(strings.TrimPrefix(q, "0x"), 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("bad -quirks flag value %q", s)
+		}
+		ret = append(ret, uint32(u))
+	}
+	return ret, nil
+}
+
+type debugHelper struct {
+	wuffsRoot string
+	ccompiler string
+	pkg       string
+	filename  string
+	chunk     int
+	dstchunk  int
+	quirks    []uint32
+}
+
+func (h *debugHelper) run() (*debugTimeline, error) {
+	packageName := filepath.Base(h.pkg)
+	if !validName(packageName) {
+		return nil, fmt.Errorf(`invalid package %q, not in [a-z0-9]+`, packageName)
+	}
+	iface, err := decoderInterface(h.wuffsRoot, h.pkg)
+	if err != nil {
+		return nil, err
+	} else if iface == "" {
+		return nil, fmt.Errorf("package %q has no image_decoder, io_transformer or token_decoder", h.pkg)
+	}
+	info, err := os.Stat(h.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := ioutil.TempDir("", "wuffs-debug-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	releaseC := filepath.Join(h.wuffsRoot, "release", "c", "wuffs-unsupported-snapshot.c")
+	driverC := strings.NewReplacer(
+		"DEBUG_PACKAGE", packageName,
+		"DEBUG_INTERFACE", iface,
+		"DEBUG_RELEASE_C", strconv.Quote(releaseC),
+	).Replace(debugDriverC)
+	driverFilename := filepath.Join(tmpDir, "driver.c")
+	if err := ioutil.WriteFile(driverFilename, []byte(driverC), 0644); err != nil {
+		return nil, err
+	}
+	exeFilename := filepath.Join(tmpDir, "driver")
+	cc := exec.Command(h.ccompiler, "-std=c99", "-O1", driverFilename, "-o", exeFilename)
+	cc.Stdout = os.Stdout
+	cc.Stderr = os.Stderr
+	if err := cc.Run(); err != nil {
+		return nil, fmt.Errorf("compiling the debug driver: %v", err)
+	}
+
+	runArgs := []string{h.filename, strconv.Itoa(h.chunk), strconv.Itoa(h.dstchunk)}
+	for _, q := range h.quirks {
+		runArgs = append(runArgs, fmt.Sprintf("%08X", q))
+	}
+	out, err := exec.Command(exeFilename, runArgs...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("running the debug driver: %v", err)
+	}
+
+	tl := &debugTimeline{
+		Package:   h.pkg,
+		Interface: iface,
+		Filename:  h.filename,
+		SrcLen:    uint64(info.Size()),
+	}
+	if err := tl.parse(out); err != nil {
+		return nil, err
+	}
+	return tl, nil
+}
+
+// parse parses the debug driver's output: an "E" line per WUFFS_TRACE event
+// and an "S" line after each method call, with tab-separated fields.
+func (tl *debugTimeline) parse(out []byte) error {
+	events := []debugEvent(nil)
+	for _, line := range strings.Split(string(out), "\n") {
+		if line == "" {
+			continue
+		}
+		f := strings.Split(line, "\t")
+		switch {
+		case (f[0] == "E") && (len(f) == 6):
+			e := debugEvent{FuncName: f[2], Status: f[3]}
+			if n, err := strconv.Atoi(f[1]); (err == nil) && (0 < n) && (n < len(debugEventNames)) {
+				e.Event = debugEventNames[n]
+			} else {
+				e.Event = "event#" + f[1]
+			}
+			if e.Status == "-" {
+				e.Status = ""
+			}
+			e.Value0, _ = strconv.ParseUint(f[4], 10, 64)
+			e.Value1, _ = strconv.ParseUint(f[5], 10, 64)
+			events = append(events, e)
+
+		case (f[0] == "S") && (len(f) == 7):
+			s := debugStep{Method: f[1], Status: f[2], SrcClosed: f[5] == "1", Events: events}
+			s.SrcPos, _ = strconv.ParseUint(f[3], 10, 64)
+			s.SrcWI, _ = strconv.ParseUint(f[4], 10, 64)
+			s.DstPos, _ = strconv.ParseUint(f[6], 10, 64)
+			tl.Steps = append(tl.Steps, s)
+			events = nil
+
+		case f[0] == "X":
+			return fmt.Errorf("debug driver: %s", strings.Join(f[1:], " "))
+
+		default:
+			return fmt.Errorf("debug driver: unexpected output %q", line)
+		}
+	}
+	if len(tl.Steps) == 0 {
+		return errors.New("debug driver: no steps recorded")
+	}
+	return nil
+}
+
+func (tl *debugTimeline) dstUnits() string {
+	switch tl.Interface {
+	case "image_decoder":
+		return "frames"
+	case "token_decoder":
+		return "tokens"
+	}
+	return "bytes"
+}
+
+func (tl *debugTimeline) printStep(w io.Writer, i int) {
+	s := &tl.Steps[i]
+	fmt.Fprintf(w, "step %d/%d: %s -> %s\n", i+1, len(tl.Steps), s.Method, s.Status)
+	closed := ""
+	if s.SrcClosed {
+		closed = ", closed"
+	}
+	fmt.Fprintf(w, "  src: read %d of %d available bytes (file length %d%s)\n",
+		s.SrcPos, s.SrcWI, tl.SrcLen, closed)
+	fmt.Fprintf(w, "  dst: %d %s\n", s.DstPos, tl.dstUnits())
+	for _, e := range s.Events {
+		fmt.Fprintf(w, "  %-11s %s", e.Event, e.FuncName)
+		switch e.Event {
+		case "suspend":
+			fmt.Fprintf(w, " at suspension point %d", e.Value0)
+		case "quirk":
+			fmt.Fprintf(w, " 0x%08X=%t", e.Value0, e.Value1 != 0)
+		}
+		if e.Status != "" {
+			fmt.Fprintf(w, " (%s)", e.Status)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+}
+
+const debugHelpText = `Commands:
+  n, <Enter>  next step
+  p           previous step
+  f           forward to the next step that did not suspend
+  b           back to the previous step that did not suspend
+  e           forward to the next step that failed
+  g N         go to step N
+  l           list every step, one per line
+  q           quit
+`
+
+// interact runs the interactive timeline stepper.
+func (tl *debugTimeline) interact(r io.Reader, w io.Writer) error {
+	fmt.Fprintf(w, "%s: %s over %s, %d steps. Type h for help.\n",
+		tl.Package, tl.Interface, tl.Filename, len(tl.Steps))
+	i, scanner := 0, bufio.NewScanner(r)
+	tl.printStep(w, i)
+	for {
+		fmt.Fprintf(w, "(wuffs debug) ")
+		if !scanner.Scan() {
+			fmt.Fprintf(w, "\n")
+			return scanner.Err()
+		}
+		cmd := strings.Fields(scanner.Text())
+		if len(cmd) == 0 {
+			cmd = []string{"n"}
+		}
+		j := i
+		switch cmd[0] {
+		case "n":
+			j = i + 1
+		case "p":
+			j = i - 1
+		case "f":
+			for j = i + 1; (j < len(tl.Steps)) && tl.Steps[j].suspended(); j++ {
+			}
+		case "b":
+			for j = i - 1; (j >= 0) && tl.Steps[j].suspended(); j-- {
+			}
+		case "e":
+			for j = i + 1; (j < len(tl.Steps)) && !tl.Steps[j].failed(); j++ {
+			}
+		case "g":
+			if len(cmd) != 2 {
+				fmt.Fprintf(w, "usage: g N\n")
+				continue
+			}
+			n, err := strconv.Atoi(cmd[1])
+			if err != nil {
+				fmt.Fprintf(w, "bad step number %q\n", cmd[1])
+				continue
+			}
+			j = n - 1
+		case "l":
+			for k := range tl.Steps {
+				s := &tl.Steps[k]
+				marker := ' '
+				if k == i {
+					marker = '>'
+				}
+				fmt.Fprintf(w, "%c %5d  %-22s src=%-8d dst=%-8d %s\n",
+					marker, k+1, s.Method, s.SrcPos, s.DstPos, s.Status)
+			}
+			continue
+		case "q":
+			return nil
+		default:
+			fmt.Fprintf(w, "%s", debugHelpText)
+			continue
+		}
+		if (j < 0) || (len(tl.Steps) <= j) {
+			fmt.Fprintf(w, "no such step\n")
+			continue
+		}
+		i = j
+		tl.printStep(w, i)
+	}
+}
+
+func (s *debugStep) suspended() bool {
+	return strings.HasPrefix(s.Status, "$")
+}
+
+func (s *debugStep) failed() bool {
+	return strings.HasPrefix(s.Status, "#")
+}
+
+// debugDriverC is the C program that runs the decoder. Its arguments are the
+// input filename, the chunk and dstchunk lengths and then zero or more quirks
+// as hexadecimal numbers.
+const debugDriverC = `// Generated by "wuffs debug". DO NOT EDIT.
+
+#include <inttypes.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+static void  //
+debug_trace(int event,
+            const char* func_name,
+            const char* status_repr,
+            uint64_t value0,
+            uint64_t value1) {
+  printf("E\t%d\t%s\t%s\t%" PRIu64 "\t%" PRIu64 "\n", event, func_name,
+         status_repr ? status_repr : "-", value0, value1);
+}
+
+#define WUFFS_TRACE(event, receiver, func_name, status_repr, value0, value1) \
+  debug_trace(event, func_name, status_repr, (uint64_t)(value0),            \
+              (uint64_t)(value1))
+
+#define WUFFS_IMPLEMENTATION
+#include DEBUG_RELEASE_C
+
+wuffs_base__io_buffer g_src;
+size_t g_chunk;
+size_t g_dst_chunk;
+uint64_t g_dst_pos;
+
+// feed makes more of the source available, returning whether it did.
+static bool  //
+feed(void) {
+  if (g_src.meta.closed) {
+    return false;
+  }
+  size_t n = g_src.data.len - g_src.meta.wi;
+  g_src.meta.wi += (n < g_chunk) ? n : g_chunk;
+  g_src.meta.closed = g_src.meta.wi == g_src.data.len;
+  return true;
+}
+
+// step records a method call and returns whether to call it again.
+static bool  //
+step(const char* method, wuffs_base__status status) {
+  printf("S\t%s\t%s\t%zu\t%zu\t%d\t%" PRIu64 "\n", method,
+         status.repr ? status.repr : "ok", g_src.meta.ri, g_src.meta.wi,
+         g_src.meta.closed ? 1 : 0, g_dst_pos);
+  return (status.repr == wuffs_base__suspension__short_read) && feed();
+}
+
+static wuffs_base__slice_u8  //
+make_workbuf(uint64_t len) {
+  uint8_t* ptr = (len > 0) ? malloc(len) : NULL;
+  return wuffs_base__make_slice_u8(ptr, ptr ? len : 0);
+}
+
+static const char*  //
+run_image_decoder(wuffs_base__image_decoder* dec) {
+  wuffs_base__image_config ic = {0};
+  wuffs_base__status status;
+  do {
+    status = wuffs_base__image_decoder__decode_image_config(dec, &ic, &g_src);
+  } while (step("decode_image_config", status));
+  if (!wuffs_base__status__is_ok(&status)) {
+    return NULL;
+  }
+
+  uint32_t width = wuffs_base__pixel_config__width(&ic.pixcfg);
+  uint32_t height = wuffs_base__pixel_config__height(&ic.pixcfg);
+  if ((width > 0x4000) || (height > 0x4000)) {
+    return "image is too large";
+  }
+  wuffs_base__pixel_config__set(
+      &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, width, height);
+  size_t len = ((size_t)width) * ((size_t)height) * 4;
+  uint8_t* ptr = malloc(len ? len : 1);
+  if (!ptr) {
+    return "could not allocate the pixel buffer";
+  }
+  wuffs_base__pixel_buffer pb = {0};
+  status = wuffs_base__pixel_buffer__set_from_slice(
+      &pb, &ic.pixcfg, wuffs_base__make_slice_u8(ptr, len));
+  if (!wuffs_base__status__is_ok(&status)) {
+    return status.repr;
+  }
+  wuffs_base__slice_u8 workbuf = make_workbuf(
+      wuffs_base__image_decoder__workbuf_len(dec).max_incl);
+
+  while (true) {
+    wuffs_base__frame_config fc = {0};
+    do {
+      status = wuffs_base__image_decoder__decode_frame_config(dec, &fc, &g_src);
+    } while (step("decode_frame_config", status));
+    if (!wuffs_base__status__is_ok(&status)) {
+      return NULL;
+    }
+    do {
+      status = wuffs_base__image_decoder__decode_frame(
+          dec, &pb, &g_src, WUFFS_BASE__PIXEL_BLEND__SRC, workbuf, NULL);
+      if (wuffs_base__status__is_ok(&status)) {
+        g_dst_pos++;
+      }
+    } while (step("decode_frame", status));
+    if (!wuffs_base__status__is_ok(&status)) {
+      return NULL;
+    }
+  }
+}
+
+static const char*  //
+run_io_transformer(wuffs_base__io_transformer* dec) {
+  uint8_t* ptr = malloc(g_dst_chunk);
+  if (!ptr) {
+    return "could not allocate the destination buffer";
+  }
+  wuffs_base__io_buffer dst =
+      wuffs_base__ptr_u8__writer(ptr, g_dst_chunk);
+  wuffs_base__slice_u8 workbuf = make_workbuf(
+      wuffs_base__io_transformer__workbuf_len(dec).max_incl);
+  while (true) {
+    wuffs_base__status status =
+        wuffs_base__io_transformer__transform_io(dec, &dst, &g_src, workbuf);
+    g_dst_pos += dst.meta.wi;
+    dst.meta.wi = 0;
+    if (!step("transform_io", status) &&
+        (status.repr != wuffs_base__suspension__short_write)) {
+      return NULL;
+    }
+  }
+}
+
+static const char*  //
+run_token_decoder(wuffs_base__token_decoder* dec) {
+  wuffs_base__token* ptr = malloc(g_dst_chunk * sizeof(wuffs_base__token));
+  if (!ptr) {
+    return "could not allocate the destination buffer";
+  }
+  wuffs_base__token_buffer dst = {0};
+  dst.data = wuffs_base__make_slice_token(ptr, g_dst_chunk);
+  wuffs_base__slice_u8 workbuf = make_workbuf(
+      wuffs_base__token_decoder__workbuf_len(dec).max_incl);
+  while (true) {
+    wuffs_base__status status =
+        wuffs_base__token_decoder__decode_tokens(dec, &dst, &g_src, workbuf);
+    g_dst_pos += dst.meta.wi;
+    dst.meta.wi = 0;
+    dst.meta.ri = 0;
+    if (!step("decode_tokens", status) &&
+        (status.repr != wuffs_base__suspension__short_write)) {
+      return NULL;
+    }
+  }
+}
+
+static const char*  //
+run(int argc, char** argv) {
+  if (argc < 4) {
+    return "bad arguments";
+  }
+  FILE* f = fopen(argv[1], "rb");
+  if (!f) {
+    return "could not open the input file";
+  }
+  size_t cap = 4096;
+  size_t len = 0;
+  uint8_t* ptr = malloc(cap);
+  while (ptr) {
+    len += fread(ptr + len, 1, cap - len, f);
+    if (len < cap) {
+      break;
+    }
+    cap *= 2;
+    ptr = realloc(ptr, cap);
+  }
+  fclose(f);
+  if (!ptr) {
+    return "could not read the input file";
+  }
+  g_src = wuffs_base__ptr_u8__reader(ptr, len, false);
+  g_src.meta.wi = 0;
+  g_chunk = (size_t)(strtoul(argv[2], NULL, 10));
+  g_dst_chunk = (size_t)(strtoul(argv[3], NULL, 10));
+  feed();
+
+  wuffs_base__DEBUG_INTERFACE* b =
+      wuffs_DEBUG_PACKAGE__decoder__alloc_as__wuffs_base__DEBUG_INTERFACE();
+  if (!b) {
+    return "could not allocate the decoder";
+  }
+  int i;
+  for (i = 4; i < argc; i++) {
+    wuffs_base__DEBUG_INTERFACE__set_quirk_enabled(
+        b, (uint32_t)(strtoul(argv[i], NULL, 16)), true);
+  }
+  return run_DEBUG_INTERFACE(b);
+}
+
+int  //
+main(int argc, char** argv) {
+  const char* msg = run(argc, argv);
+  if (msg) {
+    printf("X\t%s\n", msg);
+  }
+  return 0;
+}
+`
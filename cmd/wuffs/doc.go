@@ -0,0 +1,445 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// doc.go implements "wuffs doc", which generates API reference documentation
+// (in Markdown or HTML) for Wuffs packages: their public status codes,
+// constants, structs, interfaces and functions, with their doc comments.
+//
+// The packages are parsed but not type-checked, so that types (including any
+// refinements) are rendered as they are written in the source code.
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/wuffs/lang/parse"
+
+	cf "github.com/google/wuffs/cmd/commonflags"
+	a "github.com/google/wuffs/lang/ast"
+	t "github.com/google/wuffs/lang/token"
+)
+
+const (
+	formatDefault = "md"
+	formatUsage   = `output format: "md" (Markdown) or "html"`
+
+	outdirDefault = ""
+	outdirUsage   = `if non-empty, the directory to write one file per package to, instead of writing to stdout`
+)
+
+func doDoc(wuffsRoot string, args []string) error {
+	flags := flag.NewFlagSet("doc", flag.ExitOnError)
+	formatFlag := flags.String("format", formatDefault, formatUsage)
+	outdirFlag := flags.String("outdir", outdirDefault, outdirUsage)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if (*formatFlag != "md") && (*formatFlag != "html") {
+		return fmt.Errorf("bad -format flag value %q", *formatFlag)
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		args = []string{"std/..."}
+	}
+
+	h := docHelper{
+		wuffsRoot: wuffsRoot,
+		format:    *formatFlag,
+		outdir:    *outdirFlag,
+	}
+	for _, arg := range args {
+		recursive := strings.HasSuffix(arg, "/...")
+		if recursive {
+			arg = arg[:len(arg)-4]
+		}
+		if arg == "" {
+			continue
+		}
+		if err := h.doc(arg, recursive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type docHelper struct {
+	wuffsRoot string
+	format    string
+	outdir    string
+}
+
+func (h *docHelper) doc(dirname string, recursive bool) error {
+	for len(dirname) > 0 && dirname[len(dirname)-1] == '/' {
+		dirname = dirname[:len(dirname)-1]
+	}
+	if !cf.IsValidUsePath(dirname) {
+		return fmt.Errorf("invalid package path %q", dirname)
+	}
+
+	qualFilenames, dirnames, err := listDir(
+		filepath.Join(h.wuffsRoot, filepath.FromSlash(dirname)), ".wuffs", recursive)
+	if err != nil {
+		return err
+	}
+	if len(qualFilenames) > 0 {
+		if err := h.docDir(dirname, qualFilenames); err != nil {
+			return err
+		}
+	}
+	for _, d := range dirnames {
+		if err := h.doc(dirname+"/"+d, recursive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *docHelper) docDir(dirname string, qualFilenames []string) error {
+	pkg, err := loadDocPackage(dirname, qualFilenames)
+	if err != nil {
+		return err
+	}
+
+	w := docWriter(&markdownDocWriter{})
+	if h.format == "html" {
+		w = &htmlDocWriter{}
+	}
+	pkg.write(w)
+	out := w.finish(dirname)
+
+	if h.outdir == "" {
+		_, err := os.Stdout.Write(out)
+		return err
+	}
+	if err := os.MkdirAll(h.outdir, 0755); err != nil {
+		return err
+	}
+	filename := filepath.Join(h.outdir, strings.Replace(dirname, "/", "-", -1)+"."+h.format)
+	return ioutil.WriteFile(filename, out, 0644)
+}
+
+// docItem is a documented, top-level declaration.
+type docItem struct {
+	name string
+	decl string
+	doc  []string
+}
+
+// docStruct is a documented struct and its methods.
+type docStruct struct {
+	docItem
+	methods []docItem
+}
+
+type docPackage struct {
+	statuses   []docItem
+	consts     []docItem
+	interfaces []docStruct
+	structs    []docStruct
+	funcs      []docItem
+}
+
+func loadDocPackage(dirname string, qualFilenames []string) (*docPackage, error) {
+	tm := &t.Map{}
+	pkg := &docPackage{}
+	methods := map[t.ID][]docItem{}
+
+	for _, qualFilename := range qualFilenames {
+		src, err := ioutil.ReadFile(qualFilename)
+		if err != nil {
+			return nil, err
+		}
+		filename := path.Join(dirname, filepath.Base(qualFilename))
+		tokens, comments, err := t.Tokenize(tm, filename, src)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parse.Parse(tm, filename, tokens, &parse.Options{
+			AllowDoubleUnderscoreNames: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		hasTokens := map[uint32]bool{}
+		for _, tok := range tokens {
+			hasTokens[tok.Line] = true
+		}
+		docFor := func(line uint32) []string {
+			return docComment(comments, hasTokens, line)
+		}
+
+		for _, n := range file.TopLevelDecls() {
+			switch n.Kind() {
+			case a.KStatus:
+				if s := n.AsStatus(); s.Public() {
+					msg := s.QID()[1].Str(tm)
+					pkg.statuses = append(pkg.statuses, docItem{
+						name: msg,
+						decl: "pub status " + msg,
+						doc:  docFor(s.Line()),
+					})
+				}
+
+			case a.KConst:
+				if c := n.AsConst(); c.Public() {
+					decl := fmt.Sprintf("pub const %s : %s", c.QID()[1].Str(tm), c.XType().Str(tm))
+					if c.XType().Decorator() != t.IDArray {
+						decl += " = " + c.Value().Str(tm)
+					}
+					pkg.consts = append(pkg.consts, docItem{
+						name: c.QID()[1].Str(tm),
+						decl: decl,
+						doc:  docFor(c.Line()),
+					})
+				}
+
+			case a.KInterface:
+				if i := n.AsInterface(); i.Public() {
+					d := docStruct{docItem: docItem{
+						name: i.QID()[1].Str(tm),
+						decl: "pub interface " + i.QID()[1].Str(tm),
+						doc:  docFor(i.Line()),
+					}}
+					for _, m := range i.Methods() {
+						f := m.AsFunc()
+						d.methods = append(d.methods, docItem{
+							name: f.FuncName().Str(tm),
+							decl: funcDecl(tm, f),
+							doc:  docFor(f.Line()),
+						})
+					}
+					pkg.interfaces = append(pkg.interfaces, d)
+				}
+
+			case a.KStruct:
+				if s := n.AsStruct(); s.Public() {
+					name := s.QID()[1].Str(tm)
+					if s.Classy() {
+						name += "?"
+					}
+					decl := "pub struct " + name
+					if impls := s.Implements(); len(impls) > 0 {
+						strs := make([]string, len(impls))
+						for i, impl := range impls {
+							strs[i] = impl.AsTypeExpr().Str(tm)
+						}
+						decl += " implements " + strings.Join(strs, ", ")
+					}
+					pkg.structs = append(pkg.structs, docStruct{docItem: docItem{
+						name: s.QID()[1].Str(tm),
+						decl: decl,
+						doc:  docFor(s.Line()),
+					}})
+				}
+
+			case a.KFunc:
+				if f := n.AsFunc(); f.Public() {
+					item := docItem{
+						name: f.FuncName().Str(tm),
+						decl: funcDecl(tm, f),
+						doc:  docFor(f.Line()),
+					}
+					if r := f.Receiver(); r[1] != 0 {
+						methods[r[1]] = append(methods[r[1]], item)
+					} else {
+						pkg.funcs = append(pkg.funcs, item)
+					}
+				}
+			}
+		}
+	}
+
+	for i := range pkg.structs {
+		s := &pkg.structs[i]
+		s.methods = methods[tm.ByName(s.name)]
+	}
+	return pkg, nil
+}
+
+// funcDecl returns f's signature, such as "pub func decoder.decode_tokens?(dst:
+// base.token_writer, src: base.io_reader, workbuf: slice base.u8)".
+func funcDecl(tm *t.Map, f *a.Func) string {
+	buf := []byte("pub func ")
+	if r := f.Receiver(); r[1] != 0 {
+		buf = append(buf, r[1].Str(tm)...)
+		buf = append(buf, '.')
+	}
+	buf = append(buf, f.FuncName().Str(tm)...)
+	buf = append(buf, f.Effect().String()...)
+	buf = append(buf, '(')
+	for i, o := range f.In().Fields() {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		field := o.AsField()
+		buf = append(buf, field.Name().Str(tm)...)
+		buf = append(buf, ": "...)
+		buf = append(buf, field.XType().Str(tm)...)
+	}
+	buf = append(buf, ')')
+	if out := f.Out(); out != nil {
+		buf = append(buf, ' ')
+		buf = append(buf, out.Str(tm)...)
+	}
+	return string(buf)
+}
+
+// docComment returns the comment lines immediately above line (those that
+// are not separated from it by a blank line or a line of code), with their
+// leading "//" and one space stripped.
+func docComment(comments []string, hasTokens map[uint32]bool, line uint32) []string {
+	first := line
+	for (first > 1) && !hasTokens[first-1] &&
+		(uint(first-1) < uint(len(comments))) && (comments[first-1] != "") {
+		first--
+	}
+	ret := []string(nil)
+	for i := first; i < line; i++ {
+		c := strings.TrimPrefix(comments[i], "//")
+		c = strings.TrimPrefix(c, " ")
+		ret = append(ret, strings.TrimRight(c, " "))
+	}
+	return ret
+}
+
+func (p *docPackage) write(w docWriter) {
+	if len(p.statuses) > 0 {
+		w.heading(2, "Status Codes")
+		for _, s := range p.statuses {
+			w.item(3, s, s.name)
+		}
+	}
+	if len(p.consts) > 0 {
+		w.heading(2, "Constants")
+		for _, c := range p.consts {
+			w.item(3, c, c.name)
+		}
+	}
+	if len(p.interfaces) > 0 {
+		w.heading(2, "Interfaces")
+		for _, i := range p.interfaces {
+			w.item(3, i.docItem, i.name)
+			for _, m := range i.methods {
+				w.item(4, m, i.name+"."+m.name)
+			}
+		}
+	}
+	if len(p.structs) > 0 {
+		w.heading(2, "Structs")
+		for _, s := range p.structs {
+			w.item(3, s.docItem, s.name)
+			for _, m := range s.methods {
+				w.item(4, m, s.name+"."+m.name)
+			}
+		}
+	}
+	if len(p.funcs) > 0 {
+		w.heading(2, "Functions")
+		for _, f := range p.funcs {
+			w.item(3, f, f.name)
+		}
+	}
+}
+
+type docWriter interface {
+	heading(level int, text string)
+	item(level int, d docItem, title string)
+	finish(title string) []byte
+}
+
+type markdownDocWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *markdownDocWriter) heading(level int, text string) {
+	fmt.Fprintf(&w.buf, "\n%s %s\n", strings.Repeat("#", level), text)
+}
+
+func (w *markdownDocWriter) item(level int, d docItem, title string) {
+	fmt.Fprintf(&w.buf, "\n%s `%s`\n\n```\n%s\n```\n", strings.Repeat("#", level), title, d.decl)
+	if len(d.doc) > 0 {
+		w.buf.WriteByte('\n')
+		for _, line := range d.doc {
+			w.buf.WriteString(line)
+			w.buf.WriteByte('\n')
+		}
+	}
+}
+
+func (w *markdownDocWriter) finish(title string) []byte {
+	return append([]byte("# "+title+"\n"), w.buf.Bytes()...)
+}
+
+type htmlDocWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *htmlDocWriter) heading(level int, text string) {
+	fmt.Fprintf(&w.buf, "<h%d>%s</h%d>\n", level, html.EscapeString(text), level)
+}
+
+func (w *htmlDocWriter) item(level int, d docItem, title string) {
+	fmt.Fprintf(&w.buf, "<h%d id=\"%s\"><code>%s</code></h%d>\n<pre>%s</pre>\n",
+		level, html.EscapeString(title), html.EscapeString(title), level, html.EscapeString(d.decl))
+
+	// Blank lines separate paragraphs. Indented lines (e.g. lists) are
+	// pre-formatted.
+	inPara, inPre := false, false
+	for _, line := range d.doc {
+		pre := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+		if (line == "") || (pre != inPre) {
+			if inPara {
+				w.buf.WriteString("</p>\n")
+				inPara = false
+			} else if inPre {
+				w.buf.WriteString("</pre>\n")
+				inPre = false
+			}
+		}
+		if line == "" {
+			continue
+		}
+		if pre && !inPre {
+			w.buf.WriteString("<pre>")
+			inPre = true
+		} else if !pre && !inPara {
+			w.buf.WriteString("<p>")
+			inPara = true
+		}
+		w.buf.WriteString(html.EscapeString(line))
+		w.buf.WriteByte('\n')
+	}
+	if inPara {
+		w.buf.WriteString("</p>\n")
+	} else if inPre {
+		w.buf.WriteString("</pre>\n")
+	}
+}
+
+func (w *htmlDocWriter) finish(title string) []byte {
+	t := html.EscapeString(title)
+	buf := []byte("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>" + t +
+		"</title>\n</head>\n<body>\n<h1>" + t + "</h1>\n")
+	buf = append(buf, w.buf.Bytes()...)
+	return append(buf, "</body>\n</html>\n"...)
+}
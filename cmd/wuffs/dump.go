@@ -0,0 +1,217 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// dump.go implements "wuffs dump", which prints a .wuffs file's token stream,
+// its parsed AST or the facts that the checker derives before each statement.
+// It is intended for people working on the compiler itself, or diagnosing
+// why a proof fails.
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/wuffs/lang/check"
+	"github.com/google/wuffs/lang/parse"
+
+	a "github.com/google/wuffs/lang/ast"
+	t "github.com/google/wuffs/lang/token"
+)
+
+const (
+	astDefault = false
+	astUsage   = `whether to print the parsed AST (the default if no other flag is given)`
+
+	factsDefault = false
+	factsUsage   = `whether to type- and bounds-check the file's package and print the facts known before each statement`
+
+	tokensDefault = false
+	tokensUsage   = `whether to print the token stream`
+)
+
+func doDump(wuffsRoot string, args []string) error {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+	astFlag := flags.Bool("ast", astDefault, astUsage)
+	factsFlag := flags.Bool("facts", factsDefault, factsUsage)
+	tokensFlag := flags.Bool("tokens", tokensDefault, tokensUsage)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("no .wuffs files given")
+	}
+	if !*astFlag && !*factsFlag && !*tokensFlag {
+		*astFlag = true
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	for _, filename := range args {
+		if len(args) > 1 {
+			fmt.Fprintf(w, "==> %s <==\n", filename)
+		}
+		if err := dump(w, wuffsRoot, filepath.Clean(filename), *astFlag, *factsFlag, *tokensFlag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dump(w *bufio.Writer, wuffsRoot string, filename string, astFlag bool, factsFlag bool, tokensFlag bool) error {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	tm := &t.Map{}
+	tokens, _, err := t.Tokenize(tm, filename, src)
+	if err != nil {
+		return err
+	}
+
+	if tokensFlag {
+		for _, tok := range tokens {
+			fmt.Fprintf(w, "%d\t%d\t%s\n", tok.Line, tok.ID, tok.ID.Str(tm))
+		}
+	}
+
+	if astFlag {
+		file, err := parse.Parse(tm, filename, tokens, nil)
+		if err != nil {
+			return err
+		}
+		dumpNode(w, tm, file.AsNode(), "", 0)
+	}
+
+	if factsFlag {
+		if err := dumpFacts(w, wuffsRoot, tm, filename, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var dumpSlotNames = [...]string{"lhs", "mhs", "rhs", "list0", "list1", "list2"}
+
+// dumpNode prints n and its sub-nodes, one per line, indented by depth.
+func dumpNode(w *bufio.Writer, tm *t.Map, n *a.Node, slot string, depth int) {
+	for i := 0; i < depth; i++ {
+		w.WriteString("  ")
+	}
+	if slot != "" {
+		w.WriteString(slot)
+		w.WriteString(": ")
+	}
+	r := n.AsRaw()
+	w.WriteString(n.Kind().String())
+	if _, line := r.FilenameLine(); line != 0 {
+		fmt.Fprintf(w, " line=%d", line)
+	}
+	if f := r.Flags(); f != 0 {
+		fmt.Fprintf(w, " flags=0x%X", uint32(f))
+	}
+	for i, id := range r.IDs() {
+		if s := id.Str(tm); s != "" {
+			fmt.Fprintf(w, " id%d=%s", i, s)
+		} else if id != 0 {
+			// Some built-in IDs, such as IDXUnaryNot, have no string form.
+			fmt.Fprintf(w, " id%d=#%d", i, id)
+		}
+	}
+	switch n.Kind() {
+	case a.KExpr:
+		fmt.Fprintf(w, "  // %s", n.AsExpr().Str(tm))
+	case a.KTypeExpr:
+		fmt.Fprintf(w, "  // %s", n.AsTypeExpr().Str(tm))
+	}
+	w.WriteByte('\n')
+
+	for i, o := range r.SubNodes() {
+		if o != nil {
+			dumpNode(w, tm, o, dumpSlotNames[i], depth+1)
+		}
+	}
+	for i, l := range r.SubLists() {
+		for j, o := range l {
+			dumpNode(w, tm, o, fmt.Sprintf("%s[%d]", dumpSlotNames[3+i], j), depth+1)
+		}
+	}
+}
+
+// dumpFacts checks filename's package (every .wuffs file in the same
+// directory) and prints, for each statement in filename, the facts that the
+// bounds checker knows before that statement.
+//
+// Any "use"d packages are resolved from the "gen/wuffs" directory, so "wuffs
+// gen" needs to have been run for those packages.
+func dumpFacts(w *bufio.Writer, wuffsRoot string, tm *t.Map, filename string, src []byte) error {
+	dirname := filepath.Dir(filename)
+	infos, err := ioutil.ReadDir(dirname)
+	if err != nil {
+		return err
+	}
+	files := []*a.File(nil)
+	for _, o := range infos {
+		if o.IsDir() || !strings.HasSuffix(o.Name(), ".wuffs") {
+			continue
+		}
+		qualFilename := filepath.Join(dirname, o.Name())
+		s, err := ioutil.ReadFile(qualFilename)
+		if err != nil {
+			return err
+		}
+		tokens, _, err := t.Tokenize(tm, qualFilename, s)
+		if err != nil {
+			return err
+		}
+		f, err := parse.Parse(tm, qualFilename, tokens, nil)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+
+	srcLines := bytes.Split(src, []byte("\n"))
+	opts := &check.Options{
+		FactsHook: func(n *a.Node, facts []*a.Expr) {
+			f, line := n.AsRaw().FilenameLine()
+			if f != filename {
+				return
+			}
+			text := []byte(nil)
+			if (line > 0) && (int(line) <= len(srcLines)) {
+				text = bytes.TrimSpace(srcLines[line-1])
+			}
+			fmt.Fprintf(w, "%s:%d: %s\n", filename, line, text)
+			for _, x := range facts {
+				fmt.Fprintf(w, "\t%s\n", x.Str(tm))
+			}
+		},
+	}
+	resolveUse := func(usePath string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join(wuffsRoot, "gen", "wuffs", filepath.FromSlash(usePath)))
+	}
+	if _, err := check.Check(tm, files, resolveUse, opts); err != nil {
+		w.Flush()
+		return err
+	}
+	return nil
+}
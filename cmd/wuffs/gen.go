@@ -38,23 +38,38 @@ func doGenlib(wuffsRoot string, args []string) error { return doGenGenlib(wuffsR
 
 func doGenGenlib(wuffsRoot string, args []string, genlib bool) error {
 	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	annotationsFlag := flags.Bool("annotations", cf.AnnotationsDefault, cf.AnnotationsUsage)
+	cdialectFlag := flags.String("cdialect", cf.CdialectDefault, cf.CdialectUsage)
+	cppwrappersFlag := flags.Bool("cppwrappers", cf.CppwrappersDefault, cf.CppwrappersUsage)
 	genlinenumFlag := flags.Bool("genlinenum", cf.GenlinenumDefault, cf.GenlinenumUsage)
 	langsFlag := flags.String("langs", langsDefault, langsUsage)
+	runtimetablesFlag := flags.Bool("runtimetables", cf.RuntimetablesDefault, cf.RuntimetablesUsage)
 	skipgendepsFlag := flags.Bool("skipgendeps", skipgendepsDefault, skipgendepsUsage)
+	smtFlag := flags.String("smt", cf.SmtDefault, cf.SmtUsage)
+	smtcacheFlag := flags.String("smtcache", cf.SmtcacheDefault, cf.SmtcacheUsage)
+	terminationFlag := flags.Bool("termination", cf.TerminationDefault, cf.TerminationUsage)
 
 	ccompilersFlag := (*string)(nil)
+	onlyneededbaseFlag := (*bool)(nil)
 	skipgenFlag := (*bool)(nil)
 	versionFlag := (*string)(nil)
 	if genlib {
 		ccompilersFlag = flags.String("ccompilers", cf.CcompilersDefault, cf.CcompilersUsage)
 		skipgenFlag = flags.Bool("skipgen", skipgenDefault, skipgenUsage)
 	} else {
+		onlyneededbaseFlag = flags.Bool("only-needed-base", cf.OnlyneededbaseDefault, cf.OnlyneededbaseUsage)
 		versionFlag = flags.String("version", cf.VersionDefault, cf.VersionUsage)
 	}
 
 	if err := flags.Parse(args); err != nil {
 		return err
 	}
+	if !cf.IsValidCdialect(*cdialectFlag) {
+		return fmt.Errorf("bad -cdialect flag value %q", *cdialectFlag)
+	}
+	if !cf.IsAlphaNumericIsh(*smtFlag) {
+		return fmt.Errorf("bad -smt flag value %q", *smtFlag)
+	}
 	if genlib {
 		if !cf.IsAlphaNumericIsh(*ccompilersFlag) {
 			return fmt.Errorf("bad -ccompilers flag value %q", *ccompilersFlag)
@@ -78,11 +93,18 @@ func doGenGenlib(wuffsRoot string, args []string, genlib bool) error {
 	}
 
 	h := genHelper{
-		wuffsRoot:   wuffsRoot,
-		langs:       langs,
-		genlinenum:  *genlinenumFlag,
-		skipgen:     genlib && *skipgenFlag,
-		skipgendeps: *skipgendepsFlag,
+		wuffsRoot:     wuffsRoot,
+		langs:         langs,
+		annotations:   *annotationsFlag,
+		cdialect:      *cdialectFlag,
+		cppwrappers:   *cppwrappersFlag,
+		runtimetables: *runtimetablesFlag,
+		genlinenum:    *genlinenumFlag,
+		skipgen:       genlib && *skipgenFlag,
+		skipgendeps:   *skipgendepsFlag,
+		smt:           *smtFlag,
+		smtcache:      *smtcacheFlag,
+		termination:   *terminationFlag,
 	}
 	if genlib {
 		h.ccompilers = *ccompilersFlag
@@ -105,16 +127,23 @@ func doGenGenlib(wuffsRoot string, args []string, genlib bool) error {
 	if genlib {
 		return h.genlibAffected()
 	}
-	return genrelease(wuffsRoot, langs, v)
+	return genrelease(wuffsRoot, langs, v, *cdialectFlag, *onlyneededbaseFlag)
 }
 
 type genHelper struct {
-	wuffsRoot   string
-	langs       []string
-	ccompilers  string
-	genlinenum  bool
-	skipgen     bool
-	skipgendeps bool
+	wuffsRoot     string
+	langs         []string
+	ccompilers    string
+	annotations   bool
+	cdialect      string
+	cppwrappers   bool
+	runtimetables bool
+	genlinenum    bool
+	skipgen       bool
+	skipgendeps   bool
+	smt           string
+	smtcache      string
+	termination   bool
 
 	affected []string
 	seen     map[string]struct{}
@@ -187,9 +216,30 @@ func (h *genHelper) genDir(dirname string, qualFilenames []string) error {
 	for _, lang := range h.langs {
 		command := "wuffs-" + lang
 		cmdArgs := []string{"gen", "-package_name", packageName}
+		if h.annotations != cf.AnnotationsDefault {
+			cmdArgs = append(cmdArgs, fmt.Sprintf("-annotations=%t", h.annotations))
+		}
+		if h.cdialect != cf.CdialectDefault {
+			cmdArgs = append(cmdArgs, fmt.Sprintf("-cdialect=%s", h.cdialect))
+		}
+		if h.cppwrappers != cf.CppwrappersDefault {
+			cmdArgs = append(cmdArgs, fmt.Sprintf("-cppwrappers=%t", h.cppwrappers))
+		}
 		if h.genlinenum != cf.GenlinenumDefault {
 			cmdArgs = append(cmdArgs, fmt.Sprintf("-genlinenum=%t", h.genlinenum))
 		}
+		if h.runtimetables != cf.RuntimetablesDefault {
+			cmdArgs = append(cmdArgs, fmt.Sprintf("-runtimetables=%t", h.runtimetables))
+		}
+		if h.smt != cf.SmtDefault {
+			cmdArgs = append(cmdArgs, fmt.Sprintf("-smt=%s", h.smt))
+		}
+		if h.smtcache != cf.SmtcacheDefault {
+			cmdArgs = append(cmdArgs, fmt.Sprintf("-smtcache=%s", h.smtcache))
+		}
+		if h.termination != cf.TerminationDefault {
+			cmdArgs = append(cmdArgs, fmt.Sprintf("-termination=%t", h.termination))
+		}
 		cmdArgs = append(cmdArgs, qualFilenames...)
 		stdout := &bytes.Buffer{}
 
@@ -268,6 +318,20 @@ func (h *genHelper) genWuffs(dirname string, qualifiedFilenames []string) error
 				fmt.Fprintf(out, "pub const %s : %s = %v\n",
 					n.QID().Str(&h.tm), n.XType().Str(&h.tm), n.Value().Str(&h.tm))
 
+			case a.KEnum:
+				n := n.AsEnum()
+				if !n.Public() {
+					continue
+				}
+				fmt.Fprintf(out, "pub enum %s(", n.QID().Str(&h.tm))
+				for i, m := range n.Members() {
+					if i > 0 {
+						fmt.Fprintf(out, ", ")
+					}
+					fmt.Fprintf(out, "%s", m.AsExpr().Str(&h.tm))
+				}
+				fmt.Fprintf(out, ")\n")
+
 			case a.KFunc:
 				n := n.AsFunc()
 				if !n.Public() {
@@ -293,6 +357,27 @@ func (h *genHelper) genWuffs(dirname string, qualifiedFilenames []string) error
 				}
 				fmt.Fprintf(out, " { }\n")
 
+			case a.KInterface:
+				n := n.AsInterface()
+				fmt.Fprintf(out, "pub interface %s(\n", n.QID().Str(&h.tm))
+				for _, m := range n.Methods() {
+					m := m.AsFunc()
+					fmt.Fprintf(out, "\t%s%v(", m.FuncName().Str(&h.tm), m.Effect())
+					for i, field := range m.In().Fields() {
+						field := field.AsField()
+						if i > 0 {
+							fmt.Fprintf(out, ", ")
+						}
+						fmt.Fprintf(out, "%s: %s", field.Name().Str(&h.tm), field.XType().Str(&h.tm))
+					}
+					fmt.Fprintf(out, ")")
+					if o := m.Out(); o != nil {
+						fmt.Fprintf(out, " %s", o.Str(&h.tm))
+					}
+					fmt.Fprintf(out, ",\n")
+				}
+				fmt.Fprintf(out, ")\n")
+
 			case a.KStatus:
 				n := n.AsStatus()
 				if !n.Public() {
@@ -333,6 +418,9 @@ func (h *genHelper) genlibAffected() error {
 		args = append(args, "-srcdir", filepath.Join(h.wuffsRoot, "gen", lang))
 		if lang == "c" {
 			args = append(args, fmt.Sprintf("-ccompilers=%s", h.ccompilers))
+			if h.cdialect != cf.CdialectDefault {
+				args = append(args, fmt.Sprintf("-cdialect=%s", h.cdialect))
+			}
 		}
 		args = append(args, h.affected...)
 		cmd := exec.Command(command, args...)
@@ -0,0 +1,693 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// genfuzz.go generates fuzz/c/std/<package>_fuzzer.c, the fuzz programs for
+// every package that has a decoder. Each program calls the fuzz/c/fuzzlib
+// harness for its decoder's interface (image_decoder, io_transformer or
+// token_decoder), which exercises 1-byte-at-a-time input, tiny output buffers
+// and (for image decoders) restarting at a frame's io_position.
+//
+// A decoder that implements no base interface, such as std/tar's, instead has
+// a sequence of coroutine methods that the caller calls in order. Its program
+// calls the fuzzlib_call_sequence.c harness, passing those methods.
+//
+// A fuzzer that does not start with fuzzerGeneratedPrefix is hand-written,
+// typically because it checks format-specific properties, and genfuzz leaves
+// it alone.
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const fuzzerGeneratedPrefix = "// Code generated by running \"wuffs genfuzz\". DO NOT EDIT.\n"
+
+// fuzzerCopyrightRegexp matches an existing fuzzer's copyright line. Its year
+// is kept when regenerating that fuzzer. New fuzzers get the current year.
+var fuzzerCopyrightRegexp = regexp.MustCompile(`// Copyright ([0-9]{4}) The Wuffs Authors\.`)
+
+func doGenfuzz(wuffsRoot string, args []string) error {
+	flags := flag.NewFlagSet("genfuzz", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: wuffs genfuzz [packages]\n\n"+
+			"e.g.:  wuffs genfuzz std/gif std/zlib\n\n"+
+			"With no packages, it generates fuzzers for std/...\n")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		args = []string{"std/..."}
+	}
+
+	seeds, err := loadSeedCorpora(wuffsRoot)
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		arg = strings.TrimSuffix(filepath.ToSlash(arg), "/")
+		if !strings.HasSuffix(arg, "/...") {
+			if err := genFuzzer(wuffsRoot, arg, seeds); err != nil {
+				return err
+			}
+			continue
+		}
+		arg = arg[:len(arg)-4]
+		_, relDirnames, err := listDir(
+			filepath.Join(wuffsRoot, filepath.FromSlash(arg)), "", true)
+		if err != nil {
+			return err
+		}
+		for _, d := range relDirnames {
+			if err := genFuzzer(wuffsRoot, arg+"/"+d, seeds); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadSeedCorpora parses fuzz/c/std/seed_corpora.txt, returning a map from
+// package names like "gif" to their first in-repository seed glob, like
+// "test/data/*.gif".
+func loadSeedCorpora(wuffsRoot string) (map[string]string, error) {
+	filename := filepath.Join(wuffsRoot, "fuzz", "c", "std", "seed_corpora.txt")
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]string{}
+	s := bufio.NewScanner(bytes.NewReader(src))
+	for s.Scan() {
+		line := s.Text()
+		if (line == "") || (line[0] == '#') {
+			continue
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("%s: missing ':' in %q", filename, line)
+		}
+		for _, glob := range strings.Fields(line[i+1:]) {
+			if strings.HasPrefix(glob, "test/") {
+				ret[line[:i]] = glob
+				break
+			}
+		}
+	}
+	return ret, s.Err()
+}
+
+// genFuzzer writes the named package's fuzzer, if it has a decoder and no
+// hand-written fuzzer.
+func genFuzzer(wuffsRoot string, dirname string, seeds map[string]string) error {
+	if !strings.HasPrefix(dirname, "std/") {
+		return fmt.Errorf("genfuzz: %q is not a std package", dirname)
+	}
+	packageName := dirname[4:]
+	target, err := loadFuzzTarget(wuffsRoot, dirname)
+	if err != nil {
+		return err
+	} else if target == nil {
+		return nil
+	}
+
+	filename := filepath.Join(wuffsRoot, "fuzz", "c", "std", packageName+"_fuzzer.c")
+	year := strconv.Itoa(time.Now().Year())
+	if existing, err := ioutil.ReadFile(filename); err == nil {
+		if !bytes.HasPrefix(existing, []byte(fuzzerGeneratedPrefix)) {
+			fmt.Println("gen skipped:   ", filename, "(hand-written)")
+			return nil
+		}
+		if m := fuzzerCopyrightRegexp.FindSubmatch(existing); m != nil {
+			year = string(m[1])
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	seed := seeds[packageName]
+	if seed == "" {
+		return fmt.Errorf("genfuzz: no test/... seed corpus for %q in fuzz/c/std/seed_corpora.txt", packageName)
+	}
+	deps, err := packageDependencies(wuffsRoot, dirname)
+	if err != nil {
+		return err
+	}
+	modules := []string{"BASE"}
+	for _, d := range deps {
+		modules = append(modules, strings.ToUpper(filepath.Base(d)))
+	}
+	sort.Strings(modules[1:])
+
+	upper := strings.ToUpper(packageName)
+	srcLenMin, dstLenMin := "1", "1"
+	if target.iface == "token_decoder" {
+		consts, err := packageConsts(wuffsRoot, dirname)
+		if err != nil {
+			return err
+		}
+		if consts["DECODER_SRC_IO_BUFFER_LENGTH_MIN_INCL"] {
+			srcLenMin = "WUFFS_" + upper + "__DECODER_SRC_IO_BUFFER_LENGTH_MIN_INCL"
+		}
+		if consts["DECODER_DST_TOKEN_BUFFER_LENGTH_MIN_INCL"] {
+			dstLenMin = "WUFFS_" + upper + "__DECODER_DST_TOKEN_BUFFER_LENGTH_MIN_INCL"
+		}
+	}
+
+	// Match clang-format, which puts the two arguments on one line if they fit.
+	lenMins := srcLenMin + ", " + dstLenMin
+	if len("      "+lenMins+");") > 80 {
+		lenMins = srcLenMin + ",\n      " + dstLenMin
+	}
+
+	defines := &bytes.Buffer{}
+	for _, m := range modules {
+		fmt.Fprintf(defines, "#define WUFFS_CONFIG__MODULE__%s\n", m)
+	}
+
+	body := ""
+	switch {
+	case target.iface == "call_sequence":
+		body = genFuzzerCallSequenceC(packageName, target)
+	case len(target.structs) > 1:
+		body = genFuzzerStructChoiceC(packageName, target)
+	case target.iface == "io_transformer":
+		body = fuzzerIOTransformerC
+	case target.iface == "token_decoder":
+		body = fuzzerTokenDecoderC
+	default:
+		body = fuzzerImageDecoderC
+	}
+
+	// Match clang-format, which wraps the upcast call if it does not fit.
+	upcast := "wuffs_" + packageName + "__" + target.structs[0] +
+		"__upcast_as__wuffs_base__" + target.iface
+	upcastSuffix := ","
+	if target.iface == "image_decoder" {
+		upcastSuffix = ");"
+	}
+	if len("      "+upcast+"(&dec)"+upcastSuffix) <= 80 {
+		upcast += "(&dec)"
+	} else {
+		upcast += "(\n          &dec)"
+	}
+
+	contents := strings.NewReplacer(
+		"FUZZ_DEFINES\n", defines.String(),
+		"FUZZ_UPCAST_DEC", upcast,
+		"FUZZ_INTERFACE", target.iface,
+		"FUZZ_LEN_MINS", lenMins,
+		"FUZZ_STRUCT_UPPER", strings.ToUpper(target.structs[0]),
+		"FUZZ_STRUCT", target.structs[0],
+		"FUZZ_PACKAGE_UPPER", upper,
+		"FUZZ_PACKAGE", packageName,
+		"FUZZ_SEED", seed,
+		"FUZZ_YEAR", year,
+	).Replace(fuzzerGeneratedPrefix + fuzzerPreambleC + body)
+	return writeFile(filename, []byte(contents))
+}
+
+// fuzzTarget is what a package's generated fuzzer exercises.
+type fuzzTarget struct {
+	// iface is the base interface that the decoder structs implement, or
+	// "call_sequence" if the decoder implements no base interface.
+	iface string
+
+	// structs are the decoder structs' names, such as "decoder" or
+	// "hex_decoder". A fuzzer with more than one chooses one based on a hash
+	// of the input.
+	structs []string
+
+	// methods are the call_sequence decoder's public coroutine methods, in
+	// source order. After calling the last one, the sequence continues from
+	// methods[loopStart], the first method that can return "@end of data".
+	methods   []fuzzMethod
+	loopStart int
+
+	// seeks is whether the call_sequence decoder can return "$mispositioned
+	// read", in which case its fuzzer seeks to its seek_io_position.
+	seeks bool
+}
+
+// fuzzMethod is a call_sequence decoder's coroutine method.
+type fuzzMethod struct {
+	name string
+	// args holds "dst", "src" or "workbuf" per argument, in order.
+	args []string
+}
+
+var (
+	fuzzDecoderStructRegexp = regexp.MustCompile(
+		`(?m)^pub struct ([0-9a-z_]*decoder)\? implements base\.(image_decoder|io_transformer|token_decoder)\(`)
+	fuzzPlainDecoderStructRegexp = regexp.MustCompile(`(?m)^pub struct decoder\?\(`)
+	fuzzCoroutineRegexp          = regexp.MustCompile(`(?m)^pub func decoder\.([0-9a-z_]+)\?\((.*)\) \{$`)
+)
+
+// fuzzArgTypes maps a call_sequence method's argument types to the
+// fuzzlib_call_sequence_method arguments that they are passed.
+var fuzzArgTypes = map[string]string{
+	"base.io_writer": "dst",
+	"base.io_reader": "src",
+	"slice base.u8":  "workbuf",
+}
+
+// loadFuzzTarget returns what the named package's fuzzer exercises, or nil if
+// it has no decoder (or has a decoder that genfuzz cannot call).
+//
+// A struct named "decoder" takes priority. Failing that, a package can have
+// one or more structs named like "hex_decoder" that implement the same base
+// interface.
+func loadFuzzTarget(wuffsRoot string, dirname string) (*fuzzTarget, error) {
+	if iface, err := decoderInterface(wuffsRoot, dirname); err != nil {
+		return nil, err
+	} else if iface != "" {
+		return &fuzzTarget{iface: iface, structs: []string{"decoder"}}, nil
+	}
+
+	qualFilenames, _, err := listDir(
+		filepath.Join(wuffsRoot, filepath.FromSlash(dirname)), ".wuffs", false)
+	if err != nil {
+		return nil, err
+	}
+	src := []byte(nil)
+	for _, qf := range qualFilenames {
+		s, err := ioutil.ReadFile(qf)
+		if err != nil {
+			return nil, err
+		}
+		src = append(src, s...)
+	}
+
+	if matches := fuzzDecoderStructRegexp.FindAllSubmatch(src, -1); len(matches) > 0 {
+		ret := &fuzzTarget{iface: string(matches[0][2])}
+		for _, m := range matches {
+			if string(m[2]) == ret.iface {
+				ret.structs = append(ret.structs, string(m[1]))
+			}
+		}
+		return ret, nil
+	}
+
+	if !fuzzPlainDecoderStructRegexp.Match(src) ||
+		!bytes.Contains(src, []byte("\npub func decoder.workbuf_len() base.range_ii_u64 {")) ||
+		!bytes.Contains(src, []byte("\npub func decoder.set_quirk_enabled!(")) {
+		return nil, nil
+	}
+	ret := &fuzzTarget{iface: "call_sequence", structs: []string{"decoder"}, loopStart: -1}
+	for _, m := range fuzzCoroutineRegexp.FindAllSubmatchIndex(src, -1) {
+		method := fuzzMethod{name: string(src[m[2]:m[3]])}
+		for _, arg := range strings.Split(string(src[m[4]:m[5]]), ", ") {
+			i := strings.Index(arg, ": ")
+			if i < 0 {
+				return nil, nil
+			}
+			a := fuzzArgTypes[arg[i+2:]]
+			if a == "" {
+				return nil, nil
+			}
+			method.args = append(method.args, a)
+		}
+
+		body := src[m[1]:]
+		if i := bytes.Index(body, []byte("\n}\n")); i >= 0 {
+			body = body[:i]
+		}
+		if (ret.loopStart < 0) && bytes.Contains(body, []byte(`base."@end of data"`)) {
+			ret.loopStart = len(ret.methods)
+		}
+		ret.methods = append(ret.methods, method)
+	}
+	if len(ret.methods) == 0 {
+		return nil, nil
+	} else if ret.loopStart < 0 {
+		ret.loopStart = len(ret.methods)
+	}
+	ret.seeks = bytes.Contains(src, []byte(".seek?("))
+	return ret, nil
+}
+
+// genFuzzerCallSequenceC returns the C code for a call_sequence fuzzer: an
+// adapter function per method and a fuzz function that passes them to the
+// fuzzlib_call_sequence.c harness.
+func genFuzzerCallSequenceC(packageName string, target *fuzzTarget) string {
+	typ := "wuffs_" + packageName + "__decoder"
+	b := &bytes.Buffer{}
+	for _, method := range target.methods {
+		fmt.Fprintf(b, "\nstatic wuffs_base__status  //\n")
+		fn := "fuzz_" + method.name + "("
+		indent := strings.Repeat(" ", len(fn))
+		fmt.Fprintf(b, "%svoid* dec,\n", fn)
+		fmt.Fprintf(b, "%swuffs_base__io_buffer* dst,\n", indent)
+		fmt.Fprintf(b, "%swuffs_base__io_buffer* src,\n", indent)
+		fmt.Fprintf(b, "%swuffs_base__slice_u8 workbuf) {\n", indent)
+		args := "(" + typ + "*)(dec)"
+		for _, a := range method.args {
+			args += ", " + a
+		}
+		writeFuzzerReturnCall(b, typ+"__"+method.name, args)
+		fmt.Fprintf(b, "}\n")
+	}
+
+	fmt.Fprintf(b, "\nstatic wuffs_base__range_ii_u64  //\nfuzz_workbuf_len(void* dec) {\n")
+	writeFuzzerReturnCall(b, typ+"__workbuf_len", "("+typ+"*)(dec)")
+	fmt.Fprintf(b, "}\n")
+	seek := "NULL"
+	if target.seeks {
+		seek = "fuzz_seek_io_position"
+		fmt.Fprintf(b, "\nstatic uint64_t  //\nfuzz_seek_io_position(void* dec) {\n")
+		writeFuzzerReturnCall(b, typ+"__seek_io_position", "("+typ+"*)(dec)")
+		fmt.Fprintf(b, "}\n")
+	}
+
+	methods := &bytes.Buffer{}
+	for _, method := range target.methods {
+		fmt.Fprintf(methods, "      fuzz_%s,\n", method.name)
+	}
+	return b.String() + strings.NewReplacer(
+		"FUZZ_METHODS\n", methods.String(),
+		"FUZZ_LOOP_START", strconv.Itoa(target.loopStart),
+		"FUZZ_SEEK", seek,
+	).Replace(fuzzerCallSequenceC)
+}
+
+// writeFuzzerReturnCall writes a "return fn(args);" statement, wrapping it
+// like clang-format would if it does not fit in 80 columns.
+func writeFuzzerReturnCall(b *bytes.Buffer, fn string, args string) {
+	if line := "  return " + fn + "(" + args + ");"; len(line) <= 80 {
+		fmt.Fprintf(b, "%s\n", line)
+	} else {
+		fmt.Fprintf(b, "  return %s(\n      %s);\n", fn, args)
+	}
+}
+
+// genFuzzerStructChoiceC returns the C code for a fuzzer whose package has
+// more than one decoder struct, such as std/basenc's base32_decoder and
+// hex_decoder. It uses a hash of the input to choose one of them.
+func genFuzzerStructChoiceC(packageName string, target *fuzzTarget) string {
+	prefix := "wuffs_" + packageName + "__"
+	upperPrefix := "WUFFS_" + strings.ToUpper(packageName) + "__"
+
+	sizes := &bytes.Buffer{}
+	vars := &bytes.Buffer{}
+	cases := &bytes.Buffer{}
+	for i, st := range target.structs {
+		sep := " + \\\n"
+		if i == len(target.structs)-1 {
+			sep = ")\n"
+		}
+		open := " "
+		if i == 0 {
+			open = "("
+		}
+		fmt.Fprintf(sizes, "  %s%s%s_WORKBUF_LEN_MAX_INCL_WORST_CASE%s",
+			open, upperPrefix, strings.ToUpper(st), sep)
+		fmt.Fprintf(vars, "  %s%s %s;\n", prefix, st, st)
+
+		if i == len(target.structs)-1 {
+			fmt.Fprintf(cases, "    default:\n")
+		} else {
+			fmt.Fprintf(cases, "    case %d:\n", i)
+		}
+		fmt.Fprintf(cases, "      status = %s%s__initialize(\n", prefix, st)
+		fmt.Fprintf(cases, "          &%s, sizeof %s, WUFFS_VERSION, flags);\n", st, st)
+		upcast := prefix + st + "__upcast_as__wuffs_base__" + target.iface
+		if line := "      dec = " + upcast + "(&" + st + ");"; len(line) <= 80 {
+			fmt.Fprintf(cases, "%s\n", line)
+		} else if line := "      dec = " + upcast + "("; len(line) <= 80 {
+			fmt.Fprintf(cases, "%s\n          &%s);\n", line, st)
+		} else {
+			fmt.Fprintf(cases, "      dec =\n          %s(\n              &%s);\n", upcast, st)
+		}
+		fmt.Fprintf(cases, "      break;\n")
+	}
+
+	harness := "fuzz_io_transformer(\n" +
+		"      src, hash, dec,\n" +
+		"      wuffs_base__make_slice_u8(g_work_buffer_array, WORK_BUFFER_ARRAY_SIZE));\n"
+	if target.iface == "token_decoder" {
+		harness = "fuzz_token_decoder(\n" +
+			"      src, hash, dec,\n" +
+			"      wuffs_base__make_slice_u8(g_work_buffer_array, WORK_BUFFER_ARRAY_SIZE),\n" +
+			"      1, 1);\n"
+	} else if target.iface == "image_decoder" {
+		harness = "fuzz_image_decoder(src, hash, dec);\n"
+	}
+
+	return strings.NewReplacer(
+		"FUZZ_WORKBUF_SIZES\n", sizes.String(),
+		"FUZZ_STRUCT_VARS\n", vars.String(),
+		"FUZZ_CASES\n", cases.String(),
+		"FUZZ_NUM_STRUCTS", strconv.Itoa(len(target.structs)),
+		"FUZZ_HARNESS\n", harness,
+	).Replace(fuzzerStructChoiceC)
+}
+
+// packageConsts returns the names of the package's public constants.
+func packageConsts(wuffsRoot string, dirname string) (map[string]bool, error) {
+	qualFilenames, _, err := listDir(
+		filepath.Join(wuffsRoot, filepath.FromSlash(dirname)), ".wuffs", false)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]bool{}
+	for _, qf := range qualFilenames {
+		src, err := ioutil.ReadFile(qf)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(src), "\n") {
+			const prefix = "pub const "
+			if !strings.HasPrefix(line, prefix) {
+				continue
+			}
+			if fields := strings.Fields(line[len(prefix):]); len(fields) > 0 {
+				ret[fields[0]] = true
+			}
+		}
+	}
+	return ret, nil
+}
+
+const fuzzerPreambleC = `
+// Copyright FUZZ_YEAR The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+// Silence the nested slash-star warning for the next comment's command line.
+#pragma clang diagnostic push
+#pragma clang diagnostic ignored "-Wcomment"
+
+/*
+This fuzzer (the fuzz function) is typically run indirectly, by a framework
+such as https://github.com/google/oss-fuzz calling LLVMFuzzerTestOneInput or
+by AFL++ (see WUFFS_CONFIG__FUZZLIB_AFL).
+
+When working on the fuzz implementation, or as a coherence check, defining
+WUFFS_CONFIG__FUZZLIB_MAIN will let you manually run fuzz over a set of files:
+
+gcc -DWUFFS_CONFIG__FUZZLIB_MAIN FUZZ_PACKAGE_fuzzer.c
+./a.out ../../../FUZZ_SEED
+rm -f ./a.out
+
+It should print "PASS", amongst other information, and exit(0).
+*/
+
+#pragma clang diagnostic pop
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+FUZZ_DEFINES
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../fuzzlib/fuzzlib.c"
+#include "../fuzzlib/fuzzlib_FUZZ_INTERFACE.c"
+`
+
+const fuzzerImageDecoderC = `
+const char*  //
+fuzz(wuffs_base__io_buffer* src, uint64_t hash) {
+  wuffs_FUZZ_PACKAGE__FUZZ_STRUCT dec;
+  wuffs_base__status status = wuffs_FUZZ_PACKAGE__FUZZ_STRUCT__initialize(
+      &dec, sizeof dec, WUFFS_VERSION,
+      (hash & 1) ? WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED : 0);
+  hash >>= 1;
+  if (!wuffs_base__status__is_ok(&status)) {
+    return wuffs_base__status__message(&status);
+  }
+  return fuzz_image_decoder(
+      src, hash,
+      FUZZ_UPCAST_DEC);
+}
+`
+
+const fuzzerWorkBufferC = `
+// Wuffs allows either statically or dynamically allocated work buffers. This
+// program exercises static allocation.
+#define WORK_BUFFER_ARRAY_SIZE \
+  WUFFS_FUZZ_PACKAGE_UPPER__FUZZ_STRUCT_UPPER_WORKBUF_LEN_MAX_INCL_WORST_CASE
+#if WORK_BUFFER_ARRAY_SIZE > 0
+uint8_t g_work_buffer_array[WORK_BUFFER_ARRAY_SIZE];
+#else
+// Not all C/C++ compilers support 0-length arrays.
+uint8_t g_work_buffer_array[1];
+#endif
+`
+
+const fuzzerIOTransformerC = fuzzerWorkBufferC + `
+const char*  //
+fuzz(wuffs_base__io_buffer* src, uint64_t hash) {
+  wuffs_FUZZ_PACKAGE__FUZZ_STRUCT dec;
+  wuffs_base__status status = wuffs_FUZZ_PACKAGE__FUZZ_STRUCT__initialize(
+      &dec, sizeof dec, WUFFS_VERSION,
+      (hash & 1) ? WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED : 0);
+  hash >>= 1;
+  if (!wuffs_base__status__is_ok(&status)) {
+    return wuffs_base__status__message(&status);
+  }
+  return fuzz_io_transformer(
+      src, hash,
+      FUZZ_UPCAST_DEC,
+      wuffs_base__make_slice_u8(g_work_buffer_array, WORK_BUFFER_ARRAY_SIZE));
+}
+`
+
+const fuzzerTokenDecoderC = fuzzerWorkBufferC + `
+const char*  //
+fuzz(wuffs_base__io_buffer* src, uint64_t hash) {
+  wuffs_FUZZ_PACKAGE__FUZZ_STRUCT dec;
+  wuffs_base__status status = wuffs_FUZZ_PACKAGE__FUZZ_STRUCT__initialize(
+      &dec, sizeof dec, WUFFS_VERSION,
+      (hash & 1) ? WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED : 0);
+  hash >>= 1;
+  if (!wuffs_base__status__is_ok(&status)) {
+    return wuffs_base__status__message(&status);
+  }
+  return fuzz_token_decoder(
+      src, hash,
+      FUZZ_UPCAST_DEC,
+      wuffs_base__make_slice_u8(g_work_buffer_array, WORK_BUFFER_ARRAY_SIZE),
+      FUZZ_LEN_MINS);
+}
+`
+
+const fuzzerCallSequenceC = `
+const char*  //
+fuzz(wuffs_base__io_buffer* src, uint64_t hash) {
+  wuffs_FUZZ_PACKAGE__decoder dec;
+  wuffs_base__status status = wuffs_FUZZ_PACKAGE__decoder__initialize(
+      &dec, sizeof dec, WUFFS_VERSION,
+      (hash & 1) ? WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED : 0);
+  hash >>= 1;
+  if (!wuffs_base__status__is_ok(&status)) {
+    return wuffs_base__status__message(&status);
+  }
+
+  // Ignore the checksum for 99.99%-ish of all input. When fuzzers generate
+  // random input, the checksum is very unlikely to match. Still, it's useful
+  // to verify that checksumming does not lead to e.g. buffer overflows.
+  wuffs_FUZZ_PACKAGE__decoder__set_quirk_enabled(
+      &dec, WUFFS_BASE__QUIRK_IGNORE_CHECKSUM, hash & 0xFFFE);
+  hash >>= 16;
+
+  static const fuzzlib_call_sequence_method methods[] = {
+FUZZ_METHODS
+  };
+  const fuzzlib_call_sequence seq = ((fuzzlib_call_sequence){
+      .dec = &dec,
+      .methods = methods,
+      .num_methods = sizeof methods / sizeof methods[0],
+      .loop_start = FUZZ_LOOP_START,
+      .workbuf_len = fuzz_workbuf_len,
+      .seek_io_position = FUZZ_SEEK,
+  });
+  return fuzz_call_sequence(src, hash, &seq);
+}
+`
+
+const fuzzerStructChoiceC = `
+// Wuffs allows either statically or dynamically allocated work buffers. This
+// program exercises static allocation. The sum of the decoders' worst cases is
+// at least the largest one.
+#define WORK_BUFFER_ARRAY_SIZE \
+FUZZ_WORKBUF_SIZES
+#if WORK_BUFFER_ARRAY_SIZE > 0
+uint8_t g_work_buffer_array[WORK_BUFFER_ARRAY_SIZE];
+#else
+// Not all C/C++ compilers support 0-length arrays.
+uint8_t g_work_buffer_array[1];
+#endif
+
+const char*  //
+fuzz(wuffs_base__io_buffer* src, uint64_t hash) {
+  uint32_t flags =
+      (hash & 1) ? WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED : 0;
+  hash >>= 1;
+
+  // This package has more than one decoder. Choose one of them.
+FUZZ_STRUCT_VARS
+  wuffs_base__FUZZ_INTERFACE* dec = NULL;
+  wuffs_base__status status;
+  switch (hash % FUZZ_NUM_STRUCTS) {
+FUZZ_CASES
+  }
+  hash /= FUZZ_NUM_STRUCTS;
+  if (!wuffs_base__status__is_ok(&status)) {
+    return wuffs_base__status__message(&status);
+  }
+  return FUZZ_HARNESS
+}
+`
@@ -0,0 +1,55 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestSeedCorpora checks that every std package with a decoder has a seed
+// corpus, as "wuffs genfuzz" needs one to generate that package's fuzzer.
+func TestSeedCorpora(t *testing.T) {
+	wuffsRoot := filepath.FromSlash("../..")
+	seeds, err := loadSeedCorpora(wuffsRoot)
+	if err != nil {
+		t.Fatalf("loadSeedCorpora: %v", err)
+	}
+	_, relDirnames, err := listDir(filepath.Join(wuffsRoot, "std"), "", true)
+	if err != nil {
+		t.Fatalf("listDir: %v", err)
+	}
+
+	for _, d := range relDirnames {
+		target, err := loadFuzzTarget(wuffsRoot, "std/"+d)
+		if err != nil {
+			t.Errorf("std/%s: %v", d, err)
+			continue
+		} else if target == nil {
+			continue
+		}
+		seed := seeds[d]
+		if seed == "" {
+			t.Errorf("std/%s: no test/... seed corpus in fuzz/c/std/seed_corpora.txt", d)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(wuffsRoot, filepath.FromSlash(seed)))
+		if err != nil {
+			t.Errorf("std/%s: %v", d, err)
+		} else if len(matches) == 0 {
+			t.Errorf("std/%s: seed corpus %q matches no files", d, seed)
+		}
+	}
+}
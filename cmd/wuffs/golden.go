@@ -0,0 +1,212 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// golden.go generates the C test programs ("golden drivers") that check a
+// package's decoder against its golden corpus: its entries in the corpus
+// indexes listed by corpusindex.IndexFilenames. The C code's list of corpus
+// indexes, in test/c/testlib/testlib.c, must match.
+//
+// Golden drivers need no hand-written C code. Adding a codec's test files and
+// corpus index entries is enough to test it, whether or not the package also
+// has a hand-written test/c/std/<package>.c.
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/wuffs/lib/corpusindex"
+)
+
+// goldenNumEntries returns the number of golden corpus entries for the named
+// package.
+func goldenNumEntries(wuffsRoot string, packageName string) (int, error) {
+	n := 0
+	for _, f := range corpusindex.IndexFilenames(packageName) {
+		x, err := corpusindex.Load(filepath.Join(wuffsRoot, filepath.FromSlash(f)))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		n += len(x.Filter(packageName))
+	}
+	return n, nil
+}
+
+// decoderInterface returns which base interface (e.g. "image_decoder") the
+// package's decoder struct implements, or "" if it has no decoder struct.
+func decoderInterface(wuffsRoot string, dirname string) (string, error) {
+	qualFilenames, _, err := listDir(
+		filepath.Join(wuffsRoot, filepath.FromSlash(dirname)), ".wuffs", false)
+	if err != nil {
+		return "", err
+	}
+	const prefix = "pub struct decoder? implements base."
+	for _, qf := range qualFilenames {
+		src, err := ioutil.ReadFile(qf)
+		if err != nil {
+			return "", err
+		}
+		if i := bytes.Index(src, []byte(prefix)); i >= 0 {
+			rest := src[i+len(prefix):]
+			if j := bytes.IndexByte(rest, '('); j >= 0 {
+				switch iface := string(rest[:j]); iface {
+				case "image_decoder", "io_transformer", "token_decoder":
+					return iface, nil
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// packageDependencies returns the named package and, recursively, the
+// packages that it uses, such as "std/lzw" for "std/gif".
+func packageDependencies(wuffsRoot string, dirname string) ([]string, error) {
+	seen := map[string]bool{}
+	stack := []string{dirname}
+	for len(stack) > 0 {
+		d := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+
+		qualFilenames, _, err := listDir(
+			filepath.Join(wuffsRoot, filepath.FromSlash(d)), ".wuffs", false)
+		if err != nil {
+			return nil, err
+		}
+		for _, qf := range qualFilenames {
+			src, err := ioutil.ReadFile(qf)
+			if err != nil {
+				return nil, err
+			}
+			for _, line := range strings.Split(string(src), "\n") {
+				const prefix = "use "
+				if !strings.HasPrefix(line, prefix) {
+					continue
+				}
+				if u, err := strconv.Unquote(strings.TrimSpace(line[len(prefix):])); err == nil {
+					stack = append(stack, u)
+				}
+			}
+		}
+	}
+
+	ret := make([]string, 0, len(seen))
+	for d := range seen {
+		ret = append(ret, d)
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// genGoldenDriver writes the named package's golden driver, as "<name>.c" in
+// dstDir, and returns that filename minus its ".c" suffix. It returns "" if
+// the package has no decoder or no golden corpus.
+func genGoldenDriver(wuffsRoot string, dirname string, dstDir string) (string, error) {
+	packageName := filepath.Base(dirname)
+	iface, err := decoderInterface(wuffsRoot, dirname)
+	if err != nil {
+		return "", err
+	} else if iface == "" {
+		return "", nil
+	}
+	if n, err := goldenNumEntries(wuffsRoot, packageName); err != nil {
+		return "", err
+	} else if n == 0 {
+		return "", nil
+	}
+	deps, err := packageDependencies(wuffsRoot, dirname)
+	if err != nil {
+		return "", err
+	}
+
+	modules := []string{"BASE"}
+	for _, d := range deps {
+		modules = append(modules, strings.ToUpper(filepath.Base(d)))
+	}
+	sort.Strings(modules)
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "// Code generated by \"wuffs test\" for %s's golden corpus. DO NOT EDIT.\n\n", dirname)
+	fmt.Fprintf(b, "#define WUFFS_IMPLEMENTATION\n\n#define WUFFS_CONFIG__MODULES\n")
+	for _, m := range modules {
+		fmt.Fprintf(b, "#define WUFFS_CONFIG__MODULE__%s\n", m)
+	}
+	fmt.Fprintf(b, "\n#include %s\n#include %s\n\n",
+		strconv.Quote(filepath.Join(wuffsRoot, "release", "c", "wuffs-unsupported-snapshot.c")),
+		strconv.Quote(filepath.Join(wuffsRoot, "test", "c", "testlib", "testlib.c")))
+	b.WriteString(strings.NewReplacer(
+		"GOLDEN_INTERFACE", iface,
+		"GOLDEN_PACKAGE", packageName,
+	).Replace(goldenDriverC))
+
+	filename := filepath.Join(dstDir, packageName)
+	if err := ioutil.WriteFile(filename+".c", b.Bytes(), 0644); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+const goldenDriverC = `wuffs_GOLDEN_PACKAGE__decoder g_GOLDEN_PACKAGE_corpus_index_decoder;
+
+const char*  //
+initialize_GOLDEN_PACKAGE_corpus_index_decoder(
+    wuffs_base__GOLDEN_INTERFACE** b) {
+  CHECK_STATUS("initialize",
+               wuffs_GOLDEN_PACKAGE__decoder__initialize(
+                   &g_GOLDEN_PACKAGE_corpus_index_decoder,
+                   sizeof g_GOLDEN_PACKAGE_corpus_index_decoder, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  *b = wuffs_GOLDEN_PACKAGE__decoder__upcast_as__wuffs_base__GOLDEN_INTERFACE(
+      &g_GOLDEN_PACKAGE_corpus_index_decoder);
+  return NULL;
+}
+
+const char*  //
+test_wuffs_GOLDEN_PACKAGE_decode_corpus_index() {
+  CHECK_FOCUS(__func__);
+  return do_test__wuffs_base__GOLDEN_INTERFACE__corpus_index(
+      "GOLDEN_PACKAGE", initialize_GOLDEN_PACKAGE_corpus_index_decoder);
+}
+
+proc g_tests[] = {
+
+    test_wuffs_GOLDEN_PACKAGE_decode_corpus_index,
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "golden/GOLDEN_PACKAGE";
+  return test_main(argc, argv, g_tests, g_benches);
+}
+`
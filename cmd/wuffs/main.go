@@ -35,7 +35,11 @@ var commands = []struct {
 	do   func(wuffsRoot string, args []string) error
 }{
 	{"bench", doBench},
+	{"debug", doDebug},
+	{"doc", doDoc},
+	{"dump", doDump},
 	{"gen", doGen},
+	{"genfuzz", doGenfuzz},
 	{"genlib", doGenlib},
 	{"test", doTest},
 }
@@ -50,7 +54,11 @@ Usage:
 The commands are:
 
 	bench   benchmark packages
+	debug   step through a decoder's run over an input
+	doc     generate API reference documentation for packages
+	dump    print a file's tokens, AST or checker facts
 	gen     generate code for packages and dependencies
+	genfuzz generate fuzz programs for packages' decoders
 	genlib  generate software libraries
 	test    test packages
 `)
@@ -24,13 +24,13 @@ import (
 	cf "github.com/google/wuffs/cmd/commonflags"
 )
 
-func genrelease(wuffsRoot string, langs []string, v cf.Version) error {
+func genrelease(wuffsRoot string, langs []string, v cf.Version, cdialect string, onlyneededbase bool) error {
 	revision := runGitCommand(wuffsRoot, "rev-parse", "HEAD")
 	commitDate := runGitCommand(wuffsRoot, "show",
 		"--quiet", "--date=format-local:%Y-%m-%d", "--format=%cd")
 	gitRevListCount := runGitCommand(wuffsRoot, "rev-list", "--count", "HEAD")
 	for _, lang := range langs {
-		filename, contents, err := genreleaseLang(wuffsRoot, revision, commitDate, gitRevListCount, v, lang)
+		filename, contents, err := genreleaseLang(wuffsRoot, revision, commitDate, gitRevListCount, v, cdialect, onlyneededbase, lang)
 		if err != nil {
 			return err
 		}
@@ -41,7 +41,7 @@ func genrelease(wuffsRoot string, langs []string, v cf.Version) error {
 	return nil
 }
 
-func genreleaseLang(wuffsRoot string, revision string, commitDate, gitRevListCount string, v cf.Version, lang string) (filename string, contents []byte, err error) {
+func genreleaseLang(wuffsRoot string, revision string, commitDate, gitRevListCount string, v cf.Version, cdialect string, onlyneededbase bool, lang string) (filename string, contents []byte, err error) {
 	qualFilenames, err := findFiles(filepath.Join(wuffsRoot, "gen", lang), "."+lang)
 	if err != nil {
 		return "", nil, err
@@ -57,6 +57,12 @@ func genreleaseLang(wuffsRoot string, revision string, commitDate, gitRevListCou
 	if gitRevListCount != "" {
 		args = append(args, "-gitrevlistcount", gitRevListCount)
 	}
+	if (lang == "c") && (cdialect != cf.CdialectDefault) {
+		args = append(args, fmt.Sprintf("-cdialect=%s", cdialect))
+	}
+	if onlyneededbase {
+		args = append(args, "-only-needed-base")
+	}
 	args = append(args, qualFilenames...)
 	stdout := &bytes.Buffer{}
 
@@ -17,6 +17,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -31,6 +32,7 @@ func doTest(wuffsRoot string, args []string) error  { return doBenchTest(wuffsRo
 func doBenchTest(wuffsRoot string, args []string, bench bool) error {
 	flags := flag.NewFlagSet("test", flag.ExitOnError)
 	ccompilersFlag := flags.String("ccompilers", cf.CcompilersDefault, cf.CcompilersUsage)
+	coroutinedispatchFlag := flags.String("coroutinedispatch", cf.CoroutinedispatchDefault, cf.CoroutinedispatchUsage)
 	focusFlag := flags.String("focus", cf.FocusDefault, cf.FocusUsage)
 	iterscaleFlag := flags.Int("iterscale", cf.IterscaleDefault, cf.IterscaleUsage)
 	langsFlag := flags.String("langs", langsDefault, langsUsage)
@@ -50,6 +52,9 @@ func doBenchTest(wuffsRoot string, args []string, bench bool) error {
 	if !cf.IsAlphaNumericIsh(*ccompilersFlag) {
 		return fmt.Errorf("bad -ccompilers flag value %q", *ccompilersFlag)
 	}
+	if !cf.IsValidCoroutinedispatch(*coroutinedispatchFlag) {
+		return fmt.Errorf("bad -coroutinedispatch flag value %q", *coroutinedispatchFlag)
+	}
 	if !cf.IsAlphaNumericIsh(*focusFlag) {
 		return fmt.Errorf("bad -focus flag value %q", *focusFlag)
 	}
@@ -84,10 +89,12 @@ func doBenchTest(wuffsRoot string, args []string, bench bool) error {
 	}
 
 	h := testHelper{
-		wuffsRoot:  wuffsRoot,
-		langs:      langs,
-		cmdArgs:    cmdArgs,
-		ccompilers: *ccompilersFlag,
+		wuffsRoot:         wuffsRoot,
+		bench:             bench,
+		langs:             langs,
+		cmdArgs:           cmdArgs,
+		ccompilers:        *ccompilersFlag,
+		coroutinedispatch: *coroutinedispatchFlag,
 	}
 
 	// Ensure that we are testing the latest version of the generated code.
@@ -111,7 +118,7 @@ func doBenchTest(wuffsRoot string, args []string, bench bool) error {
 				return err
 			}
 		}
-		if err := genrelease(wuffsRoot, langs, cf.Version{}); err != nil {
+		if err := genrelease(wuffsRoot, langs, cf.Version{}, cf.CdialectDefault, false); err != nil {
 			return err
 		}
 	}
@@ -144,10 +151,12 @@ func doBenchTest(wuffsRoot string, args []string, bench bool) error {
 }
 
 type testHelper struct {
-	wuffsRoot  string
-	langs      []string
-	cmdArgs    []string
-	ccompilers string
+	wuffsRoot         string
+	bench             bool
+	langs             []string
+	cmdArgs           []string
+	ccompilers        string
+	coroutinedispatch string
 }
 
 func (h *testHelper) benchTest(dirname string, recursive bool) (failed bool, err error) {
@@ -184,13 +193,45 @@ func (h *testHelper) benchTestDir(dirname string) (failed bool, err error) {
 	}
 
 	for _, lang := range h.langs {
+		f, err := h.benchTestLang(dirname, lang)
+		if err != nil {
+			return false, err
+		}
+		failed = failed || f
+	}
+	return failed, nil
+}
+
+func (h *testHelper) benchTestLang(dirname string, lang string) (failed bool, err error) {
+	programs := []string{filepath.Join(h.wuffsRoot, "test", lang, filepath.FromSlash(dirname))}
+
+	// Golden drivers test correctness, not performance, and are only
+	// generated for C.
+	if (lang == "c") && !h.bench {
+		workDir, err := ioutil.TempDir("", "wuffs-golden")
+		if err != nil {
+			return false, err
+		}
+		defer os.RemoveAll(workDir)
+		if g, err := genGoldenDriver(h.wuffsRoot, dirname, workDir); err != nil {
+			return false, err
+		} else if g != "" {
+			programs = append(programs, g)
+		}
+	}
+
+	for _, program := range programs {
+		if _, err := os.Stat(program + "." + lang); os.IsNotExist(err) {
+			continue
+		}
 		command := "wuffs-" + lang
 		args := []string(nil)
 		args = append(args, h.cmdArgs...)
 		if lang == "c" {
 			args = append(args, fmt.Sprintf("-ccompilers=%s", h.ccompilers))
+			args = append(args, fmt.Sprintf("-coroutinedispatch=%s", h.coroutinedispatch))
 		}
-		args = append(args, filepath.Join(h.wuffsRoot, "test", lang, filepath.FromSlash(dirname)))
+		args = append(args, program)
 		cmd := exec.Command(command, args...)
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
@@ -16,11 +16,20 @@
 
 // wuffsfmt formats Wuffs programs.
 //
-// Without explicit paths, it rewrites the standard input to standard output.
-// Otherwise, the -l (list files that would change) or -w (write files in
-// place) or both flags must be given. Given a file path, it operates on that
-// file; given a directory path, it operates on all *.wuffs files in that
-// directory, recursively. File paths starting with a period are ignored.
+// Without explicit paths, it rewrites the standard input to standard output
+// (or, with the -d flag, prints a diff). Otherwise, at least one of the -d
+// (print diffs), -l (list files that would change) or -w (write files in
+// place) flags must be given. Given a file path, it operates on that file;
+// given a directory path, it operates on all *.wuffs files in that directory,
+// recursively. File paths starting with a period are ignored.
+//
+// Beyond normalizing whitespace, formatting also sorts and de-duplicates a
+// file's "use" lines, wraps over-long argument lists and aligns trailing
+// comments. See the lang/render package for details.
+//
+// The -r flag, which can be repeated, gives a rewrite rule of the form
+// "pattern -> replacement" to apply before formatting. See the
+// lang/codemod package for the rule syntax.
 package main
 
 import (
@@ -31,10 +40,12 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 
+	"github.com/google/wuffs/lang/codemod"
 	"github.com/google/wuffs/lang/parse"
 	"github.com/google/wuffs/lang/render"
 
@@ -42,10 +53,22 @@ import (
 )
 
 var (
+	dFlag = flag.Bool("d", false, "display diffs instead of rewriting files")
 	lFlag = flag.Bool("l", false, "list files whose formatting differs from wuffsfmt's")
 	wFlag = flag.Bool("w", false, "write result to (source) file instead of stdout")
+
+	rFlags ruleFlags
 )
 
+func init() {
+	flag.Var(&rFlags, "r", "rewrite rule, e.g. \"x.foo(a: y) -> x.bar(a: y)\" (repeatable)")
+}
+
+type ruleFlags []string
+
+func (f *ruleFlags) String() string     { return strings.Join(*f, "; ") }
+func (f *ruleFlags) Set(s string) error { *f = append(*f, s); return nil }
+
 func usage() {
 	fmt.Fprintf(os.Stderr, "usage: wuffsfmt [flags] [path ...]\n")
 	flag.PrintDefaults()
@@ -72,8 +95,8 @@ func main1() error {
 		return do(os.Stdin, "<standard input>")
 	}
 
-	if !*lFlag && !*wFlag {
-		return errors.New("must use -l or -w if paths are given")
+	if !*dFlag && !*lFlag && !*wFlag {
+		return errors.New("must use -d, -l or -w if paths are given")
 	}
 
 	for i := 0; i < flag.NArg(); i++ {
@@ -126,6 +149,19 @@ func do(r io.Reader, filename string) error {
 	if err != nil {
 		return err
 	}
+	if len(rFlags) > 0 {
+		rules := make([]*codemod.Rule, 0, len(rFlags))
+		for _, rFlag := range rFlags {
+			rule, err := codemod.ParseRule(tm, rFlag)
+			if err != nil {
+				return err
+			}
+			rules = append(rules, rule)
+		}
+		if tokens, _, err = codemod.Apply(tm, filename, tokens, rules); err != nil {
+			return err
+		}
+	}
 	// We don't need the AST node to pretty-print, but it's worth rejecting
 	// syntax errors early. This is just a parse, not a full type check.
 	if _, err := parse.Parse(tm, filename, tokens, &parse.Options{
@@ -139,7 +175,7 @@ func do(r io.Reader, filename string) error {
 	}
 	dst := buf.Bytes()
 
-	if r != nil {
+	if (r != nil) && !*dFlag {
 		if _, err := os.Stdout.Write(dst); err != nil {
 			return err
 		}
@@ -147,6 +183,15 @@ func do(r io.Reader, filename string) error {
 		if *lFlag {
 			fmt.Println(filename)
 		}
+		if *dFlag {
+			d, err := diff(filename, src, dst)
+			if err != nil {
+				return fmt.Errorf("computing diff: %v", err)
+			}
+			if _, err := os.Stdout.Write(d); err != nil {
+				return err
+			}
+		}
 		if *wFlag {
 			if err := writeFile(filename, dst); err != nil {
 				return err
@@ -157,6 +202,48 @@ func do(r io.Reader, filename string) error {
 	return nil
 }
 
+// diff returns a unified diff of b0 and b1, as computed by the "diff" program.
+func diff(filename string, b0 []byte, b1 []byte) ([]byte, error) {
+	f0, err := writeTempFile("wuffsfmt", b0)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f0)
+
+	f1, err := writeTempFile("wuffsfmt", b1)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f1)
+
+	data, err := exec.Command("diff", "-u",
+		"--label", filename+".orig", "--label", filename, f0, f1).CombinedOutput()
+	if len(data) > 0 {
+		// diff exits with a non-zero status when the files don't match.
+		// Ignore that failure as long as we get output.
+		return data, nil
+	}
+	return nil, err
+}
+
+func writeTempFile(prefix string, b []byte) (string, error) {
+	f, err := ioutil.TempFile("", prefix)
+	if err != nil {
+		return "", err
+	}
+	_, werr := f.Write(b)
+	cerr := f.Close()
+	if werr != nil {
+		os.Remove(f.Name())
+		return "", werr
+	}
+	if cerr != nil {
+		os.Remove(f.Name())
+		return "", cerr
+	}
+	return f.Name(), nil
+}
+
 const chmodSupported = runtime.GOOS != "windows"
 
 func writeFile(filename string, b []byte) error {
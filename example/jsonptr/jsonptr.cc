@@ -586,7 +586,8 @@ class Query {
     m_frag_j = (uint8_t*)query_c_string;
     m_frag_k = (uint8_t*)query_c_string;
     m_depth = 0;
-    m_array_index.status.repr = "#main: not an array index query fragment";
+    m_array_index.status =
+        wuffs_base__make_status("#main: not an array index query fragment");
     m_array_index.value = 0;
   }
 
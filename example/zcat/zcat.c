@@ -65,6 +65,13 @@ for a C compiler $CC, such as clang or gcc.
 #define DST_BUFFER_ARRAY_SIZE (128 * 1024)
 #endif
 
+// The dst buffer doubles as the decoder's history window, so it has to be
+// larger than that window. See the QUIRK_DST_RETAINS_HISTORY comment below.
+#if DST_BUFFER_ARRAY_SIZE <= \
+    WUFFS_GZIP__DECODER_DST_HISTORY_RETAIN_LENGTH_MAX_INCL_WORST_CASE
+#error "DST_BUFFER_ARRAY_SIZE is too small"
+#endif
+
 #ifndef SRC_BUFFER_ARRAY_SIZE
 #define SRC_BUFFER_ARRAY_SIZE (128 * 1024)
 #endif
@@ -150,6 +157,12 @@ main1(int argc, char** argv) {
     return wuffs_base__status__message(&status);
   }
 
+  // Use the dst buffer as the decoder's history window, instead of having the
+  // decoder also copy its output to an internal history buffer. Compacting
+  // dst, below, retains that window.
+  wuffs_gzip__decoder__set_quirk_enabled(
+      &dec, WUFFS_BASE__QUIRK_DST_RETAINS_HISTORY, true);
+
   wuffs_base__io_buffer dst;
   dst.data.ptr = g_dst_buffer_array;
   dst.data.len = DST_BUFFER_ARRAY_SIZE;
@@ -187,12 +200,15 @@ main1(int argc, char** argv) {
           wuffs_base__make_slice_u8(g_work_buffer_array,
                                     WORK_BUFFER_ARRAY_SIZE));
 
-      if (dst.meta.wi) {
+      if (dst.meta.wi > dst.meta.ri) {
         // TODO: handle EINTR and other write errors; see "man 2 write".
         const int stdout_fd = 1;
-        ignore_return_value(write(stdout_fd, g_dst_buffer_array, dst.meta.wi));
+        ignore_return_value(write(stdout_fd, dst.data.ptr + dst.meta.ri,
+                                  dst.meta.wi - dst.meta.ri));
         dst.meta.ri = dst.meta.wi;
-        wuffs_base__io_buffer__compact(&dst);
+        wuffs_base__io_buffer__compact_retain(
+            &dst,
+            WUFFS_GZIP__DECODER_DST_HISTORY_RETAIN_LENGTH_MAX_INCL_WORST_CASE);
       }
 
       if (status.repr == wuffs_base__suspension__short_read) {
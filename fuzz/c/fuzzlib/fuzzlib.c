@@ -114,7 +114,51 @@ make_limited_reader(wuffs_base__io_buffer b, uint64_t limit) {
   return ret;
 }
 
-#ifdef WUFFS_CONFIG__FUZZLIB_MAIN
+// fuzzlib_advance_limited_reader advances src past the bytes that lim, a
+// limited reader (see make_limited_reader) of src, has consumed. It returns
+// whether to retry the coroutine call that returned status: whether it
+// suspended for more input that the rest of src can provide.
+//
+// Some coroutines need more than one byte of input to make progress. A retry
+// after no progress doubles *limit, the limited reader's next length.
+static bool  //
+fuzzlib_advance_limited_reader(wuffs_base__io_buffer* src,
+                               const wuffs_base__io_buffer* lim,
+                               wuffs_base__status status,
+                               uint64_t* limit) {
+  src->meta.ri += lim->meta.ri;
+  if ((status.repr != wuffs_base__suspension__short_read) ||
+      lim->meta.closed) {
+    return false;
+  }
+  if (lim->meta.ri == 0) {
+    *limit = wuffs_base__u64__sat_add(*limit, *limit);
+  }
+  return true;
+}
+
+#if defined(WUFFS_CONFIG__FUZZLIB_AFL)
+
+// AFL++ (https://github.com/AFLplusplus/AFLplusplus) can run the fuzz function
+// via a libFuzzer-style LLVMFuzzerTestOneInput, but its persistent mode, which
+// needs afl-clang-fast or afl-clang-lto, is much faster:
+//
+// afl-clang-fast -DWUFFS_CONFIG__FUZZLIB_AFL gif_fuzzer.c -o gif_afl
+// afl-fuzz -i ../../../test/data -o /tmp/gif_afl_out ./gif_afl
+
+__AFL_FUZZ_INIT();
+
+int  //
+main(int argc, char** argv) {
+  __AFL_INIT();
+  const uint8_t* data = __AFL_FUZZ_TESTCASE_BUF;
+  while (__AFL_LOOP(10000)) {
+    llvmFuzzerTestOneInput(data, __AFL_FUZZ_TESTCASE_LEN);
+  }
+  return 0;
+}
+
+#elif defined(WUFFS_CONFIG__FUZZLIB_MAIN)
 
 #include <dirent.h>
 #include <errno.h>
@@ -349,4 +393,4 @@ main(int argc, char** argv) {
   return 0;
 }
 
-#endif  // WUFFS_CONFIG__FUZZLIB_MAIN
+#endif  // WUFFS_CONFIG__FUZZLIB_AFL, WUFFS_CONFIG__FUZZLIB_MAIN
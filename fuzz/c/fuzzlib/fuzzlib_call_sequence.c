@@ -0,0 +1,143 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+#ifndef WUFFS_INCLUDE_GUARD
+#error "Wuffs' .h files need to be included before this file"
+#endif
+
+// This harness is for decoders that don't implement a base interface, such as
+// std/tar and std/zip, which instead have a sequence of coroutine methods
+// (e.g. decode_entry then decode_body) that the caller calls in order.
+
+#define FUZZLIB_CALL_SEQUENCE_DST_ARRAY_SIZE 65536
+
+uint8_t g_fuzzlib_call_sequence_dst_array[FUZZLIB_CALL_SEQUENCE_DST_ARRAY_SIZE];
+
+// fuzzlib_call_sequence_method calls one of the decoder's coroutine methods.
+// Methods that don't take a dst or workbuf argument ignore them.
+typedef wuffs_base__status (*fuzzlib_call_sequence_method)(
+    void* dec,
+    wuffs_base__io_buffer* dst,
+    wuffs_base__io_buffer* src,
+    wuffs_base__slice_u8 workbuf);
+
+typedef struct {
+  void* dec;
+
+  // methods[0 .. num_methods] are called in order. After the last one, the
+  // sequence continues from methods[loop_start], until a method returns
+  // "@end of data" or an error. A loop_start of num_methods means to stop
+  // after calling the last method once.
+  const fuzzlib_call_sequence_method* methods;
+  size_t num_methods;
+  size_t loop_start;
+
+  wuffs_base__range_ii_u64 (*workbuf_len)(void* dec);
+
+  // seek_io_position is NULL if the decoder never seeks. Otherwise, it is
+  // called after a method returns "$mispositioned read".
+  uint64_t (*seek_io_position)(void* dec);
+} fuzzlib_call_sequence;
+
+static const char*  //
+fuzz_call_sequence(wuffs_base__io_buffer* src,
+                   uint64_t hash,
+                   const fuzzlib_call_sequence* seq) {
+  const char* ret = NULL;
+  wuffs_base__slice_u8 workbuf = ((wuffs_base__slice_u8){});
+
+  // Use a {} code block so that "goto exit" doesn't trigger "jump bypasses
+  // variable initialization" warnings.
+  {
+    // 50% of the time, read the input 1 byte at a time.
+    uint64_t src_limit = (hash & 1) ? 1 : UINT64_MAX;
+    hash >>= 1;
+
+    // 50% of the time, use a tiny (1 to 16 bytes) dst buffer.
+    size_t dst_len = FUZZLIB_CALL_SEQUENCE_DST_ARRAY_SIZE;
+    if (hash & 1) {
+      dst_len = 1 + ((hash >> 1) & 15);
+    }
+    hash >>= 5;
+
+    wuffs_base__io_buffer dst = ((wuffs_base__io_buffer){
+        .data = ((wuffs_base__slice_u8){
+            .ptr = g_fuzzlib_call_sequence_dst_array,
+            .len = dst_len,
+        }),
+    });
+
+    size_t i = 0;
+    while (true) {
+      // Wuffs allows either statically or dynamically allocated work
+      // buffers. This program exercises dynamic allocation. The workbuf_len
+      // can grow after a method (e.g. decode_header) has been called.
+      uint64_t n = (*seq->workbuf_len)(seq->dec).max_incl;
+      if (n > workbuf.len) {
+        if (n > 64 * 1024 * 1024) {  // Don't allocate more than 64 MiB.
+          ret = "workbuf too large";
+          goto exit;
+        }
+        free(workbuf.ptr);
+        workbuf = wuffs_base__malloc_slice_u8(malloc, n);
+        if (!workbuf.ptr) {
+          ret = "out of memory";
+          goto exit;
+        }
+      }
+
+      dst.meta.pos = wuffs_base__u64__sat_add(dst.meta.pos, dst.meta.wi);
+      dst.meta.wi = 0;
+      wuffs_base__io_buffer lim = make_limited_reader(*src, src_limit);
+      wuffs_base__status status =
+          (*seq->methods[i])(seq->dec, &dst, &lim, workbuf);
+      bool again =
+          fuzzlib_advance_limited_reader(src, &lim, status, &src_limit);
+
+      if (status.repr == wuffs_base__suspension__short_write) {
+        if (dst.meta.wi == 0) {
+          fprintf(stderr, "fuzz_call_sequence: method #%zu made no progress\n",
+                  i);
+          intentional_segfault();
+        }
+        continue;
+      } else if (again) {
+        continue;
+      } else if ((status.repr == wuffs_base__suspension__mispositioned_read) &&
+                 seq->seek_io_position) {
+        if (!wuffs_base__io_buffer__reader_seek(
+                src, (*seq->seek_io_position)(seq->dec))) {
+          ret = "seek_io_position is out of bounds";
+          goto exit;
+        }
+        continue;
+      } else if (!wuffs_base__status__is_ok(&status)) {
+        ret = wuffs_base__status__message(&status);
+        goto exit;
+      }
+
+      i++;
+      if (i < seq->num_methods) {
+        continue;
+      } else if (seq->loop_start >= seq->num_methods) {
+        goto exit;
+      }
+      i = seq->loop_start;
+    }
+  }
+
+exit:
+  free(workbuf.ptr);
+  return ret;
+}
@@ -16,6 +16,57 @@
 #error "Wuffs' .h files need to be included before this file"
 #endif
 
+// The fuzz_image_decoder__etc functions call the similarly named
+// wuffs_base__image_decoder__etc functions with limited readers of src, until
+// they no longer suspend for more input. A *src_limit of UINT64_MAX means one
+// call with all of src. A *src_limit of 1 means 1-byte-at-a-time I/O.
+
+static wuffs_base__status  //
+fuzz_image_decoder__decode_image_config(wuffs_base__image_decoder* dec,
+                                        wuffs_base__image_config* ic,
+                                        wuffs_base__io_buffer* src,
+                                        uint64_t* src_limit) {
+  while (true) {
+    wuffs_base__io_buffer lim = make_limited_reader(*src, *src_limit);
+    wuffs_base__status status =
+        wuffs_base__image_decoder__decode_image_config(dec, ic, &lim);
+    if (!fuzzlib_advance_limited_reader(src, &lim, status, src_limit)) {
+      return status;
+    }
+  }
+}
+
+static wuffs_base__status  //
+fuzz_image_decoder__decode_frame_config(wuffs_base__image_decoder* dec,
+                                        wuffs_base__frame_config* fc,
+                                        wuffs_base__io_buffer* src,
+                                        uint64_t* src_limit) {
+  while (true) {
+    wuffs_base__io_buffer lim = make_limited_reader(*src, *src_limit);
+    wuffs_base__status status =
+        wuffs_base__image_decoder__decode_frame_config(dec, fc, &lim);
+    if (!fuzzlib_advance_limited_reader(src, &lim, status, src_limit)) {
+      return status;
+    }
+  }
+}
+
+static wuffs_base__status  //
+fuzz_image_decoder__decode_frame(wuffs_base__image_decoder* dec,
+                                 wuffs_base__pixel_buffer* pb,
+                                 wuffs_base__io_buffer* src,
+                                 wuffs_base__slice_u8 workbuf,
+                                 uint64_t* src_limit) {
+  while (true) {
+    wuffs_base__io_buffer lim = make_limited_reader(*src, *src_limit);
+    wuffs_base__status status = wuffs_base__image_decoder__decode_frame(
+        dec, pb, &lim, WUFFS_BASE__PIXEL_BLEND__SRC, workbuf, NULL);
+    if (!fuzzlib_advance_limited_reader(src, &lim, status, src_limit)) {
+      return status;
+    }
+  }
+}
+
 static const char*  //
 fuzz_image_decoder(wuffs_base__io_buffer* src,
                    uint64_t hash,
@@ -27,9 +78,22 @@ fuzz_image_decoder(wuffs_base__io_buffer* src,
   // Use a {} code block so that "goto exit" doesn't trigger "jump bypasses
   // variable initialization" warnings.
   {
+    // 50% of the time, choose BGRA_PREMUL instead of the native pixel config.
+    bool bgra_premul = hash & 1;
+    hash >>= 1;
+
+    // 50% of the time, read the input 1 byte at a time.
+    uint64_t src_limit = (hash & 1) ? 1 : UINT64_MAX;
+    hash >>= 1;
+
+    // 50% of the time, after decoding every frame, restart at the first frame
+    // and decode it again.
+    bool restart = hash & 1;
+    hash >>= 1;
+
     wuffs_base__image_config ic = ((wuffs_base__image_config){});
     wuffs_base__status status =
-        wuffs_base__image_decoder__decode_image_config(dec, &ic, src);
+        fuzz_image_decoder__decode_image_config(dec, &ic, src, &src_limit);
     if (!wuffs_base__status__is_ok(&status)) {
       ret = wuffs_base__status__message(&status);
       goto exit;
@@ -39,15 +103,13 @@ fuzz_image_decoder(wuffs_base__io_buffer* src,
       goto exit;
     }
 
-    // 50% of the time, choose BGRA_PREMUL instead of the native pixel config.
-    if (hash & 1) {
+    if (bgra_premul) {
       wuffs_base__pixel_config__set(
           &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL,
           WUFFS_BASE__PIXEL_SUBSAMPLING__NONE,
           wuffs_base__pixel_config__width(&ic.pixcfg),
           wuffs_base__pixel_config__height(&ic.pixcfg));
     }
-    hash >>= 1;
 
     // Wuffs allows either statically or dynamically allocated work buffers.
     // This program exercises dynamic allocation.
@@ -84,19 +146,25 @@ fuzz_image_decoder(wuffs_base__io_buffer* src,
       goto exit;
     }
 
+    wuffs_base__frame_config first_fc = ((wuffs_base__frame_config){});
     bool seen_ok = false;
     while (true) {
       wuffs_base__frame_config fc = ((wuffs_base__frame_config){});
-      status = wuffs_base__image_decoder__decode_frame_config(dec, &fc, src);
+      status =
+          fuzz_image_decoder__decode_frame_config(dec, &fc, src, &src_limit);
       if (!wuffs_base__status__is_ok(&status)) {
         if ((status.repr != wuffs_base__note__end_of_data) || !seen_ok) {
           ret = wuffs_base__status__message(&status);
+          goto exit;
         }
-        goto exit;
+        break;
+      }
+      if (!seen_ok) {
+        first_fc = fc;
       }
 
-      status = wuffs_base__image_decoder__decode_frame(
-          dec, &pb, src, WUFFS_BASE__PIXEL_BLEND__SRC, workbuf, NULL);
+      status = fuzz_image_decoder__decode_frame(dec, &pb, src, workbuf,
+                                                &src_limit);
 
       wuffs_base__rect_ie_u32 frame_rect =
           wuffs_base__frame_config__bounds(&fc);
@@ -110,8 +178,9 @@ fuzz_image_decoder(wuffs_base__io_buffer* src,
       if (!wuffs_base__status__is_ok(&status)) {
         if ((status.repr != wuffs_base__note__end_of_data) || !seen_ok) {
           ret = wuffs_base__status__message(&status);
+          goto exit;
         }
-        goto exit;
+        break;
       }
       seen_ok = true;
 
@@ -120,6 +189,44 @@ fuzz_image_decoder(wuffs_base__io_buffer* src,
         goto exit;
       }
     }
+
+    if (!restart) {
+      goto exit;
+    }
+
+    // Restarting needs src to hold the first frame's io_position.
+    uint64_t pos = wuffs_base__frame_config__io_position(&first_fc);
+    if ((pos < src->meta.pos) || ((pos - src->meta.pos) > src->meta.wi)) {
+      ret = "internal error: io_position is out of bounds";
+      goto exit;
+    }
+    status = wuffs_base__image_decoder__restart_frame(dec, 0, pos);
+    if (!wuffs_base__status__is_ok(&status)) {
+      ret = wuffs_base__status__message(&status);
+      goto exit;
+    }
+    src->meta.ri = pos - src->meta.pos;
+
+    wuffs_base__frame_config fc = ((wuffs_base__frame_config){});
+    status = fuzz_image_decoder__decode_frame_config(dec, &fc, src, &src_limit);
+    if (!wuffs_base__status__is_ok(&status)) {
+      ret = wuffs_base__status__message(&status);
+      goto exit;
+    }
+    wuffs_base__rect_ie_u32 frame_rect = wuffs_base__frame_config__bounds(&fc);
+    wuffs_base__rect_ie_u32 first_rect =
+        wuffs_base__frame_config__bounds(&first_fc);
+    if (!wuffs_base__rect_ie_u32__equals(&frame_rect, first_rect)) {
+      ret = "internal error: restarted frame_rect does not equal first one";
+      goto exit;
+    }
+
+    status =
+        fuzz_image_decoder__decode_frame(dec, &pb, src, workbuf, &src_limit);
+    if (!wuffs_base__status__is_ok(&status)) {
+      ret = wuffs_base__status__message(&status);
+      goto exit;
+    }
   }
 
 exit:
@@ -0,0 +1,72 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+#ifndef WUFFS_INCLUDE_GUARD
+#error "Wuffs' .h files need to be included before this file"
+#endif
+
+#define FUZZLIB_IO_TRANSFORMER_DST_ARRAY_SIZE 65536
+
+uint8_t g_fuzzlib_io_transformer_dst_array
+    [FUZZLIB_IO_TRANSFORMER_DST_ARRAY_SIZE];
+
+static const char*  //
+fuzz_io_transformer(wuffs_base__io_buffer* src,
+                    uint64_t hash,
+                    wuffs_base__io_transformer* dec,
+                    wuffs_base__slice_u8 workbuf) {
+  // Ignore the checksum for 99.99%-ish of all input. When fuzzers generate
+  // random input, the checkum is very unlikely to match. Still, it's useful to
+  // verify that checksumming does not lead to e.g. buffer overflows.
+  wuffs_base__io_transformer__set_quirk_enabled(
+      dec, WUFFS_BASE__QUIRK_IGNORE_CHECKSUM, hash & 0xFFFE);
+  hash >>= 16;
+
+  // 50% of the time, read the input 1 byte at a time.
+  uint64_t src_limit = (hash & 1) ? 1 : UINT64_MAX;
+  hash >>= 1;
+
+  // 50% of the time, use a tiny (1 to 16 bytes) dst buffer.
+  size_t dst_len = FUZZLIB_IO_TRANSFORMER_DST_ARRAY_SIZE;
+  if (hash & 1) {
+    dst_len = 1 + ((hash >> 1) & 15);
+  }
+  hash >>= 5;
+
+  wuffs_base__io_buffer dst = ((wuffs_base__io_buffer){
+      .data = ((wuffs_base__slice_u8){
+          .ptr = g_fuzzlib_io_transformer_dst_array,
+          .len = dst_len,
+      }),
+  });
+
+  while (true) {
+    dst.meta.pos = wuffs_base__u64__sat_add(dst.meta.pos, dst.meta.wi);
+    dst.meta.wi = 0;
+    wuffs_base__io_buffer lim = make_limited_reader(*src, src_limit);
+    wuffs_base__status status =
+        wuffs_base__io_transformer__transform_io(dec, &dst, &lim, workbuf);
+    bool again = fuzzlib_advance_limited_reader(src, &lim, status, &src_limit);
+    if (status.repr == wuffs_base__suspension__short_write) {
+      if (dst.meta.wi == 0) {
+        fprintf(stderr, "wuffs_base__io_transformer__transform_io made no "
+                        "progress\n");
+        intentional_segfault();
+      }
+    } else if (!again) {
+      return wuffs_base__status__message(&status);
+    }
+  }
+}
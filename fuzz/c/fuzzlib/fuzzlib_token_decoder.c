@@ -0,0 +1,107 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+#ifndef WUFFS_INCLUDE_GUARD
+#error "Wuffs' .h files need to be included before this file"
+#endif
+
+#define FUZZLIB_TOKEN_DECODER_DST_ARRAY_SIZE 4096
+
+wuffs_base__token g_fuzzlib_token_decoder_dst_array
+    [FUZZLIB_TOKEN_DECODER_DST_ARRAY_SIZE];
+
+// fuzz_token_decoder checks properties common to every token decoder, such as
+// every src byte being covered by exactly one token. Packages like std/json
+// have hand-written fuzzers that also check format-specific properties.
+//
+// src_len_min and dst_len_min are the package's minimum src and dst buffer
+// lengths, if it has any DECODER_SRC_IO_BUFFER_LENGTH_MIN_INCL or
+// DECODER_DST_TOKEN_BUFFER_LENGTH_MIN_INCL constants, or 1 otherwise.
+static const char*  //
+fuzz_token_decoder(wuffs_base__io_buffer* src,
+                   uint64_t hash,
+                   wuffs_base__token_decoder* dec,
+                   wuffs_base__slice_u8 workbuf,
+                   uint64_t src_len_min,
+                   uint64_t dst_len_min) {
+  if (dst_len_min > FUZZLIB_TOKEN_DECODER_DST_ARRAY_SIZE) {
+    return "fuzz: internal error: dst_len_min is too large";
+  }
+
+  // 50% of the time, read the input src_len_min bytes at a time.
+  uint64_t src_limit = (hash & 1) ? src_len_min : UINT64_MAX;
+  hash >>= 1;
+
+  // 50% of the time, use a tiny (dst_len_min to dst_len_min + 15 tokens) dst
+  // buffer.
+  size_t dst_len = FUZZLIB_TOKEN_DECODER_DST_ARRAY_SIZE;
+  if (hash & 1) {
+    dst_len = dst_len_min + ((hash >> 1) & 15);
+    if (dst_len > FUZZLIB_TOKEN_DECODER_DST_ARRAY_SIZE) {
+      dst_len = FUZZLIB_TOKEN_DECODER_DST_ARRAY_SIZE;
+    }
+  }
+  hash >>= 5;
+
+  wuffs_base__token_buffer dst = ((wuffs_base__token_buffer){
+      .data = ((wuffs_base__slice_token){
+          .ptr = g_fuzzlib_token_decoder_dst_array,
+          .len = dst_len,
+      }),
+  });
+
+  bool prev_continued = false;
+  while (true) {
+    dst.meta.pos = wuffs_base__u64__sat_add(dst.meta.pos, dst.meta.wi);
+    dst.meta.ri = 0;
+    dst.meta.wi = 0;
+    wuffs_base__io_buffer lim = make_limited_reader(*src, src_limit);
+    wuffs_base__status status =
+        wuffs_base__token_decoder__decode_tokens(dec, &dst, &lim, workbuf);
+    if ((dst.data.len < dst.meta.wi) || (lim.meta.wi < lim.meta.ri)) {
+      return "fuzz: internal error: inconsistent indexes";
+    }
+
+    // Check that the token lengths sum to the number of src bytes consumed.
+    uint64_t n = 0;
+    size_t i;
+    for (i = 0; i < dst.meta.wi; i++) {
+      wuffs_base__token* t = &dst.data.ptr[i];
+      n += wuffs_base__token__length(t);
+      if (!prev_continued &&
+          (wuffs_base__token__value_extension(t) >= 0)) {
+        return "fuzz: internal error: extended token not after continued "
+               "token";
+      }
+      prev_continued = wuffs_base__token__continued(t);
+    }
+    if (n != lim.meta.ri) {
+      return "fuzz: internal error: token lengths do not sum to ri";
+    }
+
+    bool again = fuzzlib_advance_limited_reader(src, &lim, status, &src_limit);
+    if (status.repr == wuffs_base__suspension__short_write) {
+      if (dst.meta.wi == 0) {
+        return "fuzz: internal error: no progress";
+      }
+    } else if (!again) {
+      if (wuffs_base__status__is_ok(&status) && prev_continued) {
+        return "fuzz: internal error: decoded OK but final token was "
+               "continued";
+      }
+      return wuffs_base__status__message(&status);
+    }
+  }
+}
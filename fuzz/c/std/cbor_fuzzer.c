@@ -246,6 +246,7 @@ fuzz_complex(wuffs_base__io_buffer* full_src, uint64_t hash_56_bits) {
   uint64_t src_limit =
       buffer_limit(hash_50_bits & 0x3F,
                    WUFFS_CBOR__DECODER_SRC_IO_BUFFER_LENGTH_MIN_INCL, 4096);
+  uint64_t hash_44_bits = hash_50_bits >> 6;
 
   // ----
 
@@ -256,6 +257,10 @@ fuzz_complex(wuffs_base__io_buffer* full_src, uint64_t hash_56_bits) {
   if (!wuffs_base__status__is_ok(&status)) {
     return wuffs_base__status__message(&status);
   }
+  if (hash_44_bits & 1) {
+    wuffs_cbor__decoder__set_quirk_enabled(
+        &dec, WUFFS_CBOR__QUIRK_DECODE_BIGNUMS_AS_INLINE_INTEGERS, true);
+  }
 
   wuffs_base__token tok_array[TOK_BUFFER_ARRAY_SIZE];
   wuffs_base__token_buffer tok = ((wuffs_base__token_buffer){
@@ -74,6 +74,7 @@ const uint32_t pixfmts[] = {
     WUFFS_BASE__PIXEL_FORMAT__BGRX,
     WUFFS_BASE__PIXEL_FORMAT__RGB,
     WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL,
+    WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL_4XF32LE,
     WUFFS_BASE__PIXEL_FORMAT__RGBA_PREMUL,
 };
 
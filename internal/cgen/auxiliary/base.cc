@@ -34,6 +34,16 @@ Input::BringsItsOwnIOBuffer() {
   return nullptr;
 }
 
+std::string  //
+Input::SeekIn(IOBuffer* dst, uint64_t pos) {
+  if (!dst) {
+    return "wuffs_aux::sync_io::Input: nullptr IOBuffer";
+  } else if (!dst->reader_seek(pos)) {
+    return "wuffs_aux::sync_io::Input: unsupported seek";
+  }
+  return "";
+}
+
 // --------
 
 FileInput::FileInput(FILE* f) : m_f(f) {}
@@ -58,6 +68,25 @@ FileInput::CopyIn(IOBuffer* dst) {
   return "";
 }
 
+std::string  //
+FileInput::SeekIn(IOBuffer* dst, uint64_t pos) {
+  if (!m_f) {
+    return "wuffs_aux::sync_io::FileInput: nullptr file";
+  } else if (!dst) {
+    return "wuffs_aux::sync_io::FileInput: nullptr IOBuffer";
+  } else if (dst->reader_seek(pos)) {
+    return "";
+  }
+  // fseek takes a long, not a uint64_t.
+  long offset = static_cast<long>(pos);
+  if ((offset < 0) || (static_cast<uint64_t>(offset) != pos)) {
+    return "wuffs_aux::sync_io::FileInput: seek position out of range";
+  } else if (fseek(m_f, offset, SEEK_SET) != 0) {
+    return "wuffs_aux::sync_io::FileInput: error seeking file";
+  }
+  return "";
+}
+
 // --------
 
 MemoryInput::MemoryInput(const char* ptr, size_t len)
@@ -97,6 +126,19 @@ MemoryInput::CopyIn(IOBuffer* dst) {
   return "";
 }
 
+std::string  //
+MemoryInput::SeekIn(IOBuffer* dst, uint64_t pos) {
+  if (!dst) {
+    return "wuffs_aux::sync_io::MemoryInput: nullptr IOBuffer";
+  } else if (pos > m_io.meta.wi) {
+    return "wuffs_aux::sync_io::MemoryInput: seek position out of range";
+  } else if (!dst->reader_seek(pos)) {
+    // dst is not m_io, as m_io's sliding window covers all of the memory.
+    m_io.meta.ri = static_cast<size_t>(pos);
+  }
+  return "";
+}
+
 // --------
 
 }  // namespace sync_io
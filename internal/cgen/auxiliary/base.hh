@@ -44,6 +44,12 @@ class Input {
 
   virtual IOBuffer* BringsItsOwnIOBuffer();
   virtual std::string CopyIn(IOBuffer* dst) = 0;
+
+  // SeekIn sets dst's reader_position to pos, so that subsequent CopyIn calls
+  // continue from there. It is typically called after a decoder suspends with
+  // "$mispositioned read", and the default implementation only succeeds if pos
+  // is within dst's sliding window. See wuffs_base__io_buffer__reader_seek.
+  virtual std::string SeekIn(IOBuffer* dst, uint64_t pos);
 };
 
 // --------
@@ -56,6 +62,7 @@ class FileInput : public Input {
   FileInput(FILE* f);
 
   virtual std::string CopyIn(IOBuffer* dst);
+  virtual std::string SeekIn(IOBuffer* dst, uint64_t pos);
 
  private:
   FILE* m_f;
@@ -77,6 +84,7 @@ class MemoryInput : public Input {
 
   virtual IOBuffer* BringsItsOwnIOBuffer();
   virtual std::string CopyIn(IOBuffer* dst);
+  virtual std::string SeekIn(IOBuffer* dst, uint64_t pos);
 
  private:
   IOBuffer m_io;
@@ -67,16 +67,41 @@ DecodeImageCallbacks::SelectDecoder(uint32_t fourcc,
       return wuffs_bmp__decoder::alloc_as__wuffs_base__image_decoder();
 #endif
 
+#if !defined(WUFFS_CONFIG__MODULES) || defined(WUFFS_CONFIG__MODULE__ICO)
+    case WUFFS_BASE__FOURCC__CUR:
+      return wuffs_ico__decoder::alloc_as__wuffs_base__image_decoder();
+#endif
+
+#if !defined(WUFFS_CONFIG__MODULES) || defined(WUFFS_CONFIG__MODULE__EXR)
+    case WUFFS_BASE__FOURCC__EXR:
+      return wuffs_exr__decoder::alloc_as__wuffs_base__image_decoder();
+#endif
+
 #if !defined(WUFFS_CONFIG__MODULES) || defined(WUFFS_CONFIG__MODULE__GIF)
     case WUFFS_BASE__FOURCC__GIF:
       return wuffs_gif__decoder::alloc_as__wuffs_base__image_decoder();
 #endif
 
+#if !defined(WUFFS_CONFIG__MODULES) || defined(WUFFS_CONFIG__MODULE__HDR)
+    case WUFFS_BASE__FOURCC__HDR:
+      return wuffs_hdr__decoder::alloc_as__wuffs_base__image_decoder();
+#endif
+
+#if !defined(WUFFS_CONFIG__MODULES) || defined(WUFFS_CONFIG__MODULE__ICO)
+    case WUFFS_BASE__FOURCC__ICO:
+      return wuffs_ico__decoder::alloc_as__wuffs_base__image_decoder();
+#endif
+
 #if !defined(WUFFS_CONFIG__MODULES) || defined(WUFFS_CONFIG__MODULE__NIE)
     case WUFFS_BASE__FOURCC__NIE:
       return wuffs_nie__decoder::alloc_as__wuffs_base__image_decoder();
 #endif
 
+#if !defined(WUFFS_CONFIG__MODULES) || defined(WUFFS_CONFIG__MODULE__NETPBM)
+    case WUFFS_BASE__FOURCC__PNM:
+      return wuffs_netpbm__decoder::alloc_as__wuffs_base__image_decoder();
+#endif
+
 #if !defined(WUFFS_CONFIG__MODULES) || defined(WUFFS_CONFIG__MODULE__PNG)
     case WUFFS_BASE__FOURCC__PNG: {
       auto dec = wuffs_png__decoder::alloc_as__wuffs_base__image_decoder();
@@ -318,6 +343,7 @@ redirect:
       case WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL_4X16LE:
       case WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL:
       case WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL:
+      case WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL_4XF32LE:
       case WUFFS_BASE__PIXEL_FORMAT__RGBA_PREMUL:
         break;
       default:
@@ -85,9 +85,14 @@ class DecodeImageCallbacks {
   // the corresponding module to be enabled at compile time (i.e. #define'ing
   // WUFFS_CONFIG__MODULE__ETC).
   //  - WUFFS_BASE__FOURCC__BMP
+  //  - WUFFS_BASE__FOURCC__CUR
+  //  - WUFFS_BASE__FOURCC__EXR
   //  - WUFFS_BASE__FOURCC__GIF
+  //  - WUFFS_BASE__FOURCC__HDR
+  //  - WUFFS_BASE__FOURCC__ICO
   //  - WUFFS_BASE__FOURCC__NIE
   //  - WUFFS_BASE__FOURCC__PNG
+  //  - WUFFS_BASE__FOURCC__PNM
   //  - WUFFS_BASE__FOURCC__WBMP
   virtual wuffs_base__image_decoder::unique_ptr  //
   SelectDecoder(uint32_t fourcc, wuffs_base__slice_u8 prefix);
@@ -163,7 +163,8 @@ DecodeJson_WalkJsonPointerFragment(wuffs_base__token_buffer& tok_buf,
 
     int64_t vbc = token.value_base_category();
     uint64_t vbd = token.value_base_detail();
-    if (vbc == WUFFS_BASE__TOKEN__VBC__FILLER) {
+    if ((vbc == WUFFS_BASE__TOKEN__VBC__FILLER) ||
+        (vbc == WUFFS_BASE__TOKEN__VBC__COMMENT)) {
       continue;
     } else if ((vbc != WUFFS_BASE__TOKEN__VBC__STRUCTURE) ||
                !(vbd & WUFFS_BASE__TOKEN__VBD__STRUCTURE__PUSH)) {
@@ -188,6 +189,7 @@ do_dict:
       uint64_t vbd = token.value_base_detail();
       switch (vbc) {
         case WUFFS_BASE__TOKEN__VBC__FILLER:
+        case WUFFS_BASE__TOKEN__VBC__COMMENT:
           continue;
 
         case WUFFS_BASE__TOKEN__VBC__STRUCTURE:
@@ -241,7 +243,8 @@ do_dict:
 
       int64_t vbc = token.value_base_category();
       uint64_t vbd = token.value_base_detail();
-      if (token.continued() || (vbc == WUFFS_BASE__TOKEN__VBC__FILLER)) {
+      if (token.continued() || (vbc == WUFFS_BASE__TOKEN__VBC__FILLER) ||
+          (vbc == WUFFS_BASE__TOKEN__VBC__COMMENT)) {
         continue;
       } else if (vbc == WUFFS_BASE__TOKEN__VBC__STRUCTURE) {
         if (vbd & WUFFS_BASE__TOKEN__VBD__STRUCTURE__PUSH) {
@@ -277,7 +280,8 @@ do_list:
 
       int64_t vbc = token.value_base_category();
       uint64_t vbd = token.value_base_detail();
-      if (token.continued() || (vbc == WUFFS_BASE__TOKEN__VBC__FILLER)) {
+      if (token.continued() || (vbc == WUFFS_BASE__TOKEN__VBC__FILLER) ||
+          (vbc == WUFFS_BASE__TOKEN__VBC__COMMENT)) {
         continue;
       } else if (vbc == WUFFS_BASE__TOKEN__VBC__STRUCTURE) {
         if (vbd & WUFFS_BASE__TOKEN__VBD__STRUCTURE__PUSH) {
@@ -306,7 +310,8 @@ check_that_a_value_follows:
 
     int64_t vbc = token.value_base_category();
     uint64_t vbd = token.value_base_detail();
-    if (vbc == WUFFS_BASE__TOKEN__VBC__FILLER) {
+    if ((vbc == WUFFS_BASE__TOKEN__VBC__FILLER) ||
+        (vbc == WUFFS_BASE__TOKEN__VBC__COMMENT)) {
       continue;
     }
 
@@ -410,6 +415,7 @@ DecodeJson(DecodeJsonCallbacks& callbacks,
       uint64_t vbd = token.value_base_detail();
       switch (vbc) {
         case WUFFS_BASE__TOKEN__VBC__FILLER:
+        case WUFFS_BASE__TOKEN__VBC__COMMENT:
           continue;
 
         case WUFFS_BASE__TOKEN__VBC__STRUCTURE: {
@@ -0,0 +1,618 @@
+// After editing this file, run "go generate" in the ../data directory.
+
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ---------------- Auxiliary - RAC
+
+#if !defined(WUFFS_CONFIG__MODULES) || defined(WUFFS_CONFIG__MODULE__AUX__RAC)
+
+namespace wuffs_aux {
+
+namespace sync_io {
+
+namespace {
+
+// Like the Go lib/rac package's Codec type, a Short Codec is represented by
+// its Codec Byte's low 6 bits, shifted left by 56. A Long Codec is represented
+// by its 7 byte Codec Element and the high bit. Both exclude the Mix Bit.
+const uint64_t RacCodecZeroes = 0x0000000000000000;
+const uint64_t RacCodecZlib = 0x0100000000000000;
+const uint64_t RacCodecInvalid = 0xFFFFFFFFFFFFFFFF;
+
+const size_t RacSrcArrayLength = 32768;
+
+// RacBranch is a RAC Branch Node, as described in the "Branch Nodes" section
+// of the RAC specification. Its size in bytes is ((arity * 16) + 16).
+struct RacBranch {
+  uint8_t buf[4096];
+  uint32_t arity;
+  uint64_t coffset;
+  uint64_t cbias;
+  uint64_t dbias;
+
+  static uint64_t peek_u48le(const uint8_t* p) {
+    return wuffs_base__peek_u64le__no_bounds_check(p) & 0xFFFFFFFFFFFF;
+  }
+
+  uint8_t ttag(uint32_t i) const { return buf[(8 * i) + 7]; }
+  uint8_t stag(uint32_t i) const { return buf[(8 * (arity + 1 + i)) + 7]; }
+  uint8_t clen(uint32_t i) const { return buf[(8 * (arity + 1 + i)) + 6]; }
+  uint8_t codec_byte() const { return buf[(8 * arity) + 7]; }
+  uint8_t version() const { return buf[(16 * arity) + 14]; }
+  bool mix_bit() const { return (codec_byte() & 0x40) != 0; }
+
+  uint64_t cptr(uint32_t i) const {
+    return peek_u48le(&buf[8 * (arity + 1 + i)]);
+  }
+  uint64_t dptr(uint32_t i) const {
+    return (i == 0) ? 0 : peek_u48le(&buf[8 * i]);
+  }
+
+  uint64_t coff(uint32_t i) const { return cbias + cptr(i); }
+  uint64_t doff(uint32_t i) const { return dbias + dptr(i); }
+  uint64_t coffmax() const { return coff(arity); }
+  uint64_t dptrmax() const { return dptr(arity); }
+
+  // make_crange implements the spec's MakeCRange(i) function, setting *cmin
+  // and *cmax to the half-open range [*cmin .. *cmax).
+  void make_crange(uint32_t i, uint64_t* cmin, uint64_t* cmax) const {
+    uint64_t m = coffmax();
+    if (i >= arity) {
+      *cmin = m;
+      *cmax = m;
+      return;
+    }
+    uint64_t c = coff(i);
+    if (clen(i) != 0) {
+      uint64_t n = c + (static_cast<uint64_t>(clen(i)) * 1024);
+      if (m > n) {
+        m = n;
+      }
+    }
+    *cmin = (c < m) ? c : m;
+    *cmax = m;
+  }
+
+  uint64_t codec() const {
+    uint8_t c = codec_byte();
+    if ((c & 0x80) == 0) {
+      return static_cast<uint64_t>(c & 0x3F) << 56;
+    }
+    c &= 0x3F;
+    for (uint32_t j = 0; j < 4; j++) {
+      uint32_t i = c | (j << 6);
+      if ((i < arity) && (ttag(i) == 0xFD)) {
+        uint64_t u = wuffs_base__peek_u64le__no_bounds_check(
+            &buf[8 * (arity + 1 + i)]);
+        return (u & 0x00FFFFFFFFFFFFFF) | 0x8000000000000000;
+      }
+    }
+    return RacCodecInvalid;
+  }
+
+  // validate implements the "Branch Node Validation" section of the RAC
+  // specification, other than the checks that involve the parent node.
+  bool validate() const {
+    if ((arity == 0) || (buf[0] != 0x72) || (buf[1] != 0xC3) ||
+        (buf[2] != 0x63) || (buf[3] != arity) ||
+        (buf[(16 * arity) + 15] != arity) || (version() != 0x01)) {
+      return false;
+    }
+
+    wuffs_crc32__ieee_hasher h;
+    if (!h.initialize(sizeof__wuffs_crc32__ieee_hasher(), WUFFS_VERSION,
+                      WUFFS_INITIALIZE__DEFAULT_OPTIONS)
+             .is_ok()) {
+      return false;
+    }
+    uint32_t checksum = h.update_u32(wuffs_base__make_slice_u8(
+        const_cast<uint8_t*>(&buf[6]), (16 * arity) + 10));
+    if (((checksum ^ (checksum >> 16)) & 0xFFFF) !=
+        wuffs_base__peek_u16le__no_bounds_check(&buf[4])) {
+      return false;
+    }
+
+    for (uint32_t i = 0; i <= arity; i++) {
+      if (buf[(8 * i) + 6] != 0) {
+        return false;
+      } else if ((i > 0) && (dptr(i - 1) > dptr(i))) {
+        return false;
+      }
+    }
+
+    bool has_child = false;
+    for (uint32_t i = 0; i < arity; i++) {
+      uint8_t t = ttag(i);
+      if (t == 0xFD) {
+        continue;
+      } else if ((0xC0 <= t) && (t < 0xFD)) {
+        return false;
+      } else if (coff(i) > coffmax()) {
+        return false;
+      }
+      has_child = true;
+    }
+    return has_child && (codec() != RacCodecInvalid);
+  }
+};
+
+}  // namespace
+
+// --------
+
+RacInput::RacInput(FILE* f, uint64_t cfile_size)
+    : RacInput(f, nullptr, cfile_size) {}
+
+RacInput::RacInput(const char* ptr, size_t len)
+    : RacInput(nullptr,
+               static_cast<const uint8_t*>(static_cast<const void*>(ptr)),
+               len) {}
+
+RacInput::RacInput(const uint8_t* ptr, size_t len)
+    : RacInput(nullptr, ptr, len) {}
+
+RacInput::RacInput(FILE* f, const uint8_t* ptr, uint64_t cfile_size)
+    : m_f(f),
+      m_ptr(ptr),
+      m_cfile_size(cfile_size),
+      m_found_root(false),
+      m_root_coffset(0),
+      m_dfile_size(0),
+      m_dpos(0),
+      m_chunk_codec(RacCodecInvalid),
+      m_chunk_dmin(0),
+      m_chunk_dmax(0),
+      m_chunk_dpos(0),
+      m_chunk_primary_cmin(0),
+      m_chunk_primary_cmax(0),
+      m_chunk_primary_cpos(0),
+      m_chunk_secondary_cmin(0),
+      m_chunk_secondary_cmax(0),
+      m_chunk_ttag(0),
+      m_chunk_done(false),
+      m_have_dict(false),
+      m_dict_cpos(0),
+      m_zlib_decoder(nullptr, &free),
+      m_src_array(nullptr),
+      m_src(wuffs_base__empty_io_buffer()) {}
+
+RacInput::~RacInput() {}
+
+std::string  //
+RacInput::CopyIn(IOBuffer* dst) {
+  if (!dst) {
+    return "wuffs_aux::sync_io::RacInput: nullptr IOBuffer";
+  } else if (dst->meta.closed) {
+    return "wuffs_aux::sync_io::RacInput: end of file";
+  }
+  std::string err = FindRoot();
+  if (!err.empty()) {
+    return err;
+  }
+
+  dst->compact();
+  while (true) {
+    // Having decoded a chunk's DRange, check that the codec agrees that that
+    // is the end of the chunk.
+    if ((m_chunk_dmin < m_chunk_dmax) && (m_chunk_dpos == m_chunk_dmax) &&
+        (m_dpos == m_chunk_dmax) && !m_chunk_done) {
+      uint8_t extra[1];
+      size_t n = 0;
+      err = DecodeChunk(&extra[0], 1, &n);
+      if (!err.empty()) {
+        return err;
+      } else if (n > 0) {
+        return "wuffs_aux::sync_io::RacInput: invalid chunk (too large)";
+      }
+    }
+
+    if (m_dpos >= m_dfile_size) {
+      dst->meta.closed = true;
+      break;
+    }
+    size_t dst_len = dst->writer_length();
+    if (dst_len == 0) {
+      break;
+    }
+
+    if ((m_chunk_dmin >= m_chunk_dmax) || (m_dpos < m_chunk_dpos) ||
+        (m_dpos >= m_chunk_dmax)) {
+      err = FindChunk(m_dpos);
+      if (err.empty()) {
+        err = StartChunk();
+      }
+      if (!err.empty()) {
+        m_chunk_dmin = 0;
+        m_chunk_dmax = 0;
+        return err;
+      }
+    }
+
+    // If seeking to the middle of a chunk, decode (and discard) that chunk's
+    // opening bytes. The dst buffer's writer space is used as scratch space.
+    bool discard = m_chunk_dpos < m_dpos;
+    uint64_t remaining = discard ? (m_dpos - m_chunk_dpos)
+                                 : (m_chunk_dmax - m_chunk_dpos);
+    if (dst_len > remaining) {
+      dst_len = static_cast<size_t>(remaining);
+    }
+
+    size_t n = 0;
+    err = DecodeChunk(dst->writer_pointer(), dst_len, &n);
+    if (!err.empty()) {
+      return err;
+    } else if ((n < dst_len) && m_chunk_done) {
+      // The codec produced fewer bytes than the DRange size. The remaining
+      // bytes are NUL.
+      memset(dst->writer_pointer() + n, 0, dst_len - n);
+      n = dst_len;
+    }
+
+    m_chunk_dpos += n;
+    if (!discard) {
+      dst->meta.wi += n;
+      m_dpos += n;
+    }
+  }
+  return "";
+}
+
+std::string  //
+RacInput::DecompressedSize(uint64_t* dst) {
+  if (!dst) {
+    return "wuffs_aux::sync_io::RacInput: nullptr uint64_t";
+  }
+  std::string err = FindRoot();
+  if (err.empty()) {
+    *dst = m_dfile_size;
+  }
+  return err;
+}
+
+uint64_t  //
+RacInput::Position() const {
+  return m_dpos;
+}
+
+std::string  //
+RacInput::Seek(uint64_t pos) {
+  std::string err = FindRoot();
+  if (!err.empty()) {
+    return err;
+  } else if (pos > m_dfile_size) {
+    return "wuffs_aux::sync_io::RacInput: seek position out of bounds";
+  }
+  m_dpos = pos;
+  return "";
+}
+
+std::string  //
+RacInput::ReadAt(uint8_t* dst_ptr, size_t dst_len, uint64_t cpos) {
+  if (dst_len == 0) {
+    return "";
+  } else if ((cpos > m_cfile_size) || (dst_len > (m_cfile_size - cpos))) {
+    return "wuffs_aux::sync_io::RacInput: invalid CFile offset";
+  } else if (m_ptr) {
+    memcpy(dst_ptr, m_ptr + cpos, dst_len);
+    return "";
+  } else if (!m_f) {
+    return "wuffs_aux::sync_io::RacInput: nullptr file";
+  }
+
+  long offset = static_cast<long>(cpos);
+  if ((offset < 0) || (static_cast<uint64_t>(offset) != cpos)) {
+    return "wuffs_aux::sync_io::RacInput: file offset too large";
+  } else if (fseek(m_f, offset, SEEK_SET) != 0) {
+    return "wuffs_aux::sync_io::RacInput: error seeking file";
+  } else if (fread(dst_ptr, 1, dst_len, m_f) != dst_len) {
+    if (ferror(m_f)) {
+      return "wuffs_aux::sync_io::RacInput: error reading file";
+    }
+    return "wuffs_aux::sync_io::RacInput: unexpected end of file";
+  }
+  return "";
+}
+
+std::string  //
+RacInput::ReadBranch(uint8_t* dst_ptr,
+                     uint64_t coffset,
+                     uint64_t cremaining,
+                     uint32_t* arity) {
+  if (cremaining < 4) {
+    return "wuffs_aux::sync_io::RacInput: invalid branch node";
+  }
+  std::string err = ReadAt(dst_ptr, 4, coffset);
+  if (!err.empty()) {
+    return err;
+  }
+  *arity = dst_ptr[3];
+  uint64_t size = (16 * static_cast<uint64_t>(*arity)) + 16;
+  if (size > cremaining) {
+    return "wuffs_aux::sync_io::RacInput: invalid branch node";
+  }
+  return ReadAt(dst_ptr, static_cast<size_t>(size), coffset);
+}
+
+std::string  //
+RacInput::FindRoot() {
+  if (m_found_root) {
+    return "";
+  } else if (m_cfile_size < 32) {
+    return "wuffs_aux::sync_io::RacInput: invalid RAC file";
+  }
+
+  RacBranch b;
+  b.cbias = 0;
+  b.dbias = 0;
+
+  // Look for a Root Node at the CFile start.
+  b.coffset = 0;
+  std::string err = ReadBranch(&b.buf[0], 0, m_cfile_size, &b.arity);
+  if (err.empty() && b.validate() && (b.coffmax() == m_cfile_size)) {
+    m_found_root = true;
+    m_root_coffset = b.coffset;
+    m_dfile_size = b.dptrmax();
+    return "";
+  }
+
+  // Otherwise, look at the CFile end.
+  uint8_t last = 0;
+  err = ReadAt(&last, 1, m_cfile_size - 1);
+  if (!err.empty()) {
+    return err;
+  }
+  uint64_t size = (16 * static_cast<uint64_t>(last)) + 16;
+  if (size > m_cfile_size) {
+    return "wuffs_aux::sync_io::RacInput: invalid RAC file";
+  }
+  b.coffset = m_cfile_size - size;
+  b.arity = last;
+  err = ReadAt(&b.buf[0], static_cast<size_t>(size), b.coffset);
+  if (!err.empty()) {
+    return err;
+  } else if (!b.validate() || (b.coffmax() != m_cfile_size)) {
+    return "wuffs_aux::sync_io::RacInput: invalid RAC file";
+  }
+  m_found_root = true;
+  m_root_coffset = b.coffset;
+  m_dfile_size = b.dptrmax();
+  return "";
+}
+
+std::string  //
+RacInput::FindChunk(uint64_t dpos) {
+  RacBranch b;
+  b.coffset = m_root_coffset;
+  b.cbias = 0;
+  b.dbias = 0;
+  std::string err = ReadBranch(&b.buf[0], b.coffset,
+                               m_cfile_size - b.coffset, &b.arity);
+  if (!err.empty()) {
+    return err;
+  } else if (!b.validate() || (b.dptrmax() != m_dfile_size)) {
+    return "wuffs_aux::sync_io::RacInput: invalid branch node";
+  } else if (dpos >= m_dfile_size) {
+    return "wuffs_aux::sync_io::RacInput: internal error: bad dpos";
+  }
+
+  while (true) {
+    // Find the largest a such that (DOff[a] <= dpos). Since the DOff values
+    // are sorted and (dpos < DOffMax), it also satisfies (dpos < DOff[a+1]).
+    uint32_t a = b.arity - 1;
+    while ((a > 0) && (b.doff(a) > dpos)) {
+      a--;
+    }
+
+    uint8_t ttag = b.ttag(a);
+    if (ttag != 0xFE) {
+      m_chunk_codec = b.codec();
+      m_chunk_dmin = b.doff(a);
+      m_chunk_dmax = b.doff(a + 1);
+      b.make_crange(a, &m_chunk_primary_cmin, &m_chunk_primary_cmax);
+      b.make_crange(b.stag(a), &m_chunk_secondary_cmin,
+                    &m_chunk_secondary_cmax);
+      m_chunk_ttag = ttag;
+      return "";
+    }
+
+    // Descend to a child Branch Node, remembering what we need from the
+    // parent to validate the child.
+    uint64_t parent_coffset = b.coffset;
+    uint64_t parent_coffmax = b.coffmax();
+    uint64_t parent_dptrmax = b.dptrmax();
+    uint64_t parent_codec = b.codec();
+    uint8_t parent_version = b.version();
+    bool parent_mix_bit = b.mix_bit();
+    uint64_t sub_dsize = b.doff(a + 1) - b.doff(a);
+    uint8_t stag = b.stag(a);
+
+    b.coffset = b.coff(a);
+    b.dbias = b.doff(a);
+    if (stag < b.arity) {
+      b.cbias = b.coff(stag);
+    }
+    if (b.coffset > parent_coffmax) {
+      return "wuffs_aux::sync_io::RacInput: invalid branch node";
+    }
+    err = ReadBranch(&b.buf[0], b.coffset, parent_coffmax - b.coffset,
+                     &b.arity);
+    if (!err.empty()) {
+      return err;
+    } else if (!b.validate() || (b.coffmax() > parent_coffmax) ||
+               (b.version() > parent_version) ||
+               (b.dptrmax() != sub_dsize) ||
+               (!parent_mix_bit && (b.codec() != parent_codec))) {
+      return "wuffs_aux::sync_io::RacInput: invalid branch node";
+    } else if ((b.coffset >= parent_coffset) &&
+               (b.dptrmax() >= parent_dptrmax)) {
+      return "wuffs_aux::sync_io::RacInput: invalid branch node (loop)";
+    }
+  }
+}
+
+std::string  //
+RacInput::StartChunk() {
+  m_chunk_dpos = m_chunk_dmin;
+  m_chunk_done = false;
+
+  if (m_chunk_codec == RacCodecZeroes) {
+    return "";
+  }
+
+#if !defined(WUFFS_CONFIG__MODULES) || defined(WUFFS_CONFIG__MODULE__ZLIB)
+  if (m_chunk_codec == RacCodecZlib) {
+    if (!m_zlib_decoder) {
+      m_zlib_decoder =
+          MemOwner(malloc(sizeof__wuffs_zlib__decoder()), &free);
+      if (!m_zlib_decoder) {
+        return "wuffs_aux::sync_io::RacInput: out of memory";
+      }
+    }
+    wuffs_base__status status = wuffs_zlib__decoder__initialize(
+        static_cast<wuffs_zlib__decoder*>(m_zlib_decoder.get()),
+        sizeof__wuffs_zlib__decoder(), WUFFS_VERSION,
+        WUFFS_INITIALIZE__DEFAULT_OPTIONS);
+    if (!status.is_ok()) {
+      return status.message();
+    }
+
+    if (!m_src_array) {
+      m_src_array = std::unique_ptr<uint8_t[]>(new uint8_t[RacSrcArrayLength]);
+    }
+    m_src = wuffs_base__ptr_u8__writer(m_src_array.get(), RacSrcArrayLength);
+    m_chunk_primary_cpos = m_chunk_primary_cmin;
+    m_src.meta.closed = m_chunk_primary_cpos >= m_chunk_primary_cmax;
+    return "";
+  }
+#endif
+
+  return "wuffs_aux::sync_io::RacInput: unsupported codec";
+}
+
+std::string  //
+RacInput::LoadDictionary() {
+  uint64_t cmin = m_chunk_secondary_cmin;
+  uint64_t cmax = m_chunk_secondary_cmax;
+  if (m_have_dict && (m_dict_cpos == cmin)) {
+    return "";
+  } else if ((m_chunk_ttag != 0xFF) || (cmin >= cmax) || ((cmax - cmin) < 8)) {
+    return "wuffs_aux::sync_io::RacInput: invalid dictionary";
+  }
+
+  uint8_t u32[4];
+  std::string err = ReadAt(&u32[0], 4, cmin);
+  if (!err.empty()) {
+    return err;
+  }
+  uint32_t dict_len = wuffs_base__peek_u32le__no_bounds_check(&u32[0]);
+  if (((dict_len >> 30) != 0) || (dict_len > ((cmax - cmin) - 8))) {
+    return "wuffs_aux::sync_io::RacInput: invalid dictionary";
+  }
+
+  m_have_dict = false;
+  m_dict.resize(dict_len);
+  uint8_t* dict_ptr =
+      static_cast<uint8_t*>(static_cast<void*>(const_cast<char*>(m_dict.data())));
+  err = ReadAt(dict_ptr, dict_len, cmin + 4);
+  if (err.empty()) {
+    err = ReadAt(&u32[0], 4, cmin + 4 + dict_len);
+  }
+  if (!err.empty()) {
+    return err;
+  }
+
+  wuffs_crc32__ieee_hasher h;
+  wuffs_base__status status =
+      h.initialize(sizeof__wuffs_crc32__ieee_hasher(), WUFFS_VERSION,
+                   WUFFS_INITIALIZE__DEFAULT_OPTIONS);
+  if (!status.is_ok()) {
+    return status.message();
+  } else if (h.update_u32(wuffs_base__make_slice_u8(dict_ptr, dict_len)) !=
+             wuffs_base__peek_u32le__no_bounds_check(&u32[0])) {
+    return "wuffs_aux::sync_io::RacInput: invalid dictionary (bad checksum)";
+  }
+
+  m_have_dict = true;
+  m_dict_cpos = cmin;
+  return "";
+}
+
+std::string  //
+RacInput::DecodeChunk(uint8_t* dst_ptr, size_t dst_len, size_t* n) {
+  *n = 0;
+  if (m_chunk_done || (m_chunk_codec == RacCodecZeroes)) {
+    m_chunk_done = true;
+    return "";
+  }
+
+#if !defined(WUFFS_CONFIG__MODULES) || defined(WUFFS_CONFIG__MODULE__ZLIB)
+  if (m_chunk_codec == RacCodecZlib) {
+    wuffs_zlib__decoder* dec =
+        static_cast<wuffs_zlib__decoder*>(m_zlib_decoder.get());
+    IOBuffer dst = wuffs_base__ptr_u8__writer(dst_ptr, dst_len);
+    while (true) {
+      wuffs_base__status status = dec->transform_io(
+          &dst, &m_src, wuffs_base__make_slice_u8(&m_workbuf[0], 1));
+      *n = dst.meta.wi;
+
+      if (status.repr == nullptr) {
+        m_chunk_done = true;
+        return "";
+      } else if (status.repr == wuffs_base__suspension__short_write) {
+        return "";
+      } else if (status.repr == wuffs_base__suspension__short_read) {
+        if (m_src.meta.closed) {
+          return "wuffs_aux::sync_io::RacInput: invalid chunk (truncated)";
+        }
+        m_src.compact();
+        uint64_t k = m_chunk_primary_cmax - m_chunk_primary_cpos;
+        if (k > m_src.writer_length()) {
+          k = m_src.writer_length();
+        }
+        std::string err = ReadAt(m_src.writer_pointer(),
+                                 static_cast<size_t>(k), m_chunk_primary_cpos);
+        if (!err.empty()) {
+          return err;
+        }
+        m_src.meta.wi += k;
+        m_chunk_primary_cpos += k;
+        m_src.meta.closed = m_chunk_primary_cpos >= m_chunk_primary_cmax;
+      } else if (status.repr == wuffs_zlib__note__dictionary_required) {
+        std::string err = LoadDictionary();
+        if (!err.empty()) {
+          return err;
+        }
+        dec->add_dictionary(wuffs_base__make_slice_u8(
+            static_cast<uint8_t*>(
+                static_cast<void*>(const_cast<char*>(m_dict.data()))),
+            m_dict.size()));
+      } else {
+        return status.message();
+      }
+    }
+  }
+#endif
+
+  return "wuffs_aux::sync_io::RacInput: unsupported codec";
+}
+
+// --------
+
+}  // namespace sync_io
+
+}  // namespace wuffs_aux
+
+#endif  // !defined(WUFFS_CONFIG__MODULES) ||
+        // defined(WUFFS_CONFIG__MODULE__AUX__RAC)
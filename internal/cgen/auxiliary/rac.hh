@@ -0,0 +1,118 @@
+// After editing this file, run "go generate" in the ../data directory.
+
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ---------------- Auxiliary - RAC
+
+namespace wuffs_aux {
+
+namespace sync_io {
+
+// --------
+
+// RacInput is an Input that reads the decompressed contents (the DFile) of a
+// RAC (Random Access Compression) file, given its compressed contents (the
+// CFile). The RAC specification is at
+// https://github.com/google/wuffs/blob/main/doc/spec/rac-spec.md
+//
+// Unlike other Inputs, it is seekable. Seek sets the DFile position that the
+// next CopyIn call reads from. Only those RAC chunks that overlap the bytes
+// being read are decompressed, so that a program can cheaply read a small part
+// of a very large RAC file.
+//
+// It supports the "RAC + Zeroes" and "RAC + Zlib" codecs. The latter requires
+// the ADLER32, DEFLATE and ZLIB modules. Validating the RAC file's branch nodes
+// always requires the CRC32 module.
+//
+// It does not take responsibility for closing the file or freeing the memory
+// when done.
+class RacInput : public Input {
+ public:
+  // The FILE* constructor's f must be seekable and cfile_size bytes long.
+  RacInput(FILE* f, uint64_t cfile_size);
+  RacInput(const char* ptr, size_t len);
+  RacInput(const uint8_t* ptr, size_t len);
+  virtual ~RacInput();
+
+  virtual std::string CopyIn(IOBuffer* dst);
+
+  // DecompressedSize sets *dst to the DFile size.
+  std::string DecompressedSize(uint64_t* dst);
+
+  // Position returns the DFile position that the next CopyIn call reads from.
+  uint64_t Position() const;
+
+  // Seek sets the DFile position. It is valid to seek to (but not beyond) the
+  // end of the DFile.
+  std::string Seek(uint64_t pos);
+
+ private:
+  RacInput(FILE* f, const uint8_t* ptr, uint64_t cfile_size);
+
+  std::string ReadAt(uint8_t* dst_ptr, size_t dst_len, uint64_t cpos);
+  std::string ReadBranch(uint8_t* dst_ptr,
+                         uint64_t coffset,
+                         uint64_t cremaining,
+                         uint32_t* arity);
+  std::string FindRoot();
+  std::string FindChunk(uint64_t dpos);
+  std::string StartChunk();
+  std::string LoadDictionary();
+  std::string DecodeChunk(uint8_t* dst_ptr, size_t dst_len, size_t* n);
+
+  FILE* m_f;
+  const uint8_t* m_ptr;
+  uint64_t m_cfile_size;
+
+  bool m_found_root;
+  uint64_t m_root_coffset;
+  uint64_t m_dfile_size;
+  uint64_t m_dpos;
+
+  // The current chunk is valid when m_chunk_dmax > m_chunk_dmin.
+  uint64_t m_chunk_codec;
+  uint64_t m_chunk_dmin;
+  uint64_t m_chunk_dmax;
+  uint64_t m_chunk_dpos;
+  uint64_t m_chunk_primary_cmin;
+  uint64_t m_chunk_primary_cmax;
+  uint64_t m_chunk_primary_cpos;
+  uint64_t m_chunk_secondary_cmin;
+  uint64_t m_chunk_secondary_cmax;
+  uint8_t m_chunk_ttag;
+  bool m_chunk_done;
+
+  // The dictionary is cached, keyed by its CFile position.
+  bool m_have_dict;
+  uint64_t m_dict_cpos;
+  std::string m_dict;
+
+  MemOwner m_zlib_decoder;
+  std::unique_ptr<uint8_t[]> m_src_array;
+  IOBuffer m_src;
+
+  // The Zlib decoder's work buffer length is at most 1.
+  uint8_t m_workbuf[1];
+
+  // Delete the copy and assign constructors.
+  RacInput(const RacInput&) = delete;
+  RacInput& operator=(const RacInput&) = delete;
+};
+
+// --------
+
+}  // namespace sync_io
+
+}  // namespace wuffs_aux
@@ -25,6 +25,7 @@
 // ¡ WUFFS MONOLITHIC RELEASE DISCARDS EVERYTHING ABOVE.
 
 // ¡ INSERT base/copyright
+// ¡ INSERT generation flags.
 
 #include <stdbool.h>
 #include <stdint.h>
@@ -37,6 +38,18 @@
 #include <memory>
 #define WUFFS_BASE__HAVE_EQ_DELETE
 #define WUFFS_BASE__HAVE_UNIQUE_PTR
+// The "wuffs gen -cppwrappers" classes have std::span and std::string_view
+// overloads, when available.
+#if defined(WUFFS_BASE__CPP_WRAPPERS) && defined(__has_include)
+#if (__cplusplus >= 201703L) && __has_include(<string_view>)
+#include <string_view>
+#define WUFFS_BASE__HAVE_STRING_VIEW
+#endif
+#if (__cplusplus >= 202002L) && __has_include(<span>)
+#include <span>
+#define WUFFS_BASE__HAVE_SPAN
+#endif
+#endif  // defined(WUFFS_BASE__CPP_WRAPPERS) && defined(__has_include)
 #elif defined(__GNUC__)
 #warning "Wuffs' C++ code expects -std=c++11 or later"
 #endif
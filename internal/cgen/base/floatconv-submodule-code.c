@@ -1242,7 +1242,7 @@ wuffs_base__private_implementation__parse_number_f64_special(
       goto fail;
     }
     wuffs_base__result_f64 ret;
-    ret.status.repr = NULL;
+    ret.status = wuffs_base__make_status(NULL);
     ret.value = wuffs_base__ieee_754_bit_representation__from_u64_to_f64(
         (nan ? 0x7FFFFFFFFFFFFFFF : 0x7FF0000000000000) |
         (negative ? 0x8000000000000000 : 0));
@@ -1252,13 +1252,13 @@ wuffs_base__private_implementation__parse_number_f64_special(
 fail:
   do {
     wuffs_base__result_f64 ret;
-    ret.status.repr = wuffs_base__error__bad_argument;
+    ret.status = wuffs_base__make_status(wuffs_base__error__bad_argument);
     ret.value = 0;
     return ret;
   } while (0);
 }
 
-WUFFS_BASE__MAYBE_STATIC wuffs_base__result_f64  //
+static wuffs_base__result_f64  //
 wuffs_base__private_implementation__high_prec_dec__to_f64(
     wuffs_base__private_implementation__high_prec_dec* h,
     uint32_t options) {
@@ -1298,7 +1298,7 @@ wuffs_base__private_implementation__high_prec_dec__to_f64(
                 man, exp10);
         if (r >= 0) {
           wuffs_base__result_f64 ret;
-          ret.status.repr = NULL;
+          ret.status = wuffs_base__make_status(NULL);
           ret.value = wuffs_base__ieee_754_bit_representation__from_u64_to_f64(
               ((uint64_t)r) | (((uint64_t)(h->negative)) << 63));
           return ret;
@@ -1396,7 +1396,7 @@ wuffs_base__private_implementation__high_prec_dec__to_f64(
                     (h->negative ? 0x8000000000000000 : 0);  // (1 << 63).
 
     wuffs_base__result_f64 ret;
-    ret.status.repr = NULL;
+    ret.status = wuffs_base__make_status(NULL);
     ret.value = wuffs_base__ieee_754_bit_representation__from_u64_to_f64(bits);
     return ret;
   } while (0);
@@ -1406,7 +1406,7 @@ zero:
     uint64_t bits = h->negative ? 0x8000000000000000 : 0;
 
     wuffs_base__result_f64 ret;
-    ret.status.repr = NULL;
+    ret.status = wuffs_base__make_status(NULL);
     ret.value = wuffs_base__ieee_754_bit_representation__from_u64_to_f64(bits);
     return ret;
   } while (0);
@@ -1415,7 +1415,7 @@ infinity:
   do {
     if (options & WUFFS_BASE__PARSE_NUMBER_FXX__REJECT_INF_AND_NAN) {
       wuffs_base__result_f64 ret;
-      ret.status.repr = wuffs_base__error__bad_argument;
+      ret.status = wuffs_base__make_status(wuffs_base__error__bad_argument);
       ret.value = 0;
       return ret;
     }
@@ -1423,7 +1423,7 @@ infinity:
     uint64_t bits = h->negative ? 0xFFF0000000000000 : 0x7FF0000000000000;
 
     wuffs_base__result_f64 ret;
-    ret.status.repr = NULL;
+    ret.status = wuffs_base__make_status(NULL);
     ret.value = wuffs_base__ieee_754_bit_representation__from_u64_to_f64(bits);
     return ret;
   } while (0);
@@ -1619,7 +1619,7 @@ wuffs_base__parse_number_f64(wuffs_base__slice_u8 s, uint32_t options) {
         d /= wuffs_base__private_implementation__f64_powers_of_10[-exp10];
       }
       wuffs_base__result_f64 ret;
-      ret.status.repr = NULL;
+      ret.status = wuffs_base__make_status(NULL);
       ret.value = negative ? -d : +d;
       return ret;
     }
@@ -1639,7 +1639,7 @@ wuffs_base__parse_number_f64(wuffs_base__slice_u8 s, uint32_t options) {
       goto fallback;
     }
     wuffs_base__result_f64 ret;
-    ret.status.repr = NULL;
+    ret.status = wuffs_base__make_status(NULL);
     ret.value = wuffs_base__ieee_754_bit_representation__from_u64_to_f64(
         ((uint64_t)r) | (((uint64_t)negative) << 63));
     return ret;
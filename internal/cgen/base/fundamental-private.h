@@ -32,24 +32,72 @@
 // Denote intentional fallthroughs for -Wimplicit-fallthrough.
 //
 // The order matters here. Clang also defines "__GNUC__".
-#if defined(__clang__) && defined(__cplusplus) && (__cplusplus >= 201103L)
+#if defined(WUFFS_BASE__C_DIALECT__C23)
+#define WUFFS_BASE__FALLTHROUGH [[fallthrough]]
+#elif defined(__clang__) && defined(__cplusplus) && (__cplusplus >= 201103L)
 #define WUFFS_BASE__FALLTHROUGH [[clang::fallthrough]]
 #elif !defined(__clang__) && defined(__GNUC__) && (__GNUC__ >= 7)
 #define WUFFS_BASE__FALLTHROUGH __attribute__((fallthrough))
+#elif defined(_MSVC_LANG) && (_MSVC_LANG >= 201703L)
+#define WUFFS_BASE__FALLTHROUGH [[fallthrough]]
 #else
 #define WUFFS_BASE__FALLTHROUGH
 #endif
 
+// WUFFS_BASE__UNREACHABLE marks code that cannot be reached. It is only used
+// in C23 mode. Some C2x compilers (e.g. gcc 12) lack <stddef.h>'s
+// unreachable(), so fall back to the equivalent builtin.
+#if defined(WUFFS_BASE__C_DIALECT__C23)
+#include <stddef.h>
+#if defined(unreachable)
+#define WUFFS_BASE__UNREACHABLE() unreachable()
+#elif defined(__GNUC__)
+#define WUFFS_BASE__UNREACHABLE() __builtin_unreachable()
+#else
+#define WUFFS_BASE__UNREACHABLE() abort()
+#endif
+#endif  // defined(WUFFS_BASE__C_DIALECT__C23)
+
 // Use switch cases for coroutine suspension points, similar to the technique
 // in https://www.chiark.greenend.org.uk/~sgtatham/coroutines.html
 //
 // We use trivial macros instead of an explicit assignment and case statement
 // so that clang-format doesn't get confused by the unusual "case"s.
-#define WUFFS_BASE__COROUTINE_SUSPENSION_POINT_0 case 0:;
+//
+// In C23 mode, the switch's default case tells the compiler that
+// coro_susp_point always holds a valid suspension point.
+//
+// With WUFFS_BASE__COROUTINE_COMPUTED_GOTO, each case is also a
+// coro_susp_point_etc label, whose address is taken by the generated code's
+// table of resumption points. Taking a label's address, and jumping to it, are
+// GCC / Clang extensions, so the -Wpedantic warnings are suppressed between
+// the _BEGIN and _END macros.
+#if defined(WUFFS_BASE__COROUTINE_COMPUTED_GOTO)
+#define WUFFS_BASE__COROUTINE_LABEL(n) coro_susp_point_##n:;
+#define WUFFS_BASE__COROUTINE_COMPUTED_GOTO_BEGIN \
+  _Pragma("GCC diagnostic push")                   \
+      _Pragma("GCC diagnostic ignored \"-Wpedantic\"")
+#define WUFFS_BASE__COROUTINE_COMPUTED_GOTO_END _Pragma("GCC diagnostic pop")
+#else
+#define WUFFS_BASE__COROUTINE_LABEL(n)
+#endif
+
+#if defined(WUFFS_BASE__C_DIALECT__C23)
+#define WUFFS_BASE__COROUTINE_SUSPENSION_POINT_0 \
+  default:                                       \
+    WUFFS_BASE__UNREACHABLE();                   \
+  case 0:;                                       \
+    WUFFS_BASE__COROUTINE_LABEL(0)
+#else
+#define WUFFS_BASE__COROUTINE_SUSPENSION_POINT_0 \
+  case 0:;                                       \
+    WUFFS_BASE__COROUTINE_LABEL(0)
+#endif
 #define WUFFS_BASE__COROUTINE_SUSPENSION_POINT(n) \
   coro_susp_point = n;                            \
   WUFFS_BASE__FALLTHROUGH;                        \
-  case n:;
+  case n:;                                        \
+    WUFFS_BASE__COROUTINE_LABEL(n)
 
 #define WUFFS_BASE__COROUTINE_SUSPENSION_POINT_MAYBE_SUSPEND(n) \
   if (!status.repr) {                                           \
@@ -59,7 +107,38 @@
   }                                                             \
   coro_susp_point = n;                                          \
   goto suspend;                                                 \
-  case n:;
+  case n:;                                                      \
+    WUFFS_BASE__COROUTINE_LABEL(n)
+
+// WUFFS_BASE__COROUTINE_SUSPENSION_POINT_YIELD_NOTE is like
+// WUFFS_BASE__COROUTINE_SUSPENSION_POINT_MAYBE_SUSPEND but the status is
+// always a note, not a suspension, and the coroutine still resumes from this
+// point on the next call.
+#define WUFFS_BASE__COROUTINE_SUSPENSION_POINT_YIELD_NOTE(n) \
+  coro_susp_point = n;                                       \
+  goto yield_note;                                           \
+  case n:;                                                   \
+    WUFFS_BASE__COROUTINE_LABEL(n)
+
+// WUFFS_BASE__COROUTINE_NO_RESUME_POINT_ETC are like the
+// WUFFS_BASE__COROUTINE_SUSPENSION_POINT_ETC macros, minus the case labels.
+// They are used by a coroutine's __closed_src variant (see
+// WUFFS_CONFIG__CLOSED_SRC_FAST_PATH), which records where it suspended but
+// never resumes: resuming is left to the regular function.
+#define WUFFS_BASE__COROUTINE_NO_RESUME_POINT(n) coro_susp_point = n;
+
+#define WUFFS_BASE__COROUTINE_NO_RESUME_POINT_MAYBE_SUSPEND(n) \
+  if (!status.repr) {                                          \
+    goto ok;                                                   \
+  } else if (*status.repr != '$') {                            \
+    goto exit;                                                 \
+  }                                                            \
+  coro_susp_point = n;                                         \
+  goto suspend;
+
+#define WUFFS_BASE__COROUTINE_NO_RESUME_POINT_YIELD_NOTE(n) \
+  coro_susp_point = n;                                      \
+  goto yield_note;
 
 // Clang also defines "__GNUC__".
 #if defined(__GNUC__)
@@ -181,6 +260,106 @@ wuffs_base__u64__sat_sub_indirect(uint64_t* x, uint64_t y) {
   *x = wuffs_base__u64__sat_sub(*x, y);
 }
 
+// ---------------- Floating Point Types
+
+// wuffs_base__f64__to_uxx_saturating converts from a double to an unsigned
+// integer, truncating towards zero. Out-of-range values saturate: negative
+// numbers (including negative infinity) become 0 and too-large numbers
+// (including positive infinity) become the maximum uxx value. NaN becomes 0.
+
+static inline uint8_t  //
+wuffs_base__f64__to_u8_saturating(double f) {
+  if (!(f > 0)) {
+    return 0;
+  } else if (f >= 255.0) {
+    return 0xFF;
+  }
+  return (uint8_t)f;
+}
+
+static inline uint16_t  //
+wuffs_base__f64__to_u16_saturating(double f) {
+  if (!(f > 0)) {
+    return 0;
+  } else if (f >= 65535.0) {
+    return 0xFFFF;
+  }
+  return (uint16_t)f;
+}
+
+static inline uint32_t  //
+wuffs_base__f64__to_u32_saturating(double f) {
+  if (!(f > 0)) {
+    return 0;
+  } else if (f >= 4294967295.0) {
+    return 0xFFFFFFFF;
+  }
+  return (uint32_t)f;
+}
+
+static inline uint64_t  //
+wuffs_base__f64__to_u64_saturating(double f) {
+  if (!(f > 0)) {
+    return 0;
+  } else if (f >= 18446744073709551616.0) {
+    return 0xFFFFFFFFFFFFFFFF;
+  }
+  return (uint64_t)f;
+}
+
+static inline uint64_t  //
+wuffs_base__f64__to_bits(double f) {
+  return wuffs_base__ieee_754_bit_representation__from_f64_to_u64(f);
+}
+
+// --------
+
+// Every float value is exactly representable as a double, so the f32 methods
+// forward to their f64 equivalents.
+
+static inline uint8_t  //
+wuffs_base__f32__to_u8_saturating(float f) {
+  return wuffs_base__f64__to_u8_saturating((double)f);
+}
+
+static inline uint16_t  //
+wuffs_base__f32__to_u16_saturating(float f) {
+  return wuffs_base__f64__to_u16_saturating((double)f);
+}
+
+static inline uint32_t  //
+wuffs_base__f32__to_u32_saturating(float f) {
+  return wuffs_base__f64__to_u32_saturating((double)f);
+}
+
+static inline uint64_t  //
+wuffs_base__f32__to_u64_saturating(float f) {
+  return wuffs_base__f64__to_u64_saturating((double)f);
+}
+
+static inline uint32_t  //
+wuffs_base__f32__to_bits(float f) {
+  uint32_t u = 0;
+  if (sizeof(uint32_t) == sizeof(float)) {
+    memcpy(&u, &f, sizeof(uint32_t));
+  }
+  return u;
+}
+
+// ---------------- Floating Point Types (Utility)
+
+static inline float  //
+wuffs_base__utility__make_f32_from_bits(uint32_t u) {
+  float f = 0;
+  if (sizeof(uint32_t) == sizeof(float)) {
+    memcpy(&f, &u, sizeof(uint32_t));
+  }
+  return f;
+}
+
+#define wuffs_base__utility__make_f64_from_bits \
+  wuffs_base__ieee_754_bit_representation__from_u64_to_f64
+
 // ---------------- Slices and Tables
 
 // wuffs_base__slice_u8__prefix returns up to the first up_to bytes of s.
@@ -217,6 +396,103 @@ wuffs_base__slice_u8__copy_from_slice(wuffs_base__slice_u8 dst,
   return len;
 }
 
+// wuffs_base__slice_u8__copy_within calls memmove(s.ptr + dst_index, s.ptr +
+// src_index, len), where len is the minimum of n and the number of bytes from
+// either index to the end of s. It returns len, which is zero if either index
+// is greater than s.len.
+static inline uint64_t  //
+wuffs_base__slice_u8__copy_within(wuffs_base__slice_u8 s,
+                                  uint64_t dst_index,
+                                  uint64_t src_index,
+                                  uint64_t n) {
+  if ((dst_index > s.len) || (src_index > s.len)) {
+    return 0;
+  }
+  uint64_t len = s.len - (dst_index > src_index ? dst_index : src_index);
+  if (len > n) {
+    len = n;
+  }
+  if (len > 0) {
+    memmove(s.ptr + (size_t)dst_index, s.ptr + (size_t)src_index, (size_t)len);
+  }
+  return len;
+}
+
+// wuffs_base__slice_u8__fill sets every element of s to value.
+static inline wuffs_base__empty_struct  //
+wuffs_base__slice_u8__fill(wuffs_base__slice_u8 s, uint8_t value) {
+  if (s.len > 0) {
+    memset(s.ptr, value, s.len);
+  }
+  return wuffs_base__make_empty_struct();
+}
+
+// wuffs_base__slice_u8__equal_fold returns whether s and t have the same
+// length and contents, ignoring ASCII case: 'A' ..= 'Z' match 'a' ..= 'z'.
+// Non-ASCII bytes (including those of multi-byte UTF-8 sequences) only match
+// themselves.
+static inline bool  //
+wuffs_base__slice_u8__equal_fold(wuffs_base__slice_u8 s,
+                                 wuffs_base__slice_u8 t) {
+  if (s.len != t.len) {
+    return false;
+  }
+  size_t i;
+  for (i = 0; i < s.len; i++) {
+    uint32_t x = s.ptr[i];
+    uint32_t y = t.ptr[i];
+    if ((x != y) && (((x | 0x20) != (y | 0x20)) ||
+                     (((x | 0x20) - 0x61) > (0x7A - 0x61)))) {
+      return false;
+    }
+  }
+  return true;
+}
+
+// wuffs_base__slice_u8__index_of returns the index of the first occurrence
+// of t in s, or s.len if there is none. An empty t occurs at index 0.
+static inline uint64_t  //
+wuffs_base__slice_u8__index_of(wuffs_base__slice_u8 s,
+                               wuffs_base__slice_u8 t) {
+  if (t.len == 0) {
+    return 0;
+  } else if (t.len > s.len) {
+    return s.len;
+  }
+  const uint8_t* p = s.ptr;
+  const uint8_t* q = s.ptr + (s.len - t.len);
+  while (p <= q) {
+    p = (const uint8_t*)memchr(p, t.ptr[0], (size_t)(q - p) + 1);
+    if (!p) {
+      break;
+    } else if (!memcmp(p, t.ptr, t.len)) {
+      return (uint64_t)(p - s.ptr);
+    }
+    p++;
+  }
+  return s.len;
+}
+
+// wuffs_base__slice_u8__index_of_fold is like wuffs_base__slice_u8__index_of
+// but it ignores ASCII case, like wuffs_base__slice_u8__equal_fold.
+static inline uint64_t  //
+wuffs_base__slice_u8__index_of_fold(wuffs_base__slice_u8 s,
+                                    wuffs_base__slice_u8 t) {
+  if (t.len == 0) {
+    return 0;
+  } else if (t.len > s.len) {
+    return s.len;
+  }
+  size_t i;
+  for (i = 0; i <= (s.len - t.len); i++) {
+    if (wuffs_base__slice_u8__equal_fold(
+            wuffs_base__make_slice_u8(s.ptr + i, t.len), t)) {
+      return i;
+    }
+  }
+  return s.len;
+}
+
 // --------
 
 static inline wuffs_base__slice_u8  //
@@ -71,6 +71,11 @@
 #if defined(__ARM_NEON)
 #include <arm_neon.h>
 #define WUFFS_BASE__CPU_ARCH__ARM_NEON
+// "cpu_arch >= arm_sha2" also requires Neon. Like CRC32, the SHA-2 (ARMv8
+// Cryptographic Extension) instructions are a compile-time property.
+#if defined(__ARM_FEATURE_SHA2) || defined(__ARM_FEATURE_CRYPTO)
+#define WUFFS_BASE__CPU_ARCH__ARM_SHA2
+#endif  // defined(__ARM_FEATURE_SHA2) || defined(__ARM_FEATURE_CRYPTO)
 #endif  // defined(__ARM_NEON)
 #endif  // defined(__ARM_FEATURE_UNALIGNED) etc
 
@@ -82,6 +87,30 @@
 #define WUFFS_BASE__CPU_ARCH__X86_64
 #endif  // defined(__x86_64__)
 
+// "cpu_arch >= riscv_rvv" requires the ratified (version 1.0) RISC-V Vector
+// extension to be enabled at compile time (e.g. "-march=rv64gcv"), with the
+// "__riscv_" prefixed (version 0.12 or later) intrinsics. The kernel also has
+// to support it (saving and restoring the vector registers), which is checked
+// at runtime.
+#if defined(__riscv) && defined(__riscv_vector) && \
+    defined(__riscv_v_intrinsic) && (__riscv_v_intrinsic >= 12000) && \
+    defined(__linux__)
+#include <riscv_vector.h>
+#include <sys/auxv.h>
+#include <sys/syscall.h>
+#include <unistd.h>
+#define WUFFS_BASE__CPU_ARCH__RISCV_RVV
+#endif  // defined(__riscv) etc
+
+// "cpu_arch >= wasm_simd128" requires WebAssembly's 128-bit SIMD to be enabled
+// at compile time (e.g. "-msimd128" for Emscripten or wasi-sdk). WebAssembly
+// has no runtime feature detection: a module that uses SIMD instructions
+// fails validation on an engine that doesn't support them.
+#if defined(__wasm_simd128__)
+#include <wasm_simd128.h>
+#define WUFFS_BASE__CPU_ARCH__WASM_SIMD128
+#endif  // defined(__wasm_simd128__)
+
 #elif defined(_MSC_VER)  // (#if-chain ref AVOID_CPU_ARCH_1)
 
 #if defined(_M_X64)
@@ -128,10 +157,131 @@
 #define WUFFS_BASE__MAYBE_STATIC
 #endif  // defined(WUFFS_CONFIG__STATIC_FUNCTIONS)
 
+// --------
+
+// WUFFS_BASE__NONNULL and WUFFS_BASE__NULLABLE are Clang's _Nonnull and
+// _Nullable pointer qualifiers, or nothing for other compilers. Packages
+// generated by "wuffs gen -annotations" use them in their public function
+// prototypes, so that callers compiled with -Wnullability (or Clang's static
+// analyzer) get checking on Wuffs calls. Define WUFFS_CONFIG__NO_NULLABILITY
+// to opt out, even with Clang.
+#if defined(__clang__) && defined(__has_feature) && \
+    !defined(WUFFS_CONFIG__NO_NULLABILITY)
+#if __has_feature(nullability)
+#define WUFFS_BASE__NONNULL _Nonnull
+#define WUFFS_BASE__NULLABLE _Nullable
+#endif  // __has_feature(nullability)
+#endif  // defined(__clang__) etc
+
+#if !defined(WUFFS_BASE__NONNULL)
+#define WUFFS_BASE__NONNULL
+#define WUFFS_BASE__NULLABLE
+#endif  // !defined(WUFFS_BASE__NONNULL)
+
+// --------
+
+// Define WUFFS_CONFIG__INLINE to override the inline keyword used by code
+// generated with "wuffs gen -cdialect=c99", e.g. as __inline for compilers
+// that predate C99's inline, or as nothing at all.
+#if defined(WUFFS_CONFIG__INLINE)
+#define WUFFS_BASE__INLINE WUFFS_CONFIG__INLINE
+#else
+#define WUFFS_BASE__INLINE inline
+#endif  // defined(WUFFS_CONFIG__INLINE)
+
+// --------
+
+// Define WUFFS_CONFIG__C_DIALECT__C99 to restrict Wuffs' C code to C99, even
+// when the compiler supports a later standard, for legacy toolchains.
+//
+// Define WUFFS_CONFIG__C_DIALECT__C23 to let Wuffs' C code use C23 features,
+// such as [[fallthrough]] and unreachable(). This requires a C23 (or C2x)
+// compiler and has no effect when compiling as C++. Note that unreachable()
+// marks a coroutine resuming from an invalid suspension point, which is only
+// possible if the decoder struct's memory was otherwise corrupted, as
+// undefined behavior instead of a no-op.
+//
+// At most one of these should be defined. The "wuffs gen -cdialect=etc" flag
+// will also define one of them, in the generated code.
+#if defined(WUFFS_CONFIG__C_DIALECT__C99) && \
+    defined(WUFFS_CONFIG__C_DIALECT__C23)
+#error "WUFFS_CONFIG__C_DIALECT__C99 and __C23 are mutually exclusive"
+#elif defined(WUFFS_CONFIG__C_DIALECT__C99)
+#if defined(__STDC_VERSION__) && (__STDC_VERSION__ < 199901L)
+#error "WUFFS_CONFIG__C_DIALECT__C99 requires a C99 (or later) compiler"
+#endif
+#elif defined(WUFFS_CONFIG__C_DIALECT__C23) && !defined(__cplusplus)
+#if !defined(__STDC_VERSION__) || (__STDC_VERSION__ <= 201710L)
+#error "WUFFS_CONFIG__C_DIALECT__C23 requires a C23 (or C2x) compiler"
+#endif
+#define WUFFS_BASE__C_DIALECT__C23
+#endif
+
+// --------
+
+// Define WUFFS_CONFIG__COROUTINE_COMPUTED_GOTO to resume coroutines (such as
+// a decoder's decode_frame or transform_io methods) by jumping through a table
+// of label addresses instead of through a switch statement. This can avoid
+// some branch mispredictions in hot decoders. It uses a GCC / Clang extension
+// ("labels as values"), so other compilers ignore the #define and fall back
+// to the portable switch. The "wuffs bench -coroutinedispatch=etc" flag
+// compares the two.
+#if defined(WUFFS_CONFIG__COROUTINE_COMPUTED_GOTO) && defined(__GNUC__)
+#define WUFFS_BASE__COROUTINE_COMPUTED_GOTO
+#endif
+
+// Define WUFFS_CONFIG__CLOSED_SRC_FAST_PATH to also compile a non-resuming
+// "__closed_src" variant of each coroutine that takes an io_reader. A call
+// that starts afresh (instead of resuming a suspended coroutine) and whose
+// io_reader args are all closed, such as decoding an image that is entirely
+// in memory, runs the variant. Its suspension points are not also resumption
+// points (switch cases), so the C compiler can optimize its loops better. If
+// it does suspend (e.g. on a short write), the next call resumes in the
+// regular function, so the observable behavior is unchanged. The cost is
+// larger object code. The "wuffs bench -coroutinedispatch=closedsrc" flag
+// measures the difference.
+
+// --------
+
+// Define WUFFS_TRACE(event, receiver, func_name, status_repr, value0, value1)
+// before #include'ing this file to observe what Wuffs' functions are doing,
+// e.g. to forward to a printf-style logger or an ETW or LTTng tracepoint,
+// without patching the generated code. The arguments are:
+//  - event, one of the WUFFS_BASE__TRACE_EVENT__ETC values.
+//  - receiver, a pointer to the decoder (or similar) struct, or NULL.
+//  - func_name, a C string literal like "wuffs_gif__decoder__decode_frame".
+//  - status_repr, a const char* status message (which may be NULL).
+//  - value0 and value1, event-specific integer values (or zero).
+//
+// The events are:
+//  - STATUS when a function returns or yields an error or note status.
+//  - FRAME_BEGIN when a decode_frame call starts (not resumes).
+//  - FRAME_END when a decode_frame call finishes, with or without error. Its
+//    status_repr is NULL on success.
+//  - QUIRK when set_quirk_enabled is called. The value0 and value1 are the
+//    quirk and enabled arguments.
+//  - SUSPEND when a coroutine (public or not) suspends. Its value0 is the
+//    coroutine suspension point, which identifies where in the function it
+//    will resume. A suspending call stack produces one SUSPEND per frame,
+//    innermost first.
+//
+// The default WUFFS_TRACE is a no-op that does not evaluate its arguments.
+#define WUFFS_BASE__TRACE_EVENT__STATUS 1
+#define WUFFS_BASE__TRACE_EVENT__FRAME_BEGIN 2
+#define WUFFS_BASE__TRACE_EVENT__FRAME_END 3
+#define WUFFS_BASE__TRACE_EVENT__QUIRK 4
+#define WUFFS_BASE__TRACE_EVENT__SUSPEND 5
+
+#if !defined(WUFFS_TRACE)
+#define WUFFS_TRACE(event, ...) \
+  do {                          \
+  } while (0)
+#endif
+
 // ---------------- CPU Architecture
 
 static inline bool  //
-wuffs_base__cpu_arch__have_arm_crc32() {
+wuffs_base__cpu_arch__have_arm_crc32(void) {
 #if defined(WUFFS_BASE__CPU_ARCH__ARM_CRC32)
   return true;
 #else
@@ -140,7 +290,7 @@ wuffs_base__cpu_arch__have_arm_crc32() {
 }
 
 static inline bool  //
-wuffs_base__cpu_arch__have_arm_neon() {
+wuffs_base__cpu_arch__have_arm_neon(void) {
 #if defined(WUFFS_BASE__CPU_ARCH__ARM_NEON)
   return true;
 #else
@@ -149,7 +299,54 @@ wuffs_base__cpu_arch__have_arm_neon() {
 }
 
 static inline bool  //
-wuffs_base__cpu_arch__have_x86_avx2() {
+wuffs_base__cpu_arch__have_arm_sha2(void) {
+#if defined(WUFFS_BASE__CPU_ARCH__ARM_SHA2)
+  return true;
+#else
+  return false;
+#endif  // defined(WUFFS_BASE__CPU_ARCH__ARM_SHA2)
+}
+
+static inline bool  //
+wuffs_base__cpu_arch__have_riscv_rvv(void) {
+#if defined(WUFFS_BASE__CPU_ARCH__RISCV_RVV)
+  // Prefer the riscv_hwprobe syscall (Linux 6.4 or later). These constants
+  // are from <asm/hwprobe.h>, which not every libc provides:
+  //  - RISCV_HWPROBE_KEY_IMA_EXT_0 = 4
+  //  - RISCV_HWPROBE_IMA_V         = (1 << 2)
+  //
+  // syscall is only declared for _DEFAULT_SOURCE (not e.g. "-std=c99").
+#if defined(__NR_riscv_hwprobe) && \
+    (defined(_DEFAULT_SOURCE) || defined(_GNU_SOURCE))
+  struct {
+    int64_t key;
+    uint64_t value;
+  } pair = {4, 0};
+  // The NULL and 0 cpus arguments mean all online CPUs.
+  if (syscall(__NR_riscv_hwprobe, &pair, 1, 0, NULL, 0) == 0) {
+    return (pair.key == 4) && ((pair.value & 0x04) != 0);
+  }
+#endif  // defined(__NR_riscv_hwprobe) etc
+
+  // Otherwise, fall back to the auxiliary vector, whose ISA bits are indexed
+  // by letter. "V" is (1 << ('V' - 'A')).
+  return (getauxval(AT_HWCAP) & 0x00200000) != 0;
+#else
+  return false;
+#endif  // defined(WUFFS_BASE__CPU_ARCH__RISCV_RVV)
+}
+
+static inline bool  //
+wuffs_base__cpu_arch__have_wasm_simd128(void) {
+#if defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+  return true;
+#else
+  return false;
+#endif  // defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+}
+
+static inline bool  //
+wuffs_base__cpu_arch__have_x86_avx2(void) {
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
   // GCC defines these macros but MSVC does not.
   //  - bit_BMI2 = (1 <<  5)
@@ -176,7 +373,7 @@ wuffs_base__cpu_arch__have_x86_avx2() {
 }
 
 static inline bool  //
-wuffs_base__cpu_arch__have_x86_bmi2() {
+wuffs_base__cpu_arch__have_x86_bmi2(void) {
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
   // GCC defines these macros but MSVC does not.
   //  - bit_BMI2 = (1 <<  8)
@@ -203,7 +400,7 @@ wuffs_base__cpu_arch__have_x86_bmi2() {
 }
 
 static inline bool  //
-wuffs_base__cpu_arch__have_x86_sse42() {
+wuffs_base__cpu_arch__have_x86_sse42(void) {
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
   // GCC defines these macros but MSVC does not.
   //  - bit_PCLMUL = (1 <<  1)
@@ -231,6 +428,38 @@ wuffs_base__cpu_arch__have_x86_sse42() {
   return false;
 }
 
+static inline bool  //
+wuffs_base__cpu_arch__have_x86_sha(void) {
+#if defined(WUFFS_BASE__CPU_ARCH__X86_64)
+  // "cpu_arch >= x86_sha" also requires "cpu_arch >= x86_sse42".
+  if (!wuffs_base__cpu_arch__have_x86_sse42()) {
+    return false;
+  }
+
+  // GCC defines these macros but MSVC does not.
+  //  - bit_SHA = (1 << 29)
+  const unsigned int sha_ebx7 = 0x20000000;
+
+  // clang defines __GNUC__ and clang-cl defines _MSC_VER (but not __GNUC__).
+#if defined(__GNUC__)
+  unsigned int eax7 = 0;
+  unsigned int ebx7 = 0;
+  unsigned int ecx7 = 0;
+  unsigned int edx7 = 0;
+  if (__get_cpuid_count(7, 0, &eax7, &ebx7, &ecx7, &edx7)) {
+    return (ebx7 & sha_ebx7) == sha_ebx7;
+  }
+#elif defined(_MSC_VER)  // defined(__GNUC__)
+  int x[4];
+  __cpuidex(x, 7, 0);
+  return (((unsigned int)(x[1])) & sha_ebx7) == sha_ebx7;
+#else
+#error "WUFFS_BASE__CPU_ARCH__ETC combined with an unsupported compiler"
+#endif  // defined(__GNUC__); defined(_MSC_VER)
+#endif  // defined(WUFFS_BASE__CPU_ARCH__X86_64)
+  return false;
+}
+
 // ---------------- Fundamentals
 
 // Wuffs assumes that:
@@ -292,7 +521,7 @@ typedef struct wuffs_base__empty_struct__struct {
 } wuffs_base__empty_struct;
 
 static inline wuffs_base__empty_struct  //
-wuffs_base__make_empty_struct() {
+wuffs_base__make_empty_struct(void) {
   wuffs_base__empty_struct ret;
   ret.private_impl = 0;
   return ret;
@@ -324,6 +553,11 @@ typedef struct wuffs_base__vtable__struct {
 typedef struct wuffs_base__status__struct {
   const char* repr;
 
+  // payload is optional, status-specific detail, such as the PNG chunk type
+  // that a "#bad chunk" error refers to. It is zero if unused. Each status
+  // that sets it documents what it means.
+  uint64_t payload;
+
 #ifdef __cplusplus
   inline bool is_complete() const;
   inline bool is_error() const;
@@ -341,6 +575,15 @@ static inline wuffs_base__status  //
 wuffs_base__make_status(const char* repr) {
   wuffs_base__status z;
   z.repr = repr;
+  z.payload = 0;
+  return z;
+}
+
+static inline wuffs_base__status  //
+wuffs_base__make_status_with_payload(const char* repr, uint64_t payload) {
+  wuffs_base__status z;
+  z.repr = repr;
+  z.payload = payload;
   return z;
 }
 
@@ -380,6 +623,13 @@ wuffs_base__status__message(const wuffs_base__status* z) {
   return z->repr;
 }
 
+// wuffs_base__status__payload returns the status' payload, or zero if it is
+// OK. The payload's meaning depends on the repr.
+static inline uint64_t  //
+wuffs_base__status__payload(const wuffs_base__status* z) {
+  return z->repr ? z->payload : 0;
+}
+
 #ifdef __cplusplus
 
 inline bool  //
@@ -441,6 +691,55 @@ typedef struct wuffs_base__transform__output__struct {
 
 // --------
 
+// wuffs_base__metrics holds per-instance counters, for long-running programs
+// that want to export decoder health metrics (e.g. "how many bytes has this
+// decoder consumed, and how often has it failed") without wrapping every call
+// site. Decoders (and similar structs) have a wuffs_foo__bar__metrics
+// accessor function that returns a copy of their counters.
+//
+// The counters are updated when a public coroutine method (such as
+// decode_frame or transform_io) returns, whether it completes, suspends or
+// fails, but only if WUFFS_CONFIG__METRICS is defined when compiling the
+// implementation. Otherwise, they stay zero and cost nothing but space. The
+// struct layout does not depend on that #define.
+//
+// The num_bytes_read and num_bytes_written fields count the I/O buffer bytes
+// consumed and produced. The num_tokens_written field counts the token buffer
+// elements produced. Re-initializing the struct resets all of the counters.
+typedef struct wuffs_base__metrics__struct {
+  uint64_t num_bytes_read;
+  uint64_t num_bytes_written;
+  uint64_t num_tokens_written;
+  uint64_t num_frames_decoded;
+  uint64_t num_suspensions;
+  uint64_t num_errors;
+  // last_error is the most recent error status, or NULL if there were none.
+  wuffs_base__status last_error;
+} wuffs_base__metrics;
+
+// --------
+
+// Output hashers.
+//
+// Decoders and transformers (whose public coroutine methods write to a dst
+// argument) have a wuffs_foo__bar__set_output_hasher function (and a C++
+// set_output_hasher method) that attaches a caller-owned hasher, such as a
+// wuffs_crc32__ieee_hasher upcast to a wuffs_base__hasher_u32. The decoded
+// output is then fed through that hasher as it is produced, so that callers
+// can compute a digest of large outputs without a second pass over them.
+//
+// For io_writer outputs (e.g. transform_io), every byte written is hashed, in
+// order, when the method returns, whether it completes or suspends. For
+// pixel_buffer outputs (e.g. decode_frame), the frame_dirty_rect pixels of
+// the first plane are hashed row by row, once, when decode_frame completes
+// successfully. Pass NULL to detach the hasher.
+//
+// Like metrics, hashing only happens if WUFFS_CONFIG__OUTPUT_HASHER is defined
+// when compiling the implementation. The struct layout does not depend on
+// that #define. Re-initializing the struct detaches the hasher.
+
+// --------
+
 // FourCC constants.
 
 // ¡ INSERT FourCCs.
@@ -661,8 +960,19 @@ wuffs_base__count_leading_zeroes_u64(uint64_t u) {
   return u ? ((uint32_t)(__builtin_clzl(u))) : 64u;
 }
 
+static inline uint32_t  //
+wuffs_base__count_trailing_zeroes_u64(uint64_t u) {
+  return u ? ((uint32_t)(__builtin_ctzl(u))) : 64u;
+}
+
+static inline uint32_t  //
+wuffs_base__count_ones_u64(uint64_t u) {
+  return (uint32_t)(__builtin_popcountl(u));
+}
+
 #else
-// TODO: consider using the _BitScanReverse intrinsic if defined(_MSC_VER).
+// TODO: consider using the _BitScanReverse, _BitScanForward and __popcnt64
+// intrinsics if defined(_MSC_VER).
 
 static inline uint32_t  //
 wuffs_base__count_leading_zeroes_u64(uint64_t u) {
@@ -698,6 +1008,48 @@ wuffs_base__count_leading_zeroes_u64(uint64_t u) {
   return n;
 }
 
+static inline uint32_t  //
+wuffs_base__count_trailing_zeroes_u64(uint64_t u) {
+  if (u == 0) {
+    return 64;
+  }
+
+  uint32_t n = 0;
+  if ((u & 0xFFFFFFFF) == 0) {
+    n |= 32;
+    u >>= 32;
+  }
+  if ((u & 0xFFFF) == 0) {
+    n |= 16;
+    u >>= 16;
+  }
+  if ((u & 0xFF) == 0) {
+    n |= 8;
+    u >>= 8;
+  }
+  if ((u & 0xF) == 0) {
+    n |= 4;
+    u >>= 4;
+  }
+  if ((u & 0x3) == 0) {
+    n |= 2;
+    u >>= 2;
+  }
+  if ((u & 0x1) == 0) {
+    n |= 1;
+    u >>= 1;
+  }
+  return n;
+}
+
+static inline uint32_t  //
+wuffs_base__count_ones_u64(uint64_t u) {
+  u = u - ((u >> 1) & 0x5555555555555555);
+  u = (u & 0x3333333333333333) + ((u >> 2) & 0x3333333333333333);
+  u = (u + (u >> 4)) & 0x0F0F0F0F0F0F0F0F;
+  return (uint32_t)((u * 0x0101010101010101) >> 56);
+}
+
 #endif  // defined(__GNUC__) && (__SIZEOF_LONG__ == 8)
 
 // --------
@@ -1098,7 +1450,7 @@ wuffs_base__make_slice_u64(uint64_t* ptr, size_t len) {
 }
 
 static inline wuffs_base__slice_u8  //
-wuffs_base__empty_slice_u8() {
+wuffs_base__empty_slice_u8(void) {
   wuffs_base__slice_u8 ret;
   ret.ptr = NULL;
   ret.len = 0;
@@ -1106,7 +1458,7 @@ wuffs_base__empty_slice_u8() {
 }
 
 static inline wuffs_base__slice_u16  //
-wuffs_base__empty_slice_u16() {
+wuffs_base__empty_slice_u16(void) {
   wuffs_base__slice_u16 ret;
   ret.ptr = NULL;
   ret.len = 0;
@@ -1114,7 +1466,7 @@ wuffs_base__empty_slice_u16() {
 }
 
 static inline wuffs_base__slice_u32  //
-wuffs_base__empty_slice_u32() {
+wuffs_base__empty_slice_u32(void) {
   wuffs_base__slice_u32 ret;
   ret.ptr = NULL;
   ret.len = 0;
@@ -1122,7 +1474,7 @@ wuffs_base__empty_slice_u32() {
 }
 
 static inline wuffs_base__slice_u64  //
-wuffs_base__empty_slice_u64() {
+wuffs_base__empty_slice_u64(void) {
   wuffs_base__slice_u64 ret;
   ret.ptr = NULL;
   ret.len = 0;
@@ -1182,7 +1534,7 @@ wuffs_base__make_table_u64(uint64_t* ptr,
 }
 
 static inline wuffs_base__table_u8  //
-wuffs_base__empty_table_u8() {
+wuffs_base__empty_table_u8(void) {
   wuffs_base__table_u8 ret;
   ret.ptr = NULL;
   ret.width = 0;
@@ -1192,7 +1544,7 @@ wuffs_base__empty_table_u8() {
 }
 
 static inline wuffs_base__table_u16  //
-wuffs_base__empty_table_u16() {
+wuffs_base__empty_table_u16(void) {
   wuffs_base__table_u16 ret;
   ret.ptr = NULL;
   ret.width = 0;
@@ -1202,7 +1554,7 @@ wuffs_base__empty_table_u16() {
 }
 
 static inline wuffs_base__table_u32  //
-wuffs_base__empty_table_u32() {
+wuffs_base__empty_table_u32(void) {
   wuffs_base__table_u32 ret;
   ret.ptr = NULL;
   ret.width = 0;
@@ -1212,7 +1564,7 @@ wuffs_base__empty_table_u32() {
 }
 
 static inline wuffs_base__table_u64  //
-wuffs_base__empty_table_u64() {
+wuffs_base__empty_table_u64(void) {
   wuffs_base__table_u64 ret;
   ret.ptr = NULL;
   ret.width = 0;
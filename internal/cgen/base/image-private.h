@@ -40,6 +40,92 @@ wuffs_base__pixel_swizzler__swizzle_interleaved_transparent_black(
     wuffs_base__slice_u8 dst_palette,
     uint64_t num_pixels);
 
+// wuffs_base__pixel_swizzler__apply_decode_frame_options applies the color
+// transform and ditherer, if any, of a decode_frame method's opts argument.
+static inline wuffs_base__status  //
+wuffs_base__pixel_swizzler__apply_decode_frame_options(
+    wuffs_base__pixel_swizzler* p,
+    wuffs_base__decode_frame_options* opts) {
+  wuffs_base__status status = wuffs_base__pixel_swizzler__set_color_transform(
+      p, wuffs_base__decode_frame_options__color_transform(opts));
+  if (!wuffs_base__status__is_ok(&status)) {
+    return status;
+  }
+  return wuffs_base__pixel_swizzler__set_ditherer(
+      p, wuffs_base__decode_frame_options__ditherer(opts));
+}
+
+// wuffs_base__pixel_buffer__update_hasher_u32 feeds the pixels of pb's first
+// plane that are within the rectangle r through h, one row at a time. It does
+// nothing for planar or sub-byte pixel formats.
+//
+// It is used by the WUFFS_CONFIG__OUTPUT_HASHER code generated for decode_frame
+// methods.
+static inline void  //
+wuffs_base__pixel_buffer__update_hasher_u32(wuffs_base__pixel_buffer* pb,
+                                            wuffs_base__rect_ie_u32 r,
+                                            wuffs_base__hasher_u32* h) {
+  uint32_t bits_per_pixel =
+      wuffs_base__pixel_format__bits_per_pixel(&pb->pixcfg.private_impl.pixfmt);
+  if ((bits_per_pixel == 0) || ((bits_per_pixel & 7) != 0)) {
+    return;
+  }
+  size_t bytes_per_pixel = (size_t)(bits_per_pixel / 8);
+  wuffs_base__rect_ie_u32 bounds =
+      wuffs_base__pixel_config__bounds(&pb->pixcfg);
+  r = wuffs_base__rect_ie_u32__intersect(&r, bounds);
+  wuffs_base__table_u8 t = wuffs_base__pixel_buffer__plane(pb, 0);
+  size_t n = bytes_per_pixel * wuffs_base__rect_ie_u32__width(&r);
+  uint32_t y;
+  for (y = r.min_incl_y; y < r.max_excl_y; y++) {
+    uint8_t* row = t.ptr + (t.stride * y) + (bytes_per_pixel * r.min_incl_x);
+    wuffs_base__hasher_u32__update_u32(h, wuffs_base__make_slice_u8(row, n));
+  }
+}
+
+// wuffs_base__image_decoder_limits__check_image_config returns
+// wuffs_base__error__resource_limit_exceeded if the image configuration c, or
+// the workbuf_len of its decoder, exceeds the limits. It is used by the code
+// generated for decode_image_config methods.
+static inline wuffs_base__status  //
+wuffs_base__image_decoder_limits__check_image_config(
+    const wuffs_base__image_decoder_limits* limits,
+    const wuffs_base__image_config* c,
+    uint64_t workbuf_len) {
+  uint64_t w = c ? wuffs_base__pixel_config__width(&c->pixcfg) : 0;
+  uint64_t h = c ? wuffs_base__pixel_config__height(&c->pixcfg) : 0;
+  if ((limits->max_width && (w > limits->max_width)) ||
+      (limits->max_height && (h > limits->max_height)) ||
+      (limits->max_num_pixels && ((w * h) > limits->max_num_pixels)) ||
+      (limits->max_workbuf_length &&
+       (workbuf_len > limits->max_workbuf_length))) {
+    return wuffs_base__make_status(wuffs_base__error__resource_limit_exceeded);
+  }
+  return wuffs_base__make_status(NULL);
+}
+
+// wuffs_base__image_decoder_limits__check_num_frames returns
+// wuffs_base__error__resource_limit_exceeded if num_frames exceeds the limits.
+// It is used by the code generated for decode_frame_config methods.
+static inline wuffs_base__status  //
+wuffs_base__image_decoder_limits__check_num_frames(
+    const wuffs_base__image_decoder_limits* limits,
+    uint64_t num_frames) {
+  if (limits->max_num_frames && (num_frames > limits->max_num_frames)) {
+    return wuffs_base__make_status(wuffs_base__error__resource_limit_exceeded);
+  }
+  return wuffs_base__make_status(NULL);
+}
+
 // ---------------- Images (Utility)
 
 #define wuffs_base__utility__make_pixel_format wuffs_base__make_pixel_format
+
+#define wuffs_base__utility__composite_nonpremul_over_nonpremul \
+  wuffs_base__composite_nonpremul_over_nonpremul
+#define wuffs_base__utility__composite_nonpremul_over_premul \
+  wuffs_base__composite_nonpremul_over_premul
+#define wuffs_base__utility__composite_premul_over_nonpremul \
+  wuffs_base__composite_premul_over_nonpremul
+#define wuffs_base__utility__composite_premul_over_premul \
+  wuffs_base__composite_premul_over_premul
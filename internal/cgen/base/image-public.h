@@ -201,6 +201,31 @@ wuffs_base__color_u64__as__color_u32(uint64_t c) {
   return (a << 24) | (r << 16) | (g << 8) | (b << 0);
 }
 
+// wuffs_base__color_ycc__as__color_u32 converts from YCbCr to an opaque
+// 0xFFRRGGBB color. It uses the full range (not the "studio swing" 16 ..= 235
+// range) BT.601 coefficients, as per JFIF (JPEG File Interchange Format):
+//
+//  - R = Y + 1.40200 * (Cr - 128)
+//  - G = Y - 0.34414 * (Cb - 128) - 0.71414 * (Cr - 128)
+//  - B = Y + 1.77200 * (Cb - 128)
+static inline wuffs_base__color_u32_argb_premul  //
+wuffs_base__color_ycc__as__color_u32(uint8_t yy, uint8_t cb, uint8_t cr) {
+  // Work in 16.16 fixed point. The 0x8000 is for rounding to nearest.
+  int32_t yy1 = (((int32_t)yy) << 16) + 0x8000;
+  int32_t cb1 = ((int32_t)cb) - 128;
+  int32_t cr1 = ((int32_t)cr) - 128;
+
+  int32_t r = yy1 + (91881 * cr1);
+  int32_t g = yy1 - (22554 * cb1) - (46802 * cr1);
+  int32_t b = yy1 + (116130 * cb1);
+
+  // Clamp before shifting, so that we never right shift a negative number.
+  uint32_t r8 = (r < 0) ? 0 : ((r > 0xFFFFFF) ? 0xFF : (((uint32_t)r) >> 16));
+  uint32_t g8 = (g < 0) ? 0 : ((g > 0xFFFFFF) ? 0xFF : (((uint32_t)g) >> 16));
+  uint32_t b8 = (b < 0) ? 0 : ((b > 0xFFFFFF) ? 0xFF : (((uint32_t)b) >> 16));
+  return 0xFF000000 | (r8 << 16) | (g8 << 8) | (b8 << 0);
+}
+
 // --------
 
 typedef uint8_t wuffs_base__pixel_blend;
@@ -215,6 +240,23 @@ typedef uint8_t wuffs_base__pixel_blend;
 
 // --------
 
+typedef uint8_t wuffs_base__pixel_chroma_upsampling;
+
+// wuffs_base__pixel_chroma_upsampling encodes how to reconstruct a per-pixel
+// chroma value from subsampled (e.g. 4:2:0 or 4:2:2) chroma planes.
+//
+// NEAREST (also known as box filtering) uses the one chroma sample that
+// covers the pixel. TRIANGLE blends that sample (with weight 3/4) with its
+// nearest neighbor (with weight 1/4), separately along each 2:1 subsampled
+// axis. This matches libjpeg's "fancy upsampling". Axes that are not 2:1
+// subsampled always use NEAREST.
+#define WUFFS_BASE__PIXEL_CHROMA_UPSAMPLING__NEAREST \
+  ((wuffs_base__pixel_chroma_upsampling)0)
+#define WUFFS_BASE__PIXEL_CHROMA_UPSAMPLING__TRIANGLE \
+  ((wuffs_base__pixel_chroma_upsampling)1)
+
+// --------
+
 // wuffs_base__pixel_alpha_transparency is a pixel format's alpha channel
 // model. It is a property of the pixel format in general, not of a specific
 // pixel. An RGBA pixel format (with alpha) can still have fully opaque pixels.
@@ -306,6 +348,7 @@ wuffs_base__make_pixel_format(uint32_t repr) {
 #define WUFFS_BASE__PIXEL_FORMAT__RGB 0xA0000888
 #define WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL 0xA1008888
 #define WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL_4X16LE 0xA100BBBB
+#define WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL_4XF32LE 0xA108DDDD
 #define WUFFS_BASE__PIXEL_FORMAT__RGBA_PREMUL 0xA2008888
 #define WUFFS_BASE__PIXEL_FORMAT__RGBA_PREMUL_4X16LE 0xA200BBBB
 #define WUFFS_BASE__PIXEL_FORMAT__RGBA_BINARY 0xA3008888
@@ -425,6 +468,8 @@ typedef struct wuffs_base__pixel_subsampling__struct {
   inline uint32_t denominator_x(uint32_t plane) const;
   inline uint32_t bias_y(uint32_t plane) const;
   inline uint32_t denominator_y(uint32_t plane) const;
+  inline uint64_t num_samples_x(uint32_t plane, uint32_t width) const;
+  inline uint64_t num_samples_y(uint32_t plane, uint32_t height) const;
 #endif  // __cplusplus
 
 } wuffs_base__pixel_subsampling;
@@ -475,6 +520,38 @@ wuffs_base__pixel_subsampling__denominator_y(
   return ((s->repr >> shift) & 0x03) + 1;
 }
 
+// wuffs_base__pixel_subsampling__num_samples_x returns the number of sample
+// columns, in the given plane, that cover width pixel columns.
+static inline uint64_t  //
+wuffs_base__pixel_subsampling__num_samples_x(
+    const wuffs_base__pixel_subsampling* s,
+    uint32_t plane,
+    uint32_t width) {
+  if (width == 0) {
+    return 0;
+  }
+  return ((((uint64_t)width) - 1 +
+           wuffs_base__pixel_subsampling__bias_x(s, plane)) /
+          wuffs_base__pixel_subsampling__denominator_x(s, plane)) +
+         1;
+}
+
+// wuffs_base__pixel_subsampling__num_samples_y returns the number of sample
+// rows, in the given plane, that cover height pixel rows.
+static inline uint64_t  //
+wuffs_base__pixel_subsampling__num_samples_y(
+    const wuffs_base__pixel_subsampling* s,
+    uint32_t plane,
+    uint32_t height) {
+  if (height == 0) {
+    return 0;
+  }
+  return ((((uint64_t)height) - 1 +
+           wuffs_base__pixel_subsampling__bias_y(s, plane)) /
+          wuffs_base__pixel_subsampling__denominator_y(s, plane)) +
+         1;
+}
+
 #ifdef __cplusplus
 
 inline uint32_t  //
@@ -497,6 +574,18 @@ wuffs_base__pixel_subsampling::denominator_y(uint32_t plane) const {
   return wuffs_base__pixel_subsampling__denominator_y(this, plane);
 }
 
+inline uint64_t  //
+wuffs_base__pixel_subsampling::num_samples_x(uint32_t plane,
+                                             uint32_t width) const {
+  return wuffs_base__pixel_subsampling__num_samples_x(this, plane, width);
+}
+
+inline uint64_t  //
+wuffs_base__pixel_subsampling::num_samples_y(uint32_t plane,
+                                             uint32_t height) const {
+  return wuffs_base__pixel_subsampling__num_samples_y(this, plane, height);
+}
+
 #endif  // __cplusplus
 
 // --------
@@ -529,7 +618,7 @@ typedef struct wuffs_base__pixel_config__struct {
 } wuffs_base__pixel_config;
 
 static inline wuffs_base__pixel_config  //
-wuffs_base__null_pixel_config() {
+wuffs_base__null_pixel_config(void) {
   wuffs_base__pixel_config ret;
   ret.private_impl.pixfmt.repr = 0;
   ret.private_impl.pixsub.repr = 0;
@@ -620,17 +709,60 @@ wuffs_base__pixel_config__height(const wuffs_base__pixel_config* c) {
   return c ? c->private_impl.height : 0;
 }
 
-// TODO: this is the right API for planar (not interleaved) pixbufs? Should it
-// allow decoding into a color model different from the format's intrinsic one?
-// For example, decoding a JPEG image straight to RGBA instead of to YCbCr?
+// wuffs_base__private_implementation__pixel_config__plane_sizes sets the
+// widths and heights (in bytes and rows) of each plane of a planar pixel
+// configuration, conscious of pixel subsampling. It returns the number of
+// planes, or zero if the configuration is not planar or if any plane does not
+// hold exactly 8 bits per sample.
+static inline uint32_t  //
+wuffs_base__private_implementation__pixel_config__plane_sizes(
+    const wuffs_base__pixel_config* c,
+    uint64_t widths[WUFFS_BASE__PIXEL_FORMAT__NUM_PLANES_MAX],
+    uint64_t heights[WUFFS_BASE__PIXEL_FORMAT__NUM_PLANES_MAX]) {
+  if (!wuffs_base__pixel_format__is_planar(&c->private_impl.pixfmt)) {
+    return 0;
+  }
+  uint32_t num_planes =
+      wuffs_base__pixel_format__num_planes(&c->private_impl.pixfmt);
+  uint32_t p;
+  for (p = 0; p < num_planes; p++) {
+    if (((c->private_impl.pixfmt.repr >> (4 * p)) & 0x0F) != 8) {
+      return 0;
+    }
+    widths[p] = wuffs_base__pixel_subsampling__num_samples_x(
+        &c->private_impl.pixsub, p, c->private_impl.width);
+    heights[p] = wuffs_base__pixel_subsampling__num_samples_y(
+        &c->private_impl.pixsub, p, c->private_impl.height);
+  }
+  return num_planes;
+}
+
+// TODO: should it allow decoding into a color model different from the
+// format's intrinsic one? For example, decoding a JPEG image straight to RGBA
+// instead of to YCbCr?
 static inline uint64_t  //
 wuffs_base__pixel_config__pixbuf_len(const wuffs_base__pixel_config* c) {
   if (!c) {
     return 0;
   }
   if (wuffs_base__pixel_format__is_planar(&c->private_impl.pixfmt)) {
-    // TODO: support planar pixel formats, concious of pixel subsampling.
-    return 0;
+    // Planes are laid out consecutively, each with a stride equal to its
+    // width (in samples). Limiting each plane's size to (UINT64_MAX / 4)
+    // means that the sum of up to 4 of them cannot overflow.
+    uint64_t widths[WUFFS_BASE__PIXEL_FORMAT__NUM_PLANES_MAX];
+    uint64_t heights[WUFFS_BASE__PIXEL_FORMAT__NUM_PLANES_MAX];
+    uint32_t num_planes =
+        wuffs_base__private_implementation__pixel_config__plane_sizes(
+            c, widths, heights);
+    uint64_t n = 0;
+    uint32_t p;
+    for (p = 0; p < num_planes; p++) {
+      if ((heights[p] > 0) && (widths[p] > ((UINT64_MAX / 4) / heights[p]))) {
+        return 0;
+      }
+      n += widths[p] * heights[p];
+    }
+    return n;
   }
   uint32_t bits_per_pixel =
       wuffs_base__pixel_format__bits_per_pixel(&c->private_impl.pixfmt);
@@ -738,7 +870,7 @@ typedef struct wuffs_base__image_config__struct {
 } wuffs_base__image_config;
 
 static inline wuffs_base__image_config  //
-wuffs_base__null_image_config() {
+wuffs_base__null_image_config(void) {
   wuffs_base__image_config ret;
   ret.pixcfg = wuffs_base__null_pixel_config();
   ret.private_impl.first_frame_io_position = 0;
@@ -898,7 +1030,7 @@ typedef struct wuffs_base__frame_config__struct {
 } wuffs_base__frame_config;
 
 static inline wuffs_base__frame_config  //
-wuffs_base__null_frame_config() {
+wuffs_base__null_frame_config(void) {
   wuffs_base__frame_config ret;
   ret.private_impl.bounds = wuffs_base__make_rect_ie_u32(0, 0, 0, 0);
   ret.private_impl.duration = 0;
@@ -1094,6 +1226,31 @@ wuffs_base__frame_config::background_color() const {
 
 // --------
 
+// WUFFS_BASE__ORIENTATION__ETC are the EXIF (and TIFF) orientation values.
+// Each one's name says which visual sides of the image are the stored image's
+// first row and first column. For example, RIGHT_TOP (which cameras write
+// when rotated a quarter turn) means that the stored image needs rotating 90
+// degrees clockwise for display. The comments below say how the stored image
+// differs from the displayed image.
+#define WUFFS_BASE__ORIENTATION__TOP_LEFT 1      // The same.
+#define WUFFS_BASE__ORIENTATION__TOP_RIGHT 2     // Mirrored horizontally.
+#define WUFFS_BASE__ORIENTATION__BOTTOM_RIGHT 3  // Rotated 180 degrees.
+#define WUFFS_BASE__ORIENTATION__BOTTOM_LEFT 4   // Mirrored vertically.
+#define WUFFS_BASE__ORIENTATION__LEFT_TOP 5      // Transposed.
+#define WUFFS_BASE__ORIENTATION__RIGHT_TOP 6     // Rotated anticlockwise.
+#define WUFFS_BASE__ORIENTATION__RIGHT_BOTTOM 7  // Transversed.
+#define WUFFS_BASE__ORIENTATION__LEFT_BOTTOM 8   // Rotated clockwise.
+
+// wuffs_base__orientation__swaps_width_and_height returns whether applying
+// the orientation turns a W x H image into an H x W image.
+static inline bool  //
+wuffs_base__orientation__swaps_width_and_height(uint32_t orientation) {
+  return (WUFFS_BASE__ORIENTATION__LEFT_TOP <= orientation) &&
+         (orientation <= WUFFS_BASE__ORIENTATION__LEFT_BOTTOM);
+}
+
+// --------
+
 typedef struct wuffs_base__pixel_buffer__struct {
   wuffs_base__pixel_config pixcfg;
 
@@ -1128,12 +1285,15 @@ typedef struct wuffs_base__pixel_buffer__struct {
   inline wuffs_base__status set_color_u32_fill_rect(
       wuffs_base__rect_ie_u32 rect,
       wuffs_base__color_u32_argb_premul color);
+  inline wuffs_base__status apply_orientation(
+      const wuffs_base__pixel_buffer__struct* src,
+      uint32_t orientation);
 #endif  // __cplusplus
 
 } wuffs_base__pixel_buffer;
 
 static inline wuffs_base__pixel_buffer  //
-wuffs_base__null_pixel_buffer() {
+wuffs_base__null_pixel_buffer(void) {
   wuffs_base__pixel_buffer ret;
   ret.pixcfg = wuffs_base__null_pixel_config();
   ret.private_impl.planes[0] = wuffs_base__empty_table_u8();
@@ -1204,8 +1364,36 @@ wuffs_base__pixel_buffer__set_from_slice(wuffs_base__pixel_buffer* pb,
     return wuffs_base__make_status(wuffs_base__error__bad_argument);
   }
   if (wuffs_base__pixel_format__is_planar(&pixcfg->private_impl.pixfmt)) {
-    // TODO: support planar pixel formats, concious of pixel subsampling.
-    return wuffs_base__make_status(wuffs_base__error__unsupported_option);
+    // Split pixbuf_memory into consecutive planes, as per
+    // wuffs_base__pixel_config__pixbuf_len.
+    uint64_t widths[WUFFS_BASE__PIXEL_FORMAT__NUM_PLANES_MAX];
+    uint64_t heights[WUFFS_BASE__PIXEL_FORMAT__NUM_PLANES_MAX];
+    uint32_t num_planes =
+        wuffs_base__private_implementation__pixel_config__plane_sizes(
+            pixcfg, widths, heights);
+    if (num_planes == 0) {
+      return wuffs_base__make_status(wuffs_base__error__unsupported_option);
+    }
+    uint8_t* ptr = pixbuf_memory.ptr;
+    uint64_t len = pixbuf_memory.len;
+    uint32_t p;
+    for (p = 0; p < num_planes; p++) {
+      if ((widths[p] > SIZE_MAX) || (heights[p] > SIZE_MAX) ||
+          ((heights[p] > 0) && (widths[p] > (len / heights[p])))) {
+        memset(pb, 0, sizeof(*pb));
+        return wuffs_base__make_status(
+            wuffs_base__error__bad_argument_length_too_short);
+      }
+      wuffs_base__table_u8* tab = &pb->private_impl.planes[p];
+      tab->ptr = ptr;
+      tab->width = (size_t)(widths[p]);
+      tab->height = (size_t)(heights[p]);
+      tab->stride = (size_t)(widths[p]);
+      ptr += widths[p] * heights[p];
+      len -= widths[p] * heights[p];
+    }
+    pb->pixcfg = *pixcfg;
+    return wuffs_base__make_status(NULL);
   }
   uint32_t bits_per_pixel =
       wuffs_base__pixel_format__bits_per_pixel(&pixcfg->private_impl.pixfmt);
@@ -1374,6 +1562,29 @@ wuffs_base__pixel_buffer__set_color_u32_fill_rect(
     wuffs_base__rect_ie_u32 rect,
     wuffs_base__color_u32_argb_premul color);
 
+// wuffs_base__pixel_buffer__apply_orientation sets dst to src with the
+// orientation (one of the WUFFS_BASE__ORIENTATION__ETC values) undone, so
+// that dst is the image as it should be displayed.
+//
+// The two pixel buffers must have the same pixel format, which must be
+// interleaved and have a whole number of bytes per pixel. If
+// wuffs_base__orientation__swaps_width_and_height then dst's width and height
+// must be src's height and width, otherwise they must be src's width and
+// height. Indexed src palettes are copied to dst.
+//
+// dst and src may be the same pixel buffer, to apply the orientation in
+// place, but only if the width and height are equal or the orientation does
+// not swap them. Otherwise, their pixels must not overlap.
+//
+// For modular builds that divide the base module into sub-modules, using this
+// function requires the WUFFS_CONFIG__MODULE__BASE__PIXCONV sub-module, not
+// just WUFFS_CONFIG__MODULE__BASE__CORE.
+WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
+wuffs_base__pixel_buffer__apply_orientation(
+    wuffs_base__pixel_buffer* dst,
+    const wuffs_base__pixel_buffer* src,
+    uint32_t orientation);
+
 #ifdef __cplusplus
 
 inline wuffs_base__status  //
@@ -1426,12 +1637,6 @@ wuffs_base__pixel_buffer::color_u32_at(uint32_t x, uint32_t y) const {
   return wuffs_base__pixel_buffer__color_u32_at(this, x, y);
 }
 
-WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
-wuffs_base__pixel_buffer__set_color_u32_fill_rect(
-    wuffs_base__pixel_buffer* pb,
-    wuffs_base__rect_ie_u32 rect,
-    wuffs_base__color_u32_argb_premul color);
-
 inline wuffs_base__status  //
 wuffs_base__pixel_buffer::set_color_u32_at(
     uint32_t x,
@@ -1447,28 +1652,680 @@ wuffs_base__pixel_buffer::set_color_u32_fill_rect(
   return wuffs_base__pixel_buffer__set_color_u32_fill_rect(this, rect, color);
 }
 
+inline wuffs_base__status  //
+wuffs_base__pixel_buffer::apply_orientation(
+    const wuffs_base__pixel_buffer* src,
+    uint32_t orientation) {
+  return wuffs_base__pixel_buffer__apply_orientation(this, src, orientation);
+}
+
 #endif  // __cplusplus
 
 // --------
 
+// wuffs_base__color_transfer_function maps encoded color values in [0, 1] to
+// linear light values in [0, 1]. It is also known as a tone reproduction curve
+// (TRC) or a gamma curve. When table_len is zero, it is the ICC specification's
+// parametric curve (function type 4):
+//
+//  - y = (c*x + f)        when x <  d
+//  - y = (a*x + b)^g + e  when x >= d
+//
+// When table_len is non-zero, it is a sampled curve instead: table_len
+// big-endian uint16_t values (so 2*table_len bytes) at table_ptr, linearly
+// interpolated. The table is not copied. It typically points into the bytes
+// of an ICC profile, which must outlive any use of the function.
+typedef struct wuffs_base__color_transfer_function__struct {
+  double g;
+  double a;
+  double b;
+  double c;
+  double d;
+  double e;
+  double f;
+  const uint8_t* table_ptr;
+  uint32_t table_len;
+} wuffs_base__color_transfer_function;
+
+static inline wuffs_base__color_transfer_function  //
+wuffs_base__make_color_transfer_function__gamma(double g) {
+  wuffs_base__color_transfer_function ret;
+  ret.g = g;
+  ret.a = 1.0;
+  ret.b = 0.0;
+  ret.c = 0.0;
+  ret.d = 0.0;
+  ret.e = 0.0;
+  ret.f = 0.0;
+  ret.table_ptr = NULL;
+  ret.table_len = 0;
+  return ret;
+}
+
+// wuffs_base__make_color_transfer_function__srgb returns the sRGB transfer
+// function, which Display-P3 also uses.
+static inline wuffs_base__color_transfer_function  //
+wuffs_base__make_color_transfer_function__srgb(void) {
+  wuffs_base__color_transfer_function ret;
+  ret.g = 2.4;
+  ret.a = 1.0 / 1.055;
+  ret.b = 0.055 / 1.055;
+  ret.c = 1.0 / 12.92;
+  ret.d = 0.04045;
+  ret.e = 0.0;
+  ret.f = 0.0;
+  ret.table_ptr = NULL;
+  ret.table_len = 0;
+  return ret;
+}
+
+// wuffs_base__color_transfer_function__eval returns the linear value for the
+// encoded value x, which is clamped to [0, 1], as is the result.
+//
+// For modular builds that divide the base module into sub-modules, using this
+// function requires the WUFFS_CONFIG__MODULE__BASE__PIXCONV sub-module, not
+// just WUFFS_CONFIG__MODULE__BASE__CORE.
+WUFFS_BASE__MAYBE_STATIC double  //
+wuffs_base__color_transfer_function__eval(
+    const wuffs_base__color_transfer_function* f,
+    double x);
+
+// --------
+
+// ICC profile signatures, as big-endian uint32_t values, for
+// wuffs_base__color_icc's color_space and pcs.
+#define WUFFS_BASE__COLOR_ICC__SIGNATURE__GRAY 0x47524159  // "GRAY"
+#define WUFFS_BASE__COLOR_ICC__SIGNATURE__LAB 0x4C616220   // "Lab "
+#define WUFFS_BASE__COLOR_ICC__SIGNATURE__RGB 0x52474220   // "RGB "
+#define WUFFS_BASE__COLOR_ICC__SIGNATURE__XYZ 0x58595A20   // "XYZ "
+
+// wuffs_base__color_icc holds the parsed header of an ICC color profile and,
+// for RGB profiles whose tags include a 3x3 matrix and three TRCs (tone
+// reproduction curves), those tags. Such "matrix/TRC" profiles are the common
+// case for images and are what wuffs_base__color_transform supports.
+//
+// The ICC profile format is specified at
+// https://www.color.org/specification/ICC.1-2022-05.pdf
+typedef struct wuffs_base__color_icc__struct {
+  // Do not access the private_impl's fields directly. There is no API/ABI
+  // compatibility or safety guarantee if you do so.
+  struct {
+    uint32_t profile_version;
+    uint32_t device_class;
+    uint32_t color_space;
+    uint32_t pcs;
+    uint32_t rendering_intent;
+    bool has_matrix_trc;
+    double to_xyz_d50[9];
+    wuffs_base__color_transfer_function trcs[3];
+  } private_impl;
+
+#ifdef __cplusplus
+  inline wuffs_base__status parse(wuffs_base__slice_u8 src);
+  inline void set_srgb();
+  inline void set_display_p3();
+  inline uint32_t profile_version() const;
+  inline uint32_t device_class() const;
+  inline uint32_t color_space() const;
+  inline uint32_t pcs() const;
+  inline uint32_t rendering_intent() const;
+  inline bool has_matrix_trc() const;
+#endif  // __cplusplus
+
+} wuffs_base__color_icc;
+
+// wuffs_base__color_icc__parse parses the ICC profile in src. It returns
+// wuffs_base__error__bad_data if src is not a well-formed profile. Parsing a
+// profile that is well-formed but not a matrix/TRC profile (e.g. a CMYK or a
+// LUT-based profile) still succeeds, but has_matrix_trc will be false.
+//
+// Sampled TRCs point into src, which must outlive c.
+//
+// For modular builds that divide the base module into sub-modules, using this
+// function requires the WUFFS_CONFIG__MODULE__BASE__PIXCONV sub-module, not
+// just WUFFS_CONFIG__MODULE__BASE__CORE.
+WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
+wuffs_base__color_icc__parse(wuffs_base__color_icc* c,
+                             wuffs_base__slice_u8 src);
+
+// wuffs_base__color_icc__set_srgb and wuffs_base__color_icc__set_display_p3
+// set c to be the sRGB or Display-P3 matrix/TRC profile.
+//
+// For modular builds that divide the base module into sub-modules, using these
+// functions requires the WUFFS_CONFIG__MODULE__BASE__PIXCONV sub-module, not
+// just WUFFS_CONFIG__MODULE__BASE__CORE.
+WUFFS_BASE__MAYBE_STATIC void  //
+wuffs_base__color_icc__set_srgb(wuffs_base__color_icc* c);
+
+WUFFS_BASE__MAYBE_STATIC void  //
+wuffs_base__color_icc__set_display_p3(wuffs_base__color_icc* c);
+
+// wuffs_base__color_icc__profile_version returns the profile's version, such
+// as 0x04300000 for version 4.3.0.0.
+static inline uint32_t  //
+wuffs_base__color_icc__profile_version(const wuffs_base__color_icc* c) {
+  return c ? c->private_impl.profile_version : 0;
+}
+
+// wuffs_base__color_icc__device_class returns the profile's device class
+// signature, such as 0x6D6E7472 ("mntr", for a display device).
+static inline uint32_t  //
+wuffs_base__color_icc__device_class(const wuffs_base__color_icc* c) {
+  return c ? c->private_impl.device_class : 0;
+}
+
+// wuffs_base__color_icc__color_space returns the profile's data color space
+// signature, such as WUFFS_BASE__COLOR_ICC__SIGNATURE__RGB.
+static inline uint32_t  //
+wuffs_base__color_icc__color_space(const wuffs_base__color_icc* c) {
+  return c ? c->private_impl.color_space : 0;
+}
+
+// wuffs_base__color_icc__pcs returns the profile's profile connection space
+// signature, either WUFFS_BASE__COLOR_ICC__SIGNATURE__XYZ or
+// WUFFS_BASE__COLOR_ICC__SIGNATURE__LAB.
+static inline uint32_t  //
+wuffs_base__color_icc__pcs(const wuffs_base__color_icc* c) {
+  return c ? c->private_impl.pcs : 0;
+}
+
+// wuffs_base__color_icc__rendering_intent returns the profile's rendering
+// intent: 0 (perceptual), 1 (media-relative colorimetric), 2 (saturation) or 3
+// (ICC-absolute colorimetric).
+static inline uint32_t  //
+wuffs_base__color_icc__rendering_intent(const wuffs_base__color_icc* c) {
+  return c ? c->private_impl.rendering_intent : 0;
+}
+
+static inline bool  //
+wuffs_base__color_icc__has_matrix_trc(const wuffs_base__color_icc* c) {
+  return c && c->private_impl.has_matrix_trc;
+}
+
+#ifdef __cplusplus
+
+inline wuffs_base__status  //
+wuffs_base__color_icc::parse(wuffs_base__slice_u8 src) {
+  return wuffs_base__color_icc__parse(this, src);
+}
+
+inline void  //
+wuffs_base__color_icc::set_srgb() {
+  wuffs_base__color_icc__set_srgb(this);
+}
+
+inline void  //
+wuffs_base__color_icc::set_display_p3() {
+  wuffs_base__color_icc__set_display_p3(this);
+}
+
+inline uint32_t  //
+wuffs_base__color_icc::profile_version() const {
+  return wuffs_base__color_icc__profile_version(this);
+}
+
+inline uint32_t  //
+wuffs_base__color_icc::device_class() const {
+  return wuffs_base__color_icc__device_class(this);
+}
+
+inline uint32_t  //
+wuffs_base__color_icc::color_space() const {
+  return wuffs_base__color_icc__color_space(this);
+}
+
+inline uint32_t  //
+wuffs_base__color_icc::pcs() const {
+  return wuffs_base__color_icc__pcs(this);
+}
+
+inline uint32_t  //
+wuffs_base__color_icc::rendering_intent() const {
+  return wuffs_base__color_icc__rendering_intent(this);
+}
+
+inline bool  //
+wuffs_base__color_icc::has_matrix_trc() const {
+  return wuffs_base__color_icc__has_matrix_trc(this);
+}
+
+#endif  // __cplusplus
+
+// --------
+
+// wuffs_base__color_transform converts 8-bit-per-channel pixels from one
+// matrix/TRC color profile to another, such as from Display-P3 to sRGB. Each
+// pixel's color channels are linearized by the source TRCs, multiplied by the
+// source-to-XYZ and XYZ-to-destination matrices (with out-of-gamut values
+// clamped) and then encoded by the inverse of the destination TRCs.
+//
+// It is about 16 KiB in size. It uses lookup tables, computed once by
+// wuffs_base__color_transform__prepare, so that applying it is cheap.
+typedef struct wuffs_base__color_transform__struct {
+  // Do not access the private_impl's fields directly. There is no API/ABI
+  // compatibility or safety guarantee if you do so.
+  struct {
+    bool is_identity;
+    float matrix[9];
+    float src_luts[3][256];
+    uint8_t dst_luts[3][4096];
+  } private_impl;
+
+#ifdef __cplusplus
+  inline wuffs_base__status prepare(const wuffs_base__color_icc* src,
+                                    const wuffs_base__color_icc* dst);
+  inline uint64_t apply(wuffs_base__slice_u8 pixels,
+                        wuffs_base__pixel_format pixfmt) const;
+#endif  // __cplusplus
+
+} wuffs_base__color_transform;
+
+// wuffs_base__color_transform__prepare readies t to convert from the src
+// profile to the dst profile. A NULL dst means sRGB. It returns
+// wuffs_base__error__unsupported_option if either profile is not a
+// matrix/TRC profile.
+//
+// For modular builds that divide the base module into sub-modules, using this
+// function requires the WUFFS_CONFIG__MODULE__BASE__PIXCONV sub-module, not
+// just WUFFS_CONFIG__MODULE__BASE__CORE.
+WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
+wuffs_base__color_transform__prepare(wuffs_base__color_transform* t,
+                                     const wuffs_base__color_icc* src,
+                                     const wuffs_base__color_icc* dst);
+
+// wuffs_base__color_transform__supports_pixel_format returns whether
+// wuffs_base__color_transform__apply can convert pixels in that format: the
+// BGR, BGRA_NONPREMUL, BGRX, RGB, RGBA_NONPREMUL and RGBX formats. Alpha
+// channels are left unchanged.
+static inline bool  //
+wuffs_base__color_transform__supports_pixel_format(
+    wuffs_base__pixel_format pixfmt) {
+  switch (pixfmt.repr) {
+    case WUFFS_BASE__PIXEL_FORMAT__BGR:
+    case WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL:
+    case WUFFS_BASE__PIXEL_FORMAT__BGRX:
+    case WUFFS_BASE__PIXEL_FORMAT__RGB:
+    case WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL:
+    case WUFFS_BASE__PIXEL_FORMAT__RGBX:
+      return true;
+  }
+  return false;
+}
+
+// wuffs_base__color_transform__apply converts pixels, in place. It returns the
+// number of pixels converted, which is zero if the pixel format is not
+// supported.
+//
+// For modular builds that divide the base module into sub-modules, using this
+// function requires the WUFFS_CONFIG__MODULE__BASE__PIXCONV sub-module, not
+// just WUFFS_CONFIG__MODULE__BASE__CORE.
+WUFFS_BASE__MAYBE_STATIC uint64_t  //
+wuffs_base__color_transform__apply(const wuffs_base__color_transform* t,
+                                   wuffs_base__slice_u8 pixels,
+                                   wuffs_base__pixel_format pixfmt);
+
+#ifdef __cplusplus
+
+inline wuffs_base__status  //
+wuffs_base__color_transform::prepare(const wuffs_base__color_icc* src,
+                                     const wuffs_base__color_icc* dst) {
+  return wuffs_base__color_transform__prepare(this, src, dst);
+}
+
+inline uint64_t  //
+wuffs_base__color_transform::apply(wuffs_base__slice_u8 pixels,
+                                   wuffs_base__pixel_format pixfmt) const {
+  return wuffs_base__color_transform__apply(this, pixels, pixfmt);
+}
+
+#endif  // __cplusplus
+
+// --------
+
+// wuffs_base__pixel_dither is a dithering algorithm, used when converting
+// pixels to a destination pixel format with fewer colors.
+typedef uint8_t wuffs_base__pixel_dither;
+
+#define WUFFS_BASE__PIXEL_DITHER__NONE ((wuffs_base__pixel_dither)0)
+#define WUFFS_BASE__PIXEL_DITHER__ORDERED ((wuffs_base__pixel_dither)1)
+#define WUFFS_BASE__PIXEL_DITHER__FLOYD_STEINBERG ((wuffs_base__pixel_dither)2)
+
+// wuffs_base__pixel_ditherer holds the state for dithering one frame's worth
+// of pixels: ORDERED uses a 4x4 Bayer matrix and FLOYD_STEINBERG diffuses
+// each pixel's quantization error to its not-yet-converted neighbors.
+//
+// Pixels are assumed to arrive in raster order, width pixels per row, which
+// is how decoders write non-interlaced frames. Interlaced frames are still
+// dithered, but with a less regular pattern.
+//
+// FLOYD_STEINBERG needs a work buffer (owned by the caller, which must
+// outlive the ditherer) of at least wuffs_base__pixel_ditherer__workbuf_len
+// bytes. ORDERED needs no work buffer.
+typedef struct wuffs_base__pixel_ditherer__struct {
+  // Do not access the private_impl's fields directly. There is no API/ABI
+  // compatibility or safety guarantee if you do so.
+  struct {
+    wuffs_base__pixel_dither dither;
+    uint32_t width;
+    uint32_t x;
+    uint32_t y;
+    // Floyd-Steinberg's errors are in sixteenths. carry is for the next pixel
+    // on this row. pending_etc are for the next row, at x-1 and x. workbuf
+    // holds 3 int16_t (little-endian) per pixel of the next row.
+    int32_t carry[3];
+    int32_t pending0[3];
+    int32_t pending1[3];
+    wuffs_base__slice_u8 workbuf;
+  } private_impl;
+
+#ifdef __cplusplus
+  inline wuffs_base__status initialize(wuffs_base__pixel_dither dither,
+                                       uint32_t width,
+                                       wuffs_base__slice_u8 workbuf);
+  inline void reset();
+#endif  // __cplusplus
+
+} wuffs_base__pixel_ditherer;
+
+// wuffs_base__pixel_ditherer__workbuf_len returns the minimum work buffer
+// length, in bytes, for wuffs_base__pixel_ditherer__initialize.
+static inline uint64_t  //
+wuffs_base__pixel_ditherer__workbuf_len(wuffs_base__pixel_dither dither,
+                                        uint32_t width) {
+  return (dither == WUFFS_BASE__PIXEL_DITHER__FLOYD_STEINBERG)
+             ? (6 * ((uint64_t)width))
+             : 0;
+}
+
+// wuffs_base__pixel_ditherer__reset restarts d at the top-left pixel, with no
+// accumulated error. wuffs_base__pixel_swizzler__set_ditherer calls it, so
+// that each frame starts afresh.
+static inline void  //
+wuffs_base__pixel_ditherer__reset(wuffs_base__pixel_ditherer* d) {
+  if (!d) {
+    return;
+  }
+  d->private_impl.x = 0;
+  d->private_impl.y = 0;
+  uint32_t i;
+  for (i = 0; i < 3; i++) {
+    d->private_impl.carry[i] = 0;
+    d->private_impl.pending0[i] = 0;
+    d->private_impl.pending1[i] = 0;
+  }
+  if (d->private_impl.workbuf.len > 0) {
+    memset(d->private_impl.workbuf.ptr, 0, d->private_impl.workbuf.len);
+  }
+}
+
+// wuffs_base__pixel_ditherer__initialize readies d to dither rows of width
+// pixels. It returns wuffs_base__error__bad_argument if dither is not a
+// WUFFS_BASE__PIXEL_DITHER__ETC constant, width is zero or workbuf is too
+// short.
+static inline wuffs_base__status  //
+wuffs_base__pixel_ditherer__initialize(wuffs_base__pixel_ditherer* d,
+                                       wuffs_base__pixel_dither dither,
+                                       uint32_t width,
+                                       wuffs_base__slice_u8 workbuf) {
+  if (!d) {
+    return wuffs_base__make_status(wuffs_base__error__bad_receiver);
+  } else if ((dither > WUFFS_BASE__PIXEL_DITHER__FLOYD_STEINBERG) ||
+             (width == 0) ||
+             (((uint64_t)workbuf.len) <
+              wuffs_base__pixel_ditherer__workbuf_len(dither, width))) {
+    return wuffs_base__make_status(wuffs_base__error__bad_argument);
+  }
+  d->private_impl.dither = dither;
+  d->private_impl.width = width;
+  d->private_impl.workbuf = wuffs_base__make_slice_u8(
+      workbuf.ptr,
+      (size_t)wuffs_base__pixel_ditherer__workbuf_len(dither, width));
+  wuffs_base__pixel_ditherer__reset(d);
+  return wuffs_base__make_status(NULL);
+}
+
+#ifdef __cplusplus
+
+inline wuffs_base__status  //
+wuffs_base__pixel_ditherer::initialize(wuffs_base__pixel_dither dither,
+                                       uint32_t width,
+                                       wuffs_base__slice_u8 workbuf) {
+  return wuffs_base__pixel_ditherer__initialize(this, dither, width, workbuf);
+}
+
+inline void  //
+wuffs_base__pixel_ditherer::reset() {
+  wuffs_base__pixel_ditherer__reset(this);
+}
+
+#endif  // __cplusplus
+
+// --------
+
+// wuffs_base__decode_frame_options holds optional arguments to an image
+// decoder's decode_frame method. A NULL pointer is equivalent to a zero value.
+//
+// A non-zero row_group_height opts in to row group decoding. Instead of the
+// destination pixel buffer holding the whole frame, it only needs to hold
+// row_group_height rows (or fewer, for the final group). Each time that a group
+// of rows is complete, decode_frame returns the
+// wuffs_base__note__row_group_decoded note and the decoder's frame_dirty_rect
+// method returns the frame rows that the group covers. Frame row y is written
+// to pixel buffer row (y - frame_dirty_rect.min_incl_y). Calling decode_frame
+// again, with the same arguments, resumes decoding into the same pixel buffer
+// rows, overwriting the previous group. This lets a caller process or discard
+// a very large image's rows incrementally.
+//
+// Groups are usually yielded top-down, but some decoders (e.g. for bottom-up
+// BMP images) yield them bottom-up.
+//
+// Not every decoder supports row group decoding, or supports it for every
+// frame (e.g. interlaced GIF or PNG frames, or RLE-compressed BMP images).
+// Those that don't will return wuffs_base__error__unsupported_option when
+// row_group_height is non-zero.
+//
+// A non-NULL color_transform asks the decoder to convert the decoded pixels
+// (as it writes them to the destination pixel buffer) with that transform,
+// typically one prepared from the image's ICC profile to sRGB. The transform
+// is not copied and must outlive the decode_frame calls. This requires the
+// WUFFS_BASE__PIXEL_BLEND__SRC blend and a destination pixel format for which
+// wuffs_base__color_transform__supports_pixel_format is true. Decoders (such
+// as std/png) that support color transforms return
+// wuffs_base__error__unsupported_option when those requirements are not met.
+// Other decoders ignore color_transform.
+//
+// A non-NULL ditherer asks the decoder to dither the decoded pixels when
+// converting them to a destination pixel format with fewer colors: BGR_565
+// or INDEXED__BGRA_BINARY (or INDEXED__BGRA_NONPREMUL), whose palette is the
+// pixel buffer's. The ditherer is not copied and must outlive the
+// decode_frame calls. It is reset at the start of each frame and its width
+// should be the frame's width. This requires the WUFFS_BASE__PIXEL_BLEND__SRC
+// blend and a truecolor (not indexed) source. As for color_transform,
+// decoders that support dithering otherwise return
+// wuffs_base__error__unsupported_option and other decoders ignore ditherer.
+//
+// A true report_passes opts in to pass notifications for interlaced (or
+// otherwise multi-pass) frames, such as interlaced GIF and Adam7 PNG. Each
+// time that one or more passes (but not the final pass) complete,
+// decode_frame returns the wuffs_base__note__pass_decoded note. At that point,
+// the destination pixel buffer holds a usable, lower-fidelity image, within
+// the decoder's frame_dirty_rect, that a caller can display while the rest of
+// the frame streams in. Calling decode_frame again, with the same arguments,
+// resumes decoding. Decoders for single-pass frames ignore report_passes.
 typedef struct wuffs_base__decode_frame_options__struct {
   // Do not access the private_impl's fields directly. There is no API/ABI
   // compatibility or safety guarantee if you do so.
   struct {
-    uint8_t TODO;
+    uint32_t row_group_height;
+    const wuffs_base__color_transform* color_transform;
+    bool report_passes;
+    wuffs_base__pixel_ditherer* ditherer;
   } private_impl;
 
 #ifdef __cplusplus
+  inline void set_row_group_height(uint32_t h);
+  inline uint32_t row_group_height() const;
+  inline void set_color_transform(const wuffs_base__color_transform* t);
+  inline const wuffs_base__color_transform* color_transform() const;
+  inline void set_report_passes(bool r);
+  inline bool report_passes() const;
+  inline void set_ditherer(wuffs_base__pixel_ditherer* d);
+  inline wuffs_base__pixel_ditherer* ditherer() const;
 #endif  // __cplusplus
 
 } wuffs_base__decode_frame_options;
 
+static inline wuffs_base__decode_frame_options  //
+wuffs_base__null_decode_frame_options(void) {
+  wuffs_base__decode_frame_options ret;
+  ret.private_impl.row_group_height = 0;
+  ret.private_impl.color_transform = NULL;
+  ret.private_impl.report_passes = false;
+  ret.private_impl.ditherer = NULL;
+  return ret;
+}
+
+static inline void  //
+wuffs_base__decode_frame_options__set_row_group_height(
+    wuffs_base__decode_frame_options* o,
+    uint32_t h) {
+  if (o) {
+    o->private_impl.row_group_height = h;
+  }
+}
+
+// wuffs_base__decode_frame_options__row_group_height returns the number of
+// rows per row group, or zero if row group decoding is disabled.
+static inline uint32_t  //
+wuffs_base__decode_frame_options__row_group_height(
+    const wuffs_base__decode_frame_options* o) {
+  return o ? o->private_impl.row_group_height : 0;
+}
+
+static inline void  //
+wuffs_base__decode_frame_options__set_color_transform(
+    wuffs_base__decode_frame_options* o,
+    const wuffs_base__color_transform* t) {
+  if (o) {
+    o->private_impl.color_transform = t;
+  }
+}
+
+// wuffs_base__decode_frame_options__color_transform returns the color
+// transform to apply to decoded pixels, or NULL if there is none.
+static inline const wuffs_base__color_transform*  //
+wuffs_base__decode_frame_options__color_transform(
+    const wuffs_base__decode_frame_options* o) {
+  return o ? o->private_impl.color_transform : NULL;
+}
+
+static inline void  //
+wuffs_base__decode_frame_options__set_report_passes(
+    wuffs_base__decode_frame_options* o,
+    bool r) {
+  if (o) {
+    o->private_impl.report_passes = r;
+  }
+}
+
+// wuffs_base__decode_frame_options__report_passes returns whether decode_frame
+// should return a wuffs_base__note__pass_decoded note after each non-final
+// pass of a multi-pass frame.
+static inline bool  //
+wuffs_base__decode_frame_options__report_passes(
+    const wuffs_base__decode_frame_options* o) {
+  return o ? o->private_impl.report_passes : false;
+}
+
+static inline void  //
+wuffs_base__decode_frame_options__set_ditherer(
+    wuffs_base__decode_frame_options* o,
+    wuffs_base__pixel_ditherer* d) {
+  if (o) {
+    o->private_impl.ditherer = d;
+  }
+}
+
+// wuffs_base__decode_frame_options__ditherer returns the ditherer to apply to
+// decoded pixels, or NULL if there is none.
+static inline wuffs_base__pixel_ditherer*  //
+wuffs_base__decode_frame_options__ditherer(
+    const wuffs_base__decode_frame_options* o) {
+  return o ? o->private_impl.ditherer : NULL;
+}
+
 #ifdef __cplusplus
 
+inline void  //
+wuffs_base__decode_frame_options::set_row_group_height(uint32_t h) {
+  wuffs_base__decode_frame_options__set_row_group_height(this, h);
+}
+
+inline uint32_t  //
+wuffs_base__decode_frame_options::row_group_height() const {
+  return wuffs_base__decode_frame_options__row_group_height(this);
+}
+
+inline void  //
+wuffs_base__decode_frame_options::set_color_transform(
+    const wuffs_base__color_transform* t) {
+  wuffs_base__decode_frame_options__set_color_transform(this, t);
+}
+
+inline const wuffs_base__color_transform*  //
+wuffs_base__decode_frame_options::color_transform() const {
+  return wuffs_base__decode_frame_options__color_transform(this);
+}
+
+inline void  //
+wuffs_base__decode_frame_options::set_report_passes(bool r) {
+  wuffs_base__decode_frame_options__set_report_passes(this, r);
+}
+
+inline bool  //
+wuffs_base__decode_frame_options::report_passes() const {
+  return wuffs_base__decode_frame_options__report_passes(this);
+}
+
+inline void  //
+wuffs_base__decode_frame_options::set_ditherer(wuffs_base__pixel_ditherer* d) {
+  wuffs_base__decode_frame_options__set_ditherer(this, d);
+}
+
+inline wuffs_base__pixel_ditherer*  //
+wuffs_base__decode_frame_options::ditherer() const {
+  return wuffs_base__decode_frame_options__ditherer(this);
+}
+
 #endif  // __cplusplus
 
 // --------
 
+// wuffs_base__image_decoder_limits caps the resources that an image decoder
+// will commit to, such as a server's defense against decompression bombs. It
+// is passed to an image decoder's set_limits method. A zero field means that
+// that resource is unlimited, and a zero-valued struct sets no limits at all.
+//
+// The limits are enforced by the decoder itself, once the relevant header has
+// been decoded and before any pixel data is, so that callers do not need to
+// check each format's image_config after the fact. When exceeded, the
+// decode_image_config or decode_frame_config call returns
+// wuffs_base__error__resource_limit_exceeded, and like any other error, this
+// disables the decoder. Specifically:
+//  - max_width, max_height and max_num_pixels (width times height) are
+//    checked against the image's overall dimensions.
+//  - max_workbuf_length is checked against the workbuf_len method's min_incl.
+//  - max_num_frames is checked against the number of frame configs decoded.
+typedef struct wuffs_base__image_decoder_limits__struct {
+  uint32_t max_width;
+  uint32_t max_height;
+  uint64_t max_num_pixels;
+  uint64_t max_workbuf_length;
+  uint64_t max_num_frames;
+} wuffs_base__image_decoder_limits;
+
+// --------
+
 // wuffs_base__pixel_palette__closest_element returns the index of the palette
 // element that minimizes the sum of squared differences of the four ARGB
 // channels, working in premultiplied alpha. Ties favor the smaller index.
@@ -1484,6 +2341,69 @@ wuffs_base__pixel_palette__closest_element(
     wuffs_base__pixel_format palette_format,
     wuffs_base__color_u32_argb_premul c);
 
+// WUFFS_BASE__PIXEL_PALETTE__QUANTIZE__WORKBUF_LEN is the work buffer length,
+// in bytes, that wuffs_base__pixel_palette__quantize needs: 4 bytes for each
+// of the 32768 possible 15-bit (5 bits each for R, G and B) colors.
+#define WUFFS_BASE__PIXEL_PALETTE__QUANTIZE__WORKBUF_LEN 131072
+
+// wuffs_base__pixel_palette__quantize builds a palette of up to
+// max_num_colors (clamped to 256) colors that approximates src's pixels,
+// using the median cut algorithm, and writes it to dst_palette, which must be
+// 1024 bytes long. Each palette color is the average of the src pixels that
+// it stands for. Pixels are then typically converted (and perhaps dithered)
+// by a wuffs_base__pixel_swizzler with an INDEXED__BGRA_ETC destination.
+//
+// Pixels whose alpha is less than 0x80 are treated as transparent. If there
+// are any, the first palette entry is transparent black (0x00000000). Other
+// pixels are treated as opaque. Unused palette entries are also transparent
+// black. The palette is therefore equally valid as INDEXED__BGRA_NONPREMUL,
+// INDEXED__BGRA_PREMUL or INDEXED__BGRA_BINARY. This suits formats such as
+// GIF, whose transparency is binary.
+//
+// On success, the result's value is the number of palette entries used.
+//
+// For modular builds that divide the base module into sub-modules, using this
+// function requires the WUFFS_CONFIG__MODULE__BASE__PIXCONV sub-module, not
+// just WUFFS_CONFIG__MODULE__BASE__CORE.
+WUFFS_BASE__MAYBE_STATIC wuffs_base__result_u64  //
+wuffs_base__pixel_palette__quantize(wuffs_base__slice_u8 dst_palette,
+                                    const wuffs_base__pixel_buffer* src,
+                                    uint32_t max_num_colors,
+                                    wuffs_base__slice_u8 workbuf);
+
+// --------
+
+// wuffs_base__composite_etc composites one row of src pixels over one row of
+// dst pixels, in place, using the Porter-Duff SRC_OVER operator. This is what
+// WUFFS_BASE__PIXEL_BLEND__SRC_OVER means for a frame that does not have
+// wuffs_base__frame_config__overwrite_instead_of_blend set.
+//
+// The function name is "src_over_dst": "nonpremul_over_premul" means that src
+// is non-premultiplied and dst is premultiplied alpha. Both rows hold 4 bytes
+// per pixel, 8 bits per channel. The color channels may be in either BGRA or
+// RGBA order, but dst and src must use the same order.
+//
+// It returns the number of pixels composited, min(dst.len, src.len) / 4.
+//
+// For modular builds that divide the base module into sub-modules, using these
+// functions requires the WUFFS_CONFIG__MODULE__BASE__PIXCONV sub-module, not
+// just WUFFS_CONFIG__MODULE__BASE__CORE.
+WUFFS_BASE__MAYBE_STATIC uint64_t  //
+wuffs_base__composite_nonpremul_over_nonpremul(wuffs_base__slice_u8 dst,
+                                               wuffs_base__slice_u8 src);
+
+WUFFS_BASE__MAYBE_STATIC uint64_t  //
+wuffs_base__composite_nonpremul_over_premul(wuffs_base__slice_u8 dst,
+                                            wuffs_base__slice_u8 src);
+
+WUFFS_BASE__MAYBE_STATIC uint64_t  //
+wuffs_base__composite_premul_over_nonpremul(wuffs_base__slice_u8 dst,
+                                            wuffs_base__slice_u8 src);
+
+WUFFS_BASE__MAYBE_STATIC uint64_t  //
+wuffs_base__composite_premul_over_premul(wuffs_base__slice_u8 dst,
+                                         wuffs_base__slice_u8 src);
+
 // --------
 
 // TODO: should the func type take restrict pointers?
@@ -1508,8 +2428,22 @@ typedef struct wuffs_base__pixel_swizzler__struct {
   struct {
     wuffs_base__pixel_swizzler__func func;
     wuffs_base__pixel_swizzler__transparent_black_func transparent_black_func;
+    // bgrx_func is non-NULL (and func is NULL) when the source is planar
+    // YCbCr. Pixels are converted to an intermediate BGRX row and bgrx_func
+    // converts that row to the destination pixel format.
+    wuffs_base__pixel_swizzler__func bgrx_func;
     uint32_t dst_pixfmt_bytes_per_pixel;
     uint32_t src_pixfmt_bytes_per_pixel;
+    wuffs_base__pixel_format dst_pixfmt;
+    wuffs_base__pixel_blend blend;
+    const wuffs_base__color_transform* color_transform;
+    // quantize_func is non-NULL when the source is truecolor, the blend is
+    // SRC and the destination is BGR_565 or indexed. It converts to an
+    // intermediate BGRA_PREMUL row, which is then quantized (and dithered, if
+    // ditherer is non-NULL) to the destination. This is used instead of func
+    // when func is NULL or ditherer is non-NULL.
+    wuffs_base__pixel_swizzler__func quantize_func;
+    wuffs_base__pixel_ditherer* ditherer;
   } private_impl;
 
 #ifdef __cplusplus
@@ -1518,10 +2452,18 @@ typedef struct wuffs_base__pixel_swizzler__struct {
                                     wuffs_base__pixel_format src_pixfmt,
                                     wuffs_base__slice_u8 src_palette,
                                     wuffs_base__pixel_blend blend);
+  inline wuffs_base__status set_color_transform(
+      const wuffs_base__color_transform* t);
+  inline wuffs_base__status set_ditherer(wuffs_base__pixel_ditherer* d);
   inline uint64_t swizzle_interleaved_from_slice(
       wuffs_base__slice_u8 dst,
       wuffs_base__slice_u8 dst_palette,
       wuffs_base__slice_u8 src) const;
+  inline wuffs_base__status swizzle_ycbcr(
+      wuffs_base__pixel_buffer* dst,
+      wuffs_base__slice_u8 dst_palette,
+      const wuffs_base__pixel_buffer* src,
+      wuffs_base__pixel_chroma_upsampling upsampling) const;
 #endif  // __cplusplus
 
 } wuffs_base__pixel_swizzler;
@@ -1540,6 +2482,39 @@ wuffs_base__pixel_swizzler__prepare(wuffs_base__pixel_swizzler* p,
                                     wuffs_base__slice_u8 src_palette,
                                     wuffs_base__pixel_blend blend);
 
+// wuffs_base__pixel_swizzler__set_color_transform sets (or, for a NULL t,
+// clears) a color transform that is applied to the destination pixels after
+// each swizzle. It must be called after wuffs_base__pixel_swizzler__prepare,
+// which clears it. It returns wuffs_base__error__unsupported_option if the
+// swizzler's blend is not WUFFS_BASE__PIXEL_BLEND__SRC or its destination
+// pixel format is not supported by wuffs_base__color_transform__apply.
+//
+// For modular builds that divide the base module into sub-modules, using this
+// function requires the WUFFS_CONFIG__MODULE__BASE__PIXCONV sub-module, not
+// just WUFFS_CONFIG__MODULE__BASE__CORE.
+WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
+wuffs_base__pixel_swizzler__set_color_transform(
+    wuffs_base__pixel_swizzler* p,
+    const wuffs_base__color_transform* t);
+
+// wuffs_base__pixel_swizzler__set_ditherer sets (or, for a NULL d, clears) a
+// ditherer that is applied when converting to the destination pixels, and
+// resets d. It must be called after wuffs_base__pixel_swizzler__prepare,
+// which clears it. It returns wuffs_base__error__unsupported_option if the
+// swizzler's blend is not WUFFS_BASE__PIXEL_BLEND__SRC, its destination pixel
+// format is not BGR_565, INDEXED__BGRA_BINARY or INDEXED__BGRA_NONPREMUL, or
+// its source pixel format is indexed or planar.
+//
+// Dithering and color transforms are mutually exclusive (their supported
+// destination pixel formats do not overlap).
+//
+// For modular builds that divide the base module into sub-modules, using this
+// function requires the WUFFS_CONFIG__MODULE__BASE__PIXCONV sub-module, not
+// just WUFFS_CONFIG__MODULE__BASE__CORE.
+WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
+wuffs_base__pixel_swizzler__set_ditherer(wuffs_base__pixel_swizzler* p,
+                                         wuffs_base__pixel_ditherer* d);
+
 // wuffs_base__pixel_swizzler__swizzle_interleaved_from_slice converts pixels
 // from a source format to a destination format.
 //
@@ -1553,6 +2528,26 @@ wuffs_base__pixel_swizzler__swizzle_interleaved_from_slice(
     wuffs_base__slice_u8 dst_palette,
     wuffs_base__slice_u8 src);
 
+// wuffs_base__pixel_swizzler__swizzle_ycbcr converts pixels from a planar
+// YCbCr source (WUFFS_BASE__PIXEL_FORMAT__YCBCR, with any pixel subsampling,
+// such as 4:2:0 or 4:2:2) to an interleaved destination. The swizzler must
+// have been prepared with that src_pixfmt and with dst's pixel format.
+//
+// It converts the intersection of the dst and src bounds (both anchored at the
+// top-left). Chroma samples are upsampled as per the upsampling argument. The
+// YCbCr to RGB conversion is as per wuffs_base__color_ycc__as__color_u32.
+//
+// For modular builds that divide the base module into sub-modules, using this
+// function requires the WUFFS_CONFIG__MODULE__BASE__PIXCONV sub-module, not
+// just WUFFS_CONFIG__MODULE__BASE__CORE.
+WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
+wuffs_base__pixel_swizzler__swizzle_ycbcr(
+    const wuffs_base__pixel_swizzler* p,
+    wuffs_base__pixel_buffer* dst,
+    wuffs_base__slice_u8 dst_palette,
+    const wuffs_base__pixel_buffer* src,
+    wuffs_base__pixel_chroma_upsampling upsampling);
+
 #ifdef __cplusplus
 
 inline wuffs_base__status  //
@@ -1565,6 +2560,17 @@ wuffs_base__pixel_swizzler::prepare(wuffs_base__pixel_format dst_pixfmt,
                                              src_pixfmt, src_palette, blend);
 }
 
+inline wuffs_base__status  //
+wuffs_base__pixel_swizzler::set_color_transform(
+    const wuffs_base__color_transform* t) {
+  return wuffs_base__pixel_swizzler__set_color_transform(this, t);
+}
+
+inline wuffs_base__status  //
+wuffs_base__pixel_swizzler::set_ditherer(wuffs_base__pixel_ditherer* d) {
+  return wuffs_base__pixel_swizzler__set_ditherer(this, d);
+}
+
 uint64_t  //
 wuffs_base__pixel_swizzler::swizzle_interleaved_from_slice(
     wuffs_base__slice_u8 dst,
@@ -1574,4 +2580,14 @@ wuffs_base__pixel_swizzler::swizzle_interleaved_from_slice(
       this, dst, dst_palette, src);
 }
 
+inline wuffs_base__status  //
+wuffs_base__pixel_swizzler::swizzle_ycbcr(
+    wuffs_base__pixel_buffer* dst,
+    wuffs_base__slice_u8 dst_palette,
+    const wuffs_base__pixel_buffer* src,
+    wuffs_base__pixel_chroma_upsampling upsampling) const {
+  return wuffs_base__pixel_swizzler__swizzle_ycbcr(this, dst, dst_palette, src,
+                                                   upsampling);
+}
+
 #endif  // __cplusplus
@@ -135,7 +135,7 @@ wuffs_base__parse_number_i64(wuffs_base__slice_u8 s, uint32_t options) {
         wuffs_base__make_slice_u8(p, (size_t)(q - p)), options);
     if (r.status.repr != NULL) {
       wuffs_base__result_i64 ret;
-      ret.status.repr = r.status.repr;
+      ret.status = r.status;
       ret.value = 0;
       return ret;
     } else if (negative) {
@@ -143,14 +143,14 @@ wuffs_base__parse_number_i64(wuffs_base__slice_u8 s, uint32_t options) {
         goto fail_out_of_bounds;
       }
       wuffs_base__result_i64 ret;
-      ret.status.repr = NULL;
+      ret.status = wuffs_base__make_status(NULL);
       ret.value = -(int64_t)(r.value);
       return ret;
     } else if (r.value > 0x7FFFFFFFFFFFFFFF) {
       goto fail_out_of_bounds;
     } else {
       wuffs_base__result_i64 ret;
-      ret.status.repr = NULL;
+      ret.status = wuffs_base__make_status(NULL);
       ret.value = +(int64_t)(r.value);
       return ret;
     }
@@ -159,7 +159,7 @@ wuffs_base__parse_number_i64(wuffs_base__slice_u8 s, uint32_t options) {
 fail_bad_argument:
   do {
     wuffs_base__result_i64 ret;
-    ret.status.repr = wuffs_base__error__bad_argument;
+    ret.status = wuffs_base__make_status(wuffs_base__error__bad_argument);
     ret.value = 0;
     return ret;
   } while (0);
@@ -167,7 +167,7 @@ fail_bad_argument:
 fail_out_of_bounds:
   do {
     wuffs_base__result_i64 ret;
-    ret.status.repr = wuffs_base__error__out_of_bounds;
+    ret.status = wuffs_base__make_status(wuffs_base__error__out_of_bounds);
     ret.value = 0;
     return ret;
   } while (0);
@@ -263,7 +263,7 @@ decimal:
     }
 
     wuffs_base__result_u64 ret;
-    ret.status.repr = NULL;
+    ret.status = wuffs_base__make_status(NULL);
     ret.value = v;
     return ret;
   } while (0);
@@ -293,7 +293,7 @@ hexadecimal:
     }
 
     wuffs_base__result_u64 ret;
-    ret.status.repr = NULL;
+    ret.status = wuffs_base__make_status(NULL);
     ret.value = v;
     return ret;
   } while (0);
@@ -301,7 +301,7 @@ hexadecimal:
 ok_zero:
   do {
     wuffs_base__result_u64 ret;
-    ret.status.repr = NULL;
+    ret.status = wuffs_base__make_status(NULL);
     ret.value = 0;
     return ret;
   } while (0);
@@ -309,7 +309,7 @@ ok_zero:
 fail_bad_argument:
   do {
     wuffs_base__result_u64 ret;
-    ret.status.repr = wuffs_base__error__bad_argument;
+    ret.status = wuffs_base__make_status(wuffs_base__error__bad_argument);
     ret.value = 0;
     return ret;
   } while (0);
@@ -317,7 +317,7 @@ fail_bad_argument:
 fail_out_of_bounds:
   do {
     wuffs_base__result_u64 ret;
-    ret.status.repr = wuffs_base__error__out_of_bounds;
+    ret.status = wuffs_base__make_status(wuffs_base__error__out_of_bounds);
     ret.value = 0;
     return ret;
   } while (0);
@@ -432,15 +432,15 @@ wuffs_base__base_16__decode2(wuffs_base__slice_u8 dst,
   size_t len;
   if (dst.len < src_len2) {
     len = dst.len;
-    o.status.repr = wuffs_base__suspension__short_write;
+    o.status = wuffs_base__make_status(wuffs_base__suspension__short_write);
   } else {
     len = src_len2;
     if (!src_closed) {
-      o.status.repr = wuffs_base__suspension__short_read;
+      o.status = wuffs_base__make_status(wuffs_base__suspension__short_read);
     } else if (src.len & 1) {
-      o.status.repr = wuffs_base__error__bad_data;
+      o.status = wuffs_base__make_status(wuffs_base__error__bad_data);
     } else {
-      o.status.repr = NULL;
+      o.status = wuffs_base__make_status(NULL);
     }
   }
 
@@ -470,15 +470,15 @@ wuffs_base__base_16__decode4(wuffs_base__slice_u8 dst,
   size_t len = dst.len < src_len4 ? dst.len : src_len4;
   if (dst.len < src_len4) {
     len = dst.len;
-    o.status.repr = wuffs_base__suspension__short_write;
+    o.status = wuffs_base__make_status(wuffs_base__suspension__short_write);
   } else {
     len = src_len4;
     if (!src_closed) {
-      o.status.repr = wuffs_base__suspension__short_read;
+      o.status = wuffs_base__make_status(wuffs_base__suspension__short_read);
     } else if (src.len & 1) {
-      o.status.repr = wuffs_base__error__bad_data;
+      o.status = wuffs_base__make_status(wuffs_base__error__bad_data);
     } else {
-      o.status.repr = NULL;
+      o.status = wuffs_base__make_status(NULL);
     }
   }
 
@@ -508,13 +508,13 @@ wuffs_base__base_16__encode2(wuffs_base__slice_u8 dst,
   size_t len;
   if (dst_len2 < src.len) {
     len = dst_len2;
-    o.status.repr = wuffs_base__suspension__short_write;
+    o.status = wuffs_base__make_status(wuffs_base__suspension__short_write);
   } else {
     len = src.len;
     if (!src_closed) {
-      o.status.repr = wuffs_base__suspension__short_read;
+      o.status = wuffs_base__make_status(wuffs_base__suspension__short_read);
     } else {
-      o.status.repr = NULL;
+      o.status = wuffs_base__make_status(NULL);
     }
   }
 
@@ -545,13 +545,13 @@ wuffs_base__base_16__encode4(wuffs_base__slice_u8 dst,
   size_t len;
   if (dst_len4 < src.len) {
     len = dst_len4;
-    o.status.repr = wuffs_base__suspension__short_write;
+    o.status = wuffs_base__make_status(wuffs_base__suspension__short_write);
   } else {
     len = src.len;
     if (!src_closed) {
-      o.status.repr = wuffs_base__suspension__short_read;
+      o.status = wuffs_base__make_status(wuffs_base__suspension__short_read);
     } else {
-      o.status.repr = NULL;
+      o.status = wuffs_base__make_status(NULL);
     }
   }
 
@@ -716,10 +716,10 @@ wuffs_base__base_64__decode(wuffs_base__slice_u8 dst,
 
     if (((s0 | s1 | s2 | s3) & 0xC0) != 0) {
       if (s_len > 4) {
-        o.status.repr = wuffs_base__error__bad_data;
+        o.status = wuffs_base__make_status(wuffs_base__error__bad_data);
         goto done;
       } else if (!src_closed) {
-        o.status.repr = wuffs_base__suspension__short_read;
+        o.status = wuffs_base__make_status(wuffs_base__suspension__short_read);
         goto done;
       } else if ((options & WUFFS_BASE__BASE_64__DECODE_ALLOW_PADDING) &&
                  (s_ptr[3] == '=')) {
@@ -729,12 +729,12 @@ wuffs_base__base_64__decode(wuffs_base__slice_u8 dst,
         }
         goto src3;
       }
-      o.status.repr = wuffs_base__error__bad_data;
+      o.status = wuffs_base__make_status(wuffs_base__error__bad_data);
       goto done;
     }
 
     if (d_len < 3) {
-      o.status.repr = wuffs_base__suspension__short_write;
+      o.status = wuffs_base__make_status(wuffs_base__suspension__short_write);
       goto done;
     }
 
@@ -748,15 +748,15 @@ wuffs_base__base_64__decode(wuffs_base__slice_u8 dst,
   }
 
   if (!src_closed) {
-    o.status.repr = wuffs_base__suspension__short_read;
+    o.status = wuffs_base__make_status(wuffs_base__suspension__short_read);
     goto done;
   }
 
   if (s_len == 0) {
-    o.status.repr = NULL;
+    o.status = wuffs_base__make_status(NULL);
     goto done;
   } else if (s_len == 1) {
-    o.status.repr = wuffs_base__error__bad_data;
+    o.status = wuffs_base__make_status(wuffs_base__error__bad_data);
     goto done;
   } else if (s_len == 2) {
     goto src2;
@@ -769,18 +769,18 @@ src3:
     uint32_t s1 = alphabet[0xFF & (s >> 8)];
     uint32_t s2 = alphabet[0xFF & (s >> 16)];
     if ((s0 & 0xC0) || (s1 & 0xC0) || (s2 & 0xC3)) {
-      o.status.repr = wuffs_base__error__bad_data;
+      o.status = wuffs_base__make_status(wuffs_base__error__bad_data);
       goto done;
     }
     if (d_len < 2) {
-      o.status.repr = wuffs_base__suspension__short_write;
+      o.status = wuffs_base__make_status(wuffs_base__suspension__short_write);
       goto done;
     }
     s_ptr += pad ? 4 : 3;
     s = (s0 << 18) | (s1 << 12) | (s2 << 6);
     *d_ptr++ = (uint8_t)(s >> 16);
     *d_ptr++ = (uint8_t)(s >> 8);
-    o.status.repr = NULL;
+    o.status = wuffs_base__make_status(NULL);
     goto done;
   } while (0);
 
@@ -790,17 +790,17 @@ src2:
     uint32_t s0 = alphabet[0xFF & (s >> 0)];
     uint32_t s1 = alphabet[0xFF & (s >> 8)];
     if ((s0 & 0xC0) || (s1 & 0xCF)) {
-      o.status.repr = wuffs_base__error__bad_data;
+      o.status = wuffs_base__make_status(wuffs_base__error__bad_data);
       goto done;
     }
     if (d_len < 1) {
-      o.status.repr = wuffs_base__suspension__short_write;
+      o.status = wuffs_base__make_status(wuffs_base__suspension__short_write);
       goto done;
     }
     s_ptr += pad ? 4 : 2;
     s = (s0 << 18) | (s1 << 12);
     *d_ptr++ = (uint8_t)(s >> 16);
-    o.status.repr = NULL;
+    o.status = wuffs_base__make_status(NULL);
     goto done;
   } while (0);
 
@@ -827,7 +827,7 @@ wuffs_base__base_64__encode(wuffs_base__slice_u8 dst,
   do {
     while (s_len >= 3) {
       if (d_len < 4) {
-        o.status.repr = wuffs_base__suspension__short_write;
+        o.status = wuffs_base__make_status(wuffs_base__suspension__short_write);
         goto done;
       }
       uint32_t s = wuffs_base__peek_u24be__no_bounds_check(s_ptr);
@@ -841,14 +841,14 @@ wuffs_base__base_64__encode(wuffs_base__slice_u8 dst,
     }
 
     if (!src_closed) {
-      o.status.repr = wuffs_base__suspension__short_read;
+      o.status = wuffs_base__make_status(wuffs_base__suspension__short_read);
       goto done;
     }
 
     if (s_len == 2) {
       if (d_len <
           ((options & WUFFS_BASE__BASE_64__ENCODE_EMIT_PADDING) ? 4 : 3)) {
-        o.status.repr = wuffs_base__suspension__short_write;
+        o.status = wuffs_base__make_status(wuffs_base__suspension__short_write);
         goto done;
       }
       uint32_t s = ((uint32_t)(wuffs_base__peek_u16be__no_bounds_check(s_ptr)))
@@ -860,13 +860,13 @@ wuffs_base__base_64__encode(wuffs_base__slice_u8 dst,
       if (options & WUFFS_BASE__BASE_64__ENCODE_EMIT_PADDING) {
         *d_ptr++ = '=';
       }
-      o.status.repr = NULL;
+      o.status = wuffs_base__make_status(NULL);
       goto done;
 
     } else if (s_len == 1) {
       if (d_len <
           ((options & WUFFS_BASE__BASE_64__ENCODE_EMIT_PADDING) ? 4 : 2)) {
-        o.status.repr = wuffs_base__suspension__short_write;
+        o.status = wuffs_base__make_status(wuffs_base__suspension__short_write);
         goto done;
       }
       uint32_t s = ((uint32_t)(wuffs_base__peek_u8__no_bounds_check(s_ptr)))
@@ -878,11 +878,11 @@ wuffs_base__base_64__encode(wuffs_base__slice_u8 dst,
         *d_ptr++ = '=';
         *d_ptr++ = '=';
       }
-      o.status.repr = NULL;
+      o.status = wuffs_base__make_status(NULL);
       goto done;
 
     } else {
-      o.status.repr = NULL;
+      o.status = wuffs_base__make_status(NULL);
       goto done;
     }
   } while (0);
@@ -73,6 +73,26 @@ wuffs_base__io_reader__limited_copy_u32_to_slice(const uint8_t** ptr_iop_r,
   return (uint32_t)(n);
 }
 
+static inline uint64_t  //
+wuffs_base__io_reader__limited_copy_u64_to_slice(const uint8_t** ptr_iop_r,
+                                                 const uint8_t* io2_r,
+                                                 uint64_t length,
+                                                 wuffs_base__slice_u8 dst) {
+  const uint8_t* iop_r = *ptr_iop_r;
+  size_t n = dst.len;
+  if (((uint64_t)(n)) > length) {
+    n = (size_t)(length);
+  }
+  if (n > ((size_t)(io2_r - iop_r))) {
+    n = (size_t)(io2_r - iop_r);
+  }
+  if (n > 0) {
+    memmove(dst.ptr, iop_r, n);
+    *ptr_iop_r += n;
+  }
+  return (uint64_t)(n);
+}
+
 // wuffs_base__io_reader__match7 returns whether the io_reader's upcoming bytes
 // start with the given prefix (up to 7 bytes long). It is peek-like, not
 // read-like, in that there are no side-effects.
@@ -111,6 +131,62 @@ wuffs_base__io_reader__match7(const uint8_t* iop_r,
   return 0;
 }
 
+// wuffs_base__io_reader__read_uvarint64_fast decodes an unsigned LEB128
+// varint. The caller needs to prove that there are at least 10 readable bytes.
+// It returns false, without advancing *ptr_iop_r, if the varint's value does
+// not fit in 64 bits.
+static inline bool  //
+wuffs_base__io_reader__read_uvarint64_fast(const uint8_t** ptr_iop_r,
+                                           uint64_t* ptr_value) {
+  const uint8_t* p = *ptr_iop_r;
+  uint64_t v = 0;
+  uint32_t shift = 0;
+  for (; shift < 63; shift += 7) {
+    uint8_t c = *p++;
+    v |= ((uint64_t)(c & 0x7F)) << shift;
+    if (c < 0x80) {
+      *ptr_iop_r = p;
+      *ptr_value = v;
+      return true;
+    }
+  }
+  if (*p > 1) {
+    return false;
+  }
+  *ptr_value = v | (((uint64_t)(*p++)) << 63);
+  *ptr_iop_r = p;
+  return true;
+}
+
+// wuffs_base__io_reader__read_uvarint64_step consumes one byte, c, of an
+// unsigned LEB128 varint. *ptr_state should start at 1. After k bytes without
+// a terminator, it holds the partial value (less than 1<<(7*k)) OR'ed with
+// the sentinel bit 1<<(7*k).
+//
+// There are three possible return values:
+//  - 0 means that more bytes are needed.
+//  - 1 means success. *ptr_state now holds the varint's value.
+//  - 2 means that the varint's value does not fit in 64 bits.
+static inline uint32_t  //
+wuffs_base__io_reader__read_uvarint64_step(uint64_t* ptr_state, uint8_t c) {
+  uint32_t shift = 63 - wuffs_base__count_leading_zeroes_u64(*ptr_state);
+  uint64_t v = *ptr_state ^ (((uint64_t)1) << shift);
+  if (shift >= 63) {
+    if (c > 1) {
+      return 2;
+    }
+    *ptr_state = v | (((uint64_t)c) << 63);
+    return 1;
+  }
+  v |= ((uint64_t)(c & 0x7F)) << shift;
+  if (c < 0x80) {
+    *ptr_state = v;
+    return 1;
+  }
+  *ptr_state = v | (((uint64_t)1) << (shift + 7));
+  return 0;
+}
+
 static inline wuffs_base__io_buffer*  //
 wuffs_base__io_reader__set(wuffs_base__io_buffer* b,
                            const uint8_t** ptr_iop_r,
@@ -150,6 +226,18 @@ wuffs_base__io_writer__copy_from_slice(uint8_t** ptr_iop_w,
   return (uint64_t)(n);
 }
 
+// wuffs_base__io_writer__write_uvarint64_fast encodes x as an unsigned LEB128
+// varint. The caller needs to prove that there are at least 10 writable bytes.
+static inline void  //
+wuffs_base__io_writer__write_uvarint64_fast(uint8_t** ptr_iop_w, uint64_t x) {
+  uint8_t* p = *ptr_iop_w;
+  for (; x >= 0x80; x >>= 7) {
+    *p++ = ((uint8_t)(x | 0x80));
+  }
+  *p++ = ((uint8_t)x);
+  *ptr_iop_w = p;
+}
+
 static inline void  //
 wuffs_base__io_writer__limit(uint8_t** ptr_io2_w,
                              uint8_t* iop_w,
@@ -349,6 +437,85 @@ wuffs_base__io_writer__limited_copy_u32_from_slice(uint8_t** ptr_iop_w,
   return (uint32_t)(n);
 }
 
+// wuffs_base__io_writer__limited_copy_u64_from_history is like the
+// wuffs_base__io_writer__limited_copy_u32_from_history function above, but
+// takes 64-bit length and distance arguments. Large-window formats can then
+// copy a whole match without splitting it into u32-sized chunks.
+static inline uint64_t  //
+wuffs_base__io_writer__limited_copy_u64_from_history(uint8_t** ptr_iop_w,
+                                                     uint8_t* io1_w,
+                                                     uint8_t* io2_w,
+                                                     uint64_t length,
+                                                     uint64_t distance) {
+  if (!distance) {
+    return 0;
+  }
+  uint8_t* p = *ptr_iop_w;
+  if (((uint64_t)(p - io1_w)) < distance) {
+    return 0;
+  }
+  uint8_t* q = p - distance;
+  size_t n = (size_t)(io2_w - p);
+  if (length > ((uint64_t)(n))) {
+    length = (uint64_t)(n);
+  } else {
+    n = (size_t)(length);
+  }
+  for (; n >= 3; n -= 3) {
+    *p++ = *q++;
+    *p++ = *q++;
+    *p++ = *q++;
+  }
+  for (; n; n--) {
+    *p++ = *q++;
+  }
+  *ptr_iop_w = p;
+  return length;
+}
+
+static inline uint64_t  //
+wuffs_base__io_writer__limited_copy_u64_from_reader(uint8_t** ptr_iop_w,
+                                                    uint8_t* io2_w,
+                                                    uint64_t length,
+                                                    const uint8_t** ptr_iop_r,
+                                                    const uint8_t* io2_r) {
+  uint8_t* iop_w = *ptr_iop_w;
+  size_t n = (size_t)(io2_w - iop_w);
+  if (((uint64_t)(n)) > length) {
+    n = (size_t)(length);
+  }
+  const uint8_t* iop_r = *ptr_iop_r;
+  if (n > ((size_t)(io2_r - iop_r))) {
+    n = (size_t)(io2_r - iop_r);
+  }
+  if (n > 0) {
+    memmove(iop_w, iop_r, n);
+    *ptr_iop_w += n;
+    *ptr_iop_r += n;
+  }
+  return (uint64_t)(n);
+}
+
+static inline uint64_t  //
+wuffs_base__io_writer__limited_copy_u64_from_slice(uint8_t** ptr_iop_w,
+                                                   uint8_t* io2_w,
+                                                   uint64_t length,
+                                                   wuffs_base__slice_u8 src) {
+  uint8_t* iop_w = *ptr_iop_w;
+  size_t n = src.len;
+  if (((uint64_t)(n)) > length) {
+    n = (size_t)(length);
+  }
+  if (n > ((size_t)(io2_w - iop_w))) {
+    n = (size_t)(io2_w - iop_w);
+  }
+  if (n > 0) {
+    memmove(iop_w, src.ptr, n);
+    *ptr_iop_w += n;
+  }
+  return (uint64_t)(n);
+}
+
 static inline wuffs_base__io_buffer*  //
 wuffs_base__io_writer__set(wuffs_base__io_buffer* b,
                            uint8_t** ptr_iop_w,
@@ -38,9 +38,11 @@ typedef struct wuffs_base__io_buffer__struct {
 #ifdef __cplusplus
   inline bool is_valid() const;
   inline void compact();
+  inline void compact_retain(uint64_t history_retain_length);
   inline size_t reader_length() const;
   inline uint8_t* reader_pointer() const;
   inline uint64_t reader_position() const;
+  inline bool reader_seek(uint64_t pos);
   inline wuffs_base__slice_u8 reader_slice() const;
   inline size_t writer_length() const;
   inline uint8_t* writer_pointer() const;
@@ -126,7 +128,7 @@ wuffs_base__slice_u8__writer(wuffs_base__slice_u8 s) {
 }
 
 static inline wuffs_base__io_buffer  //
-wuffs_base__empty_io_buffer() {
+wuffs_base__empty_io_buffer(void) {
   wuffs_base__io_buffer ret;
   ret.data.ptr = NULL;
   ret.data.len = 0;
@@ -138,7 +140,7 @@ wuffs_base__empty_io_buffer() {
 }
 
 static inline wuffs_base__io_buffer_meta  //
-wuffs_base__empty_io_buffer_meta() {
+wuffs_base__empty_io_buffer_meta(void) {
   wuffs_base__io_buffer_meta ret;
   ret.wi = 0;
   ret.ri = 0;
@@ -175,6 +177,35 @@ wuffs_base__io_buffer__compact(wuffs_base__io_buffer* buf) {
   buf->meta.ri = 0;
 }
 
+// wuffs_base__io_buffer__compact_retain is like wuffs_base__io_buffer__compact
+// but it also retains up to history_retain_length bytes of history: the
+// already-read bytes immediately before the read index, which are moved to
+// the start of the buffer along with the unread bytes.
+//
+// For example, an LZ77-style decoder (such as std/deflate) with the
+// WUFFS_BASE__QUIRK_DST_RETAINS_HISTORY quirk can use the caller's dst buffer
+// as its window, instead of copying its output to an internal history
+// buffer. Between transform_io calls, the caller can consume and then
+// compact that dst buffer, retaining the decoder's
+// DECODER_DST_HISTORY_RETAIN_LENGTH_MAX_INCL_WORST_CASE bytes of history.
+static inline void  //
+wuffs_base__io_buffer__compact_retain(wuffs_base__io_buffer* buf,
+                                      uint64_t history_retain_length) {
+  if (!buf || (buf->meta.ri <= history_retain_length)) {
+    return;
+  }
+  size_t old_ri = buf->meta.ri;
+  size_t new_ri = (size_t)history_retain_length;
+  size_t memmove_start = old_ri - new_ri;
+  buf->meta.pos = wuffs_base__u64__sat_add(buf->meta.pos, memmove_start);
+  size_t n = buf->meta.wi - memmove_start;
+  if (n != 0) {
+    memmove(buf->data.ptr, buf->data.ptr + memmove_start, n);
+  }
+  buf->meta.wi = n;
+  buf->meta.ri = new_ri;
+}
+
 // Deprecated. Use wuffs_base__io_buffer__reader_position.
 static inline uint64_t  //
 wuffs_base__io_buffer__reader_io_position(const wuffs_base__io_buffer* buf) {
@@ -196,6 +227,31 @@ wuffs_base__io_buffer__reader_position(const wuffs_base__io_buffer* buf) {
   return buf ? wuffs_base__u64__sat_add(buf->meta.pos, buf->meta.ri) : 0;
 }
 
+// wuffs_base__io_buffer__reader_seek sets buf's reader_position to pos. It is
+// how callers typically satisfy a "$mispositioned read" suspension, where pos
+// comes from e.g. wuffs_foo__decoder__seek_io_position.
+//
+// If pos is within buf's sliding window, between (meta.pos + 0) and (meta.pos
+// + meta.wi) inclusive, then this just moves meta.ri and returns true.
+// Otherwise, it discards buf's contents, setting meta.ri and meta.wi to zero
+// and meta.pos to pos, and returns false. The caller should then seek the
+// underlying file (or equivalent) to pos and copy from it into buf.
+static inline bool  //
+wuffs_base__io_buffer__reader_seek(wuffs_base__io_buffer* buf, uint64_t pos) {
+  if (!buf) {
+    return false;
+  } else if ((pos >= buf->meta.pos) &&
+             ((pos - buf->meta.pos) <= buf->meta.wi)) {
+    buf->meta.ri = (size_t)(pos - buf->meta.pos);
+    return true;
+  }
+  buf->meta.wi = 0;
+  buf->meta.ri = 0;
+  buf->meta.pos = pos;
+  buf->meta.closed = false;
+  return false;
+}
+
 static inline wuffs_base__slice_u8  //
 wuffs_base__io_buffer__reader_slice(const wuffs_base__io_buffer* buf) {
   return buf ? wuffs_base__make_slice_u8(buf->data.ptr + buf->meta.ri,
@@ -231,6 +287,185 @@ wuffs_base__io_buffer__writer_slice(const wuffs_base__io_buffer* buf) {
              : wuffs_base__empty_slice_u8();
 }
 
+// --------
+
+// wuffs_base__io_buffer_vec is a vectored (scatter/gather) I/O buffer: a
+// logical stream of bytes that is held in a sequence of spans (slices), not
+// necessarily contiguous in memory. For example, the spans could be the two
+// halves of a ring buffer, or the iovec elements of a readv call.
+//
+// The index and offset fields locate the next byte to read (or to write): the
+// offset'th byte of the index'th span. Every byte before that, in earlier
+// spans or in the current span, has already been read (or written). The pos
+// field is the stream position of that next byte.
+//
+// Wuffs decoders and encoders take a wuffs_base__io_buffer, not a
+// wuffs_base__io_buffer_vec. The reader and writer functions below return a
+// wuffs_base__io_buffer that views part of the wuffs_base__io_buffer_vec. After
+// passing that view to Wuffs code, call the advance function to consume the
+// view's meta.ri bytes (for a reader) or meta.wi bytes (for a writer).
+//
+// A value with all fields zero is a valid, empty buffer.
+typedef struct wuffs_base__io_buffer_vec__struct {
+  wuffs_base__slice_u8* spans_ptr;
+  size_t spans_len;
+  size_t index;   // Invariant: index <= spans_len.
+  size_t offset;  // Invariant: offset <= spans_ptr[index].len.
+  uint64_t pos;   // Stream position of the next byte.
+  bool closed;    // No further spans are expected.
+
+#ifdef __cplusplus
+  inline uint64_t length() const;
+  inline wuffs_base__io_buffer reader(wuffs_base__slice_u8 staging) const;
+  inline wuffs_base__io_buffer writer() const;
+  inline void advance(uint64_t n);
+#endif  // __cplusplus
+
+} wuffs_base__io_buffer_vec;
+
+static inline wuffs_base__io_buffer_vec  //
+wuffs_base__make_io_buffer_vec(wuffs_base__slice_u8* spans_ptr,
+                               size_t spans_len,
+                               uint64_t pos,
+                               bool closed) {
+  wuffs_base__io_buffer_vec ret;
+  ret.spans_ptr = spans_ptr;
+  ret.spans_len = spans_len;
+  ret.index = 0;
+  ret.offset = 0;
+  ret.pos = pos;
+  ret.closed = closed;
+  return ret;
+}
+
+// wuffs_base__io_buffer_vec__length returns the number of bytes remaining, in
+// the current span and in all later spans.
+static inline uint64_t  //
+wuffs_base__io_buffer_vec__length(const wuffs_base__io_buffer_vec* v) {
+  if (!v || (v->index >= v->spans_len)) {
+    return 0;
+  }
+  uint64_t n = (uint64_t)(v->spans_ptr[v->index].len - v->offset);
+  size_t i = v->index + 1;
+  for (; i < v->spans_len; i++) {
+    n = wuffs_base__u64__sat_add(n, (uint64_t)(v->spans_ptr[i].len));
+  }
+  return n;
+}
+
+// wuffs_base__io_buffer_vec__reader returns a read-only view, starting at the
+// next byte to read. The view's meta.pos is v->pos and its meta.ri is zero.
+//
+// Usually, the view aliases the remainder of the current span: no bytes are
+// copied. But if that remainder is shorter than staging.len and other spans
+// follow, up to staging.len bytes (from the current span and later spans) are
+// gathered (copied) into staging and the view aliases staging instead. This
+// gives a decoder that stopped, with fewer than staging.len bytes unread, at
+// the end of one span enough contiguous bytes to make progress. A zero length
+// staging slice means to never copy.
+//
+// The view's meta.closed is set only if v->closed and the view holds every
+// remaining byte.
+static inline wuffs_base__io_buffer  //
+wuffs_base__io_buffer_vec__reader(const wuffs_base__io_buffer_vec* v,
+                                  wuffs_base__slice_u8 staging) {
+  wuffs_base__io_buffer ret = wuffs_base__empty_io_buffer();
+  if (!v) {
+    return ret;
+  }
+  ret.meta.pos = v->pos;
+  size_t index = v->index;
+  size_t offset = v->offset;
+  while ((index < v->spans_len) && (offset >= v->spans_ptr[index].len)) {
+    index++;
+    offset = 0;
+  }
+  if (index >= v->spans_len) {
+    ret.meta.closed = v->closed;
+    return ret;
+  }
+
+  wuffs_base__slice_u8 s = v->spans_ptr[index];
+  size_t n = s.len - offset;
+  if ((n < staging.len) && ((index + 1) < v->spans_len)) {
+    n = 0;
+    while ((n < staging.len) && (index < v->spans_len)) {
+      s = v->spans_ptr[index];
+      size_t m = s.len - offset;
+      if (m > (staging.len - n)) {
+        m = staging.len - n;
+      }
+      if (m > 0) {
+        memmove(staging.ptr + n, s.ptr + offset, m);
+        n += m;
+        offset += m;
+      }
+      if (offset >= s.len) {
+        index++;
+        offset = 0;
+      }
+    }
+    ret.data = staging;
+  } else {
+    ret.data = wuffs_base__make_slice_u8(s.ptr + offset, n);
+    index++;
+  }
+  ret.meta.wi = n;
+
+  while ((index < v->spans_len) && (v->spans_ptr[index].len == 0)) {
+    index++;
+  }
+  ret.meta.closed = v->closed && (index >= v->spans_len);
+  return ret;
+}
+
+// wuffs_base__io_buffer_vec__writer returns a write-only view of the remainder
+// of the current span (or, if that is empty, the next non-empty span). No
+// bytes are ever copied, so a destination that needs more contiguous room than
+// a single span provides will not make progress.
+static inline wuffs_base__io_buffer  //
+wuffs_base__io_buffer_vec__writer(const wuffs_base__io_buffer_vec* v) {
+  wuffs_base__io_buffer ret = wuffs_base__empty_io_buffer();
+  if (!v) {
+    return ret;
+  }
+  ret.meta.pos = v->pos;
+  size_t index = v->index;
+  size_t offset = v->offset;
+  while ((index < v->spans_len) && (offset >= v->spans_ptr[index].len)) {
+    index++;
+    offset = 0;
+  }
+  if (index < v->spans_len) {
+    wuffs_base__slice_u8 s = v->spans_ptr[index];
+    ret.data = wuffs_base__make_slice_u8(s.ptr + offset, s.len - offset);
+  }
+  return ret;
+}
+
+// wuffs_base__io_buffer_vec__advance moves the next byte to read (or to write)
+// n bytes forward, across span boundaries if necessary. It stops at the end of
+// the last span if n is larger than the remaining length.
+static inline void  //
+wuffs_base__io_buffer_vec__advance(wuffs_base__io_buffer_vec* v, uint64_t n) {
+  if (!v) {
+    return;
+  }
+  while ((n > 0) && (v->index < v->spans_len)) {
+    size_t m = v->spans_ptr[v->index].len - v->offset;
+    if (((uint64_t)(m)) > n) {
+      m = (size_t)(n);
+    }
+    v->offset += m;
+    v->pos = wuffs_base__u64__sat_add(v->pos, (uint64_t)(m));
+    n -= (uint64_t)(m);
+    if (v->offset >= v->spans_ptr[v->index].len) {
+      v->index++;
+      v->offset = 0;
+    }
+  }
+}
+
 #ifdef __cplusplus
 
 inline bool  //
@@ -243,6 +478,11 @@ wuffs_base__io_buffer::compact() {
   wuffs_base__io_buffer__compact(this);
 }
 
+inline void  //
+wuffs_base__io_buffer::compact_retain(uint64_t history_retain_length) {
+  wuffs_base__io_buffer__compact_retain(this, history_retain_length);
+}
+
 inline uint64_t  //
 wuffs_base__io_buffer::reader_io_position() const {
   return wuffs_base__io_buffer__reader_io_position(this);
@@ -263,6 +503,11 @@ wuffs_base__io_buffer::reader_position() const {
   return wuffs_base__io_buffer__reader_position(this);
 }
 
+inline bool  //
+wuffs_base__io_buffer::reader_seek(uint64_t pos) {
+  return wuffs_base__io_buffer__reader_seek(this, pos);
+}
+
 inline wuffs_base__slice_u8  //
 wuffs_base__io_buffer::reader_slice() const {
   return wuffs_base__io_buffer__reader_slice(this);
@@ -293,4 +538,24 @@ wuffs_base__io_buffer::writer_slice() const {
   return wuffs_base__io_buffer__writer_slice(this);
 }
 
+inline uint64_t  //
+wuffs_base__io_buffer_vec::length() const {
+  return wuffs_base__io_buffer_vec__length(this);
+}
+
+inline wuffs_base__io_buffer  //
+wuffs_base__io_buffer_vec::reader(wuffs_base__slice_u8 staging) const {
+  return wuffs_base__io_buffer_vec__reader(this, staging);
+}
+
+inline wuffs_base__io_buffer  //
+wuffs_base__io_buffer_vec::writer() const {
+  return wuffs_base__io_buffer_vec__writer(this);
+}
+
+inline void  //
+wuffs_base__io_buffer_vec::advance(uint64_t n) {
+  wuffs_base__io_buffer_vec__advance(this, n);
+}
+
 #endif  // __cplusplus
@@ -28,13 +28,24 @@ wuffs_base__magic_number_guess_fourcc(wuffs_base__slice_u8 prefix) {
     int32_t fourcc;
     const char* magic;
   } table[] = {
+      {0x49434F20, "\x03\x00\x00\x01\x00"},  // ICO
+      {0x43555220, "\x03\x00\x00\x02\x00"},  // CUR
       {0x57424D50, "\x01\x00\x00"},          // WBMP
+      {0x48445220, "\x01\x23\x3F"},          // HDR
       {0x424D5020, "\x01\x42\x4D"},          // BMP
       {0x47494620, "\x03\x47\x49\x46\x38"},  // GIF
       {0x54494646, "\x03\x49\x49\x2A\x00"},  // TIFF (little-endian)
       {0x54494646, "\x03\x4D\x4D\x00\x2A"},  // TIFF (big-endian)
+      {0x504E4D20, "\x01\x50\x31"},          // PNM (P1)
+      {0x504E4D20, "\x01\x50\x32"},          // PNM (P2)
+      {0x504E4D20, "\x01\x50\x33"},          // PNM (P3)
+      {0x504E4D20, "\x01\x50\x34"},          // PNM (P4)
+      {0x504E4D20, "\x01\x50\x35"},          // PNM (P5)
+      {0x504E4D20, "\x01\x50\x36"},          // PNM (P6)
+      {0x504E4D20, "\x01\x50\x37"},          // PNM (P7)
       {0x52494646, "\x03\x52\x49\x46\x46"},  // RIFF (see § below)
       {0x4E494520, "\x02\x6E\xC3\xAF"},      // NIE
+      {0x45585220, "\x03\x76\x2F\x31\x01"},  // EXR
       {0x504E4720, "\x03\x89\x50\x4E\x47"},  // PNG
       {0x4A504547, "\x01\xFF\xD8"},          // JPEG
   };
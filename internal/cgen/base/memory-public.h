@@ -72,3 +72,55 @@ wuffs_base__malloc_slice_u64(void* (*malloc_func)(size_t), uint64_t num_u64) {
   }
   return wuffs_base__make_slice_u64(NULL, 0);
 }
+
+// --------
+
+// wuffs_base__mem__allocator lets callers route the heap allocations made by
+// the wuffs_foo__bar__alloc_with functions (and their C++ alloc_with
+// equivalents) through their own allocator, such as a game engine's arena.
+//
+// zalloc_func should return a pointer to n zeroed bytes, or NULL on failure.
+// free_func should release a pointer previously returned by zalloc_func. Both
+// are passed the context field as their first argument.
+//
+// A NULL wuffs_base__mem__allocator pointer means to use the C stdlib's calloc
+// and free, which is what the wuffs_foo__bar__alloc functions do.
+//
+// Callers that manage their own memory more directly can also allocate
+// sizeof__wuffs_foo__bar() bytes and call wuffs_foo__bar__initialize.
+typedef struct wuffs_base__mem__allocator__struct {
+  void* (*zalloc_func)(void* context, size_t n);
+  void (*free_func)(void* context, void* ptr);
+  void* context;
+} wuffs_base__mem__allocator;
+
+static inline void*  //
+wuffs_base__mem__allocator__zalloc(const wuffs_base__mem__allocator* a,
+                                   size_t n) {
+  if (a && a->zalloc_func) {
+    return (*a->zalloc_func)(a->context, n);
+  }
+  return calloc(n, 1);
+}
+
+static inline void  //
+wuffs_base__mem__allocator__free(const wuffs_base__mem__allocator* a,
+                                 void* ptr) {
+  if (a && a->free_func) {
+    (*a->free_func)(a->context, ptr);
+    return;
+  }
+  free(ptr);
+}
+
+#if defined(__cplusplus) && defined(WUFFS_BASE__HAVE_UNIQUE_PTR)
+// wuffs_base__mem__allocator_deleter is a std::unique_ptr deleter that frees
+// via a (possibly NULL) wuffs_base__mem__allocator.
+struct wuffs_base__mem__allocator_deleter {
+  const wuffs_base__mem__allocator* allocator;
+
+  void operator()(void* ptr) const {
+    wuffs_base__mem__allocator__free(allocator, ptr);
+  }
+};
+#endif  // defined(__cplusplus) && defined(WUFFS_BASE__HAVE_UNIQUE_PTR)
@@ -14,6 +14,519 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// ---------------- Color Transforms
+
+// wuffs_base__private_implementation__f64_log2 and f64_exp2 approximate
+// log2(x) and exp2(x) to within about 1e-12, without depending on <math.h>.
+// The f64_log2 argument must be positive and finite.
+static double  //
+wuffs_base__private_implementation__f64_log2(double x) {
+  int32_t e = 0;
+  uint64_t u = wuffs_base__ieee_754_bit_representation__from_f64_to_u64(x);
+  if ((u & 0x7FF0000000000000ul) == 0) {
+    // Scale subnormal numbers up by 2**64.
+    x *= 18446744073709551616.0;
+    u = wuffs_base__ieee_754_bit_representation__from_f64_to_u64(x);
+    e = -64;
+  }
+  e += ((int32_t)((u >> 52) & 0x7FF)) - 1023;
+  double m = wuffs_base__ieee_754_bit_representation__from_u64_to_f64(
+      (u & 0x000FFFFFFFFFFFFFul) | 0x3FF0000000000000ul);
+  if (m > 1.4142135623730951) {
+    m *= 0.5;
+    e++;
+  }
+
+  // With m in [sqrt(0.5), sqrt(2)], t = (m-1)/(m+1) is in [-0.172, +0.172]
+  // and ln(m) = 2 * (t + t**3/3 + t**5/5 + ...) converges quickly.
+  double t = (m - 1.0) / (m + 1.0);
+  double t2 = t * t;
+  double sum = 0.0;
+  int k;
+  for (k = 1; k < 24; k += 2) {
+    sum += t / k;
+    t *= t2;
+  }
+  return ((double)e) + (2.0 * sum * 1.4426950408889634);  // 1 / ln(2).
+}
+
+static double  //
+wuffs_base__private_implementation__f64_exp2(double x) {
+  if (!(x > -1022.0)) {
+    return 0.0;
+  } else if (x > 1023.0) {
+    x = 1023.0;
+  }
+  int32_t i = (int32_t)x;
+  if (((double)i) > x) {
+    i--;
+  }
+
+  // With z = (x - i) * ln(2) in [0, 0.694), exp(z) is the Taylor series 1 +
+  // z + z**2/2! + z**3/3! + etc.
+  double z = (x - ((double)i)) * 0.6931471805599453;
+  double term = 1.0;
+  double sum = 1.0;
+  int k;
+  for (k = 1; k < 18; k++) {
+    term *= z / k;
+    sum += term;
+  }
+  return sum * wuffs_base__ieee_754_bit_representation__from_u64_to_f64(
+                   ((uint64_t)(i + 1023)) << 52);
+}
+
+static double  //
+wuffs_base__private_implementation__f64_pow(double x, double y) {
+  if (!(x > 0.0)) {
+    return 0.0;
+  }
+  return wuffs_base__private_implementation__f64_exp2(
+      y * wuffs_base__private_implementation__f64_log2(x));
+}
+
+static inline double  //
+wuffs_base__private_implementation__f64_clamp_0_1(double x) {
+  return (x > 0.0) ? ((x < 1.0) ? x : 1.0) : 0.0;
+}
+
+WUFFS_BASE__MAYBE_STATIC double  //
+wuffs_base__color_transfer_function__eval(
+    const wuffs_base__color_transfer_function* f,
+    double x) {
+  if (!f) {
+    return 0.0;
+  }
+  x = wuffs_base__private_implementation__f64_clamp_0_1(x);
+
+  double y = 0.0;
+  if (f->table_len == 1) {
+    y = wuffs_base__peek_u16be__no_bounds_check(f->table_ptr) / 65535.0;
+  } else if (f->table_len > 1) {
+    double pos = x * (f->table_len - 1);
+    uint32_t i = (uint32_t)pos;
+    if (i >= (f->table_len - 1)) {
+      i = f->table_len - 2;
+    }
+    double frac = pos - i;
+    double y0 = wuffs_base__peek_u16be__no_bounds_check(f->table_ptr + 2 * i);
+    double y1 =
+        wuffs_base__peek_u16be__no_bounds_check(f->table_ptr + 2 * (i + 1));
+    y = ((y0 * (1.0 - frac)) + (y1 * frac)) / 65535.0;
+  } else if (x < f->d) {
+    y = (f->c * x) + f->f;
+  } else {
+    y = wuffs_base__private_implementation__f64_pow((f->a * x) + f->b, f->g) +
+        f->e;
+  }
+  return wuffs_base__private_implementation__f64_clamp_0_1(y);
+}
+
+// wuffs_base__private_implementation__color_transfer_function__inverse
+// returns the encoded value for the linear value y. Sampled curves are assumed
+// to be non-decreasing.
+static double  //
+wuffs_base__private_implementation__color_transfer_function__inverse(
+    const wuffs_base__color_transfer_function* f,
+    double y) {
+  y = wuffs_base__private_implementation__f64_clamp_0_1(y);
+
+  double x = 0.0;
+  if (f->table_len > 0) {
+    double lo = 0.0;
+    double hi = 1.0;
+    int i;
+    for (i = 0; i < 32; i++) {
+      double mid = 0.5 * (lo + hi);
+      if (wuffs_base__color_transfer_function__eval(f, mid) < y) {
+        lo = mid;
+      } else {
+        hi = mid;
+      }
+    }
+    x = 0.5 * (lo + hi);
+  } else if ((f->c > 0.0) && (y < ((f->c * f->d) + f->f))) {
+    x = (y - f->f) / f->c;
+  } else if (((f->a < 0.0) || (f->a > 0.0)) && (f->g > 0.0)) {
+    x = (wuffs_base__private_implementation__f64_pow(y - f->e, 1.0 / f->g) -
+         f->b) /
+        f->a;
+  }
+  return wuffs_base__private_implementation__f64_clamp_0_1(x);
+}
+
+// --------
+
+// wuffs_base__private_implementation__color_icc__parse_trc returns 0 (and
+// sets *f) on success, 1 for a well-formed but unsupported curve and 2 for a
+// malformed one. The ptr and len are the tag's bytes.
+static int  //
+wuffs_base__private_implementation__color_icc__parse_trc(
+    wuffs_base__color_transfer_function* f,
+    const uint8_t* ptr,
+    uint32_t len) {
+  if (len < 12) {
+    return 2;
+  }
+  uint32_t type = wuffs_base__peek_u32be__no_bounds_check(ptr);
+
+  if (type == 0x63757276) {  // "curv".
+    uint32_t n = wuffs_base__peek_u32be__no_bounds_check(ptr + 8);
+    if (n > ((len - 12) / 2)) {
+      return 2;
+    } else if (n == 0) {
+      *f = wuffs_base__make_color_transfer_function__gamma(1.0);
+    } else if (n == 1) {
+      *f = wuffs_base__make_color_transfer_function__gamma(
+          wuffs_base__peek_u16be__no_bounds_check(ptr + 12) / 256.0);
+    } else {
+      *f = wuffs_base__make_color_transfer_function__gamma(1.0);
+      f->table_ptr = ptr + 12;
+      f->table_len = n;
+    }
+    return 0;
+
+  } else if (type == 0x70617261) {  // "para".
+    static const uint8_t num_params[5] = {1, 3, 4, 5, 7};
+    uint32_t function_type = wuffs_base__peek_u16be__no_bounds_check(ptr + 8);
+    if (function_type >= 5) {
+      return 1;
+    } else if (((len - 12) / 4) < num_params[function_type]) {
+      return 2;
+    }
+    double p[7] = {0};
+    uint32_t i;
+    for (i = 0; i < num_params[function_type]; i++) {
+      p[i] = ((int32_t)(wuffs_base__peek_u32be__no_bounds_check(
+                 ptr + 12 + (4 * i)))) /
+             65536.0;
+    }
+
+    *f = wuffs_base__make_color_transfer_function__gamma(p[0]);
+    switch (function_type) {
+      case 1:
+      case 2:
+        // Below x = -b/a, y is 0 (type 1) or c (type 2). Above it, y is
+        // (a*x + b)**g (plus c for type 2).
+        if (!(p[1] > 0.0)) {
+          return 1;
+        }
+        f->a = p[1];
+        f->b = p[2];
+        f->d = -p[2] / p[1];
+        f->e = p[3];
+        f->f = p[3];
+        break;
+      case 3:
+      case 4:
+        f->a = p[1];
+        f->b = p[2];
+        f->c = p[3];
+        f->d = p[4];
+        f->e = p[5];
+        f->f = p[6];
+        break;
+    }
+    return 0;
+  }
+
+  return 1;
+}
+
+WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
+wuffs_base__color_icc__parse(wuffs_base__color_icc* c,
+                             wuffs_base__slice_u8 src) {
+  if (!c) {
+    return wuffs_base__make_status(wuffs_base__error__bad_receiver);
+  }
+  memset(c, 0, sizeof(*c));
+
+  // The header is 128 bytes, followed by a 4 byte tag count. The "acsp" at
+  // offset 36 is the profile file signature.
+  if (src.len < 132) {
+    return wuffs_base__make_status(wuffs_base__error__bad_data);
+  }
+  uint32_t size = wuffs_base__peek_u32be__no_bounds_check(src.ptr + 0);
+  if ((size < 132) || (size > src.len) ||
+      (wuffs_base__peek_u32be__no_bounds_check(src.ptr + 36) != 0x61637370)) {
+    return wuffs_base__make_status(wuffs_base__error__bad_data);
+  }
+  c->private_impl.profile_version =
+      wuffs_base__peek_u32be__no_bounds_check(src.ptr + 8);
+  c->private_impl.device_class =
+      wuffs_base__peek_u32be__no_bounds_check(src.ptr + 12);
+  c->private_impl.color_space =
+      wuffs_base__peek_u32be__no_bounds_check(src.ptr + 16);
+  c->private_impl.pcs = wuffs_base__peek_u32be__no_bounds_check(src.ptr + 20);
+  c->private_impl.rendering_intent =
+      wuffs_base__peek_u32be__no_bounds_check(src.ptr + 64);
+
+  uint32_t num_tags = wuffs_base__peek_u32be__no_bounds_check(src.ptr + 128);
+  if (num_tags > ((size - 132) / 12)) {
+    return wuffs_base__make_status(wuffs_base__error__bad_data);
+  }
+
+  // The found bits are 0x01, 0x02, 0x04 for the r, g, b XYZ tags and 0x08,
+  // 0x10, 0x20 for the r, g, b TRC tags. The unsupported flag is set by a
+  // matrix/TRC tag that we cannot use.
+  uint32_t found = 0;
+  bool unsupported = false;
+  uint32_t i;
+  for (i = 0; i < num_tags; i++) {
+    const uint8_t* entry = src.ptr + 132 + (12 * i);
+    uint32_t sig = wuffs_base__peek_u32be__no_bounds_check(entry + 0);
+    uint32_t offset = wuffs_base__peek_u32be__no_bounds_check(entry + 4);
+    uint32_t length = wuffs_base__peek_u32be__no_bounds_check(entry + 8);
+    if ((offset > size) || (length > (size - offset))) {
+      return wuffs_base__make_status(wuffs_base__error__bad_data);
+    }
+    const uint8_t* ptr = src.ptr + offset;
+
+    uint32_t channel = 0;
+    switch (sig) {
+      case 0x7258595A:  // "rXYZ".
+      case 0x72545243:  // "rTRC".
+        channel = 0;
+        break;
+      case 0x6758595A:  // "gXYZ".
+      case 0x67545243:  // "gTRC".
+        channel = 1;
+        break;
+      case 0x6258595A:  // "bXYZ".
+      case 0x62545243:  // "bTRC".
+        channel = 2;
+        break;
+      default:
+        continue;
+    }
+
+    if ((sig & 0xFFFF) == 0x595A) {  // "?XYZ".
+      if ((length < 20) ||
+          (wuffs_base__peek_u32be__no_bounds_check(ptr) != 0x58595A20)) {
+        return wuffs_base__make_status(wuffs_base__error__bad_data);
+      }
+      uint32_t j;
+      for (j = 0; j < 3; j++) {
+        c->private_impl.to_xyz_d50[(3 * j) + channel] =
+            ((int32_t)(wuffs_base__peek_u32be__no_bounds_check(
+                ptr + 8 + (4 * j)))) /
+            65536.0;
+      }
+      found |= 0x01u << channel;
+
+    } else {  // "?TRC".
+      switch (wuffs_base__private_implementation__color_icc__parse_trc(
+          &c->private_impl.trcs[channel], ptr, length)) {
+        case 0:
+          found |= 0x08u << channel;
+          break;
+        case 1:
+          unsupported = true;
+          break;
+        default:
+          return wuffs_base__make_status(wuffs_base__error__bad_data);
+      }
+    }
+  }
+
+  c->private_impl.has_matrix_trc =
+      (found == 0x3F) && !unsupported &&
+      (c->private_impl.color_space == WUFFS_BASE__COLOR_ICC__SIGNATURE__RGB) &&
+      (c->private_impl.pcs == WUFFS_BASE__COLOR_ICC__SIGNATURE__XYZ);
+  return wuffs_base__make_status(NULL);
+}
+
+static void  //
+wuffs_base__private_implementation__color_icc__set_matrix_trc(
+    wuffs_base__color_icc* c,
+    const double* to_xyz_d50) {
+  if (!c) {
+    return;
+  }
+  memset(c, 0, sizeof(*c));
+  c->private_impl.profile_version = 0x04300000;
+  c->private_impl.device_class = 0x6D6E7472;  // "mntr".
+  c->private_impl.color_space = WUFFS_BASE__COLOR_ICC__SIGNATURE__RGB;
+  c->private_impl.pcs = WUFFS_BASE__COLOR_ICC__SIGNATURE__XYZ;
+  c->private_impl.has_matrix_trc = true;
+  memcpy(c->private_impl.to_xyz_d50, to_xyz_d50,
+         sizeof(c->private_impl.to_xyz_d50));
+  c->private_impl.trcs[0] = wuffs_base__make_color_transfer_function__srgb();
+  c->private_impl.trcs[1] = wuffs_base__make_color_transfer_function__srgb();
+  c->private_impl.trcs[2] = wuffs_base__make_color_transfer_function__srgb();
+}
+
+// The RGB to XYZ matrices are chromatically adapted (by the Bradford method)
+// to the D50 white point of the ICC profile connection space.
+
+WUFFS_BASE__MAYBE_STATIC void  //
+wuffs_base__color_icc__set_srgb(wuffs_base__color_icc* c) {
+  static const double to_xyz_d50[9] = {
+      0.436065674, 0.385147095, 0.143066406,  //
+      0.222488403, 0.716873169, 0.060607910,  //
+      0.013916016, 0.097076416, 0.714096069,  //
+  };
+  wuffs_base__private_implementation__color_icc__set_matrix_trc(c,
+                                                                to_xyz_d50);
+}
+
+WUFFS_BASE__MAYBE_STATIC void  //
+wuffs_base__color_icc__set_display_p3(wuffs_base__color_icc* c) {
+  static const double to_xyz_d50[9] = {
+      0.515102000,  0.291965000, 0.157153000,  //
+      0.241182000,  0.692236000, 0.066581900,  //
+      -0.001049410, 0.041881800, 0.784378000,  //
+  };
+  wuffs_base__private_implementation__color_icc__set_matrix_trc(c,
+                                                                to_xyz_d50);
+}
+
+// --------
+
+static inline size_t  //
+wuffs_base__private_implementation__color_transform__dst_lut_index(float v) {
+  return (v > 0.0f) ? ((v < 1.0f) ? ((size_t)((v * 4095.0f) + 0.5f)) : 4095)
+                    : 0;
+}
+
+WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
+wuffs_base__color_transform__prepare(wuffs_base__color_transform* t,
+                                     const wuffs_base__color_icc* src,
+                                     const wuffs_base__color_icc* dst) {
+  if (!t) {
+    return wuffs_base__make_status(wuffs_base__error__bad_receiver);
+  }
+  memset(t, 0, sizeof(*t));
+
+  wuffs_base__color_icc srgb;
+  if (!dst) {
+    wuffs_base__color_icc__set_srgb(&srgb);
+    dst = &srgb;
+  }
+  if (!wuffs_base__color_icc__has_matrix_trc(src) ||
+      !wuffs_base__color_icc__has_matrix_trc(dst)) {
+    return wuffs_base__make_status(wuffs_base__error__unsupported_option);
+  }
+
+  // Invert dst's RGB-to-XYZ matrix, d, by the adjugate method.
+  const double* d = dst->private_impl.to_xyz_d50;
+  double inv[9];
+  inv[0] = (d[4] * d[8]) - (d[5] * d[7]);
+  inv[1] = (d[2] * d[7]) - (d[1] * d[8]);
+  inv[2] = (d[1] * d[5]) - (d[2] * d[4]);
+  inv[3] = (d[5] * d[6]) - (d[3] * d[8]);
+  inv[4] = (d[0] * d[8]) - (d[2] * d[6]);
+  inv[5] = (d[2] * d[3]) - (d[0] * d[5]);
+  inv[6] = (d[3] * d[7]) - (d[4] * d[6]);
+  inv[7] = (d[1] * d[6]) - (d[0] * d[7]);
+  inv[8] = (d[0] * d[4]) - (d[1] * d[3]);
+  double det = (d[0] * inv[0]) + (d[1] * inv[3]) + (d[2] * inv[6]);
+  if ((det > -1e-9) && (det < +1e-9)) {
+    return wuffs_base__make_status(wuffs_base__error__unsupported_option);
+  }
+
+  // The combined matrix is inv(d) * s.
+  const double* s = src->private_impl.to_xyz_d50;
+  bool is_identity = true;
+  int i;
+  for (i = 0; i < 9; i++) {
+    int row = i / 3;
+    int col = i % 3;
+    double m = ((inv[(3 * row) + 0] * s[col + 0]) +
+                (inv[(3 * row) + 1] * s[col + 3]) +
+                (inv[(3 * row) + 2] * s[col + 6])) /
+               det;
+    double delta = m - ((row == col) ? 1.0 : 0.0);
+    if ((delta < -1e-4) || (delta > +1e-4)) {
+      is_identity = false;
+    }
+    t->private_impl.matrix[i] = (float)m;
+  }
+
+  int c;
+  for (c = 0; c < 3; c++) {
+    for (i = 0; i < 256; i++) {
+      t->private_impl.src_luts[c][i] =
+          (float)wuffs_base__color_transfer_function__eval(
+              &src->private_impl.trcs[c], i / 255.0);
+    }
+    for (i = 0; i < 4096; i++) {
+      double x =
+          wuffs_base__private_implementation__color_transfer_function__inverse(
+              &dst->private_impl.trcs[c], i / 4095.0);
+      t->private_impl.dst_luts[c][i] = (uint8_t)(0.5 + (255.0 * x));
+    }
+    for (i = 0; is_identity && (i < 256); i++) {
+      size_t j =
+          wuffs_base__private_implementation__color_transform__dst_lut_index(
+              t->private_impl.src_luts[c][i]);
+      is_identity = t->private_impl.dst_luts[c][j] == i;
+    }
+  }
+  t->private_impl.is_identity = is_identity;
+
+  return wuffs_base__make_status(NULL);
+}
+
+WUFFS_BASE__MAYBE_STATIC uint64_t  //
+wuffs_base__color_transform__apply(const wuffs_base__color_transform* t,
+                                   wuffs_base__slice_u8 pixels,
+                                   wuffs_base__pixel_format pixfmt) {
+  if (!t) {
+    return 0;
+  }
+  size_t bytes_per_pixel = 0;
+  size_t r_offset = 0;
+  size_t b_offset = 0;
+  switch (pixfmt.repr) {
+    case WUFFS_BASE__PIXEL_FORMAT__BGR:
+      bytes_per_pixel = 3;
+      r_offset = 2;
+      break;
+    case WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL:
+    case WUFFS_BASE__PIXEL_FORMAT__BGRX:
+      bytes_per_pixel = 4;
+      r_offset = 2;
+      break;
+    case WUFFS_BASE__PIXEL_FORMAT__RGB:
+      bytes_per_pixel = 3;
+      b_offset = 2;
+      break;
+    case WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL:
+    case WUFFS_BASE__PIXEL_FORMAT__RGBX:
+      bytes_per_pixel = 4;
+      b_offset = 2;
+      break;
+    default:
+      return 0;
+  }
+
+  size_t n = pixels.len / bytes_per_pixel;
+  if (t->private_impl.is_identity) {
+    return n;
+  }
+
+  const float* m = t->private_impl.matrix;
+  uint8_t* p = pixels.ptr;
+  size_t i;
+  for (i = 0; i < n; i++) {
+    float r = t->private_impl.src_luts[0][p[r_offset]];
+    float g = t->private_impl.src_luts[1][p[1]];
+    float b = t->private_impl.src_luts[2][p[b_offset]];
+    p[r_offset] = t->private_impl.dst_luts[0]
+        [wuffs_base__private_implementation__color_transform__dst_lut_index(
+            (m[0] * r) + (m[1] * g) + (m[2] * b))];
+    p[1] = t->private_impl.dst_luts[1]
+        [wuffs_base__private_implementation__color_transform__dst_lut_index(
+            (m[3] * r) + (m[4] * g) + (m[5] * b))];
+    p[b_offset] = t->private_impl.dst_luts[2]
+        [wuffs_base__private_implementation__color_transform__dst_lut_index(
+            (m[6] * r) + (m[7] * g) + (m[8] * b))];
+    p += bytes_per_pixel;
+  }
+  return n;
+}
+
 // ---------------- Pixel Swizzler
 
 static inline uint32_t  //
@@ -437,6 +950,217 @@ wuffs_base__pixel_buffer__set_color_u32_fill_rect(
 
 // --------
 
+// wuffs_base__private_implementation__pixel_buffer__swap_bytes swaps n (at
+// most 256) bytes at p and q, which do not overlap.
+static inline void  //
+wuffs_base__private_implementation__pixel_buffer__swap_bytes(uint8_t* p,
+                                                             uint8_t* q,
+                                                             size_t n) {
+  uint8_t tmp[256];
+  memcpy(&tmp[0], p, n);
+  memcpy(p, q, n);
+  memcpy(q, &tmp[0], n);
+}
+
+// wuffs_base__private_implementation__pixel_buffer__flip_x mirrors the table
+// (of width pixels, each bpp bytes) horizontally, in place.
+static void  //
+wuffs_base__private_implementation__pixel_buffer__flip_x(
+    wuffs_base__table_u8* t,
+    size_t width,
+    size_t bpp) {
+  size_t y;
+  for (y = 0; y < t->height; y++) {
+    uint8_t* row = t->ptr + (y * t->stride);
+    size_t x;
+    for (x = 0; ((2 * x) + 1) < width; x++) {
+      wuffs_base__private_implementation__pixel_buffer__swap_bytes(
+          row + (x * bpp), row + ((width - 1 - x) * bpp), bpp);
+    }
+  }
+}
+
+// wuffs_base__private_implementation__pixel_buffer__flip_y mirrors the table
+// vertically, in place.
+static void  //
+wuffs_base__private_implementation__pixel_buffer__flip_y(
+    wuffs_base__table_u8* t) {
+  size_t y;
+  for (y = 0; ((2 * y) + 1) < t->height; y++) {
+    uint8_t* p = t->ptr + (y * t->stride);
+    uint8_t* q = t->ptr + ((t->height - 1 - y) * t->stride);
+    size_t i;
+    for (i = 0; i < t->width; i += 256) {
+      size_t n = t->width - i;
+      wuffs_base__private_implementation__pixel_buffer__swap_bytes(
+          p + i, q + i, (n < 256) ? n : 256);
+    }
+  }
+}
+
+// wuffs_base__private_implementation__pixel_buffer__transpose transposes the
+// square table (of width by width pixels, each bpp bytes), in place.
+static void  //
+wuffs_base__private_implementation__pixel_buffer__transpose(
+    wuffs_base__table_u8* t,
+    size_t width,
+    size_t bpp) {
+  size_t y;
+  for (y = 0; y < width; y++) {
+    size_t x;
+    for (x = y + 1; x < width; x++) {
+      wuffs_base__private_implementation__pixel_buffer__swap_bytes(
+          t->ptr + (y * t->stride) + (x * bpp),
+          t->ptr + (x * t->stride) + (y * bpp), bpp);
+    }
+  }
+}
+
+WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
+wuffs_base__pixel_buffer__apply_orientation(
+    wuffs_base__pixel_buffer* dst,
+    const wuffs_base__pixel_buffer* src,
+    uint32_t orientation) {
+  if (!dst) {
+    return wuffs_base__make_status(wuffs_base__error__bad_receiver);
+  } else if (!src || (orientation < WUFFS_BASE__ORIENTATION__TOP_LEFT) ||
+             (WUFFS_BASE__ORIENTATION__LEFT_BOTTOM < orientation)) {
+    return wuffs_base__make_status(wuffs_base__error__bad_argument);
+  }
+
+  wuffs_base__pixel_format pixfmt = src->pixcfg.private_impl.pixfmt;
+  uint32_t bits_per_pixel = wuffs_base__pixel_format__bits_per_pixel(&pixfmt);
+  if ((dst->pixcfg.private_impl.pixfmt.repr != pixfmt.repr) ||
+      wuffs_base__pixel_format__is_planar(&pixfmt) || (bits_per_pixel == 0) ||
+      ((bits_per_pixel & 7) != 0) || (bits_per_pixel > (8 * 256))) {
+    return wuffs_base__make_status(wuffs_base__error__unsupported_option);
+  }
+  size_t bpp = bits_per_pixel / 8;
+
+  bool swap = wuffs_base__orientation__swaps_width_and_height(orientation);
+  size_t sw = src->pixcfg.private_impl.width;
+  size_t sh = src->pixcfg.private_impl.height;
+  size_t dw = dst->pixcfg.private_impl.width;
+  size_t dh = dst->pixcfg.private_impl.height;
+  if ((dw != (swap ? sh : sw)) || (dh != (swap ? sw : sh))) {
+    return wuffs_base__make_status(wuffs_base__error__bad_argument);
+  }
+  wuffs_base__table_u8* d = &dst->private_impl.planes[0];
+  const wuffs_base__table_u8* s = &src->private_impl.planes[0];
+  if ((d->width < (dw * bpp)) || (d->height < dh) || (s->width < (sw * bpp)) ||
+      (s->height < sh)) {
+    return wuffs_base__make_status(wuffs_base__error__bad_argument);
+  } else if ((dw == 0) || (dh == 0)) {
+    return wuffs_base__make_status(NULL);
+  }
+
+  if (dst == src) {
+    // The dimension checks above mean that, if swap, then sw == sh.
+    //
+    // A transpose, followed by horizontal and vertical flips, covers all
+    // eight orientations.
+    bool transpose = swap;
+    bool flip_x = false;
+    bool flip_y = false;
+    switch (orientation) {
+      case WUFFS_BASE__ORIENTATION__TOP_RIGHT:
+      case WUFFS_BASE__ORIENTATION__RIGHT_TOP:
+        flip_x = true;
+        break;
+      case WUFFS_BASE__ORIENTATION__BOTTOM_RIGHT:
+      case WUFFS_BASE__ORIENTATION__RIGHT_BOTTOM:
+        flip_x = true;
+        flip_y = true;
+        break;
+      case WUFFS_BASE__ORIENTATION__BOTTOM_LEFT:
+      case WUFFS_BASE__ORIENTATION__LEFT_BOTTOM:
+        flip_y = true;
+        break;
+    }
+    wuffs_base__table_u8 t = *d;
+    t.width = dw * bpp;
+    t.height = dh;
+    if (transpose) {
+      wuffs_base__private_implementation__pixel_buffer__transpose(&t, dw, bpp);
+    }
+    if (flip_x) {
+      wuffs_base__private_implementation__pixel_buffer__flip_x(&t, dw, bpp);
+    }
+    if (flip_y) {
+      wuffs_base__private_implementation__pixel_buffer__flip_y(&t);
+    }
+    return wuffs_base__make_status(NULL);
+  }
+
+  // Check that the pixels do not overlap.
+  const uint8_t* d0 = d->ptr;
+  const uint8_t* d1 = d->ptr + ((dh - 1) * d->stride) + (dw * bpp);
+  const uint8_t* s0 = s->ptr;
+  const uint8_t* s1 = s->ptr + ((sh - 1) * s->stride) + (sw * bpp);
+  if ((d0 < s1) && (s0 < d1)) {
+    return wuffs_base__make_status(wuffs_base__error__bad_argument);
+  }
+
+  if (wuffs_base__pixel_format__is_indexed(&pixfmt)) {
+    wuffs_base__table_u8* dp = &dst->private_impl.planes[3];
+    const wuffs_base__table_u8* sp = &src->private_impl.planes[3];
+    if ((dp->width != WUFFS_BASE__PIXEL_FORMAT__INDEXED__PALETTE_BYTE_LENGTH) ||
+        (sp->width != WUFFS_BASE__PIXEL_FORMAT__INDEXED__PALETTE_BYTE_LENGTH)) {
+      return wuffs_base__make_status(wuffs_base__error__bad_argument);
+    }
+    memcpy(dp->ptr, sp->ptr,
+           WUFFS_BASE__PIXEL_FORMAT__INDEXED__PALETTE_BYTE_LENGTH);
+  }
+
+  // Each dst pixel (x, y) comes from src pixel (sx, sy).
+  size_t y;
+  for (y = 0; y < dh; y++) {
+    uint8_t* d_row = d->ptr + (y * d->stride);
+    if (orientation == WUFFS_BASE__ORIENTATION__TOP_LEFT) {
+      memcpy(d_row, s->ptr + (y * s->stride), dw * bpp);
+      continue;
+    } else if (orientation == WUFFS_BASE__ORIENTATION__BOTTOM_LEFT) {
+      memcpy(d_row, s->ptr + ((sh - 1 - y) * s->stride), dw * bpp);
+      continue;
+    }
+    size_t x;
+    for (x = 0; x < dw; x++) {
+      size_t sx = 0;
+      size_t sy = 0;
+      switch (orientation) {
+        case WUFFS_BASE__ORIENTATION__TOP_RIGHT:
+          sx = sw - 1 - x;
+          sy = y;
+          break;
+        case WUFFS_BASE__ORIENTATION__BOTTOM_RIGHT:
+          sx = sw - 1 - x;
+          sy = sh - 1 - y;
+          break;
+        case WUFFS_BASE__ORIENTATION__LEFT_TOP:
+          sx = y;
+          sy = x;
+          break;
+        case WUFFS_BASE__ORIENTATION__RIGHT_TOP:
+          sx = y;
+          sy = sh - 1 - x;
+          break;
+        case WUFFS_BASE__ORIENTATION__RIGHT_BOTTOM:
+          sx = sw - 1 - y;
+          sy = sh - 1 - x;
+          break;
+        case WUFFS_BASE__ORIENTATION__LEFT_BOTTOM:
+          sx = sw - 1 - y;
+          sy = x;
+          break;
+      }
+      memcpy(d_row + (x * bpp), s->ptr + (sy * s->stride) + (sx * bpp), bpp);
+    }
+  }
+  return wuffs_base__make_status(NULL);
+}
+
+// --------
+
 WUFFS_BASE__MAYBE_STATIC uint8_t  //
 wuffs_base__pixel_palette__closest_element(
     wuffs_base__slice_u8 palette_slice,
@@ -497,6 +1221,230 @@ wuffs_base__pixel_palette__closest_element(
   return (uint8_t)best_index;
 }
 
+// wuffs_base__private_implementation__pixel_palette__box is an axis-aligned
+// box of 15-bit colors, for median cut quantization. The lo and hi bounds are
+// inclusive, indexed by R (0), G (1) and B (2), and count is the number of
+// pixels (in the histogram) within the box.
+typedef struct wuffs_base__private_implementation__pixel_palette__box__struct {
+  uint32_t lo[3];
+  uint32_t hi[3];
+  uint64_t count;
+} wuffs_base__private_implementation__pixel_palette__box;
+
+// wuffs_base__private_implementation__pixel_palette__shrink_box shrinks the
+// box to the tightest bounds that hold the same non-zero histogram entries,
+// and recalculates its count.
+static void  //
+wuffs_base__private_implementation__pixel_palette__shrink_box(
+    wuffs_base__private_implementation__pixel_palette__box* box,
+    const uint8_t* histogram) {
+  uint32_t lo[3] = {31, 31, 31};
+  uint32_t hi[3] = {0, 0, 0};
+  uint64_t count = 0;
+  uint32_t c[3];
+  for (c[0] = box->lo[0]; c[0] <= box->hi[0]; c[0]++) {
+    for (c[1] = box->lo[1]; c[1] <= box->hi[1]; c[1]++) {
+      for (c[2] = box->lo[2]; c[2] <= box->hi[2]; c[2]++) {
+        uint32_t n = wuffs_base__peek_u32le__no_bounds_check(
+            histogram + (4 * ((c[0] << 10) | (c[1] << 5) | c[2])));
+        if (n == 0) {
+          continue;
+        }
+        count += n;
+        uint32_t k;
+        for (k = 0; k < 3; k++) {
+          lo[k] = wuffs_base__u32__min(lo[k], c[k]);
+          hi[k] = wuffs_base__u32__max(hi[k], c[k]);
+        }
+      }
+    }
+  }
+  if (count > 0) {
+    uint32_t k;
+    for (k = 0; k < 3; k++) {
+      box->lo[k] = lo[k];
+      box->hi[k] = hi[k];
+    }
+  }
+  box->count = count;
+}
+
+WUFFS_BASE__MAYBE_STATIC wuffs_base__result_u64  //
+wuffs_base__pixel_palette__quantize(wuffs_base__slice_u8 dst_palette,
+                                    const wuffs_base__pixel_buffer* src,
+                                    uint32_t max_num_colors,
+                                    wuffs_base__slice_u8 workbuf) {
+  wuffs_base__result_u64 ret;
+  ret.value = 0;
+  if (!src || (max_num_colors == 0) ||
+      (dst_palette.len !=
+       WUFFS_BASE__PIXEL_FORMAT__INDEXED__PALETTE_BYTE_LENGTH)) {
+    ret.status = wuffs_base__make_status(wuffs_base__error__bad_argument);
+    return ret;
+  } else if (workbuf.len < WUFFS_BASE__PIXEL_PALETTE__QUANTIZE__WORKBUF_LEN) {
+    ret.status = wuffs_base__make_status(wuffs_base__error__bad_workbuf_length);
+    return ret;
+  } else if (wuffs_base__pixel_format__is_planar(
+                 &src->pixcfg.private_impl.pixfmt)) {
+    ret.status = wuffs_base__make_status(wuffs_base__error__unsupported_option);
+    return ret;
+  }
+  max_num_colors = wuffs_base__u32__min(max_num_colors, 256);
+  uint32_t width = src->pixcfg.private_impl.width;
+  uint32_t height = src->pixcfg.private_impl.height;
+
+  // Build a histogram of the opaque pixels' 15-bit colors.
+  uint8_t* histogram = workbuf.ptr;
+  memset(histogram, 0, WUFFS_BASE__PIXEL_PALETTE__QUANTIZE__WORKBUF_LEN);
+  bool has_transparent = false;
+  uint32_t x;
+  uint32_t y;
+  for (y = 0; y < height; y++) {
+    for (x = 0; x < width; x++) {
+      wuffs_base__color_u32_argb_premul c =
+          wuffs_base__pixel_buffer__color_u32_at(src, x, y);
+      if ((c >> 24) < 0x80) {
+        has_transparent = true;
+        continue;
+      }
+      c = wuffs_base__color_u32_argb_premul__as__color_u32_argb_nonpremul(c);
+      uint8_t* h = histogram + (4 * (((0xF80000 & c) >> 9) |
+                                     ((0x00F800 & c) >> 6) |
+                                     ((0x0000F8 & c) >> 3)));
+      uint32_t n = wuffs_base__peek_u32le__no_bounds_check(h);
+      if (n < 0xFFFFFFFF) {
+        wuffs_base__poke_u32le__no_bounds_check(h, n + 1);
+      }
+    }
+  }
+
+  // Repeatedly split the box with the most pixels, along its longest axis at
+  // the median, until there are enough boxes (or no box can be split).
+  uint32_t num_transparent = has_transparent ? 1 : 0;
+  uint32_t max_num_boxes = max_num_colors - num_transparent;
+  wuffs_base__private_implementation__pixel_palette__box boxes[256];
+  uint32_t num_boxes = 0;
+  if (max_num_boxes > 0) {
+    uint32_t k;
+    for (k = 0; k < 3; k++) {
+      boxes[0].lo[k] = 0;
+      boxes[0].hi[k] = 31;
+    }
+    wuffs_base__private_implementation__pixel_palette__shrink_box(&boxes[0],
+                                                                  histogram);
+    num_boxes = (boxes[0].count > 0) ? 1 : 0;
+  }
+  while (num_boxes < max_num_boxes) {
+    uint32_t b = num_boxes;
+    uint32_t i;
+    for (i = 0; i < num_boxes; i++) {
+      if (((boxes[i].lo[0] < boxes[i].hi[0]) ||
+           (boxes[i].lo[1] < boxes[i].hi[1]) ||
+           (boxes[i].lo[2] < boxes[i].hi[2])) &&
+          ((b == num_boxes) || (boxes[b].count < boxes[i].count))) {
+        b = i;
+      }
+    }
+    if (b == num_boxes) {
+      break;
+    }
+
+    wuffs_base__private_implementation__pixel_palette__box* box = &boxes[b];
+    uint32_t axis = 0;
+    uint32_t k;
+    for (k = 1; k < 3; k++) {
+      if ((box->hi[axis] - box->lo[axis]) < (box->hi[k] - box->lo[k])) {
+        axis = k;
+      }
+    }
+
+    // Find the median plane, the first one at which the cumulative count
+    // reaches half of the total. Splitting just after it leaves both halves
+    // non-empty, as the box's bounds are tight.
+    uint64_t planes[32] = {0};
+    uint32_t c[3];
+    for (c[0] = box->lo[0]; c[0] <= box->hi[0]; c[0]++) {
+      for (c[1] = box->lo[1]; c[1] <= box->hi[1]; c[1]++) {
+        for (c[2] = box->lo[2]; c[2] <= box->hi[2]; c[2]++) {
+          planes[c[axis]] += wuffs_base__peek_u32le__no_bounds_check(
+              histogram + (4 * ((c[0] << 10) | (c[1] << 5) | c[2])));
+        }
+      }
+    }
+    uint32_t split = box->lo[axis];
+    uint64_t cumulative = planes[split];
+    while (((split + 1) < box->hi[axis]) &&
+           ((2 * cumulative) < box->count)) {
+      split++;
+      cumulative += planes[split];
+    }
+
+    boxes[num_boxes] = *box;
+    boxes[num_boxes].lo[axis] = split + 1;
+    box->hi[axis] = split;
+    wuffs_base__private_implementation__pixel_palette__shrink_box(box,
+                                                                  histogram);
+    wuffs_base__private_implementation__pixel_palette__shrink_box(
+        &boxes[num_boxes], histogram);
+    num_boxes++;
+  }
+
+  // Re-use the histogram to map each 15-bit color to its box. The boxes are
+  // disjoint.
+  uint32_t i;
+  for (i = 0; i < num_boxes; i++) {
+    uint32_t c[3];
+    for (c[0] = boxes[i].lo[0]; c[0] <= boxes[i].hi[0]; c[0]++) {
+      for (c[1] = boxes[i].lo[1]; c[1] <= boxes[i].hi[1]; c[1]++) {
+        for (c[2] = boxes[i].lo[2]; c[2] <= boxes[i].hi[2]; c[2]++) {
+          wuffs_base__poke_u32le__no_bounds_check(
+              histogram + (4 * ((c[0] << 10) | (c[1] << 5) | c[2])), i);
+        }
+      }
+    }
+  }
+
+  // Average each box's pixels' (8-bit, not 5-bit) colors.
+  uint64_t sums[256][4];
+  memset(&sums[0][0], 0, sizeof(sums));
+  for (y = 0; y < height; y++) {
+    for (x = 0; x < width; x++) {
+      wuffs_base__color_u32_argb_premul c =
+          wuffs_base__pixel_buffer__color_u32_at(src, x, y);
+      if ((c >> 24) < 0x80) {
+        continue;
+      }
+      c = wuffs_base__color_u32_argb_premul__as__color_u32_argb_nonpremul(c);
+      uint32_t j = wuffs_base__peek_u32le__no_bounds_check(
+          histogram + (4 * (((0xF80000 & c) >> 9) | ((0x00F800 & c) >> 6) |
+                            ((0x0000F8 & c) >> 3))));
+      if (j < num_boxes) {
+        sums[j][0] += 0xFF & (c >> 0);
+        sums[j][1] += 0xFF & (c >> 8);
+        sums[j][2] += 0xFF & (c >> 16);
+        sums[j][3] += 1;
+      }
+    }
+  }
+
+  memset(dst_palette.ptr, 0, dst_palette.len);
+  for (i = 0; i < num_boxes; i++) {
+    uint64_t n = sums[i][3];
+    if (n == 0) {
+      continue;
+    }
+    uint8_t* p = dst_palette.ptr + (4 * (num_transparent + i));
+    p[0] = (uint8_t)(((2 * sums[i][0]) + n) / (2 * n));
+    p[1] = (uint8_t)(((2 * sums[i][1]) + n) / (2 * n));
+    p[2] = (uint8_t)(((2 * sums[i][2]) + n) / (2 * n));
+    p[3] = 0xFF;
+  }
+
+  ret.status = wuffs_base__make_status(NULL);
+  ret.value = num_transparent + num_boxes;
+  return ret;
+}
+
 // --------
 
 static inline uint32_t  //
@@ -755,6 +1703,84 @@ wuffs_base__composite_premul_premul_u32_axxx(uint32_t dst_premul,
 
 // --------
 
+WUFFS_BASE__MAYBE_STATIC uint64_t  //
+wuffs_base__composite_nonpremul_over_nonpremul(wuffs_base__slice_u8 dst,
+                                               wuffs_base__slice_u8 src) {
+  size_t len = (dst.len < src.len ? dst.len : src.len) / 4;
+  uint8_t* d = dst.ptr;
+  const uint8_t* s = src.ptr;
+
+  size_t n = len;
+  while (n--) {
+    uint32_t d0 = wuffs_base__peek_u32le__no_bounds_check(d);
+    uint32_t s0 = wuffs_base__peek_u32le__no_bounds_check(s);
+    wuffs_base__poke_u32le__no_bounds_check(
+        d, wuffs_base__composite_nonpremul_nonpremul_u32_axxx(d0, s0));
+    s += 4;
+    d += 4;
+  }
+  return len;
+}
+
+WUFFS_BASE__MAYBE_STATIC uint64_t  //
+wuffs_base__composite_nonpremul_over_premul(wuffs_base__slice_u8 dst,
+                                            wuffs_base__slice_u8 src) {
+  size_t len = (dst.len < src.len ? dst.len : src.len) / 4;
+  uint8_t* d = dst.ptr;
+  const uint8_t* s = src.ptr;
+
+  size_t n = len;
+  while (n--) {
+    uint32_t d0 = wuffs_base__peek_u32le__no_bounds_check(d);
+    uint32_t s0 = wuffs_base__peek_u32le__no_bounds_check(s);
+    wuffs_base__poke_u32le__no_bounds_check(
+        d, wuffs_base__composite_premul_nonpremul_u32_axxx(d0, s0));
+    s += 4;
+    d += 4;
+  }
+  return len;
+}
+
+WUFFS_BASE__MAYBE_STATIC uint64_t  //
+wuffs_base__composite_premul_over_nonpremul(wuffs_base__slice_u8 dst,
+                                            wuffs_base__slice_u8 src) {
+  size_t len = (dst.len < src.len ? dst.len : src.len) / 4;
+  uint8_t* d = dst.ptr;
+  const uint8_t* s = src.ptr;
+
+  size_t n = len;
+  while (n--) {
+    uint32_t d0 = wuffs_base__peek_u32le__no_bounds_check(d);
+    uint32_t s0 = wuffs_base__peek_u32le__no_bounds_check(s);
+    wuffs_base__poke_u32le__no_bounds_check(
+        d, wuffs_base__composite_nonpremul_premul_u32_axxx(d0, s0));
+    s += 4;
+    d += 4;
+  }
+  return len;
+}
+
+WUFFS_BASE__MAYBE_STATIC uint64_t  //
+wuffs_base__composite_premul_over_premul(wuffs_base__slice_u8 dst,
+                                         wuffs_base__slice_u8 src) {
+  size_t len = (dst.len < src.len ? dst.len : src.len) / 4;
+  uint8_t* d = dst.ptr;
+  const uint8_t* s = src.ptr;
+
+  size_t n = len;
+  while (n--) {
+    uint32_t d0 = wuffs_base__peek_u32le__no_bounds_check(d);
+    uint32_t s0 = wuffs_base__peek_u32le__no_bounds_check(s);
+    wuffs_base__poke_u32le__no_bounds_check(
+        d, wuffs_base__composite_premul_premul_u32_axxx(d0, s0));
+    s += 4;
+    d += 4;
+  }
+  return len;
+}
+
+// --------
+
 static uint64_t  //
 wuffs_base__pixel_swizzler__squash_align4_bgr_565_8888(uint8_t* dst_ptr,
                                                        size_t dst_len,
@@ -811,6 +1837,54 @@ wuffs_base__pixel_swizzler__swap_rgb_bgr(uint8_t* dst_ptr,
   return len;
 }
 
+// ‼ WUFFS MULTI-FILE SECTION +wasm_simd128
+#if defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+static uint64_t  //
+wuffs_base__pixel_swizzler__swap_rgbx_bgrx__wasm_simd128(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  size_t len = (dst_len < src_len ? dst_len : src_len) / 4;
+  uint8_t* d = dst_ptr;
+  const uint8_t* s = src_ptr;
+  size_t n = len;
+
+  v128_t swizzle = wasm_u8x16_make(0x02, 0x01, 0x00, 0x03,  //
+                                   0x06, 0x05, 0x04, 0x07,  //
+                                   0x0A, 0x09, 0x08, 0x0B,  //
+                                   0x0E, 0x0D, 0x0C, 0x0F);
+
+  while (n >= 4) {
+    v128_t x;
+    x = wasm_v128_load(s);
+    x = wasm_i8x16_swizzle(x, swizzle);
+    wasm_v128_store(d, x);
+
+    s += 4 * 4;
+    d += 4 * 4;
+    n -= 4;
+  }
+
+  while (n--) {
+    uint8_t s0 = s[0];
+    uint8_t s1 = s[1];
+    uint8_t s2 = s[2];
+    uint8_t s3 = s[3];
+    d[0] = s2;
+    d[1] = s1;
+    d[2] = s0;
+    d[3] = s3;
+    s += 4;
+    d += 4;
+  }
+  return len;
+}
+#endif  // defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+// ‼ WUFFS MULTI-FILE SECTION -wasm_simd128
+
 // ‼ WUFFS MULTI-FILE SECTION +x86_sse42
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
 WUFFS_BASE__MAYBE_ATTRIBUTE_TARGET("pclmul,popcnt,sse4.2")
@@ -966,6 +2040,22 @@ wuffs_base__pixel_swizzler__copy_8_8(uint8_t* dst_ptr,
   return len;
 }
 
+static uint64_t  //
+wuffs_base__pixel_swizzler__copy_16_16(uint8_t* dst_ptr,
+                                       size_t dst_len,
+                                       uint8_t* dst_palette_ptr,
+                                       size_t dst_palette_len,
+                                       const uint8_t* src_ptr,
+                                       size_t src_len) {
+  size_t dst_len16 = dst_len / 16;
+  size_t src_len16 = src_len / 16;
+  size_t len = (dst_len16 < src_len16) ? dst_len16 : src_len16;
+  if (len > 0) {
+    memmove(dst_ptr, src_ptr, len * 16);
+  }
+  return len;
+}
+
 // --------
 
 static uint64_t  //
@@ -3134,6 +4224,62 @@ wuffs_base__pixel_swizzler__bgrw__bgrx(uint8_t* dst_ptr,
   return len;
 }
 
+// ‼ WUFFS MULTI-FILE SECTION +wasm_simd128
+#if defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+static uint64_t  //
+wuffs_base__pixel_swizzler__bgrw__rgb__wasm_simd128(uint8_t* dst_ptr,
+                                                    size_t dst_len,
+                                                    uint8_t* dst_palette_ptr,
+                                                    size_t dst_palette_len,
+                                                    const uint8_t* src_ptr,
+                                                    size_t src_len) {
+  size_t dst_len4 = dst_len / 4;
+  size_t src_len3 = src_len / 3;
+  size_t len = (dst_len4 < src_len3) ? dst_len4 : src_len3;
+  uint8_t* d = dst_ptr;
+  const uint8_t* s = src_ptr;
+  size_t n = len;
+
+  // Out-of-range (0x80 or more) wasm_i8x16_swizzle indexes produce zero.
+  v128_t swizzle = wasm_u8x16_make(0x02, 0x01, 0x00, 0x80,  //
+                                   0x05, 0x04, 0x03, 0x80,  //
+                                   0x08, 0x07, 0x06, 0x80,  //
+                                   0x0B, 0x0A, 0x09, 0x80);
+  v128_t or_ff = wasm_u32x4_splat(0xFF000000);
+
+  // Each iteration loads 16 bytes but only consumes 12 (4 pixels), so
+  // require 6 remaining pixels (18 bytes), like the x86_sse42 version.
+  while (n >= 6) {
+    v128_t x;
+    x = wasm_v128_load(s);
+    x = wasm_i8x16_swizzle(x, swizzle);
+    x = wasm_v128_or(x, or_ff);
+    wasm_v128_store(d, x);
+
+    s += 4 * 3;
+    d += 4 * 4;
+    n -= 4;
+  }
+
+  while (n >= 1) {
+    uint8_t b0 = s[0];
+    uint8_t b1 = s[1];
+    uint8_t b2 = s[2];
+    d[0] = b2;
+    d[1] = b1;
+    d[2] = b0;
+    d[3] = 0xFF;
+
+    s += 1 * 3;
+    d += 1 * 4;
+    n -= 1;
+  }
+
+  return len;
+}
+#endif  // defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+// ‼ WUFFS MULTI-FILE SECTION -wasm_simd128
+
 // ‼ WUFFS MULTI-FILE SECTION +x86_sse42
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
 WUFFS_BASE__MAYBE_ATTRIBUTE_TARGET("pclmul,popcnt,sse4.2")
@@ -3846,6 +4992,53 @@ wuffs_base__pixel_swizzler__xxxx__index_binary_alpha__src_over(
   return len;
 }
 
+// ‼ WUFFS MULTI-FILE SECTION +wasm_simd128
+#if defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+static uint64_t  //
+wuffs_base__pixel_swizzler__xxxx__y__wasm_simd128(uint8_t* dst_ptr,
+                                                  size_t dst_len,
+                                                  uint8_t* dst_palette_ptr,
+                                                  size_t dst_palette_len,
+                                                  const uint8_t* src_ptr,
+                                                  size_t src_len) {
+  size_t dst_len4 = dst_len / 4;
+  size_t len = (dst_len4 < src_len) ? dst_len4 : src_len;
+  uint8_t* d = dst_ptr;
+  const uint8_t* s = src_ptr;
+  size_t n = len;
+
+  v128_t swizzle = wasm_u8x16_make(0x00, 0x00, 0x00, 0x00,  //
+                                   0x01, 0x01, 0x01, 0x01,  //
+                                   0x02, 0x02, 0x02, 0x02,  //
+                                   0x03, 0x03, 0x03, 0x03);
+  v128_t or_ff = wasm_u32x4_splat(0xFF000000);
+
+  while (n >= 4) {
+    v128_t x;
+    x = wasm_u32x4_splat(wuffs_base__peek_u32le__no_bounds_check(s));
+    x = wasm_i8x16_swizzle(x, swizzle);
+    x = wasm_v128_or(x, or_ff);
+    wasm_v128_store(d, x);
+
+    s += 4 * 1;
+    d += 4 * 4;
+    n -= 4;
+  }
+
+  while (n >= 1) {
+    wuffs_base__poke_u32le__no_bounds_check(
+        d + (0 * 4), 0xFF000000 | (0x010101 * (uint32_t)s[0]));
+
+    s += 1 * 1;
+    d += 1 * 4;
+    n -= 1;
+  }
+
+  return len;
+}
+#endif  // defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+// ‼ WUFFS MULTI-FILE SECTION -wasm_simd128
+
 // ‼ WUFFS MULTI-FILE SECTION +x86_sse42
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
 WUFFS_BASE__MAYBE_ATTRIBUTE_TARGET("pclmul,popcnt,sse4.2")
@@ -4068,30 +5261,270 @@ wuffs_base__pixel_swizzler__xxxxxxxx__y_16be(uint8_t* dst_ptr,
   return len;
 }
 
-// --------
-
+// --------
+
+static uint64_t  //
+wuffs_base__pixel_swizzler__y__y_16be(uint8_t* dst_ptr,
+                                      size_t dst_len,
+                                      uint8_t* dst_palette_ptr,
+                                      size_t dst_palette_len,
+                                      const uint8_t* src_ptr,
+                                      size_t src_len) {
+  size_t src_len2 = src_len / 2;
+  size_t len = (dst_len < src_len2) ? dst_len : src_len2;
+  uint8_t* d = dst_ptr;
+  const uint8_t* s = src_ptr;
+  size_t n = len;
+
+  while (n >= 1) {
+    d[0] = s[0];
+
+    s += 1 * 2;
+    d += 1 * 1;
+    n -= 1;
+  }
+
+  return len;
+}
+
+// --------
+
+// wuffs_base__private_implementation__f32le__as__u16 converts a little-endian
+// IEEE 754 float32 value, nominally in the range [0, 1], to a 16-bit value.
+// Out of range values (including infinities) are clamped and NaN becomes 0.
+// There is no tone mapping or gamma correction.
+static inline uint64_t  //
+wuffs_base__private_implementation__f32le__as__u16(const uint8_t* p) {
+  double x = wuffs_base__private_implementation__f64_clamp_0_1(
+      wuffs_base__ieee_754_bit_representation__from_u32_to_f64(
+          wuffs_base__peek_u32le__no_bounds_check(p)));
+  return (uint64_t)((x * 65535.0) + 0.5);
+}
+
+// wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le converts
+// RGBA_NONPREMUL_4XF32LE source pixels, in batches, to BGRA_NONPREMUL_4X16LE
+// and then passes each batch to func, a swizzler whose source pixel format is
+// BGRA_NONPREMUL_4X16LE.
+static uint64_t  //
+wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+    wuffs_base__pixel_swizzler__func func,
+    size_t dst_bytes_per_pixel,
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  uint8_t buf[64 * 8];
+  size_t dst_lenx = dst_len / dst_bytes_per_pixel;
+  size_t src_len16 = src_len / 16;
+  size_t len = (dst_lenx < src_len16) ? dst_lenx : src_len16;
+  uint8_t* d = dst_ptr;
+  const uint8_t* s = src_ptr;
+  size_t n = len;
+
+  while (n >= 1) {
+    size_t m = (n < 64) ? n : 64;
+    size_t i;
+    for (i = 0; i < m; i++) {
+      const uint8_t* p = s + (i * 16);
+      uint64_t r = wuffs_base__private_implementation__f32le__as__u16(p + 0);
+      uint64_t g = wuffs_base__private_implementation__f32le__as__u16(p + 4);
+      uint64_t b = wuffs_base__private_implementation__f32le__as__u16(p + 8);
+      uint64_t a = wuffs_base__private_implementation__f32le__as__u16(p + 12);
+      wuffs_base__poke_u64le__no_bounds_check(
+          buf + (i * 8), (a << 48) | (r << 32) | (g << 16) | (b << 0));
+    }
+    (*func)(d, m * dst_bytes_per_pixel, dst_palette_ptr, dst_palette_len, buf,
+            m * 8);
+
+    s += m * 16;
+    d += m * dst_bytes_per_pixel;
+    n -= m;
+  }
+  return len;
+}
+
+static uint64_t  //
+wuffs_base__pixel_swizzler__bgr_565__rgba_nonpremul_4xf32le__src(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__bgr_565__bgra_nonpremul_4x16le__src, 2, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
+
+static uint64_t  //
+wuffs_base__pixel_swizzler__bgr_565__rgba_nonpremul_4xf32le__src_over(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__bgr_565__bgra_nonpremul_4x16le__src_over, 2, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
+
+static uint64_t  //
+wuffs_base__pixel_swizzler__bgr__rgba_nonpremul_4xf32le__src(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__bgr__bgra_nonpremul_4x16le__src, 3, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
+
+static uint64_t  //
+wuffs_base__pixel_swizzler__bgr__rgba_nonpremul_4xf32le__src_over(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__bgr__bgra_nonpremul_4x16le__src_over, 3, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
+
+static uint64_t  //
+wuffs_base__pixel_swizzler__bgra_nonpremul__rgba_nonpremul_4xf32le__src(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__bgra_nonpremul__bgra_nonpremul_4x16le__src, 4, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
+
+static uint64_t  //
+wuffs_base__pixel_swizzler__bgra_nonpremul__rgba_nonpremul_4xf32le__src_over(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__bgra_nonpremul__bgra_nonpremul_4x16le__src_over, 4, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
+
+static uint64_t  //
+wuffs_base__pixel_swizzler__bgra_nonpremul_4x16le__rgba_nonpremul_4xf32le__src(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__copy_8_8, 8, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
+
+static uint64_t  //
+wuffs_base__pixel_swizzler__bgra_nonpremul_4x16le__rgba_nonpremul_4xf32le__src_over(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__bgra_nonpremul_4x16le__bgra_nonpremul_4x16le__src_over, 8, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
+
+static uint64_t  //
+wuffs_base__pixel_swizzler__bgra_premul__rgba_nonpremul_4xf32le__src(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__bgra_premul__bgra_nonpremul_4x16le__src, 4, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
+
+static uint64_t  //
+wuffs_base__pixel_swizzler__bgra_premul__rgba_nonpremul_4xf32le__src_over(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__bgra_premul__bgra_nonpremul_4x16le__src_over, 4, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
+
+static uint64_t  //
+wuffs_base__pixel_swizzler__rgba_nonpremul__rgba_nonpremul_4xf32le__src(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__rgba_nonpremul__bgra_nonpremul_4x16le__src, 4, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
+
 static uint64_t  //
-wuffs_base__pixel_swizzler__y__y_16be(uint8_t* dst_ptr,
-                                      size_t dst_len,
-                                      uint8_t* dst_palette_ptr,
-                                      size_t dst_palette_len,
-                                      const uint8_t* src_ptr,
-                                      size_t src_len) {
-  size_t src_len2 = src_len / 2;
-  size_t len = (dst_len < src_len2) ? dst_len : src_len2;
-  uint8_t* d = dst_ptr;
-  const uint8_t* s = src_ptr;
-  size_t n = len;
-
-  while (n >= 1) {
-    d[0] = s[0];
+wuffs_base__pixel_swizzler__rgba_nonpremul__rgba_nonpremul_4xf32le__src_over(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__rgba_nonpremul__bgra_nonpremul_4x16le__src_over, 4, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
 
-    s += 1 * 2;
-    d += 1 * 1;
-    n -= 1;
-  }
+static uint64_t  //
+wuffs_base__pixel_swizzler__rgba_premul__rgba_nonpremul_4xf32le__src(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__bgra_premul__rgba_nonpremul_4x16le__src, 4, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
+}
 
-  return len;
+static uint64_t  //
+wuffs_base__pixel_swizzler__rgba_premul__rgba_nonpremul_4xf32le__src_over(
+    uint8_t* dst_ptr,
+    size_t dst_len,
+    uint8_t* dst_palette_ptr,
+    size_t dst_palette_len,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  return wuffs_base__private_implementation__swizzle_rgba_nonpremul_4xf32le(
+      &wuffs_base__pixel_swizzler__bgra_premul__rgba_nonpremul_4x16le__src_over, 4, dst_ptr, dst_len, dst_palette_ptr,
+      dst_palette_len, src_ptr, src_len);
 }
 
 // --------
@@ -4154,6 +5587,11 @@ wuffs_base__pixel_swizzler__prepare__y(wuffs_base__pixel_swizzler* p,
     case WUFFS_BASE__PIXEL_FORMAT__RGBA_PREMUL:
     case WUFFS_BASE__PIXEL_FORMAT__RGBA_BINARY:
     case WUFFS_BASE__PIXEL_FORMAT__RGBX:
+#if defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+      if (wuffs_base__cpu_arch__have_wasm_simd128()) {
+        return wuffs_base__pixel_swizzler__xxxx__y__wasm_simd128;
+      }
+#endif
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
       if (wuffs_base__cpu_arch__have_x86_sse42()) {
         return wuffs_base__pixel_swizzler__xxxx__y__sse42;
@@ -4532,6 +5970,11 @@ wuffs_base__pixel_swizzler__prepare__bgr(wuffs_base__pixel_swizzler* p,
     case WUFFS_BASE__PIXEL_FORMAT__RGBA_PREMUL:
     case WUFFS_BASE__PIXEL_FORMAT__RGBA_BINARY:
     case WUFFS_BASE__PIXEL_FORMAT__RGBX:
+#if defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+      if (wuffs_base__cpu_arch__have_wasm_simd128()) {
+        return wuffs_base__pixel_swizzler__bgrw__rgb__wasm_simd128;
+      }
+#endif
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
       if (wuffs_base__cpu_arch__have_x86_sse42()) {
         return wuffs_base__pixel_swizzler__bgrw__rgb__sse42;
@@ -4607,6 +6050,11 @@ wuffs_base__pixel_swizzler__prepare__bgra_nonpremul(
     case WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL:
       switch (blend) {
         case WUFFS_BASE__PIXEL_BLEND__SRC:
+#if defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+          if (wuffs_base__cpu_arch__have_wasm_simd128()) {
+            return wuffs_base__pixel_swizzler__swap_rgbx_bgrx__wasm_simd128;
+          }
+#endif
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
           if (wuffs_base__cpu_arch__have_x86_sse42()) {
             return wuffs_base__pixel_swizzler__swap_rgbx_bgrx__sse42;
@@ -4788,6 +6236,11 @@ wuffs_base__pixel_swizzler__prepare__bgra_premul(
     case WUFFS_BASE__PIXEL_FORMAT__RGBA_PREMUL:
       switch (blend) {
         case WUFFS_BASE__PIXEL_BLEND__SRC:
+#if defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+          if (wuffs_base__cpu_arch__have_wasm_simd128()) {
+            return wuffs_base__pixel_swizzler__swap_rgbx_bgrx__wasm_simd128;
+          }
+#endif
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
           if (wuffs_base__cpu_arch__have_x86_sse42()) {
             return wuffs_base__pixel_swizzler__swap_rgbx_bgrx__sse42;
@@ -4856,6 +6309,11 @@ wuffs_base__pixel_swizzler__prepare__rgb(wuffs_base__pixel_swizzler* p,
     case WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL:
     case WUFFS_BASE__PIXEL_FORMAT__BGRA_BINARY:
     case WUFFS_BASE__PIXEL_FORMAT__BGRX:
+#if defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+      if (wuffs_base__cpu_arch__have_wasm_simd128()) {
+        return wuffs_base__pixel_swizzler__bgrw__rgb__wasm_simd128;
+      }
+#endif
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
       if (wuffs_base__cpu_arch__have_x86_sse42()) {
         return wuffs_base__pixel_swizzler__bgrw__rgb__sse42;
@@ -4907,6 +6365,11 @@ wuffs_base__pixel_swizzler__prepare__rgba_nonpremul(
     case WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL:
       switch (blend) {
         case WUFFS_BASE__PIXEL_BLEND__SRC:
+#if defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+          if (wuffs_base__cpu_arch__have_wasm_simd128()) {
+            return wuffs_base__pixel_swizzler__swap_rgbx_bgrx__wasm_simd128;
+          }
+#endif
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
           if (wuffs_base__cpu_arch__have_x86_sse42()) {
             return wuffs_base__pixel_swizzler__swap_rgbx_bgrx__sse42;
@@ -5018,6 +6481,11 @@ wuffs_base__pixel_swizzler__prepare__rgba_premul(
     case WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL:
       switch (blend) {
         case WUFFS_BASE__PIXEL_BLEND__SRC:
+#if defined(WUFFS_BASE__CPU_ARCH__WASM_SIMD128)
+          if (wuffs_base__cpu_arch__have_wasm_simd128()) {
+            return wuffs_base__pixel_swizzler__swap_rgbx_bgrx__wasm_simd128;
+          }
+#endif
 #if defined(WUFFS_BASE__CPU_ARCH__X86_64)
           if (wuffs_base__cpu_arch__have_x86_sse42()) {
             return wuffs_base__pixel_swizzler__swap_rgbx_bgrx__sse42;
@@ -5050,6 +6518,104 @@ wuffs_base__pixel_swizzler__prepare__rgba_premul(
   return NULL;
 }
 
+static wuffs_base__pixel_swizzler__func  //
+wuffs_base__pixel_swizzler__prepare__rgba_nonpremul_4xf32le(
+    wuffs_base__pixel_swizzler* p,
+    wuffs_base__pixel_format dst_pixfmt,
+    wuffs_base__slice_u8 dst_palette,
+    wuffs_base__slice_u8 src_palette,
+    wuffs_base__pixel_blend blend) {
+  switch (dst_pixfmt.repr) {
+    case WUFFS_BASE__PIXEL_FORMAT__BGR_565:
+      switch (blend) {
+        case WUFFS_BASE__PIXEL_BLEND__SRC:
+          return wuffs_base__pixel_swizzler__bgr_565__rgba_nonpremul_4xf32le__src;
+        case WUFFS_BASE__PIXEL_BLEND__SRC_OVER:
+          return wuffs_base__pixel_swizzler__bgr_565__rgba_nonpremul_4xf32le__src_over;
+      }
+      return NULL;
+
+    case WUFFS_BASE__PIXEL_FORMAT__BGR:
+      switch (blend) {
+        case WUFFS_BASE__PIXEL_BLEND__SRC:
+          return wuffs_base__pixel_swizzler__bgr__rgba_nonpremul_4xf32le__src;
+        case WUFFS_BASE__PIXEL_BLEND__SRC_OVER:
+          return wuffs_base__pixel_swizzler__bgr__rgba_nonpremul_4xf32le__src_over;
+      }
+      return NULL;
+
+    case WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL:
+      switch (blend) {
+        case WUFFS_BASE__PIXEL_BLEND__SRC:
+          return wuffs_base__pixel_swizzler__bgra_nonpremul__rgba_nonpremul_4xf32le__src;
+        case WUFFS_BASE__PIXEL_BLEND__SRC_OVER:
+          return wuffs_base__pixel_swizzler__bgra_nonpremul__rgba_nonpremul_4xf32le__src_over;
+      }
+      return NULL;
+
+    case WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL_4X16LE:
+      switch (blend) {
+        case WUFFS_BASE__PIXEL_BLEND__SRC:
+          return wuffs_base__pixel_swizzler__bgra_nonpremul_4x16le__rgba_nonpremul_4xf32le__src;
+        case WUFFS_BASE__PIXEL_BLEND__SRC_OVER:
+          return wuffs_base__pixel_swizzler__bgra_nonpremul_4x16le__rgba_nonpremul_4xf32le__src_over;
+      }
+      return NULL;
+
+    case WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL:
+      switch (blend) {
+        case WUFFS_BASE__PIXEL_BLEND__SRC:
+          return wuffs_base__pixel_swizzler__bgra_premul__rgba_nonpremul_4xf32le__src;
+        case WUFFS_BASE__PIXEL_BLEND__SRC_OVER:
+          return wuffs_base__pixel_swizzler__bgra_premul__rgba_nonpremul_4xf32le__src_over;
+      }
+      return NULL;
+
+    case WUFFS_BASE__PIXEL_FORMAT__BGRA_BINARY:
+    case WUFFS_BASE__PIXEL_FORMAT__BGRX:
+      // TODO.
+      break;
+
+    case WUFFS_BASE__PIXEL_FORMAT__RGB:
+      // TODO.
+      break;
+
+    case WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL:
+      switch (blend) {
+        case WUFFS_BASE__PIXEL_BLEND__SRC:
+          return wuffs_base__pixel_swizzler__rgba_nonpremul__rgba_nonpremul_4xf32le__src;
+        case WUFFS_BASE__PIXEL_BLEND__SRC_OVER:
+          return wuffs_base__pixel_swizzler__rgba_nonpremul__rgba_nonpremul_4xf32le__src_over;
+      }
+      return NULL;
+
+    case WUFFS_BASE__PIXEL_FORMAT__RGBA_PREMUL:
+      switch (blend) {
+        case WUFFS_BASE__PIXEL_BLEND__SRC:
+          return wuffs_base__pixel_swizzler__rgba_premul__rgba_nonpremul_4xf32le__src;
+        case WUFFS_BASE__PIXEL_BLEND__SRC_OVER:
+          return wuffs_base__pixel_swizzler__rgba_premul__rgba_nonpremul_4xf32le__src_over;
+      }
+      return NULL;
+
+    case WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL_4XF32LE:
+      switch (blend) {
+        case WUFFS_BASE__PIXEL_BLEND__SRC:
+          return wuffs_base__pixel_swizzler__copy_16_16;
+        case WUFFS_BASE__PIXEL_BLEND__SRC_OVER:
+          // TODO.
+          break;
+      }
+      return NULL;
+
+    case WUFFS_BASE__PIXEL_FORMAT__RGBA_BINARY:
+    case WUFFS_BASE__PIXEL_FORMAT__RGBX:
+      // TODO.
+      break;
+  }
+  return NULL;
+}
+
 // --------
 
 WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
@@ -5064,10 +6630,17 @@ wuffs_base__pixel_swizzler__prepare(wuffs_base__pixel_swizzler* p,
   }
   p->private_impl.func = NULL;
   p->private_impl.transparent_black_func = NULL;
+  p->private_impl.bgrx_func = NULL;
   p->private_impl.dst_pixfmt_bytes_per_pixel = 0;
   p->private_impl.src_pixfmt_bytes_per_pixel = 0;
+  p->private_impl.dst_pixfmt = dst_pixfmt;
+  p->private_impl.blend = blend;
+  p->private_impl.color_transform = NULL;
+  p->private_impl.quantize_func = NULL;
+  p->private_impl.ditherer = NULL;
 
   wuffs_base__pixel_swizzler__func func = NULL;
+  wuffs_base__pixel_swizzler__func bgrx_func = NULL;
   wuffs_base__pixel_swizzler__transparent_black_func transparent_black_func =
       NULL;
 
@@ -5079,9 +6652,12 @@ wuffs_base__pixel_swizzler__prepare(wuffs_base__pixel_swizzler* p,
         wuffs_base__error__unsupported_pixel_swizzler_option);
   }
 
+  // Planar pixel formats have zero bits_per_pixel. Of those, only YCBCR is
+  // supported, via wuffs_base__pixel_swizzler__swizzle_ycbcr.
   uint32_t src_pixfmt_bits_per_pixel =
       wuffs_base__pixel_format__bits_per_pixel(&src_pixfmt);
-  if ((src_pixfmt_bits_per_pixel == 0) ||
+  if (((src_pixfmt_bits_per_pixel == 0) &&
+       (src_pixfmt.repr != WUFFS_BASE__PIXEL_FORMAT__YCBCR)) ||
       ((src_pixfmt_bits_per_pixel & 7) != 0)) {
     return wuffs_base__make_status(
         wuffs_base__error__unsupported_pixel_swizzler_option);
@@ -5166,14 +6742,272 @@ wuffs_base__pixel_swizzler__prepare(wuffs_base__pixel_swizzler* p,
       func = wuffs_base__pixel_swizzler__prepare__rgba_premul(
           p, dst_pixfmt, dst_palette, src_palette, blend);
       break;
+
+    case WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL_4XF32LE:
+      func = wuffs_base__pixel_swizzler__prepare__rgba_nonpremul_4xf32le(
+          p, dst_pixfmt, dst_palette, src_palette, blend);
+      break;
+
+    case WUFFS_BASE__PIXEL_FORMAT__YCBCR:
+      // Planar sources go through wuffs_base__pixel_swizzler__swizzle_ycbcr,
+      // which converts to opaque BGRX first. The swizzle_interleaved_etc
+      // functions are no-ops, as func remains NULL.
+      bgrx_func = wuffs_base__pixel_swizzler__prepare__bgrx(
+          p, dst_pixfmt, dst_palette, src_palette, blend);
+      break;
+  }
+
+  // Truecolor sources can also be quantized to BGR_565 or indexed
+  // destinations, via an intermediate BGRA_PREMUL row. This recursion is only
+  // one level deep, as BGRA_PREMUL is neither BGR_565 nor indexed.
+  wuffs_base__pixel_swizzler__func quantize_func = NULL;
+  if ((blend == WUFFS_BASE__PIXEL_BLEND__SRC) &&
+      !wuffs_base__pixel_format__is_indexed(&src_pixfmt) &&
+      !wuffs_base__pixel_format__is_planar(&src_pixfmt)) {
+    switch (dst_pixfmt.repr) {
+      case WUFFS_BASE__PIXEL_FORMAT__INDEXED__BGRA_NONPREMUL:
+      case WUFFS_BASE__PIXEL_FORMAT__INDEXED__BGRA_BINARY:
+      case WUFFS_BASE__PIXEL_FORMAT__BGR_565: {
+        wuffs_base__pixel_swizzler q;
+        wuffs_base__pixel_swizzler__prepare(
+            &q,
+            wuffs_base__make_pixel_format(
+                WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL),
+            wuffs_base__empty_slice_u8(), src_pixfmt,
+            wuffs_base__empty_slice_u8(), WUFFS_BASE__PIXEL_BLEND__SRC);
+        quantize_func = q.private_impl.func;
+        break;
+      }
+    }
   }
 
   p->private_impl.func = func;
   p->private_impl.transparent_black_func = transparent_black_func;
+  p->private_impl.bgrx_func = bgrx_func;
+  p->private_impl.quantize_func = quantize_func;
   p->private_impl.dst_pixfmt_bytes_per_pixel = dst_pixfmt_bits_per_pixel / 8;
   p->private_impl.src_pixfmt_bytes_per_pixel = src_pixfmt_bits_per_pixel / 8;
-  return wuffs_base__make_status(
-      func ? NULL : wuffs_base__error__unsupported_pixel_swizzler_option);
+  if (!func && !bgrx_func && !quantize_func) {
+    return wuffs_base__make_status(
+        wuffs_base__error__unsupported_pixel_swizzler_option);
+  }
+  return wuffs_base__make_status(NULL);
+}
+
+WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
+wuffs_base__pixel_swizzler__set_color_transform(
+    wuffs_base__pixel_swizzler* p,
+    const wuffs_base__color_transform* t) {
+  if (!p) {
+    return wuffs_base__make_status(wuffs_base__error__bad_receiver);
+  } else if (t && ((p->private_impl.blend != WUFFS_BASE__PIXEL_BLEND__SRC) ||
+                   !wuffs_base__color_transform__supports_pixel_format(
+                       p->private_impl.dst_pixfmt))) {
+    return wuffs_base__make_status(wuffs_base__error__unsupported_option);
+  }
+  p->private_impl.color_transform = t;
+  return wuffs_base__make_status(NULL);
+}
+
+WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
+wuffs_base__pixel_swizzler__set_ditherer(wuffs_base__pixel_swizzler* p,
+                                         wuffs_base__pixel_ditherer* d) {
+  if (!p) {
+    return wuffs_base__make_status(wuffs_base__error__bad_receiver);
+  } else if (d && !p->private_impl.quantize_func) {
+    return wuffs_base__make_status(wuffs_base__error__unsupported_option);
+  }
+  p->private_impl.ditherer = d;
+  wuffs_base__pixel_ditherer__reset(d);
+  return wuffs_base__make_status(NULL);
+}
+
+// wuffs_base__private_implementation__pixel_swizzler__quantize converts src
+// pixels, up to 256 at a time, to an intermediate BGRA_PREMUL row and then
+// quantizes them (dithering as per p's ditherer) to the BGR_565 or indexed
+// dst. It returns the number of pixels converted.
+static uint64_t  //
+wuffs_base__private_implementation__pixel_swizzler__quantize(
+    const wuffs_base__pixel_swizzler* p,
+    wuffs_base__slice_u8 dst,
+    wuffs_base__slice_u8 dst_palette,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  static const int32_t bayer[4][4] = {
+      {0, 8, 2, 10},
+      {12, 4, 14, 6},
+      {3, 11, 1, 9},
+      {15, 7, 13, 5},
+  };
+  // steps are the B, G and R quantization step sizes, scaling the ORDERED
+  // dither's offsets. Arbitrary palettes have no fixed step size. 32 suits a
+  // palette that, like a 6x6x6 color cube, is roughly evenly spread.
+  static const int32_t steps_565[3] = {8, 4, 8};
+  static const int32_t steps_indexed[3] = {32, 32, 32};
+
+  wuffs_base__pixel_ditherer* d = p->private_impl.ditherer;
+  wuffs_base__pixel_dither dither =
+      d ? d->private_impl.dither : WUFFS_BASE__PIXEL_DITHER__NONE;
+  bool indexed =
+      p->private_impl.dst_pixfmt.repr != WUFFS_BASE__PIXEL_FORMAT__BGR_565;
+  bool nonpremul = p->private_impl.dst_pixfmt.repr ==
+                   WUFFS_BASE__PIXEL_FORMAT__INDEXED__BGRA_NONPREMUL;
+  const int32_t* steps = indexed ? steps_indexed : steps_565;
+  if (indexed && (dst_palette.len !=
+                  WUFFS_BASE__PIXEL_FORMAT__INDEXED__PALETTE_BYTE_LENGTH)) {
+    return 0;
+  }
+
+  size_t dst_bpp = p->private_impl.dst_pixfmt_bytes_per_pixel;
+  size_t src_bpp = p->private_impl.src_pixfmt_bytes_per_pixel;
+  uint64_t num_pixels = 0;
+  uint8_t bgra[4 * 256];
+  while (true) {
+    size_t n = dst.len / dst_bpp;
+    if (n > (src_len / src_bpp)) {
+      n = src_len / src_bpp;
+    }
+    if (n > 256) {
+      n = 256;
+    } else if (n == 0) {
+      break;
+    }
+    (*p->private_impl.quantize_func)(&bgra[0], 4 * n, NULL, 0, src_ptr,
+                                     n * src_bpp);
+
+    size_t i;
+    for (i = 0; i < n; i++) {
+      const uint8_t* s = &bgra[4 * i];
+      int32_t a = (int32_t)(s[3]);
+      uint8_t* e = NULL;
+      if (dither == WUFFS_BASE__PIXEL_DITHER__FLOYD_STEINBERG) {
+        e = d->private_impl.workbuf.ptr + (6 * ((size_t)d->private_impl.x));
+      }
+
+      // Add the dither offsets, clamping to stay premultiplied.
+      int32_t v[3];
+      uint32_t k;
+      for (k = 0; k < 3; k++) {
+        int32_t vk = (int32_t)(s[k]);
+        if (dither == WUFFS_BASE__PIXEL_DITHER__ORDERED) {
+          int32_t t = bayer[d->private_impl.y & 3][d->private_impl.x & 3];
+          vk += (((2 * t) - 15) * steps[k]) / 32;
+        } else if (e) {
+          int32_t w =
+              (int32_t)wuffs_base__peek_u16le__no_bounds_check(e + (2 * k));
+          if (w >= 0x8000) {
+            w -= 0x10000;
+          }
+          vk += (d->private_impl.carry[k] + w) / 16;
+        }
+        v[k] = (vk < 0) ? 0 : ((vk > a) ? a : vk);
+      }
+
+      // Quantize, setting q to the (premultiplied) value written.
+      int32_t q[3];
+      if (indexed) {
+        uint8_t index = wuffs_base__pixel_palette__closest_element(
+            dst_palette, p->private_impl.dst_pixfmt,
+            (((uint32_t)a) << 24) | (((uint32_t)v[2]) << 16) |
+                (((uint32_t)v[1]) << 8) | (((uint32_t)v[0]) << 0));
+        dst.ptr[i] = index;
+        const uint8_t* c = dst_palette.ptr + (4 * ((size_t)index));
+        for (k = 0; k < 3; k++) {
+          q[k] = (int32_t)(c[k]);
+          if (nonpremul) {
+            q[k] = ((q[k] * ((int32_t)(c[3]))) + 127) / 255;
+          }
+        }
+      } else {
+        uint32_t b5 = ((((uint32_t)v[0]) * 31) + 127) / 255;
+        uint32_t g6 = ((((uint32_t)v[1]) * 63) + 127) / 255;
+        uint32_t r5 = ((((uint32_t)v[2]) * 31) + 127) / 255;
+        wuffs_base__poke_u16le__no_bounds_check(
+            dst.ptr + (2 * i), (uint16_t)((r5 << 11) | (g6 << 5) | b5));
+        q[0] = (int32_t)((b5 << 3) | (b5 >> 2));
+        q[1] = (int32_t)((g6 << 2) | (g6 >> 4));
+        q[2] = (int32_t)((r5 << 3) | (r5 >> 2));
+      }
+
+      // Diffuse the quantization error, in sixteenths: 7 to the right, and 3,
+      // 5 and 1 to the next row's left, center and right.
+      if (e) {
+        for (k = 0; k < 3; k++) {
+          int32_t err = v[k] - q[k];
+          if (d->private_impl.x > 0) {
+            wuffs_base__poke_u16le__no_bounds_check(
+                e - 6 + (2 * k),
+                (uint16_t)(0xFFFF & (uint32_t)(d->private_impl.pending0[k] +
+                                               (3 * err))));
+          }
+          d->private_impl.pending0[k] = d->private_impl.pending1[k] + (5 * err);
+          d->private_impl.pending1[k] = err;
+          d->private_impl.carry[k] = 7 * err;
+        }
+      }
+
+      if (d) {
+        d->private_impl.x++;
+        if (d->private_impl.x >= d->private_impl.width) {
+          if (e) {
+            for (k = 0; k < 3; k++) {
+              wuffs_base__poke_u16le__no_bounds_check(
+                  e + (2 * k),
+                  (uint16_t)(0xFFFF &
+                             (uint32_t)(d->private_impl.pending0[k])));
+              d->private_impl.carry[k] = 0;
+              d->private_impl.pending0[k] = 0;
+              d->private_impl.pending1[k] = 0;
+            }
+          }
+          d->private_impl.x = 0;
+          d->private_impl.y++;
+        }
+      }
+    }
+
+    dst.ptr += n * dst_bpp;
+    dst.len -= n * dst_bpp;
+    src_ptr += n * src_bpp;
+    src_len -= n * src_bpp;
+    num_pixels += n;
+  }
+  return num_pixels;
+}
+
+// wuffs_base__private_implementation__pixel_swizzler__apply_color_transform
+// applies p's color transform (if any) to the first num_pixels pixels of dst.
+static inline void  //
+wuffs_base__private_implementation__pixel_swizzler__apply_color_transform(
+    const wuffs_base__pixel_swizzler* p,
+    wuffs_base__slice_u8 dst,
+    uint64_t num_pixels) {
+  if (p->private_impl.color_transform) {
+    uint64_t n = num_pixels * p->private_impl.dst_pixfmt_bytes_per_pixel;
+    if (n < dst.len) {
+      dst.len = (size_t)n;
+    }
+    wuffs_base__color_transform__apply(p->private_impl.color_transform, dst,
+                                       p->private_impl.dst_pixfmt);
+  }
+}
+
+// wuffs_base__private_implementation__pixel_swizzler__call_func calls p's
+// func or, when quantizing or dithering, p's quantize_func.
+static inline uint64_t  //
+wuffs_base__private_implementation__pixel_swizzler__call_func(
+    const wuffs_base__pixel_swizzler* p,
+    wuffs_base__slice_u8 dst,
+    wuffs_base__slice_u8 dst_palette,
+    const uint8_t* src_ptr,
+    size_t src_len) {
+  if (p->private_impl.quantize_func &&
+      (p->private_impl.ditherer || !p->private_impl.func)) {
+    return wuffs_base__private_implementation__pixel_swizzler__quantize(
+        p, dst, dst_palette, src_ptr, src_len);
+  }
+  return (*p->private_impl.func)(dst.ptr, dst.len, dst_palette.ptr,
+                                 dst_palette.len, src_ptr, src_len);
 }
 
 WUFFS_BASE__MAYBE_STATIC uint64_t  //
@@ -5184,16 +7018,18 @@ wuffs_base__pixel_swizzler__limited_swizzle_u32_interleaved_from_reader(
     wuffs_base__slice_u8 dst_palette,
     const uint8_t** ptr_iop_r,
     const uint8_t* io2_r) {
-  if (p && p->private_impl.func) {
+  if (p && (p->private_impl.func || p->private_impl.quantize_func)) {
     const uint8_t* iop_r = *ptr_iop_r;
     uint64_t src_len = wuffs_base__u64__min(
         ((uint64_t)up_to_num_pixels) *
             ((uint64_t)p->private_impl.src_pixfmt_bytes_per_pixel),
         ((uint64_t)(io2_r - iop_r)));
     uint64_t n =
-        (*p->private_impl.func)(dst.ptr, dst.len, dst_palette.ptr,
-                                dst_palette.len, iop_r, (size_t)src_len);
+        wuffs_base__private_implementation__pixel_swizzler__call_func(
+            p, dst, dst_palette, iop_r, (size_t)src_len);
     *ptr_iop_r += n * p->private_impl.src_pixfmt_bytes_per_pixel;
+    wuffs_base__private_implementation__pixel_swizzler__apply_color_transform(
+        p, dst, n);
     return n;
   }
   return 0;
@@ -5206,13 +7042,15 @@ wuffs_base__pixel_swizzler__swizzle_interleaved_from_reader(
     wuffs_base__slice_u8 dst_palette,
     const uint8_t** ptr_iop_r,
     const uint8_t* io2_r) {
-  if (p && p->private_impl.func) {
+  if (p && (p->private_impl.func || p->private_impl.quantize_func)) {
     const uint8_t* iop_r = *ptr_iop_r;
     uint64_t src_len = ((uint64_t)(io2_r - iop_r));
     uint64_t n =
-        (*p->private_impl.func)(dst.ptr, dst.len, dst_palette.ptr,
-                                dst_palette.len, iop_r, (size_t)src_len);
+        wuffs_base__private_implementation__pixel_swizzler__call_func(
+            p, dst, dst_palette, iop_r, (size_t)src_len);
     *ptr_iop_r += n * p->private_impl.src_pixfmt_bytes_per_pixel;
+    wuffs_base__private_implementation__pixel_swizzler__apply_color_transform(
+        p, dst, n);
     return n;
   }
   return 0;
@@ -5224,9 +7062,12 @@ wuffs_base__pixel_swizzler__swizzle_interleaved_from_slice(
     wuffs_base__slice_u8 dst,
     wuffs_base__slice_u8 dst_palette,
     wuffs_base__slice_u8 src) {
-  if (p && p->private_impl.func) {
-    return (*p->private_impl.func)(dst.ptr, dst.len, dst_palette.ptr,
-                                   dst_palette.len, src.ptr, src.len);
+  if (p && (p->private_impl.func || p->private_impl.quantize_func)) {
+    uint64_t n = wuffs_base__private_implementation__pixel_swizzler__call_func(
+        p, dst, dst_palette, src.ptr, src.len);
+    wuffs_base__private_implementation__pixel_swizzler__apply_color_transform(
+        p, dst, n);
+    return n;
   }
   return 0;
 }
@@ -5244,3 +7085,145 @@ wuffs_base__pixel_swizzler__swizzle_interleaved_transparent_black(
   }
   return 0;
 }
+
+// --------
+
+// wuffs_base__private_implementation__pixel_swizzler__ycbcr_sample returns
+// the plane's 8-bit sample for the pixel at (x, y), where the bias_etc and
+// denominator_etc arguments come from the wuffs_base__pixel_subsampling. When
+// triangle is true, 2:1 subsampled axes blend the nearest sample with its
+// neighbor, clamping at the plane's edges.
+static inline uint32_t  //
+wuffs_base__private_implementation__pixel_swizzler__ycbcr_sample(
+    const wuffs_base__table_u8* t,
+    uint32_t x,
+    uint32_t y,
+    uint32_t bias_x,
+    uint32_t denominator_x,
+    uint32_t bias_y,
+    uint32_t denominator_y,
+    bool triangle) {
+  size_t xx = ((size_t)x) + bias_x;
+  size_t yy = ((size_t)y) + bias_y;
+  size_t i0 = xx / denominator_x;
+  size_t j0 = yy / denominator_y;
+  if (!triangle || ((denominator_x != 2) && (denominator_y != 2))) {
+    return t->ptr[(j0 * t->stride) + i0];
+  }
+
+  // (i1, j1) is the neighbor on the far side of (xx, yy) from (i0, j0)'s
+  // center. When an axis isn't 2:1 subsampled, i1 == i0 or j1 == j0.
+  size_t i1 = i0;
+  if (denominator_x == 2) {
+    if (xx & 1) {
+      i1 = ((i0 + 1) < t->width) ? (i0 + 1) : i0;
+    } else {
+      i1 = (i0 > 0) ? (i0 - 1) : i0;
+    }
+  }
+  size_t j1 = j0;
+  if (denominator_y == 2) {
+    if (yy & 1) {
+      j1 = ((j0 + 1) < t->height) ? (j0 + 1) : j0;
+    } else {
+      j1 = (j0 > 0) ? (j0 - 1) : j0;
+    }
+  }
+
+  const uint8_t* row0 = t->ptr + (j0 * t->stride);
+  const uint8_t* row1 = t->ptr + (j1 * t->stride);
+  uint32_t h0 = (3 * ((uint32_t)row0[i0])) + ((uint32_t)row0[i1]);
+  uint32_t h1 = (3 * ((uint32_t)row1[i0])) + ((uint32_t)row1[i1]);
+  return ((3 * h0) + h1 + 8) >> 4;
+}
+
+WUFFS_BASE__MAYBE_STATIC wuffs_base__status  //
+wuffs_base__pixel_swizzler__swizzle_ycbcr(
+    const wuffs_base__pixel_swizzler* p,
+    wuffs_base__pixel_buffer* dst,
+    wuffs_base__slice_u8 dst_palette,
+    const wuffs_base__pixel_buffer* src,
+    wuffs_base__pixel_chroma_upsampling upsampling) {
+  if (!p) {
+    return wuffs_base__make_status(wuffs_base__error__bad_receiver);
+  } else if (!dst || !src ||
+             (upsampling > WUFFS_BASE__PIXEL_CHROMA_UPSAMPLING__TRIANGLE)) {
+    return wuffs_base__make_status(wuffs_base__error__bad_argument);
+  } else if (!p->private_impl.bgrx_func ||
+             (src->pixcfg.private_impl.pixfmt.repr !=
+              WUFFS_BASE__PIXEL_FORMAT__YCBCR) ||
+             (dst->pixcfg.private_impl.pixfmt.repr !=
+              p->private_impl.dst_pixfmt.repr)) {
+    return wuffs_base__make_status(
+        wuffs_base__error__unsupported_pixel_swizzler_option);
+  }
+
+  uint32_t width = wuffs_base__u32__min(dst->pixcfg.private_impl.width,
+                                        src->pixcfg.private_impl.width);
+  uint32_t height = wuffs_base__u32__min(dst->pixcfg.private_impl.height,
+                                         src->pixcfg.private_impl.height);
+
+  // Check that the src planes are large enough, so that the
+  // ycbcr_sample calls below stay in bounds.
+  const wuffs_base__pixel_subsampling* pixsub =
+      &src->pixcfg.private_impl.pixsub;
+  uint32_t bias_xs[3];
+  uint32_t denominator_xs[3];
+  uint32_t bias_ys[3];
+  uint32_t denominator_ys[3];
+  uint32_t q;
+  for (q = 0; q < 3; q++) {
+    const wuffs_base__table_u8* t = &src->private_impl.planes[q];
+    if ((t->width <
+         wuffs_base__pixel_subsampling__num_samples_x(pixsub, q, width)) ||
+        (t->height <
+         wuffs_base__pixel_subsampling__num_samples_y(pixsub, q, height))) {
+      return wuffs_base__make_status(wuffs_base__error__bad_argument);
+    }
+    bias_xs[q] = wuffs_base__pixel_subsampling__bias_x(pixsub, q);
+    denominator_xs[q] = wuffs_base__pixel_subsampling__denominator_x(pixsub, q);
+    bias_ys[q] = wuffs_base__pixel_subsampling__bias_y(pixsub, q);
+    denominator_ys[q] = wuffs_base__pixel_subsampling__denominator_y(pixsub, q);
+  }
+  bool triangle = upsampling == WUFFS_BASE__PIXEL_CHROMA_UPSAMPLING__TRIANGLE;
+
+  const wuffs_base__table_u8* dst_tab = &dst->private_impl.planes[0];
+  size_t dst_bpp = p->private_impl.dst_pixfmt_bytes_per_pixel;
+  if ((width > 0) && ((dst_tab->width / dst_bpp) < width)) {
+    return wuffs_base__make_status(wuffs_base__error__bad_argument);
+  }
+
+  // Convert up to 256 pixels at a time to an intermediate row of BGRX.
+  uint8_t bgrx[4 * 256];
+  uint32_t y;
+  for (y = 0; y < height; y++) {
+    uint8_t* dst_row = dst_tab->ptr + (y * dst_tab->stride);
+    uint32_t x0;
+    for (x0 = 0; x0 < width; x0 += 256) {
+      uint32_t n = wuffs_base__u32__min(width - x0, 256);
+      uint32_t i;
+      for (i = 0; i < n; i++) {
+        uint32_t x = x0 + i;
+        uint32_t s[3];
+        for (q = 0; q < 3; q++) {
+          // The luma plane (q == 0) always uses NEAREST.
+          s[q] =
+              wuffs_base__private_implementation__pixel_swizzler__ycbcr_sample(
+                  &src->private_impl.planes[q], x, y, bias_xs[q],
+                  denominator_xs[q], bias_ys[q], denominator_ys[q],
+                  triangle && (q > 0));
+        }
+        wuffs_base__poke_u32le__no_bounds_check(
+            &bgrx[4 * i], wuffs_base__color_ycc__as__color_u32(
+                              (uint8_t)s[0], (uint8_t)s[1], (uint8_t)s[2]));
+      }
+      wuffs_base__slice_u8 d = wuffs_base__make_slice_u8(
+          dst_row + (x0 * dst_bpp), (size_t)(n * dst_bpp));
+      (*p->private_impl.bgrx_func)(d.ptr, d.len, dst_palette.ptr,
+                                   dst_palette.len, &bgrx[0], 4 * n);
+      wuffs_base__private_implementation__pixel_swizzler__apply_color_transform(
+          p, d, n);
+    }
+  }
+  return wuffs_base__make_status(NULL);
+}
@@ -36,7 +36,7 @@ typedef struct wuffs_base__range_ii_u32__struct {
 } wuffs_base__range_ii_u32;
 
 static inline wuffs_base__range_ii_u32  //
-wuffs_base__empty_range_ii_u32() {
+wuffs_base__empty_range_ii_u32(void) {
   wuffs_base__range_ii_u32 ret;
   ret.min_incl = 0;
   ret.max_incl = 0;
@@ -156,7 +156,7 @@ typedef struct wuffs_base__range_ie_u32__struct {
 } wuffs_base__range_ie_u32;
 
 static inline wuffs_base__range_ie_u32  //
-wuffs_base__empty_range_ie_u32() {
+wuffs_base__empty_range_ie_u32(void) {
   wuffs_base__range_ie_u32 ret;
   ret.min_incl = 0;
   ret.max_excl = 0;
@@ -285,7 +285,7 @@ typedef struct wuffs_base__range_ii_u64__struct {
 } wuffs_base__range_ii_u64;
 
 static inline wuffs_base__range_ii_u64  //
-wuffs_base__empty_range_ii_u64() {
+wuffs_base__empty_range_ii_u64(void) {
   wuffs_base__range_ii_u64 ret;
   ret.min_incl = 0;
   ret.max_incl = 0;
@@ -405,7 +405,7 @@ typedef struct wuffs_base__range_ie_u64__struct {
 } wuffs_base__range_ie_u64;
 
 static inline wuffs_base__range_ie_u64  //
-wuffs_base__empty_range_ie_u64() {
+wuffs_base__empty_range_ie_u64(void) {
   wuffs_base__range_ie_u64 ret;
   ret.min_incl = 0;
   ret.max_excl = 0;
@@ -536,7 +536,7 @@ typedef struct wuffs_base__rect_ii_u32__struct {
 } wuffs_base__rect_ii_u32;
 
 static inline wuffs_base__rect_ii_u32  //
-wuffs_base__empty_rect_ii_u32() {
+wuffs_base__empty_rect_ii_u32(void) {
   wuffs_base__rect_ii_u32 ret;
   ret.min_incl_x = 0;
   ret.min_incl_y = 0;
@@ -673,7 +673,7 @@ typedef struct wuffs_base__rect_ie_u32__struct {
 } wuffs_base__rect_ie_u32;
 
 static inline wuffs_base__rect_ie_u32  //
-wuffs_base__empty_rect_ie_u32() {
+wuffs_base__empty_rect_ie_u32(void) {
   wuffs_base__rect_ie_u32 ret;
   ret.min_incl_x = 0;
   ret.min_incl_y = 0;
@@ -832,6 +832,7 @@ typedef struct wuffs_base__more_information__struct {
   inline uint64_t io_seek__position() const;
   inline uint32_t metadata__fourcc() const;
   inline wuffs_base__range_ie_u64 metadata__range() const;
+  inline uint32_t metadata_parsed__orientation() const;
 #endif  // __cplusplus
 
 } wuffs_base__more_information;
@@ -839,9 +840,18 @@ typedef struct wuffs_base__more_information__struct {
 #define WUFFS_BASE__MORE_INFORMATION__FLAVOR__IO_REDIRECT 1
 #define WUFFS_BASE__MORE_INFORMATION__FLAVOR__IO_SEEK 2
 #define WUFFS_BASE__MORE_INFORMATION__FLAVOR__METADATA 3
+#define WUFFS_BASE__MORE_INFORMATION__FLAVOR__METADATA_PARSED 4
+#define WUFFS_BASE__MORE_INFORMATION__FLAVOR__METADATA_RAW_TRANSFORM 5
+
+// The METADATA flavor's payload is a range of the source stream, for the
+// caller to consume. The METADATA_RAW_TRANSFORM flavor's payload is instead
+// written to the tell_me_more method's dst argument, after the decoder has
+// transformed it (e.g. converting its character encoding). Its range is
+// empty. The caller should drain dst and call tell_me_more again while that
+// returns "$short write".
 
 static inline wuffs_base__more_information  //
-wuffs_base__empty_more_information() {
+wuffs_base__empty_more_information(void) {
   wuffs_base__more_information ret;
   ret.flavor = 0;
   ret.w = 0;
@@ -904,6 +914,16 @@ wuffs_base__more_information__metadata__range(
   return ret;
 }
 
+// wuffs_base__more_information__metadata_parsed__orientation returns the
+// WUFFS_BASE__ORIENTATION__ETC value of METADATA_PARSED information whose
+// metadata__fourcc is WUFFS_BASE__FOURCC__ORNT. Unlike METADATA information,
+// METADATA_PARSED information has no payload for the caller to consume.
+static inline uint32_t  //
+wuffs_base__more_information__metadata_parsed__orientation(
+    const wuffs_base__more_information* m) {
+  return (uint32_t)(m->x);
+}
+
 #ifdef __cplusplus
 
 inline void  //
@@ -941,4 +961,9 @@ wuffs_base__more_information::metadata__range() const {
   return wuffs_base__more_information__metadata__range(this);
 }
 
+inline uint32_t  //
+wuffs_base__more_information::metadata_parsed__orientation() const {
+  return wuffs_base__more_information__metadata_parsed__orientation(this);
+}
+
 #endif  // __cplusplus
@@ -57,6 +57,7 @@ wuffs_base__make_token(uint64_t repr) {
 #define WUFFS_BASE__TOKEN__LENGTH__SHIFT 0
 
 #define WUFFS_BASE__TOKEN__VALUE_EXTENSION__NUM_BITS 46
+#define WUFFS_BASE__TOKEN__VALUE_EXTENSION__MASK 0x3FFFFFFFFFFF
 
 // --------
 
@@ -68,6 +69,7 @@ wuffs_base__make_token(uint64_t repr) {
 #define WUFFS_BASE__TOKEN__VBC__NUMBER 5
 #define WUFFS_BASE__TOKEN__VBC__INLINE_INTEGER_SIGNED 6
 #define WUFFS_BASE__TOKEN__VBC__INLINE_INTEGER_UNSIGNED 7
+#define WUFFS_BASE__TOKEN__VBC__COMMENT 8
 
 // --------
 
@@ -80,6 +82,17 @@ wuffs_base__make_token(uint64_t repr) {
 
 // --------
 
+// VBC__COMMENT tokens are only produced when a decoder is explicitly asked
+// to (e.g. by WUFFS_JSON__QUIRK_EMIT_COMMENT_TOKENS). Otherwise, comments are
+// VBC__FILLER tokens. The COMMENT VBD bits match the FILLER__COMMENT_ETC bits.
+#define WUFFS_BASE__TOKEN__VBD__COMMENT__BLOCK 0x00002
+#define WUFFS_BASE__TOKEN__VBD__COMMENT__LINE 0x00004
+
+// COMMENT__ANY is a bit-wise or of COMMENT__BLOCK AND COMMENT__LINE.
+#define WUFFS_BASE__TOKEN__VBD__COMMENT__ANY 0x00006
+
+// --------
+
 #define WUFFS_BASE__TOKEN__VBD__STRUCTURE__PUSH 0x00001
 #define WUFFS_BASE__TOKEN__VBD__STRUCTURE__POP 0x00002
 #define WUFFS_BASE__TOKEN__VBD__STRUCTURE__FROM_NONE 0x00010
@@ -232,6 +245,25 @@ wuffs_base__token__length(const wuffs_base__token* t) {
   return (t->repr >> WUFFS_BASE__TOKEN__LENGTH__SHIFT) & 0xFFFF;
 }
 
+// wuffs_base__token__joined_u64 returns the 64-bit value carried by a pair of
+// tokens: a simple token (whose continued bit is set) followed by an extended
+// token. The high 18 bits come from the low 18 bits of the first token's
+// value_minor and the low 46 bits come from the second token's
+// value_extension. Such pairs are produced by the Wuffs
+// token_writer.write_u64_token_pair_fast method, but older decoders also
+// wrote the same layout by hand.
+//
+// For VBC__INLINE_INTEGER_SIGNED, converting the result to int64_t gives the
+// (two's complement) signed value.
+static inline uint64_t  //
+wuffs_base__token__joined_u64(const wuffs_base__token* first,
+                              const wuffs_base__token* second) {
+  return (((first->repr >> WUFFS_BASE__TOKEN__VALUE_MINOR__SHIFT) & 0x3FFFF)
+          << WUFFS_BASE__TOKEN__VALUE_EXTENSION__NUM_BITS) |
+         ((~second->repr >> WUFFS_BASE__TOKEN__VALUE_EXTENSION__SHIFT) &
+          WUFFS_BASE__TOKEN__VALUE_EXTENSION__MASK);
+}
+
 #ifdef __cplusplus
 
 inline int64_t  //
@@ -294,7 +326,7 @@ wuffs_base__make_slice_token(wuffs_base__token* ptr, size_t len) {
 }
 
 static inline wuffs_base__slice_token  //
-wuffs_base__empty_slice_token() {
+wuffs_base__empty_slice_token(void) {
   wuffs_base__slice_token ret;
   ret.ptr = NULL;
   ret.len = 0;
@@ -382,7 +414,7 @@ wuffs_base__slice_token__writer(wuffs_base__slice_token s) {
 }
 
 static inline wuffs_base__token_buffer  //
-wuffs_base__empty_token_buffer() {
+wuffs_base__empty_token_buffer(void) {
   wuffs_base__token_buffer ret;
   ret.data.ptr = NULL;
   ret.data.len = 0;
@@ -394,7 +426,7 @@ wuffs_base__empty_token_buffer() {
 }
 
 static inline wuffs_base__token_buffer_meta  //
-wuffs_base__empty_token_buffer_meta() {
+wuffs_base__empty_token_buffer_meta(void) {
   wuffs_base__token_buffer_meta ret;
   ret.wi = 0;
   ret.ri = 0;
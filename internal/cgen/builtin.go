@@ -95,10 +95,24 @@ func (g *gen) writeBuiltinCall(b *buffer, n *a.Expr, sideEffectsOnly bool, depth
 
 	if qid[1].IsNumType() {
 		return g.writeBuiltinNumType(b, recv, method.Ident(), n.Args(), depth)
+	} else if qid[1].IsFloatType() {
+		return g.writeBuiltinFloatType(b, recv, qid[1], method.Ident(), n.Args(), depth)
 	} else if qid[1].IsBuiltInCPUArch() {
 		return g.writeBuiltinCPUArch(b, recv, method.Ident(), n.Args(), sideEffectsOnly, depth)
 	} else {
 		switch qid[1] {
+		case t.IDStatus:
+			if method.Ident() != t.IDWithPayload {
+				return errNoSuchBuiltin
+			}
+			// The receiver may be a status literal (not an lvalue), so pass
+			// its repr instead of its address.
+			b.writes("wuffs_base__make_status_with_payload(")
+			if err := g.writeExprRepr(b, recv, depth); err != nil {
+				return err
+			}
+			b.writes(", ")
+			return g.writeArgs(b, n.Args(), depth)
 		case t.IDIOReader:
 			return g.writeBuiltinIOReader(b, recv, method.Ident(), n.Args(), sideEffectsOnly, depth)
 		case t.IDIOWriter:
@@ -211,9 +225,9 @@ func (g *gen) writeBuiltinIOReader(b *buffer, recv *a.Expr, method t.ID, args []
 		b.printf("(%s%s > %s%s)", iopPrefix, recvName, io1Prefix, recvName)
 		return nil
 
-	case t.IDLimitedCopyU32ToSlice:
-		b.printf("wuffs_base__io_reader__limited_copy_u32_to_slice(\n&%s%s, %s%s,",
-			iopPrefix, recvName, io2Prefix, recvName)
+	case t.IDLimitedCopyU32ToSlice, t.IDLimitedCopyU64ToSlice:
+		b.printf("wuffs_base__io_reader__%s(\n&%s%s, %s%s,",
+			method.Str(g.tm), iopPrefix, recvName, io2Prefix, recvName)
 		return g.writeArgs(b, args, depth)
 
 	case t.IDCountSince:
@@ -310,7 +324,8 @@ func (g *gen) writeBuiltinIOWriter(b *buffer, recv *a.Expr, method t.ID, args []
 	case t.IDLimitedCopyU32FromHistory,
 		t.IDLimitedCopyU32FromHistory8ByteChunksDistance1Fast,
 		t.IDLimitedCopyU32FromHistory8ByteChunksFast,
-		t.IDLimitedCopyU32FromHistoryFast:
+		t.IDLimitedCopyU32FromHistoryFast,
+		t.IDLimitedCopyU64FromHistory:
 		b.printf("wuffs_base__io_writer__%s(\n&%s%s, %s%s, %s%s",
 			method.Str(g.tm), iopPrefix, recvName, io0Prefix, recvName, io2Prefix, recvName)
 		for _, o := range args {
@@ -322,14 +337,14 @@ func (g *gen) writeBuiltinIOWriter(b *buffer, recv *a.Expr, method t.ID, args []
 		b.writeb(')')
 		return nil
 
-	case t.IDLimitedCopyU32FromReader:
+	case t.IDLimitedCopyU32FromReader, t.IDLimitedCopyU64FromReader:
 		readerName, err := g.recvName(args[1].AsArg().Value())
 		if err != nil {
 			return err
 		}
 
-		b.printf("wuffs_base__io_writer__limited_copy_u32_from_reader(\n&%s%s, %s%s,",
-			iopPrefix, recvName, io2Prefix, recvName)
+		b.printf("wuffs_base__io_writer__%s(\n&%s%s, %s%s,",
+			method.Str(g.tm), iopPrefix, recvName, io2Prefix, recvName)
 		if err := g.writeExpr(b, args[0].AsArg().Value(), false, depth); err != nil {
 			return err
 		}
@@ -341,9 +356,9 @@ func (g *gen) writeBuiltinIOWriter(b *buffer, recv *a.Expr, method t.ID, args []
 			iopPrefix, recvName, io2Prefix, recvName)
 		return g.writeArgs(b, args, depth)
 
-	case t.IDLimitedCopyU32FromSlice:
-		b.printf("wuffs_base__io_writer__limited_copy_u32_from_slice(\n&%s%s, %s%s,",
-			iopPrefix, recvName, io2Prefix, recvName)
+	case t.IDLimitedCopyU32FromSlice, t.IDLimitedCopyU64FromSlice:
+		b.printf("wuffs_base__io_writer__%s(\n&%s%s, %s%s,",
+			method.Str(g.tm), iopPrefix, recvName, io2Prefix, recvName)
 		return g.writeArgs(b, args, depth)
 
 	case t.IDCountSince:
@@ -441,6 +456,49 @@ func (g *gen) writeBuiltinTokenWriter(b *buffer, recv *a.Expr, method t.ID, args
 		}
 		b.writes(")) << WUFFS_BASE__TOKEN__LENGTH__SHIFT))")
 		return nil
+
+	case t.IDWriteU64TokenPairFast:
+		recvName, err := g.recvName(recv)
+		if err != nil {
+			return err
+		}
+		value := args[2].AsArg().Value()
+
+		b.printf("*iop_%s++ = wuffs_base__make_token(\n", recvName)
+		b.writes("(((uint64_t)(")
+		if cv := args[0].AsArg().Value().ConstValue(); (cv == nil) || (cv.Sign() != 0) {
+			if err := g.writeExpr(b, args[0].AsArg().Value(), false, depth); err != nil {
+				return err
+			}
+			b.writes(")) << WUFFS_BASE__TOKEN__VALUE_MAJOR__SHIFT) |\n(((uint64_t)(")
+		}
+		if err := g.writeExpr(b, args[1].AsArg().Value(), false, depth); err != nil {
+			return err
+		}
+		b.writes(") | (((uint64_t)(")
+		if err := g.writeExpr(b, value, false, depth); err != nil {
+			return err
+		}
+		b.writes(")) >> WUFFS_BASE__TOKEN__VALUE_EXTENSION__NUM_BITS)) << WUFFS_BASE__TOKEN__VALUE_MINOR__SHIFT) |\n")
+		b.writes("(((uint64_t)(1)) << WUFFS_BASE__TOKEN__CONTINUED__SHIFT)),\n")
+
+		b.printf("*iop_%s++ = wuffs_base__make_token(\n", recvName)
+		b.writes("(~(((uint64_t)(")
+		if err := g.writeExpr(b, value, false, depth); err != nil {
+			return err
+		}
+		b.writes(")) & WUFFS_BASE__TOKEN__VALUE_EXTENSION__MASK) << WUFFS_BASE__TOKEN__VALUE_EXTENSION__SHIFT) |\n(((uint64_t)(")
+		if cv := args[3].AsArg().Value().ConstValue(); (cv == nil) || (cv.Sign() != 0) {
+			if err := g.writeExpr(b, args[3].AsArg().Value(), false, depth); err != nil {
+				return err
+			}
+			b.writes(")) << WUFFS_BASE__TOKEN__CONTINUED__SHIFT) |\n(((uint64_t)(")
+		}
+		if err := g.writeExpr(b, args[4].AsArg().Value(), false, depth); err != nil {
+			return err
+		}
+		b.writes(")) << WUFFS_BASE__TOKEN__LENGTH__SHIFT))")
+		return nil
 	}
 
 	return g.writeBuiltinIO(b, recv, method, args, depth)
@@ -454,6 +512,10 @@ func (g *gen) writeBuiltinCPUArch(b *buffer, recv *a.Expr, method t.ID, args []*
 		return g.writeBuiltinCPUArchARMNeon(b, recv, method, args, sideEffectsOnly, depth)
 	case id == t.IDX86SSE42Utility, id == t.IDX86M128I:
 		return g.writeBuiltinCPUArchX86(b, recv, method, args, sideEffectsOnly, depth)
+	case id == t.IDRISCVRVVUtility, riscvRVVZeroes[id] != "":
+		return g.writeBuiltinCPUArchRISCVRVV(b, recv, method, args, sideEffectsOnly, depth)
+	case id == t.IDWASMSIMD128Utility, id == t.IDWASMV128:
+		return g.writeBuiltinCPUArchWASMSIMD128(b, recv, method, args, sideEffectsOnly, depth)
 	}
 	return fmt.Errorf("internal error: unsupported cpu_arch method %s.%s",
 		recv.MType().Str(g.tm), method.Str(g.tm))
@@ -728,6 +790,204 @@ func (g *gen) writeBuiltinCPUArchX86(b *buffer, recv *a.Expr, method t.ID, args
 	return nil
 }
 
+// riscvRVVZeroes maps each RISC-V Vector type to a C expression for an
+// all-zeroes value of that type.
+var riscvRVVZeroes = map[t.ID]string{
+	t.IDRISCVRVVU8M1:  "__riscv_vmv_v_x_u8m1(0, __riscv_vsetvlmax_e8m1())",
+	t.IDRISCVRVVU16M2: "__riscv_vmv_v_x_u16m2(0, __riscv_vsetvlmax_e16m2())",
+	t.IDRISCVRVVU32M1: "__riscv_vmv_v_x_u32m1(0, __riscv_vsetvlmax_e32m1())",
+	t.IDRISCVRVVU32M4: "__riscv_vmv_v_x_u32m4(0, __riscv_vsetvlmax_e32m4())",
+}
+
+func (g *gen) writeBuiltinCPUArchRISCVRVV(b *buffer, recv *a.Expr, method t.ID, args []*a.Node, sideEffectsOnly bool, depth uint32) error {
+	methodStr := method.Str(g.tm)
+	if strings.HasPrefix(methodStr, "vsetvl") {
+		// The C intrinsics work in size_t, Wuffs works in base.u64.
+		b.printf("((uint64_t)(__riscv_%s(", methodStr)
+		if len(args) > 0 {
+			b.writes("(size_t)(")
+			if err := g.writeExpr(b, args[0].AsArg().Value(), false, depth); err != nil {
+				return err
+			}
+			b.writes(")")
+		}
+		b.writes(")))")
+		return nil
+
+	} else if strings.HasPrefix(methodStr, "make_") {
+		ptr := false
+		switch methodStr {
+		case "make_u16m2_index":
+			b.writes("__riscv_vid_v_u16m2(")
+		case "make_u8m1_repeat":
+			b.writes("__riscv_vmv_v_x_u8m1(")
+		case "make_u16m2_repeat":
+			b.writes("__riscv_vmv_v_x_u16m2(")
+		case "make_u32m1_repeat":
+			b.writes("__riscv_vmv_v_x_u32m1(")
+		case "make_u32m4_repeat":
+			b.writes("__riscv_vmv_v_x_u32m4(")
+		case "make_u8m1_slice_vl":
+			b.writes("__riscv_vle8_v_u8m1(")
+			ptr = true
+		default:
+			return fmt.Errorf("internal error: unsupported cpu_arch method %q", methodStr)
+		}
+		for i, o := range args {
+			if i > 0 {
+				b.writes(", ")
+			}
+			if ptr && (i == 0) {
+				if err := g.writeExprDotPtr(b, o.AsArg().Value(), false, depth); err != nil {
+					return err
+				}
+			} else {
+				if err := g.writeExpr(b, o.AsArg().Value(), false, depth); err != nil {
+					return err
+				}
+			}
+		}
+		b.writes(")")
+		return nil
+
+	} else if methodStr == "store_slice_vl" {
+		if !sideEffectsOnly {
+			// As per writeBuiltinCPUArchX86's "store_etc" methods.
+			b.writes("(")
+		}
+		b.writes("__riscv_vse8_v_u8m1(")
+		if err := g.writeExprDotPtr(b, args[0].AsArg().Value(), false, depth); err != nil {
+			return err
+		}
+		b.writes(", ")
+		if err := g.writeExpr(b, recv, false, depth); err != nil {
+			return err
+		}
+		b.writes(", ")
+		if err := g.writeExpr(b, args[1].AsArg().Value(), false, depth); err != nil {
+			return err
+		}
+		b.writes(")")
+		if !sideEffectsOnly {
+			b.writes(", wuffs_base__make_empty_struct())")
+		}
+		return nil
+	}
+
+	b.writes("__riscv_")
+	b.writes(methodStr)
+	b.writes("(")
+	if err := g.writeExpr(b, recv, false, depth); err != nil {
+		return err
+	}
+	for _, o := range args {
+		b.writes(", ")
+		if err := g.writeExpr(b, o.AsArg().Value(), false, depth); err != nil {
+			return err
+		}
+	}
+	b.writes(")")
+	return nil
+}
+
+func (g *gen) writeBuiltinCPUArchWASMSIMD128(b *buffer, recv *a.Expr, method t.ID, args []*a.Node, sideEffectsOnly bool, depth uint32) error {
+	methodStr := method.Str(g.tm)
+	if strings.HasPrefix(methodStr, "make_") {
+		fName, tName, ptr := "", "", false
+		switch methodStr {
+		case "make_v128_multiple_u8":
+			fName, tName = "wasm_u8x16_make", "uint8_t"
+		case "make_v128_multiple_u16":
+			fName, tName = "wasm_u16x8_make", "uint16_t"
+		case "make_v128_multiple_u32":
+			fName, tName = "wasm_u32x4_make", "uint32_t"
+		case "make_v128_multiple_u64":
+			fName, tName = "wasm_u64x2_make", "uint64_t"
+		case "make_v128_repeat_u8":
+			fName, tName = "wasm_u8x16_splat", "uint8_t"
+		case "make_v128_repeat_u16":
+			fName, tName = "wasm_u16x8_splat", "uint16_t"
+		case "make_v128_repeat_u32":
+			fName, tName = "wasm_u32x4_splat", "uint32_t"
+		case "make_v128_repeat_u64":
+			fName, tName = "wasm_u64x2_splat", "uint64_t"
+		case "make_v128_slice128":
+			fName, tName, ptr = "wasm_v128_load", "const void*", true
+		case "make_v128_zeroes":
+			b.writes("wasm_u64x2_splat(0)")
+			return nil
+		default:
+			return fmt.Errorf("internal error: unsupported cpu_arch method %q", methodStr)
+		}
+		b.printf("%s(", fName)
+		// Unlike _mm_setetc, the wasm_etc_make arg order is lane 0 first.
+		for i, o := range args {
+			if i > 0 {
+				b.writes(", ")
+			}
+			b.printf("(%s)(", tName)
+			if ptr {
+				if err := g.writeExprDotPtr(b, o.AsArg().Value(), false, depth); err != nil {
+					return err
+				}
+			} else {
+				if err := g.writeExpr(b, o.AsArg().Value(), false, depth); err != nil {
+					return err
+				}
+			}
+			b.writes(")")
+		}
+		b.writes(")")
+		return nil
+
+	} else if methodStr == "store_slice128" {
+		if !sideEffectsOnly {
+			// As per writeBuiltinCPUArchX86's "store_etc" methods.
+			b.writes("(")
+		}
+		b.writes("wasm_v128_store((void*)(")
+		if err := g.writeExprDotPtr(b, args[0].AsArg().Value(), false, depth); err != nil {
+			return err
+		}
+		b.writes("), ")
+		if err := g.writeExpr(b, recv, false, depth); err != nil {
+			return err
+		}
+		b.writes(")")
+		if !sideEffectsOnly {
+			b.writes(", wuffs_base__make_empty_struct())")
+		}
+		return nil
+
+	} else if strings.HasPrefix(methodStr, "truncate_u") {
+		switch methodStr {
+		case "truncate_u32":
+			b.writes("wasm_u32x4_extract_lane(")
+		case "truncate_u64":
+			b.writes("wasm_u64x2_extract_lane(")
+		}
+		if err := g.writeExpr(b, recv, false, depth); err != nil {
+			return err
+		}
+		b.writes(", 0)")
+		return nil
+	}
+
+	b.writes(methodStr)
+	b.writes("(")
+	if err := g.writeExpr(b, recv, false, depth); err != nil {
+		return err
+	}
+	for _, o := range args {
+		b.writes(", ")
+		if err := g.writeExpr(b, o.AsArg().Value(), false, depth); err != nil {
+			return err
+		}
+	}
+	b.writes(")")
+	return nil
+}
+
 func (g *gen) writeExprDotPtr(b *buffer, n *a.Expr, sideEffectsOnly bool, depth uint32) error {
 	if arrayOrSlice, lo, _, ok := n.IsSlice(); ok {
 		if err := g.writeExpr(b, arrayOrSlice, sideEffectsOnly, depth); err != nil {
@@ -805,6 +1065,42 @@ func (g *gen) writeBuiltinNumType(b *buffer, recv *a.Expr, method t.ID, args []*
 		b.writes(")))")
 		return nil
 
+	case t.IDLeadingZeros, t.IDPopcount, t.IDTrailingZeros:
+		// "recv.popcount()" in C is "wuffs_base__count_ones_u64(recv)". The
+		// leading_zeros and trailing_zeros methods are similar, but adjusted
+		// (for receivers narrower than 64 bits) to count only recv's bits.
+		sz, err := g.sizeof(recv.MType())
+		if err != nil {
+			return err
+		}
+		nBits := 8 * sz
+		switch {
+		case method == t.IDPopcount:
+			b.writes("wuffs_base__count_ones_u64(")
+		case method == t.IDLeadingZeros:
+			if nBits == 64 {
+				b.writes("wuffs_base__count_leading_zeroes_u64(")
+			} else {
+				b.writes("(wuffs_base__count_leading_zeroes_u64(")
+			}
+		case nBits == 64:
+			b.writes("wuffs_base__count_trailing_zeroes_u64(")
+		default:
+			b.writes("wuffs_base__u32__min(wuffs_base__count_trailing_zeroes_u64(")
+		}
+		if err := g.writeExpr(b, recv, false, depth); err != nil {
+			return err
+		}
+		switch {
+		case (method == t.IDPopcount) || (nBits == 64):
+			b.writes(")")
+		case method == t.IDLeadingZeros:
+			b.printf(") - %du)", 64-nBits)
+		default:
+			b.printf("), %du)", nBits)
+		}
+		return nil
+
 	case t.IDMax:
 		b.writes("wuffs_base__u")
 		if sz, err := g.sizeof(recv.MType()); err != nil {
@@ -844,6 +1140,23 @@ func (g *gen) writeBuiltinNumType(b *buffer, recv *a.Expr, method t.ID, args []*
 	return errNoSuchBuiltin
 }
 
+func (g *gen) writeBuiltinFloatType(b *buffer, recv *a.Expr, typ t.ID, method t.ID, args []*a.Node, depth uint32) error {
+	// "recv.foo(args)" in C is "wuffs_base__f32__foo(recv, args)", and
+	// likewise for f64. The C functions are in base/fundamental-private.h.
+	b.printf("wuffs_base__%s__%s(", typ.Str(g.tm), method.Str(g.tm))
+	if err := g.writeExpr(b, recv, false, depth); err != nil {
+		return err
+	}
+	for _, o := range args {
+		b.writes(", ")
+		if err := g.writeExpr(b, o.AsArg().Value(), false, depth); err != nil {
+			return err
+		}
+	}
+	b.writes(")")
+	return nil
+}
+
 func (g *gen) writeBuiltinSlice(b *buffer, recv *a.Expr, method t.ID, args []*a.Node, sideEffectsOnly bool, depth uint32) error {
 	switch method {
 	case t.IDCopyFromSlice:
@@ -883,6 +1196,22 @@ func (g *gen) writeBuiltinSlice(b *buffer, recv *a.Expr, method t.ID, args []*a.
 		}
 		b.writes(", ")
 		return g.writeArgs(b, args, depth)
+
+	case t.IDCopyWithin, t.IDFill:
+		b.printf("wuffs_base__slice_u8__%s(", method.Str(g.tm))
+		if err := g.writeExpr(b, recv, false, depth); err != nil {
+			return err
+		}
+		b.writes(", ")
+		return g.writeArgs(b, args, depth)
+
+	case t.IDEqualFold, t.IDIndexOf, t.IDIndexOfFold:
+		b.printf("wuffs_base__slice_u8__%s(", method.Str(g.tm))
+		if err := g.writeExpr(b, recv, false, depth); err != nil {
+			return err
+		}
+		b.writes(", ")
+		return g.writeArgs(b, args, depth)
 	}
 
 	if (t.IDPeekU8 <= method) && (method <= t.IDPeekU64LE) {
@@ -1084,6 +1413,23 @@ func (g *gen) writeBuiltinQuestionCall(b *buffer, n *a.Expr, depth uint32) error
 			b.printf(" = *iop_%s++;\n", recvName)
 			return nil
 
+		case t.IDSeek:
+			b.writes("self->private_impl.seek_io_position = ")
+			if err := g.writeExpr(b, n.Args()[0].AsArg().Value(), false, depth); err != nil {
+				return err
+			}
+			b.writes(";\n")
+
+			if err := g.writeCoroSuspPoint(b, false); err != nil {
+				return err
+			}
+			b.printf("if (wuffs_base__u64__sat_add(%s->meta.pos, ((uint64_t)(%s%s - %s%s))) !=\n"+
+				"self->private_impl.seek_io_position) {\n"+
+				"status = wuffs_base__make_status(wuffs_base__suspension__mispositioned_read);\n"+
+				"goto suspend;\n}\n",
+				recvName, iopPrefix, recvName, io0Prefix, recvName)
+			return nil
+
 		case t.IDSkip, t.IDSkipU32:
 			x := n.Args()[0].AsArg().Value()
 			if cv := x.ConstValue(); cv != nil && cv.Cmp(one) == 0 {
@@ -1120,6 +1466,9 @@ func (g *gen) writeBuiltinQuestionCall(b *buffer, n *a.Expr, depth uint32) error
 			b.writes("status = wuffs_base__make_status(wuffs_base__suspension__short_read);\ngoto suspend;\n}\n")
 			b.printf("iop_%s += %s;\n", recvName, scratchName)
 			return nil
+
+		case t.IDReadUvarint64, t.IDReadSvarint64:
+			return g.writeReadVarint64(b, n, recvName, method.Ident() == t.IDReadSvarint64)
 		}
 
 		if method.Ident() >= readMethodsBase {
@@ -1157,12 +1506,115 @@ func (g *gen) writeBuiltinQuestionCall(b *buffer, n *a.Expr, depth uint32) error
 				"*iop_%s++ = ((uint8_t)(%s));\n",
 				recvName, recvName, recvName, scratchName)
 			return nil
+
+		case t.IDWriteUvarint64, t.IDWriteSvarint64:
+			return g.writeWriteVarint64(b, n, recvName, method.Ident() == t.IDWriteSvarint64, depth)
 		}
 
 	}
 	return errNoSuchBuiltin
 }
 
+// writeReadVarint64 writes the C code for read_uvarint64 and read_svarint64.
+// The fast path needs 10 readable bytes, the longest valid encoding. The slow
+// path consumes one byte at a time, keeping its partial state in the scratch
+// variable so that it can resume after a "$short read" suspension.
+func (g *gen) writeReadVarint64(b *buffer, n *a.Expr, recvName string, signed bool) error {
+	if g.currFunk.tempW > maxTemp {
+		return fmt.Errorf("too many temporary variables required")
+	}
+	temp := g.currFunk.tempW
+	g.currFunk.tempW++
+
+	if err := g.writeCTypeName(b, n.MType(), tPrefix, fmt.Sprint(temp)); err != nil {
+		return err
+	}
+	b.writes(";\n")
+
+	g.currFunk.usesScratch = true
+	// TODO: don't hard-code [0], and allow recursive coroutines.
+	scratchName := fmt.Sprintf("self->private_data.%s%s[0].scratch",
+		sPrefix, g.currFunk.astFunc.FuncName().Str(g.tm))
+
+	result := func(u string) string {
+		if signed {
+			return fmt.Sprintf("((int64_t)((%s >> 1) ^ (0 - (%s & 1))))", u, u)
+		}
+		return u
+	}
+	badData := func() {
+		b.writes("status = wuffs_base__make_status(wuffs_base__error__bad_data);\n")
+		g.writeTrace(b, "STATUS", "status.repr", "0", "0")
+		b.writes("goto exit;\n")
+	}
+
+	b.printf("if (WUFFS_BASE__LIKELY(io2_%s - iop_%s >= 10)) {\n", recvName, recvName)
+	b.printf("uint64_t varint_%d = 0;\n", temp)
+	b.printf("if (!wuffs_base__io_reader__read_uvarint64_fast(&iop_%s, &varint_%d)) {\n",
+		recvName, temp)
+	badData()
+	b.writes("}\n")
+	varint := fmt.Sprintf("varint_%d", temp)
+	b.printf("%s%d = %s;\n", tPrefix, temp, result(varint))
+	b.writes("} else {\n")
+
+	b.printf("%s = 1;\n", scratchName)
+	if err := g.writeCoroSuspPoint(b, false); err != nil {
+		return err
+	}
+	b.writes("while (true) {\n")
+	b.printf("if (WUFFS_BASE__UNLIKELY(iop_%s == io2_%s)) {\n"+
+		"status = wuffs_base__make_status(wuffs_base__suspension__short_read);\ngoto suspend;\n}\n",
+		recvName, recvName)
+	b.printf("uint32_t varint_step_%d = wuffs_base__io_reader__read_uvarint64_step(&%s, *iop_%s++);\n",
+		temp, scratchName, recvName)
+	b.printf("if (varint_step_%d == 1) {\n", temp)
+	b.printf("%s%d = %s;\nbreak;\n", tPrefix, temp, result(scratchName))
+	b.printf("} else if (varint_step_%d == 2) {\n", temp)
+	badData()
+	b.writes("}\n}\n}\n")
+	return nil
+}
+
+// writeWriteVarint64 writes the C code for write_uvarint64 and
+// write_svarint64. The scratch variable holds the bits not yet written, so
+// that resuming after a "$short write" suspension can re-enter either path.
+func (g *gen) writeWriteVarint64(b *buffer, n *a.Expr, recvName string, signed bool, depth uint32) error {
+	g.currFunk.usesScratch = true
+	// TODO: don't hard-code [0], and allow recursive coroutines.
+	scratchName := fmt.Sprintf("self->private_data.%s%s[0].scratch",
+		sPrefix, g.currFunk.astFunc.FuncName().Str(g.tm))
+
+	b.printf("%s = ((uint64_t)(", scratchName)
+	x := n.Args()[0].AsArg().Value()
+	if err := g.writeExpr(b, x, false, depth); err != nil {
+		return err
+	}
+	b.writes("));\n")
+	if signed {
+		b.printf("%s = (%s << 1) ^ (0 - (%s >> 63));\n",
+			scratchName, scratchName, scratchName)
+	}
+
+	if err := g.writeCoroSuspPoint(b, false); err != nil {
+		return err
+	}
+	b.printf("if (WUFFS_BASE__LIKELY(io2_%s - iop_%s >= 10)) {\n", recvName, recvName)
+	b.printf("wuffs_base__io_writer__write_uvarint64_fast(&iop_%s, %s);\n", recvName, scratchName)
+	b.writes("} else {\n")
+	b.writes("while (true) {\n")
+	b.printf("if (iop_%s == io2_%s) {\n"+
+		"status = wuffs_base__make_status(wuffs_base__suspension__short_write);\n"+
+		"goto suspend;\n}\n",
+		recvName, recvName)
+	b.printf("if (%s < 0x80) {\n*iop_%s++ = ((uint8_t)(%s));\nbreak;\n}\n",
+		scratchName, recvName, scratchName)
+	b.printf("*iop_%s++ = ((uint8_t)(%s | 0x80));\n", recvName, scratchName)
+	b.printf("%s >>= 7;\n", scratchName)
+	b.writes("}\n}\n")
+	return nil
+}
+
 func (g *gen) writeReadUxxAsUyy(b *buffer, n *a.Expr, preName string, xx uint8, yy uint8, endianness uint8) error {
 	if (xx&7 != 0) || (xx < 16) || (xx > 64) {
 		return fmt.Errorf("internal error: bad writeReadUXX size %d", xx)
@@ -0,0 +1,150 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgen
+
+import (
+	"bytes"
+)
+
+// This file deals with "-cdialect=c99" output, for embedded toolchains that
+// are stricter than the usual C99 (or later) compiler. Compared to the
+// default output:
+//
+//  - "static inline" is spelled "static WUFFS_BASE__INLINE", which
+//    WUFFS_CONFIG__INLINE can override.
+//  - The header section (everything before the "WUFFS C HEADER ENDS HERE"
+//    marker) has no "//" comments, so that it can be #include'd by C89-ish
+//    translation units. Comments are rewritten in "/* etc */" form.
+//
+// The generated C code has no anonymous structs or unions, which are C11
+// (not C99), regardless of the dialect.
+
+var (
+	cdHeaderEndsHere = []byte("\n// ‼ WUFFS C HEADER ENDS HERE.\n")
+	cdStaticInline   = []byte("static inline ")
+	cdStaticWBInline = []byte("static WUFFS_BASE__INLINE ")
+)
+
+// C99ify rewrites generated C code per "-cdialect=c99". If keepMarkers, the
+// "// ¡ etc" and "// ‼ etc" marker comments, which "wuffs gen" looks for when
+// assembling a release, are left alone.
+func C99ify(src []byte, keepMarkers bool) []byte {
+	src = bytes.Replace(src, cdStaticInline, cdStaticWBInline, -1)
+
+	header, rest := src, []byte(nil)
+	if i := bytes.Index(src, cdHeaderEndsHere); i >= 0 {
+		header, rest = src[:i+1], src[i+1:]
+	}
+
+	dst := make([]byte, 0, len(src)+len(src)/16)
+	inBlockComment := false
+	for len(header) > 0 {
+		line := header
+		if i := bytes.IndexByte(header, '\n'); i >= 0 {
+			line, header = header[:i+1], header[i+1:]
+		} else {
+			header = nil
+		}
+		dst = cdRewriteLine(dst, line, &inBlockComment, keepMarkers)
+	}
+	return append(dst, rest...)
+}
+
+// FindLineComment returns the 1-based line number of the first "//" comment
+// in generated C code's header section, or 0 if there is none. Marker
+// comments are ignored.
+func FindLineComment(src []byte) int {
+	if i := bytes.Index(src, cdHeaderEndsHere); i >= 0 {
+		src = src[:i+1]
+	}
+	inBlockComment := false
+	for n := 1; len(src) > 0; n++ {
+		line := src
+		if i := bytes.IndexByte(src, '\n'); i >= 0 {
+			line, src = src[:i+1], src[i+1:]
+		} else {
+			src = nil
+		}
+		if cdLineCommentIndex(line, &inBlockComment, true) >= 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+func cdIsMarker(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	return bytes.HasPrefix(line, []byte("// ¡")) || bytes.HasPrefix(line, []byte("// ‼"))
+}
+
+// cdLineCommentIndex returns the index of the line's "//" comment, or -1. It
+// updates *inBlockComment for "/* etc */" comments spanning lines.
+func cdLineCommentIndex(line []byte, inBlockComment *bool, keepMarkers bool) int {
+	if keepMarkers && cdIsMarker(line) {
+		return -1
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if *inBlockComment {
+			if (c == '*') && (i+1 < len(line)) && (line[i+1] == '/') {
+				*inBlockComment = false
+				i++
+			}
+			continue
+		}
+		switch c {
+		case '/':
+			if i+1 < len(line) {
+				if line[i+1] == '/' {
+					return i
+				} else if line[i+1] == '*' {
+					*inBlockComment = true
+					i++
+				}
+			}
+		case '"', '\'':
+			for i++; (i < len(line)) && (line[i] != c) && (line[i] != '\n'); i++ {
+				if line[i] == '\\' {
+					i++
+				}
+			}
+		}
+	}
+	return -1
+}
+
+func cdRewriteLine(dst []byte, line []byte, inBlockComment *bool, keepMarkers bool) []byte {
+	i := cdLineCommentIndex(line, inBlockComment, keepMarkers)
+	if i < 0 {
+		return append(dst, line...)
+	}
+	code := bytes.TrimRight(line[:i], " \t")
+	text := bytes.TrimSpace(line[i+2:])
+	text = bytes.Replace(text, []byte("*/"), []byte("* /"), -1)
+
+	dst = append(dst, code...)
+	if len(text) > 0 {
+		if len(code) > 0 {
+			// Keep any "foo;  // bar" two-space alignment.
+			dst = append(dst, line[len(code):i]...)
+		} else {
+			dst = append(dst, line[:i]...)
+		}
+		dst = append(dst, "/* "...)
+		dst = append(dst, text...)
+		dst = append(dst, " */"...)
+	}
+	return append(dst, '\n')
+}
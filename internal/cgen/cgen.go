@@ -51,6 +51,7 @@ var (
 // "double" being a valid Wuffs variable name but not a valid C one.
 const (
 	aPrefix = "a_" // Function argument.
+	cPrefix = "c_" // Runtime-initialized const table.
 	fPrefix = "f_" // Struct field.
 	iPrefix = "i_" // Iterate variable.
 	oPrefix = "o_" // Temporary io_bind variable.
@@ -113,7 +114,11 @@ var BaseSubModules = []string{
 // The generated program is written to stdout.
 func Do(args []string) error {
 	flags := flag.FlagSet{}
+	annotationsFlag := flags.Bool("annotations", cf.AnnotationsDefault, cf.AnnotationsUsage)
+	cdialectFlag := flags.String("cdialect", cf.CdialectDefault, cf.CdialectUsage)
+	cppwrappersFlag := flags.Bool("cppwrappers", cf.CppwrappersDefault, cf.CppwrappersUsage)
 	genlinenumFlag := flags.Bool("genlinenum", cf.GenlinenumDefault, cf.GenlinenumUsage)
+	runtimetablesFlag := flags.Bool("runtimetables", cf.RuntimetablesDefault, cf.RuntimetablesUsage)
 
 	return generate.Do(&flags, args, func(pkgName string, tm *t.Map, files []*a.File) ([]byte, error) {
 		unformatted := []byte(nil)
@@ -121,8 +126,24 @@ func Do(args []string) error {
 			if len(files) != 0 {
 				return nil, fmt.Errorf("base package shouldn't have any .wuffs files")
 			}
+			if !cf.IsValidCdialect(*cdialectFlag) {
+				return nil, fmt.Errorf("bad -cdialect flag value %q", *cdialectFlag)
+			}
 			buf := make(buffer, 0, 128*1024)
 			if err := expandBangBangInsert(&buf, data.BaseAllImplC, map[string]func(*buffer) error{
+				"// ¡ INSERT generation flags.\n": func(b *buffer) error {
+					if d := strings.ToUpper(*cdialectFlag); d != "" {
+						b.printf("\n// This file was generated with \"-cdialect=%s\".\n", *cdialectFlag)
+						b.printf("#if !defined(WUFFS_CONFIG__C_DIALECT__%s)\n", d)
+						b.printf("#define WUFFS_CONFIG__C_DIALECT__%s\n", d)
+						b.printf("#endif\n")
+					}
+					if *cppwrappersFlag {
+						b.writes("\n// This file was generated with \"-cppwrappers\".\n")
+						b.writes("#define WUFFS_BASE__CPP_WRAPPERS\n")
+					}
+					return nil
+				},
 				"// ¡ INSERT InterfaceDeclarations.\n":      insertInterfaceDeclarations,
 				"// ¡ INSERT InterfaceDefinitions.\n":       insertInterfaceDefinitions,
 				"// ¡ INSERT base/all-private.h.\n":         insertBaseAllPrivateH,
@@ -164,13 +185,16 @@ func Do(args []string) error {
 
 		} else {
 			g := &gen{
-				PKGPREFIX:  "WUFFS_" + strings.ToUpper(pkgName) + "__",
-				PKGNAME:    strings.ToUpper(pkgName),
-				pkgPrefix:  "wuffs_" + pkgName + "__",
-				pkgName:    pkgName,
-				tm:         tm,
-				files:      files,
-				genlinenum: *genlinenumFlag,
+				PKGPREFIX:     "WUFFS_" + strings.ToUpper(pkgName) + "__",
+				PKGNAME:       strings.ToUpper(pkgName),
+				pkgPrefix:     "wuffs_" + pkgName + "__",
+				pkgName:       pkgName,
+				tm:            tm,
+				files:         files,
+				annotations:   *annotationsFlag,
+				cppwrappers:   *cppwrappersFlag,
+				genlinenum:    *genlinenumFlag,
+				runtimetables: *runtimetablesFlag,
 			}
 			var err error
 			unformatted, err = g.generate()
@@ -183,11 +207,15 @@ func Do(args []string) error {
 		// Wuffs, and that part is presumably already formatted. The rest is
 		// generated by this package. We take care here to print well indented
 		// C code, so further C formatting is unnecessary.
-		if pkgName == "base" {
-			return unformatted, nil
+		formatted := unformatted
+		if pkgName != "base" {
+			formatted = dumbindent.FormatBytes(nil, unformatted, nil)
 		}
 
-		return dumbindent.FormatBytes(nil, unformatted, nil), nil
+		if *cdialectFlag == "c99" {
+			return C99ify(formatted, true), nil
+		}
+		return formatted, nil
 	})
 }
 
@@ -407,65 +435,74 @@ func insertInterfaceDeclarations(buf *buffer) error {
 		buf.writes("\n// --------\n\n")
 
 		qid := t.QID{t.IDBase, builtInTokenMap.ByName(n)}
+		if err := g.writeInterfaceDeclaration(buf, "wuffs_base__"+n, builtInInterfaceMethods[qid]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		buf.printf("extern const char wuffs_base__%s__vtable_name[];\n\n", n)
+// writeInterfaceDeclaration writes the C (and C++) declarations for an
+// interface type, such as wuffs_base__hasher_u32. Structs that implement the
+// interface hold a pointer to a func_ptrs struct (a vtable).
+func (g *gen) writeInterfaceDeclaration(buf *buffer, iName string, methods []*a.Func) error {
+	buf.printf("extern const char %s__vtable_name[];\n\n", iName)
 
-		buf.printf("typedef struct wuffs_base__%s__func_ptrs__struct {\n", n)
-		for _, f := range builtInInterfaceMethods[qid] {
-			buf.writes("  ")
-			if err := g.writeFuncSignature(buf, f, wfsCFuncPtrField); err != nil {
-				return err
-			}
-			buf.writes(";\n")
+	buf.printf("typedef struct %s__func_ptrs__struct {\n", iName)
+	for _, f := range methods {
+		buf.writes("  ")
+		if err := g.writeFuncSignature(buf, f, wfsCFuncPtrField); err != nil {
+			return err
 		}
-		buf.printf("} wuffs_base__%s__func_ptrs;\n\n", n)
+		buf.writes(";\n")
+	}
+	buf.printf("} %s__func_ptrs;\n\n", iName)
 
-		buf.printf("typedef struct wuffs_base__%s__struct wuffs_base__%s;\n\n", n, n)
+	buf.printf("typedef struct %s__struct %s;\n\n", iName, iName)
 
-		for _, f := range builtInInterfaceMethods[qid] {
-			if err := g.writeFuncSignature(buf, f, wfsCDecl); err != nil {
-				return err
-			}
-			buf.writes(";\n\n")
+	for _, f := range methods {
+		if err := g.writeFuncSignature(buf, f, wfsCDecl); err != nil {
+			return err
 		}
+		buf.writes(";\n\n")
+	}
 
-		buf.writes("#if defined(__cplusplus) || defined(WUFFS_IMPLEMENTATION)\n\n")
+	buf.writes("#if defined(__cplusplus) || defined(WUFFS_IMPLEMENTATION)\n\n")
 
-		buf.printf("struct wuffs_base__%s__struct {\n", n)
-		buf.writes("  struct {\n")
-		buf.writes("    uint32_t magic;\n")
-		buf.writes("    uint32_t active_coroutine;\n")
-		buf.writes("    wuffs_base__vtable first_vtable;\n")
-		buf.writes("  } private_impl;\n\n")
+	buf.printf("struct %s__struct {\n", iName)
+	buf.writes("  struct {\n")
+	buf.writes("    uint32_t magic;\n")
+	buf.writes("    uint32_t active_coroutine;\n")
+	buf.writes("    wuffs_base__vtable first_vtable;\n")
+	buf.writes("  } private_impl;\n\n")
 
-		buf.writes("#ifdef __cplusplus\n")
-		buf.writes("#if defined(WUFFS_BASE__HAVE_UNIQUE_PTR)\n")
-		buf.printf("  using unique_ptr = std::unique_ptr<wuffs_base__%s, decltype(&free)>;\n", n)
-		buf.writes("#endif\n\n")
+	buf.writes("#ifdef __cplusplus\n")
+	buf.writes("#if defined(WUFFS_BASE__HAVE_UNIQUE_PTR)\n")
+	buf.printf("  using unique_ptr = std::unique_ptr<%s, decltype(&free)>;\n", iName)
+	buf.writes("#endif\n\n")
 
-		for _, f := range builtInInterfaceMethods[qid] {
-			if err := g.writeFuncSignature(buf, f, wfsCppDecl); err != nil {
-				return err
-			}
-			buf.writes(" {\n    return ")
-			buf.writes(g.funcCName(f))
-			if len(f.In().Fields()) == 0 {
-				buf.writes("(this")
-			} else {
-				buf.writes("(\n        this")
-				for _, o := range f.In().Fields() {
-					buf.writes(", ")
-					buf.writes(aPrefix)
-					buf.writes(o.AsField().Name().Str(g.tm))
-				}
+	for _, f := range methods {
+		if err := g.writeFuncSignature(buf, f, wfsCppDecl); err != nil {
+			return err
+		}
+		buf.writes(" {\n    return ")
+		buf.writes(g.funcCName(f))
+		if len(f.In().Fields()) == 0 {
+			buf.writes("(this")
+		} else {
+			buf.writes("(\n        this")
+			for _, o := range f.In().Fields() {
+				buf.writes(", ")
+				buf.writes(aPrefix)
+				buf.writes(o.AsField().Name().Str(g.tm))
 			}
-			buf.writes(");\n  }\n\n")
 		}
-		buf.writes("#endif  // __cplusplus\n")
-		buf.printf("};  // struct wuffs_base__%s__struct\n\n", n)
-
-		buf.writes("#endif  // defined(__cplusplus) || defined(WUFFS_IMPLEMENTATION)\n")
+		buf.writes(");\n  }\n\n")
 	}
+	buf.writes("#endif  // __cplusplus\n")
+	buf.printf("};  // struct %s__struct\n\n", iName)
+
+	buf.writes("#endif  // defined(__cplusplus) || defined(WUFFS_IMPLEMENTATION)\n")
 	return nil
 }
 
@@ -487,46 +524,8 @@ func insertInterfaceDefinitions(buf *buffer) error {
 		}
 
 		qid := t.QID{t.IDBase, builtInTokenMap.ByName(n)}
-
-		for _, f := range builtInInterfaceMethods[qid] {
-			returnsStatus := f.Effect().Coroutine() ||
-				((f.Out() != nil) && f.Out().IsStatus())
-
-			buf.writeb('\n')
-			if err := g.writeFuncSignature(buf, f, wfsCDecl); err != nil {
-				return err
-			}
-			buf.writes(" {\n")
-			if err := writeFuncImplSelfMagicCheck(buf, g.tm, f); err != nil {
-				return err
-			}
-
-			buf.writes("\n  const wuffs_base__vtable* v = &self->private_impl.first_vtable;\n")
-			buf.writes("  int i;\n")
-			buf.printf("  for (i = 0; i < %d; i++) {\n", a.MaxImplements)
-			buf.printf("    if (v->vtable_name == wuffs_base__%s__vtable_name) {\n", n)
-			buf.printf("      const wuffs_base__%s__func_ptrs* func_ptrs =\n"+
-				"          (const wuffs_base__%s__func_ptrs*)(v->function_pointers);\n", n, n)
-			buf.printf("      return (*func_ptrs->%s)(self", f.FuncName().Str(g.tm))
-			for _, o := range f.In().Fields() {
-				buf.writes(", ")
-				buf.writes(aPrefix)
-				buf.writes(o.AsField().Name().Str(g.tm))
-			}
-			buf.writes(");\n")
-			buf.writes("    } else if (v->vtable_name == NULL) {\n")
-			buf.writes("      break;\n")
-			buf.writes("    }\n")
-			buf.writes("    v++;\n")
-			buf.writes("  }\n\n")
-
-			buf.writes("  return ")
-			if returnsStatus {
-				buf.writes("wuffs_base__make_status(wuffs_base__error__bad_vtable)")
-			} else if err := writeOutParamZeroValue(buf, g.tm, f.Out()); err != nil {
-				return err
-			}
-			buf.writes(";\n}\n")
+		if err := g.writeInterfaceDefinition(buf, "wuffs_base__"+n, builtInInterfaceMethods[qid]); err != nil {
+			return err
 		}
 		if (i + 1) < len(builtin.Interfaces) {
 			buf.writeb('\n')
@@ -536,6 +535,52 @@ func insertInterfaceDefinitions(buf *buffer) error {
 	return nil
 }
 
+// writeInterfaceDefinition writes the C implementation of an interface's
+// methods, which dispatch via the vtable whose name matches iName.
+func (g *gen) writeInterfaceDefinition(buf *buffer, iName string, methods []*a.Func) error {
+	for _, f := range methods {
+		returnsStatus := f.Effect().Coroutine() ||
+			((f.Out() != nil) && f.Out().IsStatus())
+
+		buf.writeb('\n')
+		if err := g.writeFuncSignature(buf, f, wfsCDecl); err != nil {
+			return err
+		}
+		buf.writes(" {\n")
+		if err := g.writeFuncImplSelfMagicCheck(buf, f); err != nil {
+			return err
+		}
+
+		buf.writes("\n  const wuffs_base__vtable* v = &self->private_impl.first_vtable;\n")
+		buf.writes("  int i;\n")
+		buf.printf("  for (i = 0; i < %d; i++) {\n", a.MaxImplements)
+		buf.printf("    if (v->vtable_name == %s__vtable_name) {\n", iName)
+		buf.printf("      const %s__func_ptrs* func_ptrs =\n"+
+			"          (const %s__func_ptrs*)(v->function_pointers);\n", iName, iName)
+		buf.printf("      return (*func_ptrs->%s)(self", f.FuncName().Str(g.tm))
+		for _, o := range f.In().Fields() {
+			buf.writes(", ")
+			buf.writes(aPrefix)
+			buf.writes(o.AsField().Name().Str(g.tm))
+		}
+		buf.writes(");\n")
+		buf.writes("    } else if (v->vtable_name == NULL) {\n")
+		buf.writes("      break;\n")
+		buf.writes("    }\n")
+		buf.writes("    v++;\n")
+		buf.writes("  }\n\n")
+
+		buf.writes("  return ")
+		if returnsStatus {
+			buf.writes("wuffs_base__make_status(wuffs_base__error__bad_vtable)")
+		} else if err := g.writeOutParamZeroValue(buf, f.Out()); err != nil {
+			return err
+		}
+		buf.writes(";\n}\n")
+	}
+	return nil
+}
+
 var (
 	builtInTokenMap         = t.Map{}
 	builtInInterfaceMethods = map[t.QID][]*a.Func{}
@@ -561,13 +606,37 @@ type gen struct {
 	tm    *t.Map
 	files []*a.File
 
+	// annotations is whether to annotate the public function prototypes with
+	// Clang nullability qualifiers (WUFFS_BASE__NONNULL and
+	// WUFFS_BASE__NULLABLE) and GUARDED_BY-style thread-safety comments.
+	annotations bool
+
+	// inPrototype is whether writeFuncSignature is writing a function
+	// prototype (which is annotated) instead of a definition (which isn't).
+	inPrototype bool
+
+	// cppwrappers is whether to generate, for each public struct, a C++ class
+	// that owns a heap allocated instance of that struct.
+	cppwrappers bool
+
 	// genlinenum is whether to print "// foo.wuffs:123" comments in the
 	// generated C code. This can be useful for debugging, although it is not
 	// enabled by default as it can lead to many spurious changes in the
 	// generated C code (due to line numbers changing) when editing Wuffs code.
 	genlinenum bool
 
+	// runtimetables is whether const tables that have a maker function (see
+	// gatherRuntimeTables) are computed at initialize time into private_data,
+	// instead of being static const data. This trades startup time and memory
+	// for a smaller (read-only data section of the) binary.
+	runtimetables bool
+
+	enumList          []*a.Enum
+	enumMap           map[t.QID]*a.Enum
+	interfaceList     []*a.Interface
+	interfaceMap      map[t.QID]*a.Interface
 	privateDataFields map[t.QQID]struct{}
+	runtimeTables     map[t.ID]runtimeTable
 	scalarConstsMap   map[t.QID]*a.Const
 	statusList        []status
 	statusMap         map[t.QID]status
@@ -605,12 +674,24 @@ func (g *gen) generate() ([]byte, error) {
 	if err := g.forEachConst(b, bothPubPri, (*gen).gatherScalarConsts); err != nil {
 		return nil, err
 	}
+	g.gatherRuntimeTables()
 
 	// Make a topologically sorted list of structs.
 	unsortedStructs := []*a.Struct(nil)
+	g.enumMap = map[t.QID]*a.Enum{}
+	g.interfaceMap = map[t.QID]*a.Interface{}
 	for _, file := range g.files {
 		for _, tld := range file.TopLevelDecls() {
-			if tld.Kind() == a.KStruct {
+			switch tld.Kind() {
+			case a.KEnum:
+				n := tld.AsEnum()
+				g.enumList = append(g.enumList, n)
+				g.enumMap[n.QID()] = n
+			case a.KInterface:
+				n := tld.AsInterface()
+				g.interfaceList = append(g.interfaceList, n)
+				g.interfaceMap[n.QID()] = n
+			case a.KStruct:
 				unsortedStructs = append(unsortedStructs, tld.AsStruct())
 			}
 		}
@@ -727,6 +808,13 @@ func (g *gen) genHeader(b *buffer) error {
 		return err
 	}
 
+	if len(g.enumList) > 0 {
+		b.writes("// ---------------- Enums\n\n")
+		for _, n := range g.enumList {
+			g.writeEnum(b, n)
+		}
+	}
+
 	b.writes("// ---------------- Struct Declarations\n\n")
 	for _, n := range g.structList {
 		structName := n.QID().Str(g.tm)
@@ -735,6 +823,17 @@ func (g *gen) genHeader(b *buffer) error {
 
 	b.writes("#ifdef __cplusplus\nextern \"C\" {\n#endif\n\n")
 
+	if len(g.interfaceList) > 0 {
+		b.writes("// ---------------- Interface Declarations\n")
+		for _, n := range g.interfaceList {
+			b.writes("\n")
+			if err := g.writeInterfaceDeclaration(b, g.interfaceCName(n.QID()), interfaceMethods(n)); err != nil {
+				return err
+			}
+		}
+		b.writes("\n")
+	}
+
 	b.writes("// ---------------- Public Initializer Prototypes\n\n")
 	b.writes("// For any given \"wuffs_foo__bar* self\", \"wuffs_foo__bar__initialize(self,\n")
 	b.writes("// etc)\" should be called before any other \"wuffs_foo__bar__xxx(self, etc)\".\n")
@@ -755,7 +854,11 @@ func (g *gen) genHeader(b *buffer) error {
 	b.writes("// memory allocation fails. If they return non-NULL, there is no need to call\n")
 	b.writes("// wuffs_foo__bar__initialize, but the caller is responsible for eventually\n")
 	b.writes("// calling free on the returned pointer. That pointer is effectively a C++\n")
-	b.writes("// std::unique_ptr<T, decltype(&free)>.\n\n")
+	b.writes("// std::unique_ptr<T, decltype(&free)>.\n")
+	b.writes("//\n")
+	b.writes("// The alloc_with variants use the given wuffs_base__mem__allocator (or calloc\n")
+	b.writes("// and free, if it is NULL) instead. Their returned pointer should be released\n")
+	b.writes("// by wuffs_base__mem__allocator__free with that same allocator.\n\n")
 
 	for _, n := range g.structList {
 		if !n.Public() {
@@ -765,14 +868,24 @@ func (g *gen) genHeader(b *buffer) error {
 			return err
 		}
 		b.writes(";\n\n")
+		if err := g.writeAllocWithSignature(b, n); err != nil {
+			return err
+		}
+		b.writes(";\n\n")
 		structName := n.QID().Str(g.tm)
 		for _, impl := range n.Implements() {
 			iQID := impl.AsTypeExpr().QID()
-			iName := fmt.Sprintf("wuffs_%s__%s", iQID[0].Str(g.tm), iQID[1].Str(g.tm))
+			iName := g.interfaceCName(iQID)
 			b.printf("static inline %s*\n", iName)
-			b.printf("%s%s__alloc_as__%s() {\n", g.pkgPrefix, structName, iName)
+			b.printf("%s%s__alloc_as__%s(void) {\n", g.pkgPrefix, structName, iName)
 			b.printf("return (%s*)(%s%s__alloc());\n", iName, g.pkgPrefix, structName)
 			b.printf("}\n\n")
+			b.printf("static inline %s*\n", iName)
+			b.printf("%s%s__alloc_with_as__%s(\n"+
+				"    const wuffs_base__mem__allocator* allocator) {\n",
+				g.pkgPrefix, structName, iName)
+			b.printf("return (%s*)(%s%s__alloc_with(allocator));\n", iName, g.pkgPrefix, structName)
+			b.printf("}\n\n")
 		}
 	}
 
@@ -781,7 +894,7 @@ func (g *gen) genHeader(b *buffer) error {
 		structName := n.QID().Str(g.tm)
 		for _, impl := range n.Implements() {
 			iQID := impl.AsTypeExpr().QID()
-			iName := fmt.Sprintf("wuffs_%s__%s", iQID[0].Str(g.tm), iQID[1].Str(g.tm))
+			iName := g.interfaceCName(iQID)
 			b.printf("static inline %s*\n", iName)
 			b.printf("%s%s__upcast_as__%s(\n    %s%s* p) {\n",
 				g.pkgPrefix, structName, iName, g.pkgPrefix, structName)
@@ -791,9 +904,22 @@ func (g *gen) genHeader(b *buffer) error {
 	}
 
 	b.writes("// ---------------- Public Function Prototypes\n\n")
+	if g.annotations {
+		// The rest of the single file library isn't annotated, so silence
+		// Clang's "pointer is missing a nullability type specifier" warnings.
+		b.writes("#if defined(__clang__)\n")
+		b.writes("#pragma clang diagnostic push\n")
+		b.writes("#pragma clang diagnostic ignored \"-Wnullability-completeness\"\n")
+		b.writes("#endif\n\n")
+	}
 	if err := g.forEachFunc(b, pubOnly, (*gen).writeFuncPrototype); err != nil {
 		return err
 	}
+	if g.annotations {
+		b.writes("#if defined(__clang__)\n")
+		b.writes("#pragma clang diagnostic pop\n")
+		b.writes("#endif\n\n")
+	}
 
 	b.writes("#ifdef __cplusplus\n}  // extern \"C\"\n#endif\n\n")
 
@@ -851,6 +977,18 @@ func (g *gen) genImpl(b *buffer) error {
 		return err
 	}
 
+	if len(g.interfaceList) > 0 {
+		b.writes("// ---------------- Interface Definitions\n\n")
+		for _, n := range g.interfaceList {
+			iName := g.interfaceCName(n.QID())
+			b.printf("const char %s__vtable_name[] = \"{vtable}%s\";\n", iName, iName)
+			if err := g.writeInterfaceDefinition(b, iName, interfaceMethods(n)); err != nil {
+				return err
+			}
+			b.writes("\n")
+		}
+	}
+
 	b.writes("// ---------------- VTables\n\n")
 	for _, n := range g.structList {
 		if err := g.writeVTableImpl(b, n); err != nil {
@@ -858,6 +996,19 @@ func (g *gen) genImpl(b *buffer) error {
 		}
 	}
 
+	hasUnions := false
+	for _, n := range g.structList {
+		if !n.Union() {
+			continue
+		} else if !hasUnions {
+			hasUnions = true
+			b.writes("// ---------------- Union Method Implementations\n\n")
+		}
+		if err := g.writeUnionMethodImpls(b, n); err != nil {
+			return err
+		}
+	}
+
 	b.writes("// ---------------- Initializer Implementations\n\n")
 	for _, n := range g.structList {
 		if err := g.writeInitializerImpl(b, n); err != nil {
@@ -895,7 +1046,8 @@ func (g *gen) forEachFunc(b *buffer, v visibility, f func(*gen, *buffer, *a.Func
 		for _, tld := range file.TopLevelDecls() {
 			if tld.Kind() != a.KFunc ||
 				((v == pubOnly) && !tld.AsFunc().Public()) ||
-				((v == priOnly) && tld.AsFunc().Public()) {
+				((v == priOnly) && tld.AsFunc().Public()) ||
+				(!g.runtimetables && g.isRuntimeTableMaker(tld.AsFunc())) {
 				continue
 			}
 			if err := f(g, b, tld.AsFunc()); err != nil {
@@ -1018,7 +1170,9 @@ func (g *gen) gatherScalarConsts(b *buffer, n *a.Const) error {
 }
 
 func (g *gen) writeConst(b *buffer, n *a.Const) error {
-	if cv := n.Value().ConstValue(); cv != nil {
+	if _, ok := g.runtimeTables[n.QID()[1]]; ok && g.runtimetables {
+		return nil
+	} else if cv := n.Value().ConstValue(); cv != nil {
 		b.printf("#define %s%s %v\n\n", g.PKGPREFIX, n.QID()[1].Str(g.tm), cv)
 	} else {
 		b.writes("static const ")
@@ -1026,7 +1180,7 @@ func (g *gen) writeConst(b *buffer, n *a.Const) error {
 			return err
 		}
 		b.writes(" WUFFS_BASE__POTENTIALLY_UNUSED = ")
-		if err := g.writeConstList(b, n.Value()); err != nil {
+		if err := g.writeConstList(b, n.Value(), n.XType()); err != nil {
 			return err
 		}
 		b.writes(";\n\n")
@@ -1034,21 +1188,79 @@ func (g *gen) writeConst(b *buffer, n *a.Const) error {
 	return nil
 }
 
-func (g *gen) writeConstList(b *buffer, n *a.Expr) error {
+// writeEnum writes n's C type, the smallest unsigned integer type that holds
+// all of its members' values. Public enums also get a #define per member.
+// Private enums' typedefs are still needed, as private struct fields can have
+// an enum type.
+func (g *gen) writeEnum(b *buffer, n *a.Enum) {
+	members := n.Members()
+	cType := "uint8_t"
+	if len(members) > 0x10000 {
+		cType = "uint32_t"
+	} else if len(members) > 0x100 {
+		cType = "uint16_t"
+	}
+	enumName := n.QID()[1].Str(g.tm)
+	b.printf("typedef %s %s%s;\n\n", cType, g.pkgPrefix, enumName)
+	if !n.Public() {
+		return
+	}
+	for i, o := range members {
+		b.printf("#define %s%s__%s %d\n", g.PKGPREFIX, strings.ToUpper(enumName),
+			strings.ToUpper(o.AsExpr().Ident().Str(g.tm)), i)
+	}
+	b.writes("\n")
+}
+
+// isEnumType returns whether typ is an enum declared in this package.
+func (g *gen) isEnumType(typ *a.TypeExpr) bool {
+	return (typ.Decorator() == 0) && (g.enumMap[typ.QID()] != nil)
+}
+
+// writeConstList writes n, a const value of type typ, as a C initializer.
+// Each struct value (a list of its fields' values) is wrapped in an extra pair
+// of braces, as the C struct's fields are within its private_impl.
+func (g *gen) writeConstList(b *buffer, n *a.Expr, typ *a.TypeExpr) error {
 	if args, ok := n.IsList(); ok {
-		b.writeb('{')
+		s := (*a.Struct)(nil)
+		if typ.Decorator() == 0 {
+			if s = g.structMap[typ.QID()]; s == nil {
+				return fmt.Errorf("invalid const type %q", typ.Str(g.tm))
+			}
+			b.writes("{{")
+		} else {
+			b.writeb('{')
+		}
+		// Write each array element that is a struct on its own line.
+		structElems := (s == nil) && (typ.Inner().Decorator() == 0) &&
+			(typ.Inner().QID()[0] != t.IDBase)
 		for i, o := range args {
-			if i&7 == 0 {
+			if (s == nil) && ((i&7 == 0) || structElems) {
 				b.writeb('\n')
 			}
-			if err := g.writeConstList(b, o.AsExpr()); err != nil {
+			elemTyp := typ.Inner()
+			if s != nil {
+				elemTyp = s.Fields()[i].AsField().XType()
+			}
+			if err := g.writeConstList(b, o.AsExpr(), elemTyp); err != nil {
 				return err
 			}
-			b.writes(", ")
+			if s == nil {
+				b.writes(", ")
+			} else if i+1 < len(args) {
+				b.writes(", ")
+			}
+		}
+		if s != nil {
+			b.writes("}}")
+		} else {
+			b.writes("\n}")
 		}
-		b.writes("\n}")
 	} else if cv := n.ConstValue(); cv != nil {
 		b.writes(cv.String())
+		if cv.Cmp(maxInt64) > 0 {
+			b.writeb('u')
+		}
 	} else {
 		return fmt.Errorf("invalid const value %q", n.Str(g.tm))
 	}
@@ -1071,11 +1283,22 @@ func (g *gen) writeStructPrivateImpl(b *buffer, n *a.Struct) error {
 		b.writes("uint32_t magic;\n")
 		b.writes("uint32_t active_coroutine;\n")
 		for _, impl := range n.Implements() {
-			qid := impl.AsTypeExpr().QID()
-			b.printf("wuffs_base__vtable vtable_for__wuffs_%s__%s;\n",
-				qid[0].Str(g.tm), qid[1].Str(g.tm))
+			b.printf("wuffs_base__vtable vtable_for__%s;\n",
+				g.interfaceCName(impl.AsTypeExpr().QID()))
 		}
 		b.writes("wuffs_base__vtable null_vtable;\n")
+		if g.structHasMetrics(n) {
+			b.writes("wuffs_base__metrics metrics;\n")
+		}
+		if g.structHasOutputHasher(n) {
+			b.writes("wuffs_base__hasher_u32* output_hasher;\n")
+		}
+		if g.structHasSeek(n) {
+			b.writes("uint64_t seek_io_position;\n")
+		}
+		if g.structHasLimits(n) {
+			b.writes("wuffs_base__image_decoder_limits limits;\n")
+		}
 		b.writes("\n")
 	}
 
@@ -1143,6 +1366,15 @@ func (g *gen) writeStructPrivateImpl(b *buffer, n *a.Struct) error {
 			b.writes(";\n")
 		}
 
+		if g.runtimetables {
+			for _, name := range g.runtimeTableNames(n.QID()) {
+				if err := g.writeCTypeName(b, g.runtimeTables[name].konst.XType(), cPrefix, name.Str(g.tm)); err != nil {
+					return err
+				}
+				b.writes(";\n")
+			}
+		}
+
 		needEmptyLine := oldOuterLenB1 != len(*b)
 		for _, file := range g.files {
 			for _, tld := range file.TopLevelDecls() {
@@ -1198,6 +1430,10 @@ func (g *gen) writeStruct(b *buffer, n *a.Struct) error {
 	fullStructName := g.pkgPrefix + structName + "__struct"
 	b.printf("struct %s {\n", fullStructName)
 
+	if n.Union() {
+		return g.writeUnion(b, n, fullStructName)
+	}
+
 	if err := g.writeStructPrivateImpl(b, n); err != nil {
 		return err
 	}
@@ -1209,6 +1445,54 @@ func (g *gen) writeStruct(b *buffer, n *a.Struct) error {
 	}
 
 	b.printf("};  // struct %s\n\n", fullStructName)
+
+	if n.Public() && g.cppwrappers {
+		if err := g.writeCppWrapper(b, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *gen) writeUnion(b *buffer, n *a.Struct, fullStructName string) error {
+	b.writes("// Do not access the private_impl's or private_data's fields directly. There\n")
+	b.writes("// is no API/ABI compatibility or safety guarantee if you do so.\n")
+	b.writes("//\n")
+	b.writes("// The tag is zero if no member is active. Otherwise, it is one plus the\n")
+	b.writes("// index of the active member.\n\n")
+
+	b.writes("struct {\n")
+	b.writes("uint32_t tag;\n")
+	b.writes("} private_impl;\n\n")
+
+	b.writes("union {\n")
+	for _, o := range n.Fields() {
+		o := o.AsField()
+		if err := g.writeCTypeName(b, o.XType(), fPrefix, o.Name().Str(g.tm)); err != nil {
+			return err
+		}
+		b.writes(";\n")
+	}
+	b.writes("} private_data;\n")
+
+	b.printf("};  // struct %s\n\n", fullStructName)
+	return nil
+}
+
+// writeUnionMethodImpls writes the is_foo and set_foo methods implied by a
+// union declaration.
+func (g *gen) writeUnionMethodImpls(b *buffer, n *a.Struct) error {
+	structName := n.QID().Str(g.tm)
+	for i, o := range n.Fields() {
+		memberName := o.AsField().Name().Str(g.tm)
+		b.printf("static inline bool\n%s%s__is_%s(\n    const %s%s* self) {\n",
+			g.pkgPrefix, structName, memberName, g.pkgPrefix, structName)
+		b.printf("return self->private_impl.tag == %d;\n}\n\n", i+1)
+		b.printf("static inline wuffs_base__empty_struct\n%s%s__set_%s(\n    %s%s* self) {\n",
+			g.pkgPrefix, structName, memberName, g.pkgPrefix, structName)
+		b.printf("self->private_impl.tag = %d;\n", i+1)
+		b.writes("return wuffs_base__make_empty_struct();\n}\n\n")
+	}
 	return nil
 }
 
@@ -1226,13 +1510,20 @@ func (g *gen) writeCppMethods(b *buffer, n *a.Struct) error {
 	b.writes("}\n")
 	for _, impl := range n.Implements() {
 		iQID := impl.AsTypeExpr().QID()
-		iName := fmt.Sprintf("wuffs_%s__%s", iQID[0].Str(g.tm), iQID[1].Str(g.tm))
+		iName := g.interfaceCName(iQID)
 		b.printf("\nstatic inline %s::unique_ptr\n", iName)
 		b.printf("alloc_as__%s() {\n", iName)
 		b.printf("return %s::unique_ptr(\n%s%s__alloc_as__%s(), &free);\n",
 			iName, g.pkgPrefix, structName, iName)
 		b.printf("}\n")
 	}
+	b.printf("\nusing allocator_unique_ptr =\n"+
+		"std::unique_ptr<%s%s, wuffs_base__mem__allocator_deleter>;\n\n", g.pkgPrefix, structName)
+	b.writes("static inline allocator_unique_ptr\n")
+	b.writes("alloc_with(const wuffs_base__mem__allocator* allocator) {\n")
+	b.printf("return allocator_unique_ptr(%s%s__alloc_with(allocator),\n"+
+		"wuffs_base__mem__allocator_deleter{allocator});\n", g.pkgPrefix, structName)
+	b.writes("}\n")
 	b.writes("#endif  // defined(WUFFS_BASE__HAVE_UNIQUE_PTR)\n\n")
 
 	b.writes("#if defined(WUFFS_BASE__HAVE_EQ_DELETE) && !defined(WUFFS_IMPLEMENTATION)\n")
@@ -1269,9 +1560,29 @@ func (g *gen) writeCppMethods(b *buffer, n *a.Struct) error {
 	b.printf("return %s%s__initialize(\nthis, sizeof_star_self, wuffs_version, options);\n}\n\n",
 		g.pkgPrefix, structName)
 
+	if g.structHasMetrics(n) {
+		b.writes("inline wuffs_base__metrics\nmetrics() const {\n")
+		b.printf("return %s%s__metrics(this);\n}\n\n", g.pkgPrefix, structName)
+	}
+
+	if g.structHasOutputHasher(n) {
+		b.writes("inline wuffs_base__empty_struct\nset_output_hasher(\nwuffs_base__hasher_u32* h) {\n")
+		b.printf("return %s%s__set_output_hasher(this, h);\n}\n\n", g.pkgPrefix, structName)
+	}
+
+	if g.structHasSeek(n) {
+		b.writes("inline uint64_t\nseek_io_position() const {\n")
+		b.printf("return %s%s__seek_io_position(this);\n}\n\n", g.pkgPrefix, structName)
+	}
+
+	if g.structHasLimits(n) {
+		b.writes("inline wuffs_base__empty_struct\nset_limits(\nconst wuffs_base__image_decoder_limits* limits) {\n")
+		b.printf("return %s%s__set_limits(this, limits);\n}\n\n", g.pkgPrefix, structName)
+	}
+
 	for _, impl := range n.Implements() {
 		iQID := impl.AsTypeExpr().QID()
-		iName := fmt.Sprintf("wuffs_%s__%s", iQID[0].Str(g.tm), iQID[1].Str(g.tm))
+		iName := g.interfaceCName(iQID)
 		b.printf("inline %s*\n", iName)
 		b.printf("upcast_as__%s() {\n", iName)
 		b.printf("return (%s*)this;\n", iName)
@@ -1309,6 +1620,157 @@ func (g *gen) writeCppMethods(b *buffer, n *a.Struct) error {
 	return nil
 }
 
+// writeCppWrapper writes a C++ class, in a wuffs_foo namespace, that owns a
+// heap allocated wuffs_foo__bar. Its methods forward to the C functions, which
+// report errors via their wuffs_base__status return values instead of
+// throwing. Slice arguments get std::span (and, for pure functions, where the
+// slice contents are read-only, std::string_view) overloads.
+func (g *gen) writeCppWrapper(b *buffer, n *a.Struct) error {
+	structName := n.QID().Str(g.tm)
+	cStructName := g.pkgPrefix + structName
+	b.writes("#if defined(__cplusplus) && defined(WUFFS_BASE__HAVE_UNIQUE_PTR)\n\n")
+	b.printf("namespace wuffs_%s {\n\n", g.pkgName)
+
+	b.printf("// %s owns a heap allocated %s. It is movable but not\n", structName, cStructName)
+	b.writes("// copyable. A default constructed or moved-from object is empty, and calling\n")
+	b.writes("// its methods is safe but returns errors (or zero values).\n")
+	b.printf("class %s {\n", structName)
+	b.writes("public:\n")
+	b.printf("%s() : m_ptr(nullptr, &free) {}\n\n", structName)
+	b.writes("// make returns an initialized object, or an empty one if allocation failed.\n")
+	b.writes("// It doesn't throw.\n")
+	b.printf("static %s\nmake() {\n", structName)
+	b.printf("return %s(%s::alloc());\n}\n\n", structName, cStructName)
+	b.writes("inline bool\nempty() const {\nreturn !m_ptr;\n}\n\n")
+	b.printf("inline %s*\nget() const {\nreturn m_ptr.get();\n}\n\n", cStructName)
+
+	for _, impl := range n.Implements() {
+		iQID := impl.AsTypeExpr().QID()
+		iName := g.interfaceCName(iQID)
+		b.printf("inline %s*\n", iName)
+		b.printf("upcast_as__%s() const {\n", iName)
+		b.printf("return %s__upcast_as__%s(m_ptr.get());\n", cStructName, iName)
+		b.printf("}\n\n")
+	}
+
+	if g.structHasMetrics(n) {
+		b.writes("inline wuffs_base__metrics\nmetrics() const {\n")
+		b.printf("return %s__metrics(m_ptr.get());\n}\n\n", cStructName)
+	}
+
+	if g.structHasOutputHasher(n) {
+		b.writes("inline wuffs_base__empty_struct\nset_output_hasher(\nwuffs_base__hasher_u32* h) const {\n")
+		b.printf("return %s__set_output_hasher(m_ptr.get(), h);\n}\n\n", cStructName)
+	}
+
+	if g.structHasSeek(n) {
+		b.writes("inline uint64_t\nseek_io_position() const {\n")
+		b.printf("return %s__seek_io_position(m_ptr.get());\n}\n\n", cStructName)
+	}
+
+	if g.structHasLimits(n) {
+		b.writes("inline wuffs_base__empty_struct\nset_limits(\nconst wuffs_base__image_decoder_limits* limits) const {\n")
+		b.printf("return %s__set_limits(m_ptr.get(), limits);\n}\n\n", cStructName)
+	}
+
+	structID := n.QID()[1]
+	for _, file := range g.files {
+		for _, tld := range file.TopLevelDecls() {
+			if (tld.Kind() != a.KFunc) || !tld.AsFunc().Public() {
+				continue
+			}
+			f := tld.AsFunc()
+			if f.QQID()[1] != structID {
+				continue
+			}
+			if err := g.writeCppWrapperMethod(b, f, ""); err != nil {
+				return err
+			}
+			if !g.hasSliceU8Arg(f) {
+				continue
+			}
+			b.writes("#if defined(WUFFS_BASE__HAVE_SPAN)\n")
+			if err := g.writeCppWrapperMethod(b, f, "std::span<uint8_t>"); err != nil {
+				return err
+			}
+			b.writes("#endif  // defined(WUFFS_BASE__HAVE_SPAN)\n\n")
+			if !f.Effect().Pure() {
+				continue
+			}
+			b.writes("#if defined(WUFFS_BASE__HAVE_STRING_VIEW)\n")
+			if err := g.writeCppWrapperMethod(b, f, "std::string_view"); err != nil {
+				return err
+			}
+			b.writes("#endif  // defined(WUFFS_BASE__HAVE_STRING_VIEW)\n\n")
+		}
+	}
+
+	b.writes("private:\n")
+	b.printf("explicit %s(%s::unique_ptr p) : m_ptr(std::move(p)) {}\n\n", structName, cStructName)
+	b.printf("%s::unique_ptr m_ptr;\n", cStructName)
+	b.printf("};  // class %s\n\n", structName)
+
+	b.printf("}  // namespace wuffs_%s\n\n", g.pkgName)
+	b.writes("#endif  // defined(__cplusplus) && defined(WUFFS_BASE__HAVE_UNIQUE_PTR)\n\n")
+	return nil
+}
+
+func (g *gen) hasSliceU8Arg(f *a.Func) bool {
+	for _, o := range f.In().Fields() {
+		if isSliceU8(o.AsField().XType()) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSliceU8(typ *a.TypeExpr) bool {
+	if !typ.IsSliceType() {
+		return false
+	}
+	inner := typ.Inner()
+	return (inner.Decorator() == 0) && (inner.QID() == t.QID{t.IDBase, t.IDU8})
+}
+
+// writeCppWrapperMethod writes a writeCppWrapper method that forwards to f's C
+// function. If sliceCppType is non-empty, slice arguments have that C++ type
+// (e.g. "std::span<uint8_t>") instead of wuffs_base__slice_u8.
+func (g *gen) writeCppWrapperMethod(b *buffer, f *a.Func, sliceCppType string) error {
+	sig := buffer(nil)
+	if err := g.writeFuncSignature(&sig, f, wfsCppDecl); err != nil {
+		return err
+	}
+	if sliceCppType != "" {
+		for _, o := range f.In().Fields() {
+			o := o.AsField()
+			if isSliceU8(o.XType()) {
+				name := aPrefix + o.Name().Str(g.tm)
+				sig = buffer(strings.Replace(string(sig),
+					"wuffs_base__slice_u8 "+name, sliceCppType+" "+name, 1))
+			}
+		}
+	}
+	b.writex(sig)
+	b.writes(" {\n    return ")
+	b.writes(g.funcCName(f))
+	b.writes("(m_ptr.get()")
+	for _, o := range f.In().Fields() {
+		o := o.AsField()
+		name := aPrefix + o.Name().Str(g.tm)
+		switch {
+		case (sliceCppType == "") || !isSliceU8(o.XType()):
+			b.printf(", %s", name)
+		case sliceCppType == "std::string_view":
+			b.printf(",\nwuffs_base__make_slice_u8(const_cast<uint8_t*>(\n"+
+				"reinterpret_cast<const uint8_t*>(%s.data())),\n%s.size())", name, name)
+		default:
+			b.printf(",\nwuffs_base__make_slice_u8(%s.data(), %s.size())", name, name)
+		}
+	}
+	b.writes(");\n  }\n\n")
+	return nil
+}
+
 func (g *gen) writeVTableImpl(b *buffer, n *a.Struct) error {
 	impls := n.Implements()
 	if len(impls) == 0 {
@@ -1322,11 +1784,24 @@ func (g *gen) writeVTableImpl(b *buffer, n *a.Struct) error {
 	nQID := n.QID()
 	for _, impl := range impls {
 		iQID := impl.AsTypeExpr().QID()
-		b.printf("const wuffs_%s__%s__func_ptrs\n%s%s__func_ptrs_for__wuffs_%s__%s = {\n",
-			iQID[0].Str(g.tm), iQID[1].Str(g.tm),
-			g.pkgPrefix, nQID[1].Str(g.tm),
-			iQID[0].Str(g.tm), iQID[1].Str(g.tm),
-		)
+		iName := g.interfaceCName(iQID)
+		b.printf("const %s__func_ptrs\n%s%s__func_ptrs_for__%s = {\n",
+			iName, g.pkgPrefix, nQID[1].Str(g.tm), iName)
+
+		if iQID[0] != t.IDBase {
+			// An interface declared by this package.
+			for _, o := range g.interfaceMap[iQID].Methods() {
+				f := o.AsFunc()
+				b.writeb('(')
+				if err := g.writeFuncSignature(b, f, wfsCFuncPtrType); err != nil {
+					return err
+				}
+				b.printf(")(&%s%s__%s),\n",
+					g.pkgPrefix, nQID[1].Str(g.tm), f.FuncName().Str(g.tm))
+			}
+			b.writes("};\n\n")
+			continue
+		}
 
 		// Note the two t.Map values: g.tm and builtInTokenMap.
 		altQID := t.QID{
@@ -1348,6 +1823,23 @@ func (g *gen) writeVTableImpl(b *buffer, n *a.Struct) error {
 	return nil
 }
 
+func interfaceMethods(n *a.Interface) []*a.Func {
+	methods := make([]*a.Func, 0, len(n.Methods()))
+	for _, o := range n.Methods() {
+		methods = append(methods, o.AsFunc())
+	}
+	return methods
+}
+
+// interfaceCName returns the C name of an interface type, such as
+// "wuffs_base__hasher_u32" or "wuffs_foo__bar".
+func (g *gen) interfaceCName(qid t.QID) string {
+	if qid[0] == 0 {
+		return g.pkgPrefix + qid[1].Str(g.tm)
+	}
+	return "wuffs_" + qid[0].Str(g.tm) + "__" + qid[1].Str(g.tm)
+}
+
 func (g *gen) writeInitializerSignature(b *buffer, n *a.Struct, public bool) error {
 	structName := n.QID().Str(g.tm)
 	b.printf("wuffs_base__status WUFFS_BASE__WARN_UNUSED_RESULT\n"+
@@ -1362,16 +1854,144 @@ func (g *gen) writeInitializerSignature(b *buffer, n *a.Struct, public bool) err
 
 func (g *gen) writeAllocSignature(b *buffer, n *a.Struct) error {
 	structName := n.QID().Str(g.tm)
-	b.printf("%s%s*\n%s%s__alloc()", g.pkgPrefix, structName, g.pkgPrefix, structName)
+	b.printf("%s%s*\n%s%s__alloc(void)", g.pkgPrefix, structName, g.pkgPrefix, structName)
+	return nil
+}
+
+func (g *gen) writeAllocWithSignature(b *buffer, n *a.Struct) error {
+	structName := n.QID().Str(g.tm)
+	b.printf("%s%s*\n%s%s__alloc_with(\n"+
+		"    const wuffs_base__mem__allocator* allocator)",
+		g.pkgPrefix, structName, g.pkgPrefix, structName)
 	return nil
 }
 
 func (g *gen) writeSizeofSignature(b *buffer, n *a.Struct) error {
 	structName := n.QID().Str(g.tm)
-	b.printf("size_t\nsizeof__%s%s()", g.pkgPrefix, structName)
+	b.printf("size_t\nsizeof__%s%s(void)", g.pkgPrefix, structName)
+	return nil
+}
+
+func (g *gen) writeMetricsSignature(b *buffer, n *a.Struct) error {
+	structName := n.QID().Str(g.tm)
+	b.printf("wuffs_base__metrics\n%s%s__metrics(\n    const %s%s* self)",
+		g.pkgPrefix, structName, g.pkgPrefix, structName)
+	return nil
+}
+
+// structHasMetrics returns whether n gets a wuffs_base__metrics field and a
+// wuffs_foo__bar__metrics accessor: whether it is classy and has a public
+// coroutine method, whose calls update the counters.
+func (g *gen) structHasMetrics(n *a.Struct) bool {
+	if !n.Classy() {
+		return false
+	}
+	for _, file := range g.files {
+		for _, tld := range file.TopLevelDecls() {
+			if tld.Kind() != a.KFunc {
+				continue
+			}
+			o := tld.AsFunc()
+			if (o.Receiver() == n.QID()) && o.Public() && o.Effect().Coroutine() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *gen) writeSetOutputHasherSignature(b *buffer, n *a.Struct) error {
+	structName := n.QID().Str(g.tm)
+	b.printf("wuffs_base__empty_struct\n%s%s__set_output_hasher(\n    %s%s* self,\n    wuffs_base__hasher_u32* h)",
+		g.pkgPrefix, structName, g.pkgPrefix, structName)
+	return nil
+}
+
+// structHasOutputHasher returns whether n gets a wuffs_base__hasher_u32*
+// field and a wuffs_foo__bar__set_output_hasher setter: whether it is classy
+// and has a public coroutine method whose output gets hashed.
+func (g *gen) structHasOutputHasher(n *a.Struct) bool {
+	if !n.Classy() {
+		return false
+	}
+	for _, file := range g.files {
+		for _, tld := range file.TopLevelDecls() {
+			if tld.Kind() != a.KFunc {
+				continue
+			}
+			o := tld.AsFunc()
+			if (o.Receiver() == n.QID()) && (g.funcOutputHasherKind(o) != outputHasherNone) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *gen) writeSeekIOPositionSignature(b *buffer, n *a.Struct) error {
+	structName := n.QID().Str(g.tm)
+	b.printf("uint64_t\n%s%s__seek_io_position(\n    const %s%s* self)",
+		g.pkgPrefix, structName, g.pkgPrefix, structName)
+	return nil
+}
+
+// structHasSeek returns whether n gets a seek_io_position field and a
+// wuffs_foo__bar__seek_io_position accessor: whether it is classy and one of
+// its methods calls the io_reader.seek? built-in method. That accessor returns
+// the position most recently passed to seek?, which is where the caller should
+// seek the source to after a "$mispositioned read" suspension.
+func (g *gen) structHasSeek(n *a.Struct) bool {
+	if !n.Classy() {
+		return false
+	}
+	found := false
+	for _, file := range g.files {
+		for _, tld := range file.TopLevelDecls() {
+			if (tld.Kind() != a.KFunc) || (tld.AsFunc().Receiver() != n.QID()) {
+				continue
+			}
+			tld.Walk(func(o *a.Node) error {
+				if o.Kind() != a.KExpr {
+					return nil
+				}
+				x := o.AsExpr()
+				if (x.Operator() != a.ExprOperatorCall) || (x.LHS().AsExpr().Ident() != t.IDSeek) {
+					return nil
+				}
+				if typ := x.LHS().AsExpr().LHS().AsExpr().MType(); (typ != nil) && typ.IsIOType() &&
+					(typ.QID()[1] == t.IDIOReader) {
+					found = true
+				}
+				return nil
+			})
+		}
+	}
+	return found
+}
+
+func (g *gen) writeSetLimitsSignature(b *buffer, n *a.Struct) error {
+	structName := n.QID().Str(g.tm)
+	b.printf("wuffs_base__empty_struct\n%s%s__set_limits(\n    %s%s* self,\n    const wuffs_base__image_decoder_limits* limits)",
+		g.pkgPrefix, structName, g.pkgPrefix, structName)
 	return nil
 }
 
+// structHasLimits returns whether n gets a wuffs_base__image_decoder_limits
+// field and a wuffs_foo__bar__set_limits setter: whether it is classy and
+// implements base.image_decoder. Those limits are enforced by the
+// decode_image_config and decode_frame_config methods. See funcLimitsKind.
+func (g *gen) structHasLimits(n *a.Struct) bool {
+	if !n.Classy() {
+		return false
+	}
+	for _, impl := range n.Implements() {
+		if iQID := impl.AsTypeExpr().QID(); (iQID[0] == t.IDBase) && (iQID[1].Str(g.tm) == "image_decoder") {
+			return true
+		}
+	}
+	return false
+}
+
 func (g *gen) writeInitializerPrototype(b *buffer, n *a.Struct) error {
 	if !n.Classy() {
 		return nil
@@ -1386,6 +2006,34 @@ func (g *gen) writeInitializerPrototype(b *buffer, n *a.Struct) error {
 			return err
 		}
 		b.writes(";\n\n")
+
+		if g.structHasMetrics(n) {
+			if err := g.writeMetricsSignature(b, n); err != nil {
+				return err
+			}
+			b.writes(";\n\n")
+		}
+
+		if g.structHasOutputHasher(n) {
+			if err := g.writeSetOutputHasherSignature(b, n); err != nil {
+				return err
+			}
+			b.writes(";\n\n")
+		}
+
+		if g.structHasSeek(n) {
+			if err := g.writeSeekIOPositionSignature(b, n); err != nil {
+				return err
+			}
+			b.writes(";\n\n")
+		}
+
+		if g.structHasLimits(n) {
+			if err := g.writeSetLimitsSignature(b, n); err != nil {
+				return err
+			}
+			b.writes(";\n\n")
+		}
 	}
 	return nil
 }
@@ -1444,6 +2092,12 @@ func (g *gen) writeInitializerImpl(b *buffer, n *a.Struct) error {
 				continue
 			}
 			hasChoosy = true
+			if len(o.Choices()) > 0 {
+				if err := g.writeChoose(b, n.QID(), o.FuncName(), o.Choices(), true); err != nil {
+					return err
+				}
+				continue
+			}
 			b.printf("self->private_impl.choosy_%s = &%s__choosy_default;\n",
 				o.FuncName().Str(g.tm), g.funcCName(o))
 		}
@@ -1470,7 +2124,15 @@ func (g *gen) writeInitializerImpl(b *buffer, n *a.Struct) error {
 			// See gen.packagePrefix for a related TODO with otherPkg.
 			otherPkg := g.tm.ByID(qid[0])
 			prefix = "wuffs_" + otherPkg + "__"
-		} else if g.structMap[qid] == nil {
+		} else if s := g.structMap[qid]; s == nil {
+			continue
+		} else if s.Union() {
+			// A union has no initializer, only a tag. Unlike the rest of the
+			// private_data, the tag is reset even when leaving internal
+			// buffers uninitialized.
+			if f.PrivateData() {
+				b.printf("self->private_data.%s%s.private_impl.tag = 0;\n", fPrefix, f.Name().Str(g.tm))
+			}
 			continue
 		}
 
@@ -1482,10 +2144,18 @@ func (g *gen) writeInitializerImpl(b *buffer, n *a.Struct) error {
 		b.printf("}\n")
 	}
 
+	if g.runtimetables {
+		for _, name := range g.runtimeTableNames(n.QID()) {
+			if err := g.writeRuntimeTableInitialization(b, name); err != nil {
+				return err
+			}
+		}
+	}
+
 	b.writes("self->private_impl.magic = WUFFS_BASE__MAGIC;\n")
 	for _, impl := range n.Implements() {
 		qid := impl.AsTypeExpr().QID()
-		iName := fmt.Sprintf("wuffs_%s__%s", qid[0].Str(g.tm), qid[1].Str(g.tm))
+		iName := g.interfaceCName(qid)
 		b.printf("self->private_impl.vtable_for__%s.vtable_name =\n"+
 			"%s__vtable_name;\n", iName, iName)
 		b.printf("self->private_impl.vtable_for__%s.function_pointers =\n"+
@@ -1500,14 +2170,20 @@ func (g *gen) writeInitializerImpl(b *buffer, n *a.Struct) error {
 		if err := g.writeAllocSignature(b, n); err != nil {
 			return err
 		}
+		b.printf(" {\nreturn %s%s__alloc_with(NULL);\n}\n\n", g.pkgPrefix, structName)
+
+		if err := g.writeAllocWithSignature(b, n); err != nil {
+			return err
+		}
 		b.writes(" {\n")
-		b.printf("%s%s* x =\n(%s%s*)(calloc(sizeof(%s%s), 1));\n",
+		b.printf("%s%s* x =\n(%s%s*)(wuffs_base__mem__allocator__zalloc(\n"+
+			"allocator, sizeof(%s%s)));\n",
 			g.pkgPrefix, structName, g.pkgPrefix, structName, g.pkgPrefix, structName)
 		b.writes("if (!x) {\nreturn NULL;\n}\n")
 		b.printf("if (%s%s__initialize(\nx, sizeof(%s%s), "+
 			"WUFFS_VERSION, WUFFS_INITIALIZE__ALREADY_ZEROED).repr) {\n",
 			g.pkgPrefix, structName, g.pkgPrefix, structName)
-		b.writes("free(x);\nreturn NULL;\n}\n")
+		b.writes("wuffs_base__mem__allocator__free(allocator, x);\nreturn NULL;\n}\n")
 		b.writes("return x;\n")
 		b.writes("}\n\n")
 
@@ -1515,6 +2191,62 @@ func (g *gen) writeInitializerImpl(b *buffer, n *a.Struct) error {
 			return err
 		}
 		b.printf(" {\nreturn sizeof(%s%s);\n}\n\n", g.pkgPrefix, structName)
+
+		if g.structHasMetrics(n) {
+			if err := g.writeMetricsSignature(b, n); err != nil {
+				return err
+			}
+			b.writes(" {\n")
+			b.writes("if (!self || ((self->private_impl.magic != WUFFS_BASE__MAGIC) &&\n" +
+				"(self->private_impl.magic != WUFFS_BASE__DISABLED))) {\n")
+			b.writes("wuffs_base__metrics z;\n")
+			b.writes("memset(&z, 0, sizeof(z));\n")
+			b.writes("return z;\n")
+			b.writes("}\n")
+			b.writes("return self->private_impl.metrics;\n")
+			b.writes("}\n\n")
+		}
+
+		if g.structHasOutputHasher(n) {
+			if err := g.writeSetOutputHasherSignature(b, n); err != nil {
+				return err
+			}
+			b.writes(" {\n")
+			b.writes("if (self && (self->private_impl.magic == WUFFS_BASE__MAGIC)) {\n")
+			b.writes("self->private_impl.output_hasher = h;\n")
+			b.writes("}\n")
+			b.writes("return wuffs_base__make_empty_struct();\n")
+			b.writes("}\n\n")
+		}
+
+		if g.structHasSeek(n) {
+			if err := g.writeSeekIOPositionSignature(b, n); err != nil {
+				return err
+			}
+			b.writes(" {\n")
+			b.writes("if (!self || ((self->private_impl.magic != WUFFS_BASE__MAGIC) &&\n" +
+				"(self->private_impl.magic != WUFFS_BASE__DISABLED))) {\n")
+			b.writes("return 0;\n")
+			b.writes("}\n")
+			b.writes("return self->private_impl.seek_io_position;\n")
+			b.writes("}\n\n")
+		}
+
+		if g.structHasLimits(n) {
+			if err := g.writeSetLimitsSignature(b, n); err != nil {
+				return err
+			}
+			b.writes(" {\n")
+			b.writes("if (self && (self->private_impl.magic == WUFFS_BASE__MAGIC)) {\n")
+			b.writes("if (limits) {\n")
+			b.writes("self->private_impl.limits = *limits;\n")
+			b.writes("} else {\n")
+			b.writes("memset(&self->private_impl.limits, 0, sizeof(self->private_impl.limits));\n")
+			b.writes("}\n")
+			b.writes("}\n")
+			b.writes("return wuffs_base__make_empty_struct();\n")
+			b.writes("}\n\n")
+		}
 	}
 	return nil
 }
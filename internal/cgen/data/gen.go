@@ -106,6 +106,8 @@ func genData(out *bytes.Buffer) error {
 		{"../auxiliary/image.hh", "AuxImageHh"},
 		{"../auxiliary/json.cc", "AuxJsonCc"},
 		{"../auxiliary/json.hh", "AuxJsonHh"},
+		{"../auxiliary/rac.cc", "AuxRacCc"},
+		{"../auxiliary/rac.hh", "AuxRacHh"},
 	}
 
 	prefixAfterEditing := []byte("// After editing this file,")
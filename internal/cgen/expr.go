@@ -30,7 +30,7 @@ func (g *gen) writeExpr(b *buffer, n *a.Expr, sideEffectsOnly bool, depth uint32
 	depth++
 
 	if cv := n.ConstValue(); cv != nil {
-		if typ := n.MType(); typ.IsNumTypeOrIdeal() {
+		if typ := n.MType(); typ.IsNumTypeOrIdeal() || g.isEnumType(typ) {
 			b.writes(cv.String())
 			if cv.Cmp(maxInt64) > 0 {
 				b.writeb('u')
@@ -89,6 +89,13 @@ func (g *gen) writeExprOther(b *buffer, n *a.Expr, sideEffectsOnly bool, depth u
 		} else if c, ok := g.scalarConstsMap[t.QID{0, n.Ident()}]; ok {
 			b.writes(c.Value().ConstValue().String())
 
+		} else if rt, ok := g.runtimeTables[ident]; ok && g.runtimetables && n.GlobalIdent() {
+			if g.currFunk.astFunc.Receiver() != rt.maker.Receiver() {
+				return fmt.Errorf("runtime table %s used outside of its %s struct",
+					ident.Str(g.tm), rt.maker.Receiver().Str(g.tm))
+			}
+			b.printf("self->private_data.%s%s", cPrefix, ident.Str(g.tm))
+
 		} else {
 			if n.GlobalIdent() {
 				b.writes(g.PKGPREFIX)
@@ -449,12 +456,14 @@ func (g *gen) writeExprUserDefinedCall(b *buffer, n *a.Expr, depth uint32) error
 func (g *gen) writeCTypeName(b *buffer, n *a.TypeExpr, varNamePrefix string, varName string) error {
 	// It may help to refer to http://unixwiz.net/techtips/reading-cdecl.html
 
-	// TODO: fix this, allow slices of all types, not just of base.u8's. Also
-	// allow arrays of slices, slices of pointers, etc.
+	// TODO: fix this, allow slices of all types, not just of base.u8's (and
+	// the other unsigned integers). Also allow arrays of slices, slices of
+	// pointers, etc.
 	if n.IsSliceType() {
 		o := n.Inner()
-		if o.Decorator() == 0 && o.QID() == (t.QID{t.IDBase, t.IDU8}) && !o.IsRefined() {
-			b.writes("wuffs_base__slice_u8")
+		if o.Decorator() == 0 && o.QID()[0] == t.IDBase && !o.IsRefined() &&
+			(o.QID()[1] == t.IDU8 || o.QID()[1] == t.IDU16 || o.QID()[1] == t.IDU32 || o.QID()[1] == t.IDU64) {
+			b.printf("wuffs_base__slice_%s", o.QID()[1].Str(g.tm))
 			if varNamePrefix != "" {
 				b.writeb(' ')
 				b.writes(varNamePrefix)
@@ -570,6 +579,8 @@ var cTypeNames = [...]string{
 	t.IDU16:  "uint16_t",
 	t.IDU32:  "uint32_t",
 	t.IDU64:  "uint64_t",
+	t.IDF32:  "float",
+	t.IDF64:  "double",
 	t.IDBool: "bool",
 
 	t.IDIOReader:    "wuffs_base__io_buffer*",
@@ -587,6 +598,13 @@ var cTypeNames = [...]string{
 	t.IDARMNeonU32x4: "uint32x4_t",
 	t.IDARMNeonU64x2: "uint64x2_t",
 	t.IDX86M128I:     "__m128i",
+
+	t.IDRISCVRVVU8M1:  "vuint8m1_t",
+	t.IDRISCVRVVU16M2: "vuint16m2_t",
+	t.IDRISCVRVVU32M1: "vuint32m1_t",
+	t.IDRISCVRVVU32M4: "vuint32m4_t",
+
+	t.IDWASMV128: "v128_t",
 }
 
 const noSuchCOperator = " no_such_C_operator "
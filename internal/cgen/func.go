@@ -15,6 +15,7 @@
 package cgen
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"strconv"
@@ -30,6 +31,12 @@ type funk struct {
 	bBodySuspend buffer
 	bEpilogue    buffer
 
+	// bClosedSrcPrologue and bClosedSrcBodyResume replace bPrologue and
+	// bBodyResume in the function's non-resuming __closed_src variant. See
+	// funcHasClosedSrcFastPath.
+	bClosedSrcPrologue   buffer
+	bClosedSrcBodyResume buffer
+
 	astFunc       *a.Func
 	cName         string
 	coroID        uint32
@@ -39,6 +46,7 @@ type funk struct {
 	varResumables     map[t.ID]bool
 	derivedVars       map[t.ID]struct{}
 	jumpTargets       map[a.Loop]string
+	ioChecksums       []ioChecksum
 	coroSuspPoint     uint32
 	ioBinds           uint32
 	tempW             uint32
@@ -46,6 +54,33 @@ type funk struct {
 	usesEmptyIOBuffer bool
 	usesScratch       bool
 	hasGotoOK         bool
+	hasYieldNote      bool
+}
+
+// ioChecksum is the state of an io_checksum block, whose hasher is updated
+// with the bytes read or written since a mark: at the end of the block, on
+// return and on suspension. Resuming a coroutine resets the mark.
+type ioChecksum struct {
+	markName string // The C variable holding the mark.
+	position string // The C expression for the I/O position, relative to io0.
+	update   string // The C statement that updates the hasher.
+
+	// spLo and spHi are the inclusive range of the coroutine suspension
+	// points within the block. The range is empty if spLo > spHi.
+	spLo uint32
+	spHi uint32
+
+	// active is whether code generation is within the block.
+	active bool
+}
+
+// within returns the C condition for the coroutine suspension point being
+// within the io_checksum block.
+func (c *ioChecksum) within() string {
+	if c.spLo == c.spHi {
+		return fmt.Sprintf("coro_susp_point == %d", c.spLo)
+	}
+	return fmt.Sprintf("(%d <= coro_susp_point) && (coro_susp_point <= %d)", c.spLo, c.spHi)
 }
 
 func (k *funk) jumpTarget(tm *t.Map, n a.Loop) (string, error) {
@@ -85,9 +120,12 @@ const (
 	wfsCFuncPtrField       = 3
 	wfsCFuncPtrFieldChoosy = 4
 	wfsCFuncPtrType        = 5
+	wfsCDeclClosedSrc      = 6
 )
 
 func (g *gen) writeFuncSignature(b *buffer, n *a.Func, wfs uint32) error {
+	annotate := g.annotations && (wfs == wfsCDecl) && n.Public() && g.inPrototype
+
 	switch wfs {
 	case wfsCDecl:
 		if n.Public() {
@@ -96,7 +134,7 @@ func (g *gen) writeFuncSignature(b *buffer, n *a.Func, wfs uint32) error {
 			b.writes("static ")
 		}
 
-	case wfsCDeclChoosy:
+	case wfsCDeclChoosy, wfsCDeclClosedSrc:
 		b.writes("static ")
 
 	case wfsCppDecl:
@@ -117,7 +155,7 @@ func (g *gen) writeFuncSignature(b *buffer, n *a.Func, wfs uint32) error {
 	}
 
 	switch wfs {
-	case wfsCDecl, wfsCDeclChoosy:
+	case wfsCDecl, wfsCDeclChoosy, wfsCDeclClosedSrc:
 		b.writes("\n")
 	case wfsCppDecl:
 		b.writes("\n  ")
@@ -127,10 +165,12 @@ func (g *gen) writeFuncSignature(b *buffer, n *a.Func, wfs uint32) error {
 
 	comma := false
 	switch wfs {
-	case wfsCDecl, wfsCDeclChoosy:
+	case wfsCDecl, wfsCDeclChoosy, wfsCDeclClosedSrc:
 		b.writes(g.funcCName(n))
 		if wfs == wfsCDeclChoosy {
 			b.writes("__choosy_default")
+		} else if wfs == wfsCDeclClosedSrc {
+			b.writes("__closed_src")
 		}
 		b.writeb('(')
 		if r := n.Receiver(); !r.IsZero() {
@@ -138,7 +178,11 @@ func (g *gen) writeFuncSignature(b *buffer, n *a.Func, wfs uint32) error {
 			if n.Effect().Pure() {
 				b.writes("const ")
 			}
-			b.printf("%s%s* self", g.pkgPrefix, r[1].Str(g.tm))
+			b.printf("%s%s* ", g.pkgPrefix, r[1].Str(g.tm))
+			if annotate {
+				b.writes("WUFFS_BASE__NONNULL ")
+			}
+			b.writes("self")
 			comma = true
 		}
 
@@ -188,6 +232,9 @@ func (g *gen) writeFuncSignature(b *buffer, n *a.Func, wfs uint32) error {
 		varNamePrefix, varName := "", ""
 		if wfs != wfsCFuncPtrType {
 			varNamePrefix, varName = aPrefix, o.Name().Str(g.tm)
+			if annotate {
+				varNamePrefix = nullabilityQualifier(o.XType()) + aPrefix
+			}
 		}
 		if err := g.writeCTypeName(b, o.XType(), varNamePrefix, varName); err != nil {
 			return err
@@ -201,15 +248,44 @@ func (g *gen) writeFuncSignature(b *buffer, n *a.Func, wfs uint32) error {
 	return nil
 }
 
+// nullabilityQualifier returns the "WUFFS_BASE__ETC " qualifier for a
+// function argument of type typ, or "" if typ is not a C pointer type.
+func nullabilityQualifier(typ *a.TypeExpr) string {
+	switch typ.Decorator() {
+	case t.IDPtr:
+		return "WUFFS_BASE__NONNULL "
+	case t.IDNptr:
+		return "WUFFS_BASE__NULLABLE "
+	case 0:
+		if qid := typ.QID(); qid[0] == t.IDBase {
+			switch qid[1] {
+			case t.IDIOReader, t.IDIOWriter, t.IDTokenReader, t.IDTokenWriter:
+				return "WUFFS_BASE__NONNULL "
+			}
+		}
+	}
+	return ""
+}
+
 func (g *gen) writeFuncPrototype(b *buffer, n *a.Func) error {
 	caMacro, _, _, err := cpuArchCNames(n.Asserts())
 	if err != nil {
 		return err
 	}
+	if g.annotations && n.Public() && !n.Receiver().IsZero() {
+		if n.Effect().Pure() {
+			b.writes("// GUARDED_BY(self): needs shared (read-only) access to *self.\n")
+		} else {
+			b.writes("// GUARDED_BY(self): needs exclusive access to *self.\n")
+		}
+	}
 	if caMacro != "" {
 		b.printf("#if defined(WUFFS_BASE__CPU_ARCH__%s)\n", caMacro)
 	}
-	if err := g.writeFuncSignature(b, n, wfsCDecl); err != nil {
+	g.inPrototype = true
+	err = g.writeFuncSignature(b, n, wfsCDecl)
+	g.inPrototype = false
+	if err != nil {
 		return err
 	}
 	b.writes(";\n")
@@ -237,6 +313,11 @@ func (g *gen) writeFuncImpl(b *buffer, n *a.Func) error {
 		b.printf("// ‼ WUFFS MULTI-FILE SECTION +%s\n", caName)
 	}
 	b.printf("// -------- func %s.%s\n\n", g.pkgName, n.QQID().Str(g.tm))
+	if len(k.bClosedSrcBodyResume) > 0 {
+		if err := g.writeFuncImplClosedSrc(b, n, &k); err != nil {
+			return err
+		}
+	}
 	if caMacro != "" {
 		b.printf("#if defined(WUFFS_BASE__CPU_ARCH__%s)\n", caMacro)
 	}
@@ -312,10 +393,23 @@ func (g *gen) gatherFuncImpl(_ *buffer, n *a.Func) error {
 		return err
 	}
 
-	if err := g.writeFuncImplPrologue(&g.currFunk.bPrologue); err != nil {
+	// The body may have already written to bPrologue, e.g. to declare an
+	// empty_io_buffer, and the __closed_src variant's prologue needs that too.
+	closedSrc := g.funcHasClosedSrcFastPath(n)
+	if closedSrc {
+		g.currFunk.bClosedSrcPrologue = append(buffer(nil), g.currFunk.bPrologue...)
+		if err := g.writeFuncImplPrologue(&g.currFunk.bClosedSrcPrologue, true); err != nil {
+			return err
+		}
+		if err := g.writeFuncImplClosedSrcBodyResume(&g.currFunk.bClosedSrcBodyResume); err != nil {
+			return err
+		}
+	}
+
+	if err := g.writeFuncImplPrologue(&g.currFunk.bPrologue, false); err != nil {
 		return err
 	}
-	if err := g.writeFuncImplBodyResume(&g.currFunk.bBodyResume); err != nil {
+	if err := g.writeFuncImplBodyResume(&g.currFunk.bBodyResume, closedSrc); err != nil {
 		return err
 	}
 	if err := g.writeFuncImplBodySuspend(&g.currFunk.bBodySuspend); err != nil {
@@ -332,11 +426,11 @@ func (g *gen) gatherFuncImpl(_ *buffer, n *a.Func) error {
 	return nil
 }
 
-func writeOutParamZeroValue(b *buffer, tm *t.Map, typ *a.TypeExpr) error {
+func (g *gen) writeOutParamZeroValue(b *buffer, typ *a.TypeExpr) error {
 	if typ == nil {
 		b.writes("wuffs_base__make_empty_struct()")
 		return nil
-	} else if typ.IsNumType() {
+	} else if typ.IsNumType() || typ.IsFloatType() || g.isEnumType(typ) {
 		b.writes("0")
 		return nil
 	} else if typ.IsSliceType() {
@@ -346,6 +440,9 @@ func writeOutParamZeroValue(b *buffer, tm *t.Map, typ *a.TypeExpr) error {
 		}
 	} else if (typ.Decorator() == 0) && (typ.QID()[0] == t.IDBase) {
 		switch typ.QID()[1] {
+		case t.IDBool:
+			b.writes("false")
+			return nil
 		case t.IDRangeIEU32:
 			b.writes("wuffs_base__utility__empty_range_ie_u32()")
 			return nil
@@ -366,17 +463,17 @@ func writeOutParamZeroValue(b *buffer, tm *t.Map, typ *a.TypeExpr) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("internal error: cannot write the zero value of type %q", typ.Str(tm))
+	return fmt.Errorf("internal error: cannot write the zero value of type %q", typ.Str(g.tm))
 }
 
-func writeFuncImplSelfMagicCheck(b *buffer, tm *t.Map, f *a.Func) error {
+func (g *gen) writeFuncImplSelfMagicCheck(b *buffer, f *a.Func) error {
 	returnsStatus := f.Effect().Coroutine() ||
 		((f.Out() != nil) && f.Out().IsStatus())
 
 	b.writes("  if (!self) {\n    return ")
 	if returnsStatus {
 		b.writes("wuffs_base__make_status(wuffs_base__error__bad_receiver)")
-	} else if err := writeOutParamZeroValue(b, tm, f.Out()); err != nil {
+	} else if err := g.writeOutParamZeroValue(b, f.Out()); err != nil {
 		return err
 	}
 	b.writes(";\n  }\n")
@@ -393,7 +490,7 @@ func writeFuncImplSelfMagicCheck(b *buffer, tm *t.Map, f *a.Func) error {
 			"        (self->private_impl.magic == WUFFS_BASE__DISABLED)\n" +
 			"            ? wuffs_base__error__disabled_by_previous_error\n" +
 			"            : wuffs_base__error__initialize_not_called)")
-	} else if err := writeOutParamZeroValue(b, tm, f.Out()); err != nil {
+	} else if err := g.writeOutParamZeroValue(b, f.Out()); err != nil {
 		return err
 	}
 
@@ -401,19 +498,22 @@ func writeFuncImplSelfMagicCheck(b *buffer, tm *t.Map, f *a.Func) error {
 	return nil
 }
 
-func (g *gen) writeFuncImplPrologue(b *buffer) error {
+// writeFuncImplPrologue writes the function's prologue. If closedSrc, it is
+// the prologue of the __closed_src variant, which is only called after the
+// regular function has already checked its receiver and arguments.
+func (g *gen) writeFuncImplPrologue(b *buffer, closedSrc bool) error {
 	oldLenB := len(*b)
 
 	// Check the initialized/disabled state and the "self" arg.
-	if g.currFunk.astFunc.Public() && !g.currFunk.astFunc.Receiver().IsZero() {
-		if err := writeFuncImplSelfMagicCheck(b, g.tm, g.currFunk.astFunc); err != nil {
+	if !closedSrc && g.currFunk.astFunc.Public() && !g.currFunk.astFunc.Receiver().IsZero() {
+		if err := g.writeFuncImplSelfMagicCheck(b, g.currFunk.astFunc); err != nil {
 			return err
 		}
 	}
 
 	// For public functions, check (at runtime) the other args for bounds and
 	// null-ness. For private functions, those checks are done at compile time.
-	if g.currFunk.astFunc.Public() {
+	if !closedSrc && g.currFunk.astFunc.Public() {
 		if err := g.writeFuncImplArgChecks(b, g.currFunk.astFunc); err != nil {
 			return err
 		}
@@ -430,12 +530,33 @@ func (g *gen) writeFuncImplPrologue(b *buffer) error {
 		}
 	}
 
+	if g.currFunk.astFunc.Public() && !g.currFunk.astFunc.Receiver().IsZero() &&
+		(g.currFunk.astFunc.FuncName().Str(g.tm) == "set_quirk_enabled") {
+		g.writeTrace(b, "QUIRK", "NULL", aPrefix+"quirk", aPrefix+"enabled")
+	}
+
 	if g.currFunk.astFunc.Effect().Coroutine() ||
 		(g.currFunk.returnsStatus && (len(g.currFunk.derivedVars) > 0)) {
 		// TODO: rename the "status" variable to "ret"?
 		b.printf("wuffs_base__status status = wuffs_base__make_status(NULL);\n")
 	}
 
+	if g.currFunkHasMetrics() {
+		if err := g.writeMetricsPrologue(b); err != nil {
+			return err
+		}
+	}
+
+	if g.funcLimitsKind(g.currFunk.astFunc) == limitsImageConfig {
+		g.writeLimitsPrologue(b)
+	}
+
+	if g.funcOutputHasherKind(g.currFunk.astFunc) == outputHasherBytes {
+		b.writes("#if defined(WUFFS_CONFIG__OUTPUT_HASHER)\n")
+		b.printf("size_t output_hasher_wi0 = %sdst->meta.wi;\n", aPrefix)
+		b.writes("#endif  // defined(WUFFS_CONFIG__OUTPUT_HASHER)\n")
+	}
+
 	if oldLenB != len(*b) {
 		b.writes("\n")
 	}
@@ -445,6 +566,9 @@ func (g *gen) writeFuncImplPrologue(b *buffer) error {
 	if err := g.writeVars(b, &g.currFunk, false); err != nil {
 		return err
 	}
+	for _, o := range g.currFunk.ioChecksums {
+		b.printf("uint64_t %s = 0;\n", o.markName)
+	}
 	if oldLenB != len(*b) {
 		b.writes("\n")
 	}
@@ -463,12 +587,18 @@ func (g *gen) writeFuncImplPrologue(b *buffer) error {
 	return nil
 }
 
-func (g *gen) writeFuncImplBodyResume(b *buffer) error {
+func (g *gen) writeFuncImplBodyResume(b *buffer, closedSrc bool) error {
 	if g.currFunk.coroSuspPoint > 0 {
 		// TODO: don't hard-code [0], and allow recursive coroutines.
 		b.printf("uint32_t coro_susp_point = self->private_impl.%s%s[0];\n",
 			pPrefix, g.currFunk.astFunc.FuncName().Str(g.tm))
 
+		if closedSrc {
+			if err := g.writeClosedSrcDispatch(b); err != nil {
+				return err
+			}
+		}
+
 		resumeBuffer := buffer{}
 		if err := g.writeResumeSuspend(&resumeBuffer, &g.currFunk, false); err != nil {
 			return err
@@ -479,15 +609,151 @@ func (g *gen) writeFuncImplBodyResume(b *buffer) error {
 			b.writes("}\n")
 		}
 
+		// Resuming within an io_checksum block resets its mark, as the I/O
+		// buffer may have changed since the coroutine suspended.
+		for _, o := range g.currFunk.ioChecksums {
+			if o.spLo <= o.spHi {
+				b.printf("if (%s) {\n%s = %s;\n}\n", o.within(), o.markName, o.position)
+			}
+		}
+
+		// Optionally jump straight to the resumption point, via a table of
+		// label addresses, before falling back to the coroutine switch. The
+		// coro_susp_point_etc labels are defined by the
+		// WUFFS_BASE__COROUTINE_SUSPENSION_POINT_ETC macros.
+		n := g.currFunk.coroSuspPoint
+		b.writes("#if defined(WUFFS_BASE__COROUTINE_COMPUTED_GOTO)\n")
+		b.printf("if (coro_susp_point <= %d) {\n", n)
+		b.writes("WUFFS_BASE__COROUTINE_COMPUTED_GOTO_BEGIN\n")
+		b.printf("static void* const coro_susp_labels[%d] = {\n", n+1)
+		for i := uint32(0); i <= n; i++ {
+			b.printf("&&coro_susp_point_%d,", i)
+			if ((i % 4) == 3) || (i == n) {
+				b.writes("\n")
+			} else {
+				b.writes(" ")
+			}
+		}
+		b.writes("};\n")
+		b.writes("goto* coro_susp_labels[coro_susp_point];\n")
+		b.writes("WUFFS_BASE__COROUTINE_COMPUTED_GOTO_END\n")
+		b.writes("}\n")
+		b.writes("#endif  // defined(WUFFS_BASE__COROUTINE_COMPUTED_GOTO)\n\n")
+
 		// Generate a coroutine switch similiar to the technique in
 		// https://www.chiark.greenend.org.uk/~sgtatham/coroutines.html
 		//
 		// The matching } is written below. See "Close the coroutine switch".
 		b.writes("switch (coro_susp_point) {\nWUFFS_BASE__COROUTINE_SUSPENSION_POINT_0;\n\n")
+		if g.currFunkIsPublicDecodeFrame() {
+			g.writeTrace(b, "FRAME_BEGIN", "NULL", "0", "0")
+			b.writes("\n")
+		}
+	}
+	return nil
+}
+
+// funcHasClosedSrcFastPath returns whether n, which must be the current
+// function, also gets a __closed_src variant: a copy of the function that
+// cannot resume a suspended coroutine, so that its suspension points need not
+// be switch cases (or computed goto labels). Without those jump targets in the
+// middle of its loops, the C compiler can optimize the function as ordinary
+// code. Fresh (not resuming) calls to n whose io_reader args are all closed
+// are dispatched to the variant, when WUFFS_CONFIG__CLOSED_SRC_FAST_PATH is
+// defined. Such calls are unlikely to suspend, but if they do, the variant
+// saves the coroutine state exactly as the regular function does, and the
+// next call resumes in the regular function.
+func (g *gen) funcHasClosedSrcFastPath(n *a.Func) bool {
+	if (g.currFunk.coroSuspPoint == 0) || n.Receiver().IsZero() || n.Choosy() || (len(n.Asserts()) > 0) {
+		return false
+	}
+	for _, o := range n.In().Fields() {
+		if typ := o.AsField().XType(); (typ.Decorator() == 0) &&
+			(typ.QID() == t.QID{t.IDBase, t.IDIOReader}) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeClosedSrcDispatch writes the regular function's call to its
+// __closed_src variant. See funcHasClosedSrcFastPath.
+func (g *gen) writeClosedSrcDispatch(b *buffer) error {
+	n := g.currFunk.astFunc
+	b.writes("#if defined(WUFFS_CONFIG__CLOSED_SRC_FAST_PATH)\n")
+	b.writes("if (!coro_susp_point")
+	for _, o := range n.In().Fields() {
+		o := o.AsField()
+		if typ := o.XType(); (typ.Decorator() == 0) &&
+			(typ.QID() == t.QID{t.IDBase, t.IDIOReader}) {
+			name := aPrefix + o.Name().Str(g.tm)
+			b.printf(" && %s && %s->meta.closed", name, name)
+		}
+	}
+	b.printf(") {\nreturn %s__closed_src(self", g.funcCName(n))
+	for _, o := range n.In().Fields() {
+		b.printf(", %s%s", aPrefix, o.AsField().Name().Str(g.tm))
 	}
+	b.writes(");\n}\n")
+	b.writes("#endif  // defined(WUFFS_CONFIG__CLOSED_SRC_FAST_PATH)\n\n")
 	return nil
 }
 
+// writeFuncImplClosedSrcBodyResume is like writeFuncImplBodyResume but for
+// the __closed_src variant, which always starts at the top of the function.
+// Instead of a coroutine switch, it opens a plain block.
+func (g *gen) writeFuncImplClosedSrcBodyResume(b *buffer) error {
+	b.writes("uint32_t coro_susp_point = 0;\n\n")
+	b.writes("{\n")
+	if g.currFunkIsPublicDecodeFrame() {
+		g.writeTrace(b, "FRAME_BEGIN", "NULL", "0", "0")
+		b.writes("\n")
+	}
+	return nil
+}
+
+// writeFuncImplClosedSrc writes n's __closed_src variant. See
+// funcHasClosedSrcFastPath.
+func (g *gen) writeFuncImplClosedSrc(b *buffer, n *a.Func, k *funk) error {
+	b.writes("#if defined(WUFFS_CONFIG__CLOSED_SRC_FAST_PATH)\n")
+	if err := g.writeFuncSignature(b, n, wfsCDeclClosedSrc); err != nil {
+		return err
+	}
+	b.writes(" {\n")
+	b.writex(k.bClosedSrcPrologue)
+	b.writex(k.bClosedSrcBodyResume)
+	// The WUFFS_BASE__COROUTINE_NO_RESUME_POINT_ETC macros are like the
+	// WUFFS_BASE__COROUTINE_SUSPENSION_POINT_ETC macros minus the case labels.
+	b.writex(bytes.ReplaceAll(k.bBody,
+		[]byte("WUFFS_BASE__COROUTINE_SUSPENSION_POINT"),
+		[]byte("WUFFS_BASE__COROUTINE_NO_RESUME_POINT")))
+	b.writex(k.bBodySuspend)
+	b.writex(k.bEpilogue)
+	b.writes("}\n")
+	b.writes("#endif  // defined(WUFFS_CONFIG__CLOSED_SRC_FAST_PATH)\n\n")
+	return nil
+}
+
+// currFunkIsPublicDecodeFrame returns whether the current function is a
+// public decode_frame method, whose calls delimit frame boundaries for
+// WUFFS_TRACE.
+func (g *gen) currFunkIsPublicDecodeFrame() bool {
+	n := g.currFunk.astFunc
+	return n.Public() && n.Effect().Coroutine() && !n.Receiver().IsZero() &&
+		(n.FuncName().Str(g.tm) == "decode_frame")
+}
+
+// writeTrace writes a WUFFS_TRACE call for the current function. The
+// WUFFS_TRACE macro is discussed in base/fundamental-public.h.
+func (g *gen) writeTrace(b *buffer, event string, statusRepr string, value0 string, value1 string) {
+	receiver := "NULL"
+	if !g.currFunk.astFunc.Receiver().IsZero() {
+		receiver = "self"
+	}
+	b.printf("WUFFS_TRACE(WUFFS_BASE__TRACE_EVENT__%s, %s, \"%s\", %s, %s, %s);\n",
+		event, receiver, g.currFunk.cName, statusRepr, value0, value1)
+}
+
 func (g *gen) writeFuncImplBody(b *buffer) error {
 	for _, o := range g.currFunk.astFunc.Body() {
 		if err := g.writeStatement(b, o, 0); err != nil {
@@ -507,8 +773,23 @@ func (g *gen) writeFuncImplBodySuspend(b *buffer) error {
 			pPrefix, g.currFunk.astFunc.FuncName().Str(g.tm))
 		b.writes("goto exit;\n}\n\n") // Close the coroutine switch.
 
-		b.writes("goto suspend;\nsuspend:\n") // The goto avoids the "unused label" warning.
+		b.writes("goto suspend;\n")
 
+		if g.currFunk.hasYieldNote {
+			// A yielded note, unlike a returned note, resumes the coroutine.
+			b.writes("yield_note:\n")
+			b.printf("self->private_impl.%s%s[0] = coro_susp_point;\n",
+				pPrefix, g.currFunk.astFunc.FuncName().Str(g.tm))
+			b.printf("self->private_impl.active_coroutine = %d;\n", g.currFunk.coroID)
+			g.writeIOChecksumSuspendUpdates(b)
+			b.writes("goto suspend_resumables;\n")
+		}
+
+		b.writes("suspend:\n")
+		g.writeIOChecksumSuspendUpdates(b)
+		b.writes("if (wuffs_base__status__is_suspension(&status)) {\n")
+		g.writeTrace(b, "SUSPEND", "status.repr", "coro_susp_point", "0")
+		b.writes("}\n")
 		b.printf("self->private_impl.%s%s[0] = "+
 			"wuffs_base__status__is_suspension(&status) ? coro_susp_point : 0;\n",
 			pPrefix, g.currFunk.astFunc.FuncName().Str(g.tm))
@@ -516,6 +797,9 @@ func (g *gen) writeFuncImplBodySuspend(b *buffer) error {
 			b.printf("self->private_impl.active_coroutine = "+
 				"wuffs_base__status__is_suspension(&status) ? %d : 0;\n", g.currFunk.coroID)
 		}
+		if g.currFunk.hasYieldNote {
+			b.writes("suspend_resumables:\n")
+		}
 		if err := g.writeResumeSuspend(b, &g.currFunk, true); err != nil {
 			return err
 		}
@@ -527,6 +811,16 @@ func (g *gen) writeFuncImplBodySuspend(b *buffer) error {
 	return nil
 }
 
+// writeIOChecksumSuspendUpdates updates the hasher of any io_checksum block
+// that the coroutine is suspending within.
+func (g *gen) writeIOChecksumSuspendUpdates(b *buffer) {
+	for _, o := range g.currFunk.ioChecksums {
+		if o.spLo <= o.spHi {
+			b.printf("if (%s) {\n%s}\n", o.within(), o.update)
+		}
+	}
+}
+
 func (g *gen) writeFuncImplEpilogue(b *buffer) error {
 	epilogue := ""
 	if g.currFunk.astFunc.Effect().Coroutine() ||
@@ -534,6 +828,14 @@ func (g *gen) writeFuncImplEpilogue(b *buffer) error {
 
 		b.writes("goto exit;\nexit:\n") // The goto avoids the "unused label" warning.
 
+		if g.currFunkIsPublicDecodeFrame() {
+			b.writes("if (!wuffs_base__status__is_suspension(&status)) {\n")
+			g.writeTrace(b, "FRAME_END", "status.repr", "0", "0")
+			b.writes("}\n")
+		}
+
+		g.writeLimitsEpilogue(b)
+
 		if g.currFunk.astFunc.Public() {
 			epilogue = "if (wuffs_base__status__is_error(&status)) {\n" +
 				"self->private_impl.magic = WUFFS_BASE__DISABLED;\n}\n" +
@@ -559,10 +861,239 @@ func (g *gen) writeFuncImplEpilogue(b *buffer) error {
 		b.writes("\n")
 	}
 
+	if g.currFunkHasMetrics() {
+		if err := g.writeMetricsEpilogue(b); err != nil {
+			return err
+		}
+	}
+
+	if err := g.writeOutputHasherEpilogue(b); err != nil {
+		return err
+	}
+
 	b.writes(epilogue)
 	return nil
 }
 
+// currFunkHasMetrics returns whether the current function updates the
+// receiver's wuffs_base__metrics counters: whether it is a public coroutine
+// method. See base/fundamental-public.h for more discussion.
+func (g *gen) currFunkHasMetrics() bool {
+	n := g.currFunk.astFunc
+	return n.Public() && n.Effect().Coroutine() && !n.Receiver().IsZero()
+}
+
+// writeMetricsPrologue snapshots the receiver's counters and the I/O
+// arguments' positions.
+//
+// The function's epilogue overwrites (instead of adding to) the receiver's
+// counters, so that a public method that calls another public method on the
+// same receiver (e.g. decode_frame calling decode_frame_config) does not
+// count the same bytes, suspensions or errors twice.
+func (g *gen) writeMetricsPrologue(b *buffer) error {
+	b.writes("#if defined(WUFFS_CONFIG__METRICS)\n")
+	b.writes("wuffs_base__metrics metrics = self->private_impl.metrics;\n")
+	for _, o := range g.currFunk.astFunc.In().Fields() {
+		o := o.AsField()
+		if !o.XType().IsIOTokenType() {
+			continue
+		}
+		_, i1, _, _, err := g.derivedVarCNames(o.XType())
+		if err != nil {
+			return err
+		}
+		name := aPrefix + o.Name().Str(g.tm)
+		b.printf("size_t metrics_%s0 = %s->%s;\n", name, name, i1)
+	}
+	b.writes("#endif  // defined(WUFFS_CONFIG__METRICS)\n")
+	return nil
+}
+
+// writeMetricsEpilogue updates the receiver's counters. See
+// writeMetricsPrologue.
+func (g *gen) writeMetricsEpilogue(b *buffer) error {
+	b.writes("#if defined(WUFFS_CONFIG__METRICS)\n")
+	for _, o := range g.currFunk.astFunc.In().Fields() {
+		o := o.AsField()
+		if !o.XType().IsIOTokenType() {
+			continue
+		}
+		_, i1, _, isWriter, err := g.derivedVarCNames(o.XType())
+		if err != nil {
+			return err
+		}
+		counter := "num_bytes_read"
+		if o.XType().IsTokenType() {
+			if !isWriter {
+				continue
+			}
+			counter = "num_tokens_written"
+		} else if isWriter {
+			counter = "num_bytes_written"
+		}
+		name := aPrefix + o.Name().Str(g.tm)
+		b.printf("metrics.%s += (uint64_t)(%s->%s - metrics_%s0);\n", counter, name, i1, name)
+	}
+	b.writes("if (wuffs_base__status__is_error(&status)) {\n")
+	b.writes("metrics.num_errors++;\n")
+	b.writes("metrics.last_error = status;\n")
+	b.writes("} else if (wuffs_base__status__is_suspension(&status)) {\n")
+	b.writes("metrics.num_suspensions++;\n")
+	if g.currFunkIsPublicDecodeFrame() {
+		b.writes("} else if (wuffs_base__status__is_ok(&status)) {\n")
+		b.writes("metrics.num_frames_decoded++;\n")
+	}
+	b.writes("}\n")
+	b.writes("self->private_impl.metrics = metrics;\n")
+	b.writes("#endif  // defined(WUFFS_CONFIG__METRICS)\n")
+	return nil
+}
+
+const (
+	outputHasherNone   = 0
+	outputHasherBytes  = 1
+	outputHasherPixels = 2
+)
+
+// funcOutputHasherKind returns whether n feeds its output through the
+// receiver's output_hasher, and if so, whether that output is the bytes
+// written to an io_writer dst argument or the pixels written to a
+// pixel_buffer dst argument. See base/fundamental-public.h for more
+// discussion.
+//
+// The tell_me_more method's dst argument holds metadata, not the primary
+// output, so it is not hashed.
+func (g *gen) funcOutputHasherKind(n *a.Func) int {
+	if !n.Public() || !n.Effect().Coroutine() || n.Receiver().IsZero() {
+		return outputHasherNone
+	}
+	funcName := n.FuncName().Str(g.tm)
+	if funcName == "tell_me_more" {
+		return outputHasherNone
+	}
+	for _, o := range n.In().Fields() {
+		o := o.AsField()
+		if o.Name().Str(g.tm) != "dst" {
+			continue
+		}
+		typ := o.XType()
+		if typ.Decorator() == 0 {
+			if qid := typ.QID(); (qid[0] == t.IDBase) && (qid[1] == t.IDIOWriter) {
+				return outputHasherBytes
+			}
+		} else if (typ.Decorator() == t.IDPtr) && (funcName == "decode_frame") {
+			if qid := typ.Inner().QID(); (qid[0] == t.IDBase) && (qid[1] == t.IDPixelBuffer) &&
+				g.receiverHasFunc(n.Receiver(), "frame_dirty_rect") {
+				return outputHasherPixels
+			}
+		}
+		break
+	}
+	return outputHasherNone
+}
+
+func (g *gen) receiverHasFunc(receiver t.QID, funcName string) bool {
+	for _, file := range g.files {
+		for _, tld := range file.TopLevelDecls() {
+			if tld.Kind() != a.KFunc {
+				continue
+			}
+			o := tld.AsFunc()
+			if (o.Receiver() == receiver) && (o.FuncName().Str(g.tm) == funcName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeOutputHasherEpilogue feeds what the current function wrote to its dst
+// argument through the receiver's output_hasher, if any.
+//
+// Bytes are hashed on every return (including suspensions), as soon as they
+// are written, so that callers are free to drain or compact the io_buffer
+// between calls. Pixels are only hashed when decode_frame completes, once,
+// over the frame's dirty rectangle, as pixels can be written out of order
+// (e.g. for interlaced images) and over-written (e.g. for progressive ones).
+func (g *gen) writeOutputHasherEpilogue(b *buffer) error {
+	switch g.funcOutputHasherKind(g.currFunk.astFunc) {
+	case outputHasherBytes:
+		b.writes("#if defined(WUFFS_CONFIG__OUTPUT_HASHER)\n")
+		b.writes("if (self->private_impl.output_hasher) {\n")
+		b.printf("wuffs_base__hasher_u32__update_u32(\nself->private_impl.output_hasher,\n"+
+			"wuffs_base__make_slice_u8(\n%sdst->data.ptr + output_hasher_wi0,\n"+
+			"%sdst->meta.wi - output_hasher_wi0));\n", aPrefix, aPrefix)
+		b.writes("}\n")
+		b.writes("#endif  // defined(WUFFS_CONFIG__OUTPUT_HASHER)\n")
+	case outputHasherPixels:
+		b.writes("#if defined(WUFFS_CONFIG__OUTPUT_HASHER)\n")
+		b.writes("if (self->private_impl.output_hasher && wuffs_base__status__is_ok(&status)) {\n")
+		b.printf("wuffs_base__pixel_buffer__update_hasher_u32(\n%sdst,\n"+
+			"%s%s__frame_dirty_rect(self),\nself->private_impl.output_hasher);\n",
+			aPrefix, g.pkgPrefix, g.currFunk.astFunc.Receiver().Str(g.tm))
+		b.writes("}\n")
+		b.writes("#endif  // defined(WUFFS_CONFIG__OUTPUT_HASHER)\n")
+	}
+	return nil
+}
+
+const (
+	limitsNone        = 0
+	limitsImageConfig = 1
+	limitsFrameConfig = 2
+)
+
+// funcLimitsKind returns whether n enforces its receiver's
+// wuffs_base__image_decoder_limits, and if so, whether it checks the image
+// configuration (the decode_image_config method) or the frame count (the
+// decode_frame_config method). See base/image-public.h for more discussion.
+func (g *gen) funcLimitsKind(n *a.Func) int {
+	if !n.Public() || !n.Effect().Coroutine() || n.Receiver().IsZero() {
+		return limitsNone
+	} else if s := g.structMap[n.Receiver()]; (s == nil) || !g.structHasLimits(s) {
+		return limitsNone
+	}
+	switch n.FuncName().Str(g.tm) {
+	case "decode_image_config":
+		return limitsImageConfig
+	case "decode_frame_config":
+		return limitsFrameConfig
+	}
+	return limitsNone
+}
+
+// writeLimitsPrologue substitutes a local image_config for a nullptr dst
+// argument, if there are dimension limits to enforce. Decoders' own
+// decode_frame_config methods call decode_image_config with a nullptr dst,
+// and the image's dimensions should be checked either way.
+func (g *gen) writeLimitsPrologue(b *buffer) {
+	b.writes("wuffs_base__image_config limits_dst = wuffs_base__null_image_config();\n")
+	b.printf("if (!%sdst && (self->private_impl.limits.max_width ||\n"+
+		"self->private_impl.limits.max_height ||\n"+
+		"self->private_impl.limits.max_num_pixels)) {\n", aPrefix)
+	b.printf("%sdst = &limits_dst;\n", aPrefix)
+	b.writes("}\n")
+}
+
+// writeLimitsEpilogue replaces an OK status with a "#resource limit exceeded"
+// error if what the current function decoded exceeds the receiver's limits.
+func (g *gen) writeLimitsEpilogue(b *buffer) {
+	switch g.funcLimitsKind(g.currFunk.astFunc) {
+	case limitsImageConfig:
+		b.writes("if (wuffs_base__status__is_ok(&status)) {\n")
+		b.printf("status = wuffs_base__image_decoder_limits__check_image_config(\n"+
+			"&self->private_impl.limits, %sdst,\n%s%s__workbuf_len(self).min_incl);\n",
+			aPrefix, g.pkgPrefix, g.currFunk.astFunc.Receiver().Str(g.tm))
+		b.writes("}\n")
+	case limitsFrameConfig:
+		b.writes("if (wuffs_base__status__is_ok(&status)) {\n")
+		b.printf("status = wuffs_base__image_decoder_limits__check_num_frames(\n"+
+			"&self->private_impl.limits,\n%s%s__num_decoded_frame_configs(self));\n",
+			g.pkgPrefix, g.currFunk.astFunc.Receiver().Str(g.tm))
+		b.writes("}\n")
+	}
+}
+
 func (g *gen) writeFuncImplArgChecks(b *buffer, n *a.Func) error {
 	checks := []string(nil)
 
@@ -604,6 +1135,13 @@ func (g *gen) writeFuncImplArgChecks(b *buffer, n *a.Func) error {
 					checks = append(checks, fmt.Sprintf("%s%s %c %s", aPrefix, o.Name().Str(g.tm), op, bound))
 				}
 			}
+
+		case g.isEnumType(oTyp):
+			// The C type can hold values that are not enum members, unless the
+			// enum has exactly 256 (or 65536) members.
+			if n := len(g.enumMap[oTyp.QID()].Members()); (n != 0x100) && (n != 0x10000) {
+				checks = append(checks, fmt.Sprintf("%s%s > %d", aPrefix, o.Name().Str(g.tm), n-1))
+			}
 		}
 	}
 
@@ -620,11 +1158,14 @@ func (g *gen) writeFuncImplArgChecks(b *buffer, n *a.Func) error {
 	}
 	b.writes(") {\n")
 	b.writes("self->private_impl.magic = WUFFS_BASE__DISABLED;\n")
-	if g.currFunk.astFunc.Effect().Coroutine() {
+	if f := g.currFunk.astFunc; f.Effect().Coroutine() || ((f.Out() != nil) && f.Out().IsStatus()) {
 		b.writes("return wuffs_base__make_status(wuffs_base__error__bad_argument);\n")
 	} else {
-		// TODO: don't assume that the return type is empty.
-		b.printf("return wuffs_base__make_empty_struct();\n")
+		b.writes("return ")
+		if err := g.writeOutParamZeroValue(b, f.Out()); err != nil {
+			return err
+		}
+		b.writes(";\n")
 	}
 	b.writes("}\n")
 	return nil
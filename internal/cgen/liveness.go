@@ -214,6 +214,11 @@ loop:
 				break loop
 			}
 
+		case a.KSwitch:
+			if err := h.doSwitch(r, o.AsSwitch(), depth); err != nil {
+				return err
+			}
+
 		case a.KVar:
 			if err := h.doVar(r, o.AsVar(), depth); err != nil {
 				return err
@@ -358,6 +363,25 @@ func (h *livenessHelper) doIf(r livenesses, n *a.If, depth uint32) error {
 	return nil
 }
 
+func (h *livenessHelper) doSwitch(r livenesses, n *a.Switch, depth uint32) error {
+	if err := h.doExpr(r, n.Value()); err != nil {
+		return err
+	}
+
+	// A switch is exhaustive, so every path goes through exactly one arm.
+	scratch := make(livenesses, len(r))
+	result := make(livenesses, len(r))
+	for _, o := range n.Cases() {
+		copy(scratch, r)
+		if err := h.doBlock(scratch, o.AsCase().Body(), depth); err != nil {
+			return err
+		}
+		result.reconcile(scratch)
+	}
+	copy(r, result)
+	return nil
+}
+
 func (h *livenessHelper) doIterate(r livenesses, n *a.Iterate, depth uint32) error {
 	// TODO: ban jumps, rets and coroutine calls inside an iterate. Also ensure
 	// that the iterate variable values are all pure expressions.
@@ -0,0 +1,493 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgen
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// This file deals with reachability analysis of the base package's C code,
+// used by "wuffs gen -only-needed-base" to drop the base functions, types and
+// tables that none of the other packages refer to.
+//
+// It works on the generated C text, not on an AST. The text is split into
+// top-level chunks: preprocessor directives, comments, blank lines and
+// declarations. Directives (including every #define) are always kept, so
+// that #if / #endif nesting is preserved. A declaration is dropped if every
+// name that it defines starts with "wuffs_base__" and none of those names are
+// reachable from the roots. Declarations whose names can't be determined are
+// conservatively kept and treated as roots.
+
+const pruneBasePrefix = "wuffs_base__"
+
+type pruneChunkKind uint8
+
+const (
+	pruneChunkKindOther      = pruneChunkKind(0) // Always kept.
+	pruneChunkKindDefine     = pruneChunkKind(1) // Always kept. Has deps.
+	pruneChunkKindDecl       = pruneChunkKind(2) // Kept if reached.
+	pruneChunkKindDeclRoot   = pruneChunkKind(3) // Always kept. Is a root.
+	pruneChunkKindAttachComm = pruneChunkKind(4) // Kept if the next chunk is.
+)
+
+type pruneChunk struct {
+	kind    pruneChunkKind
+	reached bool
+	text    []byte
+	names   []string
+	idents  []string
+}
+
+type pruner struct {
+	defs    map[string][]*pruneChunk
+	reached map[string]bool
+	stack   []string
+}
+
+// PruneBase returns the header and implementation fragments of the base
+// package's C code, keeping only the declarations that are transitively
+// referenced by roots, typically the other packages' C code.
+func PruneBase(base [2][]byte, roots [][]byte) (ret [2][]byte, retErr error) {
+	p := &pruner{
+		defs:    map[string][]*pruneChunk{},
+		reached: map[string]bool{},
+	}
+
+	chunkss := [2][]*pruneChunk{}
+	for i, src := range base {
+		chunks, err := splitPruneChunks(src)
+		if err != nil {
+			return ret, err
+		}
+		for _, c := range chunks {
+			for _, name := range c.names {
+				p.defs[name] = append(p.defs[name], c)
+			}
+		}
+		chunkss[i] = chunks
+	}
+
+	for _, r := range roots {
+		p.push(pruneIdents(r))
+	}
+	for _, chunks := range chunkss {
+		for _, c := range chunks {
+			if c.kind == pruneChunkKindDeclRoot {
+				p.push(c.idents)
+			}
+		}
+	}
+	p.flood()
+
+	for i, chunks := range chunkss {
+		ret[i] = emitPruneChunks(chunks)
+	}
+	return ret, nil
+}
+
+func (p *pruner) push(idents []string) {
+	for _, id := range idents {
+		if !p.reached[id] {
+			p.reached[id] = true
+			p.stack = append(p.stack, id)
+		}
+	}
+}
+
+func (p *pruner) flood() {
+	for len(p.stack) > 0 {
+		id := p.stack[len(p.stack)-1]
+		p.stack = p.stack[:len(p.stack)-1]
+		for _, c := range p.defs[id] {
+			if !c.reached {
+				c.reached = true
+				p.push(c.idents)
+			}
+		}
+	}
+}
+
+func emitPruneChunks(chunks []*pruneChunk) []byte {
+	out := []byte(nil)
+	for i, c := range chunks {
+		keep := false
+		switch c.kind {
+		case pruneChunkKindOther, pruneChunkKindDefine, pruneChunkKindDeclRoot:
+			keep = true
+		case pruneChunkKindDecl:
+			keep = c.reached
+		case pruneChunkKindAttachComm:
+			keep = (i+1 >= len(chunks)) || (chunks[i+1].kind != pruneChunkKindDecl) ||
+				chunks[i+1].reached
+		}
+		if !keep {
+			continue
+		}
+		// Collapse runs of blank lines.
+		if isBlankLine(c.text) && bytes.HasSuffix(out, []byte("\n\n")) {
+			continue
+		}
+		out = append(out, c.text...)
+	}
+	return out
+}
+
+func isBlankLine(s []byte) bool {
+	return len(bytes.TrimSpace(s)) == 0
+}
+
+// splitPruneChunks splits C code into top-level chunks.
+func splitPruneChunks(src []byte) (ret []*pruneChunk, retErr error) {
+	lines := bytes.SplitAfter(src, []byte("\n"))
+
+	decl := []byte(nil)
+	depth := 0
+	inBlockComment := false
+	comment := []byte(nil)
+
+	flushComment := func(kind pruneChunkKind) {
+		if len(comment) > 0 {
+			ret = append(ret, &pruneChunk{kind: kind, text: comment})
+			comment = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := bytes.TrimSpace(line)
+
+		// Gather a preprocessor directive, including continuation lines.
+		if (len(trimmed) > 0) && (trimmed[0] == '#') && !inBlockComment {
+			for bytes.HasSuffix(bytes.TrimRight(lines[i], "\n"), []byte("\\")) &&
+				(i+1 < len(lines)) {
+				i++
+				line = append(line[:len(line):len(line)], lines[i]...)
+			}
+			if len(decl) > 0 {
+				decl = append(decl, line...)
+				continue
+			}
+			flushComment(pruneChunkKindOther)
+			c := &pruneChunk{kind: pruneChunkKindOther, text: line}
+			if name := pruneDefineName(trimmed); name != "" {
+				c.kind = pruneChunkKindDefine
+				c.names = []string{name}
+				c.idents = pruneIdents(line[bytes.IndexByte(line, '#')+1:])
+			}
+			ret = append(ret, c)
+			continue
+		}
+
+		if len(decl) == 0 {
+			if inBlockComment {
+				comment = append(comment, line...)
+				inBlockComment = !bytes.Contains(line, []byte("*/"))
+				continue
+			} else if len(trimmed) == 0 {
+				flushComment(pruneChunkKindOther)
+				ret = append(ret, &pruneChunk{kind: pruneChunkKindOther, text: line})
+				continue
+			} else if bytes.HasPrefix(trimmed, []byte("//")) {
+				comment = append(comment, line...)
+				continue
+			} else if bytes.HasPrefix(trimmed, []byte("/*")) {
+				comment = append(comment, line...)
+				inBlockComment = !bytes.Contains(trimmed[2:], []byte("*/"))
+				continue
+			} else if bytes.Equal(trimmed, []byte(`extern "C" {`)) ||
+				bytes.HasPrefix(trimmed, []byte(`}  // extern "C"`)) {
+				flushComment(pruneChunkKindOther)
+				ret = append(ret, &pruneChunk{kind: pruneChunkKindOther, text: line})
+				continue
+			}
+		}
+
+		decl = append(decl, line...)
+		depth += pruneBraceDelta(line)
+		if depth < 0 {
+			return nil, fmt.Errorf("cgen: PruneBase: unbalanced braces near %q", trimmed)
+		}
+		if (depth > 0) || !pruneEndsDecl(line) {
+			continue
+		}
+
+		c := &pruneChunk{kind: pruneChunkKindDeclRoot, text: decl, idents: pruneIdents(decl)}
+		if pruneConditionalDelta(decl) != 0 {
+			return nil, fmt.Errorf("cgen: PruneBase: unbalanced #if near %q", trimmed)
+		}
+		if names := pruneDeclNames(decl); len(names) > 0 {
+			c.kind = pruneChunkKindDecl
+			c.names = names
+		}
+		flushComment(pruneChunkKindAttachComm)
+		ret = append(ret, c)
+		decl = nil
+	}
+
+	if len(decl) > 0 {
+		return nil, fmt.Errorf("cgen: PruneBase: incomplete declaration at end of input")
+	}
+	flushComment(pruneChunkKindOther)
+	return ret, nil
+}
+
+// pruneDefineName returns the macro name of a "#define" directive, or "".
+func pruneDefineName(directive []byte) string {
+	s := bytes.TrimSpace(directive[1:])
+	if !bytes.HasPrefix(s, []byte("define")) {
+		return ""
+	}
+	s = bytes.TrimSpace(s[len("define"):])
+	n := 0
+	for (n < len(s)) && isIdentByte(s[n]) {
+		n++
+	}
+	return string(s[:n])
+}
+
+// pruneEndsDecl returns whether a line, at brace depth zero, ends a
+// declaration: its last code (not comment) byte is a ';' or a '}'.
+func pruneEndsDecl(line []byte) bool {
+	code := pruneCode(line)
+	code = bytes.TrimSpace(code)
+	return (len(code) > 0) && ((code[len(code)-1] == ';') || (code[len(code)-1] == '}'))
+}
+
+func pruneBraceDelta(line []byte) (delta int) {
+	for _, c := range pruneCode(line) {
+		if c == '{' {
+			delta++
+		} else if c == '}' {
+			delta--
+		}
+	}
+	return delta
+}
+
+func pruneConditionalDelta(decl []byte) (delta int) {
+	for _, line := range bytes.Split(decl, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if (len(line) == 0) || (line[0] != '#') {
+			continue
+		}
+		line = bytes.TrimSpace(line[1:])
+		if bytes.HasPrefix(line, []byte("if")) {
+			delta++
+		} else if bytes.HasPrefix(line, []byte("endif")) {
+			delta--
+		}
+	}
+	return delta
+}
+
+// pruneCode returns s with comments, string literals and character literals
+// replaced by spaces. It assumes that block comments do not span multiple
+// lines of declarations, which holds for the base package's C code.
+func pruneCode(s []byte) []byte {
+	ret := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case (c == '/') && (i+1 < len(s)) && (s[i+1] == '/'):
+			for ; (i < len(s)) && (s[i] != '\n'); i++ {
+			}
+			ret = append(ret, '\n')
+		case (c == '/') && (i+1 < len(s)) && (s[i+1] == '*'):
+			if j := bytes.Index(s[i+2:], []byte("*/")); j >= 0 {
+				i += j + 3
+			} else {
+				i = len(s)
+			}
+			ret = append(ret, ' ')
+		case (c == '"') || (c == '\''):
+			for i++; (i < len(s)) && (s[i] != c); i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+			ret = append(ret, ' ')
+		case (c == '#') && ((i == 0) || (s[i-1] == '\n') || isBlankLine(lineStart(s, i))):
+			// Skip a preprocessor directive, including continuation lines.
+			for ; i < len(s); i++ {
+				if (s[i] == '\n') && (s[i-1] != '\\') {
+					break
+				}
+			}
+			ret = append(ret, '\n')
+		default:
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// lineStart returns the bytes between the start of s[i]'s line and s[i].
+func lineStart(s []byte, i int) []byte {
+	j := bytes.LastIndexByte(s[:i], '\n')
+	return s[j+1 : i]
+}
+
+func isIdentByte(c byte) bool {
+	return (c == '_') ||
+		(('0' <= c) && (c <= '9')) ||
+		(('A' <= c) && (c <= 'Z')) ||
+		(('a' <= c) && (c <= 'z'))
+}
+
+// pruneIdents returns the unique identifiers in s, outside of comments and
+// string literals.
+func pruneIdents(s []byte) (ret []string) {
+	seen := map[string]bool{}
+	for _, tok := range pruneTokens(s) {
+		if isIdentByte(tok[0]) && ((tok[0] < '0') || ('9' < tok[0])) {
+			if id := string(tok); !seen[id] {
+				seen[id] = true
+				ret = append(ret, id)
+			}
+		}
+	}
+	return ret
+}
+
+// pruneTokens splits s into identifiers, numbers and single punctuation
+// bytes, outside of comments, literals and preprocessor directives.
+func pruneTokens(s []byte) (ret [][]byte) {
+	code := pruneCode(s)
+	for i := 0; i < len(code); {
+		c := code[i]
+		if isIdentByte(c) {
+			j := i + 1
+			for (j < len(code)) && isIdentByte(code[j]) {
+				j++
+			}
+			ret = append(ret, code[i:j])
+			i = j
+		} else {
+			if (c != ' ') && (c != '\t') && (c != '\n') {
+				ret = append(ret, code[i:i+1])
+			}
+			i++
+		}
+	}
+	return ret
+}
+
+// pruneDeclNames returns the names defined by a top-level declaration, or nil
+// if they can't be determined or are not all base package names.
+func pruneDeclNames(decl []byte) (ret []string) {
+	toks := pruneTokens(decl)
+	if len(toks) == 0 {
+		return nil
+	}
+
+	// depths[i] is the combined (), [] and {} nesting depth before toks[i].
+	depths := make([]int, len(toks))
+	depth := 0
+	for i, tok := range toks {
+		if (tok[0] == ')') || (tok[0] == ']') || (tok[0] == '}') {
+			depth--
+		}
+		depths[i] = depth
+		if (tok[0] == '(') || (tok[0] == '[') || (tok[0] == '{') {
+			depth++
+		}
+	}
+
+	isBase := func(tok []byte) bool { return bytes.HasPrefix(tok, []byte(pruneBasePrefix)) }
+	is := func(tok []byte, s string) bool { return string(tok) == s }
+
+	switch first := toks[0]; {
+	case is(first, "typedef"):
+		// The struct tag, if any.
+		for i := 1; i+1 < len(toks); i++ {
+			if (depths[i] == 0) && (is(toks[i], "struct") || is(toks[i], "union")) {
+				ret = append(ret, string(toks[i+1]))
+				break
+			}
+		}
+		// A function pointer typedef, "typedef etc (*name)(etc);".
+		for i := 1; i+2 < len(toks); i++ {
+			if (depths[i] == 0) && is(toks[i], "(") && is(toks[i+1], "*") {
+				return pruneAllBase(append(ret, string(toks[i+2])))
+			}
+		}
+		// Otherwise, the last top-level identifier before any '[' or ';'.
+		name := ""
+		for i := 1; i < len(toks); i++ {
+			if depths[i] != 0 {
+				continue
+			} else if is(toks[i], "[") || is(toks[i], ";") {
+				break
+			} else if isBase(toks[i]) {
+				name = string(toks[i])
+			}
+		}
+		if name == "" {
+			return nil
+		}
+		return pruneAllBase(append(ret, name))
+
+	case is(first, "struct") || is(first, "union"):
+		if (len(toks) > 2) && (is(toks[2], "{") || is(toks[2], ";")) {
+			return pruneAllBase([]string{string(toks[1])})
+		}
+		return nil
+
+	case is(first, "enum"):
+		// Enumerators are names too. Keep it simple: keep all enums.
+		return nil
+	}
+
+	// A function or a variable. For a function, the name is the base
+	// identifier immediately before the first top-level '('. Other parentheses,
+	// such as WUFFS_BASE__MAYBE_ATTRIBUTE_TARGET(etc), are skipped.
+	for i := 1; i < len(toks); i++ {
+		if depths[i] != 0 {
+			continue
+		} else if is(toks[i], "(") {
+			if isBase(toks[i-1]) {
+				return []string{string(toks[i-1])}
+			}
+		} else if is(toks[i], "[") || is(toks[i], "=") || is(toks[i], ";") || is(toks[i], "{") {
+			break
+		}
+	}
+	// A variable. The name is the last base identifier before the first
+	// top-level '[', '=' or ';'.
+	name := ""
+	for i := 0; i < len(toks); i++ {
+		if depths[i] != 0 {
+			continue
+		} else if is(toks[i], "[") || is(toks[i], "=") || is(toks[i], ";") || is(toks[i], "(") {
+			break
+		} else if isBase(toks[i]) {
+			name = string(toks[i])
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	return []string{name}
+}
+
+func pruneAllBase(names []string) []string {
+	for _, name := range names {
+		if !bytes.HasPrefix([]byte(name), []byte(pruneBasePrefix)) {
+			return nil
+		}
+	}
+	return names
+}
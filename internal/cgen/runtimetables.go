@@ -0,0 +1,127 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgen
+
+// This file deals with runtime tables: const arrays that, with the
+// -runtimetables flag, are computed when initializing a struct instead of
+// being emitted as static const data.
+//
+// A pri const FOO_BAR is a runtime table if the package also defines a maker
+// method "pri func qux.make_foo_bar!(t: slice base.u32)", for some struct qux,
+// where the slice element type matches FOO_BAR's innermost element type. The
+// maker fills the slice, whose length is the total number of elements in the
+// (possibly multi-dimensional) FOO_BAR array, with FOO_BAR's values.
+//
+// In that mode, FOO_BAR becomes a private_data field of qux and can only be
+// referred to by qux's methods. Without that mode, the maker is not
+// generated at all.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	a "github.com/google/wuffs/lang/ast"
+	t "github.com/google/wuffs/lang/token"
+)
+
+type runtimeTable struct {
+	konst *a.Const
+	maker *a.Func
+}
+
+func (g *gen) gatherRuntimeTables() {
+	g.runtimeTables = map[t.ID]runtimeTable{}
+
+	consts := map[string]*a.Const{}
+	for _, file := range g.files {
+		for _, tld := range file.TopLevelDecls() {
+			if tld.Kind() != a.KConst {
+				continue
+			}
+			c := tld.AsConst()
+			if !c.Public() && c.XType().IsArrayType() {
+				consts[c.QID()[1].Str(g.tm)] = c
+			}
+		}
+	}
+
+	for _, file := range g.files {
+		for _, tld := range file.TopLevelDecls() {
+			if tld.Kind() != a.KFunc {
+				continue
+			}
+			f := tld.AsFunc()
+			name := f.FuncName().Str(g.tm)
+			if f.Public() || f.Receiver().IsZero() || !f.Effect().Impure() ||
+				f.Effect().Coroutine() || (f.Out() != nil) || !strings.HasPrefix(name, "make_") {
+				continue
+			}
+			c := consts[strings.ToUpper(name[len("make_"):])]
+			if c == nil {
+				continue
+			}
+			args := f.In().Fields()
+			if len(args) != 1 {
+				continue
+			}
+			typ := args[0].AsField().XType()
+			if !typ.IsSliceType() || !typ.Inner().Eq(c.XType().Innermost()) {
+				continue
+			}
+			g.runtimeTables[c.QID()[1]] = runtimeTable{konst: c, maker: f}
+		}
+	}
+}
+
+func (g *gen) isRuntimeTableMaker(f *a.Func) bool {
+	for _, rt := range g.runtimeTables {
+		if rt.maker == f {
+			return true
+		}
+	}
+	return false
+}
+
+// runtimeTableNames returns the sorted names of the runtime tables owned by
+// the struct named qid.
+func (g *gen) runtimeTableNames(qid t.QID) (names []t.ID) {
+	for name, rt := range g.runtimeTables {
+		if rt.maker.Receiver() == qid {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i int, j int) bool {
+		return names[i].Str(g.tm) < names[j].Str(g.tm)
+	})
+	return names
+}
+
+func (g *gen) writeRuntimeTableInitialization(b *buffer, name t.ID) error {
+	rt := g.runtimeTables[name]
+	typ := rt.konst.XType()
+	index, length := "", uint64(1)
+	for ; typ.IsArrayType(); typ = typ.Inner() {
+		cv := typ.ArrayLength().ConstValue()
+		if cv == nil {
+			return fmt.Errorf("runtime table %s has a non-constant length", name.Str(g.tm))
+		}
+		index += "[0]"
+		length *= cv.Uint64()
+	}
+	b.printf("%s(self, wuffs_base__make_slice_%s(\n&self->private_data.%s%s%s, %d));\n",
+		g.funcCName(rt.maker), typ.QID()[1].Str(g.tm), cPrefix, name.Str(g.tm), index, length)
+	return nil
+}
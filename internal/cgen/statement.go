@@ -15,6 +15,7 @@
 package cgen
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -70,6 +71,8 @@ func (g *gen) writeStatement(b *buffer, n *a.Node, depth uint32) error {
 		return g.writeStatementJump(b, n.AsJump(), depth)
 	case a.KRet:
 		return g.writeStatementRet(b, n.AsRet(), depth)
+	case a.KSwitch:
+		return g.writeStatementSwitch(b, n.AsSwitch(), depth)
 	case a.KVar:
 		return nil
 	case a.KWhile:
@@ -224,18 +227,24 @@ func (g *gen) writeStatementAssign1(b *buffer, op t.ID, lhs *a.Expr, rhs *a.Expr
 }
 
 func (g *gen) writeStatementChoose(b *buffer, n *a.Choose, depth uint32) error {
-	recv := g.currFunk.astFunc.Receiver()
-	args := n.Args()
+	return g.writeChoose(b, g.currFunk.astFunc.Receiver(), n.Name(), n.Args(), false)
+}
+
+// writeChoose writes the assignment to a choosy function pointer. If none of
+// the args are conclusive (e.g. they are all cpu_arch functions and the CPU
+// has none of those features), the pointer is left unchanged, or for the
+// initializer (where there is no previous choice), set to the default.
+func (g *gen) writeChoose(b *buffer, recv t.QID, name t.ID, args []*a.Node, initializer bool) error {
 	if len(args) == 0 {
 		return nil
 	}
-	b.printf("self->private_impl.choosy_%s = (\n", n.Name().Str(g.tm))
+	b.printf("self->private_impl.choosy_%s = (\n", name.Str(g.tm))
 
 	conclusive := false
 	for _, o := range args {
 		id := o.AsExpr().Ident()
 		suffix := ""
-		if n.Name() == id {
+		if name == id {
 			suffix = "__choosy_default"
 		}
 		caMacro, caName, _, err := cpuArchCNames(g.findAstFunc(t.QQID{recv[0], recv[1], id}).Asserts())
@@ -254,7 +263,11 @@ func (g *gen) writeStatementChoose(b *buffer, n *a.Choose, depth uint32) error {
 	}
 
 	if !conclusive {
-		b.printf("self->private_impl.choosy_%s", n.Name().Str(g.tm))
+		if initializer {
+			b.printf("&%s%s__%s__choosy_default", g.pkgPrefix, recv.Str(g.tm), name.Str(g.tm))
+		} else {
+			b.printf("self->private_impl.choosy_%s", name.Str(g.tm))
+		}
 	}
 	b.writes(");\n")
 	return nil
@@ -275,6 +288,12 @@ func cpuArchCNames(asserts []*a.Node) (caMacro string, caName string, caAttribut
 				caMacro, caName, caAttribute = "ARM_CRC32", "arm_crc32", ""
 			case t.IDARMNeon:
 				caMacro, caName, caAttribute = "ARM_NEON", "arm_neon", ""
+			case t.IDARMSHA2:
+				caMacro, caName, caAttribute = "ARM_SHA2", "arm_sha2", ""
+			case t.IDRISCVRVV:
+				caMacro, caName, caAttribute = "RISCV_RVV", "riscv_rvv", ""
+			case t.IDWASMSIMD128:
+				caMacro, caName, caAttribute = "WASM_SIMD128", "wasm_simd128", ""
 			case t.IDX86SSE42:
 				caMacro, caName, caAttribute =
 					"X86_64", "x86_sse42",
@@ -287,6 +306,10 @@ func cpuArchCNames(asserts []*a.Node) (caMacro string, caName string, caAttribut
 				caMacro, caName, caAttribute =
 					"X86_64", "x86_bmi2",
 					"WUFFS_BASE__MAYBE_ATTRIBUTE_TARGET(\"bmi2\")"
+			case t.IDX86SHA:
+				caMacro, caName, caAttribute =
+					"X86_64", "x86_sha",
+					"WUFFS_BASE__MAYBE_ATTRIBUTE_TARGET(\"pclmul,popcnt,sse4.2,sha\")"
 			}
 		}
 	}
@@ -294,6 +317,9 @@ func cpuArchCNames(asserts []*a.Node) (caMacro string, caName string, caAttribut
 }
 
 func (g *gen) writeStatementIOBind(b *buffer, n *a.IOBind, depth uint32) error {
+	if n.Keyword() == t.IDIOChecksum {
+		return g.writeStatementIOChecksum(b, n, depth)
+	}
 	if g.currFunk.ioBinds > maxIOBinds {
 		return fmt.Errorf("too many temporary variables required")
 	}
@@ -400,6 +426,53 @@ func (g *gen) writeStatementIOBind(b *buffer, n *a.IOBind, depth uint32) error {
 	return nil
 }
 
+func (g *gen) writeStatementIOChecksum(b *buffer, n *a.IOBind, depth uint32) error {
+	if g.currFunk.ioBinds > maxIOBinds {
+		return fmt.Errorf("too many temporary variables required")
+	}
+	ioBindNum := g.currFunk.ioBinds
+	g.currFunk.ioBinds++
+
+	e := n.IO()
+	prefix := vPrefix
+	if e.Operator() != 0 {
+		prefix = aPrefix
+	}
+	name := prefix + e.Ident().Str(g.tm)
+	markName := fmt.Sprintf("%s%d_mark_%s", oPrefix, ioBindNum, name)
+	position := fmt.Sprintf("((uint64_t)(%s%s - %s%s))", iopPrefix, name, io0Prefix, name)
+
+	hasher := n.Arg1()
+	qid := hasher.MType().QID()
+	update := buffer{}
+	update.printf("%s%s__update_u32(&", g.packagePrefix(qid), qid[1].Str(g.tm))
+	if err := g.writeExpr(&update, hasher, false, 0); err != nil {
+		return err
+	}
+	update.printf(", wuffs_base__io__since(%s, %s, %s%s));\n", markName, position, io0Prefix, name)
+
+	i := len(g.currFunk.ioChecksums)
+	g.currFunk.ioChecksums = append(g.currFunk.ioChecksums, ioChecksum{
+		markName: markName,
+		position: position,
+		update:   string(update),
+		spLo:     g.currFunk.coroSuspPoint + 1,
+		active:   true,
+	})
+
+	b.printf("%s = %s;\n", markName, position)
+	for _, o := range n.Body() {
+		if err := g.writeStatement(b, o, depth); err != nil {
+			return err
+		}
+	}
+	b.writex(update)
+
+	g.currFunk.ioChecksums[i].spHi = g.currFunk.coroSuspPoint
+	g.currFunk.ioChecksums[i].active = false
+	return nil
+}
+
 func (g *gen) writeStatementIf(b *buffer, n *a.If, depth uint32) error {
 	// For an "if true { etc }", just write the "etc".
 	if cv := n.Condition().ConstValue(); (cv != nil) && (cv.Cmp(one) == 0) &&
@@ -444,6 +517,120 @@ func (g *gen) writeStatementIf(b *buffer, n *a.If, depth uint32) error {
 	return nil
 }
 
+var errHasSuspensionPoint = errors.New("cgen: internal error: has suspension point")
+
+// writeStatementSwitch writes n as a C switch, unless one of its arms can
+// suspend a coroutine. C case labels belong to the innermost C switch, so the
+// coroutine resumption's case labels cannot be inside one. Such a Wuffs switch
+// is written as an if-else chain instead.
+func (g *gen) writeStatementSwitch(b *buffer, n *a.Switch, depth uint32) error {
+	value := buffer(nil)
+	if err := g.writeExpr(&value, n.Value(), false, 0); err != nil {
+		return err
+	}
+
+	cases := n.Cases()
+	for _, o := range cases {
+		if hasSuspensionPoint(o.AsCase().Body()) {
+			return g.writeStatementSwitchAsIfElse(b, cases, value, depth)
+		}
+	}
+
+	b.printf("switch (%s) {\n", trimParens(value))
+	for i, o := range cases {
+		o := o.AsCase()
+		for j, v := range o.Values() {
+			if j > 0 {
+				b.writes(":\n")
+			}
+			b.writes("case ")
+			if err := g.writeExpr(b, v.AsExpr(), false, 0); err != nil {
+				return err
+			}
+		}
+		// A switch is exhaustive, so the final arm can be the C default.
+		if i == (len(cases) - 1) {
+			if len(o.Values()) > 0 {
+				b.writes(":\n")
+			}
+			b.writes("default")
+		}
+		b.writes(": {\n")
+		for _, p := range o.Body() {
+			if err := g.writeStatement(b, p, depth); err != nil {
+				return err
+			}
+		}
+		if !a.Terminates(o.Body()) {
+			b.writes("break;\n")
+		}
+		b.writes("}\n")
+	}
+	b.writes("}\n")
+	return nil
+}
+
+func (g *gen) writeStatementSwitchAsIfElse(b *buffer, cases []*a.Node, value buffer, depth uint32) error {
+	// A switch is exhaustive, so the final arm needs no condition. If that arm
+	// is an empty default arm, it needs no code at all.
+	numConditions := len(cases) - 1
+	if last := cases[len(cases)-1].AsCase(); last.IsDefault() && (len(last.Body()) == 0) && (len(cases) > 1) {
+		cases = cases[:len(cases)-1]
+		numConditions = len(cases)
+	}
+
+	for i, o := range cases {
+		o := o.AsCase()
+		if i > 0 {
+			b.writes("} else ")
+		}
+		if i < numConditions {
+			condition := buffer(nil)
+			for j, v := range o.Values() {
+				if j > 0 {
+					condition.writes(" || ")
+				}
+				condition.printf("(%s == ", value)
+				if err := g.writeExpr(&condition, v.AsExpr(), false, 0); err != nil {
+					return err
+				}
+				condition.writeb(')')
+			}
+			// Calling trimParens avoids clang's -Wparentheses-equality warning.
+			if len(o.Values()) == 1 {
+				condition = trimParens(condition)
+			}
+			b.printf("if (%s) ", condition)
+		}
+		b.writes("{\n")
+		for _, p := range o.Body() {
+			if err := g.writeStatement(b, p, depth); err != nil {
+				return err
+			}
+		}
+	}
+	b.writes("}\n")
+	return nil
+}
+
+// hasSuspensionPoint returns whether block contains a coroutine call or a
+// yield.
+func hasSuspensionPoint(block []*a.Node) bool {
+	for _, o := range block {
+		err := o.Walk(func(p *a.Node) error {
+			if ((p.Kind() == a.KExpr) && p.AsExpr().Effect().Coroutine()) ||
+				((p.Kind() == a.KRet) && (p.AsRet().Keyword() == t.IDYield)) {
+				return errHasSuspensionPoint
+			}
+			return nil
+		})
+		if err != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (g *gen) writeStatementIterate(b *buffer, n *a.Iterate, depth uint32) error {
 	assigns := n.Assigns()
 	if len(assigns) == 0 {
@@ -522,26 +709,54 @@ func (g *gen) writeStatementJump(b *buffer, n *a.Jump, depth uint32) error {
 func (g *gen) writeStatementRet(b *buffer, n *a.Ret, depth uint32) error {
 	retExpr := n.Value()
 
+	// Returning from within an io_checksum block skips the end of that block,
+	// so update its hasher now. Yielding is handled by the "suspend:" code.
+	if n.Keyword() == t.IDReturn {
+		for _, o := range g.currFunk.ioChecksums {
+			if o.active {
+				b.writes(o.update)
+			}
+		}
+	}
+
 	if g.currFunk.astFunc.Effect().Coroutine() ||
 		(g.currFunk.returnsStatus && (len(g.currFunk.derivedVars) > 0)) {
 
-		isComplete := false
+		isComplete, isNote := false, false
 		b.writes("status = ")
 		if retExpr.Operator() == 0 && retExpr.Ident() == t.IDOk {
 			b.writes("wuffs_base__make_status(NULL)")
 			isComplete = true
 		} else {
-			if retExpr.Ident().IsDQStrLiteral(g.tm) {
-				msg, _ := t.Unescape(retExpr.Ident().Str(g.tm))
+			lit := retExpr
+			if recv, meth, _, ok := lit.IsMethodCall(); ok && (meth == t.IDWithPayload) && recv.MType().IsStatus() {
+				lit = recv
+			}
+			if lit.Ident().IsDQStrLiteral(g.tm) {
+				msg, _ := t.Unescape(lit.Ident().Str(g.tm))
 				isComplete = statusMsgIsNote(msg)
+				isNote = isComplete
 			}
 			if err := g.writeExpr(b, retExpr, false, depth); err != nil {
 				return err
 			}
 		}
 		b.writes(";\n")
+		if n.RetsError() || isNote {
+			g.writeTrace(b, "STATUS", "status.repr", "0", "0")
+		}
 
 		if n.Keyword() == t.IDYield {
+			if isNote {
+				// Yielding a note leaves the coroutine resumable, which
+				// requires the active_coroutine tracking that only public
+				// functions have.
+				if !g.currFunk.astFunc.Public() {
+					return fmt.Errorf("cannot yield a note from a private function")
+				}
+				g.currFunk.hasYieldNote = true
+				return g.writeCoroSuspPointMacro(b, "_YIELD_NOTE")
+			}
 			return g.writeCoroSuspPoint(b, true)
 		}
 
@@ -571,6 +786,12 @@ func (g *gen) writeStatementRet(b *buffer, n *a.Ret, depth uint32) error {
 		}
 	}
 
+	if n.RetsError() && retExpr.Ident().IsDQStrLiteral(g.tm) {
+		if z := g.statusMap[t.QID{0, retExpr.Ident()}]; z.cName != "" {
+			g.writeTrace(b, "STATUS", z.cName, "0", "0")
+		}
+	}
+
 	b.writes("return ")
 	if g.currFunk.astFunc.Out() == nil {
 		b.writes("wuffs_base__make_empty_struct()")
@@ -669,16 +890,20 @@ func (g *gen) writeIterateRound(b *buffer, assigns []*a.Node, body []*a.Node, ro
 }
 
 func (g *gen) writeCoroSuspPoint(b *buffer, maybeSuspend bool) error {
+	macro := ""
+	if maybeSuspend {
+		macro = "_MAYBE_SUSPEND"
+	}
+	return g.writeCoroSuspPointMacro(b, macro)
+}
+
+func (g *gen) writeCoroSuspPointMacro(b *buffer, macro string) error {
 	const maxCoroSuspPoint = 0xFFFFFFFF
 	g.currFunk.coroSuspPoint++
 	if g.currFunk.coroSuspPoint == maxCoroSuspPoint {
 		return fmt.Errorf("too many coroutine suspension points required")
 	}
 
-	macro := ""
-	if maybeSuspend {
-		macro = "_MAYBE_SUSPEND"
-	}
 	b.printf("WUFFS_BASE__COROUTINE_SUSPENSION_POINT%s(%d);\n", macro, g.currFunk.coroSuspPoint)
 	return nil
 }
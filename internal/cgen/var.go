@@ -62,6 +62,10 @@ func (g *gen) needDerivedVar(name t.ID) bool {
 					if recv.MType().Eq(typeExprPixelSwizzler) && argsContainsArgsDotFoo(args, name) {
 						return errNeedDerivedVar
 					}
+				case t.IDLimitedCopyU32FromReader, t.IDLimitedCopyU64FromReader:
+					if argsContainsArgsDotFoo(args, name) {
+						return errNeedDerivedVar
+					}
 				}
 
 			case a.KIOBind:
@@ -339,12 +343,16 @@ func (g *gen) writeVars(b *buffer, f *funk, inStructDecl bool) error {
 			return fmt.Errorf("TODO: support token_{reader,writer} typed variables")
 		}
 
+		if inStructDecl && (typ.QID()[0] == t.IDBase) && (riscvRVVZeroes[typ.QID()[1]] != "") {
+			return fmt.Errorf("cannot save sizeless RISC-V Vector variable %q across a suspension", name)
+		}
+
 		if err := g.writeCTypeName(b, typ, vPrefix, name); err != nil {
 			return err
 		}
 		if inStructDecl {
 			b.writes(";\n")
-		} else if typ.IsNumType() {
+		} else if typ.IsNumType() || typ.IsFloatType() || g.isEnumType(typ) {
 			b.writes(" = 0;\n")
 		} else if typ.IsBool() {
 			b.writes(" = false;\n")
@@ -354,6 +362,9 @@ func (g *gen) writeVars(b *buffer, f *funk, inStructDecl bool) error {
 			b.printf(" = &%s%s;\n", uPrefix, name)
 		} else if typ.Eq(typeExprARMCRC32U32) {
 			b.writes(" = 0;\n")
+		} else if s := riscvRVVZeroes[typ.QID()[1]]; (s != "") && (typ.QID()[0] == t.IDBase) {
+			// RISC-V Vector types are sizeless, so "= {0}" is invalid C.
+			b.printf(" = %s;\n", s)
 		} else {
 			b.writes(" = {0};\n")
 		}
@@ -40,19 +40,23 @@ const (
 	KArg
 	KAssert
 	KAssign
+	KCase
 	KChoose
 	KConst
+	KEnum
 	KExpr
 	KField
 	KFile
 	KFunc
 	KIOBind
 	KIf
+	KInterface
 	KIterate
 	KJump
 	KRet
 	KStatus
 	KStruct
+	KSwitch
 	KTypeExpr
 	KUse
 	KVar
@@ -69,26 +73,30 @@ func (k Kind) String() string {
 var kindStrings = [...]string{
 	KInvalid: "KInvalid",
 
-	KArg:      "KArg",
-	KAssert:   "KAssert",
-	KAssign:   "KAssign",
-	KChoose:   "KChoose",
-	KConst:    "KConst",
-	KExpr:     "KExpr",
-	KField:    "KField",
-	KFile:     "KFile",
-	KFunc:     "KFunc",
-	KIOBind:   "KIOBind",
-	KIf:       "KIf",
-	KIterate:  "KIterate",
-	KJump:     "KJump",
-	KRet:      "KRet",
-	KStatus:   "KStatus",
-	KStruct:   "KStruct",
-	KTypeExpr: "KTypeExpr",
-	KUse:      "KUse",
-	KVar:      "KVar",
-	KWhile:    "KWhile",
+	KArg:       "KArg",
+	KAssert:    "KAssert",
+	KAssign:    "KAssign",
+	KCase:      "KCase",
+	KChoose:    "KChoose",
+	KConst:     "KConst",
+	KEnum:      "KEnum",
+	KExpr:      "KExpr",
+	KField:     "KField",
+	KFile:      "KFile",
+	KFunc:      "KFunc",
+	KIOBind:    "KIOBind",
+	KIf:        "KIf",
+	KInterface: "KInterface",
+	KIterate:   "KIterate",
+	KJump:      "KJump",
+	KRet:       "KRet",
+	KStatus:    "KStatus",
+	KStruct:    "KStruct",
+	KSwitch:    "KSwitch",
+	KTypeExpr:  "KTypeExpr",
+	KUse:       "KUse",
+	KVar:       "KVar",
+	KWhile:     "KWhile",
 }
 
 type Flags uint32
@@ -107,6 +115,7 @@ const (
 	FlagsPrivateData      = Flags(0x00008000)
 	FlagsChoosy           = Flags(0x00010000)
 	FlagsHasChooseCPUArch = Flags(0x00020000)
+	FlagsUnion            = Flags(0x00040000)
 )
 
 func (f Flags) AsEffect() Effect { return Effect(f) }
@@ -163,19 +172,23 @@ type Node struct {
 	// Arg           .             .             name          Arg
 	// Assert        keyword       .             lit(reason)   Assert
 	// Assign        operator      .             .             Assign
+	// Case          keyword       .             .             Case
 	// Choose        .             .             name          Choose
 	// Const         .             pkg           name          Const
+	// Enum          .             pkg           name          Enum
 	// Expr          operator      .             literal/ident Expr
 	// Field         .             .             name          Field
 	// File          .             .             .             File
 	// Func          funcName      receiverPkg   receiverName  Func
 	// IOBind        .             .             .             IOBind
 	// If            .             .             .             If
+	// Interface     .             pkg           name          Interface
 	// Iterate       advance       label         length        Iterate
 	// Jump          keyword       label         .             Jump
 	// Ret           keyword       .             .             Ret
 	// Status        keyword       pkg           lit(message)  Status
 	// Struct        .             pkg           name          Struct
+	// Switch        .             .             .             Switch
 	// TypeExpr      decorator     pkg           name          TypeExpr
 	// Use           .             .             lit(path)     Use
 	// Var           operator      .             name          Var
@@ -199,27 +212,31 @@ func (n *Node) MType() *TypeExpr               { return n.mType }
 func (n *Node) SetMBounds(x interval.IntRange) { n.mBounds = x }
 func (n *Node) SetMType(x *TypeExpr)           { n.mType = x }
 
-func (n *Node) AsArg() *Arg           { return (*Arg)(n) }
-func (n *Node) AsAssert() *Assert     { return (*Assert)(n) }
-func (n *Node) AsAssign() *Assign     { return (*Assign)(n) }
-func (n *Node) AsChoose() *Choose     { return (*Choose)(n) }
-func (n *Node) AsConst() *Const       { return (*Const)(n) }
-func (n *Node) AsExpr() *Expr         { return (*Expr)(n) }
-func (n *Node) AsField() *Field       { return (*Field)(n) }
-func (n *Node) AsFile() *File         { return (*File)(n) }
-func (n *Node) AsFunc() *Func         { return (*Func)(n) }
-func (n *Node) AsIOBind() *IOBind     { return (*IOBind)(n) }
-func (n *Node) AsIf() *If             { return (*If)(n) }
-func (n *Node) AsIterate() *Iterate   { return (*Iterate)(n) }
-func (n *Node) AsJump() *Jump         { return (*Jump)(n) }
-func (n *Node) AsRaw() *Raw           { return (*Raw)(n) }
-func (n *Node) AsRet() *Ret           { return (*Ret)(n) }
-func (n *Node) AsStatus() *Status     { return (*Status)(n) }
-func (n *Node) AsStruct() *Struct     { return (*Struct)(n) }
-func (n *Node) AsTypeExpr() *TypeExpr { return (*TypeExpr)(n) }
-func (n *Node) AsUse() *Use           { return (*Use)(n) }
-func (n *Node) AsVar() *Var           { return (*Var)(n) }
-func (n *Node) AsWhile() *While       { return (*While)(n) }
+func (n *Node) AsArg() *Arg             { return (*Arg)(n) }
+func (n *Node) AsAssert() *Assert       { return (*Assert)(n) }
+func (n *Node) AsAssign() *Assign       { return (*Assign)(n) }
+func (n *Node) AsCase() *Case           { return (*Case)(n) }
+func (n *Node) AsChoose() *Choose       { return (*Choose)(n) }
+func (n *Node) AsConst() *Const         { return (*Const)(n) }
+func (n *Node) AsEnum() *Enum           { return (*Enum)(n) }
+func (n *Node) AsExpr() *Expr           { return (*Expr)(n) }
+func (n *Node) AsField() *Field         { return (*Field)(n) }
+func (n *Node) AsFile() *File           { return (*File)(n) }
+func (n *Node) AsFunc() *Func           { return (*Func)(n) }
+func (n *Node) AsIOBind() *IOBind       { return (*IOBind)(n) }
+func (n *Node) AsIf() *If               { return (*If)(n) }
+func (n *Node) AsInterface() *Interface { return (*Interface)(n) }
+func (n *Node) AsIterate() *Iterate     { return (*Iterate)(n) }
+func (n *Node) AsJump() *Jump           { return (*Jump)(n) }
+func (n *Node) AsRaw() *Raw             { return (*Raw)(n) }
+func (n *Node) AsRet() *Ret             { return (*Ret)(n) }
+func (n *Node) AsStatus() *Status       { return (*Status)(n) }
+func (n *Node) AsStruct() *Struct       { return (*Struct)(n) }
+func (n *Node) AsSwitch() *Switch       { return (*Switch)(n) }
+func (n *Node) AsTypeExpr() *TypeExpr   { return (*TypeExpr)(n) }
+func (n *Node) AsUse() *Use             { return (*Use)(n) }
+func (n *Node) AsVar() *Var             { return (*Var)(n) }
+func (n *Node) AsWhile() *While         { return (*While)(n) }
 
 func (n *Node) Walk(f func(*Node) error) error {
 	if n != nil {
@@ -287,6 +304,7 @@ type Raw Node
 func (n *Raw) AsNode() *Node                  { return (*Node)(n) }
 func (n *Raw) Flags() Flags                   { return n.flags }
 func (n *Raw) FilenameLine() (string, uint32) { return n.filename, n.line }
+func (n *Raw) IDs() [3]t.ID                   { return [3]t.ID{n.id0, n.id1, n.id2} }
 func (n *Raw) SubNodes() [3]*Node             { return [3]*Node{n.lhs, n.mhs, n.rhs} }
 func (n *Raw) SubLists() [3][]*Node           { return [3][]*Node{n.list0, n.list1, n.list2} }
 
@@ -298,7 +316,7 @@ func (n *Raw) SetPackage(tm *t.Map, pkg t.ID) error {
 		default:
 			return nil
 
-		case KConst, KFunc, KStatus, KStruct:
+		case KConst, KEnum, KFunc, KInterface, KStatus, KStruct:
 			// No-op.
 
 		case KExpr:
@@ -461,9 +479,9 @@ func NewExpr(flags Flags, operator t.ID, ident t.ID, lhs *Node, mhs *Node, rhs *
 	}
 }
 
-// Assert is "assert RHS via ID2(args)", "choose etc", "pre etc", "inv etc" or
-// "post etc":
-//  - ID0:   <IDAssert|IDChoose|IDPre|IDInv|IDPost>
+// Assert is "assert RHS via ID2(args)", "choose etc", "pre etc", "inv etc",
+// "post etc" or "decreases etc":
+//  - ID0:   <IDAssert|IDChoose|IDPre|IDInv|IDPost|IDDecreases>
 //  - ID2:   <"-string literal> reason
 //  - RHS:   <Expr>
 //  - List0: <Arg> reason arguments
@@ -491,7 +509,8 @@ func (n *Assert) IsChooseCPUArch() bool {
 		return false
 	}
 	switch rhs.Ident() {
-	case t.IDARMCRC32, t.IDARMNeon, t.IDX86SSE42, t.IDX86AVX2, t.IDX86BMI2:
+	case t.IDARMCRC32, t.IDARMNeon, t.IDARMSHA2, t.IDRISCVRVV, t.IDWASMSIMD128,
+		t.IDX86SSE42, t.IDX86AVX2, t.IDX86BMI2, t.IDX86SHA:
 		return true
 	}
 	return false
@@ -583,9 +602,9 @@ func NewField(flags Flags, name t.ID, xType *TypeExpr) *Field {
 	}
 }
 
-// IOBind is "io_bind (io:LHS, data:MHS) { List2 }" or "io_limit (io:LHS,
-// limit:MHS) { List2 }":
-//  - ID0:   <IDIOBind|IDIOLimit>
+// IOBind is "io_bind (io:LHS, data:MHS) { List2 }", "io_checksum (io:LHS,
+// hasher:MHS) { List2 }" or "io_limit (io:LHS, limit:MHS) { List2 }":
+//  - ID0:   <IDIOBind|IDIOChecksum|IDIOLimit>
 //  - LHS:   <Expr>
 //  - MHS:   <Expr>
 //  - List2: <Statement> body
@@ -652,11 +671,12 @@ func NewIterate(label t.ID, assigns []*Node, length t.ID, advance t.ID, unroll t
 	}
 }
 
-// While is "while.ID1 MHS, List1 { List2 } endwhile.ID1":
+// While is "while.ID1 MHS, List1, decreases RHS { List2 } endwhile.ID1":
 //  - FlagsHasBreak    is the while has an explicit break
 //  - FlagsHasContinue is the while has an explicit continue
 //  - ID1:   <0|label>
 //  - MHS:   <Expr>
+//  - RHS:   <nil|Expr> variant
 //  - List1: <Assert> asserts
 //  - List2: <Statement> body
 //
@@ -669,6 +689,7 @@ func (n *While) HasContinue() bool { return n.flags&FlagsHasContinue != 0 }
 func (n *While) Keyword() t.ID     { return t.IDWhile }
 func (n *While) Label() t.ID       { return n.id1 }
 func (n *While) Condition() *Expr  { return n.mhs.AsExpr() }
+func (n *While) Variant() *Expr    { return n.rhs.AsExpr() }
 func (n *While) Asserts() []*Node  { return n.list1 }
 func (n *While) Body() []*Node     { return n.list2 }
 
@@ -681,11 +702,12 @@ func (n *While) IsWhileTrue() bool {
 	return (condition.Operator() == 0) && (condition.Ident() == t.IDTrue)
 }
 
-func NewWhile(label t.ID, condition *Expr, asserts []*Node) *While {
+func NewWhile(label t.ID, condition *Expr, asserts []*Node, variant *Expr) *While {
 	return &While{
 		kind:  KWhile,
 		id1:   label,
 		mhs:   condition.AsNode(),
+		rhs:   variant.AsNode(),
 		list1: asserts,
 	}
 }
@@ -713,6 +735,49 @@ func NewIf(condition *Expr, bodyIfTrue []*Node, bodyIfFalse []*Node, elseIf *If)
 	}
 }
 
+// Switch is "switch MHS { List2 }":
+//  - MHS:   <Expr>
+//  - List2: <Case> arms
+//
+// A switch is exhaustive: exactly one of its arms runs. Either the arms' values
+// cover every possible value of MHS or the final arm is a default arm.
+type Switch Node
+
+func (n *Switch) AsNode() *Node  { return (*Node)(n) }
+func (n *Switch) Value() *Expr   { return n.mhs.AsExpr() }
+func (n *Switch) Cases() []*Node { return n.list2 }
+
+func NewSwitch(value *Expr, cases []*Node) *Switch {
+	return &Switch{
+		kind:  KSwitch,
+		mhs:   value.AsNode(),
+		list2: cases,
+	}
+}
+
+// Case is "case List0 { List2 }" or "default { List2 }":
+//  - ID0:   <IDCase|IDDefault>
+//  - List0: <Expr> values, empty for the default arm
+//  - List2: <Statement> body
+type Case Node
+
+func (n *Case) AsNode() *Node    { return (*Node)(n) }
+func (n *Case) Keyword() t.ID    { return n.id0 }
+func (n *Case) IsDefault() bool  { return n.id0 == t.IDDefault }
+func (n *Case) Values() []*Node  { return n.list0 }
+func (n *Case) Body() []*Node    { return n.list2 }
+func (n *Case) Filename() string { return n.filename }
+func (n *Case) Line() uint32     { return n.line }
+
+func NewCase(keyword t.ID, values []*Node, body []*Node) *Case {
+	return &Case{
+		kind:  KCase,
+		id0:   keyword,
+		list0: values,
+		list2: body,
+	}
+}
+
 // Choose is "choose ID2: List0":
 //  - ID2:   name
 //  - List0: <Expr> method names.
@@ -841,6 +906,10 @@ func (n *TypeExpr) IsEtcUtilityType() bool {
 	return n.id0 == 0 && n.id1 == t.IDBase && n.id2.IsEtcUtility()
 }
 
+func (n *TypeExpr) IsFloatType() bool {
+	return n.id0 == 0 && n.id1 == t.IDBase && n.id2.IsFloatType()
+}
+
 func (n *TypeExpr) IsIdeal() bool {
 	return n.id0 == 0 && n.id1 == t.IDBase && n.id2 == t.IDQIdeal
 }
@@ -956,6 +1025,7 @@ const MaxBodyDepth = 255
 //  - ID2:   <0|receiverName>
 //  - LHS:   <Struct> in-parameters
 //  - RHS:   <Struct> out-parameters
+//  - List0: <Expr> initial choices (for choosy functions)
 //  - List1: <Assert> asserts
 //  - List2: <Statement> body
 type Func Node
@@ -972,6 +1042,7 @@ func (n *Func) Receiver() t.QID        { return t.QID{n.id1, n.id2} }
 func (n *Func) FuncName() t.ID         { return n.id0 }
 func (n *Func) In() *Struct            { return n.lhs.AsStruct() }
 func (n *Func) Out() *TypeExpr         { return n.rhs.AsTypeExpr() }
+func (n *Func) Choices() []*Node       { return n.list0 }
 func (n *Func) Asserts() []*Node       { return n.list1 }
 func (n *Func) Body() []*Node          { return n.list2 }
 
@@ -1009,7 +1080,7 @@ func (n *Func) CheckChooseCompatible(o *Func) error {
 	return nil
 }
 
-func NewFunc(flags Flags, filename string, line uint32, receiverName t.ID, funcName t.ID, in *Struct, out *TypeExpr, asserts []*Node, body []*Node) *Func {
+func NewFunc(flags Flags, filename string, line uint32, receiverName t.ID, funcName t.ID, in *Struct, out *TypeExpr, choices []*Node, asserts []*Node, body []*Node) *Func {
 	return &Func{
 		kind:     KFunc,
 		flags:    flags,
@@ -1019,6 +1090,7 @@ func NewFunc(flags Flags, filename string, line uint32, receiverName t.ID, funcN
 		id2:      receiverName,
 		lhs:      in.AsNode(),
 		rhs:      out.AsNode(),
+		list0:    choices,
 		list1:    asserts,
 		list2:    body,
 	}
@@ -1078,9 +1150,10 @@ func NewConst(flags Flags, filename string, line uint32, name t.ID, xType *TypeE
 // implement.
 const MaxImplements = 63
 
-// Struct is "struct ID2? implements List0 (List1)":
+// Struct is "struct ID2? implements List0 (List1)" or "union ID2 (List1)":
 //  - FlagsPublic      is "pub" vs "pri"
 //  - FlagsClassy      is "ID2" vs "ID2?"
+//  - FlagsUnion       is "union" vs "struct"
 //  - ID1:   <0|pkg> (set by calling SetPackage)
 //  - ID2:   name
 //  - List0: <TypeExpr> implements
@@ -1088,11 +1161,16 @@ const MaxImplements = 63
 //
 // The question mark indicates a classy struct - one that supports methods,
 // especially coroutines.
+//
+// A union's fields (its members) share storage. At most one member is active
+// at any one time, tracked by a tag that the implied is_foo and set_foo!
+// methods (for a member named foo) query and update.
 type Struct Node
 
 func (n *Struct) AsNode() *Node       { return (*Node)(n) }
 func (n *Struct) Classy() bool        { return n.flags&FlagsClassy != 0 }
 func (n *Struct) Public() bool        { return n.flags&FlagsPublic != 0 }
+func (n *Struct) Union() bool         { return n.flags&FlagsUnion != 0 }
 func (n *Struct) Filename() string    { return n.filename }
 func (n *Struct) Line() uint32        { return n.line }
 func (n *Struct) QID() t.QID          { return t.QID{n.id1, n.id2} }
@@ -1111,6 +1189,62 @@ func NewStruct(flags Flags, filename string, line uint32, name t.ID, implements
 	}
 }
 
+// Interface is "interface ID2 (List0)":
+//  - FlagsPublic      is "pub" vs "pri"
+//  - ID1:   <0|pkg> (set by calling SetPackage)
+//  - ID2:   name
+//  - List0: <Func> methods, whose receiver is this interface
+//
+// The methods have no bodies. A struct that "implements" an interface has to
+// provide methods with matching names and signatures.
+type Interface Node
+
+func (n *Interface) AsNode() *Node    { return (*Node)(n) }
+func (n *Interface) Public() bool     { return n.flags&FlagsPublic != 0 }
+func (n *Interface) Filename() string { return n.filename }
+func (n *Interface) Line() uint32     { return n.line }
+func (n *Interface) QID() t.QID       { return t.QID{n.id1, n.id2} }
+func (n *Interface) Methods() []*Node { return n.list0 }
+
+func NewInterface(flags Flags, filename string, line uint32, name t.ID, methods []*Node) *Interface {
+	return &Interface{
+		kind:     KInterface,
+		flags:    flags,
+		filename: filename,
+		line:     line,
+		id2:      name,
+		list0:    methods,
+	}
+}
+
+// Enum is "enum ID2 (List0)":
+//  - FlagsPublic      is "pub" vs "pri"
+//  - ID1:   <0|pkg> (set by calling SetPackage)
+//  - ID2:   name
+//  - List0: <Expr> members, each a bare identifier
+//
+// The members' values are 0, 1, 2, etc. in the order that they are listed.
+// Zero-initialized memory therefore holds the first member.
+type Enum Node
+
+func (n *Enum) AsNode() *Node    { return (*Node)(n) }
+func (n *Enum) Public() bool     { return n.flags&FlagsPublic != 0 }
+func (n *Enum) Filename() string { return n.filename }
+func (n *Enum) Line() uint32     { return n.line }
+func (n *Enum) QID() t.QID       { return t.QID{n.id1, n.id2} }
+func (n *Enum) Members() []*Node { return n.list0 }
+
+func NewEnum(flags Flags, filename string, line uint32, name t.ID, members []*Node) *Enum {
+	return &Enum{
+		kind:     KEnum,
+		flags:    flags,
+		filename: filename,
+		line:     line,
+		id2:      name,
+		list0:    members,
+	}
+}
+
 // Use is "use ID2":
 //  - ID2:   <"-string literal> package path
 type Use Node
@@ -1154,13 +1288,14 @@ func NewFile(filename string, topLevelDecls []*Node) *File {
 //  - Iterate
 //  - Jump
 //  - Ret
+//  - Switch
 //  - Var
 //  - While
 
 // Terminates returns whether a block of statements terminates. In other words,
 // whether the block is non-empty and its final statement is a "return",
-// "break", "continue", a "while true" that doesn't break or an "if-else" chain
-// where all branches terminate.
+// "break", "continue", a "while true" that doesn't break, an "if-else" chain
+// where all branches terminate or a "switch" where all arms terminate.
 func Terminates(body []*Node) bool {
 	if len(body) == 0 {
 		return false
@@ -1184,6 +1319,13 @@ func Terminates(body []*Node) bool {
 		}
 	case KJump:
 		return true
+	case KSwitch:
+		for _, o := range n.AsSwitch().Cases() {
+			if !Terminates(o.AsCase().Body()) {
+				return false
+			}
+		}
+		return true
 	case KRet:
 		return n.AsRet().Keyword() == t.IDReturn
 	case KWhile:
@@ -44,6 +44,10 @@ func TestString(tt *testing.T) {
 		"x.y",
 		"x.y.z.a",
 
+		`"#bad chunk"`,
+		`"#bad chunk".with_payload(payload: x)`,
+		`pkg."#bad chunk".with_payload(payload: x + 1)`,
+
 		"+x",
 		"-(x + y)",
 		"not x",
@@ -32,11 +32,16 @@ var FourCCs = [...][2]string{
 	{"BRTL", "Brotli"},
 	{"BZ2 ", "Bzip2"},
 	{"CBOR", "Concise Binary Object Representation"},
+	{"CMNT", "Comment (Metadata)"},
 	{"CSS ", "Cascading Style Sheets"},
+	{"CUR ", "Cursor"},
 	{"EPS ", "Encapsulated PostScript"},
+	{"EXIF", "Exchangeable Image File Format (Metadata)"},
+	{"EXR ", "OpenEXR"},
 	{"FLAC", "Free Lossless Audio Codec"},
 	{"GIF ", "Graphics Interchange Format"},
 	{"GZ  ", "GNU Zip"},
+	{"HDR ", "Radiance High Dynamic Range (RGBE)"},
 	{"HEIF", "High Efficiency Image File"},
 	{"HTML", "Hypertext Markup Language"},
 	{"ICCP", "International Color Consortium Profile"},
@@ -50,7 +55,10 @@ var FourCCs = [...][2]string{
 	{"LZ4 ", "Lempel–Ziv 4"},
 	{"MD  ", "Markdown"},
 	{"MP3 ", "MPEG-1 Audio Layer III"},
+	{"MTIM", "Modification Time (Metadata)"},
+	{"NAME", "File Name (Metadata)"},
 	{"NIE ", "Naive Image"},
+	{"ORNT", "Orientation (Metadata)"},
 	{"OTF ", "Open Type Format"},
 	{"PDF ", "Portable Document Format"},
 	{"PNG ", "Portable Network Graphics"},
@@ -117,6 +125,7 @@ var Consts = [...]struct {
 	{t.IDU32, "0xA0000888", "PIXEL_FORMAT__RGB"},
 	{t.IDU32, "0xA1008888", "PIXEL_FORMAT__RGBA_NONPREMUL"},
 	{t.IDU32, "0xA100BBBB", "PIXEL_FORMAT__RGBA_NONPREMUL_4X16LE"},
+	{t.IDU32, "0xA108DDDD", "PIXEL_FORMAT__RGBA_NONPREMUL_4XF32LE"},
 	{t.IDU32, "0xA2008888", "PIXEL_FORMAT__RGBA_PREMUL"},
 	{t.IDU32, "0xA200BBBB", "PIXEL_FORMAT__RGBA_PREMUL_4X16LE"},
 	{t.IDU32, "0xA3008888", "PIXEL_FORMAT__RGBA_BINARY"},
@@ -128,6 +137,7 @@ var Consts = [...]struct {
 	// ----
 
 	{t.IDU32, "1", "QUIRK_IGNORE_CHECKSUM"},
+	{t.IDU32, "2", "QUIRK_DST_RETAINS_HISTORY"},
 
 	// ----
 
@@ -143,6 +153,7 @@ var Consts = [...]struct {
 	{t.IDU32, "5", "TOKEN__VBC__NUMBER"},
 	{t.IDU32, "6", "TOKEN__VBC__INLINE_INTEGER_SIGNED"},
 	{t.IDU32, "7", "TOKEN__VBC__INLINE_INTEGER_UNSIGNED"},
+	{t.IDU32, "8", "TOKEN__VBC__COMMENT"},
 
 	// ----
 
@@ -153,6 +164,12 @@ var Consts = [...]struct {
 
 	// ----
 
+	{t.IDU32, "0x00002", "TOKEN__VBD__COMMENT__BLOCK"},
+	{t.IDU32, "0x00004", "TOKEN__VBD__COMMENT__LINE"},
+	{t.IDU32, "0x00006", "TOKEN__VBD__COMMENT__ANY"},
+
+	// ----
+
 	{t.IDU32, "0x00001", "TOKEN__VBD__STRUCTURE__PUSH"},
 	{t.IDU32, "0x00002", "TOKEN__VBD__STRUCTURE__POP"},
 	{t.IDU32, "0x00010", "TOKEN__VBD__STRUCTURE__FROM_NONE"},
@@ -215,6 +232,8 @@ var Statuses = [...]string{
 	`"@I/O redirect"`,
 	`"@end of data"`,
 	`"@metadata reported"`,
+	`"@pass decoded"`,
+	`"@row group decoded"`,
 
 	// Suspensions.
 	`"$even more information"`,
@@ -243,6 +262,7 @@ var Statuses = [...]string{
 	`"#no more information"`,
 	`"#not enough data"`,
 	`"#out of bounds"`,
+	`"#resource limit exceeded"`,
 	`"#unsupported method"`,
 	`"#unsupported option"`,
 	`"#unsupported pixel swizzler option"`,
@@ -259,6 +279,9 @@ var Types = []string{
 	"u32",
 	"u64",
 
+	"f32",
+	"f64",
+
 	"empty_struct",
 	"bool",
 	"utility",
@@ -306,6 +329,15 @@ var Types = []string{
 
 	"x86_sse42_utility",
 	"x86_m128i",
+
+	"riscv_rvv_utility",
+	"riscv_rvv_u8m1",
+	"riscv_rvv_u16m2",
+	"riscv_rvv_u32m1",
+	"riscv_rvv_u32m4",
+
+	"wasm_simd128_utility",
+	"wasm_v128",
 }
 
 var Funcs = [][]string{
@@ -315,27 +347,57 @@ var Funcs = [][]string{
 
 var funcsOther = [...]string{
 	"u8.high_bits(n: u32[..= 7]) u8",
+	"u8.leading_zeros() u32[..= 8]",
 	"u8.low_bits(n: u32[..= 7]) u8",
 	"u8.max(a: u8) u8",
 	"u8.min(a: u8) u8",
+	"u8.popcount() u32[..= 8]",
+	"u8.trailing_zeros() u32[..= 8]",
 
 	"u16.high_bits(n: u32[..= 15]) u16",
+	"u16.leading_zeros() u32[..= 16]",
 	"u16.low_bits(n: u32[..= 15]) u16",
 	"u16.max(a: u16) u16",
 	"u16.min(a: u16) u16",
+	"u16.popcount() u32[..= 16]",
+	"u16.trailing_zeros() u32[..= 16]",
 
 	"u32.high_bits(n: u32[..= 31]) u32",
+	"u32.leading_zeros() u32[..= 32]",
 	"u32.low_bits(n: u32[..= 31]) u32",
 	"u32.max(a: u32) u32",
 	"u32.min(a: u32) u32",
+	"u32.popcount() u32[..= 32]",
+	"u32.trailing_zeros() u32[..= 32]",
 
 	"u64.high_bits(n: u32[..= 63]) u64",
+	"u64.leading_zeros() u32[..= 64]",
 	"u64.low_bits(n: u32[..= 63]) u64",
 	"u64.max(a: u64) u64",
 	"u64.min(a: u64) u64",
+	"u64.popcount() u32[..= 64]",
+	"u64.trailing_zeros() u32[..= 64]",
+
+	// ---- f32, f64
+
+	"f32.to_bits() u32",
+	"f32.to_u8_saturating() u8",
+	"f32.to_u16_saturating() u16",
+	"f32.to_u32_saturating() u32",
+	"f32.to_u64_saturating() u64",
+
+	"f64.to_bits() u64",
+	"f64.to_u8_saturating() u8",
+	"f64.to_u16_saturating() u16",
+	"f64.to_u32_saturating() u32",
+	"f64.to_u64_saturating() u64",
 
 	// ---- utility
 
+	"utility.composite_nonpremul_over_nonpremul!(dst: slice u8, src: slice u8) u64",
+	"utility.composite_nonpremul_over_premul!(dst: slice u8, src: slice u8) u64",
+	"utility.composite_premul_over_nonpremul!(dst: slice u8, src: slice u8) u64",
+	"utility.composite_premul_over_premul!(dst: slice u8, src: slice u8) u64",
 	"utility.cpu_arch_is_32_bit() bool",
 	"utility.empty_io_reader() io_reader",
 	"utility.empty_io_writer() io_writer",
@@ -346,6 +408,8 @@ var funcsOther = [...]string{
 	"utility.empty_rect_ii_u32() rect_ii_u32",
 	"utility.empty_rect_ie_u32() rect_ie_u32",
 	"utility.empty_slice_u8() slice u8",
+	"utility.make_f32_from_bits(a: u32) f32",
+	"utility.make_f64_from_bits(a: u64) f64",
 	"utility.make_pixel_format(repr: u32) pixel_format",
 	"utility.make_range_ii_u32(min_incl: u32, max_incl: u32) range_ii_u32",
 	"utility.make_range_ie_u32(min_incl: u32, max_excl: u32) range_ie_u32",
@@ -390,6 +454,12 @@ var funcsOther = [...]string{
 	"status.is_ok() bool",
 	"status.is_suspension() bool",
 
+	// payload is the status' optional, status-specific detail, or zero.
+	// with_payload returns a copy of the status with that detail set, e.g.
+	// `return "#bad chunk".with_payload(payload: this.chunk_type as base.u64)`.
+	"status.payload() u64",
+	"status.with_payload(payload: u64) status",
+
 	// ---- io_reader
 
 	"io_reader.can_undo_byte() bool",
@@ -424,6 +494,13 @@ var funcsOther = [...]string{
 	"io_reader.read_u64be?() u64",
 	"io_reader.read_u64le?() u64",
 
+	// read_uvarint64 reads an unsigned LEB128 varint, up to 10 bytes long.
+	// read_svarint64 also zig-zag decodes it, like protobuf's sint64 and Go's
+	// binary.ReadVarint. Both fail with "#bad data" if the encoding does not
+	// fit in 64 bits.
+	"io_reader.read_uvarint64?() u64",
+	"io_reader.read_svarint64?() i64",
+
 	// TODO: these should have an explicit pre-condition "length() >= N". For
 	// now, that's implicitly checked (i.e. hard coded).
 	//
@@ -475,6 +552,12 @@ var funcsOther = [...]string{
 	"io_reader.valid_utf_8_length(up_to: u64) u64",
 
 	"io_reader.limited_copy_u32_to_slice!(up_to: u32, s: slice u8) u32",
+	"io_reader.limited_copy_u64_to_slice!(up_to: u64, s: slice u8) u64",
+
+	// seek suspends with "$mispositioned read" until the position() is the
+	// given position. The generated C code records that position for the
+	// caller, via a wuffs_foo__bar__seek_io_position function.
+	"io_reader.seek?(position: u64)",
 
 	"io_reader.skip?(n: u64)",
 	"io_reader.skip_u32?(n: u32)",
@@ -502,6 +585,11 @@ var funcsOther = [...]string{
 	"io_writer.write_u64be?(a: u64)",
 	"io_writer.write_u64le?(a: u64)",
 
+	// write_uvarint64 and write_svarint64 are the inverses of io_reader's
+	// read_uvarint64 and read_svarint64. They write between 1 and 10 bytes.
+	"io_writer.write_uvarint64?(a: u64)",
+	"io_writer.write_svarint64?(a: i64)",
+
 	// TODO: these should have an explicit pre-condition "length() >= N". For
 	// now, that's implicitly checked (i.e. hard coded).
 	//
@@ -534,6 +622,9 @@ var funcsOther = [...]string{
 	"io_writer.limited_copy_u32_from_history!(up_to: u32, distance: u32) u32",
 	"io_writer.limited_copy_u32_from_reader!(up_to: u32, r: io_reader) u32",
 	"io_writer.limited_copy_u32_from_slice!(up_to: u32, s: slice u8) u32",
+	"io_writer.limited_copy_u64_from_history!(up_to: u64, distance: u64) u64",
+	"io_writer.limited_copy_u64_from_reader!(up_to: u64, r: io_reader) u64",
+	"io_writer.limited_copy_u64_from_slice!(up_to: u64, s: slice u8) u64",
 
 	// TODO: this should have explicit pre-conditions:
 	//  - up_to <= this.length()
@@ -565,8 +656,24 @@ var funcsOther = [...]string{
 		"value_extension: u64[..= 0x3FFF_FFFF_FFFF]," +
 		"continued: u32[..= 0x1], length: u32[..= 0xFFFF])",
 
+	// write_u64_token_pair_fast writes two tokens: a simple token whose
+	// value_minor's low 18 bits are or-ed with the value's high 18 bits and
+	// whose continued bit is set, followed by an extended token holding the
+	// value's low 46 bits. The continued and length arguments apply to the
+	// second token. The first token's length is zero. This is a continued
+	// value (see /doc/note/tokens.md) with one extended token. Wider values
+	// call write_extended_token_fast once per further 46 bits.
+	"token_writer.write_u64_token_pair_fast!(" +
+		"value_major: u32[..= 0x1F_FFFF], value_minor: u32[..= 0x1FF_FFFF], value: u64," +
+		"continued: u32[..= 0x1], length: u32[..= 0xFFFF])",
+
 	"token_writer.length() u64",
 
+	// ---- decode_frame_options
+
+	"decode_frame_options.report_passes() bool",
+	"decode_frame_options.row_group_height() u32",
+
 	// ---- frame_config
 
 	"frame_config.blend() u8",
@@ -597,6 +704,8 @@ var funcsOther = [...]string{
 
 	// ---- pixel_swizzler
 
+	"pixel_swizzler.apply_decode_frame_options!(opts: nptr decode_frame_options) status",
+
 	"pixel_swizzler.prepare!(" +
 		"dst_pixfmt: pixel_format, dst_palette: slice u8," +
 		"src_pixfmt: pixel_format, src_palette: slice u8, blend: pixel_blend) status",
@@ -670,9 +779,9 @@ var funcsOther = [...]string{
 	"arm_neon_u32x2.as_u8x8() arm_neon_u8x8",
 	"arm_neon_u64x1.as_u8x8() arm_neon_u8x8",
 
-	"arm_neon_u16x8.as_u16x8() arm_neon_u16x8",
-	"arm_neon_u16x8.as_u32x4() arm_neon_u32x4",
-	"arm_neon_u16x8.as_u64x2() arm_neon_u64x2",
+	"arm_neon_u8x16.as_u16x8() arm_neon_u16x8",
+	"arm_neon_u8x16.as_u32x4() arm_neon_u32x4",
+	"arm_neon_u8x16.as_u64x2() arm_neon_u64x2",
 
 	"arm_neon_u16x8.as_u8x16() arm_neon_u8x16",
 	"arm_neon_u32x4.as_u8x16() arm_neon_u8x16",
@@ -720,6 +829,7 @@ var funcsOther = [...]string{
 	"x86_m128i._mm_add_epi32(b: x86_m128i) x86_m128i",
 	"x86_m128i._mm_add_epi64(b: x86_m128i) x86_m128i",
 	"x86_m128i._mm_add_epi8(b: x86_m128i) x86_m128i",
+	"x86_m128i._mm_alignr_epi8(b: x86_m128i, imm8: u32) x86_m128i",
 	"x86_m128i._mm_and_si128(b: x86_m128i) x86_m128i",
 	"x86_m128i._mm_avg_epu16(b: x86_m128i) x86_m128i",
 	"x86_m128i._mm_avg_epu8(b: x86_m128i) x86_m128i",
@@ -750,6 +860,9 @@ var funcsOther = [...]string{
 	"x86_m128i._mm_min_epu8(b: x86_m128i) x86_m128i",
 	"x86_m128i._mm_packus_epi16(b: x86_m128i) x86_m128i",
 	"x86_m128i._mm_sad_epu8(b: x86_m128i) x86_m128i",
+	"x86_m128i._mm_sha256msg1_epu32(b: x86_m128i) x86_m128i",
+	"x86_m128i._mm_sha256msg2_epu32(b: x86_m128i) x86_m128i",
+	"x86_m128i._mm_sha256rnds2_epu32(b: x86_m128i, k: x86_m128i) x86_m128i",
 	"x86_m128i._mm_shuffle_epi32(imm8: u32) x86_m128i",
 	"x86_m128i._mm_shuffle_epi8(b: x86_m128i) x86_m128i",
 	"x86_m128i._mm_slli_epi16(imm8: u32) x86_m128i",
@@ -773,6 +886,127 @@ var funcsOther = [...]string{
 	"x86_m128i._mm_unpacklo_epi64(b: x86_m128i) x86_m128i",
 	"x86_m128i._mm_unpacklo_epi8(b: x86_m128i) x86_m128i",
 	"x86_m128i._mm_xor_si128(b: x86_m128i) x86_m128i",
+
+	// ---- riscv_rvv_utility
+
+	// The vl (vector length) arguments are the number of elements to
+	// process, as returned by vsetvl_etc. Assigning "vl = etc.vsetvl_etc(avl:
+	// x)" also gives the fact "vl <= x", as the hardware never sets vl
+	// higher than the application vector length (avl). VLEN is at most 65536
+	// bits, so vl is at most 8192 bytes (e8m1) or 2048 words (e32m1).
+
+	"riscv_rvv_utility.vsetvl_e8m1(avl: u64) u64[..= 8192]",
+	"riscv_rvv_utility.vsetvl_e32m1(avl: u64) u64[..= 2048]",
+	"riscv_rvv_utility.vsetvlmax_e8m1() u64[..= 8192]",
+	"riscv_rvv_utility.vsetvlmax_e32m1() u64[..= 2048]",
+
+	"riscv_rvv_utility.make_u16m2_index(vl: u64) riscv_rvv_u16m2",
+
+	"riscv_rvv_utility.make_u8m1_repeat(a: u8, vl: u64) riscv_rvv_u8m1",
+	"riscv_rvv_utility.make_u16m2_repeat(a: u16, vl: u64) riscv_rvv_u16m2",
+	"riscv_rvv_utility.make_u32m1_repeat(a: u32, vl: u64) riscv_rvv_u32m1",
+	"riscv_rvv_utility.make_u32m4_repeat(a: u32, vl: u64) riscv_rvv_u32m4",
+
+	"riscv_rvv_utility.make_u8m1_slice_vl(a: slice base.u8, vl: u64) riscv_rvv_u8m1",
+
+	// ---- riscv_rvv_u8m1
+
+	"riscv_rvv_u8m1.store_slice_vl!(a: slice base.u8, vl: u64)",
+
+	"riscv_rvv_u8m1.vadd_vv_u8m1(b: riscv_rvv_u8m1, vl: u64) riscv_rvv_u8m1",
+	"riscv_rvv_u8m1.vand_vv_u8m1(b: riscv_rvv_u8m1, vl: u64) riscv_rvv_u8m1",
+	"riscv_rvv_u8m1.vmaxu_vv_u8m1(b: riscv_rvv_u8m1, vl: u64) riscv_rvv_u8m1",
+	"riscv_rvv_u8m1.vminu_vv_u8m1(b: riscv_rvv_u8m1, vl: u64) riscv_rvv_u8m1",
+	"riscv_rvv_u8m1.vor_vv_u8m1(b: riscv_rvv_u8m1, vl: u64) riscv_rvv_u8m1",
+	"riscv_rvv_u8m1.vsub_vv_u8m1(b: riscv_rvv_u8m1, vl: u64) riscv_rvv_u8m1",
+	"riscv_rvv_u8m1.vwaddu_vv_u16m2(b: riscv_rvv_u8m1, vl: u64) riscv_rvv_u16m2",
+	"riscv_rvv_u8m1.vwmulu_vx_u16m2(b: u8, vl: u64) riscv_rvv_u16m2",
+	"riscv_rvv_u8m1.vxor_vv_u8m1(b: riscv_rvv_u8m1, vl: u64) riscv_rvv_u8m1",
+
+	// ---- riscv_rvv_u16m2
+
+	"riscv_rvv_u16m2.vadd_vv_u16m2(b: riscv_rvv_u16m2, vl: u64) riscv_rvv_u16m2",
+	"riscv_rvv_u16m2.vrsub_vx_u16m2(b: u16, vl: u64) riscv_rvv_u16m2",
+	"riscv_rvv_u16m2.vwaddu_wv_u16m2(b: riscv_rvv_u8m1, vl: u64) riscv_rvv_u16m2",
+	"riscv_rvv_u16m2.vwmulu_vv_u32m4(b: riscv_rvv_u16m2, vl: u64) riscv_rvv_u32m4",
+	"riscv_rvv_u16m2.vwmulu_vx_u32m4(b: u16, vl: u64) riscv_rvv_u32m4",
+	"riscv_rvv_u16m2.vwredsumu_vs_u16m2_u32m1(b: riscv_rvv_u32m1, vl: u64) riscv_rvv_u32m1",
+
+	// ---- riscv_rvv_u32m1
+
+	"riscv_rvv_u32m1.vmv_x_s_u32m1_u32() u32",
+
+	// ---- riscv_rvv_u32m4
+
+	"riscv_rvv_u32m4.vadd_vv_u32m4(b: riscv_rvv_u32m4, vl: u64) riscv_rvv_u32m4",
+	"riscv_rvv_u32m4.vmul_vx_u32m4(b: u32, vl: u64) riscv_rvv_u32m4",
+	"riscv_rvv_u32m4.vredsum_vs_u32m4_u32m1(b: riscv_rvv_u32m1, vl: u64) riscv_rvv_u32m1",
+	"riscv_rvv_u32m4.vwaddu_wv_u32m4(b: riscv_rvv_u16m2, vl: u64) riscv_rvv_u32m4",
+
+	// ---- wasm_simd128_utility
+
+	"wasm_simd128_utility.make_v128_multiple_u8(" +
+		"a00: u8, a01: u8, a02: u8, a03: u8, a04: u8, a05: u8, a06: u8, a07: u8, " +
+		"a08: u8, a09: u8, a10: u8, a11: u8, a12: u8, a13: u8, a14: u8, a15: u8) wasm_v128",
+	"wasm_simd128_utility.make_v128_multiple_u16(" +
+		"a00: u16, a01: u16, a02: u16, a03: u16, a04: u16, a05: u16, a06: u16, a07: u16) wasm_v128",
+	"wasm_simd128_utility.make_v128_multiple_u32(" +
+		"a00: u32, a01: u32, a02: u32, a03: u32) wasm_v128",
+	"wasm_simd128_utility.make_v128_multiple_u64(" +
+		"a00: u64, a01: u64) wasm_v128",
+
+	"wasm_simd128_utility.make_v128_repeat_u8(a: u8) wasm_v128",
+	"wasm_simd128_utility.make_v128_repeat_u16(a: u16) wasm_v128",
+	"wasm_simd128_utility.make_v128_repeat_u32(a: u32) wasm_v128",
+	"wasm_simd128_utility.make_v128_repeat_u64(a: u64) wasm_v128",
+
+	"wasm_simd128_utility.make_v128_slice128(a: slice base.u8) wasm_v128",
+
+	"wasm_simd128_utility.make_v128_zeroes() wasm_v128",
+
+	// ---- wasm_v128
+
+	"wasm_v128.store_slice128!(a: slice base.u8)",
+
+	"wasm_v128.truncate_u32() u32",
+	"wasm_v128.truncate_u64() u64",
+
+	// The lane index arguments must be constant expressions.
+
+	"wasm_v128.wasm_i16x8_add(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_i16x8_mul(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_i16x8_shl(b: u32) wasm_v128",
+	"wasm_v128.wasm_i16x8_sub(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_i32x4_add(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_i32x4_dot_i16x8(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_i32x4_mul(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_i32x4_shl(b: u32) wasm_v128",
+	"wasm_v128.wasm_i32x4_sub(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_i64x2_add(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_i8x16_add(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_i8x16_sub(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_i8x16_swizzle(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_u16x8_extadd_pairwise_u8x16() wasm_v128",
+	"wasm_v128.wasm_u16x8_extend_high_u8x16() wasm_v128",
+	"wasm_v128.wasm_u16x8_extend_low_u8x16() wasm_v128",
+	"wasm_v128.wasm_u16x8_extract_lane(b: u32[..= 7]) u16",
+	"wasm_v128.wasm_u16x8_shr(b: u32) wasm_v128",
+	"wasm_v128.wasm_u32x4_extadd_pairwise_u16x8() wasm_v128",
+	"wasm_v128.wasm_u32x4_extend_high_u16x8() wasm_v128",
+	"wasm_v128.wasm_u32x4_extend_low_u16x8() wasm_v128",
+	"wasm_v128.wasm_u32x4_extract_lane(b: u32[..= 3]) u32",
+	"wasm_v128.wasm_u32x4_shr(b: u32) wasm_v128",
+	"wasm_v128.wasm_u64x2_extract_lane(b: u32[..= 1]) u64",
+	"wasm_v128.wasm_u8x16_avg(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_u8x16_extract_lane(b: u32[..= 15]) u8",
+	"wasm_v128.wasm_u8x16_max(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_u8x16_min(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_u8x16_narrow_i16x8(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_u8x16_sub_sat(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_v128_and(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_v128_andnot(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_v128_or(b: wasm_v128) wasm_v128",
+	"wasm_v128.wasm_v128_xor(b: wasm_v128) wasm_v128",
 }
 
 var Interfaces = []string{
@@ -846,6 +1080,17 @@ var SliceFuncs = []string{
 }
 
 var SliceU8Funcs = []string{
+	// copy_within copies n bytes from src_index to dst_index, like C's
+	// memmove: the two ranges may overlap. It copies fewer bytes if either
+	// range would extend past the end of the slice, and returns the number of
+	// bytes copied.
+	"GENERIC T1.copy_within!(dst_index: u64, src_index: u64, n: u64) u64",
+	"GENERIC T1.fill!(value: u8)",
+
+	"GENERIC T1.equal_fold(s: T1) bool",
+	"GENERIC T1.index_of(s: T1) u64",
+	"GENERIC T1.index_of_fold(s: T1) u64",
+
 	"GENERIC T1.peek_u8() u8",
 	"GENERIC T1.peek_u16be() u16",
 	"GENERIC T1.peek_u16le() u16",
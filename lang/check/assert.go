@@ -27,24 +27,7 @@ import (
 // equivalent to "thisHS > thatHS"). If not, it returns (0, nil).
 func otherHandSide(n *a.Expr, thisHS *a.Expr) (op t.ID, thatHS *a.Expr) {
 	op = n.Operator()
-
-	reverseOp := t.ID(0)
-	switch op {
-	case t.IDXBinaryNotEq:
-		reverseOp = t.IDXBinaryNotEq
-	case t.IDXBinaryLessThan:
-		reverseOp = t.IDXBinaryGreaterThan
-	case t.IDXBinaryLessEq:
-		reverseOp = t.IDXBinaryGreaterEq
-	case t.IDXBinaryEqEq:
-		reverseOp = t.IDXBinaryEqEq
-	case t.IDXBinaryGreaterEq:
-		reverseOp = t.IDXBinaryLessEq
-	case t.IDXBinaryGreaterThan:
-		reverseOp = t.IDXBinaryLessThan
-	}
-
-	if reverseOp != 0 {
+	if reverseOp := reverseComparisonOp(op); reverseOp != 0 {
 		if thisHS.Eq(n.LHS().AsExpr()) {
 			return op, n.RHS().AsExpr()
 		}
@@ -55,6 +38,27 @@ func otherHandSide(n *a.Expr, thisHS *a.Expr) (op t.ID, thatHS *a.Expr) {
 	return 0, nil
 }
 
+// reverseComparisonOp returns the op such that "x op y" is equivalent to "y
+// op0 x". For example, it maps "<" to ">". It returns 0 if op0 is not a
+// comparison operator.
+func reverseComparisonOp(op0 t.ID) t.ID {
+	switch op0 {
+	case t.IDXBinaryNotEq:
+		return t.IDXBinaryNotEq
+	case t.IDXBinaryLessThan:
+		return t.IDXBinaryGreaterThan
+	case t.IDXBinaryLessEq:
+		return t.IDXBinaryGreaterEq
+	case t.IDXBinaryEqEq:
+		return t.IDXBinaryEqEq
+	case t.IDXBinaryGreaterEq:
+		return t.IDXBinaryLessEq
+	case t.IDXBinaryGreaterThan:
+		return t.IDXBinaryLessThan
+	}
+	return 0
+}
+
 type facts []*a.Expr
 
 func (z *facts) appendBinaryOpFact(op t.ID, lhs *a.Expr, rhs *a.Expr) {
@@ -64,7 +68,30 @@ func (z *facts) appendBinaryOpFact(op t.ID, lhs *a.Expr, rhs *a.Expr) {
 	z.appendFact(o)
 }
 
+// mentionsFloat returns whether n or any of its sub-expressions has a floating
+// point type.
+func mentionsFloat(n *a.Expr) bool {
+	return n.AsNode().Walk(func(o *a.Node) error {
+		if o.Kind() == a.KExpr {
+			if typ := o.MType(); (typ != nil) && typ.IsFloatType() {
+				return errMentionsFloat
+			}
+		}
+		return nil
+	}) != nil
+}
+
+var errMentionsFloat = errors.New("mentions float")
+
 func (z *facts) appendFact(fact *a.Expr) {
+	// Facts never mention floating point values. The bounds checker reasons
+	// about integers, and IEEE 754 arithmetic (with rounding, infinities and
+	// NaNs) does not obey the same rules. For example, "not (x < y)" does not
+	// imply "x >= y" if either is NaN.
+	if mentionsFloat(fact) {
+		return
+	}
+
 	// TODO: make this faster than O(N) by keeping facts sorted somehow?
 	for _, x := range *z {
 		if x.Eq(fact) {
@@ -310,6 +337,10 @@ func (q *checker) proveBinaryOp(op t.ID, lhs *a.Expr, rhs *a.Expr) error {
 			}
 		}
 	}
+
+	if rel := q.relation(lhs, rhs); (rel != 0) && opImpliesOp(rel, op) {
+		return nil
+	}
 	return errFailed
 }
 
@@ -322,12 +353,162 @@ func opImpliesOp(op0 t.ID, op1 t.ID) bool {
 	switch op0 {
 	case t.IDXBinaryLessThan:
 		return op1 == t.IDXBinaryNotEq || op1 == t.IDXBinaryLessEq
+	case t.IDXBinaryEqEq:
+		return op1 == t.IDXBinaryLessEq || op1 == t.IDXBinaryGreaterEq
 	case t.IDXBinaryGreaterThan:
 		return op1 == t.IDXBinaryNotEq || op1 == t.IDXBinaryGreaterEq
 	}
 	return false
 }
 
+// maxRelationFacts bounds the number of facts that relation considers, as its
+// graph search is quadratic in the number of distinct sub-expressions.
+const maxRelationFacts = 256
+
+// relation returns the strongest comparison op such that the facts imply "lhs
+// op rhs", or 0 if they do not imply any ordering. Unlike proveBinaryOp's
+// direct look-up, it can chain facts relating two non-constant expressions,
+// such as "lhs <= x" and "x < rhs" (implying "lhs < rhs").
+//
+// The facts form a graph. Each node is an expression and each edge "x -> y"
+// means that "x <= y" or, for a strict edge, "x < y". Besides the facts'
+// edges, there are implicit edges between distinct constants, and from "e" to
+// "e + c" (or from "e - c" to "e") for a non-negative constant c.
+func (q *checker) relation(lhs *a.Expr, rhs *a.Expr) t.ID {
+	if len(q.facts) > maxRelationFacts {
+		return 0
+	}
+
+	type edge struct {
+		dst    int
+		strict bool
+	}
+	keys := map[string]int{}
+	exprs := []*a.Expr(nil)
+	edges := [][]edge(nil)
+	node := func(n *a.Expr) int {
+		k := n.Str(q.tm)
+		if i, ok := keys[k]; ok {
+			return i
+		}
+		i := len(exprs)
+		keys[k] = i
+		exprs = append(exprs, n)
+		edges = append(edges, nil)
+		return i
+	}
+	addEdge := func(x *a.Expr, y *a.Expr, strict bool) {
+		i, j := node(x), node(y)
+		edges[i] = append(edges[i], edge{j, strict})
+	}
+	// Over the integers, "x < e + 1" and "e - 1 < y" imply "x <= e" and "e <=
+	// y". An "i < n + 1" fact is typical after an "i += 1" assignment.
+	addStrictEdge := func(x *a.Expr, y *a.Expr) {
+		addEdge(x, y, true)
+		if op, yLHS, yRHS := parseBinaryOp(y); (op == t.IDXBinaryPlus) &&
+			(yRHS.ConstValue() != nil) && (yRHS.ConstValue().Cmp(one) == 0) {
+			addEdge(x, yLHS, false)
+		}
+		if op, xLHS, xRHS := parseBinaryOp(x); (op == t.IDXBinaryMinus) &&
+			(xRHS.ConstValue() != nil) && (xRHS.ConstValue().Cmp(one) == 0) {
+			addEdge(xLHS, y, false)
+		}
+	}
+
+	node(lhs)
+	node(rhs)
+	for _, x := range q.facts {
+		xOp, xLHS, xRHS := parseBinaryOp(x)
+		switch xOp {
+		case t.IDXBinaryLessThan:
+			addStrictEdge(xLHS, xRHS)
+		case t.IDXBinaryLessEq:
+			addEdge(xLHS, xRHS, false)
+		case t.IDXBinaryEqEq:
+			addEdge(xLHS, xRHS, false)
+			addEdge(xRHS, xLHS, false)
+		case t.IDXBinaryGreaterEq:
+			addEdge(xRHS, xLHS, false)
+		case t.IDXBinaryGreaterThan:
+			addStrictEdge(xRHS, xLHS)
+		}
+	}
+
+	// Add the implicit edges. Adding them can add nodes, so iterate over a
+	// fixed prefix of exprs.
+	for i, n := range exprs[:len(exprs):len(exprs)] {
+		op, nLHS, nRHS := parseBinaryOp(n)
+		if (op != t.IDXBinaryPlus) && (op != t.IDXBinaryMinus) {
+			continue
+		} else if cv := nRHS.ConstValue(); (cv == nil) || (cv.Sign() < 0) {
+			continue
+		} else if op == t.IDXBinaryPlus {
+			addEdge(nLHS, n, cv.Sign() > 0)
+		} else {
+			edges[i] = append(edges[i], edge{node(nLHS), cv.Sign() > 0})
+		}
+	}
+	for i, x := range exprs {
+		xcv := x.ConstValue()
+		if xcv == nil {
+			continue
+		}
+		for j, y := range exprs {
+			if ycv := y.ConstValue(); (ycv != nil) && (xcv.Cmp(ycv) < 0) {
+				edges[i] = append(edges[i], edge{j, true})
+			}
+		}
+	}
+
+	// reach returns whether there is a path from src to dst and, if so,
+	// whether there is one that takes at least one strict edge.
+	reach := func(src int, dst int) (found bool, strict bool) {
+		// seen[k][i] is whether node i is reachable with strictness k.
+		seen := [2][]bool{make([]bool, len(exprs)), make([]bool, len(exprs))}
+		type state struct {
+			i int
+			k int
+		}
+		stack := []state{{src, 0}}
+		seen[0][src] = true
+		for len(stack) > 0 {
+			s := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, e := range edges[s.i] {
+				k := s.k
+				if e.strict {
+					k = 1
+				}
+				if !seen[k][e.dst] {
+					seen[k][e.dst] = true
+					stack = append(stack, state{e.dst, k})
+				}
+			}
+		}
+		return seen[0][dst] || seen[1][dst], seen[1][dst]
+	}
+
+	l, r := keys[lhs.Str(q.tm)], keys[rhs.Str(q.tm)]
+	if l == r {
+		return t.IDXBinaryEqEq
+	}
+	lrFound, lrStrict := reach(l, r)
+	rlFound, rlStrict := reach(r, l)
+	switch {
+	case lrStrict:
+		return t.IDXBinaryLessThan
+	case rlStrict:
+		return t.IDXBinaryGreaterThan
+	case lrFound && rlFound:
+		return t.IDXBinaryEqEq
+	case lrFound:
+		return t.IDXBinaryLessEq
+	case rlFound:
+		return t.IDXBinaryGreaterEq
+	}
+	return 0
+}
+
 func errFailedOrNil(ok bool) error {
 	if ok {
 		return nil
@@ -343,8 +524,12 @@ func proveReasonRequirement(q *checker, op t.ID, lhs *a.Expr, rhs *a.Expr) error
 			"check: internal error: proveReasonRequirement token (0x%X) is not an XBinaryOp", op)
 	}
 	if err := q.proveBinaryOp(op, lhs, rhs); err != nil {
+		proved, note := q.smtProveBinaryOp(op, lhs, rhs)
+		if proved {
+			return nil
+		}
 		n := a.NewExpr(0, op, 0, lhs.AsNode(), nil, rhs.AsNode(), nil)
-		return fmt.Errorf("cannot prove %q: %v", n.Str(q.tm), err)
+		return smtNote(fmt.Errorf("cannot prove %q: %v", n.Str(q.tm), err), note)
 	}
 	return nil
 }
@@ -117,6 +117,42 @@ func max(i, j *big.Int) *big.Int {
 	return j
 }
 
+// countBitsBounds returns the bounds of a numeric type's leading_zeros,
+// popcount or trailing_zeros methods, given the bounds of an nBits-bit
+// unsigned integer receiver.
+//
+// For example, if x is a base.u32[1 ..= 15] then x.leading_zeros() is in the
+// range [28 ..= 31] and x.popcount() and x.trailing_zeros() are in the ranges
+// [1 ..= 4] and [0 ..= 3].
+func countBitsBounds(method t.ID, nBits int, recv bounds) bounds {
+	n := big.NewInt(int64(nBits))
+	loLen := big.NewInt(int64(recv[0].BitLen()))
+	hiLen := big.NewInt(int64(recv[1].BitLen()))
+
+	switch method {
+	case t.IDLeadingZeros:
+		return bounds{
+			big.NewInt(0).Sub(n, hiLen),
+			big.NewInt(0).Sub(n, loLen),
+		}
+
+	case t.IDPopcount:
+		if recv[0].Sign() > 0 {
+			return bounds{one, hiLen}
+		}
+		return bounds{zero, hiLen}
+
+	case t.IDTrailingZeros:
+		if recv[1].Sign() == 0 {
+			return bounds{n, n}
+		} else if recv[0].Sign() > 0 {
+			return bounds{zero, big.NewInt(0).Sub(hiLen, one)}
+		}
+		return bounds{zero, n}
+	}
+	return bounds{zero, n}
+}
+
 // bitMask returns (1<<nBits - 1) as a big integer.
 func bitMask(nBits int) *big.Int {
 	switch nBits {
@@ -212,6 +248,9 @@ func (q *checker) bcheckBlock(block []*a.Node) error {
 		if unreachable {
 			return fmt.Errorf("check: unreachable code")
 		}
+		if (q.c.opts != nil) && (q.c.opts.FactsHook != nil) {
+			q.c.opts.FactsHook(o, q.facts)
+		}
 		if err := q.bcheckStatement(o); err != nil {
 			return err
 		}
@@ -268,6 +307,11 @@ func (q *checker) bcheckStatement(n *a.Node) error {
 			return err
 		}
 
+	case a.KSwitch:
+		if err := q.bcheckSwitch(n.AsSwitch()); err != nil {
+			return err
+		}
+
 	case a.KIterate:
 		n := n.AsIterate()
 		if _, err := q.bcheckExpr(n.UnrollAsExpr(), 0); err != nil {
@@ -316,6 +360,11 @@ func (q *checker) bcheckStatement(n *a.Node) error {
 				return err
 			}
 		}
+		if w, ok := n.JumpTarget().(*a.While); ok && (n.Keyword() == t.IDContinue) {
+			if err := q.terminationContinue(w); err != nil {
+				return err
+			}
+		}
 		q.facts = q.facts[:0]
 
 	case a.KRet:
@@ -331,7 +380,12 @@ func (q *checker) bcheckStatement(n *a.Node) error {
 		}
 
 		if lTyp.IsStatus() {
-			if v := n.Value(); (v.Operator() == 0) || (v.Operator() == a.ExprOperatorSelector) {
+			v := n.Value()
+			// Setting a payload does not change the status' category.
+			if recv, meth, _, ok := v.IsMethodCall(); ok && (meth == t.IDWithPayload) && recv.MType().IsStatus() {
+				v = recv
+			}
+			if (v.Operator() == 0) || (v.Operator() == a.ExprOperatorSelector) {
 				if id := v.Ident(); (id != t.IDOk) && (q.hasIsErrorFact(id) || isErrorStatus(id, q.tm)) {
 					n.SetRetsError()
 				}
@@ -414,9 +468,22 @@ func (q *checker) bcheckAssert(n *a.Assert) error {
 			condition.LHS().AsExpr(), condition.RHS().AsExpr())
 	}
 
+	note := ""
+	if err == errFailed {
+		proved := false
+		if proved, note = q.smtProveCondition(condition); proved {
+			err = nil
+		}
+	}
+
 	if err != nil {
 		if err == errFailed {
-			return fmt.Errorf("check: cannot prove %q", condition.Str(q.tm))
+			err = smtNote(fmt.Errorf("check: cannot prove %q", condition.Str(q.tm)), note)
+			if op, lhs, rhs := parseBinaryOp(condition); isComparisonOp(op) &&
+				((lhs.ConstValue() != nil) || (rhs.ConstValue() != nil)) {
+				err = withSuggestions(err, q.suggestRequirement(op, lhs, rhs))
+			}
+			return err
 		}
 		return fmt.Errorf("check: cannot prove %q: %v", condition.Str(q.tm), err)
 	}
@@ -485,6 +552,15 @@ func (q *checker) bcheckAssignment(lhs *a.Expr, op t.ID, rhs *a.Expr) error {
 		}); err != nil {
 			return err
 		}
+
+		// After "x.set_foo!()", for a union x, "x.is_foo()" is a fact.
+		if _, meth, _, _ := rhs.IsMethodCall(); q.c.isUnionType(recv.MType()) {
+			x, err := q.makeUnionIs(recv, strings.TrimPrefix(meth.Str(q.tm), "set_"))
+			if err != nil {
+				return err
+			}
+			q.facts = append(q.facts, x)
+		}
 	}
 
 	if lhs == nil {
@@ -513,6 +589,10 @@ func (q *checker) bcheckAssignment(lhs *a.Expr, op t.ID, rhs *a.Expr) error {
 							return err
 						}
 					}
+				} else if lTyp.IsFuncType() && (lTyp.Receiver().QID() == t.QID{t.IDBase, t.IDRISCVRVVUtility}) {
+					q.bcheckAssignmentRISCVRVVSetVL(lhs, lTyp.FuncName(), rhs)
+				} else if lTyp.IsFuncType() && lTyp.Receiver().Eq(typeExprSliceU8) {
+					q.bcheckAssignmentSliceIndexOf(lhs, lTyp.FuncName(), rhs)
 				}
 			}
 		}
@@ -543,16 +623,20 @@ func (q *checker) bcheckAssignment(lhs *a.Expr, op t.ID, rhs *a.Expr) error {
 		}
 
 	} else {
-		// Update any facts involving lhs.
+		// Update any facts involving lhs. A fact like "x < lhs" is treated as
+		// its equivalent "lhs > x".
 		if err := q.facts.update(func(x *a.Expr) (*a.Expr, error) {
 			xOp, xLHS, xRHS := parseBinaryOp(x)
+			if isComparisonOp(xOp) && !xLHS.Eq(lhs) && xRHS.Eq(lhs) {
+				xOp, xLHS, xRHS = reverseComparisonOp(xOp), xRHS, xLHS
+			}
 			if xOp == 0 || !xLHS.Eq(lhs) {
 				if x.Mentions(lhs) {
 					return nil, nil
 				}
 				return x, nil
 			}
-			if xRHS.Mentions(lhs) {
+			if xRHS.Mentions(lhs) || rhs.Mentions(lhs) {
 				return nil, nil
 			}
 			switch op {
@@ -604,6 +688,15 @@ func (q *checker) bcheckAssignment1(lhs *a.Expr, lTyp *a.TypeExpr, op t.ID, rhs
 		return bounds{}, fmt.Errorf("check: internal error: missing LHS for op key 0x%X", op)
 	}
 
+	if (lTyp != nil) && lTyp.IsFloatType() {
+		// Floating point values have no bounds to prove, although the RHS's
+		// sub-expressions (e.g. array indexes) might.
+		if _, err := q.bcheckExpr(rhs, 0); err != nil {
+			return bounds{}, err
+		}
+		return bounds{zero, zero}, nil
+	}
+
 	lb, err := bounds{}, (error)(nil)
 	if lTyp != nil {
 		lb, err = q.bcheckTypeExpr(lTyp)
@@ -624,12 +717,25 @@ func (q *checker) bcheckAssignment1(lhs *a.Expr, lTyp *a.TypeExpr, op t.ID, rhs
 
 	if (lTyp != nil) && ((rb[0].Cmp(lb[0]) < 0) || (rb[1].Cmp(lb[1]) > 0)) {
 		if op == t.IDEq {
-			return bounds{}, fmt.Errorf("check: expression %q bounds %v is not within bounds %v",
-				rhs.Str(q.tm), rb, lb)
+			proved, note := q.smtProveBounds(rhs, lb)
+			if !proved {
+				return bounds{}, withSuggestions(
+					smtNote(fmt.Errorf("check: expression %q bounds %v is not within bounds %v",
+						rhs.Str(q.tm), rb, lb), note),
+					q.suggestBounds(rhs, lb))
+			}
 		} else {
-			return bounds{}, fmt.Errorf("check: assignment %q bounds %v is not within bounds %v",
-				lhs.Str(q.tm)+" "+op.Str(q.tm)+" "+rhs.Str(q.tm), rb, lb)
+			n := a.NewExpr(0, op.BinaryForm(), 0, lhs.AsNode(), nil, rhs.AsNode(), nil)
+			n.SetMType(lhs.MType())
+			proved, note := q.smtProveBounds(n, lb)
+			if !proved {
+				return bounds{}, withSuggestions(
+					smtNote(fmt.Errorf("check: assignment %q bounds %v is not within bounds %v",
+						lhs.Str(q.tm)+" "+op.Str(q.tm)+" "+rhs.Str(q.tm), rb, lb), note),
+					q.suggestAssignmentBounds(lhs, op, rhs, lb))
+			}
 		}
+		rb = bounds{max(rb[0], lb[0]), min(rb[1], lb[1])}
 	}
 	return rb, nil
 }
@@ -664,6 +770,33 @@ func (q *checker) bcheckAssignmentMaxMin(lhs *a.Expr, funcName t.ID, rhs *a.Expr
 	return nil
 }
 
+// bcheckAssignmentRISCVRVVSetVL adds the "lhs <= avl" fact for an "lhs =
+// util.vsetvl_etc(avl: avl)" assignment. The RISC-V Vector specification
+// guarantees that the vl returned by vsetvl is no greater than avl, so that
+// (as the avl is typically a slice's length) loading or storing vl elements
+// from that slice is in bounds.
+func (q *checker) bcheckAssignmentRISCVRVVSetVL(lhs *a.Expr, funcName t.ID, rhs *a.Expr) {
+	if !strings.HasPrefix(funcName.Str(q.tm), "vsetvl_") || (len(rhs.Args()) != 1) {
+		return
+	}
+	if avl := rhs.Args()[0].AsArg().Value(); !avl.Mentions(lhs) {
+		q.facts.appendBinaryOpFact(t.IDXBinaryLessEq, lhs, avl)
+	}
+}
+
+// bcheckAssignmentSliceIndexOf adds the "lhs <= x.length()" fact for an "lhs
+// = x.index_of(s: s)" or "lhs = x.index_of_fold(s: s)" assignment. Those
+// methods return x.length() when s is not found, so that "lhs < x.length()"
+// means that it was found and that x[lhs] is in bounds.
+func (q *checker) bcheckAssignmentSliceIndexOf(lhs *a.Expr, funcName t.ID, rhs *a.Expr) {
+	if (funcName != t.IDIndexOf) && (funcName != t.IDIndexOfFold) {
+		return
+	}
+	if x := rhs.LHS().AsExpr().LHS().AsExpr(); !x.Mentions(lhs) {
+		q.facts.appendBinaryOpFact(t.IDXBinaryLessEq, lhs, makeSliceLength(x))
+	}
+}
+
 func snapshot(facts []*a.Expr) []*a.Expr {
 	return append([]*a.Expr(nil), facts...)
 }
@@ -743,7 +876,150 @@ func (q *checker) bcheckIf(n *a.If) error {
 	return q.unify(branches)
 }
 
+func (q *checker) bcheckSwitch(n *a.Switch) error {
+	value := n.Value()
+	vb, err := q.bcheckExpr(value, 0)
+	if err != nil {
+		return err
+	}
+	isEnum := q.c.enumOf(value.MType()) != nil
+	cases := n.Cases()
+	if !isEnum && !cases[len(cases)-1].AsCase().IsDefault() {
+		if err := q.bcheckSwitchExhaustive(n, vb); err != nil {
+			return err
+		}
+	}
+
+	branches := [][]*a.Expr(nil)
+	snap := snapshot(q.facts)
+	for _, o := range cases {
+		o := o.AsCase()
+		for _, v := range o.Values() {
+			if _, err := q.bcheckExpr(v.AsExpr(), 0); err != nil {
+				return err
+			}
+		}
+
+		// Check the arm's body, assuming that the switch value is one of the
+		// arm's values (or, for a default arm, none of the other arms').
+		q.facts = append(q.facts[:0], snap...)
+		if values := o.Values(); len(values) == 1 {
+			q.facts.appendBinaryOpFact(t.IDXBinaryEqEq, value, values[0].AsExpr())
+		} else if len(values) > 1 && !isEnum {
+			min, max := values[0].AsExpr(), values[0].AsExpr()
+			for _, v := range values[1:] {
+				v := v.AsExpr()
+				if v.ConstValue().Cmp(min.ConstValue()) < 0 {
+					min = v
+				}
+				if v.ConstValue().Cmp(max.ConstValue()) > 0 {
+					max = v
+				}
+			}
+			q.facts.appendBinaryOpFact(t.IDXBinaryGreaterEq, value, min)
+			q.facts.appendBinaryOpFact(t.IDXBinaryLessEq, value, max)
+		} else if o.IsDefault() {
+			for _, p := range cases {
+				for _, v := range p.AsCase().Values() {
+					q.facts.appendBinaryOpFact(t.IDXBinaryNotEq, value, v.AsExpr())
+				}
+			}
+		}
+		if err := q.bcheckBlock(o.Body()); err != nil {
+			return err
+		}
+		if !a.Terminates(o.Body()) {
+			branches = append(branches, snapshot(q.facts))
+		}
+	}
+	return q.unify(branches)
+}
+
+// bcheckSwitchExhaustive checks that a switch on an integer value, without a
+// default arm, has a case value for every value within vb, the bounds of the
+// switch value.
+func (q *checker) bcheckSwitchExhaustive(n *a.Switch, vb bounds) error {
+	seen := map[string]bool{}
+	for _, o := range n.Cases() {
+		for _, v := range o.AsCase().Values() {
+			seen[v.AsExpr().ConstValue().String()] = true
+		}
+	}
+	// With N case values, one of the first N+1 values within vb is missing,
+	// unless vb has at most N values. Either way, this loop is short.
+	for i := big.NewInt(0).Set(vb[0]); i.Cmp(vb[1]) <= 0; i.Add(i, one) {
+		if !seen[i.String()] {
+			return fmt.Errorf("check: switch on %q is not exhaustive: missing %v",
+				n.Value().Str(q.tm), i)
+		}
+	}
+	return nil
+}
+
+// loopInvariantFacts returns those facts that the loop body cannot falsify:
+// they only mention numeric local variables (or args) that are not assigned
+// to anywhere in the body, combined by arithmetic and comparison operators.
+// Such facts hold on every loop iteration and after the loop, without having
+// to be re-asserted as inv conditions.
+func (q *checker) loopInvariantFacts(body []*a.Node) []*a.Expr {
+	assigned := map[string]bool{}
+	for _, o := range body {
+		o.Walk(func(o *a.Node) error {
+			switch o.Kind() {
+			case a.KAssign:
+				if lhs := o.AsAssign().LHS(); lhs != nil {
+					assigned[lhs.Str(q.tm)] = true
+				}
+			case a.KVar:
+				assigned[o.AsVar().Name().Str(q.tm)] = true
+			}
+			return nil
+		})
+	}
+
+	ret := []*a.Expr(nil)
+	for _, x := range q.facts {
+		if q.isLoopInvariantFact(x, assigned) {
+			ret = append(ret, x)
+		}
+	}
+	return ret
+}
+
+func (q *checker) isLoopInvariantFact(n *a.Expr, assigned map[string]bool) bool {
+	if n.ConstValue() != nil {
+		return true
+	}
+	switch op := n.Operator(); {
+	case op == 0:
+		return n.MType().IsNumType() && !assigned[n.Str(q.tm)]
+	case op == a.ExprOperatorSelector:
+		return n.MType().IsNumType() && (n.LHS().AsExpr().Ident() == t.IDArgs) &&
+			(n.LHS().AsExpr().Operator() == 0) && !assigned[n.Str(q.tm)]
+	case op.IsXUnaryOp(), op.IsXAssociativeOp():
+	case op.IsXBinaryOp():
+		if op == t.IDXBinaryAs {
+			return false
+		}
+	default:
+		return false
+	}
+	for _, o := range []*a.Node{n.LHS(), n.MHS(), n.RHS()} {
+		if (o != nil) && !q.isLoopInvariantFact(o.AsExpr(), assigned) {
+			return false
+		}
+	}
+	for _, o := range n.Args() {
+		if !q.isLoopInvariantFact(o.AsExpr(), assigned) {
+			return false
+		}
+	}
+	return true
+}
+
 func (q *checker) bcheckWhile(n *a.While) error {
+	invariants := q.loopInvariantFacts(n.Body())
+
 	// Check the pre and inv conditions on entry.
 	for _, o := range n.Asserts() {
 		if o.AsAssert().Keyword() == t.IDPost {
@@ -771,7 +1047,7 @@ func (q *checker) bcheckWhile(n *a.While) error {
 		// prove the post conditions here, since we won't ever exit the while
 		// loop naturally. We only exit on an explicit break.
 	} else {
-		q.facts = q.facts[:0]
+		q.facts = append(q.facts[:0], invariants...)
 		for _, o := range n.Asserts() {
 			if o.AsAssert().Keyword() == t.IDPost {
 				continue
@@ -796,8 +1072,8 @@ func (q *checker) bcheckWhile(n *a.While) error {
 		// We effectively have a "while false { etc }" loop. There's no need to
 		// check the body.
 	} else {
-		// Assume the pre and inv conditions...
-		q.facts = q.facts[:0]
+		// Assume the loop invariant facts, the pre and inv conditions...
+		q.facts = append(q.facts[:0], invariants...)
 		for _, o := range n.Asserts() {
 			if o.AsAssert().Keyword() == t.IDPost {
 				continue
@@ -808,13 +1084,29 @@ func (q *checker) bcheckWhile(n *a.While) error {
 		if cv == nil {
 			q.facts.appendFact(n.Condition())
 		}
+		// Check the variant, if any. Like an assert condition, it must be
+		// within its type's bounds.
+		if v := n.Variant(); v != nil {
+			if _, err := q.bcheckExpr(v, 0); err != nil {
+				return err
+			}
+		}
+		if q.terminationEnabled() {
+			if err := q.terminationBegin(n); err != nil {
+				return err
+			}
+			defer q.terminationEnd(n)
+		}
 		// Check the body.
 		if err := q.bcheckBlock(n.Body()); err != nil {
 			return err
 		}
-		// Check the pre and inv conditions on the implicit continue after the
-		// body.
+		// Check the pre and inv conditions (and that any variant decreased)
+		// on the implicit continue after the body.
 		if !a.Terminates(n.Body()) {
+			if err := q.terminationContinue(n); err != nil {
+				return err
+			}
 			for _, o := range n.Asserts() {
 				if o.AsAssert().Keyword() == t.IDPost {
 					continue
@@ -826,8 +1118,8 @@ func (q *checker) bcheckWhile(n *a.While) error {
 		}
 	}
 
-	// Assume the inv and post conditions.
-	q.facts = q.facts[:0]
+	// Assume the loop invariant facts and the inv and post conditions.
+	q.facts = append(q.facts[:0], invariants...)
 	for _, o := range n.Asserts() {
 		if o.AsAssert().Keyword() == t.IDPre {
 			continue
@@ -878,8 +1170,14 @@ func (q *checker) bcheckExpr(n *a.Expr, depth uint32) (bounds, error) {
 	}
 
 	if (nb[0].Cmp(tb[0]) < 0) || (nb[1].Cmp(tb[1]) > 0) {
-		return bounds{}, fmt.Errorf("check: expression %q bounds %v is not within bounds %v",
-			n.Str(q.tm), nb, tb)
+		proved, note := q.smtProveBounds(n, tb)
+		if !proved {
+			return bounds{}, withSuggestions(
+				smtNote(fmt.Errorf("check: expression %q bounds %v is not within bounds %v",
+					n.Str(q.tm), nb, tb), note),
+				q.suggestBounds(n, tb))
+		}
+		nb = bounds{max(nb[0], tb[0]), min(nb[1], tb[1])}
 	}
 
 	n.SetMBounds(nb)
@@ -917,6 +1215,10 @@ func bcheckExprConstValue(n *a.Expr) (bounds, error) {
 }
 
 func (q *checker) bcheckExpr1(n *a.Expr, depth uint32) (bounds, error) {
+	if isFloatOp(n) {
+		return q.bcheckExprFloatOp(n, depth)
+	}
+
 	switch op := n.Operator(); {
 	case op.IsXUnaryOp():
 		return q.bcheckExprUnaryOp(n, depth)
@@ -932,6 +1234,40 @@ func (q *checker) bcheckExpr1(n *a.Expr, depth uint32) (bounds, error) {
 	return q.bcheckExprOther(n, depth)
 }
 
+// isFloatOp returns whether n is an operator expression that works on floating
+// point values: arithmetic, comparisons or a conversion to a floating point
+// type.
+func isFloatOp(n *a.Expr) bool {
+	switch op := n.Operator(); {
+	case op.IsXUnaryOp(), op.IsXAssociativeOp(), op == t.IDXBinaryAs:
+		return n.MType().IsFloatType()
+	case op.IsXBinaryOp():
+		return n.LHS().AsExpr().MType().IsFloatType() || n.RHS().AsExpr().MType().IsFloatType()
+	}
+	return false
+}
+
+// bcheckExprFloatOp bounds-checks n's operands. The bounds checker does not
+// track floating point values, so the result's bounds are those of n's type.
+func (q *checker) bcheckExprFloatOp(n *a.Expr, depth uint32) (bounds, error) {
+	if o := n.LHS(); o != nil {
+		if _, err := q.bcheckExpr(o.AsExpr(), depth); err != nil {
+			return bounds{}, err
+		}
+	}
+	if o := n.RHS(); (o != nil) && (n.Operator() != t.IDXBinaryAs) {
+		if _, err := q.bcheckExpr(o.AsExpr(), depth); err != nil {
+			return bounds{}, err
+		}
+	}
+	for _, o := range n.Args() {
+		if _, err := q.bcheckExpr(o.AsExpr(), depth); err != nil {
+			return bounds{}, err
+		}
+	}
+	return q.bcheckTypeExpr(n.MType())
+}
+
 func (q *checker) bcheckExprOther(n *a.Expr, depth uint32) (bounds, error) {
 	switch n.Operator() {
 	case 0:
@@ -979,10 +1315,12 @@ func (q *checker) bcheckExprOther(n *a.Expr, depth uint32) (bounds, error) {
 		}
 
 		if err := proveReasonRequirement(q, t.IDXBinaryLessEq, zeroExpr, rhs); err != nil {
-			return bounds{}, err
+			return bounds{}, withSuggestions(err,
+				q.suggestRequirement(t.IDXBinaryLessEq, zeroExpr, rhs))
 		}
 		if err := proveReasonRequirementForRHSLength(q, t.IDXBinaryLessThan, rhs, lengthExpr); err != nil {
-			return bounds{}, err
+			return bounds{}, withSuggestions(err,
+				q.suggestRequirement(t.IDXBinaryLessThan, rhs, lengthExpr))
 		}
 
 	case t.IDDotDot:
@@ -1023,15 +1361,18 @@ func (q *checker) bcheckExprOther(n *a.Expr, depth uint32) (bounds, error) {
 
 		if mhs != zeroExpr {
 			if err := proveReasonRequirement(q, t.IDXBinaryLessEq, zeroExpr, mhs); err != nil {
-				return bounds{}, err
+				return bounds{}, withSuggestions(err,
+					q.suggestRequirement(t.IDXBinaryLessEq, zeroExpr, mhs))
 			}
 		}
 		if err := proveReasonRequirement(q, t.IDXBinaryLessEq, mhs, rhs); err != nil {
-			return bounds{}, err
+			return bounds{}, withSuggestions(err,
+				q.suggestRequirement(t.IDXBinaryLessEq, mhs, rhs))
 		}
 		if rhs != lengthExpr {
 			if err := proveReasonRequirementForRHSLength(q, t.IDXBinaryLessEq, rhs, lengthExpr); err != nil {
-				return bounds{}, err
+				return bounds{}, withSuggestions(err,
+					q.suggestRequirement(t.IDXBinaryLessEq, rhs, lengthExpr))
 			}
 		}
 
@@ -1039,6 +1380,9 @@ func (q *checker) bcheckExprOther(n *a.Expr, depth uint32) (bounds, error) {
 		if _, err := q.bcheckExpr(n.LHS().AsExpr(), depth); err != nil {
 			return bounds{}, err
 		}
+		if err := q.bcheckUnionMember(n); err != nil {
+			return bounds{}, err
+		}
 
 		// TODO: delete this hack that only matches "args".
 		if n.LHS().AsExpr().Ident() == t.IDArgs {
@@ -1130,6 +1474,22 @@ func (q *checker) bcheckExprCallSpecialCases(n *a.Expr, depth uint32) (bounds, e
 				bitMask(int(ab[1].Int64())),
 			}, nil
 
+		case t.IDLeadingZeros, t.IDPopcount, t.IDTrailingZeros:
+			// TODO: lhs has already been bcheck'ed. There should be no
+			// need to bcheck lhs.LHS().Expr() twice.
+			lb, err := q.bcheckExpr(lhs.LHS().AsExpr(), depth)
+			if err != nil {
+				return bounds{}, err
+			}
+			nBits := 0
+			if qid := recvTyp.QID(); (qid[0] == t.IDBase) && (int(qid[1]) < len(numTypeBounds)) {
+				nBits = numTypeBounds[qid[1]][1].BitLen()
+			}
+			if (nBits == 0) || (lb[0].Sign() < 0) {
+				return bounds{}, fmt.Errorf("check: %q's receiver does not have unsigned integer type", n.Str(q.tm))
+			}
+			return countBitsBounds(method, nBits, lb), nil
+
 		case t.IDMin, t.IDMax:
 			// TODO: lhs has already been bcheck'ed. There should be no
 			// need to bcheck lhs.LHS().Expr() twice.
@@ -1234,6 +1594,8 @@ func (q *checker) bcheckExprCallSpecialCases(n *a.Expr, depth uint32) (bounds, e
 				advance = thirtyTwo
 			case strings.HasSuffix(s, "_slice512"): // 512 bits is 64 bytes.
 				advance = sixtyFour
+			case strings.HasSuffix(s, "_slice_vl"): // The vl arg is in bytes.
+				advanceExpr = n.Args()[1].AsArg().Value()
 			}
 		}
 	}
@@ -1479,6 +1841,7 @@ var ioMethodAdvances = [...]struct {
 
 	t.IDWriteSimpleTokenFast - t.IDPeekU8:   {one, true},
 	t.IDWriteExtendedTokenFast - t.IDPeekU8: {one, true},
+	t.IDWriteU64TokenPairFast - t.IDPeekU8:  {two, true},
 }
 
 func makeConstValueExpr(tm *t.Map, cv *big.Int) (*a.Expr, error) {
@@ -1504,6 +1867,41 @@ func makeSliceLength(slice *a.Expr) *a.Expr {
 	return x
 }
 
+// makeUnionIs returns "x.is_foo()", for the union x and its member foo.
+func (q *checker) makeUnionIs(x *a.Expr, memberName string) (*a.Expr, error) {
+	id, err := q.tm.Insert("is_" + memberName)
+	if err != nil {
+		return nil, err
+	}
+	o := a.NewExpr(0, t.IDDot, id, x.AsNode(), nil, nil, nil)
+	o.SetMBounds(bounds{one, one})
+	o.SetMType(a.NewTypeExpr(t.IDFunc, 0, id, x.MType().AsNode(), nil, nil))
+	o = a.NewExpr(0, t.IDOpenParen, 0, o.AsNode(), nil, nil, nil)
+	o.SetMBounds(bounds{zero, one})
+	o.SetMType(typeExprBool)
+	return o, nil
+}
+
+// bcheckUnionMember checks that, if n is "x.foo" for the union x and its
+// member foo, then "x.is_foo()" is a fact.
+func (q *checker) bcheckUnionMember(n *a.Expr) error {
+	x := n.LHS().AsExpr()
+	if !q.c.isUnionType(x.MType()) || n.MType().IsFuncType() {
+		return nil
+	}
+	fact, err := q.makeUnionIs(x, n.Ident().Str(q.tm))
+	if err != nil {
+		return err
+	}
+	for _, o := range q.facts {
+		if o.Eq(fact) {
+			return nil
+		}
+	}
+	return fmt.Errorf("check: cannot prove %q, needed to access union member %q",
+		fact.Str(q.tm), n.Str(q.tm))
+}
+
 // makeSliceLengthEqEq returns "x.length() == n".
 func (q *checker) makeSliceLengthEqEq(x *a.Expr, n t.ID) *a.Expr {
 	lhs := makeSliceLength(x)
@@ -1549,22 +1947,71 @@ func (q *checker) bcheckExprXBinaryPlus(lhs *a.Expr, lb bounds, rhs *a.Expr, rb
 
 func (q *checker) bcheckExprXBinaryMinus(lhs *a.Expr, lb bounds, rhs *a.Expr, rb bounds) (bounds, error) {
 	nb := lb.Sub(rb)
-	for _, x := range q.facts {
-		xOp, xLHS, xRHS := parseBinaryOp(x)
-		if !lhs.Eq(xLHS) || !rhs.Eq(xRHS) {
-			continue
+	switch q.relation(lhs, rhs) {
+	case t.IDXBinaryLessThan:
+		nb[1] = min(nb[1], minusOne)
+	case t.IDXBinaryLessEq:
+		nb[1] = min(nb[1], zero)
+	case t.IDXBinaryEqEq:
+		nb[0] = max(nb[0], zero)
+		nb[1] = min(nb[1], zero)
+	case t.IDXBinaryGreaterEq:
+		nb[0] = max(nb[0], zero)
+	case t.IDXBinaryGreaterThan:
+		nb[0] = max(nb[0], one)
+	}
+	return nb, nil
+}
+
+func (q *checker) bcheckExprXBinarySlashPercent(op t.ID, lhs *a.Expr, lb bounds, rhs *a.Expr, rb bounds) (bounds, error) {
+	// Prohibit division by zero.
+	if rb.ContainsZero() {
+		return bounds{}, fmt.Errorf("check: divide/modulus op argument %q is possibly zero", rhs.Str(q.tm))
+	}
+
+	// Prohibit overflow. In C, both (INT32_MIN / -1) and (INT32_MIN % -1) are
+	// undefined behavior, even though the latter's mathematical value, zero,
+	// is representable.
+	qb, _ := lb.TryQuo(rb)
+	typ := lhs.MType()
+	if (typ == nil) || typ.IsIdeal() {
+		typ = rhs.MType()
+	}
+	if (typ != nil) && !typ.IsIdeal() {
+		tb, err := q.bcheckTypeExpr(typ)
+		if err != nil {
+			return bounds{}, err
 		}
-		switch xOp {
-		case t.IDXBinaryLessThan:
-			nb[1] = min(nb[1], minusOne)
-		case t.IDXBinaryLessEq:
-			nb[1] = min(nb[1], zero)
-		case t.IDXBinaryGreaterEq:
-			nb[0] = max(nb[0], zero)
-		case t.IDXBinaryGreaterThan:
-			nb[0] = max(nb[0], one)
+		if !tb.ContainsIntRange(qb) {
+			return bounds{}, fmt.Errorf("check: divide/modulus op %q bounds %v is not within bounds %v",
+				lhs.Str(q.tm)+" "+op.AmbiguousForm().Str(q.tm)+" "+rhs.Str(q.tm), qb, tb)
 		}
 	}
+
+	if op == t.IDXBinarySlash {
+		return qb, nil
+	}
+
+	// Like C (and the big.Int.Rem method), x % y has the sign of x and is
+	// smaller in magnitude than y. Let y's magnitude range over [yMin, yMax].
+	yMin, yMax := rb[0], rb[1]
+	if rb[0].Sign() < 0 {
+		yMin, yMax = neg(rb[1]), neg(rb[0])
+	}
+
+	// If x's magnitude is always less than yMin then x % y is simply x.
+	if (lb[0].Cmp(neg(yMin)) > 0) && (lb[1].Cmp(yMin) < 0) {
+		return lb, nil
+	}
+
+	m := sub1(yMax)
+	nb := bounds{zero, zero}
+	if lb[0].Sign() < 0 {
+		nb[0] = max(lb[0], neg(m))
+	}
+	if lb[1].Sign() > 0 {
+		nb[1] = min(lb[1], m)
+	}
 	return nb, nil
 }
 
@@ -1593,21 +2040,7 @@ func (q *checker) bcheckExprBinaryOp1(op t.ID, lhs *a.Expr, lb bounds, rhs *a.Ex
 		return lb.Mul(rb), nil
 
 	case t.IDXBinarySlash, t.IDXBinaryPercent:
-		// Prohibit division by zero.
-		if lb[0].Sign() < 0 {
-			return bounds{}, fmt.Errorf("check: divide/modulus op argument %q is possibly negative", lhs.Str(q.tm))
-		}
-		if rb[0].Sign() <= 0 {
-			return bounds{}, fmt.Errorf("check: divide/modulus op argument %q is possibly non-positive", rhs.Str(q.tm))
-		}
-		if op == t.IDXBinarySlash {
-			nb, _ := lb.TryQuo(rb)
-			return nb, nil
-		}
-		return bounds{
-			zero,
-			big.NewInt(0).Sub(rb[1], one),
-		}, nil
+		return q.bcheckExprXBinarySlashPercent(op, lhs, lb, rhs, rb)
 
 	case t.IDXBinaryShiftL, t.IDXBinaryTildeModShiftL, t.IDXBinaryShiftR:
 		shiftBounds := bounds{}
@@ -1795,6 +2228,8 @@ func (q *checker) bcheckTypeExpr1(typ *a.TypeExpr) (bounds, error) {
 				b = x
 			}
 		}
+	} else if e := q.c.enums[qid]; e != nil {
+		b = bounds{zero, big.NewInt(int64(len(e.Members()) - 1))}
 	}
 
 	if typ.IsRefined() {
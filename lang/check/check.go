@@ -19,6 +19,7 @@ package check
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"path"
 	"sort"
 
@@ -36,6 +37,9 @@ type Error struct {
 
 	TMap  *t.Map
 	Facts []*a.Expr
+
+	// Suggestions are assertions, any one of which would have avoided Err.
+	Suggestions []string
 }
 
 func (e *Error) Error() string {
@@ -49,10 +53,18 @@ func (e *Error) Error() string {
 		b = append(b, f.Str(e.TMap)...)
 		b = append(b, '\n')
 	}
+	if len(e.Suggestions) > 0 {
+		b = append(b, "Suggestions (any one of which suffices):\n"...)
+		for _, s := range e.Suggestions {
+			b = append(b, '\t')
+			b = append(b, s...)
+			b = append(b, '\n')
+		}
+	}
 	return string(b)
 }
 
-func Check(tm *t.Map, files []*a.File, resolveUse func(usePath string) ([]byte, error)) (*Checker, error) {
+func Check(tm *t.Map, files []*a.File, resolveUse func(usePath string) ([]byte, error), opts *Options) (*Checker, error) {
 	for _, f := range files {
 		if f == nil {
 			return nil, errors.New("check: Check given a nil *ast.File")
@@ -79,12 +91,14 @@ func Check(tm *t.Map, files []*a.File, resolveUse func(usePath string) ([]byte,
 		tm:         tm,
 		resolveUse: resolveUse,
 		reasonMap:  rMap,
+		opts:       opts,
 
 		topLevelNames: map[t.ID]a.Kind{
 			t.IDBase: a.KUse,
 		},
 
 		consts:   map[t.QID]*a.Const{},
+		enums:    map[t.QID]*a.Enum{},
 		statuses: map[t.QID]*a.Status{},
 		structs:  map[t.QID]*a.Struct{},
 
@@ -95,9 +109,9 @@ func Check(tm *t.Map, files []*a.File, resolveUse func(usePath string) ([]byte,
 		builtInSliceU8Funcs: map[t.QQID]*a.Func{},
 		builtInTableFuncs:   map[t.QQID]*a.Func{},
 
-		builtInInterfaces:     map[t.QID][]t.QQID{},
-		builtInInterfaceFuncs: map[t.QQID]*a.Func{},
-		unseenInterfaceImpls:  map[t.QQID]*a.Func{},
+		interfaces:           map[t.QID][]t.QQID{},
+		interfaceFuncs:       map[t.QQID]*a.Func{},
+		unseenInterfaceImpls: map[t.QQID]*a.Func{},
 	}
 
 	for _, funcs := range builtin.Funcs {
@@ -114,15 +128,15 @@ func Check(tm *t.Map, files []*a.File, resolveUse func(usePath string) ([]byte,
 	if err := c.parseBuiltInFuncs(c.builtInTableFuncs, builtin.TableFuncs); err != nil {
 		return nil, err
 	}
-	if err := c.parseBuiltInFuncs(c.builtInInterfaceFuncs, builtin.InterfaceFuncs); err != nil {
+	if err := c.parseBuiltInFuncs(c.interfaceFuncs, builtin.InterfaceFuncs); err != nil {
 		return nil, err
 	}
 
-	for qqid := range c.builtInInterfaceFuncs {
+	for qqid := range c.interfaceFuncs {
 		qid := t.QID{qqid[0], qqid[1]}
-		c.builtInInterfaces[qid] = append(c.builtInInterfaces[qid], qqid)
+		c.interfaces[qid] = append(c.interfaces[qid], qqid)
 	}
-	for _, qqids := range c.builtInInterfaces {
+	for _, qqids := range c.interfaces {
 		sort.Slice(qqids, func(i int, j int) bool {
 			return qqids[i].LessThan(qqids[j])
 		})
@@ -190,10 +204,14 @@ var phases = [...]struct {
 }{
 	{a.KUse, (*Checker).checkUse},
 	{a.KStatus, (*Checker).checkStatus},
+	{a.KEnum, (*Checker).checkEnumDecl},
 	{a.KConst, (*Checker).checkConst},
+	{a.KInterface, (*Checker).checkInterfaceDecl},
 	{a.KStruct, (*Checker).checkStructDecl},
 	{a.KInvalid, (*Checker).checkStructCycles},
 	{a.KStruct, (*Checker).checkStructFields},
+	{a.KInterface, (*Checker).checkInterfaceMethods},
+	{a.KConst, (*Checker).checkConstStructValue},
 	{a.KFunc, (*Checker).checkFuncSignature},
 	{a.KFunc, (*Checker).checkFuncContract},
 	{a.KFunc, (*Checker).checkFuncImplements},
@@ -211,15 +229,17 @@ type Checker struct {
 	tm         *t.Map
 	resolveUse func(usePath string) ([]byte, error)
 	reasonMap  reasonMap
+	opts       *Options
 
-	// The topLevelNames map is keyed by the const/status/struct/use
+	// The topLevelNames map is keyed by the const/enum/status/struct/use
 	// unqualified name (ID, not QID).
 	//
 	// For `use "foo/bar"`, the name is the base name: "bar".
 	topLevelNames map[t.ID]a.Kind
 
-	// These maps are keyed by the const/status/struct name (QID).
+	// These maps are keyed by the const/enum/status/struct name (QID).
 	consts   map[t.QID]*a.Const
+	enums    map[t.QID]*a.Enum
 	statuses map[t.QID]*a.Status
 	structs  map[t.QID]*a.Struct
 
@@ -231,9 +251,11 @@ type Checker struct {
 	builtInSliceU8Funcs map[t.QQID]*a.Func
 	builtInTableFuncs   map[t.QQID]*a.Func
 
-	builtInInterfaces     map[t.QID][]t.QQID
-	builtInInterfaceFuncs map[t.QQID]*a.Func
-	unseenInterfaceImpls  map[t.QQID]*a.Func
+	// These maps hold the built-in (base) interfaces and any interfaces
+	// declared by this package or used packages.
+	interfaces           map[t.QID][]t.QQID
+	interfaceFuncs       map[t.QQID]*a.Func
+	unseenInterfaceImpls map[t.QQID]*a.Func
 
 	unsortedStructs []*a.Struct
 }
@@ -274,16 +296,29 @@ func (c *Checker) checkUse(node *a.Node) error {
 		return err
 	}
 
+	// Interfaces come first, so that structs can implement them regardless of
+	// the order of the top level declarations.
 	for _, n := range f.TopLevelDecls() {
 		if err := n.AsRaw().SetPackage(c.tm, baseName); err != nil {
 			return err
 		}
+		if n.Kind() == a.KInterface {
+			if err := c.checkInterfaceDecl(n); err != nil {
+				return err
+			}
+		}
+	}
 
+	for _, n := range f.TopLevelDecls() {
 		switch n.Kind() {
 		case a.KConst:
 			if err := c.checkConst(n); err != nil {
 				return err
 			}
+		case a.KEnum:
+			if err := c.checkEnumDecl(n); err != nil {
+				return err
+			}
 		case a.KFunc:
 			if err := c.checkFuncSignature(n); err != nil {
 				return err
@@ -328,6 +363,65 @@ func (c *Checker) checkStatus(node *a.Node) error {
 	return nil
 }
 
+func (c *Checker) checkEnumDecl(node *a.Node) error {
+	n := node.AsEnum()
+	qid := n.QID()
+	if qid[0] == 0 {
+		if c.topLevelNames[qid[1]] != 0 {
+			return &Error{
+				Err:      fmt.Errorf("check: duplicate top level name %q", qid[1].Str(c.tm)),
+				Filename: n.Filename(),
+				Line:     n.Line(),
+			}
+		}
+		c.topLevelNames[qid[1]] = a.KEnum
+	} else if c.enums[qid] != nil {
+		return &Error{
+			Err:      fmt.Errorf("check: duplicate top level name %q", qid.Str(c.tm)),
+			Filename: n.Filename(),
+			Line:     n.Line(),
+		}
+	}
+	c.enums[qid] = n
+
+	typ := a.NewTypeExpr(0, qid[0], qid[1], nil, nil, nil)
+	memberNames := map[t.ID]bool{}
+	for i, o := range n.Members() {
+		o := o.AsExpr()
+		if memberNames[o.Ident()] {
+			return &Error{
+				Err: fmt.Errorf("check: duplicate member %q in enum %s",
+					o.Ident().Str(c.tm), qid.Str(c.tm)),
+				Filename: n.Filename(),
+				Line:     n.Line(),
+			}
+		}
+		memberNames[o.Ident()] = true
+		cv := big.NewInt(int64(i))
+		o.SetConstValue(cv)
+		o.SetMBounds(bounds{cv, cv})
+		o.SetMType(typ)
+	}
+	setPlaceholderMBoundsMType(n.AsNode())
+	return nil
+}
+
+// enumOf returns the enum that typ names, or nil if it does not name one.
+func (c *Checker) enumOf(typ *a.TypeExpr) *a.Enum {
+	if typ.Decorator() != 0 {
+		return nil
+	}
+	return c.enums[typ.QID()]
+}
+
+// isForeignEnumType returns whether typ is or contains an enum declared in a
+// package other than pkg. The C code generator only sees one package at a
+// time, so it cannot zero-initialize or range-check another package's enums.
+func (c *Checker) isForeignEnumType(typ *a.TypeExpr, pkg t.ID) bool {
+	e := c.enumOf(typ.Innermost())
+	return (e != nil) && (e.QID()[0] != pkg)
+}
+
 func (c *Checker) checkConst(node *a.Node) error {
 	n := node.AsConst()
 	qid := n.QID()
@@ -349,6 +443,17 @@ func (c *Checker) checkConst(node *a.Node) error {
 	}
 	c.consts[qid] = n
 
+	// Struct-typed consts (and arrays of them) are checked later, by
+	// checkConstStructValue, after the struct types' fields are checked. They
+	// are private, so a used package's ones are not checked here at all.
+	if isStructTypedConst(n) {
+		if n.Public() {
+			return fmt.Errorf("check: struct-typed const %s must be pri", qid.Str(c.tm))
+		}
+		setPlaceholderMBoundsMType(n.AsNode())
+		return nil
+	}
+
 	q := &checker{
 		c:  c,
 		tm: c.tm,
@@ -388,6 +493,101 @@ func (c *Checker) checkConst(node *a.Node) error {
 	return nil
 }
 
+// isStructTypedConst returns whether n's type, other than any array
+// decorators, is a struct type instead of a numeric type.
+func isStructTypedConst(n *a.Const) bool {
+	typ := n.XType()
+	for i := 0; typ.IsArrayType() && (i < a.MaxTypeExprDepth); i++ {
+		typ = typ.Inner()
+	}
+	return (typ.Decorator() == 0) && (typ.QID()[0] != t.IDBase)
+}
+
+func (c *Checker) checkConstStructValue(node *a.Node) error {
+	n := node.AsConst()
+	if !isStructTypedConst(n) {
+		return nil
+	}
+	qid := n.QID()
+	q := &checker{
+		c:  c,
+		tm: c.tm,
+	}
+	if err := checkTypeExpr(q, n.XType()); err != nil {
+		return fmt.Errorf("%v in const %s", err, qid.Str(c.tm))
+	}
+	if err := q.tcheckExpr(n.Value(), 0); err != nil {
+		return fmt.Errorf("%v in const %s", err, qid.Str(c.tm))
+	}
+	if _, err := q.bcheckExpr(n.Value(), 0); err != nil {
+		return fmt.Errorf("%v in const %s", err, qid.Str(c.tm))
+	}
+	if err := c.checkConstAggregate(n.Value(), n.XType()); err != nil {
+		return fmt.Errorf("check: %v for %s", err, qid.Str(c.tm))
+	}
+	setPlaceholderMBoundsMType(n.AsNode())
+	return nil
+}
+
+// checkConstAggregate checks that n, a const value, matches typ, which can be
+// a numeric type, an array type or a struct type. Arrays must be given all of
+// their elements and structs must be given all of their fields, in order.
+// Such structs must be plain (not classy, not implementing interfaces) and
+// all of their fields must have numeric, array or (recursively) such struct
+// types.
+func (c *Checker) checkConstAggregate(n *a.Expr, typ *a.TypeExpr) error {
+	if typ.IsArrayType() {
+		args, ok := n.IsList()
+		if !ok {
+			return fmt.Errorf("invalid const value %q", n.Str(c.tm))
+		} else if aLen := typ.ArrayLength().ConstValue(); (aLen == nil) || !aLen.IsInt64() ||
+			(aLen.Int64() != int64(len(args))) {
+			return fmt.Errorf("const value %q has %d elements, want %v",
+				n.Str(c.tm), len(args), typ.ArrayLength().Str(c.tm))
+		}
+		for _, o := range args {
+			if err := c.checkConstAggregate(o.AsExpr(), typ.Inner()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if typ.Decorator() != 0 {
+		return fmt.Errorf("invalid const type %q", typ.Str(c.tm))
+	} else if qid := typ.QID(); qid[0] == t.IDBase {
+		if !qid[1].IsNumType() {
+			return fmt.Errorf("invalid const type %q", typ.Str(c.tm))
+		}
+		nb := typ.AsNode().MBounds()
+		if cv := n.ConstValue(); cv == nil || cv.Cmp(nb[0]) < 0 || cv.Cmp(nb[1]) > 0 {
+			return fmt.Errorf("invalid const value %q not within %v", n.Str(c.tm), nb)
+		}
+		return nil
+	}
+
+	s := c.structs[typ.QID()]
+	if s == nil {
+		return fmt.Errorf("invalid const type %q", typ.Str(c.tm))
+	} else if s.Classy() || s.Union() || (len(s.Implements()) != 0) {
+		return fmt.Errorf("invalid const type %q: not a plain struct", typ.Str(c.tm))
+	}
+	args, ok := n.IsList()
+	if !ok {
+		return fmt.Errorf("invalid const value %q", n.Str(c.tm))
+	} else if len(args) != len(s.Fields()) {
+		return fmt.Errorf("const value %q has %d fields, want %d",
+			n.Str(c.tm), len(args), len(s.Fields()))
+	}
+	for i, o := range args {
+		f := s.Fields()[i].AsField()
+		if err := c.checkConstAggregate(o.AsExpr(), f.XType()); err != nil {
+			return fmt.Errorf("%v (field %q)", err, f.Name().Str(c.tm))
+		}
+	}
+	return nil
+}
+
 func (c *Checker) checkConstElement(n *a.Expr, nb bounds, nLists int) error {
 	if nLists > 0 {
 		nLists--
@@ -442,9 +642,11 @@ func (c *Checker) checkStructDecl(node *a.Node) error {
 		o := o.AsTypeExpr()
 		ifaceType := o.QID()
 
-		if (o.Decorator() != 0) || (ifaceType[0] != t.IDBase) ||
-			!builtin.InterfacesMap[ifaceType[1].Str(c.tm)] {
+		if (o.Decorator() != 0) || (c.interfaces[ifaceType] == nil) {
 			return fmt.Errorf("check: invalid interface type %q", o.Str(c.tm))
+		} else if (ifaceType[0] != t.IDBase) && (ifaceType[0] != qid[0]) {
+			return fmt.Errorf("check: struct %q cannot implement %q, from another package",
+				qid.Str(c.tm), o.Str(c.tm))
 		}
 		o.AsNode().SetMBounds(bounds{zero, zero})
 		o.AsNode().SetMType(typeExprTypeExpr)
@@ -452,23 +654,166 @@ func (c *Checker) checkStructDecl(node *a.Node) error {
 		if qid[0] != 0 {
 			continue
 		}
-		for _, ifaceFunc := range c.builtInInterfaces[ifaceType] {
+		for _, ifaceFunc := range c.interfaces[ifaceType] {
 			// Continuing the example, ifaceFunc could be
 			// "base.hasher_u32.update_u32".
 			c.unseenInterfaceImpls[t.QQID{qid[0], qid[1], ifaceFunc[2]}] =
-				c.builtInInterfaceFuncs[ifaceFunc]
+				c.interfaceFuncs[ifaceFunc]
 		}
 	}
 
+	if n.Union() {
+		return c.checkUnionDecl(n)
+	}
+
 	// A struct declaration implies a reset method.
 	in := a.NewStruct(0, n.Filename(), n.Line(), t.IDArgs, nil, nil)
-	f := a.NewFunc(a.EffectImpure.AsFlags(), n.Filename(), n.Line(), qid[1], t.IDReset, in, nil, nil, nil)
+	f := a.NewFunc(a.EffectImpure.AsFlags(), n.Filename(), n.Line(), qid[1], t.IDReset, in, nil, nil, nil, nil)
 	if qid[0] != 0 {
 		f.AsNode().AsRaw().SetPackage(c.tm, qid[0])
 	}
 	return c.checkFuncSignature(f.AsNode())
 }
 
+// checkUnionDecl adds the methods implied by a union declaration. For each
+// member foo, "is_foo" returns whether foo is the active member and "set_foo!"
+// makes it so. A union has no reset method: its tag is reset by its enclosing
+// struct's initializer.
+func (c *Checker) checkUnionDecl(n *a.Struct) error {
+	qid := n.QID()
+	for _, o := range n.Fields() {
+		name := o.AsField().Name().Str(c.tm)
+		isID, err := c.tm.Insert("is_" + name)
+		if err != nil {
+			return err
+		}
+		setID, err := c.tm.Insert("set_" + name)
+		if err != nil {
+			return err
+		}
+
+		for _, x := range [...]struct {
+			flags a.Flags
+			id    t.ID
+			out   *a.TypeExpr
+		}{
+			{0, isID, typeExprBool},
+			{a.EffectImpure.AsFlags(), setID, nil},
+		} {
+			in := a.NewStruct(0, n.Filename(), n.Line(), t.IDArgs, nil, nil)
+			f := a.NewFunc(x.flags, n.Filename(), n.Line(), qid[1], x.id, in, x.out, nil, nil, nil)
+			if qid[0] != 0 {
+				f.AsNode().AsRaw().SetPackage(c.tm, qid[0])
+			}
+			if err := c.checkFuncSignature(f.AsNode()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isUnionType returns whether typ is a union type.
+func (c *Checker) isUnionType(typ *a.TypeExpr) bool {
+	if typ.Decorator() != 0 {
+		return false
+	}
+	s := c.structs[typ.QID()]
+	return (s != nil) && s.Union()
+}
+
+// hasUnionType returns whether typ is or contains (e.g. is a pointer to or an
+// array of) a union type.
+func (c *Checker) hasUnionType(typ *a.TypeExpr) bool {
+	for ; typ != nil; typ = typ.Inner() {
+		if c.isUnionType(typ) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Checker) checkInterfaceDecl(node *a.Node) error {
+	n := node.AsInterface()
+	qid := n.QID()
+	if qid[0] == 0 {
+		if c.topLevelNames[qid[1]] != 0 {
+			return &Error{
+				Err:      fmt.Errorf("check: duplicate top level name %q", qid[1].Str(c.tm)),
+				Filename: n.Filename(),
+				Line:     n.Line(),
+			}
+		}
+		c.topLevelNames[qid[1]] = a.KInterface
+	} else if c.interfaces[qid] != nil {
+		return &Error{
+			Err:      fmt.Errorf("check: duplicate top level name %q", qid.Str(c.tm)),
+			Filename: n.Filename(),
+			Line:     n.Line(),
+		}
+	}
+
+	qqids := make([]t.QQID, 0, len(n.Methods()))
+	for _, o := range n.Methods() {
+		f := o.AsFunc()
+		qqid := f.QQID()
+		if c.interfaceFuncs[qqid] != nil {
+			return &Error{
+				Err:      fmt.Errorf("check: duplicate interface method %q", qqid.Str(c.tm)),
+				Filename: f.Filename(),
+				Line:     f.Line(),
+			}
+		}
+		c.interfaceFuncs[qqid] = f
+		qqids = append(qqids, qqid)
+	}
+	c.interfaces[qid] = qqids
+	setPlaceholderMBoundsMType(n.AsNode())
+	return nil
+}
+
+func (c *Checker) checkInterfaceMethods(node *a.Node) error {
+	for _, o := range node.AsInterface().Methods() {
+		n := o.AsFunc()
+		if err := c.checkFields(n.In().Fields(), true, false, false); err != nil {
+			return &Error{
+				Err:      fmt.Errorf("%v in in-params for func %s", err, n.QQID().Str(c.tm)),
+				Filename: n.Filename(),
+				Line:     n.Line(),
+			}
+		}
+		setPlaceholderMBoundsMType(n.In().AsNode())
+		if out := n.Out(); out != nil {
+			if n.Effect().Coroutine() {
+				return &Error{
+					Err:      fmt.Errorf("func %s has ? effect but non-empty return type", n.QQID().Str(c.tm)),
+					Filename: n.Filename(),
+					Line:     n.Line(),
+				}
+			} else if out.Innermost().IsCPUArchType() {
+				return &Error{
+					Err:      fmt.Errorf("check: cpu_arch type %q not allowed as return type", out.Str(c.tm)),
+					Filename: n.Filename(),
+					Line:     n.Line(),
+				}
+			}
+			q := &checker{
+				c:  c,
+				tm: c.tm,
+			}
+			if err := checkTypeExpr(q, out); err != nil {
+				return &Error{
+					Err:      fmt.Errorf("%v in out-param for func %s", err, n.QQID().Str(c.tm)),
+					Filename: n.Filename(),
+					Line:     n.Line(),
+				}
+			}
+		}
+		setPlaceholderMBoundsMType(n.AsNode())
+	}
+	return nil
+}
+
 func (c *Checker) checkStructCycles(_ *a.Node) error {
 	if _, ok := a.TopologicalSortStructs(c.unsortedStructs); !ok {
 		return fmt.Errorf("check: cyclical struct definitions")
@@ -485,6 +830,25 @@ func (c *Checker) checkStructFields(node *a.Node) error {
 			Line:     n.Line(),
 		}
 	}
+	for _, o := range n.Fields() {
+		f := o.AsField()
+		if c.isForeignEnumType(f.XType(), n.QID()[0]) {
+			return &Error{
+				Err: fmt.Errorf("check: enum type %q, from another package, not allowed for field %q in struct %s",
+					f.XType().Str(c.tm), f.Name().Str(c.tm), n.QID().Str(c.tm)),
+				Filename: n.Filename(),
+				Line:     n.Line(),
+			}
+		}
+		if typ := f.XType(); c.hasUnionType(typ) && !c.isUnionType(typ) {
+			return &Error{
+				Err: fmt.Errorf("check: union type not allowed within type %q for field %q in struct %s",
+					typ.Str(c.tm), f.Name().Str(c.tm), n.QID().Str(c.tm)),
+				Filename: n.Filename(),
+				Line:     n.Line(),
+			}
+		}
+	}
 	return nil
 }
 
@@ -560,8 +924,40 @@ func (c *Checker) checkFuncSignature1(node *a.Node, banCPUArchTypes bool) error
 			Line:     n.Line(),
 		}
 	}
+	for _, o := range n.In().Fields() {
+		if c.hasUnionType(o.AsField().XType()) {
+			return &Error{
+				Err: fmt.Errorf("check: union type %q not allowed for in-param %q for func %s",
+					o.AsField().XType().Str(c.tm), o.AsField().Name().Str(c.tm), n.QQID().Str(c.tm)),
+				Filename: n.Filename(),
+				Line:     n.Line(),
+			}
+		}
+		if c.isForeignEnumType(o.AsField().XType(), n.Receiver()[0]) {
+			return &Error{
+				Err: fmt.Errorf("check: enum type %q, from another package, not allowed for in-param %q for func %s",
+					o.AsField().XType().Str(c.tm), o.AsField().Name().Str(c.tm), n.QQID().Str(c.tm)),
+				Filename: n.Filename(),
+				Line:     n.Line(),
+			}
+		}
+	}
 	setPlaceholderMBoundsMType(n.In().AsNode())
 	if out := n.Out(); out != nil {
+		if c.hasUnionType(out) {
+			return &Error{
+				Err:      fmt.Errorf("check: union type %q not allowed as return type", out.Str(c.tm)),
+				Filename: n.Filename(),
+				Line:     n.Line(),
+			}
+		}
+		if c.isForeignEnumType(out, n.Receiver()[0]) {
+			return &Error{
+				Err:      fmt.Errorf("check: enum type %q, from another package, not allowed as return type", out.Str(c.tm)),
+				Filename: n.Filename(),
+				Line:     n.Line(),
+			}
+		}
 		if n.Effect().Coroutine() && n.Receiver()[0] != t.IDBase {
 			return &Error{
 				Err:      fmt.Errorf("func %s has ? effect but non-empty return type", n.QQID().Str(c.tm)),
@@ -639,13 +1035,16 @@ func (c *Checker) checkFuncSignature1(node *a.Node, banCPUArchTypes bool) error
 
 func (c *Checker) checkFuncContract(node *a.Node) error {
 	n := node.AsFunc()
-	if len(n.Asserts()) == 0 {
+	if (len(n.Asserts()) == 0) && (len(n.Choices()) == 0) {
 		return nil
 	}
 	q := &checker{
 		c:  c,
 		tm: c.tm,
 	}
+	if err := q.tcheckFuncChoices(n); err != nil {
+		return err
+	}
 	for _, o := range n.Asserts() {
 		setPlaceholderMBoundsMType(o)
 		if err := q.tcheckFuncAssert(o.AsAssert()); err != nil {
@@ -691,6 +1090,13 @@ func (c *Checker) checkFuncImplements(node *a.Node) error {
 
 func (c *Checker) checkFuncBody(node *a.Node) error {
 	n := node.AsFunc()
+	if s := c.structs[n.Receiver()]; (s != nil) && s.Union() {
+		return &Error{
+			Err:      fmt.Errorf("check: union %q cannot have methods", n.Receiver().Str(c.tm)),
+			Filename: n.Filename(),
+			Line:     n.Line(),
+		}
+	}
 	if len(n.Body()) == 0 {
 		return nil
 	}
@@ -725,12 +1131,17 @@ func (c *Checker) checkFuncBody(node *a.Node) error {
 	}
 
 	if err := q.bcheckBlock(n.Body()); err != nil {
+		suggestions := []string(nil)
+		if se := (*suggestionError)(nil); errors.As(err, &se) {
+			suggestions = se.suggestions
+		}
 		return &Error{
-			Err:      err,
-			Filename: q.errFilename,
-			Line:     q.errLine,
-			TMap:     c.tm,
-			Facts:    q.facts,
+			Err:         err,
+			Filename:    q.errFilename,
+			Line:        q.errLine,
+			TMap:        c.tm,
+			Facts:       q.facts,
+			Suggestions: suggestions,
 		}
 	}
 
@@ -785,6 +1196,11 @@ func (c *Checker) checkAllTypeChecked(node *a.Node) error {
 			return err
 		}
 	}
+	for _, v := range c.enums {
+		if err := allTypeChecked(c.tm, v.AsNode()); err != nil {
+			return err
+		}
+	}
 	for _, v := range c.statuses {
 		if v == nil {
 			// Built-in statuses have a nil v node.
@@ -806,10 +1222,14 @@ func nodeDebugString(tm *t.Map, n *a.Node) string {
 	switch n.Kind() {
 	case a.KConst:
 		return fmt.Sprintf("%s node %q", n.Kind(), n.AsConst().QID().Str(tm))
+	case a.KEnum:
+		return fmt.Sprintf("%s node %q", n.Kind(), n.AsEnum().QID().Str(tm))
 	case a.KExpr:
 		return fmt.Sprintf("%s node %q", n.Kind(), n.AsExpr().Str(tm))
 	case a.KFunc:
 		return fmt.Sprintf("%s node %q", n.Kind(), n.AsFunc().QQID().Str(tm))
+	case a.KInterface:
+		return fmt.Sprintf("%s node %q", n.Kind(), n.AsInterface().QID().Str(tm))
 	case a.KTypeExpr:
 		return fmt.Sprintf("%s node %q", n.Kind(), n.AsTypeExpr().Str(tm))
 	case a.KStatus:
@@ -868,4 +1288,18 @@ type checker struct {
 	errLine     uint32
 
 	facts facts
+
+	// suggesting is whether suggest is running, so that its own probing of
+	// the bounds checker does not recursively search for suggestions.
+	suggesting bool
+
+	// smtEncoding is whether an SMT obligation is being translated, so that
+	// looking up the bounds of its opaque terms does not recursively call the
+	// SMT solver.
+	smtEncoding bool
+
+	// terminationLoops holds, for each while loop whose body is being bounds
+	// checked, what is needed to prove that its variant decreases. Its values
+	// are nil for loops that need no variant.
+	terminationLoops map[*a.While]*terminationLoop
 }
@@ -18,7 +18,10 @@ import (
 	"bytes"
 	"fmt"
 	"math/big"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"sort"
 	"strings"
 	"testing"
@@ -110,7 +113,7 @@ func TestCheck(tt *testing.T) {
 		tt.Fatalf("compareToWuffsfmt: %v", err)
 	}
 
-	c, err := Check(tm, []*a.File{file}, nil)
+	c, err := Check(tm, []*a.File{file}, nil, nil)
 	if err != nil {
 		tt.Fatalf("Check: %v", err)
 	}
@@ -224,7 +227,7 @@ func TestConstValues(tt *testing.T) {
 			continue
 		}
 
-		c, err := Check(tm, []*a.File{file}, nil)
+		c, err := Check(tm, []*a.File{file}, nil, nil)
 		if err != nil {
 			tt.Errorf("%q: Check: %v", s, err)
 			continue
@@ -270,6 +273,1081 @@ func TestConstValues(tt *testing.T) {
 	}
 }
 
+// checkSource tokenizes, parses and checks src as a package's only file. A
+// Check error is returned as is, so that it can be a *Error.
+func checkSource(tm *t.Map, src string, opts *Options) error {
+	const filename = "test.wuffs"
+	tokens, _, err := t.Tokenize(tm, filename, []byte(src))
+	if err != nil {
+		return fmt.Errorf("Tokenize: %v", err)
+	}
+	file, err := parse.Parse(tm, filename, tokens, nil)
+	if err != nil {
+		return fmt.Errorf("Parse: %v", err)
+	}
+	_, err = Check(tm, []*a.File{file}, nil, opts)
+	return err
+}
+
+// checkWantErr reports a test failure unless err is nil (if wantErr is empty)
+// or err's message contains wantErr.
+func checkWantErr(tt *testing.T, name string, err error, wantErr string) {
+	tt.Helper()
+	if wantErr == "" {
+		if err != nil {
+			tt.Errorf("%q: %v", name, err)
+		}
+	} else if err == nil {
+		tt.Errorf("%q: got nil error, want %q", name, wantErr)
+	} else if !strings.Contains(err.Error(), wantErr) {
+		tt.Errorf("%q: got %v, want %q", name, err, wantErr)
+	}
+}
+
+func TestMulDivBounds(tt *testing.T) {
+	testCases := []struct {
+		args    string
+		body    string
+		wantErr string
+	}{{
+		// Bounds propagate through temporaries.
+		args: "a : base.u32[..= 100], b : base.u32[..= 200]",
+		body: "var t : base.u32[..= 20000]\n" +
+			"var u : base.u32[..= 60000]\n" +
+			"t = args.a * args.b\n" +
+			"u = t * 3\n",
+	}, {
+		args: "a : base.i32[-50 ..= 100], b : base.i32[2 ..= 8]",
+		body: "var t : base.i32[-25 ..= 50]\n" +
+			"t = args.a / args.b\n",
+	}, {
+		args: "a : base.i32[-20 ..= 5], b : base.i32[-8 ..= -6]",
+		body: "var t : base.i32[-1 ..= 3]\n" +
+			"var u : base.i32[-7 ..= 5]\n" +
+			"t = args.a / args.b\n" +
+			"u = args.a % args.b\n",
+	}, {
+		args: "a : base.u32[..= 10], b : base.u32[4 ..= 8]",
+		body: "var t : base.u32[..= 7]\n" +
+			"var u : base.u32[..= 3]\n" +
+			"t = args.a % args.b\n" +
+			"u = (args.a / 3) % args.b\n",
+	}, {
+		args: "a : base.u32[..= 10], b : base.u32[4 ..= 8]",
+		body: "var t : base.u32[..= 6]\n" +
+			"t = args.a % args.b\n",
+		wantErr: "is not within bounds",
+	}, {
+		args: "a : base.i32, b : base.i32[-1 ..= 1]",
+		body: "var t : base.i32\n" +
+			"t = args.a / args.b\n",
+		wantErr: "is possibly zero",
+	}, {
+		args: "a : base.i32, b : base.i32[-2 ..= -1]",
+		body: "var t : base.i32\n" +
+			"t = args.a % args.b\n",
+		wantErr: "divide/modulus op",
+	}}
+
+	for _, tc := range testCases {
+		src := "pri func foo(" + tc.args + ") {\n" + tc.body + "}\n"
+		checkWantErr(tt, src, checkSource(&t.Map{}, src, nil), tc.wantErr)
+	}
+}
+
+func TestSuggestions(tt *testing.T) {
+	testCases := []struct {
+		args string
+		body string
+		want []string
+	}{{
+		args: "a : base.u32",
+		body: "var t : base.u32[..= 0xFF]\n" +
+			"t = args.a\n",
+		want: []string{"assert args.a < 0x100"},
+	}, {
+		args: "a : base.u32[..= 100], b : base.u32[..= 200]",
+		body: "var t : base.u32[..= 1000]\n" +
+			"t = args.a * args.b\n",
+		want: []string{"assert args.a < 6", "assert args.b < 11"},
+	}, {
+		args: "a : base.u32[..= 100], b : base.u32[..= 200]",
+		body: "var t : base.u32[..= 1000]\n" +
+			"t = args.b - args.a\n",
+		want: []string{"assert args.b >= 100", "assert args.a < 1"},
+	}, {
+		// Neither argument alone can be constrained enough, but both can.
+		args: "a : base.u32[..= 200], b : base.u32[..= 200]",
+		body: "var t : base.u32[..= 100]\n" +
+			"t = args.a + args.b\n",
+		want: []string{"assert (args.a < 101) and (args.b < 1)"},
+	}, {
+		// The "+" itself (of type base.u8) fails before the assignment does.
+		args: "a : base.u8, b : base.u8",
+		body: "var t : base.u8\n" +
+			"t = args.a + args.b\n",
+		want: []string{"assert args.a < 1", "assert args.b < 1"},
+	}, {
+		args: "a : base.u8",
+		body: "var t : base.u8[..= 10]\n" +
+			"t = 3\n" +
+			"t += args.a\n",
+		want: []string{"assert args.a < 8"},
+	}, {
+		args: "a : base.u8",
+		body: "assert args.a < 10\n",
+		want: []string{"assert args.a < 10"},
+	}, {
+		args: "i : base.u32",
+		body: "var x : array[4] base.u8\n" +
+			"var t : base.u8\n" +
+			"t = x[args.i]\n",
+		want: []string{"assert args.i < 4"},
+	}, {
+		args: "a : base.u32, s : slice base.u8",
+		body: "var t : slice base.u8\n" +
+			"t = args.s[args.a ..]\n",
+		want: []string{"assert args.a <= args.s.length()"},
+	}}
+
+	for _, tc := range testCases {
+		src := "pri func foo(" + tc.args + ") {\n" + tc.body + "}\n"
+		err := checkSource(&t.Map{}, src, nil)
+		cErr, ok := err.(*Error)
+		if !ok {
+			tt.Errorf("%q: got %v, want a *check.Error", src, err)
+			continue
+		}
+		if got := strings.Join(cErr.Suggestions, "; "); got != strings.Join(tc.want, "; ") {
+			tt.Errorf("%q: Suggestions: got %q, want %q", src, got, strings.Join(tc.want, "; "))
+		}
+	}
+}
+
+func TestSMT(tt *testing.T) {
+	// The built-in checker does not use the "(args.a + args.a) < args.b" fact
+	// to bound "args.b - args.a", but an SMT solver can.
+	const src = "pri func foo(a : base.u32[..= 100], b : base.u32[..= 100]) {\n" +
+		"var t : base.u32[..= 100]\n" +
+		"if (args.a + args.a) < args.b {\n" +
+		"t = args.b - args.a\n" +
+		"}\n" +
+		"}\n"
+
+	if err := checkSource(&t.Map{}, src, nil); err == nil {
+		tt.Fatalf("Check without SMT: got nil error, want non-nil")
+	}
+
+	// With a missing solver, the obligation is exported but not proven.
+	dir := tt.TempDir()
+	opts := &Options{
+		SMTSolver:   "wuffs-test-no-such-smt-solver",
+		SMTCacheDir: dir,
+	}
+	if err := checkSource(&t.Map{}, src, opts); err == nil {
+		tt.Fatalf("Check with missing solver: got nil error, want non-nil")
+	} else if !strings.Contains(err.Error(), "obligation written to") {
+		tt.Fatalf("Check with missing solver: got %v, want an exported obligation", err)
+	}
+	smt2Filenames, err := filepath.Glob(filepath.Join(dir, "*.smt2"))
+	if err != nil {
+		tt.Fatalf("Glob: %v", err)
+	} else if len(smt2Filenames) != 1 {
+		tt.Fatalf("Glob: got %d SMT-LIB files, want 1", len(smt2Filenames))
+	}
+	smt2, err := os.ReadFile(smt2Filenames[0])
+	if err != nil {
+		tt.Fatalf("ReadFile: %v", err)
+	}
+	for _, want := range []string{
+		"(declare-const v0 Int) ; args.b\n(assert (<= 0 v0 100))\n",
+		"(declare-const v1 Int) ; args.a\n(assert (<= 0 v1 100))\n",
+		"(assert (< (+ v1 v1) v0))\n",
+		"(assert (not (<= 0 (- v0 v1) 4294967295)))\n(check-sat)\n",
+	} {
+		if !strings.Contains(string(smt2), want) {
+			tt.Errorf("SMT-LIB file: got %q, want it to contain %q", smt2, want)
+		}
+	}
+
+	// A cached "unsat" answer proves the obligation, even without a solver.
+	answerFilename := strings.TrimSuffix(smt2Filenames[0], ".smt2") + ".answer"
+	if err := os.WriteFile(answerFilename, []byte("unsat\n"), 0644); err != nil {
+		tt.Fatalf("WriteFile: %v", err)
+	}
+	if err := checkSource(&t.Map{}, src, opts); err != nil {
+		tt.Fatalf("Check with cached answer: %v", err)
+	}
+
+	// A cached "sat" answer does not.
+	if err := os.WriteFile(answerFilename, []byte("sat\n"), 0644); err != nil {
+		tt.Fatalf("WriteFile: %v", err)
+	}
+	if err := checkSource(&t.Map{}, src, opts); err == nil {
+		tt.Fatalf("Check with cached sat answer: got nil error, want non-nil")
+	}
+
+	// A solver that answers "unsat" proves the obligation, and its answer is
+	// cached.
+	if runtime.GOOS == "windows" {
+		return
+	}
+	dir = tt.TempDir()
+	solver := filepath.Join(dir, "fake-solver")
+	if err := os.WriteFile(solver, []byte("#!/bin/sh\necho unsat\n"), 0755); err != nil {
+		tt.Fatalf("WriteFile: %v", err)
+	}
+	opts = &Options{
+		SMTSolver:   solver,
+		SMTCacheDir: filepath.Join(dir, "cache"),
+	}
+	if err := checkSource(&t.Map{}, src, opts); err != nil {
+		tt.Fatalf("Check with solver: %v", err)
+	}
+	if answerFilenames, err := filepath.Glob(filepath.Join(dir, "cache", "*.answer")); err != nil {
+		tt.Fatalf("Glob: %v", err)
+	} else if len(answerFilenames) != 1 {
+		tt.Fatalf("Glob: got %d answer files, want 1", len(answerFilenames))
+	}
+}
+
+func TestFactsHook(tt *testing.T) {
+	const src = "pri func foo(a : base.u32) {\n" +
+		"var x : base.u32\n" +
+		"if args.a < 10 {\n" +
+		"x = args.a\n" +
+		"}\n" +
+		"}\n"
+
+	tm := &t.Map{}
+	got := []string(nil)
+	opts := &Options{
+		FactsHook: func(n *a.Node, facts []*a.Expr) {
+			_, line := n.AsRaw().FilenameLine()
+			strs := []string(nil)
+			for _, f := range facts {
+				strs = append(strs, f.Str(tm))
+			}
+			got = append(got, fmt.Sprintf("%d: %s", line, strings.Join(strs, "; ")))
+		},
+	}
+	if err := checkSource(tm, src, opts); err != nil {
+		tt.Fatalf("checkSource: %v", err)
+	}
+
+	want := []string{
+		"2: ",
+		"3: x == 0",
+		"4: x == 0; args.a < 10",
+	}
+	if !reflect.DeepEqual(got, want) {
+		tt.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestRelations(tt *testing.T) {
+	const prefix = "pri func foo!(a : base.u32[..= 100], b : base.u32[..= 100], c : base.u32[..= 100]) {\n" +
+		"var i : base.u32\n" +
+		"var t : base.u32[..= 100]\n"
+	testCases := []struct {
+		body    string
+		wantErr string
+	}{{
+		// A fact's operands can be in either order.
+		body: "if args.a <= args.b {\n" +
+			"t = args.b - args.a\n" +
+			"}\n",
+	}, {
+		// Facts can be chained.
+		body: "if args.a <= args.b {\n" +
+			"if args.b < args.c {\n" +
+			"assert args.a < args.c\n" +
+			"t = args.c - args.a\n" +
+			"}\n" +
+			"}\n",
+	}, {
+		body: "if args.a <= args.b {\n" +
+			"if args.b <= args.c {\n" +
+			"assert args.a < args.c\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `cannot prove "args.a < args.c"`,
+	}, {
+		// Incrementing i keeps (and tightens) the "i < args.b" fact.
+		body: "if i < args.b {\n" +
+			"i += 1\n" +
+			"assert i <= args.b\n" +
+			"}\n",
+	}, {
+		// A loop body that does not assign to args.a or args.b keeps their
+		// relation, without needing an inv condition.
+		body: "if args.a < args.b {\n" +
+			"while i < 10 {\n" +
+			"t = args.b - args.a\n" +
+			"i += 1\n" +
+			"} endwhile\n" +
+			"t = args.b - args.a\n" +
+			"}\n",
+	}, {
+		body: "if args.a < args.b {\n" +
+			"while i < 10 {\n" +
+			"t = args.b - args.a\n" +
+			"args.a = 0\n" +
+			"i += 1\n" +
+			"} endwhile\n" +
+			"}\n",
+		wantErr: "not within bounds",
+	}}
+
+	for _, tc := range testCases {
+		src := prefix + tc.body + "}\n"
+		checkWantErr(tt, tc.body, checkSource(&t.Map{}, src, nil), tc.wantErr)
+	}
+}
+
+func TestTermination(tt *testing.T) {
+	const prefix = "pri func foo!(a : base.u32[..= 100], b : base.u32[..= 100]) {\n" +
+		"var i : base.u32\n" +
+		"var j : base.u32\n"
+	testCases := []struct {
+		body    string
+		wantErr string
+	}{{
+		// The variant, "100 - i", is inferred from the condition.
+		body: "while i < 100 {\n" +
+			"i += 1\n" +
+			"} endwhile\n",
+	}, {
+		body: "while i < args.b {\n" +
+			"j = 0\n" +
+			"} endwhile\n",
+		wantErr: `cannot prove that the while loop variant "args.b - i" decreases`,
+	}, {
+		// An explicit continue also needs the variant to decrease.
+		body: "while i < 100 {\n" +
+			"if j > 0 {\n" +
+			"j -= 1\n" +
+			"continue\n" +
+			"}\n" +
+			"i += 1\n" +
+			"} endwhile\n",
+		wantErr: `cannot prove that the while loop variant "100 - i" decreases`,
+	}, {
+		body: "while i > 0 {\n" +
+			"if j > 0 {\n" +
+			"i -= 1\n" +
+			"continue\n" +
+			"}\n" +
+			"i -= 1\n" +
+			"} endwhile\n",
+	}, {
+		body: "while true {\n" +
+			"if i >= 100 {\n" +
+			"break\n" +
+			"}\n" +
+			"i += 1\n" +
+			"} endwhile\n",
+		wantErr: `cannot infer a variant for the while loop with condition "true"`,
+	}, {
+		body: "while true,\n" +
+			"inv i <= 100,\n" +
+			"decreases 100 - i,\n" +
+			"{\n" +
+			"if i >= 100 {\n" +
+			"break\n" +
+			"}\n" +
+			"i += 1\n" +
+			"} endwhile\n",
+	}, {
+		// A loop body that cannot repeat needs no variant.
+		body: "while true {\n" +
+			"i = 0\n" +
+			"break\n" +
+			"} endwhile\n",
+	}, {
+		// The outer loop's variant decreases, even with an inner loop
+		// between i's old and new values.
+		body: "while i < 100 {\n" +
+			"j = 0\n" +
+			"while j < 100 {\n" +
+			"j += 1\n" +
+			"} endwhile\n" +
+			"i += 1\n" +
+			"} endwhile\n",
+	}, {
+		body: "while true,\n" +
+			"decreases args.a > 0,\n" +
+			"{\n" +
+			"break\n" +
+			"} endwhile\n",
+		wantErr: `decreases expression "args.a > 0", of type "base.bool", does not have an integer type`,
+	}, {
+		body: "while i < args.b,\n" +
+			"decreases args.b - i,\n" +
+			"inv i <= 100,\n" +
+			"{\n" +
+			"i += 1\n" +
+			"} endwhile\n",
+		wantErr: `assertion chain has "inv" after "decreases"`,
+	}}
+
+	for _, tc := range testCases {
+		src := prefix + tc.body + "}\n"
+		checkWantErr(tt, tc.body, checkSource(&t.Map{}, src, &Options{CheckTermination: true}), tc.wantErr)
+	}
+}
+
+func TestUnions(tt *testing.T) {
+	const prefix = "pri union u(\n" +
+		"a : base.u32,\n" +
+		"b : array[4] base.u8,\n" +
+		")\n" +
+		"pri struct s?(\n" +
+		"x : u,\n" +
+		")\n" +
+		"pri func s.bar!() {\n" +
+		"}\n"
+	testCases := []struct {
+		src     string
+		wantErr string
+	}{{
+		src: "pri func s.foo!() {\n" +
+			"this.x.set_a!()\n" +
+			"this.x.a = 3\n" +
+			"}\n",
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"this.x.a = 3\n" +
+			"}\n",
+		wantErr: `cannot prove "this.x.is_a()", needed to access union member "this.x.a"`,
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"if this.x.is_b() {\n" +
+			"this.x.b[0] = 1\n" +
+			"}\n" +
+			"}\n",
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"this.x.set_a!()\n" +
+			"this.x.set_b!()\n" +
+			"this.x.a = 3\n" +
+			"}\n",
+		wantErr: `cannot prove "this.x.is_a()"`,
+	}, {
+		// Calling another method could change the active member.
+		src: "pri func s.foo!() {\n" +
+			"this.x.set_a!()\n" +
+			"this.bar!()\n" +
+			"this.x.a = 3\n" +
+			"}\n",
+		wantErr: `cannot prove "this.x.is_a()"`,
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"var v : u\n" +
+			"}\n",
+		wantErr: `union type "u" not allowed for var "v"`,
+	}, {
+		src:     "pri func u.foo() {\n}\n",
+		wantErr: `union "u" cannot have methods`,
+	}, {
+		src: "pri struct t?(\n" +
+			"y : array[2] u,\n" +
+			")\n",
+		wantErr: `union type not allowed within type "array[2] u" for field "y"`,
+	}, {
+		src: "pri union v(\n" +
+			"c : slice base.u8,\n" +
+			")\n",
+		wantErr: `invalid union member type "slice base.u8"`,
+	}, {
+		src: "pub union v(\n" +
+			"c : base.u8,\n" +
+			")\n",
+		wantErr: `union must be pri`,
+	}}
+
+	for _, tc := range testCases {
+		checkWantErr(tt, tc.src, checkSource(&t.Map{}, prefix+tc.src, nil), tc.wantErr)
+	}
+}
+
+func TestEnums(tt *testing.T) {
+	const prefix = "pri enum color(\n" +
+		"RED,\n" +
+		"GREEN,\n" +
+		"BLUE,\n" +
+		")\n" +
+		"pri struct s?(\n" +
+		"c : color,\n" +
+		"n : base.u32,\n" +
+		")\n"
+	testCases := []struct {
+		src     string
+		wantErr string
+	}{{
+		src: "pri func s.foo!() {\n" +
+			"this.c = color.BLUE\n" +
+			"this.n = (this.c as base.u32) + 1\n" +
+			"assert this.n <= 3\n" +
+			"}\n",
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"switch this.c {\n" +
+			"case color.RED {\n" +
+			"this.n = 1\n" +
+			"}\n" +
+			"case color.GREEN, color.BLUE {\n" +
+			"this.n = 2\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"switch this.c {\n" +
+			"case color.RED {\n" +
+			"this.n = 1\n" +
+			"}\n" +
+			"default {\n" +
+			"this.n = 2\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"switch this.c {\n" +
+			"case color.RED {\n" +
+			"this.n = 1\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `switch on "this.c" is not exhaustive: missing "GREEN", "BLUE"`,
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"switch this.c {\n" +
+			"case color.RED, color.GREEN {\n" +
+			"}\n" +
+			"case color.RED, color.BLUE {\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `duplicate case value "color.RED"`,
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"switch this.n > 0 {\n" +
+			"default {\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `switch value "this.n > 0", of type "base.bool", does not have an enum or integer type`,
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"this.c = color.PURPLE\n" +
+			"}\n",
+		wantErr: `no member named "PURPLE" found in enum "color"`,
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"this.c = 1\n" +
+			"}\n",
+		wantErr: `cannot assign`,
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"this.n = this.c as base.u32\n" +
+			"if this.c == color.RED {\n" +
+			"this.n = 0\n" +
+			"}\n" +
+			"}\n",
+	}, {
+		src: "pri func s.foo!() {\n" +
+			"if this.c < color.RED {\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `"this.c", of type "color", does not have a numeric type`,
+	}, {
+		// A switch whose arms all terminate terminates.
+		src: "pri func s.foo!() base.u32 {\n" +
+			"switch this.c {\n" +
+			"case color.RED {\n" +
+			"return 1\n" +
+			"}\n" +
+			"default {\n" +
+			"return 2\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+	}, {
+		src: "pri enum dup(\n" +
+			"A,\n" +
+			"A,\n" +
+			")\n",
+		wantErr: `duplicate member "A" in enum dup`,
+	}}
+
+	for _, tc := range testCases {
+		checkWantErr(tt, tc.src, checkSource(&t.Map{}, prefix+tc.src, nil), tc.wantErr)
+	}
+}
+
+func TestIntegerSwitches(tt *testing.T) {
+	const prefix = "pri const TWO : base.u8 = 2\n" +
+		"pri struct s?(\n" +
+		"a : array[4] base.u8,\n" +
+		"n : base.u32,\n" +
+		")\n"
+	testCases := []struct {
+		src     string
+		wantErr string
+	}{{
+		src: "pri func s.foo!(x: base.u8[..= 2]) {\n" +
+			"switch args.x {\n" +
+			"case 0 {\n" +
+			"this.n = 10\n" +
+			"}\n" +
+			"case 1, TWO {\n" +
+			"this.n = 20\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+	}, {
+		src: "pri func s.foo!(x: base.u8[..= 3]) {\n" +
+			"switch args.x {\n" +
+			"case 0 {\n" +
+			"}\n" +
+			"case 1, 2 {\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `switch on "args.x" is not exhaustive: missing 3`,
+	}, {
+		src: "pri func s.foo!(x: base.u8) {\n" +
+			"switch args.x {\n" +
+			"case 0 {\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `switch on "args.x" is not exhaustive: missing 1`,
+	}, {
+		// Each arm knows the switch value.
+		src: "pri func s.foo!(x: base.u32) {\n" +
+			"switch args.x {\n" +
+			"case 3 {\n" +
+			"this.a[args.x] = 0\n" +
+			"}\n" +
+			"case 1, 2 {\n" +
+			"assert args.x >= 1\n" +
+			"this.a[args.x] = 1\n" +
+			"}\n" +
+			"default {\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+	}, {
+		src: "pri func s.foo!(x: base.u32) {\n" +
+			"switch args.x {\n" +
+			"case 4 {\n" +
+			"}\n" +
+			"default {\n" +
+			"this.a[args.x] = 0\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `cannot prove "args.x < 4"`,
+	}, {
+		src: "pri func s.foo!(x: base.u8) {\n" +
+			"switch args.x {\n" +
+			"case 2, TWO {\n" +
+			"}\n" +
+			"default {\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `duplicate case value "TWO"`,
+	}, {
+		src: "pri func s.foo!(x: base.u8) {\n" +
+			"switch args.x {\n" +
+			"case 256 {\n" +
+			"}\n" +
+			"default {\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `case value "256" is out of range for type "base.u8"`,
+	}, {
+		src: "pri func s.foo!(x: base.u8) {\n" +
+			"switch args.x {\n" +
+			"case this.n {\n" +
+			"}\n" +
+			"default {\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `case value "this.n", of type "base.u32", does not have type "base.u8"`,
+	}}
+
+	for _, tc := range testCases {
+		checkWantErr(tt, tc.src, checkSource(&t.Map{}, prefix+tc.src, nil), tc.wantErr)
+	}
+}
+
+func TestRISCVRVVSetVL(tt *testing.T) {
+	const srcBefore = "pri func foo!(x : slice base.u8),\n" +
+		"choose cpu_arch >= riscv_rvv,\n" +
+		"{\n" +
+		"var util : base.riscv_rvv_utility\n" +
+		"var vl : base.u64\n" +
+		"var v : base.riscv_rvv_u8m1\n"
+	const srcAfter = "v = util.make_u8m1_slice_vl(a: args.x, vl: vl)\n" +
+		"args.x = args.x[vl ..]\n" +
+		"}\n"
+
+	testCases := []struct {
+		assign string
+		wantOK bool
+	}{
+		{"vl = util.vsetvl_e8m1(avl: args.x.length())\n", true},
+		{"vl = util.vsetvlmax_e8m1()\n", false},
+		{"vl = util.vsetvl_e8m1(avl: 100)\n", false},
+	}
+
+	for _, tc := range testCases {
+		err := checkSource(&t.Map{}, srcBefore+tc.assign+srcAfter, nil)
+		if gotOK := err == nil; gotOK != tc.wantOK {
+			tt.Errorf("%q: got ok=%t (err=%v), want ok=%t", tc.assign, gotOK, err, tc.wantOK)
+		}
+	}
+}
+
+func TestSliceIndexOf(tt *testing.T) {
+	const srcBefore = "pri func foo!(x : slice base.u8, y : slice base.u8) {\n" +
+		"var i : base.u64\n" +
+		"var c : base.u8\n" +
+		"var z : slice base.u8\n"
+	const srcAfter = "z = args.x[i ..]\n" +
+		"if i < args.x.length() {\n" +
+		"c = args.x[i]\n" +
+		"}\n" +
+		"}\n"
+
+	testCases := []struct {
+		assign string
+		wantOK bool
+	}{
+		{"i = args.x.index_of(s: args.y)\n", true},
+		{"i = args.x.index_of_fold(s: args.y)\n", true},
+		{"i = args.y.index_of(s: args.x)\n", false},
+		{"i = 0\nif args.x.equal_fold(s: args.y) {\ni = 1\n}\n", false},
+	}
+
+	for _, tc := range testCases {
+		err := checkSource(&t.Map{}, srcBefore+tc.assign+srcAfter, nil)
+		if gotOK := err == nil; gotOK != tc.wantOK {
+			tt.Errorf("%q: got ok=%t (err=%v), want ok=%t", tc.assign, gotOK, err, tc.wantOK)
+		}
+	}
+}
+
+func TestConstStructs(tt *testing.T) {
+	const entry = "pri struct entry(\n" +
+		"code : base.u16,\n" +
+		"len : base.u8[..= 15],\n" +
+		"pair : array[2] base.u8,\n" +
+		")\n"
+	testCases := []struct {
+		src     string
+		wantErr string
+	}{{
+		src: entry +
+			"pri const T : array[2] entry = [[1, 2, [3, 4]], [5, 6, [7, 8]]]\n" +
+			"pri func foo(i : base.u32[..= 1]) base.u32 {\n" +
+			"return (T[args.i].code as base.u32) + (T[args.i].pair[1] as base.u32)\n" +
+			"}\n",
+	}, {
+		src: entry +
+			"pri const T : array[2] array[2] entry = [\n" +
+			"[[1, 2, [3, 4]], [5, 6, [7, 8]]],\n" +
+			"[[1, 2, [3, 4]], [5, 6, [7, 8]]],\n" +
+			"]\n" +
+			"pri func foo() base.u8[..= 15] {\n" +
+			"return T[1][0].len\n" +
+			"}\n",
+	}, {
+		src: entry +
+			"pri const T : array[1] entry = [[1, 16, [3, 4]]]\n",
+		wantErr: "not within",
+	}, {
+		src: entry +
+			"pri const T : array[1] entry = [[1, 2]]\n",
+		wantErr: "has 2 fields, want 3",
+	}, {
+		src: entry +
+			"pri const T : array[1] entry = [[1, 2, [3, 4, 5]]]\n",
+		wantErr: "has 3 elements, want 2",
+	}, {
+		src: entry +
+			"pri const T : array[2] entry = [[1, 2, [3, 4]]]\n",
+		wantErr: "has 1 elements, want 2",
+	}, {
+		src: entry +
+			"pub const T : array[1] entry = [[1, 2, [3, 4]]]\n",
+		wantErr: "must be pri",
+	}, {
+		src: "pri struct entry?(\n" +
+			"code : base.u16,\n" +
+			")\n" +
+			"pri const T : array[1] entry = [[1]]\n",
+		wantErr: "not a plain struct",
+	}, {
+		src: entry +
+			"pri func foo(i : base.u32[..= 2]) base.u16 {\n" +
+			"return T[args.i].code\n" +
+			"}\n" +
+			"pri const T : array[2] entry = [[1, 2, [3, 4]], [5, 6, [7, 8]]]\n",
+		wantErr: `cannot prove "args.i < 2"`,
+	}}
+
+	for _, tc := range testCases {
+		checkWantErr(tt, tc.src, checkSource(&t.Map{}, tc.src, nil), tc.wantErr)
+	}
+}
+
+func TestFloatTypes(tt *testing.T) {
+	testCases := []struct {
+		src     string
+		wantErr string
+	}{{
+		src: "pri func foo(x : base.f32, y : base.f32) base.f32 {\n" +
+			"return -((args.x * args.y) + 16777216) / args.y\n" +
+			"}\n",
+	}, {
+		src: "pri func foo(x : base.u32) base.u8 {\n" +
+			"var f : base.f64\n" +
+			"f = args.x as base.f64\n" +
+			"f /= 3\n" +
+			"if f < 255 {\n" +
+			"return f.to_u8_saturating()\n" +
+			"}\n" +
+			"return 255\n" +
+			"}\n",
+	}, {
+		src: "pri func foo(x : base.f32) base.u64 {\n" +
+			"var f : base.f64\n" +
+			"f = args.x as base.f64\n" +
+			"return f.to_bits()\n" +
+			"}\n",
+	}, {
+		src: "pri func foo(s : slice base.u8, i : base.u64) base.f32 {\n" +
+			"return args.s[args.i] as base.f32\n" +
+			"}\n",
+		wantErr: `cannot prove "args.i < args.s.length()"`,
+	}, {
+		src: "pri func foo(x : base.f32) base.u32 {\n" +
+			"return args.x as base.u32\n" +
+			"}\n",
+		wantErr: "saturating method",
+	}, {
+		src: "pri func foo(x : base.f32, y : base.f32) base.bool {\n" +
+			"return args.x == args.y\n" +
+			"}\n",
+		wantErr: "cannot apply the operator to floating point values",
+	}, {
+		src: "pri func foo(x : base.f32, y : base.f64) base.f64 {\n" +
+			"return args.x + args.y\n" +
+			"}\n",
+		wantErr: "do not have compatible types",
+	}, {
+		src: "pri func foo(x : base.f32) base.f32 {\n" +
+			"return args.x + 16777217\n" +
+			"}\n",
+		wantErr: "do not have compatible types",
+	}, {
+		src: "pri func foo(x : base.f32) {\n" +
+			"assert args.x < 1\n" +
+			"}\n",
+		wantErr: "mentions a floating point value",
+	}}
+
+	for _, tc := range testCases {
+		checkWantErr(tt, tc.src, checkSource(&t.Map{}, tc.src, nil), tc.wantErr)
+	}
+}
+
+func TestChoosyChoices(tt *testing.T) {
+	const prefix = "pri struct s?(\n" +
+		"x : base.u32,\n" +
+		")\n" +
+		"pri func s.up_x86_sse42!(x : slice base.u8),\n" +
+		"choose cpu_arch >= x86_sse42,\n" +
+		"{\n" +
+		"}\n" +
+		"pri func s.other!(x : slice base.u8) {\n" +
+		"}\n" +
+		"pri func s.wrong!(y : slice base.u8) {\n" +
+		"}\n"
+	testCases := []struct {
+		src     string
+		wantErr string
+	}{{
+		src: prefix +
+			"pri func s.up!(x : slice base.u8),\n" +
+			"choosy = [up_x86_sse42],\n" +
+			"{\n" +
+			"}\n",
+	}, {
+		src: prefix +
+			"pri func s.up!(x : slice base.u8),\n" +
+			"choosy = [up_x86_sse42, other],\n" +
+			"{\n" +
+			"}\n",
+	}, {
+		src: prefix +
+			"pri func s.up!(x : slice base.u8),\n" +
+			"choosy = [other, up_x86_sse42],\n" +
+			"{\n" +
+			"}\n",
+		wantErr: "is not a cpu_arch function",
+	}, {
+		src: prefix +
+			"pri func s.up!(x : slice base.u8),\n" +
+			"choosy = [wrong],\n" +
+			"{\n" +
+			"}\n",
+		wantErr: "different args type",
+	}, {
+		src: prefix +
+			"pri func s.up!(x : slice base.u8),\n" +
+			"choosy = [missing],\n" +
+			"{\n" +
+			"}\n",
+		wantErr: "no function named",
+	}}
+
+	for _, tc := range testCases {
+		checkWantErr(tt, tc.src, checkSource(&t.Map{}, tc.src, nil), tc.wantErr)
+	}
+}
+
+func TestInterfaces(tt *testing.T) {
+	const prefix = "pub interface sampler(\n" +
+		"rate() base.u32,\n" +
+		"decode?(dst : base.io_writer, src : base.io_reader),\n" +
+		")\n"
+	testCases := []struct {
+		src     string
+		wantErr string
+	}{{
+		src: prefix +
+			"pub struct s? implements sampler(\n" +
+			"x : base.u32,\n" +
+			")\n" +
+			"pub func s.rate() base.u32 {\n" +
+			"return this.x\n" +
+			"}\n" +
+			"pub func s.decode?(dst : base.io_writer, src : base.io_reader) {\n" +
+			"}\n",
+	}, {
+		src: prefix +
+			"pub struct s? implements sampler(\n" +
+			"x : base.u32,\n" +
+			")\n" +
+			"pub func s.rate() base.u32 {\n" +
+			"return this.x\n" +
+			"}\n",
+		wantErr: `"s" does not implement "sampler": no matching "decode" method`,
+	}, {
+		src: prefix +
+			"pub struct s? implements sampler(\n" +
+			"x : base.u32,\n" +
+			")\n" +
+			"pub func s.rate() base.u64 {\n" +
+			"return 0\n" +
+			"}\n" +
+			"pub func s.decode?(dst : base.io_writer, src : base.io_reader) {\n" +
+			"}\n",
+		wantErr: `no matching "rate" method`,
+	}, {
+		src: prefix +
+			"pub struct s? implements other(\n" +
+			"x : base.u32,\n" +
+			")\n",
+		wantErr: `invalid interface type "other"`,
+	}, {
+		src: prefix +
+			"pub struct sampler?(\n" +
+			"x : base.u32,\n" +
+			")\n",
+		wantErr: `duplicate top level name "sampler"`,
+	}, {
+		src: "pub interface bad(\n" +
+			"decode?() base.u32,\n" +
+			")\n",
+		wantErr: "has ? effect but non-empty return type",
+	}, {
+		src: "pub interface bad(\n" +
+			"rate() base.u32,\n" +
+			"rate() base.u32,\n" +
+			")\n",
+		wantErr: "duplicate interface method",
+	}}
+
+	for _, tc := range testCases {
+		checkWantErr(tt, tc.src, checkSource(&t.Map{}, tc.src, nil), tc.wantErr)
+	}
+}
+
+func TestIOChecksum(tt *testing.T) {
+	const prefix = "pri struct hasher?(\n" +
+		"x : base.u32,\n" +
+		")\n" +
+		"pri func hasher.update_u32!(x : slice base.u8) base.u32 {\n" +
+		"return 0\n" +
+		"}\n" +
+		"pri func hasher.peek() base.u32 {\n" +
+		"return this.x\n" +
+		"}\n" +
+		"pri struct s?(\n" +
+		"h : hasher,\n" +
+		"n : base.u32,\n" +
+		")\n"
+	testCases := []struct {
+		src     string
+		wantErr string
+	}{{
+		src: "pri func s.foo?(src : base.io_reader) {\n" +
+			"io_checksum (io: args.src, hasher: this.h) {\n" +
+			"if args.src.length() > 0 {\n" +
+			"args.src.skip_u32_fast!(actual: 1, worst_case: 1)\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+	}, {
+		src: "pri func s.foo?(dst : base.io_writer) {\n" +
+			"io_checksum (io: args.dst, hasher: this.h) {\n" +
+			"}\n" +
+			"}\n",
+	}, {
+		src: "pri func s.foo?(src : base.io_reader) {\n" +
+			"io_checksum (io: args.src, hasher: this.n) {\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `io_checksum expression "this.n", of type "base.u32", does not have an update_u32 method`,
+	}, {
+		src: "pri func s.foo?(src : base.io_reader, p : ptr hasher) {\n" +
+			"io_checksum (io: args.src, hasher: args.p) {\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `does not have an update_u32 method`,
+	}, {
+		src: "pri func s.foo?(x : base.u32) {\n" +
+			"io_checksum (io: args.x, hasher: this.h) {\n" +
+			"}\n" +
+			"}\n",
+		wantErr: `does not have an I/O type`,
+	}}
+
+	for _, tc := range testCases {
+		checkWantErr(tt, tc.src, checkSource(&t.Map{}, prefix+tc.src, nil), tc.wantErr)
+	}
+}
+
 func TestBitMask(tt *testing.T) {
 	testCases := [][2]uint64{
 		{0, 0},
@@ -308,6 +1386,37 @@ func TestBitMask(tt *testing.T) {
 	}
 }
 
+func TestCountBitsBounds(tt *testing.T) {
+	testCases := []struct {
+		method t.ID
+		nBits  int
+		recv   [2]uint64
+		want   [2]uint64
+	}{
+		{t.IDLeadingZeros, 8, [2]uint64{0, 0xFF}, [2]uint64{0, 8}},
+		{t.IDLeadingZeros, 32, [2]uint64{1, 15}, [2]uint64{28, 31}},
+		{t.IDLeadingZeros, 64, [2]uint64{0, 0}, [2]uint64{64, 64}},
+		{t.IDPopcount, 16, [2]uint64{0, 0xFFFF}, [2]uint64{0, 16}},
+		{t.IDPopcount, 32, [2]uint64{1, 15}, [2]uint64{1, 4}},
+		{t.IDPopcount, 64, [2]uint64{0, 0x100}, [2]uint64{0, 9}},
+		{t.IDTrailingZeros, 8, [2]uint64{0, 0xFF}, [2]uint64{0, 8}},
+		{t.IDTrailingZeros, 32, [2]uint64{1, 15}, [2]uint64{0, 3}},
+		{t.IDTrailingZeros, 64, [2]uint64{0, 0}, [2]uint64{64, 64}},
+	}
+
+	for _, tc := range testCases {
+		recv := bounds{
+			big.NewInt(0).SetUint64(tc.recv[0]),
+			big.NewInt(0).SetUint64(tc.recv[1]),
+		}
+		got := countBitsBounds(tc.method, tc.nBits, recv)
+		if (got[0].Uint64() != tc.want[0]) || (got[1].Uint64() != tc.want[1]) {
+			tt.Errorf("countBitsBounds(%#x, %d, %v): got %v, want %v",
+				tc.method, tc.nBits, tc.recv, got, tc.want)
+		}
+	}
+}
+
 func TestBuiltInTypeMap(tt *testing.T) {
 	if got, want := len(builtInTypeMap), len(builtin.Types); got != want {
 		tt.Fatalf("lengths: got %d, want %d", got, want)
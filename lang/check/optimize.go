@@ -148,13 +148,17 @@ func (q *checker) optimizeIOMethodAdvanceExpr(receiver *a.Expr, advanceExpr *a.E
 		// TODO: update (discard?) any facts that merely mention
 		// receiver.length(), even if they aren't an exact match.
 
-		op := x.Operator()
-		if op != t.IDXBinaryGreaterEq && op != t.IDXBinaryGreaterThan {
+		lhs, rhs := (*a.Expr)(nil), (*a.Expr)(nil)
+		switch x.Operator() {
+		case t.IDXBinaryGreaterEq, t.IDXBinaryGreaterThan:
+			lhs, rhs = x.LHS().AsExpr(), x.RHS().AsExpr()
+		case t.IDXBinaryLessEq, t.IDXBinaryLessThan:
+			lhs, rhs = x.RHS().AsExpr(), x.LHS().AsExpr()
+		default:
 			return x, nil
 		}
 
 		// Check that lhs is "receiver.length()".
-		lhs := x.LHS().AsExpr()
 		if (lhs.Operator() != a.ExprOperatorCall) || (len(lhs.Args()) != 0) {
 			return x, nil
 		}
@@ -167,9 +171,10 @@ func (q *checker) optimizeIOMethodAdvanceExpr(receiver *a.Expr, advanceExpr *a.E
 			return x, nil
 		}
 
-		// Check that rhs is "advanceExpr as base.u64".
-		rhs := x.RHS().AsExpr()
-		if rhs.Operator() != t.IDXBinaryAs ||
+		// Check that rhs is "advanceExpr" or "advanceExpr as base.u64".
+		if rhs.Eq(advanceExpr) {
+			// No-op.
+		} else if rhs.Operator() != t.IDXBinaryAs ||
 			!rhs.LHS().AsExpr().Eq(advanceExpr) ||
 			!rhs.RHS().AsTypeExpr().Eq(typeExprU64) {
 			return x, nil
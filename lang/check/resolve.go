@@ -30,6 +30,7 @@ var (
 )
 
 var (
+	typeExprEnum        = a.NewTypeExpr(0, t.IDBase, t.IDQEnum, nil, nil, nil)
 	typeExprGeneric1    = a.NewTypeExpr(0, t.IDBase, t.IDDagger1, nil, nil, nil)
 	typeExprGeneric2    = a.NewTypeExpr(0, t.IDBase, t.IDDagger2, nil, nil, nil)
 	typeExprIdeal       = a.NewTypeExpr(0, t.IDBase, t.IDQIdeal, nil, nil, nil)
@@ -45,6 +46,9 @@ var (
 	typeExprU32 = a.NewTypeExpr(0, t.IDBase, t.IDU32, nil, nil, nil)
 	typeExprU64 = a.NewTypeExpr(0, t.IDBase, t.IDU64, nil, nil, nil)
 
+	typeExprF32 = a.NewTypeExpr(0, t.IDBase, t.IDF32, nil, nil, nil)
+	typeExprF64 = a.NewTypeExpr(0, t.IDBase, t.IDF64, nil, nil, nil)
+
 	typeExprEmptyStruct = a.NewTypeExpr(0, t.IDBase, t.IDEmptyStruct, nil, nil, nil)
 	typeExprBool        = a.NewTypeExpr(0, t.IDBase, t.IDBool, nil, nil, nil)
 	typeExprUtility     = a.NewTypeExpr(0, t.IDBase, t.IDUtility, nil, nil, nil)
@@ -91,6 +95,15 @@ var (
 	typeExprX86SSE42Utility = a.NewTypeExpr(0, t.IDBase, t.IDX86SSE42Utility, nil, nil, nil)
 	typeExprX86M128I        = a.NewTypeExpr(0, t.IDBase, t.IDX86M128I, nil, nil, nil)
 
+	typeExprRISCVRVVUtility = a.NewTypeExpr(0, t.IDBase, t.IDRISCVRVVUtility, nil, nil, nil)
+	typeExprRISCVRVVU8M1    = a.NewTypeExpr(0, t.IDBase, t.IDRISCVRVVU8M1, nil, nil, nil)
+	typeExprRISCVRVVU16M2   = a.NewTypeExpr(0, t.IDBase, t.IDRISCVRVVU16M2, nil, nil, nil)
+	typeExprRISCVRVVU32M1   = a.NewTypeExpr(0, t.IDBase, t.IDRISCVRVVU32M1, nil, nil, nil)
+	typeExprRISCVRVVU32M4   = a.NewTypeExpr(0, t.IDBase, t.IDRISCVRVVU32M4, nil, nil, nil)
+
+	typeExprWASMSIMD128Utility = a.NewTypeExpr(0, t.IDBase, t.IDWASMSIMD128Utility, nil, nil, nil)
+	typeExprWASMV128           = a.NewTypeExpr(0, t.IDBase, t.IDWASMV128, nil, nil, nil)
+
 	typeExprSliceU8 = a.NewTypeExpr(t.IDSlice, 0, 0, nil, nil, typeExprU8)
 	typeExprTableU8 = a.NewTypeExpr(t.IDTable, 0, 0, nil, nil, typeExprU8)
 )
@@ -109,6 +122,9 @@ var builtInTypeMap = typeMap{
 	t.IDU32: typeExprU32,
 	t.IDU64: typeExprU64,
 
+	t.IDF32: typeExprF32,
+	t.IDF64: typeExprF64,
+
 	t.IDEmptyStruct: typeExprEmptyStruct,
 	t.IDBool:        typeExprBool,
 	t.IDUtility:     typeExprUtility,
@@ -154,6 +170,15 @@ var builtInTypeMap = typeMap{
 
 	t.IDX86SSE42Utility: typeExprX86SSE42Utility,
 	t.IDX86M128I:        typeExprX86M128I,
+
+	t.IDRISCVRVVUtility: typeExprRISCVRVVUtility,
+	t.IDRISCVRVVU8M1:    typeExprRISCVRVVU8M1,
+	t.IDRISCVRVVU16M2:   typeExprRISCVRVVU16M2,
+	t.IDRISCVRVVU32M1:   typeExprRISCVRVVU32M1,
+	t.IDRISCVRVVU32M4:   typeExprRISCVRVVU32M4,
+
+	t.IDWASMSIMD128Utility: typeExprWASMSIMD128Utility,
+	t.IDWASMV128:           typeExprWASMV128,
 }
 
 func (c *Checker) parseBuiltInFuncs(m map[t.QQID]*a.Func, ss []string) error {
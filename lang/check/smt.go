@@ -0,0 +1,509 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+// This file implements the optional SMT (Satisfiability Modulo Theories)
+// backend. When enabled, a proof obligation that the built-in checker cannot
+// discharge is translated to an SMT-LIB file: the facts in scope (and the
+// bounds of any opaque terms) are hypotheses and the negated obligation is
+// the goal. An external solver such as z3 answering "unsat" (the negation is
+// unsatisfiable) proves the obligation.
+//
+// Each file is named by a hash of its contents. The solver's answer is cached
+// next to it, so that re-checking unchanged code does not re-run the solver
+// and, if the cache directory is shared, does not even need one.
+//
+// Translation is conservative. Anything that cannot be translated exactly
+// (such as a bitwise-or or a signed division) becomes an opaque integer,
+// constrained only by its bounds. Facts that cannot be translated are dropped.
+// Either way, this can only make an obligation harder to prove, not easier.
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	a "github.com/google/wuffs/lang/ast"
+	t "github.com/google/wuffs/lang/token"
+)
+
+// Options are optional arguments to Check. A nil *Options is valid and is
+// equivalent to a zero Options.
+type Options struct {
+	// SMTSolver, if non-empty, enables the SMT backend. It is the command
+	// (such as "z3") that is run with an SMT-LIB file name as its sole
+	// argument. If that command cannot be found, obligations are still
+	// written to SMTCacheDir, and previously cached answers are still used.
+	SMTSolver string
+
+	// SMTCacheDir is where SMT-LIB files and the solver's answers are stored.
+	// If empty, it defaults to a "wuffs-smt" directory under the user's cache
+	// directory.
+	SMTCacheDir string
+
+	// SMTTimeout bounds how long each solver run can take. Zero means a
+	// default of 10 seconds.
+	SMTTimeout time.Duration
+
+	// CheckTermination enables checking that every while loop in a
+	// non-coroutine function terminates. A loop that can repeat needs a
+	// variant, either inferred from its condition or given by a "decreases"
+	// clause, that provably decreases on every iteration.
+	CheckTermination bool
+
+	// FactsHook, if non-nil, is called before bounds checking each statement
+	// (including those in nested blocks) of every func body, with the facts
+	// known to be true at that point. The facts slice must not be retained
+	// or modified.
+	FactsHook func(n *a.Node, facts []*a.Expr)
+}
+
+const (
+	smtAnswerSat     = "sat"
+	smtAnswerUnknown = "unknown"
+	smtAnswerUnsat   = "unsat"
+)
+
+// smtNote annotates err with why the SMT backend did not help.
+func smtNote(err error, note string) error {
+	if note == "" {
+		return err
+	}
+	return fmt.Errorf("%v (%s)", err, note)
+}
+
+func (q *checker) smtEnabled() bool {
+	return (q.c.opts != nil) && (q.c.opts.SMTSolver != "") && !q.suggesting && !q.smtEncoding
+}
+
+// smtProveBounds tries to prove that n's value is within want.
+func (q *checker) smtProveBounds(n *a.Expr, want bounds) (proved bool, note string) {
+	if !q.smtEnabled() {
+		return false, ""
+	}
+	q.smtEncoding = true
+	defer func() { q.smtEncoding = false }()
+	e := newSMTEncoder(q)
+	x, ok := e.intExpr(n)
+	if !ok {
+		return false, "SMT: cannot translate " + n.Str(q.tm)
+	}
+	return q.smtProve(e, fmt.Sprintf("(<= %s %s %s)", smtInt(want[0]), x, smtInt(want[1])),
+		fmt.Sprintf("%s in %v", n.Str(q.tm), want))
+}
+
+// smtProveBinaryOp tries to prove "lhs op rhs".
+func (q *checker) smtProveBinaryOp(op t.ID, lhs *a.Expr, rhs *a.Expr) (proved bool, note string) {
+	return q.smtProveCondition(a.NewExpr(0, op, 0, lhs.AsNode(), nil, rhs.AsNode(), nil))
+}
+
+// smtProveCondition tries to prove the boolean expression n.
+func (q *checker) smtProveCondition(n *a.Expr) (proved bool, note string) {
+	if !q.smtEnabled() {
+		return false, ""
+	}
+	q.smtEncoding = true
+	defer func() { q.smtEncoding = false }()
+	e := newSMTEncoder(q)
+	x, ok := e.boolExpr(n)
+	if !ok {
+		return false, "SMT: cannot translate " + n.Str(q.tm)
+	}
+	return q.smtProve(e, x, n.Str(q.tm))
+}
+
+// smtProve asks the solver (or the cache) whether goal follows from the facts.
+func (q *checker) smtProve(e *smtEncoder, goal string, desc string) (proved bool, note string) {
+	for _, f := range q.facts {
+		if x, ok := e.boolExpr(f); ok {
+			e.hypotheses = append(e.hypotheses, x)
+		}
+	}
+	body := e.document(goal)
+	hash := sha256.Sum256([]byte(body))
+	name := fmt.Sprintf("%x", hash[:16])
+
+	dir := q.c.opts.SMTCacheDir
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return false, "SMT: " + err.Error()
+		}
+		dir = filepath.Join(userCacheDir, "wuffs-smt")
+	}
+	smt2Filename := filepath.Join(dir, name+".smt2")
+	answerFilename := filepath.Join(dir, name+".answer")
+
+	if answer, err := os.ReadFile(answerFilename); err == nil {
+		return q.smtConclude(strings.TrimSpace(string(answer)), smt2Filename)
+	}
+
+	header := fmt.Sprintf("; Wuffs proof obligation: %s\n; at %s:%d\n", desc, q.errFilename, q.errLine)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, "SMT: " + err.Error()
+	}
+	if err := os.WriteFile(smt2Filename, []byte(header+body), 0644); err != nil {
+		return false, "SMT: " + err.Error()
+	}
+
+	solver, err := exec.LookPath(q.c.opts.SMTSolver)
+	if err != nil {
+		return false, fmt.Sprintf("SMT: no solver %q; obligation written to %s", q.c.opts.SMTSolver, smt2Filename)
+	}
+	timeout := q.c.opts.SMTTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	out, _ := exec.CommandContext(ctx, solver, smt2Filename).Output()
+	answer := strings.TrimSpace(string(out))
+	if i := strings.IndexByte(answer, '\n'); i >= 0 {
+		answer = answer[:i]
+	}
+
+	// Only cache definitive answers, not timeouts or other failures.
+	if (answer == smtAnswerSat) || (answer == smtAnswerUnsat) {
+		if err := os.WriteFile(answerFilename, []byte(answer+"\n"), 0644); err != nil {
+			return false, "SMT: " + err.Error()
+		}
+	} else if answer == "" {
+		answer = smtAnswerUnknown
+	}
+	return q.smtConclude(answer, smt2Filename)
+}
+
+func (q *checker) smtConclude(answer string, smt2Filename string) (proved bool, note string) {
+	if answer == smtAnswerUnsat {
+		return true, ""
+	}
+	return false, fmt.Sprintf("SMT: %s for %s", answer, smt2Filename)
+}
+
+// smtEncoder translates Wuffs expressions to SMT-LIB expressions.
+type smtEncoder struct {
+	q *checker
+
+	// opaques maps an opaque term's Wuffs source (its Str) to its SMT-LIB
+	// constant's index.
+	opaques map[string]int
+
+	declarations []string
+	hypotheses   []string
+}
+
+func newSMTEncoder(q *checker) *smtEncoder {
+	return &smtEncoder{
+		q:       q,
+		opaques: map[string]int{},
+	}
+}
+
+func (e *smtEncoder) document(goal string) string {
+	b := &strings.Builder{}
+	b.WriteString("(set-logic QF_NIA)\n")
+	for _, s := range e.declarations {
+		b.WriteString(s)
+	}
+	for _, s := range e.hypotheses {
+		fmt.Fprintf(b, "(assert %s)\n", s)
+	}
+	fmt.Fprintf(b, "(assert (not %s))\n(check-sat)\n", goal)
+	return b.String()
+}
+
+// opaque returns an SMT-LIB constant standing for n, constrained only by n's
+// bounds.
+func (e *smtEncoder) opaque(n *a.Expr) (string, bool) {
+	s := n.Str(e.q.tm)
+	if i, ok := e.opaques[s]; ok {
+		return fmt.Sprintf("v%d", i), true
+	}
+	nb, err := e.q.bcheckExpr(n, 0)
+	if (err != nil) || (nb[0] == nil) || (nb[1] == nil) {
+		return "", false
+	}
+	i := len(e.opaques)
+	e.opaques[s] = i
+	e.declarations = append(e.declarations, fmt.Sprintf(
+		"(declare-const v%d Int) ; %s\n(assert (<= %s v%d %s))\n",
+		i, strings.ReplaceAll(s, "\n", " "), smtInt(nb[0]), i, smtInt(nb[1])))
+	return fmt.Sprintf("v%d", i), true
+}
+
+func smtInt(x *big.Int) string {
+	if x.Sign() < 0 {
+		return "(- " + big.NewInt(0).Neg(x).String() + ")"
+	}
+	return x.String()
+}
+
+func smtPow2(k *big.Int) (string, bool) {
+	if (k == nil) || (k.Sign() < 0) || (k.Cmp(big.NewInt(64)) > 0) {
+		return "", false
+	}
+	return big.NewInt(0).Lsh(one, uint(k.Int64())).String(), true
+}
+
+// nonNegative returns whether n's bounds are known to be non-negative.
+func (e *smtEncoder) nonNegative(n *a.Expr) bool {
+	nb, err := e.q.bcheckExpr(n, 0)
+	return (err == nil) && (nb[0] != nil) && (nb[0].Sign() >= 0)
+}
+
+// modulus returns 1<<w as a string, where w is the bit width of the unsigned
+// integer type of n's operands, for "~mod" arithmetic.
+func (e *smtEncoder) modulus(lhs *a.Expr, rhs *a.Expr) (string, bool) {
+	typ := lhs.MType()
+	if (typ == nil) || typ.IsIdeal() {
+		typ = rhs.MType()
+	}
+	if typ == nil {
+		return "", false
+	}
+	qid := typ.QID()
+	if (qid[0] != t.IDBase) || (int(qid[1]) >= len(numTypeBounds)) {
+		return "", false
+	}
+	b := numTypeBounds[qid[1]]
+	if (b[0] == nil) || (b[0].Sign() != 0) {
+		return "", false
+	}
+	return add1(b[1]).String(), true
+}
+
+func (e *smtEncoder) intExpr(n *a.Expr) (string, bool) {
+	if cv := n.ConstValue(); cv != nil {
+		return smtInt(cv), true
+	}
+
+	switch op := n.Operator(); op {
+	case t.IDXUnaryPlus:
+		return e.intExpr(n.RHS().AsExpr())
+	case t.IDXUnaryMinus:
+		if x, ok := e.intExpr(n.RHS().AsExpr()); ok {
+			return "(- " + x + ")", true
+		}
+		return "", false
+
+	case t.IDXBinaryAs:
+		return e.intExpr(n.LHS().AsExpr())
+
+	case t.IDXBinaryPlus, t.IDXBinaryMinus, t.IDXBinaryStar,
+		t.IDXBinarySlash, t.IDXBinaryPercent,
+		t.IDXBinaryShiftL, t.IDXBinaryShiftR, t.IDXBinaryAmp,
+		t.IDXBinaryTildeModPlus, t.IDXBinaryTildeModMinus, t.IDXBinaryTildeModStar,
+		t.IDXBinaryTildeModShiftL, t.IDXBinaryTildeSatPlus, t.IDXBinaryTildeSatMinus:
+		if x, ok := e.intBinaryOp(n, op, n.LHS().AsExpr(), n.RHS().AsExpr()); ok {
+			return x, true
+		}
+
+	case t.IDXAssociativePlus, t.IDXAssociativeStar:
+		smtOp := "+"
+		if op == t.IDXAssociativeStar {
+			smtOp = "*"
+		}
+		xs := []string(nil)
+		for _, o := range n.Args() {
+			x, ok := e.intExpr(o.AsExpr())
+			if !ok {
+				return "", false
+			}
+			xs = append(xs, x)
+		}
+		return "(" + smtOp + " " + strings.Join(xs, " ") + ")", true
+
+	case t.IDOpenParen:
+		// "x.min(a: y)" and "x.max(a: y)".
+		if callee := n.LHS().AsExpr(); (callee.Operator() == t.IDDot) &&
+			((callee.Ident() == t.IDMin) || (callee.Ident() == t.IDMax)) && (len(n.Args()) == 1) {
+			x, ok0 := e.intExpr(callee.LHS().AsExpr())
+			y, ok1 := e.intExpr(n.Args()[0].AsArg().Value())
+			if ok0 && ok1 {
+				cmp := "<="
+				if callee.Ident() == t.IDMax {
+					cmp = ">="
+				}
+				return fmt.Sprintf("(ite (%s %s %s) %s %s)", cmp, x, y, x, y), true
+			}
+		}
+	}
+
+	return e.opaque(n)
+}
+
+func (e *smtEncoder) intBinaryOp(n *a.Expr, op t.ID, lhs *a.Expr, rhs *a.Expr) (string, bool) {
+	x, ok := e.intExpr(lhs)
+	if !ok {
+		return "", false
+	}
+	y, ok := e.intExpr(rhs)
+	if !ok {
+		return "", false
+	}
+
+	switch op {
+	case t.IDXBinaryPlus:
+		return "(+ " + x + " " + y + ")", true
+	case t.IDXBinaryMinus:
+		return "(- " + x + " " + y + ")", true
+	case t.IDXBinaryStar:
+		return "(* " + x + " " + y + ")", true
+
+	case t.IDXBinarySlash, t.IDXBinaryPercent:
+		// SMT-LIB's div and mod are Euclidean, which matches Wuffs' (and C's)
+		// truncating division only for non-negative operands.
+		if !e.nonNegative(lhs) || !e.nonNegative(rhs) {
+			return "", false
+		}
+		if op == t.IDXBinarySlash {
+			return "(div " + x + " " + y + ")", true
+		}
+		return "(mod " + x + " " + y + ")", true
+
+	case t.IDXBinaryShiftL, t.IDXBinaryShiftR, t.IDXBinaryTildeModShiftL:
+		p, ok := smtPow2(rhs.ConstValue())
+		if !ok {
+			return "", false
+		}
+		if op == t.IDXBinaryShiftL {
+			return "(* " + x + " " + p + ")", true
+		} else if op == t.IDXBinaryShiftR {
+			if !e.nonNegative(lhs) {
+				return "", false
+			}
+			return "(div " + x + " " + p + ")", true
+		}
+		m, ok := e.modulus(lhs, rhs)
+		if !ok {
+			return "", false
+		}
+		return "(mod (* " + x + " " + p + ") " + m + ")", true
+
+	case t.IDXBinaryAmp:
+		// "x & (2**k - 1)" is "x mod 2**k" for non-negative x.
+		for i := 0; i < 2; i++ {
+			if cv := rhs.ConstValue(); (cv != nil) && (cv.Sign() >= 0) &&
+				(big.NewInt(0).And(cv, add1(cv)).Sign() == 0) && e.nonNegative(lhs) {
+				return "(mod " + x + " " + add1(cv).String() + ")", true
+			}
+			lhs, rhs, x = rhs, lhs, y
+		}
+		return "", false
+
+	case t.IDXBinaryTildeModPlus, t.IDXBinaryTildeModMinus, t.IDXBinaryTildeModStar:
+		m, ok := e.modulus(lhs, rhs)
+		if !ok {
+			return "", false
+		}
+		smtOp := map[t.ID]string{
+			t.IDXBinaryTildeModPlus:  "+",
+			t.IDXBinaryTildeModMinus: "-",
+			t.IDXBinaryTildeModStar:  "*",
+		}[op]
+		return "(mod (" + smtOp + " " + x + " " + y + ") " + m + ")", true
+
+	case t.IDXBinaryTildeSatPlus, t.IDXBinaryTildeSatMinus:
+		m, ok := e.modulus(lhs, rhs)
+		if !ok {
+			return "", false
+		}
+		if op == t.IDXBinaryTildeSatPlus {
+			s := "(+ " + x + " " + y + ")"
+			return fmt.Sprintf("(ite (< %s %s) %s (- %s 1))", s, m, s, m), true
+		}
+		s := "(- " + x + " " + y + ")"
+		return fmt.Sprintf("(ite (< %s 0) 0 %s)", s, s), true
+	}
+	return "", false
+}
+
+func (e *smtEncoder) boolExpr(n *a.Expr) (string, bool) {
+	if cv := n.ConstValue(); cv != nil {
+		if cv.Sign() == 0 {
+			return "false", true
+		}
+		return "true", true
+	}
+
+	switch op := n.Operator(); op {
+	case t.IDXUnaryNot:
+		if x, ok := e.boolExpr(n.RHS().AsExpr()); ok {
+			return "(not " + x + ")", true
+		}
+
+	case t.IDXBinaryAnd, t.IDXBinaryOr:
+		x, ok0 := e.boolExpr(n.LHS().AsExpr())
+		y, ok1 := e.boolExpr(n.RHS().AsExpr())
+		if ok0 && ok1 {
+			if op == t.IDXBinaryAnd {
+				return "(and " + x + " " + y + ")", true
+			}
+			return "(or " + x + " " + y + ")", true
+		}
+
+	case t.IDXAssociativeAnd, t.IDXAssociativeOr:
+		smtOp := "and"
+		if op == t.IDXAssociativeOr {
+			smtOp = "or"
+		}
+		xs := []string(nil)
+		for _, o := range n.Args() {
+			x, ok := e.boolExpr(o.AsExpr())
+			if !ok {
+				return "", false
+			}
+			xs = append(xs, x)
+		}
+		return "(" + smtOp + " " + strings.Join(xs, " ") + ")", true
+
+	case t.IDXBinaryNotEq, t.IDXBinaryLessThan, t.IDXBinaryLessEq,
+		t.IDXBinaryEqEq, t.IDXBinaryGreaterEq, t.IDXBinaryGreaterThan:
+		lhs, rhs := n.LHS().AsExpr(), n.RHS().AsExpr()
+		if !isNumTypeOrIdeal(lhs) || !isNumTypeOrIdeal(rhs) {
+			return "", false
+		}
+		x, ok0 := e.intExpr(lhs)
+		y, ok1 := e.intExpr(rhs)
+		if ok0 && ok1 {
+			if op == t.IDXBinaryNotEq {
+				return "(distinct " + x + " " + y + ")", true
+			}
+			smtOp := map[t.ID]string{
+				t.IDXBinaryLessThan:    "<",
+				t.IDXBinaryLessEq:      "<=",
+				t.IDXBinaryEqEq:        "=",
+				t.IDXBinaryGreaterEq:   ">=",
+				t.IDXBinaryGreaterThan: ">",
+			}[op]
+			return "(" + smtOp + " " + x + " " + y + ")", true
+		}
+	}
+	return "", false
+}
+
+func isNumTypeOrIdeal(n *a.Expr) bool {
+	if n.ConstValue() != nil {
+		return true
+	}
+	typ := n.MType()
+	return (typ != nil) && typ.IsNumTypeOrIdeal()
+}
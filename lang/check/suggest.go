@@ -0,0 +1,380 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+// This file computes suggested assertions for failed proofs. When the bounds
+// checker cannot prove something, it searches for the weakest "x < const" or
+// "x >= const" facts (for the variables x that the failing expression refers
+// to) that, if asserted beforehand, would let the proof go through. Each
+// suggestion is a binary search over the constant, re-running the bounds
+// checker with a hypothetical extra fact for each probe.
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	a "github.com/google/wuffs/lang/ast"
+	t "github.com/google/wuffs/lang/token"
+)
+
+const (
+	// maxSuggestionTerms bounds how many of an expression's variables are
+	// considered, as the search is quadratic in that number.
+	maxSuggestionTerms = 8
+
+	// maxSuggestions bounds how many alternative suggestions are reported.
+	maxSuggestions = 4
+)
+
+// suggestionError is an error that also carries suggested assertions, any one
+// of which would have avoided the error.
+type suggestionError struct {
+	err         error
+	suggestions []string
+}
+
+func (e *suggestionError) Error() string { return e.err.Error() }
+func (e *suggestionError) Unwrap() error { return e.err }
+
+// withSuggestions returns err, annotated with any suggestions.
+func withSuggestions(err error, suggestions []string) error {
+	if len(suggestions) == 0 {
+		return err
+	}
+	return &suggestionError{err: err, suggestions: suggestions}
+}
+
+// suggestBounds returns assertions that would make n's bounds (as computed by
+// bcheck) fit within want. A nil element of want means unbounded.
+func (q *checker) suggestBounds(n *a.Expr, want bounds) []string {
+	return q.suggest([]*a.Expr{n}, func() bool {
+		nb, err := q.bcheckExpr(n, 0)
+		return (err == nil) && fitsWithin(nb, want)
+	})
+}
+
+// suggestAssignmentBounds is like suggestBounds but for "lhs op rhs", where op
+// is an assignment operator such as "+=".
+func (q *checker) suggestAssignmentBounds(lhs *a.Expr, op t.ID, rhs *a.Expr, want bounds) []string {
+	return q.suggest([]*a.Expr{lhs, rhs}, func() bool {
+		nb, err := q.bcheckExprBinaryOp(op.BinaryForm(), lhs, rhs, 0)
+		return (err == nil) && fitsWithin(nb, want)
+	})
+}
+
+// suggestRequirement returns assertions that would prove "lhs op rhs". If
+// neither side is a constant, the only suggestion is that requirement itself.
+func (q *checker) suggestRequirement(op t.ID, lhs *a.Expr, rhs *a.Expr) []string {
+	if (lhs.ConstValue() == nil) && (rhs.ConstValue() == nil) {
+		n := a.NewExpr(0, op, 0, lhs.AsNode(), nil, rhs.AsNode(), nil)
+		return []string{"assert " + n.Str(q.tm)}
+	}
+	return q.suggest([]*a.Expr{lhs, rhs}, func() bool {
+		return q.proveBinaryOp(op, lhs, rhs) == nil
+	})
+}
+
+func isComparisonOp(op t.ID) bool {
+	switch op {
+	case t.IDXBinaryNotEq, t.IDXBinaryLessThan, t.IDXBinaryLessEq,
+		t.IDXBinaryEqEq, t.IDXBinaryGreaterEq, t.IDXBinaryGreaterThan:
+		return true
+	}
+	return false
+}
+
+func fitsWithin(nb bounds, want bounds) bool {
+	if (nb[0] == nil) || (nb[1] == nil) {
+		return false
+	}
+	return ((want[0] == nil) || (nb[0].Cmp(want[0]) >= 0)) &&
+		((want[1] == nil) || (nb[1].Cmp(want[1]) <= 0))
+}
+
+// hypothesis is a candidate fact: "term <= k" if upper, otherwise "term >= k".
+type hypothesis struct {
+	term  *a.Expr
+	tb    bounds
+	upper bool
+	k     *big.Int
+}
+
+func (h hypothesis) op() t.ID {
+	if h.upper {
+		return t.IDXBinaryLessEq
+	}
+	return t.IDXBinaryGreaterEq
+}
+
+// str formats h as Wuffs source code, preferring "x < 0x100" to "x <= 0xFF".
+func (h hypothesis) str(tm *t.Map) string {
+	if h.upper {
+		return h.term.Str(tm) + " < " + formatConst(add1(h.k))
+	}
+	return h.term.Str(tm) + " >= " + formatConst(h.k)
+}
+
+// suggest searches for the weakest hypotheses, about the terms within roots,
+// under which ok returns true. Single hypotheses are tried first, then pairs.
+func (q *checker) suggest(roots []*a.Expr, ok func() bool) []string {
+	if q.suggesting {
+		return nil
+	}
+	q.suggesting = true
+	defer func() { q.suggesting = false }()
+
+	terms := []*a.Expr(nil)
+	seen := map[string]bool{}
+	for _, root := range roots {
+		terms = collectSuggestionTerms(q.tm, terms, seen, root)
+	}
+	if len(terms) > maxSuggestionTerms {
+		terms = terms[:maxSuggestionTerms]
+	}
+
+	candidates := []hypothesis(nil)
+	for _, term := range terms {
+		tb, err := q.bcheckExpr(term, 0)
+		if (err != nil) || (tb[0] == nil) || (tb[1] == nil) || (tb[0].Cmp(tb[1]) >= 0) {
+			continue
+		}
+		candidates = append(candidates,
+			hypothesis{term: term, tb: tb, upper: true},
+			hypothesis{term: term, tb: tb, upper: false},
+		)
+	}
+
+	// bcheckExpr caches each node's bounds, so probing with hypothetical facts
+	// needs to clear (and afterwards restore) those cached bounds.
+	cache := map[*a.Expr]bounds{}
+	for _, root := range roots {
+		collectCachedBounds(cache, root)
+	}
+	ok0 := ok
+	ok = func() bool {
+		for n := range cache {
+			n.SetMBounds(bounds{})
+		}
+		ret := ok0()
+		for n, b := range cache {
+			n.SetMBounds(b)
+		}
+		return ret
+	}
+
+	ret := []string(nil)
+	for _, c := range candidates {
+		if h, found := q.weakest(c, nil, ok); found {
+			ret = append(ret, "assert "+h.str(q.tm))
+			if len(ret) >= maxSuggestions {
+				return ret
+			}
+		}
+	}
+	if len(ret) > 0 {
+		return ret
+	}
+
+	for i, c0 := range candidates {
+		for _, c1 := range candidates[i+1:] {
+			if c0.term == c1.term {
+				continue
+			}
+			// Relax c0 as much as possible while c1 is as tight as possible,
+			// then relax c1 given that.
+			h0, found := q.weakest(c0, []hypothesis{tightest(c1)}, ok)
+			if !found {
+				continue
+			}
+			h1, found := q.weakest(c1, []hypothesis{h0}, ok)
+			if !found {
+				continue
+			}
+			ret = append(ret, "assert ("+h0.str(q.tm)+") and ("+h1.str(q.tm)+")")
+			if len(ret) >= maxSuggestions {
+				return ret
+			}
+		}
+	}
+	return ret
+}
+
+// tightest returns the strongest hypothesis of c's form: that c's term equals
+// its lower or upper bound.
+func tightest(c hypothesis) hypothesis {
+	if c.upper {
+		c.k = c.tb[0]
+	} else {
+		c.k = c.tb[1]
+	}
+	return c
+}
+
+// weakest binary searches for the weakest form of c (the largest upper bound
+// or the smallest lower bound) under which, together with the others, ok
+// returns true. found is false if even the tightest form does not work or if
+// the weakest form places no constraint on c's term.
+func (q *checker) weakest(c hypothesis, others []hypothesis, ok func() bool) (h hypothesis, found bool) {
+	holds := func(k *big.Int) bool {
+		c.k = k
+		return q.okUnder(append([]hypothesis{c}, others...), ok)
+	}
+
+	// For an upper bound, search for the largest working k in [lo, hi]. For a
+	// lower bound, search for the smallest working k.
+	lo, hi := c.tb[0], c.tb[1]
+	if c.upper {
+		if !holds(lo) {
+			return hypothesis{}, false
+		}
+		for lo.Cmp(hi) < 0 {
+			mid := midpoint(lo, hi, true)
+			if holds(mid) {
+				lo = mid
+			} else {
+				hi = sub1(mid)
+			}
+		}
+		c.k = lo
+		return c, c.k.Cmp(c.tb[1]) < 0
+	}
+
+	if !holds(hi) {
+		return hypothesis{}, false
+	}
+	for lo.Cmp(hi) < 0 {
+		mid := midpoint(lo, hi, false)
+		if holds(mid) {
+			hi = mid
+		} else {
+			lo = add1(mid)
+		}
+	}
+	c.k = hi
+	return c, c.k.Cmp(c.tb[0]) > 0
+}
+
+// okUnder returns ok(), evaluated with hs temporarily added to the facts.
+func (q *checker) okUnder(hs []hypothesis, ok func() bool) bool {
+	saved := q.facts
+	q.facts = append(facts(nil), saved...)
+	defer func() { q.facts = saved }()
+
+	for _, h := range hs {
+		k, err := makeConstValueExpr(q.tm, h.k)
+		if err != nil {
+			return false
+		}
+		q.facts.appendBinaryOpFact(h.op(), h.term, k)
+	}
+	return ok()
+}
+
+// midpoint returns (lo + hi) / 2, rounded up or down.
+func midpoint(lo *big.Int, hi *big.Int, roundUp bool) *big.Int {
+	m := big.NewInt(0).Add(lo, hi)
+	if roundUp {
+		m.Add(m, one)
+	}
+	// Rsh rounds towards negative infinity, unlike Quo.
+	return m.Rsh(m, 1)
+}
+
+// collectCachedBounds records the bounds of n and of its non-constant
+// sub-expressions.
+func collectCachedBounds(cache map[*a.Expr]bounds, n *a.Expr) {
+	if (n == nil) || (n.ConstValue() != nil) {
+		return
+	}
+	cache[n] = n.MBounds()
+	for _, o := range [3]*a.Node{n.LHS(), n.MHS(), n.RHS()} {
+		if (o != nil) && (o.Kind() == a.KExpr) {
+			collectCachedBounds(cache, o.AsExpr())
+		}
+	}
+	for _, o := range n.Args() {
+		if o.Kind() == a.KExpr {
+			collectCachedBounds(cache, o.AsExpr())
+		} else if o.Kind() == a.KArg {
+			collectCachedBounds(cache, o.AsArg().Value())
+		}
+	}
+}
+
+// collectSuggestionTerms appends n's variables (and other opaque values, such
+// as field accesses and argument-less pure method calls) to dst.
+func collectSuggestionTerms(tm *t.Map, dst []*a.Expr, seen map[string]bool, n *a.Expr) []*a.Expr {
+	if (n == nil) || (n.ConstValue() != nil) {
+		return dst
+	}
+
+	isTerm := false
+	switch n.Operator() {
+	case 0, t.IDDot, t.IDOpenBracket:
+		isTerm = true
+	case t.IDOpenParen:
+		isTerm = n.Effect().Pure() && (len(n.Args()) == 0)
+		if !isTerm {
+			return dst
+		}
+	case t.IDXBinaryAs:
+		return collectSuggestionTerms(tm, dst, seen, n.LHS().AsExpr())
+	}
+
+	if isTerm {
+		if typ := n.MType(); (typ == nil) || !typ.IsNumType() {
+			return dst
+		}
+		if s := n.Str(tm); !seen[s] {
+			seen[s] = true
+			dst = append(dst, n)
+		}
+		return dst
+	}
+
+	for _, o := range [3]*a.Node{n.LHS(), n.MHS(), n.RHS()} {
+		if (o != nil) && (o.Kind() == a.KExpr) {
+			dst = collectSuggestionTerms(tm, dst, seen, o.AsExpr())
+		}
+	}
+	for _, o := range n.Args() {
+		if o.Kind() == a.KExpr {
+			dst = collectSuggestionTerms(tm, dst, seen, o.AsExpr())
+		}
+	}
+	return dst
+}
+
+// formatConst formats small numbers in decimal and others in hexadecimal, with
+// underscores separating groups of 4 hex digits, as in "0xFFFF_FFFF".
+func formatConst(x *big.Int) string {
+	if x.CmpAbs(big.NewInt(0x100)) < 0 {
+		return x.String()
+	}
+	sign, abs := "", big.NewInt(0).Abs(x)
+	if x.Sign() < 0 {
+		sign = "-"
+	}
+	digits := strings.ToUpper(abs.Text(16))
+	b := strings.Builder{}
+	for i, c := range digits {
+		if (i > 0) && (((len(digits) - i) % 4) == 0) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(c)
+	}
+	return fmt.Sprintf("%s0x%s", sign, b.String())
+}
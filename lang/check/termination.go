@@ -0,0 +1,251 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+// This file implements the optional termination checking. When enabled, every
+// while loop in a non-coroutine function must provably terminate. Coroutines
+// are exempt, as they can legitimately loop, suspending and resuming, for as
+// long as their caller keeps supplying input.
+//
+// A loop that cannot repeat (its body always ends in a jump or return, none of
+// which is a continue for that loop) trivially terminates. Otherwise, the loop
+// needs a variant: an integer expression that strictly decreases on every
+// iteration. It can be written explicitly, as the loop's "decreases" clause,
+// or inferred from a "while x < y" style condition (whose variant is "y - x").
+//
+// A variant is linear in its leaves, its non-arithmetic sub-expressions such
+// as variables and method calls. Every leaf has a numeric type, which has a
+// lower bound, so the variant is bounded below. It therefore suffices to
+// prove that the variant decreases by at least 1 on every path from the top
+// of the loop body back to the loop's condition. At the top, a ghost variable
+// snapshots each leaf's value, as a fact like "i == old1(i)". The usual fact
+// updates track how each leaf changes relative to its ghost, such as "i ==
+// old1(i) + 1" after an "i += 1" assignment.
+
+import (
+	"fmt"
+	"math/big"
+
+	a "github.com/google/wuffs/lang/ast"
+	t "github.com/google/wuffs/lang/token"
+)
+
+// terminationLoop is what is needed to prove that a while loop's variant
+// decreases.
+type terminationLoop struct {
+	variant *a.Expr
+	// The variant is the sum of coeffs[i] * leaves[i], plus a constant.
+	leaves []*a.Expr
+	coeffs []*big.Int
+	ghosts []*a.Expr
+}
+
+func (q *checker) terminationEnabled() bool {
+	return (q.c.opts != nil) && q.c.opts.CheckTermination && !q.astFunc.Effect().Coroutine()
+}
+
+// terminationBegin is called at the top of the loop body, after the facts
+// have been set up for checking that body. It adds the ghost facts.
+func (q *checker) terminationBegin(n *a.While) error {
+	if q.terminationLoops == nil {
+		q.terminationLoops = map[*a.While]*terminationLoop{}
+	}
+	if a.Terminates(n.Body()) && !n.HasContinue() {
+		q.terminationLoops[n] = nil
+		return nil
+	}
+
+	variant := n.Variant()
+	if variant == nil {
+		variant = inferVariant(n.Condition())
+		if variant == nil {
+			return fmt.Errorf("check: cannot infer a variant for the while loop with condition %q; "+
+				"add a \"decreases\" clause", n.Condition().Str(q.tm))
+		}
+	}
+
+	l := &terminationLoop{variant: variant}
+	coeffs := map[string]*big.Int{}
+	if !q.linearLeaves(l, coeffs, variant, one, 0) {
+		// Treat a non-linear variant as a single leaf.
+		l.leaves, l.coeffs = nil, nil
+		coeffs = map[string]*big.Int{}
+		if !q.addLeaf(l, coeffs, variant, one) {
+			return fmt.Errorf("check: while loop variant %q does not have an integer type",
+				variant.Str(q.tm))
+		}
+	}
+
+	depth := 0
+	for _, o := range q.terminationLoops {
+		if o != nil {
+			depth++
+		}
+	}
+	for i, leaf := range l.leaves {
+		l.coeffs[i] = coeffs[leaf.Str(q.tm)]
+		id, err := q.tm.Insert(fmt.Sprintf("old%d(%s)", depth+1, leaf.Str(q.tm)))
+		if err != nil {
+			return err
+		}
+		lb, err := q.bcheckTypeExpr(leaf.MType())
+		if err != nil {
+			return err
+		}
+		ghost := a.NewExpr(0, 0, id, nil, nil, nil, nil)
+		ghost.SetMType(leaf.MType())
+		ghost.SetMBounds(lb)
+		l.ghosts = append(l.ghosts, ghost)
+		q.facts.appendBinaryOpFact(t.IDXBinaryEqEq, leaf, ghost)
+	}
+	q.terminationLoops[n] = l
+	return nil
+}
+
+// terminationEnd is called after the loop body has been checked.
+func (q *checker) terminationEnd(n *a.While) {
+	delete(q.terminationLoops, n)
+}
+
+// terminationContinue is called at every explicit or implicit continue for
+// the while loop n. It proves that the variant decreased.
+func (q *checker) terminationContinue(n *a.While) error {
+	l := q.terminationLoops[n]
+	if l == nil {
+		return nil
+	}
+
+	// The variant decreased by the sum of coeffs[i] * (ghosts[i] -
+	// leaves[i]). Find a lower bound for that sum.
+	sum := big.NewInt(0)
+	for i, leaf := range l.leaves {
+		// Find bounds [dMin, dMax] for d, the difference ghosts[i] - leaves[i],
+		// where nil means unbounded.
+		dMin, dMax := (*big.Int)(nil), (*big.Int)(nil)
+		switch q.relation(leaf, l.ghosts[i]) {
+		case t.IDXBinaryLessThan:
+			dMin = one
+		case t.IDXBinaryLessEq:
+			dMin = zero
+		case t.IDXBinaryEqEq:
+			dMin, dMax = zero, zero
+		case t.IDXBinaryGreaterEq:
+			dMax = zero
+		case t.IDXBinaryGreaterThan:
+			dMax = minusOne
+		}
+
+		c := l.coeffs[i]
+		if c.Sign() < 0 {
+			dMin = nil
+			if dMax != nil {
+				dMin = big.NewInt(0).Mul(c, dMax)
+			}
+		} else if dMin != nil {
+			dMin = big.NewInt(0).Mul(c, dMin)
+		}
+		if dMin == nil {
+			return fmt.Errorf("check: cannot prove that the while loop variant %q decreases: "+
+				"%q might have changed in the wrong direction", l.variant.Str(q.tm), leaf.Str(q.tm))
+		}
+		sum.Add(sum, dMin)
+	}
+	if sum.Sign() <= 0 {
+		return fmt.Errorf("check: cannot prove that the while loop variant %q decreases",
+			l.variant.Str(q.tm))
+	}
+	return nil
+}
+
+// inferVariant returns a variant for a "while x < y" style loop, or nil.
+func inferVariant(cond *a.Expr) *a.Expr {
+	op, lhs, rhs := parseBinaryOp(cond)
+	switch op {
+	case t.IDXBinaryLessThan, t.IDXBinaryLessEq:
+		lhs, rhs = rhs, lhs
+	case t.IDXBinaryGreaterEq, t.IDXBinaryGreaterThan:
+	default:
+		return nil
+	}
+	if rhs.ConstValue() != nil {
+		return lhs
+	}
+	o := a.NewExpr(0, t.IDXBinaryMinus, 0, lhs.AsNode(), nil, rhs.AsNode(), nil)
+	o.SetMType(typeExprIdeal)
+	return o
+}
+
+// linearLeaves adds n, multiplied by coeff, to l's leaves and coeffs (keyed by
+// the leaves' Str). It returns false if n is not linear.
+func (q *checker) linearLeaves(l *terminationLoop, coeffs map[string]*big.Int, n *a.Expr, coeff *big.Int, depth uint32) bool {
+	if depth > a.MaxExprDepth {
+		return false
+	}
+	depth++
+
+	if n.ConstValue() != nil {
+		return true
+	}
+	switch n.Operator() {
+	case t.IDXUnaryPlus:
+		return q.linearLeaves(l, coeffs, n.RHS().AsExpr(), coeff, depth)
+	case t.IDXUnaryMinus:
+		return q.linearLeaves(l, coeffs, n.RHS().AsExpr(), neg(coeff), depth)
+	case t.IDXBinaryAs:
+		// A conversion preserves the value, as bounds checking proves that
+		// the value fits in the new type.
+		return q.linearLeaves(l, coeffs, n.LHS().AsExpr(), coeff, depth)
+	case t.IDXBinaryPlus:
+		return q.linearLeaves(l, coeffs, n.LHS().AsExpr(), coeff, depth) &&
+			q.linearLeaves(l, coeffs, n.RHS().AsExpr(), coeff, depth)
+	case t.IDXBinaryMinus:
+		return q.linearLeaves(l, coeffs, n.LHS().AsExpr(), coeff, depth) &&
+			q.linearLeaves(l, coeffs, n.RHS().AsExpr(), neg(coeff), depth)
+	case t.IDXBinaryStar:
+		lhs, rhs := n.LHS().AsExpr(), n.RHS().AsExpr()
+		if cv := lhs.ConstValue(); cv != nil {
+			return q.linearLeaves(l, coeffs, rhs, big.NewInt(0).Mul(coeff, cv), depth)
+		} else if cv := rhs.ConstValue(); cv != nil {
+			return q.linearLeaves(l, coeffs, lhs, big.NewInt(0).Mul(coeff, cv), depth)
+		}
+		return false
+	case t.IDXAssociativePlus:
+		for _, o := range n.Args() {
+			if !q.linearLeaves(l, coeffs, o.AsExpr(), coeff, depth) {
+				return false
+			}
+		}
+		return true
+	}
+	if n.Operator().IsXOp() {
+		return false
+	}
+	return q.addLeaf(l, coeffs, n, coeff)
+}
+
+func (q *checker) addLeaf(l *terminationLoop, coeffs map[string]*big.Int, n *a.Expr, coeff *big.Int) bool {
+	if typ := n.MType(); (typ == nil) || !typ.IsNumType() || typ.IsFloatType() {
+		return false
+	}
+	k := n.Str(q.tm)
+	if c := coeffs[k]; c != nil {
+		coeffs[k] = big.NewInt(0).Add(c, coeff)
+		return true
+	}
+	coeffs[k] = coeff
+	l.leaves = append(l.leaves, n)
+	l.coeffs = append(l.coeffs, nil)
+	return true
+}
@@ -17,6 +17,7 @@ package check
 import (
 	"fmt"
 	"math/big"
+	"strings"
 
 	a "github.com/google/wuffs/lang/ast"
 	t "github.com/google/wuffs/lang/token"
@@ -25,10 +26,12 @@ import (
 type cpuArchBits uint32
 
 const (
-	cpuArchBitsARMCRC32 = cpuArchBits(0x00000001)
-	cpuArchBitsARMNeon  = cpuArchBits(0x00000002)
-	cpuArchBitsX86SSE42 = cpuArchBits(0x00000004)
-	cpuArchBitsX86AVX2  = cpuArchBits(0x00000008)
+	cpuArchBitsARMCRC32    = cpuArchBits(0x00000001)
+	cpuArchBitsARMNeon     = cpuArchBits(0x00000002)
+	cpuArchBitsX86SSE42    = cpuArchBits(0x00000004)
+	cpuArchBitsX86AVX2     = cpuArchBits(0x00000008)
+	cpuArchBitsRISCVRVV    = cpuArchBits(0x00000010)
+	cpuArchBitsWASMSIMD128 = cpuArchBits(0x00000020)
 )
 
 func calcCPUArchBits(n *a.Func) (ret cpuArchBits) {
@@ -44,8 +47,16 @@ func calcCPUArchBits(n *a.Func) (ret cpuArchBits) {
 			ret |= cpuArchBitsARMNeon
 		case t.IDX86SSE42:
 			ret |= cpuArchBitsX86SSE42
+		case t.IDARMSHA2:
+			ret |= cpuArchBitsARMNeon
 		case t.IDX86AVX2:
 			ret |= cpuArchBitsX86SSE42 | cpuArchBitsX86AVX2
+		case t.IDX86SHA:
+			ret |= cpuArchBitsX86SSE42
+		case t.IDRISCVRVV:
+			ret |= cpuArchBitsRISCVRVV
+		case t.IDWASMSIMD128:
+			ret |= cpuArchBitsWASMSIMD128
 		}
 	}
 	return ret
@@ -63,6 +74,11 @@ func (q *checker) tcheckCPUArchBits(cab cpuArchBits, typ *a.TypeExpr) error {
 			need = cpuArchBitsARMNeon
 		case t.IDX86SSE42Utility, t.IDX86M128I:
 			need = cpuArchBitsX86SSE42
+		case t.IDRISCVRVVUtility,
+			t.IDRISCVRVVU8M1, t.IDRISCVRVVU16M2, t.IDRISCVRVVU32M1, t.IDRISCVRVVU32M4:
+			need = cpuArchBitsRISCVRVV
+		case t.IDWASMSIMD128Utility, t.IDWASMV128:
+			need = cpuArchBitsWASMSIMD128
 		}
 		if (cab & need) != need {
 			return fmt.Errorf("check: missing cpu_arch for %q", typ.Innermost().Str(q.tm))
@@ -93,6 +109,13 @@ func (q *checker) tcheckVars(cab cpuArchBits, block []*a.Node) error {
 		if err := q.tcheckTypeExpr(o.XType(), 0); err != nil {
 			return err
 		}
+		if q.c.hasUnionType(o.XType()) {
+			return fmt.Errorf("check: union type %q not allowed for var %q",
+				o.XType().Str(q.tm), name.Str(q.tm))
+		} else if q.c.isForeignEnumType(o.XType(), 0) {
+			return fmt.Errorf("check: enum type %q, from another package, not allowed for var %q",
+				o.XType().Str(q.tm), name.Str(q.tm))
+		}
 		if err := q.tcheckCPUArchBits(cab, o.XType()); err != nil {
 			return err
 		}
@@ -159,16 +182,28 @@ func (q *checker) tcheckStatement(n *a.Node) error {
 				n.Keyword().Str(q.tm), n.IO().Str(q.tm), typ.Str(q.tm))
 		}
 
-		arg1Typ := typeExprSliceU8
-		if n.Keyword() == t.IDIOLimit {
-			arg1Typ = typeExprU64
-		}
 		if err := q.tcheckExpr(n.Arg1(), 0); err != nil {
 			return err
 		}
-		if typ := n.Arg1().MType(); !typ.EqIgnoringRefinements(arg1Typ) {
-			return fmt.Errorf("check: %s expression %q, of type %q, does not have type %q",
-				n.Keyword().Str(q.tm), n.Arg1().Str(q.tm), typ.Str(q.tm), arg1Typ.Str(q.tm))
+		if n.Keyword() == t.IDIOChecksum {
+			// The hasher is updated by calling its update_u32 method, as per
+			// the base.hasher_u32 interface.
+			typ := n.Arg1().MType()
+			qid := typ.QID()
+			if f := q.c.funcs[t.QQID{qid[0], qid[1], t.IDUpdateU32}]; (typ.Decorator() != 0) ||
+				(f == nil) || (f.Effect() != a.EffectImpure) {
+				return fmt.Errorf("check: %s expression %q, of type %q, does not have an update_u32 method",
+					n.Keyword().Str(q.tm), n.Arg1().Str(q.tm), typ.Str(q.tm))
+			}
+		} else {
+			arg1Typ := typeExprSliceU8
+			if n.Keyword() == t.IDIOLimit {
+				arg1Typ = typeExprU64
+			}
+			if typ := n.Arg1().MType(); !typ.EqIgnoringRefinements(arg1Typ) {
+				return fmt.Errorf("check: %s expression %q, of type %q, does not have type %q",
+					n.Keyword().Str(q.tm), n.Arg1().Str(q.tm), typ.Str(q.tm), arg1Typ.Str(q.tm))
+			}
 		}
 
 		for _, o := range n.Body() {
@@ -208,6 +243,11 @@ func (q *checker) tcheckStatement(n *a.Node) error {
 	case a.KJump:
 		// No-op.
 
+	case a.KSwitch:
+		if err := q.tcheckSwitch(n.AsSwitch()); err != nil {
+			return err
+		}
+
 	case a.KRet:
 		n := n.AsRet()
 		lTyp := q.astFunc.Out()
@@ -258,6 +298,77 @@ func (q *checker) tcheckStatement(n *a.Node) error {
 	return nil
 }
 
+func (q *checker) tcheckSwitch(n *a.Switch) error {
+	value := n.Value()
+	if value.Effect() != 0 {
+		return fmt.Errorf("check: internal error: switch-value is not effect-free")
+	}
+	if err := q.tcheckExpr(value, 0); err != nil {
+		return err
+	}
+	typ := value.MType()
+	e := q.c.enumOf(typ)
+	if (e == nil) && !typ.IsNumType() {
+		return fmt.Errorf("check: switch value %q, of type %q, does not have an enum or integer type",
+			value.Str(q.tm), typ.Str(q.tm))
+	}
+
+	seen := map[string]bool{}
+	for _, o := range n.Cases() {
+		o := o.AsCase()
+		q.errFilename, q.errLine = o.Filename(), o.Line()
+		for _, v := range o.Values() {
+			v := v.AsExpr()
+			if err := q.tcheckExpr(v, 0); err != nil {
+				return err
+			}
+			vTyp := v.MType()
+			if vTyp.IsIdeal() && (e == nil) {
+				// No-op.
+			} else if !vTyp.EqIgnoringRefinements(typ) {
+				return fmt.Errorf("check: case value %q, of type %q, does not have type %q",
+					v.Str(q.tm), vTyp.Str(q.tm), typ.Str(q.tm))
+			}
+			cv := v.ConstValue()
+			if cv == nil {
+				return fmt.Errorf("check: case value %q is not constant", v.Str(q.tm))
+			} else if e == nil {
+				if b := numTypeBounds[typ.QID()[1]]; (cv.Cmp(b[0]) < 0) || (cv.Cmp(b[1]) > 0) {
+					return fmt.Errorf("check: case value %q is out of range for type %q",
+						v.Str(q.tm), typ.Str(q.tm))
+				}
+			}
+			if seen[cv.String()] {
+				return fmt.Errorf("check: duplicate case value %q", v.Str(q.tm))
+			}
+			seen[cv.String()] = true
+		}
+		for _, o := range o.Body() {
+			if err := q.tcheckStatement(o); err != nil {
+				return err
+			}
+		}
+		setPlaceholderMBoundsMType(o.AsNode())
+	}
+
+	// Exhaustiveness for integer switch values depends on their bounds, so it
+	// is checked later, by bcheckSwitch.
+	if cases := n.Cases(); (e != nil) && !cases[len(cases)-1].AsCase().IsDefault() {
+		missing := []string(nil)
+		for i, o := range e.Members() {
+			if !seen[fmt.Sprint(i)] {
+				missing = append(missing, fmt.Sprintf("%q", o.AsExpr().Ident().Str(q.tm)))
+			}
+		}
+		if len(missing) > 0 {
+			q.errFilename, q.errLine = n.AsNode().AsRaw().FilenameLine()
+			return fmt.Errorf("check: switch on %q is not exhaustive: missing %s",
+				value.Str(q.tm), strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
 func (q *checker) tcheckFuncAssert(n *a.Assert) error {
 	if n.IsChooseCPUArch() {
 		cond := n.Condition()
@@ -278,6 +389,9 @@ func (q *checker) tcheckAssert(n *a.Assert) error {
 		return fmt.Errorf("check: assert condition %q, of type %q, does not have a boolean type",
 			cond.Str(q.tm), cond.MType().Str(q.tm))
 	}
+	if mentionsFloat(cond) {
+		return fmt.Errorf("check: assert condition %q mentions a floating point value", cond.Str(q.tm))
+	}
 	for _, o := range n.Args() {
 		if err := q.tcheckExpr(o.AsArg().Value(), 0); err != nil {
 			return err
@@ -290,6 +404,7 @@ func (q *checker) tcheckAssert(n *a.Assert) error {
 
 func (q *checker) tcheckEq(lID t.ID, lhs *a.Expr, lTyp *a.TypeExpr, rhs *a.Expr, rTyp *a.TypeExpr) error {
 	if (rTyp.IsIdeal() && lTyp.IsNumType()) ||
+		(rTyp.IsIdeal() && lTyp.IsFloatType() && idealFitsFloatType(rhs.ConstValue(), lTyp)) ||
 		(rTyp.EqIgnoringRefinements(lTyp)) ||
 		(rTyp.IsNullptr() && lTyp.Decorator() == t.IDNptr) {
 		return nil
@@ -326,6 +441,22 @@ func (q *checker) tcheckAssign(n *a.Assign) error {
 		return q.tcheckEq(0, lhs, lTyp, rhs, rTyp)
 	}
 
+	if lTyp.IsFloatType() {
+		switch n.Operator() {
+		case t.IDPlusEq, t.IDMinusEq, t.IDStarEq, t.IDSlashEq:
+			if (rTyp.IsIdeal() && idealFitsFloatType(rhs.ConstValue(), lTyp)) || lTyp.Eq(rTyp) {
+				return nil
+			}
+			return fmt.Errorf("check: assignment %q: %q and %q, of types %q and %q, do not have compatible types",
+				n.Operator().Str(q.tm),
+				lhs.Str(q.tm), rhs.Str(q.tm),
+				lTyp.Str(q.tm), rTyp.Str(q.tm),
+			)
+		}
+		return fmt.Errorf("check: assignment %q: assignee %q, of type %q, has a floating point type",
+			n.Operator().Str(q.tm), lhs.Str(q.tm), lTyp.Str(q.tm))
+	}
+
 	if !lTyp.IsNumType() {
 		return fmt.Errorf("check: assignment %q: assignee %q, of type %q, does not have numeric type",
 			n.Operator().Str(q.tm), lhs.Str(q.tm), lTyp.Str(q.tm))
@@ -365,6 +496,16 @@ func (q *checker) tcheckLoop(n a.Loop) error {
 		}
 		setPlaceholderMBoundsMType(o)
 	}
+	if w, ok := n.(*a.While); ok && (w.Variant() != nil) {
+		v := w.Variant()
+		if err := q.tcheckExpr(v, 0); err != nil {
+			return err
+		}
+		if typ := v.MType(); !typ.IsNumTypeOrIdeal() || typ.IsFloatType() {
+			return fmt.Errorf("check: decreases expression %q, of type %q, does not have an integer type",
+				v.Str(q.tm), typ.Str(q.tm))
+		}
+	}
 	for _, o := range n.Body() {
 		if err := q.tcheckStatement(o); err != nil {
 			return err
@@ -662,6 +803,9 @@ func (q *checker) tcheckExprCall(n *a.Expr, depth uint32) error {
 
 func (q *checker) tcheckDot(n *a.Expr, depth uint32) error {
 	lhs := n.LHS().AsExpr()
+	if e := q.enumNamedBy(lhs); e != nil {
+		return q.tcheckEnumMember(n, e)
+	}
 	if err := q.tcheckExpr(lhs, depth); err != nil {
 		return err
 	}
@@ -726,6 +870,56 @@ func (q *checker) tcheckDot(n *a.Expr, depth uint32) error {
 		n.Ident().Str(q.tm), lTyp.Str(q.tm), n.Str(q.tm))
 }
 
+// enumNamedBy returns the enum named by n, an expression like "foo" or
+// "pkg.foo", or nil if n does not name one. If non-nil, n is also type checked.
+func (q *checker) enumNamedBy(n *a.Expr) *a.Enum {
+	if n.MType() != nil {
+		return nil
+	}
+	switch n.Operator() {
+	case 0:
+		id := n.Ident()
+		if _, ok := q.localVars[id]; ok || (q.c.topLevelNames[id] != a.KEnum) {
+			return nil
+		}
+		n.SetConstValue(zero)
+		n.SetMType(typeExprEnum)
+		return q.c.enums[t.QID{0, id}]
+
+	case t.IDDot:
+		pkg := n.LHS().AsExpr()
+		if pkg.Operator() != 0 {
+			return nil
+		} else if _, ok := q.localVars[pkg.Ident()]; ok || (q.c.topLevelNames[pkg.Ident()] != a.KUse) {
+			return nil
+		}
+		e := q.c.enums[t.QID{pkg.Ident(), n.Ident()}]
+		if e != nil {
+			pkg.SetConstValue(zero)
+			pkg.SetMType(typeExprPackage)
+			n.SetConstValue(zero)
+			n.SetMType(typeExprEnum)
+		}
+		return e
+	}
+	return nil
+}
+
+// tcheckEnumMember type checks n, an expression like "foo.BAR" where foo is
+// the enum e. Its type is foo and its constant value is BAR's value.
+func (q *checker) tcheckEnumMember(n *a.Expr, e *a.Enum) error {
+	for i, o := range e.Members() {
+		if o.AsExpr().Ident() == n.Ident() {
+			qid := e.QID()
+			n.SetConstValue(big.NewInt(int64(i)))
+			n.SetMType(a.NewTypeExpr(0, qid[0], qid[1], nil, nil, nil))
+			return nil
+		}
+	}
+	return fmt.Errorf("check: no member named %q found in enum %q",
+		n.Ident().Str(q.tm), e.QID().Str(q.tm))
+}
+
 func (q *checker) tcheckExprUnaryOp(n *a.Expr, depth uint32) error {
 	rhs := n.RHS().AsExpr()
 	if err := q.tcheckExpr(rhs, depth); err != nil {
@@ -735,7 +929,7 @@ func (q *checker) tcheckExprUnaryOp(n *a.Expr, depth uint32) error {
 
 	switch n.Operator() {
 	case t.IDXUnaryPlus, t.IDXUnaryMinus:
-		if !rTyp.IsNumTypeOrIdeal() {
+		if !rTyp.IsNumTypeOrIdeal() && !rTyp.IsFloatType() {
 			return fmt.Errorf("check: unary %q: %q, of type %q, does not have a numeric type",
 				n.Operator().AmbiguousForm().Str(q.tm), rhs.Str(q.tm), rTyp.Str(q.tm))
 		}
@@ -774,10 +968,19 @@ func (q *checker) tcheckExprBinaryOp(n *a.Expr, depth uint32) error {
 		if err := q.tcheckTypeExpr(rhs, 0); err != nil {
 			return err
 		}
-		if lTyp.IsNumTypeOrIdeal() && rhs.IsNumType() {
+		if (lTyp.IsNumTypeOrIdeal() || (q.c.enumOf(lTyp) != nil)) && rhs.IsNumType() {
+			n.SetMType(rhs)
+			return nil
+		}
+		if (lTyp.IsNumTypeOrIdeal() || lTyp.IsFloatType()) && rhs.IsFloatType() {
 			n.SetMType(rhs)
 			return nil
 		}
+		if lTyp.IsFloatType() && rhs.IsNumType() {
+			return fmt.Errorf("check: cannot convert expression %q, of type %q, as type %q; "+
+				"use a saturating method such as %s.to_u32_saturating() instead",
+				lhs.Str(q.tm), lTyp.Str(q.tm), rhs.Str(q.tm), lhs.Str(q.tm))
+		}
 		return fmt.Errorf("check: cannot convert expression %q, of type %q, as type %q",
 			lhs.Str(q.tm), lTyp.Str(q.tm), rhs.Str(q.tm))
 	}
@@ -787,6 +990,10 @@ func (q *checker) tcheckExprBinaryOp(n *a.Expr, depth uint32) error {
 	}
 	rTyp := rhs.MType()
 
+	if lTyp.IsFloatType() || rTyp.IsFloatType() {
+		return q.tcheckExprBinaryOpFloat(n, lhs, lTyp, rhs, rTyp)
+	}
+
 	pointerComparison := false
 	switch op {
 	case t.IDXBinaryAnd, t.IDXBinaryOr:
@@ -809,6 +1016,9 @@ func (q *checker) tcheckExprBinaryOp(n *a.Expr, depth uint32) error {
 			if lTyp.Eq(typeExprStatus) && rTyp.Eq(typeExprStatus) {
 				break
 			}
+			if (q.c.enumOf(lTyp) != nil) && lTyp.EqIgnoringRefinements(rTyp) {
+				break
+			}
 			lNullptr := lTyp.Eq(typeExprNullptr)
 			rNullptr := rTyp.Eq(typeExprNullptr)
 			if (lNullptr && rNullptr) ||
@@ -890,6 +1100,67 @@ func (q *checker) tcheckExprBinaryOp(n *a.Expr, depth uint32) error {
 	return nil
 }
 
+// tcheckExprBinaryOpFloat type-checks a binary operator whose operands have
+// floating point types. Only arithmetic (other than "%") and ordering
+// comparisons are allowed. There is no "==" or "<>", since exact equality of
+// IEEE 754 values is almost always a bug, and C compilers warn about it.
+//
+// One of the operands may be an ideal constant, but only if its value is
+// exactly representable in the other operand's type.
+func (q *checker) tcheckExprBinaryOpFloat(n *a.Expr, lhs *a.Expr, lTyp *a.TypeExpr, rhs *a.Expr, rTyp *a.TypeExpr) error {
+	op := n.Operator()
+	switch op {
+	case t.IDXBinaryPlus, t.IDXBinaryMinus, t.IDXBinaryStar, t.IDXBinarySlash,
+		t.IDXBinaryLessThan, t.IDXBinaryLessEq, t.IDXBinaryGreaterEq, t.IDXBinaryGreaterThan:
+		// No-op.
+	default:
+		return fmt.Errorf("check: binary %q: %q and %q, of types %q and %q; "+
+			"cannot apply the operator to floating point values",
+			op.AmbiguousForm().Str(q.tm),
+			lhs.Str(q.tm), rhs.Str(q.tm),
+			lTyp.Str(q.tm), rTyp.Str(q.tm),
+		)
+	}
+
+	typ := lTyp
+	if !lTyp.Eq(rTyp) {
+		ok := false
+		if lTyp.IsIdeal() {
+			typ, ok = rTyp, idealFitsFloatType(lhs.ConstValue(), rTyp)
+		} else if rTyp.IsIdeal() {
+			ok = idealFitsFloatType(rhs.ConstValue(), lTyp)
+		}
+		if !ok {
+			return fmt.Errorf("check: binary %q: %q and %q, of types %q and %q, do not have compatible types",
+				op.AmbiguousForm().Str(q.tm),
+				lhs.Str(q.tm), rhs.Str(q.tm),
+				lTyp.Str(q.tm), rTyp.Str(q.tm),
+			)
+		}
+	}
+
+	if (op < t.ID(len(comparisonOps))) && comparisonOps[op] {
+		n.SetMType(typeExprBool)
+	} else {
+		n.SetMType(typ)
+	}
+	return nil
+}
+
+// idealFitsFloatType returns whether cv, an ideal (integer) constant, is
+// exactly representable as a value of typ, a floating point type.
+func idealFitsFloatType(cv *big.Int, typ *a.TypeExpr) bool {
+	if cv == nil {
+		return false
+	}
+	prec, maxBitLen := uint(53), 1024
+	if typ.QID()[1] == t.IDF32 {
+		prec, maxBitLen = 24, 128
+	}
+	f := big.NewFloat(0).SetPrec(prec).SetInt(cv)
+	return (f.Acc() == big.Exact) && (cv.BitLen() <= maxBitLen)
+}
+
 func evalConstValueBinaryOp(tm *t.Map, n *a.Expr, l *big.Int, r *big.Int) (*big.Int, error) {
 	switch n.Operator() {
 	case t.IDXBinaryPlus:
@@ -960,6 +1231,7 @@ func (q *checker) tcheckExprAssociativeOp(n *a.Expr, depth uint32) error {
 		t.IDXAssociativeAmp, t.IDXAssociativePipe, t.IDXAssociativeHat:
 
 		expr, typ := (*a.Expr)(nil), (*a.TypeExpr)(nil)
+		ideals := []*a.Expr(nil)
 		for _, o := range n.Args() {
 			o := o.AsExpr()
 			if err := q.tcheckExpr(o, depth); err != nil {
@@ -967,9 +1239,12 @@ func (q *checker) tcheckExprAssociativeOp(n *a.Expr, depth uint32) error {
 			}
 			oTyp := o.MType()
 			if oTyp.IsIdeal() {
+				ideals = append(ideals, o)
 				continue
 			}
-			if !oTyp.IsNumType() {
+			if oTyp.IsFloatType() && (n.Operator() == t.IDXAssociativePlus || n.Operator() == t.IDXAssociativeStar) {
+				// No-op. Floating point values can be added and multiplied.
+			} else if !oTyp.IsNumType() {
 				return fmt.Errorf("check: associative %q: %q, of type %q, does not have a numeric type",
 					n.Operator().AmbiguousForm().Str(q.tm), o.Str(q.tm), oTyp.Str(q.tm))
 			}
@@ -987,6 +1262,16 @@ func (q *checker) tcheckExprAssociativeOp(n *a.Expr, depth uint32) error {
 		}
 		if typ == nil {
 			typ = typeExprIdeal
+		} else if typ.IsFloatType() {
+			for _, o := range ideals {
+				if !idealFitsFloatType(o.ConstValue(), typ) {
+					return fmt.Errorf("check: associative %q: %q and %q, of types %q and %q, "+
+						"do not have compatible types",
+						n.Operator().AmbiguousForm().Str(q.tm),
+						expr.Str(q.tm), o.Str(q.tm),
+						expr.MType().Str(q.tm), o.MType().Str(q.tm))
+				}
+			}
 		}
 		n.SetMType(typ)
 
@@ -1115,6 +1400,12 @@ swtch:
 				break swtch
 			}
 		}
+		if q.c.enums[qid] != nil {
+			if typ.IsRefined() {
+				return fmt.Errorf("check: cannot refine enum type %q", typ.Str(q.tm))
+			}
+			break swtch
+		}
 		return fmt.Errorf("check: %q is not a type", typ.Str(q.tm))
 
 	case t.IDArray:
@@ -1148,9 +1439,34 @@ func (q *checker) tcheckChoose(n *a.Choose) error {
 	} else if !f.Choosy() {
 		return fmt.Errorf("check: choose assignee %q is not choosy", fQQID[2].Str(q.tm))
 	}
-	for _, o := range n.Args() {
+	return q.tcheckChooseArgs(f, n.Args())
+}
+
+// tcheckFuncChoices checks a choosy function's initial choices: the "choosy =
+// [etc]" list that is evaluated once, when the receiver is initialized. As
+// there is no other state at that time, every choice but the last must be
+// conditioned on a cpu_arch.
+func (q *checker) tcheckFuncChoices(f *a.Func) error {
+	choices := f.Choices()
+	for i, o := range choices {
+		o := o.AsExpr()
+		gQQID := t.QQID{f.Receiver()[0], f.Receiver()[1], o.Ident()}
+		g := q.c.funcs[gQQID]
+		if (g == nil) || (i == len(choices)-1) || (g == f) {
+			continue
+		} else if !g.HasChooseCPUArch() {
+			return fmt.Errorf("check: initial choice %q for %q is not a cpu_arch function",
+				gQQID.Str(q.tm), f.QQID().Str(q.tm))
+		}
+	}
+	return q.tcheckChooseArgs(f, choices)
+}
+
+func (q *checker) tcheckChooseArgs(f *a.Func, args []*a.Node) error {
+	fQQID := f.QQID()
+	for _, o := range args {
 		o := o.AsExpr()
-		gQQID := t.QQID{qqid[0], qqid[1], o.Ident()}
+		gQQID := t.QQID{fQQID[0], fQQID[1], o.Ident()}
 		g := q.c.funcs[gQQID]
 		if g == nil {
 			return fmt.Errorf("check: no function named %q", gQQID.Str(q.tm))
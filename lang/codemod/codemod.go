@@ -0,0 +1,420 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codemod implements pattern-based rewriting of Wuffs source code.
+//
+// A rule is a pattern and a replacement, written as "pattern -> replacement".
+// Both are either Wuffs expressions or Wuffs statements. Like "gofmt -r",
+// single-character lower-case identifiers in expression positions (but not,
+// for example, argument names or field selectors) are wildcards. In the
+// pattern, a wildcard matches any sub-expression. Repeated wildcards must
+// match equivalent sub-expressions. In the replacement, a wildcard stands for
+// whatever it matched in the pattern. For example, this rule renames a
+// built-in method and adds a new argument:
+//
+//	x.low_bits(n: y) -> x.low_bits(n: y, mask: true)
+//
+// Rewriting works on the token stream, guided by the parse tree, so that
+// comments and the rest of the file are untouched. Expression rules apply to
+// every expression, including those in assert, pre, inv and post clauses, so
+// that the facts that the bounds checker relies on are rewritten in step with
+// the code that they describe.
+package codemod
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/wuffs/lang/parse"
+
+	a "github.com/google/wuffs/lang/ast"
+	t "github.com/google/wuffs/lang/token"
+)
+
+// Rule is a parsed "pattern -> replacement" rule.
+type Rule struct {
+	stmt        bool
+	pattern     []t.Token
+	replacement []t.Token
+
+	// patternWildcards and replacementWildcards are, for each token in the
+	// pattern and replacement, whether that token is a wildcard.
+	patternWildcards     []bool
+	replacementWildcards []bool
+}
+
+// ParseRule parses a "pattern -> replacement" rule.
+func ParseRule(tm *t.Map, rule string) (*Rule, error) {
+	i := strings.Index(rule, "->")
+	if i < 0 {
+		return nil, fmt.Errorf("codemod: rule %q does not contain \"->\"", rule)
+	}
+	return NewRule(tm, strings.TrimSpace(rule[:i]), strings.TrimSpace(rule[i+2:]))
+}
+
+// NewRule returns a rule that rewrites pattern to replacement.
+func NewRule(tm *t.Map, pattern string, replacement string) (*Rule, error) {
+	pStmt, pTokens, pWildcards, err := parseFragment(tm, pattern)
+	if err != nil {
+		return nil, err
+	}
+	rStmt, rTokens, rWildcards, err := parseFragment(tm, replacement)
+	if err != nil {
+		return nil, err
+	}
+	if len(pTokens) == 0 {
+		return nil, errors.New("codemod: empty pattern")
+	} else if pStmt != rStmt {
+		return nil, fmt.Errorf("codemod: %q and %q are not both expressions or both statements",
+			pattern, replacement)
+	}
+
+	bound := map[t.ID]bool{}
+	for i, w := range pWildcards {
+		if w {
+			bound[pTokens[i].ID] = true
+		}
+	}
+	for i, w := range rWildcards {
+		if w && !bound[rTokens[i].ID] {
+			return nil, fmt.Errorf("codemod: replacement wildcard %q is not in the pattern",
+				rTokens[i].ID.Str(tm))
+		}
+	}
+
+	return &Rule{
+		stmt:                 pStmt,
+		pattern:              pTokens,
+		replacement:          rTokens,
+		patternWildcards:     pWildcards,
+		replacementWildcards: rWildcards,
+	}, nil
+}
+
+// parseFragment tokenizes and parses s, either as an expression or, failing
+// that, as a single statement.
+func parseFragment(tm *t.Map, s string) (stmt bool, tokens []t.Token, wildcards []bool, retErr error) {
+	const filename = "codemod.wuffs"
+	tokens, _, err := t.Tokenize(tm, filename, []byte(s))
+	if err != nil {
+		return false, nil, nil, err
+	}
+	tokens = trimSemicolons(tokens)
+
+	singles := map[int]bool{}
+	whole := false
+	opts := &parse.Options{
+		AllowDoubleUnderscoreNames: true,
+		Span: func(n *a.Node, begin int, end int) {
+			if n.Kind() != a.KExpr {
+				return
+			} else if end == begin+1 {
+				singles[begin] = true
+			}
+			if (begin == 0) && (end == len(tokens)) {
+				whole = true
+			}
+		},
+	}
+	if _, err := parse.ParseExpr(tm, filename, tokens, opts); (err == nil) && whole {
+		return false, tokens, findWildcards(tm, tokens, singles), nil
+	}
+
+	// Parse s as the body of a coroutine, which may contain any statement.
+	const prefix = "pri func __codemod.__codemod?() {\n"
+	wrapped, _, err := t.Tokenize(tm, filename, []byte(prefix+s+"\n}\n"))
+	if err != nil {
+		return false, nil, nil, err
+	}
+	offset := 0
+	for ; (offset < len(wrapped)) && (wrapped[offset].ID != t.IDOpenCurly); offset++ {
+	}
+	offset++
+	singles = map[int]bool{}
+	single := false
+	opts.Span = func(n *a.Node, begin int, end int) {
+		if n.Kind() == a.KExpr {
+			if end == begin+1 {
+				singles[begin-offset] = true
+			}
+		} else if (begin == offset) && (end == offset+len(tokens)) {
+			single = true
+		}
+	}
+	if _, err := parse.Parse(tm, filename, wrapped, opts); err != nil {
+		return false, nil, nil, fmt.Errorf("codemod: %q is neither an expression nor a statement: %v", s, err)
+	}
+	if !single {
+		return false, nil, nil, fmt.Errorf("codemod: %q is not a single statement", s)
+	}
+	return true, tokens, findWildcards(tm, tokens, singles), nil
+}
+
+func findWildcards(tm *t.Map, tokens []t.Token, singles map[int]bool) []bool {
+	wildcards := make([]bool, len(tokens))
+	for i, tok := range tokens {
+		if s := tok.ID.Str(tm); singles[i] && (len(s) == 1) && ('a' <= s[0]) && (s[0] <= 'z') {
+			wildcards[i] = true
+		}
+	}
+	return wildcards
+}
+
+func trimSemicolons(tokens []t.Token) []t.Token {
+	for (len(tokens) > 0) && (tokens[len(tokens)-1].ID == t.IDSemicolon) {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// span is a half-open range of token indexes, and the expression (if any)
+// that those tokens were parsed as.
+type span struct {
+	begin int
+	end   int
+	expr  *a.Expr
+}
+
+// Apply rewrites src, the tokens of a Wuffs source file, by applying each
+// rule in turn. It returns the rewritten tokens and the number of rewrites.
+//
+// For each rule, matches are non-overlapping and outermost-first: the
+// sub-expressions of a rewritten expression are not themselves rewritten by
+// that rule, although they may be by subsequent rules.
+func Apply(tm *t.Map, filename string, src []t.Token, rules []*Rule) (dst []t.Token, numRewrites int, retErr error) {
+	dst = src
+	for _, r := range rules {
+		n := 0
+		dst, n, retErr = r.apply(tm, filename, dst)
+		if retErr != nil {
+			return nil, 0, retErr
+		}
+		numRewrites += n
+	}
+
+	if numRewrites > 0 {
+		if _, err := parse.Parse(tm, filename, dst, &parse.Options{
+			AllowDoubleUnderscoreNames: true,
+		}); err != nil {
+			return nil, 0, fmt.Errorf("codemod: rewritten %s does not parse: %v", filename, err)
+		}
+	}
+	return dst, numRewrites, nil
+}
+
+func (r *Rule) apply(tm *t.Map, filename string, src []t.Token) ([]t.Token, int, error) {
+	// exprSpans are keyed by their begin index, longest first.
+	exprSpans := map[int][]span{}
+	stmtSpans := map[int][]span{}
+	if _, err := parse.Parse(tm, filename, src, &parse.Options{
+		AllowDoubleUnderscoreNames: true,
+		Span: func(n *a.Node, begin int, end int) {
+			if n.Kind() == a.KExpr {
+				exprSpans[begin] = insertSpan(exprSpans[begin], span{begin, end, n.AsExpr()})
+			} else {
+				stmtSpans[begin] = insertSpan(stmtSpans[begin], span{begin, end, nil})
+			}
+		},
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	m := &matcher{
+		tm:        tm,
+		rule:      r,
+		src:       src,
+		exprSpans: exprSpans,
+		bindings:  map[t.ID]span{},
+	}
+
+	dst := []t.Token(nil)
+	numRewrites := 0
+	for i := 0; i < len(src); {
+		candidates := exprSpans[i]
+		if r.stmt {
+			candidates = stmtSpans[i]
+		}
+
+		matched := false
+		for _, c := range candidates {
+			for k := range m.bindings {
+				delete(m.bindings, k)
+			}
+			if m.match(0, c.begin, c.end) {
+				dst = m.appendReplacement(dst, c)
+				i = c.end
+				numRewrites++
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			dst = append(dst, src[i])
+			i++
+		}
+	}
+	return dst, numRewrites, nil
+}
+
+// insertSpan inserts s into spans, which is sorted by decreasing end.
+// Duplicate ranges are dropped.
+func insertSpan(spans []span, s span) []span {
+	i := 0
+	for ; (i < len(spans)) && (spans[i].end >= s.end); i++ {
+		if spans[i].end == s.end {
+			return spans
+		}
+	}
+	spans = append(spans, span{})
+	copy(spans[i+1:], spans[i:])
+	spans[i] = s
+	return spans
+}
+
+type matcher struct {
+	tm        *t.Map
+	rule      *Rule
+	src       []t.Token
+	exprSpans map[int][]span
+	bindings  map[t.ID]span
+}
+
+// match returns whether the rule's pattern, from the pi'th token onwards,
+// matches the src tokens from si (inclusive) to end (exclusive).
+func (m *matcher) match(pi int, si int, end int) bool {
+	if pi == len(m.rule.pattern) {
+		return si == end
+	}
+	p := m.rule.pattern[pi]
+
+	if !m.rule.patternWildcards[pi] {
+		return (si < end) && (m.src[si].ID == p.ID) && m.match(pi+1, si+1, end)
+	}
+
+	if b, ok := m.bindings[p.ID]; ok {
+		n := b.end - b.begin
+		if (si+n > end) || !equalIDs(m.src[b.begin:b.end], m.src[si:si+n]) {
+			return false
+		}
+		return m.match(pi+1, si+n, end)
+	}
+
+	for _, s := range m.exprSpans[si] {
+		if s.end > end {
+			continue
+		}
+		m.bindings[p.ID] = s
+		if m.match(pi+1, s.end, end) {
+			return true
+		}
+		delete(m.bindings, p.ID)
+	}
+	return false
+}
+
+func equalIDs(x []t.Token, y []t.Token) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i].ID != y[i].ID {
+			return false
+		}
+	}
+	return true
+}
+
+// appendReplacement appends the rule's replacement for the matched tokens.
+//
+// Replacement tokens take the line number of the first matched token, and
+// wildcard tokens keep their own line numbers, but line numbers never
+// decrease. This keeps comments, which are keyed by line, in place.
+func (m *matcher) appendReplacement(dst []t.Token, matched span) []t.Token {
+	line := m.src[matched.begin].Line
+	repl := m.rule.replacement
+	for i, tok := range repl {
+		if !m.rule.replacementWildcards[i] {
+			dst = append(dst, t.Token{ID: tok.ID, Line: line})
+			continue
+		}
+
+		b := m.bindings[tok.ID]
+		parens := needsParens(b.expr) && !isDelimited(repl, i) &&
+			!isParenthesized(m.src[b.begin:b.end])
+		if parens {
+			dst = append(dst, t.Token{ID: t.IDOpenParen, Line: line})
+		}
+		for _, o := range m.src[b.begin:b.end] {
+			if line < o.Line {
+				line = o.Line
+			}
+			dst = append(dst, t.Token{ID: o.ID, Line: line})
+		}
+		if parens {
+			dst = append(dst, t.Token{ID: t.IDCloseParen, Line: line})
+		}
+	}
+	return dst
+}
+
+// needsParens returns whether e, substituted for a wildcard, might need
+// parentheses to preserve its meaning.
+func needsParens(e *a.Expr) bool {
+	if e == nil {
+		return false
+	}
+	op := e.Operator()
+	return op.IsXUnaryOp() || op.IsXBinaryOp() || op.IsXAssociativeOp()
+}
+
+// isParenthesized returns whether tokens is "(etc)" for balanced etc.
+func isParenthesized(tokens []t.Token) bool {
+	if (len(tokens) < 2) || (tokens[0].ID != t.IDOpenParen) {
+		return false
+	}
+	depth := 0
+	for i, tok := range tokens {
+		if tok.ID.IsOpen() {
+			depth++
+		} else if tok.ID.IsClose() {
+			depth--
+			if depth == 0 {
+				return i == len(tokens)-1
+			}
+		}
+	}
+	return false
+}
+
+// isDelimited returns whether the i'th token of tokens is surrounded by
+// tokens (or by nothing) that separate, rather than bind to, expressions.
+func isDelimited(tokens []t.Token, i int) bool {
+	if i > 0 {
+		switch x := tokens[i-1].ID; {
+		case x.IsOpen(), x.IsAssign(), x == t.IDComma, x == t.IDColon:
+		case x.IsKeyword() && !x.IsUnaryOp() && !x.IsBinaryOp():
+		default:
+			return false
+		}
+	}
+	if i+1 < len(tokens) {
+		switch x := tokens[i+1].ID; {
+		case x.IsClose(), x == t.IDComma, x == t.IDOpenCurly, x == t.IDOpenDoubleCurly:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codemod
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/wuffs/lang/render"
+
+	t "github.com/google/wuffs/lang/token"
+)
+
+func rewrite(rules []string, src string) (dst string, numRewrites int, retErr error) {
+	const filename = "test.wuffs"
+	tm := &t.Map{}
+	tokens, comments, err := t.Tokenize(tm, filename, []byte(src))
+	if err != nil {
+		return "", 0, err
+	}
+	rs := []*Rule(nil)
+	for _, rule := range rules {
+		r, err := ParseRule(tm, rule)
+		if err != nil {
+			return "", 0, err
+		}
+		rs = append(rs, r)
+	}
+	tokens, numRewrites, err = Apply(tm, filename, tokens, rs)
+	if err != nil {
+		return "", 0, err
+	}
+	buf := &bytes.Buffer{}
+	if err := render.Render(buf, tm, tokens, comments); err != nil {
+		return "", 0, err
+	}
+	return buf.String(), numRewrites, nil
+}
+
+func TestApply(tt *testing.T) {
+	testCases := []struct {
+		rules []string
+		src   string
+		want  string
+	}{{
+		// Rename a method and add an argument, including within an assert.
+		rules: []string{"x.low_bits(n: y) -> x.low_bits(n: y, mask: true)"},
+		src: "pri func foo.bar!() {\n" +
+			"\tvar v : base.u32\n" +
+			"\t// Comment.\n" +
+			"\tv = this.a.low_bits(n: 3) + 1\n" +
+			"\tassert v.low_bits(n: 3) <= 7\n" +
+			"}\n",
+		want: "pri func foo.bar!() {\n" +
+			"\tvar v : base.u32\n" +
+			"\t// Comment.\n" +
+			"\tv = this.a.low_bits(n: 3, mask: true) + 1\n" +
+			"\tassert v.low_bits(n: 3, mask: true) <= 7\n" +
+			"}\n",
+	}, {
+		// Swap operands. Only the outermost match is rewritten.
+		rules: []string{"x - y -> 0 - (y - x)"},
+		src: "pri func foo.bar!() {\n" +
+			"\tthis.a = (this.b - 1) - this.c\n" +
+			"}\n",
+		want: "pri func foo.bar!() {\n" +
+			"\tthis.a = 0 - (this.c - (this.b - 1))\n" +
+			"}\n",
+	}, {
+		// Wildcards bound to binary expressions get parentheses if needed.
+		rules: []string{"f(a: x) -> x * 2"},
+		src: "pri func foo.bar!() {\n" +
+			"\tthis.a = f(a: this.b + 1)\n" +
+			"\tthis.a = f(a: this.c)\n" +
+			"}\n",
+		want: "pri func foo.bar!() {\n" +
+			"\tthis.a = (this.b + 1) * 2\n" +
+			"\tthis.a = this.c * 2\n" +
+			"}\n",
+	}, {
+		// Repeated wildcards must match equivalent sub-expressions.
+		rules: []string{"x + x -> x * 2"},
+		src: "pri func foo.bar!() {\n" +
+			"\tthis.a = this.b + this.b\n" +
+			"\tthis.a = this.b + this.c\n" +
+			"}\n",
+		want: "pri func foo.bar!() {\n" +
+			"\tthis.a = this.b * 2\n" +
+			"\tthis.a = this.b + this.c\n" +
+			"}\n",
+	}, {
+		// Argument names and selectors are not wildcards.
+		rules: []string{"f(a: x) -> f(b: x)"},
+		src: "pri func foo.bar!() {\n" +
+			"\tthis.a = f(a: 1)\n" +
+			"\tthis.a = f(c: 2)\n" +
+			"}\n",
+		want: "pri func foo.bar!() {\n" +
+			"\tthis.a = f(b: 1)\n" +
+			"\tthis.a = f(c: 2)\n" +
+			"}\n",
+	}, {
+		// Statement rules, e.g. adding a required effect annotation.
+		rules: []string{"x = y.read_u8() -> x = y.read_u8?()"},
+		src: "pri func foo.bar?(src: base.io_reader) {\n" +
+			"\tvar c : base.u8\n" +
+			"\tc = args.src.read_u8()\n" +
+			"\tif c == 0 {\n" +
+			"\t\tc = args.src.read_u8()\n" +
+			"\t}\n" +
+			"}\n",
+		want: "pri func foo.bar?(src: base.io_reader) {\n" +
+			"\tvar c : base.u8\n" +
+			"\tc = args.src.read_u8?()\n" +
+			"\tif c == 0 {\n" +
+			"\t\tc = args.src.read_u8?()\n" +
+			"\t}\n" +
+			"}\n",
+	}, {
+		// Rules apply in turn.
+		rules: []string{"x.foo() -> x.bar()", "x.bar() -> x.qux()"},
+		src: "pri func foo.bar!() {\n" +
+			"\tthis.a = this.b.foo()\n" +
+			"}\n",
+		want: "pri func foo.bar!() {\n" +
+			"\tthis.a = this.b.qux()\n" +
+			"}\n",
+	}}
+
+	for i, tc := range testCases {
+		have, n, err := rewrite(tc.rules, tc.src)
+		if err != nil {
+			tt.Errorf("i=%d: %v", i, err)
+			continue
+		}
+		if n == 0 {
+			tt.Errorf("i=%d: no rewrites", i)
+		}
+		if have != tc.want {
+			tt.Errorf("i=%d:\nhave:\n%s\nwant:\n%s", i, have, tc.want)
+		}
+	}
+}
+
+func TestParseRuleErrors(tt *testing.T) {
+	testCases := []string{
+		"x.foo()",
+		"-> x",
+		"x + y -> x + z",
+		"x + y -> z = x",
+		"x.foo( -> x",
+	}
+
+	for _, tc := range testCases {
+		if _, err := ParseRule(&t.Map{}, tc); err == nil {
+			tt.Errorf("%q: have nil error, want non-nil", tc)
+		}
+	}
+}
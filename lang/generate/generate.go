@@ -26,6 +26,7 @@ import (
 	"github.com/google/wuffs/lang/parse"
 	"github.com/google/wuffs/lang/wuffsroot"
 
+	cf "github.com/google/wuffs/cmd/commonflags"
 	a "github.com/google/wuffs/lang/ast"
 	t "github.com/google/wuffs/lang/token"
 )
@@ -34,6 +35,9 @@ type Generator func(packageName string, tm *t.Map, files []*a.File) ([]byte, err
 
 func Do(flags *flag.FlagSet, args []string, g Generator) error {
 	packageName := flags.String("package_name", "", "the package name of the Wuffs input code")
+	smtFlag := flags.String("smt", cf.SmtDefault, cf.SmtUsage)
+	smtcacheFlag := flags.String("smtcache", cf.SmtcacheDefault, cf.SmtcacheUsage)
+	terminationFlag := flags.Bool("termination", cf.TerminationDefault, cf.TerminationUsage)
 	if err := flags.Parse(args); err != nil {
 		return err
 	}
@@ -58,7 +62,12 @@ func Do(flags *flag.FlagSet, args []string, g Generator) error {
 			return err
 		}
 
-		if _, err := check.Check(tm, files, resolveUse); err != nil {
+		checkOpts := &check.Options{
+			SMTSolver:        *smtFlag,
+			SMTCacheDir:      *smtcacheFlag,
+			CheckTermination: *terminationFlag,
+		}
+		if _, err := check.Check(tm, files, resolveUse, checkOpts); err != nil {
 			return err
 		}
 
@@ -26,6 +26,14 @@ import (
 type Options struct {
 	AllowBuiltInNames          bool
 	AllowDoubleUnderscoreNames bool
+
+	// Span, if non-nil, is called for every expression and statement node
+	// parsed, with the half-open range of indexes into the src tokens that
+	// the node was parsed from. It can be called more than once for the same
+	// expression node, e.g. once without and once with enclosing parentheses.
+	//
+	// Statement ranges do not include any trailing semi-colon.
+	Span func(n *a.Node, begin int, end int)
 }
 
 func validConstName(s string) bool {
@@ -66,6 +74,7 @@ func Parse(tm *t.Map, filename string, src []t.Token, opts *Options) (*a.File, e
 		tm:       tm,
 		filename: filename,
 		src:      src,
+		srcLen:   len(src),
 	}
 	if len(src) > 0 {
 		p.lastLine = src[len(src)-1].Line
@@ -81,6 +90,7 @@ func ParseExpr(tm *t.Map, filename string, src []t.Token, opts *Options) (*a.Exp
 		tm:       tm,
 		filename: filename,
 		src:      src,
+		srcLen:   len(src),
 	}
 	if len(src) > 0 {
 		p.lastLine = src[len(src)-1].Line
@@ -95,11 +105,19 @@ type parser struct {
 	tm         *t.Map
 	filename   string
 	src        []t.Token
+	srcLen     int
 	opts       Options
 	lastLine   uint32
 	funcEffect a.Effect
 	loops      a.LoopStack
 	allowVar   bool
+
+	// noJumpLoops is the number of enclosing loops (the prefix of the loops
+	// stack) that cannot be the target of a break or continue.
+	noJumpLoops int
+
+	// interfaceName is the receiver for the interface methods being parsed.
+	interfaceName t.ID
 }
 
 func (p *parser) line() uint32 {
@@ -109,6 +127,17 @@ func (p *parser) line() uint32 {
 	return p.lastLine
 }
 
+// pos returns the index, into the original src tokens, of the next token.
+func (p *parser) pos() int {
+	return p.srcLen - len(p.src)
+}
+
+func (p *parser) span(n *a.Node, begin int) {
+	if p.opts.Span != nil {
+		p.opts.Span(n, begin, p.pos())
+	}
+}
+
 func (p *parser) peek1() t.ID {
 	if len(p.src) > 0 {
 		return p.src[0].ID
@@ -224,6 +253,7 @@ func (p *parser) parseTopLevelDecl() (*a.Node, error) {
 				}
 			}
 			asserts := []*a.Node(nil)
+			choices := []*a.Node(nil)
 			if p.peek1() == t.IDComma {
 				p.src = p.src[1:]
 				if p.peek1() == t.IDChoosy {
@@ -236,6 +266,22 @@ func (p *parser) parseTopLevelDecl() (*a.Node, error) {
 							p.filename, p.line())
 					}
 					flags |= a.FlagsChoosy
+					if p.peek1() == t.IDEq {
+						p.src = p.src[1:]
+						if x := p.peek1(); x != t.IDOpenBracket {
+							return nil, fmt.Errorf(`parse: expected "[", got %q at %s:%d`,
+								p.tm.ByID(x), p.filename, p.line())
+						}
+						p.src = p.src[1:]
+						choices, err = p.parseList(t.IDCloseBracket, (*parser).parseIdentAsExprNode)
+						if err != nil {
+							return nil, err
+						}
+						if len(choices) == 0 {
+							return nil, fmt.Errorf(`parse: empty "choosy" list at %s:%d`,
+								p.filename, p.line())
+						}
+					}
 					if p.peek1() != t.IDOpenCurly {
 						if x := p.peek1(); x != t.IDComma {
 							return nil, fmt.Errorf(`parse: expected ",", got %q at %s:%d`,
@@ -249,7 +295,7 @@ func (p *parser) parseTopLevelDecl() (*a.Node, error) {
 				if err != nil {
 					return nil, err
 				}
-				if err := p.assertsSorted(asserts, true); err != nil {
+				if err := p.assertsSorted(asserts, true, false); err != nil {
 					return nil, err
 				}
 				for _, o := range asserts {
@@ -290,7 +336,7 @@ func (p *parser) parseTopLevelDecl() (*a.Node, error) {
 			}
 			p.funcEffect = 0
 			in := a.NewStruct(0, p.filename, line, t.IDArgs, nil, argFields)
-			return a.NewFunc(flags, p.filename, line, id0, id1, in, out, asserts, body).AsNode(), nil
+			return a.NewFunc(flags, p.filename, line, id0, id1, in, out, choices, asserts, body).AsNode(), nil
 
 		case t.IDStatus:
 			p.src = p.src[1:]
@@ -357,11 +403,134 @@ func (p *parser) parseTopLevelDecl() (*a.Node, error) {
 			}
 			p.src = p.src[1:]
 			return a.NewStruct(flags, p.filename, line, name, implements, fields).AsNode(), nil
+
+		case t.IDUnion:
+			p.src = p.src[1:]
+			if (flags & a.FlagsPublic) != 0 {
+				return nil, fmt.Errorf(`parse: union must be pri at %s:%d`, p.filename, p.line())
+			}
+			name, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			if !p.opts.AllowDoubleUnderscoreNames && containsDoubleUnderscore(p.tm.ByID(name)) {
+				return nil, fmt.Errorf(`parse: double-underscore %q used for union name at %s:%d`,
+					p.tm.ByID(name), p.filename, p.line())
+			}
+
+			members, err := p.parseList(t.IDCloseParen, (*parser).parseUnionMemberNode)
+			if err != nil {
+				return nil, err
+			}
+			if len(members) == 0 {
+				return nil, fmt.Errorf(`parse: union %q has no members at %s:%d`,
+					p.tm.ByID(name), p.filename, p.line())
+			}
+			if x := p.peek1(); x != t.IDSemicolon {
+				got := p.tm.ByID(x)
+				return nil, fmt.Errorf(`parse: expected (implicit) ";", got %q at %s:%d`, got, p.filename, p.line())
+			}
+			p.src = p.src[1:]
+			return a.NewStruct(flags|a.FlagsUnion, p.filename, line, name, nil, members).AsNode(), nil
+
+		case t.IDEnum:
+			p.src = p.src[1:]
+			name, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			if !p.opts.AllowDoubleUnderscoreNames && containsDoubleUnderscore(p.tm.ByID(name)) {
+				return nil, fmt.Errorf(`parse: double-underscore %q used for enum name at %s:%d`,
+					p.tm.ByID(name), p.filename, p.line())
+			}
+
+			members, err := p.parseList(t.IDCloseParen, (*parser).parseIdentAsExprNode)
+			if err != nil {
+				return nil, err
+			}
+			if len(members) == 0 {
+				return nil, fmt.Errorf(`parse: enum %q has no members at %s:%d`,
+					p.tm.ByID(name), p.filename, p.line())
+			}
+			if x := p.peek1(); x != t.IDSemicolon {
+				got := p.tm.ByID(x)
+				return nil, fmt.Errorf(`parse: expected (implicit) ";", got %q at %s:%d`, got, p.filename, p.line())
+			}
+			p.src = p.src[1:]
+			return a.NewEnum(flags, p.filename, line, name, members).AsNode(), nil
+
+		case t.IDInterface:
+			p.src = p.src[1:]
+			if (flags & a.FlagsPublic) == 0 {
+				return nil, fmt.Errorf(`parse: interface must be pub at %s:%d`, p.filename, p.line())
+			}
+			name, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			if !p.opts.AllowDoubleUnderscoreNames && containsDoubleUnderscore(p.tm.ByID(name)) {
+				return nil, fmt.Errorf(`parse: double-underscore %q used for interface name at %s:%d`,
+					p.tm.ByID(name), p.filename, p.line())
+			}
+
+			p.interfaceName = name
+			methods, err := p.parseList(t.IDCloseParen, (*parser).parseInterfaceMethodNode)
+			p.interfaceName = 0
+			if err != nil {
+				return nil, err
+			}
+			if len(methods) == 0 {
+				return nil, fmt.Errorf(`parse: interface %q has no methods at %s:%d`,
+					p.tm.ByID(name), p.filename, p.line())
+			}
+			if x := p.peek1(); x != t.IDSemicolon {
+				got := p.tm.ByID(x)
+				return nil, fmt.Errorf(`parse: expected (implicit) ";", got %q at %s:%d`, got, p.filename, p.line())
+			}
+			p.src = p.src[1:]
+			return a.NewInterface(flags, p.filename, line, name, methods).AsNode(), nil
 		}
 	}
 	return nil, fmt.Errorf(`parse: unrecognized top level declaration at %s:%d`, p.filename, line)
 }
 
+// parseInterfaceMethodNode parses a bodiless method signature like
+// "decode_samples?(dst: base.io_writer, src: base.io_reader)", whose receiver
+// is the interface currently being parsed.
+func (p *parser) parseInterfaceMethodNode() (*a.Node, error) {
+	line := p.line()
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	if !p.opts.AllowBuiltInNames {
+		switch name {
+		case t.IDInitialize, t.IDReset:
+			return nil, fmt.Errorf(`parse: cannot have a method named %q at %s:%d`,
+				name.Str(p.tm), p.filename, p.line())
+		}
+	}
+	if !p.opts.AllowDoubleUnderscoreNames && containsDoubleUnderscore(p.tm.ByID(name)) {
+		return nil, fmt.Errorf(`parse: double-underscore %q used for func name at %s:%d`,
+			p.tm.ByID(name), p.filename, p.line())
+	}
+
+	flags := a.FlagsPublic | p.parseEffect().AsFlags()
+	argFields, err := p.parseList(t.IDCloseParen, (*parser).parseFieldNode)
+	if err != nil {
+		return nil, err
+	}
+	out := (*a.TypeExpr)(nil)
+	if x := p.peek1(); (x != t.IDComma) && (x != t.IDCloseParen) {
+		out, err = p.parseTypeExpr()
+		if err != nil {
+			return nil, err
+		}
+	}
+	in := a.NewStruct(0, p.filename, line, t.IDArgs, nil, argFields)
+	return a.NewFunc(flags, p.filename, line, p.interfaceName, name, in, out, nil, nil, nil).AsNode(), nil
+}
+
 func (p *parser) parseQualifiedIdentAsTypeExprNode() (*a.Node, error) {
 	pkg, name, err := p.parseQualifiedIdent()
 	if err != nil {
@@ -474,6 +643,26 @@ func (p *parser) parseExtraFieldNode() (*a.Node, error) {
 	return n, nil
 }
 
+// parseUnionMemberNode parses a union member: a field whose type is numeric
+// or an array of (arrays of) numbers. Such members are plain data, valid
+// (albeit unspecified) even when the bytes they share were last written via
+// another member.
+func (p *parser) parseUnionMemberNode() (*a.Node, error) {
+	n, err := p.parseFieldNode1(a.FlagsPrivateData)
+	if err != nil {
+		return nil, err
+	}
+	typ := n.AsField().XType()
+	for typ.Decorator() == t.IDArray {
+		typ = typ.Inner()
+	}
+	if (typ.Decorator() != 0) || (typ.QID()[0] != t.IDBase) || !typ.IsNumType() || typ.IsRefined() {
+		return nil, fmt.Errorf(`parse: invalid union member type %q at %s:%d`,
+			n.AsField().XType().Str(p.tm), p.filename, p.line())
+	}
+	return n, nil
+}
+
 func (p *parser) parseFieldNode1(flags a.Flags) (*a.Node, error) {
 	name, err := p.parseIdent()
 	if err != nil {
@@ -666,10 +855,15 @@ func (p *parser) parseBlock(doubleCurly bool) ([]*a.Node, error) {
 	return block, nil
 }
 
-func (p *parser) assertsSorted(asserts []*a.Node, allowChoose bool) error {
-	seenPre, seenInv, seenPost := false, false, false
+func (p *parser) assertsSorted(asserts []*a.Node, allowChoose bool, allowDecreases bool) error {
+	seenPre, seenInv, seenPost, seenDecreases := false, false, false, false
 	for _, o := range asserts {
-		switch o.AsAssert().Keyword() {
+		keyword := o.AsAssert().Keyword()
+		if seenDecreases {
+			return fmt.Errorf(`parse: assertion chain has "%s" after "decreases" at %s:%d`,
+				keyword.Str(p.tm), p.filename, p.line())
+		}
+		switch keyword {
 		case t.IDAssert:
 			return fmt.Errorf(`parse: assertion chain cannot contain "assert", `+
 				`only "pre", "inv" and "post" at %s:%d`, p.filename, p.line())
@@ -693,6 +887,12 @@ func (p *parser) assertsSorted(asserts []*a.Node, allowChoose bool) error {
 			}
 			seenInv = true
 			continue
+		case t.IDDecreases:
+			if !allowDecreases {
+				return fmt.Errorf(`parse: invalid "decreases" at %s:%d`, p.filename, p.line())
+			}
+			seenDecreases = true
+			continue
 		default:
 			seenPost = true
 			continue
@@ -705,7 +905,7 @@ func (p *parser) assertsSorted(asserts []*a.Node, allowChoose bool) error {
 
 func (p *parser) parseAssertNode() (*a.Node, error) {
 	switch x := p.peek1(); x {
-	case t.IDAssert, t.IDChoose, t.IDPre, t.IDInv, t.IDPost:
+	case t.IDAssert, t.IDChoose, t.IDPre, t.IDInv, t.IDPost, t.IDDecreases:
 		p.src = p.src[1:]
 		condition, err := p.parseExpr()
 		if err != nil {
@@ -716,7 +916,7 @@ func (p *parser) parseAssertNode() (*a.Node, error) {
 				condition.Str(p.tm), p.filename, p.line())
 		}
 		reason, args := t.ID(0), []*a.Node(nil)
-		if p.peek1() == t.IDVia {
+		if (p.peek1() == t.IDVia) && (x != t.IDDecreases) {
 			p.src = p.src[1:]
 			reason = p.peek1()
 			if !reason.IsDQStrLiteral(p.tm) {
@@ -739,8 +939,10 @@ func (p *parser) parseStatement() (*a.Node, error) {
 	if len(p.src) > 0 {
 		line = p.src[0].Line
 	}
+	begin := p.pos()
 	n, err := p.parseStatement1()
 	if n != nil {
+		p.span(n, begin)
 		n.AsRaw().SetFilenameLine(p.filename, line)
 		if n.Kind() == a.KIterate {
 			for _, o := range n.AsIterate().Assigns() {
@@ -800,11 +1002,11 @@ func (p *parser) parseStatement1() (*a.Node, error) {
 			return nil, err
 		}
 
-		loop := a.Loop(nil)
+		loop, loopIndex := a.Loop(nil), 0
 		if len(p.loops) == 0 {
 			// No-op.
 		} else if label == 0 {
-			loop = p.loops[len(p.loops)-1]
+			loop, loopIndex = p.loops[len(p.loops)-1], len(p.loops)-1
 			if loop.Label() != 0 {
 				return nil, fmt.Errorf(`parse: unlabeled %s for labeled %s.%s at %s:%d`,
 					x.Str(p.tm), loop.Keyword().Str(p.tm), loop.Label().Str(p.tm), p.filename, p.line())
@@ -812,11 +1014,15 @@ func (p *parser) parseStatement1() (*a.Node, error) {
 		} else {
 			for i := len(p.loops) - 1; i >= 0; i-- {
 				if l := p.loops[i]; label == l.Label() {
-					loop = l
+					loop, loopIndex = l, i
 					break
 				}
 			}
 		}
+		if (loop != nil) && (loopIndex < p.noJumpLoops) {
+			return nil, fmt.Errorf(`parse: %s out of an io_checksum block at %s:%d`,
+				x.Str(p.tm), p.filename, p.line())
+		}
 		if loop == nil {
 			sepStr, labelStr := "", ""
 			if label != 0 {
@@ -860,7 +1066,7 @@ func (p *parser) parseStatement1() (*a.Node, error) {
 		}
 		return a.NewChoose(name, args).AsNode(), nil
 
-	case t.IDIOBind, t.IDIOLimit:
+	case t.IDIOBind, t.IDIOChecksum, t.IDIOLimit:
 		return p.parseIOBindNode()
 
 	case t.IDIf:
@@ -870,6 +1076,9 @@ func (p *parser) parseStatement1() (*a.Node, error) {
 	case t.IDIterate:
 		return p.parseIterateNode()
 
+	case t.IDSwitch:
+		return p.parseSwitchNode()
+
 	case t.IDReturn, t.IDYield:
 		p.src = p.src[1:]
 		if x == t.IDYield {
@@ -910,12 +1119,17 @@ func (p *parser) parseStatement1() (*a.Node, error) {
 			return nil, fmt.Errorf(`parse: while-condition %q is not effect-free at %s:%d`,
 				condition.Str(p.tm), p.filename, p.line())
 		}
-		asserts, err := p.parseAsserts()
+		asserts, err := p.parseAsserts(true)
 		if err != nil {
 			return nil, err
 		}
+		variant := (*a.Expr)(nil)
+		if i := len(asserts) - 1; (i >= 0) && (asserts[i].AsAssert().Keyword() == t.IDDecreases) {
+			variant = asserts[i].AsAssert().Condition()
+			asserts = asserts[:i]
+		}
 
-		n := a.NewWhile(label, condition, asserts)
+		n := a.NewWhile(label, condition, asserts, variant)
 		if !p.loops.Push(n) {
 			return nil, fmt.Errorf(`parse: duplicate loop label %s at %s:%d`,
 				label.Str(p.tm), p.filename, p.line())
@@ -1035,7 +1249,7 @@ func (p *parser) parseIterateAssignNode() (*a.Node, error) {
 	return o.AsNode(), nil
 }
 
-func (p *parser) parseAsserts() ([]*a.Node, error) {
+func (p *parser) parseAsserts(allowDecreases bool) ([]*a.Node, error) {
 	asserts := []*a.Node(nil)
 	if p.peek1() == t.IDComma {
 		p.src = p.src[1:]
@@ -1043,7 +1257,7 @@ func (p *parser) parseAsserts() ([]*a.Node, error) {
 		if asserts, err = p.parseList(t.IDOpenDoubleCurly, (*parser).parseAssertNode); err != nil {
 			return nil, err
 		}
-		if err := p.assertsSorted(asserts, false); err != nil {
+		if err := p.assertsSorted(asserts, false, allowDecreases); err != nil {
 			return nil, err
 		}
 	}
@@ -1090,6 +1304,9 @@ func (p *parser) parseIOBindNode() (*a.Node, error) {
 		}
 	} else {
 		arg1Name = t.IDLimit
+		if keyword == t.IDIOChecksum {
+			arg1Name = t.IDHasher
+		}
 		if (io.Operator() != 0) && (io.IsArgsDotFoo() == 0) {
 			return nil, fmt.Errorf(`parse: invalid %s argument %q at %s:%d`,
 				keyword.Str(p.tm), io.Str(p.tm), p.filename, p.line())
@@ -1129,7 +1346,14 @@ func (p *parser) parseIOBindNode() (*a.Node, error) {
 	}
 	p.src = p.src[1:]
 
+	// An io_checksum body cannot break or continue an enclosing loop, as
+	// that would skip updating the hasher.
+	oldNoJumpLoops := p.noJumpLoops
+	if keyword == t.IDIOChecksum {
+		p.noJumpLoops = len(p.loops)
+	}
 	body, err := p.parseBlock(false)
+	p.noJumpLoops = oldNoJumpLoops
 	if err != nil {
 		return nil, err
 	}
@@ -1173,6 +1397,92 @@ func (p *parser) parseIf() (*a.If, error) {
 	return a.NewIf(condition, bodyIfTrue, bodyIfFalse, elseIf), nil
 }
 
+func (p *parser) parseSwitchNode() (*a.Node, error) {
+	if x := p.peek1(); x != t.IDSwitch {
+		got := p.tm.ByID(x)
+		return nil, fmt.Errorf(`parse: expected "switch", got %q at %s:%d`, got, p.filename, p.line())
+	}
+	p.src = p.src[1:]
+	value, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if value.Effect() != 0 {
+		return nil, fmt.Errorf(`parse: switch-value %q is not effect-free at %s:%d`,
+			value.Str(p.tm), p.filename, p.line())
+	}
+	if x := p.peek1(); x != t.IDOpenCurly {
+		got := p.tm.ByID(x)
+		return nil, fmt.Errorf(`parse: expected "{", got %q at %s:%d`, got, p.filename, p.line())
+	}
+	p.src = p.src[1:]
+
+	cases := []*a.Node(nil)
+	for {
+		if len(p.src) == 0 {
+			return nil, fmt.Errorf(`parse: expected "}" at %s:%d`, p.filename, p.line())
+		} else if p.src[0].ID == t.IDCloseCurly {
+			break
+		}
+
+		line := p.line()
+		keyword := p.peek1()
+		values := []*a.Node(nil)
+		switch keyword {
+		case t.IDCase:
+			p.src = p.src[1:]
+			values, err = p.parseList(t.IDOpenCurly, (*parser).parseCaseValueNode)
+			if err != nil {
+				return nil, err
+			}
+			if len(values) == 0 {
+				return nil, fmt.Errorf(`parse: case has no values at %s:%d`, p.filename, p.line())
+			}
+		case t.IDDefault:
+			p.src = p.src[1:]
+		default:
+			got := p.tm.ByID(keyword)
+			return nil, fmt.Errorf(`parse: expected "case" or "default", got %q at %s:%d`,
+				got, p.filename, p.line())
+		}
+		if (len(cases) > 0) && cases[len(cases)-1].AsCase().IsDefault() {
+			return nil, fmt.Errorf(`parse: switch has an arm after "default" at %s:%d`, p.filename, p.line())
+		}
+
+		body, err := p.parseBlock(false)
+		if err != nil {
+			return nil, err
+		}
+		n := a.NewCase(keyword, values, body)
+		n.AsNode().AsRaw().SetFilenameLine(p.filename, line)
+		cases = append(cases, n.AsNode())
+
+		if x := p.peek1(); x != t.IDSemicolon {
+			got := p.tm.ByID(x)
+			return nil, fmt.Errorf(`parse: expected (implicit) ";", got %q at %s:%d`, got, p.filename, p.line())
+		}
+		p.src = p.src[1:]
+	}
+	p.src = p.src[1:]
+
+	if len(cases) == 0 {
+		return nil, fmt.Errorf(`parse: switch has no arms at %s:%d`, p.filename, p.line())
+	}
+	return a.NewSwitch(value, cases).AsNode(), nil
+}
+
+func (p *parser) parseCaseValueNode() (*a.Node, error) {
+	n, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if n.Effect() != 0 {
+		return nil, fmt.Errorf(`parse: case-value %q is not effect-free at %s:%d`,
+			n.Str(p.tm), p.filename, p.line())
+	}
+	return n.AsNode(), nil
+}
+
 func (p *parser) parseIterateNode() (*a.Node, error) {
 	if x := p.peek1(); x != t.IDIterate {
 		got := p.tm.ByID(x)
@@ -1281,7 +1591,7 @@ func (p *parser) parseIterateBlock(label t.ID, assigns []*a.Node) (*a.Iterate, e
 	}
 	p.src = p.src[1:]
 
-	asserts, err := p.parseAsserts()
+	asserts, err := p.parseAsserts(false)
 	if err != nil {
 		return nil, err
 	}
@@ -1421,6 +1731,7 @@ func (p *parser) parseExpr() (*a.Expr, error) {
 }
 
 func (p *parser) parseExpr1() (*a.Expr, error) {
+	begin := p.pos()
 	lhs, err := p.parseOperand()
 	if err != nil {
 		return nil, err
@@ -1447,7 +1758,9 @@ func (p *parser) parseExpr1() (*a.Expr, error) {
 			if op == 0 {
 				return nil, fmt.Errorf(`parse: internal error: no binary form for token 0x%02X`, x)
 			}
-			return a.NewExpr(0, op, 0, lhs.AsNode(), nil, rhs, nil), nil
+			n := a.NewExpr(0, op, 0, lhs.AsNode(), nil, rhs, nil)
+			p.span(n.AsNode(), begin)
+			return n, nil
 		}
 
 		args := []*a.Node{lhs.AsNode(), rhs}
@@ -1463,12 +1776,15 @@ func (p *parser) parseExpr1() (*a.Expr, error) {
 		if op == 0 {
 			return nil, fmt.Errorf(`parse: internal error: no associative form for token 0x%02X`, x)
 		}
-		return a.NewExpr(0, op, 0, nil, nil, nil, args), nil
+		n := a.NewExpr(0, op, 0, nil, nil, nil, args)
+		p.span(n.AsNode(), begin)
+		return n, nil
 	}
 	return lhs, nil
 }
 
 func (p *parser) parseOperand() (*a.Expr, error) {
+	begin := p.pos()
 	switch x := p.peek1(); {
 	case x.IsUnaryOp():
 		p.src = p.src[1:]
@@ -1480,11 +1796,15 @@ func (p *parser) parseOperand() (*a.Expr, error) {
 		if op == 0 {
 			return nil, fmt.Errorf(`parse: internal error: no unary form for token 0x%02X`, x)
 		}
-		return a.NewExpr(0, op, 0, nil, nil, rhs.AsNode(), nil), nil
+		n := a.NewExpr(0, op, 0, nil, nil, rhs.AsNode(), nil)
+		p.span(n.AsNode(), begin)
+		return n, nil
 
-	case x.IsLiteral(p.tm):
+	case x.IsLiteral(p.tm) && !x.IsDQStrLiteral(p.tm):
 		p.src = p.src[1:]
-		return a.NewExpr(0, 0, x, nil, nil, nil, nil), nil
+		n := a.NewExpr(0, 0, x, nil, nil, nil, nil)
+		p.span(n.AsNode(), begin)
+		return n, nil
 
 	case x == t.IDOpenParen:
 		p.src = p.src[1:]
@@ -1497,16 +1817,25 @@ func (p *parser) parseOperand() (*a.Expr, error) {
 			return nil, fmt.Errorf(`parse: expected ")", got %q at %s:%d`, got, p.filename, p.line())
 		}
 		p.src = p.src[1:]
+		p.span(expr.AsNode(), begin)
 		return expr, nil
 	}
 
-	id, err := p.parseIdent()
-	if err != nil {
+	// A status literal, like an identifier, can be followed by a method call,
+	// e.g. `"#bad chunk".with_payload(payload: etc)`, but not by a
+	// package-qualified status literal selector.
+	lhs, first := (*a.Expr)(nil), true
+	if x := p.peek1(); x.IsDQStrLiteral(p.tm) {
+		p.src = p.src[1:]
+		lhs, first = a.NewExpr(0, 0, x, nil, nil, nil, nil), false
+	} else if id, err := p.parseIdent(); err != nil {
 		return nil, err
+	} else {
+		lhs = a.NewExpr(0, 0, id, nil, nil, nil, nil)
 	}
-	lhs := a.NewExpr(0, 0, id, nil, nil, nil, nil)
 
-	for first := true; ; first = false {
+	for ; ; first = false {
+		p.span(lhs.AsNode(), begin)
 		flags := a.Flags(0)
 		switch p.peek1() {
 		default:
@@ -1535,11 +1864,10 @@ func (p *parser) parseOperand() (*a.Expr, error) {
 			selector := p.peek1()
 			if first && selector.IsDQStrLiteral(p.tm) {
 				p.src = p.src[1:]
+			} else if id, err := p.parseIdent(); err != nil {
+				return nil, err
 			} else {
-				selector, err = p.parseIdent()
-				if err != nil {
-					return nil, err
-				}
+				selector = id
 			}
 			lhs = a.NewExpr(0, a.ExprOperatorSelector, selector, lhs.AsNode(), nil, nil, nil)
 		}
@@ -0,0 +1,97 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parse
+
+import (
+	"strings"
+	"testing"
+
+	t "github.com/google/wuffs/lang/token"
+)
+
+func TestIOChecksumJumps(tt *testing.T) {
+	testCases := []struct {
+		body    string
+		wantErr string
+	}{{
+		// Jumps within the io_checksum block are allowed.
+		body: "io_checksum (io: args.src, hasher: this.h) {\n" +
+			"while true {\n" +
+			"break\n" +
+			"} endwhile\n" +
+			"}\n",
+	}, {
+		// As is an io_checksum block within a loop.
+		body: "while true {\n" +
+			"io_checksum (io: args.src, hasher: this.h) {\n" +
+			"}\n" +
+			"break\n" +
+			"} endwhile\n",
+	}, {
+		body: "while true {\n" +
+			"io_checksum (io: args.src, hasher: this.h) {\n" +
+			"break\n" +
+			"}\n" +
+			"} endwhile\n",
+		wantErr: "break out of an io_checksum block",
+	}, {
+		body: "while true {\n" +
+			"io_checksum (io: args.src, hasher: this.h) {\n" +
+			"continue\n" +
+			"}\n" +
+			"} endwhile\n",
+		wantErr: "continue out of an io_checksum block",
+	}, {
+		// Jumping out of a nested block is still jumping out of the
+		// io_checksum block.
+		body: "while.outer true {\n" +
+			"io_checksum (io: args.src, hasher: this.h) {\n" +
+			"while true {\n" +
+			"break.outer\n" +
+			"} endwhile\n" +
+			"}\n" +
+			"} endwhile.outer\n",
+		wantErr: "break out of an io_checksum block",
+	}, {
+		// The io_checksum restriction ends with its block.
+		body: "while true {\n" +
+			"io_checksum (io: args.src, hasher: this.h) {\n" +
+			"}\n" +
+			"io_limit (io: args.src, limit: 1) {\n" +
+			"continue\n" +
+			"}\n" +
+			"} endwhile\n",
+	}}
+
+	for _, tc := range testCases {
+		const filename = "test.wuffs"
+		src := "pri func foo.bar?(src : base.io_reader) {\n" + tc.body + "}\n"
+		tm := &t.Map{}
+		tokens, _, err := t.Tokenize(tm, filename, []byte(src))
+		if err != nil {
+			tt.Fatalf("Tokenize: %v", err)
+		}
+		_, err = Parse(tm, filename, tokens, nil)
+		if tc.wantErr == "" {
+			if err != nil {
+				tt.Errorf("%q: %v", tc.body, err)
+			}
+		} else if err == nil {
+			tt.Errorf("%q: got nil error, want %q", tc.body, tc.wantErr)
+		} else if !strings.Contains(err.Error(), tc.wantErr) {
+			tt.Errorf("%q: got %v, want %q", tc.body, err, tc.wantErr)
+		}
+	}
+}
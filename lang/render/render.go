@@ -25,8 +25,6 @@ import (
 	t "github.com/google/wuffs/lang/token"
 )
 
-var newLine = []byte{'\n'}
-
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -34,11 +32,61 @@ func max(a, b int) int {
 	return b
 }
 
+// Render writes the formatted form of src (and its comments) to w.
+//
+// As well as normalizing whitespace, it sorts and de-duplicates a leading
+// block of "use" lines, wraps over-long argument lists one argument per line
+// and aligns the trailing comments of consecutive lines.
 func Render(w io.Writer, tm *t.Map, src []t.Token, comments []string) (err error) {
 	if len(src) == 0 {
 		return nil
 	}
 
+	src, comments = sortUses(tm, src, comments)
+
+	lines := []outLine(nil)
+	for i := 0; ; i++ {
+		if lines, err = render(tm, src, comments); err != nil {
+			return err
+		} else if i == maxWrapPasses {
+			break
+		}
+		changed := false
+		if src, comments, changed = wrapLongLines(tm, src, comments, lines); !changed {
+			break
+		}
+	}
+
+	alignComments(lines)
+	buf := make([]byte, 0, 4096)
+	for _, line := range lines {
+		buf = append(buf, line.text...)
+		buf = append(buf, '\n')
+		if len(buf) >= 4096 {
+			if _, err = w.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+// outLine is a rendered line of output, without its trailing '\n'.
+type outLine struct {
+	text []byte
+	// srcLine is the source line that the code in text came from, or 0 for a
+	// line that holds only a comment (or is blank).
+	srcLine uint32
+	// numTabs is the number of leading tabs in text.
+	numTabs int
+	// commentPos is the index in text of a trailing comment's "//", or -1
+	// if there is no trailing comment.
+	commentPos int
+}
+
+func render(tm *t.Map, src []t.Token, comments []string) (lines []outLine, err error) {
 	const maxIndent = 0xFFFF
 	indent := 0
 	buf := make([]byte, 0, 1024)
@@ -47,6 +95,10 @@ func Render(w io.Writer, tm *t.Map, src []t.Token, comments []string) (err error
 	inStruct := false
 	varNameLength := uint32(0)
 
+	// switchCurlies records, for each open "{", whether it opens a switch's
+	// list of arms. Like Go's switch and case, the arms are not indented.
+	switchCurlies := []bool(nil)
+
 	prevLine := src[0].Line - 1
 	prevLineHanging := false
 
@@ -74,14 +126,13 @@ func Render(w io.Writer, tm *t.Map, src []t.Token, comments []string) (err error
 				continue
 			}
 			if commentLine > prevLine+1 {
-				if _, err = w.Write(newLine); err != nil {
-					return err
-				}
-			}
-			buf = append(buf, '\n')
-			if _, err = w.Write(buf); err != nil {
-				return err
+				lines = append(lines, outLine{commentPos: -1})
 			}
+			lines = append(lines, outLine{
+				text:       append([]byte(nil), buf...),
+				numTabs:    commentIndent,
+				commentPos: -1,
+			})
 			varNameLength = 0
 			prevLine = commentLine
 		}
@@ -99,9 +150,7 @@ func Render(w io.Writer, tm *t.Map, src []t.Token, comments []string) (err error
 
 		// Collapse one or more blank lines to just one.
 		if prevLine < line-1 {
-			if _, err = w.Write(newLine); err != nil {
-				return err
-			}
+			lines = append(lines, outLine{commentPos: -1})
 			varNameLength = 0
 		}
 
@@ -112,12 +161,15 @@ func Render(w io.Writer, tm *t.Map, src []t.Token, comments []string) (err error
 		indentAdjustment := 0
 		if id := lineTokens[0].ID; id == t.IDCloseDoubleCurly {
 			// No-op.
+		} else if (id == t.IDCloseCurly) && (len(switchCurlies) > 0) && switchCurlies[len(switchCurlies)-1] {
+			// No-op.
 		} else if id.IsClose() {
 			indentAdjustment--
 		} else if hanging && ((id != t.IDOpenCurly) && (id != t.IDOpenDoubleCurly)) {
 			indentAdjustment++
 		}
 		buf = appendTabs(buf, indent+indentAdjustment)
+		numTabs := max(0, indent+indentAdjustment)
 
 		// Apply or update varNameLength.
 		if len(lineTokens) < 3 {
@@ -126,7 +178,7 @@ func Render(w io.Writer, tm *t.Map, src []t.Token, comments []string) (err error
 			id0 := lineTokens[0].ID
 			id1 := lineTokens[1].ID
 			if (id0 == t.IDPri) || (id0 == t.IDPub) {
-				inStruct = id1 == t.IDStruct
+				inStruct = (id1 == t.IDStruct) || (id1 == t.IDUnion)
 				if id1 != t.IDConst {
 					varNameLength = 0
 				}
@@ -154,6 +206,7 @@ func Render(w io.Writer, tm *t.Map, src []t.Token, comments []string) (err error
 
 		// Render the lineTokens.
 		prevID, prevIsTightRight := t.ID(0), false
+		isSwitch := lineTokens[0].ID == t.IDSwitch
 		for _, tok := range lineTokens {
 			if prevID == t.IDEq || (prevID != 0 && !prevIsTightRight && !tok.ID.IsTightLeft()) {
 				// The "(" token's tight-left-ness is context dependent. For
@@ -161,6 +214,10 @@ func Render(w io.Writer, tm *t.Map, src []t.Token, comments []string) (err error
 				if tok.ID != t.IDOpenParen || !isCloseIdentStrLiteralQuestion(tm, prevID) {
 					buf = append(buf, ' ')
 				}
+			} else if (tok.ID == t.IDOpenBracket) && (prevID == t.IDComma) {
+				// Likewise for "[". For "a[i]", the "[" is tight-left. For a
+				// nested list like "[[0, 1], [2, 3]]", the second "[" is not.
+				buf = append(buf, ' ')
 			}
 
 			if s := tm.ByID(tok.ID); (s == "") || (s[0] < '0') || ('9' < s[0]) {
@@ -170,15 +227,26 @@ func Render(w io.Writer, tm *t.Map, src []t.Token, comments []string) (err error
 			}
 
 			if tok.ID == t.IDOpenCurly {
-				if indent == maxIndent {
-					return errors.New("render: too many \"{\" tokens")
+				switchCurlies = append(switchCurlies, isSwitch)
+				if isSwitch {
+					isSwitch = false
+				} else if indent == maxIndent {
+					return nil, errors.New("render: too many \"{\" tokens")
+				} else {
+					indent++
 				}
-				indent++
 			} else if tok.ID == t.IDCloseCurly {
-				if indent == 0 {
-					return errors.New("render: too many \"}\" tokens")
+				wasSwitch := false
+				if n := len(switchCurlies); n > 0 {
+					wasSwitch, switchCurlies = switchCurlies[n-1], switchCurlies[:n-1]
+				}
+				if wasSwitch {
+					// No-op.
+				} else if indent == 0 {
+					return nil, errors.New("render: too many \"}\" tokens")
+				} else {
+					indent--
 				}
-				indent--
 			}
 
 			prevIsTightRight = tok.ID.IsTightRight()
@@ -193,11 +261,17 @@ func Render(w io.Writer, tm *t.Map, src []t.Token, comments []string) (err error
 			prevID = tok.ID
 		}
 
-		buf = appendComment(buf, comments, line, 0, false)
-		buf = append(buf, '\n')
-		if _, err = w.Write(buf); err != nil {
-			return err
+		commentPos := -1
+		if n := len(buf); hasComment(comments, line) {
+			commentPos = n + 2
 		}
+		buf = appendComment(buf, comments, line, 0, false)
+		lines = append(lines, outLine{
+			text:       append([]byte(nil), buf...),
+			srcLine:    line,
+			numTabs:    numTabs,
+			commentPos: commentPos,
+		})
 		commentLine = line + 1
 		prevLine = line
 		lastID := lineTokens[len(lineTokens)-1].ID
@@ -211,19 +285,22 @@ func Render(w io.Writer, tm *t.Map, src []t.Token, comments []string) (err error
 		buf = appendComment(buf, comments, commentLine, indent, true)
 		if len(buf) > 0 {
 			if commentLine > prevLine+1 {
-				if _, err = w.Write(newLine); err != nil {
-					return err
-				}
-			}
-			buf = append(buf, '\n')
-			if _, err = w.Write(buf); err != nil {
-				return err
+				lines = append(lines, outLine{commentPos: -1})
 			}
+			lines = append(lines, outLine{
+				text:       append([]byte(nil), buf...),
+				numTabs:    indent,
+				commentPos: -1,
+			})
 			prevLine = commentLine
 		}
 	}
 
-	return nil
+	return lines, nil
+}
+
+func hasComment(comments []string, line uint32) bool {
+	return (uint(line) < uint(len(comments))) && (comments[line] != "")
 }
 
 func appendComment(buf []byte, comments []string, line uint32, indent int, otherwiseEmpty bool) []byte {
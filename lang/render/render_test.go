@@ -0,0 +1,130 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	t "github.com/google/wuffs/lang/token"
+)
+
+func format(src string) (string, error) {
+	tm := &t.Map{}
+	tokens, comments, err := t.Tokenize(tm, "test.wuffs", []byte(src))
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := Render(buf, tm, tokens, comments); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func TestRender(tt *testing.T) {
+	testCases := []struct {
+		src  string
+		want string
+	}{{
+		// Sort and de-duplicate "use" lines.
+		src: "use \"std/zlib\"\n" +
+			"use \"std/crc32\"\n" +
+			"use \"std/zlib\"\n" +
+			"\n" +
+			"pri const A : base.u32 = 1\n",
+		want: "use \"std/crc32\"\n" +
+			"use \"std/zlib\"\n" +
+			"\n" +
+			"pri const A : base.u32 = 1\n",
+	}, {
+		// A commented "use" line ends the block.
+		src: "use \"std/zlib\"  // Z.\n" +
+			"use \"std/crc32\"\n",
+		want: "use \"std/zlib\"  // Z.\n" +
+			"use \"std/crc32\"\n",
+	}, {
+		// Align trailing comments, but only within a block of lines that
+		// have the same indentation.
+		src: "pri func foo.bar!() {\n" +
+			"\tthis.a = 1  // One.\n" +
+			"\tthis.bb = 22  // Two.\n" +
+			"\tthis.ccc = 333  // Three.\n" +
+			"\n" +
+			"\tthis.d = 4  // Four.\n" +
+			"}\n",
+		want: "pri func foo.bar!() {\n" +
+			"\tthis.a = 1      // One.\n" +
+			"\tthis.bb = 22    // Two.\n" +
+			"\tthis.ccc = 333  // Three.\n" +
+			"\n" +
+			"\tthis.d = 4  // Four.\n" +
+			"}\n",
+	}, {
+		// Wrap a long argument list, one argument per line.
+		src: "pri func foo.bar!() {\n" +
+			"\tthis.a = this.some_long_method_name!(first_argument: 0x1234, " +
+			"second_argument: this.b + this.c, third_argument: 0x5678_9ABC)\n" +
+			"\tthis.d = this.short!(a: 1, b: 2)\n" +
+			"}\n",
+		want: "pri func foo.bar!() {\n" +
+			"\tthis.a = this.some_long_method_name!(\n" +
+			"\t\tfirst_argument: 0x1234,\n" +
+			"\t\tsecond_argument: this.b + this.c,\n" +
+			"\t\tthird_argument: 0x5678_9ABC)\n" +
+			"\tthis.d = this.short!(a: 1, b: 2)\n" +
+			"}\n",
+	}, {
+		// Like Go, don't indent a switch's arms.
+		src: "pri func foo.bar!() {\n" +
+			"switch this.a {\n" +
+			"case 0 {\n" +
+			"this.b = 1\n" +
+			"}\n" +
+			"default {\n" +
+			"}\n" +
+			"}\n" +
+			"}\n",
+		want: "pri func foo.bar!() {\n" +
+			"\tswitch this.a {\n" +
+			"\tcase 0 {\n" +
+			"\t\tthis.b = 1\n" +
+			"\t}\n" +
+			"\tdefault {\n" +
+			"\t}\n" +
+			"\t}\n" +
+			"}\n",
+	}}
+
+	for i, tc := range testCases {
+		have, err := format(tc.src)
+		if err != nil {
+			tt.Errorf("test case #%d: format: %v", i, err)
+			continue
+		}
+		if have != tc.want {
+			tt.Errorf("test case #%d:\nhave:\n%s\nwant:\n%s", i, have, tc.want)
+			continue
+		}
+
+		// Formatting should be idempotent.
+		again, err := format(have)
+		if err != nil {
+			tt.Errorf("test case #%d: format again: %v", i, err)
+		} else if again != have {
+			tt.Errorf("test case #%d: not idempotent:\nhave:\n%s\nwant:\n%s", i, again, have)
+		}
+	}
+}
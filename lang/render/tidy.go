@@ -0,0 +1,271 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+// This file holds the formatting passes that go beyond normalizing
+// whitespace: sorting "use" lines, wrapping long argument lists and aligning
+// trailing comments. The first two re-arrange the tokens' line numbers
+// (before rendering), the last one re-arranges the rendered output.
+
+import (
+	"sort"
+
+	t "github.com/google/wuffs/lang/token"
+)
+
+const (
+	// maxLineWidth is the width (in columns, with tabWidth columns per tab)
+	// above which a line's argument list is wrapped, one argument per line.
+	maxLineWidth = 120
+	tabWidth     = 4
+
+	// maxWrapPasses bounds how many times an argument (itself a call with
+	// its own argument list) can be re-wrapped.
+	maxWrapPasses = 4
+)
+
+// copyTokensAndComments returns copies of src and comments, so that the
+// passes below do not modify their callers' slices.
+func copyTokensAndComments(src []t.Token, comments []string) ([]t.Token, []string) {
+	return append([]t.Token(nil), src...), append([]string(nil), comments...)
+}
+
+// insertLines adds delta (which may be negative) to the line number of every
+// token in src whose line is after line. It also adds or removes the
+// corresponding (necessarily empty) comments entries.
+func insertLines(src []t.Token, comments []string, line uint32, delta int) []string {
+	for i := range src {
+		if src[i].Line > line {
+			src[i].Line = uint32(int(src[i].Line) + delta)
+		}
+	}
+	if uint(line+1) >= uint(len(comments)) {
+		return comments
+	}
+	if delta > 0 {
+		tail := append(make([]string, delta), comments[line+1:]...)
+		return append(comments[:line+1], tail...)
+	}
+	return append(comments[:line+1], comments[int(line+1)-delta:]...)
+}
+
+// sortUses sorts and de-duplicates the first block of consecutive "use"
+// lines, such as:
+//
+//	use "std/zlib"
+//	use "std/crc32"
+//
+// Any comment on one of those lines (or a blank line between them) ends the
+// block, as does any line that is not exactly one "use" statement.
+func sortUses(tm *t.Map, src []t.Token, comments []string) ([]t.Token, []string) {
+	i := 0
+	for ; (i < len(src)) && (src[i].ID != t.IDUse); i++ {
+	}
+	firstLine := uint32(0)
+	paths := []string(nil)
+	j := i
+	for ; (j+2 < len(src)) && (src[j].ID == t.IDUse) && (src[j+2].ID == t.IDSemicolon); j += 3 {
+		line := src[j].Line
+		if (src[j+1].Line != line) || (src[j+2].Line != line) || hasComment(comments, line) ||
+			((firstLine != 0) && (line != firstLine+uint32(len(paths)))) ||
+			((j+3 < len(src)) && (src[j+3].Line == line)) {
+			break
+		} else if firstLine == 0 {
+			firstLine = line
+		}
+		paths = append(paths, tm.ByID(src[j+1].ID))
+	}
+	if len(paths) < 2 {
+		return src, comments
+	}
+
+	sorted := append([]string(nil), paths...)
+	sort.Strings(sorted)
+	n := 0
+	for _, p := range sorted {
+		if (n == 0) || (sorted[n-1] != p) {
+			sorted[n] = p
+			n++
+		}
+	}
+	sorted = sorted[:n]
+	if (len(sorted) == len(paths)) && sort.StringsAreSorted(paths) {
+		return src, comments
+	}
+
+	src, comments = copyTokensAndComments(src, comments)
+	uses := make([]t.Token, 0, 3*len(sorted))
+	for k, p := range sorted {
+		line := firstLine + uint32(k)
+		uses = append(uses,
+			t.Token{ID: t.IDUse, Line: line},
+			t.Token{ID: tm.ByName(p), Line: line},
+			t.Token{ID: t.IDSemicolon, Line: line},
+		)
+	}
+	src = append(src[:i], append(uses, src[i+3*len(paths):]...)...)
+	lastLine := firstLine + uint32(len(sorted)) - 1
+	comments = insertLines(src[i+len(uses):], comments, lastLine, len(sorted)-len(paths))
+	return src, comments
+}
+
+// wrapLongLines finds the source lines whose rendered form (in lines) is
+// wider than maxLineWidth and moves the arguments of each such line's first
+// multi-argument call onto their own lines:
+//
+//	x = this.foo!(a: 1, b: 2, c: 3)
+//
+// becomes:
+//
+//	x = this.foo!(
+//		a: 1,
+//		b: 2,
+//		c: 3)
+//
+// Lines with a trailing comment or that end with a "{" are left alone.
+func wrapLongLines(tm *t.Map, src []t.Token, comments []string, lines []outLine) ([]t.Token, []string, bool) {
+	tooLong := map[uint32]bool{}
+	for _, line := range lines {
+		if (line.srcLine == 0) || (line.commentPos >= 0) {
+			continue
+		}
+		if width := (tabWidth * line.numTabs) + len(line.text) - line.numTabs; width > maxLineWidth {
+			tooLong[line.srcLine] = true
+		}
+	}
+	if len(tooLong) == 0 {
+		return src, comments, false
+	}
+
+	// shift is how many lines have been inserted so far, as tooLong's keys
+	// are the line numbers before any insertions.
+	copied, shift := false, uint32(0)
+	for i := 0; i < len(src); {
+		line := src[i].Line
+		j := i + 1
+		for ; (j < len(src)) && (src[j].Line == line); j++ {
+		}
+		if !tooLong[line-shift] {
+			i = j
+			continue
+		}
+
+		breaks := findArgBreaks(tm, src[i:j])
+		if len(breaks) == 0 {
+			i = j
+			continue
+		}
+		if !copied {
+			copied = true
+			src, comments = copyTokensAndComments(src, comments)
+		}
+		comments = insertLines(src, comments, line, len(breaks))
+		shift += uint32(len(breaks))
+		for k, b := range breaks {
+			end := j
+			if k+1 < len(breaks) {
+				end = i + breaks[k+1]
+			}
+			for x := i + b; x < end; x++ {
+				src[x].Line = line + uint32(k+1)
+			}
+		}
+		i = j
+	}
+	return src, comments, copied
+}
+
+// findArgBreaks returns the indexes (into lineTokens) of the tokens that
+// should start a new line: the start of each argument of the first call
+// (whose "(" and matching ")" are both in lineTokens) that has two or more
+// arguments. It returns nil if there is no such call.
+func findArgBreaks(tm *t.Map, lineTokens []t.Token) []int {
+	n := len(lineTokens)
+	for n > 0 && lineTokens[n-1].ID == t.IDSemicolon {
+		n--
+	}
+	if (n == 0) || (lineTokens[n-1].ID == t.IDOpenCurly) || (lineTokens[n-1].ID == t.IDOpenDoubleCurly) {
+		return nil
+	}
+
+	for k := 1; k < n; k++ {
+		if lineTokens[k].ID != t.IDOpenParen {
+			continue
+		}
+		if prev := lineTokens[k-1].ID; !prev.IsIdent(tm) && (prev != t.IDExclam) && (prev != t.IDQuestion) {
+			continue
+		}
+
+		breaks := []int{k + 1}
+		depth := 0
+		for x := k + 1; x < n; x++ {
+			id := lineTokens[x].ID
+			if id.IsOpen() {
+				depth++
+			} else if !id.IsClose() {
+				if (id == t.IDComma) && (depth == 0) && (x+1 < n) {
+					breaks = append(breaks, x+1)
+				}
+			} else if depth > 0 {
+				depth--
+			} else {
+				if len(breaks) >= 2 {
+					return breaks
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// alignComments aligns the trailing comments of consecutive code lines that
+// have the same indentation, so that the "//"s start in the same column.
+func alignComments(lines []outLine) {
+	for i := 0; i < len(lines); {
+		j := i + 1
+		if isCommentedCode(lines[i]) {
+			for ; (j < len(lines)) && isCommentedCode(lines[j]) && (lines[j].numTabs == lines[i].numTabs); j++ {
+			}
+		}
+		if j-i < 2 {
+			i = j
+			continue
+		}
+
+		column := 0
+		for _, line := range lines[i:j] {
+			column = max(column, line.commentPos)
+		}
+		for k := i; k < j; k++ {
+			line := &lines[k]
+			if pad := column - line.commentPos; pad > 0 {
+				text := make([]byte, 0, len(line.text)+pad)
+				text = append(text, line.text[:line.commentPos]...)
+				for ; pad > 0; pad-- {
+					text = append(text, ' ')
+				}
+				text = append(text, line.text[line.commentPos:]...)
+				line.text = text
+				line.commentPos = column
+			}
+		}
+		i = j
+	}
+}
+
+func isCommentedCode(line outLine) bool {
+	return (line.srcLine != 0) && (line.commentPos >= 0)
+}
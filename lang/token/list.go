@@ -120,6 +120,7 @@ func (x ID) IsBuiltInCPUArchARMNeon() bool {
 }
 func (x ID) IsCannotAssignTo() bool { return minCannotAssignTo <= x && x <= maxCannotAssignTo }
 func (x ID) IsClose() bool          { return minClose <= x && x <= maxClose }
+func (x ID) IsFloatType() bool      { return minFloatType <= x && x <= maxFloatType }
 func (x ID) IsKeyword() bool        { return minKeyword <= x && x <= maxKeyword }
 func (x ID) IsNumType() bool        { return minNumType <= x && x <= maxNumType }
 func (x ID) IsNumTypeOrIdeal() bool { return minNumTypeOrIdeal <= x && x <= maxNumTypeOrIdeal }
@@ -139,6 +140,8 @@ func (x ID) IsEtcUtility() bool {
 		switch x {
 		case IDARMCRC32Utility,
 			IDARMNeonUtility,
+			IDRISCVRVVUtility,
+			IDWASMSIMD128Utility,
 			IDX86SSE42Utility,
 			IDX86AVX2Utility:
 			return true
@@ -207,8 +210,8 @@ type Token struct {
 //  -  0x20 ..=  0x3F are squiggly assignments, such as "=" and "+=".
 //  -  0x40 ..=  0x6F are operators, such as "+", "==" and "not".
 //  -  0x70 ..=  0xAF are x-ops (disambiguation forms): unary vs binary "+".
-//  -  0xB0 ..=  0xCF are keywords, such as "if" and "return".
-//  -  0xD0 ..=  0xDF are type modifiers, such as "ptr" and "slice".
+//  -  0xB0 ..=  0xD7 are keywords, such as "if" and "return".
+//  -  0xD8 ..=  0xDF are type modifiers, such as "ptr" and "slice".
 //  -  0xE0 ..=  0xFF are literals, such as "ok" and "true".
 //  - 0x100 ..= 0x3FF are identifiers, such as "bool", "u32" and "read_u8".
 //
@@ -364,7 +367,7 @@ const (
 
 const (
 	minKeyword = 0xB0
-	maxKeyword = 0xCF
+	maxKeyword = 0xD7
 
 	IDAssert     = ID(0xB0)
 	IDBreak      = ID(0xB1)
@@ -376,33 +379,41 @@ const (
 	IDEndwhile   = ID(0xB7)
 	IDFunc       = ID(0xB8)
 	IDIOBind     = ID(0xB9)
-	IDIOLimit    = ID(0xBA)
-	IDIf         = ID(0xBB)
-	IDImplements = ID(0xBC)
-	IDInv        = ID(0xBD)
-	IDIterate    = ID(0xBE)
-	IDPost       = ID(0xBF)
-	IDPre        = ID(0xC0)
-	IDPri        = ID(0xC1)
-	IDPub        = ID(0xC2)
-	IDReturn     = ID(0xC3)
-	IDStruct     = ID(0xC4)
-	IDUse        = ID(0xC5)
-	IDVar        = ID(0xC6)
-	IDVia        = ID(0xC7)
-	IDWhile      = ID(0xC8)
-	IDYield      = ID(0xC9)
+	IDIOChecksum = ID(0xBA)
+	IDIOLimit    = ID(0xBB)
+	IDIf         = ID(0xBC)
+	IDImplements = ID(0xBD)
+	IDInv        = ID(0xBE)
+	IDIterate    = ID(0xBF)
+	IDPost       = ID(0xC0)
+	IDPre        = ID(0xC1)
+	IDPri        = ID(0xC2)
+	IDPub        = ID(0xC3)
+	IDReturn     = ID(0xC4)
+	IDStruct     = ID(0xC5)
+	IDUse        = ID(0xC6)
+	IDVar        = ID(0xC7)
+	IDVia        = ID(0xC8)
+	IDWhile      = ID(0xC9)
+	IDYield      = ID(0xCA)
+	IDInterface  = ID(0xCB)
+	IDDecreases  = ID(0xCC)
+	IDUnion      = ID(0xCD)
+	IDEnum       = ID(0xCE)
+	IDSwitch     = ID(0xCF)
+	IDCase       = ID(0xD0)
+	IDDefault    = ID(0xD1)
 )
 
 const (
-	minTypeModifier = 0xD0
+	minTypeModifier = 0xD8
 	maxTypeModifier = 0xDF
 
-	IDArray = ID(0xD0)
-	IDNptr  = ID(0xD1)
-	IDPtr   = ID(0xD2)
-	IDSlice = ID(0xD3)
-	IDTable = ID(0xD4)
+	IDArray = ID(0xD8)
+	IDNptr  = ID(0xD9)
+	IDPtr   = ID(0xDA)
+	IDSlice = ID(0xDB)
+	IDTable = ID(0xDC)
 )
 
 const (
@@ -428,6 +439,8 @@ const (
 	minNumType        = 0x110
 	maxNumType        = 0x117
 	maxNumTypeOrIdeal = 0x117
+	minFloatType      = 0x118
+	maxFloatType      = 0x119
 	maxBuiltInIdent   = 0x3FF
 
 	// -------- 0x100 block.
@@ -441,6 +454,7 @@ const (
 	IDDagger1 = ID(0x106)
 	IDDagger2 = ID(0x107)
 
+	IDQEnum        = ID(0x109)
 	IDQNonNullptr  = ID(0x10A)
 	IDQNullptr     = ID(0x10B)
 	IDQPackage     = ID(0x10C)
@@ -460,6 +474,12 @@ const (
 	IDU32 = ID(0x116)
 	IDU64 = ID(0x117)
 
+	// The IEEE 754 floating point types are deliberately outside of the
+	// IDI8..IDU64 block. They are not NumType's: they have no bounds and
+	// cannot take part in bounds-checking proofs.
+	IDF32 = ID(0x118)
+	IDF64 = ID(0x119)
+
 	IDBase            = ID(0x120)
 	IDBool            = ID(0x121)
 	IDEmptyIOReader   = ID(0x122)
@@ -499,10 +519,11 @@ const (
 	IDMatch31       = ID(0x166)
 	IDMatch7        = ID(0x167)
 	IDPosition      = ID(0x168)
-	IDSince         = ID(0x169)
-	IDSkip          = ID(0x16A)
-	IDSkipU32       = ID(0x16B)
-	IDSkipU32Fast   = ID(0x16C)
+	IDSeek          = ID(0x169)
+	IDSince         = ID(0x16A)
+	IDSkip          = ID(0x16B)
+	IDSkipU32       = ID(0x16C)
+	IDSkipU32Fast   = ID(0x16D)
 
 	IDCopyFromSlice                                     = ID(0x170)
 	IDLimitedCopyU32FromHistory                         = ID(0x171)
@@ -512,6 +533,10 @@ const (
 	IDLimitedCopyU32FromReader                          = ID(0x175)
 	IDLimitedCopyU32FromSlice                           = ID(0x176)
 	IDLimitedCopyU32ToSlice                             = ID(0x177)
+	IDLimitedCopyU64FromHistory                         = ID(0x178)
+	IDLimitedCopyU64FromReader                          = ID(0x179)
+	IDLimitedCopyU64FromSlice                           = ID(0x17A)
+	IDLimitedCopyU64ToSlice                             = ID(0x17B)
 
 	// -------- 0x180 block.
 
@@ -521,6 +546,9 @@ const (
 	IDReadU16BE = ID(0x182)
 	IDReadU16LE = ID(0x183)
 
+	IDReadUvarint64 = ID(0x184)
+	IDReadSvarint64 = ID(0x185)
+
 	IDReadU8AsU32    = ID(0x189)
 	IDReadU16BEAsU32 = ID(0x18A)
 	IDReadU16LEAsU32 = ID(0x18B)
@@ -638,6 +666,12 @@ const (
 
 	IDWriteSimpleTokenFast   = ID(0x1F1)
 	IDWriteExtendedTokenFast = ID(0x1F2)
+	IDWriteU64TokenPairFast  = ID(0x1F3)
+
+	// --------
+
+	IDWriteUvarint64 = ID(0x1F8)
+	IDWriteSvarint64 = ID(0x1F9)
 
 	// -------- 0x200 block.
 
@@ -650,29 +684,42 @@ const (
 	IDSet            = ID(0x206)
 	IDUnroll         = ID(0x207)
 	IDUpdate         = ID(0x208)
+	IDUpdateU32      = ID(0x209)
 
 	// TODO: range/rect methods like intersection and contains?
 
-	IDHighBits = ID(0x220)
-	IDLowBits  = ID(0x221)
-	IDMax      = ID(0x222)
-	IDMin      = ID(0x223)
+	IDHighBits      = ID(0x220)
+	IDLeadingZeros  = ID(0x221)
+	IDLowBits       = ID(0x222)
+	IDMax           = ID(0x223)
+	IDMin           = ID(0x224)
+	IDPopcount      = ID(0x225)
+	IDTrailingZeros = ID(0x226)
 
 	IDIsError      = ID(0x230)
 	IDIsOK         = ID(0x231)
 	IDIsSuspension = ID(0x232)
 
 	IDData             = ID(0x240)
-	IDHeight           = ID(0x241)
-	IDIO               = ID(0x242)
-	IDLimit            = ID(0x243)
-	IDPrefix           = ID(0x244)
-	IDRow              = ID(0x245)
-	IDStride           = ID(0x246)
-	IDSuffix           = ID(0x247)
-	IDUintptrLow12Bits = ID(0x248)
-	IDValidUTF8Length  = ID(0x249)
-	IDWidth            = ID(0x24A)
+	IDEqualFold        = ID(0x241)
+	IDHasher           = ID(0x242)
+	IDHeight           = ID(0x243)
+	IDIO               = ID(0x244)
+	IDIndexOf          = ID(0x245)
+	IDIndexOfFold      = ID(0x246)
+	IDLimit            = ID(0x247)
+	IDPrefix           = ID(0x248)
+	IDRow              = ID(0x249)
+	IDStride           = ID(0x24A)
+	IDSuffix           = ID(0x24B)
+	IDUintptrLow12Bits = ID(0x24C)
+	IDValidUTF8Length  = ID(0x24D)
+	IDWidth            = ID(0x24E)
+
+	IDCopyWithin  = ID(0x250)
+	IDFill        = ID(0x251)
+	IDPayload     = ID(0x252)
+	IDWithPayload = ID(0x253)
 
 	IDLimitedSwizzleU32InterleavedFromReader = ID(0x280)
 	IDSwizzleInterleavedFromReader           = ID(0x281)
@@ -682,7 +729,7 @@ const (
 	minBuiltInCPUArch        = 0x300
 	minBuiltInCPUArchARMNeon = 0x30E
 	maxBuiltInCPUArchARMNeon = 0x38F
-	maxBuiltInCPUArch        = 0x3AF
+	maxBuiltInCPUArch        = 0x3CF
 
 	// If adding more CPUArch utility types, also update IsEtcUtility.
 
@@ -691,6 +738,8 @@ const (
 
 	IDARMCRC32U32 = ID(0x302)
 
+	IDARMSHA2 = ID(0x308)
+
 	IDARMNeon        = ID(0x30E)
 	IDARMNeonUtility = ID(0x30F)
 
@@ -711,8 +760,25 @@ const (
 	IDX86AVX2         = ID(0x392)
 	IDX86AVX2Utility  = ID(0x393)
 	IDX86BMI2         = ID(0x394)
+	IDX86SHA          = ID(0x395)
 
 	IDX86M128I = ID(0x3A0)
+
+	IDRISCVRVV        = ID(0x3B0)
+	IDRISCVRVVUtility = ID(0x3B1)
+
+	// RISC-V Vector types are sizeless: their length (the hardware's VLEN)
+	// is only known at run time. The "m1", "m2", etc. suffix is the LMUL
+	// register grouping.
+	IDRISCVRVVU8M1  = ID(0x3B2)
+	IDRISCVRVVU16M2 = ID(0x3B3)
+	IDRISCVRVVU32M1 = ID(0x3B4)
+	IDRISCVRVVU32M4 = ID(0x3B5)
+
+	IDWASMSIMD128        = ID(0x3C0)
+	IDWASMSIMD128Utility = ID(0x3C1)
+
+	IDWASMV128 = ID(0x3C2)
 )
 
 var builtInsByID = [nBuiltInIDs]string{
@@ -799,6 +865,7 @@ var builtInsByID = [nBuiltInIDs]string{
 	IDEndwhile:   "endwhile",
 	IDFunc:       "func",
 	IDIOBind:     "io_bind",
+	IDIOChecksum: "io_checksum",
 	IDIOLimit:    "io_limit",
 	IDIf:         "if",
 	IDImplements: "implements",
@@ -815,6 +882,13 @@ var builtInsByID = [nBuiltInIDs]string{
 	IDVia:        "via",
 	IDWhile:      "while",
 	IDYield:      "yield",
+	IDInterface:  "interface",
+	IDDecreases:  "decreases",
+	IDUnion:      "union",
+	IDEnum:       "enum",
+	IDSwitch:     "switch",
+	IDCase:       "case",
+	IDDefault:    "default",
 
 	IDArray: "array",
 	IDNptr:  "nptr",
@@ -848,6 +922,10 @@ var builtInsByID = [nBuiltInIDs]string{
 	IDDagger1: "†", // U+2020 DAGGER
 	IDDagger2: "‡", // U+2021 DOUBLE DAGGER
 
+	// IDQEnum is used by the type checker to build an artificial MType for
+	// enum names, such as the "foo" in "foo.BAR".
+	IDQEnum: "«Enum»",
+
 	// IDQNonNullptr is used by the type checker to build an artificial MType
 	// for function pointers.
 	IDQNonNullptr: "«NonNullptr»",
@@ -886,6 +964,9 @@ var builtInsByID = [nBuiltInIDs]string{
 	IDU32: "u32",
 	IDU64: "u64",
 
+	IDF32: "f32",
+	IDF64: "f64",
+
 	IDBase:            "base",
 	IDBool:            "bool",
 	IDEmptyIOReader:   "empty_io_reader",
@@ -925,6 +1006,7 @@ var builtInsByID = [nBuiltInIDs]string{
 	IDMatch31:       "match31",
 	IDMatch7:        "match7",
 	IDPosition:      "position",
+	IDSeek:          "seek",
 	IDSince:         "since",
 	IDSkip:          "skip",
 	IDSkipU32:       "skip_u32",
@@ -938,6 +1020,10 @@ var builtInsByID = [nBuiltInIDs]string{
 	IDLimitedCopyU32FromReader:                          "limited_copy_u32_from_reader",
 	IDLimitedCopyU32FromSlice:                           "limited_copy_u32_from_slice",
 	IDLimitedCopyU32ToSlice:                             "limited_copy_u32_to_slice",
+	IDLimitedCopyU64FromHistory:                         "limited_copy_u64_from_history",
+	IDLimitedCopyU64FromReader:                          "limited_copy_u64_from_reader",
+	IDLimitedCopyU64FromSlice:                           "limited_copy_u64_from_slice",
+	IDLimitedCopyU64ToSlice:                             "limited_copy_u64_to_slice",
 
 	// -------- 0x180 block.
 
@@ -947,6 +1033,9 @@ var builtInsByID = [nBuiltInIDs]string{
 	IDReadU16BE: "read_u16be",
 	IDReadU16LE: "read_u16le",
 
+	IDReadUvarint64: "read_uvarint64",
+	IDReadSvarint64: "read_svarint64",
+
 	IDReadU8AsU32:    "read_u8_as_u32",
 	IDReadU16BEAsU32: "read_u16be_as_u32",
 	IDReadU16LEAsU32: "read_u16le_as_u32",
@@ -1062,6 +1151,12 @@ var builtInsByID = [nBuiltInIDs]string{
 
 	IDWriteSimpleTokenFast:   "write_simple_token_fast",
 	IDWriteExtendedTokenFast: "write_extended_token_fast",
+	IDWriteU64TokenPairFast:  "write_u64_token_pair_fast",
+
+	// --------
+
+	IDWriteUvarint64: "write_uvarint64",
+	IDWriteSvarint64: "write_svarint64",
 
 	// -------- 0x200 block.
 
@@ -1074,19 +1169,27 @@ var builtInsByID = [nBuiltInIDs]string{
 	IDSet:            "set",
 	IDUnroll:         "unroll",
 	IDUpdate:         "update",
+	IDUpdateU32:      "update_u32",
 
-	IDHighBits: "high_bits",
-	IDLowBits:  "low_bits",
-	IDMax:      "max",
-	IDMin:      "min",
+	IDHighBits:      "high_bits",
+	IDLeadingZeros:  "leading_zeros",
+	IDLowBits:       "low_bits",
+	IDMax:           "max",
+	IDMin:           "min",
+	IDPopcount:      "popcount",
+	IDTrailingZeros: "trailing_zeros",
 
 	IDIsError:      "is_error",
 	IDIsOK:         "is_ok",
 	IDIsSuspension: "is_suspension",
 
 	IDData:             "data",
+	IDEqualFold:        "equal_fold",
+	IDHasher:           "hasher",
 	IDHeight:           "height",
 	IDIO:               "io",
+	IDIndexOf:          "index_of",
+	IDIndexOfFold:      "index_of_fold",
 	IDLimit:            "limit",
 	IDPrefix:           "prefix",
 	IDRow:              "row",
@@ -1096,6 +1199,11 @@ var builtInsByID = [nBuiltInIDs]string{
 	IDValidUTF8Length:  "valid_utf_8_length",
 	IDWidth:            "width",
 
+	IDCopyWithin:  "copy_within",
+	IDFill:        "fill",
+	IDPayload:     "payload",
+	IDWithPayload: "with_payload",
+
 	IDLimitedSwizzleU32InterleavedFromReader: "limited_swizzle_u32_interleaved_from_reader",
 	IDSwizzleInterleavedFromReader:           "swizzle_interleaved_from_reader",
 
@@ -1106,6 +1214,8 @@ var builtInsByID = [nBuiltInIDs]string{
 
 	IDARMCRC32U32: "arm_crc32_u32",
 
+	IDARMSHA2: "arm_sha2",
+
 	IDARMNeon:        "arm_neon",
 	IDARMNeonUtility: "arm_neon_utility",
 
@@ -1124,8 +1234,22 @@ var builtInsByID = [nBuiltInIDs]string{
 	IDX86AVX2:         "x86_avx2",
 	IDX86AVX2Utility:  "x86_avx2_utility",
 	IDX86BMI2:         "x86_bmi2",
+	IDX86SHA:          "x86_sha",
 
 	IDX86M128I: "x86_m128i",
+
+	IDRISCVRVV:        "riscv_rvv",
+	IDRISCVRVVUtility: "riscv_rvv_utility",
+
+	IDRISCVRVVU8M1:  "riscv_rvv_u8m1",
+	IDRISCVRVVU16M2: "riscv_rvv_u16m2",
+	IDRISCVRVVU32M1: "riscv_rvv_u32m1",
+	IDRISCVRVVU32M4: "riscv_rvv_u32m4",
+
+	IDWASMSIMD128:        "wasm_simd128",
+	IDWASMSIMD128Utility: "wasm_simd128_utility",
+
+	IDWASMV128: "wasm_v128",
 }
 
 var builtInsByName = map[string]ID{}
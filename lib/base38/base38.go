@@ -50,6 +50,22 @@ func Encode(s string) (u uint32, ok bool) {
 	return 0, false
 }
 
+// Decode decodes a uint32 in the range [0, Max] as a 4-byte string. It is the
+// inverse of Encode.
+func Decode(u uint32) (s string, ok bool) {
+	if u > Max {
+		return "", false
+	}
+	b := [4]byte{}
+	for i := 3; i >= 0; i-- {
+		b[i] = alphabet[u%38]
+		u /= 38
+	}
+	return string(b[:]), true
+}
+
+const alphabet = " 0123456789?abcdefghijklmnopqrstuvwxyz"
+
 var table = [256]uint8{
 	' ': 1,
 	'0': 2,
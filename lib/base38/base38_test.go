@@ -75,6 +75,10 @@ func TestValid(tt *testing.T) {
 			tt.Errorf("%q: got %d, want <= %d and <= %d", tc.s, got, Max, 1<<MaxBits-1)
 			continue
 		}
+		if s, ok := Decode(got); !ok || (s != tc.s) {
+			tt.Errorf("%q: Decode: got %q, %t, want %q, %t", tc.s, s, ok, tc.s, true)
+			continue
+		}
 		maxSeen = maxSeen || (got == Max)
 	}
 	if !maxSeen {
@@ -104,3 +108,9 @@ func TestInvalid(tt *testing.T) {
 		}
 	}
 }
+
+func TestDecodeInvalid(tt *testing.T) {
+	if s, ok := Decode(Max + 1); ok {
+		tt.Fatalf("Decode(Max + 1): got %q, %t, want %q, %t", s, ok, "", false)
+	}
+}
@@ -0,0 +1,448 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+// Package cgobzip2 wraps the C "bzip2" library.
+//
+// Unlike some other compression libraries, bzip2 does not support shared
+// dictionaries. Passing a non-empty dictionary to a Reset method is an error.
+package cgobzip2
+
+/*
+#cgo LDFLAGS: -lbz2
+#include "bzlib.h"
+
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef struct {
+	uint32_t ndst;
+	uint32_t nsrc;
+	uint32_t eof;
+} advances;
+
+bz_stream* cgobzip2_compress_start(int block_size_100k) {
+	bz_stream* z = (bz_stream*)(calloc(1, sizeof(bz_stream)));
+	if (!z) {
+		return NULL;
+	}
+	if (BZ2_bzCompressInit(z, block_size_100k, 0, 0) != BZ_OK) {
+		free(z);
+		return NULL;
+	}
+	return z;
+}
+
+void cgobzip2_compress_free(bz_stream* z) {
+	BZ2_bzCompressEnd(z);
+	free(z);
+}
+
+int32_t cgobzip2_compress(bz_stream* z,
+		advances* a,
+		uint8_t* dst_ptr,
+		uint32_t dst_len,
+		uint8_t* src_ptr,
+		uint32_t src_len,
+		int final) {
+	z->next_out = (char*)(dst_ptr);
+	z->avail_out = dst_len;
+	z->next_in = (char*)(src_ptr);
+	z->avail_in = src_len;
+
+	int result = BZ2_bzCompress(z, final ? BZ_FINISH : BZ_RUN);
+
+	a->ndst = dst_len - z->avail_out;
+	a->nsrc = src_len - z->avail_in;
+	a->eof = (result == BZ_STREAM_END) ? 1 : 0;
+
+	z->next_out = NULL;
+	z->avail_out = 0;
+	z->next_in = NULL;
+	z->avail_in = 0;
+
+	if ((result == BZ_RUN_OK) || (result == BZ_FINISH_OK) ||
+			(result == BZ_STREAM_END)) {
+		return 0;
+	}
+	return result;
+}
+
+bz_stream* cgobzip2_decompress_start() {
+	bz_stream* z = (bz_stream*)(calloc(1, sizeof(bz_stream)));
+	if (!z) {
+		return NULL;
+	}
+	if (BZ2_bzDecompressInit(z, 0, 0) != BZ_OK) {
+		free(z);
+		return NULL;
+	}
+	return z;
+}
+
+void cgobzip2_decompress_free(bz_stream* z) {
+	BZ2_bzDecompressEnd(z);
+	free(z);
+}
+
+int32_t cgobzip2_decompress(bz_stream* z,
+		advances* a,
+		uint8_t* dst_ptr,
+		uint32_t dst_len,
+		uint8_t* src_ptr,
+		uint32_t src_len) {
+	z->next_out = (char*)(dst_ptr);
+	z->avail_out = dst_len;
+	z->next_in = (char*)(src_ptr);
+	z->avail_in = src_len;
+
+	int result = BZ2_bzDecompress(z);
+
+	a->ndst = dst_len - z->avail_out;
+	a->nsrc = src_len - z->avail_in;
+	a->eof = (result == BZ_STREAM_END) ? 1 : 0;
+
+	z->next_out = NULL;
+	z->avail_out = 0;
+	z->next_in = NULL;
+	z->avail_in = 0;
+
+	if ((result == BZ_OK) || (result == BZ_STREAM_END)) {
+		return 0;
+	}
+	return result;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"io"
+	"unsafe"
+
+	"github.com/google/wuffs/lib/compression"
+)
+
+const cgoEnabled = true
+
+// maxLen avoids overflow concerns when converting C and Go integer types.
+const maxLen = 1 << 30
+
+var (
+	errDictionariesAreNotSupported = errors.New("cgobzip2: dictionaries are not supported")
+	errMissingResetCall            = errors.New("cgobzip2: missing Reset call")
+	errNilIOReader                 = errors.New("cgobzip2: nil io.Reader")
+	errNilIOWriter                 = errors.New("cgobzip2: nil io.Writer")
+	errNilReceiver                 = errors.New("cgobzip2: nil receiver")
+	errOutOfMemory                 = errors.New("cgobzip2: out of memory")
+)
+
+type errCode int32
+
+func (e errCode) Error() string {
+	switch e {
+	case C.BZ_SEQUENCE_ERROR:
+		return "cgobzip2: sequence error"
+	case C.BZ_PARAM_ERROR:
+		return "cgobzip2: parameter error"
+	case C.BZ_MEM_ERROR:
+		return "cgobzip2: memory error"
+	case C.BZ_DATA_ERROR:
+		return "cgobzip2: data error"
+	case C.BZ_DATA_ERROR_MAGIC:
+		return "cgobzip2: data error (bad magic)"
+	}
+	return "cgobzip2: unknown error"
+}
+
+// Reader decompresses from the bzip2 format.
+//
+// The zero value is not usable until Reset is called.
+type Reader struct {
+	buf  [65536]byte
+	i, j uint32
+	r    io.Reader
+
+	readErr  error
+	bzip2Err error
+
+	z *C.bz_stream
+	a C.advances
+}
+
+// Reset implements compression.Reader.
+func (r *Reader) Reset(reader io.Reader, dictionary []byte) error {
+	if r == nil {
+		return errNilReceiver
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	if reader == nil {
+		return errNilIOReader
+	}
+	if len(dictionary) > 0 {
+		return errDictionariesAreNotSupported
+	}
+
+	z := C.cgobzip2_decompress_start()
+	if z == nil {
+		return errOutOfMemory
+	}
+
+	r.r = reader
+	r.z = z
+	return nil
+}
+
+// Close implements compression.Reader.
+func (r *Reader) Close() error {
+	if r == nil {
+		return errNilReceiver
+	}
+	if r.r == nil {
+		return nil
+	}
+	r.i = 0
+	r.j = 0
+	r.r = nil
+	r.readErr = nil
+	r.bzip2Err = nil
+	if r.z != nil {
+		C.cgobzip2_decompress_free(r.z)
+		r.z = nil
+	}
+	return nil
+}
+
+// Read implements compression.Reader.
+func (r *Reader) Read(p []byte) (int, error) {
+	if r == nil {
+		return 0, errNilReceiver
+	}
+	if r.r == nil {
+		return 0, errMissingResetCall
+	}
+
+	if len(p) > maxLen {
+		p = p[:maxLen]
+	}
+
+	for numRead := 0; ; {
+		if r.bzip2Err != nil {
+			return numRead, r.bzip2Err
+		}
+		if len(p) == 0 {
+			return numRead, nil
+		}
+
+		if r.i >= r.j {
+			if r.readErr != nil {
+				return numRead, r.readErr
+			}
+
+			n, err := r.r.Read(r.buf[:])
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			r.i, r.j, r.readErr = 0, uint32(n), err
+			continue
+		}
+
+		e := errCode(C.cgobzip2_decompress(r.z, &r.a,
+			(*C.uint8_t)(unsafe.Pointer(&p[0])),
+			(C.uint32_t)(len(p)),
+			(*C.uint8_t)(unsafe.Pointer(&r.buf[r.i])),
+			(C.uint32_t)(r.j-r.i),
+		))
+
+		numRead += int(r.a.ndst)
+		p = p[int(r.a.ndst):]
+
+		r.i += uint32(r.a.nsrc)
+
+		if e == 0 {
+			if r.a.eof == 0 {
+				continue
+			}
+			r.bzip2Err = io.EOF
+		} else {
+			r.bzip2Err = e
+		}
+		return numRead, r.bzip2Err
+	}
+}
+
+// Writer compresses to the bzip2 format.
+//
+// Compressed bytes may be buffered and not sent to the underlying io.Writer
+// until Close is called.
+//
+// The zero value is not usable until Reset is called.
+type Writer struct {
+	buf [65536]byte
+	j   uint32
+	w   io.Writer
+
+	writeErr error
+
+	z *C.bz_stream
+	a C.advances
+}
+
+// bzip2BlockSize100k maps a compression level to bzip2's block size, in units
+// of 100 kilobytes. Larger blocks compress better but need more memory.
+func bzip2BlockSize100k(level compression.Level) int32 {
+	return level.Interpolate(1, 3, 9, 9, 9)
+}
+
+// Reset implements compression.Writer.
+func (w *Writer) Reset(writer io.Writer, dictionary []byte, level compression.Level) error {
+	if w == nil {
+		return errNilReceiver
+	}
+	w.close()
+	if writer == nil {
+		return errNilIOWriter
+	}
+	if len(dictionary) > 0 {
+		return errDictionariesAreNotSupported
+	}
+
+	z := C.cgobzip2_compress_start(C.int(bzip2BlockSize100k(level)))
+	if z == nil {
+		return errOutOfMemory
+	}
+
+	w.w = writer
+	w.z = z
+	return nil
+}
+
+// Close implements compression.Writer.
+func (w *Writer) Close() error {
+	if w == nil {
+		return errNilReceiver
+	}
+	err := w.flush(true)
+	w.close()
+	return err
+}
+
+func (w *Writer) flush(final bool) error {
+	if w.w == nil {
+		return nil
+	}
+
+	if final {
+		if err := w.write(nil, true); err != nil {
+			return err
+		}
+	}
+
+	if w.j == 0 {
+		return nil
+	}
+	_, err := w.w.Write(w.buf[:w.j])
+	w.j = 0
+	return err
+}
+
+func (w *Writer) close() {
+	if w.w == nil {
+		return
+	}
+	w.j = 0
+	w.w = nil
+	w.writeErr = nil
+	if w.z != nil {
+		C.cgobzip2_compress_free(w.z)
+		w.z = nil
+	}
+}
+
+// Write implements compression.Writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w == nil {
+		return 0, errNilReceiver
+	}
+	if w.w == nil {
+		return 0, errMissingResetCall
+	}
+	if w.writeErr != nil {
+		return 0, w.writeErr
+	}
+
+	originalLenP := len(p)
+	for {
+		remaining := []byte(nil)
+		if len(p) > maxLen {
+			p, remaining = p[:maxLen], p[maxLen:]
+		}
+
+		if err := w.write(p, false); err != nil {
+			return 0, err
+		}
+
+		p, remaining = remaining, nil
+		if len(p) == 0 {
+			return originalLenP, nil
+		}
+	}
+}
+
+func (w *Writer) write(p []byte, final bool) error {
+	if len(p) > maxLen {
+		panic("unreachable")
+	}
+
+	for (len(p) > 0) || final {
+		if w.j == uint32(len(w.buf)) {
+			if err := w.flush(false); err != nil {
+				w.writeErr = err
+				return w.writeErr
+			}
+		}
+
+		srcPtr := (*C.uint8_t)(nil)
+		if len(p) > 0 {
+			srcPtr = (*C.uint8_t)(unsafe.Pointer(&p[0]))
+		}
+		finalInt := C.int(0)
+		if final {
+			finalInt = 1
+		}
+
+		e := errCode(C.cgobzip2_compress(w.z, &w.a,
+			(*C.uint8_t)(unsafe.Pointer(&w.buf[w.j])),
+			(C.uint32_t)(uint32(len(w.buf))-w.j),
+			srcPtr,
+			(C.uint32_t)(len(p)),
+			finalInt,
+		))
+		if final {
+			final = w.a.eof == 0
+		}
+
+		w.j += uint32(w.a.ndst)
+		p = p[uint32(w.a.nsrc):]
+
+		if e != 0 {
+			w.writeErr = e
+			return w.writeErr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgobzip2
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+const (
+	uncompressedMore = "More!\n"
+)
+
+func TestRoundTrip(tt *testing.T) {
+	if !cgoEnabled {
+		tt.Skip("cgo is not enabled")
+	}
+
+	w := &Writer{}
+	r := &Reader{}
+
+	for i := 0; i < 3; i++ {
+		buf := &bytes.Buffer{}
+
+		// Compress.
+		{
+			if err := w.Reset(buf, nil, 0); err != nil {
+				w.Close()
+				tt.Fatalf("i=%d: Reset: %v", i, err)
+			}
+			if _, err := w.Write([]byte(uncompressedMore)); err != nil {
+				w.Close()
+				tt.Fatalf("i=%d: Write: %v", i, err)
+			}
+			if err := w.Close(); err != nil {
+				tt.Fatalf("i=%d: Close: %v", i, err)
+			}
+		}
+
+		compressed := buf.String()
+		if !strings.HasPrefix(compressed, "BZh9") {
+			tt.Fatalf("i=%d: compressed: got % 02x, want a \"BZh9\" prefix", i, compressed)
+		}
+
+		// Uncompress, using the standard library.
+		{
+			gotBytes, err := ioutil.ReadAll(bzip2.NewReader(strings.NewReader(compressed)))
+			if err != nil {
+				tt.Fatalf("i=%d: ReadAll (std): %v", i, err)
+			}
+			if got, want := string(gotBytes), uncompressedMore; got != want {
+				tt.Fatalf("i=%d (std):\ngot  %q\nwant %q", i, got, want)
+			}
+		}
+
+		// Uncompress, using this package.
+		{
+			if err := r.Reset(strings.NewReader(compressed), nil); err != nil {
+				r.Close()
+				tt.Fatalf("i=%d: Reset: %v", i, err)
+			}
+			gotBytes, err := ioutil.ReadAll(r)
+			if err != nil {
+				r.Close()
+				tt.Fatalf("i=%d: ReadAll: %v", i, err)
+			}
+			if got, want := string(gotBytes), uncompressedMore; got != want {
+				r.Close()
+				tt.Fatalf("i=%d:\ngot  %q\nwant %q", i, got, want)
+			}
+			if err := r.Close(); err != nil {
+				tt.Fatalf("i=%d: Close: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestDictionary(tt *testing.T) {
+	if !cgoEnabled {
+		tt.Skip("cgo is not enabled")
+	}
+
+	w := &Writer{}
+	if err := w.Reset(&bytes.Buffer{}, []byte("abc"), 0); err != errDictionariesAreNotSupported {
+		tt.Fatalf("Writer.Reset: got %v, want %v", err, errDictionariesAreNotSupported)
+	}
+
+	r := &Reader{}
+	if err := r.Reset(strings.NewReader(""), []byte("abc")); err != errDictionariesAreNotSupported {
+		tt.Fatalf("Reader.Reset: got %v, want %v", err, errDictionariesAreNotSupported)
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+// Package corpusindex parses and formats corpus index files, such as
+// test/data/corpus-index.txt, which record the expected results of decoding
+// each file in a test corpus.
+//
+// Recording those results in a language-neutral text file (instead of in each
+// implementation's test code) lets any implementation (C or otherwise) check
+// its conformance against the same expectations.
+//
+// "wuffs test" discovers the corpus indexes that apply to each package (see
+// IndexFilenames) and generates C test programs that check that package's
+// entries, so that a codec needs no hand-written test code to be tested
+// against its golden corpus.
+//
+// Each non-blank line that does not start with a '#' is an entry with eight
+// space-separated columns:
+//
+//   - filename, relative to the index file's directory.
+//   - decoder, the name of the decoder's package, e.g. "gif", "json" or
+//     "zlib".
+//   - quirks, either "-" or a comma-separated list of quirks (as hexadecimal
+//     numbers, e.g. "0x3E162002") that are enabled before decoding.
+//   - width and height, in pixels, or "0 0" if decode_image_config failed.
+//   - frames, the number of successful decode_frame calls.
+//   - digest, either "-" (if no decode_frame calls were made) or the CRC-32
+//     IEEE checksum, as an 8-digit hexadecimal number, of the canvases (see
+//     below) after each decode_frame call, concatenated.
+//   - status, the rest of the line, either "ok" or the first non-ok status
+//     message, such as "#gif: bad header".
+//
+// An entry records expectations, not necessarily exact results. Any of the
+// width, height, frames, digest and status columns may be "*", a wildcard that
+// matches any result. A status of "error" matches any status other than "ok".
+// Entries imported from external conformance suites (see
+// script/import-conformance-suite.go) often use these, as such suites
+// typically specify only whether a file is valid.
+//
+// The canvas is a width × height BGRA_NONPREMUL pixel buffer with no padding
+// between rows, initially all zeroes. Frames are decoded onto it, without
+// applying disposal, with the SRC blend for the first frame or when the frame
+// config says to overwrite instead of blend, and the SRC_OVER blend
+// otherwise. The canvas is hashed after each decode_frame call, whether or
+// not that call succeeded.
+//
+// For decoders that are not image decoders, the width and height are always
+// zero. For io_transformer decoders (such as "deflate", "gzip" and "zlib"),
+// frames is the number of decoded bytes and digest is their CRC-32 checksum,
+// or "-" if there are none. For token decoders (such as "json"), frames is the
+// number of tokens with a non-zero value and digest is the CRC-32 checksum of
+// those tokens in the 16-bytes-per-token debug format (see
+// script/print-json-token-debug-format.c), or "-" if there are none. For both,
+// a successful decoding that leaves some of the file unconsumed has the status
+// "#corpusindex: trailing data".
+package corpusindex
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// ConformanceSuites lists the external conformance suites that
+// script/import-conformance-suite.go can import. Each suite is imported to its
+// own test/data/conformance/<suite> directory, with its own corpus index.
+var ConformanceSuites = []string{
+	"jsontestsuite",
+	"pngsuite",
+	"zlib",
+}
+
+// IndexFilenames returns the corpus index filenames, relative to the Wuffs
+// root directory, that can hold the named package's entries. Not all of them
+// need to exist: only test/data/corpus-index.txt is part of this repository.
+// The others are imported conformance suites (see ConformanceSuites) and the
+// package's own corpus, test/corpus/<package>/corpus-index.txt, whose input
+// files are in that same directory.
+func IndexFilenames(packageName string) []string {
+	ret := []string{"test/data/corpus-index.txt"}
+	for _, suite := range ConformanceSuites {
+		ret = append(ret, "test/data/conformance/"+suite+"/corpus-index.txt")
+	}
+	return append(ret, "test/corpus/"+packageName+"/corpus-index.txt")
+}
+
+// StatusError is a Status that matches any status other than "ok".
+const StatusError = "error"
+
+// StatusTrailingData is the status for a non-image decoder that succeeds
+// without consuming all of its input.
+const StatusTrailingData = "#corpusindex: trailing data"
+
+// Column is a bitmask of the Entry columns that can be wildcards.
+type Column uint32
+
+const (
+	ColumnWidth Column = 1 << iota
+	ColumnHeight
+	ColumnFrames
+	ColumnDigest
+	ColumnStatus
+)
+
+// Entry is the expected result of decoding one corpus file.
+//
+// Columns whose bit is set in Wildcards are "*" wildcards and their
+// corresponding fields are ignored.
+type Entry struct {
+	Filename  string
+	Decoder   string
+	Quirks    []uint32
+	Width     uint32
+	Height    uint32
+	NumFrames uint64
+	HasDigest bool
+	Digest    uint32
+	Status    string
+	Wildcards Column
+}
+
+// Match returns whether have, the actual result of decoding e's file,
+// satisfies e's expectations. Only the width, height, frames, digest and
+// status columns are compared, and have should not contain wildcards.
+func (e *Entry) Match(have *Entry) bool {
+	if (e.Wildcards&ColumnWidth == 0) && (e.Width != have.Width) {
+		return false
+	}
+	if (e.Wildcards&ColumnHeight == 0) && (e.Height != have.Height) {
+		return false
+	}
+	if (e.Wildcards&ColumnFrames == 0) && (e.NumFrames != have.NumFrames) {
+		return false
+	}
+	if (e.Wildcards&ColumnDigest == 0) &&
+		((e.HasDigest != have.HasDigest) || (e.Digest != have.Digest)) {
+		return false
+	}
+	if e.Wildcards&ColumnStatus == 0 {
+		if e.Status == StatusError {
+			return have.Status != "ok"
+		}
+		return e.Status == have.Status
+	}
+	return true
+}
+
+// String returns the entry formatted as a single line, without a trailing
+// '\n'.
+func (e *Entry) String() string {
+	return string(e.AppendLine(nil))
+}
+
+// AppendLine appends the entry, formatted as a single line without a trailing
+// '\n', to b.
+func (e *Entry) AppendLine(b []byte) []byte {
+	b = append(b, e.Filename...)
+	b = append(b, ' ')
+	b = append(b, e.Decoder...)
+	b = append(b, ' ')
+	if len(e.Quirks) == 0 {
+		b = append(b, '-')
+	} else {
+		for i, q := range e.Quirks {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, fmt.Sprintf("0x%08X", q)...)
+		}
+	}
+	b = append(b, ' ')
+	b = e.appendColumn(b, ColumnWidth, uint64(e.Width))
+	b = append(b, ' ')
+	b = e.appendColumn(b, ColumnHeight, uint64(e.Height))
+	b = append(b, ' ')
+	b = e.appendColumn(b, ColumnFrames, e.NumFrames)
+	b = append(b, ' ')
+	if e.Wildcards&ColumnDigest != 0 {
+		b = append(b, '*')
+	} else if e.HasDigest {
+		b = append(b, fmt.Sprintf("0x%08X", e.Digest)...)
+	} else {
+		b = append(b, '-')
+	}
+	b = append(b, ' ')
+	if e.Wildcards&ColumnStatus != 0 {
+		b = append(b, '*')
+	} else {
+		b = append(b, e.Status...)
+	}
+	return b
+}
+
+func (e *Entry) appendColumn(b []byte, c Column, u uint64) []byte {
+	if e.Wildcards&c != 0 {
+		return append(b, '*')
+	}
+	return strconv.AppendUint(b, u, 10)
+}
+
+// Index is a list of entries.
+type Index []Entry
+
+// Filter returns those entries whose Decoder equals decoder.
+func (x Index) Filter(decoder string) Index {
+	ret := Index(nil)
+	for _, e := range x {
+		if e.Decoder == decoder {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+// Format returns the entries formatted one per line.
+func (x Index) Format() []byte {
+	b := []byte(nil)
+	for i := range x {
+		b = x[i].AppendLine(b)
+		b = append(b, '\n')
+	}
+	return b
+}
+
+// Load parses the named corpus index file.
+func Load(filename string) (Index, error) {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(src)
+}
+
+// Parse parses a corpus index.
+func Parse(src []byte) (Index, error) {
+	ret := Index(nil)
+	for lineNum := 1; len(src) > 0; lineNum++ {
+		line := src
+		if i := bytes.IndexByte(src, '\n'); i >= 0 {
+			line, src = src[:i], src[i+1:]
+		} else {
+			src = nil
+		}
+		line = bytes.TrimSpace(line)
+		if (len(line) == 0) || (line[0] == '#') {
+			continue
+		}
+		e, err := parseLine(string(line))
+		if err != nil {
+			return nil, fmt.Errorf("corpusindex: line %d: %v", lineNum, err)
+		}
+		ret = append(ret, e)
+	}
+	return ret, nil
+}
+
+var (
+	errInconsistentDigest = errors.New("digest is inconsistent with frames and status")
+	errInvalidDigest      = errors.New("invalid digest")
+	errInvalidQuirks      = errors.New("invalid quirks")
+	errMissingColumns     = errors.New("missing columns")
+)
+
+func parseLine(line string) (e Entry, err error) {
+	const numColumns = 8
+	columns := strings.Fields(line)
+	if len(columns) < numColumns {
+		return Entry{}, errMissingColumns
+	}
+
+	e.Filename = columns[0]
+	e.Decoder = columns[1]
+
+	if q := columns[2]; q != "-" {
+		for _, s := range strings.Split(q, ",") {
+			if !strings.HasPrefix(s, "0x") {
+				return Entry{}, errInvalidQuirks
+			}
+			u, err := strconv.ParseUint(s[2:], 16, 32)
+			if err != nil {
+				return Entry{}, errInvalidQuirks
+			}
+			e.Quirks = append(e.Quirks, uint32(u))
+		}
+	}
+
+	if u, err := e.parseColumn(columns[3], ColumnWidth, 32); err != nil {
+		return Entry{}, fmt.Errorf("invalid width: %v", err)
+	} else {
+		e.Width = uint32(u)
+	}
+	if u, err := e.parseColumn(columns[4], ColumnHeight, 32); err != nil {
+		return Entry{}, fmt.Errorf("invalid height: %v", err)
+	} else {
+		e.Height = uint32(u)
+	}
+	if u, err := e.parseColumn(columns[5], ColumnFrames, 64); err != nil {
+		return Entry{}, fmt.Errorf("invalid frames: %v", err)
+	} else {
+		e.NumFrames = u
+	}
+
+	if d := columns[6]; d == "*" {
+		e.Wildcards |= ColumnDigest
+	} else if d != "-" {
+		if !strings.HasPrefix(d, "0x") || (len(d) != 10) {
+			return Entry{}, errInvalidDigest
+		}
+		u, err := strconv.ParseUint(d[2:], 16, 32)
+		if err != nil {
+			return Entry{}, errInvalidDigest
+		}
+		e.HasDigest, e.Digest = true, uint32(u)
+	}
+
+	e.Status = strings.Join(columns[7:], " ")
+	if e.Status == "*" {
+		e.Status, e.Wildcards = "", e.Wildcards|ColumnStatus
+	} else if (e.Status == "ok") && (e.Wildcards&(ColumnFrames|ColumnDigest) == 0) &&
+		(e.HasDigest != (e.NumFrames > 0)) {
+		return Entry{}, errInconsistentDigest
+	}
+	return e, nil
+}
+
+func (e *Entry) parseColumn(s string, c Column, bitSize int) (uint64, error) {
+	if s == "*" {
+		e.Wildcards |= c
+		return 0, nil
+	}
+	return strconv.ParseUint(s, 10, bitSize)
+}
+
+// NewDigest returns a hash.Hash32 that computes an Entry's Digest, when the
+// canvas is written to it after each decode_frame call.
+func NewDigest() hash.Hash32 {
+	return crc32.NewIEEE()
+}
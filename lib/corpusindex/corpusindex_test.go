@@ -0,0 +1,160 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpusindex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(tt *testing.T) {
+	const src = `# A comment.
+
+a.gif gif - 3 2 1 0x0123ABCD ok
+b.gif   gif 0x3E162002,0x00000001 3 2 2 0x89ABCDEF ok
+c.png png - 0 0 0 - #png: bad header
+`
+	x, err := Parse([]byte(src))
+	if err != nil {
+		tt.Fatalf("Parse: %v", err)
+	}
+	if n := len(x); n != 3 {
+		tt.Fatalf("len: have %d, want %d", n, 3)
+	}
+
+	if e := x[1]; (len(e.Quirks) != 2) || (e.Quirks[0] != 0x3E162002) ||
+		(e.NumFrames != 2) || !e.HasDigest || (e.Digest != 0x89ABCDEF) {
+		tt.Fatalf("x[1]: have %+v", e)
+	}
+	if e := x[2]; e.HasDigest || (e.Status != "#png: bad header") {
+		tt.Fatalf("x[2]: have %+v", e)
+	}
+
+	const want = "" +
+		"a.gif gif - 3 2 1 0x0123ABCD ok\n" +
+		"b.gif gif 0x3E162002,0x00000001 3 2 2 0x89ABCDEF ok\n" +
+		"c.png png - 0 0 0 - #png: bad header\n"
+	if have := string(x.Format()); have != want {
+		tt.Fatalf("Format:\nhave %q\nwant %q", have, want)
+	}
+
+	if have := x.Filter("png"); (len(have) != 1) || (have[0].Filename != "c.png") {
+		tt.Fatalf("Filter: have %v", have)
+	}
+}
+
+func TestParseErrors(tt *testing.T) {
+	testCases := []string{
+		"a.gif gif - 3 2 1 0x0123ABCD",
+		"a.gif gif 12 3 2 1 0x0123ABCD ok",
+		"a.gif gif - -3 2 1 0x0123ABCD ok",
+		"a.gif gif - 3 2 x 0x0123ABCD ok",
+		"a.gif gif - 3 2 1 0x123ABCD ok",
+		"a.gif gif - 3 2 1 - ok",
+		"a.gif gif - 3 2 0 0x0123ABCD ok",
+	}
+	for _, tc := range testCases {
+		if _, err := Parse([]byte(tc)); err == nil {
+			tt.Errorf("%q: have nil error, want non-nil", tc)
+		} else if !strings.HasPrefix(err.Error(), "corpusindex: line 1: ") {
+			tt.Errorf("%q: have %q, want line number", tc, err)
+		}
+	}
+}
+
+func TestWildcards(tt *testing.T) {
+	const src = "" +
+		"a.png png - 3 2 * * ok\n" +
+		"x.png png - * * * * error\n" +
+		"i.json json - 0 0 0 - *\n"
+	x, err := Parse([]byte(src))
+	if err != nil {
+		tt.Fatalf("Parse: %v", err)
+	}
+	if have := string(x.Format()); have != src {
+		tt.Fatalf("Format:\nhave %q\nwant %q", have, src)
+	}
+
+	testCases := []struct {
+		want  int
+		have  Entry
+		match bool
+	}{
+		{0, Entry{Width: 3, Height: 2, NumFrames: 1, HasDigest: true, Status: "ok"}, true},
+		{0, Entry{Width: 3, Height: 1, NumFrames: 1, HasDigest: true, Status: "ok"}, false},
+		{0, Entry{Width: 3, Height: 2, Status: "#png: bad header"}, false},
+		{1, Entry{Status: "#png: bad header"}, true},
+		{1, Entry{Status: "$base: short read"}, true},
+		{1, Entry{Width: 1, Height: 1, Status: "ok"}, false},
+		{2, Entry{Status: "ok"}, true},
+		{2, Entry{Status: StatusTrailingData}, true},
+		{2, Entry{NumFrames: 1, Status: "ok"}, false},
+	}
+	for i, tc := range testCases {
+		if have := x[tc.want].Match(&tc.have); have != tc.match {
+			tt.Errorf("i=%d: have %t, want %t", i, have, tc.match)
+		}
+	}
+}
+
+func TestDigest(tt *testing.T) {
+	h := NewDigest()
+	h.Write([]byte("123456789"))
+	if have, want := h.Sum32(), uint32(0xCBF43926); have != want {
+		tt.Fatalf("have 0x%08X, want 0x%08X", have, want)
+	}
+}
+
+func TestTestDataIndex(tt *testing.T) {
+	const dir = "../../test/data"
+	x, err := Load(filepath.Join(dir, "corpus-index.txt"))
+	if err != nil {
+		tt.Fatalf("Load: %v", err)
+	}
+	if len(x) == 0 {
+		tt.Fatalf("Load: have no entries")
+	}
+	for _, e := range x {
+		if _, err := os.Stat(filepath.Join(dir, e.Filename)); err != nil {
+			tt.Errorf("%s: %v", e.Filename, err)
+		}
+	}
+}
+
+func TestOptionalIndexes(tt *testing.T) {
+	// The conformance suites and package corpora are optional. Check
+	// whichever ones are present.
+	filenames := IndexFilenames("*")
+	for _, f := range filenames[1:] {
+		matches, err := filepath.Glob(filepath.Join("../..", f))
+		if err != nil {
+			tt.Fatalf("Glob: %v", err)
+		}
+		for _, m := range matches {
+			x, err := Load(m)
+			if err != nil {
+				tt.Errorf("%s: Load: %v", m, err)
+				continue
+			}
+			for _, e := range x {
+				if _, err := os.Stat(filepath.Join(filepath.Dir(m), e.Filename)); err != nil {
+					tt.Errorf("%s: %s: %v", m, e.Filename, err)
+				}
+			}
+		}
+	}
+}
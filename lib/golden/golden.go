@@ -0,0 +1,131 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+// Package golden compares byte and pixel data against golden (expected)
+// values, producing error messages in the same style as Wuffs' C test
+// library: the first difference is reported along with hex dump excerpts of
+// the surrounding data.
+package golden
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// CompareBytes returns nil if have and want are equal. Otherwise, it returns
+// an error describing the first difference.
+func CompareBytes(have []byte, want []byte) error {
+	n := len(have)
+	if n > len(want) {
+		n = len(want)
+	}
+	i := 0
+	for ; i < n; i++ {
+		if have[i] != want[i] {
+			break
+		}
+	}
+
+	b := &strings.Builder{}
+	if len(have) != len(want) {
+		fmt.Fprintf(b, "golden: length: have %d, want %d.\n", len(have), len(want))
+	} else if i < len(have) {
+		fmt.Fprintf(b, "golden: length=%d:\n", n)
+	} else {
+		return nil
+	}
+	fmt.Fprintf(b, "contents differ at byte %d (in hex: 0x%06x):\n", i, i)
+	HexDump(b, have, i)
+	b.WriteString("excerpts of have (above) versus want (below):\n")
+	HexDump(b, want, i)
+	return fmt.Errorf("%s", b.String())
+}
+
+// HexDump writes up to seven lines of hexadecimal, each covering 16 bytes, of
+// data centered on the line containing the byte at offset i.
+func HexDump(b *strings.Builder, data []byte, i int) {
+	if len(data) == 0 {
+		return
+	}
+	base := i - (i & 15)
+	for j := -3 * 16; j <= +3*16; j += 16 {
+		if (j < 0) && (base < -j) {
+			continue
+		}
+		o := base + j
+		if o >= len(data) {
+			break
+		}
+		n := len(data) - o
+		fmt.Fprintf(b, "  %06x:", o)
+		for k := 0; k < 16; k++ {
+			if k%2 == 0 {
+				b.WriteByte(' ')
+			}
+			if k < n {
+				fmt.Fprintf(b, "%02x", data[o+k])
+			} else {
+				b.WriteString("  ")
+			}
+		}
+		b.WriteString("  ")
+		for k := 0; k < 16; k++ {
+			c := byte(' ')
+			if k < n {
+				c = data[o+k]
+				if (c < 0x20) || (0x7F <= c) {
+					c = '.'
+				}
+			}
+			b.WriteByte(c)
+		}
+		b.WriteByte('\n')
+		if n < 16 {
+			break
+		}
+	}
+}
+
+// CompareImages returns nil if have and want have the same bounds and every
+// pixel has the same non-alpha-premultiplied 16-bit-per-channel color.
+// Otherwise, it returns an error describing the first difference, in
+// row-major order.
+//
+// Comparing in non-premultiplied space means that fully transparent pixels
+// compare equal regardless of their (meaningless) color channels only if both
+// images are themselves premultiplied. It also means that comparing a
+// paletted image against its RGBA equivalent works as expected.
+func CompareImages(have image.Image, want image.Image) error {
+	hb, wb := have.Bounds(), want.Bounds()
+	if hb != wb {
+		return fmt.Errorf("golden: bounds: have %v, want %v", hb, wb)
+	}
+	for y := hb.Min.Y; y < hb.Max.Y; y++ {
+		for x := hb.Min.X; x < hb.Max.X; x++ {
+			hc := color.NRGBA64Model.Convert(have.At(x, y)).(color.NRGBA64)
+			wc := color.NRGBA64Model.Convert(want.At(x, y)).(color.NRGBA64)
+			if hc != wc {
+				return fmt.Errorf("golden: pixel at (%d, %d): have "+
+					"NRGBA64{0x%04X, 0x%04X, 0x%04X, 0x%04X}, want "+
+					"NRGBA64{0x%04X, 0x%04X, 0x%04X, 0x%04X}",
+					x, y, hc.R, hc.G, hc.B, hc.A, wc.R, wc.G, wc.B, wc.A)
+			}
+		}
+	}
+	return nil
+}
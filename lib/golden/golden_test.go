@@ -0,0 +1,78 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golden
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestCompareBytes(tt *testing.T) {
+	if err := CompareBytes([]byte("abc"), []byte("abc")); err != nil {
+		tt.Fatalf("equal: got %v, want nil", err)
+	}
+
+	err := CompareBytes([]byte("0123456789abcdefXYZ"), []byte("0123456789abcdefXyZ"))
+	if err == nil {
+		tt.Fatalf("different: got nil, want non-nil")
+	}
+	const want = "" +
+		"golden: length=19:\n" +
+		"contents differ at byte 17 (in hex: 0x000011):\n" +
+		"  000000: 3031 3233 3435 3637 3839 6162 6364 6566  0123456789abcdef\n" +
+		"  000010: 5859 5a                                  XYZ             \n" +
+		"excerpts of have (above) versus want (below):\n" +
+		"  000000: 3031 3233 3435 3637 3839 6162 6364 6566  0123456789abcdef\n" +
+		"  000010: 5879 5a                                  XyZ             \n"
+	if got := err.Error(); got != want {
+		tt.Fatalf("different:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	err = CompareBytes([]byte("abc"), []byte("abcd"))
+	if (err == nil) || !strings.HasPrefix(err.Error(), "golden: length: have 3, want 4.\n") {
+		tt.Fatalf("different lengths: got %v", err)
+	}
+}
+
+func TestCompareImages(tt *testing.T) {
+	r := image.Rect(0, 0, 2, 2)
+	palette := color.Palette{
+		color.NRGBA{0x00, 0x00, 0x00, 0xFF},
+		color.NRGBA{0x40, 0x80, 0xC0, 0xFF},
+	}
+	have := image.NewPaletted(r, palette)
+	want := image.NewRGBA(r)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			have.SetColorIndex(x, y, uint8(x))
+			want.Set(x, y, palette[x])
+		}
+	}
+	if err := CompareImages(have, want); err != nil {
+		tt.Fatalf("equal: got %v, want nil", err)
+	}
+
+	want.Set(1, 1, color.NRGBA{0x40, 0x80, 0xC1, 0xFF})
+	err := CompareImages(have, want)
+	if (err == nil) || !strings.HasPrefix(err.Error(), "golden: pixel at (1, 1): ") {
+		tt.Fatalf("different pixels: got %v", err)
+	}
+
+	if err := CompareImages(have, image.NewRGBA(image.Rect(0, 0, 2, 3))); err == nil {
+		tt.Fatalf("different bounds: got nil, want non-nil")
+	}
+}
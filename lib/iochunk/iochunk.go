@@ -0,0 +1,99 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+// Package iochunk provides io.Reader and io.Writer wrappers that limit the
+// amount of data passed per call.
+//
+// Like the C test library's make_limited_reader and make_limited_writer, they
+// are useful for testing that coroutine-based decoders and encoders produce
+// the same output regardless of how their input and output is chunked.
+package iochunk
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	errInvalidChunkSize = errors.New("iochunk: invalid chunk size")
+)
+
+// Reader is an io.Reader that reads at most N bytes per Read call from an
+// underlying io.Reader.
+type Reader struct {
+	R io.Reader
+	N int
+}
+
+// Read implements io.Reader.
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.N <= 0 {
+		return 0, errInvalidChunkSize
+	}
+	if len(p) > r.N {
+		p = p[:r.N]
+	}
+	return r.R.Read(p)
+}
+
+// Writer is an io.Writer that writes at most N bytes per Write call to an
+// underlying io.Writer. A single Write call to a Writer may result in
+// multiple Write calls to the underlying io.Writer.
+type Writer struct {
+	W io.Writer
+	N int
+}
+
+// Write implements io.Writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.N <= 0 {
+		return 0, errInvalidChunkSize
+	}
+	n := 0
+	for len(p) > 0 {
+		q := p
+		if len(q) > w.N {
+			q = q[:w.N]
+		}
+		m, err := w.W.Write(q)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		p = p[m:]
+	}
+	return n, nil
+}
+
+// Split returns b split into consecutive sub-slices, each of length n except
+// possibly the last one, which may be shorter. It returns nil if n is not
+// positive.
+//
+// The sub-slices share b's backing array.
+func Split(b []byte, n int) [][]byte {
+	if n <= 0 {
+		return nil
+	}
+	ret := make([][]byte, 0, (len(b)+n-1)/n)
+	for len(b) > n {
+		ret = append(ret, b[:n:n])
+		b = b[n:]
+	}
+	if len(b) > 0 {
+		ret = append(ret, b)
+	}
+	return ret
+}
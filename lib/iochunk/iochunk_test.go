@@ -0,0 +1,89 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iochunk
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+type recordingWriter struct {
+	lens []int
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.lens = append(w.lens, len(p))
+	return len(p), nil
+}
+
+func TestReader(tt *testing.T) {
+	const src = "abcdefghij"
+	r := &Reader{R: strings.NewReader(src), N: 3}
+	buf := make([]byte, 100)
+	if n, err := r.Read(buf); (n != 3) || (err != nil) {
+		tt.Fatalf("Read: got %d, %v, want %d, nil", n, err, 3)
+	}
+	rest, err := ioutil.ReadAll(r)
+	if err != nil {
+		tt.Fatalf("ReadAll: %v", err)
+	}
+	if got, want := string(buf[:3])+string(rest), src; got != want {
+		tt.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriter(tt *testing.T) {
+	rw := &recordingWriter{}
+	w := &Writer{W: rw, N: 4}
+	if n, err := w.Write(make([]byte, 10)); (n != 10) || (err != nil) {
+		tt.Fatalf("Write: got %d, %v, want %d, nil", n, err, 10)
+	}
+	if got, want := rw.lens, []int{4, 4, 2}; !equalInts(got, want) {
+		tt.Fatalf("lens: got %v, want %v", got, want)
+	}
+
+	if _, err := (&Writer{W: rw}).Write([]byte("x")); err == nil {
+		tt.Fatalf("zero N: got nil error, want non-nil")
+	}
+}
+
+func TestSplit(tt *testing.T) {
+	b := []byte("abcdefg")
+	got := Split(b, 3)
+	if (len(got) != 3) || !bytes.Equal(bytes.Join(got, nil), b) {
+		tt.Fatalf("got %q", got)
+	}
+	got[0] = append(got[0], 'X')
+	if string(b) != "abcdefg" {
+		tt.Fatalf("appending to a chunk modified the original: %q", b)
+	}
+	if got := Split(nil, 3); len(got) != 0 {
+		tt.Fatalf("nil input: got %q", got)
+	}
+}
+
+func equalInts(x []int, y []int) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+// Package minimize shrinks an input while preserving some property of it,
+// such as two decoders disagreeing on what that input decodes to.
+//
+// It implements a variant of Zeller and Hildebrandt's "delta debugging"
+// (ddmin) algorithm: repeatedly try removing a chunk of the input, keeping the
+// removal if the property still holds, and halving the chunk size when no
+// chunk can be removed. The result is 1-minimal: removing any single byte
+// would lose the property.
+package minimize
+
+import (
+	"errors"
+)
+
+var (
+	errPropertyDoesNotHold = errors.New("minimize: the property does not hold for the original input")
+)
+
+// Predicate returns whether the candidate input has the property to preserve.
+// It should not retain or modify the candidate slice.
+type Predicate func(candidate []byte) (bool, error)
+
+// Options are optional arguments to Minimize. The zero value is valid.
+type Options struct {
+	// MaxTries, if positive, is the maximum number of times that Minimize
+	// calls the Predicate. When reached, Minimize returns its best so far,
+	// which might not be 1-minimal.
+	MaxTries int
+
+	// Progress, if non-nil, is called with the length of each successively
+	// smaller input found.
+	Progress func(length int)
+}
+
+// Minimize returns the smallest input that it can find, formed by removing
+// bytes from the original input, such that p(input) is true. It returns an
+// error if p(original) is false or if any p call returns an error.
+func Minimize(original []byte, p Predicate, opts *Options) ([]byte, error) {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+
+	tries := 0
+	try := func(candidate []byte) (ok bool, exhausted bool, err error) {
+		if (o.MaxTries > 0) && (tries >= o.MaxTries) {
+			return false, true, nil
+		}
+		tries++
+		ok, err = p(candidate)
+		return ok, false, err
+	}
+
+	if ok, _, err := try(original); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errPropertyDoesNotHold
+	}
+
+	input := append([]byte(nil), original...)
+	candidate := make([]byte, 0, len(input))
+	for numChunks := 2; len(input) > 0; {
+		if numChunks > len(input) {
+			numChunks = len(input)
+		}
+		chunkSize := (len(input) + numChunks - 1) / numChunks
+
+		reduced := false
+		for i := 0; i < len(input); i += chunkSize {
+			j := i + chunkSize
+			if j > len(input) {
+				j = len(input)
+			}
+			candidate = append(candidate[:0], input[:i]...)
+			candidate = append(candidate, input[j:]...)
+
+			ok, exhausted, err := try(candidate)
+			if err != nil {
+				return nil, err
+			} else if exhausted {
+				return input, nil
+			} else if ok {
+				input, candidate = candidate, input
+				if o.Progress != nil {
+					o.Progress(len(input))
+				}
+				reduced = true
+				break
+			}
+		}
+
+		if reduced {
+			// Keep the chunk size roughly the same, relative to the now
+			// shorter input.
+			if numChunks > 2 {
+				numChunks--
+			}
+		} else if numChunks >= len(input) {
+			break
+		} else {
+			numChunks *= 2
+		}
+	}
+	return input, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minimize
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// countTags and countTagsBuggy are two "decoders" that disagree on inputs
+// containing an escaped "\\<".
+func countTags(s []byte) int {
+	return bytes.Count(s, []byte("<"))
+}
+
+func countTagsBuggy(s []byte) int {
+	return countTags(s) - bytes.Count(s, []byte("\\<"))
+}
+
+func diverges(candidate []byte) (bool, error) {
+	return countTags(candidate) != countTagsBuggy(candidate), nil
+}
+
+func TestMinimizeDivergence(tt *testing.T) {
+	original := []byte(strings.Repeat("<a href='x'>link</a> ", 50) + "\\<" +
+		strings.Repeat(" <b>bold</b>", 50))
+	progress := 0
+	have, err := Minimize(original, diverges, &Options{
+		Progress: func(length int) { progress++ },
+	})
+	if err != nil {
+		tt.Fatalf("Minimize: %v", err)
+	}
+	if want := "\\<"; string(have) != want {
+		tt.Fatalf("have %q, want %q", have, want)
+	}
+	if progress == 0 {
+		tt.Fatalf("progress: have 0 calls, want more")
+	}
+}
+
+func TestMinimizeSubsequence(tt *testing.T) {
+	p := func(candidate []byte) (bool, error) {
+		i := bytes.IndexByte(candidate, 'q')
+		return (i >= 0) && (bytes.IndexByte(candidate[i:], 'z') >= 0), nil
+	}
+	have, err := Minimize([]byte("the quick brown fox jumps over the lazy dog"), p, nil)
+	if err != nil {
+		tt.Fatalf("Minimize: %v", err)
+	}
+	if want := "qz"; string(have) != want {
+		tt.Fatalf("have %q, want %q", have, want)
+	}
+}
+
+func TestMinimizeMaxTries(tt *testing.T) {
+	original := []byte("abc\\<def")
+	have, err := Minimize(original, diverges, &Options{MaxTries: 1})
+	if err != nil {
+		tt.Fatalf("Minimize: %v", err)
+	}
+	if !bytes.Equal(have, original) {
+		tt.Fatalf("have %q, want %q", have, original)
+	}
+}
+
+func TestMinimizePropertyDoesNotHold(tt *testing.T) {
+	if _, err := Minimize([]byte("<a>"), diverges, nil); err != errPropertyDoesNotHold {
+		tt.Fatalf("have %v, want %v", err, errPropertyDoesNotHold)
+	}
+}
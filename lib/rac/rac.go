@@ -86,6 +86,8 @@ func (c Codec) name() string {
 			return "LZ4"
 		case 3:
 			return "Zstandard"
+		case 4:
+			return "Bzip2"
 		}
 	}
 	return ""
@@ -100,6 +102,7 @@ const (
 	CodecZlib      = Codec(0x01 << 56)
 	CodecLZ4       = Codec(0x02 << 56)
 	CodecZstandard = Codec(0x03 << 56)
+	CodecBzip2     = Codec(0x04 << 56)
 
 	codecMixBit     = Codec(1 << 62)
 	codecLongZeroes = Codec(1 << 63)
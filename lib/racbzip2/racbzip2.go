@@ -0,0 +1,158 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+// Package racbzip2 provides access to RAC (Random Access Compression) files
+// with the Bzip2 compression codec.
+//
+// The RAC specification is at
+// https://github.com/google/wuffs/blob/main/doc/spec/rac-spec.md
+package racbzip2
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/google/wuffs/lib/cgobzip2"
+	"github.com/google/wuffs/lib/compression"
+	"github.com/google/wuffs/lib/rac"
+)
+
+var (
+	errCannotCut                   = errors.New("racbzip2: cannot cut")
+	errDictionariesAreNotSupported = errors.New("racbzip2: dictionaries are not supported")
+)
+
+// CodecReader specializes a rac.Reader to decode Bzip2-compressed chunks.
+type CodecReader struct {
+	// lim provides a limited view of a RAC file.
+	lim io.LimitedReader
+
+	cachedReader compression.Reader
+}
+
+// Close implements rac.CodecReader.
+func (r *CodecReader) Close() error {
+	if r.cachedReader != nil {
+		return r.cachedReader.Close()
+	}
+	return nil
+}
+
+// Accepts implements rac.CodecReader.
+func (r *CodecReader) Accepts(c rac.Codec) bool {
+	return c == rac.CodecBzip2
+}
+
+// Clone implements rac.CodecReader.
+func (r *CodecReader) Clone() rac.CodecReader {
+	return &CodecReader{}
+}
+
+// MakeDecompressor implements rac.CodecReader.
+func (r *CodecReader) MakeDecompressor(racFile io.ReadSeeker, chunk rac.Chunk) (io.Reader, error) {
+	if chunk.CSecondary.Size() != 0 {
+		return nil, errDictionariesAreNotSupported
+	}
+	if _, err := racFile.Seek(chunk.CPrimary[0], io.SeekStart); err != nil {
+		return nil, err
+	}
+	r.lim.R = racFile
+	r.lim.N = chunk.CPrimary.Size()
+
+	// The chunk's CPrimary range is only an upper bound, and can contain
+	// trailing bytes that belong to the next chunk. Unlike the standard
+	// library's compress/bzip2 package, cgobzip2 stops at the end of the
+	// first bzip2 stream instead of decoding concatenated streams.
+	if r.cachedReader == nil {
+		r.cachedReader = &cgobzip2.Reader{}
+	}
+	if err := r.cachedReader.Reset(&r.lim, nil); err != nil {
+		return nil, err
+	}
+	return r.cachedReader, nil
+}
+
+// CodecWriter specializes a rac.Writer to encode Bzip2-compressed chunks.
+type CodecWriter struct {
+	compressed   bytes.Buffer
+	cachedWriter compression.Writer
+}
+
+// Close implements rac.CodecWriter.
+func (w *CodecWriter) Close() error {
+	return nil
+}
+
+// Clone implements rac.CodecWriter.
+func (w *CodecWriter) Clone() rac.CodecWriter {
+	return &CodecWriter{}
+}
+
+// Compress implements rac.CodecWriter.
+func (w *CodecWriter) Compress(p []byte, q []byte, resourcesData [][]byte) (
+	codec rac.Codec, compressed []byte, secondaryResource int, tertiaryResource int, retErr error) {
+
+	// Compress p+q. Bzip2 does not support shared dictionaries.
+	baseline, err := w.compress(p, q)
+	if err != nil {
+		return 0, nil, 0, 0, err
+	}
+	return rac.CodecBzip2, baseline, rac.NoResourceUsed, rac.NoResourceUsed, nil
+}
+
+func (w *CodecWriter) compress(p []byte, q []byte) ([]byte, error) {
+	w.compressed.Reset()
+	if w.cachedWriter == nil {
+		w.cachedWriter = &cgobzip2.Writer{}
+	}
+	if err := w.cachedWriter.Reset(&w.compressed, nil, compression.LevelSmall); err != nil {
+		return nil, err
+	}
+
+	if len(p) > 0 {
+		if _, err := w.cachedWriter.Write(p); err != nil {
+			w.cachedWriter.Close()
+			return nil, err
+		}
+	}
+	if len(q) > 0 {
+		if _, err := w.cachedWriter.Write(q); err != nil {
+			w.cachedWriter.Close()
+			return nil, err
+		}
+	}
+
+	if err := w.cachedWriter.Close(); err != nil {
+		return nil, err
+	}
+	return w.compressed.Bytes(), nil
+}
+
+// CanCut implements rac.CodecWriter.
+func (w *CodecWriter) CanCut() bool {
+	return false
+}
+
+// Cut implements rac.CodecWriter.
+func (w *CodecWriter) Cut(codec rac.Codec, encoded []byte, maxEncodedLen int) (encodedLen int, decodedLen int, retErr error) {
+	return 0, 0, errCannotCut
+}
+
+// WrapResource implements rac.CodecWriter.
+func (w *CodecWriter) WrapResource(raw []byte) ([]byte, error) {
+	return nil, nil
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+// Package tokendump decodes and prints Wuffs token streams, such as those
+// produced by a wuffs_base__token_decoder.
+//
+// See https://github.com/google/wuffs/blob/main/doc/note/tokens.md
+//
+// The binary dump format is the same as that printed by
+// script/print-json-token-debug-format.c, so that the output of generated C
+// code can be compared against golden files. Like that program's output, the
+// format is only for debugging or regression testing, and certainly not for
+// long term storage.
+package tokendump
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/wuffs/lib/base38"
+)
+
+var (
+	errInputTooLong       = errors.New("tokendump: input is too long")
+	errInvalidTokenStream = errors.New("tokendump: invalid token stream")
+)
+
+// Token is a wuffs_base__token's 64-bit representation.
+type Token uint64
+
+// Value returns the token's value: the high 47 bits, sign-extended.
+func (t Token) Value() int64 { return int64(t) >> 17 }
+
+// ValueExtension returns a negative value if the token was not an extended
+// token.
+func (t Token) ValueExtension() int64 { return (^int64(t)) >> 17 }
+
+// ValueMajor returns a negative value if the token was not a simple token.
+func (t Token) ValueMajor() int64 { return int64(t) >> 42 }
+
+// ValueBaseCategory returns a negative value if the token was not a simple
+// token.
+func (t Token) ValueBaseCategory() int64 { return int64(t) >> 38 }
+
+// ValueMinor returns the token's 25-bit value minor.
+func (t Token) ValueMinor() uint64 { return (uint64(t) >> 17) & 0x1FFFFFF }
+
+// ValueBaseDetail returns the token's 21-bit value base detail.
+func (t Token) ValueBaseDetail() uint64 { return (uint64(t) >> 17) & 0x1FFFFF }
+
+// Continued returns whether the token's continued bit is set.
+func (t Token) Continued() bool { return (t & 0x10000) != 0 }
+
+// Length returns the token's length, the number of source bytes it spans.
+func (t Token) Length() uint64 { return uint64(t) & 0xFFFF }
+
+// ReadTokens reads native-format tokens: a sequence of 8-byte little-endian
+// uint64 values, as written by fwrite'ing a wuffs_base__token array on a
+// little-endian machine.
+func ReadTokens(r io.Reader) ([]Token, error) {
+	ret := []Token(nil)
+	buf := [8]byte{}
+	for {
+		if _, err := io.ReadFull(r, buf[:]); err == io.EOF {
+			return ret, nil
+		} else if err == io.ErrUnexpectedEOF {
+			return nil, errInvalidTokenStream
+		} else if err != nil {
+			return nil, err
+		}
+		ret = append(ret, Token(binary.LittleEndian.Uint64(buf[:])))
+	}
+}
+
+// Options configure Dump.
+type Options struct {
+	// AllTokens is whether to print all tokens, including those (such as
+	// whitespace) whose Value is zero.
+	AllTokens bool
+
+	// HumanReadable is whether to print one line of text per token instead of
+	// 16 bytes of binary per token.
+	HumanReadable bool
+}
+
+// Dump writes the tokens to w in the print-json-token-debug-format style.
+//
+// In the binary format, each token is 16 bytes (128 bits) of big-endian
+// numbers:
+//
+//   - POS (4 bytes) is the position: the sum of all previous tokens' lengths,
+//     including elided tokens.
+//   - LEN (2 bytes) is the length.
+//   - CON (2 bytes) is the continued bit
+//   - EXT (1 bytes) is 1 for extended and 0 for simple tokens.
+//
+// Extended tokens have a VALUE_EXTENSION (7 bytes).
+//
+// Simple tokens have a VALUE_MAJOR (3 bytes) and then either 4 bytes
+// VALUE_MINOR (when VALUE_MAJOR is non-zero) or (1 + 3) bytes
+// VALUE_BASE_CATEGORY and VALUE_BASE_DETAIL (when VALUE_MAJOR is zero).
+//
+// The POS field is only 32 bits, so Dump returns an error if the tokens'
+// cumulative length exceeds 0xFFFF_FFFF.
+func Dump(w io.Writer, tokens []Token, opts *Options) error {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+
+	pos := uint64(0)
+	for _, t := range tokens {
+		if o.AllTokens || (t.Value() != 0) {
+			var err error
+			if o.HumanReadable {
+				err = dumpText(w, t, pos)
+			} else {
+				err = dumpBinary(w, t, pos)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		pos += t.Length()
+		if pos > 0xFFFFFFFF {
+			return errInputTooLong
+		}
+	}
+	return nil
+}
+
+func dumpBinary(w io.Writer, t Token, pos uint64) error {
+	buf := [16]byte{}
+	binary.BigEndian.PutUint32(buf[0x0:], uint32(pos))
+	binary.BigEndian.PutUint16(buf[0x4:], uint16(t.Length()))
+	if t.Continued() {
+		binary.BigEndian.PutUint16(buf[0x6:], 1)
+	}
+	if vmajor := t.ValueMajor(); vmajor > 0 {
+		binary.BigEndian.PutUint32(buf[0x8:], uint32(vmajor))
+		binary.BigEndian.PutUint32(buf[0xC:], uint32(t.ValueMinor()))
+	} else if vmajor == 0 {
+		binary.BigEndian.PutUint32(buf[0xC:], uint32(t.ValueBaseDetail()))
+		buf[0xC] = uint8(t.ValueBaseCategory())
+	} else {
+		binary.BigEndian.PutUint64(buf[0x8:], uint64(t.ValueExtension()))
+		buf[0x8] = 0x01
+	}
+	_, err := w.Write(buf[:])
+	return err
+}
+
+func dumpText(w io.Writer, t Token, pos uint64) error {
+	con := 0
+	if t.Continued() {
+		con = 1
+	}
+	if _, err := fmt.Fprintf(w, "pos=0x%08X  len=0x%04X  con=%d  ",
+		uint32(pos), t.Length(), con); err != nil {
+		return err
+	}
+
+	var err error
+	if vmajor := t.ValueMajor(); vmajor > 0 {
+		name, ok := base38.Decode(uint32(vmajor))
+		if !ok {
+			name = "****"
+		}
+		_, err = fmt.Fprintf(w, "vmajor=0x%06X:%s vminor=0x%07X\n",
+			vmajor, name, t.ValueMinor())
+	} else if vmajor == 0 {
+		_, err = fmt.Fprintf(w, "vbc=%s  vbd=0x%06X\n",
+			vbcNames[t.ValueBaseCategory()&15], t.ValueBaseDetail())
+	} else {
+		_, err = fmt.Fprintf(w, "extended... vextension=0x%012X\n",
+			t.ValueExtension())
+	}
+	return err
+}
+
+var vbcNames = [16]string{
+	"0:Filler...........",
+	"1:Structure........",
+	"2:String...........",
+	"3:UnicodeCodePoint.",
+	"4:Literal..........",
+	"5:Number...........",
+	"6:InlineIntSigned..",
+	"7:InlineIntUnsigned",
+	"8:Reserved.........",
+	"9:Reserved.........",
+	"A:Reserved.........",
+	"B:Reserved.........",
+	"C:Reserved.........",
+	"D:Reserved.........",
+	"E:Reserved.........",
+	"F:Reserved.........",
+}
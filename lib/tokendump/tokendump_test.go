@@ -0,0 +1,108 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokendump
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// mkSimple returns a simple token with the given value base category, value
+// base detail, continued bit and length.
+func mkSimple(vbc uint64, vbd uint64, con bool, length uint64) Token {
+	t := (vbc << 38) | (vbd << 17) | length
+	if con {
+		t |= 0x10000
+	}
+	return Token(t)
+}
+
+func TestAccessors(tt *testing.T) {
+	t := mkSimple(1, 0x4011, true, 3)
+	if got, want := t.ValueMajor(), int64(0); got != want {
+		tt.Errorf("ValueMajor: got %d, want %d", got, want)
+	}
+	if got, want := t.ValueBaseCategory(), int64(1); got != want {
+		tt.Errorf("ValueBaseCategory: got %d, want %d", got, want)
+	}
+	if got, want := t.ValueBaseDetail(), uint64(0x4011); got != want {
+		tt.Errorf("ValueBaseDetail: got 0x%X, want 0x%X", got, want)
+	}
+	if got, want := t.Continued(), true; got != want {
+		tt.Errorf("Continued: got %t, want %t", got, want)
+	}
+	if got, want := t.Length(), uint64(3); got != want {
+		tt.Errorf("Length: got %d, want %d", got, want)
+	}
+
+	x := ^Token(0x1234<<17) &^ 0x1FFFF
+	if got, want := x.ValueExtension(), int64(0x1234); got != want {
+		tt.Errorf("ValueExtension: got 0x%X, want 0x%X", got, want)
+	}
+	if got := x.ValueMajor(); got >= 0 {
+		tt.Errorf("ValueMajor: got %d, want < 0", got)
+	}
+}
+
+func TestDump(tt *testing.T) {
+	tokens := []Token{
+		mkSimple(1, 0x4011, false, 1), // Structure push.
+		mkSimple(0, 0, false, 2),      // Whitespace, elided by default.
+		mkSimple(7, 0x2A, false, 2),   // Inline unsigned integer.
+		mkSimple(1, 0x1042, false, 1), // Structure pop.
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Dump(buf, tokens, nil); err != nil {
+		tt.Fatalf("Dump: %v", err)
+	}
+	if got, want := hex.EncodeToString(buf.Bytes()), ""+
+		"00000000000100000000000001004011"+
+		"0000000300020000000000000700002a"+
+		"00000005000100000000000001001042"; got != want {
+		tt.Errorf("binary:\ngot  %s\nwant %s", got, want)
+	}
+
+	buf.Reset()
+	if err := Dump(buf, tokens, &Options{AllTokens: true, HumanReadable: true}); err != nil {
+		tt.Fatalf("Dump: %v", err)
+	}
+	if got, want := buf.String(), ""+
+		"pos=0x00000000  len=0x0001  con=0  vbc=1:Structure........  vbd=0x004011\n"+
+		"pos=0x00000001  len=0x0002  con=0  vbc=0:Filler...........  vbd=0x000000\n"+
+		"pos=0x00000003  len=0x0002  con=0  vbc=7:InlineIntUnsigned  vbd=0x00002A\n"+
+		"pos=0x00000005  len=0x0001  con=0  vbc=1:Structure........  vbd=0x001042\n"; got != want {
+		tt.Errorf("text:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestReadTokens(tt *testing.T) {
+	src := []byte{
+		0x01, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+	}
+	got, err := ReadTokens(bytes.NewReader(src))
+	if err != nil {
+		tt.Fatalf("ReadTokens: %v", err)
+	}
+	if (len(got) != 2) || (got[0] != 0x20001) || (got[1] != 0xFFFFFFFFFFFFFFFF) {
+		tt.Fatalf("ReadTokens: got %X", got)
+	}
+
+	if _, err := ReadTokens(bytes.NewReader(src[:9])); err == nil {
+		tt.Fatalf("ReadTokens: got nil error, want non-nil")
+	}
+}
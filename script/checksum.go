@@ -17,16 +17,19 @@
 package main
 
 // checksum.go prints a checksum of stdin's bytes, or of the opening digits of
-// π. Checksum algorithms include "adler32" and "crc32/ieee".
+// π. Checksum algorithms include "adler32", "crc32/castagnoli", "crc32/ieee",
+// "crc64/ecma" and "sha256".
 //
 // Usage: go run checksum.go -algorithm=crc32/ieee < foo.bar
 
 import (
+	"crypto/sha256"
 	"flag"
 	"fmt"
 	"hash"
 	"hash/adler32"
 	"hash/crc32"
+	"hash/crc64"
 	"io"
 	"os"
 	"strings"
@@ -75,8 +78,14 @@ func do(r io.Reader) error {
 	switch *algorithm {
 	case "adler32":
 		h = adler32.New()
+	case "crc32/castagnoli":
+		h = crc32.New(crc32.MakeTable(crc32.Castagnoli))
 	case "crc32/ieee":
 		h = crc32.NewIEEE()
+	case "crc64/ecma":
+		h = crc64.New(crc64.MakeTable(crc64.ECMA))
+	case "sha256":
+		h = sha256.New()
 	default:
 		return fmt.Errorf("unknown algorithm %q", *algorithm)
 	}
@@ -90,8 +99,10 @@ func do(r io.Reader) error {
 		fmt.Printf("0x%08X", h.Sum32())
 	case hash.Hash64:
 		fmt.Printf("0x%016X", h.Sum64())
+	case hash.Hash:
+		fmt.Printf("\"%x\"", h.Sum(nil))
 	default:
-		return fmt.Errorf("algorithm %q is not a Hash32 or Hash64", *algorithm)
+		return fmt.Errorf("algorithm %q is not a Hash", *algorithm)
 	}
 	return nil
 }
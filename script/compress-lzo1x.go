@@ -0,0 +1,170 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+// compress-lzo1x.go applies LZO1X-compression, producing a raw LZO1X stream
+// (without the lzop file format's header and per-block checksums).
+//
+// It is a simple, greedy compressor, not an optimal one. Unlike the reference
+// LZO1X-1 compressor, it also emits the short (2 or 3 byte) M1 matches, so
+// that its output exercises every instruction kind.
+//
+// Usage: go run compress-lzo1x.go < pi.txt > pi.txt.lzo1x
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	if err := main1(); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func main1() error {
+	src, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(compress(src))
+	return err
+}
+
+const maxDistance = 0xBFFF
+
+type compressor struct {
+	dst []byte
+
+	// state is 0 after a match with no trailing literals, 1 ..= 3 after a
+	// match with that many trailing literals and 4 after a longer literal
+	// run.
+	state int
+}
+
+func compress(src []byte) []byte {
+	c := &compressor{}
+	table := map[[3]byte]int{}
+	lit, i := 0, 0
+	for i+3 <= len(src) {
+		key := [3]byte{src[i], src[i+1], src[i+2]}
+		j, ok := table[key]
+		table[key] = i
+		if !ok || (i-j > maxDistance) {
+			i++
+			continue
+		}
+		n := 3
+		for (i+n < len(src)) && (src[i+n] == src[j+n]) {
+			n++
+		}
+		c.emitLiterals(src[lit:i])
+		lit = i + c.emitMatch(n, i-j)
+		i += n
+	}
+	c.emitLiterals(src[lit:])
+	// The end-of-stream marker is an M4 match with a zero distance.
+	c.dst = append(c.dst, 0x11, 0x00, 0x00)
+	return c.dst
+}
+
+func (c *compressor) emitLiterals(s []byte) {
+	t := len(s)
+	if t == 0 {
+		return
+	}
+	if (len(c.dst) == 0) && (t <= 238) {
+		c.dst = append(c.dst, byte(17+t))
+		c.state = 4
+		if t < 4 {
+			c.state = t
+		}
+	} else if (t <= 3) && (len(c.dst) >= 2) {
+		// Trailing literals are encoded in the previous match's low 2 bits.
+		c.dst[len(c.dst)-2] |= byte(t)
+		c.state = t
+	} else if t <= 18 {
+		c.dst = append(c.dst, byte(t-3))
+		c.state = 4
+	} else {
+		c.dst = append(c.dst, 0)
+		c.dst = appendLengthExtension(c.dst, t-18)
+		c.state = 4
+	}
+	c.dst = append(c.dst, s...)
+}
+
+// emitMatch emits a match of length n (at least 3) and returns how many of
+// those n bytes were emitted. It can be less than n, after a short M1 match,
+// in which case the remainder becomes part of the next literal run.
+func (c *compressor) emitMatch(n int, distance int) (emitted int) {
+	for n > 0 {
+		if (c.state >= 1) && (c.state <= 3) && (distance <= 0x0400) {
+			// A 2 byte M1 match.
+			d := distance - 1
+			c.dst = append(c.dst, byte(d&3)<<2, byte(d>>2))
+			n, emitted = n-2, emitted+2
+		} else if (c.state == 4) && (0x0801 <= distance) && (distance <= 0x0C00) {
+			// A 3 byte M1 match.
+			d := distance - 0x0801
+			c.dst = append(c.dst, byte(d&3)<<2, byte(d>>2))
+			n, emitted = n-3, emitted+3
+		} else if n < 3 {
+			break
+		} else if (n <= 8) && (distance <= 0x0800) {
+			// An M2 match.
+			d := distance - 1
+			c.dst = append(c.dst, byte(n-1)<<5|byte(d&7)<<2, byte(d>>3))
+			n, emitted = 0, emitted+n
+		} else if distance <= 0x4000 {
+			// An M3 match.
+			d := distance - 1
+			if n <= 33 {
+				c.dst = append(c.dst, byte(32|(n-2)))
+			} else {
+				c.dst = append(c.dst, 32)
+				c.dst = appendLengthExtension(c.dst, n-33)
+			}
+			c.dst = append(c.dst, byte(d<<2), byte(d>>6))
+			n, emitted = 0, emitted+n
+		} else {
+			// An M4 match.
+			d := distance - 0x4000
+			flag := byte(d>>11) & 8
+			if n <= 9 {
+				c.dst = append(c.dst, 16|flag|byte(n-2))
+			} else {
+				c.dst = append(c.dst, 16|flag)
+				c.dst = appendLengthExtension(c.dst, n-9)
+			}
+			c.dst = append(c.dst, byte(d<<2), byte(d>>6))
+			n, emitted = 0, emitted+n
+		}
+		c.state = 0
+	}
+	return emitted
+}
+
+// appendLengthExtension appends n (which must be positive) as a run of zero
+// bytes, each worth 255, followed by a final non-zero byte.
+func appendLengthExtension(dst []byte, n int) []byte {
+	for ; n > 255; n -= 255 {
+		dst = append(dst, 0)
+	}
+	return append(dst, byte(n))
+}
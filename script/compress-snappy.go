@@ -0,0 +1,159 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+// compress-snappy.go applies Snappy-compression, producing the framing format
+// (the *.sz file format).
+//
+// Add the "-block" flag to produce a single raw block instead.
+//
+// It is a simple, greedy compressor, not an optimal one. For the framing
+// format, like the reference compressor, each chunk holds up to 64KiB of
+// uncompressed data and is stored uncompressed if compression does not save
+// at least 12.5%.
+//
+// Usage: go run compress-snappy.go        < pi.txt > pi.txt.sz
+//        go run compress-snappy.go -block < pi.txt > pi.txt.snappy
+
+import (
+	"encoding/binary"
+	"flag"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+)
+
+var block = flag.Bool("block", false, "produce a raw block")
+
+func main() {
+	if err := main1(); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func main1() error {
+	flag.Parse()
+	src, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if *block {
+		_, err = os.Stdout.Write(compressBlock(nil, src))
+		return err
+	}
+
+	dst := []byte("\xFF\x06\x00\x00sNaPpY")
+	for len(src) > 0 {
+		s := src
+		if len(s) > 0x10000 {
+			s = s[:0x10000]
+		}
+		src = src[len(s):]
+
+		chunkType, chunkData := byte(0x00), compressBlock(nil, s)
+		if len(chunkData) >= (len(s) - (len(s) / 8)) {
+			chunkType, chunkData = 0x01, s
+		}
+		n := 4 + len(chunkData)
+		dst = append(dst, chunkType, byte(n>>0), byte(n>>8), byte(n>>16))
+		dst = appendU32LE(dst, maskedCRC(s))
+		dst = append(dst, chunkData...)
+	}
+	_, err = os.Stdout.Write(dst)
+	return err
+}
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+func maskedCRC(s []byte) uint32 {
+	c := crc32.Checksum(s, castagnoliTable)
+	return ((c >> 15) | (c << 17)) + 0xA282EAD8
+}
+
+func appendU32LE(dst []byte, x uint32) []byte {
+	return append(dst, byte(x>>0), byte(x>>8), byte(x>>16), byte(x>>24))
+}
+
+func compressBlock(dst []byte, src []byte) []byte {
+	buf := [binary.MaxVarintLen64]byte{}
+	dst = append(dst, buf[:binary.PutUvarint(buf[:], uint64(len(src)))]...)
+	table := map[[4]byte]int{}
+	lit, i := 0, 0
+	for i+4 <= len(src) {
+		key := [4]byte{src[i], src[i+1], src[i+2], src[i+3]}
+		j, ok := table[key]
+		table[key] = i
+		if !ok {
+			i++
+			continue
+		}
+		n := 4
+		for (i+n < len(src)) && (src[i+n] == src[j+n]) {
+			n++
+		}
+		dst = appendLiteral(dst, src[lit:i])
+		dst = appendCopy(dst, n, i-j)
+		i += n
+		lit = i
+	}
+	return appendLiteral(dst, src[lit:])
+}
+
+func appendLiteral(dst []byte, s []byte) []byte {
+	if len(s) == 0 {
+		return dst
+	}
+	n := len(s) - 1
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n<<2))
+	case n < (1 << 8):
+		dst = append(dst, 60<<2, byte(n))
+	case n < (1 << 16):
+		dst = append(dst, 61<<2, byte(n), byte(n>>8))
+	case n < (1 << 24):
+		dst = append(dst, 62<<2, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, s...)
+}
+
+func appendCopy(dst []byte, n int, offset int) []byte {
+	for n > 0 {
+		m := n
+		if m > 64 {
+			// Leave at least 4 bytes for the final copy.
+			m = 60
+			if n > 68 {
+				m = 64
+			}
+		}
+		n -= m
+		switch {
+		case (4 <= m) && (m <= 11) && (offset < 2048):
+			dst = append(dst, byte(offset>>8)<<5|byte(m-4)<<2|1, byte(offset))
+		case offset < 65536:
+			dst = append(dst, byte(m-1)<<2|2, byte(offset), byte(offset>>8))
+		default:
+			dst = append(dst, byte(m-1)<<2|3)
+			dst = appendU32LE(dst, uint32(offset))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+// convert-gif-to-apng.go decodes an animated GIF from stdin and encodes an
+// APNG (Animated PNG) to stdout.
+//
+// APNG is described at https://wiki.mozilla.org/APNG_Specification
+//
+// Each frame is encoded as 8-bit RGBA (color type 6), non-interlaced, with
+// every row using filter 0 (none). The first frame is the default (IDAT)
+// image. The other frames use fdAT chunks.
+//
+// Usage: go run convert-gif-to-apng.go < foo.gif > foo.apng
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/gif"
+	"os"
+)
+
+func main() {
+	if err := main1(); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func main1() error {
+	g, err := gif.DecodeAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if len(g.Image) == 0 {
+		return errors.New("no frames")
+	} else if g.Image[0].Bounds() != image.Rect(0, 0, g.Config.Width, g.Config.Height) {
+		return errors.New("the first frame does not cover the entire image")
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	w.WriteString("\x89PNG\x0D\x0A\x1A\x0A")
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(g.Config.Width))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(g.Config.Height))
+	ihdr[8] = 8  // Depth.
+	ihdr[9] = 6  // Color type: RGBA.
+	ihdr[10] = 0 // Compression.
+	ihdr[11] = 0 // Filter.
+	ihdr[12] = 0 // Interlace.
+	writeChunk(w, "IHDR", ihdr)
+
+	// The GIF LoopCount is the number of repetitions (with -1 meaning no
+	// repetition and 0 meaning forever). The APNG num_plays is the number of
+	// plays (with 0 meaning forever).
+	numPlays := uint32(0)
+	if g.LoopCount < 0 {
+		numPlays = 1
+	} else if g.LoopCount > 0 {
+		numPlays = uint32(g.LoopCount) + 1
+	}
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(g.Image)))
+	binary.BigEndian.PutUint32(actl[4:], numPlays)
+	writeChunk(w, "acTL", actl)
+
+	seqNum := uint32(0)
+	for i, src := range g.Image {
+		b := src.Bounds()
+
+		disposeOp := byte(0) // APNG_DISPOSE_OP_NONE.
+		if i < len(g.Disposal) {
+			switch g.Disposal[i] {
+			case gif.DisposalBackground:
+				disposeOp = 1 // APNG_DISPOSE_OP_BACKGROUND.
+			case gif.DisposalPrevious:
+				disposeOp = 2 // APNG_DISPOSE_OP_PREVIOUS.
+			}
+		}
+		blendOp := byte(1) // APNG_BLEND_OP_OVER.
+		if i == 0 {
+			blendOp = 0 // APNG_BLEND_OP_SOURCE.
+		}
+
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seqNum)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(b.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(b.Dy()))
+		binary.BigEndian.PutUint32(fctl[12:], uint32(b.Min.X))
+		binary.BigEndian.PutUint32(fctl[16:], uint32(b.Min.Y))
+		binary.BigEndian.PutUint16(fctl[20:], uint16(g.Delay[i]))
+		binary.BigEndian.PutUint16(fctl[22:], 100)
+		fctl[24] = disposeOp
+		fctl[25] = blendOp
+		writeChunk(w, "fcTL", fctl)
+		seqNum++
+
+		data, err := encodePixels(src)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			writeChunk(w, "IDAT", data)
+		} else {
+			fdat := make([]byte, 4+len(data))
+			binary.BigEndian.PutUint32(fdat[0:], seqNum)
+			copy(fdat[4:], data)
+			writeChunk(w, "fdAT", fdat)
+			seqNum++
+		}
+	}
+
+	writeChunk(w, "IEND", nil)
+	return nil
+}
+
+func encodePixels(src image.Image) ([]byte, error) {
+	b := src.Bounds()
+	dst := image.NewNRGBA(b)
+	draw.Draw(dst, b, src, b.Min, draw.Src)
+
+	buf := &bytes.Buffer{}
+	zw := zlib.NewWriter(buf)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := dst.PixOffset(b.Min.X, y)
+		j := dst.PixOffset(b.Max.X, y)
+		// Filter 0 (none), then the row's pixels.
+		if _, err := zw.Write([]byte{0}); err != nil {
+			return nil, err
+		}
+		if _, err := zw.Write(dst.Pix[i:j]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeChunk(w *bufio.Writer, chunkType string, data []byte) {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header[0:], uint32(len(data)))
+	copy(header[4:], chunkType)
+	w.Write(header)
+	w.Write(data)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	footer := make([]byte, 4)
+	binary.BigEndian.PutUint32(footer, crc.Sum32())
+	w.Write(footer)
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+// convert-png-to-16bpc-png.go decodes PNG from stdin and encodes PNG, with 16
+// bits per channel, to stdout. Opaque images are encoded as RGB (color type
+// 2), otherwise as RGBA (color type 6). Either way, the Go standard library's
+// encoder picks each row's filter adaptively.
+//
+// Usage: go run convert-png-to-16bpc-png.go < foo.png > foo.16bpc.png
+
+import (
+	"bufio"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+)
+
+func main() {
+	if err := main1(); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func main1() error {
+	src, err := png.Decode(os.Stdin)
+	if err != nil {
+		return err
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA64(b)
+	draw.Draw(dst, b, src, b.Min, draw.Src)
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	return png.Encode(w, dst)
+}
@@ -0,0 +1,269 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+// convert-png-to-exr.go decodes PNG from stdin and encodes a single-part,
+// scanline OpenEXR image to stdout, with A, B, G and R channels. The 8-bit
+// sample values are scaled to the range [0, 1] without any linearization.
+//
+// OpenEXR is described at https://openexr.com/en/latest/OpenEXRFileLayout.html
+//
+// Usage: go run convert-png-to-exr.go -compression=zip < foo.png > foo.exr
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+)
+
+var (
+	compression = flag.String("compression", "zip", "the compression: none, rle, zips or zip")
+	pixelType   = flag.String("pixel_type", "half", "the channel pixel type: half or float")
+)
+
+const (
+	compressionNone = 0
+	compressionRLE  = 1
+	compressionZIPS = 2
+	compressionZIP  = 3
+
+	pixelTypeHalf  = 1
+	pixelTypeFloat = 2
+)
+
+func main() {
+	if err := main1(); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func main1() error {
+	flag.Parse()
+
+	c, linesPerChunk := 0, 0
+	switch *compression {
+	case "none":
+		c, linesPerChunk = compressionNone, 1
+	case "rle":
+		c, linesPerChunk = compressionRLE, 1
+	case "zips":
+		c, linesPerChunk = compressionZIPS, 1
+	case "zip":
+		c, linesPerChunk = compressionZIP, 16
+	default:
+		return errors.New("bad -compression flag value")
+	}
+
+	pt, sampleSize := 0, 0
+	switch *pixelType {
+	case "half":
+		pt, sampleSize = pixelTypeHalf, 2
+	case "float":
+		pt, sampleSize = pixelTypeFloat, 4
+	default:
+		return errors.New("bad -pixel_type flag value")
+	}
+
+	src, err := png.Decode(os.Stdin)
+	if err != nil {
+		return err
+	}
+	b := src.Bounds()
+	width, height := b.Dx(), b.Dy()
+
+	w := &bytes.Buffer{}
+	w.WriteString("\x76\x2F\x31\x01\x02\x00\x00\x00")
+
+	chlist := &bytes.Buffer{}
+	for _, name := range []string{"A", "B", "G", "R"} {
+		chlist.WriteString(name)
+		chlist.WriteByte(0)
+		writeU32(chlist, uint32(pt))
+		chlist.WriteString("\x00\x00\x00\x00")
+		writeU32(chlist, 1)
+		writeU32(chlist, 1)
+	}
+	chlist.WriteByte(0)
+	writeAttribute(w, "channels", "chlist", chlist.Bytes())
+	writeAttribute(w, "compression", "compression", []byte{byte(c)})
+	box := &bytes.Buffer{}
+	writeU32(box, 0)
+	writeU32(box, 0)
+	writeU32(box, uint32(width-1))
+	writeU32(box, uint32(height-1))
+	writeAttribute(w, "dataWindow", "box2i", box.Bytes())
+	writeAttribute(w, "displayWindow", "box2i", box.Bytes())
+	writeAttribute(w, "lineOrder", "lineOrder", []byte{0})
+	writeAttribute(w, "pixelAspectRatio", "float", f32Bytes(1))
+	writeAttribute(w, "screenWindowCenter", "v2f", append(f32Bytes(0), f32Bytes(0)...))
+	writeAttribute(w, "screenWindowWidth", "float", f32Bytes(1))
+	w.WriteByte(0)
+
+	numChunks := (height + linesPerChunk - 1) / linesPerChunk
+	offsetTableIndex := w.Len()
+	w.Write(make([]byte, 8*numChunks))
+
+	for i := 0; i < numChunks; i++ {
+		binary.LittleEndian.PutUint64(w.Bytes()[offsetTableIndex+8*i:], uint64(w.Len()))
+
+		y0 := b.Min.Y + (i * linesPerChunk)
+		y1 := y0 + linesPerChunk
+		if y1 > b.Max.Y {
+			y1 = b.Max.Y
+		}
+
+		raw := make([]byte, 0, (y1-y0)*width*4*sampleSize)
+		for y := y0; y < y1; y++ {
+			for channel := 0; channel < 4; channel++ {
+				for x := b.Min.X; x < b.Max.X; x++ {
+					nrgba := color.NRGBAModel.Convert(src.At(x, y)).(color.NRGBA)
+					v := [4]uint8{nrgba.A, nrgba.B, nrgba.G, nrgba.R}[channel]
+					f := float32(v) / 255
+					if pt == pixelTypeHalf {
+						raw = append(raw, f32ToHalfBytes(f)...)
+					} else {
+						raw = append(raw, f32Bytes(f)...)
+					}
+				}
+			}
+		}
+
+		data := raw
+		switch c {
+		case compressionRLE:
+			data = rle(predict(reorder(raw)))
+		case compressionZIPS, compressionZIP:
+			z := &bytes.Buffer{}
+			zw := zlib.NewWriter(z)
+			zw.Write(predict(reorder(raw)))
+			zw.Close()
+			data = z.Bytes()
+		}
+		if len(data) >= len(raw) {
+			data = raw
+		}
+
+		writeU32(w, uint32(y0-b.Min.Y))
+		writeU32(w, uint32(len(data)))
+		w.Write(data)
+	}
+
+	_, err = os.Stdout.Write(w.Bytes())
+	return err
+}
+
+func writeU32(w *bytes.Buffer, x uint32) {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], x)
+	w.Write(buf[:])
+}
+
+func writeAttribute(w *bytes.Buffer, name string, typ string, value []byte) {
+	w.WriteString(name)
+	w.WriteByte(0)
+	w.WriteString(typ)
+	w.WriteByte(0)
+	writeU32(w, uint32(len(value)))
+	w.Write(value)
+}
+
+func f32Bytes(f float32) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], math.Float32bits(f))
+	return buf[:]
+}
+
+// f32ToHalfBytes converts f, which must be in the range [0, 1], to an IEEE
+// 754 half-precision value, rounding to nearest.
+func f32ToHalfBytes(f float32) []byte {
+	h := uint16(0)
+	if f >= 1.0/16384 {
+		e := math.Floor(math.Log2(float64(f)))
+		m := math.Round((float64(f)/math.Exp2(e) - 1) * 1024)
+		if m >= 1024 {
+			m, e = 0, e+1
+		}
+		h = uint16(e+15)<<10 | uint16(m)
+	} else if f > 0 {
+		h = uint16(math.Round(float64(f) * (1 << 24)))
+	}
+	return []byte{uint8(h), uint8(h >> 8)}
+}
+
+// reorder splits s into its even- and odd-indexed bytes, concatenated.
+func reorder(s []byte) []byte {
+	t := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i += 2 {
+		t = append(t, s[i])
+	}
+	for i := 1; i < len(s); i += 2 {
+		t = append(t, s[i])
+	}
+	return t
+}
+
+// predict replaces all but the first byte with the delta (biased by 128) from
+// the previous byte.
+func predict(s []byte) []byte {
+	t := make([]byte, len(s))
+	for i := range s {
+		if i == 0 {
+			t[i] = s[i]
+		} else {
+			t[i] = s[i] - s[i-1] + 128
+		}
+	}
+	return t
+}
+
+// rle run-length encodes s. A non-negative count byte c means that the next
+// byte is repeated (c + 1) times. A negative c means that (-c) literal bytes
+// follow.
+func rle(s []byte) []byte {
+	const minRun, maxRun = 3, 127
+	t := []byte(nil)
+	for i := 0; i < len(s); {
+		j := i + 1
+		for (j < len(s)) && (s[j] == s[i]) && ((j - i) < maxRun) {
+			j++
+		}
+		if (j - i) >= minRun {
+			t = append(t, byte(j-i-1), s[i])
+			i = j
+			continue
+		}
+
+		j = i
+		for (j < len(s)) && ((j - i) < maxRun) {
+			if ((j + 2) < len(s)) && (s[j] == s[j+1]) && (s[j] == s[j+2]) {
+				break
+			}
+			j++
+		}
+		t = append(t, byte(-(j - i)))
+		t = append(t, s[i:j]...)
+		i = j
+	}
+	return t
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+// convert-png-to-hdr.go decodes PNG from stdin and encodes a Radiance RGBE
+// (.hdr) image to stdout. The 8-bit sample values are scaled to the range
+// [0, 1] without any linearization. The alpha channel is dropped.
+//
+// The Radiance file format is described at
+// https://paulbourke.net/dataformats/pic/
+//
+// Usage: go run convert-png-to-hdr.go -rle=true < foo.png > foo.hdr
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+)
+
+var (
+	rle = flag.Bool("rle", true, "whether to use (new style) run-length encoded scanlines")
+)
+
+func main() {
+	if err := main1(); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func main1() error {
+	flag.Parse()
+
+	src, err := png.Decode(os.Stdin)
+	if err != nil {
+		return err
+	}
+	b := src.Bounds()
+	width := b.Dx()
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y %d +X %d\n", b.Dy(), width)
+
+	scanline := make([]byte, 4*width)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			nrgba := color.NRGBAModel.Convert(src.At(x, y)).(color.NRGBA)
+			i := 4 * (x - b.Min.X)
+			scanline[i+0], scanline[i+1], scanline[i+2], scanline[i+3] = rgbe(
+				float64(nrgba.R)/255,
+				float64(nrgba.G)/255,
+				float64(nrgba.B)/255)
+		}
+
+		if !*rle || (width < 8) || (width > 0x7FFF) {
+			w.Write(scanline)
+			continue
+		}
+		w.Write([]byte{0x02, 0x02, uint8(width >> 8), uint8(width)})
+		channel := make([]byte, width)
+		for c := 0; c < 4; c++ {
+			for x := range channel {
+				channel[x] = scanline[(4*x)+c]
+			}
+			w.Write(encodeRLE(channel))
+		}
+	}
+	return nil
+}
+
+// rgbe converts the r, g and b values to four bytes: three mantissas and a
+// shared exponent.
+func rgbe(r float64, g float64, b float64) (uint8, uint8, uint8, uint8) {
+	v := math.Max(r, math.Max(g, b))
+	if v < 1e-32 {
+		return 0, 0, 0, 0
+	}
+	m, e := math.Frexp(v)
+	scale := m * 256 / v
+	return uint8(r * scale), uint8(g * scale), uint8(b * scale), uint8(e + 128)
+}
+
+// encodeRLE run-length encodes one channel of a scanline. A count byte c
+// greater than 128 means that the next byte is repeated (c - 128) times.
+// Otherwise, c (which is non-zero) literal bytes follow.
+func encodeRLE(s []byte) []byte {
+	const minRun, maxRun = 3, 127
+	t := []byte(nil)
+	for i := 0; i < len(s); {
+		j := i + 1
+		for (j < len(s)) && (s[j] == s[i]) && ((j - i) < maxRun) {
+			j++
+		}
+		if (j - i) >= minRun {
+			t = append(t, byte(128+j-i), s[i])
+			i = j
+			continue
+		}
+
+		j = i
+		for (j < len(s)) && ((j - i) < 128) {
+			if ((j + 2) < len(s)) && (s[j] == s[j+1]) && (s[j] == s[j+2]) {
+				break
+			}
+			j++
+		}
+		t = append(t, byte(j-i))
+		t = append(t, s[i:j]...)
+		i = j
+	}
+	return t
+}
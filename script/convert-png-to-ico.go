@@ -0,0 +1,152 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+// convert-png-to-ico.go decodes PNG from stdin and encodes an ICO (or CUR)
+// image to stdout.
+//
+// The output's directory has two entries. The first is a 1x1 opaque black
+// decoy, so that decoders have to select the larger second entry (the
+// converted image). The second entry's payload is either a PNG file or a 24
+// bits per pixel BMP (without a BITMAPFILEHEADER) whose AND mask is set for
+// pixels with alpha less than 0x80.
+//
+// Usage: go run convert-png-to-ico.go -payload=bmp < foo.png > foo.ico
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+var (
+	cur      = flag.Bool("cur", false, "whether to write CUR (instead of ICO)")
+	hotspotX = flag.Int("hotspot_x", 0, "the CUR hotspot's x coordinate")
+	hotspotY = flag.Int("hotspot_y", 0, "the CUR hotspot's y coordinate")
+	payload  = flag.String("payload", "png", "the image encoding: \"bmp\" or \"png\"")
+)
+
+func main() {
+	if err := main1(); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func main1() error {
+	flag.Parse()
+
+	src, err := png.Decode(os.Stdin)
+	if err != nil {
+		return err
+	}
+	b := src.Bounds()
+	if (b.Dx() > 256) || (b.Dy() > 256) {
+		return errors.New("image is too large")
+	}
+
+	decoy := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	decoy.SetNRGBA(0, 0, color.NRGBA{0x00, 0x00, 0x00, 0xFF})
+
+	images := []image.Image{decoy, src}
+	payloads := [][]byte{}
+	for _, m := range images {
+		switch *payload {
+		case "bmp":
+			payloads = append(payloads, encodeDIB(m))
+		case "png":
+			buf := &bytes.Buffer{}
+			if err := png.Encode(buf, m); err != nil {
+				return err
+			}
+			payloads = append(payloads, buf.Bytes())
+		default:
+			return errors.New("bad -payload flag value")
+		}
+	}
+
+	out := []byte(nil)
+	typ := uint16(1)
+	if *cur {
+		typ = 2
+	}
+	out = binary.LittleEndian.AppendUint16(out, 0)
+	out = binary.LittleEndian.AppendUint16(out, typ)
+	out = binary.LittleEndian.AppendUint16(out, uint16(len(images)))
+
+	offset := 6 + (16 * len(images))
+	for i, m := range images {
+		mb := m.Bounds()
+		out = append(out, uint8(mb.Dx()), uint8(mb.Dy()), 0x00, 0x00)
+		if *cur {
+			out = binary.LittleEndian.AppendUint16(out, uint16(*hotspotX))
+			out = binary.LittleEndian.AppendUint16(out, uint16(*hotspotY))
+		} else {
+			out = binary.LittleEndian.AppendUint16(out, 1)
+			out = binary.LittleEndian.AppendUint16(out, 32)
+		}
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(payloads[i])))
+		out = binary.LittleEndian.AppendUint32(out, uint32(offset))
+		offset += len(payloads[i])
+	}
+	for _, p := range payloads {
+		out = append(out, p...)
+	}
+
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+// encodeDIB encodes m as a bottom-up 24 bits per pixel BITMAPINFOHEADER, XOR
+// bitmap and AND mask. The header's height counts both bitmaps.
+func encodeDIB(m image.Image) []byte {
+	b := m.Bounds()
+	xorStride := ((3 * b.Dx()) + 3) &^ 3
+	andStride := (((b.Dx() + 7) / 8) + 3) &^ 3
+
+	out := []byte(nil)
+	out = binary.LittleEndian.AppendUint32(out, 40)
+	out = binary.LittleEndian.AppendUint32(out, uint32(b.Dx()))
+	out = binary.LittleEndian.AppendUint32(out, uint32(2*b.Dy()))
+	out = binary.LittleEndian.AppendUint16(out, 1)
+	out = binary.LittleEndian.AppendUint16(out, 24)
+	out = binary.LittleEndian.AppendUint32(out, 0)
+	out = binary.LittleEndian.AppendUint32(out, uint32((xorStride+andStride)*b.Dy()))
+	out = append(out, make([]byte, 16)...)
+
+	xor := make([]byte, xorStride*b.Dy())
+	and := make([]byte, andStride*b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := b.Max.Y - 1 - y
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(m.At(x, y)).(color.NRGBA)
+			i := (row * xorStride) + (3 * (x - b.Min.X))
+			xor[i+0], xor[i+1], xor[i+2] = c.B, c.G, c.R
+			if c.A < 0x80 {
+				j := x - b.Min.X
+				and[(row*andStride)+(j/8)] |= 0x80 >> uint(j&7)
+			}
+		}
+	}
+	out = append(out, xor...)
+	return append(out, and...)
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+// convert-png-to-netpbm.go decodes PNG from stdin and encodes Netpbm to
+// stdout: PGM (P2 or P5), PPM (P3 or P6) or PAM (P7). The -plain flag selects
+// the ASCII (P2 or P3) variants instead of the binary (P5 or P6) ones.
+//
+// Netpbm is described at http://netpbm.sourceforge.net/doc/
+//
+// Usage: go run convert-png-to-netpbm.go -format=ppm < foo.png > foo.ppm
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+var (
+	format = flag.String("format", "ppm", "the output format: pgm, ppm or pam")
+	plain  = flag.Bool("plain", false, "whether to encode ASCII samples")
+)
+
+func main() {
+	if err := main1(); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func main1() error {
+	flag.Parse()
+
+	src, err := png.Decode(os.Stdin)
+	if err != nil {
+		return err
+	}
+	b := src.Bounds()
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	switch {
+	case *format == "pgm":
+		fmt.Fprintf(w, "P%c\n%d %d\n255\n", variant('2', '5'), b.Dx(), b.Dy())
+	case *format == "ppm":
+		fmt.Fprintf(w, "P%c\n%d %d\n255\n", variant('3', '6'), b.Dx(), b.Dy())
+	case *format == "pam" && !*plain:
+		fmt.Fprintf(w, "P7\nWIDTH %d\nHEIGHT %d\nDEPTH 4\nMAXVAL 255\n"+
+			"TUPLTYPE RGB_ALPHA\nENDHDR\n", b.Dx(), b.Dy())
+	default:
+		return errors.New("bad -format or -plain flag value")
+	}
+
+	sw := sampleWriter{w: w}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			switch *format {
+			case "pgm":
+				c := color.GrayModel.Convert(src.At(x, y)).(color.Gray)
+				sw.write(c.Y)
+			case "ppm":
+				c := color.NRGBAModel.Convert(src.At(x, y)).(color.NRGBA)
+				sw.write(c.R, c.G, c.B)
+			case "pam":
+				c := color.NRGBAModel.Convert(src.At(x, y)).(color.NRGBA)
+				sw.write(c.R, c.G, c.B, c.A)
+			}
+		}
+	}
+	if *plain {
+		w.WriteByte('\n')
+	}
+	return nil
+}
+
+func variant(ascii byte, binary byte) byte {
+	if *plain {
+		return ascii
+	}
+	return binary
+}
+
+// sampleWriter writes samples either as raw bytes or, for the plain formats,
+// as decimal numbers on lines of at most 70 bytes.
+type sampleWriter struct {
+	w       *bufio.Writer
+	lineLen int
+}
+
+func (s *sampleWriter) write(samples ...byte) {
+	if !*plain {
+		s.w.Write(samples)
+		return
+	}
+	for _, x := range samples {
+		str := fmt.Sprint(x)
+		if s.lineLen == 0 {
+			// No-op.
+		} else if s.lineLen+1+len(str) > 70 {
+			s.w.WriteByte('\n')
+			s.lineLen = 0
+		} else {
+			s.w.WriteByte(' ')
+			s.lineLen++
+		}
+		s.w.WriteString(str)
+		s.lineLen += len(str)
+	}
+}
@@ -0,0 +1,512 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+// encode-flac.go encodes raw PCM (interleaved, little-endian, two's
+// complement samples) as FLAC.
+//
+// It is not a good compressor. Its purpose is to produce test data that
+// exercises a decoder: it cycles through the various frame header codes,
+// stereo channel assignments, subframe types (constant, verbatim, fixed and
+// LPC), residual coding methods, partition orders and escaped partitions.
+//
+// Usage: go run encode-flac.go -bps=8 -channels=2 < romeo.txt > romeo.txt.flac
+
+import (
+	"crypto/md5"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+)
+
+var (
+	bps       = flag.Int("bps", 16, "bits per sample: 8, 12, 16, 20 or 24")
+	blockSize = flag.Int("blocksize", 4096, "samples per block, per channel")
+	channels  = flag.Int("channels", 2, "number of channels")
+	lshift    = flag.Int("lshift", 0, "bits to shift each input sample left by")
+	rate      = flag.Int("rate", 44100, "sample rate, in Hz")
+)
+
+// outBPS is the encoded bits per sample. With a positive -lshift flag, every
+// sample's low bits are zero, which exercises a decoder's "wasted bits".
+var outBPS int
+
+func main() {
+	if err := main1(); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func main1() error {
+	flag.Parse()
+	bytesPerSample := 0
+	switch *bps {
+	case 8:
+		bytesPerSample = 1
+	case 12, 16:
+		bytesPerSample = 2
+	case 20, 24:
+		bytesPerSample = 3
+	default:
+		return errors.New("bad -bps flag")
+	}
+	if (*channels < 1) || (8 < *channels) {
+		return errors.New("bad -channels flag")
+	} else if (*blockSize < 16) || (65535 < *blockSize) {
+		return errors.New("bad -blocksize flag")
+	} else if (*rate < 1) || (0xFFFFF < *rate) {
+		return errors.New("bad -rate flag")
+	} else if (*lshift < 0) || (24 < (*bps + *lshift)) {
+		return errors.New("bad -lshift flag")
+	}
+	outBPS = *bps + *lshift
+
+	src, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	frameSize := bytesPerSample * *channels
+	if (len(src) % frameSize) != 0 {
+		return errors.New("input length is not a multiple of the frame size")
+	}
+	numSamples := len(src) / frameSize
+
+	// Deinterleave and sign-extend.
+	samples := make([][]int64, *channels)
+	for c := range samples {
+		samples[c] = make([]int64, numSamples)
+	}
+	for i, p := 0, src; i < numSamples; i++ {
+		for c := 0; c < *channels; c++ {
+			u := uint32(0)
+			for j := 0; j < bytesPerSample; j++ {
+				u |= uint32(p[j]) << (8 * uint(j))
+			}
+			p = p[bytesPerSample:]
+			shift := 32 - uint(8*bytesPerSample)
+			samples[c][i] = int64(int32(u<<shift) >> shift)
+			if lim := int64(1) << uint(*bps-1); (samples[c][i] < -lim) || (lim <= samples[c][i]) {
+				return errors.New("input sample is out of range for the -bps flag")
+			}
+			samples[c][i] <<= uint(*lshift)
+		}
+	}
+
+	dst := []byte("fLaC")
+	dst = appendStreamInfo(dst, uint64(numSamples), md5.Sum(src))
+	// A padding block exercises skipping unknown metadata.
+	dst = append(dst, 0x81, 0x00, 0x00, 0x08)
+	dst = append(dst, make([]byte, 8)...)
+
+	for f, i := 0, 0; i < numSamples; f++ {
+		n := *blockSize
+		if n > (numSamples - i) {
+			n = numSamples - i
+		}
+		block := make([][]int64, *channels)
+		for c := range block {
+			block[c] = samples[c][i : i+n]
+		}
+		dst = appendFrame(dst, f, block)
+		i += n
+	}
+
+	_, err = os.Stdout.Write(dst)
+	return err
+}
+
+func appendStreamInfo(dst []byte, numSamples uint64, sum [16]byte) []byte {
+	dst = append(dst, 0x00, 0x00, 0x00, 34)
+	dst = append(dst,
+		byte(*blockSize>>8), byte(*blockSize),
+		byte(*blockSize>>8), byte(*blockSize),
+		0, 0, 0, // Minimum frame size (unknown).
+		0, 0, 0, // Maximum frame size (unknown).
+	)
+	x := (uint64(*rate) << 44) |
+		(uint64(*channels-1) << 41) |
+		(uint64(outBPS-1) << 36) |
+		(numSamples & 0xF_FFFF_FFFF)
+	for i := 7; i >= 0; i-- {
+		dst = append(dst, byte(x>>(8*uint(i))))
+	}
+	return append(dst, sum[:]...)
+}
+
+func appendFrame(dst []byte, f int, block [][]int64) []byte {
+	n := len(block[0])
+	w := &bitWriter{}
+
+	// Frame header.
+	w.write(0x3FFE, 14)
+	w.write(0, 1) // Reserved.
+	w.write(0, 1) // Fixed block size.
+
+	bsCode, bsExtra, bsExtraBits := uint64(7), uint64(n-1), uint(16)
+	switch {
+	case n == 192:
+		bsCode, bsExtraBits = 1, 0
+	case (n == 576) || (n == 1152) || (n == 2304) || (n == 4608):
+		bsCode, bsExtraBits = 2+uint64(log2(n/576)), 0
+	case (n >= 256) && ((n & (n - 1)) == 0) && (n <= 32768):
+		bsCode, bsExtraBits = 8+uint64(log2(n/256)), 0
+	case n <= 256:
+		bsCode, bsExtraBits = 6, 8
+	}
+	w.write(bsCode, 4)
+
+	srCode, srExtra, srExtraBits := uint64(0), uint64(0), uint(0)
+	switch f % 4 {
+	case 1:
+		if (*rate%1000) == 0 && (*rate/1000) < 256 {
+			srCode, srExtra, srExtraBits = 12, uint64(*rate/1000), 8
+		}
+	case 2:
+		if *rate < 65536 {
+			srCode, srExtra, srExtraBits = 13, uint64(*rate), 16
+		}
+	case 3:
+		if (*rate%10) == 0 && (*rate/10) < 65536 {
+			srCode, srExtra, srExtraBits = 14, uint64(*rate/10), 16
+		}
+	}
+	w.write(srCode, 4)
+
+	channelAssignment := *channels - 1
+	if *channels == 2 {
+		channelAssignment = [4]int{1, 8, 9, 10}[f%4]
+	}
+	w.write(uint64(channelAssignment), 4)
+
+	ssCode := uint64(0)
+	if (f % 2) == 1 {
+		ssCode = map[int]uint64{8: 1, 12: 2, 16: 4, 20: 5, 24: 6}[outBPS]
+	}
+	w.write(ssCode, 3)
+	w.write(0, 1) // Reserved.
+
+	w.writeUTF8(uint64(f))
+	w.write(bsExtra, bsExtraBits)
+	w.write(srExtra, srExtraBits)
+	w.write(uint64(crc8(w.buf)), 8)
+
+	// Subframes.
+	subframes, subframeBPS := block, []int{}
+	for range block {
+		subframeBPS = append(subframeBPS, outBPS)
+	}
+	if channelAssignment >= 8 {
+		l, r := block[0], block[1]
+		side := make([]int64, n)
+		for i := range side {
+			side[i] = l[i] - r[i]
+		}
+		switch channelAssignment {
+		case 8:
+			subframes = [][]int64{l, side}
+			subframeBPS[1]++
+		case 9:
+			subframes = [][]int64{side, r}
+			subframeBPS[0]++
+		case 10:
+			mid := make([]int64, n)
+			for i := range mid {
+				mid[i] = (l[i] + r[i]) >> 1
+			}
+			subframes = [][]int64{mid, side}
+			subframeBPS[1]++
+		}
+	}
+	for c, s := range subframes {
+		appendSubframe(w, f+c, s, subframeBPS[c])
+	}
+
+	// Frame footer.
+	w.align()
+	w.write(uint64(crc16(w.buf)), 16)
+	return append(dst, w.buf...)
+}
+
+func appendSubframe(w *bitWriter, k int, s []int64, bps int) {
+	n := len(s)
+
+	// Wasted bits are the trailing zero bits common to every sample.
+	wasted, x := uint(0), int64(0)
+	for _, v := range s {
+		x |= v
+	}
+	if x != 0 {
+		for ((x >> wasted) & 1) == 0 {
+			wasted++
+		}
+	}
+	if wasted > 0 {
+		t := make([]int64, n)
+		for i, v := range s {
+			t[i] = v >> wasted
+		}
+		s, bps = t, bps-int(wasted)
+	}
+
+	writeHeader := func(kind uint64) {
+		w.write(0, 1)
+		w.write(kind, 6)
+		if wasted == 0 {
+			w.write(0, 1)
+		} else {
+			w.write(1, 1)
+			w.write(0, wasted-1)
+			w.write(1, 1)
+		}
+	}
+
+	constant := true
+	for _, v := range s {
+		constant = constant && (v == s[0])
+	}
+	if constant {
+		writeHeader(0)
+		w.writeSigned(s[0], uint(bps))
+		return
+	}
+
+	switch k % 4 {
+	case 0:
+		// Verbatim.
+		writeHeader(1)
+		for _, v := range s {
+			w.writeSigned(v, uint(bps))
+		}
+
+	case 1, 2:
+		// Fixed prediction.
+		order := (k / 4) % 5
+		if order > n {
+			order = n
+		}
+		coefs := [5][]int64{
+			{},
+			{1},
+			{2, -1},
+			{3, -3, 1},
+			{4, -6, 4, -1},
+		}[order]
+		writeHeader(8 + uint64(order))
+		for _, v := range s[:order] {
+			w.writeSigned(v, uint(bps))
+		}
+		writeResidual(w, k, predict(s, coefs, 0), order)
+
+	case 3:
+		// Linear prediction. The coefficients are arbitrary (the residuals
+		// are exact regardless) but the first one dominates.
+		order := 1 + ((k / 4) % 32)
+		if order > n {
+			order = n
+		}
+		const precision, shift = 12, 9
+		coefs := make([]int64, order)
+		coefs[0] = 1 << shift
+		for j := 1; j < order; j++ {
+			coefs[j] = int64((j*37)%19) - 9
+		}
+		writeHeader(31 + uint64(order))
+		for _, v := range s[:order] {
+			w.writeSigned(v, uint(bps))
+		}
+		w.write(precision-1, 4)
+		w.write(shift, 5)
+		for _, c := range coefs {
+			w.writeSigned(c, precision)
+		}
+		writeResidual(w, k, predict(s, coefs, shift), order)
+	}
+}
+
+// predict returns the residuals after the first len(coefs) warm-up samples.
+func predict(s []int64, coefs []int64, shift uint) []int64 {
+	order := len(coefs)
+	residuals := []int64(nil)
+	for i := order; i < len(s); i++ {
+		sum := int64(0)
+		for j, c := range coefs {
+			sum += c * s[i-1-j]
+		}
+		residuals = append(residuals, s[i]-(sum>>shift))
+	}
+	return residuals
+}
+
+func writeResidual(w *bitWriter, k int, residuals []int64, order int) {
+	n := len(residuals) + order
+
+	// Use the largest partition order up to (k % 4) that divides the block.
+	pOrder := uint(k % 4)
+	for ; pOrder > 0; pOrder-- {
+		if ((n & ((1 << pOrder) - 1)) == 0) && ((n >> pOrder) >= order) {
+			break
+		}
+	}
+	method, paramBits, escape := uint64(0), uint(4), uint64(15)
+	if ((k / 2) % 2) == 1 {
+		method, paramBits, escape = 1, 5, 31
+	}
+	w.write(method, 2)
+	w.write(uint64(pOrder), 4)
+
+	for p := 0; p < (1 << pOrder); p++ {
+		m := (n >> pOrder)
+		if p == 0 {
+			m -= order
+		}
+		part := residuals[:m]
+		residuals = residuals[m:]
+
+		if ((k + p) % 5) == 4 {
+			// An escaped partition.
+			rawBits := uint(0)
+			for _, v := range part {
+				for !fitsSigned(v, rawBits) {
+					rawBits++
+				}
+			}
+			if rawBits <= 31 {
+				w.write(escape, paramBits)
+				w.write(uint64(rawBits), 5)
+				for _, v := range part {
+					w.writeSigned(v, rawBits)
+				}
+				continue
+			}
+		}
+
+		// Choose the Rice parameter that minimizes the encoded size.
+		bestParam, bestSize := uint64(0), uint64(1<<63)
+		for param := uint64(0); param < escape; param++ {
+			size := uint64(0)
+			for _, v := range part {
+				size += (zigzag(v) >> param) + 1 + param
+			}
+			if size < bestSize {
+				bestParam, bestSize = param, size
+			}
+		}
+		w.write(bestParam, paramBits)
+		for _, v := range part {
+			u := zigzag(v)
+			for q := u >> bestParam; q > 0; q-- {
+				w.write(0, 1)
+			}
+			w.write(1, 1)
+			w.write(u&((1<<bestParam)-1), uint(bestParam))
+		}
+	}
+}
+
+func fitsSigned(v int64, n uint) bool {
+	if n == 0 {
+		return v == 0
+	}
+	lim := int64(1) << (n - 1)
+	return (-lim <= v) && (v < lim)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func log2(x int) int {
+	n := 0
+	for ; x > 1; x >>= 1 {
+		n++
+	}
+	return n
+}
+
+type bitWriter struct {
+	buf   []byte
+	bits  uint64
+	nBits uint
+}
+
+func (w *bitWriter) write(x uint64, n uint) {
+	for ; n > 0; n-- {
+		w.bits = (w.bits << 1) | ((x >> (n - 1)) & 1)
+		w.nBits++
+		if w.nBits == 8 {
+			w.buf = append(w.buf, byte(w.bits))
+			w.bits, w.nBits = 0, 0
+		}
+	}
+}
+
+func (w *bitWriter) writeSigned(x int64, n uint) {
+	w.write(uint64(x)&((1<<n)-1), n)
+}
+
+func (w *bitWriter) writeUTF8(x uint64) {
+	if x < 0x80 {
+		w.write(x, 8)
+		return
+	}
+	n := uint(2)
+	for x >= (1 << (5*n + 1)) {
+		n++
+	}
+	w.write(((0xFF00>>n)&0xFF)|(x>>(6*(n-1))), 8)
+	for i := n - 1; i > 0; i-- {
+		w.write(0x80|((x>>(6*(i-1)))&0x3F), 8)
+	}
+}
+
+func (w *bitWriter) align() {
+	if w.nBits > 0 {
+		w.write(0, 8-w.nBits)
+	}
+}
+
+func crc8(b []byte) uint8 {
+	c := uint8(0)
+	for _, x := range b {
+		c ^= x
+		for i := 0; i < 8; i++ {
+			if (c & 0x80) != 0 {
+				c = (c << 1) ^ 0x07
+			} else {
+				c <<= 1
+			}
+		}
+	}
+	return c
+}
+
+func crc16(b []byte) uint16 {
+	c := uint16(0)
+	for _, x := range b {
+		c ^= uint16(x) << 8
+		for i := 0; i < 8; i++ {
+			if (c & 0x8000) != 0 {
+				c = (c << 1) ^ 0x8005
+			} else {
+				c <<= 1
+			}
+		}
+	}
+	return c
+}
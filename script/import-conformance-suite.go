@@ -0,0 +1,227 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+// import-conformance-suite.go copies an external conformance suite, checked
+// out on local disk, into test/data/conformance/<suite> and writes a corpus
+// index (see lib/corpusindex) of that suite's expectations alongside, so that
+// "wuffs test" checks them.
+//
+// Usage: go run script/import-conformance-suite.go -suite=S -src=DIR
+//
+// Run it from the Wuffs root directory. The suites (and their -src
+// directories) are:
+//
+//   - jsontestsuite: https://github.com/nst/JSONTestSuite's test_parsing
+//     directory. Its y_*.json files must be accepted, its n_*.json files must
+//     be rejected and its i_*.json files may be either.
+//   - pngsuite: http://www.schaik.com/pngsuite/ unpacked. Its x*.png files
+//     must be rejected and every other *.png file must decode to the width and
+//     height in its IHDR chunk.
+//   - zlib: a directory of *.deflate, *.gz or *.zlib files. If a file named
+//     "foo.zlib.decompressed" or "foo" sits alongside "foo.zlib" then "foo.zlib"
+//     must decode to exactly those bytes. Otherwise, it must be rejected.
+//
+// The suites' expectations are typically only whether each file is valid, so
+// the imported entries use lib/corpusindex's wildcards for everything else.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/wuffs/lib/corpusindex"
+)
+
+var (
+	dstFlag   = flag.String("dst", "", "destination directory; defaults to test/data/conformance/<suite>")
+	srcFlag   = flag.String("src", "", "source directory")
+	suiteFlag = flag.String("suite", "", "jsontestsuite, pngsuite or zlib")
+)
+
+// quirkJSONAllowTrailingFiller is std/json's QUIRK_ALLOW_TRAILING_FILLER. It
+// lets a valid JSON document end with a "\n", as many of JSONTestSuite's do.
+const quirkJSONAllowTrailingFiller = 0x49099411
+
+func main() {
+	if err := main1(); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func main1() error {
+	flag.Parse()
+
+	importer := (func(filename string, src []byte) (corpusindex.Entry, bool, error))(nil)
+	switch *suiteFlag {
+	case "jsontestsuite":
+		importer = importJSONTestSuite
+	case "pngsuite":
+		importer = importPNGSuite
+	case "zlib":
+		importer = importZlib
+	default:
+		return fmt.Errorf("bad -suite flag value %q", *suiteFlag)
+	}
+	if *srcFlag == "" {
+		return errors.New("missing -src flag")
+	}
+	dst := *dstFlag
+	if dst == "" {
+		dst = filepath.Join("test", "data", "conformance", *suiteFlag)
+	}
+
+	infos, err := ioutil.ReadDir(*srcFlag)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	x := corpusindex.Index(nil)
+	for _, info := range infos {
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		src, err := ioutil.ReadFile(filepath.Join(*srcFlag, info.Name()))
+		if err != nil {
+			return err
+		}
+		e, ok, err := importer(info.Name(), src)
+		if err != nil {
+			return fmt.Errorf("%s: %v", info.Name(), err)
+		} else if !ok {
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(dst, e.Filename), src, 0644); err != nil {
+			return err
+		}
+		x = append(x, e)
+	}
+	if len(x) == 0 {
+		return fmt.Errorf("no %s files found in %s", *suiteFlag, *srcFlag)
+	}
+	sort.Slice(x, func(i int, j int) bool { return x[i].Filename < x[j].Filename })
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "# This file was generated by script/import-conformance-suite.go\n")
+	fmt.Fprintf(buf, "# -suite=%s. See lib/corpusindex for the format.\n\n", *suiteFlag)
+	buf.Write(x.Format())
+	if err := ioutil.WriteFile(filepath.Join(dst, "corpus-index.txt"), buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d %s entries to %s\n", len(x), *suiteFlag, dst)
+	return nil
+}
+
+func importJSONTestSuite(filename string, src []byte) (e corpusindex.Entry, ok bool, err error) {
+	if !strings.HasSuffix(filename, ".json") || (len(filename) < 2) || (filename[1] != '_') {
+		return corpusindex.Entry{}, false, nil
+	}
+	e = corpusindex.Entry{
+		Filename:  filename,
+		Decoder:   "json",
+		Quirks:    []uint32{quirkJSONAllowTrailingFiller},
+		Wildcards: corpusindex.ColumnFrames | corpusindex.ColumnDigest,
+	}
+	switch filename[0] {
+	case 'i':
+		e.Wildcards |= corpusindex.ColumnStatus
+	case 'n':
+		e.Status = corpusindex.StatusError
+	case 'y':
+		e.Status = "ok"
+	default:
+		return corpusindex.Entry{}, false, nil
+	}
+	return e, true, nil
+}
+
+func importPNGSuite(filename string, src []byte) (e corpusindex.Entry, ok bool, err error) {
+	if !strings.HasSuffix(filename, ".png") {
+		return corpusindex.Entry{}, false, nil
+	}
+	e = corpusindex.Entry{
+		Filename: filename,
+		Decoder:  "png",
+	}
+	if filename[0] == 'x' {
+		e.Wildcards = corpusindex.ColumnWidth | corpusindex.ColumnHeight |
+			corpusindex.ColumnFrames | corpusindex.ColumnDigest
+		e.Status = corpusindex.StatusError
+		return e, true, nil
+	}
+
+	// The 8 byte PNG signature is followed by the IHDR chunk: a 4 byte length,
+	// the 4 byte "IHDR" chunk type and then the 4 byte width and height.
+	if (len(src) < 24) || (string(src[12:16]) != "IHDR") {
+		return corpusindex.Entry{}, false, errors.New("missing IHDR chunk")
+	}
+	e.Width = binary.BigEndian.Uint32(src[16:])
+	e.Height = binary.BigEndian.Uint32(src[20:])
+	e.NumFrames = 1
+	e.Wildcards = corpusindex.ColumnDigest
+	e.Status = "ok"
+	return e, true, nil
+}
+
+func importZlib(filename string, src []byte) (e corpusindex.Entry, ok bool, err error) {
+	ext := filepath.Ext(filename)
+	e = corpusindex.Entry{
+		Filename: filename,
+	}
+	switch ext {
+	case ".deflate":
+		e.Decoder = "deflate"
+	case ".gz":
+		e.Decoder = "gzip"
+	case ".zlib":
+		e.Decoder = "zlib"
+	default:
+		return corpusindex.Entry{}, false, nil
+	}
+
+	want, err := ioutil.ReadFile(filepath.Join(*srcFlag, filename+".decompressed"))
+	if os.IsNotExist(err) {
+		want, err = ioutil.ReadFile(filepath.Join(*srcFlag, strings.TrimSuffix(filename, ext)))
+	}
+	if os.IsNotExist(err) {
+		e.Wildcards = corpusindex.ColumnFrames | corpusindex.ColumnDigest
+		e.Status = corpusindex.StatusError
+		return e, true, nil
+	} else if err != nil {
+		return corpusindex.Entry{}, false, err
+	}
+
+	e.NumFrames = uint64(len(want))
+	if len(want) > 0 {
+		h := corpusindex.NewDigest()
+		h.Write(want)
+		e.HasDigest, e.Digest = true, h.Sum32()
+	}
+	e.Status = "ok"
+	return e, true, nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+// minimize-divergence.go shrinks an input that two programs disagree on, such
+// as two builds of the same decoder, or a Wuffs decoder and a reference
+// implementation. The programs disagree if their stdout output differs or if
+// one of them fails (exits with a non-zero status) and the other does not.
+//
+// Each program is a shell command that is passed the candidate input on its
+// stdin. The minimized input is written to stdout and a description of how
+// the two programs' outputs still differ is written to stderr.
+//
+// Add the "-tokens" flag if the programs' outputs are native-format token
+// streams (an array of wuffs_base__token values), so that the first differing
+// token is described, instead of the first differing byte.
+//
+// Usage: go run minimize-divergence.go -a "./old/jsonptr" -b "./new/jsonptr" \
+//            < diverging.json > minimized.json
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/google/wuffs/lib/golden"
+	"github.com/google/wuffs/lib/minimize"
+	"github.com/google/wuffs/lib/tokendump"
+)
+
+var (
+	aFlag        = flag.String("a", "", "the first program (a shell command)")
+	bFlag        = flag.String("b", "", "the second (e.g. reference) program (a shell command)")
+	maxtriesFlag = flag.Int("maxtries", 0, "the maximum number of candidate inputs to try; 0 means no limit")
+	timeoutFlag  = flag.Duration("timeout", 10*time.Second, "how long to wait for each program run")
+	tokensFlag   = flag.Bool("tokens", false, "whether the programs' outputs are token streams")
+	verboseFlag  = flag.Bool("v", false, "whether to print progress to stderr")
+)
+
+func main() {
+	if err := main1(); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func main1() error {
+	flag.Parse()
+	if (*aFlag == "") || (*bFlag == "") {
+		return errors.New("main: both -a and -b flags are required")
+	}
+	input, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	minimized, err := minimize.Minimize(input, diverges, &minimize.Options{
+		MaxTries: *maxtriesFlag,
+		Progress: func(length int) {
+			if *verboseFlag {
+				fmt.Fprintf(os.Stderr, "minimized to %d bytes\n", length)
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("main: %v (do the two programs disagree on the original input?)", err)
+	}
+	if _, err := os.Stdout.Write(minimized); err != nil {
+		return err
+	}
+
+	aOut, err := run(*aFlag, minimized)
+	if err != nil {
+		return err
+	}
+	bOut, err := run(*bFlag, minimized)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "minimized from %d to %d bytes\n", len(input), len(minimized))
+	if aOut.failed != bOut.failed {
+		fmt.Fprintf(os.Stderr, "-a failed: %t, -b failed: %t\n", aOut.failed, bOut.failed)
+	}
+	return describe(aOut.stdout, bOut.stdout)
+}
+
+type outcome struct {
+	stdout []byte
+	failed bool
+}
+
+func run(command string, input []byte) (outcome, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), *timeoutFlag)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(input)
+	stdout, err := cmd.Output()
+	if ctx.Err() != nil {
+		return outcome{}, fmt.Errorf("main: %q timed out", command)
+	} else if _, ok := err.(*exec.ExitError); ok {
+		return outcome{stdout: stdout, failed: true}, nil
+	} else if err != nil {
+		return outcome{}, err
+	}
+	return outcome{stdout: stdout}, nil
+}
+
+func diverges(candidate []byte) (bool, error) {
+	aOut, err := run(*aFlag, candidate)
+	if err != nil {
+		return false, err
+	}
+	bOut, err := run(*bFlag, candidate)
+	if err != nil {
+		return false, err
+	}
+	return (aOut.failed != bOut.failed) || !bytes.Equal(aOut.stdout, bOut.stdout), nil
+}
+
+// describe writes how aOut and bOut differ to stderr.
+func describe(aOut []byte, bOut []byte) error {
+	if !*tokensFlag {
+		if err := golden.CompareBytes(aOut, bOut); err != nil {
+			fmt.Fprintf(os.Stderr, "-a (have) versus -b (want) output:\n%v", err)
+		}
+		return nil
+	}
+
+	aToks, err := tokendump.ReadTokens(bytes.NewReader(aOut))
+	if err != nil {
+		return fmt.Errorf("main: -a output: %v", err)
+	}
+	bToks, err := tokendump.ReadTokens(bytes.NewReader(bOut))
+	if err != nil {
+		return fmt.Errorf("main: -b output: %v", err)
+	}
+	i := 0
+	for ; (i < len(aToks)) && (i < len(bToks)); i++ {
+		if aToks[i] != bToks[i] {
+			break
+		}
+	}
+	if (i == len(aToks)) && (i == len(bToks)) {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "token streams differ at token %d (of %d and %d tokens):\n",
+		i, len(aToks), len(bToks))
+	for _, o := range [2]struct {
+		name string
+		toks []tokendump.Token
+	}{{"-a", aToks}, {"-b", bToks}} {
+		line := "(none)\n"
+		if i < len(o.toks) {
+			line, err = dumpLastToken(o.toks[:i+1])
+			if err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s", o.name, line)
+	}
+	return nil
+}
+
+// dumpLastToken returns the human-readable dump of the last token, with its
+// position (which depends on the preceding tokens' lengths).
+func dumpLastToken(toks []tokendump.Token) (string, error) {
+	buf := &strings.Builder{}
+	if err := tokendump.Dump(buf, toks, &tokendump.Options{
+		AllTokens:     true,
+		HumanReadable: true,
+	}); err != nil {
+		return "", err
+	}
+	s := strings.TrimSuffix(buf.String(), "\n")
+	return s[strings.LastIndexByte(s, '\n')+1:] + "\n", nil
+}
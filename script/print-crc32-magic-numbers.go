@@ -18,14 +18,21 @@ package main
 
 // print-crc32-magic-numbers.go prints the std/crc32 magic number tables.
 //
+// Add the "-castagnoli" flag to print the CASTAGNOLI_TABLE instead of the
+// IEEE_TABLE.
+//
 // Usage: go run print-crc32-magic-numbers.go
+//        go run print-crc32-magic-numbers.go -castagnoli
 
 import (
+	"flag"
 	"fmt"
 	"hash/crc32"
 	"os"
 )
 
+var castagnoli = flag.Bool("castagnoli", false, "print the Castagnoli table")
+
 func main() {
 	if err := main1(); err != nil {
 		os.Stderr.WriteString(err.Error() + "\n")
@@ -34,14 +41,21 @@ func main() {
 }
 
 func main1() error {
-	tables := [16]crc32.Table{}
-	tables[0] = *crc32.MakeTable(crc32.IEEE)
+	flag.Parse()
 
 	// See "Multi-Byte Lookup Tables" in std/crc32/README.md for more detail on
-	// the slicing-by-M algorithm. We use an M of 16.
+	// the slicing-by-M algorithm. We use an M of 16 for IEEE and 8 for
+	// Castagnoli.
+	tables := make([]crc32.Table, 16)
+	tables[0] = *crc32.MakeTable(crc32.IEEE)
+	if *castagnoli {
+		tables = tables[:8]
+		tables[0] = *crc32.MakeTable(crc32.Castagnoli)
+	}
+
 	for i := 0; i < 256; i++ {
 		crc := tables[0][i]
-		for j := 1; j < 16; j++ {
+		for j := 1; j < len(tables); j++ {
 			crc = tables[0][crc&0xFF] ^ (crc >> 8)
 			tables[j][i] = crc
 		}
@@ -19,13 +19,17 @@ package main
 // print-crc32-x86-sse42-magic-numbers.go prints the std/crc32
 // IEEE_X86_SSE42_ETC magic number tables.
 //
+// Add the "-castagnoli" flag to print the CASTAGNOLI_X86_SSE42_ETC numbers
+// instead.
+//
 // This reproduces the numbers on pages 16 and 22 of Gopal et al. "Fast CRC
 // Computation for Generic Polynomials Using PCLMULQDQ Instruction":
 // https://www.intel.com/content/dam/www/public/us/en/documents/white-papers/fast-crc-computation-generic-polynomials-pclmulqdq-paper.pdf
 //
 // Usage: go run print-crc32-x86-sse42-magic-numbers.go
+//        go run print-crc32-x86-sse42-magic-numbers.go -castagnoli
 //
-// Output:
+// Output (without -castagnoli):
 // The numbers from page 16 (the regular format).
 // Px  = 0x1_04C1_1DB7
 // k1  = 0x0_8833_794C
@@ -47,15 +51,26 @@ package main
 // μ'  = 0x1_F701_1641
 
 import (
+	"flag"
 	"fmt"
 	"strings"
 )
 
+var castagnoli = flag.Bool("castagnoli", false, "print the Castagnoli numbers")
+
 // px is the P(x) polynomial given on page 16, a bit-reversal (with explicit
 // high bit) of the CRC-32/IEEE polynomial sometimes written as 0xEDB8_8320.
 // P(x)  = 0x1_04C1_1DB7
 // P(x)  = 0b1_00000100_11000001_00011101_10110111
-const px = "100000100110000010001110110110111"
+const pxIEEE = "100000100110000010001110110110111"
+
+// pxCastagnoli is like pxIEEE but for the CRC-32C/Castagnoli polynomial,
+// sometimes written as 0x82F6_3B78.
+// P(x)  = 0x1_1EDC_6F41
+// P(x)  = 0b1_00011110_11011100_01101111_01000001
+const pxCastagnoli = "100011110110111000110111101000001"
+
+var px = pxIEEE
 
 // pxdash is P(x)', the bit-reversed format for P(x), given on page 22. This
 // constant is not used by this program, but it is provided for completeness.
@@ -127,6 +142,11 @@ func calcMu(name string) {
 }
 
 func main() {
+	flag.Parse()
+	if *castagnoli {
+		px = pxCastagnoli
+	}
+
 	fmt.Println("The numbers from page 16 (the regular format).")
 	show("Px ", px)
 	calcKn("k1 ", 512+64)
@@ -0,0 +1,161 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+// print-crc64-x86-sse42-magic-numbers.go prints the std/crc64
+// ECMA_X86_SSE42_ETC magic number tables.
+//
+// Rather than evaluating "x to the power N, modulo P(x)" symbolically, with
+// careful attention to bit-reflection and off-by-one shifts, it finds each
+// folding constant k by solving a system of linear equations over GF(2). A
+// 128-bit value X, followed by D zero bytes, must have the same CRC (with a
+// zero initial state and no final XOR) as the 128-bit value
+// "clmul(X.lo, k.lo) ^ clmul(X.hi, k.hi)". Both sides are linear in X and the
+// right hand side is also linear in k. It then checks the solution against
+// pseudo-random X values.
+//
+// Usage: go run print-crc64-x86-sse42-magic-numbers.go
+//
+// Output:
+// FOLD4 (D = 64): k.lo = 0x6AE3_EFBB_9DD4_41F3, k.hi = 0x081F_6054_A784_2DF4
+// FOLD1 (D = 16): k.lo = 0xE05D_D497_CA39_3AE4, k.hi = 0xDABE_95AF_C787_5F40
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc64"
+	"math/rand"
+	"os"
+)
+
+var table = crc64.MakeTable(crc64.ECMA)
+
+func main() {
+	if err := main1(); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func main1() error {
+	for _, c := range []struct {
+		name string
+		d    int
+	}{
+		{"FOLD4", 64},
+		{"FOLD1", 16},
+	} {
+		lo, err := solve(c.d, 0)
+		if err != nil {
+			return err
+		}
+		hi, err := solve(c.d, 8)
+		if err != nil {
+			return err
+		}
+		if err := check(c.d, lo, hi); err != nil {
+			return err
+		}
+		fmt.Printf("%s (D = %d): k.lo = %s, k.hi = %s\n", c.name, c.d, format(lo), format(hi))
+	}
+	return nil
+}
+
+// rawCRC returns the CRC-64/ECMA of b, with a zero initial state and no final
+// XOR.
+func rawCRC(b []byte) uint64 {
+	return ^crc64.Update(^uint64(0), table, b)
+}
+
+// clmul returns the 128-bit carry-less product of x and y, as (lo, hi).
+func clmul(x uint64, y uint64) (lo uint64, hi uint64) {
+	for i := uint(0); i < 64; i++ {
+		if (y>>i)&1 != 0 {
+			lo ^= x << i
+			if i > 0 {
+				hi ^= x >> (64 - i)
+			}
+		}
+	}
+	return lo, hi
+}
+
+// solve finds the k such that, if X is the 128-bit value whose only 1 bit is
+// the low bit of byte i, rawCRC(X followed by d zero bytes) equals
+// rawCRC(clmul(1, k)), which is rawCRC(k followed by 8 zero bytes).
+func solve(d int, i int) (uint64, error) {
+	buf := make([]byte, 16+d)
+	buf[i] = 1
+	target := rawCRC(buf)
+
+	// basis[j] is a (value, mask) pair whose value's highest 1 bit is bit j.
+	type pair struct{ value, mask uint64 }
+	basis := [64]*pair{}
+	for j := uint(0); j < 64; j++ {
+		b := [16]byte{}
+		binary.LittleEndian.PutUint64(b[:8], 1<<j)
+		p := pair{rawCRC(b[:]), 1 << j}
+		for bit := 63; bit >= 0; bit-- {
+			if (p.value>>uint(bit))&1 == 0 {
+				continue
+			} else if basis[bit] != nil {
+				p.value ^= basis[bit].value
+				p.mask ^= basis[bit].mask
+			} else {
+				basis[bit] = &p
+				break
+			}
+		}
+	}
+
+	k := uint64(0)
+	for bit := 63; bit >= 0; bit-- {
+		if (target>>uint(bit))&1 == 0 {
+			continue
+		} else if basis[bit] == nil {
+			return 0, fmt.Errorf("no solution for d=%d, i=%d", d, i)
+		}
+		target ^= basis[bit].value
+		k ^= basis[bit].mask
+	}
+	return k, nil
+}
+
+func check(d int, kLo uint64, kHi uint64) error {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 1000; n++ {
+		buf := make([]byte, 16+d)
+		rng.Read(buf[:16])
+		xLo := binary.LittleEndian.Uint64(buf[0:8])
+		xHi := binary.LittleEndian.Uint64(buf[8:16])
+
+		lo0, hi0 := clmul(xLo, kLo)
+		lo1, hi1 := clmul(xHi, kHi)
+		folded := [16]byte{}
+		binary.LittleEndian.PutUint64(folded[0:8], lo0^lo1)
+		binary.LittleEndian.PutUint64(folded[8:16], hi0^hi1)
+
+		if rawCRC(buf) != rawCRC(folded[:]) {
+			return fmt.Errorf("check failed for d=%d", d)
+		}
+	}
+	return nil
+}
+
+func format(x uint64) string {
+	return fmt.Sprintf("0x%04X_%04X_%04X_%04X", x>>48, (x>>32)&0xFFFF, (x>>16)&0xFFFF, x&0xFFFF)
+}
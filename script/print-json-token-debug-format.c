@@ -83,7 +83,11 @@
 #define WUFFS_CONFIG__MODULES
 #define WUFFS_CONFIG__MODULE__BASE
 #define WUFFS_CONFIG__MODULE__CBOR
+#define WUFFS_CONFIG__MODULE__CSV
+#define WUFFS_CONFIG__MODULE__INI
 #define WUFFS_CONFIG__MODULE__JSON
+#define WUFFS_CONFIG__MODULE__MESSAGEPACK
+#define WUFFS_CONFIG__MODULE__PROTOWIRE
 
 // If building this program in an environment that doesn't easily accommodate
 // relative includes, you can use the script/inline-c-relative-includes.go
@@ -121,7 +125,11 @@ wuffs_base__io_buffer g_src;
 wuffs_base__token_buffer g_tok;
 
 wuffs_cbor__decoder g_cbor_decoder;
+wuffs_csv__decoder g_csv_decoder;
+wuffs_ini__decoder g_ini_decoder;
 wuffs_json__decoder g_json_decoder;
+wuffs_messagepack__decoder g_messagepack_decoder;
+wuffs_protowire__decoder g_protowire_decoder;
 wuffs_base__token_decoder* g_dec;
 wuffs_base__status g_dec_status;
 
@@ -161,6 +169,10 @@ read_src() {
 typedef enum file_format_enum {
   FILE_FORMAT_JSON,
   FILE_FORMAT_CBOR,
+  FILE_FORMAT_CSV,
+  FILE_FORMAT_INI,
+  FILE_FORMAT_MESSAGEPACK,
+  FILE_FORMAT_PROTOWIRE,
 } file_format;
 
 struct {
@@ -207,10 +219,28 @@ parse_flags(int argc, char** argv) {
       g_flags.input_format = FILE_FORMAT_CBOR;
       continue;
     }
+    if (!strcmp(arg, "i=csv") || !strcmp(arg, "input-format=csv")) {
+      g_flags.input_format = FILE_FORMAT_CSV;
+      continue;
+    }
+    if (!strcmp(arg, "i=ini") || !strcmp(arg, "input-format=ini")) {
+      g_flags.input_format = FILE_FORMAT_INI;
+      continue;
+    }
     if (!strcmp(arg, "i=json") || !strcmp(arg, "input-format=json")) {
       g_flags.input_format = FILE_FORMAT_JSON;
       continue;
     }
+    if (!strcmp(arg, "i=messagepack") ||
+        !strcmp(arg, "input-format=messagepack")) {
+      g_flags.input_format = FILE_FORMAT_MESSAGEPACK;
+      continue;
+    }
+    if (!strcmp(arg, "i=protowire") ||
+        !strcmp(arg, "input-format=protowire")) {
+      g_flags.input_format = FILE_FORMAT_PROTOWIRE;
+      continue;
+    }
     if (!strcmp(arg, "q") || !strcmp(arg, "quirks")) {
       g_flags.quirks = true;
       continue;
@@ -233,7 +263,7 @@ const char* g_vbc_names[16] = {
     "5:Number...........",  //
     "6:InlineIntSigned..",  //
     "7:InlineIntUnsigned",  //
-    "8:Reserved.........",  //
+    "8:Comment..........",  //
     "9:Reserved.........",  //
     "A:Reserved.........",  //
     "B:Reserved.........",  //
@@ -272,6 +302,40 @@ main1(int argc, char** argv) {
     }
     g_dec = wuffs_json__decoder__upcast_as__wuffs_base__token_decoder(
         &g_json_decoder);
+  } else if (g_flags.input_format == FILE_FORMAT_CSV) {
+    wuffs_base__status init_status = wuffs_csv__decoder__initialize(
+        &g_csv_decoder, sizeof__wuffs_csv__decoder(), WUFFS_VERSION, 0);
+    if (!wuffs_base__status__is_ok(&init_status)) {
+      return wuffs_base__status__message(&init_status);
+    }
+    g_dec = wuffs_csv__decoder__upcast_as__wuffs_base__token_decoder(
+        &g_csv_decoder);
+  } else if (g_flags.input_format == FILE_FORMAT_INI) {
+    wuffs_base__status init_status = wuffs_ini__decoder__initialize(
+        &g_ini_decoder, sizeof__wuffs_ini__decoder(), WUFFS_VERSION, 0);
+    if (!wuffs_base__status__is_ok(&init_status)) {
+      return wuffs_base__status__message(&init_status);
+    }
+    g_dec = wuffs_ini__decoder__upcast_as__wuffs_base__token_decoder(
+        &g_ini_decoder);
+  } else if (g_flags.input_format == FILE_FORMAT_MESSAGEPACK) {
+    wuffs_base__status init_status = wuffs_messagepack__decoder__initialize(
+        &g_messagepack_decoder, sizeof__wuffs_messagepack__decoder(),
+        WUFFS_VERSION, 0);
+    if (!wuffs_base__status__is_ok(&init_status)) {
+      return wuffs_base__status__message(&init_status);
+    }
+    g_dec = wuffs_messagepack__decoder__upcast_as__wuffs_base__token_decoder(
+        &g_messagepack_decoder);
+  } else if (g_flags.input_format == FILE_FORMAT_PROTOWIRE) {
+    wuffs_base__status init_status = wuffs_protowire__decoder__initialize(
+        &g_protowire_decoder, sizeof__wuffs_protowire__decoder(),
+        WUFFS_VERSION, 0);
+    if (!wuffs_base__status__is_ok(&init_status)) {
+      return wuffs_base__status__message(&init_status);
+    }
+    g_dec = wuffs_protowire__decoder__upcast_as__wuffs_base__token_decoder(
+        &g_protowire_decoder);
   } else {
     wuffs_base__status init_status = wuffs_cbor__decoder__initialize(
         &g_cbor_decoder, sizeof__wuffs_cbor__decoder(), WUFFS_VERSION, 0);
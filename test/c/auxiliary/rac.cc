@@ -0,0 +1,293 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program checks the wuffs_aux::sync_io::RacInput C++ class. Unlike
+the test/c/std programs, it is C++ (not C) and so it does not use testlib.
+
+To manually run this test:
+
+for CXX in clang++ g++; do
+  $CXX -std=c++11 -Wall -Werror rac.cc && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+*/
+
+#define WUFFS_IMPLEMENTATION
+
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__ADLER32
+#define WUFFS_CONFIG__MODULE__AUX__BASE
+#define WUFFS_CONFIG__MODULE__AUX__RAC
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__CRC32
+#define WUFFS_CONFIG__MODULE__DEFLATE
+#define WUFFS_CONFIG__MODULE__ZLIB
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C++ file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+
+#include <stdio.h>
+
+#include <string>
+
+using wuffs_aux::sync_io::RacInput;
+
+// ---------------- Test Data
+
+// g_rac_zlib_at_end is a RAC + Zlib file, with the index at the end, of two
+// chunks: "More!\n" and "Less.\n". It was generated by the Go lib/rac package,
+// like its Example_indexLocationAtEnd.
+const char g_rac_zlib_at_end[] =
+    "\x72\xC3\x63\x00\x78\x9C\x01\x06\x00\xF9\xFF\x4D\x6F\x72\x65\x21"
+    "\x0A\x07\x42\x01\xBF\x78\x9C\x01\x06\x00\xF9\xFF\x4C\x65\x73\x73"
+    "\x2E\x0A\x07\x52\x01\xD0\x72\xC3\x63\x02\x54\x38\x00\xFF\x06\x00"
+    "\x00\x00\x00\x00\x00\xFF\x0C\x00\x00\x00\x00\x00\x00\x01\x04\x00"
+    "\x00\x00\x00\x00\x01\xFF\x15\x00\x00\x00\x00\x00\x01\xFF\x56\x00"
+    "\x00\x00\x00\x00\x01\x02";
+
+const char g_rac_zlib_at_end_want[] = "More!\nLess.\n";
+
+// g_rac_zeroes_at_start is a RAC + Zeroes file, with the index at the start,
+// of two chunks: 10 and 7 NUL bytes. It was generated by the Go lib/rac
+// package.
+const char g_rac_zeroes_at_start[] =
+    "\x72\xC3\x63\x02\x35\xDD\x00\xFF\x0A\x00\x00\x00\x00\x00\x00\xFF"
+    "\x11\x00\x00\x00\x00\x00\x00\x00\x30\x00\x00\x00\x00\x00\x01\xFF"
+    "\x30\x00\x00\x00\x00\x00\x01\xFF\x30\x00\x00\x00\x00\x00\x01\x02";
+
+const size_t g_rac_zeroes_at_start_want_len = 17;
+
+// test/data/sheep-more.rac is a RAC + Zlib file, with the index at the start,
+// whose chunks share a dictionary.
+const char g_rac_sheep_more_filename[] = "../../data/sheep-more.rac";
+
+const char g_rac_sheep_more_want[] =
+    "One sheep.\nTwo sheep.\nThree sheep.\nMore!\n";
+
+// ---------------- Helpers
+
+// read_all calls in.CopyIn, using an IOBuffer whose length is buf_len, until
+// it reaches the end of the DFile, appending what it reads to *have.
+static std::string  //
+read_all(RacInput& in, size_t buf_len, std::string* have) {
+  uint8_t buf[64];
+  if ((buf_len == 0) || (buf_len > sizeof buf)) {
+    return "read_all: invalid buf_len";
+  }
+  wuffs_aux::IOBuffer dst = wuffs_base__ptr_u8__writer(&buf[0], buf_len);
+  for (int i = 0; i < 100000; i++) {
+    std::string err = in.CopyIn(&dst);
+    if (!err.empty()) {
+      return err;
+    }
+    have->append(reinterpret_cast<const char*>(dst.reader_pointer()),
+                 dst.reader_length());
+    dst.meta.ri = dst.meta.wi;
+    if (dst.meta.closed) {
+      return "";
+    }
+  }
+  return "read_all: no progress";
+}
+
+// check_read_all checks that reading the whole DFile, after seeking to each
+// position in turn, gives the corresponding suffix of want.
+static std::string  //
+check_read_all(RacInput& in, const std::string& want) {
+  uint64_t size = 0;
+  std::string err = in.DecompressedSize(&size);
+  if (!err.empty()) {
+    return "DecompressedSize: " + err;
+  } else if (size != want.size()) {
+    return "DecompressedSize: have " + std::to_string(size) + ", want " +
+           std::to_string(want.size());
+  }
+
+  static const size_t buf_lens[] = {1, 3, 64};
+  for (size_t buf_len : buf_lens) {
+    // Seek backwards, from the end to the start, so that every Seek (other
+    // than the first) goes before where the previous read finished.
+    for (size_t pos = want.size() + 1; pos-- > 0;) {
+      std::string prefix = "buf_len=" + std::to_string(buf_len) +
+                           ", pos=" + std::to_string(pos) + ": ";
+      err = in.Seek(pos);
+      if (!err.empty()) {
+        return prefix + "Seek: " + err;
+      } else if (in.Position() != pos) {
+        return prefix + "Position: have " + std::to_string(in.Position());
+      }
+      std::string have;
+      err = read_all(in, buf_len, &have);
+      if (!err.empty()) {
+        return prefix + "read_all: " + err;
+      } else if (have != want.substr(pos)) {
+        return prefix + "read_all: have \"" + have + "\", want \"" +
+               want.substr(pos) + "\"";
+      } else if (in.Position() != want.size()) {
+        return prefix + "Position: have " + std::to_string(in.Position());
+      }
+    }
+  }
+
+  err = in.Seek(want.size() + 1);
+  if (err != "wuffs_aux::sync_io::RacInput: seek position out of bounds") {
+    return "Seek beyond the end: have \"" + err + "\"";
+  }
+  return "";
+}
+
+// ---------------- RAC Tests
+
+static std::string  //
+test_wuffs_aux_rac_decode_zlib_index_at_end() {
+  RacInput in(g_rac_zlib_at_end, sizeof(g_rac_zlib_at_end) - 1);
+  return check_read_all(in, g_rac_zlib_at_end_want);
+}
+
+static std::string  //
+test_wuffs_aux_rac_decode_zlib_index_at_start() {
+  FILE* f = fopen(g_rac_sheep_more_filename, "rb");
+  if (!f) {
+    return std::string("fopen: could not open ") + g_rac_sheep_more_filename;
+  }
+  std::string ret;
+  if ((fseek(f, 0, SEEK_END) != 0) || (ftell(f) <= 0)) {
+    ret = "fseek: could not find the file size";
+  } else {
+    RacInput in(f, static_cast<uint64_t>(ftell(f)));
+    ret = check_read_all(in, g_rac_sheep_more_want);
+  }
+  fclose(f);
+  return ret;
+}
+
+static std::string  //
+test_wuffs_aux_rac_decode_zeroes_index_at_start() {
+  RacInput in(g_rac_zeroes_at_start, sizeof(g_rac_zeroes_at_start) - 1);
+  return check_read_all(in,
+                        std::string(g_rac_zeroes_at_start_want_len, '\x00'));
+}
+
+static std::string  //
+test_wuffs_aux_rac_decode_invalid() {
+  const std::string at_end(g_rac_zlib_at_end, sizeof(g_rac_zlib_at_end) - 1);
+  const std::string at_start(g_rac_zeroes_at_start,
+                             sizeof(g_rac_zeroes_at_start) - 1);
+
+  struct {
+    std::string cfile;
+    size_t offset;
+    uint8_t xor_mask;
+    bool fix_checksum;
+    const char* want;
+  } test_cases[] = {
+      // Too short to be a RAC file.
+      {at_end.substr(0, 31), 0, 0x00, false,
+       "wuffs_aux::sync_io::RacInput: invalid RAC file"},
+      // Truncated, so that the index (at the end) is missing its last byte.
+      {at_end.substr(0, at_end.size() - 1), 0, 0x00, false,
+       "wuffs_aux::sync_io::RacInput: invalid RAC file"},
+      // Truncated, so that the index (at the start) refers to CFile bytes
+      // beyond the end.
+      {at_start.substr(0, at_start.size() - 1), 0, 0x00, false,
+       "wuffs_aux::sync_io::RacInput: invalid RAC file"},
+      // A bad index checksum.
+      {at_end, 0x2A, 0x01, false,
+       "wuffs_aux::sync_io::RacInput: invalid RAC file"},
+      // A bad index version.
+      {at_start, 0x2E, 0x02, true,
+       "wuffs_aux::sync_io::RacInput: invalid RAC file"},
+      // Decreasing DPtr values, with a good checksum.
+      {at_start, 0x08, 0x1F, true,
+       "wuffs_aux::sync_io::RacInput: invalid RAC file"},
+      // A bad zlib checksum in the second chunk's compressed data.
+      {at_end, 0x24, 0x01, false, "zlib: bad checksum"},
+  };
+
+  for (size_t tc = 0; tc < sizeof(test_cases) / sizeof(test_cases[0]); tc++) {
+    std::string cfile = test_cases[tc].cfile;
+    cfile[test_cases[tc].offset] ^= static_cast<char>(test_cases[tc].xor_mask);
+    if (test_cases[tc].fix_checksum) {
+      // Re-calculate the checksum of the index (at the start, with arity 2),
+      // so that something other than the checksum is invalid.
+      wuffs_crc32__ieee_hasher h;
+      if (!h.initialize(sizeof h, WUFFS_VERSION,
+                        WUFFS_INITIALIZE__DEFAULT_OPTIONS)
+               .is_ok()) {
+        return "tc=" + std::to_string(tc) + ": initialize failed";
+      }
+      uint32_t checksum = h.update_u32(wuffs_base__make_slice_u8(
+          reinterpret_cast<uint8_t*>(&cfile[6]), (16 * 2) + 10));
+      checksum ^= checksum >> 16;
+      cfile[4] = static_cast<char>(checksum >> 0);
+      cfile[5] = static_cast<char>(checksum >> 8);
+    }
+
+    RacInput in(cfile.data(), cfile.size());
+    std::string have;
+    std::string err = read_all(in, 64, &have);
+    if (err != test_cases[tc].want) {
+      return "tc=" + std::to_string(tc) + ": have \"" + err + "\", want \"" +
+             test_cases[tc].want + "\"";
+    }
+
+    // The error is sticky for an invalid index.
+    if (err.find("invalid RAC file") != std::string::npos) {
+      uint64_t size = 0;
+      if (in.DecompressedSize(&size) != err) {
+        return "tc=" + std::to_string(tc) + ": DecompressedSize succeeded";
+      } else if (in.Seek(0) != err) {
+        return "tc=" + std::to_string(tc) + ": Seek succeeded";
+      }
+    }
+  }
+  return "";
+}
+
+// ---------------- Manifest
+
+struct {
+  const char* name;
+  std::string (*func)();
+} g_tests[] = {
+    {"test_wuffs_aux_rac_decode_invalid", test_wuffs_aux_rac_decode_invalid},
+    {"test_wuffs_aux_rac_decode_zeroes_index_at_start",
+     test_wuffs_aux_rac_decode_zeroes_index_at_start},
+    {"test_wuffs_aux_rac_decode_zlib_index_at_end",
+     test_wuffs_aux_rac_decode_zlib_index_at_end},
+    {"test_wuffs_aux_rac_decode_zlib_index_at_start",
+     test_wuffs_aux_rac_decode_zlib_index_at_start},
+};
+
+int  //
+main() {
+  size_t num_tests = sizeof(g_tests) / sizeof(g_tests[0]);
+  for (size_t i = 0; i < num_tests; i++) {
+    std::string err = (*g_tests[i].func)();
+    if (!err.empty()) {
+      printf("%-16s%-8sFAIL %s: %s\n", "aux/rac", "c++", g_tests[i].name,
+             err.c_str());
+      return 1;
+    }
+  }
+  printf("%-16s%-8sPASS (%zu tests)\n", "aux/rac", "c++", num_tests);
+  return 0;
+}
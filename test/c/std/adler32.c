@@ -92,6 +92,93 @@ test_wuffs_adler32_interface() {
       "test/data/hat.lossy.webp", 0, SIZE_MAX, 0xF1BB258D);
 }
 
+static void*  //
+counting_zalloc(void* context, size_t n) {
+  (*((int*)context))++;
+  return calloc(n, 1);
+}
+
+static void  //
+counting_free(void* context, void* ptr) {
+  (*((int*)context))--;
+  free(ptr);
+}
+
+const char*  //
+test_wuffs_adler32_alloc_with() {
+  CHECK_FOCUS(__func__);
+  int num_live_allocations = 0;
+  wuffs_base__mem__allocator allocator = {
+      .zalloc_func = &counting_zalloc,
+      .free_func = &counting_free,
+      .context = &num_live_allocations,
+  };
+
+  wuffs_base__hasher_u32* h =
+      wuffs_adler32__hasher__alloc_with_as__wuffs_base__hasher_u32(&allocator);
+  if (!h) {
+    RETURN_FAIL("alloc_with: returned NULL");
+  } else if (num_live_allocations != 1) {
+    wuffs_base__mem__allocator__free(&allocator, h);
+    RETURN_FAIL("num_live_allocations: have %d, want 1", num_live_allocations);
+  }
+  const char* status = do_test__wuffs_base__hasher_u32(
+      h, "test/data/hat.lossy.webp", 0, SIZE_MAX, 0xF1BB258D);
+  wuffs_base__mem__allocator__free(&allocator, h);
+  if (status) {
+    return status;
+  } else if (num_live_allocations != 0) {
+    RETURN_FAIL("num_live_allocations: have %d, want 0", num_live_allocations);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_adler32_combine() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hat.png"));
+  size_t n = src.meta.wi;
+  const uint32_t want = 0xDFC6C9C6;
+
+  wuffs_adler32__hasher h;
+  CHECK_STATUS("initialize",
+               wuffs_adler32__hasher__initialize(
+                   &h, sizeof h, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  size_t splits[] = {0, 1, 7, 100, n / 3, n / 2, n - 1, n};
+  int i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(splits); i++) {
+    size_t split = splits[i];
+    wuffs_base__slice_u8 data_a =
+        wuffs_base__make_slice_u8(src.data.ptr, split);
+    wuffs_base__slice_u8 data_b =
+        wuffs_base__make_slice_u8(src.data.ptr + split, n - split);
+
+    // Seeding with 1, the Adler-32 checksum of the empty string, resets h.
+    wuffs_adler32__hasher__seed_u32(&h, 1);
+    uint32_t a = wuffs_adler32__hasher__update_u32(&h, data_a);
+    wuffs_adler32__hasher__seed_u32(&h, 1);
+    uint32_t b = wuffs_adler32__hasher__update_u32(&h, data_b);
+    wuffs_adler32__hasher__seed_u32(&h, a);
+    uint32_t have_seed = wuffs_adler32__hasher__update_u32(&h, data_b);
+    uint32_t have_combine =
+        wuffs_adler32__hasher__combine_u32(&h, a, b, data_b.len);
+
+    if (have_combine != want) {
+      RETURN_FAIL("split=%zu: combine: have 0x%08" PRIX32 ", want 0x%08" PRIX32,
+                  split, have_combine, want);
+    } else if (have_seed != want) {
+      RETURN_FAIL("split=%zu: seed: have 0x%08" PRIX32 ", want 0x%08" PRIX32,
+                  split, have_seed, want);
+    }
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_adler32_golden() {
   CHECK_FOCUS(__func__);
@@ -301,6 +388,8 @@ bench_mimic_adler32_100k() {
 
 proc g_tests[] = {
 
+    test_wuffs_adler32_alloc_with,
+    test_wuffs_adler32_combine,
     test_wuffs_adler32_golden,
     test_wuffs_adler32_interface,
     test_wuffs_adler32_pi,
@@ -0,0 +1,518 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror base64.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__CRC32
+#define WUFFS_CONFIG__MODULE__BASE64
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_base64_midsummer_gt = {
+    .want_filename = "test/data/midsummer.txt",
+    .src_filename = "test/data/midsummer.txt.base64",
+};
+
+golden_test g_base64_pi_gt = {
+    .want_filename = "test/data/pi.txt",
+    .src_filename = "test/data/pi.txt.base64",
+};
+
+golden_test g_base64_encode_midsummer_gt = {
+    .want_filename = "test/data/midsummer.txt.base64",
+    .src_filename = "test/data/midsummer.txt",
+};
+
+golden_test g_base64_encode_pi_gt = {
+    .want_filename = "test/data/pi.txt.base64",
+    .src_filename = "test/data/pi.txt",
+};
+
+// ---------------- Base64 Tests
+
+const char*  //
+test_wuffs_base64_decode_interface() {
+  CHECK_FOCUS(__func__);
+  wuffs_base64__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_base64__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  // The romeo.txt.base64 file is wrapped at 76 columns.
+  wuffs_base64__decoder__set_quirk_enabled(
+      &dec, WUFFS_BASE64__QUIRK_ALLOW_WHITESPACE, true);
+  return do_test__wuffs_base__io_transformer(
+      wuffs_base64__decoder__upcast_as__wuffs_base__io_transformer(&dec),
+      "test/data/romeo.txt.base64", 0, SIZE_MAX, 942, 0x0A);
+}
+
+const char*  //
+test_wuffs_base64_encode_interface() {
+  CHECK_FOCUS(__func__);
+  wuffs_base64__encoder enc;
+  CHECK_STATUS("initialize",
+               wuffs_base64__encoder__initialize(
+                   &enc, sizeof enc, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  return do_test__wuffs_base__io_transformer(
+      wuffs_base64__encoder__upcast_as__wuffs_base__io_transformer(&enc),
+      "test/data/romeo.txt", 0, SIZE_MAX, 1256, 'K');
+}
+
+// do_wuffs_base64_transform runs a base64 decoder (or, if encode is true, an
+// encoder) with the non-zero quirks enabled.
+const char*  //
+do_wuffs_base64_transform(wuffs_base__io_buffer* dst,
+                          wuffs_base__io_buffer* src,
+                          uint32_t wuffs_initialize_flags,
+                          uint64_t wlimit,
+                          uint64_t rlimit,
+                          bool encode,
+                          const uint32_t* quirks) {
+  wuffs_base64__decoder dec;
+  wuffs_base64__encoder enc;
+  wuffs_base__io_transformer* t = NULL;
+  if (encode) {
+    CHECK_STATUS("initialize", wuffs_base64__encoder__initialize(
+                                   &enc, sizeof enc, WUFFS_VERSION,
+                                   wuffs_initialize_flags));
+    t = wuffs_base64__encoder__upcast_as__wuffs_base__io_transformer(&enc);
+  } else {
+    CHECK_STATUS("initialize", wuffs_base64__decoder__initialize(
+                                   &dec, sizeof dec, WUFFS_VERSION,
+                                   wuffs_initialize_flags));
+    t = wuffs_base64__decoder__upcast_as__wuffs_base__io_transformer(&dec);
+  }
+  int i;
+  for (i = 0; i < 3; i++) {
+    if (quirks[i]) {
+      wuffs_base__io_transformer__set_quirk_enabled(t, quirks[i], true);
+    }
+  }
+
+  while (true) {
+    wuffs_base__io_buffer limited_dst = make_limited_writer(*dst, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+
+    wuffs_base__status status = wuffs_base__io_transformer__transform_io(
+        t, &limited_dst, &limited_src, g_work_slice_u8);
+
+    dst->meta.wi += limited_dst.meta.wi;
+    src->meta.ri += limited_src.meta.ri;
+
+    if (((wlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_write)) ||
+        ((rlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_read))) {
+      continue;
+    }
+    return status.repr;
+  }
+}
+
+const uint32_t g_base64_no_quirks[3] = {0};
+
+const char*  //
+wuffs_base64_decode(wuffs_base__io_buffer* dst,
+                    wuffs_base__io_buffer* src,
+                    uint32_t wuffs_initialize_flags,
+                    uint64_t wlimit,
+                    uint64_t rlimit) {
+  return do_wuffs_base64_transform(dst, src, wuffs_initialize_flags, wlimit,
+                                   rlimit, false, g_base64_no_quirks);
+}
+
+const char*  //
+wuffs_base64_encode(wuffs_base__io_buffer* dst,
+                    wuffs_base__io_buffer* src,
+                    uint32_t wuffs_initialize_flags,
+                    uint64_t wlimit,
+                    uint64_t rlimit) {
+  return do_wuffs_base64_transform(dst, src, wuffs_initialize_flags, wlimit,
+                                   rlimit, true, g_base64_no_quirks);
+}
+
+const char*  //
+test_wuffs_base64_decode_artificial() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* src;
+    const char* want_ptr;
+    size_t want_len;
+    const char* want_status;
+    uint32_t quirks[3];
+  } test_cases[] = {
+      {
+          .src = "",
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = NULL,
+      },
+      {
+          .src = "aGVsbG8=",
+          .want_ptr = "hello",
+          .want_len = 5,
+          .want_status = NULL,
+      },
+      {
+          .src = "aGVsbA==",
+          .want_ptr = "hell",
+          .want_len = 4,
+          .want_status = NULL,
+      },
+      {
+          // Bytes that need the '+' and '/' (or '-' and '_') characters.
+          .src = "+/+/",
+          .want_ptr = "\xFB\xFF\xBF",
+          .want_len = 3,
+          .want_status = NULL,
+      },
+      {
+          .src = "-_-_",
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = wuffs_base64__error__bad_character,
+      },
+      {
+          .src = "-_-_",
+          .want_ptr = "\xFB\xFF\xBF",
+          .want_len = 3,
+          .want_status = NULL,
+          .quirks = {WUFFS_BASE64__QUIRK_URL_SAFE_ALPHABET},
+      },
+      {
+          .src = "+/+/",
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = wuffs_base64__error__bad_character,
+          .quirks = {WUFFS_BASE64__QUIRK_URL_SAFE_ALPHABET},
+      },
+      {
+          .src = "aGVs\nbG8=\n",
+          .want_ptr = "hel",
+          .want_len = 3,
+          .want_status = wuffs_base64__error__bad_character,
+      },
+      {
+          .src = " aGVs\r\nbG\t8 = \n",
+          .want_ptr = "hello",
+          .want_len = 5,
+          .want_status = NULL,
+          .quirks = {WUFFS_BASE64__QUIRK_ALLOW_WHITESPACE},
+      },
+      {
+          .src = "aGVsbG8",
+          .want_ptr = "hel",
+          .want_len = 3,
+          .want_status = wuffs_base64__error__bad_padding,
+      },
+      {
+          .src = "aGVsbG8",
+          .want_ptr = "hello",
+          .want_len = 5,
+          .want_status = NULL,
+          .quirks = {WUFFS_BASE64__QUIRK_OMIT_PADDING},
+      },
+      {
+          // QUIRK_OMIT_PADDING still accepts padding.
+          .src = "aGVsbA==",
+          .want_ptr = "hell",
+          .want_len = 4,
+          .want_status = NULL,
+          .quirks = {WUFFS_BASE64__QUIRK_OMIT_PADDING},
+      },
+      {
+          .src = "aGVsbA=",
+          .want_ptr = "hell",
+          .want_len = 4,
+          .want_status = wuffs_base64__error__bad_padding,
+      },
+      {
+          .src = "aGVsbA===",
+          .want_ptr = "hell",
+          .want_len = 4,
+          .want_status = wuffs_base64__error__bad_padding,
+      },
+      {
+          .src = "aGVsb===",
+          .want_ptr = "hel",
+          .want_len = 3,
+          .want_status = wuffs_base64__error__bad_padding,
+      },
+      {
+          // Data after padding.
+          .src = "aGVsbA==aGVs",
+          .want_ptr = "hell",
+          .want_len = 4,
+          .want_status = wuffs_base64__error__bad_padding,
+      },
+      {
+          .src = "aGVsb",
+          .want_ptr = "hel",
+          .want_len = 3,
+          .want_status = wuffs_base64__error__truncated_input,
+          .quirks = {WUFFS_BASE64__QUIRK_OMIT_PADDING},
+      },
+      {
+          .src = "aGV*bG8=",
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = wuffs_base64__error__bad_character,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    int lc;
+    for (lc = 0; lc < 2; lc++) {
+      uint64_t limit = lc ? 1 : UINT64_MAX;
+      wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+          .data = g_have_slice_u8,
+      });
+      wuffs_base__io_buffer src = make_io_buffer_from_string(
+          test_cases[tc].src, strlen(test_cases[tc].src));
+      const char* status = do_wuffs_base64_transform(
+          &have, &src, WUFFS_INITIALIZE__DEFAULT_OPTIONS, limit, limit, false,
+          test_cases[tc].quirks);
+      if (status != test_cases[tc].want_status) {
+        RETURN_FAIL("tc=%d, lc=%d: status: have \"%s\", want \"%s\"", tc, lc,
+                    status, test_cases[tc].want_status);
+      }
+      wuffs_base__io_buffer want = make_io_buffer_from_string(
+          test_cases[tc].want_ptr, test_cases[tc].want_len);
+      CHECK_STRING(check_io_buffers_equal("", &have, &want));
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_base64_encode_artificial() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* src_ptr;
+    size_t src_len;
+    const char* want;
+    uint32_t quirks[3];
+  } test_cases[] = {
+      {
+          .src_ptr = "",
+          .src_len = 0,
+          .want = "",
+      },
+      {
+          .src_ptr = "h",
+          .src_len = 1,
+          .want = "aA==",
+      },
+      {
+          .src_ptr = "hell",
+          .src_len = 4,
+          .want = "aGVsbA==",
+      },
+      {
+          .src_ptr = "hello",
+          .src_len = 5,
+          .want = "aGVsbG8=",
+      },
+      {
+          .src_ptr = "hello!",
+          .src_len = 6,
+          .want = "aGVsbG8h",
+      },
+      {
+          .src_ptr = "hell",
+          .src_len = 4,
+          .want = "aGVsbA",
+          .quirks = {WUFFS_BASE64__QUIRK_OMIT_PADDING},
+      },
+      {
+          .src_ptr = "hello",
+          .src_len = 5,
+          .want = "aGVsbG8",
+          .quirks = {WUFFS_BASE64__QUIRK_OMIT_PADDING},
+      },
+      {
+          .src_ptr = "\xFB\xFF\xBF\xFF",
+          .src_len = 4,
+          .want = "+/+//w==",
+      },
+      {
+          .src_ptr = "\xFB\xFF\xBF\xFF",
+          .src_len = 4,
+          .want = "-_-__w",
+          .quirks = {WUFFS_BASE64__QUIRK_URL_SAFE_ALPHABET,
+                     WUFFS_BASE64__QUIRK_OMIT_PADDING},
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    int lc;
+    for (lc = 0; lc < 2; lc++) {
+      uint64_t limit = lc ? 1 : UINT64_MAX;
+      wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+          .data = g_have_slice_u8,
+      });
+      wuffs_base__io_buffer src = make_io_buffer_from_string(
+          test_cases[tc].src_ptr, test_cases[tc].src_len);
+      CHECK_STRING(do_wuffs_base64_transform(
+          &have, &src, WUFFS_INITIALIZE__DEFAULT_OPTIONS, limit, limit, true,
+          test_cases[tc].quirks));
+      wuffs_base__io_buffer want = make_io_buffer_from_string(
+          test_cases[tc].want, strlen(test_cases[tc].want));
+      CHECK_STRING(check_io_buffers_equal("", &have, &want));
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_base64_decode_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_base64_decode, &g_base64_midsummer_gt,
+                            UINT64_MAX, UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_base64_decode_pi_just_one_read() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_base64_decode, &g_base64_pi_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_base64_decode_pi_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_base64_decode, &g_base64_pi_gt, 59, 61);
+}
+
+const char*  //
+test_wuffs_base64_encode_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_base64_encode,
+                            &g_base64_encode_midsummer_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_base64_encode_pi_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_base64_encode, &g_base64_encode_pi_gt, 59,
+                            61);
+}
+
+// ---------------- Base64 Benches
+
+const char*  //
+bench_wuffs_base64_decode_100k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_base64_decode,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_dst,
+      &g_base64_pi_gt, UINT64_MAX, UINT64_MAX, 30);
+}
+
+const char*  //
+bench_wuffs_base64_encode_100k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_base64_encode,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_src,
+      &g_base64_encode_pi_gt, UINT64_MAX, UINT64_MAX, 30);
+}
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_base64_decode_artificial,
+    test_wuffs_base64_decode_interface,
+    test_wuffs_base64_decode_midsummer,
+    test_wuffs_base64_decode_pi_just_one_read,
+    test_wuffs_base64_decode_pi_many_small_writes_reads,
+    test_wuffs_base64_encode_artificial,
+    test_wuffs_base64_encode_interface,
+    test_wuffs_base64_encode_midsummer,
+    test_wuffs_base64_encode_pi_many_small_writes_reads,
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+    bench_wuffs_base64_decode_100k,
+    bench_wuffs_base64_encode_100k,
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/base64";
+  return test_main(argc, argv, g_tests, g_benches);
+}
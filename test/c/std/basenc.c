@@ -0,0 +1,549 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror basenc.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__CRC32
+#define WUFFS_CONFIG__MODULE__BASENC
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_basenc_base32_midsummer_gt = {
+    .want_filename = "test/data/midsummer.txt",
+    .src_filename = "test/data/midsummer.txt.base32",
+};
+
+golden_test g_basenc_base32_encode_midsummer_gt = {
+    .want_filename = "test/data/midsummer.txt.base32",
+    .src_filename = "test/data/midsummer.txt",
+};
+
+golden_test g_basenc_hex_midsummer_gt = {
+    .want_filename = "test/data/midsummer.txt",
+    .src_filename = "test/data/midsummer.txt.hex",
+};
+
+golden_test g_basenc_hex_encode_midsummer_gt = {
+    .want_filename = "test/data/midsummer.txt.hex",
+    .src_filename = "test/data/midsummer.txt",
+};
+
+// ---------------- Basenc Tests
+
+typedef enum basenc_transformer_enum {
+  basenc_base32_decoder = 0,
+  basenc_base32_encoder = 1,
+  basenc_hex_decoder = 2,
+  basenc_hex_encoder = 3,
+} basenc_transformer;
+
+const char*  //
+test_wuffs_basenc_base32_decode_interface() {
+  CHECK_FOCUS(__func__);
+  wuffs_basenc__base32_decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_basenc__base32_decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  return do_test__wuffs_base__io_transformer(
+      wuffs_basenc__base32_decoder__upcast_as__wuffs_base__io_transformer(
+          &dec),
+      "test/data/midsummer.txt.base32", 0, SIZE_MAX, 11065, 0x0A);
+}
+
+const char*  //
+test_wuffs_basenc_hex_decode_interface() {
+  CHECK_FOCUS(__func__);
+  wuffs_basenc__hex_decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_basenc__hex_decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  return do_test__wuffs_base__io_transformer(
+      wuffs_basenc__hex_decoder__upcast_as__wuffs_base__io_transformer(&dec),
+      "test/data/midsummer.txt.hex", 0, SIZE_MAX, 11065, 0x0A);
+}
+
+// do_wuffs_basenc_transform runs one of the basenc transformers with the
+// non-zero quirks enabled.
+const char*  //
+do_wuffs_basenc_transform(wuffs_base__io_buffer* dst,
+                          wuffs_base__io_buffer* src,
+                          uint32_t wuffs_initialize_flags,
+                          uint64_t wlimit,
+                          uint64_t rlimit,
+                          basenc_transformer which,
+                          const uint32_t* quirks) {
+  wuffs_basenc__base32_decoder base32_dec;
+  wuffs_basenc__base32_encoder base32_enc;
+  wuffs_basenc__hex_decoder hex_dec;
+  wuffs_basenc__hex_encoder hex_enc;
+  wuffs_base__io_transformer* t = NULL;
+  switch (which) {
+    case basenc_base32_decoder:
+      CHECK_STATUS("initialize", wuffs_basenc__base32_decoder__initialize(
+                                     &base32_dec, sizeof base32_dec,
+                                     WUFFS_VERSION, wuffs_initialize_flags));
+      t = wuffs_basenc__base32_decoder__upcast_as__wuffs_base__io_transformer(
+          &base32_dec);
+      break;
+    case basenc_base32_encoder:
+      CHECK_STATUS("initialize", wuffs_basenc__base32_encoder__initialize(
+                                     &base32_enc, sizeof base32_enc,
+                                     WUFFS_VERSION, wuffs_initialize_flags));
+      t = wuffs_basenc__base32_encoder__upcast_as__wuffs_base__io_transformer(
+          &base32_enc);
+      break;
+    case basenc_hex_decoder:
+      CHECK_STATUS("initialize", wuffs_basenc__hex_decoder__initialize(
+                                     &hex_dec, sizeof hex_dec, WUFFS_VERSION,
+                                     wuffs_initialize_flags));
+      t = wuffs_basenc__hex_decoder__upcast_as__wuffs_base__io_transformer(
+          &hex_dec);
+      break;
+    case basenc_hex_encoder:
+      CHECK_STATUS("initialize", wuffs_basenc__hex_encoder__initialize(
+                                     &hex_enc, sizeof hex_enc, WUFFS_VERSION,
+                                     wuffs_initialize_flags));
+      t = wuffs_basenc__hex_encoder__upcast_as__wuffs_base__io_transformer(
+          &hex_enc);
+      break;
+  }
+  int i;
+  for (i = 0; i < 2; i++) {
+    if (quirks[i]) {
+      wuffs_base__io_transformer__set_quirk_enabled(t, quirks[i], true);
+    }
+  }
+
+  while (true) {
+    wuffs_base__io_buffer limited_dst = make_limited_writer(*dst, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+
+    wuffs_base__status status = wuffs_base__io_transformer__transform_io(
+        t, &limited_dst, &limited_src, g_work_slice_u8);
+
+    dst->meta.wi += limited_dst.meta.wi;
+    src->meta.ri += limited_src.meta.ri;
+
+    if (((wlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_write)) ||
+        ((rlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_read))) {
+      continue;
+    }
+    return status.repr;
+  }
+}
+
+const uint32_t g_basenc_no_quirks[2] = {0};
+
+const char*  //
+wuffs_basenc_base32_decode(wuffs_base__io_buffer* dst,
+                           wuffs_base__io_buffer* src,
+                           uint32_t wuffs_initialize_flags,
+                           uint64_t wlimit,
+                           uint64_t rlimit) {
+  return do_wuffs_basenc_transform(dst, src, wuffs_initialize_flags, wlimit,
+                                   rlimit, basenc_base32_decoder,
+                                   g_basenc_no_quirks);
+}
+
+const char*  //
+wuffs_basenc_base32_encode(wuffs_base__io_buffer* dst,
+                           wuffs_base__io_buffer* src,
+                           uint32_t wuffs_initialize_flags,
+                           uint64_t wlimit,
+                           uint64_t rlimit) {
+  return do_wuffs_basenc_transform(dst, src, wuffs_initialize_flags, wlimit,
+                                   rlimit, basenc_base32_encoder,
+                                   g_basenc_no_quirks);
+}
+
+const char*  //
+wuffs_basenc_hex_decode(wuffs_base__io_buffer* dst,
+                        wuffs_base__io_buffer* src,
+                        uint32_t wuffs_initialize_flags,
+                        uint64_t wlimit,
+                        uint64_t rlimit) {
+  return do_wuffs_basenc_transform(dst, src, wuffs_initialize_flags, wlimit,
+                                   rlimit, basenc_hex_decoder,
+                                   g_basenc_no_quirks);
+}
+
+const char*  //
+wuffs_basenc_hex_encode(wuffs_base__io_buffer* dst,
+                        wuffs_base__io_buffer* src,
+                        uint32_t wuffs_initialize_flags,
+                        uint64_t wlimit,
+                        uint64_t rlimit) {
+  return do_wuffs_basenc_transform(dst, src, wuffs_initialize_flags, wlimit,
+                                   rlimit, basenc_hex_encoder,
+                                   g_basenc_no_quirks);
+}
+
+const char*  //
+test_wuffs_basenc_artificial() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    basenc_transformer which;
+    const char* src_ptr;
+    size_t src_len;
+    const char* want_ptr;
+    size_t want_len;
+    const char* want_status;
+    uint32_t quirks[2];
+  } test_cases[] = {
+      {
+          .which = basenc_base32_encoder,
+          .src_ptr = "hello",
+          .src_len = 5,
+          .want_ptr = "NBSWY3DP",
+          .want_len = 8,
+      },
+      {
+          .which = basenc_base32_encoder,
+          .src_ptr = "hello world",
+          .src_len = 11,
+          .want_ptr = "NBSWY3DPEB3W64TMMQ======",
+          .want_len = 24,
+      },
+      {
+          .which = basenc_base32_encoder,
+          .src_ptr = "hello world",
+          .src_len = 11,
+          .want_ptr = "D1IMOR3F41RMUSJCCG",
+          .want_len = 18,
+          .quirks = {WUFFS_BASENC__QUIRK_BASE32_HEX_ALPHABET,
+                     WUFFS_BASENC__QUIRK_OMIT_PADDING},
+      },
+      {
+          .which = basenc_base32_decoder,
+          .src_ptr = "NBSWY3DPEB3W64TMMQ======",
+          .src_len = 24,
+          .want_ptr = "hello world",
+          .want_len = 11,
+      },
+      {
+          .which = basenc_base32_decoder,
+          .src_ptr = "NBSWY3A=",
+          .src_len = 8,
+          .want_ptr = "hell",
+          .want_len = 4,
+      },
+      {
+          .which = basenc_base32_decoder,
+          .src_ptr = "NBSW\nY===\n",
+          .src_len = 10,
+          .want_ptr = "hel",
+          .want_len = 3,
+          .quirks = {WUFFS_BASENC__QUIRK_ALLOW_WHITESPACE},
+      },
+      {
+          .which = basenc_base32_decoder,
+          .src_ptr = "D1IMOR3F41RMUSJCCG",
+          .src_len = 18,
+          .want_ptr = "hello world",
+          .want_len = 11,
+          .quirks = {WUFFS_BASENC__QUIRK_BASE32_HEX_ALPHABET,
+                     WUFFS_BASENC__QUIRK_OMIT_PADDING},
+      },
+      {
+          .which = basenc_base32_decoder,
+          .src_ptr = "nbswy3dp",
+          .src_len = 8,
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = wuffs_basenc__error__bad_character,
+      },
+      {
+          .which = basenc_base32_decoder,
+          .src_ptr = "NBSWY3A",
+          .src_len = 7,
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = wuffs_basenc__error__bad_padding,
+      },
+      {
+          // Only 5 of the 6 padding bytes.
+          .which = basenc_base32_decoder,
+          .src_ptr = "NBSWY3DPEB3W64TMMQ=====",
+          .src_len = 23,
+          .want_ptr = "hello world",
+          .want_len = 11,
+          .want_status = wuffs_basenc__error__bad_padding,
+      },
+      {
+          // A partial group of 3 5-bit values is never valid.
+          .which = basenc_base32_decoder,
+          .src_ptr = "NBS=====",
+          .src_len = 8,
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = wuffs_basenc__error__bad_padding,
+      },
+      {
+          .which = basenc_base32_decoder,
+          .src_ptr = "NBS",
+          .src_len = 3,
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = wuffs_basenc__error__truncated_input,
+          .quirks = {WUFFS_BASENC__QUIRK_OMIT_PADDING},
+      },
+      {
+          .which = basenc_hex_encoder,
+          .src_ptr = "\x00\x7F\xAB\xFF",
+          .src_len = 4,
+          .want_ptr = "007fabff",
+          .want_len = 8,
+      },
+      {
+          .which = basenc_hex_encoder,
+          .src_ptr = "\x00\x7F\xAB\xFF",
+          .src_len = 4,
+          .want_ptr = "007FABFF",
+          .want_len = 8,
+          .quirks = {WUFFS_BASENC__QUIRK_HEX_UPPER_CASE},
+      },
+      {
+          .which = basenc_hex_decoder,
+          .src_ptr = "007fABff",
+          .src_len = 8,
+          .want_ptr = "\x00\x7F\xAB\xFF",
+          .want_len = 4,
+      },
+      {
+          .which = basenc_hex_decoder,
+          .src_ptr = "00 7f\r\nAB f\tf",
+          .src_len = 13,
+          .want_ptr = "\x00\x7F\xAB\xFF",
+          .want_len = 4,
+          .quirks = {WUFFS_BASENC__QUIRK_ALLOW_WHITESPACE},
+      },
+      {
+          .which = basenc_hex_decoder,
+          .src_ptr = "00 7f",
+          .src_len = 5,
+          .want_ptr = "\x00",
+          .want_len = 1,
+          .want_status = wuffs_basenc__error__bad_character,
+      },
+      {
+          .which = basenc_hex_decoder,
+          .src_ptr = "007g",
+          .src_len = 4,
+          .want_ptr = "\x00",
+          .want_len = 1,
+          .want_status = wuffs_basenc__error__bad_character,
+      },
+      {
+          .which = basenc_hex_decoder,
+          .src_ptr = "007",
+          .src_len = 3,
+          .want_ptr = "\x00",
+          .want_len = 1,
+          .want_status = wuffs_basenc__error__truncated_input,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    int lc;
+    for (lc = 0; lc < 2; lc++) {
+      uint64_t limit = lc ? 1 : UINT64_MAX;
+      wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+          .data = g_have_slice_u8,
+      });
+      wuffs_base__io_buffer src = make_io_buffer_from_string(
+          test_cases[tc].src_ptr, test_cases[tc].src_len);
+      const char* status = do_wuffs_basenc_transform(
+          &have, &src, WUFFS_INITIALIZE__DEFAULT_OPTIONS, limit, limit,
+          test_cases[tc].which, test_cases[tc].quirks);
+      if (status != test_cases[tc].want_status) {
+        RETURN_FAIL("tc=%d, lc=%d: status: have \"%s\", want \"%s\"", tc, lc,
+                    status, test_cases[tc].want_status);
+      }
+      wuffs_base__io_buffer want = make_io_buffer_from_string(
+          test_cases[tc].want_ptr, test_cases[tc].want_len);
+      CHECK_STRING(check_io_buffers_equal("", &have, &want));
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_basenc_base32_decode_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_basenc_base32_decode,
+                            &g_basenc_base32_midsummer_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_basenc_base32_decode_midsummer_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_basenc_base32_decode,
+                            &g_basenc_base32_midsummer_gt, 59, 61);
+}
+
+const char*  //
+test_wuffs_basenc_base32_encode_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_basenc_base32_encode,
+                            &g_basenc_base32_encode_midsummer_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_basenc_base32_encode_midsummer_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_basenc_base32_encode,
+                            &g_basenc_base32_encode_midsummer_gt, 59, 61);
+}
+
+const char*  //
+test_wuffs_basenc_hex_decode_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_basenc_hex_decode,
+                            &g_basenc_hex_midsummer_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_basenc_hex_decode_midsummer_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_basenc_hex_decode,
+                            &g_basenc_hex_midsummer_gt, 59, 61);
+}
+
+const char*  //
+test_wuffs_basenc_hex_encode_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_basenc_hex_encode,
+                            &g_basenc_hex_encode_midsummer_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_basenc_hex_encode_midsummer_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_basenc_hex_encode,
+                            &g_basenc_hex_encode_midsummer_gt, 59, 61);
+}
+
+// ---------------- Basenc Benches
+
+const char*  //
+bench_wuffs_basenc_base32_decode_10k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_basenc_base32_decode,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_dst,
+      &g_basenc_base32_midsummer_gt, UINT64_MAX, UINT64_MAX, 300);
+}
+
+const char*  //
+bench_wuffs_basenc_hex_decode_10k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_basenc_hex_decode,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_dst,
+      &g_basenc_hex_midsummer_gt, UINT64_MAX, UINT64_MAX, 300);
+}
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_basenc_artificial,
+    test_wuffs_basenc_base32_decode_interface,
+    test_wuffs_basenc_base32_decode_midsummer,
+    test_wuffs_basenc_base32_decode_midsummer_many_small_writes_reads,
+    test_wuffs_basenc_base32_encode_midsummer,
+    test_wuffs_basenc_base32_encode_midsummer_many_small_writes_reads,
+    test_wuffs_basenc_hex_decode_interface,
+    test_wuffs_basenc_hex_decode_midsummer,
+    test_wuffs_basenc_hex_decode_midsummer_many_small_writes_reads,
+    test_wuffs_basenc_hex_encode_midsummer,
+    test_wuffs_basenc_hex_encode_midsummer_many_small_writes_reads,
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+    bench_wuffs_basenc_base32_decode_10k,
+    bench_wuffs_basenc_hex_decode_10k,
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/basenc";
+  return test_main(argc, argv, g_tests, g_benches);
+}
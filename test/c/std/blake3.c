@@ -0,0 +1,285 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror blake3.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__BLAKE3
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_blake3_midsummer_gt = {
+    .src_filename = "test/data/midsummer.txt",
+};
+
+golden_test g_blake3_pi_gt = {
+    .src_filename = "test/data/pi.txt",
+};
+
+// ---------------- BLAKE3 Tests
+
+// hex_checksum writes the lower-case hexadecimal form of h's checksum (so far)
+// to dst, which must have room for 65 bytes, including the NUL terminator.
+const char*  //
+hex_checksum(char* dst, wuffs_blake3__hasher* h) {
+  uint8_t checksum[32];
+  uint64_t n = wuffs_blake3__hasher__checksum(
+      h, wuffs_base__make_slice_u8(checksum, sizeof checksum));
+  if (n != sizeof checksum) {
+    RETURN_FAIL("checksum: have %" PRIu64 ", want %d", n,
+                (int)(sizeof checksum));
+  }
+  int i;
+  for (i = 0; i < 32; i++) {
+    dst[(2 * i) + 0] = "0123456789abcdef"[checksum[i] >> 4];
+    dst[(2 * i) + 1] = "0123456789abcdef"[checksum[i] & 15];
+  }
+  dst[64] = '\x00';
+  return NULL;
+}
+
+const char*  //
+test_wuffs_blake3_short_dst() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_blake3__hasher checksum;
+  CHECK_STATUS("initialize",
+               wuffs_blake3__hasher__initialize(
+                   &checksum, sizeof checksum, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_blake3__hasher__update(&checksum, ((wuffs_base__slice_u8){
+                                              .ptr = (uint8_t*)("abc"),
+                                              .len = 3,
+                                          }));
+
+  uint8_t have[5] = {0};
+  uint64_t n = wuffs_blake3__hasher__checksum(
+      &checksum, wuffs_base__make_slice_u8(have, sizeof have));
+  if (n != sizeof have) {
+    RETURN_FAIL("checksum: have %" PRIu64 ", want %d", n, (int)(sizeof have));
+  }
+  const uint8_t want[5] = {0x64, 0x37, 0xB3, 0xAC, 0x38};
+  if (memcmp(have, want, sizeof have)) {
+    RETURN_FAIL("checksum: have 0x%02X%02X%02X%02X%02X, want 0x6437B3AC38",
+                have[0], have[1], have[2], have[3], have[4]);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_blake3_vectors() {
+  CHECK_FOCUS(__func__);
+
+  // These test vectors are from the BLAKE3 reference implementation's
+  // test_vectors.json, truncated to the default 32 byte output. The input is
+  // the first length bytes of the repeating sequence 0, 1, 2, ..., 250. The
+  // lengths straddle the 1024 byte chunk boundaries, exercising the tree of
+  // chunk chaining values.
+  struct {
+    size_t length;
+    const char* want;
+  } test_cases[] = {
+      {0, "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+      {1, "2d3adedff11b61f14c886e35afa036736dcd87a74d27b5c1510225d0f592e213"},
+      {1023, "10108970eeda3eb932baac1428c7a2163b0e924c9a9e25b35bba72b28f70bd11"},
+      {1024, "42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7"},
+      {1025, "d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444"},
+      {2048, "e776b6028c7cd22a4d0ba182a8bf62205d2ef576467e838ed6f2529b85fba24a"},
+      {2049, "5f4d72f40d7a5f82b15ca2b2e44b1de3c2ef86c426c95c1af0b6879522563030"},
+      {3072, "b98cb0ff3623be03326b373de6b9095218513e64f1ee2edd2525c7ad1e5cffd2"},
+      {3073, "7124b49501012f81cc7f11ca069ec9226cecb8a2c850cfe644e327d22d3e1cd3"},
+      {4096, "015094013f57a5277b59d8475c0501042c0b642e531b0a1c8f58d2163229e969"},
+      {4097, "9b4052b38f1c5fc8b1f9ff7ac7b27cd242487b3d890d15c96a1c25b8aa0fb995"},
+      {5120, "9cadc15fed8b5d854562b26a9536d9707cadeda9b143978f319ab34230535833"},
+      {5121, "628bd2cb2004694adaab7bbd778a25df25c47b9d4155a55f8fbd79f2fe154cff"},
+      {6144, "3e2e5b74e048f3add6d21faab3f83aa44d3b2278afb83b80b3c35164ebeca205"},
+      {6145, "f1323a8631446cc50536a9f705ee5cb619424d46887f3c376c695b70e0f0507f"},
+      {7168, "61da957ec2499a95d6b8023e2b0e604ec7f6b50e80a9678b89d2628e99ada77a"},
+      {7169, "a003fc7a51754a9b3c7fae0367ab3d782dccf28855a03d435f8cfe74605e7817"},
+      {8192, "aae792484c8efe4f19e2ca7d371d8c467ffb10748d8a5a1ae579948f718a2a63"},
+      {8193, "bab6c09cb8ce8cf459261398d2e7aef35700bf488116ceb94a36d0f5f1b7bc3b"},
+      {16384, "f875d6646de28985646f34ee13be9a576fd515f76b5b0a26bb324735041ddde4"},
+      {31744, "62b6960e1a44bcc1eb1a611a8d6235b6b4b78f32e7abc4fb4c6cdcce94895c47"},
+  };
+
+  wuffs_base__slice_u8 src = g_src_slice_u8;
+  if (src.len < 31744) {
+    RETURN_FAIL("src.len: have %zu, want >= 31744", src.len);
+  }
+  size_t i;
+  for (i = 0; i < 31744; i++) {
+    src.ptr[i] = (uint8_t)(i % 251);
+  }
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    int j;
+    for (j = 0; j < 2; j++) {
+      wuffs_blake3__hasher checksum;
+      CHECK_STATUS("initialize",
+                   wuffs_blake3__hasher__initialize(
+                       &checksum, sizeof checksum, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+      char have[65];
+      size_t num_fragments = 0;
+      size_t num_bytes = 0;
+      do {
+        wuffs_base__slice_u8 data = ((wuffs_base__slice_u8){
+            .ptr = src.ptr + num_bytes,
+            .len = test_cases[tc].length - num_bytes,
+        });
+        size_t limit = 101 + 103 * num_fragments;
+        if ((j > 0) && (data.len > limit)) {
+          data.len = limit;
+        }
+        wuffs_blake3__hasher__update(&checksum, data);
+        // Computing an intermediate checksum should not affect the final one.
+        CHECK_STRING(hex_checksum(have, &checksum));
+        num_fragments++;
+        num_bytes += data.len;
+      } while (num_bytes < test_cases[tc].length);
+
+      CHECK_STRING(hex_checksum(have, &checksum));
+      if (strcmp(have, test_cases[tc].want)) {
+        RETURN_FAIL("tc=%d, j=%d, length=%zu: have \"%s\", want \"%s\"", tc,
+                    j, test_cases[tc].length, have, test_cases[tc].want);
+      }
+    }
+  }
+  return NULL;
+}
+
+// ---------------- BLAKE3 Benches
+
+uint8_t g_wuffs_blake3_unused_checksum[32];
+
+const char*  //
+wuffs_bench_blake3(wuffs_base__io_buffer* dst,
+                   wuffs_base__io_buffer* src,
+                   uint32_t wuffs_initialize_flags,
+                   uint64_t wlimit,
+                   uint64_t rlimit) {
+  uint64_t len = src->meta.wi - src->meta.ri;
+  if (rlimit) {
+    len = wuffs_base__u64__min(len, rlimit);
+  }
+  wuffs_blake3__hasher checksum;
+  CHECK_STATUS("initialize", wuffs_blake3__hasher__initialize(
+                                 &checksum, sizeof checksum, WUFFS_VERSION,
+                                 wuffs_initialize_flags));
+  wuffs_blake3__hasher__update(&checksum,
+                               ((wuffs_base__slice_u8){
+                                   .ptr = src->data.ptr + src->meta.ri,
+                                   .len = len,
+                               }));
+  wuffs_blake3__hasher__checksum(
+      &checksum,
+      wuffs_base__make_slice_u8(g_wuffs_blake3_unused_checksum,
+                                sizeof g_wuffs_blake3_unused_checksum));
+  src->meta.ri += len;
+  return NULL;
+}
+
+const char*  //
+bench_wuffs_blake3_10k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_bench_blake3,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_src,
+      &g_blake3_midsummer_gt, UINT64_MAX, UINT64_MAX, 300);
+}
+
+const char*  //
+bench_wuffs_blake3_100k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_bench_blake3,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_src,
+      &g_blake3_pi_gt, UINT64_MAX, UINT64_MAX, 30);
+}
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_blake3_short_dst,
+    test_wuffs_blake3_vectors,
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+    bench_wuffs_blake3_10k,
+    bench_wuffs_blake3_100k,
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/blake3";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -179,6 +179,124 @@ test_wuffs_bmp_decode_io_redirect() {
   return NULL;
 }
 
+const char*  //
+do_test_wuffs_bmp_decode_row_groups(wuffs_base__io_buffer src,
+                                    uint32_t row_group_height) {
+  wuffs_bmp__decoder full;
+  CHECK_STATUS("initialize (full)",
+               wuffs_bmp__decoder__initialize(
+                   &full, sizeof full, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_bmp__decoder grouped;
+  CHECK_STATUS("initialize (grouped)",
+               wuffs_bmp__decoder__initialize(
+                   &grouped, sizeof grouped, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  return do_test__wuffs_base__image_decoder__row_groups_src(
+      wuffs_bmp__decoder__upcast_as__wuffs_base__image_decoder(&full),
+      wuffs_bmp__decoder__upcast_as__wuffs_base__image_decoder(&grouped), src,
+      row_group_height);
+}
+
+const char*  //
+test_wuffs_bmp_decode_row_groups() {
+  CHECK_FOCUS(__func__);
+  const char* filenames[] = {
+      "test/data/hat.bmp",
+      "test/data/hibiscus.primitive.bmp",
+      "test/data/hippopotamus.bmp",
+      "test/data/pjw-thumbnail.bmp",
+  };
+  const uint32_t row_group_heights[] = {1, 7, 20, 100};
+  size_t i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(filenames); i++) {
+    wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+        .data = g_src_slice_u8,
+    });
+    CHECK_STRING(read_file(&src, filenames[i]));
+
+    // Swap any BITFIELDS file's red and blue channel masks. The decoder
+    // then can't treat the pixels as plain BGRA and takes its bitfields path.
+    if ((src.meta.wi >= 0x42) &&
+        (wuffs_base__peek_u32le__no_bounds_check(src.data.ptr + 0x1E) == 3)) {
+      uint32_t red =
+          wuffs_base__peek_u32le__no_bounds_check(src.data.ptr + 0x36);
+      uint32_t blue =
+          wuffs_base__peek_u32le__no_bounds_check(src.data.ptr + 0x3E);
+      wuffs_base__poke_u32le__no_bounds_check(src.data.ptr + 0x36, blue);
+      wuffs_base__poke_u32le__no_bounds_check(src.data.ptr + 0x3E, red);
+    }
+
+    // These files are all bottom-up. Also make a top-down copy of each one,
+    // by negating its height and reversing its rows.
+    wuffs_base__io_buffer top_down = ((wuffs_base__io_buffer){
+        .data = wuffs_base__make_slice_u8(g_src_array_u8 + src.meta.wi,
+                                          src.meta.wi),
+    });
+    memcpy(top_down.data.ptr, src.data.ptr, src.meta.wi);
+    top_down.meta.wi = src.meta.wi;
+    top_down.meta.closed = true;
+    uint8_t* p = top_down.data.ptr;
+    uint32_t offset = wuffs_base__peek_u32le__no_bounds_check(p + 0x0A);
+    uint32_t width = wuffs_base__peek_u32le__no_bounds_check(p + 0x12);
+    uint32_t height = wuffs_base__peek_u32le__no_bounds_check(p + 0x16);
+    uint32_t bpp = wuffs_base__peek_u16le__no_bounds_check(p + 0x1C);
+    size_t stride = (((width * bpp) + 31) / 32) * 4;
+    if ((height == 0) || (height > 0x7FFFFFFF) ||
+        (offset > src.meta.wi) ||
+        ((height * stride) > (src.meta.wi - offset))) {
+      RETURN_FAIL("%s: unexpected header", filenames[i]);
+    }
+    wuffs_base__poke_u32le__no_bounds_check(p + 0x16, 0u - height);
+    uint32_t y;
+    for (y = 0; y < (height / 2); y++) {
+      uint8_t* row0 = p + offset + (stride * y);
+      uint8_t* row1 = p + offset + (stride * (height - 1 - y));
+      size_t x;
+      for (x = 0; x < stride; x++) {
+        uint8_t c = row0[x];
+        row0[x] = row1[x];
+        row1[x] = c;
+      }
+    }
+
+    size_t j;
+    for (j = 0; j < WUFFS_TESTLIB_ARRAY_SIZE(row_group_heights); j++) {
+      CHECK_STRING(
+          do_test_wuffs_bmp_decode_row_groups(src, row_group_heights[j]));
+      CHECK_STRING(
+          do_test_wuffs_bmp_decode_row_groups(top_down, row_group_heights[j]));
+    }
+  }
+
+  // Row groups are unsupported for RLE compression.
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/bricks-dither.bmp"));
+  wuffs_bmp__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_bmp__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config",
+               wuffs_bmp__decoder__decode_image_config(&dec, &ic, &src));
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     &pb, &ic.pixcfg, g_pixel_slice_u8));
+  wuffs_base__decode_frame_options opts =
+      wuffs_base__null_decode_frame_options();
+  wuffs_base__decode_frame_options__set_row_group_height(&opts, 5);
+  wuffs_base__status status = wuffs_bmp__decoder__decode_frame(
+      &dec, &pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC, g_work_slice_u8, &opts);
+  if (status.repr != wuffs_base__error__unsupported_option) {
+    RETURN_FAIL("decode_frame (RLE): have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__error__unsupported_option);
+  }
+  return NULL;
+}
+
 // ---------------- Mimic Tests
 
 #ifdef WUFFS_MIMIC
@@ -213,6 +331,7 @@ proc g_tests[] = {
     test_wuffs_bmp_decode_frame_config,
     test_wuffs_bmp_decode_interface,
     test_wuffs_bmp_decode_io_redirect,
+    test_wuffs_bmp_decode_row_groups,
 
 #ifdef WUFFS_MIMIC
 
@@ -0,0 +1,256 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror bzip2.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__BZIP2
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_bzip2_pi_gt = {
+    .src_filename = "test/data/pi.txt",
+};
+
+// ---------------- BZIP2 Tests
+
+const char*  //
+test_wuffs_bzip2_encode_interface() {
+  CHECK_FOCUS(__func__);
+  // The encoder is too large to comfortably live on the stack.
+  wuffs_bzip2__encoder* enc = wuffs_bzip2__encoder__alloc();
+  if (!enc) {
+    RETURN_FAIL("alloc failed");
+  }
+  const char* ret = do_test__wuffs_base__io_transformer(
+      wuffs_bzip2__encoder__upcast_as__wuffs_base__io_transformer(enc),
+      "test/data/romeo.txt", 0, SIZE_MAX, 566, 0x00);
+  free(enc);
+  return ret;
+}
+
+const char*  //
+wuffs_bzip2_encode(wuffs_base__io_buffer* dst,
+                   wuffs_base__io_buffer* src,
+                   uint32_t wuffs_initialize_flags,
+                   uint64_t wlimit,
+                   uint64_t rlimit) {
+  wuffs_bzip2__encoder* enc = wuffs_bzip2__encoder__alloc();
+  if (!enc) {
+    return "alloc failed";
+  }
+  const char* ret = NULL;
+
+  while (true) {
+    wuffs_base__io_buffer limited_dst = make_limited_writer(*dst, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+
+    wuffs_base__status status = wuffs_bzip2__encoder__transform_io(
+        enc, &limited_dst, &limited_src, g_work_slice_u8);
+
+    dst->meta.wi += limited_dst.meta.wi;
+    src->meta.ri += limited_src.meta.ri;
+
+    if (((wlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_write)) ||
+        ((rlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_read))) {
+      continue;
+    }
+    ret = status.repr;
+    break;
+  }
+
+  free(enc);
+  return ret;
+}
+
+const char*  //
+test_wuffs_bzip2_encode_empty() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  src.meta.closed = true;
+
+  // The "BZh9" stream header, the end-of-stream magic and a zero CRC.
+  static const uint8_t want_array[14] = {
+      0x42, 0x5A, 0x68, 0x39, 0x17, 0x72, 0x45,
+      0x38, 0x50, 0x90, 0x00, 0x00, 0x00, 0x00,
+  };
+  wuffs_base__io_buffer want = wuffs_base__ptr_u8__reader(
+      (uint8_t*)want_array, sizeof want_array, true);
+
+  CHECK_STRING(wuffs_bzip2_encode(&have, &src, 0, UINT64_MAX, UINT64_MAX));
+  return check_io_buffers_equal("", &have, &want);
+}
+
+const char*  //
+do_test_wuffs_bzip2_encode(wuffs_base__io_buffer src,
+                           size_t want_wi,
+                           uint8_t want_final_byte) {
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  CHECK_STRING(wuffs_bzip2_encode(&have, &src, 0, UINT64_MAX, UINT64_MAX));
+  if (have.meta.wi != want_wi) {
+    RETURN_FAIL("dst wi: have %zu, want %zu", have.meta.wi, want_wi);
+  } else if (have.data.ptr[have.meta.wi - 1] != want_final_byte) {
+    RETURN_FAIL("final byte: have 0x%02X, want 0x%02X",
+                have.data.ptr[have.meta.wi - 1], want_final_byte);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_bzip2_encode_long_runs() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  // Runs of every length from 1 to 600, which exercises the initial RLE1
+  // run-length encoding's 4-plus-count form and its 255 byte limit.
+  uint32_t i;
+  for (i = 1; i <= 600; i++) {
+    memset(src.data.ptr + src.meta.wi, 'a' + (i % 26), i);
+    src.meta.wi += i;
+  }
+  src.meta.closed = true;
+  return do_test_wuffs_bzip2_encode(src, 1536, 0xB0);
+}
+
+const char*  //
+test_wuffs_bzip2_encode_multiple_blocks() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  // 2 MiB of pseudo-random text spans three 900k blocks.
+  uint32_t x = 1;
+  for (src.meta.wi = 0; src.meta.wi < 0x200000; src.meta.wi++) {
+    x = (x * 1103515245) + 12345;
+    src.data.ptr[src.meta.wi] = (uint8_t)('a' + ((x >> 16) % 7));
+  }
+  src.meta.closed = true;
+  return do_test_wuffs_bzip2_encode(src, 772993, 0x51);
+}
+
+const char*  //
+test_wuffs_bzip2_encode_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/pi.txt"));
+
+  // The output should not depend on the I/O buffer sizes.
+  CHECK_STRING(wuffs_bzip2_encode(&want, &src, 0, UINT64_MAX, UINT64_MAX));
+  src.meta.ri = 0;
+  CHECK_STRING(wuffs_bzip2_encode(&have, &src, 0, 41, 43));
+  return check_io_buffers_equal("", &have, &want);
+}
+
+// ---------------- BZIP2 Benches
+
+const char*  //
+bench_wuffs_bzip2_encode_100k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(wuffs_bzip2_encode, 0, tcounter_src,
+                             &g_bzip2_pi_gt, UINT64_MAX, UINT64_MAX, 10);
+}
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_bzip2_encode_empty,
+    test_wuffs_bzip2_encode_interface,
+    test_wuffs_bzip2_encode_long_runs,
+    test_wuffs_bzip2_encode_many_small_writes_reads,
+    test_wuffs_bzip2_encode_multiple_blocks,
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+    bench_wuffs_bzip2_encode_100k,
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/bzip2";
+  return test_main(argc, argv, g_tests, g_benches);
+}
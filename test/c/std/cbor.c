@@ -76,6 +76,182 @@ golden_test g_cbor_cbor_rfc_7049_examples_gt = {
 
 // ---------------- CBOR Tests
 
+const char*  //
+test_wuffs_cbor_decode_bignums() {
+  CHECK_FOCUS(__func__);
+
+  // Each test case is a tag 2 or tag 3 byte string of the given length. The
+  // 70000 byte cases are longer than any single token's length. The src is
+  // read 9 bytes at a time (and the dst written 2 tokens at a time) or, for a
+  // zero src_chunk_len, all at once (which, with the decoder's closed src
+  // fast path enabled, exercises that path).
+  const struct {
+    uint8_t tag;
+    uint32_t length;
+    size_t src_chunk_len;
+  } test_cases[] = {
+      {.tag = 2, .length = 0, .src_chunk_len = 0},      //
+      {.tag = 3, .length = 1, .src_chunk_len = 0},      //
+      {.tag = 2, .length = 5, .src_chunk_len = 9},      //
+      {.tag = 2, .length = 6, .src_chunk_len = 9},      //
+      {.tag = 3, .length = 9, .src_chunk_len = 9},      //
+      {.tag = 2, .length = 23, .src_chunk_len = 0},     //
+      {.tag = 2, .length = 23, .src_chunk_len = 9},     //
+      {.tag = 2, .length = 300, .src_chunk_len = 9},    //
+      {.tag = 3, .length = 70000, .src_chunk_len = 0},  //
+      {.tag = 3, .length = 70000, .src_chunk_len = 9},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    uint32_t length = test_cases[tc].length;
+    uint8_t* s = g_src_slice_u8.ptr;
+    size_t header_length = 1;
+    *s++ = 0xC0 | test_cases[tc].tag;
+    if (length < 24) {
+      *s++ = 0x40 | length;
+    } else if (length < 0x10000) {
+      *s++ = 0x59;
+      wuffs_base__poke_u16be__no_bounds_check(s, length);
+      s += 2;
+      header_length = 3;
+    } else {
+      *s++ = 0x5A;
+      wuffs_base__poke_u32be__no_bounds_check(s, length);
+      s += 4;
+      header_length = 5;
+    }
+    uint8_t* data = s;
+    uint32_t i;
+    for (i = 0; i < length; i++) {
+      *s++ = (uint8_t)((i * 37) + 11);
+    }
+    size_t src_len = (size_t)(s - g_src_slice_u8.ptr);
+    size_t src_chunk_len = test_cases[tc].src_chunk_len
+                               ? test_cases[tc].src_chunk_len
+                               : src_len;
+    size_t dst_chunk_len =
+        test_cases[tc].src_chunk_len ? 2 : g_have_slice_token.len;
+
+    wuffs_cbor__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_cbor__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_cbor__decoder__set_quirk_enabled(
+        &dec, WUFFS_CBOR__QUIRK_DECODE_BIGNUMS_AS_INLINE_INTEGERS, true);
+    wuffs_base__token_buffer tok_buf = ((wuffs_base__token_buffer){
+        .data = g_have_slice_token,
+    });
+    wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+        .data = g_src_slice_u8,
+    });
+    while (true) {
+      src.meta.wi = wuffs_base__u64__min(src.meta.ri + src_chunk_len, src_len);
+      src.meta.closed = src.meta.wi == src_len;
+      tok_buf.data.len = wuffs_base__u64__min(tok_buf.meta.wi + dst_chunk_len,
+                                              g_have_slice_token.len);
+      wuffs_base__status status = wuffs_cbor__decoder__decode_tokens(
+          &dec, &tok_buf, &src, g_work_slice_u8);
+      if (wuffs_base__status__is_ok(&status)) {
+        break;
+      } else if ((status.repr != wuffs_base__suspension__short_read) &&
+                 (status.repr != wuffs_base__suspension__short_write)) {
+        RETURN_FAIL("tc=%d: decode_tokens: \"%s\"", tc, status.repr);
+      }
+    }
+
+    // The tag token is followed by a simple token and then, since a 46-bit
+    // value_extension is less than 6 bytes, about 1.2 extended tokens per 8
+    // bytes.
+    const wuffs_base__token* t = g_have_slice_token.ptr;
+    size_t num_ext = ((8 * (size_t)length) + 45) / 46;
+    if (tok_buf.meta.wi != (2 + num_ext)) {
+      RETURN_FAIL("tc=%d: num_tokens: have %zu, want %zu", tc,
+                  tok_buf.meta.wi, 2 + num_ext);
+    } else if ((wuffs_base__token__value_major(&t[0]) !=
+                WUFFS_CBOR__TOKEN_VALUE_MAJOR) ||
+               (wuffs_base__token__value_minor(&t[0]) !=
+                (WUFFS_CBOR__TOKEN_VALUE_MINOR__TAG | test_cases[tc].tag))) {
+      RETURN_FAIL("tc=%d: tag token: have 0x%016" PRIX64, tc, t[0].repr);
+    } else if ((wuffs_base__token__value_base_category(&t[1]) !=
+                WUFFS_BASE__TOKEN__VBC__INLINE_INTEGER_UNSIGNED) ||
+               (wuffs_base__token__value_base_detail(&t[1]) != 0) ||
+               (wuffs_base__token__continued(&t[1]) != (length > 0)) ||
+               (wuffs_base__token__length(&t[1]) != header_length)) {
+      RETURN_FAIL("tc=%d: simple token: have 0x%016" PRIX64, tc, t[1].repr);
+    }
+
+    // Reassemble the extended tokens' bits, most significant first. The
+    // leading padding bits should be zero and the rest should be the data.
+    size_t num_padding_bits = (46 * num_ext) - (8 * (size_t)length);
+    size_t num_bits = 0;
+    size_t total_length = 0;
+    uint8_t b = 0;
+    for (i = 0; i < num_ext; i++) {
+      const wuffs_base__token* ti = &t[2 + i];
+      int64_t v = wuffs_base__token__value_extension(ti);
+      if ((v < 0) || (wuffs_base__token__continued(ti) != (i + 1 < num_ext))) {
+        RETURN_FAIL("tc=%d: extended token #%" PRIu32 ": have 0x%016" PRIX64,
+                    tc, i, ti->repr);
+      }
+      total_length += wuffs_base__token__length(ti);
+      int j;
+      for (j = 45; j >= 0; j--, num_bits++) {
+        uint8_t bit = (uint8_t)(1 & (v >> j));
+        if (num_bits < num_padding_bits) {
+          if (bit) {
+            RETURN_FAIL("tc=%d: non-zero padding bit", tc);
+          }
+          continue;
+        }
+        b = (uint8_t)((b << 1) | bit);
+        size_t n = num_bits - num_padding_bits;
+        if (((n & 7) == 7) && (b != data[n / 8])) {
+          RETURN_FAIL("tc=%d: byte #%zu: have 0x%02X, want 0x%02X", tc, n / 8,
+                      b, data[n / 8]);
+        }
+      }
+    }
+    if (total_length != length) {
+      RETURN_FAIL("tc=%d: total_length: have %zu, want %" PRIu32, tc,
+                  total_length, length);
+    }
+  }
+
+  // A bignum tag applies only to an immediately following, definite-length
+  // byte string. Here, the tag is followed by an array or an indefinite
+  // length byte string. Either way, the "\x41\x01" is an ordinary string.
+  const char* ordinary[] = {
+      "\xC2\x81\x41\x01",
+      "\xC2\x5F\x41\x01\xFF",
+  };
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(ordinary); tc++) {
+    wuffs_cbor__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_cbor__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_cbor__decoder__set_quirk_enabled(
+        &dec, WUFFS_CBOR__QUIRK_DECODE_BIGNUMS_AS_INLINE_INTEGERS, true);
+    wuffs_base__token_buffer tok_buf = ((wuffs_base__token_buffer){
+        .data = g_have_slice_token,
+    });
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)(ordinary[tc]), strlen(ordinary[tc]), true);
+    CHECK_STATUS("decode_tokens", wuffs_cbor__decoder__decode_tokens(
+                                      &dec, &tok_buf, &src, g_work_slice_u8));
+    size_t i;
+    for (i = 0; i < tok_buf.meta.wi; i++) {
+      if (wuffs_base__token__value_base_category(&g_have_slice_token.ptr[i]) ==
+          WUFFS_BASE__TOKEN__VBC__INLINE_INTEGER_UNSIGNED) {
+        RETURN_FAIL("ordinary #%d: token #%zu was an inline integer", tc, i);
+      }
+    }
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_cbor_decode_interface() {
   CHECK_FOCUS(__func__);
@@ -210,6 +386,7 @@ test_wuffs_cbor_decode_valid() {
 
 proc g_tests[] = {
 
+    test_wuffs_cbor_decode_bignums,
     test_wuffs_cbor_decode_interface,
     test_wuffs_cbor_decode_invalid,
     test_wuffs_cbor_decode_valid,
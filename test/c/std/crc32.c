@@ -79,6 +79,178 @@ golden_test g_crc32_pi_gt = {
 
 // ---------------- CRC32 Tests
 
+// do_test_wuffs_crc32_combine checks, for a number of split points, that
+// combining the checksums of a file's two halves, or seeding a hasher with the
+// first half's checksum, gives the whole file's checksum.
+const char*  //
+do_test_wuffs_crc32_combine(bool castagnoli,
+                            const char* filename,
+                            uint32_t want) {
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, filename));
+  size_t n = src.meta.wi;
+
+  size_t splits[] = {0, 1, 7, 100, n / 3, n / 2, n - 1, n};
+  int i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(splits); i++) {
+    size_t split = splits[i];
+    wuffs_base__slice_u8 data_a =
+        wuffs_base__make_slice_u8(src.data.ptr, split);
+    wuffs_base__slice_u8 data_b =
+        wuffs_base__make_slice_u8(src.data.ptr + split, n - split);
+
+    uint32_t crc_a = 0;
+    uint32_t crc_b = 0;
+    uint32_t have_seed = 0;
+    uint32_t have_combine = 0;
+    if (castagnoli) {
+      wuffs_crc32__castagnoli_hasher h;
+      CHECK_STATUS("initialize",
+                   wuffs_crc32__castagnoli_hasher__initialize(
+                       &h, sizeof h, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+      crc_a = wuffs_crc32__castagnoli_hasher__update_u32(&h, data_a);
+      wuffs_crc32__castagnoli_hasher__seed_u32(&h, 0);
+      crc_b = wuffs_crc32__castagnoli_hasher__update_u32(&h, data_b);
+      wuffs_crc32__castagnoli_hasher__seed_u32(&h, crc_a);
+      have_seed = wuffs_crc32__castagnoli_hasher__update_u32(&h, data_b);
+      have_combine = wuffs_crc32__castagnoli_hasher__combine_u32(
+          &h, crc_a, crc_b, data_b.len);
+
+    } else {
+      wuffs_crc32__ieee_hasher h;
+      CHECK_STATUS("initialize",
+                   wuffs_crc32__ieee_hasher__initialize(
+                       &h, sizeof h, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+      crc_a = wuffs_crc32__ieee_hasher__update_u32(&h, data_a);
+      wuffs_crc32__ieee_hasher__seed_u32(&h, 0);
+      crc_b = wuffs_crc32__ieee_hasher__update_u32(&h, data_b);
+      wuffs_crc32__ieee_hasher__seed_u32(&h, crc_a);
+      have_seed = wuffs_crc32__ieee_hasher__update_u32(&h, data_b);
+      have_combine =
+          wuffs_crc32__ieee_hasher__combine_u32(&h, crc_a, crc_b, data_b.len);
+    }
+
+    if (have_combine != want) {
+      RETURN_FAIL("split=%zu: combine: have 0x%08" PRIX32 ", want 0x%08" PRIX32,
+                  split, have_combine, want);
+    } else if (have_seed != want) {
+      RETURN_FAIL("split=%zu: seed: have 0x%08" PRIX32 ", want 0x%08" PRIX32,
+                  split, have_seed, want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_crc32_castagnoli_interface() {
+  CHECK_FOCUS(__func__);
+  wuffs_crc32__castagnoli_hasher h;
+  CHECK_STATUS("initialize",
+               wuffs_crc32__castagnoli_hasher__initialize(
+                   &h, sizeof h, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  return do_test__wuffs_base__hasher_u32(
+      wuffs_crc32__castagnoli_hasher__upcast_as__wuffs_base__hasher_u32(&h),
+      "test/data/hat.lossy.webp", 0, SIZE_MAX, 0x56A84923);
+}
+
+const char*  //
+test_wuffs_crc32_castagnoli_combine() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_crc32_combine(true, "test/data/hat.png", 0xCFB87FD9);
+}
+
+const char*  //
+test_wuffs_crc32_castagnoli_golden() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* filename;
+    // The want values are determined by script/checksum.go.
+    uint32_t want;
+  } test_cases[] = {
+      {
+          .filename = "test/data/hat.bmp",
+          .want = 0xABF7B0E6,
+      },
+      {
+          .filename = "test/data/hat.gif",
+          .want = 0x9570A28D,
+      },
+      {
+          .filename = "test/data/hat.lossy.webp",
+          .want = 0x56A84923,
+      },
+      {
+          .filename = "test/data/hat.png",
+          .want = 0xCFB87FD9,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+        .data = g_src_slice_u8,
+    });
+    CHECK_STRING(read_file(&src, test_cases[tc].filename));
+
+    int j;
+    for (j = 0; j < 2; j++) {
+      wuffs_crc32__castagnoli_hasher checksum;
+      CHECK_STATUS("initialize",
+                   wuffs_crc32__castagnoli_hasher__initialize(
+                       &checksum, sizeof checksum, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+      uint32_t have = 0;
+      size_t num_fragments = 0;
+      size_t num_bytes = 0;
+      do {
+        wuffs_base__slice_u8 data = ((wuffs_base__slice_u8){
+            .ptr = src.data.ptr + num_bytes,
+            .len = src.meta.wi - num_bytes,
+        });
+        size_t limit = 101 + 103 * num_fragments;
+        if ((j > 0) && (data.len > limit)) {
+          data.len = limit;
+        }
+        have = wuffs_crc32__castagnoli_hasher__update_u32(&checksum, data);
+        num_fragments++;
+        num_bytes += data.len;
+      } while (num_bytes < src.meta.wi);
+
+      if (have != test_cases[tc].want) {
+        RETURN_FAIL("tc=%d, j=%d, filename=\"%s\": have 0x%08" PRIX32
+                    ", want 0x%08" PRIX32,
+                    tc, j, test_cases[tc].filename, have, test_cases[tc].want);
+      }
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_crc32_castagnoli_check_value() {
+  CHECK_FOCUS(__func__);
+  wuffs_crc32__castagnoli_hasher checksum;
+  CHECK_STATUS("initialize",
+               wuffs_crc32__castagnoli_hasher__initialize(
+                   &checksum, sizeof checksum, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  uint32_t have = wuffs_crc32__castagnoli_hasher__update_u32(
+      &checksum, wuffs_base__make_slice_u8((uint8_t*)("123456789"), 9));
+  // This is the "check" value from the Rocksoft CRC catalogue.
+  uint32_t want = 0xE3069283;
+  if (have != want) {
+    RETURN_FAIL("have 0x%08" PRIX32 ", want 0x%08" PRIX32, have, want);
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_crc32_ieee_interface() {
   CHECK_FOCUS(__func__);
@@ -92,6 +264,12 @@ test_wuffs_crc32_ieee_interface() {
       "test/data/hat.lossy.webp", 0, SIZE_MAX, 0x89F53B4E);
 }
 
+const char*  //
+test_wuffs_crc32_ieee_combine() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_crc32_combine(false, "test/data/hat.png", 0xD5DA5C2F);
+}
+
 const char*  //
 test_wuffs_crc32_ieee_golden() {
   CHECK_FOCUS(__func__);
@@ -269,6 +447,29 @@ test_mimic_crc32_ieee_pi() {
 
 uint32_t g_wuffs_crc32_unused_u32;
 
+const char*  //
+wuffs_bench_crc32_castagnoli(wuffs_base__io_buffer* dst,
+                             wuffs_base__io_buffer* src,
+                             uint32_t wuffs_initialize_flags,
+                             uint64_t wlimit,
+                             uint64_t rlimit) {
+  uint64_t len = src->meta.wi - src->meta.ri;
+  if (rlimit) {
+    len = wuffs_base__u64__min(len, rlimit);
+  }
+  wuffs_crc32__castagnoli_hasher checksum;
+  CHECK_STATUS("initialize", wuffs_crc32__castagnoli_hasher__initialize(
+                                 &checksum, sizeof checksum, WUFFS_VERSION,
+                                 wuffs_initialize_flags));
+  g_wuffs_crc32_unused_u32 = wuffs_crc32__castagnoli_hasher__update_u32(
+      &checksum, ((wuffs_base__slice_u8){
+                     .ptr = src->data.ptr + src->meta.ri,
+                     .len = len,
+                 }));
+  src->meta.ri += len;
+  return NULL;
+}
+
 const char*  //
 wuffs_bench_crc32_ieee(wuffs_base__io_buffer* dst,
                        wuffs_base__io_buffer* src,
@@ -292,6 +493,24 @@ wuffs_bench_crc32_ieee(wuffs_base__io_buffer* dst,
   return NULL;
 }
 
+const char*  //
+bench_wuffs_crc32_castagnoli_10k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_bench_crc32_castagnoli,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_src,
+      &g_crc32_midsummer_gt, UINT64_MAX, UINT64_MAX, 1500);
+}
+
+const char*  //
+bench_wuffs_crc32_castagnoli_100k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_bench_crc32_castagnoli,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_src,
+      &g_crc32_pi_gt, UINT64_MAX, UINT64_MAX, 150);
+}
+
 const char*  //
 bench_wuffs_crc32_ieee_10k() {
   CHECK_FOCUS(__func__);
@@ -338,6 +557,11 @@ bench_mimic_crc32_ieee_100k() {
 
 proc g_tests[] = {
 
+    test_wuffs_crc32_castagnoli_check_value,
+    test_wuffs_crc32_castagnoli_combine,
+    test_wuffs_crc32_castagnoli_golden,
+    test_wuffs_crc32_castagnoli_interface,
+    test_wuffs_crc32_ieee_combine,
     test_wuffs_crc32_ieee_golden,
     test_wuffs_crc32_ieee_interface,
     test_wuffs_crc32_ieee_pi,
@@ -353,6 +577,8 @@ proc g_tests[] = {
 
 proc g_benches[] = {
 
+    bench_wuffs_crc32_castagnoli_10k,
+    bench_wuffs_crc32_castagnoli_100k,
     bench_wuffs_crc32_ieee_10k,
     bench_wuffs_crc32_ieee_100k,
 
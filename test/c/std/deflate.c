@@ -197,6 +197,45 @@ wuffs_deflate_decode(wuffs_base__io_buffer* dst,
   }
 }
 
+// wuffs_deflate_decode_dst_retains_history is like wuffs_deflate_decode but
+// enables the WUFFS_BASE__QUIRK_DST_RETAINS_HISTORY quirk. Unlike
+// make_limited_writer, each limited_dst keeps the earlier output as history.
+const char*  //
+wuffs_deflate_decode_dst_retains_history(wuffs_base__io_buffer* dst,
+                                         wuffs_base__io_buffer* src,
+                                         uint32_t wuffs_initialize_flags,
+                                         uint64_t wlimit,
+                                         uint64_t rlimit) {
+  wuffs_deflate__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_deflate__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION, wuffs_initialize_flags));
+  wuffs_deflate__decoder__set_quirk_enabled(
+      &dec, WUFFS_BASE__QUIRK_DST_RETAINS_HISTORY, true);
+
+  while (true) {
+    wuffs_base__io_buffer limited_dst = *dst;
+    if ((limited_dst.data.len - limited_dst.meta.wi) > wlimit) {
+      limited_dst.data.len = limited_dst.meta.wi + wlimit;
+    }
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+
+    wuffs_base__status status = wuffs_deflate__decoder__transform_io(
+        &dec, &limited_dst, &limited_src, g_work_slice_u8);
+
+    dst->meta.wi = limited_dst.meta.wi;
+    src->meta.ri += limited_src.meta.ri;
+
+    if (((wlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_write)) ||
+        ((rlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_read))) {
+      continue;
+    }
+    return status.repr;
+  }
+}
+
 const char*  //
 test_wuffs_deflate_decode_256_bytes() {
   CHECK_FOCUS(__func__);
@@ -288,6 +327,114 @@ test_wuffs_deflate_decode_deflate_huffman_primlen_9() {
   return NULL;
 }
 
+const char*  //
+test_wuffs_deflate_decode_dst_retains_history() {
+  CHECK_FOCUS(__func__);
+  CHECK_STRING(do_test_io_buffers(wuffs_deflate_decode_dst_retains_history,
+                                  &g_deflate_pi_gt, 59, 61));
+  return do_test_io_buffers(wuffs_deflate_decode_dst_retains_history,
+                            &g_deflate_midsummer_gt, 1000, UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_deflate_decode_dst_retains_history_broken() {
+  CHECK_FOCUS(__func__);
+
+  // Enabling the quirk but using make_limited_writer, which does not retain
+  // the earlier output, should be detected as a bad distance.
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, g_deflate_pi_gt.src_filename));
+  src.meta.ri = g_deflate_pi_gt.src_offset0;
+  src.meta.wi = g_deflate_pi_gt.src_offset1;
+
+  wuffs_deflate__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_deflate__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_deflate__decoder__set_quirk_enabled(
+      &dec, WUFFS_BASE__QUIRK_DST_RETAINS_HISTORY, true);
+
+  while (true) {
+    wuffs_base__io_buffer dst = ((wuffs_base__io_buffer){
+        .data = wuffs_base__make_slice_u8(g_have_slice_u8.ptr, 59),
+    });
+    wuffs_base__status status = wuffs_deflate__decoder__transform_io(
+        &dec, &dst, &src, g_work_slice_u8);
+    if (status.repr == wuffs_base__suspension__short_write) {
+      continue;
+    } else if (status.repr != wuffs_deflate__error__bad_distance) {
+      RETURN_FAIL("status: have \"%s\", want \"%s\"", status.repr,
+                  wuffs_deflate__error__bad_distance);
+    }
+    break;
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_deflate_decode_dst_retains_history_compact_retain() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&want, g_deflate_pi_gt.want_filename));
+  CHECK_STRING(read_file(&src, g_deflate_pi_gt.src_filename));
+  src.meta.ri = g_deflate_pi_gt.src_offset0;
+  src.meta.wi = g_deflate_pi_gt.src_offset1;
+
+  // The dst buffer, the decoder's window plus 1000 bytes, is much smaller
+  // than the decoded output. The caller drains it after every transform_io
+  // call and compacts it, retaining the window.
+  const uint64_t window_len =
+      WUFFS_DEFLATE__DECODER_DST_HISTORY_RETAIN_LENGTH_MAX_INCL_WORST_CASE;
+  wuffs_base__io_buffer dst = ((wuffs_base__io_buffer){
+      .data = wuffs_base__make_slice_u8(g_pixel_slice_u8.ptr,
+                                        (size_t)(window_len + 1000)),
+  });
+
+  wuffs_deflate__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_deflate__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_deflate__decoder__set_quirk_enabled(
+      &dec, WUFFS_BASE__QUIRK_DST_RETAINS_HISTORY, true);
+
+  int num_compactions = 0;
+  while (true) {
+    wuffs_base__status status = wuffs_deflate__decoder__transform_io(
+        &dec, &dst, &src, g_work_slice_u8);
+    size_t n = dst.meta.wi - dst.meta.ri;
+    if (n > (have.data.len - have.meta.wi)) {
+      RETURN_FAIL("have buffer is too short");
+    }
+    memcpy(have.data.ptr + have.meta.wi, dst.data.ptr + dst.meta.ri, n);
+    have.meta.wi += n;
+    dst.meta.ri = dst.meta.wi;
+
+    if (status.repr != wuffs_base__suspension__short_write) {
+      CHECK_STATUS("transform_io", status);
+      break;
+    }
+    wuffs_base__io_buffer__compact_retain(&dst, window_len);
+    num_compactions++;
+  }
+
+  if (num_compactions < 2) {
+    RETURN_FAIL("num_compactions: have %d, want >= 2", num_compactions);
+  }
+  return check_io_buffers_equal("", &have, &want);
+}
+
 const char*  //
 test_wuffs_deflate_decode_midsummer() {
   CHECK_FOCUS(__func__);
@@ -901,6 +1048,9 @@ proc g_tests[] = {
     test_wuffs_deflate_decode_deflate_distance_32768,
     test_wuffs_deflate_decode_deflate_distance_code_31,
     test_wuffs_deflate_decode_deflate_huffman_primlen_9,
+    test_wuffs_deflate_decode_dst_retains_history,
+    test_wuffs_deflate_decode_dst_retains_history_broken,
+    test_wuffs_deflate_decode_dst_retains_history_compact_retain,
     test_wuffs_deflate_decode_interface,
     test_wuffs_deflate_decode_midsummer,
     test_wuffs_deflate_decode_pi_just_one_read,
@@ -0,0 +1,427 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror ebml.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__EBML
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+// No EBML golden tests.
+
+// ---------------- EBML Tests
+
+// wuffs_ebml_decode decodes src into tok, limiting each decode_tokens call to
+// wlimit tokens and rlimit bytes.
+const char*  //
+wuffs_ebml_decode(wuffs_base__token_buffer* tok,
+                  wuffs_base__io_buffer* src,
+                  uint64_t wlimit,
+                  uint64_t rlimit) {
+  wuffs_ebml__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_ebml__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  while (true) {
+    wuffs_base__token_buffer limited_tok =
+        make_limited_token_writer(*tok, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+
+    wuffs_base__status status = wuffs_ebml__decoder__decode_tokens(
+        &dec, &limited_tok, &limited_src, g_work_slice_u8);
+
+    tok->meta.wi += limited_tok.meta.wi;
+    src->meta.ri += limited_src.meta.ri;
+
+    if (((wlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_write)) ||
+        ((rlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_read))) {
+      continue;
+    }
+    return status.repr;
+  }
+}
+
+// ebml_summarize writes a summary of the tokens to dst, which has a capacity
+// of at least 1024 bytes. Each element is summarized as "[id:size]", with an
+// unknown size written as "?" and with its children (or, for a non-master
+// element, the combined length of its string tokens) before the "]". It also
+// checks that the token lengths sum to src_len.
+const char*  //
+ebml_summarize(char* dst, wuffs_base__token_buffer* tok, uint64_t src_len) {
+  char* d = dst;
+  uint64_t total_length = 0;
+  uint64_t string_length = 0;
+  bool in_string = false;
+  size_t i;
+  for (i = tok->meta.ri; i < tok->meta.wi; i++) {
+    wuffs_base__token* t = &tok->data.ptr[i];
+    total_length += wuffs_base__token__length(t);
+    if (wuffs_base__token__value_major(t) == WUFFS_EBML__TOKEN_VALUE_MAJOR) {
+      if ((wuffs_base__token__value_minor(t) !=
+           WUFFS_EBML__TOKEN_VALUE_MINOR__ELEMENT_ID) ||
+          (++i >= tok->meta.wi)) {
+        RETURN_FAIL("i=%zu: bad element id token", i);
+      }
+      total_length += wuffs_base__token__length(&tok->data.ptr[i]);
+      d += sprintf(d, "[%" PRIX64,
+                   (uint64_t)(wuffs_base__token__value_extension(
+                       &tok->data.ptr[i])));
+      continue;
+    }
+    switch (wuffs_base__token__value_base_category(t)) {
+      case WUFFS_BASE__TOKEN__VBC__STRUCTURE:
+        if (wuffs_base__token__value_base_detail(t) &
+            WUFFS_BASE__TOKEN__VBD__STRUCTURE__POP) {
+          if (in_string) {
+            d += sprintf(d, " %" PRIu64, string_length);
+            string_length = 0;
+            in_string = false;
+          }
+          d += sprintf(d, "]");
+        }
+        break;
+      case WUFFS_BASE__TOKEN__VBC__LITERAL:
+        d += sprintf(d, ":?");
+        break;
+      case WUFFS_BASE__TOKEN__VBC__INLINE_INTEGER_UNSIGNED:
+        if (++i >= tok->meta.wi) {
+          RETURN_FAIL("i=%zu: bad element size token", i);
+        }
+        total_length += wuffs_base__token__length(&tok->data.ptr[i]);
+        d += sprintf(
+            d, ":%" PRIu64,
+            (((uint64_t)(wuffs_base__token__value_base_detail(t))) << 46) |
+                ((uint64_t)(wuffs_base__token__value_extension(
+                    &tok->data.ptr[i]))));
+        break;
+      case WUFFS_BASE__TOKEN__VBC__STRING:
+        string_length += wuffs_base__token__length(t);
+        in_string = true;
+        break;
+      default:
+        RETURN_FAIL("i=%zu: unexpected token", i);
+    }
+    if ((d - dst) > 960) {
+      RETURN_FAIL("summary is too long");
+    }
+  }
+  if (total_length != src_len) {
+    RETURN_FAIL("total length: have %" PRIu64 ", want %" PRIu64, total_length,
+                src_len);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_ebml_decode_elements() {
+  CHECK_FOCUS(__func__);
+
+  const char src_ptr[] =
+      // An EBML header holding an EBMLVersion element.
+      "\x1A\x45\xDF\xA3\x84"
+      "\x42\x86\x81\x01"
+      // An unknown-sized Segment, with an 8-byte size.
+      "\x18\x53\x80\x67\x01\xFF\xFF\xFF\xFF\xFF\xFF\xFF"
+      // An Info holding a TimestampScale.
+      "\x15\x49\xA9\x66\x87"
+      "\x2A\xD7\xB1\x83\x0F\x42\x40"
+      // An unknown-sized Cluster holding a Timestamp and a SimpleBlock.
+      "\x1F\x43\xB6\x75\xFF"
+      "\xE7\x81\x00"
+      "\xA3\x84"
+      "abcd"
+      // Another unknown-sized Cluster, which ends the previous one.
+      "\x1F\x43\xB6\x75\xFF"
+      "\xA3\x82"
+      "xy"
+      // An empty Cues, which ends the previous Cluster. The end of the file
+      // ends the Segment.
+      "\x1C\x53\xBB\x6B\x80";
+  const size_t src_len = sizeof(src_ptr) - 1;
+  const char* want =
+      "[1A45DFA3:4[4286:1 1]]"
+      "[18538067:?[1549A966:7[2AD7B1:3 3]]"
+      "[1F43B675:?[E7:1 1][A3:4 4]]"
+      "[1F43B675:?[A3:2 2]]"
+      "[1C53BB6B:0]]";
+
+  int tc;
+  for (tc = 0; tc < 4; tc++) {
+    uint64_t wlimit = (tc & 1) ? 6 : UINT64_MAX;
+    uint64_t rlimit =
+        (tc & 2) ? WUFFS_EBML__DECODER_SRC_IO_BUFFER_LENGTH_MIN_INCL
+                 : UINT64_MAX;
+
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src =
+        wuffs_base__ptr_u8__reader((uint8_t*)src_ptr, src_len, true);
+    CHECK_STRING(wuffs_ebml_decode(&tok, &src, wlimit, rlimit));
+    if (src.meta.ri != src_len) {
+      RETURN_FAIL("tc=%d: src.meta.ri: have %zu, want %zu", tc, src.meta.ri,
+                  src_len);
+    }
+
+    char have[1024];
+    CHECK_STRING(ebml_summarize(have, &tok, src_len));
+    if (strcmp(have, want)) {
+      RETURN_FAIL("tc=%d:\nhave \"%s\"\nwant \"%s\"", tc, have, want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_ebml_decode_end_of_data() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_ebml__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_ebml__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  int i;
+  for (i = 0; i < 2; i++) {
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src =
+        wuffs_base__ptr_u8__reader((uint8_t*)("\xEC\x81\x00"), 3, true);
+    wuffs_base__status status =
+        wuffs_ebml__decoder__decode_tokens(&dec, &tok, &src, g_work_slice_u8);
+    const char* want = i ? wuffs_base__note__end_of_data : NULL;
+    if (status.repr != want) {
+      RETURN_FAIL("i=%d: have \"%s\", want \"%s\"", i, status.repr, want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_ebml_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* want;
+    const char* src_ptr;
+    size_t src_len;
+  } test_cases[] = {
+      {
+          // Element ID longer than 4 bytes.
+          .want = wuffs_ebml__error__bad_element_id,
+          .src_ptr = "\x08\x00\x00\x00\x00\x80",
+          .src_len = 6,
+      },
+      {
+          // Element size longer than 8 bytes.
+          .want = wuffs_ebml__error__bad_element_size,
+          .src_ptr = "\xEC\x00\x80\x00\x00\x00\x00\x00\x00\x00",
+          .src_len = 10,
+      },
+      {
+          // Unknown size for a non-master element.
+          .want = wuffs_ebml__error__bad_element_size,
+          .src_ptr = "\xEC\xFF",
+          .src_len = 2,
+      },
+      {
+          // Child element's data larger than its parent.
+          .want = wuffs_ebml__error__bad_element_size,
+          .src_ptr = "\x1A\x45\xDF\xA3\x83"
+                     "\x42\x86\x82\x01\x02",
+          .src_len = 10,
+      },
+      {
+          // Child element's header larger than its parent.
+          .want = wuffs_ebml__error__bad_element_size,
+          .src_ptr = "\x1A\x45\xDF\xA3\x82"
+                     "\x42\x86\x80",
+          .src_len = 8,
+      },
+      {
+          // Truncated element ID.
+          .want = wuffs_ebml__error__truncated_input,
+          .src_ptr = "\x1A\x45",
+          .src_len = 2,
+      },
+      {
+          // Truncated element size.
+          .want = wuffs_ebml__error__truncated_input,
+          .src_ptr = "\x1A\x45\xDF\xA3\x40",
+          .src_len = 5,
+      },
+      {
+          // Truncated element data.
+          .want = wuffs_ebml__error__truncated_input,
+          .src_ptr = "\xA3\x84"
+                     "ab",
+          .src_len = 4,
+      },
+      {
+          // Truncated master element.
+          .want = wuffs_ebml__error__truncated_input,
+          .src_ptr = "\x1A\x45\xDF\xA3\x84",
+          .src_len = 5,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)(test_cases[tc].src_ptr), test_cases[tc].src_len, true);
+    const char* have = wuffs_ebml_decode(&tok, &src, UINT64_MAX, UINT64_MAX);
+    if (have != test_cases[tc].want) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, have,
+                  test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_ebml_decode_recursion_depth() {
+  CHECK_FOCUS(__func__);
+
+  // Make a chain of nested BlockGroup elements, each one 2 bytes smaller than
+  // its parent.
+  uint8_t src_array[2 * 40];
+  int n;
+  for (n = 32; n <= 33; n++) {
+    int i;
+    for (i = 0; i < n; i++) {
+      src_array[(2 * i) + 0] = 0xA0;
+      src_array[(2 * i) + 1] = (uint8_t)(0x80 | (2 * (n - 1 - i)));
+    }
+
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src =
+        wuffs_base__ptr_u8__reader(&src_array[0], 2 * n, true);
+    const char* have = wuffs_ebml_decode(&tok, &src, UINT64_MAX, UINT64_MAX);
+    const char* want =
+        (n <= WUFFS_EBML__DECODER_DEPTH_MAX_INCL)
+            ? NULL
+            : wuffs_ebml__error__unsupported_recursion_depth;
+    if (have != want) {
+      RETURN_FAIL("n=%d: have \"%s\", want \"%s\"", n, have, want);
+    }
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- EBML Benches
+
+// No EBML benches.
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_ebml_decode_elements,
+    test_wuffs_ebml_decode_end_of_data,
+    test_wuffs_ebml_decode_invalid,
+    test_wuffs_ebml_decode_recursion_depth,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No EBML benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/ebml";
+  return test_main(argc, argv, g_tests, g_benches);
+}
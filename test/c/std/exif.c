@@ -0,0 +1,410 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror exif.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__EXIF
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- EXIF Tests
+
+// g_exif_le_with_prefix and g_exif_be hold the same EXIF data, with different
+// endianness. The former also has the "Exif\x00\x00" prefix used by JPEG's
+// APP1 segments. Its IFDs are:
+//  - Primary: Orientation (6), EXIF IFD pointer and GPS IFD pointer.
+//  - EXIF:    DateTimeOriginal ("2020:01:02 03:04:05").
+//  - GPS:     GPSLatitudeRef ("N") and GPSLatitude (37/1, 48/1, 3000/100).
+
+const char g_exif_le_with_prefix[] =
+      "\x45\x78\x69\x66\x00\x00\x49\x49\x2A\x00\x08\x00\x00\x00\x03\x00"
+      "\x12\x01\x03\x00\x01\x00\x00\x00\x06\x00\x00\x00\x69\x87\x04\x00"
+      "\x01\x00\x00\x00\x32\x00\x00\x00\x25\x88\x04\x00\x01\x00\x00\x00"
+      "\x58\x00\x00\x00\x00\x00\x00\x00\x01\x00\x03\x90\x02\x00\x14\x00"
+      "\x00\x00\x44\x00\x00\x00\x00\x00\x00\x00\x32\x30\x32\x30\x3A\x30"
+      "\x31\x3A\x30\x32\x20\x30\x33\x3A\x30\x34\x3A\x30\x35\x00\x02\x00"
+      "\x01\x00\x02\x00\x02\x00\x00\x00\x4E\x00\x00\x00\x02\x00\x05\x00"
+      "\x03\x00\x00\x00\x76\x00\x00\x00\x00\x00\x00\x00\x25\x00\x00\x00"
+      "\x01\x00\x00\x00\x30\x00\x00\x00\x01\x00\x00\x00\xB8\x0B\x00\x00"
+      "\x64\x00\x00\x00";
+
+const char g_exif_be[] =
+      "\x4D\x4D\x00\x2A\x00\x00\x00\x08\x00\x03\x01\x12\x00\x03\x00\x00"
+      "\x00\x01\x00\x06\x00\x00\x87\x69\x00\x04\x00\x00\x00\x01\x00\x00"
+      "\x00\x32\x88\x25\x00\x04\x00\x00\x00\x01\x00\x00\x00\x58\x00\x00"
+      "\x00\x00\x00\x01\x90\x03\x00\x02\x00\x00\x00\x14\x00\x00\x00\x44"
+      "\x00\x00\x00\x00\x32\x30\x32\x30\x3A\x30\x31\x3A\x30\x32\x20\x30"
+      "\x33\x3A\x30\x34\x3A\x30\x35\x00\x00\x02\x00\x01\x00\x02\x00\x00"
+      "\x00\x02\x4E\x00\x00\x00\x00\x02\x00\x05\x00\x00\x00\x03\x00\x00"
+      "\x00\x76\x00\x00\x00\x00\x00\x00\x00\x25\x00\x00\x00\x01\x00\x00"
+      "\x00\x30\x00\x00\x00\x01\x00\x00\x0B\xB8\x00\x00\x00\x64";
+
+typedef struct {
+  uint32_t ifd;
+  uint32_t type;
+  uint32_t tag;
+  uint32_t count;
+  uint32_t position;
+  uint32_t value;
+} exif_entry;
+
+const char*  //
+decode_exif_entries(exif_entry* entries,
+                    size_t entries_len,
+                    size_t* num_entries,
+                    bool* big_endian,
+                    const char* src_ptr,
+                    size_t src_len) {
+  wuffs_exif__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_exif__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  // Use the smallest possible token buffer, to exercise coroutine resumption.
+  wuffs_base__token
+      tok_array[WUFFS_EXIF__DECODER_DST_TOKEN_BUFFER_LENGTH_MIN_INCL];
+  wuffs_base__token_buffer tok_buf =
+      wuffs_base__slice_token__writer(wuffs_base__make_slice_token(
+          &tok_array[0], WUFFS_TESTLIB_ARRAY_SIZE(tok_array)));
+  const bool closed = true;
+  wuffs_base__io_buffer src = wuffs_base__slice_u8__reader(
+      wuffs_base__make_slice_u8((uint8_t*)src_ptr, src_len), closed);
+
+  uint64_t pos = 0;
+  int chain = 0;
+  *num_entries = 0;
+  while (true) {
+    wuffs_base__status status = wuffs_exif__decoder__decode_tokens(
+        &dec, &tok_buf, &src, g_work_slice_u8);
+
+    while (tok_buf.meta.ri < tok_buf.meta.wi) {
+      wuffs_base__token* t = &tok_buf.data.ptr[tok_buf.meta.ri++];
+      pos += wuffs_base__token__length(t);
+      if (chain == 1) {
+        uint64_t x = wuffs_base__token__value_extension(t);
+        entries[*num_entries].count = (uint32_t)(x >> 16);
+        entries[*num_entries].position = (uint32_t)(x & 0xFFFF);
+        chain = 2;
+        continue;
+      } else if (chain == 2) {
+        entries[*num_entries].value =
+            (uint32_t)(wuffs_base__token__value_extension(t));
+        (*num_entries)++;
+        chain = 0;
+        continue;
+      } else if (wuffs_base__token__value_major(t) !=
+                 WUFFS_EXIF__TOKEN_VALUE_MAJOR) {
+        continue;
+      }
+
+      uint32_t vminor = wuffs_base__token__value_minor(t);
+      if (vminor & WUFFS_EXIF__TOKEN_VALUE_MINOR__HEADER) {
+        if (pos != src_len) {
+          RETURN_FAIL("header: pos: have %" PRIu64 ", want %zu", pos, src_len);
+        }
+        *big_endian =
+            vminor & WUFFS_EXIF__TOKEN_VALUE_MINOR__HEADER__BIG_ENDIAN;
+        continue;
+      } else if (*num_entries >= entries_len) {
+        RETURN_FAIL("too many entries");
+      } else if (!wuffs_base__token__continued(t)) {
+        RETURN_FAIL("entry token was not continued");
+      }
+      entries[*num_entries].ifd = vminor >> 20;
+      entries[*num_entries].type = (vminor >> 16) & 0x0F;
+      entries[*num_entries].tag = vminor & 0xFFFF;
+      chain = 1;
+    }
+
+    if (wuffs_base__status__is_ok(&status)) {
+      break;
+    } else if (status.repr != wuffs_base__suspension__short_write) {
+      return status.repr;
+    }
+    wuffs_base__token_buffer__compact(&tok_buf);
+  }
+
+  if (chain != 0) {
+    RETURN_FAIL("incomplete token chain");
+  } else if (pos != src_len) {
+    RETURN_FAIL("pos: have %" PRIu64 ", want %zu", pos, src_len);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_exif_decode_cycle() {
+  CHECK_FOCUS(__func__);
+
+  // The primary IFD's EXIF IFD pointer points back to the primary IFD. Each
+  // IFD should still be visited at most once.
+  const char src[] =
+      "II\x2A\x00\x08\x00\x00\x00"
+      "\x01\x00"
+      "\x69\x87\x04\x00\x01\x00\x00\x00\x08\x00\x00\x00"
+      "\x00\x00\x00\x00";
+
+  exif_entry entries[8];
+  size_t num_entries = 0;
+  bool big_endian = true;
+  CHECK_STRING(decode_exif_entries(entries, WUFFS_TESTLIB_ARRAY_SIZE(entries),
+                                   &num_entries, &big_endian, src,
+                                   sizeof(src) - 1));
+  if (num_entries != 2) {
+    RETURN_FAIL("num_entries: have %zu, want 2", num_entries);
+  } else if (big_endian) {
+    RETURN_FAIL("big_endian: have true, want false");
+  } else if ((entries[0].ifd != WUFFS_EXIF__IFD__PRIMARY) ||
+             (entries[1].ifd != WUFFS_EXIF__IFD__EXIF)) {
+    RETURN_FAIL("ifd: have (%" PRIu32 ", %" PRIu32 "), want (0, 1)",
+                entries[0].ifd, entries[1].ifd);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_exif_decode_entries() {
+  CHECK_FOCUS(__func__);
+
+  exif_entry want_le[6] = {
+      {0, 3, 0x0112, 1, 24, 6},   //
+      {0, 4, 0x8769, 1, 36, 50},  //
+      {0, 4, 0x8825, 1, 48, 88},  //
+      {1, 2, 0x9003, 20, 74, '2'},  //
+      {2, 2, 0x0001, 2, 104, 'N'},  //
+      {2, 5, 0x0002, 3, 124, 0},  //
+  };
+
+  int i;
+  for (i = 0; i < 2; i++) {
+    const char* src_ptr = i ? g_exif_be : g_exif_le_with_prefix;
+    size_t src_len =
+        i ? (sizeof(g_exif_be) - 1) : (sizeof(g_exif_le_with_prefix) - 1);
+    uint32_t delta = i ? 6 : 0;
+
+    exif_entry have[8];
+    size_t num_entries = 0;
+    bool big_endian = !i;
+    CHECK_STRING(decode_exif_entries(have, WUFFS_TESTLIB_ARRAY_SIZE(have),
+                                     &num_entries, &big_endian, src_ptr,
+                                     src_len));
+    if (big_endian != (i == 1)) {
+      RETURN_FAIL("i=%d: big_endian: have %d, want %d", i, big_endian, i);
+    } else if (num_entries != WUFFS_TESTLIB_ARRAY_SIZE(want_le)) {
+      RETURN_FAIL("i=%d: num_entries: have %zu, want %zu", i, num_entries,
+                  (size_t)(WUFFS_TESTLIB_ARRAY_SIZE(want_le)));
+    }
+
+    size_t j;
+    for (j = 0; j < num_entries; j++) {
+      exif_entry* h = &have[j];
+      exif_entry* w = &want_le[j];
+      if ((h->ifd != w->ifd) || (h->type != w->type) || (h->tag != w->tag) ||
+          (h->count != w->count) || (h->position != (w->position - delta)) ||
+          (h->value != w->value)) {
+        RETURN_FAIL("i=%d, j=%zu: have {%" PRIu32 ", %" PRIu32 ", 0x%04" PRIX32
+                    ", %" PRIu32 ", %" PRIu32 ", %" PRIu32 "}",
+                    i, j, h->ifd, h->type, h->tag, h->count, h->position,
+                    h->value);
+      }
+    }
+
+    // Check that the reported position can be used to read the string value.
+    const char* want_date = "2020:01:02 03:04:05";
+    if (memcmp(src_ptr + have[3].position, want_date, strlen(want_date) + 1)) {
+      RETURN_FAIL("i=%d: DateTimeOriginal: values differed", i);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_exif_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* src_ptr;
+    size_t src_len;
+    const char* want_status;
+  } test_cases[] = {
+      // Empty input.
+      {"", 0, wuffs_exif__error__bad_header},
+      // Truncated TIFF header.
+      {"II\x2A\x00\x08\x00", 6, wuffs_exif__error__bad_header},
+      // Bad TIFF magic.
+      {"II\x2B\x00\x08\x00\x00\x00", 8, wuffs_exif__error__bad_header},
+      // Primary IFD offset out of bounds.
+      {"MM\x00\x2A\x00\x00\x10\x00", 8, wuffs_exif__error__bad_ifd},
+      // Primary IFD has 1 entry but only room for 0.
+      {"II\x2A\x00\x08\x00\x00\x00\x01\x00", 10, wuffs_exif__error__bad_ifd},
+      // The 8 byte long entry value's offset is out of bounds.
+      {"II\x2A\x00\x08\x00\x00\x00"
+       "\x01\x00"
+       "\x0F\x01\x02\x00\x08\x00\x00\x00\xF0\x00\x00\x00"
+       "\x00\x00\x00\x00",
+       26, wuffs_exif__error__bad_ifd},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    exif_entry entries[8];
+    size_t num_entries = 0;
+    bool big_endian = false;
+    const char* have = decode_exif_entries(
+        entries, WUFFS_TESTLIB_ARRAY_SIZE(entries), &num_entries, &big_endian,
+        test_cases[tc].src_ptr, test_cases[tc].src_len);
+    if (have != test_cases[tc].want_status) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, have,
+                  test_cases[tc].want_status);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_exif_decode_too_long() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_exif__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_exif__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__token tok_array[256];
+  wuffs_base__token_buffer tok_buf =
+      wuffs_base__slice_token__writer(wuffs_base__make_slice_token(
+          &tok_array[0], WUFFS_TESTLIB_ARRAY_SIZE(tok_array)));
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  src.meta.wi = WUFFS_EXIF__DECODER_SRC_LENGTH_MAX_INCL + 1;
+  src.meta.closed = true;
+  memset(src.data.ptr, 0, src.meta.wi);
+
+  wuffs_base__status status =
+      wuffs_exif__decoder__decode_tokens(&dec, &tok_buf, &src, g_work_slice_u8);
+  if (status.repr != wuffs_exif__error__unsupported_exif_length) {
+    RETURN_FAIL("have \"%s\", want \"%s\"", status.repr,
+                wuffs_exif__error__unsupported_exif_length);
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- EXIF Benches
+
+// No EXIF benches.
+
+// ---------------- Mimic Benches
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_exif_decode_cycle,
+    test_wuffs_exif_decode_entries,
+    test_wuffs_exif_decode_invalid,
+    test_wuffs_exif_decode_too_long,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No EXIF benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/exif";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -0,0 +1,415 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror exr.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__ADLER32
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__DEFLATE
+#define WUFFS_CONFIG__MODULE__EXR
+#define WUFFS_CONFIG__MODULE__ZLIB
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- EXR Tests
+
+// g_exr_1x1_y_half_prefix is the header (and offset table) of a 1×1 EXR image
+// with one uncompressed HALF channel, Y (luminance).
+static const uint8_t g_exr_1x1_y_half_prefix[] = {
+    0x76, 0x2F, 0x31, 0x01, 0x02, 0x00, 0x00, 0x00,  //
+    'c',  'h',  'a',  'n',  'n',  'e',  'l',  's',   //
+    0x00, 'c',  'h',  'l',  'i',  's',  't',  0x00,  //
+    0x13, 0x00, 0x00, 0x00, 'Y',  0x00, 0x01, 0x00,  //
+    0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00,  //
+    0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 'c',   //
+    'o',  'm',  'p',  'r',  'e',  's',  's',  'i',   //
+    'o',  'n',  0x00, 'c',  'o',  'm',  'p',  'r',   //
+    'e',  's',  's',  'i',  'o',  'n',  0x00, 0x01,  //
+    0x00, 0x00, 0x00, 0x00, 'd',  'a',  't',  'a',   //
+    'W',  'i',  'n',  'd',  'o',  'w',  0x00, 'b',   //
+    'o',  'x',  '2',  'i',  0x00, 0x10, 0x00, 0x00,  //
+    0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,  //
+    0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,  //
+    0x00, 0x00, 0x7A, 0x00, 0x00, 0x00, 0x00, 0x00,  //
+    0x00, 0x00,                                      //
+};
+
+// decode_exr_1x1_y_half decodes a 1×1 Y-only EXR image, whose only sample is
+// the IEEE 754 half-precision value h, to RGBA_NONPREMUL_4XF32LE pixels.
+const char*  //
+decode_exr_1x1_y_half(uint32_t have_bits[4], uint16_t h) {
+  uint8_t src_array[sizeof(g_exr_1x1_y_half_prefix) + 10];
+  memcpy(src_array, g_exr_1x1_y_half_prefix, sizeof(g_exr_1x1_y_half_prefix));
+  uint8_t* chunk = src_array + sizeof(g_exr_1x1_y_half_prefix);
+  wuffs_base__poke_u32le__no_bounds_check(chunk + 0, 0);
+  wuffs_base__poke_u32le__no_bounds_check(chunk + 4, 2);
+  wuffs_base__poke_u16le__no_bounds_check(chunk + 8, h);
+
+  wuffs_exr__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_exr__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  wuffs_base__io_buffer src =
+      wuffs_base__ptr_u8__reader(src_array, sizeof(src_array), true);
+  CHECK_STATUS("decode_image_config",
+               wuffs_exr__decoder__decode_image_config(&dec, &ic, &src));
+  if (ic.pixcfg.private_impl.pixfmt.repr !=
+      WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL_4XF32LE) {
+    RETURN_FAIL("pixfmt: have 0x%08" PRIX32 ", want 0x%08" PRIX32,
+                ic.pixcfg.private_impl.pixfmt.repr,
+                (uint32_t)WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL_4XF32LE);
+  }
+
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     &pb, &ic.pixcfg, g_pixel_slice_u8));
+  CHECK_STATUS("decode_frame", wuffs_exr__decoder__decode_frame(
+                                   &dec, &pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC,
+                                   g_work_slice_u8, NULL));
+
+  wuffs_base__table_u8 tab = wuffs_base__pixel_buffer__plane(&pb, 0);
+  int i;
+  for (i = 0; i < 4; i++) {
+    have_bits[i] = wuffs_base__peek_u32le__no_bounds_check(tab.ptr + (4 * i));
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_exr_decode_frame_config() {
+  CHECK_FOCUS(__func__);
+  wuffs_exr__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_exr__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__frame_config fc = ((wuffs_base__frame_config){});
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hippopotamus.exr"));
+  CHECK_STATUS("decode_frame_config #0",
+               wuffs_exr__decoder__decode_frame_config(&dec, &fc, &src));
+
+  // The 331 byte header is followed by a 2 × 8 byte offset table.
+  uint64_t have_io_position = wuffs_base__frame_config__io_position(&fc);
+  if (have_io_position != 347) {
+    RETURN_FAIL("io_position: have %" PRIu64 ", want 347", have_io_position);
+  }
+
+  wuffs_base__status status =
+      wuffs_exr__decoder__decode_frame_config(&dec, &fc, &src);
+  if (status.repr != wuffs_base__note__end_of_data) {
+    RETURN_FAIL("decode_frame_config #1: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__note__end_of_data);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_exr_decode_half() {
+  CHECK_FOCUS(__func__);
+
+  const struct {
+    uint16_t h;
+    uint32_t want;
+  } tcs[] = {
+      {.h = 0x0000, .want = 0x00000000},  // +0.
+      {.h = 0x8000, .want = 0x80000000},  // -0.
+      {.h = 0x3C00, .want = 0x3F800000},  // 1.
+      {.h = 0xC000, .want = 0xC0000000},  // -2.
+      {.h = 0x3555, .want = 0x3EAAA000},  // Approximately 1/3.
+      {.h = 0x7BFF, .want = 0x477FE000},  // 65504, the largest finite half.
+      {.h = 0x0400, .want = 0x38800000},  // The smallest positive normal.
+      {.h = 0x03FF, .want = 0x387FC000},  // The largest subnormal.
+      {.h = 0x0001, .want = 0x33800000},  // The smallest positive subnormal.
+      {.h = 0x7C00, .want = 0x7F800000},  // +Infinity.
+      {.h = 0xFC00, .want = 0xFF800000},  // -Infinity.
+      {.h = 0x7E00, .want = 0x7FC00000},  // NaN.
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(tcs); tc++) {
+    uint32_t have[4] = {0};
+    const char* status = decode_exr_1x1_y_half(have, tcs[tc].h);
+    if (status) {
+      RETURN_FAIL("tc=%d: %s", tc, status);
+    }
+    uint32_t want[4] = {tcs[tc].want, tcs[tc].want, tcs[tc].want, 0x3F800000};
+    int i;
+    for (i = 0; i < 4; i++) {
+      if (have[i] != want[i]) {
+        RETURN_FAIL("tc=%d, i=%d: have 0x%08" PRIX32 ", want 0x%08" PRIX32, tc,
+                    i, have[i], want[i]);
+      }
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_exr_decode_interface() {
+  CHECK_FOCUS(__func__);
+
+  const char* filenames[] = {
+      "test/data/hippopotamus.exr",
+      "test/data/hippopotamus.rle.exr",
+      "test/data/hippopotamus.uncompressed.exr",
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(filenames); tc++) {
+    wuffs_exr__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_exr__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    const char* have = do_test__wuffs_base__image_decoder(
+        wuffs_exr__decoder__upcast_as__wuffs_base__image_decoder(&dec),
+        filenames[tc], 0, SIZE_MAX, 36, 28, 0xFFF5F5F5);
+    if (have) {
+      RETURN_FAIL("tc=%d (%s): %s", tc, filenames[tc], have);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_exr_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  const struct {
+    size_t offset;
+    uint8_t value;
+    const char* want;
+  } tcs[] = {
+      {
+          // Bad magic number.
+          .offset = 0x00,
+          .value = 0x77,
+          .want = wuffs_exr__error__bad_header,
+      },
+      {
+          // Bad file format version.
+          .offset = 0x04,
+          .value = 0x03,
+          .want = wuffs_exr__error__bad_header,
+      },
+      {
+          // Tiled.
+          .offset = 0x05,
+          .value = 0x02,
+          .want = wuffs_exr__error__unsupported_exr_file,
+      },
+      {
+          // Bad pixel type.
+          .offset = 0x1E,
+          .value = 0x03,
+          .want = wuffs_exr__error__bad_header,
+      },
+      {
+          // Subsampled channel.
+          .offset = 0x26,
+          .value = 0x02,
+          .want = wuffs_exr__error__unsupported_exr_file,
+      },
+      {
+          // No R, G, B or Y channel.
+          .offset = 0x1C,
+          .value = 'Z',
+          .want = wuffs_exr__error__unsupported_exr_file,
+      },
+      {
+          // PIZ compression.
+          .offset = 0x4B,
+          .value = 0x04,
+          .want = wuffs_exr__error__unsupported_exr_file,
+      },
+      {
+          // Bad compression.
+          .offset = 0x4B,
+          .value = 0x0A,
+          .want = wuffs_exr__error__bad_header,
+      },
+      {
+          // Negative width (xMax < xMin).
+          .offset = 0x6C,
+          .value = 0x80,
+          .want = wuffs_exr__error__bad_header,
+      },
+      {
+          // Bad chunk y coordinate.
+          .offset = sizeof(g_exr_1x1_y_half_prefix) + 0,
+          .value = 0x01,
+          .want = wuffs_exr__error__bad_chunk,
+      },
+      {
+          // Chunk data larger than uncompressed.
+          .offset = sizeof(g_exr_1x1_y_half_prefix) + 4,
+          .value = 0x03,
+          .want = wuffs_exr__error__bad_chunk,
+      },
+      {
+          // Chunk data smaller than uncompressed (with no compression).
+          .offset = sizeof(g_exr_1x1_y_half_prefix) + 4,
+          .value = 0x01,
+          .want = wuffs_exr__error__bad_chunk,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(tcs); tc++) {
+    uint8_t src_array[sizeof(g_exr_1x1_y_half_prefix) + 10];
+    memcpy(src_array, g_exr_1x1_y_half_prefix,
+           sizeof(g_exr_1x1_y_half_prefix));
+    uint8_t* chunk = src_array + sizeof(g_exr_1x1_y_half_prefix);
+    wuffs_base__poke_u32le__no_bounds_check(chunk + 0, 0);
+    wuffs_base__poke_u32le__no_bounds_check(chunk + 4, 2);
+    wuffs_base__poke_u16le__no_bounds_check(chunk + 8, 0x3C00);
+    src_array[tcs[tc].offset] = tcs[tc].value;
+
+    wuffs_exr__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_exr__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__pixel_config pixcfg = ((wuffs_base__pixel_config){});
+    wuffs_base__pixel_config__set(&pixcfg,
+                                  WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+                                  WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, 1, 1);
+    wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+    CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                       &pb, &pixcfg, g_pixel_slice_u8));
+
+    wuffs_base__io_buffer src =
+        wuffs_base__ptr_u8__reader(src_array, sizeof(src_array), true);
+    wuffs_base__status status = wuffs_exr__decoder__decode_frame(
+        &dec, &pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC, g_work_slice_u8, NULL);
+    if (status.repr != tcs[tc].want) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, status.repr,
+                  tcs[tc].want);
+    }
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- EXR Benches
+
+// No EXR benches.
+
+// ---------------- Mimic Benches
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_exr_decode_frame_config,
+    test_wuffs_exr_decode_half,
+    test_wuffs_exr_decode_interface,
+    test_wuffs_exr_decode_invalid,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No EXR benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/exr";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -0,0 +1,395 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror flac.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__FLAC
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_flac_midsummer_gt = {
+    .want_filename = "test/data/midsummer.txt",
+    .src_filename = "test/data/midsummer.txt.flac",
+};
+
+golden_test g_flac_romeo_gt = {
+    .want_filename = "test/data/romeo.txt",
+    .src_filename = "test/data/romeo.txt.flac",
+};
+
+golden_test g_flac_romeo_s24_gt = {
+    .want_filename = "test/data/romeo.txt",
+    .src_filename = "test/data/romeo.txt.s24.flac",
+};
+
+// ---------------- FLAC Tests
+
+const char*  //
+wuffs_flac_decode(wuffs_base__io_buffer* dst,
+                  wuffs_base__io_buffer* src,
+                  uint32_t wuffs_initialize_flags,
+                  uint64_t wlimit,
+                  uint64_t rlimit) {
+  wuffs_flac__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_flac__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION, wuffs_initialize_flags));
+
+  // The first decode_frame call also decodes the header.
+  while (true) {
+    wuffs_base__io_buffer limited_dst = make_limited_writer(*dst, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+
+    wuffs_base__status status = wuffs_flac__decoder__decode_frame(
+        &dec, &limited_dst, &limited_src, g_work_slice_u8);
+
+    dst->meta.wi += limited_dst.meta.wi;
+    src->meta.ri += limited_src.meta.ri;
+
+    if (status.repr == wuffs_base__note__end_of_data) {
+      return NULL;
+    } else if ((status.repr == NULL) ||
+               ((wlimit < UINT64_MAX) &&
+                (status.repr == wuffs_base__suspension__short_write)) ||
+               ((rlimit < UINT64_MAX) &&
+                (status.repr == wuffs_base__suspension__short_read))) {
+      continue;
+    }
+    return status.repr;
+  }
+}
+
+const char*  //
+do_test_wuffs_flac_decode_bad(size_t byte_offset,
+                              uint8_t xor_mask,
+                              const char* want_status) {
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, g_flac_romeo_gt.src_filename));
+  if (byte_offset >= src.meta.wi) {
+    RETURN_FAIL("source file was too short");
+  }
+  src.data.ptr[byte_offset] ^= xor_mask;
+
+  const char* have_status =
+      wuffs_flac_decode(&have, &src, WUFFS_INITIALIZE__DEFAULT_OPTIONS,
+                        UINT64_MAX, UINT64_MAX);
+  if (have_status != want_status) {
+    RETURN_FAIL("have \"%s\", want \"%s\"", have_status, want_status);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_flac_decode_bad_frame_checksum() {
+  CHECK_FOCUS(__func__);
+  // The last two bytes (of this 1165 byte file) are the last frame's CRC-16.
+  return do_test_wuffs_flac_decode_bad(1164, 0x01,
+                                       wuffs_flac__error__bad_frame_checksum);
+}
+
+const char*  //
+test_wuffs_flac_decode_bad_frame_header_checksum() {
+  CHECK_FOCUS(__func__);
+  // The first frame starts at offset 54, after the 4 byte magic, the 38 byte
+  // STREAMINFO block and the 12 byte PADDING block. Its header's byte 5 is
+  // the 8-bit "block size minus 1".
+  return do_test_wuffs_flac_decode_bad(
+      54 + 5, 0x01, wuffs_flac__error__bad_frame_header_checksum);
+}
+
+const char*  //
+test_wuffs_flac_decode_bad_header() {
+  CHECK_FOCUS(__func__);
+  // Byte 4 holds the first metadata block's type, which must be STREAMINFO.
+  return do_test_wuffs_flac_decode_bad(4, 0x01,
+                                       wuffs_flac__error__bad_header);
+}
+
+const char*  //
+test_wuffs_flac_decode_bad_workbuf_length() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, g_flac_romeo_gt.src_filename));
+
+  wuffs_flac__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_flac__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  CHECK_STATUS("decode_header",
+               wuffs_flac__decoder__decode_header(&dec, &src));
+
+  // Two channels times 64 samples times 4 bytes per sample.
+  uint64_t workbuf_len = wuffs_flac__decoder__workbuf_len(&dec).max_incl;
+  if (workbuf_len != 512) {
+    RETURN_FAIL("workbuf_len: have %" PRIu64 ", want 512", workbuf_len);
+  }
+
+  wuffs_base__status status = wuffs_flac__decoder__decode_frame(
+      &dec, &have, &src,
+      wuffs_base__make_slice_u8(g_work_slice_u8.ptr, workbuf_len - 1));
+  if (status.repr != wuffs_base__error__bad_workbuf_length) {
+    RETURN_FAIL("have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__error__bad_workbuf_length);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_flac_decode_header() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* filename;
+    uint32_t want_sample_rate;
+    uint32_t want_num_channels;
+    uint32_t want_bits_per_sample;
+    uint64_t want_total_samples;
+  } test_cases[] = {
+      {
+          .filename = "test/data/midsummer.txt.flac",
+          .want_sample_rate = 44100,
+          .want_num_channels = 1,
+          .want_bits_per_sample = 8,
+          .want_total_samples = 11065,
+      },
+      {
+          .filename = "test/data/romeo.txt.flac",
+          .want_sample_rate = 44100,
+          .want_num_channels = 2,
+          .want_bits_per_sample = 8,
+          .want_total_samples = 471,
+      },
+      {
+          .filename = "test/data/romeo.txt.s24.flac",
+          .want_sample_rate = 48000,
+          .want_num_channels = 2,
+          .want_bits_per_sample = 24,
+          .want_total_samples = 157,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+        .data = g_src_slice_u8,
+    });
+    CHECK_STRING(read_file(&src, test_cases[tc].filename));
+
+    wuffs_flac__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_flac__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    CHECK_STATUS("decode_header",
+                 wuffs_flac__decoder__decode_header(&dec, &src));
+
+    uint32_t have_sample_rate = wuffs_flac__decoder__sample_rate(&dec);
+    if (have_sample_rate != test_cases[tc].want_sample_rate) {
+      RETURN_FAIL("tc=%d: sample_rate: have %" PRIu32 ", want %" PRIu32, tc,
+                  have_sample_rate, test_cases[tc].want_sample_rate);
+    }
+    uint32_t have_num_channels = wuffs_flac__decoder__num_channels(&dec);
+    if (have_num_channels != test_cases[tc].want_num_channels) {
+      RETURN_FAIL("tc=%d: num_channels: have %" PRIu32 ", want %" PRIu32, tc,
+                  have_num_channels, test_cases[tc].want_num_channels);
+    }
+    uint32_t have_bits_per_sample = wuffs_flac__decoder__bits_per_sample(&dec);
+    if (have_bits_per_sample != test_cases[tc].want_bits_per_sample) {
+      RETURN_FAIL("tc=%d: bits_per_sample: have %" PRIu32 ", want %" PRIu32,
+                  tc, have_bits_per_sample,
+                  test_cases[tc].want_bits_per_sample);
+    }
+    uint64_t have_total_samples = wuffs_flac__decoder__total_samples(&dec);
+    if (have_total_samples != test_cases[tc].want_total_samples) {
+      RETURN_FAIL("tc=%d: total_samples: have %" PRIu64 ", want %" PRIu64,
+                  tc, have_total_samples, test_cases[tc].want_total_samples);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_flac_decode_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_flac_decode, &g_flac_midsummer_gt,
+                            UINT64_MAX, UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_flac_decode_romeo() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_flac_decode, &g_flac_romeo_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_flac_decode_romeo_limited() {
+  CHECK_FOCUS(__func__);
+  // The src and dst buffers are limited, so that the decoder has to suspend
+  // (and resume) many times.
+  return do_test_io_buffers(wuffs_flac_decode, &g_flac_romeo_gt, 7, 5);
+}
+
+const char*  //
+test_wuffs_flac_decode_romeo_s16() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+
+  // The romeo.txt.s16.flac samples are the romeo.txt bytes shifted left by 8
+  // bits, so that every subframe has 8 "wasted bits".
+  CHECK_STRING(read_file(&src, "test/data/romeo.txt"));
+  size_t i;
+  for (i = 0; i < src.meta.wi; i++) {
+    want.data.ptr[want.meta.wi++] = 0x00;
+    want.data.ptr[want.meta.wi++] = src.data.ptr[i];
+  }
+
+  src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/romeo.txt.s16.flac"));
+  CHECK_STRING(wuffs_flac_decode(&have, &src, WUFFS_INITIALIZE__DEFAULT_OPTIONS,
+                                 UINT64_MAX, UINT64_MAX));
+  return check_io_buffers_equal("", &have, &want);
+}
+
+const char*  //
+test_wuffs_flac_decode_romeo_s24() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_flac_decode, &g_flac_romeo_s24_gt,
+                            UINT64_MAX, UINT64_MAX);
+}
+
+// ---------------- FLAC Benches
+
+const char*  //
+bench_wuffs_flac_decode_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_flac_decode, WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED,
+      tcounter_dst, &g_flac_midsummer_gt, UINT64_MAX, UINT64_MAX, 300);
+}
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_flac_decode_bad_frame_checksum,
+    test_wuffs_flac_decode_bad_frame_header_checksum,
+    test_wuffs_flac_decode_bad_header,
+    test_wuffs_flac_decode_bad_workbuf_length,
+    test_wuffs_flac_decode_header,
+    test_wuffs_flac_decode_midsummer,
+    test_wuffs_flac_decode_romeo,
+    test_wuffs_flac_decode_romeo_limited,
+    test_wuffs_flac_decode_romeo_s16,
+    test_wuffs_flac_decode_romeo_s24,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+    bench_wuffs_flac_decode_midsummer,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/flac";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -56,9 +56,18 @@ the first "./a.out" with "./a.out -bench". Combine these changes with the
 // code simply isn't compiled.
 #define WUFFS_CONFIG__MODULES
 #define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__CRC32
 #define WUFFS_CONFIG__MODULE__GIF
 #define WUFFS_CONFIG__MODULE__LZW
 
+// Defining WUFFS_CONFIG__METRICS is also optional. It enables the per-decoder
+// wuffs_base__metrics counters, which test_wuffs_gif_decode_metrics checks.
+#define WUFFS_CONFIG__METRICS
+
+// Likewise, WUFFS_CONFIG__OUTPUT_HASHER enables output hashers, which
+// test_wuffs_gif_decode_output_hasher checks.
+#define WUFFS_CONFIG__OUTPUT_HASHER
+
 // If building this program in an environment that doesn't easily accommodate
 // relative includes, you can use the script/inline-c-relative-includes.go
 // program to generate a stand-alone C file.
@@ -1268,6 +1277,86 @@ test_wuffs_gif_decode_interlaced_truncated() {
   return NULL;
 }
 
+const char*  //
+test_wuffs_gif_decode_limits() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  // The animated-red-blue.gif image is 64 x 48 pixels with 4 frames.
+  CHECK_STRING(read_file(&src, "test/data/animated-red-blue.gif"));
+
+  struct {
+    wuffs_base__image_decoder_limits limits;
+    uint64_t want_num_frames;
+    const char* want_final_status;
+  } test_cases[] = {
+      {.want_num_frames = 4,
+       .want_final_status = wuffs_base__note__end_of_data},
+      {.limits = {.max_width = 64,
+                  .max_height = 48,
+                  .max_num_pixels = 3072,
+                  .max_num_frames = 4},
+       .want_num_frames = 4,
+       .want_final_status = wuffs_base__note__end_of_data},
+      {.limits = {.max_width = 63},
+       .want_num_frames = 0,
+       .want_final_status = wuffs_base__error__resource_limit_exceeded},
+      {.limits = {.max_height = 47},
+       .want_num_frames = 0,
+       .want_final_status = wuffs_base__error__resource_limit_exceeded},
+      {.limits = {.max_num_pixels = 3071},
+       .want_num_frames = 0,
+       .want_final_status = wuffs_base__error__resource_limit_exceeded},
+      {.limits = {.max_num_frames = 2},
+       .want_num_frames = 2,
+       .want_final_status = wuffs_base__error__resource_limit_exceeded},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    src.meta.ri = 0;
+
+    wuffs_gif__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_gif__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_gif__decoder__set_limits(&dec, &test_cases[tc].limits);
+
+    // Passing a NULL dst means that decode_frame_config calls
+    // decode_image_config with a NULL dst, which should still check the
+    // image's dimensions.
+    uint64_t have_num_frames = 0;
+    wuffs_base__status status = wuffs_base__make_status(NULL);
+    while (true) {
+      status = wuffs_gif__decoder__decode_frame_config(&dec, NULL, &src);
+      if (!wuffs_base__status__is_ok(&status)) {
+        break;
+      }
+      have_num_frames++;
+    }
+
+    if (have_num_frames != test_cases[tc].want_num_frames) {
+      RETURN_FAIL("tc=%d: num_frames: have %" PRIu64 ", want %" PRIu64, tc,
+                  have_num_frames, test_cases[tc].want_num_frames);
+    } else if (status.repr != test_cases[tc].want_final_status) {
+      RETURN_FAIL("tc=%d: final status: have \"%s\", want \"%s\"", tc,
+                  status.repr, test_cases[tc].want_final_status);
+    } else if (!wuffs_base__status__is_error(&status)) {
+      continue;
+    }
+
+    // Like any other error, exceeding a limit disables the decoder.
+    status = wuffs_gif__decoder__decode_frame_config(&dec, NULL, &src);
+    if (status.repr != wuffs_base__error__disabled_by_previous_error) {
+      RETURN_FAIL("tc=%d: after error: have \"%s\", want \"%s\"", tc,
+                  status.repr, wuffs_base__error__disabled_by_previous_error);
+    }
+  }
+  return NULL;
+}
+
 const char*  //
 do_test_wuffs_gif_decode_metadata(bool full) {
   wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
@@ -1455,6 +1544,163 @@ test_wuffs_gif_decode_metadata_full() {
   return do_test_wuffs_gif_decode_metadata(true);
 }
 
+const char*  //
+test_wuffs_gif_decode_metrics() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/animated-red-blue.gif"));
+  size_t full_wi = src.meta.wi;
+
+  wuffs_gif__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_gif__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+
+  // Suspend once, part way through the header.
+  src.meta.wi = 10;
+  src.meta.closed = false;
+  wuffs_base__status status =
+      wuffs_gif__decoder__decode_image_config(&dec, &ic, &src);
+  if (status.repr != wuffs_base__suspension__short_read) {
+    RETURN_FAIL("decode_image_config: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__suspension__short_read);
+  }
+  src.meta.wi = full_wi;
+  src.meta.closed = true;
+  CHECK_STATUS("decode_image_config",
+               wuffs_gif__decoder__decode_image_config(&dec, &ic, &src));
+
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     &pb, &ic.pixcfg, g_pixel_slice_u8));
+  while (true) {
+    status = wuffs_gif__decoder__decode_frame(
+        &dec, &pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC, g_work_slice_u8, NULL);
+    if (status.repr == wuffs_base__note__end_of_data) {
+      break;
+    } else if (!wuffs_base__status__is_ok(&status)) {
+      RETURN_FAIL("decode_frame: \"%s\"", status.repr);
+    }
+  }
+
+  // decode_frame calls decode_frame_config, on the same decoder, but that
+  // should not count the same bytes twice.
+  wuffs_base__metrics m = wuffs_gif__decoder__metrics(&dec);
+  if (m.num_bytes_read != src.meta.ri) {
+    RETURN_FAIL("num_bytes_read: have %" PRIu64 ", want %" PRIu64,
+                m.num_bytes_read, (uint64_t)src.meta.ri);
+  } else if (m.num_bytes_written != 0) {
+    RETURN_FAIL("num_bytes_written: have %" PRIu64 ", want 0",
+                m.num_bytes_written);
+  } else if (m.num_frames_decoded != 4) {
+    RETURN_FAIL("num_frames_decoded: have %" PRIu64 ", want 4",
+                m.num_frames_decoded);
+  } else if (m.num_suspensions != 1) {
+    RETURN_FAIL("num_suspensions: have %" PRIu64 ", want 1",
+                m.num_suspensions);
+  } else if ((m.num_errors != 0) || (m.last_error.repr != NULL)) {
+    RETURN_FAIL("num_errors: have %" PRIu64 " (\"%s\"), want 0", m.num_errors,
+                m.last_error.repr);
+  }
+
+  // An error is counted and remembered, even though it disables the decoder.
+  src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/bricks-dither.png"));
+  CHECK_STATUS("initialize",
+               wuffs_gif__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  status = wuffs_gif__decoder__decode_image_config(&dec, &ic, &src);
+  if (status.repr != wuffs_gif__error__bad_header) {
+    RETURN_FAIL("decode_image_config: have \"%s\", want \"%s\"", status.repr,
+                wuffs_gif__error__bad_header);
+  }
+  m = wuffs_gif__decoder__metrics(&dec);
+  if (m.num_errors != 1) {
+    RETURN_FAIL("num_errors: have %" PRIu64 ", want 1", m.num_errors);
+  } else if (m.last_error.repr != wuffs_gif__error__bad_header) {
+    RETURN_FAIL("last_error: have \"%s\", want \"%s\"", m.last_error.repr,
+                wuffs_gif__error__bad_header);
+  } else if (m.num_frames_decoded != 0) {
+    RETURN_FAIL("num_frames_decoded: have %" PRIu64 ", want 0",
+                m.num_frames_decoded);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_gif_decode_output_hasher() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  CHECK_STRING(read_file(&want, "test/data/bricks-dither.indexes"));
+  wuffs_crc32__ieee_hasher want_h;
+  CHECK_STATUS("initialize",
+               wuffs_crc32__ieee_hasher__initialize(
+                   &want_h, sizeof want_h, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  uint32_t want_checksum = wuffs_crc32__ieee_hasher__update_u32(
+      &want_h, wuffs_base__io_buffer__reader_slice(&want));
+
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/bricks-dither.gif"));
+
+  wuffs_gif__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_gif__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_crc32__ieee_hasher have_h;
+  CHECK_STATUS("initialize",
+               wuffs_crc32__ieee_hasher__initialize(
+                   &have_h, sizeof have_h, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_gif__decoder__set_output_hasher(
+      &dec,
+      wuffs_crc32__ieee_hasher__upcast_as__wuffs_base__hasher_u32(&have_h));
+
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config",
+               wuffs_gif__decoder__decode_image_config(&dec, &ic, &src));
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     &pb, &ic.pixcfg, g_pixel_slice_u8));
+
+  // Nothing is hashed until decode_frame completes.
+  uint64_t full_wi = src.meta.wi;
+  src.meta.wi = src.meta.ri + 100;
+  src.meta.closed = false;
+  wuffs_base__status status = wuffs_gif__decoder__decode_frame(
+      &dec, &pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC, g_work_slice_u8, NULL);
+  if (status.repr != wuffs_base__suspension__short_read) {
+    RETURN_FAIL("decode_frame: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__suspension__short_read);
+  }
+  src.meta.wi = full_wi;
+  src.meta.closed = true;
+  CHECK_STATUS("decode_frame",
+               wuffs_gif__decoder__decode_frame(&dec, &pb, &src,
+                                                WUFFS_BASE__PIXEL_BLEND__SRC,
+                                                g_work_slice_u8, NULL));
+
+  uint32_t have_checksum = wuffs_crc32__ieee_hasher__update_u32(
+      &have_h, wuffs_base__empty_slice_u8());
+  if (have_checksum != want_checksum) {
+    RETURN_FAIL("checksum: have 0x%08" PRIX32 ", want 0x%08" PRIX32,
+                have_checksum, want_checksum);
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_gif_decode_missing_two_src_bytes() {
   CHECK_FOCUS(__func__);
@@ -1581,6 +1827,27 @@ test_wuffs_gif_decode_nul_byte_trailer() {
   return do_test_wuffs_gif_decode_expecting(src, 0, NULL, false);
 }
 
+const char*  //
+test_wuffs_gif_decode_passes() {
+  CHECK_FOCUS(__func__);
+
+  // The GIF interlacing has 4 passes and so 3 notes.
+  wuffs_gif__decoder full;
+  CHECK_STATUS("initialize (full)",
+               wuffs_gif__decoder__initialize(
+                   &full, sizeof full, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_gif__decoder passes;
+  CHECK_STATUS("initialize (passes)",
+               wuffs_gif__decoder__initialize(
+                   &passes, sizeof passes, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  return do_test__wuffs_base__image_decoder__passes(
+      wuffs_gif__decoder__upcast_as__wuffs_base__image_decoder(&full),
+      wuffs_gif__decoder__upcast_as__wuffs_base__image_decoder(&passes),
+      "test/data/hippopotamus.interlaced.gif", 3);
+}
+
 const char*  //
 test_wuffs_gif_decode_pixel_data_none() {
   CHECK_FOCUS(__func__);
@@ -1632,6 +1899,88 @@ test_wuffs_gif_decode_pixel_data_too_much_with_quirk() {
       src, WUFFS_GIF__QUIRK_IGNORE_TOO_MUCH_PIXEL_DATA, NULL, false);
 }
 
+const char*  //
+test_wuffs_gif_decode_row_groups() {
+  CHECK_FOCUS(__func__);
+  const char* filenames[] = {
+      "test/data/bricks-dither.gif",
+      "test/data/hippopotamus.regular.gif",
+      "test/data/pjw-thumbnail.gif",
+  };
+  const uint32_t row_group_heights[] = {1, 7, 20, 100};
+  size_t i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(filenames); i++) {
+    size_t j;
+    for (j = 0; j < WUFFS_TESTLIB_ARRAY_SIZE(row_group_heights); j++) {
+      wuffs_gif__decoder full;
+      CHECK_STATUS("initialize (full)",
+                   wuffs_gif__decoder__initialize(
+                       &full, sizeof full, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+      wuffs_gif__decoder grouped;
+      CHECK_STATUS("initialize (grouped)",
+                   wuffs_gif__decoder__initialize(
+                       &grouped, sizeof grouped, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+      CHECK_STRING(do_test__wuffs_base__image_decoder__row_groups(
+          wuffs_gif__decoder__upcast_as__wuffs_base__image_decoder(&full),
+          wuffs_gif__decoder__upcast_as__wuffs_base__image_decoder(&grouped),
+          filenames[i], row_group_heights[j]));
+    }
+  }
+
+  const char* animated_filenames[] = {
+      "test/data/animated-red-blue.gif",
+      "test/data/muybridge.gif",
+  };
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(animated_filenames); i++) {
+    size_t j;
+    for (j = 0; j < WUFFS_TESTLIB_ARRAY_SIZE(row_group_heights); j++) {
+      wuffs_gif__decoder full;
+      CHECK_STATUS("initialize (full)",
+                   wuffs_gif__decoder__initialize(
+                       &full, sizeof full, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+      wuffs_gif__decoder grouped;
+      CHECK_STATUS("initialize (grouped)",
+                   wuffs_gif__decoder__initialize(
+                       &grouped, sizeof grouped, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+      CHECK_STRING(do_test__wuffs_base__image_decoder__row_groups_animated(
+          wuffs_gif__decoder__upcast_as__wuffs_base__image_decoder(&full),
+          wuffs_gif__decoder__upcast_as__wuffs_base__image_decoder(&grouped),
+          animated_filenames[i], row_group_heights[j]));
+    }
+  }
+
+  // Row groups are unsupported for interlaced frames.
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hippopotamus.interlaced.gif"));
+  wuffs_gif__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_gif__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config",
+               wuffs_gif__decoder__decode_image_config(&dec, &ic, &src));
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     &pb, &ic.pixcfg, g_pixel_slice_u8));
+  wuffs_base__decode_frame_options opts =
+      wuffs_base__null_decode_frame_options();
+  wuffs_base__decode_frame_options__set_row_group_height(&opts, 5);
+  wuffs_base__status status = wuffs_gif__decoder__decode_frame(
+      &dec, &pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC, g_work_slice_u8, &opts);
+  if (status.repr != wuffs_base__error__unsupported_option) {
+    RETURN_FAIL("decode_frame (interlaced): have \"%s\", want \"%s\"",
+                status.repr, wuffs_base__error__unsupported_option);
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_gif_frame_dirty_rect() {
   CHECK_FOCUS(__func__);
@@ -2334,12 +2683,16 @@ proc g_tests[] = {
     test_wuffs_gif_decode_input_is_a_png,
     test_wuffs_gif_decode_interface_image_decoder,
     test_wuffs_gif_decode_interlaced_truncated,
+    test_wuffs_gif_decode_limits,
     test_wuffs_gif_decode_metadata_empty,
     test_wuffs_gif_decode_metadata_full,
+    test_wuffs_gif_decode_metrics,
+    test_wuffs_gif_decode_output_hasher,
     test_wuffs_gif_decode_missing_two_src_bytes,
     test_wuffs_gif_decode_multiple_graphic_controls,
     test_wuffs_gif_decode_multiple_loop_counts,
     test_wuffs_gif_decode_nul_byte_trailer,
+    test_wuffs_gif_decode_passes,
     test_wuffs_gif_decode_pixel_data_none,
     test_wuffs_gif_decode_pixel_data_not_enough,
     test_wuffs_gif_decode_pixel_data_too_much_sans_quirk,
@@ -2349,6 +2702,7 @@ proc g_tests[] = {
     test_wuffs_gif_decode_pixfmt_bgra_nonpremul,
     test_wuffs_gif_decode_pixfmt_rgb,
     test_wuffs_gif_decode_pixfmt_rgba_nonpremul,
+    test_wuffs_gif_decode_row_groups,
     test_wuffs_gif_decode_zero_width_frame,
     test_wuffs_gif_frame_dirty_rect,
     test_wuffs_gif_num_decoded_frame_configs,
@@ -60,6 +60,11 @@ the first "./a.out" with "./a.out -bench". Combine these changes with the
 #define WUFFS_CONFIG__MODULE__DEFLATE
 #define WUFFS_CONFIG__MODULE__GZIP
 
+// Defining WUFFS_CONFIG__OUTPUT_HASHER is also optional. It enables
+// wuffs_gzip__decoder__set_output_hasher, which
+// test_wuffs_gzip_decode_output_hasher checks.
+#define WUFFS_CONFIG__OUTPUT_HASHER
+
 // If building this program in an environment that doesn't easily accommodate
 // relative includes, you can use the script/inline-c-relative-includes.go
 // program to generate a stand-alone C file.
@@ -216,6 +221,94 @@ test_wuffs_gzip_checksum_verify_good() {
   return do_test_wuffs_gzip_checksum(false, 0);
 }
 
+const char*  //
+test_wuffs_gzip_decode_metadata() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  // Patch the FNAME's first byte from 'm' to 0xE9, which is 'é' in ISO 8859-1.
+  CHECK_STRING(read_file(&src, "@000A=6D=E9;test/data/midsummer.txt.gz"));
+
+  wuffs_gzip__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_gzip__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_gzip__decoder__set_report_metadata(&dec, WUFFS_BASE__FOURCC__CMNT,
+                                           true);
+  wuffs_gzip__decoder__set_report_metadata(&dec, WUFFS_BASE__FOURCC__MTIM,
+                                           true);
+  wuffs_gzip__decoder__set_report_metadata(&dec, WUFFS_BASE__FOURCC__NAME,
+                                           true);
+
+  // The file has no FCOMMENT, so only MTIME and FNAME are reported.
+  wuffs_base__status status = wuffs_gzip__decoder__transform_io(
+      &dec, &have, &src, g_work_slice_u8);
+  if (status.repr != wuffs_base__note__metadata_reported) {
+    RETURN_FAIL("transform_io #0: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__note__metadata_reported);
+  }
+  wuffs_base__more_information minfo = wuffs_base__empty_more_information();
+  CHECK_STATUS("tell_me_more #0", wuffs_gzip__decoder__tell_me_more(
+                                      &dec, &have, &minfo, &src));
+  if (minfo.flavor != WUFFS_BASE__MORE_INFORMATION__FLAVOR__METADATA_PARSED) {
+    RETURN_FAIL("flavor #0: have %" PRIu32 ", want %" PRIu32, minfo.flavor,
+                WUFFS_BASE__MORE_INFORMATION__FLAVOR__METADATA_PARSED);
+  } else if (minfo.w != WUFFS_BASE__FOURCC__MTIM) {
+    RETURN_FAIL("fourcc #0: have 0x%08" PRIX32 ", want 0x%08" PRIX32, minfo.w,
+                WUFFS_BASE__FOURCC__MTIM);
+  } else if (minfo.x != 1499322359) {
+    RETURN_FAIL("mtime: have %" PRIu64 ", want 1499322359", minfo.x);
+  }
+
+  status = wuffs_gzip__decoder__transform_io(&dec, &have, &src,
+                                             g_work_slice_u8);
+  if (status.repr != wuffs_base__note__metadata_reported) {
+    RETURN_FAIL("transform_io #1: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__note__metadata_reported);
+  }
+
+  // Write the FNAME one byte at a time, so that tell_me_more has to suspend.
+  minfo = wuffs_base__empty_more_information();
+  while (true) {
+    wuffs_base__io_buffer limited_have = make_limited_writer(have, 1);
+    status = wuffs_gzip__decoder__tell_me_more(&dec, &limited_have, &minfo,
+                                               &src);
+    have.meta.wi += limited_have.meta.wi;
+    if (status.repr != wuffs_base__suspension__short_write) {
+      break;
+    }
+  }
+  if (!wuffs_base__status__is_ok(&status)) {
+    RETURN_FAIL("tell_me_more #1: \"%s\"", status.repr);
+  } else if (minfo.flavor !=
+             WUFFS_BASE__MORE_INFORMATION__FLAVOR__METADATA_RAW_TRANSFORM) {
+    RETURN_FAIL("flavor #1: have %" PRIu32 ", want %" PRIu32, minfo.flavor,
+                WUFFS_BASE__MORE_INFORMATION__FLAVOR__METADATA_RAW_TRANSFORM);
+  } else if (minfo.w != WUFFS_BASE__FOURCC__NAME) {
+    RETURN_FAIL("fourcc #1: have 0x%08" PRIX32 ", want 0x%08" PRIX32, minfo.w,
+                WUFFS_BASE__FOURCC__NAME);
+  }
+  const char* want_name = "\xC3\xA9idsummer.txt";
+  if ((have.meta.wi != strlen(want_name)) ||
+      memcmp(have.data.ptr, want_name, have.meta.wi)) {
+    RETURN_FAIL("name: have \"%.*s\", want \"%s\"", (int)(have.meta.wi),
+                have.data.ptr, want_name);
+  }
+
+  have.meta.wi = 0;
+  CHECK_STATUS("transform_io #2", wuffs_gzip__decoder__transform_io(
+                                      &dec, &have, &src, g_work_slice_u8));
+  if (have.meta.wi != 11065) {
+    RETURN_FAIL("decoded length: have %zu, want 11065", have.meta.wi);
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_gzip_decode_midsummer() {
   CHECK_FOCUS(__func__);
@@ -223,6 +316,118 @@ test_wuffs_gzip_decode_midsummer() {
                             UINT64_MAX);
 }
 
+const char*  //
+test_wuffs_gzip_decode_multiple_members() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+
+  // Concatenate two gzip files, like "gzip -c romeo.txt midsummer.txt", and
+  // then append two bytes of trailing garbage.
+  CHECK_STRING(read_file(&src, "test/data/romeo.txt.gz"));
+  src.meta.closed = false;
+  CHECK_STRING(read_file(&src, g_gzip_midsummer_gt.src_filename));
+  if ((src.data.len - src.meta.wi) < 2) {
+    RETURN_FAIL("source buffer was too short");
+  }
+  src.data.ptr[src.meta.wi++] = 0x00;
+  src.data.ptr[src.meta.wi++] = 0x1F;
+  CHECK_STRING(read_file(&want, "test/data/romeo.txt"));
+  want.meta.closed = false;
+  CHECK_STRING(read_file(&want, g_gzip_midsummer_gt.want_filename));
+
+  const uint64_t rlimits[2] = {UINT64_MAX, 13};
+  int i;
+  for (i = 0; i < 2; i++) {
+    have.meta.wi = 0;
+    src.meta.ri = 0;
+    CHECK_STRING(wuffs_gzip_decode(
+        &have, &src, WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED,
+        UINT64_MAX, rlimits[i]));
+    CHECK_STRING(check_io_buffers_equal("", &have, &want));
+    if (src.meta.ri != (src.meta.wi - 2)) {
+      RETURN_FAIL("i=%d: ri: have %zu, want %zu", i, src.meta.ri,
+                  src.meta.wi - 2);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_gzip_decode_output_hasher() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, g_gzip_midsummer_gt.src_filename));
+
+  // The gzip format's trailer holds the CRC-32 checksum of the decoded output,
+  // which the attached hasher should re-compute.
+  if (src.meta.wi < 8) {
+    RETURN_FAIL("source file was too short");
+  }
+  uint32_t want = wuffs_base__peek_u32le__no_bounds_check(src.data.ptr +
+                                                          src.meta.wi - 8);
+
+  // Short writes make transform_io suspend, and the caller drains the dst
+  // buffer, between its calls. Every byte should still be hashed once.
+  const uint64_t wlimits[4] = {1, 7, 4096, UINT64_MAX};
+  int i;
+  for (i = 0; i < 4; i++) {
+    wuffs_gzip__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_gzip__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_crc32__ieee_hasher h;
+    CHECK_STATUS("initialize",
+                 wuffs_crc32__ieee_hasher__initialize(
+                     &h, sizeof h, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_gzip__decoder__set_output_hasher(
+        &dec, wuffs_crc32__ieee_hasher__upcast_as__wuffs_base__hasher_u32(&h));
+    src.meta.ri = 0;
+
+    uint64_t num_bytes = 0;
+    while (true) {
+      have.meta.ri = 0;
+      have.meta.wi = 0;
+      wuffs_base__io_buffer limited_have =
+          make_limited_writer(have, wlimits[i]);
+      wuffs_base__status status = wuffs_gzip__decoder__transform_io(
+          &dec, &limited_have, &src, g_work_slice_u8);
+      num_bytes += limited_have.meta.wi;
+      if (status.repr == wuffs_base__suspension__short_write) {
+        continue;
+      } else if (!wuffs_base__status__is_ok(&status)) {
+        RETURN_FAIL("i=%d: transform_io: \"%s\"", i, status.repr);
+      }
+      break;
+    }
+
+    uint32_t have_checksum = wuffs_crc32__ieee_hasher__update_u32(
+        &h, wuffs_base__empty_slice_u8());
+    if (have_checksum != want) {
+      RETURN_FAIL("i=%d: checksum: have 0x%08" PRIX32 ", want 0x%08" PRIX32,
+                  i, have_checksum, want);
+    } else if (num_bytes != 11065) {
+      RETURN_FAIL("i=%d: num_bytes: have %" PRIu64 ", want 11065", i,
+                  num_bytes);
+    }
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_gzip_decode_pi() {
   CHECK_FOCUS(__func__);
@@ -300,7 +505,10 @@ proc g_tests[] = {
     test_wuffs_gzip_checksum_verify_bad7,
     test_wuffs_gzip_checksum_verify_good,
     test_wuffs_gzip_decode_interface,
+    test_wuffs_gzip_decode_metadata,
     test_wuffs_gzip_decode_midsummer,
+    test_wuffs_gzip_decode_multiple_members,
+    test_wuffs_gzip_decode_output_hasher,
     test_wuffs_gzip_decode_pi,
 
 #ifdef WUFFS_MIMIC
@@ -0,0 +1,417 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror hdr.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__HDR
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- HDR Tests
+
+// decode_hdr_f32 decodes src (an HDR image of at most 4 pixels) to
+// RGBA_NONPREMUL_4XF32LE pixels, writing each pixel's R, G, B and A bits to
+// have.
+const char*  //
+decode_hdr_f32(uint32_t have[16], const char* src_ptr, size_t src_len) {
+  wuffs_hdr__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_hdr__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  wuffs_base__io_buffer src =
+      wuffs_base__ptr_u8__reader((uint8_t*)(src_ptr), src_len, true);
+  CHECK_STATUS("decode_image_config",
+               wuffs_hdr__decoder__decode_image_config(&dec, &ic, &src));
+  uint32_t width = wuffs_base__pixel_config__width(&ic.pixcfg);
+  uint32_t height = wuffs_base__pixel_config__height(&ic.pixcfg);
+  if ((width * height) > 4) {
+    RETURN_FAIL("width * height: have %" PRIu32 ", want at most 4",
+                width * height);
+  }
+
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     &pb, &ic.pixcfg, g_pixel_slice_u8));
+  CHECK_STATUS("decode_frame",
+               wuffs_hdr__decoder__decode_frame(&dec, &pb, &src,
+                                                WUFFS_BASE__PIXEL_BLEND__SRC,
+                                                g_work_slice_u8, NULL));
+
+  wuffs_base__table_u8 tab = wuffs_base__pixel_buffer__plane(&pb, 0);
+  uint32_t i = 0;
+  uint32_t y;
+  for (y = 0; y < height; y++) {
+    uint8_t* row = tab.ptr + (y * tab.stride);
+    uint32_t j;
+    for (j = 0; j < (4 * width); j++) {
+      have[i++] = wuffs_base__peek_u32le__no_bounds_check(row + (4 * j));
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_hdr_decode_frame_config() {
+  CHECK_FOCUS(__func__);
+  wuffs_hdr__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_hdr__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__frame_config fc = ((wuffs_base__frame_config){});
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hippopotamus.hdr"));
+  CHECK_STATUS("decode_frame_config #0",
+               wuffs_hdr__decoder__decode_frame_config(&dec, &fc, &src));
+
+  uint64_t have_io_position = wuffs_base__frame_config__io_position(&fc);
+  if (have_io_position != 47) {
+    RETURN_FAIL("io_position: have %" PRIu64 ", want 47", have_io_position);
+  }
+
+  wuffs_base__status status =
+      wuffs_hdr__decoder__decode_frame_config(&dec, &fc, &src);
+  if (status.repr != wuffs_base__note__end_of_data) {
+    RETURN_FAIL("decode_frame_config #1: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__note__end_of_data);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_hdr_decode_interface() {
+  CHECK_FOCUS(__func__);
+
+  const char* filenames[] = {
+      "test/data/hippopotamus.flat.hdr",
+      "test/data/hippopotamus.hdr",
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(filenames); tc++) {
+    wuffs_hdr__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_hdr__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    const char* have = do_test__wuffs_base__image_decoder(
+        wuffs_hdr__decoder__upcast_as__wuffs_base__image_decoder(&dec),
+        filenames[tc], 0, SIZE_MAX, 36, 28, 0xFFF4F4F4);
+    if (have) {
+      RETURN_FAIL("tc=%d (%s): %s", tc, filenames[tc], have);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_hdr_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  const struct {
+    const char* src;
+    const char* want;
+  } tcs[] = {
+      {
+          .src = "#!RADIANCE\n\n-Y 1 +X 1\n\x80\x80\x80\x81",
+          .want = wuffs_hdr__error__bad_header,
+      },
+      {
+          .src = "#?RADIANCE\nFORMAT=32-bit_rle_xyze\n\n-Y 1 +X 1\n",
+          .want = wuffs_hdr__error__unsupported_hdr_file,
+      },
+      {
+          .src = "#?RADIANCE\n\n+X 1 -Y 1\n\x80\x80\x80\x81",
+          .want = wuffs_hdr__error__unsupported_hdr_file,
+      },
+      {
+          .src = "#?RADIANCE\n\n-Y 1 -X 1\n\x80\x80\x80\x81",
+          .want = wuffs_hdr__error__unsupported_hdr_file,
+      },
+      {
+          .src = "#?RADIANCE\n\n-Y 1 +X\n\x80\x80\x80\x81",
+          .want = wuffs_hdr__error__bad_header,
+      },
+      {
+          .src = "#?RADIANCE\n\n-Y 1 +X 99999999\n",
+          .want = wuffs_hdr__error__unsupported_hdr_file,
+      },
+      {
+          // A repeat with no previous pixel.
+          .src = "#?RADIANCE\n\n-Y 1 +X 1\n\x01\x01\x01\x01",
+          .want = wuffs_hdr__error__bad_scanline,
+      },
+      {
+          // Too many repeats.
+          .src = "#?RADIANCE\n\n-Y 1 +X 2\n\x80\x80\x80\x81\x01\x01\x01\x02",
+          .want = wuffs_hdr__error__bad_scanline,
+      },
+      {
+          // A new style scanline with the wrong width.
+          .src = "#?RADIANCE\n\n-Y 1 +X 264\n\x02\x02\x01\x09",
+          .want = wuffs_hdr__error__bad_scanline,
+      },
+      {
+          // A new style scanline with too long a run: 127 + 127 + 11 > 264.
+          .src = "#?RADIANCE\n\n-Y 1 +X 264\n\x02\x02\x01\x08"
+                 "\xFF\x01\xFF\x01\x8B\x01",
+          .want = wuffs_hdr__error__bad_scanline,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(tcs); tc++) {
+    wuffs_hdr__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_hdr__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__pixel_config pixcfg = ((wuffs_base__pixel_config){});
+    wuffs_base__pixel_config__set(&pixcfg,
+                                  WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+                                  WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, 264, 1);
+    wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+    CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                       &pb, &pixcfg, g_pixel_slice_u8));
+
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)(tcs[tc].src), strlen(tcs[tc].src), true);
+    wuffs_base__status status = wuffs_hdr__decoder__decode_frame(
+        &dec, &pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC, g_work_slice_u8, NULL);
+    if (status.repr != tcs[tc].want) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, status.repr,
+                  tcs[tc].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_hdr_decode_old_style() {
+  CHECK_FOCUS(__func__);
+
+  // The pixel (0x80, 0x40, 0x20, 0x81) is (1.0, 0.5, 0.25). The (1, 1, 1, 2)
+  // pixel repeats it twice.
+  static const char src[] =
+      "#?RGBE\n# A comment.\nEXPOSURE=1.0\n\n-Y 1 +X 3\n"
+      "\x80\x40\x20\x81\x01\x01\x01\x02";
+  uint32_t have[16] = {0};
+  CHECK_STRING(decode_hdr_f32(have, src, sizeof(src) - 1));
+
+  int i;
+  for (i = 0; i < 12; i++) {
+    static const uint32_t want[4] = {0x3F800000, 0x3F000000, 0x3E800000,
+                                     0x3F800000};
+    if (have[i] != want[i & 3]) {
+      RETURN_FAIL("i=%d: have 0x%08" PRIX32 ", want 0x%08" PRIX32, i, have[i],
+                  want[i & 3]);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_hdr_decode_bottom_up() {
+  CHECK_FOCUS(__func__);
+
+  // The first scanline (2.0) is the bottom row. The second scanline (zero,
+  // as its exponent is zero) is the top row.
+  static const char src[] =
+      "#?RADIANCE\n\n+Y 2 +X 1\n"
+      "\x80\x80\x80\x82\x80\x80\x80\x00";
+  uint32_t have[16] = {0};
+  CHECK_STRING(decode_hdr_f32(have, src, sizeof(src) - 1));
+
+  static const uint32_t want[8] = {
+      0x00000000, 0x00000000, 0x00000000, 0x3F800000,  //
+      0x40000000, 0x40000000, 0x40000000, 0x3F800000,  //
+  };
+  int i;
+  for (i = 0; i < 8; i++) {
+    if (have[i] != want[i]) {
+      RETURN_FAIL("i=%d: have 0x%08" PRIX32 ", want 0x%08" PRIX32, i, have[i],
+                  want[i]);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_hdr_decode_quirk_rgbe_pixels() {
+  CHECK_FOCUS(__func__);
+  wuffs_hdr__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_hdr__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_hdr__decoder__set_quirk_enabled(&dec, WUFFS_HDR__QUIRK_RGBE_PIXELS,
+                                        true);
+
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hippopotamus.hdr"));
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config",
+               wuffs_hdr__decoder__decode_image_config(&dec, &ic, &src));
+  if (ic.pixcfg.private_impl.pixfmt.repr !=
+      WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL) {
+    RETURN_FAIL("pixfmt: have 0x%08" PRIX32 ", want 0x%08" PRIX32,
+                ic.pixcfg.private_impl.pixfmt.repr,
+                (uint32_t)WUFFS_BASE__PIXEL_FORMAT__RGBA_NONPREMUL);
+  }
+
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     &pb, &ic.pixcfg, g_pixel_slice_u8));
+  CHECK_STATUS("decode_frame",
+               wuffs_hdr__decoder__decode_frame(&dec, &pb, &src,
+                                                WUFFS_BASE__PIXEL_BLEND__SRC,
+                                                g_work_slice_u8, NULL));
+
+  // The flat (uncompressed) version of the file holds the RGBE bytes as-is,
+  // after a 47 byte header.
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  CHECK_STRING(read_file(&want, "test/data/hippopotamus.flat.hdr"));
+  if ((want.meta.wi - 47) != (36 * 28 * 4)) {
+    RETURN_FAIL("want length: have %zu, want %d", want.meta.wi - 47,
+                36 * 28 * 4);
+  }
+  wuffs_base__table_u8 tab = wuffs_base__pixel_buffer__plane(&pb, 0);
+  uint32_t y;
+  for (y = 0; y < 28; y++) {
+    if (memcmp(tab.ptr + (y * tab.stride), want.data.ptr + 47 + (y * 36 * 4),
+               36 * 4)) {
+      RETURN_FAIL("y=%" PRIu32 ": pixels differ", y);
+    }
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- HDR Benches
+
+// No HDR benches.
+
+// ---------------- Mimic Benches
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_hdr_decode_bottom_up,
+    test_wuffs_hdr_decode_frame_config,
+    test_wuffs_hdr_decode_interface,
+    test_wuffs_hdr_decode_invalid,
+    test_wuffs_hdr_decode_old_style,
+    test_wuffs_hdr_decode_quirk_rgbe_pixels,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No HDR benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/hdr";
+  return test_main(argc, argv, g_tests, g_benches);
+}
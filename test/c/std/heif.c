@@ -0,0 +1,393 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror heif.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__HEIF
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+// No HEIF golden tests.
+
+// ---------------- HEIF Tests
+
+// wuffs_heif_decode decodes src into tok, limiting each decode_tokens call to
+// wlimit tokens and rlimit bytes.
+const char*  //
+wuffs_heif_decode(wuffs_base__token_buffer* tok,
+                  wuffs_base__io_buffer* src,
+                  uint64_t wlimit,
+                  uint64_t rlimit) {
+  wuffs_heif__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_heif__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  while (true) {
+    wuffs_base__token_buffer limited_tok =
+        make_limited_token_writer(*tok, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+
+    wuffs_base__status status = wuffs_heif__decoder__decode_tokens(
+        &dec, &limited_tok, &limited_src, g_work_slice_u8);
+
+    tok->meta.wi += limited_tok.meta.wi;
+    src->meta.ri += limited_src.meta.ri;
+
+    if (((wlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_write)) ||
+        ((rlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_read))) {
+      continue;
+    }
+    return status.repr;
+  }
+}
+
+// heif_summarize writes a summary of the tokens to dst, which has a capacity
+// of at least 1024 bytes. Each record is summarized as "name(v0,v1,etc)", with
+// four-character codes (such as brands and item types) shown as text. It also
+// checks that the token lengths sum to src_len.
+const char*  //
+heif_summarize(char* dst, wuffs_base__token_buffer* tok, uint64_t src_len) {
+  static const char* names[] = {
+      "?",           "brand",       "primary", "type",
+      "extent",      "idat_extent", "idat",    "ref",
+      "association", "ispe",        "irot",    "icc",
+      "alpha",
+  };
+  // fourccs[m] has bit i set if the i'th value of record kind m is a
+  // four-character code.
+  static const uint8_t fourccs[] = {0, 1, 0, 2, 0, 0, 0, 1, 0, 0, 0, 0, 0};
+
+  char* d = dst;
+  uint64_t total_length = 0;
+  size_t i;
+  for (i = tok->meta.ri; i < tok->meta.wi; i++) {
+    wuffs_base__token* t = &tok->data.ptr[i];
+    total_length += wuffs_base__token__length(t);
+    if (wuffs_base__token__value_base_category(t) ==
+        WUFFS_BASE__TOKEN__VBC__FILLER) {
+      continue;
+    }
+    uint64_t m = wuffs_base__token__value_minor(t);
+    if ((wuffs_base__token__value_major(t) != WUFFS_HEIF__TOKEN_VALUE_MAJOR) ||
+        (m == 0) || (m >= WUFFS_TESTLIB_ARRAY_SIZE(names))) {
+      RETURN_FAIL("i=%zu: bad record token", i);
+    }
+    d += sprintf(d, "%s%s(", (d == dst) ? "" : " ", names[m]);
+    int j;
+    for (j = 0; wuffs_base__token__continued(&tok->data.ptr[i]); j++) {
+      if ((i + 2) >= tok->meta.wi) {
+        RETURN_FAIL("i=%zu: bad value tokens", i);
+      }
+      uint64_t x = wuffs_base__token__joined_u64(&tok->data.ptr[i + 1],
+                                                 &tok->data.ptr[i + 2]);
+      i += 2;
+      if (fourccs[m] & (1 << j)) {
+        d += sprintf(d, "%s%c%c%c%c", j ? "," : "", (char)(x >> 24),
+                     (char)(x >> 16), (char)(x >> 8), (char)(x >> 0));
+      } else {
+        d += sprintf(d, "%s%" PRIu64, j ? "," : "", x);
+      }
+    }
+    d += sprintf(d, ")");
+    if ((d - dst) > 960) {
+      RETURN_FAIL("summary is too long");
+    }
+  }
+  if (total_length != src_len) {
+    RETURN_FAIL("total length: have %" PRIu64 ", want %" PRIu64, total_length,
+                src_len);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_heif_decode_records() {
+  CHECK_FOCUS(__func__);
+
+  const char src_ptr[] =
+      // An "ftyp" box.
+      "\x00\x00\x00\x14" "ftypavif\x00\x00\x00\x00mif1"
+      // A "meta" box holding "hdlr", "pitm", "iloc", "iinf", "iref" and "iprp".
+      "\x00\x00\x01\x34meta\x00\x00\x00\x00"
+      "\x00\x00\x00\x0Chdlr\x00\x00\x00\x00"
+      "\x00\x00\x00\x0Epitm\x00\x00\x00\x00\x00\x01"
+      // Items 1 and 2 each have one extent, in the "mdat" box.
+      "\x00\x00\x00\x2Ciloc\x00\x00\x00\x00\x44\x00\x00\x02"
+      "\x00\x01\x00\x00\x00\x01\x00\x00\x01\x50\x00\x00\x00\x06"
+      "\x00\x02\x00\x00\x00\x01\x00\x00\x01\x56\x00\x00\x00\x03"
+      "\x00\x00\x00\x38iinf\x00\x00\x00\x00\x00\x02"
+      "\x00\x00\x00\x15infe\x02\x00\x00\x00\x00\x01\x00\x00" "av01\x00"
+      "\x00\x00\x00\x15infe\x02\x00\x00\x00\x00\x02\x00\x00" "av01\x00"
+      // Item 2 is an auxiliary image for item 1.
+      "\x00\x00\x00\x1Airef\x00\x00\x00\x00"
+      "\x00\x00\x00\x0E" "auxl\x00\x02\x00\x01\x00\x01"
+      "\x00\x00\x00\x90iprp"
+      // The properties are ispe (640x480), irot, colr (ICC) and auxC (alpha).
+      "\x00\x00\x00\x6Dipco"
+      "\x00\x00\x00\x14ispe\x00\x00\x00\x00\x00\x00\x02\x80\x00\x00\x01\xE0"
+      "\x00\x00\x00\x09irot\x01"
+      "\x00\x00\x00\x10" "colrprofICC!"
+      "\x00\x00\x00\x38" "auxC\x00\x00\x00\x00"
+      "urn:mpeg:mpegB:cicp:systems:auxiliary:alpha\x00"
+      "\x00\x00\x00\x1Bipma\x00\x00\x00\x00\x00\x00\x00\x02"
+      "\x00\x01\x03\x81\x82\x03"
+      "\x00\x02\x02\x81\x84"
+      // An "mdat" box that extends to the end of the file.
+      "\x00\x00\x00\x00mdatAV1AV1ALF";
+  const size_t src_len = sizeof(src_ptr) - 1;
+  const char* want =
+      "brand(avif) primary(1) extent(1,336,6) extent(2,342,3) type(1,av01) "
+      "type(2,av01) ref(auxl,2,1) ispe(1,640,480) irot(2,1) icc(3,241,4) "
+      "alpha(4) association(1,1,1) association(1,2,1) association(1,3,0) "
+      "association(2,1,1) association(2,4,1)";
+
+  int tc;
+  for (tc = 0; tc < 4; tc++) {
+    uint64_t wlimit =
+        (tc & 1) ? WUFFS_HEIF__DECODER_DST_TOKEN_BUFFER_LENGTH_MIN_INCL
+                 : UINT64_MAX;
+    uint64_t rlimit =
+        (tc & 2) ? WUFFS_HEIF__DECODER_SRC_IO_BUFFER_LENGTH_MIN_INCL
+                 : UINT64_MAX;
+
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src =
+        wuffs_base__ptr_u8__reader((uint8_t*)src_ptr, src_len, true);
+    CHECK_STRING(wuffs_heif_decode(&tok, &src, wlimit, rlimit));
+    if (src.meta.ri != src_len) {
+      RETURN_FAIL("tc=%d: src.meta.ri: have %zu, want %zu", tc, src.meta.ri,
+                  src_len);
+    }
+
+    char have[1024];
+    CHECK_STRING(heif_summarize(have, &tok, src_len));
+    if (strcmp(have, want)) {
+      RETURN_FAIL("tc=%d:\nhave \"%s\"\nwant \"%s\"", tc, have, want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_heif_decode_end_of_data() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_heif__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_heif__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  int i;
+  for (i = 0; i < 2; i++) {
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)("\x00\x00\x00\x08"
+                   "free"),
+        8, true);
+    wuffs_base__status status =
+        wuffs_heif__decoder__decode_tokens(&dec, &tok, &src, g_work_slice_u8);
+    const char* want = i ? wuffs_base__note__end_of_data : NULL;
+    if (status.repr != want) {
+      RETURN_FAIL("i=%d: have \"%s\", want \"%s\"", i, status.repr, want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_heif_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* want;
+    const char* src_ptr;
+    size_t src_len;
+  } test_cases[] = {
+      {
+          // Box size smaller than the 8-byte header.
+          .want = wuffs_heif__error__bad_box_size,
+          .src_ptr = "\x00\x00\x00\x07"
+                     "free",
+          .src_len = 8,
+      },
+      {
+          // Zero (to the end of the file) box size for a "meta" box.
+          .want = wuffs_heif__error__bad_box_size,
+          .src_ptr = "\x00\x00\x00\x00"
+                     "meta\x00\x00\x00\x00",
+          .src_len = 12,
+      },
+      {
+          // Child box larger than its parent.
+          .want = wuffs_heif__error__bad_box_size,
+          .src_ptr = "\x00\x00\x00\x14"
+                     "meta\x00\x00\x00\x00"
+                     "\x00\x00\x00\x09"
+                     "pitm",
+          .src_len = 20,
+      },
+      {
+          // A "pitm" box too small for its item_ID.
+          .want = wuffs_heif__error__bad_box_size,
+          .src_ptr = "\x00\x00\x00\x19"
+                     "meta\x00\x00\x00\x00"
+                     "\x00\x00\x00\x0D"
+                     "pitm\x00\x00\x00\x00\x01",
+          .src_len = 25,
+      },
+      {
+          // An "iloc" box with a 2-byte offset_size.
+          .want = wuffs_heif__error__bad_iloc_box,
+          .src_ptr = "\x00\x00\x00\x1E"
+                     "meta\x00\x00\x00\x00"
+                     "\x00\x00\x00\x12"
+                     "iloc\x00\x00\x00\x00\x24\x00\x00\x00",
+          .src_len = 30,
+      },
+      {
+          // Truncated header.
+          .want = wuffs_heif__error__truncated_input,
+          .src_ptr = "\x00\x00\x00\x08"
+                     "fre",
+          .src_len = 7,
+      },
+      {
+          // Truncated body.
+          .want = wuffs_heif__error__truncated_input,
+          .src_ptr = "\x00\x00\x00\x0A"
+                     "free\x00",
+          .src_len = 9,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)(test_cases[tc].src_ptr), test_cases[tc].src_len, true);
+    const char* have = wuffs_heif_decode(&tok, &src, UINT64_MAX, UINT64_MAX);
+    if (have != test_cases[tc].want) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, have,
+                  test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- HEIF Benches
+
+// No HEIF benches.
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_heif_decode_end_of_data,
+    test_wuffs_heif_decode_invalid,
+    test_wuffs_heif_decode_records,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No HEIF benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/heif";
+  return test_main(argc, argv, g_tests, g_benches);
+}
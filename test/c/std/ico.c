@@ -0,0 +1,359 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror ico.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__ADLER32
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__BMP
+#define WUFFS_CONFIG__MODULE__CRC32
+#define WUFFS_CONFIG__MODULE__DEFLATE
+#define WUFFS_CONFIG__MODULE__ICO
+#define WUFFS_CONFIG__MODULE__PNG
+#define WUFFS_CONFIG__MODULE__ZLIB
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- ICO Tests
+
+const char*  //
+test_wuffs_ico_decode_and_mask() {
+  CHECK_FOCUS(__func__);
+  wuffs_ico__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_ico__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  // A 2x2 ICO whose (bottom-up, 24 bits per pixel) BMP payload starts at
+  // offset 22. The AND mask makes the top-left and bottom-right pixels
+  // transparent.
+  static const uint8_t src_array[] = {
+      0x00, 0x00, 0x01, 0x00, 0x01, 0x00,                          //
+      0x02, 0x02, 0x00, 0x00, 0x01, 0x00, 0x18, 0x00,              //
+      0x40, 0x00, 0x00, 0x00, 0x16, 0x00, 0x00, 0x00,              //
+      0x28, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00,              //
+      0x04, 0x00, 0x00, 0x00, 0x01, 0x00, 0x18, 0x00,              //
+      0x00, 0x00, 0x00, 0x00, 0x18, 0x00, 0x00, 0x00,              //
+      0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,              //
+      0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,              //
+      0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x00, 0x00,              //
+      0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x00, 0x00,              //
+      0x40, 0x00, 0x00, 0x00, 0x80, 0x00, 0x00, 0x00,              //
+  };
+  wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+      (uint8_t*)(src_array), sizeof(src_array), true);
+
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config",
+               wuffs_ico__decoder__decode_image_config(&dec, &ic, &src));
+  if ((wuffs_base__pixel_config__width(&ic.pixcfg) != 2) ||
+      (wuffs_base__pixel_config__height(&ic.pixcfg) != 2)) {
+    RETURN_FAIL("dimensions: have %" PRIu32 "x%" PRIu32 ", want 2x2",
+                wuffs_base__pixel_config__width(&ic.pixcfg),
+                wuffs_base__pixel_config__height(&ic.pixcfg));
+  }
+  wuffs_base__pixel_config__set(&ic.pixcfg,
+                                WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+                                WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, 2, 2);
+
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     &pb, &ic.pixcfg, g_pixel_slice_u8));
+  CHECK_STATUS("decode_frame",
+               wuffs_ico__decoder__decode_frame(&dec, &pb, &src,
+                                                WUFFS_BASE__PIXEL_BLEND__SRC,
+                                                g_work_slice_u8, NULL));
+
+  static const uint32_t want[4] = {
+      0x00000000, 0xFF0C0B0A,  //
+      0xFF030201, 0x00000000,  //
+  };
+  wuffs_base__table_u8 tab = wuffs_base__pixel_buffer__plane(&pb, 0);
+  int i;
+  for (i = 0; i < 4; i++) {
+    uint32_t have = wuffs_base__peek_u32le__no_bounds_check(
+        tab.ptr + ((i >> 1) * tab.stride) + (4 * (i & 1)));
+    if (have != want[i]) {
+      RETURN_FAIL("i=%d: have 0x%08" PRIX32 ", want 0x%08" PRIX32, i, have,
+                  want[i]);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_ico_decode_cursor_hotspot() {
+  CHECK_FOCUS(__func__);
+  wuffs_ico__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_ico__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hippopotamus.cur"));
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config",
+               wuffs_ico__decoder__decode_image_config(&dec, &ic, &src));
+
+  wuffs_base__rect_ie_u32 have = wuffs_ico__decoder__cursor_hotspot(&dec);
+  if ((have.min_incl_x != 3) || (have.min_incl_y != 5)) {
+    RETURN_FAIL("hotspot: have (%" PRIu32 ", %" PRIu32 "), want (3, 5)",
+                have.min_incl_x, have.min_incl_y);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_ico_decode_frame_config() {
+  CHECK_FOCUS(__func__);
+
+  const struct {
+    const char* filename;
+    uint64_t want_io_position;
+  } tcs[] = {
+      // The BMP payload's frame starts after the ICONDIR (6 bytes), two
+      // ICONDIRENTRY's (32 bytes), the decoy (48 bytes) and the
+      // BITMAPINFOHEADER (40 bytes).
+      {.filename = "test/data/hippopotamus.cur", .want_io_position = 126},
+      // The PNG payload's frame starts at the PNG's IDAT chunk.
+      {.filename = "test/data/hippopotamus.ico", .want_io_position = 145},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(tcs); tc++) {
+    wuffs_ico__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_ico__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__frame_config fc = ((wuffs_base__frame_config){});
+    wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+        .data = g_src_slice_u8,
+    });
+    CHECK_STRING(read_file(&src, tcs[tc].filename));
+    CHECK_STATUS("decode_frame_config #0",
+                 wuffs_ico__decoder__decode_frame_config(&dec, &fc, &src));
+
+    uint64_t have_io_position = wuffs_base__frame_config__io_position(&fc);
+    if (have_io_position != tcs[tc].want_io_position) {
+      RETURN_FAIL("tc=%d: io_position: have %" PRIu64 ", want %" PRIu64, tc,
+                  have_io_position, tcs[tc].want_io_position);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_ico_decode_interface() {
+  CHECK_FOCUS(__func__);
+
+  const char* filenames[] = {
+      "test/data/hippopotamus.cur",
+      "test/data/hippopotamus.ico",
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(filenames); tc++) {
+    wuffs_ico__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_ico__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    const char* have = do_test__wuffs_base__image_decoder(
+        wuffs_ico__decoder__upcast_as__wuffs_base__image_decoder(&dec),
+        filenames[tc], 0, SIZE_MAX, 36, 28, 0xFFF5F5F5);
+    if (have) {
+      RETURN_FAIL("tc=%d (%s): %s", tc, filenames[tc], have);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_ico_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  const struct {
+    const char* src;
+    size_t src_len;
+    const char* want;
+  } tcs[] = {
+      {
+          // A non-zero reserved field.
+          .src = "\x01\x00\x01\x00\x01\x00",
+          .src_len = 6,
+          .want = wuffs_ico__error__bad_header,
+      },
+      {
+          // A type that is neither 1 (ICO) nor 2 (CUR).
+          .src = "\x00\x00\x03\x00\x01\x00",
+          .src_len = 6,
+          .want = wuffs_ico__error__bad_header,
+      },
+      {
+          // No directory entries.
+          .src = "\x00\x00\x01\x00\x00\x00",
+          .src_len = 6,
+          .want = wuffs_ico__error__bad_header,
+      },
+      {
+          // An image offset (6) inside the 22 byte header and directory.
+          .src = "\x00\x00\x01\x00\x01\x00"
+                 "\x01\x01\x00\x00\x01\x00\x18\x00"
+                 "\x00\x00\x00\x00\x06\x00\x00\x00",
+          .src_len = 22,
+          .want = wuffs_ico__error__bad_header,
+      },
+      {
+          // A BMP payload with an OS/2 (12 byte) BITMAPCOREHEADER.
+          .src = "\x00\x00\x01\x00\x01\x00"
+                 "\x01\x01\x00\x00\x01\x00\x18\x00"
+                 "\x00\x00\x00\x00\x16\x00\x00\x00"
+                 "\x0C\x00\x00\x00\x01\x00\x02\x00\x01\x00\x18\x00",
+          .src_len = 34,
+          .want = wuffs_bmp__error__unsupported_bmp_file,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(tcs); tc++) {
+    wuffs_ico__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_ico__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__image_config ic = ((wuffs_base__image_config){});
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)(tcs[tc].src), tcs[tc].src_len, true);
+    wuffs_base__status status =
+        wuffs_ico__decoder__decode_image_config(&dec, &ic, &src);
+    if (status.repr != tcs[tc].want) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, status.repr,
+                  tcs[tc].want);
+    }
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- ICO Benches
+
+// No ICO benches.
+
+// ---------------- Mimic Benches
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_ico_decode_and_mask,
+    test_wuffs_ico_decode_cursor_hotspot,
+    test_wuffs_ico_decode_frame_config,
+    test_wuffs_ico_decode_interface,
+    test_wuffs_ico_decode_invalid,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No ICO benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/ico";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -0,0 +1,438 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror ini.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__INI
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_ini_ini_things_gt = {
+    .want_filename = "test/data/ini-things.tokens",
+    .src_filename = "test/data/ini-things.ini",
+};
+
+// ---------------- INI Tests
+
+// render_ini_tokens decodes src (with the given quirks enabled) and writes a
+// compact rendering of its sections, keys and values to dst. Each section's
+// dict is enclosed by "{}" and each string by "<>". Backslash-escapes are
+// rendered as the (ASCII) character they decode to.
+const char*  //
+render_ini_tokens(wuffs_base__io_buffer* dst,
+                  const char* src_str,
+                  const uint32_t* quirks) {
+  wuffs_base__token_buffer tok =
+      wuffs_base__slice_token__writer(g_have_slice_token);
+  wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+      (uint8_t*)(src_str), strlen(src_str), true);
+
+  wuffs_ini__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_ini__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  for (; quirks && *quirks; quirks++) {
+    wuffs_ini__decoder__set_quirk_enabled(&dec, *quirks, true);
+  }
+  CHECK_STATUS("decode_tokens", wuffs_ini__decoder__decode_tokens(
+                                    &dec, &tok, &src, g_work_slice_u8));
+
+  size_t pos = 0;
+  bool in_field = false;
+  while (tok.meta.ri < tok.meta.wi) {
+    wuffs_base__token* t = &tok.data.ptr[tok.meta.ri++];
+    uint64_t len = wuffs_base__token__length(t);
+    uint64_t vbc = wuffs_base__token__value_base_category(t);
+    uint64_t vbd = wuffs_base__token__value_base_detail(t);
+    if (dst->meta.wi + len + 2 > dst->data.len) {
+      return "dst is too short";
+    }
+    uint8_t* p = dst->data.ptr + dst->meta.wi;
+
+    if (vbc == WUFFS_BASE__TOKEN__VBC__STRUCTURE) {
+      if ((vbd & WUFFS_BASE__TOKEN__VBD__STRUCTURE__PUSH) &&
+          (vbd & WUFFS_BASE__TOKEN__VBD__STRUCTURE__FROM_DICT)) {
+        *p++ = '{';
+      } else if ((vbd & WUFFS_BASE__TOKEN__VBD__STRUCTURE__POP) &&
+                 (vbd & WUFFS_BASE__TOKEN__VBD__STRUCTURE__TO_DICT)) {
+        *p++ = '}';
+      }
+    } else if ((vbc == WUFFS_BASE__TOKEN__VBC__STRING) ||
+               (vbc == WUFFS_BASE__TOKEN__VBC__UNICODE_CODE_POINT)) {
+      if (!in_field) {
+        *p++ = '<';
+      }
+      if (vbc == WUFFS_BASE__TOKEN__VBC__UNICODE_CODE_POINT) {
+        *p++ = (uint8_t)vbd;
+      } else if (vbd &
+                 WUFFS_BASE__TOKEN__VBD__STRING__CONVERT_1_DST_1_SRC_COPY) {
+        memcpy(p, src.data.ptr + pos, len);
+        p += len;
+      }
+      in_field = wuffs_base__token__continued(t);
+      if (!in_field) {
+        *p++ = '>';
+      }
+    }
+
+    dst->meta.wi = p - dst->data.ptr;
+    pos += len;
+  }
+
+  if (pos != src.meta.wi) {
+    RETURN_FAIL("total token length: have %zu, want %zu", pos, src.meta.wi);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_ini_decode_interface() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_ini__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_ini__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  CHECK_STRING(do_test__wuffs_base__token_decoder(
+      wuffs_ini__decoder__upcast_as__wuffs_base__token_decoder(&dec),
+      &g_ini_ini_things_gt));
+
+  return NULL;
+}
+
+const char*  //
+test_wuffs_ini_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  const uint32_t allow_backslash_escapes[] = {
+      WUFFS_INI__QUIRK_ALLOW_BACKSLASH_ESCAPES,
+      0,
+  };
+
+  struct {
+    const char* want;
+    const char* str;
+    const uint32_t* quirks;
+  } test_cases[] = {
+      {.want = wuffs_ini__error__bad_key_value_pair, .str = "a"},
+      {.want = wuffs_ini__error__bad_key_value_pair, .str = "a b\n"},
+      {.want = wuffs_ini__error__bad_key_value_pair, .str = "a \n= b"},
+      {.want = wuffs_ini__error__bad_key_value_pair, .str = "= b"},
+      {.want = wuffs_ini__error__bad_key_value_pair, .str = "a: b"},
+      {.want = wuffs_ini__error__bad_section_header, .str = "[]"},
+      {.want = wuffs_ini__error__bad_section_header, .str = "[a"},
+      {.want = wuffs_ini__error__bad_section_header, .str = "[a\n]"},
+      {.want = wuffs_ini__error__bad_section_header, .str = "[a] b"},
+      {.want = wuffs_ini__error__bad_backslash_escape,
+       .str = "a = \\q",
+       .quirks = allow_backslash_escapes},
+      {.want = wuffs_ini__error__bad_backslash_escape,
+       .str = "a = b\\\nc",
+       .quirks = allow_backslash_escapes},
+      {.want = wuffs_ini__error__bad_backslash_escape,
+       .str = "a = b\\",
+       .quirks = allow_backslash_escapes},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)(test_cases[tc].str), strlen(test_cases[tc].str), true);
+
+    wuffs_ini__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_ini__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    const uint32_t* q = test_cases[tc].quirks;
+    for (; q && *q; q++) {
+      wuffs_ini__decoder__set_quirk_enabled(&dec, *q, true);
+    }
+
+    const char* have =
+        wuffs_ini__decoder__decode_tokens(&dec, &tok, &src, g_work_slice_u8)
+            .repr;
+    if (have != test_cases[tc].want) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, have,
+                  test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_ini_decode_valid() {
+  CHECK_FOCUS(__func__);
+
+  const uint32_t allow_backslash_escapes[] = {
+      WUFFS_INI__QUIRK_ALLOW_BACKSLASH_ESCAPES,
+      0,
+  };
+  const uint32_t allow_bom[] = {
+      WUFFS_INI__QUIRK_ALLOW_LEADING_UNICODE_BYTE_ORDER_MARK,
+      0,
+  };
+  const uint32_t allow_colon_separator[] = {
+      WUFFS_INI__QUIRK_ALLOW_COLON_SEPARATOR,
+      0,
+  };
+  const uint32_t allow_line_continuation[] = {
+      WUFFS_INI__QUIRK_ALLOW_LINE_CONTINUATION,
+      0,
+  };
+  const uint32_t properties[] = {
+      WUFFS_INI__QUIRK_ALLOW_BACKSLASH_ESCAPES,
+      WUFFS_INI__QUIRK_ALLOW_COLON_SEPARATOR,
+      WUFFS_INI__QUIRK_ALLOW_LINE_CONTINUATION,
+      0,
+  };
+
+  struct {
+    const char* want;
+    const char* str;
+    const uint32_t* quirks;
+  } test_cases[] = {
+      {.want = "", .str = ""},
+      {.want = "", .str = " \n\r\n; a = b\n# [c]"},
+      {.want = "<a><b>", .str = "a=b"},
+      {.want = "<a><b>", .str = "  a \t=\t b  \n"},
+      {.want = "<a><>", .str = "a ="},
+      {.want = "<a><>", .str = "a =  \r\n"},
+      {.want = "<a b><c  d>", .str = "a b = c  d"},
+      {.want = "<a><b = c; d>", .str = "a = b = c; d"},
+      {.want = "<a:b><c>", .str = "a:b = c"},
+      {.want = "<a\\b><c\\>", .str = "a\\b = c\\"},
+      {.want = "<s>{}", .str = "[s]"},
+      {.want = "<a><b><s>{<c><d>}<t u>{}",
+       .str = "a = b\n[s]\nc = d\n[t u]  \n"},
+      {.want = "<\xEF\xBB\xBF"
+               "a><b>",
+       .str = "\xEF\xBB\xBF"
+              "a = b"},
+      {.want = "<a><b>",
+       .str = "\xEF\xBB\xBF"
+              "a = b",
+       .quirks = allow_bom},
+      {.want = "<a><b>", .str = "a : b", .quirks = allow_colon_separator},
+      {.want = "<a><b:c>", .str = "a = b:c", .quirks = allow_colon_separator},
+      {.want = "<a><\t\n\\=\" b >",
+       .str = "a = \\t\\n\\\\\\=\\\"\\ b\\ ",
+       .quirks = allow_backslash_escapes},
+      {.want = "<a><bc><d><e>",
+       .str = "a = b\\\n    c\nd = e",
+       .quirks = allow_line_continuation},
+      {.want = "<a><b\\c>",
+       .str = "a = b\\c",
+       .quirks = allow_line_continuation},
+      {.want = "<a><bc\td>",
+       .str = "a: b\\\r\n  c\\td\r\n",
+       .quirks = properties},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+        .data = g_have_slice_u8,
+    });
+    const char* status = render_ini_tokens(&have, test_cases[tc].str,
+                                           test_cases[tc].quirks);
+    if (status) {
+      RETURN_FAIL("tc=%d: %s", tc, status);
+    }
+    size_t want_len = strlen(test_cases[tc].want);
+    if ((have.meta.wi != want_len) ||
+        memcmp(have.data.ptr, test_cases[tc].want, want_len)) {
+      RETURN_FAIL("tc=%d: have \"%.*s\", want \"%s\"", tc, (int)(have.meta.wi),
+                  have.data.ptr, test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_ini_decode_split_io() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_base__io_buffer whole = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&whole, "test/data/ini-things.ini"));
+
+  // Feed the decoder's src and dst a few bytes or tokens at a time. The
+  // tokens' lengths should still add up to the whole input.
+  int i;
+  for (i = 1; i < 8; i++) {
+    wuffs_ini__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_ini__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src = whole;
+    src.meta.wi = 0;
+    src.meta.closed = false;
+    uint64_t total_length = 0;
+    uint64_t num_sections = 0;
+
+    while (true) {
+      wuffs_base__token_buffer limited_tok = make_limited_token_writer(tok, i);
+      wuffs_base__status status = wuffs_ini__decoder__decode_tokens(
+          &dec, &limited_tok, &src, g_work_slice_u8);
+      size_t t;
+      for (t = 0; t < limited_tok.meta.wi; t++) {
+        wuffs_base__token* token = &limited_tok.data.ptr[t];
+        total_length += wuffs_base__token__length(token);
+        if ((wuffs_base__token__value_base_category(token) ==
+             WUFFS_BASE__TOKEN__VBC__STRUCTURE) &&
+            (wuffs_base__token__value_base_detail(token) ==
+             (WUFFS_BASE__TOKEN__VBD__STRUCTURE__PUSH |
+              WUFFS_BASE__TOKEN__VBD__STRUCTURE__FROM_DICT |
+              WUFFS_BASE__TOKEN__VBD__STRUCTURE__TO_DICT))) {
+          num_sections++;
+        }
+      }
+
+      if (status.repr == wuffs_base__suspension__short_read) {
+        src.meta.wi += wuffs_base__u64__min(i, whole.meta.wi - src.meta.wi);
+        src.meta.closed = src.meta.wi == whole.meta.wi;
+      } else if (status.repr != wuffs_base__suspension__short_write) {
+        CHECK_STATUS("decode_tokens", status);
+        break;
+      }
+    }
+
+    if (total_length != whole.meta.wi) {
+      RETURN_FAIL("i=%d: total_length: have %" PRIu64 ", want %zu", i,
+                  total_length, whole.meta.wi);
+    } else if (num_sections != 2) {
+      RETURN_FAIL("i=%d: num_sections: have %" PRIu64 ", want 2", i,
+                  num_sections);
+    }
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- INI Benches
+
+// No INI benches.
+
+// ---------------- Mimic Benches
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_ini_decode_interface,
+    test_wuffs_ini_decode_invalid,
+    test_wuffs_ini_decode_split_io,
+    test_wuffs_ini_decode_valid,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No INI benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/ini";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -98,6 +98,312 @@ test_wuffs_core_count_leading_zeroes_u64() {
   return NULL;
 }
 
+const char*  //
+test_wuffs_core_count_ones_u64() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    uint64_t num;
+    uint32_t want;
+  } test_cases[] = {
+      {.num = 0x0000000000000000, .want = 0},
+      {.num = 0x0000000000000001, .want = 1},
+      {.num = 0x0000000000008001, .want = 2},
+      {.num = 0x0000000040302010, .want = 5},
+      {.num = 0x0123456789ABCDEF, .want = 32},
+      {.num = 0x8000000000000001, .want = 2},
+      {.num = 0xFFFFFFFFFFFFFFFF, .want = 64},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    uint32_t have = wuffs_base__count_ones_u64(test_cases[tc].num);
+    if (have != test_cases[tc].want) {
+      RETURN_FAIL("0x%" PRIX64 ": have %" PRIu32 ", want %" PRIu32,
+                  test_cases[tc].num, have, test_cases[tc].want);
+    }
+  }
+
+  return NULL;
+}
+
+const char*  //
+test_wuffs_core_count_trailing_zeroes_u64() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    uint64_t num;
+    uint32_t want;
+  } test_cases[] = {
+      {.num = 0x0000000000000000, .want = 64},
+      {.num = 0x0000000000000001, .want = 0},
+      {.num = 0x0000000000008000, .want = 15},
+      {.num = 0x0000000040302010, .want = 4},
+      {.num = 0x0123456789ABCDE0, .want = 5},
+      {.num = 0x8000000000000000, .want = 63},
+      {.num = 0xFFFFFFFFFFFFFFFF, .want = 0},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    uint32_t have = wuffs_base__count_trailing_zeroes_u64(test_cases[tc].num);
+    if (have != test_cases[tc].want) {
+      RETURN_FAIL("0x%" PRIX64 ": have %" PRIu32 ", want %" PRIu32,
+                  test_cases[tc].num, have, test_cases[tc].want);
+    }
+  }
+
+  return NULL;
+}
+
+const char*  //
+test_wuffs_core_io_buffer_compact_retain() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    uint64_t history_retain_length;
+    size_t ri;
+    size_t wi;
+    uint64_t meta_pos;
+  } test_cases[] = {
+      // Retaining less than the read index discards the oldest bytes.
+      {.history_retain_length = 2, .ri = 2, .wi = 4, .meta_pos = 104},
+      {.history_retain_length = 0, .ri = 0, .wi = 2, .meta_pos = 106},
+      // Retaining at least the read index is a no-op.
+      {.history_retain_length = 6, .ri = 6, .wi = 8, .meta_pos = 100},
+      {.history_retain_length = 9, .ri = 6, .wi = 8, .meta_pos = 100},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    uint8_t data[8] = {0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17};
+    wuffs_base__io_buffer buf = wuffs_base__make_io_buffer(
+        wuffs_base__make_slice_u8(data, 8),
+        wuffs_base__make_io_buffer_meta(8, 6, 100, false));
+    wuffs_base__io_buffer__compact_retain(
+        &buf, test_cases[tc].history_retain_length);
+    uint8_t want_first = (uint8_t)(0x10 + (test_cases[tc].meta_pos - 100));
+    if ((buf.meta.ri != test_cases[tc].ri) ||
+        (buf.meta.wi != test_cases[tc].wi) ||
+        (buf.meta.pos != test_cases[tc].meta_pos)) {
+      RETURN_FAIL("tc=%d: have (ri=%zu, wi=%zu, pos=%" PRIu64
+                  "), want (ri=%zu, wi=%zu, pos=%" PRIu64 ")",
+                  tc, buf.meta.ri, buf.meta.wi, buf.meta.pos,
+                  test_cases[tc].ri, test_cases[tc].wi,
+                  test_cases[tc].meta_pos);
+    } else if (data[0] != want_first) {
+      RETURN_FAIL("tc=%d: data[0]: have 0x%02X, want 0x%02X", tc, data[0],
+                  want_first);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_core_io_buffer_reader_seek() {
+  CHECK_FOCUS(__func__);
+
+  uint8_t data[8] = {0};
+  wuffs_base__io_buffer buf = wuffs_base__make_io_buffer(
+      wuffs_base__make_slice_u8(data, 8),
+      wuffs_base__make_io_buffer_meta(6, 4, 100, true));
+
+  struct {
+    uint64_t pos;
+    bool want;
+    size_t ri;
+    size_t wi;
+    uint64_t meta_pos;
+  } test_cases[] = {
+      // Seeking within the sliding window, forwards or backwards.
+      {.pos = 105, .want = true, .ri = 5, .wi = 6, .meta_pos = 100},
+      {.pos = 101, .want = true, .ri = 1, .wi = 6, .meta_pos = 100},
+      {.pos = 100, .want = true, .ri = 0, .wi = 6, .meta_pos = 100},
+      {.pos = 106, .want = true, .ri = 6, .wi = 6, .meta_pos = 100},
+      // Seeking outside of it discards the buffered data.
+      {.pos = 107, .want = false, .ri = 0, .wi = 0, .meta_pos = 107},
+      {.pos = 99, .want = false, .ri = 0, .wi = 0, .meta_pos = 99},
+      {.pos = 99, .want = true, .ri = 0, .wi = 0, .meta_pos = 99},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    bool have = wuffs_base__io_buffer__reader_seek(&buf, test_cases[tc].pos);
+    if ((have != test_cases[tc].want) ||
+        (buf.meta.ri != test_cases[tc].ri) ||
+        (buf.meta.wi != test_cases[tc].wi) ||
+        (buf.meta.pos != test_cases[tc].meta_pos)) {
+      RETURN_FAIL("tc=%d: have %d (ri=%zu, wi=%zu, pos=%" PRIu64
+                  "), want %d (ri=%zu, wi=%zu, pos=%" PRIu64 ")",
+                  tc, have, buf.meta.ri, buf.meta.wi, buf.meta.pos,
+                  test_cases[tc].want, test_cases[tc].ri,
+                  test_cases[tc].wi, test_cases[tc].meta_pos);
+    } else if ((tc == 3) && !buf.meta.closed) {
+      RETURN_FAIL("tc=%d: closed: have false, want true", tc);
+    } else if ((tc == 4) && buf.meta.closed) {
+      RETURN_FAIL("tc=%d: closed: have true, want false", tc);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_core_io_buffer_vec() {
+  CHECK_FOCUS(__func__);
+
+  // Split some JSON over multiple spans (including an empty span), so that
+  // the "12345" number and the "true" literal both straddle span boundaries.
+  const char* want = "[12345, \"ab\", true]";
+  wuffs_base__slice_u8 spans[6] = {
+      wuffs_base__make_slice_u8((void*)("[12"), 3),
+      wuffs_base__make_slice_u8((void*)("345, \"a"), 7),
+      wuffs_base__make_slice_u8(NULL, 0),
+      wuffs_base__make_slice_u8((void*)("b\", t"), 5),
+      wuffs_base__make_slice_u8((void*)("ru"), 2),
+      wuffs_base__make_slice_u8((void*)("e]"), 2),
+  };
+  const uint64_t total = 19;
+
+  // Check reading, via a small staging buffer, and advancing.
+  {
+    wuffs_base__io_buffer_vec v =
+        wuffs_base__make_io_buffer_vec(spans, 6, 100, true);
+    uint8_t staging_array[4];
+    wuffs_base__slice_u8 staging =
+        wuffs_base__make_slice_u8(staging_array, sizeof staging_array);
+    if (wuffs_base__io_buffer_vec__length(&v) != total) {
+      RETURN_FAIL("length: have %" PRIu64 ", want %" PRIu64,
+                  wuffs_base__io_buffer_vec__length(&v), total);
+    }
+
+    // The first span is shorter than staging, so it gets gathered.
+    wuffs_base__io_buffer r = wuffs_base__io_buffer_vec__reader(&v, staging);
+    if ((r.data.ptr != staging_array) || (r.meta.wi != 4) ||
+        (r.meta.pos != 100) || r.meta.closed ||
+        memcmp(r.data.ptr, "[123", 4)) {
+      RETURN_FAIL("reader #0: have wi=%zu, pos=%" PRIu64 ", want 4, 100",
+                  r.meta.wi, r.meta.pos);
+    }
+    wuffs_base__io_buffer_vec__advance(&v, 1);
+
+    // The rest of the first span is still shorter than staging.
+    r = wuffs_base__io_buffer_vec__reader(&v, staging);
+    if ((r.meta.wi != 4) || (r.meta.pos != 101) ||
+        memcmp(r.data.ptr, "1234", 4)) {
+      RETURN_FAIL("reader #1: have wi=%zu, pos=%" PRIu64 ", want 4, 101",
+                  r.meta.wi, r.meta.pos);
+    }
+    wuffs_base__io_buffer_vec__advance(&v, 2);
+
+    // The second span is at least as long as staging, so it is aliased.
+    r = wuffs_base__io_buffer_vec__reader(&v, staging);
+    if ((r.data.ptr != spans[1].ptr) || (r.meta.wi != 7) ||
+        (r.meta.pos != 103)) {
+      RETURN_FAIL("reader #2: have wi=%zu, pos=%" PRIu64 ", want 7, 103",
+                  r.meta.wi, r.meta.pos);
+    }
+    wuffs_base__io_buffer_vec__advance(&v, 7);
+    if ((v.index != 2) || (v.offset != 0) ||
+        (wuffs_base__io_buffer_vec__length(&v) != 9)) {
+      RETURN_FAIL("advance #2: have index=%zu, offset=%zu", v.index, v.offset);
+    }
+
+    // The empty span is skipped.
+    r = wuffs_base__io_buffer_vec__reader(&v, staging);
+    if ((r.data.ptr != spans[3].ptr) || (r.meta.wi != 5)) {
+      RETURN_FAIL("reader #3: have wi=%zu, want 5", r.meta.wi);
+    }
+    wuffs_base__io_buffer_vec__advance(&v, 8);
+
+    // Only the last byte remains, so the view is closed.
+    r = wuffs_base__io_buffer_vec__reader(&v, staging);
+    if ((r.meta.wi != 1) || (r.meta.pos != 118) || !r.meta.closed ||
+        (r.data.ptr[0] != ']')) {
+      RETURN_FAIL("reader #4: have wi=%zu, pos=%" PRIu64 ", want 1, 118",
+                  r.meta.wi, r.meta.pos);
+    }
+    wuffs_base__io_buffer_vec__advance(&v, 999);
+    if ((v.index != 6) || (v.pos != 119) ||
+        (wuffs_base__io_buffer_vec__length(&v) != 0)) {
+      RETURN_FAIL("advance #4: have index=%zu, pos=%" PRIu64, v.index, v.pos);
+    }
+  }
+
+  // Check writing.
+  {
+    uint8_t dst_array[19];
+    wuffs_base__slice_u8 dst_spans[3] = {
+        wuffs_base__make_slice_u8(dst_array + 0, 5),
+        wuffs_base__make_slice_u8(dst_array + 5, 0),
+        wuffs_base__make_slice_u8(dst_array + 5, 14),
+    };
+    wuffs_base__io_buffer_vec v =
+        wuffs_base__make_io_buffer_vec(dst_spans, 3, 0, false);
+    uint64_t n = 0;
+    while (n < total) {
+      wuffs_base__io_buffer w = wuffs_base__io_buffer_vec__writer(&v);
+      size_t m = wuffs_base__io_buffer__writer_length(&w);
+      if ((m == 0) || (w.meta.pos != n)) {
+        RETURN_FAIL("writer: have len=%zu, pos=%" PRIu64 ", at n=%" PRIu64, m,
+                    w.meta.pos, n);
+      } else if (m > 3) {
+        m = 3;
+      }
+      memcpy(w.data.ptr, want + n, m);
+      wuffs_base__io_buffer_vec__advance(&v, m);
+      n += m;
+    }
+    if (memcmp(dst_array, want, total)) {
+      RETURN_FAIL("writer: contents differ");
+    }
+  }
+
+  // Check decoding JSON tokens, where a number (and the decoder's lookahead
+  // for "true") needs more contiguous bytes than any one span holds.
+  {
+    wuffs_json__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_json__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_base__io_buffer_vec v =
+        wuffs_base__make_io_buffer_vec(spans, 6, 0, true);
+    uint8_t staging_array[16];
+    wuffs_base__slice_u8 staging =
+        wuffs_base__make_slice_u8(staging_array, sizeof staging_array);
+
+    uint64_t have_total = 0;
+    uint64_t have_num_tokens = 0;
+    int i;
+    for (i = 0; i < 100; i++) {
+      wuffs_base__token_buffer tok =
+          wuffs_base__slice_token__writer(g_have_slice_token);
+      wuffs_base__io_buffer src =
+          wuffs_base__io_buffer_vec__reader(&v, staging);
+      wuffs_base__status status =
+          wuffs_json__decoder__decode_tokens(&dec, &tok, &src, g_work_slice_u8);
+      size_t j;
+      for (j = tok.meta.ri; j < tok.meta.wi; j++) {
+        have_total += wuffs_base__token__length(&tok.data.ptr[j]);
+        have_num_tokens++;
+      }
+      wuffs_base__io_buffer_vec__advance(&v, src.meta.ri);
+      if (wuffs_base__status__is_ok(&status)) {
+        break;
+      } else if (status.repr != wuffs_base__suspension__short_read) {
+        RETURN_FAIL("decode_tokens: \"%s\"", status.repr);
+      }
+    }
+    if (have_total != total) {
+      RETURN_FAIL("total token length: have %" PRIu64 ", want %" PRIu64,
+                  have_total, total);
+    } else if (have_num_tokens != 11) {
+      RETURN_FAIL("num tokens: have %" PRIu64 ", want 11", have_num_tokens);
+    }
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_core_multiply_u64() {
   CHECK_FOCUS(__func__);
@@ -138,6 +444,184 @@ test_wuffs_core_multiply_u64() {
   return NULL;
 }
 
+const char*  //
+test_wuffs_core_slice_u8_copy_within() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    uint64_t dst_index;
+    uint64_t src_index;
+    uint64_t n;
+    uint64_t want_n;
+    const char* want;
+  } test_cases[] = {
+      {.dst_index = 0, .src_index = 4, .n = 3, .want_n = 3, .want = "efgdefgh"},
+      {.dst_index = 4, .src_index = 0, .n = 3, .want_n = 3, .want = "abcdabch"},
+      // Overlapping ranges, in either direction.
+      {.dst_index = 0, .src_index = 2, .n = 6, .want_n = 6, .want = "cdefghgh"},
+      {.dst_index = 2, .src_index = 0, .n = 6, .want_n = 6, .want = "ababcdef"},
+      // n is clipped to fit within the slice.
+      {.dst_index = 5, .src_index = 1, .n = 9, .want_n = 3, .want = "abcdebcd"},
+      {.dst_index = 1, .src_index = 6, .n = 9, .want_n = 2, .want = "aghdefgh"},
+      {.dst_index = 8, .src_index = 0, .n = 9, .want_n = 0, .want = "abcdefgh"},
+      {.dst_index = 9, .src_index = 0, .n = 9, .want_n = 0, .want = "abcdefgh"},
+      {.dst_index = 3, .src_index = 3, .n = 2, .want_n = 2, .want = "abcdefgh"},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    uint8_t data[8];
+    memcpy(data, "abcdefgh", 8);
+    wuffs_base__slice_u8 s = wuffs_base__make_slice_u8(&data[0], 8);
+    uint64_t have_n = wuffs_base__slice_u8__copy_within(
+        s, test_cases[tc].dst_index, test_cases[tc].src_index,
+        test_cases[tc].n);
+    if (have_n != test_cases[tc].want_n) {
+      RETURN_FAIL("tc=%d: n: have %" PRIu64 ", want %" PRIu64, tc, have_n,
+                  test_cases[tc].want_n);
+    }
+    if (memcmp(data, test_cases[tc].want, 8)) {
+      RETURN_FAIL("tc=%d: data: have \"%.8s\", want \"%s\"", tc,
+                  (const char*)(data), test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_core_slice_u8_equal_fold() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* s;
+    const char* t;
+    bool want;
+  } test_cases[] = {
+      {.s = "", .t = "", .want = true},
+      {.s = "charset", .t = "charset", .want = true},
+      {.s = "Charset", .t = "cHARSET", .want = true},
+      {.s = "UTF-8", .t = "utf-8", .want = true},
+      {.s = "utf-8", .t = "utf_8", .want = false},
+      {.s = "charset", .t = "charsets", .want = false},
+      // '@' and '`' differ only in their 0x20 bit, but they are not letters.
+      {.s = "@", .t = "`", .want = false},
+      {.s = "[", .t = "{", .want = false},
+      // Only ASCII case is folded.
+      {.s = "\xC3\xA9", .t = "\xC3\x89", .want = false},
+      {.s = "\xC3\xA9", .t = "\xC3\xA9", .want = true},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__slice_u8 s = wuffs_base__make_slice_u8(
+        (void*)(test_cases[tc].s), strlen(test_cases[tc].s));
+    wuffs_base__slice_u8 t = wuffs_base__make_slice_u8(
+        (void*)(test_cases[tc].t), strlen(test_cases[tc].t));
+    bool have = wuffs_base__slice_u8__equal_fold(s, t);
+    if (have != test_cases[tc].want) {
+      RETURN_FAIL("\"%s\", \"%s\": have %d, want %d", test_cases[tc].s,
+                  test_cases[tc].t, have, test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_core_slice_u8_fill() {
+  CHECK_FOCUS(__func__);
+
+  uint8_t data[8];
+  memcpy(data, "abcdefgh", 8);
+  wuffs_base__slice_u8__fill(wuffs_base__make_slice_u8(&data[2], 4), 'x');
+  if (memcmp(data, "abxxxxgh", 8)) {
+    RETURN_FAIL("data: have \"%.8s\", want \"abxxxxgh\"",
+                (const char*)(data));
+  }
+  wuffs_base__slice_u8__fill(wuffs_base__make_slice_u8(&data[0], 0), 'y');
+  if (memcmp(data, "abxxxxgh", 8)) {
+    RETURN_FAIL("empty: have \"%.8s\", want \"abxxxxgh\"",
+                (const char*)(data));
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_core_slice_u8_index_of() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* s;
+    const char* t;
+    uint64_t want;
+    uint64_t want_fold;
+  } test_cases[] = {
+      {.s = "", .t = "", .want = 0, .want_fold = 0},
+      {.s = "abc", .t = "", .want = 0, .want_fold = 0},
+      {.s = "", .t = "abc", .want = 0, .want_fold = 0},
+      {.s = "ab", .t = "abc", .want = 2, .want_fold = 2},
+      {.s = "abcabc", .t = "ca", .want = 2, .want_fold = 2},
+      {.s = "abcabc", .t = "bc", .want = 1, .want_fold = 1},
+      {.s = "abcabc", .t = "cb", .want = 6, .want_fold = 6},
+      {.s = "aaab", .t = "aab", .want = 1, .want_fold = 1},
+      {.s = "Content-Type: text/html; CHARSET=utf-8",
+       .t = "charset=",
+       .want = 38,
+       .want_fold = 25},
+      {.s = "xmlXML", .t = "XML", .want = 3, .want_fold = 0},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__slice_u8 s = wuffs_base__make_slice_u8(
+        (void*)(test_cases[tc].s), strlen(test_cases[tc].s));
+    wuffs_base__slice_u8 t = wuffs_base__make_slice_u8(
+        (void*)(test_cases[tc].t), strlen(test_cases[tc].t));
+    uint64_t have = wuffs_base__slice_u8__index_of(s, t);
+    if (have != test_cases[tc].want) {
+      RETURN_FAIL("index_of(\"%s\", \"%s\"): have %" PRIu64
+                  ", want %" PRIu64,
+                  test_cases[tc].s, test_cases[tc].t, have,
+                  test_cases[tc].want);
+    }
+    have = wuffs_base__slice_u8__index_of_fold(s, t);
+    if (have != test_cases[tc].want_fold) {
+      RETURN_FAIL("index_of_fold(\"%s\", \"%s\"): have %" PRIu64
+                  ", want %" PRIu64,
+                  test_cases[tc].s, test_cases[tc].t, have,
+                  test_cases[tc].want_fold);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_core_status_payload() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_base__status z = wuffs_base__make_status(wuffs_base__error__bad_data);
+  if (wuffs_base__status__payload(&z) != 0) {
+    RETURN_FAIL("make_status: have %" PRIu64 ", want 0",
+                wuffs_base__status__payload(&z));
+  }
+
+  z = wuffs_base__make_status_with_payload(wuffs_base__error__bad_data, 123);
+  if (!wuffs_base__status__is_error(&z)) {
+    RETURN_FAIL("make_status_with_payload: is_error: have false");
+  }
+  if (wuffs_base__status__payload(&z) != 123) {
+    RETURN_FAIL("make_status_with_payload: have %" PRIu64 ", want 123",
+                wuffs_base__status__payload(&z));
+  }
+
+  // An OK status has no payload.
+  z = wuffs_base__make_status_with_payload(NULL, 456);
+  if (wuffs_base__status__payload(&z) != 0) {
+    RETURN_FAIL("ok: have %" PRIu64 ", want 0",
+                wuffs_base__status__payload(&z));
+  }
+  return NULL;
+}
+
 // ---------------- String Conversions Tests
 
 // wuffs_base__private_implementation__high_prec_dec__to_debug_string converts
@@ -3623,6 +4107,211 @@ test_wuffs_json_decode_quirk_allow_trailing_filler() {
   return NULL;
 }
 
+const char*  //
+test_wuffs_json_decode_quirk_emit_comment_tokens() {
+  CHECK_FOCUS(__func__);
+
+  // Each byte of want is one token's VBC: 'c'omment, 'f'iller, 'l'iteral,
+  // 'n'umber, 's'tring or structure ('[', ']', '{' or '}').
+  struct {
+    const char* want_disabled;
+    const char* want_enabled;
+    const char* str;
+  } test_cases[] = {
+      {.want_disabled = "[fffsssffnfff[",
+       .want_enabled = "[fcfsssffnffc[",
+       .str = "{ /*c*/ \"k\": 1, //l\n}"},
+      {.want_disabled = "[ffflf[",
+       .want_enabled = "[fcflf[",
+       .str = "[ /**/ true,]"},
+      {.want_disabled = "fn", .want_enabled = "cn", .str = "//\n0"},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    int q;
+    for (q = 0; q < 2; q++) {
+      wuffs_json__decoder dec;
+      CHECK_STATUS("initialize", wuffs_json__decoder__initialize(
+                                     &dec, sizeof dec, WUFFS_VERSION,
+                                     WUFFS_INITIALIZE__DEFAULT_OPTIONS));
+      wuffs_json__decoder__set_quirk_enabled(
+          &dec, WUFFS_JSON__QUIRK_ALLOW_COMMENT_BLOCK, true);
+      wuffs_json__decoder__set_quirk_enabled(
+          &dec, WUFFS_JSON__QUIRK_ALLOW_COMMENT_LINE, true);
+      wuffs_json__decoder__set_quirk_enabled(
+          &dec, WUFFS_JSON__QUIRK_ALLOW_EXTRA_COMMA, true);
+      wuffs_json__decoder__set_quirk_enabled(
+          &dec, WUFFS_JSON__QUIRK_EMIT_COMMENT_TOKENS, q);
+
+      wuffs_base__token_buffer tok =
+          wuffs_base__slice_token__writer(g_have_slice_token);
+      wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+          (void*)test_cases[tc].str, strlen(test_cases[tc].str), true);
+      CHECK_STATUS("decode_tokens",
+                   wuffs_json__decoder__decode_tokens(&dec, &tok, &src,
+                                                      g_work_slice_u8));
+
+      char have[64];
+      size_t n = 0;
+      size_t total_length = 0;
+      while ((tok.meta.ri < tok.meta.wi) && (n < (sizeof have) - 1)) {
+        wuffs_base__token* t = &tok.data.ptr[tok.meta.ri++];
+        total_length += wuffs_base__token__length(t);
+        switch (wuffs_base__token__value_base_category(t)) {
+          case WUFFS_BASE__TOKEN__VBC__COMMENT:
+            have[n++] = 'c';
+            break;
+          case WUFFS_BASE__TOKEN__VBC__FILLER:
+            have[n++] = 'f';
+            break;
+          case WUFFS_BASE__TOKEN__VBC__LITERAL:
+            have[n++] = 'l';
+            break;
+          case WUFFS_BASE__TOKEN__VBC__NUMBER:
+            have[n++] = 'n';
+            break;
+          case WUFFS_BASE__TOKEN__VBC__STRING:
+            have[n++] = 's';
+            break;
+          case WUFFS_BASE__TOKEN__VBC__STRUCTURE:
+            have[n++] = '[';
+            break;
+          default:
+            have[n++] = '?';
+            break;
+        }
+      }
+      have[n] = 0;
+
+      const char* want =
+          q ? test_cases[tc].want_enabled : test_cases[tc].want_disabled;
+      if (strcmp(have, want)) {
+        RETURN_FAIL("tc=%d, q=%d: have \"%s\", want \"%s\"", tc, q, have,
+                    want);
+      }
+      if (total_length != src.data.len) {
+        RETURN_FAIL("tc=%d, q=%d: total_length: have %zu, want %zu", tc, q,
+                    total_length, src.data.len);
+      }
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_json_decode_quirk_lone_surrogates() {
+  CHECK_FOCUS(__func__);
+
+  // want has 3 strings, one for each possible q:
+  //  - q==0 sets no quirks.
+  //  - q==1 sets WUFFS_JSON__QUIRK_REPLACE_LONE_SURROGATES.
+  //  - q==2 sets WUFFS_JSON__QUIRK_EMIT_LONE_SURROGATES.
+  // A NULL want means that decoding should fail. Otherwise, U+FFFD is shown
+  // as "?" and a lone surrogate code unit token is shown as e.g. "<D800>".
+  struct {
+    const char* want[3];
+    const char* str;
+  } test_cases[] = {
+      {.want = {"a\xF0\x90\x80\x80z", "a\xF0\x90\x80\x80z",
+                "a\xF0\x90\x80\x80z"},
+       .str = "\"a\\uD800\\uDC00z\""},
+      {.want = {NULL, "a?z", "a<DC00>z"}, .str = "\"a\\uDC00z\""},
+      {.want = {NULL, "a?z", "a<D800>z"}, .str = "\"a\\uD800z\""},
+      {.want = {NULL, "a?", "a<DBFF>"}, .str = "\"a\\uDBFF\""},
+      {.want = {NULL, "a??z", "a<D800><DBFF>z"},
+       .str = "\"a\\uD800\\uDBFFz\""},
+      {.want = {NULL, "a?\xF4\x8F\xBF\xBFz", "a<D800>\xF4\x8F\xBF\xBFz"},
+       .str = "\"a\\uD800\\uDBFF\\uDFFFz\""},
+      {.want = {NULL, NULL, NULL}, .str = "\"a\xFFz\""},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    int q;
+    for (q = 0; q < 3; q++) {
+      wuffs_json__decoder dec;
+      CHECK_STATUS("initialize", wuffs_json__decoder__initialize(
+                                     &dec, sizeof dec, WUFFS_VERSION,
+                                     WUFFS_INITIALIZE__DEFAULT_OPTIONS));
+      wuffs_json__decoder__set_quirk_enabled(
+          &dec, WUFFS_JSON__QUIRK_REPLACE_LONE_SURROGATES, q == 1);
+      wuffs_json__decoder__set_quirk_enabled(
+          &dec, WUFFS_JSON__QUIRK_EMIT_LONE_SURROGATES, q == 2);
+
+      wuffs_base__token_buffer tok =
+          wuffs_base__slice_token__writer(g_have_slice_token);
+      wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+          (void*)test_cases[tc].str, strlen(test_cases[tc].str), true);
+      wuffs_base__status status =
+          wuffs_json__decoder__decode_tokens(&dec, &tok, &src, g_work_slice_u8);
+      const char* want = test_cases[tc].want[q];
+      if (!want) {
+        if (wuffs_base__status__is_ok(&status)) {
+          RETURN_FAIL("tc=%d, q=%d: decode_tokens: have ok, want an error", tc,
+                      q);
+        }
+        continue;
+      } else if (!wuffs_base__status__is_ok(&status)) {
+        RETURN_FAIL("tc=%d, q=%d: decode_tokens: have \"%s\", want ok", tc, q,
+                    status.repr);
+      }
+
+      char have[64];
+      size_t n = 0;
+      uint64_t src_index = 0;
+      while (tok.meta.ri < tok.meta.wi) {
+        wuffs_base__token* t = &tok.data.ptr[tok.meta.ri++];
+        uint64_t token_length = wuffs_base__token__length(t);
+        if ((sizeof have) - n < 16) {
+          RETURN_FAIL("tc=%d, q=%d: output too long", tc, q);
+        }
+
+        if (wuffs_base__token__value_major(t) ==
+            WUFFS_JSON__TOKEN_VALUE_MAJOR) {
+          uint64_t minor = wuffs_base__token__value_minor(t);
+          if ((minor & ~0xFFFFu) !=
+              WUFFS_JSON__TOKEN_VALUE_MINOR__UTF_16_CODE_UNIT) {
+            RETURN_FAIL("tc=%d, q=%d: unexpected value_minor", tc, q);
+          }
+          n += sprintf(&have[n], "<%04X>", (uint32_t)(minor & 0xFFFF));
+
+        } else if (wuffs_base__token__value_base_category(t) ==
+                   WUFFS_BASE__TOKEN__VBC__UNICODE_CODE_POINT) {
+          uint32_t vbd = (uint32_t)wuffs_base__token__value_base_detail(t);
+          if (vbd == 0xFFFD) {
+            have[n++] = '?';
+          } else {
+            n += wuffs_base__utf_8__encode(
+                wuffs_base__make_slice_u8((uint8_t*)&have[n], (sizeof have) - n),
+                vbd);
+          }
+
+        } else if (wuffs_base__token__value_base_detail(t) &
+                   WUFFS_BASE__TOKEN__VBD__STRING__CONVERT_1_DST_1_SRC_COPY) {
+          if ((sizeof have) - n < token_length + 16) {
+            RETURN_FAIL("tc=%d, q=%d: output too long", tc, q);
+          }
+          memcpy(&have[n], &test_cases[tc].str[src_index], token_length);
+          n += token_length;
+        }
+
+        src_index += token_length;
+      }
+      have[n] = 0;
+
+      if (src_index != src.meta.ri) {
+        RETURN_FAIL("tc=%d, q=%d: src_index: have %" PRIu64 ", want %zu", tc,
+                    q, src_index, src.meta.ri);
+      }
+      if (strcmp(have, want)) {
+        RETURN_FAIL("tc=%d, q=%d: have \"%s\", want \"%s\"", tc, q, have, want);
+      }
+    }
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_json_decode_quirk_replace_invalid_unicode() {
   CHECK_FOCUS(__func__);
@@ -4204,7 +4893,17 @@ proc g_tests[] = {
     // They aren't specific to the std/json code, but putting them here is as
     // good as any other place.
     test_wuffs_core_count_leading_zeroes_u64,
+    test_wuffs_core_count_ones_u64,
+    test_wuffs_core_count_trailing_zeroes_u64,
+    test_wuffs_core_io_buffer_compact_retain,
+    test_wuffs_core_io_buffer_reader_seek,
+    test_wuffs_core_io_buffer_vec,
     test_wuffs_core_multiply_u64,
+    test_wuffs_core_slice_u8_copy_within,
+    test_wuffs_core_slice_u8_equal_fold,
+    test_wuffs_core_slice_u8_fill,
+    test_wuffs_core_slice_u8_index_of,
+    test_wuffs_core_status_payload,
     test_wuffs_strconv_base_16,
     test_wuffs_strconv_base_64,
     test_wuffs_strconv_hpd_rounded_integer,
@@ -4232,6 +4931,8 @@ proc g_tests[] = {
     test_wuffs_json_decode_quirk_allow_leading_etc,
     test_wuffs_json_decode_quirk_allow_trailing_comments,
     test_wuffs_json_decode_quirk_allow_trailing_filler,
+    test_wuffs_json_decode_quirk_emit_comment_tokens,
+    test_wuffs_json_decode_quirk_lone_surrogates,
     test_wuffs_json_decode_quirk_replace_invalid_unicode,
     test_wuffs_json_decode_src_io_buffer_length,
     test_wuffs_json_decode_string,
@@ -0,0 +1,258 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror lzo.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__LZO
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_lzo_midsummer_gt = {
+    .want_filename = "test/data/midsummer.txt",
+    .src_filename = "test/data/midsummer.txt.lzo1x",
+};
+
+golden_test g_lzo_pi_gt = {
+    .want_filename = "test/data/pi.txt",
+    .src_filename = "test/data/pi.txt.lzo1x",
+};
+
+// ---------------- LZO Tests
+
+const char*  //
+test_wuffs_lzo_decode_interface() {
+  CHECK_FOCUS(__func__);
+  wuffs_lzo__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_lzo__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  return do_test__wuffs_base__io_transformer(
+      wuffs_lzo__decoder__upcast_as__wuffs_base__io_transformer(&dec),
+      "test/data/romeo.txt.lzo1x", 0, SIZE_MAX, 942, 0x0A);
+}
+
+const char*  //
+wuffs_lzo_decode(wuffs_base__io_buffer* dst,
+                 wuffs_base__io_buffer* src,
+                 uint32_t wuffs_initialize_flags,
+                 uint64_t wlimit,
+                 uint64_t rlimit) {
+  wuffs_lzo__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_lzo__decoder__initialize(&dec, sizeof dec, WUFFS_VERSION,
+                                              wuffs_initialize_flags));
+
+  while (true) {
+    wuffs_base__io_buffer limited_dst = make_limited_writer(*dst, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+
+    wuffs_base__status status = wuffs_lzo__decoder__transform_io(
+        &dec, &limited_dst, &limited_src, g_work_slice_u8);
+
+    dst->meta.wi += limited_dst.meta.wi;
+    src->meta.ri += limited_src.meta.ri;
+
+    if (((wlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_write)) ||
+        ((rlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_read))) {
+      continue;
+    }
+    return status.repr;
+  }
+}
+
+const char*  //
+test_wuffs_lzo_decode_artificial() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* src_ptr;
+    size_t src_len;
+    const char* want_ptr;
+    size_t want_len;
+    const char* want_status;
+  } test_cases[] = {
+      {
+          // An empty stream is just the end-of-stream marker.
+          .src_ptr = "\x11\x00\x00",
+          .src_len = 3,
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = NULL,
+      },
+      {
+          // An initial literal run (0x16 is 17 + 5) and a 3 byte M2 match at
+          // distance 5, followed by 2 trailing literals.
+          .src_ptr = "\x16hello\x52\x00, \x11\x00\x00",
+          .src_len = 14,
+          .want_ptr = "hellohel, ",
+          .want_len = 10,
+          .want_status = NULL,
+      },
+      {
+          // An M2 match at distance 2, after only 1 byte of output.
+          .src_ptr = "\x12z\x44\x00\x11\x00\x00",
+          .src_len = 7,
+          .want_ptr = "z",
+          .want_len = 1,
+          .want_status = wuffs_lzo__error__bad_distance,
+      },
+      {
+          // An end-of-stream marker whose length is 4 instead of 3.
+          .src_ptr = "\x12z\x12\x00\x00",
+          .src_len = 5,
+          .want_ptr = "z",
+          .want_len = 1,
+          .want_status = wuffs_lzo__error__bad_end_of_stream_marker,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+        .data = g_have_slice_u8,
+    });
+    wuffs_base__io_buffer src = make_io_buffer_from_string(
+        test_cases[tc].src_ptr, test_cases[tc].src_len);
+    const char* status =
+        wuffs_lzo_decode(&have, &src, WUFFS_INITIALIZE__DEFAULT_OPTIONS,
+                         UINT64_MAX, UINT64_MAX);
+    if (status != test_cases[tc].want_status) {
+      RETURN_FAIL("tc=%d: status: have \"%s\", want \"%s\"", tc, status,
+                  test_cases[tc].want_status);
+    }
+    wuffs_base__io_buffer want = make_io_buffer_from_string(
+        test_cases[tc].want_ptr, test_cases[tc].want_len);
+    CHECK_STRING(check_io_buffers_equal("", &have, &want));
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_lzo_decode_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_lzo_decode, &g_lzo_midsummer_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_lzo_decode_pi_just_one_read() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_lzo_decode, &g_lzo_pi_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_lzo_decode_pi_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_lzo_decode, &g_lzo_pi_gt, 59, 61);
+}
+
+// ---------------- LZO Benches
+
+const char*  //
+bench_wuffs_lzo_decode_10k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_lzo_decode, WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED,
+      tcounter_dst, &g_lzo_midsummer_gt, UINT64_MAX, UINT64_MAX, 300);
+}
+
+const char*  //
+bench_wuffs_lzo_decode_100k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_lzo_decode, WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED,
+      tcounter_dst, &g_lzo_pi_gt, UINT64_MAX, UINT64_MAX, 30);
+}
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_lzo_decode_artificial,
+    test_wuffs_lzo_decode_interface,
+    test_wuffs_lzo_decode_midsummer,
+    test_wuffs_lzo_decode_pi_just_one_read,
+    test_wuffs_lzo_decode_pi_many_small_writes_reads,
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+    bench_wuffs_lzo_decode_10k,
+    bench_wuffs_lzo_decode_100k,
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/lzo";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -186,6 +186,68 @@ test_wuffs_lzw_decode_bricks_nodither() {
                                   19200, UINT64_MAX, UINT64_MAX);
 }
 
+const char*  //
+test_wuffs_lzw_decode_flush_up_to() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  CHECK_STRING(read_file(&want, "test/data/bricks-dither.indexes"));
+
+  const uint64_t up_tos[] = {1, 7, 100, 5000};
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(up_tos); tc++) {
+    wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+        .data = g_src_slice_u8,
+    });
+    CHECK_STRING(read_file(&src, "test/data/bricks-dither.indexes.giflzw"));
+    uint8_t literal_width = src.data.ptr[src.meta.ri++];
+
+    wuffs_lzw__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_lzw__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_lzw__decoder__set_literal_width(&dec, literal_width);
+
+    // Decode with an empty dst, collecting the output from flush_up_to.
+    wuffs_base__io_buffer empty = ((wuffs_base__io_buffer){});
+    size_t have_len = 0;
+    while (true) {
+      wuffs_base__status status = wuffs_lzw__decoder__transform_io(
+          &dec, &empty, &src, g_work_slice_u8);
+      if (wuffs_base__status__is_ok(&status)) {
+        break;
+      } else if (status.repr != wuffs_base__suspension__short_write) {
+        RETURN_FAIL("tc=%d: transform_io: have \"%s\", want \"%s\"", tc,
+                    status.repr, wuffs_base__suspension__short_write);
+      }
+      while (true) {
+        wuffs_base__slice_u8 s =
+            wuffs_lzw__decoder__flush_up_to(&dec, up_tos[tc]);
+        if (s.len == 0) {
+          break;
+        } else if (s.len > up_tos[tc]) {
+          RETURN_FAIL("tc=%d: flush_up_to: have %zu bytes, want <= %" PRIu64,
+                      tc, s.len, up_tos[tc]);
+        } else if ((s.len > (want.meta.wi - have_len)) ||
+                   memcmp(s.ptr, want.data.ptr + have_len, s.len)) {
+          RETURN_FAIL("tc=%d: flush_up_to: bytes differ at offset %zu", tc,
+                      have_len);
+        }
+        have_len += s.len;
+      }
+    }
+
+    if (have_len != want.meta.wi) {
+      RETURN_FAIL("tc=%d: have_len: have %zu, want %zu", tc, have_len,
+                  want.meta.wi);
+    }
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_lzw_decode_many_big_reads() {
   CHECK_FOCUS(__func__);
@@ -382,6 +444,214 @@ test_wuffs_lzw_decode_width_1() {
   return do_test_wuffs_lzw_decode_width(1, src, want);
 }
 
+// ---------------- LZW Encoder Tests
+
+const char*  //
+test_wuffs_lzw_encode_interface() {
+  CHECK_FOCUS(__func__);
+  wuffs_lzw__encoder enc;
+  CHECK_STATUS("initialize",
+               wuffs_lzw__encoder__initialize(
+                   &enc, sizeof enc, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  return do_test__wuffs_base__io_transformer(
+      wuffs_lzw__encoder__upcast_as__wuffs_base__io_transformer(&enc),
+      "test/data/pi.txt", 0, SIZE_MAX, 50515, 0x20);
+}
+
+// do_test_wuffs_lzw_encode_round_trip encodes src (with the given literal
+// width) and then decodes the result, which should reproduce src.
+const char*  //
+do_test_wuffs_lzw_encode_round_trip(wuffs_base__io_buffer src,
+                                    uint32_t literal_width,
+                                    uint64_t wlimit,
+                                    uint64_t rlimit) {
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+
+  wuffs_lzw__encoder enc;
+  CHECK_STATUS("initialize",
+               wuffs_lzw__encoder__initialize(
+                   &enc, sizeof enc, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_lzw__encoder__set_literal_width(&enc, literal_width);
+  int num_iters = 0;
+  while (true) {
+    num_iters++;
+    wuffs_base__io_buffer limited_have = make_limited_writer(have, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(src, rlimit);
+
+    wuffs_base__status status = wuffs_lzw__encoder__transform_io(
+        &enc, &limited_have, &limited_src, g_work_slice_u8);
+    have.meta.wi += limited_have.meta.wi;
+    src.meta.ri += limited_src.meta.ri;
+    if (wuffs_base__status__is_ok(&status)) {
+      break;
+    }
+    if ((status.repr != wuffs_base__suspension__short_read) &&
+        (status.repr != wuffs_base__suspension__short_write)) {
+      RETURN_FAIL("encode: have \"%s\", want \"%s\" or \"%s\"", status.repr,
+                  wuffs_base__suspension__short_read,
+                  wuffs_base__suspension__short_write);
+    }
+  }
+  if (src.meta.ri != src.meta.wi) {
+    RETURN_FAIL("encode: src was not exhausted");
+  }
+  if ((wlimit < UINT64_MAX) || (rlimit < UINT64_MAX)) {
+    if (num_iters <= 1) {
+      RETURN_FAIL("num_iters: have %d, want > 1", num_iters);
+    }
+  } else if (num_iters != 1) {
+    RETURN_FAIL("num_iters: have %d, want 1", num_iters);
+  }
+  have.meta.closed = true;
+
+  wuffs_lzw__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_lzw__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_lzw__decoder__set_literal_width(&dec, literal_width);
+  CHECK_STATUS("decode", wuffs_lzw__decoder__transform_io(
+                             &dec, &want, &have, g_work_slice_u8));
+  if (have.meta.ri != have.meta.wi) {
+    RETURN_FAIL("decode: have %d unread bytes, want 0",
+                (int)(have.meta.wi - have.meta.ri));
+  }
+
+  return check_io_buffers_equal("", &want, &src);
+}
+
+const char*  //
+do_test_wuffs_lzw_encode_round_trip_file(const char* src_filename,
+                                         uint64_t wlimit,
+                                         uint64_t rlimit) {
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, src_filename));
+  return do_test_wuffs_lzw_encode_round_trip(src, 8, wlimit, rlimit);
+}
+
+const char*  //
+test_wuffs_lzw_encode_round_trip_bricks_dither() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_lzw_encode_round_trip_file(
+      "test/data/bricks-dither.indexes", UINT64_MAX, UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_lzw_encode_round_trip_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_lzw_encode_round_trip_file(
+      "test/data/bricks-gray.indexes", 41, 43);
+}
+
+const char*  //
+test_wuffs_lzw_encode_round_trip_pi() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_lzw_encode_round_trip_file("test/data/pi.txt",
+                                                  UINT64_MAX, UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_lzw_encode_round_trip_width_2() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  // Generate 2-bit literals that are compressible but not trivially so, long
+  // enough that the key-value table fills up and is cleared many times.
+  uint32_t x = 1;
+  for (src.meta.wi = 0; src.meta.wi < 100000; src.meta.wi++) {
+    x = (x * 1103515245) + 12345;
+    src.data.ptr[src.meta.wi] = (uint8_t)((x >> 16) & (x >> 24) & 3);
+  }
+  src.meta.closed = true;
+  return do_test_wuffs_lzw_encode_round_trip(src, 2, UINT64_MAX, UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_lzw_encode_bad_literal() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  src.meta.wi = 3;
+  src.meta.closed = true;
+  src.data.ptr[0] = 0x00;
+  src.data.ptr[1] = 0x03;
+  src.data.ptr[2] = 0x04;
+
+  wuffs_lzw__encoder enc;
+  CHECK_STATUS("initialize",
+               wuffs_lzw__encoder__initialize(
+                   &enc, sizeof enc, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_lzw__encoder__set_literal_width(&enc, 2);
+  wuffs_base__status status =
+      wuffs_lzw__encoder__transform_io(&enc, &have, &src, g_work_slice_u8);
+  if (status.repr != wuffs_lzw__error__bad_literal) {
+    RETURN_FAIL("transform_io: have \"%s\", want \"%s\"", status.repr,
+                wuffs_lzw__error__bad_literal);
+  }
+  if (src.meta.ri != 2) {
+    RETURN_FAIL("src.meta.ri: have %d, want 2", (int)(src.meta.ri));
+  }
+  return NULL;
+}
+
+const char*  //
+do_test_wuffs_lzw_encode_golden(const char* src_str,
+                                bool tiff_flavor,
+                                const uint8_t* want_ptr,
+                                size_t want_len) {
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer src =
+      wuffs_base__ptr_u8__reader((uint8_t*)src_str, strlen(src_str), true);
+  wuffs_base__io_buffer want =
+      wuffs_base__ptr_u8__reader((uint8_t*)want_ptr, want_len, true);
+
+  wuffs_lzw__encoder enc;
+  CHECK_STATUS("initialize",
+               wuffs_lzw__encoder__initialize(
+                   &enc, sizeof enc, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_lzw__encoder__set_quirk_enabled(&enc, WUFFS_LZW__QUIRK_TIFF_FLAVOR,
+                                        tiff_flavor);
+  CHECK_STATUS("transform_io", wuffs_lzw__encoder__transform_io(
+                                   &enc, &have, &src, g_work_slice_u8));
+  return check_io_buffers_equal("", &have, &want);
+}
+
+const char*  //
+test_wuffs_lzw_encode_golden_gif() {
+  CHECK_FOCUS(__func__);
+  // The codes are 0x100 (clear), 0x054 'T', 0x04F 'O', 0x102 "TO" and 0x101
+  // (end), packed 9 bits each, Least Significant Bits first.
+  static const uint8_t want[] = {0x00, 0xA9, 0x3C, 0x11, 0x18, 0x10};
+  return do_test_wuffs_lzw_encode_golden("TOTO", false, want, sizeof want);
+}
+
+const char*  //
+test_wuffs_lzw_encode_golden_tiff() {
+  CHECK_FOCUS(__func__);
+  // The codes are the same as for test_wuffs_lzw_encode_golden_gif, but
+  // packed Most Significant Bits first.
+  static const uint8_t want[] = {0x80, 0x15, 0x09, 0xF0, 0x28, 0x08};
+  return do_test_wuffs_lzw_encode_golden("TOTO", true, want, sizeof want);
+}
+
 // ---------------- LZW Benches
 
 const char*  //
@@ -441,6 +711,7 @@ proc g_tests[] = {
 
     test_wuffs_lzw_decode_bricks_dither,
     test_wuffs_lzw_decode_bricks_nodither,
+    test_wuffs_lzw_decode_flush_up_to,
     test_wuffs_lzw_decode_interface,
     test_wuffs_lzw_decode_many_big_reads,
     test_wuffs_lzw_decode_many_small_writes_reads,
@@ -449,6 +720,14 @@ proc g_tests[] = {
     test_wuffs_lzw_decode_pi,
     test_wuffs_lzw_decode_width_0,
     test_wuffs_lzw_decode_width_1,
+    test_wuffs_lzw_encode_bad_literal,
+    test_wuffs_lzw_encode_golden_gif,
+    test_wuffs_lzw_encode_golden_tiff,
+    test_wuffs_lzw_encode_interface,
+    test_wuffs_lzw_encode_round_trip_bricks_dither,
+    test_wuffs_lzw_encode_round_trip_many_small_writes_reads,
+    test_wuffs_lzw_encode_round_trip_pi,
+    test_wuffs_lzw_encode_round_trip_width_2,
 
     NULL,
 };
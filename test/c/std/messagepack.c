@@ -0,0 +1,411 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror messagepack.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__CBOR
+#define WUFFS_CONFIG__MODULE__MESSAGEPACK
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_messagepack_messagepack_examples_gt = {
+    .want_filename = "test/data/messagepack-examples.tokens",
+    .src_filename = "test/data/messagepack-examples.msgpack",
+};
+
+// ---------------- MessagePack Tests
+
+const char*  //
+test_wuffs_messagepack_decode_interface() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_messagepack__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_messagepack__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  CHECK_STRING(do_test__wuffs_base__token_decoder(
+      wuffs_messagepack__decoder__upcast_as__wuffs_base__token_decoder(&dec),
+      &g_messagepack_messagepack_examples_gt));
+
+  return NULL;
+}
+
+const char*  //
+test_wuffs_messagepack_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  // This suite contains invalid examples, which should be rejected.
+  char* test_cases[] = {
+      // Never used opcode.
+      "\xC1",
+      // Truncated (uint 8) value.
+      "\xCC",
+      // Truncated (array 16) header.
+      "\xDC\x01",
+      // Truncated (str 8) string.
+      "\xD9\x05\x61\x62",
+      // Truncated (ext 8) data.
+      "\xC7\x02\x01\x61",
+      // Invalid UTF-8 in a str.
+      "\xA2\xFF\xFE",
+      // Array with 1 of 2 elements.
+      "\x92\x01",
+      // Map with a key but no value.
+      "\x81\x01",
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__token tok_array[256];
+    wuffs_base__token_buffer tok_buf =
+        wuffs_base__slice_token__writer(wuffs_base__make_slice_token(
+            &tok_array[0], WUFFS_TESTLIB_ARRAY_SIZE(tok_array)));
+    const bool closed = true;
+    wuffs_base__io_buffer io_buf = wuffs_base__slice_u8__reader(
+        wuffs_base__make_slice_u8((uint8_t*)(test_cases[tc]),
+                                  strlen(test_cases[tc])),
+        closed);
+
+    wuffs_messagepack__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_messagepack__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__status status = wuffs_messagepack__decoder__decode_tokens(
+        &dec, &tok_buf, &io_buf, g_work_slice_u8);
+    if (!wuffs_base__status__is_error(&status)) {
+      RETURN_FAIL("tc=%d: have \"%s\", want an error", tc, status.repr);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_messagepack_decode_valid() {
+  CHECK_FOCUS(__func__);
+
+  // This suite contains valid examples, similar to the
+  // test_wuffs_messagepack_decode_invalid examples, but they should be
+  // accepted.
+  char* test_cases[] = {
+      // Map with a key and a value.
+      "\x81\x01\x02",
+      // Array holding an empty array and an empty map.
+      "\x92\x90\x80",
+      // Ext 8 with 1 byte of data.
+      "\xC7\x01\x01\xFF",
+      // Fixext 1 with a negative extension type.
+      "\xD4\xFF\x01",
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__token tok_array[256];
+    wuffs_base__token_buffer tok_buf =
+        wuffs_base__slice_token__writer(wuffs_base__make_slice_token(
+            &tok_array[0], WUFFS_TESTLIB_ARRAY_SIZE(tok_array)));
+    const bool closed = true;
+    wuffs_base__io_buffer io_buf = wuffs_base__slice_u8__reader(
+        wuffs_base__make_slice_u8((uint8_t*)(test_cases[tc]),
+                                  strlen(test_cases[tc])),
+        closed);
+
+    wuffs_messagepack__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_messagepack__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__status status = wuffs_messagepack__decoder__decode_tokens(
+        &dec, &tok_buf, &io_buf, g_work_slice_u8);
+    if (!wuffs_base__status__is_ok(&status)) {
+      RETURN_FAIL("tc=%d: have \"%s\", want no error", tc, status.repr);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_messagepack_decode_recursion_depth() {
+  CHECK_FOCUS(__func__);
+
+  // Nest fixarrays, each holding one element, 1024 and 1025 deep. The
+  // innermost element is nil.
+  int depth;
+  for (depth = 1024; depth <= 1025; depth++) {
+    memset(g_src_slice_u8.ptr, 0x91, depth);
+    g_src_slice_u8.ptr[depth] = 0xC0;
+
+    wuffs_base__token_buffer tok_buf = ((wuffs_base__token_buffer){
+        .data = g_have_slice_token,
+    });
+    const bool closed = true;
+    wuffs_base__io_buffer io_buf = wuffs_base__slice_u8__reader(
+        wuffs_base__make_slice_u8(g_src_slice_u8.ptr, depth + 1), closed);
+
+    wuffs_messagepack__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_messagepack__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__status status = wuffs_messagepack__decoder__decode_tokens(
+        &dec, &tok_buf, &io_buf, g_work_slice_u8);
+    const char* want =
+        (depth <= 1024) ? NULL
+                        : wuffs_messagepack__error__unsupported_recursion_depth;
+    if (status.repr != want) {
+      RETURN_FAIL("depth=%d: have \"%s\", want \"%s\"", depth, status.repr,
+                  want);
+    } else if ((want != NULL) && (io_buf.meta.ri != 1024)) {
+      RETURN_FAIL("depth=%d: ri: have %zu, want 1024", depth, io_buf.meta.ri);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_messagepack_decode_same_as_cbor() {
+  CHECK_FOCUS(__func__);
+
+  // Both encode [1, [2, 3], {"a": true, "b": null}, "xyz", h'0102', -200,
+  // 70000, 1.5], with 1.5 as a float 64.
+  static const uint8_t src_cbor[] = {
+      0x88, 0x01, 0x82, 0x02, 0x03, 0xA2, 0x61, 0x61, 0xF5, 0x61,
+      0x62, 0xF6, 0x63, 0x78, 0x79, 0x7A, 0x42, 0x01, 0x02, 0x38,
+      0xC7, 0x1A, 0x00, 0x01, 0x11, 0x70, 0xFB, 0x3F, 0xF8, 0x00,
+      0x00, 0x00, 0x00, 0x00, 0x00,
+  };
+  static const uint8_t src_messagepack[] = {
+      0x98, 0x01, 0x92, 0x02, 0x03, 0x82, 0xA1, 0x61, 0xC3, 0xA1,
+      0x62, 0xC0, 0xA3, 0x78, 0x79, 0x7A, 0xC4, 0x02, 0x01, 0x02,
+      0xD1, 0xFF, 0x38, 0xCE, 0x00, 0x01, 0x11, 0x70, 0xCB, 0x3F,
+      0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+  };
+
+  wuffs_base__token tok_array_cbor[64];
+  wuffs_base__token_buffer tok_buf_cbor =
+      wuffs_base__slice_token__writer(wuffs_base__make_slice_token(
+          &tok_array_cbor[0], WUFFS_TESTLIB_ARRAY_SIZE(tok_array_cbor)));
+  wuffs_base__io_buffer io_buf_cbor = wuffs_base__ptr_u8__reader(
+      (uint8_t*)(src_cbor), sizeof src_cbor, true);
+  wuffs_cbor__decoder dec_cbor;
+  CHECK_STATUS("initialize",
+               wuffs_cbor__decoder__initialize(
+                   &dec_cbor, sizeof dec_cbor, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  CHECK_STATUS("decode_tokens (cbor)",
+               wuffs_cbor__decoder__decode_tokens(
+                   &dec_cbor, &tok_buf_cbor, &io_buf_cbor, g_work_slice_u8));
+
+  wuffs_base__token tok_array_messagepack[64];
+  wuffs_base__token_buffer tok_buf_messagepack =
+      wuffs_base__slice_token__writer(wuffs_base__make_slice_token(
+          &tok_array_messagepack[0],
+          WUFFS_TESTLIB_ARRAY_SIZE(tok_array_messagepack)));
+  wuffs_base__io_buffer io_buf_messagepack = wuffs_base__ptr_u8__reader(
+      (uint8_t*)(src_messagepack), sizeof src_messagepack, true);
+  wuffs_messagepack__decoder dec_messagepack;
+  CHECK_STATUS("initialize",
+               wuffs_messagepack__decoder__initialize(
+                   &dec_messagepack, sizeof dec_messagepack, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  CHECK_STATUS("decode_tokens (messagepack)",
+               wuffs_messagepack__decoder__decode_tokens(
+                   &dec_messagepack, &tok_buf_messagepack,
+                   &io_buf_messagepack, g_work_slice_u8));
+
+  // The token lengths differ, as the two formats' headers differ, but
+  // everything else should match.
+  if (tok_buf_cbor.meta.wi != tok_buf_messagepack.meta.wi) {
+    RETURN_FAIL("number of tokens: cbor %zu, messagepack %zu",
+                tok_buf_cbor.meta.wi, tok_buf_messagepack.meta.wi);
+  }
+  size_t i;
+  for (i = 0; i < tok_buf_cbor.meta.wi; i++) {
+    const uint64_t mask = ~(((uint64_t)0xFFFF)
+                            << WUFFS_BASE__TOKEN__LENGTH__SHIFT);
+    uint64_t have = tok_array_messagepack[i].repr & mask;
+    uint64_t want = tok_array_cbor[i].repr & mask;
+    if (have != want) {
+      RETURN_FAIL("i=%zu: have 0x%016" PRIX64 ", want 0x%016" PRIX64, i, have,
+                  want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_messagepack_decode_short_reads() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(
+      read_file(&src, g_messagepack_messagepack_examples_gt.src_filename));
+  const size_t src_len = src.meta.wi;
+
+  wuffs_messagepack__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_messagepack__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  // Reveal the source one byte at a time, consuming tokens two at a time.
+  src.meta.wi = 0;
+  src.meta.closed = false;
+  uint64_t pos = 0;
+  while (true) {
+    wuffs_base__token tok_array[2];
+    wuffs_base__token_buffer tok_buf =
+        wuffs_base__slice_token__writer(wuffs_base__make_slice_token(
+            &tok_array[0], WUFFS_TESTLIB_ARRAY_SIZE(tok_array)));
+    wuffs_base__status status = wuffs_messagepack__decoder__decode_tokens(
+        &dec, &tok_buf, &src, g_work_slice_u8);
+    size_t i;
+    for (i = 0; i < tok_buf.meta.wi; i++) {
+      pos += wuffs_base__token__length(&tok_array[i]);
+    }
+
+    if (wuffs_base__status__is_ok(&status)) {
+      break;
+    } else if (status.repr == wuffs_base__suspension__short_read) {
+      if (src.meta.wi >= src_len) {
+        RETURN_FAIL("short read at end of input");
+      }
+      src.meta.wi++;
+      src.meta.closed = src.meta.wi == src_len;
+    } else if (status.repr != wuffs_base__suspension__short_write) {
+      RETURN_FAIL("decode_tokens: \"%s\"", status.repr);
+    }
+  }
+
+  if (pos != src_len) {
+    RETURN_FAIL("pos: have %" PRIu64 ", want %zu", pos, src_len);
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- MessagePack Benches
+
+// No MessagePack benches.
+
+// ---------------- Mimic Benches
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_messagepack_decode_interface,
+    test_wuffs_messagepack_decode_invalid,
+    test_wuffs_messagepack_decode_recursion_depth,
+    test_wuffs_messagepack_decode_same_as_cbor,
+    test_wuffs_messagepack_decode_short_reads,
+    test_wuffs_messagepack_decode_valid,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No MessagePack benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/messagepack";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -0,0 +1,445 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror mp4.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__MP4
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+// No MP4 golden tests.
+
+// ---------------- MP4 Tests
+
+// wuffs_mp4_decode decodes src into tok, limiting each decode_tokens call to
+// wlimit tokens and rlimit bytes.
+const char*  //
+wuffs_mp4_decode(wuffs_base__token_buffer* tok,
+                 wuffs_base__io_buffer* src,
+                 uint64_t wlimit,
+                 uint64_t rlimit) {
+  wuffs_mp4__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_mp4__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  while (true) {
+    wuffs_base__token_buffer limited_tok =
+        make_limited_token_writer(*tok, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+
+    wuffs_base__status status = wuffs_mp4__decoder__decode_tokens(
+        &dec, &limited_tok, &limited_src, g_work_slice_u8);
+
+    tok->meta.wi += limited_tok.meta.wi;
+    src->meta.ri += limited_src.meta.ri;
+
+    if (((wlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_write)) ||
+        ((rlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_read))) {
+      continue;
+    }
+    return status.repr;
+  }
+}
+
+// mp4_summarize writes a summary of the tokens to dst, which has a capacity of
+// at least 1024 bytes. Each box is summarized as "[type:size]", with its
+// children (or, for a leaf box, the combined length of its string tokens)
+// before the "]". It also checks that the token lengths sum to src_len.
+const char*  //
+mp4_summarize(char* dst, wuffs_base__token_buffer* tok, uint64_t src_len) {
+  char* d = dst;
+  uint64_t total_length = 0;
+  uint64_t string_length = 0;
+  bool in_string = false;
+  size_t i;
+  for (i = tok->meta.ri; i < tok->meta.wi; i++) {
+    wuffs_base__token* t = &tok->data.ptr[i];
+    total_length += wuffs_base__token__length(t);
+    if (wuffs_base__token__value_major(t) == WUFFS_MP4__TOKEN_VALUE_MAJOR) {
+      if ((wuffs_base__token__value_minor(t) !=
+           WUFFS_MP4__TOKEN_VALUE_MINOR__BOX_TYPE) ||
+          (++i >= tok->meta.wi)) {
+        RETURN_FAIL("i=%zu: bad box type token", i);
+      }
+      uint32_t x = (uint32_t)(wuffs_base__token__value_extension(
+          &tok->data.ptr[i]));
+      d += sprintf(d, "[%c%c%c%c", (char)(x >> 24), (char)(x >> 16),
+                   (char)(x >> 8), (char)(x >> 0));
+      continue;
+    }
+    switch (wuffs_base__token__value_base_category(t)) {
+      case WUFFS_BASE__TOKEN__VBC__STRUCTURE:
+        if (wuffs_base__token__value_base_detail(t) &
+            WUFFS_BASE__TOKEN__VBD__STRUCTURE__POP) {
+          if (in_string) {
+            d += sprintf(d, " %" PRIu64, string_length);
+            string_length = 0;
+            in_string = false;
+          }
+          d += sprintf(d, "]");
+        }
+        break;
+      case WUFFS_BASE__TOKEN__VBC__INLINE_INTEGER_UNSIGNED:
+        if (++i >= tok->meta.wi) {
+          RETURN_FAIL("i=%zu: bad box size token", i);
+        }
+        total_length += wuffs_base__token__length(&tok->data.ptr[i]);
+        d += sprintf(
+            d, ":%" PRIu64,
+            (((uint64_t)(wuffs_base__token__value_base_detail(t))) << 46) |
+                ((uint64_t)(wuffs_base__token__value_extension(
+                    &tok->data.ptr[i]))));
+        break;
+      case WUFFS_BASE__TOKEN__VBC__STRING:
+        string_length += wuffs_base__token__length(t);
+        in_string = true;
+        break;
+      default:
+        RETURN_FAIL("i=%zu: unexpected token", i);
+    }
+    if ((d - dst) > 960) {
+      RETURN_FAIL("summary is too long");
+    }
+  }
+  if (total_length != src_len) {
+    RETURN_FAIL("total length: have %" PRIu64 ", want %" PRIu64, total_length,
+                src_len);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_mp4_decode_boxes() {
+  CHECK_FOCUS(__func__);
+
+  const char src_ptr[] =
+      // A 16-byte "ftyp" box.
+      "\x00\x00\x00\x10"
+      "ftypisom\x00\x00\x02\x00"
+      // A "moov" box holding a "trak" box (holding a "tkhd" box) and a 64-bit
+      // sized "udta" box.
+      "\x00\x00\x00\x32"
+      "moov"
+      "\x00\x00\x00\x12"
+      "trak"
+      "\x00\x00\x00\x0A"
+      "tkhdAB"
+      "\x00\x00\x00\x01"
+      "udta"
+      "\x00\x00\x00\x00\x00\x00\x00\x18"
+      "\x00\x00\x00\x08"
+      "free"
+      // A "meta" box, whose version and flags are part of its header.
+      "\x00\x00\x00\x14"
+      "meta\x00\x00\x00\x00"
+      "\x00\x00\x00\x08"
+      "hdlr"
+      // A "uuid" box.
+      "\x00\x00\x00\x1A"
+      "uuid0123456789ABCDEFxy"
+      // A "mdat" box that extends to the end of the file.
+      "\x00\x00\x00\x00"
+      "mdat0123456789";
+  const size_t src_len = sizeof(src_ptr) - 1;
+  const char* want =
+      "[ftyp:16 8][moov:50[trak:18[tkhd:10 2]][udta:24[free:8]]]"
+      "[meta:20[hdlr:8]][uuid:26 2][mdat:0 10]";
+
+  int tc;
+  for (tc = 0; tc < 4; tc++) {
+    uint64_t wlimit = (tc & 1) ? 5 : UINT64_MAX;
+    uint64_t rlimit =
+        (tc & 2) ? WUFFS_MP4__DECODER_SRC_IO_BUFFER_LENGTH_MIN_INCL
+                 : UINT64_MAX;
+
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src =
+        wuffs_base__ptr_u8__reader((uint8_t*)src_ptr, src_len, true);
+    CHECK_STRING(wuffs_mp4_decode(&tok, &src, wlimit, rlimit));
+    if (src.meta.ri != src_len) {
+      RETURN_FAIL("tc=%d: src.meta.ri: have %zu, want %zu", tc, src.meta.ri,
+                  src_len);
+    }
+
+    char have[1024];
+    CHECK_STRING(mp4_summarize(have, &tok, src_len));
+    if (strcmp(have, want)) {
+      RETURN_FAIL("tc=%d:\nhave \"%s\"\nwant \"%s\"", tc, have, want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_mp4_decode_end_of_data() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_mp4__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_mp4__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  int i;
+  for (i = 0; i < 2; i++) {
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)("\x00\x00\x00\x08"
+                   "free"),
+        8, true);
+    wuffs_base__status status =
+        wuffs_mp4__decoder__decode_tokens(&dec, &tok, &src, g_work_slice_u8);
+    const char* want = i ? wuffs_base__note__end_of_data : NULL;
+    if (status.repr != want) {
+      RETURN_FAIL("i=%d: have \"%s\", want \"%s\"", i, status.repr, want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_mp4_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* want;
+    const char* src_ptr;
+    size_t src_len;
+  } test_cases[] = {
+      {
+          // Box size smaller than the 8-byte header.
+          .want = wuffs_mp4__error__bad_box_size,
+          .src_ptr = "\x00\x00\x00\x07"
+                     "free",
+          .src_len = 8,
+      },
+      {
+          // Box size smaller than the 16-byte header.
+          .want = wuffs_mp4__error__bad_box_size,
+          .src_ptr = "\x00\x00\x00\x01"
+                     "free"
+                     "\x00\x00\x00\x00\x00\x00\x00\x0F",
+          .src_len = 16,
+      },
+      {
+          // Box size smaller than the 12-byte "meta" header.
+          .want = wuffs_mp4__error__bad_box_size,
+          .src_ptr = "\x00\x00\x00\x08"
+                     "meta\x00\x00\x00\x00",
+          .src_len = 12,
+      },
+      {
+          // Child box larger than its parent.
+          .want = wuffs_mp4__error__bad_box_size,
+          .src_ptr = "\x00\x00\x00\x10"
+                     "moov"
+                     "\x00\x00\x00\x09"
+                     "free",
+          .src_len = 16,
+      },
+      {
+          // Zero (to the end of the file) box size for a child box.
+          .want = wuffs_mp4__error__bad_box_size,
+          .src_ptr = "\x00\x00\x00\x10"
+                     "moov"
+                     "\x00\x00\x00\x00"
+                     "free",
+          .src_len = 16,
+      },
+      {
+          // A 64-bit box size that overflows its parent.
+          .want = wuffs_mp4__error__bad_box_size,
+          .src_ptr = "\x00\x00\x00\x20"
+                     "moov"
+                     "\x00\x00\x00\x01"
+                     "free"
+                     "\xFF\xFF\xFF\xFF\xFF\xFF\xFF\xFF",
+          .src_len = 24,
+      },
+      {
+          // Truncated header.
+          .want = wuffs_mp4__error__truncated_input,
+          .src_ptr = "\x00\x00\x00\x08"
+                     "fre",
+          .src_len = 7,
+      },
+      {
+          // Truncated body.
+          .want = wuffs_mp4__error__truncated_input,
+          .src_ptr = "\x00\x00\x00\x0A"
+                     "free\x00",
+          .src_len = 9,
+      },
+      {
+          // Truncated child box.
+          .want = wuffs_mp4__error__truncated_input,
+          .src_ptr = "\x00\x00\x00\x10"
+                     "moov"
+                     "\x00\x00\x00\x08",
+          .src_len = 12,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)(test_cases[tc].src_ptr), test_cases[tc].src_len, true);
+    const char* have = wuffs_mp4_decode(&tok, &src, UINT64_MAX, UINT64_MAX);
+    if (have != test_cases[tc].want) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, have,
+                  test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_mp4_decode_recursion_depth() {
+  CHECK_FOCUS(__func__);
+
+  // Make a chain of nested "moov" boxes, each one 8 bytes smaller than its
+  // parent, ending with a "free" box.
+  uint8_t src_array[8 * 40];
+  int n;
+  for (n = 32; n <= 33; n++) {
+    int i;
+    for (i = 0; i < n; i++) {
+      wuffs_base__poke_u32be__no_bounds_check(&src_array[8 * i],
+                                              (uint32_t)(8 * (n - i)));
+      memcpy(&src_array[(8 * i) + 4], (i < (n - 1)) ? "moov" : "free", 4);
+    }
+
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src =
+        wuffs_base__ptr_u8__reader(&src_array[0], 8 * n, true);
+    const char* have = wuffs_mp4_decode(&tok, &src, UINT64_MAX, UINT64_MAX);
+    const char* want =
+        (n <= WUFFS_MP4__DECODER_DEPTH_MAX_INCL)
+            ? NULL
+            : wuffs_mp4__error__unsupported_recursion_depth;
+    if (have != want) {
+      RETURN_FAIL("n=%d: have \"%s\", want \"%s\"", n, have, want);
+    }
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- MP4 Benches
+
+// No MP4 benches.
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_mp4_decode_boxes,
+    test_wuffs_mp4_decode_end_of_data,
+    test_wuffs_mp4_decode_invalid,
+    test_wuffs_mp4_decode_recursion_depth,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No MP4 benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/mp4";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -0,0 +1,421 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror netpbm.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__NETPBM
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Netpbm Tests
+
+const char*  //
+test_wuffs_netpbm_decode_interface() {
+  CHECK_FOCUS(__func__);
+
+  const struct {
+    const char* filename;
+    wuffs_base__color_u32_argb_premul want_final_pixel;
+  } tcs[] = {
+      {.filename = "test/data/hippopotamus.pam", .want_final_pixel = 0xFFF5F5F5},
+      {.filename = "test/data/hippopotamus.pgm", .want_final_pixel = 0xFFF5F5F5},
+      {.filename = "test/data/hippopotamus.plain.ppm",
+       .want_final_pixel = 0xFFF5F5F5},
+      {.filename = "test/data/hippopotamus.ppm", .want_final_pixel = 0xFFF5F5F5},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(tcs); tc++) {
+    wuffs_netpbm__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_netpbm__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    const char* have = do_test__wuffs_base__image_decoder(
+        wuffs_netpbm__decoder__upcast_as__wuffs_base__image_decoder(&dec),
+        tcs[tc].filename, 0, SIZE_MAX, 36, 28, tcs[tc].want_final_pixel);
+    if (have) {
+      RETURN_FAIL("tc=%d (%s): %s", tc, tcs[tc].filename, have);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_netpbm_decode_frame_config() {
+  CHECK_FOCUS(__func__);
+  wuffs_netpbm__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_netpbm__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__frame_config fc = ((wuffs_base__frame_config){});
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hippopotamus.pam"));
+  CHECK_STATUS("decode_frame_config #0",
+               wuffs_netpbm__decoder__decode_frame_config(&dec, &fc, &src));
+
+  uint64_t have_io_position = wuffs_base__frame_config__io_position(&fc);
+  if (have_io_position != 67) {
+    RETURN_FAIL("io_position: have %" PRIu64 ", want 67", have_io_position);
+  }
+
+  wuffs_base__status status =
+      wuffs_netpbm__decoder__decode_frame_config(&dec, &fc, &src);
+  if (status.repr != wuffs_base__note__end_of_data) {
+    RETURN_FAIL("decode_frame_config #1: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__note__end_of_data);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_netpbm_decode_row_groups() {
+  CHECK_FOCUS(__func__);
+  const char* filenames[] = {
+      "test/data/hippopotamus.plain.ppm",
+      "test/data/hippopotamus.ppm",
+  };
+  const uint32_t row_group_heights[] = {1, 5, 28, 100};
+  size_t f;
+  for (f = 0; f < WUFFS_TESTLIB_ARRAY_SIZE(filenames); f++) {
+    size_t i;
+    for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(row_group_heights); i++) {
+      wuffs_netpbm__decoder full;
+      CHECK_STATUS("initialize (full)",
+                   wuffs_netpbm__decoder__initialize(
+                       &full, sizeof full, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+      wuffs_netpbm__decoder grouped;
+      CHECK_STATUS("initialize (grouped)",
+                   wuffs_netpbm__decoder__initialize(
+                       &grouped, sizeof grouped, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+      CHECK_STRING(do_test__wuffs_base__image_decoder__row_groups(
+          wuffs_netpbm__decoder__upcast_as__wuffs_base__image_decoder(&full),
+          wuffs_netpbm__decoder__upcast_as__wuffs_base__image_decoder(&grouped),
+          filenames[f], row_group_heights[i]));
+    }
+  }
+  return NULL;
+}
+
+// decode_netpbm_string decodes src into pb, a BGRA_NONPREMUL pixel buffer
+// backed by g_pixel_slice_u8.
+const char*  //
+decode_netpbm_string(wuffs_base__pixel_buffer* pb, const char* src_ptr) {
+  wuffs_netpbm__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_netpbm__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+      (uint8_t*)(src_ptr), strlen(src_ptr), true);
+  CHECK_STATUS("decode_image_config",
+               wuffs_netpbm__decoder__decode_image_config(&dec, &ic, &src));
+  wuffs_base__pixel_config__set(
+      &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE,
+      wuffs_base__pixel_config__width(&ic.pixcfg),
+      wuffs_base__pixel_config__height(&ic.pixcfg));
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     pb, &ic.pixcfg, g_pixel_slice_u8));
+  CHECK_STATUS("decode_frame", wuffs_netpbm__decoder__decode_frame(
+                                   &dec, pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC,
+                                   g_work_slice_u8, NULL));
+  return NULL;
+}
+
+const char*  //
+test_wuffs_netpbm_decode_variants() {
+  CHECK_FOCUS(__func__);
+
+  // Each want array holds the BGRA_NONPREMUL pixels in row-major order.
+  const struct {
+    const char* src;
+    uint32_t num_pixels;
+    uint32_t want[10];
+  } tcs[] = {
+      {
+          // Plain PBM, with a comment. 1 means black.
+          .src = "P1\n# Comment.\n3 1\n0 1\n0",
+          .num_pixels = 3,
+          .want = {0xFFFFFFFF, 0xFF000000, 0xFFFFFFFF},
+      },
+      {
+          // Raw PBM. Each row is padded to a whole number of bytes.
+          .src = "P4\n5 2\n\x88\x40",
+          .num_pixels = 10,
+          .want = {0xFF000000, 0xFFFFFFFF, 0xFFFFFFFF, 0xFFFFFFFF, 0xFF000000,
+                   0xFFFFFFFF, 0xFF000000, 0xFFFFFFFF, 0xFFFFFFFF, 0xFFFFFFFF},
+      },
+      {
+          // Plain PGM, scaled from a maxval of 15.
+          .src = "P2\n3 1\n15\n0 7 15\n",
+          .num_pixels = 3,
+          .want = {0xFF000000, 0xFF777777, 0xFFFFFFFF},
+      },
+      {
+          // Raw PGM, 16 bits per sample.
+          .src = "P5\n2 1\n65535\n\x12\x34\xAB\xCD",
+          .num_pixels = 2,
+          .want = {0xFF121212, 0xFFABABAB},
+      },
+      {
+          // Plain PPM, 16 bits per sample.
+          .src = "P3\n1 1\n65535\n65535 32768 1\n",
+          .num_pixels = 1,
+          .want = {0xFFFF8000},
+      },
+      {
+          // Raw PPM, scaled from a maxval of 1023.
+          .src = "P6\n1 1\n1023\n\x03\xFF\x02\x01\x01\x01",
+          .num_pixels = 1,
+          .want = {0xFFFF8040},
+      },
+      {
+          // PAM, GRAYSCALE_ALPHA.
+          .src = "P7\nWIDTH 2\nHEIGHT 1\nDEPTH 2\nMAXVAL 255\n"
+                 "TUPLTYPE GRAYSCALE_ALPHA\nENDHDR\n\x40\x80\xC0\xFF",
+          .num_pixels = 2,
+          .want = {0x80404040, 0xFFC0C0C0},
+      },
+      {
+          // PAM, RGB_ALPHA, 16 bits per sample.
+          .src = "P7\n# Comment.\nWIDTH 1\nHEIGHT 1\nDEPTH 4\nMAXVAL 65535\n"
+                 "ENDHDR\n\xFF\xFF\x01\x01\x12\x34\x80\x01",
+          .num_pixels = 1,
+          .want = {0x80FF0112},
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(tcs); tc++) {
+    wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+    const char* status = decode_netpbm_string(&pb, tcs[tc].src);
+    if (status) {
+      RETURN_FAIL("tc=%d: %s", tc, status);
+    }
+    uint32_t width = wuffs_base__pixel_config__width(&pb.pixcfg);
+    uint32_t height = wuffs_base__pixel_config__height(&pb.pixcfg);
+    if ((width * height) != tcs[tc].num_pixels) {
+      RETURN_FAIL("tc=%d: num_pixels: have %" PRIu32 ", want %" PRIu32, tc,
+                  width * height, tcs[tc].num_pixels);
+    }
+    wuffs_base__table_u8 tab = wuffs_base__pixel_buffer__plane(&pb, 0);
+    uint32_t i;
+    for (i = 0; i < tcs[tc].num_pixels; i++) {
+      uint32_t have = wuffs_base__peek_u32le__no_bounds_check(
+          tab.ptr + ((i / width) * tab.stride) + (4 * (i % width)));
+      if (have != tcs[tc].want[i]) {
+        RETURN_FAIL("tc=%d, i=%" PRIu32 ": have 0x%08" PRIX32
+                    ", want 0x%08" PRIX32,
+                    tc, i, have, tcs[tc].want[i]);
+      }
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_netpbm_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  const struct {
+    const char* src;
+    const char* want;
+  } tcs[] = {
+      {
+          .src = "P8\n1 1\n255\n\x01",
+          .want = wuffs_netpbm__error__bad_header,
+      },
+      {
+          .src = "P5\n1 1\n0\n\x01",
+          .want = wuffs_netpbm__error__bad_header,
+      },
+      {
+          .src = "P5\n1 1\n65536\n\x01",
+          .want = wuffs_netpbm__error__bad_header,
+      },
+      {
+          .src = "P5\n1 1\n255Z\x01",
+          .want = wuffs_netpbm__error__bad_header,
+      },
+      {
+          .src = "P2\n99999999999 1\n255\n0\n",
+          .want = wuffs_netpbm__error__bad_number,
+      },
+      {
+          .src = "P2\n1 1\n255\n256\n",
+          .want = wuffs_netpbm__error__bad_sample_value,
+      },
+      {
+          .src = "P1\n1 1\n2\n",
+          .want = wuffs_netpbm__error__bad_sample_value,
+      },
+      {
+          .src = "P7\nWIDTH 1\nHEIGHT 1\nDEPTH 5\nMAXVAL 255\nENDHDR\n\x01",
+          .want = wuffs_netpbm__error__unsupported_netpbm_file,
+      },
+      {
+          // Missing DEPTH.
+          .src = "P7\nWIDTH 1\nHEIGHT 1\nMAXVAL 255\nENDHDR\n\x01",
+          .want = wuffs_netpbm__error__bad_header,
+      },
+      {
+          .src = "P7\nWIDTH 1\nHEIGHT 1\nDEPTH 1\nMAXVAL 255\nBOGUS 1\n"
+                 "ENDHDR\n\x01",
+          .want = wuffs_netpbm__error__bad_header,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(tcs); tc++) {
+    wuffs_netpbm__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_netpbm__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__pixel_config pixcfg = ((wuffs_base__pixel_config){});
+    wuffs_base__pixel_config__set(&pixcfg,
+                                  WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+                                  WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, 1, 1);
+    wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+    CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                       &pb, &pixcfg, g_pixel_slice_u8));
+
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)(tcs[tc].src), strlen(tcs[tc].src), true);
+    wuffs_base__status status = wuffs_netpbm__decoder__decode_frame(
+        &dec, &pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC, g_work_slice_u8, NULL);
+    if (status.repr != tcs[tc].want) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, status.repr,
+                  tcs[tc].want);
+    }
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Netpbm Benches
+
+// No Netpbm benches.
+
+// ---------------- Mimic Benches
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_netpbm_decode_frame_config,
+    test_wuffs_netpbm_decode_interface,
+    test_wuffs_netpbm_decode_invalid,
+    test_wuffs_netpbm_decode_row_groups,
+    test_wuffs_netpbm_decode_variants,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No Netpbm benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/netpbm";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -108,6 +108,211 @@ test_wuffs_nie_decode_frame_config() {
   return NULL;
 }
 
+const char*  //
+test_wuffs_nie_decode_row_groups() {
+  CHECK_FOCUS(__func__);
+  const uint32_t row_group_heights[] = {1, 5, 28, 100};
+  size_t i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(row_group_heights); i++) {
+    wuffs_nie__decoder full;
+    CHECK_STATUS("initialize (full)",
+                 wuffs_nie__decoder__initialize(
+                     &full, sizeof full, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_nie__decoder grouped;
+    CHECK_STATUS("initialize (grouped)",
+                 wuffs_nie__decoder__initialize(
+                     &grouped, sizeof grouped, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    CHECK_STRING(do_test__wuffs_base__image_decoder__row_groups(
+        wuffs_nie__decoder__upcast_as__wuffs_base__image_decoder(&full),
+        wuffs_nie__decoder__upcast_as__wuffs_base__image_decoder(&grouped),
+        "test/data/hippopotamus.nie", row_group_heights[i]));
+  }
+  return NULL;
+}
+
+// decode_nie_file decodes the named NIE file into pb, a BGRA_NONPREMUL pixel
+// buffer backed by g_pixel_slice_u8.
+const char*  //
+decode_nie_file(wuffs_base__pixel_buffer* pb, const char* filename) {
+  wuffs_nie__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_nie__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, filename));
+  CHECK_STATUS("decode_image_config",
+               wuffs_nie__decoder__decode_image_config(&dec, &ic, &src));
+  wuffs_base__pixel_config__set(
+      &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE,
+      wuffs_base__pixel_config__width(&ic.pixcfg),
+      wuffs_base__pixel_config__height(&ic.pixcfg));
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     pb, &ic.pixcfg, g_pixel_slice_u8));
+  CHECK_STATUS("decode_frame", wuffs_nie__decoder__decode_frame(
+                                   &dec, pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC,
+                                   g_work_slice_u8, NULL));
+  return NULL;
+}
+
+const char*  //
+test_wuffs_nie_encode_frame() {
+  CHECK_FOCUS(__func__);
+  const char* filename = "test/data/hippopotamus.nie";
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STRING(decode_nie_file(&pb, filename));
+
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  CHECK_STRING(read_file(&want, filename));
+
+  // Encode with an output buffer whose capacity grows by a little each time,
+  // so that the encoder has to suspend (and resume) many times.
+  int tc;
+  for (tc = 0; tc < 2; tc++) {
+    wuffs_nie__encoder enc;
+    CHECK_STATUS("initialize",
+                 wuffs_nie__encoder__initialize(
+                     &enc, sizeof enc, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+        .data = g_have_slice_u8,
+    });
+    if (tc == 1) {
+      have.data.len = 0;
+    }
+    while (true) {
+      wuffs_base__status status =
+          wuffs_nie__encoder__encode_frame(&enc, &have, &pb);
+      if ((tc == 1) && (status.repr == wuffs_base__suspension__short_write) &&
+          (have.data.len < g_have_slice_u8.len)) {
+        have.data.len += 13;
+        continue;
+      }
+      CHECK_STATUS("encode_frame", status);
+      break;
+    }
+    CHECK_STRING(check_io_buffers_equal("", &have, &want));
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_nie_encode_nia() {
+  CHECK_FOCUS(__func__);
+
+  // This is the "Example NIA File" from the NIE specification.
+  const uint8_t want_array[] = {
+      0x6E, 0xC3, 0xAF, 0x41, 0xFF, 0x62, 0x6E, 0x34, 0x03, 0x00, 0x00, 0x00,
+      0x02, 0x00, 0x00, 0x00, 0x00, 0x9A, 0x0E, 0x2A, 0x00, 0x00, 0x00, 0x00,
+      0x6E, 0xC3, 0xAF, 0x45, 0xFF, 0x62, 0x6E, 0x34, 0x03, 0x00, 0x00, 0x00,
+      0x02, 0x00, 0x00, 0x00, 0xFF, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+      0x00, 0x00, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+      0x00, 0x00, 0xFF, 0xFF, 0x00, 0xCE, 0x2B, 0x7E, 0x00, 0x00, 0x00, 0x00,
+      0x6E, 0xC3, 0xAF, 0x45, 0xFF, 0x62, 0x6E, 0x34, 0x03, 0x00, 0x00, 0x00,
+      0x02, 0x00, 0x00, 0x00, 0x00, 0xFF, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+      0x00, 0x00, 0xFF, 0xFF, 0x00, 0xFF, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+      0x00, 0x00, 0xFF, 0xFF, 0x0A, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80,
+  };
+  wuffs_base__io_buffer want = wuffs_base__ptr_u8__reader(
+      (uint8_t*)(want_array), WUFFS_TESTLIB_ARRAY_SIZE(want_array), true);
+
+  // The first frame is the French flag (blue, white and red).
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STRING(decode_nie_file(&pb, "test/data/crude-flag.nie"));
+
+  wuffs_nie__nia_encoder enc;
+  CHECK_STATUS("initialize",
+               wuffs_nie__nia_encoder__initialize(
+                   &enc, sizeof enc, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+
+  CHECK_STATUS("encode_frame #0",
+               wuffs_nie__nia_encoder__encode_frame(
+                   &enc, &have, &pb, 1 * WUFFS_BASE__FLICKS_PER_SECOND));
+
+  // The second frame is the Italian flag (green, white and red). The frame
+  // durations are relative: 1 second and then 2 seconds.
+  wuffs_base__table_u8 tab = wuffs_base__pixel_buffer__plane(&pb, 0);
+  uint32_t y;
+  for (y = 0; y < 2; y++) {
+    wuffs_base__poke_u32le__no_bounds_check(tab.ptr + (y * tab.stride),
+                                            0xFF00FF00);
+  }
+  CHECK_STATUS("encode_frame #1",
+               wuffs_nie__nia_encoder__encode_frame(
+                   &enc, &have, &pb, 2 * WUFFS_BASE__FLICKS_PER_SECOND));
+
+  CHECK_STATUS("encode_footer",
+               wuffs_nie__nia_encoder__encode_footer(&enc, &have, 10));
+  CHECK_STRING(check_io_buffers_equal("", &have, &want));
+
+  wuffs_base__status status =
+      wuffs_nie__nia_encoder__encode_frame(&enc, &have, &pb, 0);
+  if (status.repr != wuffs_base__error__bad_call_sequence) {
+    RETURN_FAIL("encode_frame #2: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__error__bad_call_sequence);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_nie_encode_nia_zero_frames() {
+  CHECK_FOCUS(__func__);
+
+  // A zero frame NIA file has a header (with zero width and height) and a
+  // footer but no payload.
+  const uint8_t want_array[] = {
+      0x6E, 0xC3, 0xAF, 0x41, 0xFF, 0x62, 0x6E, 0x34, 0x00, 0x00, 0x00, 0x00,
+      0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80,
+  };
+  wuffs_base__io_buffer want = wuffs_base__ptr_u8__reader(
+      (uint8_t*)(want_array), WUFFS_TESTLIB_ARRAY_SIZE(want_array), true);
+
+  // Encode with an output buffer that is initially empty and then grows by a
+  // little each time, so that the encoder has to suspend (and resume).
+  wuffs_nie__nia_encoder enc;
+  CHECK_STATUS("initialize",
+               wuffs_nie__nia_encoder__initialize(
+                   &enc, sizeof enc, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  have.data.len = 0;
+  while (true) {
+    wuffs_base__status status =
+        wuffs_nie__nia_encoder__encode_footer(&enc, &have, 0);
+    if ((status.repr == wuffs_base__suspension__short_write) &&
+        (have.data.len < g_have_slice_u8.len)) {
+      have.data.len += 5;
+      continue;
+    }
+    CHECK_STATUS("encode_footer #0", status);
+    break;
+  }
+  CHECK_STRING(check_io_buffers_equal("", &have, &want));
+
+  wuffs_base__status status =
+      wuffs_nie__nia_encoder__encode_footer(&enc, &have, 0);
+  if (status.repr != wuffs_base__error__bad_call_sequence) {
+    RETURN_FAIL("encode_footer #1: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__error__bad_call_sequence);
+  }
+  return NULL;
+}
+
 // ---------------- Mimic Tests
 
 #ifdef WUFFS_MIMIC
@@ -134,6 +339,10 @@ proc g_tests[] = {
 
     test_wuffs_nie_decode_frame_config,
     test_wuffs_nie_decode_interface,
+    test_wuffs_nie_decode_row_groups,
+    test_wuffs_nie_encode_frame,
+    test_wuffs_nie_encode_nia,
+    test_wuffs_nie_encode_nia_zero_frames,
 
 #ifdef WUFFS_MIMIC
 
@@ -63,6 +63,9 @@ the first "./a.out" with "./a.out -bench". Combine these changes with the
 #define WUFFS_CONFIG__MODULE__BASE
 #define WUFFS_CONFIG__MODULE__CRC32
 #define WUFFS_CONFIG__MODULE__DEFLATE
+#define WUFFS_CONFIG__MODULE__EXIF
+#define WUFFS_CONFIG__MODULE__GIF
+#define WUFFS_CONFIG__MODULE__LZW
 #define WUFFS_CONFIG__MODULE__PNG
 #define WUFFS_CONFIG__MODULE__ZLIB
 
@@ -151,6 +154,8 @@ do_wuffs_png_swizzle(uint32_t width,
                                  WUFFS_INITIALIZE__DEFAULT_OPTIONS));
   dec.private_impl.f_width = width;
   dec.private_impl.f_height = height;
+  dec.private_impl.f_frame_rect_x1 = width;
+  dec.private_impl.f_frame_rect_y1 = height;
   dec.private_impl.f_pass_bytes_per_row = width;
   dec.private_impl.f_filter_distance = filter_distance;
   wuffs_png__decoder__choose_filter_implementations(&dec);
@@ -191,6 +196,431 @@ test_wuffs_png_decode_interface() {
       "test/data/bricks-gray.png", 0, SIZE_MAX, 160, 120, 0xFF060606);
 }
 
+const char*  //
+test_wuffs_png_decode_animated() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/animated-red-blue.apng"));
+
+  wuffs_png__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_png__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config",
+               wuffs_png__decoder__decode_image_config(&dec, &ic, &src));
+
+  // animated-red-blue.apng was converted from animated-red-blue.gif, whose
+  // num_loops is 3.
+  uint32_t have_num_loops = wuffs_png__decoder__num_animation_loops(&dec);
+  if (have_num_loops != 3) {
+    RETURN_FAIL("num_loops: have %" PRIu32 ", want 3", have_num_loops);
+  }
+
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     &pb, &ic.pixcfg, g_pixel_slice_u8));
+
+  wuffs_base__rect_ie_u32 want_bounds[4] = {
+      make_rect_ie_u32(0, 0, 64, 48),
+      make_rect_ie_u32(15, 31, 52, 40),
+      make_rect_ie_u32(15, 0, 64, 40),
+      make_rect_ie_u32(15, 0, 64, 40),
+  };
+  // There are 7056000 flicks per centisecond.
+  wuffs_base__flicks want_durations[4] = {
+      10 * 7056000,
+      20 * 7056000,
+      30 * 7056000,
+      40 * 7056000,
+  };
+  uint64_t io_positions[4] = {0};
+
+  uint32_t i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(want_bounds); i++) {
+    wuffs_base__frame_config fc = ((wuffs_base__frame_config){});
+    wuffs_base__status status =
+        wuffs_png__decoder__decode_frame_config(&dec, &fc, &src);
+    if (!wuffs_base__status__is_ok(&status)) {
+      RETURN_FAIL("decode_frame_config #%" PRIu32 ": \"%s\"", i, status.repr);
+    }
+
+    wuffs_base__rect_ie_u32 have = wuffs_base__frame_config__bounds(&fc);
+    wuffs_base__rect_ie_u32 want = want_bounds[i];
+    if (!wuffs_base__rect_ie_u32__equals(&have, want)) {
+      RETURN_FAIL("decode_frame_config #%" PRIu32 ": bounds: have (%" PRIu32
+                  ", %" PRIu32 ")-(%" PRIu32 ", %" PRIu32 "), want (%" PRIu32
+                  ", %" PRIu32 ")-(%" PRIu32 ", %" PRIu32 ")",
+                  i, have.min_incl_x, have.min_incl_y, have.max_excl_x,
+                  have.max_excl_y, want.min_incl_x, want.min_incl_y,
+                  want.max_excl_x, want.max_excl_y);
+    }
+
+    wuffs_base__flicks have_duration = wuffs_base__frame_config__duration(&fc);
+    if (have_duration != want_durations[i]) {
+      RETURN_FAIL("decode_frame_config #%" PRIu32 ": duration: have %" PRId64
+                  ", want %" PRId64,
+                  i, have_duration, want_durations[i]);
+    }
+
+    bool have_overwrite =
+        wuffs_base__frame_config__overwrite_instead_of_blend(&fc);
+    if (have_overwrite != (i == 0)) {
+      RETURN_FAIL("decode_frame_config #%" PRIu32
+                  ": overwrite_instead_of_blend: have %d, want %d",
+                  i, have_overwrite, (i == 0));
+    }
+
+    io_positions[i] = wuffs_base__frame_config__io_position(&fc);
+
+    status = wuffs_png__decoder__decode_frame(
+        &dec, &pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC, g_work_slice_u8, NULL);
+    if (!wuffs_base__status__is_ok(&status)) {
+      RETURN_FAIL("decode_frame #%" PRIu32 ": \"%s\"", i, status.repr);
+    }
+
+    wuffs_base__rect_ie_u32 dirty_rect =
+        wuffs_png__decoder__frame_dirty_rect(&dec);
+    if (!wuffs_base__rect_ie_u32__equals(&dirty_rect, want)) {
+      RETURN_FAIL("decode_frame #%" PRIu32 ": frame_dirty_rect mismatch", i);
+    }
+  }
+
+  // There should be no more frames, no matter how many times we call
+  // decode_frame.
+  for (i = 0; i < 3; i++) {
+    wuffs_base__status status = wuffs_png__decoder__decode_frame(
+        &dec, &pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC, g_work_slice_u8, NULL);
+    if (status.repr != wuffs_base__note__end_of_data) {
+      RETURN_FAIL("decode_frame: have \"%s\", want \"%s\"", status.repr,
+                  wuffs_base__note__end_of_data);
+    }
+  }
+
+  uint64_t have_num_frames = wuffs_png__decoder__num_decoded_frames(&dec);
+  if (have_num_frames != WUFFS_TESTLIB_ARRAY_SIZE(want_bounds)) {
+    RETURN_FAIL("num_decoded_frames: have %" PRIu64 ", want %d",
+                have_num_frames, (int)(WUFFS_TESTLIB_ARRAY_SIZE(want_bounds)));
+  }
+
+  // Restart at each frame, in reverse order.
+  for (i = WUFFS_TESTLIB_ARRAY_SIZE(want_bounds); i > 0;) {
+    i--;
+    CHECK_STATUS("restart_frame", wuffs_png__decoder__restart_frame(
+                                      &dec, i, io_positions[i]));
+    src.meta.ri = io_positions[i];
+
+    wuffs_base__frame_config fc = ((wuffs_base__frame_config){});
+    CHECK_STATUS("decode_frame_config (restarted)",
+                 wuffs_png__decoder__decode_frame_config(&dec, &fc, &src));
+    wuffs_base__rect_ie_u32 have = wuffs_base__frame_config__bounds(&fc);
+    if (!wuffs_base__rect_ie_u32__equals(&have, want_bounds[i])) {
+      RETURN_FAIL("decode_frame_config #%" PRIu32
+                  " (restarted): bounds mismatch",
+                  i);
+    }
+    CHECK_STATUS("decode_frame (restarted)",
+                 wuffs_png__decoder__decode_frame(
+                     &dec, &pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC,
+                     g_work_slice_u8, NULL));
+  }
+
+  return NULL;
+}
+
+// composite_animation decodes every frame of an animated image and appends
+// each frame's composited BGRA_NONPREMUL pixels (the whole canvas, not just
+// the frame's bounds) to dst. Like example/convert-to-nia, it blends each
+// frame onto the previous frame's disposed pixels.
+const char*  //
+composite_animation(wuffs_base__image_decoder* dec,
+                    wuffs_base__io_buffer* src,
+                    wuffs_base__io_buffer* dst,
+                    uint32_t* num_frames) {
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config",
+               wuffs_base__image_decoder__decode_image_config(dec, &ic, src));
+  uint32_t width = wuffs_base__pixel_config__width(&ic.pixcfg);
+  uint32_t height = wuffs_base__pixel_config__height(&ic.pixcfg);
+  wuffs_base__pixel_config__set(&ic.pixcfg,
+                                WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+                                WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, width,
+                                height);
+
+  // The first half of g_pixel_slice_u8 holds the pixel buffer. The second
+  // half holds the RESTORE_PREVIOUS disposal's backup copy.
+  size_t half = g_pixel_slice_u8.len / 2;
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice",
+               wuffs_base__pixel_buffer__set_from_slice(
+                   &pb, &ic.pixcfg,
+                   wuffs_base__make_slice_u8(g_pixel_slice_u8.ptr, half)));
+  wuffs_base__table_u8 tab = wuffs_base__pixel_buffer__plane(&pb, 0);
+  size_t frame_len = tab.width * tab.height;
+  if (tab.width != tab.stride) {
+    RETURN_FAIL("unexpected pixel buffer stride");
+  }
+  uint8_t* backup = g_pixel_slice_u8.ptr + half;
+
+  uint32_t i;
+  for (i = 0; true; i++) {
+    wuffs_base__frame_config fc = ((wuffs_base__frame_config){});
+    wuffs_base__status status =
+        wuffs_base__image_decoder__decode_frame_config(dec, &fc, src);
+    if (status.repr == wuffs_base__note__end_of_data) {
+      break;
+    } else if (!wuffs_base__status__is_ok(&status)) {
+      RETURN_FAIL("decode_frame_config #%" PRIu32 ": \"%s\"", i, status.repr);
+    }
+
+    if (i == 0) {
+      CHECK_STATUS("fill_rect",
+                   wuffs_base__pixel_buffer__set_color_u32_fill_rect(
+                       &pb, wuffs_base__pixel_config__bounds(&ic.pixcfg),
+                       wuffs_base__frame_config__background_color(&fc)));
+    }
+    uint8_t disposal = wuffs_base__frame_config__disposal(&fc);
+    if (disposal == WUFFS_BASE__ANIMATION_DISPOSAL__RESTORE_PREVIOUS) {
+      memcpy(backup, tab.ptr, frame_len);
+    }
+
+    status = wuffs_base__image_decoder__decode_frame(
+        dec, &pb, src,
+        wuffs_base__frame_config__overwrite_instead_of_blend(&fc)
+            ? WUFFS_BASE__PIXEL_BLEND__SRC
+            : WUFFS_BASE__PIXEL_BLEND__SRC_OVER,
+        g_work_slice_u8, NULL);
+    if (!wuffs_base__status__is_ok(&status)) {
+      RETURN_FAIL("decode_frame #%" PRIu32 ": \"%s\"", i, status.repr);
+    }
+
+    if (frame_len > (dst->data.len - dst->meta.wi)) {
+      RETURN_FAIL("decode_frame #%" PRIu32 ": dst is too short", i);
+    }
+    memcpy(dst->data.ptr + dst->meta.wi, tab.ptr, frame_len);
+    dst->meta.wi += frame_len;
+
+    if (disposal == WUFFS_BASE__ANIMATION_DISPOSAL__RESTORE_BACKGROUND) {
+      CHECK_STATUS("fill_rect",
+                   wuffs_base__pixel_buffer__set_color_u32_fill_rect(
+                       &pb, wuffs_base__frame_config__bounds(&fc),
+                       wuffs_base__frame_config__background_color(&fc)));
+    } else if (disposal == WUFFS_BASE__ANIMATION_DISPOSAL__RESTORE_PREVIOUS) {
+      memcpy(tab.ptr, backup, frame_len);
+    }
+  }
+
+  *num_frames = i;
+  return NULL;
+}
+
+const char*  //
+test_wuffs_png_decode_animated_pixels() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/animated-red-blue.apng"));
+
+  wuffs_png__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_png__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  uint32_t num_frames = 0;
+  CHECK_STRING(composite_animation(
+      wuffs_png__decoder__upcast_as__wuffs_base__image_decoder(&dec), &src,
+      &have, &num_frames));
+  if (num_frames != 4) {
+    RETURN_FAIL("num_frames: have %" PRIu32 ", want 4", num_frames);
+  }
+
+  // animated-red-blue.nia, generated by example/convert-to-nia, holds the
+  // composited pixels of animated-red-blue.gif, which animated-red-blue.apng
+  // was converted from. Each NIA frame is an 8 byte duration, a 16 byte NIE
+  // header and then 64 * 48 * 4 bytes of BGRA_NONPREMUL pixels. Frames #1, #2
+  // and #3 have non-zero offsets and SRC_OVER blending.
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  CHECK_STRING(read_file(&want, "test/data/animated-red-blue.nia"));
+  const size_t frame_len = 64 * 48 * 4;
+  if (have.meta.wi != (num_frames * frame_len)) {
+    RETURN_FAIL("unexpected composited length");
+  }
+
+  uint32_t i;
+  for (i = 0; i < num_frames; i++) {
+    size_t offset = 16 + (i * (8 + 16 + frame_len)) + 8 + 16;
+    if ((offset > want.meta.wi) || (frame_len > (want.meta.wi - offset))) {
+      RETURN_FAIL("frame #%" PRIu32 ": NIA file is too short", i);
+    }
+    wuffs_base__io_buffer have_frame = wuffs_base__ptr_u8__reader(
+        have.data.ptr + (i * frame_len), frame_len, true);
+    wuffs_base__io_buffer want_frame =
+        wuffs_base__ptr_u8__reader(want.data.ptr + offset, frame_len, true);
+    char prefix[32];
+    snprintf(prefix, sizeof prefix, "frame #%" PRIu32 ": ", i);
+    CHECK_STRING(check_io_buffers_equal(prefix, &have_frame, &want_frame));
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_png_decode_animated_dispose() {
+  CHECK_FOCUS(__func__);
+
+  // Patch frame #1's disposal, in both animated-red-blue.apng and the
+  // animated-red-blue.gif that it was converted from, and check that Wuffs'
+  // PNG and GIF decoders agree on the composited pixels. Frame #1 is
+  // 37 x 9 pixels at (15, 31) and the (blended) frame #2 overlaps it.
+  //
+  // Frame #1's fcTL chunk starts at 0x0519. Its dispose_op is at 0x0539 and
+  // its CRC-32 checksum is at 0x053B. Its GIF Graphic Control Extension
+  // starts at 0x084E, and its Packed Fields byte is at 0x0851.
+  const size_t apng_fctl = 0x0519;
+  const size_t gif_packed = 0x0851;
+
+  const size_t frame_len = 64 * 48 * 4;
+  wuffs_base__io_buffer nia = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  CHECK_STRING(read_file(&nia, "test/data/animated-red-blue.nia"));
+  const size_t nia_frame_2 = 16 + (2 * (8 + 16 + frame_len)) + 8 + 16;
+  if ((nia_frame_2 > nia.meta.wi) ||
+      (frame_len > (nia.meta.wi - nia_frame_2))) {
+    RETURN_FAIL("NIA file is too short");
+  }
+
+  // The APNG dispose_op values are 1 (BACKGROUND) and 2 (PREVIOUS). The GIF
+  // Disposal Method values are 2 and 3.
+  int tc;
+  for (tc = 1; tc <= 2; tc++) {
+    // Split g_src_slice_u8 into two halves for the PNG and GIF sources. Split
+    // g_have_slice_u8 likewise for their composited pixels.
+    size_t src_half = g_src_slice_u8.len / 2;
+    wuffs_base__io_buffer png_src = ((wuffs_base__io_buffer){
+        .data = wuffs_base__make_slice_u8(g_src_slice_u8.ptr, src_half),
+    });
+    wuffs_base__io_buffer gif_src = ((wuffs_base__io_buffer){
+        .data = wuffs_base__make_slice_u8(g_src_slice_u8.ptr + src_half,
+                                          src_half),
+    });
+    CHECK_STRING(read_file(&png_src, "test/data/animated-red-blue.apng"));
+    CHECK_STRING(read_file(&gif_src, "test/data/animated-red-blue.gif"));
+    if ((png_src.meta.wi < (apng_fctl + 8 + 26 + 4)) ||
+        memcmp(png_src.data.ptr + apng_fctl + 4, "fcTL", 4) ||
+        (png_src.data.ptr[apng_fctl + 8 + 24] != 0) ||
+        (gif_src.meta.wi <= gif_packed) ||
+        memcmp(gif_src.data.ptr + gif_packed - 3, "\x21\xF9\x04", 3) ||
+        (((gif_src.data.ptr[gif_packed] >> 2) & 7) != 1)) {
+      RETURN_FAIL("unexpected test file contents");
+    }
+
+    png_src.data.ptr[apng_fctl + 8 + 24] = (uint8_t)tc;
+    wuffs_crc32__ieee_hasher crc32;
+    CHECK_STATUS("initialize", wuffs_crc32__ieee_hasher__initialize(
+                                   &crc32, sizeof crc32, WUFFS_VERSION,
+                                   WUFFS_INITIALIZE__DEFAULT_OPTIONS));
+    wuffs_base__poke_u32be__no_bounds_check(
+        png_src.data.ptr + apng_fctl + 8 + 26,
+        wuffs_crc32__ieee_hasher__update_u32(
+            &crc32, wuffs_base__make_slice_u8(png_src.data.ptr + apng_fctl + 4,
+                                              4 + 26)));
+    gif_src.data.ptr[gif_packed] =
+        (uint8_t)((gif_src.data.ptr[gif_packed] & ~0x1C) | ((tc + 1) << 2));
+
+    size_t have_half = g_have_slice_u8.len / 2;
+    wuffs_base__io_buffer png_have = ((wuffs_base__io_buffer){
+        .data = wuffs_base__make_slice_u8(g_have_slice_u8.ptr, have_half),
+    });
+    wuffs_base__io_buffer gif_have = ((wuffs_base__io_buffer){
+        .data = wuffs_base__make_slice_u8(g_have_slice_u8.ptr + have_half,
+                                          have_half),
+    });
+    uint32_t png_num_frames = 0;
+    uint32_t gif_num_frames = 0;
+
+    wuffs_png__decoder png_dec;
+    CHECK_STATUS("initialize",
+                 wuffs_png__decoder__initialize(
+                     &png_dec, sizeof png_dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    CHECK_STRING(composite_animation(
+        wuffs_png__decoder__upcast_as__wuffs_base__image_decoder(&png_dec),
+        &png_src, &png_have, &png_num_frames));
+
+    wuffs_gif__decoder gif_dec;
+    CHECK_STATUS("initialize",
+                 wuffs_gif__decoder__initialize(
+                     &gif_dec, sizeof gif_dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    CHECK_STRING(composite_animation(
+        wuffs_gif__decoder__upcast_as__wuffs_base__image_decoder(&gif_dec),
+        &gif_src, &gif_have, &gif_num_frames));
+
+    if ((png_num_frames != 4) || (gif_num_frames != 4)) {
+      RETURN_FAIL("tc=%d: num_frames: have %" PRIu32 " and %" PRIu32
+                  ", want 4",
+                  tc, png_num_frames, gif_num_frames);
+    }
+    char prefix[32];
+    snprintf(prefix, sizeof prefix, "tc=%d: ", tc);
+    CHECK_STRING(check_io_buffers_equal(prefix, &png_have, &gif_have));
+
+    // Check that the disposal made a difference: frame #2 should not match
+    // the unpatched animation's frame #2.
+    if (!memcmp(png_have.data.ptr + (2 * frame_len),
+                nia.data.ptr + nia_frame_2, frame_len)) {
+      RETURN_FAIL("tc=%d: frame #2 was unaffected by frame #1's disposal", tc);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_png_decode_bad_chunk_payload() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hippopotamus.regular.png"));
+
+  // The IDAT chunk type starts at byte offset 37, after the 8 byte magic and
+  // the 25 byte IHDR chunk and the IDAT chunk length. Renaming it to "fdAT",
+  // outside of an animation, is a "#bad chunk" error.
+  if ((src.meta.wi < 41) || memcmp(src.data.ptr + 37, "IDAT", 4)) {
+    RETURN_FAIL("unexpected test file contents");
+  }
+  memcpy(src.data.ptr + 37, "fdAT", 4);
+
+  wuffs_png__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_png__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  wuffs_base__status status =
+      wuffs_png__decoder__decode_image_config(&dec, &ic, &src);
+  if (status.repr != wuffs_png__error__bad_chunk) {
+    RETURN_FAIL("status: have \"%s\", want \"%s\"", status.repr,
+                wuffs_png__error__bad_chunk);
+  }
+  uint64_t have = wuffs_base__status__payload(&status);
+  uint64_t want = 0x54416466;  // 'fdAT'le.
+  if (have != want) {
+    RETURN_FAIL("payload: have 0x%" PRIX64 ", want 0x%" PRIX64, have, want);
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_png_decode_bad_crc32_checksum_critical() {
   CHECK_FOCUS(__func__);
@@ -198,6 +628,167 @@ test_wuffs_png_decode_bad_crc32_checksum_critical() {
       &wuffs_png_decode);
 }
 
+const char*  //
+do_test_wuffs_png_decode_color_transform(wuffs_base__slice_u8 dst,
+                                         wuffs_base__decode_frame_options* opts,
+                                         wuffs_base__pixel_blend blend,
+                                         const char* want_status) {
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hippopotamus.regular.png"));
+
+  wuffs_png__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_png__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config",
+               wuffs_png__decoder__decode_image_config(&dec, &ic, &src));
+  wuffs_base__pixel_config__set(
+      &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE,
+      wuffs_base__pixel_config__width(&ic.pixcfg),
+      wuffs_base__pixel_config__height(&ic.pixcfg));
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice",
+               wuffs_base__pixel_buffer__set_from_slice(&pb, &ic.pixcfg, dst));
+
+  wuffs_base__status status = wuffs_png__decoder__decode_frame(
+      &dec, &pb, &src, blend, g_work_slice_u8, opts);
+  if (status.repr != want_status) {
+    RETURN_FAIL("decode_frame: have \"%s\", want \"%s\"", status.repr,
+                want_status);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_png_decode_color_transform() {
+  CHECK_FOCUS(__func__);
+
+  // The hippopotamus image is 36 x 28 pixels.
+  const size_t n = 36 * 28 * 4;
+  wuffs_base__pixel_format pixfmt =
+      wuffs_base__make_pixel_format(WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL);
+
+  wuffs_base__color_icc display_p3;
+  wuffs_base__color_icc__set_display_p3(&display_p3);
+  wuffs_base__color_transform t;
+  CHECK_STATUS("prepare",
+               wuffs_base__color_transform__prepare(&t, &display_p3, NULL));
+  wuffs_base__decode_frame_options opts =
+      wuffs_base__null_decode_frame_options();
+  wuffs_base__decode_frame_options__set_color_transform(&opts, &t);
+
+  // Decoding with the color transform should be equivalent to decoding
+  // without it and then applying it afterwards.
+  CHECK_STRING(do_test_wuffs_png_decode_color_transform(
+      wuffs_base__make_slice_u8(g_want_array_u8, n), NULL,
+      WUFFS_BASE__PIXEL_BLEND__SRC, NULL));
+  wuffs_base__color_transform__apply(
+      &t, wuffs_base__make_slice_u8(g_want_array_u8, n), pixfmt);
+  CHECK_STRING(do_test_wuffs_png_decode_color_transform(
+      wuffs_base__make_slice_u8(g_have_array_u8, n), &opts,
+      WUFFS_BASE__PIXEL_BLEND__SRC, NULL));
+  if (memcmp(g_have_array_u8, g_want_array_u8, n)) {
+    RETURN_FAIL("pixels differ");
+  }
+
+  return do_test_wuffs_png_decode_color_transform(
+      wuffs_base__make_slice_u8(g_have_array_u8, n), &opts,
+      WUFFS_BASE__PIXEL_BLEND__SRC_OVER, wuffs_base__error__unsupported_option);
+}
+
+const char*  //
+do_test_wuffs_png_decode_dither(wuffs_base__slice_u8 dst,
+                                wuffs_base__decode_frame_options* opts,
+                                wuffs_base__pixel_blend blend,
+                                const char* want_status) {
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hippopotamus.regular.png"));
+
+  wuffs_png__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_png__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config",
+               wuffs_png__decoder__decode_image_config(&dec, &ic, &src));
+  wuffs_base__pixel_config__set(
+      &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGR_565,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE,
+      wuffs_base__pixel_config__width(&ic.pixcfg),
+      wuffs_base__pixel_config__height(&ic.pixcfg));
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice",
+               wuffs_base__pixel_buffer__set_from_slice(&pb, &ic.pixcfg, dst));
+
+  wuffs_base__status status = wuffs_png__decoder__decode_frame(
+      &dec, &pb, &src, blend, g_work_slice_u8, opts);
+  if (status.repr != want_status) {
+    RETURN_FAIL("decode_frame: have \"%s\", want \"%s\"", status.repr,
+                want_status);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_png_decode_dither() {
+  CHECK_FOCUS(__func__);
+
+  // The hippopotamus image is 36 x 28 pixels.
+  const uint32_t width = 36;
+  const uint32_t height = 28;
+  const size_t n = width * height * 2;
+
+  uint8_t workbuf_array[6 * 36];
+  wuffs_base__pixel_ditherer ditherer;
+  CHECK_STATUS("initialize", wuffs_base__pixel_ditherer__initialize(
+                                 &ditherer,
+                                 WUFFS_BASE__PIXEL_DITHER__FLOYD_STEINBERG,
+                                 width,
+                                 wuffs_base__make_slice_u8(
+                                     &workbuf_array[0], sizeof workbuf_array)));
+  wuffs_base__decode_frame_options opts =
+      wuffs_base__null_decode_frame_options();
+  wuffs_base__decode_frame_options__set_ditherer(&opts, &ditherer);
+
+  // Dithering should change some, but not most, of the BGR_565 pixels.
+  CHECK_STRING(do_test_wuffs_png_decode_dither(
+      wuffs_base__make_slice_u8(g_want_array_u8, n), NULL,
+      WUFFS_BASE__PIXEL_BLEND__SRC, NULL));
+  CHECK_STRING(do_test_wuffs_png_decode_dither(
+      wuffs_base__make_slice_u8(g_have_array_u8, n), &opts,
+      WUFFS_BASE__PIXEL_BLEND__SRC, NULL));
+  size_t num_differ = 0;
+  size_t i;
+  for (i = 0; i < n; i += 2) {
+    if (wuffs_base__peek_u16le__no_bounds_check(g_have_array_u8 + i) !=
+        wuffs_base__peek_u16le__no_bounds_check(g_want_array_u8 + i)) {
+      num_differ++;
+    }
+  }
+  if ((num_differ == 0) || (num_differ >= (n / 4))) {
+    RETURN_FAIL("num_differ: have %zu, want in (0, %zu)", num_differ, n / 4);
+  }
+
+  // The decoder should have visited every row.
+  if ((ditherer.private_impl.x != 0) || (ditherer.private_impl.y != height)) {
+    RETURN_FAIL("ditherer position: have (%" PRIu32 ", %" PRIu32
+                "), want (0, %" PRIu32 ")",
+                ditherer.private_impl.x, ditherer.private_impl.y, height);
+  }
+
+  return do_test_wuffs_png_decode_dither(
+      wuffs_base__make_slice_u8(g_have_array_u8, n), &opts,
+      WUFFS_BASE__PIXEL_BLEND__SRC_OVER, wuffs_base__error__unsupported_option);
+}
+
 const char*  //
 test_wuffs_png_decode_filters_golden() {
   CHECK_FOCUS(__func__);
@@ -472,6 +1063,331 @@ test_wuffs_png_decode_frame_config() {
   return NULL;
 }
 
+const char*  //
+test_wuffs_png_decode_limits_workbuf() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hippopotamus.regular.png"));
+
+  uint64_t workbuf_len = 0;
+  int i;
+  for (i = 0; i < 3; i++) {
+    src.meta.ri = 0;
+    wuffs_png__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_png__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_base__image_decoder_limits limits =
+        ((wuffs_base__image_decoder_limits){
+            .max_workbuf_length = (i == 0) ? 0 : (workbuf_len + 1 - i),
+        });
+    wuffs_png__decoder__set_limits(&dec, &limits);
+
+    wuffs_base__image_config ic = ((wuffs_base__image_config){});
+    wuffs_base__status status =
+        wuffs_png__decoder__decode_image_config(&dec, &ic, &src);
+    const char* want =
+        (i < 2) ? NULL : wuffs_base__error__resource_limit_exceeded;
+    if (status.repr != want) {
+      RETURN_FAIL("i=%d: have \"%s\", want \"%s\"", i, status.repr, want);
+    } else if (i == 0) {
+      workbuf_len = wuffs_png__decoder__workbuf_len(&dec).min_incl;
+      if (workbuf_len == 0) {
+        RETURN_FAIL("workbuf_len: have 0, want non-zero");
+      }
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_png_decode_metadata_exif() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/artificial/png-exif.png"));
+
+  int report;
+  for (report = 0; report < 2; report++) {
+    wuffs_png__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_png__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_png__decoder__set_report_metadata(&dec, WUFFS_BASE__FOURCC__EXIF,
+                                            report);
+
+    int num_reported = 0;
+    uint32_t orientation = 0;
+    wuffs_base__image_config ic = ((wuffs_base__image_config){});
+    src.meta.ri = 0;
+
+    while (true) {
+      wuffs_base__status status =
+          wuffs_png__decoder__decode_image_config(&dec, &ic, &src);
+      if (wuffs_base__status__is_ok(&status)) {
+        break;
+      } else if (status.repr != wuffs_base__note__metadata_reported) {
+        RETURN_FAIL("decode_image_config (report=%d): have \"%s\", want \"%s\"",
+                    report, status.repr, wuffs_base__note__metadata_reported);
+      }
+      num_reported++;
+
+      wuffs_base__io_buffer empty = wuffs_base__empty_io_buffer();
+      wuffs_base__more_information minfo = wuffs_base__empty_more_information();
+      CHECK_STATUS("tell_me_more", wuffs_png__decoder__tell_me_more(
+                                       &dec, &empty, &minfo, &src));
+      if (minfo.flavor != WUFFS_BASE__MORE_INFORMATION__FLAVOR__METADATA) {
+        RETURN_FAIL("flavor: have %" PRIu32 ", want %" PRIu32, minfo.flavor,
+                    WUFFS_BASE__MORE_INFORMATION__FLAVOR__METADATA);
+      } else if (wuffs_base__more_information__metadata__fourcc(&minfo) !=
+                 WUFFS_BASE__FOURCC__EXIF) {
+        RETURN_FAIL("fourcc: have 0x%08" PRIX32 ", want 0x%08" PRIX32,
+                    wuffs_base__more_information__metadata__fourcc(&minfo),
+                    WUFFS_BASE__FOURCC__EXIF);
+      }
+      wuffs_base__range_ie_u64 r =
+          wuffs_base__more_information__metadata__range(&minfo);
+      if ((r.min_incl != 0x29) || (r.max_excl != 0xB7)) {
+        RETURN_FAIL("range: have 0x%" PRIX64 "..0x%" PRIX64
+                    ", want 0x29..0xB7",
+                    r.min_incl, r.max_excl);
+      } else if (r.max_excl > src.meta.wi) {
+        RETURN_FAIL("range: out of bounds");
+      }
+
+      // Chain into std/exif to find the Orientation.
+      wuffs_exif__decoder exif_dec;
+      CHECK_STATUS("initialize",
+                   wuffs_exif__decoder__initialize(
+                       &exif_dec, sizeof exif_dec, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+      wuffs_base__token tok_array[256];
+      wuffs_base__token_buffer tok_buf =
+          wuffs_base__slice_token__writer(wuffs_base__make_slice_token(
+              &tok_array[0], WUFFS_TESTLIB_ARRAY_SIZE(tok_array)));
+      wuffs_base__io_buffer exif_src = wuffs_base__slice_u8__reader(
+          wuffs_base__make_slice_u8(src.data.ptr + r.min_incl,
+                                    r.max_excl - r.min_incl),
+          true);
+      CHECK_STATUS("decode_tokens",
+                   wuffs_exif__decoder__decode_tokens(&exif_dec, &tok_buf,
+                                                      &exif_src,
+                                                      g_work_slice_u8));
+      size_t i;
+      for (i = 0; i + 2 < tok_buf.meta.wi; i++) {
+        wuffs_base__token* t = &tok_buf.data.ptr[i];
+        if ((wuffs_base__token__value_major(t) ==
+             WUFFS_EXIF__TOKEN_VALUE_MAJOR) &&
+            (wuffs_base__token__value_minor(t) ==
+             ((WUFFS_EXIF__IFD__PRIMARY << 20) |
+              (WUFFS_EXIF__TYPE__SHORT << 16) |
+              WUFFS_EXIF__TAG__ORIENTATION))) {
+          orientation =
+              (uint32_t)(wuffs_base__token__value_extension(&t[2]));
+        }
+      }
+
+      src.meta.ri = r.max_excl;
+    }
+
+    if (num_reported != report) {
+      RETURN_FAIL("num_reported: have %d, want %d", num_reported, report);
+    } else if (orientation != (report ? 6u : 0u)) {
+      RETURN_FAIL("orientation (report=%d): have %" PRIu32, report,
+                  orientation);
+    } else if (wuffs_base__pixel_config__width(&ic.pixcfg) != 1) {
+      RETURN_FAIL("width (report=%d): have %" PRIu32 ", want 1", report,
+                  wuffs_base__pixel_config__width(&ic.pixcfg));
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_png_decode_metadata_ornt() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/artificial/png-exif.png"));
+
+  // Reporting "EXIF" takes priority over reporting "ORNT".
+  int report_exif;
+  for (report_exif = 0; report_exif < 2; report_exif++) {
+    wuffs_png__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_png__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_png__decoder__set_report_metadata(&dec, WUFFS_BASE__FOURCC__EXIF,
+                                            report_exif);
+    wuffs_png__decoder__set_report_metadata(&dec, WUFFS_BASE__FOURCC__ORNT,
+                                            true);
+
+    uint32_t want_flavor =
+        report_exif ? WUFFS_BASE__MORE_INFORMATION__FLAVOR__METADATA
+                    : WUFFS_BASE__MORE_INFORMATION__FLAVOR__METADATA_PARSED;
+    uint32_t want_fourcc =
+        report_exif ? WUFFS_BASE__FOURCC__EXIF : WUFFS_BASE__FOURCC__ORNT;
+    int num_reported = 0;
+    wuffs_base__image_config ic = ((wuffs_base__image_config){});
+    src.meta.ri = 0;
+
+    while (true) {
+      wuffs_base__status status =
+          wuffs_png__decoder__decode_image_config(&dec, &ic, &src);
+      if (wuffs_base__status__is_ok(&status)) {
+        break;
+      } else if (status.repr != wuffs_base__note__metadata_reported) {
+        RETURN_FAIL("decode_image_config: have \"%s\", want \"%s\"",
+                    status.repr, wuffs_base__note__metadata_reported);
+      }
+      num_reported++;
+
+      wuffs_base__io_buffer empty = wuffs_base__empty_io_buffer();
+      wuffs_base__more_information minfo = wuffs_base__empty_more_information();
+      CHECK_STATUS("tell_me_more", wuffs_png__decoder__tell_me_more(
+                                       &dec, &empty, &minfo, &src));
+      if (minfo.flavor != want_flavor) {
+        RETURN_FAIL("flavor: have %" PRIu32 ", want %" PRIu32, minfo.flavor,
+                    want_flavor);
+      } else if (wuffs_base__more_information__metadata__fourcc(&minfo) !=
+                 want_fourcc) {
+        RETURN_FAIL("fourcc: have 0x%08" PRIX32 ", want 0x%08" PRIX32,
+                    wuffs_base__more_information__metadata__fourcc(&minfo),
+                    want_fourcc);
+      }
+
+      if (report_exif) {
+        src.meta.ri =
+            wuffs_base__more_information__metadata__range(&minfo).max_excl;
+      } else if (wuffs_base__more_information__metadata_parsed__orientation(
+                     &minfo) != WUFFS_BASE__ORIENTATION__RIGHT_TOP) {
+        RETURN_FAIL(
+            "orientation: have %" PRIu32 ", want %" PRIu32,
+            wuffs_base__more_information__metadata_parsed__orientation(&minfo),
+            (uint32_t)(WUFFS_BASE__ORIENTATION__RIGHT_TOP));
+      }
+    }
+
+    if (num_reported != 1) {
+      RETURN_FAIL("num_reported: have %d, want 1", num_reported);
+    } else if (wuffs_base__pixel_config__width(&ic.pixcfg) != 1) {
+      RETURN_FAIL("width: have %" PRIu32 ", want 1",
+                  wuffs_base__pixel_config__width(&ic.pixcfg));
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_png_decode_passes() {
+  CHECK_FOCUS(__func__);
+
+  // The Adam7 interlacing has 7 passes and so 6 notes.
+  wuffs_png__decoder full;
+  CHECK_STATUS("initialize (full)",
+               wuffs_png__decoder__initialize(
+                   &full, sizeof full, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_png__decoder passes;
+  CHECK_STATUS("initialize (passes)",
+               wuffs_png__decoder__initialize(
+                   &passes, sizeof passes, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  return do_test__wuffs_base__image_decoder__passes(
+      wuffs_png__decoder__upcast_as__wuffs_base__image_decoder(&full),
+      wuffs_png__decoder__upcast_as__wuffs_base__image_decoder(&passes),
+      "test/data/hippopotamus.interlaced.png", 6);
+}
+
+const char*  //
+test_wuffs_png_decode_row_groups() {
+  CHECK_FOCUS(__func__);
+  const char* filenames[] = {
+      "test/data/bricks-dither.png",
+      "test/data/hippopotamus.regular.16bpc.png",
+      "test/data/hippopotamus.regular.png",
+      "test/data/pjw-thumbnail.png",
+  };
+  const uint32_t row_group_heights[] = {1, 5, 28, 100};
+  size_t i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(filenames); i++) {
+    size_t j;
+    for (j = 0; j < WUFFS_TESTLIB_ARRAY_SIZE(row_group_heights); j++) {
+      wuffs_png__decoder full;
+      CHECK_STATUS("initialize (full)",
+                   wuffs_png__decoder__initialize(
+                       &full, sizeof full, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+      wuffs_png__decoder grouped;
+      CHECK_STATUS("initialize (grouped)",
+                   wuffs_png__decoder__initialize(
+                       &grouped, sizeof grouped, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+      CHECK_STRING(do_test__wuffs_base__image_decoder__row_groups(
+          wuffs_png__decoder__upcast_as__wuffs_base__image_decoder(&full),
+          wuffs_png__decoder__upcast_as__wuffs_base__image_decoder(&grouped),
+          filenames[i], row_group_heights[j]));
+    }
+  }
+
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(row_group_heights); i++) {
+    wuffs_png__decoder full;
+    CHECK_STATUS("initialize (full)",
+                 wuffs_png__decoder__initialize(
+                     &full, sizeof full, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_png__decoder grouped;
+    CHECK_STATUS("initialize (grouped)",
+                 wuffs_png__decoder__initialize(
+                     &grouped, sizeof grouped, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    CHECK_STRING(do_test__wuffs_base__image_decoder__row_groups_animated(
+        wuffs_png__decoder__upcast_as__wuffs_base__image_decoder(&full),
+        wuffs_png__decoder__upcast_as__wuffs_base__image_decoder(&grouped),
+        "test/data/animated-red-blue.apng", row_group_heights[i]));
+  }
+
+  // Row groups are unsupported for Adam7 interlaced images.
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hippopotamus.interlaced.png"));
+  wuffs_png__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_png__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config",
+               wuffs_png__decoder__decode_image_config(&dec, &ic, &src));
+  wuffs_base__pixel_config__set(
+      &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE,
+      wuffs_base__pixel_config__width(&ic.pixcfg), 5);
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     &pb, &ic.pixcfg, g_pixel_slice_u8));
+  wuffs_base__decode_frame_options opts =
+      wuffs_base__null_decode_frame_options();
+  wuffs_base__decode_frame_options__set_row_group_height(&opts, 5);
+  wuffs_base__status status = wuffs_png__decoder__decode_frame(
+      &dec, &pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC, g_work_slice_u8, &opts);
+  if (status.repr != wuffs_base__error__unsupported_option) {
+    RETURN_FAIL("decode_frame (interlaced): have \"%s\", want \"%s\"",
+                status.repr, wuffs_base__error__unsupported_option);
+  }
+  return NULL;
+}
+
 // ---------------- Mimic Tests
 
 #ifdef WUFFS_MIMIC
@@ -653,6 +1569,8 @@ do_bench_wuffs_png_decode_filter(uint8_t filter,
                                  WUFFS_INITIALIZE__DEFAULT_OPTIONS));
   dec.private_impl.f_width = width;
   dec.private_impl.f_height = height;
+  dec.private_impl.f_frame_rect_x1 = width;
+  dec.private_impl.f_frame_rect_y1 = height;
   dec.private_impl.f_pass_bytes_per_row = bytes_per_row;
   dec.private_impl.f_filter_distance = filter_distance;
   wuffs_png__decoder__choose_filter_implementations(&dec);
@@ -806,11 +1724,22 @@ bench_mimic_png_decode_image_4002k_24bpp() {
 
 proc g_tests[] = {
 
+    test_wuffs_png_decode_animated,
+    test_wuffs_png_decode_animated_dispose,
+    test_wuffs_png_decode_animated_pixels,
+    test_wuffs_png_decode_bad_chunk_payload,
     test_wuffs_png_decode_bad_crc32_checksum_critical,
+    test_wuffs_png_decode_color_transform,
+    test_wuffs_png_decode_dither,
     test_wuffs_png_decode_filters_golden,
     test_wuffs_png_decode_filters_round_trip,
     test_wuffs_png_decode_frame_config,
+    test_wuffs_png_decode_limits_workbuf,
     test_wuffs_png_decode_interface,
+    test_wuffs_png_decode_metadata_exif,
+    test_wuffs_png_decode_metadata_ornt,
+    test_wuffs_png_decode_passes,
+    test_wuffs_png_decode_row_groups,
 
 #ifdef WUFFS_MIMIC
 
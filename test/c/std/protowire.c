@@ -0,0 +1,409 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror protowire.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__PROTOWIRE
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_protowire_protowire_examples_gt = {
+    .want_filename = "test/data/protowire-examples.tokens",
+    .src_filename = "test/data/protowire-examples.binpb",
+};
+
+// ---------------- Protowire Tests
+
+const char*  //
+test_wuffs_protowire_decode_interface() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_protowire__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_protowire__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  CHECK_STRING(do_test__wuffs_base__token_decoder(
+      wuffs_protowire__decoder__upcast_as__wuffs_base__token_decoder(&dec),
+      &g_protowire_protowire_examples_gt));
+
+  return NULL;
+}
+
+const char*  //
+test_wuffs_protowire_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  // This suite contains invalid examples, which should be rejected.
+  char* test_cases[] = {
+      // Field number 0.
+      "\x02\x01\x61",
+      // Wire type 6.
+      "\x0E\x01",
+      // Wire type 7.
+      "\x0F",
+      // Tag wider than 32 bits.
+      "\x80\x80\x80\x80\x10\x01",
+      // Truncated varint value.
+      "\x08\x80",
+      // Varint value overflowing 64 bits.
+      "\x08\xFF\xFF\xFF\xFF\xFF\xFF\xFF\xFF\xFF\x02",
+      // Varint value longer than 10 bytes.
+      "\x08\xFF\xFF\xFF\xFF\xFF\xFF\xFF\xFF\xFF\x81\x01",
+      // Truncated I32 value.
+      "\x0D\x01\x02",
+      // Truncated LEN value.
+      "\x12\x05\x61\x62\x63",
+      // Unterminated group.
+      "\x1B",
+      // End group without a start group.
+      "\x1C",
+      // Mismatched end group field number.
+      "\x1B\x24",
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__token tok_array[256];
+    wuffs_base__token_buffer tok_buf =
+        wuffs_base__slice_token__writer(wuffs_base__make_slice_token(
+            &tok_array[0], WUFFS_TESTLIB_ARRAY_SIZE(tok_array)));
+    const bool closed = true;
+    wuffs_base__io_buffer io_buf = wuffs_base__slice_u8__reader(
+        wuffs_base__make_slice_u8((uint8_t*)(test_cases[tc]),
+                                  strlen(test_cases[tc])),
+        closed);
+
+    wuffs_protowire__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_protowire__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__status status = wuffs_protowire__decoder__decode_tokens(
+        &dec, &tok_buf, &io_buf, g_work_slice_u8);
+    if (!wuffs_base__status__is_error(&status)) {
+      RETURN_FAIL("tc=%d: have \"%s\", want an error", tc, status.repr);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_protowire_decode_valid() {
+  CHECK_FOCUS(__func__);
+
+  // This suite contains valid examples, similar to the
+  // test_wuffs_protowire_decode_invalid examples, but they should be
+  // accepted.
+  char* test_cases[] = {
+      // Empty message.
+      "",
+      // VARINT value.
+      "\x08\x01",
+      // I32 value.
+      "\x0D\x01\x02\x03\x04",
+      // LEN value.
+      "\x12\x01\x7F",
+      // Empty group.
+      "\x1B\x1C",
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__token tok_array[256];
+    wuffs_base__token_buffer tok_buf =
+        wuffs_base__slice_token__writer(wuffs_base__make_slice_token(
+            &tok_array[0], WUFFS_TESTLIB_ARRAY_SIZE(tok_array)));
+    const bool closed = true;
+    wuffs_base__io_buffer io_buf = wuffs_base__slice_u8__reader(
+        wuffs_base__make_slice_u8((uint8_t*)(test_cases[tc]),
+                                  strlen(test_cases[tc])),
+        closed);
+
+    wuffs_protowire__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_protowire__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__status status = wuffs_protowire__decoder__decode_tokens(
+        &dec, &tok_buf, &io_buf, g_work_slice_u8);
+    if (!wuffs_base__status__is_ok(&status)) {
+      RETURN_FAIL("tc=%d: have \"%s\", want no error", tc, status.repr);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_protowire_decode_u64_token_pairs() {
+  CHECK_FOCUS(__func__);
+
+  // Field number 0x1FFF_FFFF (too wide for one token), VARINT value
+  // 0xFFFF_FFFF_FFFF_FFFF (likewise).
+  const char* src_ptr =
+      "\xF8\xFF\xFF\xFF\x0F"
+      "\xFF\xFF\xFF\xFF\xFF\xFF\xFF\xFF\xFF\x01";
+  const size_t src_len = 15;
+
+  wuffs_base__token tok_array[256];
+  wuffs_base__token_buffer tok_buf =
+      wuffs_base__slice_token__writer(wuffs_base__make_slice_token(
+          &tok_array[0], WUFFS_TESTLIB_ARRAY_SIZE(tok_array)));
+  wuffs_base__io_buffer io_buf = wuffs_base__slice_u8__reader(
+      wuffs_base__make_slice_u8((uint8_t*)(src_ptr), src_len), true);
+
+  wuffs_protowire__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_protowire__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  CHECK_STATUS("decode_tokens",
+               wuffs_protowire__decoder__decode_tokens(
+                   &dec, &tok_buf, &io_buf, g_work_slice_u8));
+
+  // The two token pairs are bracketed by push and pop structure tokens.
+  if (tok_buf.meta.wi != 6) {
+    RETURN_FAIL("tok_buf.meta.wi: have %zu, want 6", tok_buf.meta.wi);
+  }
+  const wuffs_base__token* t = &tok_array[1];
+  const uint64_t want_values[2] = {0x1FFFFFFF, 0xFFFFFFFFFFFFFFFF};
+  const uint64_t want_lengths[2] = {5, 10};
+  int i;
+  for (i = 0; i < 2; i++, t += 2) {
+    if (!wuffs_base__token__continued(&t[0]) ||
+        (wuffs_base__token__length(&t[0]) != 0)) {
+      RETURN_FAIL("i=%d: first token: have 0x%016" PRIX64
+                  ", want continued and zero length",
+                  i, t[0].repr);
+    } else if (wuffs_base__token__continued(&t[1]) ||
+               (wuffs_base__token__value_extension(&t[1]) < 0)) {
+      RETURN_FAIL("i=%d: second token: have 0x%016" PRIX64
+                  ", want extended and not continued",
+                  i, t[1].repr);
+    }
+    uint64_t have_value = wuffs_base__token__joined_u64(&t[0], &t[1]);
+    if (have_value != want_values[i]) {
+      RETURN_FAIL("i=%d: value: have 0x%" PRIX64 ", want 0x%" PRIX64, i,
+                  have_value, want_values[i]);
+    }
+    uint64_t have_length = wuffs_base__token__length(&t[1]);
+    if (have_length != want_lengths[i]) {
+      RETURN_FAIL("i=%d: length: have %" PRIu64 ", want %" PRIu64, i,
+                  have_length, want_lengths[i]);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_protowire_decode_recursion_depth() {
+  CHECK_FOCUS(__func__);
+
+  // Nest groups (all with field number 1) 1024 and 1025 deep.
+  int depth;
+  for (depth = 1024; depth <= 1025; depth++) {
+    memset(g_src_slice_u8.ptr, 0x0B, depth);
+    memset(g_src_slice_u8.ptr + depth, 0x0C, depth);
+
+    wuffs_base__token_buffer tok_buf = ((wuffs_base__token_buffer){
+        .data = g_have_slice_token,
+    });
+    const bool closed = true;
+    wuffs_base__io_buffer io_buf = wuffs_base__slice_u8__reader(
+        wuffs_base__make_slice_u8(g_src_slice_u8.ptr, 2 * depth), closed);
+
+    wuffs_protowire__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_protowire__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__status status = wuffs_protowire__decoder__decode_tokens(
+        &dec, &tok_buf, &io_buf, g_work_slice_u8);
+    const char* want =
+        (depth <= 1024) ? NULL
+                        : wuffs_protowire__error__unsupported_recursion_depth;
+    if (status.repr != want) {
+      RETURN_FAIL("depth=%d: have \"%s\", want \"%s\"", depth, status.repr,
+                  want);
+    } else if ((want != NULL) && (io_buf.meta.ri != 1024)) {
+      RETURN_FAIL("depth=%d: ri: have %zu, want 1024", depth, io_buf.meta.ri);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_protowire_decode_short_reads() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(
+      read_file(&src, g_protowire_protowire_examples_gt.src_filename));
+  const size_t src_len = src.meta.wi;
+
+  wuffs_protowire__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_protowire__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  // Reveal the source one byte at a time, consuming tokens two at a time.
+  src.meta.wi = 0;
+  src.meta.closed = false;
+  uint64_t pos = 0;
+  while (true) {
+    wuffs_base__token tok_array[2];
+    wuffs_base__token_buffer tok_buf =
+        wuffs_base__slice_token__writer(wuffs_base__make_slice_token(
+            &tok_array[0], WUFFS_TESTLIB_ARRAY_SIZE(tok_array)));
+    wuffs_base__status status = wuffs_protowire__decoder__decode_tokens(
+        &dec, &tok_buf, &src, g_work_slice_u8);
+    size_t i;
+    for (i = 0; i < tok_buf.meta.wi; i++) {
+      pos += wuffs_base__token__length(&tok_array[i]);
+    }
+
+    if (wuffs_base__status__is_ok(&status)) {
+      break;
+    } else if (status.repr == wuffs_base__suspension__short_read) {
+      if (src.meta.wi >= src_len) {
+        RETURN_FAIL("short read at end of input");
+      }
+      src.meta.wi++;
+      src.meta.closed = src.meta.wi == src_len;
+    } else if (status.repr != wuffs_base__suspension__short_write) {
+      RETURN_FAIL("decode_tokens: \"%s\"", status.repr);
+    }
+  }
+
+  if (pos != src_len) {
+    RETURN_FAIL("pos: have %" PRIu64 ", want %zu", pos, src_len);
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Protowire Benches
+
+// No Protowire benches.
+
+// ---------------- Mimic Benches
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_protowire_decode_interface,
+    test_wuffs_protowire_decode_invalid,
+    test_wuffs_protowire_decode_recursion_depth,
+    test_wuffs_protowire_decode_short_reads,
+    test_wuffs_protowire_decode_u64_token_pairs,
+    test_wuffs_protowire_decode_valid,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No Protowire benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/protowire";
+  return test_main(argc, argv, g_tests, g_benches);
+}
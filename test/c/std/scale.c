@@ -0,0 +1,413 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror scale.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__NIE
+#define WUFFS_CONFIG__MODULE__SCALE
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Scale Tests
+
+// do_test_wuffs_scale scales src_pixels, a src_width × src_height
+// BGRA_PREMUL image, to a dst_width × dst_height image with the given pixel
+// format and checks that the dst pixels match want_pixels.
+const char*  //
+do_test_wuffs_scale(uint32_t filter,
+                    const uint32_t* src_pixels,
+                    uint32_t src_width,
+                    uint32_t src_height,
+                    uint32_t dst_pixfmt_repr,
+                    const uint32_t* want_pixels,
+                    uint32_t dst_width,
+                    uint32_t dst_height) {
+  uint8_t src_data[256];
+  uint8_t dst_data[256];
+  if ((src_width * src_height * 4 > sizeof src_data) ||
+      (dst_width * dst_height * 4 > sizeof dst_data)) {
+    RETURN_FAIL("image too large");
+  }
+
+  wuffs_base__pixel_config src_pixcfg = ((wuffs_base__pixel_config){});
+  wuffs_base__pixel_config__set(
+      &src_pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, src_width, src_height);
+  wuffs_base__pixel_buffer src_pixbuf = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (src)",
+               wuffs_base__pixel_buffer__set_from_slice(
+                   &src_pixbuf, &src_pixcfg,
+                   wuffs_base__make_slice_u8(src_data, sizeof src_data)));
+  uint32_t i;
+  for (i = 0; i < src_width * src_height; i++) {
+    wuffs_base__poke_u32le__no_bounds_check(src_data + (4 * i),
+                                            src_pixels[i]);
+  }
+
+  wuffs_base__pixel_config dst_pixcfg = ((wuffs_base__pixel_config){});
+  wuffs_base__pixel_config__set(&dst_pixcfg, dst_pixfmt_repr,
+                                WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, dst_width,
+                                dst_height);
+  wuffs_base__pixel_buffer dst_pixbuf = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (dst)",
+               wuffs_base__pixel_buffer__set_from_slice(
+                   &dst_pixbuf, &dst_pixcfg,
+                   wuffs_base__make_slice_u8(dst_data, sizeof dst_data)));
+
+  wuffs_scale__scaler scaler;
+  CHECK_STATUS("initialize",
+               wuffs_scale__scaler__initialize(
+                   &scaler, sizeof scaler, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  CHECK_STATUS("prepare",
+               wuffs_scale__scaler__prepare(&scaler, &dst_pixbuf, &src_pixbuf,
+                                            filter,
+                                            WUFFS_BASE__PIXEL_BLEND__SRC));
+  uint64_t workbuf_len = wuffs_scale__scaler__workbuf_len(&scaler).max_incl;
+  if (workbuf_len > g_work_slice_u8.len) {
+    RETURN_FAIL("workbuf_len: have %" PRIu64 ", want <= %zu", workbuf_len,
+                g_work_slice_u8.len);
+  }
+  CHECK_STATUS("scale",
+               wuffs_scale__scaler__scale(
+                   &scaler, &dst_pixbuf, &src_pixbuf,
+                   wuffs_base__make_slice_u8(g_work_slice_u8.ptr,
+                                             (size_t)workbuf_len)));
+
+  uint32_t y;
+  for (y = 0; y < dst_height; y++) {
+    uint32_t x;
+    for (x = 0; x < dst_width; x++) {
+      uint32_t have =
+          wuffs_base__pixel_buffer__color_u32_at(&dst_pixbuf, x, y);
+      uint32_t want = want_pixels[(y * dst_width) + x];
+      if (have != want) {
+        RETURN_FAIL("(%" PRIu32 ", %" PRIu32 "): have 0x%08" PRIX32
+                    ", want 0x%08" PRIX32,
+                    x, y, have, want);
+      }
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_scale_bilinear() {
+  CHECK_FOCUS(__func__);
+  const uint32_t src[2] = {0xFF000000, 0xFFFFFFFF};
+  const uint32_t want[4] = {0xFF000000, 0xFF404040, 0xFFBFBFBF, 0xFFFFFFFF};
+  return do_test_wuffs_scale(WUFFS_SCALE__FILTER__BILINEAR, src, 2, 1,
+                             WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL, want, 4,
+                             1);
+}
+
+const char*  //
+test_wuffs_scale_box() {
+  CHECK_FOCUS(__func__);
+  const uint32_t src[16] = {
+      0xFF000000, 0xFF000004, 0xFF000010, 0xFF000010,  //
+      0xFF000008, 0xFF00000C, 0xFF000010, 0xFF000010,  //
+      0xFF000000, 0xFF000000, 0xFF000100, 0xFF000300,  //
+      0xFF000000, 0xFF000000, 0xFF000500, 0xFF000700,  //
+  };
+  const uint32_t want[4] = {0xFF000006, 0xFF000010, 0xFF000000, 0xFF000400};
+  return do_test_wuffs_scale(WUFFS_SCALE__FILTER__BOX, src, 4, 4,
+                             WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL, want, 2,
+                             2);
+}
+
+const char*  //
+test_wuffs_scale_convert() {
+  CHECK_FOCUS(__func__);
+  // Scaling a BGRA_PREMUL image to a BGR image converts (and drops the alpha
+  // channel) in the same pass. BGR_565 further quantizes each channel.
+  const uint32_t src[4] = {0xFF102030, 0xFF304050, 0xFF506070, 0xFF708090};
+  const uint32_t want_bgr[1] = {0xFF405060};
+  CHECK_STRING(do_test_wuffs_scale(WUFFS_SCALE__FILTER__BOX, src, 2, 2,
+                                   WUFFS_BASE__PIXEL_FORMAT__BGR, want_bgr, 1,
+                                   1));
+  const uint32_t want_bgr_565[1] = {0xFF425163};
+  return do_test_wuffs_scale(WUFFS_SCALE__FILTER__BOX, src, 2, 2,
+                             WUFFS_BASE__PIXEL_FORMAT__BGR_565, want_bgr_565,
+                             1, 1);
+}
+
+const char*  //
+test_wuffs_scale_errors() {
+  CHECK_FOCUS(__func__);
+  uint8_t src_data[64] = {0};
+  uint8_t dst_data[64] = {0};
+
+  wuffs_base__pixel_config pixcfg = ((wuffs_base__pixel_config){});
+  wuffs_base__pixel_config__set(&pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL,
+                                WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, 4, 4);
+  wuffs_base__pixel_buffer src_pixbuf = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (src)",
+               wuffs_base__pixel_buffer__set_from_slice(
+                   &src_pixbuf, &pixcfg,
+                   wuffs_base__make_slice_u8(src_data, sizeof src_data)));
+  wuffs_base__pixel_config__set(&pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL,
+                                WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, 2, 2);
+  wuffs_base__pixel_buffer dst_pixbuf = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (dst)",
+               wuffs_base__pixel_buffer__set_from_slice(
+                   &dst_pixbuf, &pixcfg,
+                   wuffs_base__make_slice_u8(dst_data, sizeof dst_data)));
+
+  wuffs_scale__scaler scaler;
+  CHECK_STATUS("initialize",
+               wuffs_scale__scaler__initialize(
+                   &scaler, sizeof scaler, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  const char* have = NULL;
+  const char* want = NULL;
+
+  have = wuffs_scale__scaler__scale(&scaler, &dst_pixbuf, &src_pixbuf,
+                                    g_work_slice_u8)
+             .repr;
+  want = wuffs_scale__error__bad_call_sequence;
+  if (have != want) {
+    RETURN_FAIL("scale before prepare: have \"%s\", want \"%s\"", have, want);
+  }
+
+  have = wuffs_scale__scaler__prepare(&scaler, &dst_pixbuf, &src_pixbuf, 3,
+                                      WUFFS_BASE__PIXEL_BLEND__SRC)
+             .repr;
+  want = wuffs_scale__error__unsupported_filter;
+  if (have != want) {
+    RETURN_FAIL("prepare: have \"%s\", want \"%s\"", have, want);
+  }
+
+  CHECK_STATUS("prepare",
+               wuffs_scale__scaler__prepare(&scaler, &dst_pixbuf, &src_pixbuf,
+                                            WUFFS_SCALE__FILTER__BOX,
+                                            WUFFS_BASE__PIXEL_BLEND__SRC));
+  uint64_t workbuf_len = wuffs_scale__scaler__workbuf_len(&scaler).max_incl;
+  if (workbuf_len != 88) {
+    RETURN_FAIL("workbuf_len: have %" PRIu64 ", want 88", workbuf_len);
+  }
+
+  have = wuffs_scale__scaler__scale(
+             &scaler, &dst_pixbuf, &src_pixbuf,
+             wuffs_base__make_slice_u8(g_work_slice_u8.ptr, workbuf_len - 1))
+             .repr;
+  want = wuffs_base__error__bad_workbuf_length;
+  if (have != want) {
+    RETURN_FAIL("scale (short workbuf): have \"%s\", want \"%s\"", have, want);
+  }
+
+  // Scaling the src to itself changes the dst's dimensions.
+  have = wuffs_scale__scaler__scale(&scaler, &src_pixbuf, &src_pixbuf,
+                                    g_work_slice_u8)
+             .repr;
+  want = wuffs_scale__error__inconsistent_image_dimensions;
+  if (have != want) {
+    RETURN_FAIL("scale (src to src): have \"%s\", want \"%s\"", have, want);
+  }
+
+  CHECK_STATUS("scale",
+               wuffs_scale__scaler__scale(&scaler, &dst_pixbuf, &src_pixbuf,
+                                          g_work_slice_u8));
+  return NULL;
+}
+
+const char*  //
+test_wuffs_scale_nearest() {
+  CHECK_FOCUS(__func__);
+  const uint32_t src[4] = {0xFF000001, 0xFF000002, 0xFF000003, 0xFF000004};
+  const uint32_t want[16] = {
+      0xFF000001, 0xFF000001, 0xFF000002, 0xFF000002,  //
+      0xFF000001, 0xFF000001, 0xFF000002, 0xFF000002,  //
+      0xFF000003, 0xFF000003, 0xFF000004, 0xFF000004,  //
+      0xFF000003, 0xFF000003, 0xFF000004, 0xFF000004,  //
+  };
+  return do_test_wuffs_scale(WUFFS_SCALE__FILTER__NEAREST, src, 2, 2,
+                             WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL, want, 4,
+                             4);
+}
+
+// ---------------- Scale Benches
+
+const char*  //
+do_bench_wuffs_scale(uint32_t filter,
+                     uint32_t divisor,
+                     uint64_t iters_unscaled) {
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/hippopotamus.nie"));
+
+  wuffs_nie__decoder dec;
+  CHECK_STATUS("initialize (nie)",
+               wuffs_nie__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config",
+               wuffs_nie__decoder__decode_image_config(&dec, &ic, &src));
+  uint32_t width = wuffs_base__pixel_config__width(&ic.pixcfg);
+  uint32_t height = wuffs_base__pixel_config__height(&ic.pixcfg);
+  wuffs_base__pixel_config__set(&ic.pixcfg,
+                                WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL,
+                                WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, width,
+                                height);
+  wuffs_base__pixel_buffer src_pixbuf = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (src)",
+               wuffs_base__pixel_buffer__set_from_slice(
+                   &src_pixbuf, &ic.pixcfg, g_pixel_slice_u8));
+  CHECK_STATUS("decode_frame",
+               wuffs_nie__decoder__decode_frame(
+                   &dec, &src_pixbuf, &src, WUFFS_BASE__PIXEL_BLEND__SRC,
+                   wuffs_base__empty_slice_u8(), NULL));
+
+  wuffs_base__pixel_config dst_pixcfg = ((wuffs_base__pixel_config){});
+  wuffs_base__pixel_config__set(
+      &dst_pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, width / divisor, height / divisor);
+  wuffs_base__pixel_buffer dst_pixbuf = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (dst)",
+               wuffs_base__pixel_buffer__set_from_slice(
+                   &dst_pixbuf, &dst_pixcfg, g_have_slice_u8));
+
+  wuffs_scale__scaler scaler;
+  CHECK_STATUS("initialize (scale)",
+               wuffs_scale__scaler__initialize(
+                   &scaler, sizeof scaler, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  CHECK_STATUS("prepare",
+               wuffs_scale__scaler__prepare(&scaler, &dst_pixbuf, &src_pixbuf,
+                                            filter,
+                                            WUFFS_BASE__PIXEL_BLEND__SRC));
+
+  bench_start();
+  uint64_t n_bytes = 0;
+  uint64_t i;
+  uint64_t iters = iters_unscaled * g_flags.iterscale;
+  for (i = 0; i < iters; i++) {
+    CHECK_STATUS("scale",
+                 wuffs_scale__scaler__scale(&scaler, &dst_pixbuf, &src_pixbuf,
+                                            g_work_slice_u8));
+    n_bytes += ((uint64_t)width) * ((uint64_t)height) * 4;
+  }
+  bench_finish(iters, n_bytes);
+  return NULL;
+}
+
+const char*  //
+bench_wuffs_scale_bilinear() {
+  CHECK_FOCUS(__func__);
+  return do_bench_wuffs_scale(WUFFS_SCALE__FILTER__BILINEAR, 2, 100);
+}
+
+const char*  //
+bench_wuffs_scale_box() {
+  CHECK_FOCUS(__func__);
+  return do_bench_wuffs_scale(WUFFS_SCALE__FILTER__BOX, 4, 100);
+}
+
+const char*  //
+bench_wuffs_scale_nearest() {
+  CHECK_FOCUS(__func__);
+  return do_bench_wuffs_scale(WUFFS_SCALE__FILTER__NEAREST, 2, 100);
+}
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_scale_bilinear,
+    test_wuffs_scale_box,
+    test_wuffs_scale_convert,
+    test_wuffs_scale_errors,
+    test_wuffs_scale_nearest,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+    bench_wuffs_scale_bilinear,
+    bench_wuffs_scale_box,
+    bench_wuffs_scale_nearest,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/scale";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -0,0 +1,399 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror sfnt.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__SFNT
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+// No SFNT golden tests.
+
+// ---------------- SFNT Tests
+
+// g_sfnt_src is a TrueType font with head, maxp, cmap (with a format 4
+// subtable, referenced by two encoding records) and name tables, in that file
+// offset order, with 2 bytes of padding between consecutive tables. It was
+// generated by a short Python script.
+const char g_sfnt_src[] =
+    "\x00\x01\x00\x00\x00\x04\x00\x40\x00\x02\x00\x00\x63\x6D\x61\x70"
+    "\x00\x11\x00\xAF\x00\x00\x00\x8C\x00\x00\x00\x36\x68\x65\x61\x64"
+    "\x62\x91\x43\xF4\x00\x00\x00\x4C\x00\x00\x00\x36\x6D\x61\x78\x70"
+    "\x00\x07\x50\x00\x00\x00\x00\x84\x00\x00\x00\x06\x6E\x61\x6D\x65"
+    "\xD7\x65\x6C\x6C\x00\x00\x00\xC4\x00\x00\x00\x05\x00\x01\x00\x00"
+    "\x00\x01\x00\x00\x95\xF6\x02\x1C\x5F\x0F\x3C\xF5\x00\x03\x03\xE8"
+    "\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x02"
+    "\xFF\xF6\xFF\xEC\x03\x84\x03\x20\x00\x00\x00\x08\x00\x02\x00\x00"
+    "\x00\x00\x00\x00\x00\x00\x50\x00\x00\x07\x00\x00\x00\x00\x00\x02"
+    "\x00\x03\x00\x01\x00\x00\x00\x14\x00\x00\x00\x03\x00\x00\x00\x14"
+    "\x00\x04\x00\x22\x00\x00\x00\x04\x00\x04\x00\x01\x00\x00\x00\x5A"
+    "\xFF\xFF\x00\x00\x00\x41\xFF\xFF\xFF\xC0\x00\x01\x00\x00\x00\x00"
+    "\x00\x05\x00\x00\x68\x65\x6C\x6C\x6F";
+
+// wuffs_sfnt_decode decodes src into tok, limiting each decode_tokens call to
+// wlimit tokens and rlimit bytes.
+const char*  //
+wuffs_sfnt_decode(wuffs_base__token_buffer* tok,
+                  wuffs_base__io_buffer* src,
+                  uint32_t quirk,
+                  uint64_t wlimit,
+                  uint64_t rlimit) {
+  wuffs_sfnt__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_sfnt__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  if (quirk) {
+    wuffs_sfnt__decoder__set_quirk_enabled(&dec, quirk, true);
+  }
+
+  while (true) {
+    wuffs_base__token_buffer limited_tok =
+        make_limited_token_writer(*tok, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+
+    wuffs_base__status status = wuffs_sfnt__decoder__decode_tokens(
+        &dec, &limited_tok, &limited_src, g_work_slice_u8);
+
+    tok->meta.wi += limited_tok.meta.wi;
+    src->meta.ri += limited_src.meta.ri;
+
+    if (((wlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_write)) ||
+        ((rlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_read))) {
+      continue;
+    }
+    return status.repr;
+  }
+}
+
+// sfnt_summarize writes a summary of the tokens to dst, which has a capacity
+// of at least 1024 bytes. Lists are bracketed, numbers are in decimal, string
+// and filler chains are "s" or "f" followed by their combined length and
+// table tags are between angle brackets. It also checks that the token
+// lengths sum to src_len.
+const char*  //
+sfnt_summarize(char* dst, wuffs_base__token_buffer* tok, uint64_t src_len) {
+  char* d = dst;
+  uint64_t total_length = 0;
+  uint64_t chain_length = 0;
+  size_t i;
+  for (i = tok->meta.ri; i < tok->meta.wi; i++) {
+    wuffs_base__token* t = &tok->data.ptr[i];
+    uint64_t len = wuffs_base__token__length(t);
+    total_length += len;
+    if (wuffs_base__token__value_major(t) == WUFFS_SFNT__TOKEN_VALUE_MAJOR) {
+      if ((wuffs_base__token__value_minor(t) !=
+           WUFFS_SFNT__TOKEN_VALUE_MINOR__TABLE_TAG) ||
+          (++i >= tok->meta.wi)) {
+        RETURN_FAIL("i=%zu: bad table tag token", i);
+      }
+      uint32_t x = (uint32_t)(wuffs_base__token__value_extension(
+          &tok->data.ptr[i]));
+      d += sprintf(d, "<%c%c%c%c>", (char)(x >> 24), (char)(x >> 16),
+                   (char)(x >> 8), (char)(x >> 0));
+      continue;
+    }
+    int64_t detail = wuffs_base__token__value_base_detail(t);
+    switch (wuffs_base__token__value_base_category(t)) {
+      case WUFFS_BASE__TOKEN__VBC__STRUCTURE:
+        d += sprintf(
+            d, (detail & WUFFS_BASE__TOKEN__VBD__STRUCTURE__POP) ? "]" : "[");
+        break;
+      case WUFFS_BASE__TOKEN__VBC__INLINE_INTEGER_SIGNED:
+        d += sprintf(d, " %" PRId64, (detail ^ 0x100000) - 0x100000);
+        break;
+      case WUFFS_BASE__TOKEN__VBC__INLINE_INTEGER_UNSIGNED:
+        if (!wuffs_base__token__continued(t)) {
+          d += sprintf(d, " %" PRIu64, (uint64_t)detail);
+        } else if (++i >= tok->meta.wi) {
+          RETURN_FAIL("i=%zu: bad number token", i);
+        } else {
+          total_length += wuffs_base__token__length(&tok->data.ptr[i]);
+          d += sprintf(d, " %" PRIu64,
+                       (uint64_t)(wuffs_base__token__value_extension(
+                           &tok->data.ptr[i])));
+        }
+        break;
+      case WUFFS_BASE__TOKEN__VBC__STRING:
+      case WUFFS_BASE__TOKEN__VBC__FILLER:
+        chain_length += len;
+        if (!wuffs_base__token__continued(t)) {
+          d += sprintf(d, " %c%" PRIu64,
+                       (wuffs_base__token__value_base_category(t) ==
+                        WUFFS_BASE__TOKEN__VBC__STRING)
+                           ? 's'
+                           : 'f',
+                       chain_length);
+          chain_length = 0;
+        }
+        break;
+      default:
+        RETURN_FAIL("i=%zu: unexpected token", i);
+    }
+    if ((d - dst) > 960) {
+      RETURN_FAIL("summary is too long");
+    }
+  }
+  if (total_length != src_len) {
+    RETURN_FAIL("total length: have %" PRIu64 ", want %" PRIu64, total_length,
+                src_len);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_sfnt_decode_tables() {
+  CHECK_FOCUS(__func__);
+
+  const size_t src_len = sizeof(g_sfnt_src) - 1;
+  const char* want =
+      "[[ 65536 4 64 2 0]"
+      "[[ s4 1114287 140 54][ s4 1653687284 76 54]"
+      "[ s4 479232 132 6][ s4 3613748332 196 5]]"
+      "[<head> 65536 65536 2515927580 1594834165 3 1000 s8 s8"
+      " -10 -20 900 800 0 8 2 0 0]"
+      " f2[<maxp> 20480 7 s0]"
+      " f2[<cmap> 0 2[[ 3 1 20][ 0 3 20]]"
+      " 4 34 0 4 4 1 0[ 90 65535] 0[ 65 65535][ -64 1][ 0 0] s2]"
+      " f2[<name> s5]]";
+
+  int tc;
+  for (tc = 0; tc < 4; tc++) {
+    uint64_t wlimit = (tc & 1) ? 2 : UINT64_MAX;
+    uint64_t rlimit = (tc & 2) ? 4 : UINT64_MAX;
+
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src =
+        wuffs_base__ptr_u8__reader((uint8_t*)g_sfnt_src, src_len, true);
+    CHECK_STRING(wuffs_sfnt_decode(&tok, &src, 0, wlimit, rlimit));
+    if (src.meta.ri != src_len) {
+      RETURN_FAIL("tc=%d: src.meta.ri: have %zu, want %zu", tc, src.meta.ri,
+                  src_len);
+    }
+
+    char have[1024];
+    CHECK_STRING(sfnt_summarize(have, &tok, src_len));
+    if (strcmp(have, want)) {
+      RETURN_FAIL("tc=%d:\nhave \"%s\"\nwant \"%s\"", tc, have, want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_sfnt_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  const size_t n = sizeof(g_sfnt_src) - 1;
+  uint8_t src_array[256];
+
+  struct {
+    const char* want;
+    uint32_t quirk;
+    size_t length;
+    size_t offset;
+    uint8_t value;
+  } test_cases[] = {
+      {
+          // A 0x00020000 sfntVersion.
+          .want = wuffs_sfnt__error__bad_header,
+          .length = n,
+          .offset = 1,
+          .value = 0x02,
+      },
+      {
+          // Zero tables.
+          .want = wuffs_sfnt__error__bad_header,
+          .length = n,
+          .offset = 5,
+          .value = 0x00,
+      },
+      {
+          // A "zmap" tag, out of sorted order.
+          .want = wuffs_sfnt__error__bad_table_directory,
+          .length = n,
+          .offset = 12,
+          .value = 'z',
+      },
+      {
+          // A head table overlapping the table directory.
+          .want = wuffs_sfnt__error__bad_table_directory,
+          .length = n,
+          .offset = 39,
+          .value = 0x30,
+      },
+      {
+          // A bad head magicNumber.
+          .want = wuffs_sfnt__error__bad_head_table,
+          .length = n,
+          .offset = 76 + 12,
+          .value = 0x00,
+      },
+      {
+          // A bad maxp version.
+          .want = wuffs_sfnt__error__bad_maxp_table,
+          .length = n,
+          .offset = 132 + 2,
+          .value = 0x40,
+      },
+      {
+          // A cmap format 4 subtable whose last endCode is not 0xFFFF.
+          .want = wuffs_sfnt__error__bad_cmap_table,
+          .length = n,
+          .offset = 140 + 20 + 17,
+          .value = 0xFE,
+      },
+      {
+          // A modified name table.
+          .want = wuffs_sfnt__error__bad_checksum,
+          .length = n,
+          .offset = n - 1,
+          .value = 'O',
+      },
+      {
+          // A modified name table, with QUIRK_IGNORE_CHECKSUMS.
+          .want = NULL,
+          .quirk = WUFFS_SFNT__QUIRK_IGNORE_CHECKSUMS,
+          .length = n,
+          .offset = n - 1,
+          .value = 'O',
+      },
+      {
+          // A truncated cmap table.
+          .want = wuffs_sfnt__error__truncated_input,
+          .length = 150,
+          .offset = 0,
+          .value = 0x00,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    memcpy(src_array, g_sfnt_src, n);
+    src_array[test_cases[tc].offset] = test_cases[tc].value;
+
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        &src_array[0], test_cases[tc].length, true);
+    const char* have = wuffs_sfnt_decode(&tok, &src, test_cases[tc].quirk,
+                                         UINT64_MAX, UINT64_MAX);
+    if (have != test_cases[tc].want) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, have,
+                  test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- SFNT Benches
+
+// No SFNT benches.
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_sfnt_decode_invalid,
+    test_wuffs_sfnt_decode_tables,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No SFNT benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/sfnt";
+  return test_main(argc, argv, g_tests, g_benches);
+}
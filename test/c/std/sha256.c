@@ -0,0 +1,406 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror sha256.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__SHA256
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_sha256_midsummer_gt = {
+    .src_filename = "test/data/midsummer.txt",
+};
+
+golden_test g_sha256_pi_gt = {
+    .src_filename = "test/data/pi.txt",
+};
+
+// ---------------- SHA256 Tests
+
+// hex_checksum writes the lower-case hexadecimal form of h's checksum (so far)
+// to dst, which must have room for 65 bytes, including the NUL terminator.
+const char*  //
+hex_checksum(char* dst, wuffs_sha256__hasher* h) {
+  uint8_t checksum[32];
+  uint64_t n = wuffs_sha256__hasher__checksum(
+      h, wuffs_base__make_slice_u8(checksum, sizeof checksum));
+  if (n != sizeof checksum) {
+    RETURN_FAIL("checksum: have %" PRIu64 ", want %d", n,
+                (int)(sizeof checksum));
+  }
+  int i;
+  for (i = 0; i < 32; i++) {
+    dst[(2 * i) + 0] = "0123456789abcdef"[checksum[i] >> 4];
+    dst[(2 * i) + 1] = "0123456789abcdef"[checksum[i] & 15];
+  }
+  dst[64] = '\x00';
+  return NULL;
+}
+
+const char*  //
+test_wuffs_sha256_golden() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* filename;
+    // The want values are determined by script/checksum.go.
+    const char* want;
+  } test_cases[] = {
+      {
+          .filename = "test/data/hat.bmp",
+          .want = "0ee3a9c0b94ebd3e14ce2b683b2119255d80db0568db97412fc725ba7661c777",
+      },
+      {
+          .filename = "test/data/hat.gif",
+          .want = "da46a35274a6b3db483a2e527cc031b0f37522d2d09bf24a64e368566b47ba08",
+      },
+      {
+          .filename = "test/data/hat.jpeg",
+          .want = "6085c5a68849523350f1b82175229c037486deca160ca57c2c6b9551f7574ab3",
+      },
+      {
+          .filename = "test/data/hat.lossless.webp",
+          .want = "d91429191b532107311b3c16a251a65ecc1142375132c3c27ac2f1dc78f9ac80",
+      },
+      {
+          .filename = "test/data/hat.lossy.webp",
+          .want = "c3e4e8e405b501cb204081787511f1a36ab8300ceaba70955cc50e1942d6f258",
+      },
+      {
+          .filename = "test/data/hat.png",
+          .want = "d3f360af629b57807a1bdbcae77a1d752df493278110663a23958198999b6d59",
+      },
+      {
+          .filename = "test/data/hat.tiff",
+          .want = "f44307c419c5f9bb852e5f5f54d5bbd0373d5f707d803b99fac8637f04ec2392",
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+        .data = g_src_slice_u8,
+    });
+    CHECK_STRING(read_file(&src, test_cases[tc].filename));
+
+    int j;
+    for (j = 0; j < 2; j++) {
+      wuffs_sha256__hasher checksum;
+      CHECK_STATUS("initialize",
+                   wuffs_sha256__hasher__initialize(
+                       &checksum, sizeof checksum, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+      char have[65];
+      size_t num_fragments = 0;
+      size_t num_bytes = 0;
+      do {
+        wuffs_base__slice_u8 data = ((wuffs_base__slice_u8){
+            .ptr = src.data.ptr + num_bytes,
+            .len = src.meta.wi - num_bytes,
+        });
+        size_t limit = 101 + 103 * num_fragments;
+        if ((j > 0) && (data.len > limit)) {
+          data.len = limit;
+        }
+        wuffs_sha256__hasher__update(&checksum, data);
+        // Computing an intermediate checksum should not affect the final one.
+        CHECK_STRING(hex_checksum(have, &checksum));
+        num_fragments++;
+        num_bytes += data.len;
+      } while (num_bytes < src.meta.wi);
+
+      CHECK_STRING(hex_checksum(have, &checksum));
+      if (strcmp(have, test_cases[tc].want)) {
+        RETURN_FAIL("tc=%d, j=%d, filename=\"%s\": have \"%s\", want \"%s\"",
+                    tc, j, test_cases[tc].filename, have, test_cases[tc].want);
+      }
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_sha256_nist() {
+  CHECK_FOCUS(__func__);
+
+  // These test vectors are from FIPS 180-2 and its examples.
+  struct {
+    const char* src;
+    size_t num_repeats;
+    const char* want;
+  } test_cases[] = {
+      {
+          .src = "abc",
+          .num_repeats = 1,
+          .want = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+      },
+      {
+          .src = "abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq",
+          .num_repeats = 1,
+          .want = "248d6a61d20638b8e5c026930c3e6039a33ce45964ff2167f6ecedd419db06c1",
+      },
+      {
+          .src = "abcdefghbcdefghicdefghijdefghijkefghijklfghijklmghijklmn"
+                 "hijklmnoijklmnopjklmnopqklmnopqrlmnopqrsmnopqrstnopqrstu",
+          .num_repeats = 1,
+          .want = "cf5b16a778af8380036ce59e7b0492370b249b11e8f07a51afac45037afee9d1",
+      },
+      {
+          .src = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+                 "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+                 "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+                 "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+                 "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+          .num_repeats = 3125,  // 3125 * 320 is one million.
+          .want = "cdc76e5c9914fb9281a1c7e284d73e67f1809a48a497200e046d39ccc7112cd0",
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_sha256__hasher checksum;
+    CHECK_STATUS("initialize",
+                 wuffs_sha256__hasher__initialize(
+                     &checksum, sizeof checksum, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    size_t r;
+    for (r = 0; r < test_cases[tc].num_repeats; r++) {
+      wuffs_sha256__hasher__update(
+          &checksum, ((wuffs_base__slice_u8){
+                         .ptr = (uint8_t*)(test_cases[tc].src),
+                         .len = strlen(test_cases[tc].src),
+                     }));
+    }
+
+    char have[65];
+    CHECK_STRING(hex_checksum(have, &checksum));
+    if (strcmp(have, test_cases[tc].want)) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, have,
+                  test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_sha256_pi() {
+  CHECK_FOCUS(__func__);
+
+  const char* digits =
+      "3."
+      "141592653589793238462643383279502884197169399375105820974944592307816406"
+      "2862089986280348253421170";
+  if (strlen(digits) != 99) {
+    RETURN_FAIL("strlen(digits): have %d, want 99", (int)(strlen(digits)));
+  }
+
+  // The want values are determined by script/checksum.go.
+  //
+  // Each want is the checksum of the first length bytes of the digits string.
+  // The lengths straddle the 55, 56 and 64 byte boundaries where the final
+  // padding needs one or two blocks.
+  struct {
+    size_t length;
+    const char* want;
+  } test_cases[] = {
+      {0, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+      {1, "4e07408562bedb8b60ce05c1decfe3ad16b72230967de01f640b7e4729b49fce"},
+      {2, "267c23695b6e8fc29bd88b9f5d1962d3d208fa9c2fafd974614e6ab3b84fa55c"},
+      {55, "4b4450dc4dc1583d82f22502d37f5561b29d464603f6e8f0df47d72860c62910"},
+      {56, "860650b802184fd436e12d7a4ab1b08a8296939672321d1685badfafe321525e"},
+      {57, "78e5956d6bc700903ee9b1254d10432de83fed74e65b3edde94462c6b55c3ff6"},
+      {63, "72c73ed09101189b8f36dd3c25a09004efb503670b6b1cda2955aa4cfd0955b0"},
+      {64, "d76fbbe816ab81938f7191f537dc3023f465188486d12732e48c7dbf4c345ffa"},
+      {65, "a0da75b06f9f8ea69f34bc53a2beacef9ebb4d1e515ee18a78971ff1c5759434"},
+      {98, "385aba08c78a08fbc5c85e7fe228c34afbd01f222353bcfa3134aef31ea120b2"},
+      {99, "350a957ceea5aa9399e053b9784f853510f7ca989d8373ce28eeffb4f741e27d"},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_sha256__hasher checksum;
+    CHECK_STATUS("initialize",
+                 wuffs_sha256__hasher__initialize(
+                     &checksum, sizeof checksum, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_sha256__hasher__update(&checksum,
+                                 ((wuffs_base__slice_u8){
+                                     .ptr = (uint8_t*)(digits),
+                                     .len = test_cases[tc].length,
+                                 }));
+
+    char have[65];
+    CHECK_STRING(hex_checksum(have, &checksum));
+    if (strcmp(have, test_cases[tc].want)) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, have,
+                  test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_sha256_short_dst() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_sha256__hasher checksum;
+  CHECK_STATUS("initialize",
+               wuffs_sha256__hasher__initialize(
+                   &checksum, sizeof checksum, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_sha256__hasher__update(&checksum, ((wuffs_base__slice_u8){
+                                              .ptr = (uint8_t*)("abc"),
+                                              .len = 3,
+                                          }));
+
+  uint8_t have[5] = {0};
+  uint64_t n = wuffs_sha256__hasher__checksum(
+      &checksum, wuffs_base__make_slice_u8(have, sizeof have));
+  if (n != sizeof have) {
+    RETURN_FAIL("checksum: have %" PRIu64 ", want %d", n, (int)(sizeof have));
+  }
+  const uint8_t want[5] = {0xBA, 0x78, 0x16, 0xBF, 0x8F};
+  if (memcmp(have, want, sizeof have)) {
+    RETURN_FAIL("checksum: have 0x%02X%02X%02X%02X%02X, want 0xBA7816BF8F",
+                have[0], have[1], have[2], have[3], have[4]);
+  }
+  return NULL;
+}
+
+// ---------------- SHA256 Benches
+
+uint8_t g_wuffs_sha256_unused_checksum[32];
+
+const char*  //
+wuffs_bench_sha256(wuffs_base__io_buffer* dst,
+                   wuffs_base__io_buffer* src,
+                   uint32_t wuffs_initialize_flags,
+                   uint64_t wlimit,
+                   uint64_t rlimit) {
+  uint64_t len = src->meta.wi - src->meta.ri;
+  if (rlimit) {
+    len = wuffs_base__u64__min(len, rlimit);
+  }
+  wuffs_sha256__hasher checksum;
+  CHECK_STATUS("initialize", wuffs_sha256__hasher__initialize(
+                                 &checksum, sizeof checksum, WUFFS_VERSION,
+                                 wuffs_initialize_flags));
+  wuffs_sha256__hasher__update(&checksum,
+                               ((wuffs_base__slice_u8){
+                                   .ptr = src->data.ptr + src->meta.ri,
+                                   .len = len,
+                               }));
+  wuffs_sha256__hasher__checksum(
+      &checksum,
+      wuffs_base__make_slice_u8(g_wuffs_sha256_unused_checksum,
+                                sizeof g_wuffs_sha256_unused_checksum));
+  src->meta.ri += len;
+  return NULL;
+}
+
+const char*  //
+bench_wuffs_sha256_10k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_bench_sha256,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_src,
+      &g_sha256_midsummer_gt, UINT64_MAX, UINT64_MAX, 300);
+}
+
+const char*  //
+bench_wuffs_sha256_100k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_bench_sha256,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_src,
+      &g_sha256_pi_gt, UINT64_MAX, UINT64_MAX, 30);
+}
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_sha256_golden,
+    test_wuffs_sha256_nist,
+    test_wuffs_sha256_pi,
+    test_wuffs_sha256_short_dst,
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+    bench_wuffs_sha256_10k,
+    bench_wuffs_sha256_100k,
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/sha256";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -0,0 +1,672 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror snappy.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__CRC32
+#define WUFFS_CONFIG__MODULE__SNAPPY
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_snappy_midsummer_gt = {
+    .want_filename = "test/data/midsummer.txt",
+    .src_filename = "test/data/midsummer.txt.sz",
+};
+
+golden_test g_snappy_pi_gt = {
+    .want_filename = "test/data/pi.txt",
+    .src_filename = "test/data/pi.txt.sz",
+};
+
+golden_test g_snappy_pi_encode_gt = {
+    .src_filename = "test/data/pi.txt",
+};
+
+golden_test g_snappy_block_format_midsummer_gt = {
+    .want_filename = "test/data/midsummer.txt",
+    .src_filename = "test/data/midsummer.txt.snappy",
+};
+
+// ---------------- Snappy Tests
+
+const char*  //
+test_wuffs_snappy_decode_interface() {
+  CHECK_FOCUS(__func__);
+  wuffs_snappy__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_snappy__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  return do_test__wuffs_base__io_transformer(
+      wuffs_snappy__decoder__upcast_as__wuffs_base__io_transformer(&dec),
+      "test/data/romeo.txt.sz", 0, SIZE_MAX, 942, 0x0A);
+}
+
+const char*  //
+do_wuffs_snappy_decode(wuffs_base__io_buffer* dst,
+                       wuffs_base__io_buffer* src,
+                       uint32_t wuffs_initialize_flags,
+                       uint64_t wlimit,
+                       uint64_t rlimit,
+                       bool quirk_block_format) {
+  wuffs_snappy__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_snappy__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION, wuffs_initialize_flags));
+  wuffs_snappy__decoder__set_quirk_enabled(
+      &dec, WUFFS_SNAPPY__QUIRK_BLOCK_FORMAT, quirk_block_format);
+
+  while (true) {
+    wuffs_base__io_buffer limited_dst = make_limited_writer(*dst, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+
+    wuffs_base__status status = wuffs_snappy__decoder__transform_io(
+        &dec, &limited_dst, &limited_src, g_work_slice_u8);
+
+    dst->meta.wi += limited_dst.meta.wi;
+    src->meta.ri += limited_src.meta.ri;
+
+    if (((wlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_write)) ||
+        ((rlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_read))) {
+      continue;
+    }
+    return status.repr;
+  }
+}
+
+const char*  //
+wuffs_snappy_decode(wuffs_base__io_buffer* dst,
+                    wuffs_base__io_buffer* src,
+                    uint32_t wuffs_initialize_flags,
+                    uint64_t wlimit,
+                    uint64_t rlimit) {
+  return do_wuffs_snappy_decode(dst, src, wuffs_initialize_flags, wlimit,
+                                rlimit, false);
+}
+
+const char*  //
+wuffs_snappy_decode_block_format(wuffs_base__io_buffer* dst,
+                                 wuffs_base__io_buffer* src,
+                                 uint32_t wuffs_initialize_flags,
+                                 uint64_t wlimit,
+                                 uint64_t rlimit) {
+  return do_wuffs_snappy_decode(dst, src, wuffs_initialize_flags, wlimit,
+                                rlimit, true);
+}
+
+const char*  //
+do_test_wuffs_snappy_checksum(bool ignore_checksum, bool bad_checksum) {
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+
+  CHECK_STRING(read_file(&src, g_snappy_midsummer_gt.src_filename));
+  // Flip a bit in the first data chunk's checksum, which immediately follows
+  // the 10 byte stream identifier chunk and that data chunk's 4 byte header.
+  if (src.meta.wi < 18) {
+    RETURN_FAIL("source file was too short");
+  }
+  if (bad_checksum) {
+    src.data.ptr[14] ^= 1;
+  }
+
+  wuffs_snappy__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_snappy__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_snappy__decoder__set_quirk_enabled(
+      &dec, WUFFS_BASE__QUIRK_IGNORE_CHECKSUM, ignore_checksum);
+
+  wuffs_base__status have_z =
+      wuffs_snappy__decoder__transform_io(&dec, &have, &src, g_work_slice_u8);
+  const char* want_z = (bad_checksum && !ignore_checksum)
+                           ? wuffs_snappy__error__bad_checksum
+                           : NULL;
+  if (have_z.repr != want_z) {
+    RETURN_FAIL("have \"%s\", want \"%s\"", have_z.repr, want_z);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_snappy_checksum_ignore() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_snappy_checksum(true, true);
+}
+
+const char*  //
+test_wuffs_snappy_checksum_verify_bad() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_snappy_checksum(false, true);
+}
+
+const char*  //
+test_wuffs_snappy_checksum_verify_good() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_snappy_checksum(false, false);
+}
+
+const char*  //
+test_wuffs_snappy_decode_artificial() {
+  CHECK_FOCUS(__func__);
+
+  // The "hellohello" block is a 5 byte literal and then a 5 byte copy (with a
+  // 2 byte offset) at offset 5.
+  struct {
+    const char* src_ptr;
+    size_t src_len;
+    const char* want_ptr;
+    size_t want_len;
+    const char* want_status;
+    bool quirk_block_format;
+  } test_cases[] = {
+      {
+          .src_ptr = "",
+          .src_len = 0,
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = wuffs_snappy__error__bad_header,
+      },
+      {
+          .src_ptr = "\xFF\x06\x00\x00sNaPpY",
+          .src_len = 10,
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = NULL,
+      },
+      {
+          // An uncompressed data chunk.
+          .src_ptr = "\xFF\x06\x00\x00sNaPpY"
+                     "\x01\x09\x00\x00\xBB\x1F\x1C\x19hello",
+          .src_len = 23,
+          .want_ptr = "hello",
+          .want_len = 5,
+          .want_status = NULL,
+      },
+      {
+          // The same, without the stream identifier.
+          .src_ptr = "\x01\x09\x00\x00\xBB\x1F\x1C\x19hello",
+          .src_len = 13,
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = wuffs_snappy__error__bad_header,
+      },
+      {
+          // A padding chunk and a compressed data chunk.
+          .src_ptr = "\xFF\x06\x00\x00sNaPpY"
+                     "\xFE\x02\x00\x00\x00\x00"
+                     "\x00\x0E\x00\x00\xF9\xE4\x88\xBF"
+                     "\x0A\x10hello\x12\x05\x00",
+          .src_len = 34,
+          .want_ptr = "hellohello",
+          .want_len = 10,
+          .want_status = NULL,
+      },
+      {
+          // The same, with a bad checksum.
+          .src_ptr = "\xFF\x06\x00\x00sNaPpY"
+                     "\xFE\x02\x00\x00\x00\x00"
+                     "\x00\x0E\x00\x00\xF8\xE4\x88\xBF"
+                     "\x0A\x10hello\x12\x05\x00",
+          .src_len = 34,
+          .want_ptr = "hellohello",
+          .want_len = 10,
+          .want_status = wuffs_snappy__error__bad_checksum,
+      },
+      {
+          // The same, with a chunk length that is one too long.
+          .src_ptr = "\xFF\x06\x00\x00sNaPpY"
+                     "\x00\x0F\x00\x00\xF9\xE4\x88\xBF"
+                     "\x0A\x10hello\x12\x05\x00\x00",
+          .src_len = 29,
+          .want_ptr = "hellohello",
+          .want_len = 10,
+          .want_status = wuffs_snappy__error__bad_chunk_length,
+      },
+      {
+          // A reserved unskippable chunk type.
+          .src_ptr = "\xFF\x06\x00\x00sNaPpY"
+                     "\x02\x00\x00\x00",
+          .src_len = 14,
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = wuffs_snappy__error__bad_chunk_type,
+      },
+      {
+          .src_ptr = "\x0A\x10hello\x12\x05\x00",
+          .src_len = 10,
+          .want_ptr = "hellohello",
+          .want_len = 10,
+          .want_status = NULL,
+          .quirk_block_format = true,
+      },
+      {
+          // A copy offset that reaches back before the start of the block.
+          .src_ptr = "\x0A\x10hello\x12\x06\x00",
+          .src_len = 10,
+          .want_ptr = "hello",
+          .want_len = 5,
+          .want_status = wuffs_snappy__error__bad_copy_offset,
+          .quirk_block_format = true,
+      },
+      {
+          // A copy that goes beyond the uncompressed length of 6.
+          .src_ptr = "\x06\x10hello\x12\x05\x00",
+          .src_len = 10,
+          .want_ptr = "hello",
+          .want_len = 5,
+          .want_status = wuffs_snappy__error__bad_uncompressed_length,
+          .quirk_block_format = true,
+      },
+      {
+          // An uncompressed length varint longer than 5 bytes.
+          .src_ptr = "\x80\x80\x80\x80\x80\x00",
+          .src_len = 6,
+          .want_ptr = "",
+          .want_len = 0,
+          .want_status = wuffs_snappy__error__bad_uncompressed_length,
+          .quirk_block_format = true,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+        .data = g_have_slice_u8,
+    });
+    wuffs_base__io_buffer src = make_io_buffer_from_string(
+        test_cases[tc].src_ptr, test_cases[tc].src_len);
+    const char* status = do_wuffs_snappy_decode(
+        &have, &src, WUFFS_INITIALIZE__DEFAULT_OPTIONS, UINT64_MAX, UINT64_MAX,
+        test_cases[tc].quirk_block_format);
+    if (status != test_cases[tc].want_status) {
+      RETURN_FAIL("tc=%d: status: have \"%s\", want \"%s\"", tc, status,
+                  test_cases[tc].want_status);
+    }
+    wuffs_base__io_buffer want = make_io_buffer_from_string(
+        test_cases[tc].want_ptr, test_cases[tc].want_len);
+    CHECK_STRING(check_io_buffers_equal("", &have, &want));
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_snappy_decode_block_format_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_snappy_decode_block_format,
+                            &g_snappy_block_format_midsummer_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_snappy_decode_block_format_midsummer_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_snappy_decode_block_format,
+                            &g_snappy_block_format_midsummer_gt, 59, 61);
+}
+
+const char*  //
+test_wuffs_snappy_decode_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_snappy_decode, &g_snappy_midsummer_gt,
+                            UINT64_MAX, UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_snappy_decode_pi_just_one_read() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_snappy_decode, &g_snappy_pi_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_snappy_decode_pi_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_snappy_decode, &g_snappy_pi_gt, 59, 61);
+}
+
+// ---------------- Snappy Encoder Tests
+
+const char*  //
+test_wuffs_snappy_encode_interface() {
+  CHECK_FOCUS(__func__);
+  wuffs_snappy__encoder enc;
+  CHECK_STATUS("initialize",
+               wuffs_snappy__encoder__initialize(
+                   &enc, sizeof enc, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  return do_test__wuffs_base__io_transformer(
+      wuffs_snappy__encoder__upcast_as__wuffs_base__io_transformer(&enc),
+      "test/data/romeo.txt", 0, SIZE_MAX, 769, 0x0A);
+}
+
+// do_test_wuffs_snappy_encode_round_trip encodes src and then decodes the
+// result, which should reproduce src.
+const char*  //
+do_test_wuffs_snappy_encode_round_trip(wuffs_base__io_buffer src,
+                                       uint64_t wlimit,
+                                       uint64_t rlimit,
+                                       bool quirk_block_format) {
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+
+  wuffs_snappy__encoder enc;
+  CHECK_STATUS("initialize",
+               wuffs_snappy__encoder__initialize(
+                   &enc, sizeof enc, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_snappy__encoder__set_quirk_enabled(
+      &enc, WUFFS_SNAPPY__QUIRK_BLOCK_FORMAT, quirk_block_format);
+  int num_iters = 0;
+  while (true) {
+    num_iters++;
+    wuffs_base__io_buffer limited_have = make_limited_writer(have, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(src, rlimit);
+
+    wuffs_base__status status = wuffs_snappy__encoder__transform_io(
+        &enc, &limited_have, &limited_src, g_work_slice_u8);
+    have.meta.wi += limited_have.meta.wi;
+    src.meta.ri += limited_src.meta.ri;
+    if (wuffs_base__status__is_ok(&status)) {
+      break;
+    }
+    if ((status.repr != wuffs_base__suspension__short_read) &&
+        (status.repr != wuffs_base__suspension__short_write)) {
+      RETURN_FAIL("encode: have \"%s\", want \"%s\" or \"%s\"", status.repr,
+                  wuffs_base__suspension__short_read,
+                  wuffs_base__suspension__short_write);
+    }
+  }
+  if (src.meta.ri != src.meta.wi) {
+    RETURN_FAIL("encode: src was not exhausted");
+  }
+  if ((wlimit < UINT64_MAX) || (rlimit < UINT64_MAX)) {
+    if (num_iters <= 1) {
+      RETURN_FAIL("num_iters: have %d, want > 1", num_iters);
+    }
+  } else if (num_iters != 1) {
+    RETURN_FAIL("num_iters: have %d, want 1", num_iters);
+  }
+  have.meta.closed = true;
+
+  wuffs_snappy__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_snappy__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_snappy__decoder__set_quirk_enabled(
+      &dec, WUFFS_SNAPPY__QUIRK_BLOCK_FORMAT, quirk_block_format);
+  CHECK_STATUS("decode", wuffs_snappy__decoder__transform_io(
+                             &dec, &want, &have, g_work_slice_u8));
+  if (have.meta.ri != have.meta.wi) {
+    RETURN_FAIL("decode: have %d unread bytes, want 0",
+                (int)(have.meta.wi - have.meta.ri));
+  }
+
+  return check_io_buffers_equal("", &want, &src);
+}
+
+const char*  //
+do_test_wuffs_snappy_encode_round_trip_file(const char* src_filename,
+                                            uint64_t wlimit,
+                                            uint64_t rlimit,
+                                            bool quirk_block_format) {
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, src_filename));
+  return do_test_wuffs_snappy_encode_round_trip(src, wlimit, rlimit,
+                                                quirk_block_format);
+}
+
+const char*  //
+test_wuffs_snappy_encode_round_trip_block_format_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_snappy_encode_round_trip_file(
+      "test/data/midsummer.txt", UINT64_MAX, UINT64_MAX, true);
+}
+
+const char*  //
+test_wuffs_snappy_encode_round_trip_block_format_pi_many_small_writes() {
+  CHECK_FOCUS(__func__);
+  // The block format's uncompressed length comes first, so the encoder needs
+  // a closed source. Only limit the writes, not the reads.
+  return do_test_wuffs_snappy_encode_round_trip_file("test/data/pi.txt", 59,
+                                                     UINT64_MAX, true);
+}
+
+const char*  //
+test_wuffs_snappy_encode_round_trip_harvesters_jpeg() {
+  CHECK_FOCUS(__func__);
+  // JPEG is already compressed, so this exercises multiple uncompressed
+  // chunks.
+  return do_test_wuffs_snappy_encode_round_trip_file(
+      "test/data/harvesters.jpeg", UINT64_MAX, UINT64_MAX, false);
+}
+
+const char*  //
+test_wuffs_snappy_encode_round_trip_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_snappy_encode_round_trip_file(
+      "test/data/midsummer.txt", UINT64_MAX, UINT64_MAX, false);
+}
+
+const char*  //
+test_wuffs_snappy_encode_round_trip_pi_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_snappy_encode_round_trip_file("test/data/pi.txt", 59,
+                                                     61, false);
+}
+
+const char*  //
+test_wuffs_snappy_encode_golden() {
+  CHECK_FOCUS(__func__);
+  // "abcd" repeated 4 times is a 4 byte literal and then a 12 byte copy (with
+  // a 2 byte offset, as 12 is too long for a 1 byte offset copy).
+  static const char* src_str = "abcdabcdabcdabcd";
+  static const uint8_t want_bytes[] = {
+      0x10, 0x0C, 0x61, 0x62, 0x63, 0x64, 0x2E, 0x04, 0x00,
+  };
+
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer src =
+      wuffs_base__ptr_u8__reader((uint8_t*)src_str, strlen(src_str), true);
+  wuffs_base__io_buffer want = wuffs_base__ptr_u8__reader(
+      (uint8_t*)want_bytes, WUFFS_TESTLIB_ARRAY_SIZE(want_bytes), true);
+
+  wuffs_snappy__encoder enc;
+  CHECK_STATUS("initialize",
+               wuffs_snappy__encoder__initialize(
+                   &enc, sizeof enc, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_snappy__encoder__set_quirk_enabled(
+      &enc, WUFFS_SNAPPY__QUIRK_BLOCK_FORMAT, true);
+  CHECK_STATUS("transform_io", wuffs_snappy__encoder__transform_io(
+                                   &enc, &have, &src, g_work_slice_u8));
+  return check_io_buffers_equal("", &have, &want);
+}
+
+const char*  //
+test_wuffs_snappy_encode_empty() {
+  CHECK_FOCUS(__func__);
+  // The framing format's output is just the stream identifier chunk.
+  static const uint8_t want_bytes[] = {
+      0xFF, 0x06, 0x00, 0x00, 0x73, 0x4E, 0x61, 0x50, 0x70, 0x59,
+  };
+
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer src =
+      wuffs_base__ptr_u8__reader(g_src_slice_u8.ptr, 0, true);
+  wuffs_base__io_buffer want = wuffs_base__ptr_u8__reader(
+      (uint8_t*)want_bytes, WUFFS_TESTLIB_ARRAY_SIZE(want_bytes), true);
+
+  wuffs_snappy__encoder enc;
+  CHECK_STATUS("initialize",
+               wuffs_snappy__encoder__initialize(
+                   &enc, sizeof enc, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  CHECK_STATUS("transform_io", wuffs_snappy__encoder__transform_io(
+                                   &enc, &have, &src, g_work_slice_u8));
+  return check_io_buffers_equal("", &have, &want);
+}
+
+// ---------------- Snappy Benches
+
+const char*  //
+bench_wuffs_snappy_decode_10k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_snappy_decode,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_dst,
+      &g_snappy_midsummer_gt, UINT64_MAX, UINT64_MAX, 300);
+}
+
+const char*  //
+bench_wuffs_snappy_decode_100k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_snappy_decode,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_dst,
+      &g_snappy_pi_gt, UINT64_MAX, UINT64_MAX, 30);
+}
+
+const char*  //
+wuffs_snappy_encode(wuffs_base__io_buffer* dst,
+                    wuffs_base__io_buffer* src,
+                    uint32_t wuffs_initialize_flags,
+                    uint64_t wlimit,
+                    uint64_t rlimit) {
+  wuffs_snappy__encoder enc;
+  CHECK_STATUS("initialize",
+               wuffs_snappy__encoder__initialize(
+                   &enc, sizeof enc, WUFFS_VERSION, wuffs_initialize_flags));
+  CHECK_STATUS("transform_io", wuffs_snappy__encoder__transform_io(
+                                   &enc, dst, src, g_work_slice_u8));
+  return NULL;
+}
+
+const char*  //
+bench_wuffs_snappy_encode_100k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_snappy_encode,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_src,
+      &g_snappy_pi_encode_gt, UINT64_MAX, UINT64_MAX, 30);
+}
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_snappy_checksum_ignore,
+    test_wuffs_snappy_checksum_verify_bad,
+    test_wuffs_snappy_checksum_verify_good,
+    test_wuffs_snappy_decode_artificial,
+    test_wuffs_snappy_decode_block_format_midsummer,
+    test_wuffs_snappy_decode_block_format_midsummer_many_small_writes_reads,
+    test_wuffs_snappy_decode_interface,
+    test_wuffs_snappy_decode_midsummer,
+    test_wuffs_snappy_decode_pi_just_one_read,
+    test_wuffs_snappy_decode_pi_many_small_writes_reads,
+    test_wuffs_snappy_encode_empty,
+    test_wuffs_snappy_encode_golden,
+    test_wuffs_snappy_encode_interface,
+    test_wuffs_snappy_encode_round_trip_block_format_midsummer,
+    test_wuffs_snappy_encode_round_trip_block_format_pi_many_small_writes,
+    test_wuffs_snappy_encode_round_trip_harvesters_jpeg,
+    test_wuffs_snappy_encode_round_trip_midsummer,
+    test_wuffs_snappy_encode_round_trip_pi_many_small_writes_reads,
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+    bench_wuffs_snappy_decode_10k,
+    bench_wuffs_snappy_decode_100k,
+    bench_wuffs_snappy_encode_100k,
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/snappy";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -0,0 +1,288 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror tar.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__TAR
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+
+// ---------------- Tar Tests
+
+const char*  //
+test_wuffs_tar_decode_entries() {
+  CHECK_FOCUS(__func__);
+  wuffs_tar__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_tar__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/romeo-and-midsummer.tar"));
+
+  const char* midsummer_dir = "a-midsummer-nights-dream/";
+  struct {
+    size_t name_length;
+    uint32_t mode;
+    uint8_t type;
+    uint64_t size;
+    uint64_t body_io_position;
+  } wants[] = {
+      {12, 0755, WUFFS_TAR__ENTRY_TYPE__DIRECTORY, 0, 0x0200},
+      {21, 0644, WUFFS_TAR__ENTRY_TYPE__REGULAR_FILE, 942, 0x0400},
+      {125, 0644, WUFFS_TAR__ENTRY_TYPE__REGULAR_FILE, 11065, 0x0A00},
+      {300, 0600, WUFFS_TAR__ENTRY_TYPE__REGULAR_FILE, 11065, 0x3C00},
+  };
+
+  size_t i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(wants); i++) {
+    CHECK_STATUS("decode_entry",
+                 wuffs_tar__decoder__decode_entry(&dec, &src));
+
+    wuffs_base__slice_u8 name = wuffs_tar__decoder__entry_name(&dec);
+    if (name.len != wants[i].name_length) {
+      RETURN_FAIL("i=%zu: name length: have %zu, want %zu", i, name.len,
+                  wants[i].name_length);
+    } else if (strncmp((const char*)(name.ptr), "shakespeare/", 12)) {
+      RETURN_FAIL("i=%zu: name: have \"%.*s\", want a \"shakespeare/\" prefix",
+                  i, (int)(name.len), name.ptr);
+    } else if ((i >= 2) &&
+               strncmp((const char*)(name.ptr + 12), midsummer_dir,
+                       strlen(midsummer_dir))) {
+      RETURN_FAIL("i=%zu: name: have \"%.*s\", want a \"%s\" sub-directory", i,
+                  (int)(name.len), name.ptr, midsummer_dir);
+    }
+
+    uint32_t have_mode = wuffs_tar__decoder__entry_mode(&dec);
+    if (have_mode != wants[i].mode) {
+      RETURN_FAIL("i=%zu: mode: have 0%o, want 0%o", i, have_mode,
+                  wants[i].mode);
+    }
+    uint8_t have_type = wuffs_tar__decoder__entry_type(&dec);
+    if (have_type != wants[i].type) {
+      RETURN_FAIL("i=%zu: type: have 0x%02X, want 0x%02X", i, have_type,
+                  wants[i].type);
+    }
+    uint64_t have_size = wuffs_tar__decoder__entry_size(&dec);
+    if (have_size != wants[i].size) {
+      RETURN_FAIL("i=%zu: size: have %" PRIu64 ", want %" PRIu64, i, have_size,
+                  wants[i].size);
+    }
+    uint64_t have_pos = wuffs_tar__decoder__body_io_position(&dec);
+    if (have_pos != wants[i].body_io_position) {
+      RETURN_FAIL("i=%zu: body_io_position: have 0x%" PRIX64
+                  ", want 0x%" PRIX64,
+                  i, have_pos, wants[i].body_io_position);
+    }
+  }
+
+  wuffs_base__status status = wuffs_tar__decoder__decode_entry(&dec, &src);
+  if (status.repr != wuffs_base__note__end_of_data) {
+    RETURN_FAIL("decode_entry: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__note__end_of_data);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_tar_decode_body() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  CHECK_STRING(read_file(&want, "test/data/midsummer.txt"));
+
+  // Decode the body of the last entry, skipping over the other entries. The
+  // src and dst buffers are limited, so that the decoder has to suspend (and
+  // resume) many times.
+  int tc;
+  for (tc = 0; tc < 2; tc++) {
+    wuffs_tar__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_tar__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+        .data = g_src_slice_u8,
+    });
+    CHECK_STRING(read_file(&src, "test/data/romeo-and-midsummer.tar"));
+    wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+        .data = g_have_slice_u8,
+    });
+
+    int num_entries = 0;
+    while (true) {
+      wuffs_base__io_buffer limited_src = make_limited_reader(src, 333);
+      wuffs_base__status status =
+          wuffs_tar__decoder__decode_entry(&dec, &limited_src);
+      src.meta.ri += limited_src.meta.ri;
+      if (status.repr == wuffs_base__suspension__short_read) {
+        continue;
+      }
+      CHECK_STATUS("decode_entry", status);
+      num_entries++;
+      if (num_entries == 4) {
+        break;
+      }
+      // For the second entry, consume some of the body directly from src.
+      if ((tc == 1) && (num_entries == 2)) {
+        src.meta.ri += 100;
+      }
+    }
+
+    while (true) {
+      wuffs_base__io_buffer limited_src = make_limited_reader(src, 333);
+      wuffs_base__io_buffer limited_have = make_limited_writer(have, 444);
+      wuffs_base__status status =
+          wuffs_tar__decoder__decode_body(&dec, &limited_have, &limited_src);
+      src.meta.ri += limited_src.meta.ri;
+      have.meta.wi += limited_have.meta.wi;
+      if ((status.repr == wuffs_base__suspension__short_read) ||
+          (status.repr == wuffs_base__suspension__short_write)) {
+        continue;
+      }
+      CHECK_STATUS("decode_body", status);
+      break;
+    }
+    CHECK_STRING(check_io_buffers_equal("", &have, &want));
+
+    wuffs_base__status status = wuffs_tar__decoder__decode_entry(&dec, &src);
+    if (status.repr != wuffs_base__note__end_of_data) {
+      RETURN_FAIL("tc=%d: decode_entry: have \"%s\", want \"%s\"", tc,
+                  status.repr, wuffs_base__note__end_of_data);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_tar_decode_bad_checksum() {
+  CHECK_FOCUS(__func__);
+  wuffs_tar__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_tar__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/romeo-and-midsummer.tar"));
+  // Change the first entry's mode from 0755 to 0757.
+  src.data.ptr[0x6A] = '7';
+
+  wuffs_base__status status = wuffs_tar__decoder__decode_entry(&dec, &src);
+  if (status.repr != wuffs_tar__error__bad_checksum) {
+    RETURN_FAIL("decode_entry: have \"%s\", want \"%s\"", status.repr,
+                wuffs_tar__error__bad_checksum);
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_tar_decode_bad_checksum,
+    test_wuffs_tar_decode_body,
+    test_wuffs_tar_decode_entries,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No tar benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/tar";
+  return test_main(argc, argv, g_tests, g_benches);
+}
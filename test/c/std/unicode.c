@@ -0,0 +1,411 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror unicode.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__UNICODE
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_unicode_utf_16le_midsummer_gt = {
+    .want_filename = "test/data/midsummer.txt",
+    .src_filename = "test/data/midsummer.txt.utf-16le",
+};
+
+golden_test g_unicode_utf_16le_unicode_things_gt = {
+    .want_filename = "test/data/unicode-things.txt",
+    .src_filename = "test/data/unicode-things.txt.utf-16le",
+};
+
+golden_test g_unicode_utf_16be_unicode_things_gt = {
+    .want_filename = "test/data/unicode-things.txt",
+    .src_filename = "test/data/unicode-things.txt.utf-16be",
+};
+
+// ---------------- Unicode Tests
+
+const char*  //
+test_wuffs_unicode_utf_16_decode_interface() {
+  CHECK_FOCUS(__func__);
+  wuffs_unicode__utf_16_decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_unicode__utf_16_decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  return do_test__wuffs_base__io_transformer(
+      wuffs_unicode__utf_16_decoder__upcast_as__wuffs_base__io_transformer(
+          &dec),
+      "test/data/midsummer.txt.utf-16le", 0, SIZE_MAX, 11065, 0x0A);
+}
+
+// do_wuffs_unicode_utf_16_decode runs a utf_16_decoder with the non-zero
+// quirks enabled.
+const char*  //
+do_wuffs_unicode_utf_16_decode(wuffs_base__io_buffer* dst,
+                               wuffs_base__io_buffer* src,
+                               uint32_t wuffs_initialize_flags,
+                               uint64_t wlimit,
+                               uint64_t rlimit,
+                               const uint32_t* quirks) {
+  wuffs_unicode__utf_16_decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_unicode__utf_16_decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION, wuffs_initialize_flags));
+  int i;
+  for (i = 0; i < 3; i++) {
+    if (quirks[i]) {
+      wuffs_unicode__utf_16_decoder__set_quirk_enabled(&dec, quirks[i], true);
+    }
+  }
+
+  while (true) {
+    wuffs_base__io_buffer limited_dst = make_limited_writer(*dst, wlimit);
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+
+    wuffs_base__status status = wuffs_unicode__utf_16_decoder__transform_io(
+        &dec, &limited_dst, &limited_src, g_work_slice_u8);
+
+    dst->meta.wi += limited_dst.meta.wi;
+    src->meta.ri += limited_src.meta.ri;
+
+    if (((wlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_write)) ||
+        ((rlimit < UINT64_MAX) &&
+         (status.repr == wuffs_base__suspension__short_read))) {
+      continue;
+    }
+    return status.repr;
+  }
+}
+
+const uint32_t g_unicode_no_quirks[3] = {0};
+
+const uint32_t g_unicode_big_endian_quirks[3] = {
+    WUFFS_UNICODE__QUIRK_BIG_ENDIAN,
+};
+
+const char*  //
+wuffs_unicode_utf_16le_decode(wuffs_base__io_buffer* dst,
+                              wuffs_base__io_buffer* src,
+                              uint32_t wuffs_initialize_flags,
+                              uint64_t wlimit,
+                              uint64_t rlimit) {
+  return do_wuffs_unicode_utf_16_decode(dst, src, wuffs_initialize_flags,
+                                        wlimit, rlimit, g_unicode_no_quirks);
+}
+
+const char*  //
+wuffs_unicode_utf_16be_decode(wuffs_base__io_buffer* dst,
+                              wuffs_base__io_buffer* src,
+                              uint32_t wuffs_initialize_flags,
+                              uint64_t wlimit,
+                              uint64_t rlimit) {
+  return do_wuffs_unicode_utf_16_decode(dst, src, wuffs_initialize_flags,
+                                        wlimit, rlimit,
+                                        g_unicode_big_endian_quirks);
+}
+
+const char*  //
+test_wuffs_unicode_utf_16_decode_artificial() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* src_ptr;
+    size_t src_len;
+    const char* want_ptr;
+    size_t want_len;
+    size_t want_ri;
+    const char* want_status;
+    uint32_t quirks[3];
+  } test_cases[] = {
+      {
+          .src_ptr = "H\x00i\x00",
+          .src_len = 4,
+          .want_ptr = "Hi",
+          .want_len = 2,
+          .want_ri = 4,
+      },
+      {
+          // U+00E9, U+20AC and U+1D11E (a surrogate pair).
+          .src_ptr = "\xE9\x00\xAC\x20\x34\xD8\x1E\xDD",
+          .src_len = 8,
+          .want_ptr = "\xC3\xA9\xE2\x82\xAC\xF0\x9D\x84\x9E",
+          .want_len = 9,
+          .want_ri = 8,
+      },
+      {
+          .src_ptr = "\x00\xE9\x20\xAC\xD8\x34\xDD\x1E",
+          .src_len = 8,
+          .want_ptr = "\xC3\xA9\xE2\x82\xAC\xF0\x9D\x84\x9E",
+          .want_len = 9,
+          .want_ri = 8,
+          .quirks = {WUFFS_UNICODE__QUIRK_BIG_ENDIAN},
+      },
+      {
+          // Without QUIRK_DETECT_BYTE_ORDER_MARK, a BOM is just U+FEFF.
+          .src_ptr = "\xFF\xFEH\x00",
+          .src_len = 4,
+          .want_ptr = "\xEF\xBB\xBFH",
+          .want_len = 4,
+          .want_ri = 4,
+      },
+      {
+          .src_ptr = "\xFF\xFEH\x00",
+          .src_len = 4,
+          .want_ptr = "H",
+          .want_len = 1,
+          .want_ri = 4,
+          .quirks = {WUFFS_UNICODE__QUIRK_DETECT_BYTE_ORDER_MARK},
+      },
+      {
+          // The BOM's byte order overrides QUIRK_BIG_ENDIAN.
+          .src_ptr = "\xFE\xFF\x00H",
+          .src_len = 4,
+          .want_ptr = "H",
+          .want_len = 1,
+          .want_ri = 4,
+          .quirks = {WUFFS_UNICODE__QUIRK_DETECT_BYTE_ORDER_MARK},
+      },
+      {
+          // An unpaired low surrogate.
+          .src_ptr = "H\x00\x1E\xDDi\x00",
+          .src_len = 6,
+          .want_ptr = "H",
+          .want_len = 1,
+          .want_ri = 4,
+          .want_status = wuffs_unicode__error__bad_utf_16_surrogate,
+      },
+      {
+          // An unpaired high surrogate.
+          .src_ptr = "H\x00\x34\xD8i\x00",
+          .src_len = 6,
+          .want_ptr = "H",
+          .want_len = 1,
+          .want_ri = 6,
+          .want_status = wuffs_unicode__error__bad_utf_16_surrogate,
+      },
+      {
+          .src_ptr = "H\x00\x34\xD8",
+          .src_len = 4,
+          .want_ptr = "H",
+          .want_len = 1,
+          .want_ri = 4,
+          .want_status = wuffs_unicode__error__truncated_input,
+      },
+      {
+          .src_ptr = "H\x00i",
+          .src_len = 3,
+          .want_ptr = "H",
+          .want_len = 1,
+          .want_ri = 3,
+          .want_status = wuffs_unicode__error__truncated_input,
+      },
+      {
+          .src_ptr = "\x1E\xDD\x34\xD8\x34\xD8\x1E\xDDi",
+          .src_len = 9,
+          .want_ptr = "\xEF\xBF\xBD\xEF\xBF\xBD\xF0\x9D\x84\x9E\xEF\xBF\xBD",
+          .want_len = 13,
+          .want_ri = 9,
+          .quirks = {WUFFS_UNICODE__QUIRK_REPLACE_INVALID_UNICODE},
+      },
+      {
+          .src_ptr = "H\x00\x34\xD8",
+          .src_len = 4,
+          .want_ptr = "H\xEF\xBF\xBD",
+          .want_len = 4,
+          .want_ri = 4,
+          .quirks = {WUFFS_UNICODE__QUIRK_REPLACE_INVALID_UNICODE},
+      },
+      {
+          .src_ptr = "H\x00\xAC\x20\x34\xD8\x1E\xDD",
+          .src_len = 8,
+          .want_ptr = "",
+          .want_len = 0,
+          .want_ri = 8,
+          .quirks = {WUFFS_UNICODE__QUIRK_VALIDATE_ONLY},
+      },
+      {
+          .src_ptr = "H\x00\xAC\x20\x1E\xDD\x34\xD8",
+          .src_len = 8,
+          .want_ptr = "",
+          .want_len = 0,
+          .want_ri = 6,
+          .want_status = wuffs_unicode__error__bad_utf_16_surrogate,
+          .quirks = {WUFFS_UNICODE__QUIRK_VALIDATE_ONLY},
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    int lc;
+    for (lc = 0; lc < 2; lc++) {
+      uint64_t limit = lc ? 1 : UINT64_MAX;
+      wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+          .data = g_have_slice_u8,
+      });
+      wuffs_base__io_buffer src = make_io_buffer_from_string(
+          test_cases[tc].src_ptr, test_cases[tc].src_len);
+      const char* status = do_wuffs_unicode_utf_16_decode(
+          &have, &src, WUFFS_INITIALIZE__DEFAULT_OPTIONS, limit, limit,
+          test_cases[tc].quirks);
+      if (status != test_cases[tc].want_status) {
+        RETURN_FAIL("tc=%d, lc=%d: status: have \"%s\", want \"%s\"", tc, lc,
+                    status, test_cases[tc].want_status);
+      } else if (src.meta.ri != test_cases[tc].want_ri) {
+        RETURN_FAIL("tc=%d, lc=%d: ri: have %zu, want %zu", tc, lc,
+                    src.meta.ri, test_cases[tc].want_ri);
+      }
+      wuffs_base__io_buffer want = make_io_buffer_from_string(
+          test_cases[tc].want_ptr, test_cases[tc].want_len);
+      CHECK_STRING(check_io_buffers_equal("", &have, &want));
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_unicode_utf_16be_decode_unicode_things() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_unicode_utf_16be_decode,
+                            &g_unicode_utf_16be_unicode_things_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_unicode_utf_16be_decode_unicode_things_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_unicode_utf_16be_decode,
+                            &g_unicode_utf_16be_unicode_things_gt, 5, 7);
+}
+
+const char*  //
+test_wuffs_unicode_utf_16le_decode_midsummer() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_unicode_utf_16le_decode,
+                            &g_unicode_utf_16le_midsummer_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_unicode_utf_16le_decode_midsummer_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_unicode_utf_16le_decode,
+                            &g_unicode_utf_16le_midsummer_gt, 59, 61);
+}
+
+const char*  //
+test_wuffs_unicode_utf_16le_decode_unicode_things() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_unicode_utf_16le_decode,
+                            &g_unicode_utf_16le_unicode_things_gt, UINT64_MAX,
+                            UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_unicode_utf_16le_decode_unicode_things_many_small_writes_reads() {
+  CHECK_FOCUS(__func__);
+  return do_test_io_buffers(wuffs_unicode_utf_16le_decode,
+                            &g_unicode_utf_16le_unicode_things_gt, 5, 7);
+}
+
+// ---------------- Unicode Benches
+
+const char*  //
+bench_wuffs_unicode_utf_16le_decode_10k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_unicode_utf_16le_decode,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_dst,
+      &g_unicode_utf_16le_midsummer_gt, UINT64_MAX, UINT64_MAX, 300);
+}
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_unicode_utf_16_decode_artificial,
+    test_wuffs_unicode_utf_16_decode_interface,
+    test_wuffs_unicode_utf_16be_decode_unicode_things,
+    test_wuffs_unicode_utf_16be_decode_unicode_things_many_small_writes_reads,
+    test_wuffs_unicode_utf_16le_decode_midsummer,
+    test_wuffs_unicode_utf_16le_decode_midsummer_many_small_writes_reads,
+    test_wuffs_unicode_utf_16le_decode_unicode_things,
+    test_wuffs_unicode_utf_16le_decode_unicode_things_many_small_writes_reads,
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+    bench_wuffs_unicode_utf_16le_decode_10k,
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/unicode";
+  return test_main(argc, argv, g_tests, g_benches);
+}
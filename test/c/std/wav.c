@@ -0,0 +1,382 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror wav.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__WAV
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+
+// ---------------- WAV Tests
+
+// wav_append_u16le and friends append to b, which has a capacity of (at
+// least) 256 bytes.
+static void  //
+wav_append_u16le(wuffs_base__io_buffer* b, uint16_t x) {
+  wuffs_base__poke_u16le__no_bounds_check(b->data.ptr + b->meta.wi, x);
+  b->meta.wi += 2;
+}
+
+static void  //
+wav_append_u32le(wuffs_base__io_buffer* b, uint32_t x) {
+  wuffs_base__poke_u32le__no_bounds_check(b->data.ptr + b->meta.wi, x);
+  b->meta.wi += 4;
+}
+
+static void  //
+wav_append_string(wuffs_base__io_buffer* b, const char* s, size_t n) {
+  memcpy(b->data.ptr + b->meta.wi, s, n);
+  b->meta.wi += n;
+}
+
+// make_wav writes a WAVE file with a 16-byte "fmt " chunk (or a 40-byte one,
+// if extensible), a 3-byte "LIST" chunk (plus padding) and a "data" chunk
+// holding data_length bytes. The RIFF length is fixed up afterwards.
+static void  //
+make_wav(wuffs_base__io_buffer* b,
+         uint16_t format,
+         uint16_t num_channels,
+         uint32_t sample_rate,
+         uint16_t bits_per_sample,
+         bool extensible,
+         uint32_t data_length) {
+  uint16_t block_align = num_channels * (bits_per_sample / 8);
+  b->meta.wi = 0;
+  b->meta.ri = 0;
+  wav_append_string(b, "RIFF\x00\x00\x00\x00WAVE", 12);
+  wav_append_string(b, "fmt ", 4);
+  wav_append_u32le(b, extensible ? 40 : 16);
+  wav_append_u16le(b, extensible ? 0xFFFE : format);
+  wav_append_u16le(b, num_channels);
+  wav_append_u32le(b, sample_rate);
+  wav_append_u32le(b, sample_rate * block_align);
+  wav_append_u16le(b, block_align);
+  wav_append_u16le(b, bits_per_sample);
+  if (extensible) {
+    wav_append_u16le(b, 22);
+    wav_append_u16le(b, bits_per_sample);
+    wav_append_u32le(b, 0x3);
+    wav_append_u16le(b, format);
+    wav_append_string(
+        b, "\x00\x00\x00\x00\x10\x00\x80\x00\x00\xAA\x00\x38\x9B\x71", 14);
+  }
+  wav_append_string(b, "LIST\x03\x00\x00\x00" "abc\x00", 12);
+  wav_append_string(b, "data", 4);
+  wav_append_u32le(b, data_length);
+  uint32_t i;
+  for (i = 0; i < data_length; i++) {
+    b->data.ptr[b->meta.wi++] = (uint8_t)(i * 7);
+  }
+  wuffs_base__poke_u32le__no_bounds_check(b->data.ptr + 4,
+                                          (uint32_t)(b->meta.wi - 8));
+  b->meta.closed = true;
+}
+
+const char*  //
+test_wuffs_wav_decode_data() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  make_wav(&src, WUFFS_WAV__FORMAT__PCM, 2, 8000, 16, false, 200);
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  memcpy(want.data.ptr, src.data.ptr + src.meta.wi - 200, 200);
+  want.meta.wi = 200;
+
+  // The src and dst buffers are limited, so that the decoder has to suspend
+  // (and resume) many times.
+  int tc;
+  for (tc = 0; tc < 2; tc++) {
+    wuffs_wav__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_wav__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    src.meta.ri = 0;
+    wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+        .data = g_have_slice_u8,
+    });
+
+    while (true) {
+      wuffs_base__io_buffer limited_src = make_limited_reader(src, 5);
+      wuffs_base__status status =
+          wuffs_wav__decoder__decode_header(&dec, &limited_src);
+      src.meta.ri += limited_src.meta.ri;
+      if (status.repr == wuffs_base__suspension__short_read) {
+        continue;
+      }
+      CHECK_STATUS("decode_header", status);
+      break;
+    }
+
+    // For the second test case, consume some of the data directly from src.
+    if (tc == 1) {
+      memcpy(have.data.ptr, src.data.ptr + src.meta.ri, 10);
+      have.meta.wi += 10;
+      src.meta.ri += 10;
+    }
+
+    while (true) {
+      wuffs_base__io_buffer limited_src = make_limited_reader(src, 33);
+      wuffs_base__io_buffer limited_have = make_limited_writer(have, 44);
+      wuffs_base__status status =
+          wuffs_wav__decoder__decode_data(&dec, &limited_have, &limited_src);
+      src.meta.ri += limited_src.meta.ri;
+      have.meta.wi += limited_have.meta.wi;
+      if ((status.repr == wuffs_base__suspension__short_read) ||
+          (status.repr == wuffs_base__suspension__short_write)) {
+        continue;
+      }
+      CHECK_STATUS("decode_data", status);
+      break;
+    }
+    CHECK_STRING(check_io_buffers_equal("", &have, &want));
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_wav_decode_errors() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+
+  const char* wants[] = {
+      wuffs_wav__error__bad_header,
+      wuffs_wav__error__unsupported_wav_format,
+      wuffs_wav__error__unsupported_wav_format,
+      wuffs_wav__error__bad_header,
+      wuffs_wav__error__bad_chunk,
+      wuffs_wav__error__bad_chunk,
+  };
+
+  int tc;
+  for (tc = 0; tc < (int)(WUFFS_TESTLIB_ARRAY_SIZE(wants)); tc++) {
+    make_wav(&src, WUFFS_WAV__FORMAT__PCM, 1, 8000, 8, false, 10);
+    switch (tc) {
+      case 0:  // Not a RIFF WAVE file.
+        src.data.ptr[11] = 'X';
+        break;
+      case 1:  // An ADPCM format tag.
+        wuffs_base__poke_u16le__no_bounds_check(src.data.ptr + 20, 0x0002);
+        break;
+      case 2:  // A 12-bit PCM container.
+        wuffs_base__poke_u16le__no_bounds_check(src.data.ptr + 34, 12);
+        break;
+      case 3:  // An inconsistent block_align.
+        wuffs_base__poke_u16le__no_bounds_check(src.data.ptr + 32, 2);
+        break;
+      case 4:  // A chunk that extends beyond the RIFF length.
+        wuffs_base__poke_u32le__no_bounds_check(src.data.ptr + 40, 0x100);
+        break;
+      case 5:  // A "data" chunk before the "fmt " chunk.
+        memcpy(src.data.ptr + 12, "data", 4);
+        break;
+    }
+
+    wuffs_wav__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_wav__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_base__status status = wuffs_wav__decoder__decode_header(&dec, &src);
+    if (status.repr != wants[tc]) {
+      RETURN_FAIL("tc=%d: decode_header: have \"%s\", want \"%s\"", tc,
+                  status.repr, wants[tc]);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_wav_decode_header() {
+  CHECK_FOCUS(__func__);
+  struct {
+    uint16_t format;
+    uint16_t num_channels;
+    uint32_t sample_rate;
+    uint16_t bits_per_sample;
+    bool extensible;
+    uint32_t data_length;
+    uint64_t want_data_io_position;
+    uint64_t want_num_frames;
+  } tcs[] = {
+      {WUFFS_WAV__FORMAT__PCM, 1, 8000, 8, false, 17, 56, 17},
+      {WUFFS_WAV__FORMAT__PCM, 2, 44100, 16, false, 40, 56, 10},
+      {WUFFS_WAV__FORMAT__PCM, 2, 48000, 24, true, 30, 80, 5},
+      {WUFFS_WAV__FORMAT__IEEE_FLOAT, 6, 96000, 32, true, 50, 80, 2},
+      {WUFFS_WAV__FORMAT__IEEE_FLOAT, 1, 22050, 64, false, 16, 56, 2},
+  };
+
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+
+  size_t i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(tcs); i++) {
+    make_wav(&src, tcs[i].format, tcs[i].num_channels, tcs[i].sample_rate,
+             tcs[i].bits_per_sample, tcs[i].extensible, tcs[i].data_length);
+
+    wuffs_wav__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_wav__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    CHECK_STATUS("decode_header",
+                 wuffs_wav__decoder__decode_header(&dec, &src));
+
+    uint16_t have_format = wuffs_wav__decoder__format(&dec);
+    if (have_format != tcs[i].format) {
+      RETURN_FAIL("i=%zu: format: have %d, want %d", i, (int)(have_format),
+                  (int)(tcs[i].format));
+    }
+    uint16_t have_num_channels = wuffs_wav__decoder__num_channels(&dec);
+    if (have_num_channels != tcs[i].num_channels) {
+      RETURN_FAIL("i=%zu: num_channels: have %d, want %d", i,
+                  (int)(have_num_channels), (int)(tcs[i].num_channels));
+    }
+    uint32_t have_sample_rate = wuffs_wav__decoder__sample_rate(&dec);
+    if (have_sample_rate != tcs[i].sample_rate) {
+      RETURN_FAIL("i=%zu: sample_rate: have %" PRIu32 ", want %" PRIu32, i,
+                  have_sample_rate, tcs[i].sample_rate);
+    }
+    uint16_t have_bps = wuffs_wav__decoder__bits_per_sample(&dec);
+    if (have_bps != tcs[i].bits_per_sample) {
+      RETURN_FAIL("i=%zu: bits_per_sample: have %d, want %d", i,
+                  (int)(have_bps), (int)(tcs[i].bits_per_sample));
+    }
+    uint32_t have_mask = wuffs_wav__decoder__channel_mask(&dec);
+    uint32_t want_mask = tcs[i].extensible ? 3 : 0;
+    if (have_mask != want_mask) {
+      RETURN_FAIL("i=%zu: channel_mask: have 0x%" PRIX32 ", want 0x%" PRIX32,
+                  i, have_mask, want_mask);
+    }
+    uint64_t have_pos = wuffs_wav__decoder__data_io_position(&dec);
+    if (have_pos != tcs[i].want_data_io_position) {
+      RETURN_FAIL("i=%zu: data_io_position: have %" PRIu64 ", want %" PRIu64,
+                  i, have_pos, tcs[i].want_data_io_position);
+    } else if (have_pos != src.meta.ri) {
+      RETURN_FAIL("i=%zu: data_io_position: have %" PRIu64 ", want %zu", i,
+                  have_pos, src.meta.ri);
+    }
+    uint64_t have_length = wuffs_wav__decoder__data_length(&dec);
+    if (have_length != tcs[i].data_length) {
+      RETURN_FAIL("i=%zu: data_length: have %" PRIu64 ", want %" PRIu32, i,
+                  have_length, tcs[i].data_length);
+    }
+    uint64_t have_num_frames = wuffs_wav__decoder__num_frames(&dec);
+    if (have_num_frames != tcs[i].want_num_frames) {
+      RETURN_FAIL("i=%zu: num_frames: have %" PRIu64 ", want %" PRIu64, i,
+                  have_num_frames, tcs[i].want_num_frames);
+    }
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_wav_decode_data,
+    test_wuffs_wav_decode_errors,
+    test_wuffs_wav_decode_header,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No wav benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/wav";
+  return test_main(argc, argv, g_tests, g_benches);
+}
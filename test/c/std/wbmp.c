@@ -116,6 +116,368 @@ colors_differ(wuffs_base__color_u32_argb_premul color0,
   return false;
 }
 
+// make_icc_profile writes a minimal sRGB-like matrix/TRC ICC profile to dst,
+// returning its length. The three TRC tags share the one "para" curve.
+size_t  //
+make_icc_profile(uint8_t* dst, size_t dst_len) {
+  static const double to_xyz_d50[9] = {
+      0.436065674, 0.385147095, 0.143066406,  //
+      0.222488403, 0.716873169, 0.060607910,  //
+      0.013916016, 0.097076416, 0.714096069,  //
+  };
+  static const double para[5] = {
+      2.4, 1.0 / 1.055, 0.055 / 1.055, 1.0 / 12.92, 0.04045,
+  };
+  static const uint32_t sigs[6] = {
+      0x7258595A, 0x6758595A, 0x6258595A,  // "rXYZ", "gXYZ", "bXYZ".
+      0x72545243, 0x67545243, 0x62545243,  // "rTRC", "gTRC", "bTRC".
+  };
+  const size_t n = 296;
+  if (dst_len < n) {
+    return 0;
+  }
+  memset(dst, 0, n);
+  wuffs_base__poke_u32be__no_bounds_check(dst + 0, n);
+  wuffs_base__poke_u32be__no_bounds_check(dst + 8, 0x04300000);
+  wuffs_base__poke_u32be__no_bounds_check(dst + 12, 0x6D6E7472);  // "mntr".
+  wuffs_base__poke_u32be__no_bounds_check(dst + 16, 0x52474220);  // "RGB ".
+  wuffs_base__poke_u32be__no_bounds_check(dst + 20, 0x58595A20);  // "XYZ ".
+  wuffs_base__poke_u32be__no_bounds_check(dst + 36, 0x61637370);  // "acsp".
+  wuffs_base__poke_u32be__no_bounds_check(dst + 128, 6);
+
+  // The tag table (at offset 132) is followed by three 20 byte XYZ tags (at
+  // offset 204) and one 32 byte para tag (at offset 264).
+  int i;
+  for (i = 0; i < 6; i++) {
+    uint8_t* entry = dst + 132 + (12 * i);
+    wuffs_base__poke_u32be__no_bounds_check(entry + 0, sigs[i]);
+    wuffs_base__poke_u32be__no_bounds_check(entry + 4,
+                                            (i < 3) ? (204 + (20 * i)) : 264);
+    wuffs_base__poke_u32be__no_bounds_check(entry + 8, (i < 3) ? 20 : 32);
+  }
+  for (i = 0; i < 3; i++) {
+    uint8_t* tag = dst + 204 + (20 * i);
+    wuffs_base__poke_u32be__no_bounds_check(tag, 0x58595A20);  // "XYZ ".
+    int j;
+    for (j = 0; j < 3; j++) {
+      wuffs_base__poke_u32be__no_bounds_check(
+          tag + 8 + (4 * j),
+          (uint32_t)((int32_t)((to_xyz_d50[(3 * j) + i] * 65536.0) + 0.5)));
+    }
+  }
+  wuffs_base__poke_u32be__no_bounds_check(dst + 264, 0x70617261);  // "para".
+  wuffs_base__poke_u16be__no_bounds_check(dst + 272, 3);
+  for (i = 0; i < 5; i++) {
+    wuffs_base__poke_u32be__no_bounds_check(
+        dst + 276 + (4 * i), (uint32_t)((int32_t)((para[i] * 65536.0) + 0.5)));
+  }
+  return n;
+}
+
+const char*  //
+test_wuffs_color_icc_parse() {
+  CHECK_FOCUS(__func__);
+
+  uint8_t profile[296];
+  size_t n = make_icc_profile(profile, sizeof(profile));
+
+  wuffs_base__color_icc c;
+  CHECK_STATUS("parse", wuffs_base__color_icc__parse(
+                            &c, wuffs_base__make_slice_u8(profile, n)));
+  if (wuffs_base__color_icc__profile_version(&c) != 0x04300000) {
+    RETURN_FAIL("profile_version: have 0x%08" PRIX32 ", want 0x04300000",
+                wuffs_base__color_icc__profile_version(&c));
+  } else if (wuffs_base__color_icc__color_space(&c) !=
+             WUFFS_BASE__COLOR_ICC__SIGNATURE__RGB) {
+    RETURN_FAIL("color_space: have 0x%08" PRIX32,
+                wuffs_base__color_icc__color_space(&c));
+  } else if (!wuffs_base__color_icc__has_matrix_trc(&c)) {
+    RETURN_FAIL("has_matrix_trc: have false, want true");
+  }
+
+  // Truncating the profile, or pointing a tag past its end, is bad data.
+  wuffs_base__status status =
+      wuffs_base__color_icc__parse(&c, wuffs_base__make_slice_u8(profile, 200));
+  if (status.repr != wuffs_base__error__bad_data) {
+    RETURN_FAIL("truncated: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__error__bad_data);
+  }
+  wuffs_base__poke_u32be__no_bounds_check(profile + 136, 290);
+  status =
+      wuffs_base__color_icc__parse(&c, wuffs_base__make_slice_u8(profile, n));
+  if (status.repr != wuffs_base__error__bad_data) {
+    RETURN_FAIL("tag offset: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__error__bad_data);
+  }
+
+  // An unknown TRC type is well-formed but not matrix/TRC.
+  n = make_icc_profile(profile, sizeof(profile));
+  wuffs_base__poke_u32be__no_bounds_check(profile + 264, 0x78787878);
+  CHECK_STATUS("parse", wuffs_base__color_icc__parse(
+                            &c, wuffs_base__make_slice_u8(profile, n)));
+  if (wuffs_base__color_icc__has_matrix_trc(&c)) {
+    RETURN_FAIL("has_matrix_trc: have true, want false");
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_color_transfer_function_eval() {
+  CHECK_FOCUS(__func__);
+
+  uint8_t table[6] = {0x00, 0x00, 0x40, 0x00, 0xFF, 0xFF};
+  wuffs_base__color_transfer_function sampled =
+      wuffs_base__make_color_transfer_function__gamma(1.0);
+  sampled.table_ptr = table;
+  sampled.table_len = 3;
+
+  const struct {
+    wuffs_base__color_transfer_function f;
+    double x;
+    double want;
+  } test_cases[] = {
+      {
+          .f = wuffs_base__make_color_transfer_function__srgb(),
+          .x = 0.5,
+          .want = 0.214041140,
+      },
+      {
+          .f = wuffs_base__make_color_transfer_function__srgb(),
+          .x = 0.02,
+          .want = 0.001547988,
+      },
+      {
+          .f = wuffs_base__make_color_transfer_function__gamma(2.2),
+          .x = 0.5,
+          .want = 0.217637641,
+      },
+      {
+          .f = wuffs_base__make_color_transfer_function__gamma(2.2),
+          .x = 7.0,
+          .want = 1.000000000,
+      },
+      {
+          .f = sampled,
+          .x = 0.25,
+          .want = 0.125001907,
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    double have = wuffs_base__color_transfer_function__eval(&test_cases[tc].f,
+                                                            test_cases[tc].x);
+    double delta = have - test_cases[tc].want;
+    if ((delta < -1e-8) || (delta > +1e-8)) {
+      RETURN_FAIL("tc=%d: have %.9f, want %.9f", tc, have, test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_color_transform() {
+  CHECK_FOCUS(__func__);
+
+  uint8_t profile[296];
+  size_t n = make_icc_profile(profile, sizeof(profile));
+  wuffs_base__color_icc srgb_like;
+  CHECK_STATUS("parse", wuffs_base__color_icc__parse(
+                            &srgb_like, wuffs_base__make_slice_u8(profile, n)));
+  wuffs_base__color_icc display_p3;
+  wuffs_base__color_icc__set_display_p3(&display_p3);
+
+  const struct {
+    const wuffs_base__color_icc* src;
+    uint32_t src_pixel;
+    uint32_t want_pixel;
+  } test_cases[] = {
+      // Converting from an (almost exactly) sRGB profile to sRGB is a no-op.
+      {.src = &srgb_like, .src_pixel = 0x80C86432, .want_pixel = 0x80C86432},
+      // Display-P3's gamut is wider than sRGB's: saturated colors get more
+      // saturated (or clamped) but grays stay gray.
+      {.src = &display_p3, .src_pixel = 0x80C86432, .want_pixel = 0x80D75D1F},
+      {.src = &display_p3, .src_pixel = 0xFFFF0000, .want_pixel = 0xFFFF0000},
+      {.src = &display_p3, .src_pixel = 0x00808080, .want_pixel = 0x00808080},
+  };
+
+  wuffs_base__color_transform t;
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    CHECK_STATUS("prepare", wuffs_base__color_transform__prepare(
+                                &t, test_cases[tc].src, NULL));
+    uint8_t pixel[4];
+    wuffs_base__poke_u32le__no_bounds_check(pixel, test_cases[tc].src_pixel);
+    uint64_t have_n = wuffs_base__color_transform__apply(
+        &t, wuffs_base__make_slice_u8(pixel, 4),
+        wuffs_base__make_pixel_format(
+            WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL));
+    uint32_t have = wuffs_base__peek_u32le__no_bounds_check(pixel);
+    if (have_n != 1) {
+      RETURN_FAIL("tc=%d: num_pixels: have %" PRIu64 ", want 1", tc, have_n);
+    } else if (have != test_cases[tc].want_pixel) {
+      RETURN_FAIL("tc=%d: have 0x%08" PRIX32 ", want 0x%08" PRIX32, tc, have,
+                  test_cases[tc].want_pixel);
+    }
+  }
+
+  // The swizzler only applies color transforms for the SRC blend.
+  wuffs_base__pixel_swizzler swizzler;
+  CHECK_STATUS("prepare",
+               wuffs_base__pixel_swizzler__prepare(
+                   &swizzler,
+                   wuffs_base__make_pixel_format(
+                       WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL),
+                   wuffs_base__empty_slice_u8(),
+                   wuffs_base__make_pixel_format(WUFFS_BASE__PIXEL_FORMAT__RGB),
+                   wuffs_base__empty_slice_u8(),
+                   WUFFS_BASE__PIXEL_BLEND__SRC_OVER));
+  wuffs_base__status status =
+      wuffs_base__pixel_swizzler__set_color_transform(&swizzler, &t);
+  if (status.repr != wuffs_base__error__unsupported_option) {
+    RETURN_FAIL("set_color_transform: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__error__unsupported_option);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_pixel_buffer_apply_orientation() {
+  CHECK_FOCUS(__func__);
+
+  // The src images are 3x2 ("abc" above "def") and 3x3 ("abc" above "def"
+  // above "ghi"). The want strings are the dst rows, concatenated.
+  const char* want_3x2[8] = {
+      "abcdef", "cbafed", "fedcba", "defabc",
+      "adbecf", "daebfc", "fcebda", "cfbead",
+  };
+  const char* want_3x3[8] = {
+      "abcdefghi", "cbafedihg", "ihgfedcba", "ghidefabc",
+      "adgbehcfi", "gdahebifc", "ifchebgda", "cfibehadg",
+  };
+
+  int in_place;
+  for (in_place = 0; in_place < 2; in_place++) {
+    const uint32_t sw = 3;
+    const uint32_t sh = in_place ? 3 : 2;
+    const char* src_chars = "abcdefghi";
+
+    uint32_t orientation;
+    for (orientation = 1; orientation <= 8; orientation++) {
+      bool swap = wuffs_base__orientation__swaps_width_and_height(orientation);
+      uint32_t dw = swap ? sh : sw;
+      uint32_t dh = swap ? sw : sh;
+      const char* want =
+          in_place ? want_3x3[orientation - 1] : want_3x2[orientation - 1];
+
+      uint8_t src_pixels[9];
+      uint8_t dst_pixels[9];
+      memcpy(src_pixels, src_chars, 9);
+      memset(dst_pixels, 0, 9);
+
+      wuffs_base__pixel_config src_pixcfg = ((wuffs_base__pixel_config){});
+      wuffs_base__pixel_config__set(&src_pixcfg, WUFFS_BASE__PIXEL_FORMAT__Y,
+                                    WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, sw,
+                                    sh);
+      wuffs_base__pixel_buffer src_pixbuf = ((wuffs_base__pixel_buffer){});
+      CHECK_STATUS("set_from_slice (src)",
+                   wuffs_base__pixel_buffer__set_from_slice(
+                       &src_pixbuf, &src_pixcfg,
+                       wuffs_base__make_slice_u8(src_pixels, 9)));
+
+      wuffs_base__pixel_config dst_pixcfg = ((wuffs_base__pixel_config){});
+      wuffs_base__pixel_config__set(&dst_pixcfg, WUFFS_BASE__PIXEL_FORMAT__Y,
+                                    WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, dw,
+                                    dh);
+      wuffs_base__pixel_buffer dst_pixbuf = ((wuffs_base__pixel_buffer){});
+      CHECK_STATUS("set_from_slice (dst)",
+                   wuffs_base__pixel_buffer__set_from_slice(
+                       &dst_pixbuf, &dst_pixcfg,
+                       wuffs_base__make_slice_u8(dst_pixels, 9)));
+
+      uint8_t* have = dst_pixels;
+      if (in_place) {
+        have = src_pixels;
+        CHECK_STATUS("apply_orientation",
+                     wuffs_base__pixel_buffer__apply_orientation(
+                         &src_pixbuf, &src_pixbuf, orientation));
+      } else {
+        CHECK_STATUS("apply_orientation",
+                     wuffs_base__pixel_buffer__apply_orientation(
+                         &dst_pixbuf, &src_pixbuf, orientation));
+      }
+      if (memcmp(have, want, sw * sh) != 0) {
+        RETURN_FAIL("in_place=%d, orientation=%" PRIu32
+                    ": have \"%.*s\", want \"%s\"",
+                    in_place, orientation, (int)(sw * sh), (const char*)have,
+                    want);
+      }
+
+      // Non-square images cannot swap their width and height in place.
+      if (!in_place && swap) {
+        wuffs_base__status status = wuffs_base__pixel_buffer__apply_orientation(
+            &src_pixbuf, &src_pixbuf, orientation);
+        if (status.repr != wuffs_base__error__bad_argument) {
+          RETURN_FAIL("orientation=%" PRIu32 ": have \"%s\", want \"%s\"",
+                      orientation, status.repr,
+                      wuffs_base__error__bad_argument);
+        }
+      }
+    }
+  }
+
+  // Multi-byte pixels are moved as a whole.
+  {
+    uint32_t src_pixels[2] = {0xFF112233, 0xFF445566};
+    uint32_t dst_pixels[2] = {0};
+    wuffs_base__pixel_config src_pixcfg = ((wuffs_base__pixel_config){});
+    wuffs_base__pixel_config__set(&src_pixcfg,
+                                  WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+                                  WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, 2, 1);
+    wuffs_base__pixel_buffer src_pixbuf = ((wuffs_base__pixel_buffer){});
+    CHECK_STATUS("set_from_slice (src)",
+                 wuffs_base__pixel_buffer__set_from_slice(
+                     &src_pixbuf, &src_pixcfg,
+                     wuffs_base__make_slice_u8((uint8_t*)src_pixels, 8)));
+    wuffs_base__pixel_config dst_pixcfg = ((wuffs_base__pixel_config){});
+    wuffs_base__pixel_config__set(&dst_pixcfg,
+                                  WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+                                  WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, 1, 2);
+    wuffs_base__pixel_buffer dst_pixbuf = ((wuffs_base__pixel_buffer){});
+    CHECK_STATUS("set_from_slice (dst)",
+                 wuffs_base__pixel_buffer__set_from_slice(
+                     &dst_pixbuf, &dst_pixcfg,
+                     wuffs_base__make_slice_u8((uint8_t*)dst_pixels, 8)));
+
+    CHECK_STATUS("apply_orientation",
+                 wuffs_base__pixel_buffer__apply_orientation(
+                     &dst_pixbuf, &src_pixbuf,
+                     WUFFS_BASE__ORIENTATION__LEFT_BOTTOM));
+    if ((dst_pixels[0] != 0xFF445566) || (dst_pixels[1] != 0xFF112233)) {
+      RETURN_FAIL("have 0x%08" PRIX32 " 0x%08" PRIX32
+                  ", want 0xFF445566 0xFF112233",
+                  dst_pixels[0], dst_pixels[1]);
+    }
+
+    // The dst width and height must match.
+    wuffs_base__status status = wuffs_base__pixel_buffer__apply_orientation(
+        &dst_pixbuf, &src_pixbuf, WUFFS_BASE__ORIENTATION__TOP_RIGHT);
+    if (status.repr != wuffs_base__error__bad_argument) {
+      RETURN_FAIL("dimensions: have \"%s\", want \"%s\"", status.repr,
+                  wuffs_base__error__bad_argument);
+    }
+
+    // Orientations range from 1 to 8 inclusive.
+    status = wuffs_base__pixel_buffer__apply_orientation(&src_pixbuf,
+                                                         &src_pixbuf, 9);
+    if (status.repr != wuffs_base__error__bad_argument) {
+      RETURN_FAIL("orientation: have \"%s\", want \"%s\"", status.repr,
+                  wuffs_base__error__bad_argument);
+    }
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_pixel_buffer_fill_rect() {
   CHECK_FOCUS(__func__);
@@ -213,6 +575,302 @@ test_wuffs_pixel_buffer_fill_rect() {
   return NULL;
 }
 
+const char*  //
+test_wuffs_pixel_composite() {
+  CHECK_FOCUS(__func__);
+
+  const uint32_t dst_color = 0xC0002080;
+  const uint32_t src_color = 0x80604020;
+
+  const struct {
+    uint64_t (*func)(wuffs_base__slice_u8 dst, wuffs_base__slice_u8 src);
+    uint32_t want;
+  } funcs[] = {
+      {
+          .func = wuffs_base__composite_nonpremul_over_nonpremul,
+          .want = 0xE0373249,
+      },
+      {
+          .func = wuffs_base__composite_nonpremul_over_premul,
+          .want = 0xE0303050,
+      },
+      {
+          .func = wuffs_base__composite_premul_over_nonpremul,
+          .want = 0xE06D565B,
+      },
+      {
+          .func = wuffs_base__composite_premul_over_premul,
+          .want = 0xE0605060,
+      },
+  };
+
+  int f;
+  for (f = 0; f < WUFFS_TESTLIB_ARRAY_SIZE(funcs); f++) {
+    // The dst row is 3 pixels wide but the src row is only 2 and a half, so
+    // only the first 2 dst pixels should be modified.
+    uint8_t dst_array[12];
+    uint8_t src_array[10];
+    int i;
+    for (i = 0; i < 3; i++) {
+      wuffs_base__poke_u32le__no_bounds_check(dst_array + (4 * i), dst_color);
+    }
+    for (i = 0; i < 2; i++) {
+      wuffs_base__poke_u32le__no_bounds_check(src_array + (4 * i), src_color);
+    }
+    src_array[8] = 0xFF;
+    src_array[9] = 0xFF;
+
+    uint64_t have_n = (*funcs[f].func)(
+        wuffs_base__make_slice_u8(dst_array, 12),
+        wuffs_base__make_slice_u8(src_array, 10));
+    if (have_n != 2) {
+      RETURN_FAIL("f=%d: num_pixels: have %" PRIu64 ", want 2", f, have_n);
+    }
+
+    for (i = 0; i < 3; i++) {
+      uint32_t want = (i < 2) ? funcs[f].want : dst_color;
+      uint32_t have =
+          wuffs_base__peek_u32le__no_bounds_check(dst_array + (4 * i));
+      if (have != want) {
+        RETURN_FAIL("f=%d, i=%d: have 0x%08" PRIX32 ", want 0x%08" PRIX32, f,
+                    i, have, want);
+      }
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_pixel_palette_quantize() {
+  CHECK_FOCUS(__func__);
+
+  // A 4x4 image: 2 transparent, 2 dark red, 4 red, 4 green and 4 blue pixels.
+  const uint32_t src_pixels[16] = {
+      0x00000000, 0x00000000, 0xFFF00000, 0xFFF00000,  //
+      0xFFFF0000, 0xFFFF0000, 0xFFFF0000, 0xFFFF0000,  //
+      0xFF00FF00, 0xFF00FF00, 0xFF00FF00, 0xFF00FF00,  //
+      0xFF0000FF, 0xFF0000FF, 0xFF0000FF, 0xFF0000FF,  //
+  };
+  wuffs_base__pixel_config pixcfg = ((wuffs_base__pixel_config){});
+  wuffs_base__pixel_config__set(&pixcfg,
+                                WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+                                WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, 4, 4);
+  wuffs_base__pixel_buffer pixbuf = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     &pixbuf, &pixcfg, g_src_slice_u8));
+  int i;
+  for (i = 0; i < 16; i++) {
+    wuffs_base__poke_u32le__no_bounds_check(g_src_slice_u8.ptr + (4 * i),
+                                            src_pixels[i]);
+  }
+
+  const struct {
+    uint32_t max_num_colors;
+    uint64_t want_num_colors;
+    uint32_t want[5];
+  } tcs[] = {
+      {
+          .max_num_colors = 256,
+          .want_num_colors = 5,
+          .want = {0x00000000, 0xFF0000FF, 0xFFF00000, 0xFF00FF00,
+                   0xFFFF0000},
+      },
+      {
+          // The two reds are averaged: (2*0xF0 + 4*0xFF) / 6 is 0xFA.
+          .max_num_colors = 4,
+          .want_num_colors = 4,
+          .want = {0x00000000, 0xFF0000FF, 0xFFFA0000, 0xFF00FF00},
+      },
+      {
+          .max_num_colors = 1,
+          .want_num_colors = 1,
+          .want = {0x00000000},
+      },
+  };
+
+  uint8_t palette_array[1024];
+  wuffs_base__slice_u8 palette =
+      wuffs_base__make_slice_u8(&palette_array[0], 1024);
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(tcs); tc++) {
+    wuffs_base__result_u64 r = wuffs_base__pixel_palette__quantize(
+        palette, &pixbuf, tcs[tc].max_num_colors, g_work_slice_u8);
+    CHECK_STATUS("quantize", r.status);
+    if (r.value != tcs[tc].want_num_colors) {
+      RETURN_FAIL("tc=%d: num_colors: have %" PRIu64 ", want %" PRIu64, tc,
+                  r.value, tcs[tc].want_num_colors);
+    }
+    for (i = 0; i < 256; i++) {
+      uint32_t have =
+          wuffs_base__peek_u32le__no_bounds_check(&palette_array[4 * i]);
+      uint32_t want = (i < 5) ? tcs[tc].want[i] : 0;
+      if (have != want) {
+        RETURN_FAIL("tc=%d, i=%d: have 0x%08" PRIX32 ", want 0x%08" PRIX32,
+                    tc, i, have, want);
+      }
+    }
+  }
+
+  wuffs_base__result_u64 r = wuffs_base__pixel_palette__quantize(
+      palette, &pixbuf, 256, wuffs_base__make_slice_u8(g_work_slice_u8.ptr, 1));
+  if (r.status.repr != wuffs_base__error__bad_workbuf_length) {
+    RETURN_FAIL("short workbuf: have \"%s\", want \"%s\"", r.status.repr,
+                wuffs_base__error__bad_workbuf_length);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_pixel_swizzler_dither() {
+  CHECK_FOCUS(__func__);
+
+  uint8_t palette_array[1024];
+  wuffs_base__slice_u8 palette =
+      wuffs_base__make_slice_u8(&palette_array[0], 1024);
+  uint8_t workbuf_array[48];
+  wuffs_base__pixel_swizzler swizzler;
+  wuffs_base__pixel_ditherer ditherer;
+
+  // A 2-color (opaque black and white) palette.
+  memset(&palette_array[0], 0x00, 1024);
+  int i;
+  for (i = 0; i < 256; i++) {
+    palette_array[(4 * i) + 3] = 0xFF;
+  }
+  memset(&palette_array[4], 0xFF, 4);
+
+  // Gray 0x86 is between two BGR_565 values: 0x8430 (gray 0x84) and 0x8C31
+  // (roughly gray 0x8C). Gray 0x40 is a quarter of the way from black to
+  // white.
+  const struct {
+    uint32_t dst_pixfmt_repr;
+    uint8_t src_gray;
+    wuffs_base__pixel_dither dither;
+    uint32_t width;
+    uint32_t height;
+    uint16_t want[32];
+  } tcs[] = {
+      {
+          .dst_pixfmt_repr = WUFFS_BASE__PIXEL_FORMAT__BGR_565,
+          .src_gray = 0x86,
+          .dither = WUFFS_BASE__PIXEL_DITHER__NONE,
+          .width = 4,
+          .height = 4,
+          .want = {0x8430, 0x8430, 0x8430, 0x8430, 0x8430, 0x8430, 0x8430,
+                   0x8430, 0x8430, 0x8430, 0x8430, 0x8430, 0x8430, 0x8430,
+                   0x8430, 0x8430},
+      },
+      {
+          .dst_pixfmt_repr = WUFFS_BASE__PIXEL_FORMAT__BGR_565,
+          .src_gray = 0x86,
+          .dither = WUFFS_BASE__PIXEL_DITHER__ORDERED,
+          .width = 4,
+          .height = 4,
+          .want = {0x8430, 0x8430, 0x8430, 0x8430, 0x8C31, 0x8430, 0x8C31,
+                   0x8430, 0x8430, 0x8430, 0x8430, 0x8430, 0x8C31, 0x8430,
+                   0x8C31, 0x8430},
+      },
+      {
+          .dst_pixfmt_repr = WUFFS_BASE__PIXEL_FORMAT__BGR_565,
+          .src_gray = 0x86,
+          .dither = WUFFS_BASE__PIXEL_DITHER__FLOYD_STEINBERG,
+          .width = 4,
+          .height = 4,
+          .want = {0x8430, 0x8430, 0x8430, 0x8430, 0x8430, 0x8C31, 0x8430,
+                   0x8430, 0x8430, 0x8430, 0x8430, 0x8C31, 0x8430, 0x8C31,
+                   0x8430, 0x8430},
+      },
+      {
+          .dst_pixfmt_repr = WUFFS_BASE__PIXEL_FORMAT__INDEXED__BGRA_BINARY,
+          .src_gray = 0x40,
+          .dither = WUFFS_BASE__PIXEL_DITHER__NONE,
+          .width = 8,
+          .height = 4,
+          .want = {0},
+      },
+      {
+          .dst_pixfmt_repr = WUFFS_BASE__PIXEL_FORMAT__INDEXED__BGRA_BINARY,
+          .src_gray = 0x40,
+          .dither = WUFFS_BASE__PIXEL_DITHER__FLOYD_STEINBERG,
+          .width = 8,
+          .height = 4,
+          .want = {0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 1, 0, 1, 0, 1,
+                   0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 1, 0, 1, 0, 1},
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(tcs); tc++) {
+    wuffs_base__pixel_format dst_pixfmt =
+        wuffs_base__make_pixel_format(tcs[tc].dst_pixfmt_repr);
+    size_t dst_bpp = wuffs_base__pixel_format__bits_per_pixel(&dst_pixfmt) / 8;
+    size_t n = tcs[tc].width * tcs[tc].height;
+    memset(g_src_slice_u8.ptr, tcs[tc].src_gray, n);
+
+    CHECK_STATUS("prepare",
+                 wuffs_base__pixel_swizzler__prepare(
+                     &swizzler, dst_pixfmt, palette,
+                     wuffs_base__make_pixel_format(WUFFS_BASE__PIXEL_FORMAT__Y),
+                     wuffs_base__empty_slice_u8(),
+                     WUFFS_BASE__PIXEL_BLEND__SRC));
+    CHECK_STATUS("initialize",
+                 wuffs_base__pixel_ditherer__initialize(
+                     &ditherer, tcs[tc].dither, tcs[tc].width,
+                     wuffs_base__make_slice_u8(&workbuf_array[0], 48)));
+    CHECK_STATUS("set_ditherer", wuffs_base__pixel_swizzler__set_ditherer(
+                                     &swizzler, &ditherer));
+
+    // Swizzle one row at a time, like an image decoder would.
+    uint32_t y;
+    for (y = 0; y < tcs[tc].height; y++) {
+      size_t o = y * tcs[tc].width;
+      uint64_t have_n =
+          wuffs_base__pixel_swizzler__swizzle_interleaved_from_slice(
+              &swizzler,
+              wuffs_base__make_slice_u8(g_have_slice_u8.ptr + (o * dst_bpp),
+                                        tcs[tc].width * dst_bpp),
+              palette,
+              wuffs_base__make_slice_u8(g_src_slice_u8.ptr + o,
+                                        tcs[tc].width));
+      if (have_n != tcs[tc].width) {
+        RETURN_FAIL("tc=%d, y=%" PRIu32 ": have_n: have %" PRIu64
+                    ", want %" PRIu32,
+                    tc, y, have_n, tcs[tc].width);
+      }
+    }
+
+    for (i = 0; i < (int)n; i++) {
+      uint16_t have =
+          (dst_bpp == 2)
+              ? wuffs_base__peek_u16le__no_bounds_check(g_have_slice_u8.ptr +
+                                                        (2 * i))
+              : g_have_slice_u8.ptr[i];
+      if (have != tcs[tc].want[i]) {
+        RETURN_FAIL("tc=%d, i=%d: have 0x%04" PRIX16 ", want 0x%04" PRIX16,
+                    tc, i, have, tcs[tc].want[i]);
+      }
+    }
+  }
+
+  // Dithering requires the SRC blend.
+  CHECK_STATUS("prepare",
+               wuffs_base__pixel_swizzler__prepare(
+                   &swizzler,
+                   wuffs_base__make_pixel_format(
+                       WUFFS_BASE__PIXEL_FORMAT__BGR_565),
+                   wuffs_base__empty_slice_u8(),
+                   wuffs_base__make_pixel_format(WUFFS_BASE__PIXEL_FORMAT__Y),
+                   wuffs_base__empty_slice_u8(),
+                   WUFFS_BASE__PIXEL_BLEND__SRC_OVER));
+  wuffs_base__status status =
+      wuffs_base__pixel_swizzler__set_ditherer(&swizzler, &ditherer);
+  if (status.repr != wuffs_base__error__unsupported_option) {
+    RETURN_FAIL("set_ditherer: have \"%s\", want \"%s\"", status.repr,
+                wuffs_base__error__unsupported_option);
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_pixel_swizzler_swizzle() {
   CHECK_FOCUS(__func__);
@@ -430,6 +1088,93 @@ test_wuffs_pixel_swizzler_swizzle() {
   return NULL;
 }
 
+const char*  //
+test_wuffs_pixel_swizzler_swizzle_ycbcr() {
+  CHECK_FOCUS(__func__);
+
+  // A 4x2 image with 4:2:0 subsampling has one 2x1 sample Cb plane and one
+  // 2x1 sample Cr plane. All luma samples are 0x80, as are all Cb samples.
+  // The Cr samples are 0x80 (left) and 0xC0 (right).
+  wuffs_base__pixel_config src_pixcfg = ((wuffs_base__pixel_config){});
+  wuffs_base__pixel_config__set(&src_pixcfg, WUFFS_BASE__PIXEL_FORMAT__YCBCR,
+                                WUFFS_BASE__PIXEL_SUBSAMPLING__420, 4, 2);
+  uint64_t have_pixbuf_len = wuffs_base__pixel_config__pixbuf_len(&src_pixcfg);
+  if (have_pixbuf_len != 12) {
+    RETURN_FAIL("pixbuf_len: have %" PRIu64 ", want 12", have_pixbuf_len);
+  }
+  wuffs_base__pixel_buffer src_pixbuf = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (src)",
+               wuffs_base__pixel_buffer__set_from_slice(
+                   &src_pixbuf, &src_pixcfg, g_src_slice_u8));
+  memset(g_src_slice_u8.ptr, 0x80, 12);
+  wuffs_base__table_u8 cr = wuffs_base__pixel_buffer__plane(&src_pixbuf, 2);
+  if ((cr.width != 2) || (cr.height != 1)) {
+    RETURN_FAIL("Cr plane: have %zux%zu, want 2x1", cr.width, cr.height);
+  }
+  cr.ptr[1] = 0xC0;
+
+  wuffs_base__pixel_config dst_pixcfg = ((wuffs_base__pixel_config){});
+  wuffs_base__pixel_config__set(&dst_pixcfg,
+                                WUFFS_BASE__PIXEL_FORMAT__BGRA_PREMUL,
+                                WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, 4, 2);
+  wuffs_base__pixel_buffer dst_pixbuf = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (dst)",
+               wuffs_base__pixel_buffer__set_from_slice(
+                   &dst_pixbuf, &dst_pixcfg, g_have_slice_u8));
+
+  wuffs_base__pixel_swizzler swizzler;
+  CHECK_STATUS("prepare",
+               wuffs_base__pixel_swizzler__prepare(
+                   &swizzler, dst_pixcfg.private_impl.pixfmt,
+                   wuffs_base__empty_slice_u8(), src_pixcfg.private_impl.pixfmt,
+                   wuffs_base__empty_slice_u8(), WUFFS_BASE__PIXEL_BLEND__SRC));
+
+  const struct {
+    wuffs_base__pixel_chroma_upsampling upsampling;
+    uint32_t want[4];
+  } tcs[] = {
+      {
+          .upsampling = WUFFS_BASE__PIXEL_CHROMA_UPSAMPLING__NEAREST,
+          .want = {0xFF808080, 0xFF808080, 0xFFDA5280, 0xFFDA5280},
+      },
+      {
+          // The Cr values are 0x80, 0x90, 0xB0 and 0xC0.
+          .upsampling = WUFFS_BASE__PIXEL_CHROMA_UPSAMPLING__TRIANGLE,
+          .want = {0xFF808080, 0xFF967580, 0xFFC35E80, 0xFFDA5280},
+      },
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(tcs); tc++) {
+    CHECK_STATUS("swizzle_ycbcr", wuffs_base__pixel_swizzler__swizzle_ycbcr(
+                                      &swizzler, &dst_pixbuf,
+                                      wuffs_base__empty_slice_u8(), &src_pixbuf,
+                                      tcs[tc].upsampling));
+    uint32_t y;
+    for (y = 0; y < 2; y++) {
+      uint32_t x;
+      for (x = 0; x < 4; x++) {
+        uint32_t have =
+            wuffs_base__pixel_buffer__color_u32_at(&dst_pixbuf, x, y);
+        if (have != tcs[tc].want[x]) {
+          RETURN_FAIL("tc=%d, x=%" PRIu32 ", y=%" PRIu32
+                      ": have 0x%08" PRIX32 ", want 0x%08" PRIX32,
+                      tc, x, y, have, tcs[tc].want[x]);
+        }
+      }
+    }
+  }
+
+  // The interleaved functions are no-ops for a planar source.
+  uint64_t have_n = wuffs_base__pixel_swizzler__swizzle_interleaved_from_slice(
+      &swizzler, g_have_slice_u8, wuffs_base__empty_slice_u8(), g_src_slice_u8);
+  if (have_n != 0) {
+    RETURN_FAIL("swizzle_interleaved_from_slice: have %" PRIu64 ", want 0",
+                have_n);
+  }
+  return NULL;
+}
+
 // ---------------- WBMP Tests
 
 const char*  //
@@ -445,6 +1190,30 @@ test_wuffs_wbmp_decode_interface() {
       "test/data/muybridge-frame-000.wbmp", 0, SIZE_MAX, 30, 20, 0xFFFFFFFF);
 }
 
+const char*  //
+test_wuffs_wbmp_decode_row_groups() {
+  CHECK_FOCUS(__func__);
+  const uint32_t row_group_heights[] = {1, 7, 20, 100};
+  size_t i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(row_group_heights); i++) {
+    wuffs_wbmp__decoder full;
+    CHECK_STATUS("initialize (full)",
+                 wuffs_wbmp__decoder__initialize(
+                     &full, sizeof full, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_wbmp__decoder grouped;
+    CHECK_STATUS("initialize (grouped)",
+                 wuffs_wbmp__decoder__initialize(
+                     &grouped, sizeof grouped, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    CHECK_STRING(do_test__wuffs_base__image_decoder__row_groups(
+        wuffs_wbmp__decoder__upcast_as__wuffs_base__image_decoder(&full),
+        wuffs_wbmp__decoder__upcast_as__wuffs_base__image_decoder(&grouped),
+        "test/data/muybridge-frame-000.wbmp", row_group_heights[i]));
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_wbmp_decode_frame_config() {
   CHECK_FOCUS(__func__);
@@ -655,15 +1424,24 @@ bench_wuffs_pixel_swizzler_bgra_premul_rgba_nonpremul_src_over() {
 
 proc g_tests[] = {
 
-    // These pixel_buffer / pixel_swizzler tests are really testing the Wuffs
-    // base library. They aren't specific to the std/wbmp code, but putting
-    // them here is as good as any other place.
+    // These color / pixel_buffer / pixel_swizzler tests are really testing
+    // the Wuffs base library. They aren't specific to the std/wbmp code, but
+    // putting them here is as good as any other place.
+    test_wuffs_color_icc_parse,
+    test_wuffs_color_transfer_function_eval,
+    test_wuffs_color_transform,
+    test_wuffs_pixel_buffer_apply_orientation,
     test_wuffs_pixel_buffer_fill_rect,
+    test_wuffs_pixel_composite,
+    test_wuffs_pixel_palette_quantize,
+    test_wuffs_pixel_swizzler_dither,
     test_wuffs_pixel_swizzler_swizzle,
+    test_wuffs_pixel_swizzler_swizzle_ycbcr,
 
     test_wuffs_wbmp_decode_frame_config,
     test_wuffs_wbmp_decode_image_config,
     test_wuffs_wbmp_decode_interface,
+    test_wuffs_wbmp_decode_row_groups,
 
 #ifdef WUFFS_MIMIC
 
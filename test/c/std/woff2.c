@@ -0,0 +1,445 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror woff2.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__WOFF2
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+// No WOFF2 golden tests.
+
+// ---------------- WOFF2 Tests
+
+// g_woff2_src is a WOFF2 file with three untransformed tables: "name", "cmap"
+// and (with an explicit tag) "ABCD". Wuffs does not implement Brotli, so its
+// "compressed" data is a placeholder and g_woff2_decompressed is the
+// corresponding decompressed table data.
+const char g_woff2_src[] =
+    // Signature, flavor, length, numTables and reserved.
+    "wOF2\x00\x01\x00\x00\x00\x00\x00\x3E\x00\x03\x00\x00"
+    // totalSfntSize, totalCompressedSize, majorVersion and minorVersion.
+    "\x00\x00\x00\x50\x00\x00\x00\x04\x00\x01\x00\x00"
+    // metaOffset, metaLength, metaOrigLength, privOffset and privLength.
+    "\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"
+    "\x00\x00\x00\x00\x00\x00\x00\x00"
+    // The table directory.
+    "\x05\x05"
+    "\x00\x08"
+    "\x3F"
+    "ABCD\x03"
+    // The compressed data.
+    "????";
+
+const char g_woff2_decompressed[] = "hellocmapdataxyz";
+
+const char g_woff2_want_sfnt[] =
+      "\x00\x01\x00\x00\x00\x03\x00\x20\x00\x01\x00\x10\x41\x42\x43\x44"
+      "\x78\x79\x7A\x00\x00\x00\x00\x4C\x00\x00\x00\x03\x63\x6D\x61\x70"
+      "\xC7\xCE\xD5\xD1\x00\x00\x00\x44\x00\x00\x00\x08\x6E\x61\x6D\x65"
+      "\xD7\x65\x6C\x6C\x00\x00\x00\x3C\x00\x00\x00\x05\x68\x65\x6C\x6C"
+      "\x6F\x00\x00\x00\x63\x6D\x61\x70\x64\x61\x74\x61\x78\x79\x7A\x00";
+
+const char*  //
+test_wuffs_woff2_decode_header() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_woff2__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_woff2__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+      (uint8_t*)g_woff2_src, sizeof(g_woff2_src) - 1, true);
+  CHECK_STATUS("decode_header",
+               wuffs_woff2__decoder__decode_header(&dec, &src));
+
+  struct {
+    const char* name;
+    uint64_t have;
+    uint64_t want;
+  } checks[] = {
+      {"flavor", wuffs_woff2__decoder__flavor(&dec), 0x00010000},
+      {"num_tables", wuffs_woff2__decoder__num_tables(&dec), 3},
+      {"total_sfnt_size", wuffs_woff2__decoder__total_sfnt_size(&dec), 80},
+      {"compressed_data_io_position",
+       wuffs_woff2__decoder__compressed_data_io_position(&dec), 58},
+      {"compressed_data_length",
+       wuffs_woff2__decoder__compressed_data_length(&dec), 4},
+      {"decompressed_length", wuffs_woff2__decoder__decompressed_length(&dec),
+       16},
+      {"workbuf_len", wuffs_woff2__decoder__workbuf_len(&dec).max_incl, 80},
+      {"table_tag(0)", wuffs_woff2__decoder__table_tag(&dec, 0), 0x6E616D65},
+      {"table_tag(1)", wuffs_woff2__decoder__table_tag(&dec, 1), 0x636D6170},
+      {"table_tag(2)", wuffs_woff2__decoder__table_tag(&dec, 2), 0x41424344},
+      {"table_tag(3)", wuffs_woff2__decoder__table_tag(&dec, 3), 0},
+      {"table_length(0)", wuffs_woff2__decoder__table_length(&dec, 0), 5},
+      {"table_length(2)", wuffs_woff2__decoder__table_length(&dec, 2), 3},
+      {"table_transform_length(1)",
+       wuffs_woff2__decoder__table_transform_length(&dec, 1), 8},
+  };
+
+  int i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(checks); i++) {
+    if (checks[i].have != checks[i].want) {
+      RETURN_FAIL("%s: have %" PRIu64 ", want %" PRIu64, checks[i].name,
+                  checks[i].have, checks[i].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_woff2_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  const size_t n = sizeof(g_woff2_src) - 1;
+  uint8_t src_array[64];
+
+  struct {
+    const char* want;
+    size_t offset;
+    uint8_t value;
+  } test_cases[] = {
+      // A bad signature.
+      {.want = wuffs_woff2__error__bad_header, .offset = 3, .value = '1'},
+      // A non-zero reserved field.
+      {.want = wuffs_woff2__error__bad_header, .offset = 15, .value = 0x01},
+      // A length too short for the compressed data.
+      {.want = wuffs_woff2__error__bad_header, .offset = 11, .value = 0x3D},
+      // A "ttcf" flavor.
+      {.want = wuffs_woff2__error__unsupported_woff2_collection,
+       .offset = 4,
+       .value = 't'},
+      // A duplicate "name" table (instead of "cmap").
+      {.want = wuffs_woff2__error__bad_table_directory,
+       .offset = 50,
+       .value = 0x05},
+      // A UIntBase128 with a leading zero.
+      {.want = wuffs_woff2__error__bad_table_directory,
+       .offset = 49,
+       .value = 0x80},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    memcpy(src_array, g_woff2_src, n);
+    src_array[test_cases[tc].offset] = test_cases[tc].value;
+    if (test_cases[tc].offset == 4) {
+      memcpy(&src_array[4], "ttcf", 4);
+    }
+
+    wuffs_woff2__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_woff2__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_base__io_buffer src =
+        wuffs_base__ptr_u8__reader(&src_array[0], n, true);
+    wuffs_base__status status =
+        wuffs_woff2__decoder__decode_header(&dec, &src);
+    if (status.repr != test_cases[tc].want) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, status.repr,
+                  test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_woff2_decode_sfnt() {
+  CHECK_FOCUS(__func__);
+
+  int tc;
+  for (tc = 0; tc < 3; tc++) {
+    wuffs_woff2__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_woff2__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)g_woff2_src, sizeof(g_woff2_src) - 1, true);
+    CHECK_STATUS("decode_header",
+                 wuffs_woff2__decoder__decode_header(&dec, &src));
+
+    // tc == 2 truncates the decompressed data.
+    wuffs_base__io_buffer data = wuffs_base__ptr_u8__reader(
+        (uint8_t*)g_woff2_decompressed,
+        sizeof(g_woff2_decompressed) - ((tc == 2) ? 2 : 1), true);
+    wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+        .data = g_have_slice_u8,
+    });
+    uint64_t limit = tc ? 3 : UINT64_MAX;
+
+    while (true) {
+      wuffs_base__io_buffer limited_have = make_limited_writer(have, limit);
+      wuffs_base__io_buffer limited_data = make_limited_reader(data, limit);
+      wuffs_base__status status = wuffs_woff2__decoder__decode_sfnt(
+          &dec, &limited_have, &limited_data, g_work_slice_u8);
+      have.meta.wi += limited_have.meta.wi;
+      data.meta.ri += limited_data.meta.ri;
+      if ((status.repr == wuffs_base__suspension__short_read) ||
+          (status.repr == wuffs_base__suspension__short_write)) {
+        continue;
+      } else if (tc == 2) {
+        if (status.repr != wuffs_woff2__error__truncated_input) {
+          RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, status.repr,
+                      wuffs_woff2__error__truncated_input);
+        }
+        break;
+      }
+      CHECK_STATUS("decode_sfnt", status);
+      wuffs_base__io_buffer want = make_io_buffer_from_string(
+          g_woff2_want_sfnt, sizeof(g_woff2_want_sfnt) - 1);
+      CHECK_STRING(check_io_buffers_equal("", &have, &want));
+      break;
+    }
+  }
+  return NULL;
+}
+
+// g_woff2_transformed_src is a WOFF2 file with an untransformed "hhea" table
+// and transformed "glyf", "loca" and "hmtx" tables, for three glyphs: an empty
+// glyph, a simple glyph and a composite glyph. The simple glyph has two
+// contours, instructions, an overlap bit and a computed bounding box. Its
+// points use the 1, 2 and 4 byte triplet encodings and their reconstructed
+// flags use the repeat count compression. The "hmtx" table has explicit
+// advance widths and a monospaced glyph's left side bearing but its
+// proportional glyphs' left side bearings are their xMin values.
+const char g_woff2_transformed_src[] =
+    "\x77\x4F\x46\x32\x00\x01\x00\x00\x00\x00\x00\x3F\x00\x04\x00\x00"
+    "\x00\x00\x00\x00\x00\x00\x00\x04\x00\x01\x00\x00\x00\x00\x00\x00"
+    "\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"
+    "\x02\x24\x0A\x64\x59\x0B\x08\x00\x43\x0A\x07\x3F\x3F\x3F\x3F";
+
+const char g_woff2_transformed_decompressed[] =
+    "\x00\x01\x00\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0A\x0B\x0C"
+    "\x0D\x0E\x0F\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1A\x1B\x1C"
+    "\x1D\x1E\x00\x02\x00\x00\x00\x01\x00\x03\x00\x00\x00\x00\x00\x06"
+    "\x00\x00\x00\x02\x00\x00\x00\x06\x00\x00\x00\x0F\x00\x00\x00\x08"
+    "\x00\x00\x00\x0C\x00\x00\x00\x03\x00\x00\x00\x02\xFF\xFF\x03\x03"
+    "\x01\x0B\x81\x66\x7D\x7D\x00\x64\x64\x8F\xF3\x07\xD0\x0B\xB8\x07"
+    "\xD0\x0B\xB8\x02\x01\x01\x03\x00\x01\x00\x32\xFF\xC4\x20\x00\x00"
+    "\x00\x00\x0A\x00\x14\x00\x1E\x00\x28\xB0\x01\x2C\x40\x01\x01\xF4"
+    "\x02\x58\xFF\xF9";
+
+const char g_woff2_transformed_want_sfnt[] =
+    "\x00\x01\x00\x00\x00\x04\x00\x40\x00\x02\x00\x00\x67\x6C\x79\x66"
+    "\x40\x87\x37\x06\x00\x00\x00\x78\x00\x00\x00\x40\x68\x68\x65\x61"
+    "\x78\x81\x69\x72\x00\x00\x00\x4C\x00\x00\x00\x24\x68\x6D\x74\x78"
+    "\x04\x45\xFE\xD4\x00\x00\x00\xB8\x00\x00\x00\x0A\x6C\x6F\x63\x61"
+    "\x00\x14\x00\x20\x00\x00\x00\x70\x00\x00\x00\x08\x00\x01\x00\x00"
+    "\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0A\x0B\x0C\x0D\x0E\x0F\x10"
+    "\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1A\x1B\x1C\x1D\x1E\x00\x02"
+    "\x00\x00\x00\x00\x00\x14\x00\x20\x00\x02\xFE\xD4\xEA\xE8\x0E\x74"
+    "\x02\x58\x00\x02\x00\x05\x00\x02\xB0\x01\x71\x33\x34\x09\x02\x64"
+    "\xFE\x70\x07\xD0\x07\xD0\x64\x01\xF4\xF4\x48\xF4\x48\x00\x00\x00"
+    "\xFF\xFF\x00\x0A\x00\x14\x00\x1E\x00\x28\x01\x03\x00\x01\x00\x32"
+    "\xFF\xC4\x00\x01\x2C\x00\x00\x00\x01\xF4\x00\x00\x02\x58\xFE\xD4"
+    "\xFF\xF9\x00\x00";
+
+const char*  //
+test_wuffs_woff2_decode_transformed() {
+  CHECK_FOCUS(__func__);
+
+  int tc;
+  for (tc = 0; tc < 2; tc++) {
+    wuffs_woff2__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_woff2__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)g_woff2_transformed_src, sizeof(g_woff2_transformed_src) - 1,
+        true);
+    CHECK_STATUS("decode_header",
+                 wuffs_woff2__decoder__decode_header(&dec, &src));
+
+    // The workbuf holds the SFNT file (580 bytes, with room for a glyf table
+    // up to 5 times its 89 byte transformed length) and then the 89 + 7 bytes
+    // of transformed glyf and hmtx table data.
+    uint64_t have_workbuf_len =
+        wuffs_woff2__decoder__workbuf_len(&dec).max_incl;
+    if (have_workbuf_len != 676) {
+      RETURN_FAIL("tc=%d: workbuf_len: have %" PRIu64 ", want 676", tc,
+                  have_workbuf_len);
+    }
+
+    wuffs_base__io_buffer data = wuffs_base__ptr_u8__reader(
+        (uint8_t*)g_woff2_transformed_decompressed,
+        sizeof(g_woff2_transformed_decompressed) - 1, true);
+    wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+        .data = g_have_slice_u8,
+    });
+    uint64_t limit = tc ? 3 : UINT64_MAX;
+
+    while (true) {
+      wuffs_base__io_buffer limited_have = make_limited_writer(have, limit);
+      wuffs_base__io_buffer limited_data = make_limited_reader(data, limit);
+      wuffs_base__status status = wuffs_woff2__decoder__decode_sfnt(
+          &dec, &limited_have, &limited_data, g_work_slice_u8);
+      have.meta.wi += limited_have.meta.wi;
+      data.meta.ri += limited_data.meta.ri;
+      if ((status.repr == wuffs_base__suspension__short_read) ||
+          (status.repr == wuffs_base__suspension__short_write)) {
+        continue;
+      }
+      CHECK_STATUS("decode_sfnt", status);
+      wuffs_base__io_buffer want =
+          make_io_buffer_from_string(g_woff2_transformed_want_sfnt,
+                                     sizeof(g_woff2_transformed_want_sfnt) - 1);
+      CHECK_STRING(check_io_buffers_equal("", &have, &want));
+      break;
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_woff2_decode_unsupported_transform() {
+  CHECK_FOCUS(__func__);
+
+  // Replace the "name" and "cmap" tables with "glyf" and "loca" tables that
+  // use the reserved transformation version 1 (and so have transformLength
+  // fields). The length field grows from 0x3E to 0x40.
+  uint8_t src_array[64];
+  memcpy(src_array, g_woff2_src, 48);
+  memcpy(&src_array[48], "\x4A\x05\x04\x4B\x08\x00\x3F" "ABCD\x03????", 16);
+  src_array[11] = 0x40;
+  const size_t n = 64;
+
+  wuffs_woff2__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_woff2__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  wuffs_base__io_buffer src =
+      wuffs_base__ptr_u8__reader(&src_array[0], n, true);
+  CHECK_STATUS("decode_header",
+               wuffs_woff2__decoder__decode_header(&dec, &src));
+
+  wuffs_base__io_buffer dst = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  const char* have =
+      wuffs_woff2__decoder__decode_sfnt(&dec, &dst, &src, g_work_slice_u8)
+          .repr;
+  if (have != wuffs_woff2__error__unsupported_woff2_transform) {
+    RETURN_FAIL("have \"%s\", want \"%s\"", have,
+                wuffs_woff2__error__unsupported_woff2_transform);
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- WOFF2 Benches
+
+// No WOFF2 benches.
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_woff2_decode_header,
+    test_wuffs_woff2_decode_invalid,
+    test_wuffs_woff2_decode_sfnt,
+    test_wuffs_woff2_decode_transformed,
+    test_wuffs_woff2_decode_unsupported_transform,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No WOFF2 benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/woff2";
+  return test_main(argc, argv, g_tests, g_benches);
+}
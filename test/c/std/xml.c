@@ -0,0 +1,314 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror xml.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__XML
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_xml_xml_things_gt = {
+    .want_filename = "test/data/xml-things.tokens",
+    .src_filename = "test/data/xml-things.xml",
+};
+
+// ---------------- XML Tests
+
+const char*  //
+test_wuffs_xml_decode_interface() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_xml__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_xml__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+  CHECK_STRING(do_test__wuffs_base__token_decoder(
+      wuffs_xml__decoder__upcast_as__wuffs_base__token_decoder(&dec),
+      &g_xml_xml_things_gt));
+
+  return NULL;
+}
+
+const char*  //
+test_wuffs_xml_decode_invalid() {
+  CHECK_FOCUS(__func__);
+
+  struct {
+    const char* want;
+    const char* str;
+  } test_cases[] = {
+      {.want = wuffs_xml__error__bad_document_structure, .str = "<a>"},
+      {.want = wuffs_xml__error__bad_document_structure, .str = "<a/><b/>"},
+      {.want = wuffs_xml__error__bad_document_structure, .str = "x<a/>"},
+      {.want = wuffs_xml__error__bad_document_structure,
+       .str = "<a/><!DOCTYPE a>"},
+      {.want = wuffs_xml__error__bad_end_tag, .str = "<a></b>"},
+      {.want = wuffs_xml__error__bad_end_tag, .str = "<ab></a>"},
+      {.want = wuffs_xml__error__bad_end_tag, .str = "<a></a x>"},
+      {.want = wuffs_xml__error__bad_name, .str = "<1a/>"},
+      {.want = wuffs_xml__error__bad_name, .str = "< a/>"},
+      {.want = wuffs_xml__error__bad_markup, .str = "<a b/>"},
+      {.want = wuffs_xml__error__bad_markup, .str = "<a x='1'y='2'/>"},
+      {.want = wuffs_xml__error__bad_markup, .str = "<a x=1/>"},
+      {.want = wuffs_xml__error__bad_markup, .str = "<a x=\"<\"/>"},
+      {.want = wuffs_xml__error__bad_markup, .str = "<a><!ELEMENT a></a>"},
+      {.want = wuffs_xml__error__bad_reference, .str = "<a>&foo;</a>"},
+      {.want = wuffs_xml__error__bad_reference, .str = "<a>&amp</a>"},
+      {.want = wuffs_xml__error__bad_reference, .str = "<a>&#0;</a>"},
+      {.want = wuffs_xml__error__bad_reference, .str = "<a>&#xD800;</a>"},
+      {.want = wuffs_xml__error__bad_reference, .str = "<a>&#x110000;</a>"},
+      {.want = wuffs_xml__error__bad_text, .str = "<a>]]></a>"},
+      {.want = wuffs_xml__error__bad_comment, .str = "<!-- -- --><a/>"},
+      {.want = wuffs_xml__error__bad_comment, .str = "<a><!-- ---></a>"},
+      {.want = wuffs_xml__error__bad_processing_instruction,
+       .str = "<a/><?xml version='1.0'?>"},
+      {.want = wuffs_xml__error__bad_processing_instruction,
+       .str = " <?xml version='1.0'?><a/>"},
+      {.want = wuffs_xml__error__bad_processing_instruction,
+       .str = "<?XML version='1.0'?><a/>"},
+      {.want = wuffs_xml__error__bad_processing_instruction,
+       .str = "<?pi/?><a/>"},
+      {.want = wuffs_xml__error__bad_character, .str = "<a>\x01</a>"},
+      {.want = wuffs_xml__error__bad_character, .str = "<a>\xC0\x80</a>"},
+      {.want = wuffs_xml__error__bad_character, .str = "<a>\xED\xA0\x80</a>"},
+      {.want = wuffs_xml__error__bad_character, .str = "<a>\xEF\xBF\xBE</a>"},
+      {.want = wuffs_xml__error__bad_character, .str = "<a>\xE2\x98</a>"},
+      {.want = wuffs_xml__error__unsupported_doctype_internal_subset,
+       .str = "<!DOCTYPE a [<!ELEMENT a ANY>]><a/>"},
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)(test_cases[tc].str), strlen(test_cases[tc].str), true);
+
+    wuffs_xml__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_xml__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    const char* have =
+        wuffs_xml__decoder__decode_tokens(&dec, &tok, &src, g_work_slice_u8)
+            .repr;
+    if (have != test_cases[tc].want) {
+      RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, have,
+                  test_cases[tc].want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_xml_decode_valid() {
+  CHECK_FOCUS(__func__);
+
+  // This suite contains valid examples, similar to the
+  // test_wuffs_xml_decode_invalid examples, but they should be accepted.
+  char* test_cases[] = {
+      "<a/>",
+      "<a></a >",
+      "<a:b.c-d_e\xC3\xA9/>",
+      "\xEF\xBB\xBF<?xml version='1.0'?><a/>",
+      "<?xml version='1.0'?>\n<!DOCTYPE a>\n<a/>\n<!-- - -->\n<?pi?>\n",
+      "<!DOCTYPE a SYSTEM 'x[y]>z'><a/>",
+      "<a x = '\"' y=\"'\">&lt;&gt;&amp;&apos;&quot;&#9;&#x10FFFF;</a>",
+      "<a><![CDATA[]]><![CDATA[<&]]]]></a>",
+      "<a>]] ]>\x7F\xEF\xBF\xBD\xF0\x9F\x92\xA9</a>",
+  };
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(test_cases); tc++) {
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src = wuffs_base__ptr_u8__reader(
+        (uint8_t*)(test_cases[tc]), strlen(test_cases[tc]), true);
+
+    wuffs_xml__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_xml__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__status status =
+        wuffs_xml__decoder__decode_tokens(&dec, &tok, &src, g_work_slice_u8);
+    if (!wuffs_base__status__is_ok(&status)) {
+      RETURN_FAIL("tc=%d: have \"%s\", want no error", tc, status.repr);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_xml_decode_split_io() {
+  CHECK_FOCUS(__func__);
+
+  wuffs_base__io_buffer whole = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&whole, "test/data/xml-things.xml"));
+
+  // Feed the decoder's src and dst a few bytes or tokens at a time. The
+  // tokens' lengths should still add up to the whole input.
+  int i;
+  for (i = 1; i < 8; i++) {
+    wuffs_xml__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_xml__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+    wuffs_base__token_buffer tok =
+        wuffs_base__slice_token__writer(g_have_slice_token);
+    wuffs_base__io_buffer src = whole;
+    src.meta.wi = 0;
+    src.meta.closed = false;
+    uint64_t total_length = 0;
+
+    while (true) {
+      wuffs_base__token_buffer limited_tok = make_limited_token_writer(tok, i);
+      wuffs_base__status status = wuffs_xml__decoder__decode_tokens(
+          &dec, &limited_tok, &src, g_work_slice_u8);
+      size_t t;
+      for (t = 0; t < limited_tok.meta.wi; t++) {
+        total_length += wuffs_base__token__length(&limited_tok.data.ptr[t]);
+      }
+
+      if (status.repr == wuffs_base__suspension__short_read) {
+        src.meta.wi += wuffs_base__u64__min(i, whole.meta.wi - src.meta.wi);
+        src.meta.closed = src.meta.wi == whole.meta.wi;
+      } else if (status.repr != wuffs_base__suspension__short_write) {
+        CHECK_STATUS("decode_tokens", status);
+        break;
+      }
+    }
+
+    if (total_length != whole.meta.wi) {
+      RETURN_FAIL("i=%d: total_length: have %" PRIu64 ", want %zu", i,
+                  total_length, whole.meta.wi);
+    }
+  }
+  return NULL;
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- XML Benches
+
+// No XML benches.
+
+// ---------------- Mimic Benches
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_xml_decode_interface,
+    test_wuffs_xml_decode_invalid,
+    test_wuffs_xml_decode_split_io,
+    test_wuffs_xml_decode_valid,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No XML benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/xml";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -0,0 +1,505 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror xxhash.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__XXHASH
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Golden Tests
+
+golden_test g_xxhash_midsummer_gt = {
+    .src_filename = "test/data/midsummer.txt",
+};
+
+golden_test g_xxhash_pi_gt = {
+    .src_filename = "test/data/pi.txt",
+};
+
+// ---------------- XXHash Tests
+
+// The want values are determined by the reference xxHash algorithm (with a
+// zero seed), as printed by "xxhsum -H0" and "xxhsum -H1".
+
+struct {
+  const char* filename;
+  uint32_t want32;
+  uint64_t want64;
+} g_xxhash_golden_test_cases[] = {
+    {
+        .filename = "test/data/hat.bmp",
+        .want32 = 0xCAD975D7,
+        .want64 = 0xA7D576E6A9BAF900,
+    },
+    {
+        .filename = "test/data/hat.gif",
+        .want32 = 0x27633229,
+        .want64 = 0x38E8A7CAFE15E5B8,
+    },
+    {
+        .filename = "test/data/hat.jpeg",
+        .want32 = 0xEEF96C12,
+        .want64 = 0x6B8E028CE8CC09AD,
+    },
+    {
+        .filename = "test/data/hat.lossless.webp",
+        .want32 = 0xA731CF6A,
+        .want64 = 0xCA571B25E75792DA,
+    },
+    {
+        .filename = "test/data/hat.lossy.webp",
+        .want32 = 0x1A54B53D,
+        .want64 = 0x85D813707FE352B7,
+    },
+    {
+        .filename = "test/data/hat.png",
+        .want32 = 0x2EF9D842,
+        .want64 = 0x6096D53175D9C0B5,
+    },
+    {
+        .filename = "test/data/hat.tiff",
+        .want32 = 0x244C2A7F,
+        .want64 = 0x2B7A9E69AEB07DD1,
+    },
+};
+
+// Each want is the checksum of the first length bytes of g_xxhash_pi_digits.
+// The lengths straddle the 4, 8, 16 and 32 byte boundaries between the
+// algorithms' block, stripe and tail processing.
+const char* g_xxhash_pi_digits =
+    "3."
+    "141592653589793238462643383279502884197169399375105820974944592307816406"
+    "2862089986280348253421170";
+
+struct {
+  size_t length;
+  uint32_t want32;
+  uint64_t want64;
+} g_xxhash_pi_test_cases[] = {
+    {0, 0x02CC5D05, 0xEF46DB3751D8E999},  //
+    {1, 0x9CEC73C4, 0x26167C2AF5162CA4},  //
+    {3, 0x76EB9891, 0x765F8073D4013B31},  //
+    {4, 0x65EE94C3, 0x3E160875545B6BE3},  //
+    {7, 0xF7876132, 0xFD47EAC9931E5611},  //
+    {8, 0x5C7905AB, 0x9ECF69693F684A04},  //
+    {15, 0x27DAF5DF, 0xF127AEAAA3C7373B},  //
+    {16, 0xA724DADF, 0xDB620698899E4B6D},  //
+    {17, 0x82C243CD, 0x6E478EE5FA6DD2E9},  //
+    {31, 0x6C4406C8, 0xD0DF37F5BDB842D2},  //
+    {32, 0x260382A6, 0x28EE2A083406DB5A},  //
+    {33, 0x6AD6D4BD, 0x374E44E23156B38C},  //
+    {35, 0xF8DCB125, 0xF584A7417BA286F4},  //
+    {99, 0x7E7BD839, 0x7DBC5F307AC4DB70},  //
+};
+
+// These test vectors are from the xxHash project's documentation and its
+// Python binding's examples.
+struct {
+  const char* src;
+  uint32_t want32;
+  uint64_t want64;
+} g_xxhash_upstream_test_cases[] = {
+    {
+        .src = "",
+        .want32 = 0x02CC5D05,
+        .want64 = 0xEF46DB3751D8E999,
+    },
+    {
+        .src = "abc",
+        .want32 = 0x32D153FF,
+        .want64 = 0x44BC2CF5AD770999,
+    },
+    {
+        .src = "Nobody inspects the spammish repetition",
+        .want32 = 0xE2293B2F,
+        .want64 = 0xFBCEA83C8A378BF1,
+    },
+};
+
+const char*  //
+test_wuffs_xxhash32_golden() {
+  CHECK_FOCUS(__func__);
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(g_xxhash_golden_test_cases);
+       tc++) {
+    wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+        .data = g_src_slice_u8,
+    });
+    CHECK_STRING(read_file(&src, g_xxhash_golden_test_cases[tc].filename));
+
+    int j;
+    for (j = 0; j < 2; j++) {
+      wuffs_xxhash__hasher32 checksum;
+      CHECK_STATUS("initialize",
+                   wuffs_xxhash__hasher32__initialize(
+                       &checksum, sizeof checksum, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+      uint32_t have = 0;
+      size_t num_fragments = 0;
+      size_t num_bytes = 0;
+      do {
+        wuffs_base__slice_u8 data = ((wuffs_base__slice_u8){
+            .ptr = src.data.ptr + num_bytes,
+            .len = src.meta.wi - num_bytes,
+        });
+        size_t limit = 101 + 103 * num_fragments;
+        if ((j > 0) && (data.len > limit)) {
+          data.len = limit;
+        }
+        have = wuffs_xxhash__hasher32__update_u32(&checksum, data);
+        num_fragments++;
+        num_bytes += data.len;
+      } while (num_bytes < src.meta.wi);
+
+      uint32_t want = g_xxhash_golden_test_cases[tc].want32;
+      if (have != want) {
+        RETURN_FAIL("tc=%d, j=%d, filename=\"%s\": have 0x%08" PRIX32
+                    ", want 0x%08" PRIX32,
+                    tc, j, g_xxhash_golden_test_cases[tc].filename, have, want);
+      }
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_xxhash32_pi() {
+  CHECK_FOCUS(__func__);
+
+  if (strlen(g_xxhash_pi_digits) != 99) {
+    RETURN_FAIL("strlen(digits): have %d, want 99",
+                (int)(strlen(g_xxhash_pi_digits)));
+  }
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(g_xxhash_pi_test_cases); tc++) {
+    wuffs_xxhash__hasher32 checksum;
+    CHECK_STATUS("initialize",
+                 wuffs_xxhash__hasher32__initialize(
+                     &checksum, sizeof checksum, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_xxhash__hasher32__update(
+        &checksum, ((wuffs_base__slice_u8){
+                       .ptr = (uint8_t*)(g_xxhash_pi_digits),
+                       .len = g_xxhash_pi_test_cases[tc].length,
+                   }));
+
+    uint32_t have = wuffs_xxhash__hasher32__checksum_u32(&checksum);
+    uint32_t want = g_xxhash_pi_test_cases[tc].want32;
+    if (have != want) {
+      RETURN_FAIL("tc=%d: have 0x%08" PRIX32 ", want 0x%08" PRIX32, tc, have,
+                  want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_xxhash32_upstream() {
+  CHECK_FOCUS(__func__);
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(g_xxhash_upstream_test_cases);
+       tc++) {
+    wuffs_xxhash__hasher32 checksum;
+    CHECK_STATUS("initialize",
+                 wuffs_xxhash__hasher32__initialize(
+                     &checksum, sizeof checksum, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    uint32_t have = wuffs_xxhash__hasher32__update_u32(
+        &checksum, ((wuffs_base__slice_u8){
+                       .ptr = (uint8_t*)(g_xxhash_upstream_test_cases[tc].src),
+                       .len = strlen(g_xxhash_upstream_test_cases[tc].src),
+                   }));
+    uint32_t want = g_xxhash_upstream_test_cases[tc].want32;
+    if (have != want) {
+      RETURN_FAIL("tc=%d: have 0x%08" PRIX32 ", want 0x%08" PRIX32, tc, have,
+                  want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_xxhash64_golden() {
+  CHECK_FOCUS(__func__);
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(g_xxhash_golden_test_cases);
+       tc++) {
+    wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+        .data = g_src_slice_u8,
+    });
+    CHECK_STRING(read_file(&src, g_xxhash_golden_test_cases[tc].filename));
+
+    int j;
+    for (j = 0; j < 2; j++) {
+      wuffs_xxhash__hasher64 checksum;
+      CHECK_STATUS("initialize",
+                   wuffs_xxhash__hasher64__initialize(
+                       &checksum, sizeof checksum, WUFFS_VERSION,
+                       WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+      size_t num_fragments = 0;
+      size_t num_bytes = 0;
+      do {
+        wuffs_base__slice_u8 data = ((wuffs_base__slice_u8){
+            .ptr = src.data.ptr + num_bytes,
+            .len = src.meta.wi - num_bytes,
+        });
+        size_t limit = 101 + 103 * num_fragments;
+        if ((j > 0) && (data.len > limit)) {
+          data.len = limit;
+        }
+        wuffs_xxhash__hasher64__update(&checksum, data);
+        // Computing an intermediate checksum should not affect the final one.
+        wuffs_xxhash__hasher64__checksum_u64(&checksum);
+        num_fragments++;
+        num_bytes += data.len;
+      } while (num_bytes < src.meta.wi);
+
+      uint64_t have = wuffs_xxhash__hasher64__checksum_u64(&checksum);
+      uint64_t want = g_xxhash_golden_test_cases[tc].want64;
+      if (have != want) {
+        RETURN_FAIL("tc=%d, j=%d, filename=\"%s\": have 0x%016" PRIX64
+                    ", want 0x%016" PRIX64,
+                    tc, j, g_xxhash_golden_test_cases[tc].filename, have, want);
+      }
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_xxhash64_pi() {
+  CHECK_FOCUS(__func__);
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(g_xxhash_pi_test_cases); tc++) {
+    wuffs_xxhash__hasher64 checksum;
+    CHECK_STATUS("initialize",
+                 wuffs_xxhash__hasher64__initialize(
+                     &checksum, sizeof checksum, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_xxhash__hasher64__update(
+        &checksum, ((wuffs_base__slice_u8){
+                       .ptr = (uint8_t*)(g_xxhash_pi_digits),
+                       .len = g_xxhash_pi_test_cases[tc].length,
+                   }));
+
+    uint64_t have = wuffs_xxhash__hasher64__checksum_u64(&checksum);
+    uint64_t want = g_xxhash_pi_test_cases[tc].want64;
+    if (have != want) {
+      RETURN_FAIL("tc=%d: have 0x%016" PRIX64 ", want 0x%016" PRIX64, tc, have,
+                  want);
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_xxhash64_upstream() {
+  CHECK_FOCUS(__func__);
+
+  int tc;
+  for (tc = 0; tc < WUFFS_TESTLIB_ARRAY_SIZE(g_xxhash_upstream_test_cases);
+       tc++) {
+    wuffs_xxhash__hasher64 checksum;
+    CHECK_STATUS("initialize",
+                 wuffs_xxhash__hasher64__initialize(
+                     &checksum, sizeof checksum, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_xxhash__hasher64__update(
+        &checksum, ((wuffs_base__slice_u8){
+                       .ptr = (uint8_t*)(g_xxhash_upstream_test_cases[tc].src),
+                       .len = strlen(g_xxhash_upstream_test_cases[tc].src),
+                   }));
+    uint64_t have = wuffs_xxhash__hasher64__checksum_u64(&checksum);
+    uint64_t want = g_xxhash_upstream_test_cases[tc].want64;
+    if (have != want) {
+      RETURN_FAIL("tc=%d: have 0x%016" PRIX64 ", want 0x%016" PRIX64, tc, have,
+                  want);
+    }
+  }
+  return NULL;
+}
+
+// ---------------- XXHash Benches
+
+uint64_t g_wuffs_xxhash_unused_u64;
+
+const char*  //
+wuffs_bench_xxhash32(wuffs_base__io_buffer* dst,
+                     wuffs_base__io_buffer* src,
+                     uint32_t wuffs_initialize_flags,
+                     uint64_t wlimit,
+                     uint64_t rlimit) {
+  uint64_t len = src->meta.wi - src->meta.ri;
+  if (rlimit) {
+    len = wuffs_base__u64__min(len, rlimit);
+  }
+  wuffs_xxhash__hasher32 checksum;
+  CHECK_STATUS("initialize", wuffs_xxhash__hasher32__initialize(
+                                 &checksum, sizeof checksum, WUFFS_VERSION,
+                                 wuffs_initialize_flags));
+  g_wuffs_xxhash_unused_u64 = wuffs_xxhash__hasher32__update_u32(
+      &checksum, ((wuffs_base__slice_u8){
+                     .ptr = src->data.ptr + src->meta.ri,
+                     .len = len,
+                 }));
+  src->meta.ri += len;
+  return NULL;
+}
+
+const char*  //
+wuffs_bench_xxhash64(wuffs_base__io_buffer* dst,
+                     wuffs_base__io_buffer* src,
+                     uint32_t wuffs_initialize_flags,
+                     uint64_t wlimit,
+                     uint64_t rlimit) {
+  uint64_t len = src->meta.wi - src->meta.ri;
+  if (rlimit) {
+    len = wuffs_base__u64__min(len, rlimit);
+  }
+  wuffs_xxhash__hasher64 checksum;
+  CHECK_STATUS("initialize", wuffs_xxhash__hasher64__initialize(
+                                 &checksum, sizeof checksum, WUFFS_VERSION,
+                                 wuffs_initialize_flags));
+  wuffs_xxhash__hasher64__update(&checksum,
+                                 ((wuffs_base__slice_u8){
+                                     .ptr = src->data.ptr + src->meta.ri,
+                                     .len = len,
+                                 }));
+  g_wuffs_xxhash_unused_u64 = wuffs_xxhash__hasher64__checksum_u64(&checksum);
+  src->meta.ri += len;
+  return NULL;
+}
+
+const char*  //
+bench_wuffs_xxhash32_10k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_bench_xxhash32,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_src,
+      &g_xxhash_midsummer_gt, UINT64_MAX, UINT64_MAX, 1500);
+}
+
+const char*  //
+bench_wuffs_xxhash32_100k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_bench_xxhash32,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_src,
+      &g_xxhash_pi_gt, UINT64_MAX, UINT64_MAX, 150);
+}
+
+const char*  //
+bench_wuffs_xxhash64_10k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_bench_xxhash64,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_src,
+      &g_xxhash_midsummer_gt, UINT64_MAX, UINT64_MAX, 1500);
+}
+
+const char*  //
+bench_wuffs_xxhash64_100k() {
+  CHECK_FOCUS(__func__);
+  return do_bench_io_buffers(
+      wuffs_bench_xxhash64,
+      WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED, tcounter_src,
+      &g_xxhash_pi_gt, UINT64_MAX, UINT64_MAX, 150);
+}
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_xxhash32_golden,
+    test_wuffs_xxhash32_pi,
+    test_wuffs_xxhash32_upstream,
+    test_wuffs_xxhash64_golden,
+    test_wuffs_xxhash64_pi,
+    test_wuffs_xxhash64_upstream,
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+    bench_wuffs_xxhash32_10k,
+    bench_wuffs_xxhash32_100k,
+    bench_wuffs_xxhash64_10k,
+    bench_wuffs_xxhash64_100k,
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/xxhash";
+  return test_main(argc, argv, g_tests, g_benches);
+}
@@ -0,0 +1,359 @@
+// Copyright 2026 The Wuffs Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ----------------
+
+/*
+This test program is typically run indirectly, by the "wuffs test" or "wuffs
+bench" commands. These commands take an optional "-mimic" flag to check that
+Wuffs' output mimics (i.e. exactly matches) other libraries' output, such as
+giflib for GIF, libpng for PNG, etc.
+
+To manually run this test:
+
+for CC in clang gcc; do
+  $CC -std=c99 -Wall -Werror zip.c && ./a.out
+  rm -f a.out
+done
+
+Each edition should print "PASS", amongst other information, and exit(0).
+
+Add the "wuffs mimic cflags" (everything after the colon below) to the C
+compiler flags (after the .c file) to run the mimic tests.
+
+To manually run the benchmarks, replace "-Wall -Werror" with "-O3" and replace
+the first "./a.out" with "./a.out -bench". Combine these changes with the
+"wuffs mimic cflags" to run the mimic benchmarks.
+*/
+
+// ¿ wuffs mimic cflags: -DWUFFS_MIMIC
+
+// Wuffs ships as a "single file C library" or "header file library" as per
+// https://github.com/nothings/stb/blob/master/docs/stb_howto.txt
+//
+// To use that single file as a "foo.c"-like implementation, instead of a
+// "foo.h"-like header, #define WUFFS_IMPLEMENTATION before #include'ing or
+// compiling it.
+#define WUFFS_IMPLEMENTATION
+
+// Defining the WUFFS_CONFIG__MODULE* macros are optional, but it lets users of
+// release/c/etc.c choose which parts of Wuffs to build. That file contains the
+// entire Wuffs standard library, implementing a variety of codecs and file
+// formats. Without this macro definition, an optimizing compiler or linker may
+// very well discard Wuffs code for unused codecs, but listing the Wuffs
+// modules we use makes that process explicit. Preprocessing means that such
+// code simply isn't compiled.
+#define WUFFS_CONFIG__MODULES
+#define WUFFS_CONFIG__MODULE__BASE
+#define WUFFS_CONFIG__MODULE__CRC32
+#define WUFFS_CONFIG__MODULE__DEFLATE
+#define WUFFS_CONFIG__MODULE__ZIP
+
+// If building this program in an environment that doesn't easily accommodate
+// relative includes, you can use the script/inline-c-relative-includes.go
+// program to generate a stand-alone C file.
+#include "../../../release/c/wuffs-unsupported-snapshot.c"
+#include "../testlib/testlib.c"
+#ifdef WUFFS_MIMIC
+// No mimic library.
+#endif
+
+// ---------------- Zip Tests
+
+// seek_src handles a "$mispositioned read" suspension, given that src holds
+// the entire file.
+const char*  //
+seek_src(wuffs_zip__decoder* dec, wuffs_base__io_buffer* src) {
+  uint64_t pos = wuffs_zip__decoder__seek_io_position(dec);
+  if (!wuffs_base__io_buffer__reader_seek(src, pos)) {
+    RETURN_FAIL("seek_io_position: out of bounds");
+  }
+  return NULL;
+}
+
+// decode_zip_end_of_central_directory decodes the end of central directory
+// record, letting each call see at most rlimit bytes of src.
+const char*  //
+decode_zip_end_of_central_directory(wuffs_zip__decoder* dec,
+                                    wuffs_base__io_buffer* src,
+                                    uint64_t rlimit) {
+  while (true) {
+    wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+    wuffs_base__status status =
+        wuffs_zip__decoder__decode_end_of_central_directory(dec, &limited_src);
+    src->meta.ri += limited_src.meta.ri;
+
+    if (status.repr == wuffs_base__suspension__mispositioned_read) {
+      CHECK_STRING(seek_src(dec, src));
+      continue;
+    } else if ((rlimit < UINT64_MAX) &&
+               (status.repr == wuffs_base__suspension__short_read)) {
+      continue;
+    }
+    CHECK_STATUS("decode_end_of_central_directory", status);
+    return NULL;
+  }
+}
+
+// decode_zip_entry decodes the next central directory entry, its local header
+// and its data, appending that data to dst. Each call sees at most wlimit
+// bytes of dst and rlimit bytes of src.
+const char*  //
+decode_zip_entry(wuffs_zip__decoder* dec,
+                 wuffs_base__io_buffer* dst,
+                 wuffs_base__io_buffer* src,
+                 uint64_t wlimit,
+                 uint64_t rlimit) {
+  int i;
+  for (i = 0; i < 3; i++) {
+    while (true) {
+      wuffs_base__io_buffer limited_dst = make_limited_writer(*dst, wlimit);
+      wuffs_base__io_buffer limited_src = make_limited_reader(*src, rlimit);
+      wuffs_base__status status;
+      switch (i) {
+        case 0:
+          status = wuffs_zip__decoder__decode_central_directory_entry(
+              dec, &limited_src);
+          break;
+        case 1:
+          status = wuffs_zip__decoder__decode_local_header(dec, &limited_src);
+          break;
+        default:
+          status = wuffs_zip__decoder__decode_entry_data(
+              dec, &limited_dst, &limited_src, g_work_slice_u8);
+          break;
+      }
+      dst->meta.wi += limited_dst.meta.wi;
+      src->meta.ri += limited_src.meta.ri;
+
+      if (status.repr == wuffs_base__suspension__mispositioned_read) {
+        CHECK_STRING(seek_src(dec, src));
+        continue;
+      } else if (((wlimit < UINT64_MAX) &&
+                  (status.repr == wuffs_base__suspension__short_write)) ||
+                 ((rlimit < UINT64_MAX) &&
+                  (status.repr == wuffs_base__suspension__short_read))) {
+        continue;
+      }
+      CHECK_STATUS("decode", status);
+      break;
+    }
+  }
+  return NULL;
+}
+
+const char*  //
+do_test_wuffs_zip_decode_entries(uint64_t wlimit, uint64_t rlimit) {
+  wuffs_zip__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_zip__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, "test/data/romeo-and-midsummer.zip"));
+  src.meta.closed = true;
+  // decode_end_of_central_directory peeks at the whole 22 byte record, so it
+  // cannot make progress if it sees fewer bytes than that.
+  CHECK_STRING(decode_zip_end_of_central_directory(
+      &dec, &src, (rlimit < 22) ? 22 : rlimit));
+  if (wuffs_zip__decoder__num_entries(&dec) != 2) {
+    RETURN_FAIL("num_entries: have %" PRIu64 ", want 2",
+                wuffs_zip__decoder__num_entries(&dec));
+  }
+
+  const char* filenames[] = {"romeo.txt", "midsummer.txt"};
+  const uint32_t methods[] = {WUFFS_ZIP__COMPRESSION_METHOD__DEFLATE,
+                              WUFFS_ZIP__COMPRESSION_METHOD__STORE};
+  size_t i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(filenames); i++) {
+    wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+        .data = g_have_slice_u8,
+    });
+    CHECK_STRING(decode_zip_entry(&dec, &have, &src, wlimit, rlimit));
+
+    wuffs_base__slice_u8 name = wuffs_zip__decoder__entry_name(&dec);
+    if ((name.len != strlen(filenames[i])) ||
+        memcmp(name.ptr, filenames[i], name.len)) {
+      RETURN_FAIL("i=%zu: name: have \"%.*s\", want \"%s\"", i,
+                  (int)(name.len), name.ptr, filenames[i]);
+    }
+    uint32_t have_method = wuffs_zip__decoder__entry_compression_method(&dec);
+    if (have_method != methods[i]) {
+      RETURN_FAIL("i=%zu: compression method: have %" PRIu32
+                  ", want %" PRIu32,
+                  i, have_method, methods[i]);
+    }
+
+    char path[64];
+    snprintf(path, sizeof path, "test/data/%s", filenames[i]);
+    wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+        .data = g_want_slice_u8,
+    });
+    CHECK_STRING(read_file(&want, path));
+    CHECK_STRING(check_io_buffers_equal("", &have, &want));
+  }
+
+  wuffs_base__status status =
+      wuffs_zip__decoder__decode_central_directory_entry(&dec, &src);
+  if (status.repr != wuffs_base__note__end_of_data) {
+    RETURN_FAIL("decode_central_directory_entry: have \"%s\", want \"%s\"",
+                status.repr, wuffs_base__note__end_of_data);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_zip_decode_entries() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_zip_decode_entries(UINT64_MAX, UINT64_MAX);
+}
+
+const char*  //
+test_wuffs_zip_decode_entries_tiny_buffers() {
+  CHECK_FOCUS(__func__);
+  const uint64_t limits[] = {1, 2, 3, 7, 64};
+  size_t i;
+  for (i = 0; i < WUFFS_TESTLIB_ARRAY_SIZE(limits); i++) {
+    const char* z = do_test_wuffs_zip_decode_entries(limits[i], UINT64_MAX);
+    if (z) {
+      RETURN_FAIL("wlimit=%" PRIu64 ": %s", limits[i], z);
+    }
+    z = do_test_wuffs_zip_decode_entries(UINT64_MAX, limits[i]);
+    if (z) {
+      RETURN_FAIL("rlimit=%" PRIu64 ": %s", limits[i], z);
+    }
+    z = do_test_wuffs_zip_decode_entries(limits[i], limits[i]);
+    if (z) {
+      RETURN_FAIL("wlimit=rlimit=%" PRIu64 ": %s", limits[i], z);
+    }
+  }
+  return NULL;
+}
+
+// do_test_wuffs_zip_decode_bad checks that decoding the num_good_entries'th
+// entry (counting from zero) fails with want_status.
+const char*  //
+do_test_wuffs_zip_decode_bad(const char* filename,
+                             size_t num_good_entries,
+                             const char* want_status) {
+  wuffs_zip__decoder dec;
+  CHECK_STATUS("initialize",
+               wuffs_zip__decoder__initialize(
+                   &dec, sizeof dec, WUFFS_VERSION,
+                   WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, filename));
+  src.meta.closed = true;
+  CHECK_STRING(decode_zip_end_of_central_directory(&dec, &src, UINT64_MAX));
+
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  size_t i;
+  for (i = 0; i < num_good_entries; i++) {
+    CHECK_STRING(decode_zip_entry(&dec, &have, &src, UINT64_MAX, UINT64_MAX));
+  }
+  const char* have_status =
+      decode_zip_entry(&dec, &have, &src, UINT64_MAX, UINT64_MAX);
+  if (!have_status || !strstr(have_status, want_status)) {
+    RETURN_FAIL("have \"%s\", want \"%s\"",
+                have_status ? have_status : "(null)", want_status);
+  }
+  return NULL;
+}
+
+const char*  //
+test_wuffs_zip_decode_bad_checksum() {
+  CHECK_FOCUS(__func__);
+  // Change the first byte of the (stored) midsummer.txt data.
+  return do_test_wuffs_zip_decode_bad(
+      "@0264=41=58;test/data/romeo-and-midsummer.zip", 1,
+      wuffs_zip__error__bad_checksum);
+}
+
+const char*  //
+test_wuffs_zip_decode_overlapping_entries() {
+  CHECK_FOCUS(__func__);
+  // Change the central directory's midsummer.txt local header offset from
+  // 0x0239 to 0x0039, inside the romeo.txt entry.
+  return do_test_wuffs_zip_decode_bad(
+      "@2DFF=02=00;test/data/romeo-and-midsummer.zip", 1,
+      wuffs_zip__error__overlapping_entries);
+}
+
+const char*  //
+test_wuffs_zip_decode_bad_uncompressed_size() {
+  CHECK_FOCUS(__func__);
+  // Change romeo.txt's uncompressed size, in both its local header and its
+  // central directory entry, from 0x03AE (942) to 0x03AD and then to 0x03AF.
+  // The first is caught part-way through the data and the second at its end.
+  CHECK_STRING(do_test_wuffs_zip_decode_bad(
+      "@0016=AE=AD;@2DB5=AE=AD;test/data/romeo-and-midsummer.zip", 0,
+      wuffs_zip__error__bad_uncompressed_size));
+  return do_test_wuffs_zip_decode_bad(
+      "@0016=AE=AF;@2DB5=AE=AF;test/data/romeo-and-midsummer.zip", 0,
+      wuffs_zip__error__bad_uncompressed_size);
+}
+
+// ---------------- Mimic Tests
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+// ---------------- Manifest
+
+proc g_tests[] = {
+
+    test_wuffs_zip_decode_bad_checksum,
+    test_wuffs_zip_decode_bad_uncompressed_size,
+    test_wuffs_zip_decode_entries,
+    test_wuffs_zip_decode_entries_tiny_buffers,
+    test_wuffs_zip_decode_overlapping_entries,
+
+#ifdef WUFFS_MIMIC
+
+// No mimic tests.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+proc g_benches[] = {
+
+// No zip benches.
+
+#ifdef WUFFS_MIMIC
+
+// No mimic benches.
+
+#endif  // WUFFS_MIMIC
+
+    NULL,
+};
+
+int  //
+main(int argc, char** argv) {
+  g_proc_package_name = "std/zip";
+  return test_main(argc, argv, g_tests, g_benches);
+}
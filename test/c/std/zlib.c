@@ -203,6 +203,74 @@ do_test_wuffs_zlib_checksum(bool ignore_checksum, uint32_t bad_checksum) {
   return NULL;
 }
 
+// do_test_wuffs_zlib_checksum_tiny_buffers reads the src data 1 byte at a
+// time, so that the io_checksum block (which hashes the dst data) suspends and
+// resumes many times, hashing only part of the dst data each time.
+const char*  //
+do_test_wuffs_zlib_checksum_tiny_buffers(uint32_t bad_checksum) {
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  wuffs_base__io_buffer want = ((wuffs_base__io_buffer){
+      .data = g_want_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, g_zlib_midsummer_gt.src_filename));
+  CHECK_STRING(read_file(&want, g_zlib_midsummer_gt.want_filename));
+  if (src.meta.wi < 4) {
+    RETURN_FAIL("source file was too short");
+  }
+  if (bad_checksum) {
+    src.data.ptr[src.meta.wi - 1 - (bad_checksum & 3)] ^= 1;
+  }
+
+  int tc;
+  for (tc = 0; tc < 2; tc++) {
+    wuffs_zlib__decoder dec;
+    CHECK_STATUS("initialize",
+                 wuffs_zlib__decoder__initialize(
+                     &dec, sizeof dec, WUFFS_VERSION,
+                     WUFFS_INITIALIZE__LEAVE_INTERNAL_BUFFERS_UNINITIALIZED));
+    wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+        .data = g_have_slice_u8,
+    });
+    src.meta.ri = 0;
+
+    // tc == 1 also writes the dst data 1 byte at a time.
+    uint64_t wlimit = tc ? 1 : UINT64_MAX;
+    int num_mid_block_suspensions = 0;
+    wuffs_base__status status;
+    while (true) {
+      wuffs_base__io_buffer limited_have = make_limited_writer(have, wlimit);
+      wuffs_base__io_buffer limited_src = make_limited_reader(src, 1);
+      status = wuffs_zlib__decoder__transform_io(&dec, &limited_have,
+                                                 &limited_src, g_work_slice_u8);
+      have.meta.wi += limited_have.meta.wi;
+      src.meta.ri += limited_src.meta.ri;
+      if ((status.repr != wuffs_base__suspension__short_read) &&
+          (status.repr != wuffs_base__suspension__short_write)) {
+        break;
+      } else if ((have.meta.wi > 0) && (have.meta.wi < want.meta.wi)) {
+        num_mid_block_suspensions++;
+      }
+    }
+
+    if (num_mid_block_suspensions < 1000) {
+      RETURN_FAIL("tc=%d: num_mid_block_suspensions: have %d, want >= 1000",
+                  tc, num_mid_block_suspensions);
+    }
+    if (bad_checksum) {
+      if (status.repr != wuffs_zlib__error__bad_checksum) {
+        RETURN_FAIL("tc=%d: have \"%s\", want \"%s\"", tc, status.repr,
+                    wuffs_zlib__error__bad_checksum);
+      }
+      continue;
+    }
+    CHECK_STATUS("transform_io", status);
+    CHECK_STRING(check_io_buffers_equal("", &have, &want));
+  }
+  return NULL;
+}
+
 const char*  //
 test_wuffs_zlib_checksum_ignore() {
   CHECK_FOCUS(__func__);
@@ -227,6 +295,18 @@ test_wuffs_zlib_checksum_verify_good() {
   return do_test_wuffs_zlib_checksum(false, 0);
 }
 
+const char*  //
+test_wuffs_zlib_checksum_verify_tiny_buffers_bad() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_zlib_checksum_tiny_buffers(4 | 0);
+}
+
+const char*  //
+test_wuffs_zlib_checksum_verify_tiny_buffers_good() {
+  CHECK_FOCUS(__func__);
+  return do_test_wuffs_zlib_checksum_tiny_buffers(0);
+}
+
 const char*  //
 test_wuffs_zlib_decode_midsummer() {
   CHECK_FOCUS(__func__);
@@ -288,6 +368,58 @@ test_wuffs_zlib_decode_sheep() {
   return check_io_buffers_equal("", &have, &want);
 }
 
+const char*  //
+test_wuffs_zlib_dictionary_matches() {
+  CHECK_FOCUS(__func__);
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__io_buffer src =
+      make_io_buffer_from_string(g_zlib_sheep_src_ptr, g_zlib_sheep_src_len);
+  wuffs_base__slice_u8 good_dict = ((wuffs_base__slice_u8){
+      .ptr = ((uint8_t*)(g_zlib_sheep_dict_ptr)),
+      .len = g_zlib_sheep_dict_len,
+  });
+  wuffs_base__slice_u8 bad_dict = ((wuffs_base__slice_u8){
+      .ptr = ((uint8_t*)(" goats.\n")),
+      .len = 8,
+  });
+
+  wuffs_zlib__decoder dec;
+  CHECK_STATUS("initialize", wuffs_zlib__decoder__initialize(
+                                 &dec, sizeof dec, WUFFS_VERSION,
+                                 WUFFS_INITIALIZE__DEFAULT_OPTIONS));
+
+  // Before the zlib header is read, no dictionary matches.
+  if (wuffs_zlib__decoder__dictionary_matches(&dec, good_dict)) {
+    RETURN_FAIL("dictionary_matches (before header): have true, want false");
+  }
+
+  wuffs_base__status status =
+      wuffs_zlib__decoder__transform_io(&dec, &have, &src, g_work_slice_u8);
+  if (status.repr != wuffs_zlib__note__dictionary_required) {
+    RETURN_FAIL("transform_io (before dict): have \"%s\", want \"%s\"",
+                status.repr, wuffs_zlib__note__dictionary_required);
+  }
+
+  // Probing with the wrong dictionary does not affect the decoder.
+  if (wuffs_zlib__decoder__dictionary_matches(&dec, bad_dict)) {
+    RETURN_FAIL("dictionary_matches (bad dict): have true, want false");
+  }
+  if (!wuffs_zlib__decoder__dictionary_matches(&dec, good_dict)) {
+    RETURN_FAIL("dictionary_matches (good dict): have false, want true");
+  }
+  wuffs_zlib__decoder__add_dictionary(&dec, good_dict);
+
+  CHECK_STATUS(
+      "transform_io (after dict)",
+      wuffs_zlib__decoder__transform_io(&dec, &have, &src, g_work_slice_u8));
+
+  wuffs_base__io_buffer want =
+      make_io_buffer_from_string(g_zlib_sheep_want_ptr, g_zlib_sheep_want_len);
+  return check_io_buffers_equal("", &have, &want);
+}
+
 // ---------------- Mimic Tests
 
 #ifdef WUFFS_MIMIC
@@ -375,10 +507,13 @@ proc g_tests[] = {
     test_wuffs_zlib_checksum_verify_bad0,
     test_wuffs_zlib_checksum_verify_bad3,
     test_wuffs_zlib_checksum_verify_good,
+    test_wuffs_zlib_checksum_verify_tiny_buffers_bad,
+    test_wuffs_zlib_checksum_verify_tiny_buffers_good,
     test_wuffs_zlib_decode_interface,
     test_wuffs_zlib_decode_midsummer,
     test_wuffs_zlib_decode_pi,
     test_wuffs_zlib_decode_sheep,
+    test_wuffs_zlib_dictionary_matches,
 
 #ifdef WUFFS_MIMIC
 
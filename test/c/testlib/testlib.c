@@ -300,18 +300,29 @@ check_focus() {
 #define WUFFS_TESTLIB_QUOTE_EXPAND(x) #x
 #define WUFFS_TESTLIB_QUOTE(x) WUFFS_TESTLIB_QUOTE_EXPAND(x)
 
+// The g_cc suffix distinguishes "wuffs bench -coroutinedispatch=etc" results
+// (for the same C compiler) from each other.
+#if defined(WUFFS_BASE__COROUTINE_COMPUTED_GOTO)
+#define WUFFS_TESTLIB_CC_SUFFIX "cg"
+#elif defined(WUFFS_CONFIG__CLOSED_SRC_FAST_PATH)
+#define WUFFS_TESTLIB_CC_SUFFIX "cs"
+#else
+#define WUFFS_TESTLIB_CC_SUFFIX ""
+#endif
+
 // The order matters here. Clang also defines "__GNUC__".
 #if defined(__clang__)
-const char* g_cc = "clang" WUFFS_TESTLIB_QUOTE(__clang_major__);
+const char* g_cc =
+    "clang" WUFFS_TESTLIB_QUOTE(__clang_major__) WUFFS_TESTLIB_CC_SUFFIX;
 const char* g_cc_version = __clang_version__;
 #elif defined(__GNUC__)
-const char* g_cc = "gcc" WUFFS_TESTLIB_QUOTE(__GNUC__);
+const char* g_cc = "gcc" WUFFS_TESTLIB_QUOTE(__GNUC__) WUFFS_TESTLIB_CC_SUFFIX;
 const char* g_cc_version = __VERSION__;
 #elif defined(_MSC_VER)
-const char* g_cc = "cl";
+const char* g_cc = "cl" WUFFS_TESTLIB_CC_SUFFIX;
 const char* g_cc_version = "???";
 #else
-const char* g_cc = "cc";
+const char* g_cc = "cc" WUFFS_TESTLIB_CC_SUFFIX;
 const char* g_cc_version = "???";
 #endif
 
@@ -1238,6 +1249,817 @@ do_test__wuffs_base__image_decoder(
   return NULL;
 }
 
+// do_test__wuffs_base__image_decoder__row_groups decodes the same image twice:
+// once by the full decoder, into a full pixel buffer, and once by the grouped
+// decoder, in row group mode, into a pixel buffer only row_group_height rows
+// tall. The two decodings should produce the same pixels. The row groups
+// should cover the image either top-down or (as for most BMP images)
+// bottom-up, without gaps or overlaps.
+//
+// The _src variant takes the encoded image from src instead of from a file.
+const char*  //
+do_test__wuffs_base__image_decoder__row_groups_src(
+    wuffs_base__image_decoder* full,
+    wuffs_base__image_decoder* grouped,
+    wuffs_base__io_buffer src,
+    uint32_t row_group_height) {
+  if (row_group_height == 0) {
+    return "row_group_height is zero";
+  }
+
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS("decode_image_config (full)",
+               wuffs_base__image_decoder__decode_image_config(full, &ic, &src));
+
+  uint32_t width = wuffs_base__pixel_config__width(&ic.pixcfg);
+  uint32_t height = wuffs_base__pixel_config__height(&ic.pixcfg);
+  if ((width > 16384) || (height > 16384) ||
+      ((width * height * 4) > PIXEL_BUFFER_ARRAY_SIZE) ||
+      ((width * row_group_height * 4) > IO_BUFFER_ARRAY_SIZE)) {
+    return "dimensions are too large";
+  }
+  size_t bytes_per_row = width * 4;
+
+  wuffs_base__pixel_config__set(
+      &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, width, height);
+  wuffs_base__pixel_buffer full_pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (full)",
+               wuffs_base__pixel_buffer__set_from_slice(&full_pb, &ic.pixcfg,
+                                                        g_pixel_slice_u8));
+  CHECK_STATUS("decode_frame (full)",
+               wuffs_base__image_decoder__decode_frame(
+                   full, &full_pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC,
+                   g_work_slice_u8, NULL));
+
+  src.meta.ri = 0;
+  CHECK_STATUS(
+      "decode_image_config (grouped)",
+      wuffs_base__image_decoder__decode_image_config(grouped, NULL, &src));
+
+  wuffs_base__pixel_config__set(
+      &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, width, row_group_height);
+  wuffs_base__pixel_buffer grouped_pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (grouped)",
+               wuffs_base__pixel_buffer__set_from_slice(
+                   &grouped_pb, &ic.pixcfg, g_have_slice_u8));
+
+  wuffs_base__decode_frame_options opts =
+      wuffs_base__null_decode_frame_options();
+  wuffs_base__decode_frame_options__set_row_group_height(&opts,
+                                                         row_group_height);
+
+  // Feed the grouped decoder a little at a time, so that "$short read"
+  // suspensions are interleaved with the row group notes.
+  size_t src_len = src.meta.wi;
+  src.meta.wi = src.meta.ri;
+  src.meta.closed = false;
+
+  // The rows in [y0, y1) have been checked. Unless y0 == y1, each row group
+  // should start at y1 (top-down) or end at y0 (bottom-up).
+  uint32_t y0 = 0;
+  uint32_t y1 = 0;
+  while (true) {
+    wuffs_base__status status = wuffs_base__image_decoder__decode_frame(
+        grouped, &grouped_pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC,
+        g_work_slice_u8, &opts);
+    if ((status.repr == wuffs_base__suspension__short_read) &&
+        (src.meta.wi < src_len)) {
+      src.meta.wi = ((src_len - src.meta.wi) > 13) ? (src.meta.wi + 13)  //
+                                                    : src_len;
+      src.meta.closed = src.meta.wi == src_len;
+      continue;
+    } else if (status.repr != wuffs_base__note__row_group_decoded) {
+      CHECK_STATUS("decode_frame (grouped)", status);
+      break;
+    }
+
+    wuffs_base__rect_ie_u32 r =
+        wuffs_base__image_decoder__frame_dirty_rect(grouped);
+    bool top_down = (y0 == y1) ? (r.min_incl_y == 0) : (r.min_incl_y == y1);
+    bool bottom_up =
+        (y0 == y1) ? (r.max_excl_y == height) : (r.max_excl_y == y0);
+    if ((!top_down && !bottom_up) || (r.max_excl_y <= r.min_incl_y) ||
+        ((r.max_excl_y - r.min_incl_y) > row_group_height) ||
+        (r.max_excl_y > height)) {
+      RETURN_FAIL("row_group_height=%" PRIu32 ": dirty rect: have y in [%" PRIu32
+                  ", %" PRIu32 "), after rows [%" PRIu32 ", %" PRIu32 ")",
+                  row_group_height, r.min_incl_y, r.max_excl_y, y0, y1);
+    }
+    uint32_t y;
+    for (y = r.min_incl_y; y < r.max_excl_y; y++) {
+      if (memcmp(g_have_array_u8 + (bytes_per_row * (y - r.min_incl_y)),
+                 g_pixel_array_u8 + (bytes_per_row * y), bytes_per_row)) {
+        RETURN_FAIL("row_group_height=%" PRIu32 ": row %" PRIu32
+                    ": pixels differ",
+                    row_group_height, y);
+      }
+    }
+    if (y0 == y1) {
+      y0 = r.min_incl_y;
+      y1 = r.max_excl_y;
+    } else if (top_down) {
+      y1 = r.max_excl_y;
+    } else {
+      y0 = r.min_incl_y;
+    }
+  }
+
+  if ((y0 != 0) || (y1 != height)) {
+    RETURN_FAIL("row_group_height=%" PRIu32 ": rows decoded: have [%" PRIu32
+                ", %" PRIu32 "), want [0, %" PRIu32 ")",
+                row_group_height, y0, y1, height);
+  }
+  return NULL;
+}
+
+const char*  //
+do_test__wuffs_base__image_decoder__row_groups(
+    wuffs_base__image_decoder* full,
+    wuffs_base__image_decoder* grouped,
+    const char* src_filename,
+    uint32_t row_group_height) {
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, src_filename));
+  return do_test__wuffs_base__image_decoder__row_groups_src(
+      full, grouped, src, row_group_height);
+}
+
+// do_test__wuffs_base__image_decoder__row_groups_animated is like
+// do_test__wuffs_base__image_decoder__row_groups but checks every frame of an
+// animated image. Each frame's row groups should match the frame rect's part
+// of the full decoder's pixel buffer, just after it decoded that frame.
+const char*  //
+do_test__wuffs_base__image_decoder__row_groups_animated(
+    wuffs_base__image_decoder* full,
+    wuffs_base__image_decoder* grouped,
+    const char* src_filename,
+    uint32_t row_group_height) {
+  wuffs_base__io_buffer full_src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&full_src, src_filename));
+  wuffs_base__io_buffer grouped_src = full_src;
+
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  CHECK_STATUS(
+      "decode_image_config (full)",
+      wuffs_base__image_decoder__decode_image_config(full, &ic, &full_src));
+  CHECK_STATUS("decode_image_config (grouped)",
+               wuffs_base__image_decoder__decode_image_config(grouped, NULL,
+                                                              &grouped_src));
+
+  uint32_t width = wuffs_base__pixel_config__width(&ic.pixcfg);
+  uint32_t height = wuffs_base__pixel_config__height(&ic.pixcfg);
+  if (((width * height * 4) > PIXEL_BUFFER_ARRAY_SIZE) ||
+      ((width * row_group_height * 4) > IO_BUFFER_ARRAY_SIZE)) {
+    return "dimensions are too large";
+  }
+  size_t bytes_per_row = width * 4;
+
+  wuffs_base__pixel_config__set(
+      &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, width, height);
+  wuffs_base__pixel_buffer full_pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (full)",
+               wuffs_base__pixel_buffer__set_from_slice(&full_pb, &ic.pixcfg,
+                                                        g_pixel_slice_u8));
+  wuffs_base__pixel_config__set(
+      &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, width, row_group_height);
+  wuffs_base__pixel_buffer grouped_pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (grouped)",
+               wuffs_base__pixel_buffer__set_from_slice(
+                   &grouped_pb, &ic.pixcfg, g_have_slice_u8));
+
+  wuffs_base__decode_frame_options opts =
+      wuffs_base__null_decode_frame_options();
+  wuffs_base__decode_frame_options__set_row_group_height(&opts,
+                                                         row_group_height);
+
+  uint32_t num_frames = 0;
+  while (true) {
+    wuffs_base__status status =
+        wuffs_base__image_decoder__decode_frame_config(full, NULL, &full_src);
+    if (status.repr == wuffs_base__note__end_of_data) {
+      break;
+    }
+    CHECK_STATUS("decode_frame_config (full)", status);
+    CHECK_STATUS("decode_frame_config (grouped)",
+                 wuffs_base__image_decoder__decode_frame_config(grouped, NULL,
+                                                                &grouped_src));
+    CHECK_STATUS("decode_frame (full)",
+                 wuffs_base__image_decoder__decode_frame(
+                     full, &full_pb, &full_src, WUFFS_BASE__PIXEL_BLEND__SRC,
+                     g_work_slice_u8, NULL));
+    wuffs_base__rect_ie_u32 fr =
+        wuffs_base__image_decoder__frame_dirty_rect(full);
+    size_t x0 = fr.min_incl_x * 4;
+    size_t x1 = fr.max_excl_x * 4;
+
+    uint32_t y = fr.min_incl_y;
+    while (true) {
+      status = wuffs_base__image_decoder__decode_frame(
+          grouped, &grouped_pb, &grouped_src, WUFFS_BASE__PIXEL_BLEND__SRC,
+          g_work_slice_u8, &opts);
+      if (status.repr != wuffs_base__note__row_group_decoded) {
+        CHECK_STATUS("decode_frame (grouped)", status);
+        break;
+      }
+      wuffs_base__rect_ie_u32 r =
+          wuffs_base__image_decoder__frame_dirty_rect(grouped);
+      if ((r.min_incl_x != fr.min_incl_x) || (r.max_excl_x != fr.max_excl_x) ||
+          (r.min_incl_y != y) || (r.max_excl_y <= y) ||
+          ((r.max_excl_y - y) > row_group_height)) {
+        RETURN_FAIL("frame #%" PRIu32 ": dirty rect: have y in [%" PRIu32
+                    ", %" PRIu32 "), want min_incl_y %" PRIu32,
+                    num_frames, r.min_incl_y, r.max_excl_y, y);
+      }
+      for (; y < r.max_excl_y; y++) {
+        if (memcmp(g_have_array_u8 + (bytes_per_row * (y - r.min_incl_y)) + x0,
+                   g_pixel_array_u8 + (bytes_per_row * y) + x0, x1 - x0)) {
+          RETURN_FAIL("frame #%" PRIu32 ": row %" PRIu32 ": pixels differ",
+                      num_frames, y);
+        }
+      }
+    }
+    if (y != fr.max_excl_y) {
+      RETURN_FAIL("frame #%" PRIu32 ": rows decoded: have %" PRIu32
+                  ", want %" PRIu32,
+                  num_frames, y, fr.max_excl_y);
+    }
+    num_frames++;
+  }
+
+  if (num_frames < 2) {
+    RETURN_FAIL("num_frames: have %" PRIu32 ", want >= 2", num_frames);
+  }
+  return NULL;
+}
+
+// do_test__wuffs_base__image_decoder__passes decodes the same image twice:
+// once by the full decoder and once by the passes decoder, which opts in to
+// pass notifications and is fed its source a little at a time. It checks the
+// number of notes and that the two decodings produce the same pixels.
+const char*  //
+do_test__wuffs_base__image_decoder__passes(wuffs_base__image_decoder* full,
+                                           wuffs_base__image_decoder* passes,
+                                           const char* src_filename,
+                                           uint32_t want_num_notes) {
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+      .data = g_src_slice_u8,
+  });
+  CHECK_STRING(read_file(&src, src_filename));
+  CHECK_STATUS("decode_image_config (full)",
+               wuffs_base__image_decoder__decode_image_config(full, &ic, &src));
+
+  uint32_t width = wuffs_base__pixel_config__width(&ic.pixcfg);
+  uint32_t height = wuffs_base__pixel_config__height(&ic.pixcfg);
+  if ((width > 16384) || (height > 16384) ||
+      ((width * height * 4) > PIXEL_BUFFER_ARRAY_SIZE) ||
+      ((width * height * 4) > IO_BUFFER_ARRAY_SIZE)) {
+    return "dimensions are too large";
+  }
+
+  wuffs_base__pixel_config__set(
+      &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, width, height);
+  wuffs_base__pixel_buffer full_pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (full)",
+               wuffs_base__pixel_buffer__set_from_slice(&full_pb, &ic.pixcfg,
+                                                        g_pixel_slice_u8));
+  CHECK_STATUS("decode_frame (full)",
+               wuffs_base__image_decoder__decode_frame(
+                   full, &full_pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC,
+                   g_work_slice_u8, NULL));
+
+  src.meta.ri = 0;
+  CHECK_STATUS(
+      "decode_image_config (passes)",
+      wuffs_base__image_decoder__decode_image_config(passes, NULL, &src));
+  wuffs_base__pixel_buffer passes_pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice (passes)",
+               wuffs_base__pixel_buffer__set_from_slice(&passes_pb, &ic.pixcfg,
+                                                        g_have_slice_u8));
+
+  wuffs_base__decode_frame_options opts =
+      wuffs_base__null_decode_frame_options();
+  wuffs_base__decode_frame_options__set_report_passes(&opts, true);
+
+  // Feed the passes decoder a little at a time, so that "$short read"
+  // suspensions are interleaved with the pass notes.
+  size_t src_len = src.meta.wi;
+  src.meta.wi = src.meta.ri;
+  src.meta.closed = false;
+
+  uint32_t have_num_notes = 0;
+  while (true) {
+    wuffs_base__status status = wuffs_base__image_decoder__decode_frame(
+        passes, &passes_pb, &src, WUFFS_BASE__PIXEL_BLEND__SRC,
+        g_work_slice_u8, &opts);
+    if ((status.repr == wuffs_base__suspension__short_read) &&
+        (src.meta.wi < src_len)) {
+      src.meta.wi = ((src_len - src.meta.wi) > 13) ? (src.meta.wi + 13)  //
+                                                    : src_len;
+      src.meta.closed = src.meta.wi == src_len;
+      continue;
+    } else if (status.repr != wuffs_base__note__pass_decoded) {
+      CHECK_STATUS("decode_frame (passes)", status);
+      break;
+    }
+
+    have_num_notes++;
+    wuffs_base__rect_ie_u32 r =
+        wuffs_base__image_decoder__frame_dirty_rect(passes);
+    if (wuffs_base__rect_ie_u32__is_empty(&r)) {
+      RETURN_FAIL("note #%" PRIu32 ": dirty rect is empty", have_num_notes);
+    }
+  }
+
+  if (have_num_notes != want_num_notes) {
+    RETURN_FAIL("notes: have %" PRIu32 ", want %" PRIu32, have_num_notes,
+                want_num_notes);
+  } else if (memcmp(g_have_array_u8, g_pixel_array_u8, width * height * 4)) {
+    RETURN_FAIL("pixels differ");
+  }
+  return NULL;
+}
+
+// write_token_debug_format writes t, a token at position pos in the source,
+// to the 16 bytes at ptr. This 16-bytes-per-token debug format is the same one
+// used by `script/print-json-token-debug-format.c`.
+void  //
+write_token_debug_format(uint8_t* ptr, wuffs_base__token* t, uint64_t pos) {
+  uint16_t len = wuffs_base__token__length(t);
+  uint16_t con = wuffs_base__token__continued(t) ? 1 : 0;
+  int32_t vmajor = wuffs_base__token__value_major(t);
+
+  wuffs_base__poke_u32be__no_bounds_check(ptr + 0x0, (uint32_t)(pos));
+  wuffs_base__poke_u16be__no_bounds_check(ptr + 0x4, len);
+  wuffs_base__poke_u16be__no_bounds_check(ptr + 0x6, con);
+  if (vmajor > 0) {
+    wuffs_base__poke_u32be__no_bounds_check(ptr + 0x8, vmajor);
+    uint32_t vminor = wuffs_base__token__value_minor(t);
+    wuffs_base__poke_u32be__no_bounds_check(ptr + 0xC, vminor);
+  } else if (vmajor == 0) {
+    uint8_t vbc = wuffs_base__token__value_base_category(t);
+    uint32_t vbd = wuffs_base__token__value_base_detail(t);
+    wuffs_base__poke_u32be__no_bounds_check(ptr + 0x8, 0);
+    wuffs_base__poke_u8__no_bounds_check(ptr + 0x000C, vbc);
+    wuffs_base__poke_u24be__no_bounds_check(ptr + 0xD, vbd);
+  } else {
+    wuffs_base__poke_u8__no_bounds_check(ptr + 0x0008, 0x01);
+    wuffs_base__poke_u56be__no_bounds_check(
+        ptr + 0x9, wuffs_base__token__value_extension(t));
+  }
+}
+
+// The corpus index (test/data/corpus-index.txt) records the expected result of
+// decoding each test/data file. Its format is documented in the Go package
+// lib/corpusindex. Imported conformance suites (see
+// script/import-conformance-suite.go) have their own corpus indexes, which are
+// optional, as those suites are not part of this repository. So is each
+// package's own corpus, test/corpus/<package>/corpus-index.txt.
+
+const char* g_corpus_index_filenames[] = {
+    "test/data/corpus-index.txt",
+    "test/data/conformance/jsontestsuite/corpus-index.txt",
+    "test/data/conformance/pngsuite/corpus-index.txt",
+    "test/data/conformance/zlib/corpus-index.txt",
+};
+
+const char* g_corpus_index_trailing_data = "#corpusindex: trailing data";
+
+uint32_t g_corpus_index_crc32_table[256] = {0};
+
+uint32_t  //
+corpus_index_crc32_update(uint32_t crc, const uint8_t* ptr, size_t len) {
+  if (g_corpus_index_crc32_table[1] == 0) {
+    uint32_t i;
+    for (i = 0; i < 256; i++) {
+      uint32_t c = i;
+      int j;
+      for (j = 0; j < 8; j++) {
+        c = (c & 1) ? (0xEDB88320 ^ (c >> 1)) : (c >> 1);
+      }
+      g_corpus_index_crc32_table[i] = c;
+    }
+  }
+  crc = ~crc;
+  for (; len > 0; ptr++, len--) {
+    crc = g_corpus_index_crc32_table[0xFF & (crc ^ *ptr)] ^ (crc >> 8);
+  }
+  return ~crc;
+}
+
+// decode_corpus_index_entry decodes src and writes the "width height frames
+// digest status" columns of its corpus index entry to dst.
+const char*  //
+decode_corpus_index_entry(char* dst,
+                          size_t dst_len,
+                          wuffs_base__image_decoder* b,
+                          uint32_t* quirks_ptr,
+                          size_t quirks_len,
+                          wuffs_base__io_buffer* src) {
+  size_t i;
+  for (i = 0; i < quirks_len; i++) {
+    wuffs_base__image_decoder__set_quirk_enabled(b, quirks_ptr[i], true);
+  }
+
+  wuffs_base__image_config ic = ((wuffs_base__image_config){});
+  wuffs_base__status status =
+      wuffs_base__image_decoder__decode_image_config(b, &ic, src);
+  if (!wuffs_base__status__is_ok(&status)) {
+    snprintf(dst, dst_len, "0 0 0 - %s", status.repr);
+    return NULL;
+  }
+
+  uint32_t width = wuffs_base__pixel_config__width(&ic.pixcfg);
+  uint32_t height = wuffs_base__pixel_config__height(&ic.pixcfg);
+  if ((width > 16384) || (height > 16384) ||
+      ((width * height * 4) > PIXEL_BUFFER_ARRAY_SIZE)) {
+    return "dimensions are too large";
+  }
+  size_t canvas_len = ((size_t)width) * ((size_t)height) * 4;
+  memset(g_pixel_array_u8, 0, canvas_len);
+
+  wuffs_base__pixel_config__set(
+      &ic.pixcfg, WUFFS_BASE__PIXEL_FORMAT__BGRA_NONPREMUL,
+      WUFFS_BASE__PIXEL_SUBSAMPLING__NONE, width, height);
+  wuffs_base__pixel_buffer pb = ((wuffs_base__pixel_buffer){});
+  CHECK_STATUS("set_from_slice", wuffs_base__pixel_buffer__set_from_slice(
+                                     &pb, &ic.pixcfg, g_pixel_slice_u8));
+
+  uint64_t num_frames = 0;
+  bool has_digest = false;
+  uint32_t digest = 0;
+  while (true) {
+    wuffs_base__frame_config fc = ((wuffs_base__frame_config){});
+    status = wuffs_base__image_decoder__decode_frame_config(b, &fc, src);
+    if (status.repr == wuffs_base__note__end_of_data) {
+      status = wuffs_base__make_status(NULL);
+      break;
+    } else if (!wuffs_base__status__is_ok(&status)) {
+      break;
+    }
+
+    wuffs_base__pixel_blend blend =
+        ((num_frames == 0) ||
+         wuffs_base__frame_config__overwrite_instead_of_blend(&fc))
+            ? WUFFS_BASE__PIXEL_BLEND__SRC
+            : WUFFS_BASE__PIXEL_BLEND__SRC_OVER;
+    status = wuffs_base__image_decoder__decode_frame(b, &pb, src, blend,
+                                                     g_work_slice_u8, NULL);
+    has_digest = true;
+    digest = corpus_index_crc32_update(digest, g_pixel_array_u8, canvas_len);
+    if (!wuffs_base__status__is_ok(&status)) {
+      break;
+    }
+    num_frames++;
+  }
+
+  char digest_str[16];
+  if (has_digest) {
+    snprintf(digest_str, sizeof(digest_str), "0x%08" PRIX32, digest);
+  } else {
+    snprintf(digest_str, sizeof(digest_str), "-");
+  }
+  snprintf(dst, dst_len, "%" PRIu32 " %" PRIu32 " %" PRIu64 " %s %s", width,
+           height, num_frames, digest_str,
+           status.repr ? status.repr : "ok");
+  return NULL;
+}
+
+// transform_corpus_index_entry is like decode_corpus_index_entry but for an
+// io_transformer instead of an image_decoder.
+const char*  //
+transform_corpus_index_entry(char* dst,
+                             size_t dst_len,
+                             wuffs_base__io_transformer* b,
+                             uint32_t* quirks_ptr,
+                             size_t quirks_len,
+                             wuffs_base__io_buffer* src) {
+  size_t i;
+  for (i = 0; i < quirks_len; i++) {
+    wuffs_base__io_transformer__set_quirk_enabled(b, quirks_ptr[i], true);
+  }
+  wuffs_base__range_ii_u64 workbuf_len =
+      wuffs_base__io_transformer__workbuf_len(b);
+  if (workbuf_len.max_incl > IO_BUFFER_ARRAY_SIZE) {
+    return "workbuf_len is too large";
+  }
+
+  wuffs_base__io_buffer have = ((wuffs_base__io_buffer){
+      .data = g_have_slice_u8,
+  });
+  wuffs_base__status status = wuffs_base__io_transformer__transform_io(
+      b, &have, src, g_work_slice_u8);
+  if (status.repr == wuffs_base__suspension__short_write) {
+    return "output is too long";
+  } else if (wuffs_base__status__is_ok(&status) &&
+             (src->meta.ri < src->meta.wi)) {
+    status.repr = g_corpus_index_trailing_data;
+  }
+
+  char digest_str[16];
+  if (have.meta.wi > 0) {
+    snprintf(digest_str, sizeof(digest_str), "0x%08" PRIX32,
+             corpus_index_crc32_update(0, have.data.ptr, have.meta.wi));
+  } else {
+    snprintf(digest_str, sizeof(digest_str), "-");
+  }
+  snprintf(dst, dst_len, "0 0 %zu %s %s", have.meta.wi, digest_str,
+           status.repr ? status.repr : "ok");
+  return NULL;
+}
+
+// tokenize_corpus_index_entry is like decode_corpus_index_entry but for a
+// token_decoder instead of an image_decoder.
+const char*  //
+tokenize_corpus_index_entry(char* dst,
+                            size_t dst_len,
+                            wuffs_base__token_decoder* b,
+                            uint32_t* quirks_ptr,
+                            size_t quirks_len,
+                            wuffs_base__io_buffer* src) {
+  size_t i;
+  for (i = 0; i < quirks_len; i++) {
+    wuffs_base__token_decoder__set_quirk_enabled(b, quirks_ptr[i], true);
+  }
+  wuffs_base__range_ii_u64 workbuf_len =
+      wuffs_base__token_decoder__workbuf_len(b);
+  if (workbuf_len.max_incl > IO_BUFFER_ARRAY_SIZE) {
+    return "workbuf_len is too large";
+  }
+
+  uint64_t num_tokens = 0;
+  uint64_t pos = 0;
+  uint32_t digest = 0;
+  wuffs_base__status status = wuffs_base__make_status(NULL);
+  while (true) {
+    wuffs_base__token_buffer tok = ((wuffs_base__token_buffer){
+        .data = g_have_slice_token,
+    });
+    status = wuffs_base__token_decoder__decode_tokens(b, &tok, src,
+                                                      g_work_slice_u8);
+    for (; tok.meta.ri < tok.meta.wi; tok.meta.ri++) {
+      wuffs_base__token* t = &tok.data.ptr[tok.meta.ri];
+      if (wuffs_base__token__value(t) != 0) {
+        uint8_t buf[16];
+        write_token_debug_format(buf, t, pos);
+        digest = corpus_index_crc32_update(digest, buf, 16);
+        num_tokens++;
+      }
+      pos += wuffs_base__token__length(t);
+    }
+    if (status.repr != wuffs_base__suspension__short_write) {
+      break;
+    }
+  }
+  if (wuffs_base__status__is_ok(&status) && (src->meta.ri < src->meta.wi)) {
+    status.repr = g_corpus_index_trailing_data;
+  }
+
+  char digest_str[16];
+  if (num_tokens > 0) {
+    snprintf(digest_str, sizeof(digest_str), "0x%08" PRIX32, digest);
+  } else {
+    snprintf(digest_str, sizeof(digest_str), "-");
+  }
+  snprintf(dst, dst_len, "0 0 %" PRIu64 " %s %s", num_tokens, digest_str,
+           status.repr ? status.repr : "ok");
+  return NULL;
+}
+
+// corpus_index_next_field returns the next space-separated field of the
+// NUL-terminated line at *p, advancing *p past it.
+char*  //
+corpus_index_next_field(char** p) {
+  char* s = *p;
+  while ((*s == ' ') || (*s == '\t')) {
+    s++;
+  }
+  char* ret = s;
+  while (*s && (*s != ' ') && (*s != '\t')) {
+    s++;
+  }
+  if (*s) {
+    *s++ = '\x00';
+  }
+  *p = s;
+  return ret;
+}
+
+// corpus_index_normalize collapses runs of spaces or tabs in the NUL-terminated
+// s to a single space and trims any trailing ones.
+void  //
+corpus_index_normalize(char* s) {
+  char* d = s;
+  bool space = false;
+  for (; *s; s++) {
+    if ((*s == ' ') || (*s == '\t') || (*s == '\r')) {
+      space = true;
+      continue;
+    }
+    if (space) {
+      *d++ = ' ';
+      space = false;
+    }
+    *d++ = *s;
+  }
+  *d = '\x00';
+}
+
+// corpus_index_match returns whether have, the normalized "width height frames
+// digest status" columns of a decoding result, satisfies want, the same
+// columns of a corpus index entry, which may contain wildcards.
+bool  //
+corpus_index_match(const char* have, const char* want) {
+  int i;
+  for (i = 0; i < 4; i++) {
+    const char* h = strchr(have, ' ');
+    const char* w = strchr(want, ' ');
+    if (!h || !w) {
+      return false;
+    }
+    bool wildcard = (want[0] == '*') && ((want + 1) == w);
+    if (!wildcard &&
+        (((h - have) != (w - want)) || strncmp(have, want, h - have))) {
+      return false;
+    }
+    have = h + 1;
+    want = w + 1;
+  }
+  if (!strcmp(want, "*")) {
+    return true;
+  } else if (!strcmp(want, "error")) {
+    return strcmp(have, "ok") != 0;
+  }
+  return strcmp(have, want) == 0;
+}
+
+typedef struct {
+  const char* (*image_decoder)(wuffs_base__image_decoder** b);
+  const char* (*io_transformer)(wuffs_base__io_transformer** b);
+  const char* (*token_decoder)(wuffs_base__token_decoder** b);
+} corpus_index_initializers;
+
+const char*  //
+do_test__corpus_index(const char* decoder_name,
+                      corpus_index_initializers* initializers) {
+  char package_index_filename[256];
+  snprintf(package_index_filename, sizeof(package_index_filename),
+           "test/corpus/%s/corpus-index.txt", decoder_name);
+
+  int num_entries = 0;
+  size_t f;
+  for (f = 0; f <= WUFFS_TESTLIB_ARRAY_SIZE(g_corpus_index_filenames); f++) {
+    const char* index_filename =
+        (f < WUFFS_TESTLIB_ARRAY_SIZE(g_corpus_index_filenames))
+            ? g_corpus_index_filenames[f]
+            : package_index_filename;
+    if (f > 0) {
+      FILE* probe = fopen(index_filename, "rb");
+      if (!probe) {
+        continue;
+      }
+      fclose(probe);
+    }
+    wuffs_base__io_buffer index = ((wuffs_base__io_buffer){
+        .data = g_want_slice_u8,
+    });
+    CHECK_STRING(read_file(&index, index_filename));
+    if (index.meta.wi >= index.data.len) {
+      RETURN_FAIL("%s: corpus index is too large", index_filename);
+    }
+    index.data.ptr[index.meta.wi] = '\x00';
+    int dir_len = (int)(strrchr(index_filename, '/') - index_filename);
+
+    int line_num = 0;
+    char* line = (char*)(index.data.ptr);
+    while (*line) {
+      line_num++;
+      char* end = strchr(line, '\n');
+      char* next = end ? (end + 1) : (line + strlen(line));
+      if (end) {
+        *end = '\x00';
+      }
+
+      char* p = line;
+      line = next;
+      char* filename = corpus_index_next_field(&p);
+      if ((*filename == '\x00') || (*filename == '#')) {
+        continue;
+      }
+      char* decoder = corpus_index_next_field(&p);
+      char* quirks_str = corpus_index_next_field(&p);
+      if (*quirks_str == '\x00') {
+        RETURN_FAIL("%s line %d: missing columns", index_filename, line_num);
+      } else if (strcmp(decoder, decoder_name)) {
+        continue;
+      }
+      corpus_index_normalize(p);
+      char* want = p;
+      if (*want == ' ') {
+        want++;
+      }
+
+      uint32_t quirks[16];
+      size_t num_quirks = 0;
+      if (strcmp(quirks_str, "-")) {
+        char* q = quirks_str;
+        while (true) {
+          if (num_quirks >= WUFFS_TESTLIB_ARRAY_SIZE(quirks)) {
+            RETURN_FAIL("%s line %d: too many quirks", index_filename,
+                        line_num);
+          }
+          char* q_end = NULL;
+          quirks[num_quirks++] = (uint32_t)(strtoul(q, &q_end, 16));
+          if (*q_end == '\x00') {
+            break;
+          } else if (*q_end != ',') {
+            RETURN_FAIL("%s line %d: invalid quirks", index_filename,
+                        line_num);
+          }
+          q = q_end + 1;
+        }
+      }
+
+      char path[1024];
+      snprintf(path, sizeof(path), "%.*s/%s", dir_len, index_filename,
+               filename);
+      wuffs_base__io_buffer src = ((wuffs_base__io_buffer){
+          .data = g_src_slice_u8,
+      });
+      CHECK_STRING(read_file(&src, path));
+
+      char have[1024];
+      if (initializers->image_decoder) {
+        wuffs_base__image_decoder* b = NULL;
+        CHECK_STRING((*initializers->image_decoder)(&b));
+        CHECK_STRING(decode_corpus_index_entry(have, sizeof(have), b, quirks,
+                                               num_quirks, &src));
+      } else if (initializers->io_transformer) {
+        wuffs_base__io_transformer* b = NULL;
+        CHECK_STRING((*initializers->io_transformer)(&b));
+        CHECK_STRING(transform_corpus_index_entry(have, sizeof(have), b,
+                                                  quirks, num_quirks, &src));
+      } else if (initializers->token_decoder) {
+        wuffs_base__token_decoder* b = NULL;
+        CHECK_STRING((*initializers->token_decoder)(&b));
+        CHECK_STRING(tokenize_corpus_index_entry(have, sizeof(have), b,
+                                                 quirks, num_quirks, &src));
+      } else {
+        return "no initializer";
+      }
+      if (!corpus_index_match(have, want)) {
+        RETURN_FAIL("%s (%s line %d):\nhave \"%s\"\nwant \"%s\"", path,
+                    index_filename, line_num, have, want);
+      }
+      num_entries++;
+    }
+  }
+
+  if (num_entries == 0) {
+    RETURN_FAIL("corpus index: no \"%s\" entries", decoder_name);
+  }
+  return NULL;
+}
+
+const char*  //
+do_test__wuffs_base__image_decoder__corpus_index(
+    const char* decoder_name,
+    const char* (*initialize_decoder)(wuffs_base__image_decoder** b)) {
+  corpus_index_initializers initializers = ((corpus_index_initializers){
+      .image_decoder = initialize_decoder,
+  });
+  return do_test__corpus_index(decoder_name, &initializers);
+}
+
+const char*  //
+do_test__wuffs_base__io_transformer__corpus_index(
+    const char* decoder_name,
+    const char* (*initialize_decoder)(wuffs_base__io_transformer** b)) {
+  corpus_index_initializers initializers = ((corpus_index_initializers){
+      .io_transformer = initialize_decoder,
+  });
+  return do_test__corpus_index(decoder_name, &initializers);
+}
+
+const char*  //
+do_test__wuffs_base__token_decoder__corpus_index(
+    const char* decoder_name,
+    const char* (*initialize_decoder)(wuffs_base__token_decoder** b)) {
+  corpus_index_initializers initializers = ((corpus_index_initializers){
+      .token_decoder = initialize_decoder,
+  });
+  return do_test__corpus_index(decoder_name, &initializers);
+}
+
 const char*  //
 do_test__wuffs_base__io_transformer(wuffs_base__io_transformer* b,
                                     const char* src_filename,
@@ -1310,34 +2132,10 @@ do_test__wuffs_base__token_decoder(wuffs_base__token_decoder* b,
     uint16_t len = wuffs_base__token__length(t);
 
     if (wuffs_base__token__value(t) != 0) {
-      uint16_t con = wuffs_base__token__continued(t) ? 1 : 0;
-      int32_t vmajor = wuffs_base__token__value_major(t);
-
       if ((have.data.len - have.meta.wi) < 16) {
         return "testlib: output is too long";
       }
-      // This 16-bytes-per-token debug format is the same one used by
-      // `script/print-json-token-debug-format.c`.
-      uint8_t* ptr = have.data.ptr + have.meta.wi;
-
-      wuffs_base__poke_u32be__no_bounds_check(ptr + 0x0, (uint32_t)(pos));
-      wuffs_base__poke_u16be__no_bounds_check(ptr + 0x4, len);
-      wuffs_base__poke_u16be__no_bounds_check(ptr + 0x6, con);
-      if (vmajor > 0) {
-        wuffs_base__poke_u32be__no_bounds_check(ptr + 0x8, vmajor);
-        uint32_t vminor = wuffs_base__token__value_minor(t);
-        wuffs_base__poke_u32be__no_bounds_check(ptr + 0xC, vminor);
-      } else if (vmajor == 0) {
-        uint8_t vbc = wuffs_base__token__value_base_category(t);
-        uint32_t vbd = wuffs_base__token__value_base_detail(t);
-        wuffs_base__poke_u32be__no_bounds_check(ptr + 0x8, 0);
-        wuffs_base__poke_u8__no_bounds_check(ptr + 0x000C, vbc);
-        wuffs_base__poke_u24be__no_bounds_check(ptr + 0xD, vbd);
-      } else {
-        wuffs_base__poke_u8__no_bounds_check(ptr + 0x0008, 0x01);
-        wuffs_base__poke_u56be__no_bounds_check(
-            ptr + 0x9, wuffs_base__token__value_extension(t));
-      }
+      write_token_debug_format(have.data.ptr + have.meta.wi, t, pos);
       have.meta.wi += 16;
     }
 